@@ -0,0 +1,179 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/controller-tools/pkg/genall/help"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// newCompletionCommand returns the command printing the shell completion
+// scripts, or the completion data of the markers for editor plugins.
+func newCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish|markers",
+		Short: "Print the shell completion script, or the completion data of the markers.",
+		Long: `Print the completion script of the given shell, completing the generators, their options and the output rules,
+or the completion data of all the markers as JSON for editor plugins.`,
+		Example: `	# Load the completions in the current bash session
+	source <(controller-gen completion bash)
+
+	# Load the completions for each new zsh session
+	controller-gen completion zsh > "${fpath[1]}/_controller-gen"
+
+	# Load the completions for each new fish session
+	controller-gen completion fish > ~/.config/fish/completions/controller-gen.fish
+
+	# Save the completion data of the markers for an editor plugin
+	controller-gen completion markers > markers-completion.json`,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"bash", "zsh", "fish", "markers"},
+		RunE: func(c *cobra.Command, args []string) error {
+			out := c.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return c.Root().GenBashCompletionV2(out, true)
+			case "zsh":
+				return c.Root().GenZshCompletion(out)
+			case "fish":
+				return c.Root().GenFishCompletion(out, true)
+			default:
+				regsByGen, err := allMarkerRegistries()
+				if err != nil {
+					return err
+				}
+				helpInfo, err := help.ForGenerators(regsByGen)
+				if err != nil {
+					return err
+				}
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				enc.SetEscapeHTML(false)
+				return enc.Encode(help.Completions(helpInfo))
+			}
+		},
+	}
+}
+
+// allMarkerRegistries returns the registry of the markers of each known
+// generator, by name.
+func allMarkerRegistries() (map[string]*markers.Registry, error) {
+	regsByGen := make(map[string]*markers.Registry, len(allGenerators))
+	for genName, gen := range allGenerators {
+		reg := &markers.Registry{}
+		if err := gen.RegisterMarkers(reg); err != nil {
+			return nil, fmt.Errorf("unable to register the markers of the %s generator: %w", genName, err)
+		}
+		regsByGen[genName] = reg
+	}
+	return regsByGen, nil
+}
+
+// completeOptions completes the options (generators, output rules and their
+// arguments) on the command line, a segment at a time: "cr" completes to "crd"
+// and "crd:", "crd:" to each of its arguments, and "output:" to each output
+// rule and each generator having its own ones.
+func completeOptions(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	if eq := strings.Index(toComplete, "="); eq >= 0 {
+		// completing another argument of an option, after a comma
+		comma := strings.LastIndex(toComplete, ",")
+		if comma < eq {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		name := toComplete[:eq]
+		if colon := strings.LastIndex(name, ":"); colon >= 0 {
+			name = name[:colon]
+		}
+		for _, defn := range optionsRegistry.AllDefinitions() {
+			if defn.Name != name {
+				continue
+			}
+			for _, arg := range argumentNames(defn) {
+				if !strings.Contains(toComplete, ":"+arg+"=") && !strings.Contains(toComplete, ","+arg+"=") {
+					completions = append(completions, toComplete[:comma+1]+arg+"=")
+				}
+			}
+		}
+	} else {
+		for _, defn := range optionsRegistry.AllDefinitions() {
+			for _, option := range optionSyntaxes(defn) {
+				if !strings.HasPrefix(option, toComplete) {
+					continue
+				}
+				// only complete up to the next segment
+				if colon := strings.Index(option[len(toComplete):], ":"); colon >= 0 {
+					option = option[:len(toComplete)+colon+1]
+				}
+				completions = append(completions, option)
+			}
+		}
+	}
+	slices.Sort(completions)
+	completions = slices.Compact(completions)
+
+	directive := cobra.ShellCompDirectiveNoFileComp
+	for _, completion := range completions {
+		if strings.HasSuffix(completion, ":") || strings.HasSuffix(completion, "=") {
+			// more is to be typed after those
+			directive |= cobra.ShellCompDirectiveNoSpace
+			break
+		}
+	}
+	return completions, directive
+}
+
+// optionSyntaxes returns the ways of starting to write the given option: its
+// name if it may be given without arguments, and its name with each argument.
+func optionSyntaxes(defn *markers.Definition) []string {
+	if defn.Empty() {
+		return []string{defn.Name}
+	}
+	if defn.AnonymousField() {
+		return []string{defn.Name + "="}
+	}
+
+	var syntaxes []string
+	allOptional := true
+	for _, arg := range argumentNames(defn) {
+		syntaxes = append(syntaxes, defn.Name+":"+arg+"=")
+		allOptional = allOptional && defn.Fields[arg].Optional
+	}
+	if allOptional {
+		syntaxes = append(syntaxes, defn.Name)
+	}
+	return syntaxes
+}
+
+// argumentNames returns the sorted names of the arguments of the given option.
+func argumentNames(defn *markers.Definition) []string {
+	names := make([]string, 0, len(defn.Fields))
+	for name := range defn.Fields {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,116 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+
+	"sigs.k8s.io/controller-tools/pkg/genall/help"
+)
+
+var _ = Describe("Completion", func() {
+	// complete returns the completions of the given word, without their
+	// descriptions, and the completion directive.
+	complete := func(toComplete string) ([]string, string) {
+		session := controllerGen(GinkgoT().TempDir(), nil, "__complete", toComplete)
+		Expect(session).To(gexec.Exit(0))
+		lines := strings.Split(strings.TrimSpace(string(session.Out.Contents())), "\n")
+		var completions []string
+		for _, line := range lines[:len(lines)-1] {
+			completion, _, _ := strings.Cut(line, "\t")
+			completions = append(completions, completion)
+		}
+		return completions, lines[len(lines)-1]
+	}
+
+	DescribeTable("should print the completion script of each shell",
+		func(shell, header string) {
+			session := controllerGen(GinkgoT().TempDir(), nil, "completion", shell)
+			Expect(session).To(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(HavePrefix(header))
+		},
+		Entry("bash", "bash", "# bash completion V2 for controller-gen"),
+		Entry("zsh", "zsh", "#compdef controller-gen"),
+		Entry("fish", "fish", "# fish completion for controller-gen"),
+	)
+
+	It("should refuse unknown shells", func() {
+		session := controllerGen(GinkgoT().TempDir(), nil, "completion", "powershell")
+		Expect(session).To(gexec.Exit(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring(`invalid argument "powershell"`))
+	})
+
+	It("should complete the generators a segment at a time", func() {
+		completions, directive := complete("rb")
+		Expect(completions).To(ContainElements("rbac", "rbac:"))
+		Expect(completions).To(HaveEach(HavePrefix("rb")))
+		// no space, and no files
+		Expect(directive).To(Equal(":6"))
+	})
+
+	It("should complete the arguments of the generators", func() {
+		completions, _ := complete("crd:")
+		Expect(completions).To(ContainElements("crd:maxDescLen=", "crd:crdVersions=", "crd:allowDangerousTypes="))
+		Expect(completions).To(HaveEach(HavePrefix("crd:")))
+
+		By("completing the other arguments after a comma")
+		completions, _ = complete("crd:maxDescLen=0,")
+		Expect(completions).To(ContainElement("crd:maxDescLen=0,crdVersions="))
+		Expect(completions).NotTo(ContainElement("crd:maxDescLen=0,maxDescLen="), "arguments given already shouldn't be completed")
+
+		By("completing nothing within the value of an argument")
+		completions, directive := complete("crd:maxDescLen=")
+		Expect(completions).To(BeEmpty())
+		Expect(directive).To(Equal(":4"))
+	})
+
+	It("should complete the output rules, and the generators having their own", func() {
+		completions, _ := complete("output:")
+		Expect(completions).To(ContainElements("output:dir=", "output:none", "output:stdout", "output:bundle:", "output:archive:", "output:crd:", "output:rbac:"))
+
+		completions, _ = complete("output:crd:")
+		Expect(completions).To(ContainElements("output:crd:dir=", "output:crd:stdout", "output:crd:artifacts:"))
+	})
+
+	It("should print the completion data of all the markers", func() {
+		session := controllerGen(GinkgoT().TempDir(), nil, "completion", "markers")
+		Expect(session).To(gexec.Exit(0))
+		var completions []help.Completion
+		Expect(json.Unmarshal(session.Out.Contents(), &completions)).To(Succeed())
+		Expect(completions).To(ContainElement(help.Completion{
+			Label:      "+kubebuilder:object:root",
+			InsertText: "+kubebuilder:object:root",
+			Target:     "type",
+			Syntax:     "+kubebuilder:object:root=<bool>",
+		}))
+		Expect(completions).To(ContainElement(And(
+			HaveField("Label", "+kubebuilder:validation:Minimum"),
+			HaveField("InsertText", "+kubebuilder:validation:Minimum="),
+			HaveField("Target", "field"),
+		)))
+		Expect(completions).To(ContainElement(And(
+			HaveField("Label", "+kubebuilder:rbac"),
+			HaveField("Target", "package"),
+			HaveField("Syntax", ContainSubstring("groups=")),
+		)))
+	})
+})
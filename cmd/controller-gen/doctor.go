@@ -0,0 +1,66 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+)
+
+// newDoctorCommand returns the command checking the environment of the run of
+// the given options, which it runs with the given function, having set
+// doctor.
+func newDoctorCommand(doctor *bool, run func(*cobra.Command, []string) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor [options]",
+		Short: "Check that the environment can run the generators of the given options, explaining the problems found.",
+		Long: `Check that the environment can run the generators of the given options (or of the configuration file),
+without running them: that the go command can be run in a module, that the packages and their dependencies
+can be loaded, that the envtest binaries are found if needed, and that the output directories can be written to.
+The likely causes of the problems found, and how to fix them, are explained.`,
+		Example: `	# Check why running the generators of a project fails
+	controller-gen doctor object crd rbac:roleName=manager-role paths=./api/... output:crd:dir=./config/crd/bases`,
+		RunE: func(c *cobra.Command, rawOpts []string) error {
+			*doctor = true
+			return run(c, rawOpts)
+		},
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeOptions,
+		SilenceUsage:      true,
+	}
+}
+
+// runDoctor checks the environment of the run of the given options, printing
+// the result of each check, and failing if any did.
+func runDoctor(c *cobra.Command, opts []controllergen.Option) error {
+	out := c.OutOrStdout()
+	failed := false
+	for _, res := range controllergen.Doctor(c.Context(), opts...) {
+		fmt.Fprintf(out, "%-8s %s: %s\n", res.Status, res.Check, res.Message)
+		if res.Hint != "" {
+			fmt.Fprintf(out, "%-8s hint: %s\n", "", res.Hint)
+		}
+		failed = failed || res.Status == controllergen.CheckFailed
+	}
+	if failed {
+		return noUsageError{fmt.Errorf("found problems with the environment")}
+	}
+	return nil
+}
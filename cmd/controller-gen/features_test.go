@@ -0,0 +1,58 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("The feature gates flag", func() {
+	var testdataDir string
+
+	BeforeEach(func() {
+		var err error
+		testdataDir, err = filepath.Abs(filepath.Join("..", "..", "pkg", "docs", "testdata"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should run with the given gates", func() {
+		outDir := GinkgoT().TempDir()
+		session := controllerGen(testdataDir, nil, "--feature-gates=ConcurrentGenerators=false,ConcurrentSchemas=true", "crd", "paths=./api/...", "output:crd:dir="+outDir)
+		Expect(session).To(gexec.Exit(0))
+		Expect(filepath.Join(outDir, "testdata.kubebuilder.io_widgets.yaml")).To(BeARegularFile())
+	})
+
+	It("should refuse unknown features, and invalid values", func() {
+		session := controllerGen(testdataDir, nil, "--feature-gates=Nope=true", "crd", "paths=./api/...")
+		Expect(session).To(gexec.Exit(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring("unknown feature Nope"))
+
+		session = controllerGen(testdataDir, nil, "--feature-gates=ConcurrentGenerators=maybe", "crd", "paths=./api/...")
+		Expect(session).To(gexec.Exit(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring(`invalid value "maybe" of feature gate ConcurrentGenerators`))
+	})
+
+	It("should describe the known features in the usage", func() {
+		session := controllerGen(testdataDir, nil, "--help")
+		Expect(session).To(gexec.Exit(0))
+		Expect(string(session.Out.Contents())).To(ContainSubstring("ConcurrentGenerators=true|false (BETA - default=true)"))
+	})
+})
@@ -17,121 +17,88 @@ limitations under the License.
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/tools/go/packages"
+	"k8s.io/klog/v2"
 
-	"sigs.k8s.io/controller-tools/pkg/applyconfiguration"
-	"sigs.k8s.io/controller-tools/pkg/crd"
-	"sigs.k8s.io/controller-tools/pkg/deepcopy"
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
 	"sigs.k8s.io/controller-tools/pkg/genall"
 	"sigs.k8s.io/controller-tools/pkg/genall/help"
 	prettyhelp "sigs.k8s.io/controller-tools/pkg/genall/help/pretty"
+	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
-	"sigs.k8s.io/controller-tools/pkg/rbac"
-	"sigs.k8s.io/controller-tools/pkg/schemapatcher"
 	"sigs.k8s.io/controller-tools/pkg/version"
-	"sigs.k8s.io/controller-tools/pkg/webhook"
+	"sigs.k8s.io/yaml"
 )
 
 //go:generate go run ../helpgen/main.go paths=../../pkg/... generate:headerFile=../../hack/boilerplate/boilerplate.go.txt
 
-// Options are specified to controller-gen by turning generators and output rules into
-// markers, and then parsing them using the standard registry logic (without the "+").
-// Each marker and output rule should thus be usable as a marker target.
-
 var (
-	// allGenerators maintains the list of all known generators, giving
-	// them names for use on the command line.
-	// each turns into a command line option,
-	// and has options for output forms.
-	allGenerators = map[string]genall.Generator{
-		"crd":                crd.Generator{},
-		"rbac":               rbac.Generator{},
-		"object":             deepcopy.Generator{},
-		"applyconfiguration": applyconfiguration.Generator{},
-		"webhook":            webhook.Generator{},
-		"schemapatch":        schemapatcher.Generator{},
-	}
-
-	// allOutputRules defines the list of all known output rules, giving
-	// them names for use on the command line.
-	// Each output rule turns into two command line options:
-	// - output:<generator>:<form> (per-generator output)
-	// - output:<form> (default output)
-	allOutputRules = map[string]genall.OutputRule{
-		"dir":       genall.OutputToDirectory(""),
-		"none":      genall.OutputToNothing,
-		"stdout":    genall.OutputToStdout,
-		"artifacts": genall.OutputArtifacts{},
-	}
+	// allGenerators are the generators known to controller-gen, by name.
+	allGenerators = controllergen.Generators()
 
 	// optionsRegistry contains all the marker definitions used to process command line options
-	optionsRegistry = &markers.Registry{}
+	optionsRegistry = controllergen.OptionsRegistry()
 )
 
-func init() {
-	for genName, gen := range allGenerators {
-		// make the generator options marker itself
-		defn := markers.Must(markers.MakeDefinition(genName, markers.DescribesPackage, gen))
-		if err := optionsRegistry.Register(defn); err != nil {
-			panic(err)
-		}
-		if helpGiver, hasHelp := gen.(genall.HasHelp); hasHelp {
-			if help := helpGiver.Help(); help != nil {
-				optionsRegistry.AddHelp(defn, help)
-			}
-		}
-
-		// make per-generation output rule markers
-		for ruleName, rule := range allOutputRules {
-			ruleMarker := markers.Must(markers.MakeDefinition(fmt.Sprintf("output:%s:%s", genName, ruleName), markers.DescribesPackage, rule))
-			if err := optionsRegistry.Register(ruleMarker); err != nil {
-				panic(err)
-			}
-			if helpGiver, hasHelp := rule.(genall.HasHelp); hasHelp {
-				if help := helpGiver.Help(); help != nil {
-					optionsRegistry.AddHelp(ruleMarker, help)
-				}
-			}
-		}
-	}
-
-	// make "default output" output rule markers
-	for ruleName, rule := range allOutputRules {
-		ruleMarker := markers.Must(markers.MakeDefinition("output:"+ruleName, markers.DescribesPackage, rule))
-		if err := optionsRegistry.Register(ruleMarker); err != nil {
-			panic(err)
-		}
-		if helpGiver, hasHelp := rule.(genall.HasHelp); hasHelp {
-			if help := helpGiver.Help(); help != nil {
-				optionsRegistry.AddHelp(ruleMarker, help)
-			}
-		}
-	}
-
-	// add in the common options markers
-	if err := genall.RegisterOptionsMarkers(optionsRegistry); err != nil {
-		panic(err)
-	}
-}
-
 // noUsageError suppresses usage printing when it occurs
 // (since cobra doesn't provide a good way to avoid printing
 // out usage in only certain situations).
 type noUsageError struct{ error }
 
+// reportedError is an error that was already reported (e.g. as JSON
+// diagnostics), so that only the exit status is left to set.
+type reportedError struct{ error }
+
 func main() {
 	helpLevel := 0
 	whichLevel := 0
 	showVersion := false
 	var buildTags []string
+	var platform string
+	loadCache, _ := loader.DefaultTypesCacheDir()
+	var configFile string
+	verify := false
+	watch := false
+	keepGoing := false
+	scopeErrors := false
+	lint := false
+	dryRun := false
+	parallelism := 0
+	diagnostics := string(genall.DiagnosticsText)
+	errorReport := false
+	timings := ""
+	checkConsistency := false
+	doctor := false
+	lineEndings := string(genall.LineEndingsLF)
+	pinnedVersion := ""
+	var goHeader genall.GoHeader
+	var goTemplates string
+	var featureGates []string
+	var generationManifest, depfile, loadGraph string
+	var profiles genall.Profiles
+	var cluster genall.ClusterConfig
+	pathsFrom := ""
+	changedOnly := ""
+	verbosity := 0
+	logFormat := "text"
+	exportMarkers := ""
+	var vars map[string]string
+	varsFromEnv := false
 
 	cmd := &cobra.Command{
 		Use:   "controller-gen",
@@ -153,10 +120,91 @@ func main() {
 	# Explain the markers for generating CRDs, and their arguments
 	controller-gen crd -ww
 
+	# Complete the generators, their options and the output rules in bash
+	source <(controller-gen completion bash)
+
+	# Export the reference of all the markers, e.g. for the docs site or an editor extension
+	controller-gen --export-markers=yaml > markers.yaml
+
 	# Generate applyconfigurations for CRDs for use with Server Side Apply. They will be placed
 	# into a "applyconfiguration/" subdirectory
 
 	controller-gen applyconfiguration paths=./apis/...
+
+	# Run the generators declared in controller-gen.yaml, overriding the options of the crd generator
+	controller-gen crd:maxDescLen=0
+
+	# Check that the generated code and manifests are up to date, e.g. in CI
+	controller-gen --verify object crd paths=./apis/...
+
+	# Show which files the generators would write, and where, without writing them
+	controller-gen --dry-run object crd rbac:roleName=manager-role paths=./apis/... output:crd:dir=./config/crd/bases
+
+	# Regenerate deepcopy implementations and CRDs whenever the API types are saved
+	controller-gen --watch object crd paths=./apis/...
+
+	# Regenerate only the CRD of the CronJob kind, leaving the CRDs of the other kinds untouched
+	controller-gen crd crd:only=batch.tutorial.kubebuilder.io/v1/CronJob paths=./apis/... output:crd:dir=./config/crd/bases
+
+	# Output the CRDs and RBAC manifests to a single archive, e.g. to attach it to a release
+	controller-gen crd rbac:roleName=manager-role paths=./apis/... output:archive:file=dist/manifests.tar.gz
+
+	# Still write the bundle in CI even if some generators fail, summarizing the failures
+	controller-gen --keep-going object crd rbac:roleName=manager-role webhook paths=./... output:crd:bundle:file=dist/install.yaml
+
+	# Regenerate the deepcopy implementations of the packages changed since the main branch
+	git diff --name-only main -- '*.go' | xargs -n1 dirname | sort -u | sed 's|^|./|' | controller-gen object --paths-from=-
+
+	# Regenerate only the artifacts of the packages affected by the changes since the main branch
+	controller-gen --changed-only=main object crd paths=./apis/... output:crd:dir=./config/crd/bases
+
+	# Regenerate only the artifacts of the packages affected by the changes since the last run
+	controller-gen --changed-only --generation-manifest=.controller-gen.json object crd paths=./apis/...
+
+	# Refresh the CRDs of a development cluster
+	controller-gen --context=kind-dev crd paths=./apis/... output:crd:apply
+
+	# Preview an experimental behavior, or opt out of one enabled by default
+	controller-gen --feature-gates=ConcurrentGenerators=false object crd paths=./apis/...
+
+	# Report errors and warnings as JSON records, e.g. for CI annotations
+	controller-gen --diagnostics=json crd paths=./apis/...
+
+	# Summarize the errors of a large run by package and file at its end, as the first ones scroll away
+	controller-gen --error-report object crd rbac:roleName=manager-role webhook paths=./...
+
+	# Check for markers that none of the given generators use, are misplaced, or conflict
+	controller-gen --lint --diagnostics=json object crd rbac:roleName=manager-role paths=./apis/...
+
+	# Generate deepcopy implementations and applyconfigurations with the license header required of Go files
+	controller-gen --go-header-file=hack/boilerplate.go.txt --go-header-year=2026 --go-header-owner="The ACME Authors" object applyconfiguration paths=./apis/...
+
+	# Verify the generated files of a Windows checkout with CRLF line endings (e.g. with git's core.autocrlf)
+	controller-gen --verify --line-endings=crlf object crd paths=./apis/...
+
+	# Lay out the generated Go files with the templates of hack/templates, e.g. to meet internal style requirements
+	controller-gen --go-templates=hack/templates object paths=./apis/...
+
+	# Generate the same artifacts on all machines, whichever build of controller-gen they run, e.g. for hermetic builds
+	controller-gen --pin-version=v0.18.0 crd paths=./apis/... output:crd:dir=./config/crd/bases
+
+	# Record what was generated from which files, e.g. for remote caching by the build system
+	controller-gen --generation-manifest=out/manifest.json --depfile=out/crds.d crd paths=./apis/... output:dir=config/crd/bases
+
+	# Log the time spent loading packages and running each generator, and the files written, as JSON
+	controller-gen -vv --log-format=json crd rbac:roleName=manager-role paths=./apis/...
+
+	# Find out whether a slow run is spent loading packages, in a given generator, or writing files
+	controller-gen --timings object crd rbac:roleName=manager-role paths=./apis/...
+
+	# Check that the CRDs, RBAC, webhooks and apply configuration schemas generated together agree with each other
+	controller-gen --check-consistency crd rbac:roleName=manager-role webhook smdschema paths=./apis/... output:dir=config/generated
+
+	# Profile a slow run, to attach the profiles to a bug report
+	controller-gen --cpuprofile=cpu.pprof --memprofile=mem.pprof crd paths=./apis/...
+
+	# Run an external generator (see the plugin package) along with the CRD generator
+	controller-gen crd plugin:commands={"./bin/policy-gen --strict"} output:plugin:dir=./config/policy paths=./apis/...
 `,
 		RunE: func(c *cobra.Command, rawOpts []string) error {
 			// print version if asked for it
@@ -170,34 +218,203 @@ func main() {
 				return c.Usage()
 			}
 
+			// export the marker reference if we asked for it, then bail
+			if exportMarkers != "" {
+				return exportMarkerDocs(c.OutOrStdout(), exportMarkers)
+			}
+
+			if err := setUpLogging(c.ErrOrStderr(), verbosity, logFormat); err != nil {
+				return err
+			}
+
+			// the files are written relative to the working directory of the
+			// command, even with a configuration file in another one
+			if err := absPaths(&generationManifest, &depfile, &loadGraph, &loadCache, &profiles.CPU, &profiles.Memory, &profiles.Trace, &cluster.Kubeconfig); err != nil {
+				return err
+			}
+
+			var changes *genall.Changes
+			if c.Flags().Changed("changed-only") {
+				var err error
+				if changes, err = readChanges(changedOnly, generationManifest, watch); err != nil {
+					return err
+				}
+			}
+
+			if pathsFrom != "" {
+				paths, err := readPaths(pathsFrom, c.InOrStdin())
+				if err != nil {
+					return err
+				}
+				if len(paths) == 0 {
+					// e.g. no packages changed
+					slog.Debug("no paths to generate from", "from", pathsFrom)
+					return nil
+				}
+				rawOpts = append(rawOpts, "paths="+quotedSlice(paths))
+			}
+
+			rawOpts, config, err := withConfigOptions(configFile, rawOpts)
+			if err != nil {
+				return err
+			}
+			customMarkers := config.Markers
+
 			// print the marker docs if we asked for them, then bail
 			if whichLevel > 0 {
-				return printMarkerDocs(c, rawOpts, whichLevel)
+				return printMarkerDocs(c, rawOpts, customMarkers, whichLevel)
 			}
 
 			// otherwise, set up the runtime for actually running the generators
-			tagsFlag := fmt.Sprintf("-tags=%s", strings.Join(buildTags, ","))
-			rt, err := genall.FromOptionsWithConfig(&packages.Config{BuildFlags: []string{tagsFlag}}, optionsRegistry, rawOpts)
+			gates, err := genall.ParseFeatureGates(featureGates)
 			if err != nil {
 				return err
 			}
-			if len(rt.Generators) == 0 {
-				return fmt.Errorf("no generators specified")
+			diagnosticsFormat := genall.DiagnosticsFormat(diagnostics)
+			runOpts := []controllergen.Option{
+				controllergen.WithOptions(rawOpts...),
+				controllergen.WithBuildTags(buildTags...),
+				controllergen.WithPlatform(platform),
+				controllergen.WithLoadCache(loadCache),
+				controllergen.WithVerify(verify),
+				controllergen.WithDryRun(dryRun),
+				controllergen.WithKeepGoing(keepGoing),
+				controllergen.WithScopeErrors(scopeErrors),
+				controllergen.WithLint(lint),
+				controllergen.WithParallelism(parallelism),
+				controllergen.WithDiagnostics(diagnosticsFormat),
+				controllergen.WithErrorReport(errorReport),
+				controllergen.WithTimings(genall.TimingsFormat(timings)),
+				controllergen.WithConsistencyCheck(checkConsistency),
+				controllergen.WithGoHeader(goHeader),
+				controllergen.WithGoTemplates(goTemplates),
+				controllergen.WithFeatureGates(gates),
+				controllergen.WithGenerationManifest(generationManifest),
+				controllergen.WithDepfile(depfile),
+				controllergen.WithChanges(changes),
+				controllergen.WithLoadGraph(loadGraph),
+				controllergen.WithCluster(cluster),
+				controllergen.WithLineEndings(genall.LineEndings(lineEndings)),
+				controllergen.WithPinnedVersion(pinnedVersion),
+				controllergen.WithCustomMarkers(customMarkers...),
+				controllergen.WithVariables(config.Variables),
+				controllergen.WithVariables(vars),
+				controllergen.WithVariablesFromEnv(varsFromEnv),
+			}
+			newRuntime := func() (*genall.Runtime, error) {
+				return controllergen.NewRuntime(c.Context(), runOpts...)
+			}
+
+			// check the environment of the run if we asked for it, then bail
+			if doctor {
+				return runDoctor(c, runOpts)
+			}
+
+			stopProfiles, err := profiles.Start()
+			if err != nil {
+				return err
+			}
+			defer func() {
+				// the run itself went on, so it's just reported
+				if err := stopProfiles(); err != nil {
+					fmt.Fprintln(c.ErrOrStderr(), err)
+				}
+			}()
+
+			if dryRun && (verify || watch) {
+				return fmt.Errorf("--dry-run can't be used with --verify or --watch")
+			}
+			if lint && (dryRun || verify || watch) {
+				return fmt.Errorf("--lint can't be used with --dry-run, --verify or --watch")
+			}
+			if watch {
+				if verify {
+					return fmt.Errorf("--watch and --verify can't be used together")
+				}
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				return genall.Watch(ctx, newRuntime, watchDelay, c.OutOrStderr())
+			}
+
+			rt, err := newRuntime()
+			if err != nil {
+				if diagnosticsFormat == genall.DiagnosticsJSON {
+					c.SilenceErrors = true
+					if err := genall.WriteDiagnostics(c.ErrOrStderr(), loader.ErrorDiagnostic(err)); err != nil {
+						return err
+					}
+					return reportedError{err}
+				}
+				return err
 			}
 
 			if hadErrs := rt.Run(); hadErrs {
+				// the diagnostics are all there is to report
+				if diagnosticsFormat == genall.DiagnosticsJSON {
+					c.SilenceErrors = true
+					return reportedError{fmt.Errorf("not all generators ran successfully")}
+				}
 				// don't obscure the actual error with a bunch of usage
+				if lint {
+					return noUsageError{fmt.Errorf("found problems with the markers")}
+				}
+				if verify {
+					return noUsageError{fmt.Errorf("not all generators ran successfully, or generated files are out of date")}
+				}
 				return noUsageError{fmt.Errorf("not all generators ran successfully")}
 			}
 			return nil
 		},
-		SilenceUsage: true, // silence the usage, then print it out ourselves if it wasn't suppressed
+		// the options aren't subcommands
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeOptions,
+		SilenceUsage:      true, // silence the usage, then print it out ourselves if it wasn't suppressed
 	}
-	cmd.Flags().CountVarP(&whichLevel, "which-markers", "w", "print out all markers available with the requested generators\n(up to -www for the most detailed output, or -wwww for json output)")
-	cmd.Flags().CountVarP(&helpLevel, "detailed-help", "h", "print out more detailed help\n(up to -hhh for the most detailed output, or -hhhh for json output)")
-	cmd.Flags().BoolVar(&showVersion, "version", false, "show version")
-	cmd.Flags().StringSliceVar(&buildTags, "load-build-tags", []string{"ignore_autogenerated"}, "build tags to use when loading Go packages")
-	cmd.Flags().Bool("help", false, "print out usage and a summary of options")
+	cmd.AddCommand(newCompletionCommand())
+	cmd.AddCommand(newDoctorCommand(&doctor, cmd.RunE))
+	cmd.PersistentFlags().CountVarP(&whichLevel, "which-markers", "w", "print out all markers available with the requested generators\n(up to -www for the most detailed output, or -wwww for json output)")
+	cmd.PersistentFlags().CountVarP(&helpLevel, "detailed-help", "h", "print out more detailed help\n(up to -hhh for the most detailed output, or -hhhh for json output)")
+	cmd.PersistentFlags().StringVar(&exportMarkers, "export-markers", "", "print out the markers of all the generators, with their targets, arguments, help and deprecation,\nas json or yaml (e.g. for rendering a marker reference)")
+	cmd.PersistentFlags().BoolVar(&showVersion, "version", false, "show version")
+	cmd.PersistentFlags().StringSliceVar(&buildTags, "load-build-tags", controllergen.DefaultBuildTags, "build tags to use when loading Go packages")
+	cmd.PersistentFlags().StringVar(&platform, "load-platform", "", "target platform to load Go packages for, as GOOS/GOARCH (e.g. linux/amd64), so that the files\nselected by build constraints don't depend on the machine running controller-gen\n(defaults to the platform of the go command)")
+	cmd.PersistentFlags().StringVar(&loadCache, "load-cache", loadCache, "directory caching the types of the dependencies loaded from export data across runs\n(with the ExportDataDependencies feature gate), invalidated when their files change,\nor empty not to cache them")
+	cmd.PersistentFlags().StringVar(&pathsFrom, "paths-from", "", "read the paths to generate from (as per the paths option) from the given file, or standard-in if -,\none per line, skipping blank lines and lines starting with #")
+	cmd.PersistentFlags().StringVar(&changedOnly, "changed-only", "", "only regenerate the artifacts of the packages affected by the changes since the merge base\nwith the given git ref (e.g. main), or without a ref, since the run that wrote the\n--generation-manifest (per-package and per-group artifacts are regenerated for the\naffected packages only, the others in full as long as any package is affected)")
+	cmd.PersistentFlags().Lookup("changed-only").NoOptDefVal = changedSinceManifest
+	cmd.PersistentFlags().StringVar(&configFile, "config", "", "configuration file declaring the options to run with, which the options on the command line override\n(defaults to "+genall.ConfigFileName+" in the working directory, if any; relative paths are relative to the directory of the file)")
+	cmd.PersistentFlags().BoolVar(&verify, "verify", false, "check that the generated files are up to date, without writing them,\nprinting the stale ones and their diffs")
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "run the generators without writing anything, printing the plan of the run instead:\nthe packages, and the output rule and files to write of each generator")
+	cmd.PersistentFlags().BoolVar(&lint, "lint", false, "check the markers of the packages instead of running the generators, reporting as errors\nthe markers only used by generators that aren't enabled, the ones on the wrong kind of node\n(e.g. field markers on a type), and the conflicting ones (e.g. +optional and +required)")
+	cmd.PersistentFlags().StringToStringVar(&vars, "var", nil, "value of a variable interpolated as ${NAME} in the string values of markers, as NAME=VALUE\n(overriding the variables of the configuration file; $${ is a literal ${)")
+	cmd.PersistentFlags().BoolVar(&varsFromEnv, "var-from-env", false, "resolve the variables without values from the environment")
+	cmd.PersistentFlags().BoolVar(&watch, "watch", false, "run the generators again each time the Go files of the packages change,\nuntil interrupted")
+	cmd.PersistentFlags().StringVar(&diagnostics, "diagnostics", diagnostics, "format of the reported errors and warnings, text or json\n(json prints a record per line, with the file, line, column, marker,\nseverity, message and suggested fix of each)")
+	cmd.PersistentFlags().StringVar(&timings, "timings", "", "report the wall time and allocations of the phases of the run at its end, text or json\n(loading packages, collecting markers, each generator, and each output rule)")
+	cmd.PersistentFlags().Lookup("timings").NoOptDefVal = string(genall.TimingsText)
+	cmd.PersistentFlags().BoolVar(&checkConsistency, "check-consistency", false, "cross-check the manifests of the run once all the generators ran, failing when they drifted:\nconversion webhooks not served along with the admission webhooks, roles that can write a kind\nbut not its status or finalizers, and smdschema schemas not matching the CRDs")
+	cmd.PersistentFlags().BoolVar(&errorReport, "error-report", false, "report the errors again at the end of the run, grouped by package, then by file,\nso that the first ones aren't lost among the ones following from them (with text diagnostics)")
+	cmd.PersistentFlags().BoolVarP(&keepGoing, "keep-going", "k", false, "still write the bundles, archives and standard-out once a generator failed,\nand summarize the failed generators and packages at the end\n(by default, they're discarded after a failure)")
+	cmd.PersistentFlags().BoolVar(&scopeErrors, "scope-errors", false, "only fail on the errors of the packages the generators needed (the roots, and the\ndependencies they parsed or loaded the types of), reporting the errors of the other\ndependencies as warnings (by default, the errors of all loaded packages fail the run)")
+	cmd.PersistentFlags().IntVarP(&parallelism, "parallelism", "j", parallelism, "maximum number of generators to run concurrently\n(defaults to the number of CPUs)")
+	cmd.PersistentFlags().StringSliceVar(&featureGates, "feature-gates", nil, "experimental behaviors to enable or disable, as Feature=true|false pairs:\n"+genall.FeatureGatesHelp())
+	cmd.PersistentFlags().StringVar(&goHeader.File, "go-header-file", "", "header (e.g. license) of the generated Go files, for the generators whose headerFile isn't set\n(\" YEAR\" or {{.Year}}, and {{.Owner}} are substituted in it)")
+	cmd.PersistentFlags().StringVar(&goHeader.Year, "go-header-year", "", "year substituted in the Go header, unless the generator sets its own")
+	cmd.PersistentFlags().StringVar(&goHeader.Owner, "go-header-owner", "", "owner substituted in the Go header")
+	cmd.PersistentFlags().StringVar(&goTemplates, "go-templates", "", "directory of text/templates laying out the generated Go files (header, package doc, imports\nand declarations): <file>.tmpl for a file, e.g. zz_generated.deepcopy.go.tmpl, or default.tmpl")
+	cmd.PersistentFlags().StringVar(&lineEndings, "line-endings", lineEndings, "line endings of the generated files, whatever the platform, lf or crlf\n(e.g. crlf to verify a checkout converting them, as with git's core.autocrlf)")
+	cmd.PersistentFlags().StringVar(&pinnedVersion, "pin-version", "", "version of controller-gen recorded in the generated artifacts (e.g. in the\ncontroller-gen.kubebuilder.io/version annotation of CRDs), instead of the version of the binary,\nso that they're identical whichever build of it generated them")
+	cmd.PersistentFlags().StringVar(&generationManifest, "generation-manifest", "", "write a JSON manifest of the generated files (with their SHA-256 digests)\nand of the input files to the given path")
+	cmd.PersistentFlags().StringVar(&depfile, "depfile", "", "write a Make-style depfile to the given path, with a rule per generated file\ndepending on the input files, e.g. for Make, Ninja or Bazel")
+	cmd.PersistentFlags().StringVar(&cluster.Kubeconfig, "kubeconfig", "", "kubeconfig file of the cluster the apply output rule applies manifests to,\nand that audits read from\n(defaults to $KUBECONFIG or ~/.kube/config)")
+	cmd.PersistentFlags().StringVar(&cluster.Context, "context", "", "context of the kubeconfig file the apply output rule and audits use\n(defaults to the current context)")
+	cmd.PersistentFlags().StringVar(&profiles.CPU, "cpuprofile", "", "write a CPU profile of the run to the given path, in pprof format")
+	cmd.PersistentFlags().StringVar(&profiles.Memory, "memprofile", "", "write a memory profile to the given path once the run is over, in pprof format")
+	cmd.PersistentFlags().StringVar(&profiles.Trace, "trace", "", "write an execution trace of the run to the given path, for go tool trace")
+	cmd.PersistentFlags().StringVar(&loadGraph, "load-graph", "", "write the packages loaded during the run to the given path as JSON, with the root\nor import that pulled each in, how much of it was loaded and for which type, and its errors")
+	cmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "log what's being done to standard-error\n(-v for the loaded packages and the time each generator took,\n-vv for the type-checked packages and each file written as well)")
+	cmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormat, "format of the logs, text or json")
+	cmd.PersistentFlags().Bool("help", false, "print out usage and a summary of options")
 	oldUsage := cmd.UsageFunc()
 	cmd.SetUsageFunc(func(c *cobra.Command) error {
 		if err := oldUsage(c); err != nil {
@@ -210,7 +427,18 @@ func main() {
 		return helpForLevels(c.OutOrStdout(), c.OutOrStderr(), helpLevel, optionsRegistry, help.SortByOption)
 	})
 
+	// each generator can be run as a subcommand with conventional flags too
+	cmd.AddGroup(&cobra.Group{ID: generatorsGroup, Title: "Generators (run as subcommands with flags, or as options):"})
+	for _, defn := range optionsRegistry.AllDefinitions() {
+		if _, isGenerator := allGenerators[defn.Name]; isGenerator {
+			cmd.AddCommand(newGeneratorCommand(defn, cmd.PersistentFlags(), cmd.RunE, oldUsage))
+		}
+	}
+
 	if err := cmd.Execute(); err != nil {
+		if errors.As(err, new(reportedError)) {
+			os.Exit(1)
+		}
 		var errNoUsage noUsageError
 		if !errors.As(err, &errNoUsage) {
 			// print the usage unless we suppressed it
@@ -223,19 +451,152 @@ func main() {
 	}
 }
 
+// withConfigOptions returns the options of the given configuration file (or
+// of the one in the working directory, if none is given and it exists),
+// overridden by the given options, along with the configuration itself (empty
+// without configuration file), for the custom markers and variables it
+// declares.
+// It changes into the directory of the file, so that the relative paths in it
+// are relative to the file.
+func withConfigOptions(configFile string, rawOpts []string) ([]string, *genall.Config, error) {
+	if configFile == "" {
+		if _, err := os.Stat(genall.ConfigFileName); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return rawOpts, &genall.Config{}, nil
+			}
+			return nil, nil, err
+		}
+		configFile = genall.ConfigFileName
+	}
+
+	config, err := genall.LoadConfig(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	configOpts, err := config.Options()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration file %s: %w", configFile, err)
+	}
+	if dir := filepath.Dir(configFile); dir != "." {
+		if err := os.Chdir(dir); err != nil {
+			return nil, nil, err
+		}
+	}
+	options, err := genall.OverrideOptions(optionsRegistry, configOpts, rawOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return options, config, nil
+}
+
+// readPaths reads the paths in the given file (or in the given standard-in,
+// for -), one per line, skipping blank lines and comments.
+func readPaths(file string, stdin io.Reader) ([]string, error) {
+	in := stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the paths from %s: %w", file, err)
+	}
+	return paths, nil
+}
+
+// changedSinceManifest is the value of --changed-only without a ref, for the
+// changes since the run that wrote the generation manifest.
+const changedSinceManifest = "manifest"
+
+// readChanges returns the changes since the merge base with the given git
+// ref, or since the run that wrote the given generation manifest without one.
+func readChanges(ref, generationManifest string, watch bool) (*genall.Changes, error) {
+	if watch {
+		return nil, fmt.Errorf("--changed-only and --watch can't be used together")
+	}
+	if ref != changedSinceManifest {
+		return genall.ChangesSinceRef(ref)
+	}
+	if generationManifest == "" {
+		return nil, fmt.Errorf("--changed-only needs a git ref, or a --generation-manifest to compare with")
+	}
+	return genall.ChangesSinceManifest(generationManifest)
+}
+
+// absPaths makes the given paths absolute, leaving empty ones empty.
+func absPaths(paths ...*string) error {
+	for _, path := range paths {
+		if *path == "" {
+			continue
+		}
+		absPath, err := filepath.Abs(*path)
+		if err != nil {
+			return err
+		}
+		*path = absPath
+	}
+	return nil
+}
+
 // printMarkerDocs prints out marker help for the given generators specified in
 // the rawOptions, at the given level.
-func printMarkerDocs(c *cobra.Command, rawOptions []string, whichLevel int) error {
+func printMarkerDocs(c *cobra.Command, rawOptions []string, customMarkers []genall.CustomMarker, whichLevel int) error {
 	// just grab a registry so we don't lag while trying to load roots
 	// (like we'd do if we just constructed the full runtime).
 	reg, err := genall.RegistryFromOptions(optionsRegistry, rawOptions)
 	if err != nil {
 		return err
 	}
+	if err := genall.RegisterCustomMarkers(reg, customMarkers); err != nil {
+		return err
+	}
 
 	return helpForLevels(c.OutOrStdout(), c.OutOrStderr(), whichLevel, reg, help.SortByCategory)
 }
 
+// exportMarkerDocs prints out the marker help for all known generators, in the
+// given format.
+func exportMarkerDocs(out io.Writer, format string) error {
+	regsByGen, err := allMarkerRegistries()
+	if err != nil {
+		return err
+	}
+	helpInfo, err := help.ForGenerators(regsByGen)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(helpInfo)
+	case "yaml":
+		contents, err := yaml.Marshal(helpInfo)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(contents)
+		return err
+	default:
+		return fmt.Errorf("unknown marker export format %q, must be json or yaml", format)
+	}
+}
+
 func helpForLevels(mainOut io.Writer, errOut io.Writer, whichLevel int, reg *markers.Registry, sorter help.SortGroup) error {
 	helpInfo := help.ByCategory(reg, sorter)
 	switch whichLevel {
@@ -268,6 +629,51 @@ func helpForLevels(mainOut io.Writer, errOut io.Writer, whichLevel int, reg *mar
 	return nil
 }
 
+// setUpLogging sets up the default logger to log to the given writer in the
+// given format, at a level depending on the verbosity: only warnings and errors
+// by default, the time spent loading and generating from -v, and each
+// type-checked package and written file as well from -vv.
+func setUpLogging(w io.Writer, verbosity int, format string) error {
+	opts := &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.LevelKey && attr.Value.Any() == loader.LevelTrace {
+				attr.Value = slog.StringValue("TRACE")
+			}
+			return attr
+		},
+	}
+	switch {
+	case verbosity >= 2:
+		opts.Level = loader.LevelTrace
+	case verbosity == 1:
+		opts.Level = slog.LevelDebug
+	}
+
+	var handler slog.Handler
+	switch format {
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q, must be text or json", format)
+	}
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	// the generators built on gengo log through klog, whose own format would
+	// break the JSON records otherwise
+	if format == "json" {
+		klog.SetSlogLogger(logger)
+	}
+	return nil
+}
+
+// watchDelay is how long to wait for more changes before running the
+// generators again in watch mode.
+const watchDelay = 300 * time.Millisecond
+
 const (
 	_ = iota
 	summaryHelp
@@ -0,0 +1,56 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main_test
+
+import (
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+func TestControllerGen(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "controller-gen Command Suite")
+}
+
+// controllerGenPath is the path of the controller-gen binary under test.
+var controllerGenPath string
+
+var _ = BeforeSuite(func() {
+	var err error
+	controllerGenPath, err = gexec.Build("sigs.k8s.io/controller-tools/cmd/controller-gen")
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(gexec.CleanupBuildArtifacts)
+})
+
+// controllerGen runs controller-gen with the given arguments in the given
+// directory, and standard-in, if any, waiting for it to exit.
+func controllerGen(dir string, stdin io.Reader, args ...string) *gexec.Session {
+	GinkgoHelper()
+	cmd := exec.Command(controllerGenPath, args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+	Expect(err).NotTo(HaveOccurred())
+	Eventually(session, 2*time.Minute).Should(gexec.Exit())
+	return session
+}
@@ -0,0 +1,78 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Reading the paths from a file", func() {
+	const paths = "# the changed packages\n\n  ./api/v1alpha1  \n# ./api/v1\n"
+
+	var testdataDir, outDir string
+
+	BeforeEach(func() {
+		var err error
+		testdataDir, err = filepath.Abs(filepath.Join("..", "..", "pkg", "docs", "testdata"))
+		Expect(err).NotTo(HaveOccurred())
+		outDir = GinkgoT().TempDir()
+	})
+
+	// generated returns the names of the files generated so far.
+	generated := func() []string {
+		entries, err := os.ReadDir(outDir)
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	It("should generate from the paths in the file, skipping blank lines and comments", func() {
+		pathsFile := filepath.Join(GinkgoT().TempDir(), "paths.txt")
+		Expect(os.WriteFile(pathsFile, []byte(paths), 0o644)).To(Succeed())
+		session := controllerGen(testdataDir, nil, "crd", "--paths-from="+pathsFile, "--output-dir="+outDir)
+		Expect(session).To(gexec.Exit(0))
+		Expect(generated()).To(Equal([]string{"testdata.kubebuilder.io_gizmoes.yaml"}))
+	})
+
+	It("should generate from the paths in standard-in, for -", func() {
+		session := controllerGen(testdataDir, strings.NewReader(paths), "crd", "--paths-from=-", "--output-dir="+outDir)
+		Expect(session).To(gexec.Exit(0))
+		Expect(generated()).To(Equal([]string{"testdata.kubebuilder.io_gizmoes.yaml"}))
+	})
+
+	It("should generate nothing, successfully, without any paths", func() {
+		session := controllerGen(testdataDir, strings.NewReader("# nothing changed\n\n"), "crd", "--paths-from=-", "--output-dir="+outDir)
+		Expect(session).To(gexec.Exit(0))
+		Expect(generated()).To(BeEmpty())
+	})
+
+	It("should fail to read the paths from missing files", func() {
+		session := controllerGen(testdataDir, nil, "crd", "--paths-from="+filepath.Join(outDir, "missing.txt"), "--output-dir="+outDir)
+		Expect(session).To(gexec.Exit(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring("missing.txt: no such file or directory"))
+		Expect(generated()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Profiling", func() {
+	var testdataDir string
+
+	BeforeEach(func() {
+		var err error
+		testdataDir, err = filepath.Abs(filepath.Join("..", "..", "pkg", "docs", "testdata"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should write the CPU and memory profiles, and the execution trace, of the run", func() {
+		profileDir, outDir := GinkgoT().TempDir(), GinkgoT().TempDir()
+		session := controllerGen(testdataDir, nil,
+			"--cpuprofile="+filepath.Join(profileDir, "cpu.pprof"),
+			"--memprofile="+filepath.Join(profileDir, "mem.pprof"),
+			"--trace="+filepath.Join(profileDir, "run.trace"),
+			"object", "crd", "paths=./api/...", "output:dir="+outDir,
+		)
+		Expect(session).To(gexec.Exit(0))
+		Expect(filepath.Join(outDir, "zz_generated.deepcopy.go")).To(BeARegularFile())
+
+		// pprof profiles are gzipped protocol buffers
+		for _, name := range []string{"cpu.pprof", "mem.pprof"} {
+			contents, err := os.ReadFile(filepath.Join(profileDir, name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(HavePrefix("\x1f\x8b"), name)
+		}
+		trace, err := os.ReadFile(filepath.Join(profileDir, "run.trace"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(trace)).To(HavePrefix("go 1."))
+	})
+
+	It("should write the profiles of failed runs too", func() {
+		profileDir := GinkgoT().TempDir()
+		session := controllerGen(testdataDir, nil,
+			"--memprofile="+filepath.Join(profileDir, "mem.pprof"),
+			"crd", "paths=./api/...", "output:crd:unknown",
+		)
+		Expect(session).To(gexec.Exit(1))
+		Expect(filepath.Join(profileDir, "mem.pprof")).To(BeARegularFile())
+	})
+
+	It("should write the profiles relative to the working directory", func() {
+		workDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(workDir, "controller-gen.yaml"), []byte("paths: [./api/...]\ngenerators:\n  crd: {}\noutput:\n  default: none\n"), 0o644)).To(Succeed())
+		Expect(os.Symlink(filepath.Join(testdataDir, "api"), filepath.Join(workDir, "api"))).To(Succeed())
+		Expect(os.Symlink(filepath.Join(testdataDir, "go.mod"), filepath.Join(workDir, "go.mod"))).To(Succeed())
+		Expect(os.Symlink(filepath.Join(testdataDir, "go.sum"), filepath.Join(workDir, "go.sum"))).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(workDir, "profiles"), 0o755)).To(Succeed())
+
+		callerDir := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(callerDir, "profiles"), 0o755)).To(Succeed())
+		session := controllerGen(callerDir, nil, "--config="+filepath.Join(workDir, "controller-gen.yaml"), "--cpuprofile=profiles/cpu.pprof")
+		Expect(session).To(gexec.Exit(0))
+		Expect(filepath.Join(callerDir, "profiles", "cpu.pprof")).To(BeARegularFile())
+		Expect(filepath.Join(workDir, "profiles", "cpu.pprof")).NotTo(BeAnExistingFile())
+	})
+
+	It("should fail when the profiles can't be created", func() {
+		session := controllerGen(testdataDir, nil,
+			"--cpuprofile="+filepath.Join(GinkgoT().TempDir(), "missing", "cpu.pprof"),
+			"crd", "paths=./api/...", "output:none",
+		)
+		Expect(session).To(gexec.Exit(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring("unable to create the CPU profile"))
+
+		session = controllerGen(testdataDir, nil,
+			"--trace="+filepath.Join(GinkgoT().TempDir(), "missing", "run.trace"),
+			"crd", "paths=./api/...", "output:none",
+		)
+		Expect(session).To(gexec.Exit(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring("unable to create the execution trace"))
+	})
+})
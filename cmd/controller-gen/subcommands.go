@@ -0,0 +1,178 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// generatorsGroup is the group of the generator subcommands in the usage.
+const generatorsGroup = "generators"
+
+// newGeneratorCommand returns the subcommand running the generator with the
+// given options marker, with a flag per argument of the generator, besides
+// the options in the marker-style syntax (so that `controller-gen crd
+// paths=./...` keeps working).  The flags are turned into options, which are
+// run by the given function.
+func newGeneratorCommand(defn *markers.Definition, commonFlags *pflag.FlagSet, run func(*cobra.Command, []string) error, usage func(*cobra.Command) error) *cobra.Command {
+	genName := defn.Name
+	var short string
+	var fieldsHelp map[string]markers.DetailedHelp
+	if help := optionsRegistry.HelpFor(defn); help != nil {
+		short = strings.TrimSuffix(help.Summary, ".")
+		if short != "" {
+			short = strings.ToUpper(short[:1]) + short[1:]
+		}
+		fieldsHelp = help.FieldsHelp(defn)
+	}
+
+	argsByFlag := make(map[string]flagArgument)
+	c := &cobra.Command{
+		Use:     genName + " [flags] [options]",
+		Short:   short,
+		GroupID: generatorsGroup,
+		Example: fmt.Sprintf("	controller-gen %s --paths=./apis/... --output-dir=./out", genName),
+		RunE: func(c *cobra.Command, rawOpts []string) error {
+			genOpt, err := flagsOption(c.Flags(), genName, argsByFlag)
+			if err != nil {
+				return err
+			}
+			opts := []string{genOpt}
+			if c.Flags().Changed("only") {
+				only, _ := c.Flags().GetStringSlice("only")
+				opts = append(opts, fmt.Sprintf("%s:only=%s", genName, quotedSlice(only)))
+			}
+			if c.Flags().Changed("paths") {
+				paths, _ := c.Flags().GetStringSlice("paths")
+				opts = append(opts, "paths="+quotedSlice(paths))
+			}
+			if c.Flags().Changed("output-dir") {
+				dir, _ := c.Flags().GetString("output-dir")
+				opts = append(opts, fmt.Sprintf("output:%s:dir=%s", genName, strconv.Quote(dir)))
+			}
+			return run(c, append(opts, rawOpts...))
+		},
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeOptions,
+		SilenceUsage:      true,
+	}
+	c.SetUsageFunc(usage)
+
+	for _, argName := range argumentNames(defn) {
+		flagName := kebabCase(argName)
+		if commonFlags.Lookup(flagName) != nil {
+			// don't shadow the common flags; the argument can still be set
+			// in the marker-style syntax
+			continue
+		}
+		arg := defn.Fields[argName]
+		argsByFlag[flagName] = flagArgument{name: argName, quoted: arg.Type == markers.StringType || arg.Type == markers.CELType}
+		flagUsage := fieldsHelp[argName].Summary
+		switch {
+		case arg.Type == markers.StringType, arg.Type == markers.CELType:
+			c.Flags().String(flagName, "", flagUsage)
+		case arg.Type == markers.BoolType:
+			c.Flags().Bool(flagName, false, flagUsage)
+		case arg.Type == markers.IntType:
+			c.Flags().Int(flagName, 0, flagUsage)
+		case arg.Type == markers.SliceType && arg.ItemType.Type == markers.StringType:
+			c.Flags().StringSlice(flagName, nil, flagUsage)
+		default:
+			// anything else is passed as is, in the marker-style syntax
+			c.Flags().String(flagName, "", flagUsage+" (as "+arg.TypeString()+")")
+		}
+	}
+	c.Flags().StringSlice("only", nil, "only process the kinds matching these group[/version[/Kind]] patterns")
+	c.Flags().StringSlice("paths", nil, "paths to the Go packages to generate from")
+	c.Flags().String("output-dir", "", "directory to write the generated files to")
+	return c
+}
+
+// flagArgument is the argument of a generator set by a flag.
+type flagArgument struct {
+	// name is the name of the argument.
+	name string
+	// quoted is true if the value of the flag is quoted as a string, rather
+	// than given as is.
+	quoted bool
+}
+
+// flagsOption returns the generator options marker with the arguments given
+// as flags.
+func flagsOption(flags *pflag.FlagSet, genName string, argsByFlag map[string]flagArgument) (string, error) {
+	var args []string
+	for _, flagName := range slices.Sorted(maps.Keys(argsByFlag)) {
+		if !flags.Changed(flagName) {
+			continue
+		}
+		arg := argsByFlag[flagName]
+		value := flags.Lookup(flagName).Value.String()
+		switch {
+		case arg.quoted:
+			value = strconv.Quote(value)
+		case flags.Lookup(flagName).Value.Type() == "stringSlice":
+			items, err := flags.GetStringSlice(flagName)
+			if err != nil {
+				return "", err
+			}
+			value = quotedSlice(items)
+		}
+		args = append(args, arg.name+"="+value)
+	}
+	if len(args) == 0 {
+		return genName, nil
+	}
+	return genName + ":" + strings.Join(args, ","), nil
+}
+
+// quotedSlice returns the given strings as a slice in the marker-style
+// syntax.
+func quotedSlice(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+// kebabCase turns the given camel-case argument name into a flag name (e.g.
+// maxDescLen into max-desc-len).
+func kebabCase(name string) string {
+	runes := []rune(name)
+	out := &strings.Builder{}
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				out.WriteRune('-')
+			}
+		}
+		out.WriteRune(unicode.ToLower(r))
+	}
+	return out.String()
+}
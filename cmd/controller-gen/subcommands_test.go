@@ -0,0 +1,111 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Generator subcommands", func() {
+	var testdataDir string
+
+	BeforeEach(func() {
+		var err error
+		testdataDir, err = filepath.Abs(filepath.Join("..", "..", "pkg", "docs", "testdata"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// generated returns the files generated in the given directory, by name.
+	generated := func(dir string) map[string]string {
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		files := make(map[string]string, len(entries))
+		for _, entry := range entries {
+			contents, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			Expect(err).NotTo(HaveOccurred())
+			files[entry.Name()] = string(contents)
+		}
+		return files
+	}
+
+	It("should run the generator with the arguments given as flags, as with the options", func() {
+		flagsDir, optionsDir := GinkgoT().TempDir(), GinkgoT().TempDir()
+		session := controllerGen(testdataDir, nil, "crd", "--max-desc-len=0", "--crd-versions=v1", "--paths=./api/...", "--output-dir="+flagsDir)
+		Expect(session).To(gexec.Exit(0))
+		session = controllerGen(testdataDir, nil, "crd:maxDescLen=0,crdVersions=v1", "paths=./api/...", "output:crd:dir="+optionsDir)
+		Expect(session).To(gexec.Exit(0))
+
+		files := generated(flagsDir)
+		Expect(files).To(HaveKey("testdata.kubebuilder.io_widgets.yaml"))
+		Expect(files["testdata.kubebuilder.io_widgets.yaml"]).NotTo(ContainSubstring("description:"))
+		Expect(files).To(Equal(generated(optionsDir)))
+	})
+
+	It("should quote the string arguments given as flags", func() {
+		headerFile := filepath.Join(GinkgoT().TempDir(), "header.txt")
+		Expect(os.WriteFile(headerFile, []byte("// Copyright YEAR The ACME Authors.\n"), 0o644)).To(Succeed())
+		outDir := GinkgoT().TempDir()
+		session := controllerGen(testdataDir, nil, "object", "--header-file="+headerFile, "--year=2030", "--paths=./api/v1", "--output-dir="+outDir)
+		Expect(session).To(gexec.Exit(0))
+		Expect(generated(outDir)["zz_generated.deepcopy.go"]).To(HavePrefix("//go:build !ignore_autogenerated\n\n// Copyright 2030 The ACME Authors.\n"))
+	})
+
+	It("should only process the kinds given as flags", func() {
+		outDir := GinkgoT().TempDir()
+		session := controllerGen(testdataDir, nil, "crd", "--only=testdata.kubebuilder.io/v1/Widget", "--paths=./api/...", "--output-dir="+outDir)
+		Expect(session).To(gexec.Exit(0))
+		Expect(generated(outDir)).To(HaveLen(1))
+		Expect(generated(outDir)).To(HaveKey("testdata.kubebuilder.io_widgets.yaml"))
+	})
+
+	It("should keep accepting the options in the marker-style syntax, along with the flags", func() {
+		outDir := GinkgoT().TempDir()
+		session := controllerGen(testdataDir, nil, "crd", "--max-desc-len=0", "paths=./api/...", "output:crd:dir="+outDir)
+		Expect(session).To(gexec.Exit(0))
+		Expect(generated(outDir)).To(HaveLen(2))
+		Expect(generated(outDir)["testdata.kubebuilder.io_widgets.yaml"]).NotTo(ContainSubstring("description:"))
+	})
+
+	It("should list the generators and their flags in the usage", func() {
+		session := controllerGen(testdataDir, nil, "--help")
+		Expect(string(session.Out.Contents())).To(And(
+			ContainSubstring("Generators (run as subcommands with flags, or as options):"),
+			MatchRegexp(`\n  crd +Generates CustomResourceDefinition objects\n`),
+		))
+
+		session = controllerGen(testdataDir, nil, "crd", "--help")
+		Expect(session).To(gexec.Exit(0))
+		Expect(string(session.Out.Contents())).To(And(
+			ContainSubstring("controller-gen crd [flags] [options]"),
+			MatchRegexp(`--max-desc-len int +specifies the maximum description length`),
+			MatchRegexp(`--crd-versions strings`),
+			MatchRegexp(`--allow-dangerous-types +allows types`),
+			MatchRegexp(`--output-dir string`),
+		))
+	})
+
+	It("should refuse unknown flags", func() {
+		session := controllerGen(testdataDir, nil, "crd", "--bogus", "--paths=./api/...")
+		Expect(session).To(gexec.Exit(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring("unknown flag: --bogus"))
+	})
+})
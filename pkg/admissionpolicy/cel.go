@@ -0,0 +1,343 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// celIdentifier matches property names that can be used as CEL identifiers.
+var celIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// celReservedWords can't be used as CEL identifiers.
+var celReservedWords = []string{
+	"as", "break", "const", "continue", "else", "false", "for", "function", "if", "import",
+	"in", "let", "loop", "package", "namespace", "null", "return", "true", "var", "void", "while",
+}
+
+// scope describes where a schema is in the object being validated, in terms
+// of CEL expressions of a ValidatingAdmissionPolicy.
+type scope struct {
+	// self accesses the value described by the schema.
+	self string
+	// oldSelf accesses the previous value described by the schema, or is empty
+	// if the previous value can't be correlated with the current one.
+	oldSelf string
+	// guards check that self is present, within the current iteration scope.
+	guards []string
+	// oldGuards check that oldSelf is present.
+	oldGuards []string
+	// wrap turns an expression for the current iteration scope into an
+	// expression for the whole object.
+	wrap func(expr string) string
+	// depth is the number of nested iteration scopes.
+	depth int
+}
+
+// rootScope is the scope of the schema of a whole object.
+var rootScope = scope{
+	self:    "object",
+	oldSelf: "oldObject",
+	wrap:    func(expr string) string { return expr },
+}
+
+// property returns the scope of the given property of the value in this scope.
+func (s scope) property(name string) scope {
+	child := s
+	child.self, child.guards = access(s.self, name, s.guards)
+	if s.oldSelf != "" {
+		child.oldSelf, child.oldGuards = access(s.oldSelf, name, s.oldGuards)
+	}
+	return child
+}
+
+// access returns an expression accessing the given property of the given
+// value, and the guards checking that it's present.
+func access(value, name string, guards []string) (string, []string) {
+	guards = slices.Clone(guards)
+	if celIdentifier.MatchString(name) && !slices.Contains(celReservedWords, name) {
+		return value + "." + name, append(guards, fmt.Sprintf("has(%s.%s)", value, name))
+	}
+	quoted := fmt.Sprintf("%q", name)
+	return fmt.Sprintf("%s[%s]", value, quoted), append(guards, fmt.Sprintf("%s in %s", quoted, value))
+}
+
+// items returns the scope of the items of the list or map in this scope.
+// Previous values of items aren't correlated with current ones.
+func (s scope) items(isMap bool) scope {
+	variable := fmt.Sprintf("item%d", s.depth)
+	self := variable
+	if isMap {
+		variable = fmt.Sprintf("key%d", s.depth)
+		self = fmt.Sprintf("%s[%s]", s.self, variable)
+	}
+	return scope{
+		self: self,
+		wrap: func(expr string) string {
+			return s.wrap(guarded(s.guards, fmt.Sprintf("%s.all(%s, %s)", s.self, variable, expr)))
+		},
+		depth: s.depth + 1,
+	}
+}
+
+// guarded returns an expression that is true if any of the given presence
+// guards are false, or else if the given expression is true.
+func guarded(guards []string, expr string) string {
+	return unless(negateAll(guards), expr)
+}
+
+// unless returns an expression that is true if any of the given conditions
+// are true, or else if the given expression is true.
+func unless(conds []string, expr string) string {
+	if len(conds) == 0 {
+		return expr
+	}
+	return strings.Join(append(slices.Clone(conds), parenthesize(expr)), " || ")
+}
+
+// negateAll negates each of the given presence guards.
+func negateAll(guards []string) []string {
+	conds := make([]string, 0, len(guards))
+	for _, guard := range guards {
+		conds = append(conds, "!"+parenthesize(guard))
+	}
+	return conds
+}
+
+// parenthesize wraps the given expression in parentheses, unless it's a
+// function call.
+func parenthesize(expr string) string {
+	if strings.HasPrefix(expr, "has(") && strings.HasSuffix(expr, ")") && strings.Count(expr, "(") == 1 {
+		return expr
+	}
+	return "(" + expr + ")"
+}
+
+// Validations converts the x-kubernetes-validations of the given schema and
+// its subschemata into the validations of a ValidatingAdmissionPolicy.
+//
+// Rules are rewritten to refer to object (and oldObject) instead of self (and
+// oldSelf), and guarded so that they only apply when the value they're on is
+// present.  Rules for items of lists and maps are checked for all items.
+// Transition rules (i.e. rules referring to oldSelf) are only checked on
+// updates, and are skipped for items of lists and maps, since the previous
+// values of items can't be correlated with their current values.  Rules with
+// optionalOldSelf are skipped too.
+//
+// The subschemata of the given properties of the root schema are skipped (this
+// is usually used to skip the status of objects).
+func Validations(schema *apiextensionsv1.JSONSchemaProps, skipProperties ...string) []admissionregistrationv1.Validation {
+	var validations []admissionregistrationv1.Validation
+	walk(schema, rootScope, func(s scope, schema *apiextensionsv1.JSONSchemaProps) {
+		for _, rule := range schema.XValidations {
+			if validation, ok := convertRule(s, rule); ok {
+				validations = append(validations, validation)
+			}
+		}
+	}, skipProperties)
+	return validations
+}
+
+// walk calls visit for the given schema and each of its subschemata, in a
+// stable order.
+func walk(schema *apiextensionsv1.JSONSchemaProps, s scope, visit func(scope, *apiextensionsv1.JSONSchemaProps), skipProperties []string) {
+	if schema == nil {
+		return
+	}
+	visit(s, schema)
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		if !slices.Contains(skipProperties, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop := schema.Properties[name]
+		walk(&prop, s.property(name), visit, nil)
+	}
+
+	if schema.Items != nil && schema.Items.Schema != nil {
+		walk(schema.Items.Schema, s.items(false), visit, nil)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		walk(schema.AdditionalProperties.Schema, s.items(true), visit, nil)
+	}
+}
+
+// convertRule converts the given validation rule in the given scope.
+func convertRule(s scope, rule apiextensionsv1.ValidationRule) (admissionregistrationv1.Validation, bool) {
+	isTransition := referencesIdentifier(rule.Rule, "oldSelf") || referencesIdentifier(rule.MessageExpression, "oldSelf")
+	if isTransition && (s.oldSelf == "" || (rule.OptionalOldSelf != nil && *rule.OptionalOldSelf)) {
+		return admissionregistrationv1.Validation{}, false
+	}
+
+	conds := negateAll(s.guards)
+	if isTransition {
+		conds = append(conds, "request.operation != 'UPDATE'")
+		conds = append(conds, negateAll(s.oldGuards)...)
+	}
+	validation := admissionregistrationv1.Validation{
+		Expression: s.wrap(unless(conds, replaceSelf(rule.Rule, s.self, s.oldSelf))),
+		Message:    rule.Message,
+	}
+	if rule.MessageExpression != "" {
+		validation.MessageExpression = replaceSelf(rule.MessageExpression, s.self, s.oldSelf)
+	}
+	return validation, true
+}
+
+// referencesIdentifier checks if the given CEL expression references the given
+// identifier.
+func referencesIdentifier(expr, ident string) bool {
+	found := false
+	scanIdentifiers(expr, func(name string) string {
+		if name == ident {
+			found = true
+		}
+		return name
+	})
+	return found
+}
+
+// replaceSelf replaces references to self and oldSelf in the given CEL
+// expression with the given expressions.
+func replaceSelf(expr, self, oldSelf string) string {
+	return scanIdentifiers(expr, func(name string) string {
+		switch name {
+		case "self":
+			return self
+		case "oldSelf":
+			return oldSelf
+		default:
+			return name
+		}
+	})
+}
+
+// scanIdentifiers calls replace for each (unqualified) identifier in the given
+// CEL expression, replacing it with the result.  String and bytes literals, and
+// field selections (like the name in "x.name") are left untouched.
+func scanIdentifiers(expr string, replace func(name string) string) string {
+	var out strings.Builder
+	prevSignificant := byte(0)
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '"' || c == '\'' || (isStringPrefix(c) && i+1 < len(expr) && (expr[i+1] == '"' || expr[i+1] == '\'' || isStringPrefix(expr[i+1]))):
+			end := stringLiteralEnd(expr, i)
+			if end < 0 {
+				// not actually a string literal, but an identifier
+				end = identifierEnd(expr, i)
+				name := expr[i:end]
+				if prevSignificant != '.' {
+					name = replace(name)
+				}
+				out.WriteString(name)
+			} else {
+				out.WriteString(expr[i:end])
+			}
+			prevSignificant = 'x'
+			i = end
+		case isIdentifierStart(c):
+			end := identifierEnd(expr, i)
+			name := expr[i:end]
+			if prevSignificant != '.' {
+				name = replace(name)
+			}
+			out.WriteString(name)
+			prevSignificant = 'x'
+			i = end
+		case c >= '0' && c <= '9':
+			// numbers (which may contain letters, e.g. 0x1F or 1u) aren't identifiers
+			end := i
+			for end < len(expr) && (isIdentifierStart(expr[end]) || (expr[end] >= '0' && expr[end] <= '9') || expr[end] == '.') {
+				end++
+			}
+			out.WriteString(expr[i:end])
+			prevSignificant = '0'
+			i = end
+		default:
+			out.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				prevSignificant = c
+			}
+			i++
+		}
+	}
+	return out.String()
+}
+
+// isStringPrefix checks if the given character is a string literal prefix
+// (raw or bytes).
+func isStringPrefix(c byte) bool {
+	return c == 'r' || c == 'R' || c == 'b' || c == 'B'
+}
+
+// isIdentifierStart checks if the given character can start an identifier.
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// identifierEnd returns the end of the identifier starting at the given index.
+func identifierEnd(expr string, start int) int {
+	end := start
+	for end < len(expr) && (isIdentifierStart(expr[end]) || (expr[end] >= '0' && expr[end] <= '9')) {
+		end++
+	}
+	return end
+}
+
+// stringLiteralEnd returns the end of the string literal starting at the given
+// index, or -1 if there's no string literal there.
+func stringLiteralEnd(expr string, start int) int {
+	i := start
+	raw := false
+	for i < len(expr) && isStringPrefix(expr[i]) && i-start < 2 {
+		if expr[i] == 'r' || expr[i] == 'R' {
+			raw = true
+		}
+		i++
+	}
+	if i >= len(expr) || (expr[i] != '"' && expr[i] != '\'') {
+		return -1
+	}
+
+	quote := expr[i : i+1]
+	if strings.HasPrefix(expr[i:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	i += len(quote)
+	for i < len(expr) {
+		if !raw && expr[i] == '\\' {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(expr[i:], quote) {
+			return i + len(quote)
+		}
+		i++
+	}
+	return len(expr)
+}
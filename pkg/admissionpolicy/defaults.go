@@ -0,0 +1,151 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// Mutations converts the defaults of the properties of the given schema and
+// its subschemata into the mutations of a MutatingAdmissionPolicy.
+//
+// Each default becomes a JSON patch adding the property if it's missing,
+// provided that the object containing it is present.  Defaults within items
+// of lists and maps are skipped, since they can't be addressed by a single
+// JSON patch.
+//
+// The subschemata of the given properties of the root schema are skipped (this
+// is usually used to skip the status of objects).
+func Mutations(schema *apiextensionsv1.JSONSchemaProps, skipProperties ...string) []admissionregistrationv1.Mutation {
+	var mutations []admissionregistrationv1.Mutation
+	walkProperties(schema, rootScope, "", func(parent scope, name, pointer string, prop *apiextensionsv1.JSONSchemaProps) {
+		if prop.Default == nil {
+			return
+		}
+		value, err := celValue(prop.Default.Raw)
+		if err != nil {
+			// the CRD generator has already checked that defaults are valid JSON
+			return
+		}
+		child := parent.property(name)
+		missing := "!" + parenthesize(child.guards[len(child.guards)-1])
+		conds := append(slices.Clone(parent.guards), missing)
+		mutations = append(mutations, admissionregistrationv1.Mutation{
+			PatchType: admissionregistrationv1.PatchTypeJSONPatch,
+			JSONPatch: &admissionregistrationv1.JSONPatch{
+				Expression: fmt.Sprintf(`%s ? [JSONPatch{op: "add", path: %q, value: %s}] : []`,
+					strings.Join(conds, " && "), pointer, value),
+			},
+		})
+	}, skipProperties)
+	return mutations
+}
+
+// walkProperties calls visit for each property of the given schema and its
+// object subschemata (but not items of lists and maps), in a stable order,
+// with the scope of the object containing the property and the JSON pointer
+// to the property.
+func walkProperties(schema *apiextensionsv1.JSONSchemaProps, s scope, pointer string, visit func(parent scope, name, pointer string, prop *apiextensionsv1.JSONSchemaProps), skipProperties []string) {
+	if schema == nil {
+		return
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		if !slices.Contains(skipProperties, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop := schema.Properties[name]
+		propPointer := pointer + "/" + escapeJSONPointer(name)
+		visit(s, name, propPointer, &prop)
+		walkProperties(&prop, s.property(name), propPointer, visit, nil)
+	}
+}
+
+// escapeJSONPointer escapes the given property name for use in a JSON pointer.
+func escapeJSONPointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// celValue converts the given JSON value into a CEL literal.
+func celValue(raw []byte) (string, error) {
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+	return celLiteral(value), nil
+}
+
+// celLiteral converts the given decoded JSON value into a CEL literal.
+func celLiteral(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		// integers stay integers, anything else is a double
+		if _, err := value.Int64(); err == nil {
+			return value.String()
+		}
+		if strings.ContainsAny(value.String(), ".eE") {
+			return value.String()
+		}
+		return value.String() + ".0"
+	case string:
+		return strconv.Quote(value)
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, celLiteral(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		entries := make([]string, 0, len(value))
+		for _, key := range keys {
+			entries = append(entries, strconv.Quote(key)+": "+celLiteral(value[key]))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	default:
+		panic(fmt.Sprintf("unexpected JSON value of type %T", value))
+	}
+}
+
+// celStringMap converts the given map into a CEL map literal, in a stable order.
+func celStringMap(values map[string]string) string {
+	generic := make(map[string]any, len(values))
+	for key, value := range values {
+		generic[key] = value
+	}
+	return celLiteral(generic)
+}
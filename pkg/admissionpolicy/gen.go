@@ -0,0 +1,218 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admissionpolicy contains libraries for generating
+// ValidatingAdmissionPolicy manifests from the CEL validation markers of
+// CustomResourceDefinition types, and MutatingAdmissionPolicy manifests from
+// their defaulting markers.
+//
+// The generated policies run the same rules as the CRD schemata, using the
+// validations of the CustomResourceDefinition generator (including
+// XValidation, the union markers like ExactlyOneOf, and immutability
+// markers), but in an admission policy, so that they can be bound to specific
+// namespaces or objects, or audited before being enforced, without deploying
+// a webhook.
+package admissionpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crdgen "sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates ValidatingAdmissionPolicy (and ValidatingAdmissionPolicyBinding)
+// objects from the CEL validation rules of CustomResourceDefinition types.
+//
+// A policy (and binding) is generated for each served version of each kind
+// with validation rules, named "<version>.<plural>.<group>".
+type Generator struct {
+	// ValidationActions sets the actions of the generated bindings, i.e.
+	// Deny, Warn and/or Audit.  If not set, defaults to Deny.
+	ValidationActions []string `marker:",optional"`
+
+	// IncludeStatus enables checking the validation rules of the status of
+	// objects.  The status is usually only written by the controller, so its
+	// rules are skipped by default.
+	IncludeStatus bool `marker:",optional"`
+
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+var _ genall.Generator = &Generator{}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crdgen.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	actions := make([]admissionregistrationv1.ValidationAction, 0, len(g.ValidationActions))
+	for _, action := range g.ValidationActions {
+		switch admissionregistrationv1.ValidationAction(action) {
+		case admissionregistrationv1.Deny, admissionregistrationv1.Warn, admissionregistrationv1.Audit:
+			actions = append(actions, admissionregistrationv1.ValidationAction(action))
+		default:
+			return fmt.Errorf("invalid validation action %q, must be one of Deny, Warn or Audit", action)
+		}
+	}
+	if len(actions) == 0 {
+		actions = []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny}
+	}
+
+	parser := &crdgen.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+	}
+	crdgen.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crdgen.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+	kubeKinds := crdgen.FindKubeKinds(parser, metav1Pkg)
+	if len(kubeKinds) == 0 {
+		// no objects in the roots
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	var skipProperties []string
+	if !g.IncludeStatus {
+		skipProperties = []string{"status"}
+	}
+
+	for _, groupKind := range kubeKinds {
+		parser.NeedCRDFor(groupKind, nil)
+		crd := parser.CustomResourceDefinitions[groupKind]
+
+		var objs []any
+		for _, version := range crd.Spec.Versions {
+			if !version.Served || version.Schema == nil {
+				continue
+			}
+			validations := Validations(version.Schema.OpenAPIV3Schema, skipProperties...)
+			if len(validations) == 0 {
+				continue
+			}
+
+			name := fmt.Sprintf("%s.%s.%s", version.Name, crd.Spec.Names.Plural, crd.Spec.Group)
+			objs = append(objs,
+				admissionregistrationv1.ValidatingAdmissionPolicy{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ValidatingAdmissionPolicy",
+						APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+					},
+					Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+						FailurePolicy:    ptrTo(admissionregistrationv1.Fail),
+						MatchConstraints: matchConstraints(crd, version.Name),
+						Validations:      validations,
+					},
+				},
+				admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ValidatingAdmissionPolicyBinding",
+						APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+					},
+					Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+						PolicyName:        name,
+						ValidationActions: actions,
+					},
+				},
+			)
+		}
+		if len(objs) == 0 {
+			continue
+		}
+
+		fileName := fmt.Sprintf("%s_%s.yaml", crd.Spec.Group, crd.Spec.Names.Plural)
+		if err := ctx.WriteYAML(fileName, headerText, objs,
+			genall.WithTransform(transformRemoveStatus),
+			genall.WithTransform(genall.TransformRemoveCreationTimestamp)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchConstraints matches creating and updating objects of the given version
+// of the given CRD.
+func matchConstraints(crd apiextensionsv1.CustomResourceDefinition, version string) *admissionregistrationv1.MatchResources {
+	return &admissionregistrationv1.MatchResources{
+		MatchPolicy: ptrTo(admissionregistrationv1.Exact),
+		ResourceRules: []admissionregistrationv1.NamedRuleWithOperations{{
+			RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+				Operations: []admissionregistrationv1.OperationType{
+					admissionregistrationv1.Create,
+					admissionregistrationv1.Update,
+				},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{crd.Spec.Group},
+					APIVersions: []string{version},
+					Resources:   []string{crd.Spec.Names.Plural},
+				},
+			},
+		}},
+	}
+}
+
+// transformRemoveStatus ensures we do not write the status field of policies.
+func transformRemoveStatus(obj map[string]any) error {
+	delete(obj, "status")
+	return nil
+}
+
+// ptrTo returns a pointer to the given value.
+func ptrTo[T any](v T) *T {
+	return &v
+}
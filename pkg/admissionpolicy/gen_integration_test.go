@@ -0,0 +1,222 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-tools/pkg/admissionpolicy"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+var _ = Describe("ValidatingAdmissionPolicy Generation From Parsing to Manifests", func() {
+	var genCtx *genall.GenerationContext
+	var outputDir string
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(func() { Expect(os.Chdir(cwd)).To(Succeed()) })
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(admissionpolicy.Generator{}.RegisterMarkers(reg)).To(Succeed())
+
+		outputDir = GinkgoT().TempDir()
+		genCtx = &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Checker:    &loader.TypeChecker{},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+	})
+
+	It("should generate policies and bindings matching the golden file", func() {
+		By("generating the manifests")
+		Expect(admissionpolicy.Generator{}.Generate(genCtx)).To(Succeed())
+		for _, pkg := range genCtx.Roots {
+			Expect(pkg.Errors).To(HaveLen(0))
+		}
+
+		By("comparing with the golden file")
+		actual, err := os.ReadFile(filepath.Join(outputDir, "testdata.kubebuilder.io_widgets.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		golden.CompareFile(GinkgoT(), "testdata.kubebuilder.io_widgets.yaml", actual)
+
+		By("checking that kinds without validation rules are skipped")
+		entries, err := os.ReadDir(outputDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	It("should use the given validation actions", func() {
+		Expect(admissionpolicy.Generator{ValidationActions: []string{"Warn", "Audit"}}.Generate(genCtx)).To(Succeed())
+
+		actual, err := os.ReadFile(filepath.Join(outputDir, "testdata.kubebuilder.io_widgets.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(actual)).To(ContainSubstring("validationActions:\n  - Warn\n  - Audit\n"))
+	})
+
+	It("should check the status if requested", func() {
+		Expect(admissionpolicy.Generator{IncludeStatus: true}.Generate(genCtx)).To(Succeed())
+
+		actual, err := os.ReadFile(filepath.Join(outputDir, "testdata.kubebuilder.io_widgets.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(actual)).To(ContainSubstring("!has(object.status.ready)"))
+	})
+
+	It("should reject unknown validation actions", func() {
+		err := admissionpolicy.Generator{ValidationActions: []string{"Ignore"}}.Generate(genCtx)
+		Expect(err).To(MatchError(ContainSubstring(`invalid validation action "Ignore"`)))
+	})
+})
+
+var _ = Describe("MutatingAdmissionPolicy Generation From Parsing to Manifests", func() {
+	var genCtx *genall.GenerationContext
+	var outputDir string
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(func() { Expect(os.Chdir(cwd)).To(Succeed()) })
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(admissionpolicy.MutatingGenerator{}.RegisterMarkers(reg)).To(Succeed())
+
+		outputDir = GinkgoT().TempDir()
+		genCtx = &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Checker:    &loader.TypeChecker{},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+	})
+
+	It("should generate policies and bindings matching the golden file", func() {
+		By("generating the manifests")
+		Expect(admissionpolicy.MutatingGenerator{}.Generate(genCtx)).To(Succeed())
+		for _, pkg := range genCtx.Roots {
+			Expect(pkg.Errors).To(HaveLen(0))
+		}
+
+		By("comparing with the golden file")
+		actual, err := os.ReadFile(filepath.Join(outputDir, "testdata.kubebuilder.io_widgets.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		golden.CompareFile(GinkgoT(), filepath.Join("mutating", "testdata.kubebuilder.io_widgets.yaml"), actual)
+
+		By("checking that kinds without defaults are skipped")
+		entries, err := os.ReadDir(outputDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Converting CRD defaults", func() {
+	It("should escape JSON pointers and convert values to CEL literals", func() {
+		schema := &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"a/b~c": {
+					Type:    "number",
+					Default: &apiextensionsv1.JSON{Raw: []byte("2")},
+				},
+				"items": {
+					Type: "array",
+					Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{
+							"skipped": {Type: "string", Default: &apiextensionsv1.JSON{Raw: []byte(`"x"`)}},
+						},
+					}},
+				},
+				"ratio": {
+					Type:    "number",
+					Default: &apiextensionsv1.JSON{Raw: []byte("0.5")},
+				},
+			},
+		}
+		Expect(admissionpolicy.Mutations(schema)).To(Equal([]admissionregistrationv1.Mutation{{
+			PatchType: admissionregistrationv1.PatchTypeJSONPatch,
+			JSONPatch: &admissionregistrationv1.JSONPatch{
+				Expression: `!("a/b~c" in object) ? [JSONPatch{op: "add", path: "/a~1b~0c", value: 2}] : []`,
+			},
+		}, {
+			PatchType: admissionregistrationv1.PatchTypeJSONPatch,
+			JSONPatch: &admissionregistrationv1.JSONPatch{
+				Expression: `!has(object.ratio) ? [JSONPatch{op: "add", path: "/ratio", value: 0.5}] : []`,
+			},
+		}}))
+	})
+})
+
+var _ = Describe("Converting CRD validation rules", func() {
+	It("should only replace references to self and oldSelf", func() {
+		schema := &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					XValidations: apiextensionsv1.ValidationRules{{
+						Rule: `self.self == "self" && self.oldSelf == r'oldSelf' && oldSelf.selfLink == self.selfLink`,
+					}},
+				},
+			},
+		}
+		Expect(admissionpolicy.Validations(schema)).To(Equal([]admissionregistrationv1.Validation{{
+			Expression: `!has(object.spec) || request.operation != 'UPDATE' || !has(oldObject.spec) || ` +
+				`(object.spec.self == "self" && object.spec.oldSelf == r'oldSelf' && oldObject.spec.selfLink == object.spec.selfLink)`,
+		}}))
+	})
+
+	It("should access properties that aren't CEL identifiers by index", func() {
+		schema := &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"x-y": {
+					Type:         "string",
+					XValidations: apiextensionsv1.ValidationRules{{Rule: "self != ''"}},
+				},
+			},
+		}
+		Expect(admissionpolicy.Validations(schema)).To(Equal([]admissionregistrationv1.Validation{{
+			Expression: `!("x-y" in object) || (object["x-y"] != '')`,
+		}}))
+	})
+})
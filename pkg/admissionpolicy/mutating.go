@@ -0,0 +1,243 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crdgen "sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+var (
+	// InjectLabelDefinition is a marker for labels to inject into objects
+	// of a kind with a MutatingAdmissionPolicy.
+	InjectLabelDefinition = markers.Must(markers.MakeDefinition("kubebuilder:admissionpolicy:label", markers.DescribesType, InjectLabel{}))
+
+	// InjectAnnotationDefinition is a marker for annotations to inject into
+	// objects of a kind with a MutatingAdmissionPolicy.
+	InjectAnnotationDefinition = markers.Must(markers.MakeDefinition("kubebuilder:admissionpolicy:annotation", markers.DescribesType, InjectAnnotation{}))
+)
+
+// +controllertools:marker:generateHelp:category="Admission Policy"
+
+// InjectLabel sets a label on all objects of this kind on admission.
+//
+// The label is set by the MutatingAdmissionPolicy generated for this kind,
+// overwriting any existing value.
+type InjectLabel struct {
+	// Key is the key of the label.
+	Key string
+	// Value is the value of the label.
+	Value string
+}
+
+// +controllertools:marker:generateHelp:category="Admission Policy"
+
+// InjectAnnotation sets an annotation on all objects of this kind on admission.
+//
+// The annotation is set by the MutatingAdmissionPolicy generated for this kind,
+// overwriting any existing value.
+type InjectAnnotation struct {
+	// Key is the key of the annotation.
+	Key string
+	// Value is the value of the annotation.
+	Value string
+}
+
+// +controllertools:marker:generateHelp
+
+// MutatingGenerator generates MutatingAdmissionPolicy (and MutatingAdmissionPolicyBinding)
+// objects from the defaulting markers of CustomResourceDefinition types.
+//
+// The generated policies set the defaults of the CRD schemata (i.e. the
+// values of +kubebuilder:default markers) of fields missing from objects, and
+// the labels and annotations of +kubebuilder:admissionpolicy:label and
+// +kubebuilder:admissionpolicy:annotation markers, as an alternative to a
+// defaulting webhook.  A policy (and binding) is generated for each served
+// version of each kind with defaults or injected labels or annotations, named
+// "<version>.<plural>.<group>".
+type MutatingGenerator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+var _ genall.Generator = &MutatingGenerator{}
+
+func (MutatingGenerator) CheckFilter() loader.NodeFilter {
+	return crdgen.Generator{}.CheckFilter()
+}
+
+func (MutatingGenerator) RegisterMarkers(into *markers.Registry) error {
+	if err := crdmarkers.Register(into); err != nil {
+		return err
+	}
+	if err := markers.RegisterAll(into, InjectLabelDefinition, InjectAnnotationDefinition); err != nil {
+		return err
+	}
+	into.AddHelp(InjectLabelDefinition, InjectLabel{}.Help())
+	into.AddHelp(InjectAnnotationDefinition, InjectAnnotation{}.Help())
+	return nil
+}
+
+func (g MutatingGenerator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crdgen.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+	}
+	crdgen.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crdgen.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+	kubeKinds := crdgen.FindKubeKinds(parser, metav1Pkg)
+	if len(kubeKinds) == 0 {
+		// no objects in the roots
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	for _, groupKind := range kubeKinds {
+		parser.NeedCRDFor(groupKind, nil)
+		crd := parser.CustomResourceDefinitions[groupKind]
+
+		var objs []any
+		for _, version := range crd.Spec.Versions {
+			if !version.Served || version.Schema == nil {
+				continue
+			}
+			mutations := Mutations(version.Schema.OpenAPIV3Schema, "status")
+			if mutation, ok := injectionMutation(parser, groupKind, version.Name); ok {
+				mutations = append(mutations, mutation)
+			}
+			if len(mutations) == 0 {
+				continue
+			}
+
+			name := fmt.Sprintf("%s.%s.%s", version.Name, crd.Spec.Names.Plural, crd.Spec.Group)
+			objs = append(objs,
+				admissionregistrationv1.MutatingAdmissionPolicy{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "MutatingAdmissionPolicy",
+						APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+					},
+					Spec: admissionregistrationv1.MutatingAdmissionPolicySpec{
+						FailurePolicy:    ptrTo(admissionregistrationv1.Fail),
+						MatchConstraints: matchConstraints(crd, version.Name),
+						Mutations:        mutations,
+						// defaulting is idempotent, so it's safe to reapply
+						// when other plugins have removed fields
+						ReinvocationPolicy: admissionregistrationv1.IfNeededReinvocationPolicy,
+					},
+				},
+				admissionregistrationv1.MutatingAdmissionPolicyBinding{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "MutatingAdmissionPolicyBinding",
+						APIVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+					},
+					Spec: admissionregistrationv1.MutatingAdmissionPolicyBindingSpec{
+						PolicyName: name,
+					},
+				},
+			)
+		}
+		if len(objs) == 0 {
+			continue
+		}
+
+		fileName := fmt.Sprintf("%s_%s.yaml", crd.Spec.Group, crd.Spec.Names.Plural)
+		if err := ctx.WriteYAML(fileName, headerText, objs,
+			genall.WithTransform(transformRemoveStatus),
+			genall.WithTransform(genall.TransformRemoveCreationTimestamp)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// injectionMutation returns a mutation setting the labels and annotations of
+// the injection markers of the given version of the given kind, if any.
+func injectionMutation(parser *crdgen.Parser, groupKind schema.GroupKind, version string) (admissionregistrationv1.Mutation, bool) {
+	labels := make(map[string]string)
+	annotations := make(map[string]string)
+	for pkg, gv := range parser.GroupVersions {
+		if gv.Group != groupKind.Group || gv.Version != version {
+			continue
+		}
+		info, known := parser.Types[crdgen.TypeIdent{Package: pkg, Name: groupKind.Kind}]
+		if !known {
+			continue
+		}
+		for _, marker := range info.Markers[InjectLabelDefinition.Name] {
+			label := marker.(InjectLabel)
+			labels[label.Key] = label.Value
+		}
+		for _, marker := range info.Markers[InjectAnnotationDefinition.Name] {
+			annotation := marker.(InjectAnnotation)
+			annotations[annotation.Key] = annotation.Value
+		}
+	}
+	if len(labels) == 0 && len(annotations) == 0 {
+		return admissionregistrationv1.Mutation{}, false
+	}
+
+	var fields []string
+	if len(labels) > 0 {
+		fields = append(fields, "labels: "+celStringMap(labels))
+	}
+	if len(annotations) > 0 {
+		fields = append(fields, "annotations: "+celStringMap(annotations))
+	}
+	return admissionregistrationv1.Mutation{
+		PatchType: admissionregistrationv1.PatchTypeApplyConfiguration,
+		ApplyConfiguration: &admissionregistrationv1.ApplyConfiguration{
+			Expression: fmt.Sprintf("Object{metadata: Object.metadata{%s}}", strings.Join(fields, ", ")),
+		},
+	}, true
+}
@@ -0,0 +1,133 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate ../../../.run-controller-gen.sh admissionpolicy paths=. output:dir=.
+//go:generate ../../../.run-controller-gen.sh mutatingadmissionpolicy paths=. output:dir=./mutating
+
+// +groupName=testdata.kubebuilder.io
+// +versionName=v1
+package widget
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WidgetSpec is the spec for the widgets API.
+// +kubebuilder:validation:ExactlyOneOf=size;replicas
+type WidgetSpec struct {
+	// Size is the size of the widget.
+	// +kubebuilder:validation:XValidation:rule="self in ['small', 'large']",message="size must be small or large"
+	Size *string `json:"size,omitempty"`
+
+	// Replicas is the number of replicas of the widget.
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Class is the class of the widget, which can't be changed.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="class is immutable"
+	Class string `json:"class"`
+
+	// Parts are the parts of the widget.
+	Parts []Part `json:"parts,omitempty"`
+
+	// Labels are the labels of the widget.
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k != 'self')",messageExpression="'reserved label: ' + 'self'"
+	Labels map[string]Label `json:"labels,omitempty"`
+
+	// Mode is the mode of the widget.
+	// +kubebuilder:default=auto
+	Mode string `json:"mode,omitempty"`
+
+	// Options are the options of the widget.
+	Options *WidgetOptions `json:"options,omitempty"`
+}
+
+// WidgetOptions are the options of a widget.
+type WidgetOptions struct {
+	// Retries is the number of retries.
+	// +kubebuilder:default=3
+	Retries int32 `json:"retries,omitempty"`
+
+	// Tags are the tags of the widget.
+	// +kubebuilder:default={"default","x/y"}
+	Tags []string `json:"tags,omitempty"`
+
+	// Settings are free-form settings of the widget.
+	// +kubebuilder:default={verbose: "true", level: "info"}
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// Part is a part of a widget.
+// +kubebuilder:validation:XValidation:rule="self.min <= self.max",message="min must not exceed max"
+// +kubebuilder:validation:XValidation:rule="self.name == oldSelf.name",message="skipped for list items"
+type Part struct {
+	Name string `json:"name"`
+	// +kubebuilder:default=1
+	Count int32 `json:"count,omitempty"`
+	Min   int32 `json:"min"`
+	Max   int32 `json:"max"`
+}
+
+// Label is the value of a label.
+// +kubebuilder:validation:XValidation:rule="size(self) < 64"
+type Label string
+
+// WidgetStatus is the status for the widgets API.
+type WidgetStatus struct {
+	// +kubebuilder:default=Pending
+	Phase string `json:"phase,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="self >= 0"
+	Ready int32 `json:"ready,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:admissionpolicy:label:key=app.kubernetes.io/managed-by,value=widget-operator
+// +kubebuilder:admissionpolicy:annotation:key=example.com/owner,value="team widgets"
+
+// Widget is the Schema for the widgets API.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WidgetList contains a list of Widget.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
+
+// Gadget has no validation rules, so no policy is generated for it.
+// +kubebuilder:object:root=true
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GadgetList contains a list of Gadget.
+type GadgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Gadget `json:"items"`
+}
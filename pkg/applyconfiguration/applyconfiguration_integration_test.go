@@ -17,6 +17,7 @@ limitations under the License.
 package applyconfiguration
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/fs"
@@ -25,9 +26,11 @@ import (
 	"path/filepath"
 	"strings"
 
+	openapiv2 "github.com/google/gnostic-models/openapiv2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
 
 	"sigs.k8s.io/controller-tools/pkg/crd"
 	"sigs.k8s.io/controller-tools/pkg/genall"
@@ -233,6 +236,150 @@ var _ = Describe("ApplyConfiguration generation from API types", func() {
 		Entry("with the an alternative output package", "other"),
 		Entry("with a package outside of the current directory", "../../clients"),
 	)
+
+	It("should generate the structured-merge-diff schema of the CronJob group", func() {
+		output := make(outputToMap)
+
+		By("Initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("smdschema", markers.DescribesPackage, SchemaGenerator{})))).To(Succeed())
+
+		rt, err := genall.FromOptions(optionsRegistry, []string{"smdschema", "paths=./api/v1"})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+
+		By("Running the generator")
+		Expect(rt.Run()).To(BeFalse(), "Generator should run without errors")
+
+		By("Parsing the generated schema")
+		Expect(output).To(HaveKey("testdata.kubebuilder.io.schema.yaml"))
+		parser, err := typed.NewParser(typed.YAMLObject(output["testdata.kubebuilder.io.schema.yaml"].contents))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Checking the managed fields semantics of a CronJob")
+		cronJobType := parser.Type("io.k8s.sigs.controller-tools.pkg.applyconfiguration.testdata.cronjob.api.v1.CronJob")
+		cronJob, err := cronJobType.FromYAML(`
+apiVersion: testdata.kubebuilder.io/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            image: backup:v1
+`)
+		Expect(err).NotTo(HaveOccurred())
+		fields, err := cronJob.ToFieldSet()
+		Expect(err).NotTo(HaveOccurred())
+		// containers are a map keyed by name
+		Expect(fields.String()).To(ContainSubstring(`.spec.jobTemplate.spec.template.spec.containers[name="backup"].image`))
+	})
+
+	It("should type-check the package before checking it for generic types", func() {
+		By("Initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		generator := Generator{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("applyconfiguration", markers.DescribesPackage, generator)))).To(Succeed())
+		Expect(generator.RegisterMarkers(optionsRegistry)).To(Succeed())
+
+		rt, err := genall.FromOptions(optionsRegistry, []string{"applyconfiguration", "paths=./api/v1"})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: make(outputToMap)}
+		var errOut bytes.Buffer
+		rt.ErrorWriter = &errOut
+
+		By("Running the generator")
+		Expect(rt.Run()).To(BeFalse(), "Generator should run without errors:\n%s", errOut.String())
+		Expect(errOut.String()).NotTo(ContainSubstring("unknown type"), "the schemas should resolve the types of other packages")
+		Expect(filepath.Join("api/v1", applyConfigurationDir, "api/v1", "cronjob.go")).To(BeARegularFile())
+	})
+
+	It("should reject the generic types the apply configurations would be generated for", func() {
+		Expect(os.WriteFile(filepath.Join("api/v1", "generic_types.go"), []byte(`package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource
+
+type GenericKind struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec Range[int32]
+}
+
+type Range[T any] struct {
+	Min T
+}
+`), 0o644)).To(Succeed())
+
+		By("Initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		generator := Generator{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("applyconfiguration", markers.DescribesPackage, generator)))).To(Succeed())
+		Expect(generator.RegisterMarkers(optionsRegistry)).To(Succeed())
+
+		rt, err := genall.FromOptions(optionsRegistry, []string{"applyconfiguration", "paths=./api/v1"})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: make(outputToMap)}
+		rt.ErrorWriter = GinkgoWriter
+
+		By("Running the generator")
+		Expect(rt.Run()).To(BeTrue(), "Generator should fail")
+		var errs []string
+		for _, err := range rt.Roots[0].Errors {
+			errs = append(errs, err.Msg)
+		}
+		Expect(errs).To(ContainElement(ContainSubstring("GenericKind uses the generic type Range, which is not supported by applyconfiguration generation")))
+
+		By("Checking that no apply configurations were written")
+		Expect(filepath.Join("api/v1", applyConfigurationDir)).NotTo(BeADirectory())
+	})
+
+	It("should generate the swagger document of the CronJob group", func() {
+		output := make(outputToMap)
+
+		By("Initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("swagger", markers.DescribesPackage, SwaggerGenerator{})))).To(Succeed())
+
+		rt, err := genall.FromOptions(optionsRegistry, []string{"swagger:title=CronJob API,version=v1", "paths=./api/v1"})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+
+		By("Running the generator")
+		Expect(rt.Run()).To(BeFalse(), "Generator should run without errors")
+
+		By("Parsing the generated document")
+		Expect(output).To(HaveKey("testdata.kubebuilder.io/swagger.json"))
+		document, err := openapiv2.ParseDocument(output["testdata.kubebuilder.io/swagger.json"].contents)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(document.Info.Title).To(Equal("CronJob API"))
+		Expect(document.Info.Version).To(Equal("v1"))
+
+		By("Checking the definitions of the kinds and the types they refer to")
+		var definitions []string
+		for _, definition := range document.Definitions.AdditionalProperties {
+			definitions = append(definitions, definition.Name)
+		}
+		Expect(definitions).To(ContainElements(
+			"io.k8s.sigs.controller-tools.pkg.applyconfiguration.testdata.cronjob.api.v1.CronJob",
+			"io.k8s.sigs.controller-tools.pkg.applyconfiguration.testdata.cronjob.api.v1.CronJobSpec",
+			"io.k8s.api.core.v1.Container",
+		))
+	})
 })
 
 func replaceOutputPkgMarker(dir string, newOutputPackage string) error {
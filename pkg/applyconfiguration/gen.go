@@ -22,6 +22,7 @@ import (
 	"go/ast"
 	"maps"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -81,6 +82,22 @@ func (Generator) CheckFilter() loader.NodeFilter {
 	}
 }
 
+// ExclusiveRun is true, since gengo's parser relies on global state.
+func (Generator) ExclusiveRun() bool {
+	return true
+}
+
+// RequiredModules requires the version of k8s.io/apimachinery whose managed
+// fields support type converters from schemes, as used by the generated
+// utilities.
+func (Generator) RequiredModules() []genall.ModuleRequirement {
+	return []genall.ModuleRequirement{{
+		Module:     "k8s.io/apimachinery",
+		MinVersion: "v0.30.0",
+		Reason:     "the generated NewTypeConverter functions",
+	}}
+}
+
 func (Generator) RegisterMarkers(into *markers.Registry) error {
 	if err := markers.RegisterAll(into,
 		isCRDMarker, enablePkgMarker, enableTypeMarker, outputPkgMarker); err != nil {
@@ -136,27 +153,28 @@ func outputPkg(col *markers.Collector, pkg *loader.Package) string {
 	return defaultOutputPackage
 }
 
+// OutputPackage returns the import path of the package the apply
+// configurations of the given package are generated in, and whether they're
+// generated at all.
+func OutputPackage(col *markers.Collector, pkg *loader.Package) (string, bool) {
+	enabled, err := enabledOnPackage(col, pkg)
+	if err != nil || !enabled {
+		return "", false
+	}
+	return path.Join(pkg.PkgPath, outputPkg(col, pkg)), true
+}
+
 func isCRD(info *markers.TypeInfo) bool {
 	objectEnabled := info.Markers.Get(isCRDMarker.Name)
 	return objectEnabled != nil
 }
 
 func (d Generator) Generate(ctx *genall.GenerationContext) error {
-	headerFilePath := d.HeaderFile
-
-	if headerFilePath == "" {
-		tmpFile, err := os.CreateTemp("", "applyconfig-header-*.txt")
-		if err != nil {
-			return fmt.Errorf("failed to create temporary file: %w", err)
-		}
-		if err := tmpFile.Close(); err != nil {
-			return fmt.Errorf("failed to close temporary file: %w", err)
-		}
-
-		defer os.Remove(tmpFile.Name())
-
-		headerFilePath = tmpFile.Name()
+	headerFilePath, cleanup, err := ctx.GoHeaderFile(d.HeaderFile)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
 
 	// Parse external apply configurations
 	externalACs := make(map[types.Name]string)
@@ -174,6 +192,7 @@ func (d Generator) Generate(ctx *genall.GenerationContext) error {
 		Checker:                     ctx.Checker,
 		HeaderFilePath:              headerFilePath,
 		ExternalApplyConfigurations: externalACs,
+		FileTypes:                   ctx.VerifiableFileTypes,
 	}
 
 	errs := []error{}
@@ -198,6 +217,10 @@ type ObjectGenCtx struct {
 	Checker                     *loader.TypeChecker
 	HeaderFilePath              string
 	ExternalApplyConfigurations map[types.Name]string
+	// FileTypes wraps the file types of gengo, if set, for the files it
+	// writes to be written as the other files of the run (see
+	// genall.GenerationContext.VerifiableFileTypes).
+	FileTypes func(map[string]generator.FileType) map[string]generator.FileType
 }
 
 // generateForPackage generates apply configuration implementations for
@@ -236,6 +259,12 @@ func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) error {
 	if err != nil {
 		return fmt.Errorf("failed making a context: %w", err)
 	}
+	// code-generator writes the files itself, bypassing the output rules
+	fileTypes := ctx.FileTypes
+	if fileTypes == nil {
+		fileTypes = genall.GenerationContext{}.VerifiableFileTypes
+	}
+	c.FileTypes = fileTypes(c.FileTypes)
 
 	pkg, ok := c.Universe[root.PkgPath]
 	if !ok {
@@ -281,6 +310,17 @@ func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) error {
 		return err
 	}
 
+	ctx.Checker.Check(root)
+
+	// applyconfiguration-gen doesn't support generics, and would write invalid
+	// code for generic types
+	if errs := genericTypeErrors(ctx.Collector, root); len(errs) > 0 {
+		for _, err := range errs {
+			root.AddError(err)
+		}
+		return nil
+	}
+
 	schemaFile, err := ctx.buildOpenAPISchema(root, gv)
 	if err != nil {
 		return fmt.Errorf("failed to build OpenAPI schema: %w", err)
@@ -298,6 +338,58 @@ func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) error {
 	return nil
 }
 
+// genericTypeErrors returns errors for the generic types the apply
+// configurations of the given package would be generated for, i.e. the ones
+// used (directly or through other types of the package) by the types apply
+// configurations are enabled on.
+func genericTypeErrors(col *markers.Collector, root *loader.Package) []error {
+	root.NeedTypesInfo()
+	infos := make(map[string]*markers.TypeInfo)
+	var enabled []string
+	if err := markers.EachType(col, root, func(info *markers.TypeInfo) {
+		infos[info.Name] = info
+		if enabledOnType(info) {
+			enabled = append(enabled, info.Name)
+		}
+	}); err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	seen := make(map[string]struct{})
+	var check func(name string)
+	check = func(name string) {
+		info, known := infos[name]
+		if _, checked := seen[name]; checked || !known {
+			return
+		}
+		seen[name] = struct{}{}
+		if info.RawSpec.TypeParams != nil {
+			errs = append(errs, loader.ErrFromNode(fmt.Errorf("generic type %s is not supported by applyconfiguration generation", name), info.RawSpec))
+			return
+		}
+		ast.Inspect(info.RawSpec.Type, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.IndexExpr, *ast.IndexListExpr:
+				if inst, isInstance := loader.InstanceOf(root.TypesInfo, node.(ast.Expr)); isInstance {
+					errs = append(errs, loader.ErrFromNode(fmt.Errorf("%s uses the generic type %s, which is not supported by applyconfiguration generation", name, inst.Named.Obj().Name()), node))
+					return false
+				}
+			case *ast.Ident:
+				// other types of the package get apply configurations too
+				if obj := root.TypesInfo.Uses[node]; obj != nil && obj.Pkg() == root.Types {
+					check(node.Name)
+				}
+			}
+			return true
+		})
+	}
+	for _, name := range enabled {
+		check(name)
+	}
+	return errs
+}
+
 func isCRDClusterScoped(info *markers.TypeInfo) bool {
 	resourceMarker := info.Markers.Get(isCRDMarker.Name)
 	if resourceMarker == nil {
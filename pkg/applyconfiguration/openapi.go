@@ -33,12 +33,56 @@ import (
 )
 
 // buildOpenAPISchema generates a minimal OpenAPI v2 Swagger document containing
-// schemas for every type referenced by root CRD types in the package. Types are
-// represented as separate definitions with $ref links between them, producing
-// namedType entries in the structured-merge-diff schema. The definition keys
-// match the convention used by code-generator (via kube-openapi
-// util.ToRESTFriendlyName).
+// schemas for every type referenced by root CRD types in the package, writing
+// it to a temporary file.  Returns an empty path if the package has no root CRD
+// types.
 func (ctx *ObjectGenCtx) buildOpenAPISchema(root *loader.Package, gv schema.GroupVersion) (string, error) {
+	definitions, err := ctx.buildOpenAPIDefinitions(root, gv, enabledOnType)
+	if err != nil || definitions == nil {
+		return "", err
+	}
+
+	swaggerJSON, err := json.Marshal(swaggerDocument(definitions))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal swagger document: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "openapi-schema-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(swaggerJSON); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to write swagger document: %w", err)
+	}
+
+	return tmpFile.Name(), nil
+}
+
+// swaggerDocument returns an OpenAPI v2 Swagger document with the given
+// definitions.
+func swaggerDocument(definitions map[string]any) map[string]any {
+	return map[string]any{
+		"swagger": "2.0",
+		"info": map[string]any{
+			"title":   "Kubernetes CRD Swagger",
+			"version": "v0.1.0",
+		},
+		"paths":       map[string]any{},
+		"definitions": definitions,
+	}
+}
+
+// buildOpenAPIDefinitions generates the OpenAPI v2 definitions of every type
+// referenced by the root types of the package (those the given function
+// accepts), by definition key.  Types are represented as separate definitions
+// with $ref links between them, producing namedType entries in the
+// structured-merge-diff schema. The definition keys match the convention used
+// by code-generator (via kube-openapi util.ToRESTFriendlyName).  Returns nil if
+// the package has no root types.
+func (ctx *ObjectGenCtx) buildOpenAPIDefinitions(root *loader.Package, gv schema.GroupVersion, isRootType func(*markers.TypeInfo) bool) (map[string]any, error) {
 	p := &crd.Parser{
 		Collector:              ctx.Collector,
 		Checker:                ctx.Checker,
@@ -69,49 +113,50 @@ func (ctx *ObjectGenCtx) buildOpenAPISchema(root *loader.Package, gv schema.Grou
 	// $ref references in the schemas.
 	crdTypeSet := make(map[string]bool)
 	if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
-		if !enabledOnType(info) {
+		if !isRootType(info) {
 			return
 		}
 		crdTypeSet[info.Name] = true
 		p.NeedSchemaFor(crd.TypeIdent{Package: root, Name: info.Name})
 	}); err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(crdTypeSet) == 0 {
-		return "", nil
+		return nil, nil
 	}
 
-	// Build pkgByPath map for resolving cross-package refs.
+	// Build pkgByPath map for resolving cross-package refs, which are
+	// written without vendor (like the overrides).
 	pkgByPath := make(map[string]*loader.Package)
 	for ident := range p.Schemata {
 		if ident.Package != nil {
-			pkgByPath[ident.Package.PkgPath] = ident.Package
+			pkgByPath[loader.NonVendorPath(ident.Package.PkgPath)] = ident.Package
 		}
 	}
 
 	// Process every type in Schemata into a swagger definition.
 	definitions := make(map[string]any)
 	for ident, s := range p.Schemata {
-		schema := s.DeepCopy()
-
 		// Resolve $ref entries inside AllOf (embedded structs) so that
 		// FlattenEmbedded can merge their properties. $ref in Properties,
-		// Items, etc. are preserved for namedType generation.
-		if err := resolveAllOfRefs(schema, ident.Package, p, pkgByPath); err != nil {
-			return "", fmt.Errorf("failed to resolve allOf refs for %s: %w", ident.Name, err)
+		// Items, etc. are preserved for namedType generation.  FlattenEmbedded
+		// copies the schema, so the parser's schemata are left untouched.
+		resolved, _, err := resolveAllOfRefs(s, ident.Package, p, pkgByPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve allOf refs for %s: %w", ident.Name, err)
 		}
-		schema = crd.FlattenEmbedded(schema, ident.Package)
+		schema := crd.FlattenEmbedded(&resolved, ident.Package)
 
 		// Convert internal $ref format to swagger definition keys.
 		convertRefs(schema, ident.Package)
 
 		schemaJSON, err := json.Marshal(schema)
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal schema for %s: %w", ident.Name, err)
+			return nil, fmt.Errorf("failed to marshal schema for %s: %w", ident.Name, err)
 		}
 		var schemaMap map[string]any
 		if err := json.Unmarshal(schemaJSON, &schemaMap); err != nil {
-			return "", fmt.Errorf("failed to unmarshal schema for %s: %w", ident.Name, err)
+			return nil, fmt.Errorf("failed to unmarshal schema for %s: %w", ident.Name, err)
 		}
 
 		// Clean the schema to be OpenAPI v2 compatible.
@@ -138,99 +183,106 @@ func (ctx *ObjectGenCtx) buildOpenAPISchema(root *loader.Package, gv schema.Grou
 	}
 
 	resolveRefDefinitions(definitions)
-
-	swagger := map[string]any{
-		"swagger": "2.0",
-		"info": map[string]any{
-			"title":   "Kubernetes CRD Swagger",
-			"version": "v0.1.0",
-		},
-		"paths":       map[string]any{},
-		"definitions": definitions,
-	}
-
-	swaggerJSON, err := json.Marshal(swagger)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal swagger document: %w", err)
-	}
-
-	tmpFile, err := os.CreateTemp("", "openapi-schema-*.json")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer tmpFile.Close()
-
-	if _, err := tmpFile.Write(swaggerJSON); err != nil {
-		os.Remove(tmpFile.Name())
-		return "", fmt.Errorf("failed to write swagger document: %w", err)
-	}
-
-	return tmpFile.Name(), nil
+	return definitions, nil
 }
 
-// resolveAllOfRefs walks the schema and resolves $ref entries inside AllOf slices
-// by replacing them with the referenced type's schema (deep-copied). This preserves
-// $ref in other locations (Properties, Items, etc.) while making AllOf entries ready
-// for flattening by FlattenEmbedded.
+// resolveAllOfRefs returns the given schema with the $ref entries inside its
+// AllOf slices (and those of its nested schemata) replaced by the referenced
+// type's schema, and whether any was.  This preserves $ref in other locations
+// (Properties, Items, etc.) while making AllOf entries ready for flattening by
+// FlattenEmbedded.
+//
+// The given schema and the parser's schemata aren't modified: only the maps
+// and slices on the way to the resolved entries are copied, rather than the
+// whole schemata, which FlattenEmbedded copies anyway.
 //
 // We only resolve AllOfs, as nullable, anyOf, oneOf and not are dropped, since swagger
 // v2 doesn't support them.
-func resolveAllOfRefs(schema *apiextensionsv1.JSONSchemaProps, contextPkg *loader.Package, p *crd.Parser, pkgByPath map[string]*loader.Package) error {
-	if schema == nil {
-		return nil
-	}
-
-	for i := range schema.AllOf {
-		entry := &schema.AllOf[i]
-		if entry.Ref != nil && len(*entry.Ref) > 0 {
+func resolveAllOfRefs(schema apiextensionsv1.JSONSchemaProps, contextPkg *loader.Package, p *crd.Parser, pkgByPath map[string]*loader.Package) (apiextensionsv1.JSONSchemaProps, bool, error) {
+	resolvedAny := false
+
+	if len(schema.AllOf) > 0 {
+		allOf := make([]apiextensionsv1.JSONSchemaProps, len(schema.AllOf))
+		for i, entry := range schema.AllOf {
+			if entry.Ref == nil || len(*entry.Ref) == 0 {
+				// Recurse into non-ref AllOf entries.
+				resolved, resolvedEntry, err := resolveAllOfRefs(entry, contextPkg, p, pkgByPath)
+				if err != nil {
+					return schema, false, err
+				}
+				allOf[i] = resolved
+				resolvedAny = resolvedAny || resolvedEntry
+				continue
+			}
 			typeName, pkgPath, err := crd.RefParts(*entry.Ref)
 			if err != nil {
-				return fmt.Errorf("failed to parse ref %q: %w", *entry.Ref, err)
+				return schema, false, fmt.Errorf("failed to parse ref %q: %w", *entry.Ref, err)
 			}
 			pkg := contextPkg
 			if pkgPath != "" {
 				pkg = pkgByPath[pkgPath]
 			}
 			if pkg == nil {
-				return fmt.Errorf("package %q not found for ref %q", pkgPath, *entry.Ref)
+				return schema, false, fmt.Errorf("package %q not found for ref %q", pkgPath, *entry.Ref)
 			}
 			refIdent := crd.TypeIdent{Package: pkg, Name: typeName}
 			refSchema, found := p.Schemata[refIdent]
 			if !found {
-				return fmt.Errorf("schema not found for type %q in package %q", typeName, pkg.PkgPath)
+				return schema, false, fmt.Errorf("schema not found for type %q in package %q", typeName, pkg.PkgPath)
 			}
-			resolved := refSchema.DeepCopy()
 			// Recurse into the resolved schema to handle nested embeddings.
-			if err := resolveAllOfRefs(resolved, pkg, p, pkgByPath); err != nil {
-				return err
-			}
-			schema.AllOf[i] = *resolved
-		} else {
-			// Recurse into non-ref AllOf entries.
-			if err := resolveAllOfRefs(entry, contextPkg, p, pkgByPath); err != nil {
-				return err
+			resolved, _, err := resolveAllOfRefs(refSchema, pkg, p, pkgByPath)
+			if err != nil {
+				return schema, false, err
 			}
+			allOf[i] = resolved
+			resolvedAny = true
+		}
+		if resolvedAny {
+			schema.AllOf = allOf
 		}
 	}
 
 	// Recurse into other schema locations that may contain nested AllOf refs.
+	var props map[string]apiextensionsv1.JSONSchemaProps
 	for k, v := range schema.Properties {
-		if err := resolveAllOfRefs(&v, contextPkg, p, pkgByPath); err != nil {
-			return err
+		resolved, resolvedProp, err := resolveAllOfRefs(v, contextPkg, p, pkgByPath)
+		if err != nil {
+			return schema, false, err
 		}
-		schema.Properties[k] = v
+		if !resolvedProp {
+			continue
+		}
+		if props == nil {
+			props = maps.Clone(schema.Properties)
+		}
+		props[k] = resolved
+	}
+	if props != nil {
+		schema.Properties = props
+		resolvedAny = true
 	}
 	if schema.Items != nil && schema.Items.Schema != nil {
-		if err := resolveAllOfRefs(schema.Items.Schema, contextPkg, p, pkgByPath); err != nil {
-			return err
+		resolved, resolvedItems, err := resolveAllOfRefs(*schema.Items.Schema, contextPkg, p, pkgByPath)
+		if err != nil {
+			return schema, false, err
+		}
+		if resolvedItems {
+			schema.Items = &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &resolved, JSONSchemas: schema.Items.JSONSchemas}
+			resolvedAny = true
 		}
 	}
 	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
-		if err := resolveAllOfRefs(schema.AdditionalProperties.Schema, contextPkg, p, pkgByPath); err != nil {
-			return err
+		resolved, resolvedProps, err := resolveAllOfRefs(*schema.AdditionalProperties.Schema, contextPkg, p, pkgByPath)
+		if err != nil {
+			return schema, false, err
+		}
+		if resolvedProps {
+			schema.AdditionalProperties = &apiextensionsv1.JSONSchemaPropsOrBool{Allows: schema.AdditionalProperties.Allows, Schema: &resolved}
+			resolvedAny = true
 		}
 	}
-	return nil
+	return schema, resolvedAny, nil
 }
 
 // convertRefs walks the schema and converts internal $ref links from the
@@ -0,0 +1,208 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applyconfiguration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	openapiv2 "github.com/google/gnostic-models/openapiv2"
+	"gopkg.in/yaml.v2"
+	"k8s.io/kube-openapi/pkg/schemaconv"
+	utilproto "k8s.io/kube-openapi/pkg/util/proto"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+var isObjectMarker = markers.Must(markers.MakeDefinition("kubebuilder:object:root", markers.DescribesType, false))
+
+// +controllertools:marker:generateHelp
+
+// SchemaGenerator generates the structured-merge-diff schemas of the kinds of
+// the API packages.
+//
+// The schema of the kinds of each API group (each type marked with
+// +kubebuilder:object:root, and the types they refer to) is written to
+// <group>.schema.yaml, in the format of the schemas embedded in apply
+// configurations, i.e. for typed.NewParser of structured-merge-diff.  This
+// lets tooling and tests that need the managed fields semantics of the kinds
+// use them without deriving them from the CRDs.
+type SchemaGenerator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (SchemaGenerator) CheckFilter() loader.NodeFilter {
+	return Generator{}.CheckFilter()
+}
+
+func (SchemaGenerator) RegisterMarkers(into *markers.Registry) error {
+	if err := into.Register(isObjectMarker); err != nil {
+		return err
+	}
+	return Generator{}.RegisterMarkers(into)
+}
+
+func (g SchemaGenerator) Generate(ctx *genall.GenerationContext) error {
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	for _, group := range kindDefinitions(ctx) {
+		schemaYAML, err := smdSchema(group.definitions)
+		if err != nil {
+			group.root.AddError(fmt.Errorf("failed to build the structured-merge-diff schema of group %q: %w", group.name, err))
+			continue
+		}
+		if err := writeSchema(ctx, schemaFileName(group.name), headerText, schemaYAML); err != nil {
+			group.root.AddError(err)
+		}
+	}
+	return nil
+}
+
+// groupDefinitions are the OpenAPI v2 definitions of the kinds of an API
+// group.
+type groupDefinitions struct {
+	name string
+	// root is the first API package of the group, for reporting errors.
+	root        *loader.Package
+	definitions map[string]any
+}
+
+// kindDefinitions returns the OpenAPI v2 definitions of the kinds (each type
+// marked with +kubebuilder:object:root, and the types they refer to) of the
+// API packages among the roots, by group, sorted by group name.
+func kindDefinitions(ctx *genall.GenerationContext) []groupDefinitions {
+	objGenCtx := ObjectGenCtx{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+	}
+	isKind := func(info *markers.TypeInfo) bool {
+		isRoot, _ := info.Markers.Get(isObjectMarker.Name).(bool)
+		return isRoot
+	}
+
+	groups := make(map[string]*groupDefinitions)
+	for _, root := range ctx.Roots {
+		pkgMarkers, err := markers.PackageMarkers(ctx.Collector, root)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		gv := crd.GroupVersionForPackage(pkgMarkers, root)
+		if gv.Empty() {
+			// not an API package
+			continue
+		}
+
+		definitions, err := objGenCtx.buildOpenAPIDefinitions(root, gv, isKind)
+		if err != nil {
+			root.AddError(fmt.Errorf("failed to build OpenAPI schema: %w", err))
+			continue
+		}
+		if definitions == nil {
+			continue
+		}
+		group, known := groups[gv.Group]
+		if !known {
+			group = &groupDefinitions{name: gv.Group, root: root, definitions: make(map[string]any)}
+			groups[gv.Group] = group
+		}
+		maps.Copy(group.definitions, definitions)
+	}
+
+	res := make([]groupDefinitions, 0, len(groups))
+	for _, group := range groups {
+		res = append(res, *group)
+	}
+	slices.SortFunc(res, func(a, b groupDefinitions) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return res
+}
+
+// schemaFileName returns the name of the schema file of the given group.
+func schemaFileName(group string) string {
+	return groupFileName(group) + ".schema.yaml"
+}
+
+// groupFileName returns the name of the given group in file names.
+func groupFileName(group string) string {
+	if group == "" {
+		// the legacy "core" group
+		return "core"
+	}
+	return group
+}
+
+// smdSchema converts the given OpenAPI v2 definitions to a structured-merge-diff
+// schema, in YAML, like applyconfiguration-gen does for apply configurations.
+func smdSchema(definitions map[string]any) ([]byte, error) {
+	swaggerJSON, err := json.Marshal(swaggerDocument(definitions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal swagger document: %w", err)
+	}
+	document, err := openapiv2.ParseDocument(swaggerJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse swagger document: %w", err)
+	}
+	// validates all the references between the types, too
+	models, err := utilproto.NewOpenAPIData(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenAPI models: %w", err)
+	}
+	smdSchema, err := schemaconv.ToSchema(models)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(smdSchema)
+}
+
+// writeSchema writes the given schema, prefixed with the given header, to the
+// given file.
+func writeSchema(ctx *genall.GenerationContext, fileName, headerText string, schema []byte) error {
+	out, err := ctx.Open(nil, fileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	contents := append([]byte(headerText), schema...)
+	n, err := out.Write(contents)
+	if err != nil {
+		return err
+	}
+	if n < len(contents) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applyconfiguration
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// SwaggerGenerator generates the OpenAPI v2 (swagger) documents of the kinds
+// of the API packages.
+//
+// The document of the kinds of each API group (each type marked with
+// +kubebuilder:object:root, and the types they refer to) is written to
+// <group>/swagger.json, in the format code-generator publishes its
+// swagger.json in: the definitions are named after the Go packages of the
+// types (e.g. io.k8s.api.core.v1.Pod for k8s.io/api/core/v1.Pod), and refer
+// to each other.  It's the document the apply configurations are generated
+// from, so it can be fed to applyconfiguration-gen's openapi-schema flag, or
+// to client SDK pipelines.
+type SwaggerGenerator struct {
+	// Title specifies the title of the documents.  Defaults to "Kubernetes
+	// CRD Swagger".
+	Title string `marker:",optional"`
+	// Version specifies the version of the documents.  Defaults to "v0.1.0".
+	Version string `marker:",optional"`
+}
+
+func (SwaggerGenerator) CheckFilter() loader.NodeFilter {
+	return Generator{}.CheckFilter()
+}
+
+func (SwaggerGenerator) RegisterMarkers(into *markers.Registry) error {
+	return SchemaGenerator{}.RegisterMarkers(into)
+}
+
+func (g SwaggerGenerator) Generate(ctx *genall.GenerationContext) error {
+	for _, group := range kindDefinitions(ctx) {
+		document := swaggerDocument(group.definitions)
+		info := document["info"].(map[string]any)
+		if g.Title != "" {
+			info["title"] = g.Title
+		}
+		if g.Version != "" {
+			info["version"] = g.Version
+		}
+		swaggerJSON, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			group.root.AddError(fmt.Errorf("failed to marshal the swagger document of group %q: %w", group.name, err))
+			continue
+		}
+		swaggerJSON = append(swaggerJSON, '\n')
+		if err := writeSchema(ctx, path.Join(groupFileName(group.name), "swagger.json"), "", swaggerJSON); err != nil {
+			group.root.AddError(err)
+		}
+	}
+	return nil
+}
@@ -43,3 +43,43 @@ func (Generator) Help() *markers.DefinitionHelp {
 		},
 	}
 }
+
+func (SchemaGenerator) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "generates the structured-merge-diff schemas of the kinds of",
+			Details: "the API packages.\n\nThe schema of the kinds of each API group (each type marked with\n<group>.schema.yaml, in the format of the schemas embedded in apply\nconfigurations, i.e. for typed.NewParser of structured-merge-diff.  This\nlets tooling and tests that need the managed fields semantics of the kinds\nuse them without deriving them from the CRDs.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"HeaderFile": {
+				Summary: "specifies the header text (e.g. license) to prepend to generated files.",
+				Details: "",
+			},
+			"Year": {
+				Summary: "specifies the year to substitute for \" YEAR\" in the header file.",
+				Details: "",
+			},
+		},
+	}
+}
+
+func (SwaggerGenerator) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "generates the OpenAPI v2 (swagger) documents of the kinds",
+			Details: "of the API packages.\n\nThe document of the kinds of each API group (each type marked with\n<group>/swagger.json, in the format code-generator publishes its\nswagger.json in: the definitions are named after the Go packages of the\ntypes (e.g. io.k8s.api.core.v1.Pod for k8s.io/api/core/v1.Pod), and refer\nto each other.  It's the document the apply configurations are generated\nfrom, so it can be fed to applyconfiguration-gen's openapi-schema flag, or\nto client SDK pipelines.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"Title": {
+				Summary: "specifies the title of the documents.  Defaults to \"Kubernetes",
+				Details: "CRD Swagger\".",
+			},
+			"Version": {
+				Summary: "specifies the version of the documents.  Defaults to \"v0.1.0\".",
+				Details: "",
+			},
+		},
+	}
+}
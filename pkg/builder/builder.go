@@ -0,0 +1,377 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// metav1Path is the package of the metadata of objects.
+const metav1Path = "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// builderFile collects the generated builders of a single package.
+type builderFile struct {
+	pkg     *loader.Package
+	imports *gogen.Imports
+	out     *bytes.Buffer
+}
+
+func newBuilderFile(pkg *loader.Package) *builderFile {
+	imports := gogen.NewImports(pkg.Name)
+	// refer to metav1 by its conventional alias
+	imports.NeedImportAs(metav1Path, "v1", "metav1")
+
+	return &builderFile{
+		pkg:     pkg,
+		imports: imports,
+		out:     new(bytes.Buffer),
+	}
+}
+
+// qualifier returns the package qualifier to use for types from the given
+// package, importing it as needed.
+func (f *builderFile) qualifier(pkg *types.Package) string {
+	if pkg == f.pkg.Types {
+		return ""
+	}
+	return f.imports.NeedImport(loader.NonVendorPath(pkg.Path()), pkg.Name())
+}
+
+// typeName returns the syntax for referring to the given type in the file.
+func (f *builderFile) typeName(typ types.Type) string {
+	return types.TypeString(typ, f.qualifier)
+}
+
+// generateBuilder generates the builder of the given kind, unless its
+// constructor or builder type already exist.
+func (f *builderFile) generateBuilder(parser *crd.Parser, gvk schema.GroupVersionKind) {
+	kind := gvk.Kind
+	builder := kind + "Builder"
+	scope := f.pkg.Types.Scope()
+	if scope.Lookup("New"+kind) != nil || scope.Lookup(builder) != nil {
+		return
+	}
+
+	var spec, status *builderField
+	for _, field := range builderFields(parser, f.pkg, kind) {
+		switch field.name {
+		case "Spec":
+			spec = &field
+		case "Status":
+			status = &field
+		}
+	}
+
+	namespace := "default"
+	if resource, isResource := parser.Types[crd.TypeIdent{Package: f.pkg, Name: kind}].Markers.Get("kubebuilder:resource").(crdmarkers.Resource); isResource && resource.Scope == "Cluster" {
+		namespace = ""
+	}
+	metav1 := f.imports.NeedImport(metav1Path, "v1")
+	maps := f.imports.NeedImport("maps", "maps")
+
+	c := &gogen.CodeWriter{Out: f.out}
+	c.Linef(`
+// %[1]s builds %[2]s objects, for tests.
+type %[1]s struct {
+	object %[2]s
+}
+`, builder, kind)
+
+	var specNamed *types.Named
+	if spec != nil {
+		if named, isNamed := spec.typ.(*types.Named); isNamed && named.Obj().Pkg() == f.pkg.Types {
+			specNamed = named
+		}
+	}
+
+	doc := fmt.Sprintf("New%[1]s returns a builder of a %[1]s named %[2]s-sample", kind, strings.ToLower(kind))
+	if namespace != "" {
+		doc += " in the " + namespace + " namespace"
+	}
+	if specNamed != nil {
+		doc += ", with values for its required spec fields"
+	}
+	c.Line(comment(doc + "."))
+	c.Linef("func New%s() *%s {", kind, builder)
+	c.Linef("return &%s{object: %s{", builder, kind)
+	c.Linef("TypeMeta: %s.TypeMeta{APIVersion: %q, Kind: %q},", metav1, gvk.GroupVersion().String(), kind)
+	if namespace != "" {
+		c.Linef("ObjectMeta: %s.ObjectMeta{Name: %q, Namespace: %q},", metav1, strings.ToLower(kind)+"-sample", namespace)
+	} else {
+		c.Linef("ObjectMeta: %s.ObjectMeta{Name: %q},", metav1, strings.ToLower(kind)+"-sample")
+	}
+	var specFields []builderField
+	if specNamed != nil {
+		specFields = builderFields(parser, f.pkg, specNamed.Obj().Name())
+		f.writeRequiredValues(c, parser, spec, specNamed, specFields)
+	}
+	c.Line("}}")
+	c.Line("}")
+
+	c.Linef(`
+// WithName sets the name of the %[1]s.
+func (b *%[2]s) WithName(name string) *%[2]s {
+	b.object.Name = name
+	return b
+}
+
+// WithNamespace sets the namespace of the %[1]s.
+func (b *%[2]s) WithNamespace(namespace string) *%[2]s {
+	b.object.Namespace = namespace
+	return b
+}
+
+// WithLabels adds the given labels to the %[1]s.
+func (b *%[2]s) WithLabels(labels map[string]string) *%[2]s {
+	if b.object.Labels == nil {
+		b.object.Labels = make(map[string]string, len(labels))
+	}
+	%[3]s.Copy(b.object.Labels, labels)
+	return b
+}
+
+// WithAnnotations adds the given annotations to the %[1]s.
+func (b *%[2]s) WithAnnotations(annotations map[string]string) *%[2]s {
+	if b.object.Annotations == nil {
+		b.object.Annotations = make(map[string]string, len(annotations))
+	}
+	%[3]s.Copy(b.object.Annotations, annotations)
+	return b
+}`, kind, builder, maps)
+
+	for _, field := range specFields {
+		c.Linef(`
+// %[1]s sets the %[2]s field of the spec of the %[3]s.
+func (b *%[4]s) %[1]s(value %[5]s) *%[4]s {
+	b.object.%[6]s.%[7]s = value
+	return b
+}`, methodName(field.name), field.jsonName, kind, builder, f.typeName(field.typ), spec.name, field.name)
+	}
+
+	if status != nil {
+		c.Linef(`
+// WithStatus sets the status of the %[1]s.
+func (b *%[2]s) WithStatus(status %[3]s) *%[2]s {
+	b.object.Status = status
+	return b
+}`, kind, builder, f.typeName(status.typ))
+	}
+
+	c.Linef(`
+// Build returns the built %[1]s.  It shares the maps, slices and pointers
+// set on the builder.
+func (b *%[2]s) Build() *%[1]s {
+	object := b.object
+	return &object
+}`, kind, builder)
+}
+
+// comment returns the given text as a comment, wrapped at 80 columns.
+func comment(text string) string {
+	var out strings.Builder
+	line := "//"
+	for word := range strings.FieldsSeq(text) {
+		if len(line)+1+len(word) > 80 && line != "//" {
+			out.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	out.WriteString(line)
+	return out.String()
+}
+
+// writeRequiredValues writes the values of the required fields of the given
+// spec, from their schema, as part of the literal of the kind.
+func (f *builderFile) writeRequiredValues(c *gogen.CodeWriter, parser *crd.Parser, spec *builderField, specNamed *types.Named, fields []builderField) {
+	specIdent := crd.TypeIdent{Package: f.pkg, Name: specNamed.Obj().Name()}
+	parser.NeedFlattenedSchemaFor(specIdent)
+	specSchema := parser.FlattenedSchemata[specIdent]
+
+	var values []string
+	for _, field := range fields {
+		if !slices.Contains(specSchema.Required, field.jsonName) {
+			continue
+		}
+		basic, isBasic := field.typ.Underlying().(*types.Basic)
+		if !isBasic {
+			continue
+		}
+		prop := specSchema.Properties[field.jsonName]
+		if value, ok := requiredValue(field.jsonName, &prop, basic); ok {
+			values = append(values, fmt.Sprintf("%s: %s,", field.name, value))
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	c.Linef("%s: %s{", spec.name, f.typeName(specNamed))
+	for _, value := range values {
+		c.Line(value)
+	}
+	c.Line("},")
+}
+
+// requiredValue returns the Go literal of the value of the given required
+// field, with the given schema and basic type, from its default, then the
+// first value of its enum, then its minimum (for numbers) or a placeholder
+// (for strings).  Zero values are omitted.
+func requiredValue(jsonName string, prop *apiextensionsv1.JSONSchemaProps, basic *types.Basic) (string, bool) {
+	var raw *apiextensionsv1.JSON
+	switch {
+	case prop.Default != nil:
+		raw = prop.Default
+	case len(prop.Enum) > 0:
+		raw = &prop.Enum[0]
+	}
+	if raw != nil {
+		var value any
+		if err := json.Unmarshal(raw.Raw, &value); err != nil {
+			// markers are validated when parsed, so this shouldn't happen
+			return "", false
+		}
+		lit, ok := literal(value)
+		return lit, ok && lit != `""` && lit != "0" && lit != "false"
+	}
+
+	switch {
+	case basic.Info()&types.IsString != 0:
+		return strconv.Quote("<" + jsonName + ">"), true
+	case basic.Info()&types.IsNumeric != 0 && prop.Minimum != nil:
+		minimum := *prop.Minimum
+		if prop.ExclusiveMinimum {
+			minimum++
+		}
+		if minimum == 0 {
+			return "", false
+		}
+		return strconv.FormatFloat(minimum, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+// builderField is a field set by builders.
+type builderField struct {
+	name     string
+	jsonName string
+	typ      types.Type
+}
+
+// builderFields returns the named, serialized fields of the given struct type
+// of the given package.
+func builderFields(parser *crd.Parser, pkg *loader.Package, typeName string) []builderField {
+	info := parser.Types[crd.TypeIdent{Package: pkg, Name: typeName}]
+	if info == nil {
+		return nil
+	}
+	var fields []builderField
+	for _, field := range info.Fields {
+		if field.Name == "" {
+			// embedded fields are set through the fields they promote, if at
+			// all
+			continue
+		}
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		fields = append(fields, builderField{
+			name:     field.Name,
+			jsonName: jsonName,
+			typ:      pkg.TypesInfo.TypeOf(field.RawField.Type),
+		})
+	}
+	return fields
+}
+
+// reservedMethods are the names of the methods of all builders, which fields
+// of the spec don't get.
+var reservedMethods = map[string]struct{}{
+	"WithName":        {},
+	"WithNamespace":   {},
+	"WithLabels":      {},
+	"WithAnnotations": {},
+	"WithStatus":      {},
+	"Build":           {},
+}
+
+// methodName returns the name of the method setting the given field of the
+// spec.
+func methodName(fieldName string) string {
+	name := "With" + fieldName
+	if _, reserved := reservedMethods[name]; reserved {
+		return "WithSpec" + fieldName
+	}
+	return name
+}
+
+// literal returns the Go literal of the given JSON value, if it's a scalar.
+func literal(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return strconv.Quote(value), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+// contents returns the formatted contents of the file, or nil if no builder
+// was generated for the package.
+func (f *builderFile) contents(headerText string) []byte {
+	if f.out.Len() == 0 {
+		return nil
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[3]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+%[2]s
+)
+`, f.pkg.Name, f.imports.Block(), headerText)
+	outContent.Write(f.out.Bytes())
+
+	return gogen.Format(f.pkg, outContent.Bytes())
+}
@@ -0,0 +1,26 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder generates fluent builders of the kinds of API packages, to
+// make unit tests (e.g. of reconcilers) less verbose:
+//
+//	cronJob := v1.NewCronJob().WithName("backup").WithSchedule("@daily").Build()
+//
+// Unlike apply configurations, builders fill in the objects themselves: new
+// objects are named after their kind in the default namespace, and their
+// required spec fields get sensible values (their default, the first value
+// of their enum, their minimum, or a placeholder).
+package builder
@@ -0,0 +1,114 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"slices"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing declarations, we only see hand-written ones.
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.builders.go"
+
+// +controllertools:marker:generateHelp
+
+// Generator generates fluent builders of kinds, for tests.
+//
+// The builders of the kinds of each package are written to
+// zz_generated.builders.go, as a New<Kind> function returning a <Kind>Builder,
+// with With<Field> methods for the metadata, the fields of the spec and the
+// status of the kind, and a Build method returning the object.  Kinds whose
+// New<Kind> function or <Kind>Builder type already exist are skipped.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// builders shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	kindsByPkg := make(map[*loader.Package][]string)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		for _, root := range ctx.Roots {
+			if parser.GroupVersions[root].Group != groupKind.Group {
+				continue
+			}
+			if parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}] == nil {
+				continue
+			}
+			kindsByPkg[root] = append(kindsByPkg[root], groupKind.Kind)
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		kinds := kindsByPkg[root]
+		if len(kinds) == 0 {
+			continue
+		}
+		slices.Sort(kinds)
+
+		file := newBuilderFile(root)
+		for _, kind := range kinds {
+			file.generateBuilder(parser, parser.GroupVersions[root].WithKind(kind))
+		}
+		if outContents := file.contents(headerText); outContents != nil {
+			gogen.WriteOut(ctx, root, outputFileName, outContents)
+		}
+	}
+	return nil
+}
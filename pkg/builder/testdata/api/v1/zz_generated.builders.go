@@ -0,0 +1,189 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CronJobBuilder builds CronJob objects, for tests.
+type CronJobBuilder struct {
+	object CronJob
+}
+
+// NewCronJob returns a builder of a CronJob named cronjob-sample in the default
+// namespace, with values for its required spec fields.
+func NewCronJob() *CronJobBuilder {
+	return &CronJobBuilder{object: CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch.testdata.kubebuilder.io/v1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cronjob-sample", Namespace: "default"},
+		Spec: CronJobSpec{
+			Schedule:          "<schedule>",
+			TimeZone:          "UTC",
+			ConcurrencyPolicy: "Allow",
+			Parallelism:       1,
+		},
+	}}
+}
+
+// WithName sets the name of the CronJob.
+func (b *CronJobBuilder) WithName(name string) *CronJobBuilder {
+	b.object.Name = name
+	return b
+}
+
+// WithNamespace sets the namespace of the CronJob.
+func (b *CronJobBuilder) WithNamespace(namespace string) *CronJobBuilder {
+	b.object.Namespace = namespace
+	return b
+}
+
+// WithLabels adds the given labels to the CronJob.
+func (b *CronJobBuilder) WithLabels(labels map[string]string) *CronJobBuilder {
+	if b.object.Labels == nil {
+		b.object.Labels = make(map[string]string, len(labels))
+	}
+	maps.Copy(b.object.Labels, labels)
+	return b
+}
+
+// WithAnnotations adds the given annotations to the CronJob.
+func (b *CronJobBuilder) WithAnnotations(annotations map[string]string) *CronJobBuilder {
+	if b.object.Annotations == nil {
+		b.object.Annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(b.object.Annotations, annotations)
+	return b
+}
+
+// WithSchedule sets the schedule field of the spec of the CronJob.
+func (b *CronJobBuilder) WithSchedule(value string) *CronJobBuilder {
+	b.object.Spec.Schedule = value
+	return b
+}
+
+// WithTimeZone sets the timeZone field of the spec of the CronJob.
+func (b *CronJobBuilder) WithTimeZone(value string) *CronJobBuilder {
+	b.object.Spec.TimeZone = value
+	return b
+}
+
+// WithConcurrencyPolicy sets the concurrencyPolicy field of the spec of the CronJob.
+func (b *CronJobBuilder) WithConcurrencyPolicy(value ConcurrencyPolicy) *CronJobBuilder {
+	b.object.Spec.ConcurrencyPolicy = value
+	return b
+}
+
+// WithParallelism sets the parallelism field of the spec of the CronJob.
+func (b *CronJobBuilder) WithParallelism(value int32) *CronJobBuilder {
+	b.object.Spec.Parallelism = value
+	return b
+}
+
+// WithSuspend sets the suspend field of the spec of the CronJob.
+func (b *CronJobBuilder) WithSuspend(value bool) *CronJobBuilder {
+	b.object.Spec.Suspend = value
+	return b
+}
+
+// WithStartingDeadlineSeconds sets the startingDeadlineSeconds field of the spec of the CronJob.
+func (b *CronJobBuilder) WithStartingDeadlineSeconds(value *int64) *CronJobBuilder {
+	b.object.Spec.StartingDeadlineSeconds = value
+	return b
+}
+
+// WithSpecName sets the name field of the spec of the CronJob.
+func (b *CronJobBuilder) WithSpecName(value string) *CronJobBuilder {
+	b.object.Spec.Name = value
+	return b
+}
+
+// WithTemplate sets the template field of the spec of the CronJob.
+func (b *CronJobBuilder) WithTemplate(value corev1.PodTemplateSpec) *CronJobBuilder {
+	b.object.Spec.Template = value
+	return b
+}
+
+// WithStatus sets the status of the CronJob.
+func (b *CronJobBuilder) WithStatus(status CronJobStatus) *CronJobBuilder {
+	b.object.Status = status
+	return b
+}
+
+// Build returns the built CronJob.  It shares the maps, slices and pointers
+// set on the builder.
+func (b *CronJobBuilder) Build() *CronJob {
+	object := b.object
+	return &object
+}
+
+// ScheduleBuilder builds Schedule objects, for tests.
+type ScheduleBuilder struct {
+	object Schedule
+}
+
+// NewSchedule returns a builder of a Schedule named schedule-sample.
+func NewSchedule() *ScheduleBuilder {
+	return &ScheduleBuilder{object: Schedule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch.testdata.kubebuilder.io/v1", Kind: "Schedule"},
+		ObjectMeta: metav1.ObjectMeta{Name: "schedule-sample"},
+	}}
+}
+
+// WithName sets the name of the Schedule.
+func (b *ScheduleBuilder) WithName(name string) *ScheduleBuilder {
+	b.object.Name = name
+	return b
+}
+
+// WithNamespace sets the namespace of the Schedule.
+func (b *ScheduleBuilder) WithNamespace(namespace string) *ScheduleBuilder {
+	b.object.Namespace = namespace
+	return b
+}
+
+// WithLabels adds the given labels to the Schedule.
+func (b *ScheduleBuilder) WithLabels(labels map[string]string) *ScheduleBuilder {
+	if b.object.Labels == nil {
+		b.object.Labels = make(map[string]string, len(labels))
+	}
+	maps.Copy(b.object.Labels, labels)
+	return b
+}
+
+// WithAnnotations adds the given annotations to the Schedule.
+func (b *ScheduleBuilder) WithAnnotations(annotations map[string]string) *ScheduleBuilder {
+	if b.object.Annotations == nil {
+		b.object.Annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(b.object.Annotations, annotations)
+	return b
+}
+
+// Build returns the built Schedule.  It shares the maps, slices and pointers
+// set on the builder.
+func (b *ScheduleBuilder) Build() *Schedule {
+	object := b.object
+	return &object
+}
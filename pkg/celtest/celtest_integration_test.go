@@ -0,0 +1,35 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celtest_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	"sigs.k8s.io/controller-tools/pkg/celtest"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+)
+
+var _ = Describe("CEL Test Generation", func() {
+	headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+
+	It("should generate tests evaluating the CEL rules of the kinds of the package against the fixtures", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{celtest.Generator{HeaderFile: headerFile}}, "./api/...")
+		golden.CompareFiles(GinkgoT(), "testdata", out, "api/v1/zz_generated.cel_test.go")
+	})
+})
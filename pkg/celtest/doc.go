@@ -0,0 +1,27 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package celtest generates unit tests of the CEL validation rules of the
+// kinds of a project, evaluating them against fixture objects with cel-go, so
+// that changes to the rules get unit coverage without a cluster:
+//
+//	--- FAIL: TestCELRules/invalid/widget.yaml (0.00s)
+//	    zz_generated.cel_test.go:239: Widget privileged is accepted by the rules of the CRD, but should be rejected
+//
+// Both the x-kubernetes-validations of the CRDs and the validations of the
+// ValidatingAdmissionPolicies generated from them (see the admissionpolicy
+// generator) are evaluated.
+package celtest
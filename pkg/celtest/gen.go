@@ -0,0 +1,265 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-tools/pkg/admissionpolicy"
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	// outputFileName is the name of the generated file in each package.
+	outputFileName = "zz_generated.cel_test.go"
+	// defaultFixtures is the default directory of the fixtures, relative to
+	// each package.
+	defaultFixtures = "testdata/cel"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates unit tests of the CEL validation rules of the kinds.
+//
+// The test of each package is written to zz_generated.cel_test.go, with the
+// schemas of the versions of the kinds of the package that have validation
+// rules, and the validations of the ValidatingAdmissionPolicies generated for
+// them.  It evaluates the rules against the objects of the YAML files of the
+// "valid" and "invalid" subdirectories of the fixtures directory: the objects
+// of the former must pass both the rules of the CRD and of the policy, and
+// those of the latter must be rejected by both (so invalid fixtures should
+// break rules that the policies check too, see admissionpolicy.Validations).
+//
+// Files named *.update.yaml hold updates rather than creations: pairs of
+// objects, the old object followed by the new one, to cover transition rules.
+type Generator struct {
+	// Fixtures specifies the directory of the fixtures, relative to each
+	// package.  Defaults to testdata/cel.
+	Fixtures string `marker:",optional"`
+	// IncludeStatus indicates whether the policies validate the status of
+	// objects too, like the option of the admissionpolicy generator.
+	IncludeStatus bool `marker:",optional"`
+
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+// kindRules are the CEL rules of a version of a kind.
+type kindRules struct {
+	apiVersion, kind string
+	// schema is the schema of the version, with its x-kubernetes-validations.
+	schema []byte
+	// policy are the validations of the policy of the version.
+	policy []policyValidation
+}
+
+// policyValidation is a validation of a ValidatingAdmissionPolicy.
+type policyValidation struct {
+	expression, message, messageExpression string
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	fixtures := g.Fixtures
+	if fixtures == "" {
+		fixtures = defaultFixtures
+	}
+
+	var skipProperties []string
+	if !g.IncludeStatus {
+		skipProperties = []string{"status"}
+	}
+
+	// descriptions aren't needed for validation
+	noDescriptions := 0
+	rulesByPkg := make(map[*loader.Package][]kindRules)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(groupKind, &noDescriptions)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, root := range ctx.Roots {
+			gv := parser.GroupVersions[root]
+			if gv.Group != groupKind.Group || parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}] == nil {
+				continue
+			}
+			for _, version := range crdRaw.Spec.Versions {
+				if version.Name != gv.Version || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+					continue
+				}
+				if !hasRules(version.Schema.OpenAPIV3Schema) {
+					continue
+				}
+				schemaJSON, err := json.MarshalIndent(version.Schema.OpenAPIV3Schema, "", "  ")
+				if err != nil {
+					root.AddError(err)
+					continue
+				}
+				rules := kindRules{
+					apiVersion: gv.String(),
+					kind:       groupKind.Kind,
+					schema:     schemaJSON,
+				}
+				for _, validation := range admissionpolicy.Validations(version.Schema.OpenAPIV3Schema, skipProperties...) {
+					rules.policy = append(rules.policy, policyValidation{
+						expression:        validation.Expression,
+						message:           validation.Message,
+						messageExpression: validation.MessageExpression,
+					})
+				}
+				rulesByPkg[root] = append(rulesByPkg[root], rules)
+			}
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		rules := rulesByPkg[root]
+		if len(rules) == 0 {
+			continue
+		}
+		slices.SortFunc(rules, func(a, b kindRules) int {
+			return strings.Compare(a.kind, b.kind)
+		})
+		outContents, err := generateForPackage(root, rules, fixtures, headerText)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, outContents)
+	}
+	return nil
+}
+
+// hasRules checks if the given schema or any of its subschemata has
+// x-kubernetes-validations.
+func hasRules(schema *apiextensionsv1.JSONSchemaProps) bool {
+	if schema == nil {
+		return false
+	}
+	if len(schema.XValidations) > 0 {
+		return true
+	}
+	for _, prop := range schema.Properties {
+		if hasRules(&prop) {
+			return true
+		}
+	}
+	if schema.Items != nil && hasRules(schema.Items.Schema) {
+		return true
+	}
+	return schema.AdditionalProperties != nil && hasRules(schema.AdditionalProperties.Schema)
+}
+
+// generateForPackage generates the test of the CEL rules of the kinds of the
+// given package.
+func generateForPackage(root *loader.Package, rules []kindRules, fixtures, headerText string) ([]byte, error) {
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s_test
+
+%[3]s
+
+// celRules are the CEL rules of the versions of the kinds of this package, by
+// apiVersion and kind.
+var celRules = map[celKind]celKindRules{
+`, root.Name, headerText, testPrelude)
+	for _, kind := range rules {
+		fmt.Fprintf(outContent, "{apiVersion: %q, kind: %q}: {\nschema: %s,\n", kind.apiVersion, kind.kind, stringLiteral(string(kind.schema)))
+		if len(kind.policy) > 0 {
+			outContent.WriteString("policy: []policyValidation{\n")
+			for _, validation := range kind.policy {
+				fmt.Fprintf(outContent, "{\nexpression: %s,\n", stringLiteral(validation.expression))
+				if validation.message != "" {
+					fmt.Fprintf(outContent, "message: %s,\n", stringLiteral(validation.message))
+				}
+				if validation.messageExpression != "" {
+					fmt.Fprintf(outContent, "messageExpression: %s,\n", stringLiteral(validation.messageExpression))
+				}
+				outContent.WriteString("},\n")
+			}
+			outContent.WriteString("},\n")
+		}
+		outContent.WriteString("},\n")
+	}
+	outContent.WriteString("}\n\n")
+	fmt.Fprintf(outContent, `// fixturesDir is the directory of the fixtures.
+const fixturesDir = %q
+
+`, path.Clean(strings.ReplaceAll(fixtures, `\`, "/")))
+	outContent.WriteString(testFuncs)
+
+	return gogen.Format(root, outContent.Bytes()), nil
+}
+
+// stringLiteral returns the given string as a raw string literal, if
+// possible.
+func stringLiteral(s string) string {
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WidgetSpec is the spec of a widget.
+// +kubebuilder:validation:XValidation:rule="!has(self.maxReplicas) || self.replicas <= self.maxReplicas",message="replicas must not exceed maxReplicas"
+type WidgetSpec struct {
+	// Size is the size of the widget.
+	// +kubebuilder:validation:XValidation:rule="self in ['small', 'large']",message="size must be small or large"
+	Size string `json:"size"`
+
+	// Replicas is the number of replicas of the widget.
+	Replicas int32 `json:"replicas"`
+
+	// MaxReplicas is the maximum number of replicas of the widget.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// Class is the class of the widget, which can't be changed.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="class is immutable"
+	Class string `json:"class"`
+
+	// Ports are the ports of the widget.
+	// +optional
+	Ports []Port `json:"ports,omitempty"`
+}
+
+// Port is a network port.
+// +kubebuilder:validation:XValidation:rule="self.number > 1024 || self.privileged",messageExpression="'port ' + string(self.number) + ' is privileged'"
+type Port struct {
+	Number int32 `json:"number"`
+
+	// +optional
+	Privileged bool `json:"privileged,omitempty"`
+}
+
+// WidgetStatus is the status of a widget.
+type WidgetStatus struct {
+	// +kubebuilder:validation:XValidation:rule="self >= 0"
+	Ready int32 `json:"ready,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// Widget is the Schema for the widgets API.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WidgetList contains a list of Widget.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// Gadget has no validation rules, so no test is generated for it.
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GadgetList contains a list of Gadget.
+type GadgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Gadget `json:"items"`
+}
@@ -0,0 +1,396 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	apiextensionscel "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"k8s.io/apiserver/pkg/cel/environment"
+)
+
+// celKind is a version of a kind.
+type celKind struct {
+	apiVersion, kind string
+}
+
+// celKindRules are the CEL rules of a version of a kind.
+type celKindRules struct {
+	// schema is the schema of the CRD, with its x-kubernetes-validations.
+	schema string
+	// policy are the validations of the ValidatingAdmissionPolicy.
+	policy []policyValidation
+}
+
+// policyValidation is a validation of a ValidatingAdmissionPolicy.
+type policyValidation struct {
+	expression, message, messageExpression string
+}
+
+// celRules are the CEL rules of the versions of the kinds of this package, by
+// apiVersion and kind.
+var celRules = map[celKind]celKindRules{
+	{apiVersion: "apps.testdata.kubebuilder.io/v1", kind: "Widget"}: {
+		schema: `{
+  "type": "object",
+  "properties": {
+    "apiVersion": {
+      "type": "string"
+    },
+    "kind": {
+      "type": "string"
+    },
+    "metadata": {
+      "type": "object"
+    },
+    "spec": {
+      "type": "object",
+      "required": [
+        "class",
+        "replicas",
+        "size"
+      ],
+      "properties": {
+        "class": {
+          "type": "string",
+          "x-kubernetes-validations": [
+            {
+              "rule": "self == oldSelf",
+              "message": "class is immutable"
+            }
+          ]
+        },
+        "maxReplicas": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "ports": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "required": [
+              "number"
+            ],
+            "properties": {
+              "number": {
+                "type": "integer",
+                "format": "int32"
+              },
+              "privileged": {
+                "type": "boolean"
+              }
+            },
+            "x-kubernetes-validations": [
+              {
+                "rule": "self.number \u003e 1024 || self.privileged",
+                "messageExpression": "'port ' + string(self.number) + ' is privileged'"
+              }
+            ]
+          }
+        },
+        "replicas": {
+          "type": "integer",
+          "format": "int32"
+        },
+        "size": {
+          "type": "string",
+          "x-kubernetes-validations": [
+            {
+              "rule": "self in ['small', 'large']",
+              "message": "size must be small or large"
+            }
+          ]
+        }
+      },
+      "x-kubernetes-validations": [
+        {
+          "rule": "!has(self.maxReplicas) || self.replicas \u003c= self.maxReplicas",
+          "message": "replicas must not exceed maxReplicas"
+        }
+      ]
+    },
+    "status": {
+      "type": "object",
+      "properties": {
+        "ready": {
+          "type": "integer",
+          "format": "int32",
+          "x-kubernetes-validations": [
+            {
+              "rule": "self \u003e= 0"
+            }
+          ]
+        }
+      }
+    }
+  }
+}`,
+		policy: []policyValidation{
+			{
+				expression: `!has(object.spec) || (!has(object.spec.maxReplicas) || object.spec.replicas <= object.spec.maxReplicas)`,
+				message:    `replicas must not exceed maxReplicas`,
+			},
+			{
+				expression: `!has(object.spec) || !has(object.spec.class) || request.operation != 'UPDATE' || !has(oldObject.spec) || !has(oldObject.spec.class) || (object.spec.class == oldObject.spec.class)`,
+				message:    `class is immutable`,
+			},
+			{
+				expression:        `!has(object.spec) || !has(object.spec.ports) || (object.spec.ports.all(item0, item0.number > 1024 || item0.privileged))`,
+				messageExpression: `'port ' + string(item0.number) + ' is privileged'`,
+			},
+			{
+				expression: `!has(object.spec) || !has(object.spec.size) || (object.spec.size in ['small', 'large'])`,
+				message:    `size must be small or large`,
+			},
+		},
+	},
+}
+
+// fixturesDir is the directory of the fixtures.
+const fixturesDir = "testdata/cel"
+
+// TestCELRules checks that the fixtures of the kinds of this package pass, or
+// are rejected by, the CEL rules of their CRDs and policies.
+func TestCELRules(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		valid bool
+	}{
+		{name: "valid", valid: true},
+		{name: "invalid", valid: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := filepath.Glob(filepath.Join(fixturesDir, tc.name, "*.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range files {
+				t.Run(filepath.Base(file), func(t *testing.T) {
+					objs, err := readFixtures(file)
+					if err != nil {
+						t.Fatal(err)
+					}
+					isUpdate := strings.HasSuffix(file, ".update.yaml")
+					if isUpdate && len(objs)%2 != 0 {
+						t.Fatal("updates must be pairs of objects, the old object followed by the new one")
+					}
+					for i := 0; i < len(objs); i++ {
+						var obj, oldObj map[string]any
+						if isUpdate {
+							oldObj, obj = objs[i], objs[i+1]
+							i++
+						} else {
+							obj = objs[i]
+						}
+						apiVersion, _ := obj["apiVersion"].(string)
+						kind, _ := obj["kind"].(string)
+						rules, known := celRules[celKind{apiVersion: apiVersion, kind: kind}]
+						if !known {
+							// a fixture of another package, or of a kind without rules
+							continue
+						}
+						metadata, _ := obj["metadata"].(map[string]any)
+						name, _ := metadata["name"].(string)
+
+						errs, err := validateCRDRules(rules.schema, obj, oldObj)
+						if err != nil {
+							t.Fatal(err)
+						}
+						switch {
+						case tc.valid && len(errs) > 0:
+							t.Errorf("%s %s is rejected by the rules of the CRD: %v", kind, name, errs.ToAggregate())
+						case !tc.valid && len(errs) == 0:
+							t.Errorf("%s %s is accepted by the rules of the CRD, but should be rejected", kind, name)
+						}
+
+						if len(rules.policy) == 0 {
+							// no policy is generated for the kind
+							continue
+						}
+						failures, err := validatePolicy(rules.policy, obj, oldObj)
+						if err != nil {
+							t.Fatal(err)
+						}
+						switch {
+						case tc.valid && len(failures) > 0:
+							t.Errorf("%s %s is rejected by the policy: %s", kind, name, strings.Join(failures, ", "))
+						case !tc.valid && len(failures) == 0:
+							t.Errorf("%s %s is accepted by the policy, but should be rejected", kind, name)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+// readFixtures reads the objects of the given YAML file.
+func readFixtures(file string) ([]map[string]any, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objs []map[string]any
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var obj map[string]any
+		if err := utilyaml.Unmarshal(doc, &obj); err != nil {
+			return nil, err
+		}
+		if len(obj) > 0 {
+			objs = append(objs, obj)
+		}
+	}
+}
+
+// validateCRDRules evaluates the x-kubernetes-validations of the given schema
+// against the given object, and the old object on updates, like the API
+// server does.
+func validateCRDRules(schemaJSON string, obj, oldObj map[string]any) (field.ErrorList, error) {
+	var v1Schema apiextensionsv1.JSONSchemaProps
+	if err := json.Unmarshal([]byte(schemaJSON), &v1Schema); err != nil {
+		return nil, err
+	}
+	var schema apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&v1Schema, &schema, nil); err != nil {
+		return nil, err
+	}
+	structural, err := structuralschema.NewStructural(&schema)
+	if err != nil {
+		return nil, err
+	}
+
+	validator := apiextensionscel.NewValidator(structural, true, celconfig.PerCallLimit)
+	if validator == nil {
+		return nil, nil
+	}
+	var old any
+	if oldObj != nil {
+		old = oldObj
+	}
+	errs, _ := validator.Validate(context.Background(), nil, structural, obj, old, celconfig.RuntimeCELCostBudget)
+	return errs, nil
+}
+
+// policyEnv returns the CEL environment of the validations of policies.
+var policyEnv = sync.OnceValues(func() (*cel.Env, error) {
+	envSet, err := environment.MustBaseEnvSet(environment.DefaultCompatibilityVersion()).Extend(environment.VersionedOptions{
+		IntroducedVersion: environment.DefaultCompatibilityVersion(),
+		EnvOptions: []cel.EnvOption{
+			cel.Variable("object", cel.DynType),
+			cel.Variable("oldObject", cel.DynType),
+			cel.Variable("request", cel.DynType),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return envSet.Env(environment.StoredExpressions)
+})
+
+// validatePolicy evaluates the given validations of a policy against the
+// given object, and the old object on updates, returning the messages of the
+// failed validations.
+func validatePolicy(validations []policyValidation, obj, oldObj map[string]any) ([]string, error) {
+	env, err := policyEnv()
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]any{
+		"object":    obj,
+		"oldObject": nil,
+		"request":   map[string]any{"operation": "CREATE"},
+	}
+	if oldObj != nil {
+		vars["oldObject"] = oldObj
+		vars["request"] = map[string]any{"operation": "UPDATE"}
+	}
+
+	var failures []string
+	for _, validation := range validations {
+		result, err := evaluate(env, validation.expression, vars)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		if result == types.True {
+			continue
+		}
+		message := validation.message
+		if validation.messageExpression != "" {
+			if result, err := evaluate(env, validation.messageExpression, vars); err == nil {
+				if text, ok := result.(types.String); ok {
+					message = string(text)
+				}
+			}
+		}
+		if message == "" {
+			message = fmt.Sprintf("failed expression: %s", validation.expression)
+		}
+		failures = append(failures, message)
+	}
+	return failures, nil
+}
+
+// evaluate compiles and evaluates the given CEL expression with the given
+// variables.
+func evaluate(env *cel.Env, expression string, vars map[string]any) (any, error) {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("compilation of %q failed: %w", expression, issues.Err())
+	}
+	program, err := env.Program(ast, cel.CostLimit(celconfig.PerCallLimit))
+	if err != nil {
+		return nil, fmt.Errorf("compilation of %q failed: %w", expression, err)
+	}
+	result, _, err := program.Eval(vars)
+	if err != nil {
+		return nil, fmt.Errorf("evaluation of %q failed: %w", expression, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	testdataDir  = "./testdata"
+	clientsetDir = "api/clientset"
+	listersDir   = "api/listers"
+	informersDir = "api/informers"
+)
+
+// readTree reads the files under the given directory of the given file
+// system, by path relative to that directory.
+func readTree(fsys fs.FS, dir string) map[string]string {
+	files := make(map[string]string)
+	Expect(fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		files[rel] = string(data)
+		return nil
+	})).To(Succeed())
+	return files
+}
+
+// expectGenerated checks that the given generated directory of the current
+// directory matches the one in the testdata, updating the testdata instead if
+// $UPDATE is set.
+func expectGenerated(originalCWD, dir string) {
+	fixturePath := filepath.Join(originalCWD, testdataDir, dir)
+	if os.Getenv("UPDATE") != "" {
+		Expect(os.RemoveAll(fixturePath)).To(Succeed())
+		Expect(os.CopyFS(fixturePath, os.DirFS(dir))).To(Succeed())
+	}
+
+	expected := readTree(os.DirFS(filepath.Join(originalCWD, testdataDir)), dir)
+	actual := readTree(os.DirFS("."), dir)
+	Expect(actual).To(HaveLen(len(expected)))
+	for name, content := range expected {
+		Expect(actual).To(HaveKeyWithValue(name, content), "Generated files should match the checked in files, diff found in %s", name)
+	}
+}
+
+var _ = Describe("Clientset generation from API types", func() {
+	var originalCWD string
+
+	BeforeEach(func() {
+		By("copying the testdata to a temporary directory")
+		tmpDir := GinkgoT().TempDir()
+		Expect(os.CopyFS(tmpDir, os.DirFS(testdataDir))).To(Succeed())
+
+		By("switching into the copy to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		originalCWD = cwd
+		DeferCleanup(os.Chdir, originalCWD)
+		Expect(os.Chdir(tmpDir)).To(Succeed()) // go modules are directory-sensitive
+	})
+
+	It("should generate a typed clientset for the kinds of all API versions", func() {
+		Expect(os.RemoveAll(clientsetDir)).To(Succeed())
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("client", markers.DescribesPackage, Generator{})))).To(Succeed())
+
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			"client:headerFile=" + path.Join(originalCWD, "../../hack/boilerplate/boilerplate.generatego.txt"),
+			"paths=./api/v1",
+			"paths=./api/v1alpha1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: genall.OutputToNothing}
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "Generator should run without errors")
+
+		By("comparing the generated clientset to the checked in one")
+		expectGenerated(originalCWD, clientsetDir)
+	})
+
+	It("should generate listers and informers for the kinds of all API versions", func() {
+		Expect(os.RemoveAll(listersDir)).To(Succeed())
+		Expect(os.RemoveAll(informersDir)).To(Succeed())
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("lister", markers.DescribesPackage, ListerGenerator{})))).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("informer", markers.DescribesPackage, InformerGenerator{})))).To(Succeed())
+
+		headerFile := path.Join(originalCWD, "../../hack/boilerplate/boilerplate.generatego.txt")
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			"lister:headerFile=" + headerFile,
+			"informer:headerFile=" + headerFile,
+			"paths=./api/v1",
+			"paths=./api/v1alpha1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: genall.OutputToNothing}
+
+		By("running the generators")
+		Expect(rt.Run()).To(BeFalse(), "Generators should run without errors")
+
+		By("comparing the generated listers and informers to the checked in ones")
+		expectGenerated(originalCWD, listersDir)
+		expectGenerated(originalCWD, informersDir)
+	})
+
+	It("should fail to generate listers for API packages without a Resource function", func() {
+		Expect(os.RemoveAll(listersDir)).To(Succeed())
+
+		By("removing the Resource function of one of the versions")
+		info, err := os.ReadFile("api/v1alpha1/groupversion_info.go")
+		Expect(err).NotTo(HaveOccurred())
+		info = []byte(strings.Replace(string(info), "func Resource(", "func resource(", 1))
+		Expect(os.WriteFile("api/v1alpha1/groupversion_info.go", info, 0o644)).To(Succeed())
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("lister", markers.DescribesPackage, ListerGenerator{})))).To(Succeed())
+
+		rt, err := genall.FromOptions(optionsRegistry, []string{"lister", "paths=./api/v1", "paths=./api/v1alpha1"})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: genall.OutputToNothing}
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeTrue(), "Generator should fail")
+		_, err = os.Stat(listersDir)
+		Expect(os.IsNotExist(err)).To(BeTrue(), "Nothing should be generated")
+	})
+
+	It("should fail if the apply configurations of the API versions are generated in different packages", func() {
+		Expect(os.RemoveAll(clientsetDir)).To(Succeed())
+
+		By("moving the apply configurations of one of the versions")
+		info, err := os.ReadFile("api/v1alpha1/groupversion_info.go")
+		Expect(err).NotTo(HaveOccurred())
+		info = []byte(strings.Replace(string(info), `"../applyconfiguration"`, `"applyconfiguration"`, 1))
+		Expect(os.WriteFile("api/v1alpha1/groupversion_info.go", info, 0o644)).To(Succeed())
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("client", markers.DescribesPackage, Generator{})))).To(Succeed())
+
+		rt, err := genall.FromOptions(optionsRegistry, []string{"client", "paths=./api/..."})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: genall.OutputToNothing}
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeTrue(), "Generator should fail")
+		_, err = os.Stat(clientsetDir)
+		Expect(os.IsNotExist(err)).To(BeTrue(), "Nothing should be generated")
+	})
+})
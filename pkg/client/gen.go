@@ -0,0 +1,123 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"slices"
+
+	"k8s.io/code-generator/cmd/client-gen/args"
+	"k8s.io/code-generator/cmd/client-gen/generators"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	defaultClientsetPackage = "clientset"
+	defaultListersPackage   = "listers"
+	defaultInformersPackage = "informers"
+	clientsetName           = "versioned"
+	objectMetaPkgPath       = "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates typed clientsets, in the style of client-gen, for the
+// kinds of the API packages.
+//
+// The clientset has a typed client for each kind (each type marked with
+// +kubebuilder:object:root that embeds ObjectMeta), with Get, List, Watch,
+// Create, Update, Delete and Patch methods, UpdateStatus for kinds with a
+// status subresource, and expansion interfaces for adding methods by hand.
+// If apply configurations are generated for the API packages (see the
+// applyconfiguration generator), the clients have Apply methods as well.
+//
+// Like with client-gen, a fake clientset is generated as well, in the
+// "versioned/fake" subpackage: a drop-in replacement of the clientset for unit
+// tests, backed by an object tracker, whose actions can be intercepted with
+// reactors.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+
+	// OutputPackage specifies the package to generate the clientset in,
+	// relative to the directory containing the API packages.  Defaults to
+	// "clientset", e.g. api/clientset for the packages api/v1 and api/v2.
+	//
+	// The clientset itself is generated in the "versioned" subpackage, and the
+	// typed clients in "versioned/typed/<group>/<version>".
+	OutputPackage string `marker:",optional"`
+
+	// FakeClientset indicates whether to generate a fake clientset, backed by
+	// an object tracker, for use in tests.  Defaults to true.
+	FakeClientset *bool `marker:",optional"`
+}
+
+// ExclusiveRun is true, since the clients are written by gengo, which can't
+// parse packages concurrently.
+func (Generator) ExclusiveRun() bool {
+	return true
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return registerMarkers(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	apis := findAPIPackages(ctx)
+	if len(apis.roots) == 0 {
+		return nil
+	}
+
+	headerFilePath, cleanup, err := ctx.GoHeaderFile(g.HeaderFile)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	arguments := args.New()
+	arguments.GoHeaderFile = headerFilePath
+	arguments.ClientsetName = clientsetName
+	arguments.FakeClient = g.FakeClientset == nil || *g.FakeClientset
+	arguments.PluralExceptions = apis.pluralExceptionList()
+	arguments.Groups = apis.groups
+	arguments.OutputDir, arguments.OutputPkg = apis.outputPackage(g.OutputPackage, defaultClientsetPackage)
+
+	// the Apply methods of all clients refer to a single package of apply
+	// configurations, so they're only generated if all kinds have them there
+	if len(apis.applyPkgs) > 0 {
+		if len(apis.applyPkgs) != len(apis.roots) || slices.ContainsFunc(apis.applyPkgs, func(applyPkg string) bool { return applyPkg != apis.applyPkgs[0] }) {
+			return fmt.Errorf("apply configurations must be generated in the same package for all API packages (see kubebuilder:ac:output:package), or for none of them")
+		}
+		arguments.ApplyConfigurationPackage = apis.applyPkgs[0]
+	}
+	if err := arguments.Validate(); err != nil {
+		return err
+	}
+
+	// The following code is based on the main function of client-gen.
+	c, err := apis.newContext(generators.NameSystems(apis.pluralExceptions), generators.DefaultNameSystem())
+	if err != nil {
+		return err
+	}
+	targets := generators.GetTargets(c, arguments)
+	if err := c.ExecuteTargets(targets); err != nil {
+		return fmt.Errorf("failed executing generator: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"path"
+
+	"k8s.io/code-generator/cmd/informer-gen/args"
+	"k8s.io/code-generator/cmd/informer-gen/generators"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// InformerGenerator generates shared informers, in the style of informer-gen,
+// for the kinds of the API packages (the same kinds the client generator
+// generates typed clients for).
+//
+// The informers use the clientset generated by the client generator, and the
+// listers generated by the lister generator, so those need to be generated as
+// well.
+type InformerGenerator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+
+	// OutputPackage specifies the package to generate the informers in,
+	// relative to the directory containing the API packages.  Defaults to
+	// "informers", e.g. api/informers for the packages api/v1 and api/v2.
+	//
+	// The shared informer factory is generated in the "externalversions"
+	// subpackage, and the informers in "externalversions/<group>/<version>".
+	OutputPackage string `marker:",optional"`
+
+	// ClientsetPackage specifies the package the clientset is generated in, as
+	// set with the outputPackage option of the client generator.  Defaults to
+	// "clientset".
+	ClientsetPackage string `marker:",optional"`
+
+	// ListersPackage specifies the package the listers are generated in, as
+	// set with the outputPackage option of the lister generator.  Defaults to
+	// "listers".
+	ListersPackage string `marker:",optional"`
+}
+
+// ExclusiveRun is true, since the informers are written by gengo, like the
+// clients.
+func (InformerGenerator) ExclusiveRun() bool {
+	return true
+}
+
+func (InformerGenerator) RegisterMarkers(into *markers.Registry) error {
+	return registerMarkers(into)
+}
+
+func (g InformerGenerator) Generate(ctx *genall.GenerationContext) error {
+	apis := findAPIPackages(ctx)
+	if len(apis.roots) == 0 {
+		return nil
+	}
+
+	headerFilePath, cleanup, err := ctx.GoHeaderFile(g.HeaderFile)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	arguments := args.New()
+	arguments.GoHeaderFile = headerFilePath
+	arguments.PluralExceptions = apis.pluralExceptionList()
+	arguments.OutputDir, arguments.OutputPkg = apis.outputPackage(g.OutputPackage, defaultInformersPackage)
+	_, clientsetPkg := apis.outputPackage(g.ClientsetPackage, defaultClientsetPackage)
+	arguments.VersionedClientSetPackage = path.Join(clientsetPkg, clientsetName)
+	_, arguments.ListersPackage = apis.outputPackage(g.ListersPackage, defaultListersPackage)
+	if err := arguments.Validate(); err != nil {
+		return err
+	}
+
+	// The following code is based on the main function of informer-gen.
+	c, err := apis.newContext(generators.NameSystems(apis.pluralExceptions), generators.DefaultNameSystem())
+	if err != nil {
+		return err
+	}
+	targets := generators.GetTargets(c, arguments)
+	if err := c.ExecuteTargets(targets); err != nil {
+		return fmt.Errorf("failed executing generator: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	"k8s.io/code-generator/cmd/lister-gen/args"
+	"k8s.io/code-generator/cmd/lister-gen/generators"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// ListerGenerator generates listers, in the style of lister-gen, for the kinds
+// of the API packages (the same kinds the client generator generates typed
+// clients for).
+//
+// The listers list and get objects from the indexers of shared informers
+// (see the informer generator), and have expansion interfaces for adding
+// methods by hand.
+//
+// Like with lister-gen, the API packages need a Resource function returning
+// the group-resource of the given resource, for the NotFound errors of the
+// listers.
+type ListerGenerator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+
+	// OutputPackage specifies the package to generate the listers in, relative
+	// to the directory containing the API packages.  Defaults to "listers",
+	// e.g. api/listers for the packages api/v1 and api/v2.
+	//
+	// The listers are generated in "<group>/<version>" subpackages.
+	OutputPackage string `marker:",optional"`
+}
+
+// ExclusiveRun is true, since the listers are written by gengo, like the
+// clients.
+func (ListerGenerator) ExclusiveRun() bool {
+	return true
+}
+
+func (ListerGenerator) RegisterMarkers(into *markers.Registry) error {
+	return registerMarkers(into)
+}
+
+func (g ListerGenerator) Generate(ctx *genall.GenerationContext) error {
+	apis := findAPIPackages(ctx)
+	if len(apis.roots) == 0 {
+		return nil
+	}
+
+	headerFilePath, cleanup, err := ctx.GoHeaderFile(g.HeaderFile)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	arguments := args.New()
+	arguments.GoHeaderFile = headerFilePath
+	arguments.PluralExceptions = apis.pluralExceptionList()
+	arguments.OutputDir, arguments.OutputPkg = apis.outputPackage(g.OutputPackage, defaultListersPackage)
+	if err := arguments.Validate(); err != nil {
+		return err
+	}
+
+	// The following code is based on the main function of lister-gen.
+	c, err := apis.newContext(generators.NameSystems(apis.pluralExceptions), generators.DefaultNameSystem())
+	if err != nil {
+		return err
+	}
+	for _, root := range apis.roots {
+		if c.Universe.Package(root.PkgPath).Functions["Resource"] == nil {
+			root.AddError(fmt.Errorf("package %s has no Resource function, which is needed by listers; add one like "+
+				"func Resource(resource string) schema.GroupResource { return GroupVersion.WithResource(resource).GroupResource() }", root.PkgPath))
+			return nil
+		}
+	}
+
+	targets := generators.GetTargets(c, arguments)
+	if err := c.ExecuteTargets(targets); err != nil {
+		return fmt.Errorf("failed executing generator: %w", err)
+	}
+	return nil
+}
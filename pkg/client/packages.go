@@ -0,0 +1,295 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	clientgentypes "k8s.io/code-generator/cmd/client-gen/types"
+	"k8s.io/gengo/v2"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/parser"
+	"k8s.io/gengo/v2/types"
+
+	"sigs.k8s.io/controller-tools/pkg/applyconfiguration"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+var isObjectMarker = markers.Must(markers.MakeDefinition("kubebuilder:object:root", markers.DescribesType, false))
+
+// registerMarkers registers the markers needed by the generators of this
+// package.
+func registerMarkers(into *markers.Registry) error {
+	if err := into.Register(isObjectMarker); err != nil {
+		return err
+	}
+	// needed to figure out the scopes, status subresources and plurals of
+	// kinds, and whether apply configurations are generated for them
+	if err := crdmarkers.Register(into); err != nil {
+		return err
+	}
+	return applyconfiguration.Generator{}.RegisterMarkers(into)
+}
+
+// clientKind is a kind to generate a typed client (and lister and informer)
+// for.
+type clientKind struct {
+	name          string
+	plural        string
+	clusterScoped bool
+	hasStatus     bool
+}
+
+// apiPackages are the API packages (those with a group name) among the roots
+// that have kinds, shared by the generators of this package so that they
+// agree on the kinds, and on how they're named.
+type apiPackages struct {
+	roots []*loader.Package
+	kinds map[string][]clientKind
+	// groups are the group-versions of the packages, for client-gen.
+	groups []clientgentypes.GroupVersions
+	// pluralExceptions are the plurals of the kinds, as used by the CRDs.
+	pluralExceptions map[string]string
+	// applyPkgs are the packages of the apply configurations of the
+	// packages, if generated.
+	applyPkgs []string
+	// fileTypes wraps the file types of gengo, for the files it writes to be
+	// written as the other files of the run.
+	fileTypes func(map[string]generator.FileType) map[string]generator.FileType
+}
+
+// findAPIPackages finds the API packages among the roots of the given
+// context, adding any errors to the roots.
+func findAPIPackages(ctx *genall.GenerationContext) apiPackages {
+	apis := apiPackages{
+		kinds:            make(map[string][]clientKind),
+		pluralExceptions: make(map[string]string),
+		fileTypes:        ctx.VerifiableFileTypes,
+	}
+	for _, root := range ctx.Roots {
+		pkgMarkers, err := markers.PackageMarkers(ctx.Collector, root)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		gv := crd.GroupVersionForPackage(pkgMarkers, root)
+		if gv.Empty() {
+			// not an API package
+			continue
+		}
+
+		kinds, err := findKinds(ctx.Collector, root)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		if len(kinds) == 0 {
+			continue
+		}
+		for _, kind := range kinds {
+			apis.pluralExceptions[kind.name] = kind.plural
+		}
+		apis.kinds[root.PkgPath] = kinds
+		apis.roots = append(apis.roots, root)
+
+		apis.groups = addGroupVersion(apis.groups, gv.Group, gv.Version, root.PkgPath)
+		if applyPkg, enabled := applyconfiguration.OutputPackage(ctx.Collector, root); enabled {
+			apis.applyPkgs = append(apis.applyPkgs, applyPkg)
+		}
+	}
+	return apis
+}
+
+// outputPackage returns the directory and import path of the given package,
+// relative to the directory containing the API packages (or of the given
+// default package, if empty).
+func (a apiPackages) outputPackage(rel, defaultRel string) (string, string) {
+	if rel == "" {
+		rel = defaultRel
+	}
+	baseDir, basePkg := commonParent(a.roots)
+	return filepath.Join(baseDir, filepath.FromSlash(rel)), path.Join(basePkg, rel)
+}
+
+// newContext loads the API packages for code-generator, with the given name
+// systems, and marks their kinds with the client-gen tags its generators
+// look for (like the applyconfiguration generator does).
+func (a apiPackages) newContext(nameSystems namer.NameSystems, defaultNameSystem string) (*generator.Context, error) {
+	pkgPaths := make([]string, 0, len(a.roots))
+	for _, root := range a.roots {
+		pkgPaths = append(pkgPaths, root.PkgPath)
+	}
+	slices.Sort(pkgPaths)
+	p := parser.NewWithOptions(parser.Options{BuildTags: []string{gengo.StdBuildTag}})
+	if err := p.LoadPackages(pkgPaths...); err != nil {
+		return nil, fmt.Errorf("failed making a parser: %w", err)
+	}
+	c, err := generator.NewContext(p, nameSystems, defaultNameSystem)
+	if err != nil {
+		return nil, fmt.Errorf("failed making a context: %w", err)
+	}
+	// the clients are written by gengo, so verify them like other artifacts
+	c.FileTypes = a.fileTypes(c.FileTypes)
+
+	for _, root := range a.roots {
+		pkg, ok := c.Universe[root.PkgPath]
+		if !ok {
+			return nil, fmt.Errorf("package %q not found in universe", root.Name)
+		}
+		// the lister and informer generators read the group from here
+		pkg.Comments = append(pkg.Comments, "+groupName="+a.groupOf(root.PkgPath))
+		for _, kind := range a.kinds[root.PkgPath] {
+			typ, ok := pkg.Types[kind.name]
+			if !ok || !hasObjectMeta(typ) {
+				continue
+			}
+			typ.CommentLines = append(typ.CommentLines, clientTags(typ, kind)...)
+		}
+	}
+	return c, nil
+}
+
+// pluralExceptionList returns the plurals of the kinds, in the
+// "<kind>:<plural>" form of the plural-exceptions flag of code-generator.
+func (a apiPackages) pluralExceptionList() []string {
+	res := make([]string, 0, len(a.pluralExceptions))
+	for kind, plural := range a.pluralExceptions {
+		res = append(res, kind+":"+plural)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// groupOf returns the group of the given API package.
+func (a apiPackages) groupOf(pkgPath string) string {
+	for _, group := range a.groups {
+		for _, version := range group.Versions {
+			if version.Package == pkgPath {
+				return string(group.Group)
+			}
+		}
+	}
+	return ""
+}
+
+// findKinds returns the kinds of the given package to generate typed clients
+// for.
+func findKinds(col *markers.Collector, root *loader.Package) ([]clientKind, error) {
+	var kinds []clientKind
+	err := markers.EachType(col, root, func(info *markers.TypeInfo) {
+		if isRoot, _ := info.Markers.Get(isObjectMarker.Name).(bool); !isRoot {
+			return
+		}
+		kind := clientKind{
+			name:      info.Name,
+			plural:    flect.Pluralize(info.Name),
+			hasStatus: info.Markers.Get("kubebuilder:subresource:status") != nil,
+		}
+		if resource, ok := info.Markers.Get("kubebuilder:resource").(crdmarkers.Resource); ok {
+			kind.clusterScoped = resource.Scope == string(apiextensionsv1.ClusterScoped)
+			if resource.Path != "" && !strings.EqualFold(resource.Path, kind.plural) {
+				kind.plural = resource.Path
+			}
+		}
+		kinds = append(kinds, kind)
+	})
+	return kinds, err
+}
+
+// clientTags returns the client-gen tags to add to the comments of the given
+// type, for generating a client for the given kind.
+func clientTags(typ *types.Type, kind clientKind) []string {
+	existing := make(map[string]bool)
+	for _, line := range append(slices.Clone(typ.SecondClosestCommentLines), typ.CommentLines...) {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	var tags []string
+	add := func(tag string) {
+		if !existing[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	add("+genclient")
+	if kind.clusterScoped {
+		add("+genclient:nonNamespaced")
+	}
+	if !kind.hasStatus {
+		// UpdateStatus can't work without the status subresource
+		add("+genclient:noStatus")
+	}
+	return tags
+}
+
+// hasObjectMeta checks if the given type embeds ObjectMeta, which is needed
+// for a client.
+func hasObjectMeta(typ *types.Type) bool {
+	for _, member := range typ.Members {
+		if member.Embedded && member.Type.Name == (types.Name{Package: objectMetaPkgPath, Name: "ObjectMeta"}) {
+			return true
+		}
+	}
+	return false
+}
+
+// addGroupVersion adds the given group-version, with its types in the given
+// package, to the given groups for client-gen.
+func addGroupVersion(groups []clientgentypes.GroupVersions, group, version, pkgPath string) []clientgentypes.GroupVersions {
+	packageVersion := clientgentypes.PackageVersion{Version: clientgentypes.Version(version), Package: pkgPath}
+	for i := range groups {
+		if groups[i].Group == clientgentypes.Group(group) {
+			groups[i].Versions = append(groups[i].Versions, packageVersion)
+			return groups
+		}
+	}
+	return append(groups, clientgentypes.GroupVersions{
+		PackageName: clientgentypes.Group(group).PackageName(),
+		Group:       clientgentypes.Group(group),
+		Versions:    []clientgentypes.PackageVersion{packageVersion},
+	})
+}
+
+// commonParent returns the deepest directory (and import path) containing the
+// given packages, excluding the packages themselves.
+func commonParent(roots []*loader.Package) (string, string) {
+	dir, pkgPath := filepath.Dir(roots[0].Dir), path.Dir(roots[0].PkgPath)
+	for _, root := range roots[1:] {
+		for !isWithin(filepath.Dir(root.Dir), dir, string(filepath.Separator)) {
+			dir = filepath.Dir(dir)
+		}
+		for !isWithin(path.Dir(root.PkgPath), pkgPath, "/") {
+			pkgPath = path.Dir(pkgPath)
+		}
+	}
+	return dir, pkgPath
+}
+
+// isWithin checks if the given path is the given parent, or within it.
+func isWithin(p, parent, separator string) bool {
+	return p == parent || strings.HasPrefix(p, strings.TrimSuffix(parent, separator)+separator)
+}
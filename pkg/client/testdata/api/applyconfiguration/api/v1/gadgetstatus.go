@@ -0,0 +1,41 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+// GadgetStatusApplyConfiguration represents a declarative configuration of the GadgetStatus type for use
+// with apply.
+//
+// GadgetStatus is the status of a Gadget.
+type GadgetStatusApplyConfiguration struct {
+	Phase *string `json:"phase,omitempty"`
+}
+
+// GadgetStatusApplyConfiguration constructs a declarative configuration of the GadgetStatus type for use with
+// apply.
+func GadgetStatus() *GadgetStatusApplyConfiguration {
+	return &GadgetStatusApplyConfiguration{}
+}
+
+// WithPhase sets the Phase field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Phase field is set to the value of the last call.
+func (b *GadgetStatusApplyConfiguration) WithPhase(value string) *GadgetStatusApplyConfiguration {
+	b.Phase = &value
+	return b
+}
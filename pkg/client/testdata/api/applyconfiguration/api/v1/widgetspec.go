@@ -0,0 +1,41 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+// WidgetSpecApplyConfiguration represents a declarative configuration of the WidgetSpec type for use
+// with apply.
+//
+// WidgetSpec is the spec of a Widget.
+type WidgetSpecApplyConfiguration struct {
+	Size *int32 `json:"size,omitempty"`
+}
+
+// WidgetSpecApplyConfiguration constructs a declarative configuration of the WidgetSpec type for use with
+// apply.
+func WidgetSpec() *WidgetSpecApplyConfiguration {
+	return &WidgetSpecApplyConfiguration{}
+}
+
+// WithSize sets the Size field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Size field is set to the value of the last call.
+func (b *WidgetSpecApplyConfiguration) WithSize(value int32) *WidgetSpecApplyConfiguration {
+	b.Size = &value
+	return b
+}
@@ -0,0 +1,46 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	managedfields "k8s.io/apimachinery/pkg/util/managedfields"
+	apiv1alpha1 "sigs.k8s.io/controller-tools/pkg/client/testdata/api/applyconfiguration/api/v1alpha1"
+	internal "sigs.k8s.io/controller-tools/pkg/client/testdata/api/applyconfiguration/internal"
+	v1alpha1 "sigs.k8s.io/controller-tools/pkg/client/testdata/api/v1alpha1"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=testdata.kubebuilder.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("Widget"):
+		return &apiv1alpha1.WidgetApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WidgetSpec"):
+		return &apiv1alpha1.WidgetSpecApplyConfiguration{}
+
+	}
+	return nil
+}
+
+func NewTypeConverter(scheme *runtime.Scheme) managedfields.TypeConverter {
+	return managedfields.NewSchemeTypeConverter(scheme, internal.Parser())
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WidgetSpec is the spec of a Widget.
+type WidgetSpec struct {
+	Size int32 `json:"size,omitempty"`
+}
+
+// WidgetStatus is the status of a Widget.
+type WidgetStatus struct {
+	Ready bool `json:"ready,omitempty"`
+}
+
+// Widget is a namespaced kind with a status subresource, so its client has
+// UpdateStatus and ApplyStatus methods.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:subresource:status
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// WidgetList contains a list of Widgets.
+// +kubebuilder:object:root=true
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
+
+// GadgetSpec is the spec of a Gadget.
+type GadgetSpec struct {
+	Owner string `json:"owner,omitempty"`
+}
+
+// GadgetStatus is the status of a Gadget.
+type GadgetStatus struct {
+	Phase string `json:"phase,omitempty"`
+}
+
+// Gadget is a cluster-scoped kind with an irregular plural, and without a
+// status subresource, so its client has no UpdateStatus method.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,path=gadgetry
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GadgetSpec   `json:"spec,omitempty"`
+	Status GadgetStatus `json:"status,omitempty"`
+}
+
+// GadgetList contains a list of Gadgets.
+// +kubebuilder:object:root=true
+type GadgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Gadget `json:"items"`
+}
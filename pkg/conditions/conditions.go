@@ -0,0 +1,271 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const metav1Path = "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// identifierRE matches the reasons that can be part of constant names.
+var identifierRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// conditionBlock is a constant block declaring the condition types of an
+// object.
+type conditionBlock struct {
+	pkg *loader.Package
+	// object is the type of the object.
+	object *types.Named
+	// field is the path to the conditions of the object.
+	field string
+	// typeName is the type of the condition types, "string" if untyped.
+	typeName   string
+	conditions []condition
+}
+
+// condition is a condition type declared by a constant.
+type condition struct {
+	name    string
+	value   string
+	reasons []string
+}
+
+// conditionBlocks returns the condition blocks of the given package, in
+// declaration order.  Invalid blocks are reported as errors of the package,
+// and left out.
+func conditionBlocks(registry *markers.Registry, pkg *loader.Package) []*conditionBlock {
+	var blocks []*conditionBlock
+	objects := make(map[*types.Named]bool)
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, isGenDecl := decl.(*ast.GenDecl)
+			if !isGenDecl || genDecl.Tok != token.CONST {
+				continue
+			}
+			objectVals, err := markerValues(registry, genDecl.Doc, objectMarker)
+			if err != nil {
+				pkg.AddError(err)
+				continue
+			}
+			if len(objectVals) == 0 {
+				continue
+			}
+			block, err := newConditionBlock(registry, pkg, genDecl, objectVals[0].(Object))
+			if err != nil {
+				pkg.AddError(err)
+				continue
+			}
+			if objects[block.object] {
+				pkg.AddError(loader.ErrFromNode(fmt.Errorf("condition types of %s declared by several constant blocks", block.object.Obj().Name()), genDecl))
+				continue
+			}
+			objects[block.object] = true
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// newConditionBlock returns the condition block of the given constant
+// declaration, with the given object marker.
+func newConditionBlock(registry *markers.Registry, pkg *loader.Package, genDecl *ast.GenDecl, object Object) (*conditionBlock, error) {
+	typeName, isTypeName := pkg.Types.Scope().Lookup(object.Object).(*types.TypeName)
+	if !isTypeName {
+		return nil, loader.ErrFromNode(fmt.Errorf("unknown object type %s", object.Object), genDecl)
+	}
+	named, isNamed := typeName.Type().(*types.Named)
+	if !isNamed {
+		return nil, loader.ErrFromNode(fmt.Errorf("object type %s must be a named struct type", object.Object), genDecl)
+	}
+	field := object.Field
+	if field == "" {
+		field = "Status.Conditions"
+	}
+	if err := checkConditionsField(pkg, named, field); err != nil {
+		return nil, loader.ErrFromNode(err, genDecl)
+	}
+
+	block := &conditionBlock{pkg: pkg, object: named, field: field}
+	for _, spec := range genDecl.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		doc := valueSpec.Doc
+		if genDecl.Lparen == token.NoPos {
+			// single constant, documented by the declaration
+			doc = genDecl.Doc
+		}
+		reasonVals, err := markerValues(registry, doc, reasonsMarker)
+		if err != nil {
+			return nil, err
+		}
+		var reasons []string
+		for _, reasonVal := range reasonVals {
+			reasons = append(reasons, reasonVal.(Reasons)...)
+		}
+		for _, reason := range reasons {
+			if !identifierRE.MatchString(reason) {
+				return nil, loader.ErrFromNode(fmt.Errorf("reason %q must be a valid Go identifier", reason), valueSpec)
+			}
+		}
+
+		for _, name := range valueSpec.Names {
+			constObj, isConst := pkg.TypesInfo.Defs[name].(*types.Const)
+			if !isConst || constObj.Val().Kind() != constant.String {
+				return nil, loader.ErrFromNode(fmt.Errorf("condition type %s must be a string constant", name.Name), valueSpec)
+			}
+			constType := "string"
+			if constNamed, isNamed := constObj.Type().(*types.Named); isNamed {
+				if constNamed.Obj().Pkg() != pkg.Types {
+					return nil, loader.ErrFromNode(fmt.Errorf("condition type %s must be of a type of the same package", name.Name), valueSpec)
+				}
+				constType = constNamed.Obj().Name()
+			}
+			if block.typeName != "" && block.typeName != constType {
+				return nil, loader.ErrFromNode(fmt.Errorf("condition types of %s must all be of the same type, got %s and %s", object.Object, block.typeName, constType), valueSpec)
+			}
+			block.typeName = constType
+			block.conditions = append(block.conditions, condition{
+				name:    name.Name,
+				value:   constant.StringVal(constObj.Val()),
+				reasons: reasons,
+			})
+		}
+	}
+	return block, nil
+}
+
+// checkConditionsField checks that the given path of fields of the given
+// object leads to []metav1.Condition, without going through pointers.
+func checkConditionsField(pkg *loader.Package, object *types.Named, path string) error {
+	var current types.Type = object
+	for part := range strings.SplitSeq(path, ".") {
+		fieldObj, _, _ := types.LookupFieldOrMethod(current, false, pkg.Types, part)
+		fieldVar, isVar := fieldObj.(*types.Var)
+		if !isVar || !fieldVar.IsField() {
+			return fmt.Errorf("object type %s has no field %s", object.Obj().Name(), path)
+		}
+		current = fieldVar.Type()
+		if _, isPointer := current.(*types.Pointer); isPointer {
+			return fmt.Errorf("conditions field %s of %s must not go through pointers", path, object.Obj().Name())
+		}
+	}
+	if slice, isSlice := current.(*types.Slice); isSlice {
+		if elem, isNamed := slice.Elem().(*types.Named); isNamed && elem.Obj().Name() == "Condition" && elem.Obj().Pkg() != nil && elem.Obj().Pkg().Path() == metav1Path {
+			return nil
+		}
+	}
+	return fmt.Errorf("conditions field %s of %s must be a []metav1.Condition, not %s", path, object.Obj().Name(), current)
+}
+
+// markerValues returns the values of the given marker in the given comments.
+func markerValues(registry *markers.Registry, doc *ast.CommentGroup, def *markers.Definition) ([]any, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	var values []any
+	for lines, text := range markers.MarkersIn(doc.List) {
+		if registry.Lookup(text, markers.DescribesType) != def {
+			continue
+		}
+		value, err := def.Parse(text)
+		if err != nil {
+			return nil, loader.ErrFromNode(err, lines[0])
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// hasMember checks if (a pointer to) the object already has a member (e.g. a
+// method) of the given name.
+func (b *conditionBlock) hasMember(name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(b.object), true, b.pkg.Types, name)
+	return obj != nil
+}
+
+// writeReasons writes the reason constants of the block, returning whether
+// any was written.
+func (b *conditionBlock) writeReasons(out *bytes.Buffer) bool {
+	objectName := b.object.Obj().Name()
+	wrote := false
+	for _, cond := range b.conditions {
+		if len(cond.reasons) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "// Reasons of the %s condition of %s.\nconst (\n", cond.value, objectName)
+		for _, reason := range cond.reasons {
+			fmt.Fprintf(out, "%[1]sReason%[2]s = %[2]q\n", cond.name, reason)
+		}
+		out.WriteString(")\n\n")
+		wrote = true
+	}
+	return wrote
+}
+
+// writeMethods writes the condition methods of the object, returning whether
+// any was written.
+func (b *conditionBlock) writeMethods(out *bytes.Buffer) bool {
+	objectName := b.object.Obj().Name()
+	conditionsExpr := "in." + b.field
+	typeExpr := "conditionType"
+	if b.typeName != "string" {
+		typeExpr = "string(conditionType)"
+	}
+
+	wrote := false
+	if !b.hasMember("SetCondition") {
+		fmt.Fprintf(out, `// SetCondition sets the given condition of the %[1]s, updating its last
+// transition time if its status changed, and returns whether it changed.
+func (in *%[1]s) SetCondition(condition metav1.Condition) bool {
+	return meta.SetStatusCondition(&%[2]s, condition)
+}
+
+`, objectName, conditionsExpr)
+		wrote = true
+	}
+	if !b.hasMember("GetCondition") {
+		fmt.Fprintf(out, `// GetCondition returns the condition of the given type of the %[1]s, or nil
+// if it isn't set.
+func (in *%[1]s) GetCondition(conditionType %[3]s) *metav1.Condition {
+	return meta.FindStatusCondition(%[2]s, %[4]s)
+}
+
+`, objectName, conditionsExpr, b.typeName, typeExpr)
+		wrote = true
+	}
+	if !b.hasMember("IsConditionTrue") {
+		fmt.Fprintf(out, `// IsConditionTrue checks if the condition of the given type of the %[1]s is
+// set and true.
+func (in *%[1]s) IsConditionTrue(conditionType %[3]s) bool {
+	return meta.IsStatusConditionTrue(%[2]s, %[4]s)
+}
+
+`, objectName, conditionsExpr, b.typeName, typeExpr)
+		wrote = true
+	}
+	return wrote
+}
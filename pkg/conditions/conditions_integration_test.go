@@ -0,0 +1,42 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/conditions"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+)
+
+var _ = Describe("Conditions Generation", func() {
+	headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+
+	It("should generate condition helpers and reasons from the markers of condition types", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{conditions.Generator{HeaderFile: headerFile}}, "./api/...")
+		golden.CompareFiles(GinkgoT(), "testdata", out, "api/v1/zz_generated.conditions.go")
+	})
+
+	It("should fail on objects without conditions, and with invalid reasons", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{conditions.Generator{}}, "./invalid/...")
+		Expect(errOut).To(ContainSubstring("object type Gizmo has no field Status.Conditions"))
+		Expect(errOut).To(ContainSubstring(`reason "Not Ready" must be a valid Go identifier`))
+	})
+})
@@ -0,0 +1,30 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conditions generates helpers for the conditions of objects, from
+// markers on the constant blocks declaring their condition types, e.g.:
+//
+//	// +kubebuilder:conditions:object=Widget
+//	const (
+//		// WidgetReady is true when all the widget pods are ready.
+//		// +kubebuilder:conditions:reasons={PodsReady,PodsPending}
+//		WidgetReady WidgetConditionType = "Ready"
+//	)
+//
+// generates SetCondition, GetCondition and IsConditionTrue methods on Widget,
+// working on its status conditions, as well as WidgetReadyReasonPodsReady and
+// WidgetReadyReasonPodsPending constants.
+package conditions
@@ -0,0 +1,157 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing methods, we only see hand-written ones.
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.conditions.go"
+
+// The markers describe constant blocks, which the collector doesn't handle,
+// so they're registered as type markers for the sake of help, and parsed from
+// the constant blocks by the generator.
+var (
+	objectMarker  = markers.Must(markers.MakeDefinition("kubebuilder:conditions", markers.DescribesType, Object{}))
+	reasonsMarker = markers.Must(markers.MakeDefinition("kubebuilder:conditions:reasons", markers.DescribesType, Reasons(nil)))
+)
+
+// +controllertools:marker:generateHelp:category=conditions
+
+// Object marks a constant block as declaring the condition types of an object.
+//
+// It must be set on the block itself, e.g. +kubebuilder:conditions:object=Widget,
+// and generates SetCondition, GetCondition and IsConditionTrue methods on the
+// object.  When the constants have a (string) type, the methods take
+// condition types of that type.
+type Object struct {
+	// Object is the name of the object type, in the same package.
+	Object string
+	// Field is the path to the []metav1.Condition field of the object
+	// (defaults to Status.Conditions).
+	Field string `marker:",optional"`
+}
+
+// +controllertools:marker:generateHelp:category=conditions
+
+// Reasons lists the reasons of a condition type.
+//
+// It must be set on a constant of a block marked with
+// +kubebuilder:conditions:object, and generates a <Constant>Reason<Reason>
+// constant for each reason, so reasons must be valid Go identifiers.
+type Reasons []string
+
+// +controllertools:marker:generateHelp
+
+// Generator generates helpers for the conditions of objects.
+//
+// The helpers of the objects of each package are written to
+// zz_generated.conditions.go.  Methods that already exist are not generated.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return func(node ast.Node) bool {
+		// ignore interfaces
+		_, isIface := node.(*ast.InterfaceType)
+		return !isIface
+	}
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := markers.RegisterAll(into, objectMarker, reasonsMarker); err != nil {
+		return err
+	}
+	into.AddHelp(objectMarker, Object{}.Help())
+	into.AddHelp(reasonsMarker, Reasons(nil).Help())
+	return nil
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range ctx.Roots {
+		ctx.Checker.Check(root)
+		root.NeedTypesInfo()
+
+		blocks := conditionBlocks(ctx.Collector.Registry, root)
+		if len(blocks) == 0 {
+			continue
+		}
+		reasons, methods := new(bytes.Buffer), new(bytes.Buffer)
+		hasReasons, hasMethods := false, false
+		for _, block := range blocks {
+			hasReasons = block.writeReasons(reasons) || hasReasons
+			hasMethods = block.writeMethods(methods) || hasMethods
+		}
+		if !hasReasons && !hasMethods {
+			continue
+		}
+		reasons.Write(methods.Bytes())
+		gogen.WriteOut(ctx, root, outputFileName, fileContents(root, headerText, hasMethods, reasons.Bytes()))
+	}
+
+	return nil
+}
+
+// fileContents returns the formatted contents of the file of the given
+// package, with the given helpers, importing the meta helpers if they're
+// used.
+func fileContents(pkg *loader.Package, headerText string, usesMeta bool, helpers []byte) []byte {
+	var importsBlock string
+	if usesMeta {
+		importsBlock = `import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+`
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+%[3]s
+`, pkg.Name, headerText, importsBlock)
+	outContent.Write(helpers)
+
+	return gogen.Format(pkg, outContent.Bytes())
+}
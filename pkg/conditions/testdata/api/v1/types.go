@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WidgetConditionType is a type of condition of widgets.
+type WidgetConditionType string
+
+// +kubebuilder:conditions:object=Widget
+const (
+	// WidgetReady is true when all the widget pods are ready.
+	// +kubebuilder:conditions:reasons={PodsReady,PodsPending}
+	WidgetReady WidgetConditionType = "Ready"
+
+	// WidgetDegraded is true when some widget pods are failing.
+	// +kubebuilder:conditions:reasons=CrashLooping
+	WidgetDegraded WidgetConditionType = "Degraded"
+
+	// WidgetPaused is true when the widget is paused.
+	WidgetPaused WidgetConditionType = "Paused"
+)
+
+// WidgetStatus defines the observed state of a widget.
+type WidgetStatus struct {
+	// Conditions are the conditions of the widget.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Widget is an app made of widgets.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// GadgetAvailable is true when the gadget can be used.
+// +kubebuilder:conditions:object=Gadget,field=Conditions
+// +kubebuilder:conditions:reasons=Installed
+const GadgetAvailable = "Available"
+
+// Gadget is a part of widgets, with its own conditions.
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SetCondition sets the given condition of the gadget, keeping its last
+// transition time.
+func (in *Gadget) SetCondition(condition metav1.Condition) bool {
+	for i := range in.Conditions {
+		if in.Conditions[i].Type == condition.Type {
+			condition.LastTransitionTime = in.Conditions[i].LastTransitionTime
+			in.Conditions[i] = condition
+			return true
+		}
+	}
+	in.Conditions = append(in.Conditions, condition)
+	return true
+}
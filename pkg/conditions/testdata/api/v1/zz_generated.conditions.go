@@ -0,0 +1,72 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the Ready condition of Widget.
+const (
+	WidgetReadyReasonPodsReady   = "PodsReady"
+	WidgetReadyReasonPodsPending = "PodsPending"
+)
+
+// Reasons of the Degraded condition of Widget.
+const (
+	WidgetDegradedReasonCrashLooping = "CrashLooping"
+)
+
+// Reasons of the Available condition of Gadget.
+const (
+	GadgetAvailableReasonInstalled = "Installed"
+)
+
+// SetCondition sets the given condition of the Widget, updating its last
+// transition time if its status changed, and returns whether it changed.
+func (in *Widget) SetCondition(condition metav1.Condition) bool {
+	return meta.SetStatusCondition(&in.Status.Conditions, condition)
+}
+
+// GetCondition returns the condition of the given type of the Widget, or nil
+// if it isn't set.
+func (in *Widget) GetCondition(conditionType WidgetConditionType) *metav1.Condition {
+	return meta.FindStatusCondition(in.Status.Conditions, string(conditionType))
+}
+
+// IsConditionTrue checks if the condition of the given type of the Widget is
+// set and true.
+func (in *Widget) IsConditionTrue(conditionType WidgetConditionType) bool {
+	return meta.IsStatusConditionTrue(in.Status.Conditions, string(conditionType))
+}
+
+// GetCondition returns the condition of the given type of the Gadget, or nil
+// if it isn't set.
+func (in *Gadget) GetCondition(conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(in.Conditions, conditionType)
+}
+
+// IsConditionTrue checks if the condition of the given type of the Gadget is
+// set and true.
+func (in *Gadget) IsConditionTrue(conditionType string) bool {
+	return meta.IsStatusConditionTrue(in.Conditions, conditionType)
+}
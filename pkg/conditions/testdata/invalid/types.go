@@ -0,0 +1,45 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package invalid
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:conditions:object=Gizmo
+const (
+	// GizmoReady is true when the gizmo is ready.
+	GizmoReady = "Ready"
+)
+
+// Gizmo has no status conditions.
+type Gizmo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// +kubebuilder:conditions:object=Thing,field=Conditions
+const (
+	// ThingReady is true when the thing is ready.
+	// +kubebuilder:conditions:reasons={"Not Ready"}
+	ThingReady = "Ready"
+)
+
+// Thing has conditions, with an invalid reason.
+type Thing struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
@@ -0,0 +1,253 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+)
+
+// applyRequest is a server-side apply request received by a fake API server.
+type applyRequest struct {
+	Path         string
+	FieldManager string
+	Force        string
+	ContentType  string
+	Kind         string
+}
+
+var _ = Describe("Applying the manifests to a cluster", func() {
+	var (
+		kubeconfig string
+		overlay    map[string][]byte
+
+		requestsMu sync.Mutex
+		requests   []applyRequest
+	)
+
+	// discovery are the resources served by the fake API server, by the
+	// path of their group version.
+	discovery := map[string]any{
+		"/api": metav1.APIVersions{
+			TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+			Versions: []string{"v1"},
+		},
+		"/apis": metav1.APIGroupList{
+			TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"},
+			Groups: []metav1.APIGroup{{
+				Name:             "apiextensions.k8s.io",
+				Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "apiextensions.k8s.io/v1", Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apiextensions.k8s.io/v1", Version: "v1"},
+			}, {
+				Name:             "rbac.authorization.k8s.io",
+				Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "rbac.authorization.k8s.io/v1", Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "rbac.authorization.k8s.io/v1", Version: "v1"},
+			}},
+		},
+		"/api/v1": metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"get", "patch"}}},
+		},
+		"/apis/apiextensions.k8s.io/v1": metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+			GroupVersion: "apiextensions.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition", Verbs: metav1.Verbs{"get", "patch"}}},
+		},
+		"/apis/rbac.authorization.k8s.io/v1": metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusterroles", Kind: "ClusterRole", Verbs: metav1.Verbs{"get", "patch"}},
+				{Name: "roles", Kind: "Role", Namespaced: true, Verbs: metav1.Verbs{"get", "patch"}},
+			},
+		},
+	}
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+
+		testdata, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		overlay = map[string][]byte{
+			filepath.Join(testdata, "api", "v1", "markers.go"): []byte(`package v1
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get,namespace=system
+`),
+		}
+
+		By("starting an API server recording the applied objects")
+		requests = nil
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method == http.MethodGet {
+				resources, found := discovery[r.URL.Path]
+				if !found {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(resources)).To(Succeed())
+				return
+			}
+
+			Expect(r.Method).To(Equal(http.MethodPatch))
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			var obj struct{ Kind string }
+			Expect(json.Unmarshal(body, &obj)).To(Succeed())
+			requestsMu.Lock()
+			requests = append(requests, applyRequest{
+				Path:         r.URL.Path,
+				FieldManager: r.URL.Query().Get("fieldManager"),
+				Force:        r.URL.Query().Get("force"),
+				ContentType:  r.Header.Get("Content-Type"),
+				Kind:         obj.Kind,
+			})
+			requestsMu.Unlock()
+			_, err = w.Write(body)
+			Expect(err).NotTo(HaveOccurred())
+		}))
+		DeferCleanup(server.Close)
+
+		kubeconfig = filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+		Expect(os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+- name: other
+  context:
+    cluster: other
+    user: test
+current-context: other
+users:
+- name: test
+  user: {}
+`, server.URL)), 0o600)).To(Succeed())
+	})
+
+	// applied returns the paths applied to so far.
+	applied := func() []string {
+		requestsMu.Lock()
+		defer requestsMu.Unlock()
+		var paths []string
+		for _, request := range requests {
+			paths = append(paths, request.Path)
+		}
+		return paths
+	}
+
+	It("should server-side apply the manifests in install order, with the field manager of controller-gen", func() {
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("rbac:roleName=manager-role", "crd", "output:apply"),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithOverlay(overlay),
+			controllergen.WithCluster(genall.ClusterConfig{Kubeconfig: kubeconfig, Context: "test"}),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+
+		Expect(applied()).To(Equal([]string{
+			"/apis/apiextensions.k8s.io/v1/customresourcedefinitions/gizmoes.testdata.kubebuilder.io",
+			"/apis/apiextensions.k8s.io/v1/customresourcedefinitions/widgets.testdata.kubebuilder.io",
+			"/apis/rbac.authorization.k8s.io/v1/clusterroles/manager-role",
+			"/apis/rbac.authorization.k8s.io/v1/namespaces/system/roles/manager-role",
+		}))
+		for _, request := range requests {
+			Expect(request.ContentType).To(Equal("application/apply-patch+yaml"))
+			Expect(request.FieldManager).To(Equal("controller-gen"))
+			Expect(request.Force).To(Equal("true"), "the fields managed by others should be taken over")
+		}
+		Expect("config").NotTo(BeADirectory(), "nothing should be written")
+	})
+
+	It("should apply with the field manager and context of the output rule", func() {
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "output:crd:apply:kubeconfig="+kubeconfig+",context=test,fieldManager=dev"),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+		Expect(applied()).To(HaveLen(2))
+		for _, request := range requests {
+			Expect(request.Kind).To(Equal("CustomResourceDefinition"))
+			Expect(request.FieldManager).To(Equal("dev"))
+		}
+	})
+
+	It("should fail to apply to unreachable clusters", func() {
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "output:apply"),
+			controllergen.WithPaths("./api/..."),
+			// the current context's cluster doesn't exist
+			controllergen.WithCluster(genall.ClusterConfig{Kubeconfig: kubeconfig}),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(MatchError(controllergen.ErrGenerationFailed))
+		Expect(errOut.String()).To(ContainSubstring("unable to load the configuration of the cluster"))
+		Expect(applied()).To(BeEmpty())
+	})
+
+	It("should not apply anything when a generator fails", func() {
+		var errOut bytes.Buffer
+		rt, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("crd", "output:apply"),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithCluster(genall.ClusterConfig{Kubeconfig: kubeconfig, Context: "test"}),
+			controllergen.WithErrorWriter(&errOut),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		var failing genall.Generator = probeGenerator{concurrency: &concurrency{}, err: errors.New("generator failed")}
+		rt.Generators = append(rt.Generators, &failing)
+		Expect(rt.Run()).To(BeTrue())
+		Expect(errOut.String()).To(ContainSubstring("generator failed"))
+		Expect(applied()).To(BeEmpty())
+	})
+
+	It("should refuse to apply other files than manifests", func() {
+		_, err := genall.OutputToCluster{}.Open(nil, "zz_generated.deepcopy.go")
+		Expect(err).To(MatchError("cannot apply zz_generated.deepcopy.go to a cluster, as it's not a YAML manifest"))
+	})
+})
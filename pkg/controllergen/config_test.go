@@ -0,0 +1,150 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+)
+
+var _ = Describe("Configuration files", func() {
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	// loadConfig writes the given configuration file and loads it.
+	loadConfig := func(contents string) (*genall.Config, error) {
+		path := filepath.Join(GinkgoT().TempDir(), genall.ConfigFileName)
+		Expect(os.WriteFile(path, []byte(contents), 0o644)).To(Succeed())
+		return genall.LoadConfig(path)
+	}
+
+	It("should run the generators it declares, as with the equivalent options", func() {
+		crdDir, objectDir := GinkgoT().TempDir(), GinkgoT().TempDir()
+		config, err := loadConfig(`paths:
+- ./api/v1
+generators:
+  object: {}
+  crd:
+    maxDescLen: 0
+    only: [testdata.kubebuilder.io/v1/Widget]
+output:
+  crd:
+    dir: ` + crdDir + `
+  object:
+    dir: ` + objectDir + `
+`)
+		Expect(err).NotTo(HaveOccurred())
+		options, err := config.Options()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(options).To(Equal([]string{
+			`paths={"./api/v1"}`,
+			"crd:maxDescLen=0",
+			`crd:only={"testdata.kubebuilder.io/v1/Widget"}`,
+			"object",
+			`output:crd:dir="` + crdDir + `"`,
+			`output:object:dir="` + objectDir + `"`,
+		}))
+
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions(options...),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+
+		By("generating the same artifacts as the command line options")
+		outDir := GinkgoT().TempDir()
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("object", "crd:maxDescLen=0", "output:dir="+outDir),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+		for dir, name := range map[string]string{objectDir: "zz_generated.deepcopy.go", crdDir: "testdata.kubebuilder.io_widgets.yaml"} {
+			expected, err := os.ReadFile(filepath.Join(outDir, name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(filepath.Join(dir, name))).To(Equal(expected))
+		}
+		Expect(os.ReadDir(crdDir)).To(HaveLen(1), "the crd generator should only generate the Widget CRD")
+	})
+
+	It("should have its options overridden by the given ones", func() {
+		config, err := loadConfig(`paths: [./api/...]
+generators:
+  crd:
+    maxDescLen: 0
+  rbac:
+    roleName: manager-role
+output:
+  default: none
+  crd: stdout
+`)
+		Expect(err).NotTo(HaveOccurred())
+		options, err := config.Options()
+		Expect(err).NotTo(HaveOccurred())
+
+		overridden, err := genall.OverrideOptions(controllergen.OptionsRegistry(), options, []string{"crd", "output:crd:dir=out", "paths=./api/v1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overridden).To(Equal([]string{
+			`rbac:roleName="manager-role"`,
+			"output:none",
+			"crd",
+			"output:crd:dir=out",
+			"paths=./api/v1",
+		}))
+
+		_, err = genall.OverrideOptions(controllergen.OptionsRegistry(), options, []string{"nope"})
+		Expect(err).To(MatchError(`unknown option "nope"`))
+	})
+
+	It("should refuse invalid configuration files", func() {
+		By("refusing unknown fields")
+		_, err := loadConfig("generator:\n  crd: {}\n")
+		Expect(err).To(MatchError(And(ContainSubstring("unable to parse configuration file"), ContainSubstring(`unknown field "generator"`))))
+
+		By("refusing invalid YAML")
+		_, err = loadConfig("paths: [\n")
+		Expect(err).To(MatchError(ContainSubstring("unable to parse configuration file")))
+
+		By("refusing several output rules for a generator")
+		config, err := loadConfig("output:\n  crd:\n    dir: out\n    stdout: {}\n")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = config.Options()
+		Expect(err).To(MatchError(`invalid output rule of "crd": expected a single output rule, got 2`))
+
+		By("refusing options without values")
+		config, err = loadConfig("generators:\n  crd:\n    maxDescLen:\n")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = config.Options()
+		Expect(err).To(MatchError(`invalid options of generator "crd": argument "maxDescLen" has no value`))
+
+		By("failing on missing files")
+		_, err = genall.LoadConfig(filepath.Join(GinkgoT().TempDir(), genall.ConfigFileName))
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
@@ -0,0 +1,143 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// TypeSchema is the OpenAPI v3 schema of a type, as returned by Schemata.
+type TypeSchema struct {
+	// PkgPath is the import path of the package of the type.
+	PkgPath string
+	// Name is the name of the type.
+	Name string
+	// Schema is the flattened schema of the type, as it's embedded in
+	// CustomResourceDefinitions.
+	Schema apiextensionsv1.JSONSchemaProps
+}
+
+// CRDs returns the v1 CustomResourceDefinitions the crd generator of the
+// given options generates, in memory instead of writing them (nor validating
+// them on a server).  The crd generator is run with its defaults unless the
+// options set it (e.g. WithOptions("crd:maxDescLen=0")), and the other
+// generators and output rules of the options are ignored.  The errors of
+// the packages are returned along with ErrGenerationFailed.
+func CRDs(ctx context.Context, opts ...Option) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	gen, genCtx, err := newCRDGeneration(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	crds, err := gen.CustomResourceDefinitions(genCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := packageErrors(genCtx.Roots); err != nil {
+		return nil, err
+	}
+	return crds, nil
+}
+
+// Schemata returns the OpenAPI v3 schemata of the types of the API packages
+// of the given options, as the crd generator of the options generates them
+// (see CRDs), sorted by package and name.  The errors of the packages are
+// returned along with ErrGenerationFailed.
+func Schemata(ctx context.Context, opts ...Option) ([]TypeSchema, error) {
+	gen, genCtx, err := newCRDGeneration(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	schemata, err := gen.Schemata(genCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := packageErrors(genCtx.Roots); err != nil {
+		return nil, err
+	}
+
+	res := make([]TypeSchema, 0, len(schemata))
+	for typ, schema := range schemata {
+		res = append(res, TypeSchema{PkgPath: typ.Package.PkgPath, Name: typ.Name, Schema: schema})
+	}
+	slices.SortFunc(res, func(a, b TypeSchema) int {
+		return cmp.Or(strings.Compare(a.PkgPath, b.PkgPath), strings.Compare(a.Name, b.Name))
+	})
+	return res, nil
+}
+
+// newCRDGeneration loads the packages of the given options, returning their
+// crd generator (adding one if they have none) and the context to run it
+// with, as the runtime would.
+func newCRDGeneration(ctx context.Context, opts []Option) (crd.Generator, *genall.GenerationContext, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !slices.ContainsFunc(o.Options, isCRDGeneratorOption) {
+		opts = append(opts, WithOptions("crd"))
+	}
+	rt, err := NewRuntime(ctx, opts...)
+	if err != nil {
+		return crd.Generator{}, nil, err
+	}
+
+	for _, gen := range rt.Generators {
+		crdGen, isCRD := (*gen).(crd.Generator)
+		if !isCRD {
+			continue
+		}
+		genCtx := rt.GenerationContext // make a shallow copy
+		genCtx.Kinds = rt.KindFilters[gen]
+		genCtx.GeneratorParallelism = rt.Parallelism
+		genCtx.ModuleVersions = genall.ResolveModuleVersions(rt.Roots, rt.Generators)
+		return crdGen, &genCtx, nil
+	}
+	return crd.Generator{}, nil, errors.New("no crd generator specified")
+}
+
+// isCRDGeneratorOption returns true if the given option is the crd generator,
+// with or without arguments.
+func isCRDGeneratorOption(option string) bool {
+	name, args, _ := strings.Cut(option, ":")
+	return name == "crd" && !strings.HasPrefix(args, "only")
+}
+
+// packageErrors returns the errors of the given packages (except type errors,
+// as when running the generators) along with ErrGenerationFailed, if any.
+func packageErrors(roots []*loader.Package) error {
+	errs := []error{ErrGenerationFailed}
+	for _, diag := range loader.Diagnostics(roots, packages.TypeError) {
+		if diag.Severity == loader.SeverityError {
+			errs = append(errs, errors.New(diag.String()))
+		}
+	}
+	if len(errs) == 1 {
+		return nil
+	}
+	return errors.Join(errs...)
+}
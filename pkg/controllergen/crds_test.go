@@ -0,0 +1,97 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+)
+
+var _ = Describe("Generating in memory", func() {
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	It("should return the CRDs the crd generator writes", func() {
+		outDir := GinkgoT().TempDir()
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "output:crd:dir="+outDir),
+			controllergen.WithPaths("./api/..."),
+		)).To(Succeed())
+
+		crds, err := controllergen.CRDs(context.Background(), controllergen.WithPaths("./api/..."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crds).To(HaveLen(2))
+		for _, crd := range crds {
+			contents, err := os.ReadFile(filepath.Join(outDir, crd.Spec.Group+"_"+crd.Spec.Names.Plural+".yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			var written apiextensionsv1.CustomResourceDefinition
+			Expect(yaml.Unmarshal(contents, &written)).To(Succeed())
+			crd.Status = apiextensionsv1.CustomResourceDefinitionStatus{} // not written
+			Expect(crd).To(Equal(written))
+		}
+	})
+
+	It("should run the crd generator with the arguments of the options", func() {
+		crds, err := controllergen.CRDs(context.Background(),
+			controllergen.WithOptions("object", "crd:maxDescLen=0", "crd:only=testdata.kubebuilder.io/v1/Widget"),
+			controllergen.WithPaths("./api/v1"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crds).To(ConsistOf(HaveField("Spec.Names.Kind", "Widget")))
+		Expect(crds[0].Spec.Versions[0].Schema.OpenAPIV3Schema.Description).To(BeEmpty())
+	})
+
+	It("should return the schemata of the types of the API packages", func() {
+		schemata, err := controllergen.Schemata(context.Background(), controllergen.WithPaths("./api/v1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schemata).To(ContainElement(SatisfyAll(
+			HaveField("PkgPath", "testdata.kubebuilder.io/docs/api/v1"),
+			HaveField("Name", "WidgetStatus"),
+			HaveField("Schema.Description", "WidgetStatus is the status of a Widget."),
+			HaveField("Schema.Properties", HaveKey("lastUpdated")))))
+		Expect(slices.IsSortedFunc(schemata, func(a, b controllergen.TypeSchema) int {
+			return cmp.Or(strings.Compare(a.PkgPath, b.PkgPath), strings.Compare(a.Name, b.Name))
+		})).To(BeTrue())
+	})
+
+	It("should return the errors of the packages", func() {
+		_, err := controllergen.CRDs(context.Background(),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithOverlay(map[string][]byte{
+				mustAbs("api/v1/broken.go"): []byte("package v1\n\ntype BrokenSpec struct {\n\t// +kubebuilder:validation:MinLength=three\n\tName string `json:\"name\"`\n}\n"),
+			}),
+		)
+		Expect(err).To(MatchError(controllergen.ErrGenerationFailed))
+		Expect(err).To(MatchError(ContainSubstring("broken.go")))
+	})
+})
@@ -0,0 +1,69 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllergen is the supported API for running the generators of
+// controller-gen from other tools, instead of shelling out to the binary or
+// reaching into its internals.
+//
+// # Running
+//
+// Run runs generators the way the controller-gen command does, configured by
+// Option functions:
+//
+//	err := controllergen.Run(ctx,
+//		controllergen.WithOptions("crd", "object", "output:crd:dir=config/crd/bases"),
+//		controllergen.WithPaths("./api/..."),
+//	)
+//
+// The generators, output rules and their arguments are given in the same
+// syntax as on the command line, so that anything controller-gen can do is
+// available.  NewRuntime returns the genall.Runtime that Run runs instead,
+// for finer control.
+//
+// # Generating in memory
+//
+// CRDs and Schemata run the crd generator of the options, returning the
+// CustomResourceDefinitions and the OpenAPI v3 schemata of the types as
+// typed objects instead of writing them, e.g. to install the CRDs of the
+// API packages of an operator from its tests:
+//
+//	crds, err := controllergen.CRDs(ctx, controllergen.WithPaths("./api/..."))
+//
+// # Generators
+//
+// Generators and OutputRules return the generators and output rules known
+// to controller-gen, by the names used in options, and OptionsRegistry the
+// registry parsing those options.
+//
+// # Concurrency
+//
+// Each run keeps its own state: the files it verifies or plans, the layout
+// and line endings of the files it writes, and the artifacts it holds until
+// its end (bundles, archives, standard-out and manifests to apply).  Run,
+// CRDs and Schemata may thus be called concurrently (e.g. from parallel
+// tests), as long as the runs don't write the same files.  The generators
+// relying on global state (see genall.NeedsExclusiveRun) still run one at a
+// time across runs, and the paths are loaded from the working directory of
+// the process, which the runs share.
+//
+// # Compatibility
+//
+// The exported API of this package only changes in backwards-compatible
+// ways: new Option functions and fields of Options may be added, but
+// existing ones keep their meaning, and runs stay safe to run concurrently.
+// Generators and output rules are only removed after being deprecated for a
+// release.
+package controllergen
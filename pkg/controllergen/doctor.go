@@ -0,0 +1,397 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goversion "go/version"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/apiserver"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// CheckStatus is the outcome of a check of Doctor.
+type CheckStatus string
+
+const (
+	// CheckPassed is the status of the checks that found no problem.
+	CheckPassed CheckStatus = "ok"
+	// CheckWarning is the status of the checks that found problems that
+	// may make some runs fail, or generate unexpected artifacts.
+	CheckWarning CheckStatus = "warning"
+	// CheckFailed is the status of the checks that found problems making
+	// the run fail.
+	CheckFailed CheckStatus = "failed"
+	// CheckSkipped is the status of the checks that aren't needed by the
+	// run, or that depend on a check that failed.
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// CheckResult is the result of a check of Doctor.
+type CheckResult struct {
+	// Check is what was checked, e.g. "go" or "output".
+	Check string `json:"check"`
+	// Status is the outcome of the check.
+	Status CheckStatus `json:"status"`
+	// Message describes what was found.
+	Message string `json:"message"`
+	// Hint explains the likely cause of the problem found, and how to fix
+	// it, if known.
+	Hint string `json:"hint,omitempty"`
+}
+
+// Doctor checks that the environment can run the generators of the given
+// options, instead of running them, so that problems with the environment
+// are reported with their likely causes, rather than as the errors of the
+// go command they end up as.  It checks, in order:
+//
+//   - go: that the go command can be run, in a module, and is no newer than
+//     the Go controller-gen was built with;
+//   - packages: that the packages can be loaded, e.g. that no dependency is
+//     missing from go.mod or go.sum;
+//   - dependencies: that the dependencies are recent enough for the
+//     artifacts of the generators (see genall.RequiresModules);
+//   - envtest: that the envtest binaries are found, if a generator starts an
+//     API server (see genall.NeedsAPIServer);
+//   - output: that the output directories of the generators can be written
+//     to.
+func Doctor(ctx context.Context, opts ...Option) []CheckResult {
+	results := []CheckResult{checkGo(ctx)}
+
+	rt, err := NewRuntime(ctx, opts...)
+	if err != nil {
+		results = append(results, CheckResult{Check: "packages", Status: CheckFailed, Message: err.Error(), Hint: explain(err.Error())})
+		for _, check := range []string{"dependencies", "envtest", "output"} {
+			results = append(results, CheckResult{Check: check, Status: CheckSkipped, Message: "the packages couldn't be loaded"})
+		}
+		return results
+	}
+	return append(results,
+		checkPackages(rt),
+		checkDependencies(rt),
+		checkEnvtest(rt),
+		checkOutput(rt),
+	)
+}
+
+// checkGo checks the go command that packages are loaded with.
+func checkGo(ctx context.Context) CheckResult {
+	res := CheckResult{Check: "go"}
+	cmd := exec.CommandContext(ctx, "go", "env", "-json", "GOVERSION", "GOMOD")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		res.Status = CheckFailed
+		res.Message = "the go command isn't in $PATH"
+		res.Hint = "packages are loaded with the go command: install Go (see https://go.dev/dl), or add the bin directory of its installation to $PATH"
+		return res
+	}
+	if err != nil {
+		res.Status = CheckFailed
+		res.Message = fmt.Sprintf("unable to run the go command: %v", err)
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			res.Message = fmt.Sprintf("unable to run the go command: %s", msg)
+		}
+		res.Hint = explain(res.Message)
+		return res
+	}
+	var env struct {
+		GOVERSION string
+		GOMOD     string
+	}
+	if err := json.Unmarshal(out, &env); err != nil {
+		res.Status = CheckFailed
+		res.Message = fmt.Sprintf("unable to parse the environment of the go command: %v", err)
+		return res
+	}
+
+	if env.GOMOD == "" || env.GOMOD == os.DevNull {
+		res.Status = CheckWarning
+		res.Message = fmt.Sprintf("%s, outside of any module", env.GOVERSION)
+		res.Hint = explain("go: cannot find main module")
+		return res
+	}
+	builtWith := runtime.Version()
+	if goversion.IsValid(env.GOVERSION) && goversion.IsValid(builtWith) && goversion.Compare(goversion.Lang(env.GOVERSION), goversion.Lang(builtWith)) > 0 {
+		res.Status = CheckWarning
+		res.Message = fmt.Sprintf("%s, newer than the %s controller-gen was built with, in the module of %s", env.GOVERSION, builtWith, env.GOMOD)
+		res.Hint = "packages using the language features or standard library of the newer Go may fail to type-check: rebuild controller-gen with it (e.g. with go install sigs.k8s.io/controller-tools/cmd/controller-gen)"
+		return res
+	}
+	res.Status = CheckPassed
+	res.Message = fmt.Sprintf("%s, in the module of %s", env.GOVERSION, env.GOMOD)
+	return res
+}
+
+// checkPackages checks the errors of loading the packages of the runtime.
+func checkPackages(rt *genall.Runtime) CheckResult {
+	res := CheckResult{Check: "packages"}
+	if len(rt.Roots) == 0 {
+		res.Status = CheckWarning
+		res.Message = "no packages to generate from"
+		res.Hint = "give the paths of the packages, e.g. paths=./api/..."
+		return res
+	}
+
+	// type errors are left out, as when running the generators
+	var errs []loader.Diagnostic
+	for _, diag := range loader.Diagnostics(rt.Roots, packages.TypeError) {
+		if diag.Severity == loader.SeverityError {
+			errs = append(errs, diag)
+		}
+	}
+	if len(errs) == 0 {
+		res.Status = CheckPassed
+		res.Message = fmt.Sprintf("loaded %d root package(s)", len(rt.Roots))
+		return res
+	}
+
+	res.Status = CheckFailed
+	res.Message = fmt.Sprintf("%d error(s) loading the packages, starting with: %s", len(errs), errs[0])
+	for _, diag := range errs {
+		if res.Hint = explain(diag.Message); res.Hint != "" {
+			break
+		}
+	}
+	return res
+}
+
+// checkDependencies checks the dependencies of the main modules of the roots
+// against the requirements of the generators.
+func checkDependencies(rt *genall.Runtime) CheckResult {
+	res := CheckResult{Check: "dependencies"}
+	versions := genall.ResolveModuleVersions(rt.Roots, rt.Generators)
+	var problems, upgrades []string
+	checked := make(map[string]struct{})
+	for _, root := range rt.Roots {
+		if root.Module == nil {
+			continue
+		}
+		if _, isChecked := checked[root.Module.GoMod]; isChecked {
+			continue
+		}
+		checked[root.Module.GoMod] = struct{}{}
+
+		for _, gen := range rt.Generators {
+			requiring, requires := (*gen).(genall.RequiresModules)
+			if !requires {
+				continue
+			}
+			for _, req := range requiring.RequiredModules() {
+				if versions.OlderThan(root, req.Module, req.MinVersion) {
+					problems = append(problems, fmt.Sprintf("%s %s (required by %s) is older than %s, which %s need", req.Module, versions.Of(root, req.Module), root.Module.Path, req.MinVersion, req.Reason))
+					upgrades = append(upgrades, req.Module+"@"+req.MinVersion)
+				}
+			}
+		}
+	}
+	if len(problems) == 0 {
+		res.Status = CheckPassed
+		res.Message = "the dependencies are recent enough for the generators"
+		return res
+	}
+	slices.Sort(upgrades)
+	res.Status = CheckWarning
+	res.Message = strings.Join(problems, "; ")
+	res.Hint = "the generated code may not compile: upgrade the dependencies, e.g. with go get " + strings.Join(slices.Compact(upgrades), " ")
+	return res
+}
+
+// checkEnvtest checks that the envtest binaries are found, if a generator
+// starts an API server.
+func checkEnvtest(rt *genall.Runtime) CheckResult {
+	res := CheckResult{Check: "envtest"}
+	needed := slices.ContainsFunc(rt.Generators, func(gen *genall.Generator) bool {
+		needing, needs := (*gen).(genall.NeedsAPIServer)
+		return needs && needing.NeedsAPIServer()
+	})
+	if !needed {
+		res.Status = CheckSkipped
+		res.Message = "no generator starts an API server"
+		return res
+	}
+	etcdPath, apiServerPath, err := apiserver.Binaries()
+	if err != nil {
+		res.Status = CheckFailed
+		res.Message = err.Error()
+		res.Hint = fmt.Sprintf("install the envtest binaries with setup-envtest (e.g. setup-envtest use -p path, after go install sigs.k8s.io/controller-runtime/tools/setup-envtest@latest), and set $%s to the directory it prints", apiserver.AssetsEnv)
+		return res
+	}
+	res.Status = CheckPassed
+	res.Message = fmt.Sprintf("found etcd at %s, and kube-apiserver at %s", etcdPath, apiServerPath)
+	return res
+}
+
+// checkOutput checks that the directories the generators write to can be
+// written to.
+func checkOutput(rt *genall.Runtime) CheckResult {
+	res := CheckResult{Check: "output"}
+	var dirs []string
+	for _, gen := range rt.Generators {
+		dirs = append(dirs, outputDirs(rt.OutputRules.ForGenerator(gen), rt.Roots)...)
+	}
+	slices.Sort(dirs)
+	dirs = slices.Compact(dirs)
+	if len(dirs) == 0 {
+		res.Status = CheckSkipped
+		res.Message = "no generator writes to a directory"
+		return res
+	}
+
+	var problems []string
+	for _, dir := range dirs {
+		if err := checkWritable(dir); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", dir, err))
+		}
+	}
+	if len(problems) > 0 {
+		res.Status = CheckFailed
+		res.Message = "unable to write to " + strings.Join(problems, "; ")
+		res.Hint = "fix the ownership or the permissions of the directories (e.g. of volumes mounted in containers running as another user), or write to others with the output rules, e.g. output:crd:dir=..."
+		return res
+	}
+	res.Status = CheckPassed
+	res.Message = "can write to " + strings.Join(dirs, ", ")
+	return res
+}
+
+// outputDirs returns the directories the given output rule writes to, for
+// the given roots, as far as it's known.
+func outputDirs(rule genall.OutputRule, roots []*loader.Package) []string {
+	// package-associated artifacts are written beside the packages by
+	// default
+	var pkgDirs []string
+	for _, root := range roots {
+		if len(root.CompiledGoFiles) > 0 {
+			pkgDirs = append(pkgDirs, filepath.Dir(root.CompiledGoFiles[0]))
+		}
+	}
+
+	switch rule := rule.(type) {
+	case genall.OutputToDirectory:
+		return []string{string(rule)}
+	case genall.OutputArtifacts:
+		if rule.Code != "" {
+			return []string{string(rule.Config), string(rule.Code)}
+		}
+		return append([]string{string(rule.Config)}, pkgDirs...)
+	case genall.OutputToHelmChart:
+		return append([]string{rule.Chart}, pkgDirs...)
+	case genall.OutputToBundle:
+		return append([]string{filepath.Dir(rule.File)}, pkgDirs...)
+	case genall.OutputToArchive:
+		return []string{filepath.Dir(rule.File)}
+	default:
+		return nil
+	}
+}
+
+// checkWritable checks that files can be written to the given directory,
+// creating it if needed: it's the closest existing directory that it would
+// be created in that must be writable otherwise.
+func checkWritable(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s isn't a directory", existing)
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		if !errors.Is(err, fs.ErrNotExist) || parent == existing {
+			return err
+		}
+		existing = parent
+	}
+	file, err := os.CreateTemp(existing, ".controller-gen-doctor-*")
+	if err != nil {
+		return err
+	}
+	return errors.Join(file.Close(), os.Remove(file.Name()))
+}
+
+// commonCauses are the likely causes of common errors of the go command, or
+// of loading packages, by pattern of their messages.
+var commonCauses = []struct {
+	pattern *regexp.Regexp
+	hint    string
+}{
+	{
+		regexp.MustCompile(`missing go\.sum entry`),
+		"go.sum lacks the checksums of some dependencies: run go mod tidy (or go mod download)",
+	},
+	{
+		regexp.MustCompile(`no required module provides package|cannot find module providing package`),
+		"a package is imported that no module required by go.mod provides: add it with go get, or run go mod tidy",
+	},
+	{
+		regexp.MustCompile(`requires go >= |requires newer Go version|toolchain not available`),
+		"the module needs a newer Go than the go command: upgrade Go, or let it download the toolchain the module needs (GOTOOLCHAIN=auto)",
+	},
+	{
+		regexp.MustCompile(`cannot find main module|go\.mod file not found|does not contain main module|main module \(.*\) does not contain package|not in std|outside main module`),
+		"the packages aren't in the module of the working directory: run controller-gen from the directory of the go.mod of the packages (or below it), or add their module to a go.work",
+	},
+	{
+		regexp.MustCompile(`build constraints exclude all Go files`),
+		"no file of a package is built with the build tags and platform packages are loaded for: check --load-build-tags and --load-platform",
+	},
+	{
+		regexp.MustCompile(`updates to go\.mod needed|inconsistent vendoring|go\.mod has post-v\d+ module path`),
+		"go.mod (or vendor/modules.txt) is out of date: run go mod tidy, and go mod vendor when vendoring",
+	},
+	{
+		regexp.MustCompile(`dial tcp|no such host|proxy\.golang\.org|i/o timeout|GOPROXY|GOFLAGS=-mod=mod`),
+		"dependencies couldn't be downloaded: check the access to the module proxy ($GOPROXY), or download them beforehand (go mod download) to run offline with GOFLAGS=-mod=mod GOPROXY=off",
+	},
+	{
+		regexp.MustCompile(`no generators specified`),
+		"give the doctor the options of the run to check, e.g. controller-gen doctor crd object paths=./api/...",
+	},
+	{
+		regexp.MustCompile(`permission denied`),
+		"a file or directory can't be read or written: check its ownership and permissions (e.g. of the module cache, or of volumes mounted in containers running as another user)",
+	},
+}
+
+// explain returns the likely cause of the given error message, and how to fix
+// it, if it's known, or "".
+func explain(msg string) string {
+	for _, cause := range commonCauses {
+		if cause.pattern.MatchString(msg) {
+			return cause.hint
+		}
+	}
+	return ""
+}
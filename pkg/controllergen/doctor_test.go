@@ -0,0 +1,119 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+)
+
+var _ = Describe("Checking the environment", func() {
+	var outDir string
+
+	BeforeEach(func() {
+		outDir = GinkgoT().TempDir()
+
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	// resultOf returns the result of the given check.
+	resultOf := func(results []controllergen.CheckResult, check string) controllergen.CheckResult {
+		for _, res := range results {
+			if res.Check == check {
+				return res
+			}
+		}
+		Fail("no result for the " + check + " check")
+		return controllergen.CheckResult{}
+	}
+
+	It("should find no problem with a working environment", func() {
+		results := controllergen.Doctor(context.Background(),
+			controllergen.WithOptions("crd", "object", "output:crd:dir="+filepath.Join(outDir, "crds")),
+			controllergen.WithPaths("./api/..."),
+		)
+		Expect(results).To(HaveEach(HaveField("Status", BeElementOf(controllergen.CheckPassed, controllergen.CheckSkipped))))
+		Expect(resultOf(results, "go").Status).To(Equal(controllergen.CheckPassed))
+		Expect(resultOf(results, "packages").Status).To(Equal(controllergen.CheckPassed))
+		Expect(resultOf(results, "envtest").Status).To(Equal(controllergen.CheckSkipped))
+		Expect(resultOf(results, "output").Message).To(ContainSubstring(filepath.Join(outDir, "crds")))
+	})
+
+	It("should explain the errors of loading the packages", func() {
+		results := controllergen.Doctor(context.Background(),
+			controllergen.WithPaths("./api/v1"),
+		)
+		Expect(resultOf(results, "packages")).To(SatisfyAll(
+			HaveField("Status", controllergen.CheckFailed),
+			HaveField("Message", "no generators specified"),
+			HaveField("Hint", ContainSubstring("controller-gen doctor crd object paths="))))
+
+		// without looking the missing module up on the proxy
+		GinkgoT().Setenv("GOFLAGS", "-mod=readonly")
+		results = controllergen.Doctor(context.Background(),
+			controllergen.WithOptions("object"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithOverlay(map[string][]byte{
+				mustAbs("api/v1/missing.go"): []byte("package v1\n\nimport _ \"example.com/missing/pkg\"\n"),
+			}),
+		)
+		Expect(resultOf(results, "packages")).To(SatisfyAll(
+			HaveField("Status", controllergen.CheckFailed),
+			HaveField("Message", ContainSubstring("example.com/missing/pkg")),
+			HaveField("Hint", ContainSubstring("go mod tidy"))))
+	})
+
+	It("should report the output directories that can't be written to", func() {
+		file := filepath.Join(outDir, "file")
+		Expect(os.WriteFile(file, nil, 0o644)).To(Succeed())
+		results := controllergen.Doctor(context.Background(),
+			controllergen.WithOptions("crd", "output:crd:dir="+filepath.Join(file, "crds")),
+			controllergen.WithPaths("./api/v1"),
+		)
+		Expect(resultOf(results, "output")).To(SatisfyAll(
+			HaveField("Status", controllergen.CheckFailed),
+			HaveField("Message", ContainSubstring("not a directory"))))
+	})
+
+	It("should report the missing envtest binaries of the generators starting an API server", func() {
+		GinkgoT().Setenv("KUBEBUILDER_ASSETS", GinkgoT().TempDir())
+		results := controllergen.Doctor(context.Background(),
+			controllergen.WithOptions("crd:validateOn=envtest", "output:crd:dir="+outDir),
+			controllergen.WithPaths("./api/v1"),
+		)
+		Expect(resultOf(results, "envtest")).To(SatisfyAll(
+			HaveField("Status", controllergen.CheckFailed),
+			HaveField("Message", ContainSubstring("unable to find etcd in $KUBEBUILDER_ASSETS")),
+			HaveField("Hint", ContainSubstring("setup-envtest"))))
+	})
+})
+
+// mustAbs returns the absolute path of the given path.
+func mustAbs(path string) string {
+	abs, err := filepath.Abs(path)
+	Expect(err).NotTo(HaveOccurred())
+	return abs
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+)
+
+var _ = Describe("Planning dry runs", func() {
+	var outDir string
+
+	BeforeEach(func() {
+		outDir = GinkgoT().TempDir()
+
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	// dryRun dry-runs the given options over the testdata API, returning
+	// the plan.
+	dryRun := func(options ...string) string {
+		var out, errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions(options...),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithDryRun(true),
+			controllergen.WithOutputWriter(&out),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+		return out.String()
+	}
+
+	It("should print the packages, and the files each generator would write by output rule, without writing them", func() {
+		plan := dryRun("object", "crd:maxDescLen=0", "rbac:roleName=manager-role", "output:crd:dir="+outDir, "output:rbac:stdout")
+		Expect(plan).To(Equal(`packages:
+  testdata.kubebuilder.io/docs/api/v1alpha1
+  testdata.kubebuilder.io/docs/api/v1
+
+object (output: artifacts:config=config/object)
+  api/v1alpha1/zz_generated.deepcopy.go
+  api/v1/zz_generated.deepcopy.go
+
+crd (output: dir=` + outDir + `)
+  ` + filepath.Join(outDir, "testdata.kubebuilder.io_gizmoes.yaml") + `
+  ` + filepath.Join(outDir, "testdata.kubebuilder.io_widgets.yaml") + `
+
+rbac (output: stdout)
+  (nothing)
+`))
+
+		By("writing nothing")
+		Expect(os.ReadDir(outDir)).To(BeEmpty())
+		for _, version := range []string{"v1", "v1alpha1"} {
+			Expect(filepath.Join("api", version, "zz_generated.deepcopy.go")).NotTo(BeAnExistingFile())
+		}
+		Expect("config").NotTo(BeADirectory())
+	})
+
+	It("should plan the artifacts held until the end of the run by their output rule", func() {
+		bundlePath := filepath.Join(outDir, "install.yaml")
+		plan := dryRun("crd", "output:crd:bundle:file="+bundlePath)
+		Expect(plan).To(ContainSubstring("\n  " + bundlePath + " (bundling testdata.kubebuilder.io_gizmoes.yaml)\n"))
+		Expect(plan).To(ContainSubstring("\n  " + bundlePath + " (bundling testdata.kubebuilder.io_widgets.yaml)\n"))
+
+		plan = dryRun("crd", "output:crd:stdout")
+		Expect(plan).To(ContainSubstring("crd (output: stdout)\n  standard-out: testdata.kubebuilder.io_gizmoes.yaml\n  standard-out: testdata.kubebuilder.io_widgets.yaml\n"))
+		Expect(os.ReadDir(outDir)).To(BeEmpty())
+	})
+})
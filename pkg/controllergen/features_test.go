@@ -0,0 +1,66 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+)
+
+var _ = Describe("Feature gates", func() {
+	It("should parse the gates, defaulting the unset features to their spec", func() {
+		gates, err := genall.ParseFeatureGates([]string{"ConcurrentGenerators=false", " ReleaseSyntax=true"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gates).To(Equal(genall.FeatureGates{genall.ConcurrentGenerators: false, genall.ReleaseSyntax: true}))
+		Expect(gates.Enabled(genall.ConcurrentGenerators)).To(BeFalse())
+		Expect(gates.Enabled(genall.ReleaseSyntax)).To(BeTrue())
+
+		By("defaulting the features not set")
+		Expect(gates.Enabled(genall.ConcurrentSchemas)).To(BeFalse(), "alpha features should be disabled by default")
+		Expect(genall.FeatureGates(nil).Enabled(genall.ConcurrentGenerators)).To(BeTrue(), "beta features should be enabled by default")
+	})
+
+	DescribeTable("should refuse invalid gates",
+		func(gate, message string) {
+			_, err := genall.ParseFeatureGates([]string{gate})
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("without a value", "ConcurrentGenerators", `invalid feature gate "ConcurrentGenerators", expected Feature=true|false`),
+		Entry("of unknown features", "Nope=true", "unknown feature Nope (known features are ConcurrentGenerators, ConcurrentSchemas, "),
+		Entry("with an invalid value", "ConcurrentGenerators=maybe", `invalid value "maybe" of feature gate ConcurrentGenerators, expected true or false`),
+	)
+
+	It("should stage the features added by generators", func() {
+		const staged genall.Feature = "ControllerGenTestStaged"
+		Expect(genall.AddFeatures(map[genall.Feature]genall.FeatureSpec{
+			staged: {Stage: genall.Alpha, Description: "a staged change"},
+		})).To(Succeed())
+		Expect(genall.KnownFeatures()).To(HaveKey(staged))
+		Expect(genall.FeatureGatesHelp()).To(ContainSubstring("ControllerGenTestStaged=true|false (ALPHA - default=false): a staged change\n"))
+
+		gates, err := genall.ParseFeatureGates([]string{"ControllerGenTestStaged=true"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gates.Enabled(staged)).To(BeTrue())
+
+		By("refusing features known already")
+		Expect(genall.AddFeatures(map[genall.Feature]genall.FeatureSpec{
+			genall.ReleaseSyntax: {Stage: genall.Beta},
+		})).To(MatchError("feature ReleaseSyntax is known already"))
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+)
+
+var _ = Describe("Keeping going after failures", func() {
+	var outDir string
+
+	BeforeEach(func() {
+		outDir = GinkgoT().TempDir()
+
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	// run runs a failing generator before the object and crd generators,
+	// one after another, bundling the CRDs.
+	run := func(opts ...controllergen.Option) (string, bool) {
+		var errOut bytes.Buffer
+		rt, err := controllergen.NewRuntime(context.Background(), append([]controllergen.Option{
+			controllergen.WithOptions("object", "crd", "output:object:dir="+outDir, "output:crd:bundle:file="+filepath.Join(outDir, "install.yaml")),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithParallelism(1),
+			controllergen.WithErrorWriter(&errOut),
+		}, opts...)...)
+		Expect(err).NotTo(HaveOccurred())
+		var failing genall.Generator = probeGenerator{concurrency: &concurrency{}, err: errors.New("generator failed")}
+		rt.Generators = append(genall.Generators{&failing}, rt.Generators...)
+		failed := rt.Run()
+		return errOut.String(), failed
+	}
+
+	It("should run all the generators after a failure by default, discarding the held artifacts", func() {
+		errOut, failed := run()
+		Expect(failed).To(BeTrue())
+		Expect(errOut).To(ContainSubstring("generator failed"))
+		Expect(errOut).NotTo(ContainSubstring("skipped"))
+		Expect(errOut).NotTo(ContainSubstring("generators failed:"), "only runs keeping going are summarized")
+		Expect(filepath.Join(outDir, "zz_generated.deepcopy.go")).To(BeARegularFile())
+		Expect(filepath.Join(outDir, "install.yaml")).NotTo(BeAnExistingFile())
+	})
+
+	It("should write the held artifacts too when keeping going, and summarize the failures", func() {
+		errOut, failed := run(controllergen.WithKeepGoing(true))
+		Expect(failed).To(BeTrue(), "the run should still fail")
+		Expect(errOut).To(ContainSubstring("generator failed"))
+		Expect(errOut).NotTo(ContainSubstring("skipped"))
+		Expect(errOut).To(ContainSubstring("1 of 3 generators failed: controllergen_test.probeGenerator\n"))
+		Expect(errOut).NotTo(ContainSubstring("packages had errors"))
+
+		By("writing the artifacts of the other generators, held ones included")
+		Expect(filepath.Join(outDir, "zz_generated.deepcopy.go")).To(BeARegularFile())
+		bundle, err := os.ReadFile(filepath.Join(outDir, "install.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(bundle)).To(ContainSubstring("name: widgets.testdata.kubebuilder.io"))
+	})
+
+	It("should summarize the packages that had errors", func() {
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("object", "crd", "output:object:dir="+outDir, "output:crd:dir="+outDir),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithOverlay(map[string][]byte{
+				filepath.Join(cwd, "api", "v1", "broken.go"): []byte("package v1\n\nfunc broken( {\n"),
+			}),
+			controllergen.WithKeepGoing(true),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(MatchError(controllergen.ErrGenerationFailed))
+		Expect(errOut.String()).To(ContainSubstring("1 of 2 packages had errors: testdata.kubebuilder.io/docs/api/v1\n"))
+		Expect(filepath.Join(outDir, "testdata.kubebuilder.io_gizmoes.yaml")).To(BeARegularFile(), "the CRDs of the other packages should still be written")
+	})
+})
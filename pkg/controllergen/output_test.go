@@ -0,0 +1,282 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Outputting the artifacts", func() {
+	var (
+		outDir string
+		// overlay adds RBAC and webhook markers to the Widget API, so that
+		// the rbac and webhook generators have something to generate.
+		overlay map[string][]byte
+	)
+
+	BeforeEach(func() {
+		outDir = GinkgoT().TempDir()
+
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+
+		testdata, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		overlay = map[string][]byte{
+			filepath.Join(testdata, "api", "v1", "markers.go"): []byte(`package v1
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list
+
+// +kubebuilder:webhook:path=/mutate-widget,mutating=true,failurePolicy=fail,sideEffects=None,groups=testdata.kubebuilder.io,resources=widgets,verbs=create;update,versions=v1,name=mwidget.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-widget,mutating=false,failurePolicy=fail,sideEffects=None,groups=testdata.kubebuilder.io,resources=widgets,verbs=create;update,versions=v1,name=vwidget.kb.io,admissionReviewVersions=v1
+`),
+		}
+	})
+
+	// kinds returns the kinds of the objects of the given multi-document
+	// YAML, in order.
+	kinds := func(contents []byte) []string {
+		var kinds []string
+		for _, document := range bytes.Split(contents, []byte("---\n")) {
+			var object struct{ Kind string }
+			Expect(yaml.Unmarshal(document, &object)).To(Succeed())
+			if object.Kind != "" {
+				kinds = append(kinds, object.Kind)
+			}
+		}
+		return kinds
+	}
+
+	It("should bundle the manifests of all the generators in install order", func() {
+		bundlePath := filepath.Join(outDir, "install.yaml")
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("webhook", "rbac:roleName=manager-role", "crd", "output:bundle:file="+bundlePath+",namespace=system"),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithOverlay(overlay),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+
+		contents, err := os.ReadFile(bundlePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds(contents)).To(Equal([]string{
+			"Namespace",
+			"CustomResourceDefinition",
+			"CustomResourceDefinition",
+			"ClusterRole",
+			"MutatingWebhookConfiguration",
+			"ValidatingWebhookConfiguration",
+		}))
+		Expect(string(contents)).To(HavePrefix("---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: system\n"))
+		Expect(os.ReadDir(outDir)).To(HaveLen(1), "only the bundle should be written")
+
+		By("bundling the same manifests whatever the order of the generators")
+		reordered := filepath.Join(outDir, "reordered.yaml")
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "rbac:roleName=manager-role", "webhook", "output:bundle:file="+reordered+",namespace=system"),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithOverlay(overlay),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+		Expect(os.ReadFile(reordered)).To(Equal(contents))
+	})
+
+	It("should stream the manifests to standard-out as multi-document YAML, ordered by path", func() {
+		stream := func(options ...string) string {
+			var out, errOut bytes.Buffer
+			Expect(controllergen.Run(context.Background(),
+				controllergen.WithOptions(append(options, "output:stdout")...),
+				controllergen.WithPaths("./api/..."),
+				controllergen.WithOverlay(overlay),
+				controllergen.WithOutputWriter(&out),
+				controllergen.WithErrorWriter(&errOut),
+			)).To(Succeed(), errOut.String())
+			return out.String()
+		}
+		out := stream("crd", "rbac:roleName=manager-role")
+		Expect(kinds([]byte(out))).To(Equal([]string{"ClusterRole", "CustomResourceDefinition", "CustomResourceDefinition"}))
+		var sources []string
+		for _, line := range strings.Split(out, "\n") {
+			if source, isSource := strings.CutPrefix(line, "# Source: "); isSource {
+				sources = append(sources, source)
+			}
+		}
+		Expect(sources).To(Equal([]string{"role.yaml", "testdata.kubebuilder.io_gizmoes.yaml", "testdata.kubebuilder.io_widgets.yaml"}))
+		Expect(out).To(HavePrefix("# Source: role.yaml\n---\n"))
+		Expect(strings.Count(out, "\n---\n")).To(Equal(3), "each artifact should start its own document")
+		Expect(os.ReadDir(outDir)).To(BeEmpty())
+
+		By("streaming the same manifests whatever the order of the generators")
+		Expect(stream("rbac:roleName=manager-role", "crd")).To(Equal(out))
+	})
+
+	It("should not stream anything to standard-out when a generator fails", func() {
+		var out, errOut bytes.Buffer
+		rt, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("crd", "output:stdout"),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithOutputWriter(&out),
+			controllergen.WithErrorWriter(&errOut),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		var failing genall.Generator = probeGenerator{concurrency: &concurrency{}, err: errors.New("generator failed")}
+		rt.Generators = append(rt.Generators, &failing)
+		Expect(rt.Run()).To(BeTrue())
+		Expect(errOut.String()).To(ContainSubstring("generator failed"))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	Context("to archives", func() {
+		// readArchive returns the entries of the given archive, in order.
+		readArchive := func(archivePath string) ([]string, map[string][]byte) {
+			var names []string
+			entries := make(map[string][]byte)
+			if strings.HasSuffix(archivePath, ".zip") {
+				zipReader, err := zip.OpenReader(archivePath)
+				Expect(err).NotTo(HaveOccurred())
+				defer zipReader.Close()
+				for _, file := range zipReader.File {
+					entry, err := file.Open()
+					Expect(err).NotTo(HaveOccurred())
+					names = append(names, file.Name)
+					entries[file.Name], err = io.ReadAll(entry)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(entry.Close()).To(Succeed())
+				}
+				return names, entries
+			}
+			file, err := os.Open(archivePath)
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+			gzipReader, err := gzip.NewReader(file)
+			Expect(err).NotTo(HaveOccurred())
+			tarReader := tar.NewReader(gzipReader)
+			for {
+				header, err := tarReader.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+				names = append(names, header.Name)
+				entries[header.Name], err = io.ReadAll(tarReader)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			return names, entries
+		}
+		archive := func(archivePath string) error {
+			var errOut bytes.Buffer
+			err := controllergen.Run(context.Background(),
+				controllergen.WithOptions("object", "crd", "rbac:roleName=manager-role", "output:archive:file="+archivePath),
+				controllergen.WithPaths("./api/..."),
+				controllergen.WithOverlay(overlay),
+				controllergen.WithPinnedVersion("v0.0.0-test"),
+				controllergen.WithErrorWriter(&errOut),
+			)
+			GinkgoWriter.Print(errOut.String())
+			return err
+		}
+
+		DescribeTable("should archive all the artifacts along with their manifest, reproducibly",
+			func(name string) {
+				archivePath := filepath.Join(outDir, name)
+				Expect(archive(archivePath)).To(Succeed())
+				names, entries := readArchive(archivePath)
+				Expect(names).To(Equal([]string{
+					"manifest.json",
+					"api/v1/zz_generated.deepcopy.go",
+					"api/v1alpha1/zz_generated.deepcopy.go",
+					"role.yaml",
+					"testdata.kubebuilder.io_gizmoes.yaml",
+					"testdata.kubebuilder.io_widgets.yaml",
+				}))
+				Expect(string(entries["api/v1/zz_generated.deepcopy.go"])).To(ContainSubstring("func (in *Widget) DeepCopy() *Widget"))
+				Expect(os.ReadDir(outDir)).To(HaveLen(1), "only the archive should be written")
+
+				By("listing the artifacts in the manifest")
+				var manifest struct {
+					Generator string
+					Version   string
+					Files     []struct {
+						Path   string
+						Size   int
+						SHA256 string
+					}
+				}
+				Expect(json.Unmarshal(entries["manifest.json"], &manifest)).To(Succeed())
+				Expect(manifest.Generator).To(Equal("controller-gen"))
+				Expect(manifest.Version).To(Equal("v0.0.0-test"))
+				Expect(manifest.Files).To(HaveLen(len(names) - 1))
+				for i, file := range manifest.Files {
+					Expect(file.Path).To(Equal(names[i+1]))
+					Expect(file.Size).To(Equal(len(entries[file.Path])))
+					digest := sha256.Sum256(entries[file.Path])
+					Expect(file.SHA256).To(Equal(hex.EncodeToString(digest[:])))
+				}
+
+				By("writing the same archive again")
+				contents, err := os.ReadFile(archivePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.Remove(archivePath)).To(Succeed())
+				time.Sleep(time.Second) // for the modification times to differ, if they mattered
+				Expect(archive(archivePath)).To(Succeed())
+				Expect(os.ReadFile(archivePath)).To(Equal(contents))
+			},
+			Entry("as a gzipped tarball", "manifests.tar.gz"),
+			Entry("as a gzipped tarball, with the short extension", "manifests.tgz"),
+			Entry("as a zip file", "manifests.zip"),
+		)
+
+		It("should refuse archives of unknown formats", func() {
+			Expect(archive(filepath.Join(outDir, "manifests.rar"))).To(MatchError(controllergen.ErrGenerationFailed))
+			_, err := genall.OutputToArchive{File: "manifests.rar"}.Open(nil, "role.yaml")
+			Expect(err).To(MatchError("unknown archive format of manifests.rar, must be .tar.gz, .tgz or .zip"))
+			Expect(os.ReadDir(outDir)).To(BeEmpty())
+		})
+
+		It("should refuse artifacts at the path of the manifest", func() {
+			_, err := genall.OutputToArchive{File: "manifests.zip"}.Open(nil, "manifest.json")
+			Expect(err).To(MatchError("cannot output manifest.json to an archive, as it's the path of its manifest"))
+		})
+	})
+
+	It("should not bundle manifests outside of a run", func() {
+		_, err := genall.OutputToBundle{File: filepath.Join(outDir, "install.yaml")}.Open(nil, "install.yaml")
+		Expect(err).To(MatchError(ContainSubstring("the bundle output rule can only be used by the generators of a Runtime")))
+	})
+})
@@ -0,0 +1,189 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// concurrency tracks the generators running at the same time.
+type concurrency struct {
+	running atomic.Int32
+	peak    atomic.Int32
+	// shared is set if a generator needing to run on its own didn't.
+	shared atomic.Bool
+}
+
+// probeGenerator is a generator recording how it runs concurrently with the
+// others, failing with the given error, if any, once done.
+type probeGenerator struct {
+	concurrency *concurrency
+	hold        time.Duration
+	err         error
+}
+
+func (probeGenerator) RegisterMarkers(*markers.Registry) error { return nil }
+
+func (g probeGenerator) Generate(*genall.GenerationContext) error {
+	running := g.concurrency.running.Add(1)
+	defer g.concurrency.running.Add(-1)
+	for {
+		peak := g.concurrency.peak.Load()
+		if running <= peak || g.concurrency.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	time.Sleep(g.hold)
+	return g.err
+}
+
+// exclusiveProbeGenerator is a probeGenerator needing to run on its own.
+type exclusiveProbeGenerator struct {
+	probeGenerator
+}
+
+func (exclusiveProbeGenerator) ExclusiveRun() bool { return true }
+
+func (g exclusiveProbeGenerator) Generate(ctx *genall.GenerationContext) error {
+	if g.concurrency.running.Load() != 0 {
+		g.concurrency.shared.Store(true)
+	}
+	return g.probeGenerator.Generate(ctx)
+}
+
+var _ = Describe("Running the generators concurrently", func() {
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	// run runs the given generators over the testdata API.
+	run := func(gens []genall.Generator, opts ...controllergen.Option) (string, bool) {
+		var errOut bytes.Buffer
+		rt, err := controllergen.NewRuntime(context.Background(), append([]controllergen.Option{
+			controllergen.WithOptions("object"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithErrorWriter(&errOut),
+		}, opts...)...)
+		Expect(err).NotTo(HaveOccurred())
+		rt.Generators = nil
+		for _, gen := range gens {
+			rt.Generators = append(rt.Generators, &gen)
+		}
+		failed := rt.Run()
+		return errOut.String(), failed
+	}
+
+	probes := func(c *concurrency, n int) []genall.Generator {
+		gens := make([]genall.Generator, n)
+		for i := range gens {
+			gens[i] = probeGenerator{concurrency: c, hold: 50 * time.Millisecond}
+		}
+		return gens
+	}
+
+	It("should run as many generators at once as the parallelism", func() {
+		var c concurrency
+		errOut, failed := run(probes(&c, 8), controllergen.WithParallelism(3))
+		Expect(failed).To(BeFalse(), errOut)
+		Expect(c.peak.Load()).To(BeEquivalentTo(3))
+
+		By("running them one after another without the feature gate")
+		c = concurrency{}
+		errOut, failed = run(probes(&c, 4), controllergen.WithParallelism(3), controllergen.WithFeatureGates(genall.FeatureGates{genall.ConcurrentGenerators: false}))
+		Expect(failed).To(BeFalse(), errOut)
+		Expect(c.peak.Load()).To(BeEquivalentTo(1))
+
+		By("running them one after another with a parallelism of one")
+		c = concurrency{}
+		errOut, failed = run(probes(&c, 4), controllergen.WithParallelism(1))
+		Expect(failed).To(BeFalse(), errOut)
+		Expect(c.peak.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should run the generators needing it on their own", func() {
+		var c concurrency
+		gens := probes(&c, 6)
+		gens[3] = exclusiveProbeGenerator{probeGenerator{concurrency: &c, hold: 50 * time.Millisecond}}
+		errOut, failed := run(gens, controllergen.WithParallelism(4))
+		Expect(failed).To(BeFalse(), errOut)
+		Expect(c.shared.Load()).To(BeFalse(), "the exclusive generator shouldn't share its run")
+		Expect(c.peak.Load()).To(BeEquivalentTo(3), "the other generators should still run concurrently")
+	})
+
+	It("should report the errors in the order of the generators, whichever failed first", func() {
+		var c concurrency
+		gens := make([]genall.Generator, 4)
+		for i := range gens {
+			// the later generators fail first
+			gens[i] = probeGenerator{concurrency: &c, hold: time.Duration(len(gens)-i) * 50 * time.Millisecond, err: fmt.Errorf("generator %d failed", i)}
+		}
+		errOut, failed := run(gens, controllergen.WithParallelism(4), controllergen.WithKeepGoing(true))
+		Expect(failed).To(BeTrue())
+		Expect(c.peak.Load()).To(BeEquivalentTo(4))
+		Expect(strings.Index(errOut, "generator 0 failed")).To(BeNumerically(">=", 0), errOut)
+		for i := 1; i < len(gens); i++ {
+			Expect(strings.Index(errOut, fmt.Sprintf("generator %d failed", i))).To(BeNumerically(">", strings.Index(errOut, fmt.Sprintf("generator %d failed", i-1))), errOut)
+		}
+	})
+
+	It("should still run the generators not started yet after a failure", func() {
+		var c concurrency
+		gens := make([]genall.Generator, 4)
+		for i := range gens {
+			gens[i] = probeGenerator{concurrency: &c, err: fmt.Errorf("generator %d failed", i)}
+		}
+		errOut, failed := run(gens, controllergen.WithParallelism(1))
+		Expect(failed).To(BeTrue())
+		for i := range gens {
+			Expect(errOut).To(ContainSubstring("generator %d failed", i))
+		}
+		Expect(errOut).NotTo(ContainSubstring("skipped"))
+	})
+
+	It("should generate the same artifacts whatever the parallelism", func() {
+		generate := func(parallelism int) string {
+			outDir := GinkgoT().TempDir()
+			var errOut bytes.Buffer
+			Expect(controllergen.Run(context.Background(),
+				controllergen.WithOptions("object", "crd", "rbac:roleName=manager-role", "webhook", "applyconfiguration", "output:dir="+outDir),
+				controllergen.WithPaths("./api/..."),
+				controllergen.WithParallelism(parallelism),
+				controllergen.WithErrorWriter(&errOut),
+			)).To(Succeed(), errOut.String())
+			return outDir
+		}
+		golden.Compare(GinkgoT(), generate(1), generate(8))
+	})
+})
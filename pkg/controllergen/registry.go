@@ -0,0 +1,212 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen
+
+import (
+	"fmt"
+	"maps"
+
+	"sigs.k8s.io/controller-tools/pkg/admissionpolicy"
+	"sigs.k8s.io/controller-tools/pkg/applyconfiguration"
+	"sigs.k8s.io/controller-tools/pkg/builder"
+	"sigs.k8s.io/controller-tools/pkg/celtest"
+	"sigs.k8s.io/controller-tools/pkg/client"
+	"sigs.k8s.io/controller-tools/pkg/conditions"
+	"sigs.k8s.io/controller-tools/pkg/conversion"
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/crdinstaller"
+	"sigs.k8s.io/controller-tools/pkg/cue"
+	"sigs.k8s.io/controller-tools/pkg/deepcopy"
+	"sigs.k8s.io/controller-tools/pkg/defaulter"
+	"sigs.k8s.io/controller-tools/pkg/docs"
+	"sigs.k8s.io/controller-tools/pkg/events"
+	"sigs.k8s.io/controller-tools/pkg/finalizer"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/jsonschema"
+	"sigs.k8s.io/controller-tools/pkg/keys"
+	"sigs.k8s.io/controller-tools/pkg/kustomize"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/controller-tools/pkg/metrics"
+	"sigs.k8s.io/controller-tools/pkg/olm"
+	"sigs.k8s.io/controller-tools/pkg/openapi"
+	"sigs.k8s.io/controller-tools/pkg/patch"
+	"sigs.k8s.io/controller-tools/pkg/plugin"
+	"sigs.k8s.io/controller-tools/pkg/protobuf"
+	"sigs.k8s.io/controller-tools/pkg/pydantic"
+	"sigs.k8s.io/controller-tools/pkg/rbac"
+	"sigs.k8s.io/controller-tools/pkg/registry"
+	"sigs.k8s.io/controller-tools/pkg/roundtrip"
+	"sigs.k8s.io/controller-tools/pkg/rust"
+	"sigs.k8s.io/controller-tools/pkg/samples"
+	"sigs.k8s.io/controller-tools/pkg/schemapatcher"
+	"sigs.k8s.io/controller-tools/pkg/schematest"
+	"sigs.k8s.io/controller-tools/pkg/ssamigration"
+	"sigs.k8s.io/controller-tools/pkg/storageversion"
+	"sigs.k8s.io/controller-tools/pkg/typescript"
+	"sigs.k8s.io/controller-tools/pkg/validation"
+	"sigs.k8s.io/controller-tools/pkg/webhook"
+)
+
+// Options are specified to controller-gen by turning generators and output rules into
+// markers, and then parsing them using the standard registry logic (without the "+").
+// Each marker and output rule should thus be usable as a marker target.
+
+var (
+	// allGenerators maintains the list of all known generators, giving
+	// them names for use on the command line.
+	// each turns into a command line option,
+	// and has options for output forms.
+	allGenerators = map[string]genall.Generator{
+		"crd":                     crd.Generator{},
+		"crdinstaller":            crdinstaller.Generator{},
+		"rbac":                    rbac.Generator{},
+		"object":                  deepcopy.Generator{},
+		"applyconfiguration":      applyconfiguration.Generator{},
+		"smdschema":               applyconfiguration.SchemaGenerator{},
+		"swagger":                 applyconfiguration.SwaggerGenerator{},
+		"client":                  client.Generator{},
+		"lister":                  client.ListerGenerator{},
+		"informer":                client.InformerGenerator{},
+		"openapi":                 openapi.Generator{},
+		"conversion":              conversion.Generator{},
+		"defaulter":               defaulter.Generator{},
+		"validation":              validation.Generator{},
+		"conditions":              conditions.Generator{},
+		"finalizer":               finalizer.Generator{},
+		"events":                  events.Generator{},
+		"keys":                    keys.Generator{},
+		"metrics":                 metrics.Generator{},
+		"roundtrip":               roundtrip.Generator{},
+		"builder":                 builder.Generator{},
+		"registry":                registry.Generator{},
+		"patch":                   patch.Generator{},
+		"ssamigration":            ssamigration.Generator{},
+		"docs":                    docs.Generator{},
+		"samples":                 samples.Generator{},
+		"schematest":              schematest.Generator{},
+		"celtest":                 celtest.Generator{},
+		"jsonschema":              jsonschema.Generator{},
+		"cue":                     cue.Generator{},
+		"typescript":              typescript.Generator{},
+		"pydantic":                pydantic.Generator{},
+		"rust":                    rust.Generator{},
+		"protobuf":                protobuf.Generator{},
+		"olm":                     olm.Generator{},
+		"kustomize":               kustomize.Generator{},
+		"storageversion":          storageversion.Generator{},
+		"webhook":                 webhook.Generator{},
+		"webhookregistration":     webhook.RegistrationGenerator{},
+		"schemapatch":             schemapatcher.Generator{},
+		"admissionpolicy":         admissionpolicy.Generator{},
+		"mutatingadmissionpolicy": admissionpolicy.MutatingGenerator{},
+		"plugin":                  plugin.Generator{},
+	}
+
+	// allOutputRules defines the list of all known output rules, giving
+	// them names for use on the command line.
+	// Each output rule turns into two command line options:
+	// - output:<generator>:<form> (per-generator output)
+	// - output:<form> (default output)
+	allOutputRules = map[string]genall.OutputRule{
+		"dir":       genall.OutputToDirectory(""),
+		"none":      genall.OutputToNothing,
+		"stdout":    genall.OutputToStdout,
+		"artifacts": genall.OutputArtifacts{},
+		"helm":      genall.OutputToHelmChart{},
+		"bundle":    genall.OutputToBundle{},
+		"archive":   genall.OutputToArchive{},
+		"apply":     genall.OutputToCluster{},
+	}
+
+	// optionsRegistry contains all the marker definitions used to process command line options
+	optionsRegistry = &markers.Registry{}
+)
+
+func init() {
+	for genName, gen := range allGenerators {
+		// make the generator options marker itself
+		defn := markers.Must(markers.MakeDefinition(genName, markers.DescribesPackage, gen))
+		if err := optionsRegistry.Register(defn); err != nil {
+			panic(err)
+		}
+		if helpGiver, hasHelp := gen.(genall.HasHelp); hasHelp {
+			if help := helpGiver.Help(); help != nil {
+				optionsRegistry.AddHelp(defn, help)
+			}
+		}
+
+		// make the generator kinds filter marker
+		onlyMarker := markers.Must(markers.MakeDefinition(genName+":only", markers.DescribesPackage, genall.KindFilter(nil)))
+		if err := optionsRegistry.Register(onlyMarker); err != nil {
+			panic(err)
+		}
+		if help := (genall.KindFilter(nil)).Help(); help != nil {
+			optionsRegistry.AddHelp(onlyMarker, help)
+		}
+
+		// make per-generation output rule markers
+		for ruleName, rule := range allOutputRules {
+			ruleMarker := markers.Must(markers.MakeDefinition(fmt.Sprintf("output:%s:%s", genName, ruleName), markers.DescribesPackage, rule))
+			if err := optionsRegistry.Register(ruleMarker); err != nil {
+				panic(err)
+			}
+			if helpGiver, hasHelp := rule.(genall.HasHelp); hasHelp {
+				if help := helpGiver.Help(); help != nil {
+					optionsRegistry.AddHelp(ruleMarker, help)
+				}
+			}
+		}
+	}
+
+	// make "default output" output rule markers
+	for ruleName, rule := range allOutputRules {
+		ruleMarker := markers.Must(markers.MakeDefinition("output:"+ruleName, markers.DescribesPackage, rule))
+		if err := optionsRegistry.Register(ruleMarker); err != nil {
+			panic(err)
+		}
+		if helpGiver, hasHelp := rule.(genall.HasHelp); hasHelp {
+			if help := helpGiver.Help(); help != nil {
+				optionsRegistry.AddHelp(ruleMarker, help)
+			}
+		}
+	}
+
+	// add in the common options markers
+	if err := genall.RegisterOptionsMarkers(optionsRegistry); err != nil {
+		panic(err)
+	}
+}
+
+// Generators returns the generators known to controller-gen, by the names
+// they're given as options with.
+func Generators() map[string]genall.Generator {
+	return maps.Clone(allGenerators)
+}
+
+// OutputRules returns the output rules known to controller-gen, by the names
+// they're given as options with (as output:<name> and
+// output:<generator>:<name>).
+func OutputRules() map[string]genall.OutputRule {
+	return maps.Clone(allOutputRules)
+}
+
+// OptionsRegistry returns the registry of the options of controller-gen: the
+// generators, their kinds filters, the output rules and the paths, along with
+// their help.  It's shared, and shouldn't be modified.
+func OptionsRegistry() *markers.Registry {
+	return optionsRegistry
+}
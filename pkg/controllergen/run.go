@@ -0,0 +1,505 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// ErrGenerationFailed is returned by Run when generators or packages had
+// errors, which were written to the error writer.
+var ErrGenerationFailed = errors.New("not all generators ran successfully")
+
+// DefaultBuildTags are the build tags packages are loaded with, unless set
+// with WithBuildTags.  They leave out the generated files that aren't needed
+// to generate anew.
+var DefaultBuildTags = []string{"ignore_autogenerated"}
+
+// Options configure a run of the generators.  They're set with Option
+// functions.
+type Options struct {
+	// Options are the generators, output rules, kinds filters and paths to
+	// run with, in the syntax of the command line (e.g. "crd:maxDescLen=0"
+	// or "output:crd:dir=config/crd/bases").
+	Options []string
+	// BuildTags are the build tags to load packages with.
+	BuildTags []string
+	// Platform is the target platform to load packages for, as GOOS/GOARCH
+	// (e.g. linux/amd64), so that the files selected by build constraints
+	// don't depend on the machine running the generators.  Packages are
+	// loaded for the platform of the go command if empty.
+	Platform string
+	// LoadCache is the directory of the on-disk cache of the types of the
+	// dependencies loaded from export data (see loader.TypesCache), which
+	// isn't used if empty.
+	LoadCache string
+	// Overlay replaces the contents of the given files (by absolute path)
+	// when loading packages, or adds them, as with packages.Config.Overlay,
+	// e.g. to generate from unsaved editor buffers.
+	Overlay map[string][]byte
+	// Verify compares the generated files with the files on disk, instead
+	// of writing them, reporting the stale ones as errors.
+	Verify bool
+	// DryRun writes the plan of the run to standard-out, instead of
+	// writing anything.
+	DryRun bool
+	// KeepGoing still writes the held artifacts once a generator failed, and
+	// summarizes the failures (see genall.Runtime.KeepGoing).
+	KeepGoing bool
+	// Lint checks the markers of the packages instead of running the
+	// generators (see genall.Runtime.Lint).
+	Lint bool
+	// Parallelism is the maximum number of generators run concurrently,
+	// the number of CPUs if zero.
+	Parallelism int
+	// Diagnostics is the format of the reported errors and warnings.
+	Diagnostics genall.DiagnosticsFormat
+	// ErrorReport reports the errors again at the end of the run, grouped
+	// by package and file (see genall.Runtime.ErrorReport).
+	ErrorReport bool
+	// Timings is the format of the report of the timings of the phases of
+	// the run, if set (see genall.Runtime.Timings).
+	Timings genall.TimingsFormat
+	// CheckConsistency cross-checks the manifests of the run once all the
+	// generators ran (see genall.Runtime.CheckConsistency).
+	CheckConsistency bool
+	// GoHeader is the header of the generated Go files, for the generators
+	// whose own header file isn't set.
+	GoHeader genall.GoHeader
+	// FeatureGates enable or disable the experimental behaviors of the
+	// generators.
+	FeatureGates genall.FeatureGates
+	// GenerationManifest is the path of the generation manifest to write,
+	// if any.
+	GenerationManifest string
+	// Depfile is the path of the Make-style depfile to write, if any.
+	Depfile string
+	// Changes are the files changed since a previous run, if any, to only
+	// regenerate the artifacts of the packages they affect (see
+	// genall.Runtime.Changes).
+	Changes *genall.Changes
+	// LoadGraph is the path of the load graph to write, if any (see
+	// genall.Runtime.LoadGraph).
+	LoadGraph string
+	// ScopeErrors only fails on the errors of the packages the generators
+	// needed, reporting the others as warnings (see
+	// genall.Runtime.ScopeErrors).
+	ScopeErrors bool
+	// GoTemplates is the directory of the templates laying out the
+	// generated Go files, if any (see genall.LoadGoTemplates).
+	GoTemplates string
+	// Cluster is the cluster that the apply output rule applies manifests
+	// to, unless the rule sets its own, and that audits read from.
+	Cluster genall.ClusterConfig
+	// LineEndings are the line endings of the generated files.
+	LineEndings genall.LineEndings
+	// PinnedVersion is the version of controller-gen recorded in the
+	// generated artifacts, if set, instead of the version of the binary
+	// (see genall.GenerationContext.Version).
+	PinnedVersion string
+	// CustomMarkers are the markers declared by the project, collected along
+	// with the markers of the generators.
+	CustomMarkers []genall.CustomMarker
+	// Variables are the values of the variables interpolated in the string
+	// values of markers, by name (see markers.Variables).
+	Variables map[string]string
+	// VariablesFromEnv resolves the variables without values from the
+	// environment.
+	VariablesFromEnv bool
+	// ErrorWriter is where errors and warnings are written to, standard-error
+	// if nil.
+	ErrorWriter io.Writer
+	// OutputWriter is where the artifacts output to standard-out, and the
+	// plan of dry runs, are written to, standard-out if nil.
+	OutputWriter io.Writer
+}
+
+// Option sets an option of a run.
+type Option func(*Options)
+
+// WithOptions adds the given generators, output rules, kinds filters or
+// paths, in the syntax of the command line.
+func WithOptions(options ...string) Option {
+	return func(o *Options) {
+		o.Options = append(o.Options, options...)
+	}
+}
+
+// WithPaths adds the given paths (or go-style path patterns) to the package
+// roots to generate from.
+func WithPaths(paths ...string) Option {
+	return func(o *Options) {
+		quoted := make([]string, len(paths))
+		for i, path := range paths {
+			quoted[i] = fmt.Sprintf("%q", path)
+		}
+		o.Options = append(o.Options, "paths={"+strings.Join(quoted, ",")+"}")
+	}
+}
+
+// WithBuildTags loads the packages with the given build tags, instead of
+// DefaultBuildTags.
+func WithBuildTags(tags ...string) Option {
+	return func(o *Options) {
+		o.BuildTags = tags
+	}
+}
+
+// WithPlatform loads the packages for the given target platform, as
+// GOOS/GOARCH (e.g. linux/amd64).
+func WithPlatform(platform string) Option {
+	return func(o *Options) {
+		o.Platform = platform
+	}
+}
+
+// WithLoadCache caches the types of the dependencies loaded from export data
+// (with the ExportDataDependencies feature gate) in the given directory,
+// across runs.
+func WithLoadCache(dir string) Option {
+	return func(o *Options) {
+		o.LoadCache = dir
+	}
+}
+
+// WithOverlay loads packages with the given contents of files, by absolute
+// path, instead of the ones on disk (see packages.Config.Overlay).
+func WithOverlay(overlay map[string][]byte) Option {
+	return func(o *Options) {
+		o.Overlay = overlay
+	}
+}
+
+// WithVerify sets whether to compare the generated files with the files on
+// disk, instead of writing them.
+func WithVerify(verify bool) Option {
+	return func(o *Options) {
+		o.Verify = verify
+	}
+}
+
+// WithDryRun sets whether to write the plan of the run to standard-out,
+// instead of writing anything.
+func WithDryRun(dryRun bool) Option {
+	return func(o *Options) {
+		o.DryRun = dryRun
+	}
+}
+
+// WithKeepGoing sets whether to still write the held artifacts once a
+// generator failed, summarizing the failures.
+func WithKeepGoing(keepGoing bool) Option {
+	return func(o *Options) {
+		o.KeepGoing = keepGoing
+	}
+}
+
+// WithLint sets whether to check the markers of the packages, instead of
+// running the generators.
+func WithLint(lint bool) Option {
+	return func(o *Options) {
+		o.Lint = lint
+	}
+}
+
+// WithParallelism runs at most the given number of generators concurrently.
+func WithParallelism(parallelism int) Option {
+	return func(o *Options) {
+		o.Parallelism = parallelism
+	}
+}
+
+// WithDiagnostics reports errors and warnings in the given format.
+func WithDiagnostics(format genall.DiagnosticsFormat) Option {
+	return func(o *Options) {
+		o.Diagnostics = format
+	}
+}
+
+// WithErrorReport sets whether to report the errors again at the end of the
+// run, grouped by package and file.
+func WithErrorReport(errorReport bool) Option {
+	return func(o *Options) {
+		o.ErrorReport = errorReport
+	}
+}
+
+// WithTimings reports the wall time and allocations of the phases of the
+// run in the given format at its end, if set.
+func WithTimings(format genall.TimingsFormat) Option {
+	return func(o *Options) {
+		o.Timings = format
+	}
+}
+
+// WithConsistencyCheck cross-checks the manifests of the run once all the
+// generators ran, failing it when they drifted from each other.
+func WithConsistencyCheck(check bool) Option {
+	return func(o *Options) {
+		o.CheckConsistency = check
+	}
+}
+
+// WithGoHeader sets the header of the generated Go files, for the generators
+// whose own header file isn't set.
+func WithGoHeader(header genall.GoHeader) Option {
+	return func(o *Options) {
+		o.GoHeader = header
+	}
+}
+
+// WithGenerationManifest writes the generation manifest of the run to the
+// given path.
+func WithGenerationManifest(path string) Option {
+	return func(o *Options) {
+		o.GenerationManifest = path
+	}
+}
+
+// WithDepfile writes a Make-style depfile of the run to the given path.
+func WithDepfile(path string) Option {
+	return func(o *Options) {
+		o.Depfile = path
+	}
+}
+
+// WithChanges only regenerates the artifacts of the packages affected by the
+// given changes, e.g. from genall.ChangesSinceRef or
+// genall.ChangesSinceManifest.
+func WithChanges(changes *genall.Changes) Option {
+	return func(o *Options) {
+		o.Changes = changes
+	}
+}
+
+// WithLoadGraph writes the packages loaded during the run, and why, to the
+// given path.
+func WithLoadGraph(path string) Option {
+	return func(o *Options) {
+		o.LoadGraph = path
+	}
+}
+
+// WithScopeErrors sets whether to only fail on the errors of the packages the
+// generators needed, reporting the errors of the other dependencies as
+// warnings.
+func WithScopeErrors(scopeErrors bool) Option {
+	return func(o *Options) {
+		o.ScopeErrors = scopeErrors
+	}
+}
+
+// WithFeatureGates enables or disables the given experimental behaviors of
+// the generators.
+func WithFeatureGates(gates genall.FeatureGates) Option {
+	return func(o *Options) {
+		o.FeatureGates = gates
+	}
+}
+
+// WithGoTemplates lays out the generated Go files with the templates of the
+// given directory.
+func WithGoTemplates(dir string) Option {
+	return func(o *Options) {
+		o.GoTemplates = dir
+	}
+}
+
+// WithLineEndings ends the lines of the generated files with the given line
+// endings, whatever the platform.
+func WithLineEndings(endings genall.LineEndings) Option {
+	return func(o *Options) {
+		o.LineEndings = endings
+	}
+}
+
+// WithPinnedVersion records the given version of controller-gen in the
+// generated artifacts, instead of the version of the binary.
+func WithPinnedVersion(version string) Option {
+	return func(o *Options) {
+		o.PinnedVersion = version
+	}
+}
+
+// WithCluster applies manifests to the given cluster with the apply output
+// rule, unless the rule sets its own, and audits the given cluster.
+func WithCluster(cluster genall.ClusterConfig) Option {
+	return func(o *Options) {
+		o.Cluster = cluster
+	}
+}
+
+// WithCustomMarkers adds the given custom markers of the project (see
+// genall.CustomMarker).
+func WithCustomMarkers(customMarkers ...genall.CustomMarker) Option {
+	return func(o *Options) {
+		o.CustomMarkers = append(o.CustomMarkers, customMarkers...)
+	}
+}
+
+// WithVariables sets the values of the variables interpolated in the string
+// values of markers, overriding the ones already set.
+func WithVariables(vars map[string]string) Option {
+	return func(o *Options) {
+		if o.Variables == nil {
+			o.Variables = make(map[string]string, len(vars))
+		}
+		maps.Copy(o.Variables, vars)
+	}
+}
+
+// WithVariablesFromEnv resolves the variables interpolated in the string
+// values of markers from the environment, when they have no value.
+func WithVariablesFromEnv(fromEnv bool) Option {
+	return func(o *Options) {
+		o.VariablesFromEnv = fromEnv
+	}
+}
+
+// WithErrorWriter writes errors and warnings to the given writer, instead of
+// standard-error.
+func WithErrorWriter(w io.Writer) Option {
+	return func(o *Options) {
+		o.ErrorWriter = w
+	}
+}
+
+// WithOutputWriter writes the artifacts output to standard-out, and the plan
+// of dry runs, to the given writer, instead of standard-out.
+func WithOutputWriter(w io.Writer) Option {
+	return func(o *Options) {
+		o.OutputWriter = w
+	}
+}
+
+// NewRuntime loads the packages of the given options, returning the runtime
+// running their generators.  The context bounds the loading of packages.
+func NewRuntime(ctx context.Context, opts ...Option) (*genall.Runtime, error) {
+	o := Options{
+		BuildTags:   DefaultBuildTags,
+		Diagnostics: genall.DiagnosticsText,
+		LineEndings: genall.LineEndingsLF,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Diagnostics != genall.DiagnosticsText && o.Diagnostics != genall.DiagnosticsJSON {
+		return nil, fmt.Errorf("unknown diagnostics format %q, must be %s or %s", o.Diagnostics, genall.DiagnosticsText, genall.DiagnosticsJSON)
+	}
+	if o.Timings != "" && o.Timings != genall.TimingsText && o.Timings != genall.TimingsJSON {
+		return nil, fmt.Errorf("unknown timings format %q, must be %s or %s", o.Timings, genall.TimingsText, genall.TimingsJSON)
+	}
+	if o.LineEndings != genall.LineEndingsLF && o.LineEndings != genall.LineEndingsCRLF {
+		return nil, fmt.Errorf("unknown line endings %q, must be %s or %s", o.LineEndings, genall.LineEndingsLF, genall.LineEndingsCRLF)
+	}
+
+	cfg := &packages.Config{
+		Context:    ctx,
+		BuildFlags: []string{"-tags=" + strings.Join(o.BuildTags, ",")},
+		Overlay:    o.Overlay,
+	}
+	if o.FeatureGates.Enabled(genall.ExportDataDependencies) {
+		// only used if the generators don't need the markers of dependencies
+		cfg.Mode |= packages.NeedExportFile
+	}
+	if o.Platform != "" {
+		goos, goarch, ok := strings.Cut(o.Platform, "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("invalid platform %q, must be GOOS/GOARCH (e.g. linux/amd64)", o.Platform)
+		}
+		cfg.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch)
+	}
+	rt, err := genall.FromOptionsWithConfig(cfg, optionsRegistry, o.Options)
+	if err != nil {
+		return nil, err
+	}
+	if len(rt.Generators) == 0 {
+		return nil, fmt.Errorf("no generators specified")
+	}
+	rt.Verify = o.Verify
+	rt.DryRun = o.DryRun
+	rt.KeepGoing = o.KeepGoing
+	rt.Lint = o.Lint
+	rt.KnownGenerators = Generators()
+	rt.Diagnostics = o.Diagnostics
+	rt.ErrorReport = o.ErrorReport
+	rt.Timings = o.Timings
+	rt.CheckConsistency = o.CheckConsistency
+	rt.GoHeader = o.GoHeader
+	rt.FeatureGates = o.FeatureGates
+	rt.GenerationManifest = o.GenerationManifest
+	rt.Depfile = o.Depfile
+	rt.Changes = o.Changes
+	rt.LoadGraph = o.LoadGraph
+	rt.ScopeErrors = o.ScopeErrors
+	if o.LoadCache != "" {
+		rt.Checker.Cache = &loader.TypesCache{Dir: o.LoadCache}
+	}
+	rt.Cluster = o.Cluster
+	rt.LineEndings = o.LineEndings
+	rt.Version = o.PinnedVersion
+	if err := genall.RegisterCustomMarkers(rt.Collector.Registry, o.CustomMarkers); err != nil {
+		return nil, err
+	}
+	rt.CustomMarkers = o.CustomMarkers
+	if len(o.Variables) > 0 || o.VariablesFromEnv {
+		rt.Collector.Variables = &markers.Variables{Values: o.Variables, FromEnv: o.VariablesFromEnv}
+	}
+	if o.GoTemplates != "" {
+		if rt.GoTemplates, err = genall.LoadGoTemplates(o.GoTemplates); err != nil {
+			return nil, err
+		}
+	}
+	rt.ErrorWriter = o.ErrorWriter
+	rt.OutputWriter = o.OutputWriter
+	rt.Parallelism = o.Parallelism
+	if rt.Parallelism <= 0 {
+		rt.Parallelism = runtime.GOMAXPROCS(0)
+	}
+	return rt, nil
+}
+
+// Run runs the generators of the given options, as controller-gen does,
+// returning ErrGenerationFailed if any failed, once their errors were
+// written.  Once the context is done, the generators not started yet are
+// skipped, and the context's error is returned.
+func Run(ctx context.Context, opts ...Option) error {
+	rt, err := NewRuntime(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	if hadErrs := rt.RunContext(ctx); hadErrs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return ErrGenerationFailed
+	}
+	return nil
+}
@@ -0,0 +1,841 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Running the generators", func() {
+	var outDir string
+
+	BeforeEach(func() {
+		outDir = GinkgoT().TempDir()
+
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("../docs/testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	It("should run the generators of the given options", func() {
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "output:crd:dir="+outDir),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+
+		Expect(filepath.Join(outDir, "testdata.kubebuilder.io_widgets.yaml")).To(BeARegularFile())
+		Expect(filepath.Join(outDir, "testdata.kubebuilder.io_gizmoes.yaml")).To(BeARegularFile())
+	})
+
+	It("should generate the same deep-copies with the dependencies loaded from export data, or from their cache", func() {
+		generate := func(gates genall.FeatureGates, opts ...controllergen.Option) string {
+			dir := GinkgoT().TempDir()
+			var errOut bytes.Buffer
+			Expect(controllergen.Run(context.Background(), append([]controllergen.Option{
+				controllergen.WithOptions("object", "output:dir="+dir),
+				controllergen.WithPaths("./api/v1"),
+				controllergen.WithFeatureGates(gates),
+				controllergen.WithErrorWriter(&errOut),
+			}, opts...)...)).To(Succeed(), errOut.String())
+			out, err := os.ReadFile(filepath.Join(dir, "zz_generated.deepcopy.go"))
+			Expect(err).NotTo(HaveOccurred())
+			return string(out)
+		}
+
+		expected := generate(nil)
+		Expect(generate(genall.FeatureGates{genall.ExportDataDependencies: true})).To(Equal(expected))
+
+		By("caching the types of the dependencies, and reading them from the cache the next time")
+		cacheDir := GinkgoT().TempDir()
+		Expect(generate(genall.FeatureGates{genall.ExportDataDependencies: true}, controllergen.WithLoadCache(cacheDir))).To(Equal(expected))
+		Expect(os.ReadDir(cacheDir)).NotTo(BeEmpty())
+		Expect(generate(genall.FeatureGates{genall.ExportDataDependencies: true}, controllergen.WithLoadCache(cacheDir))).To(Equal(expected))
+	})
+
+	It("should generate the same artifacts when releasing the syntax of the packages once the generators are done with them", func() {
+		generate := func(gates genall.FeatureGates) map[string]string {
+			dir := GinkgoT().TempDir()
+			var errOut bytes.Buffer
+			Expect(controllergen.Run(context.Background(),
+				controllergen.WithOptions("object", "crd", "output:dir="+dir),
+				controllergen.WithPaths("./api/v1"),
+				controllergen.WithFeatureGates(gates),
+				controllergen.WithErrorWriter(&errOut),
+			)).To(Succeed(), errOut.String())
+			entries, err := os.ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			out := make(map[string]string, len(entries))
+			for _, entry := range entries {
+				contents, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+				Expect(err).NotTo(HaveOccurred())
+				out[entry.Name()] = string(contents)
+			}
+			return out
+		}
+
+		expected := generate(nil)
+		Expect(expected).To(HaveKey("zz_generated.deepcopy.go"))
+		Expect(generate(genall.FeatureGates{genall.ReleaseSyntax: true})).To(Equal(expected))
+	})
+
+	// generateAll runs the generators that don't need arguments, and that
+	// write to the output directory rather than beside the packages, with the
+	// given options, returning the generated files by path.
+	generateAll := func(opts ...controllergen.Option) map[string]string {
+		dir := GinkgoT().TempDir()
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(), append([]controllergen.Option{
+			controllergen.WithOptions(
+				"crd", "crdinstaller", "rbac", "object", "smdschema", "swagger",
+				"defaulter", "validation", "conditions", "finalizer", "events", "keys",
+				"metrics", "roundtrip", "builder", "registry", "patch", "ssamigration",
+				"docs", "samples", "schematest", "celtest", "jsonschema", "cue",
+				"typescript", "pydantic", "rust", "protobuf", "olm", "kustomize",
+				"webhook", "admissionpolicy", "mutatingadmissionpolicy",
+				"output:dir="+dir,
+			),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithErrorWriter(&errOut),
+		}, opts...)...)).To(Succeed(), errOut.String())
+		out := make(map[string]string)
+		Expect(filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			out[rel] = string(contents)
+			return nil
+		})).To(Succeed())
+		return out
+	}
+
+	It("should generate byte-identical artifacts when running all generators twice", func() {
+		expected := generateAll()
+		Expect(expected).To(HaveKey("zz_generated.deepcopy.go"))
+		Expect(expected).To(HaveKey("testdata.kubebuilder.io_widgets.yaml"))
+		Expect(generateAll()).To(Equal(expected))
+	})
+
+	It("should generate the same artifacts from anywhere, free of environment-specific data", func() {
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		home, err := os.UserHomeDir()
+		Expect(err).NotTo(HaveOccurred())
+		modCache, err := exec.Command("go", "env", "GOMODCACHE").Output()
+		Expect(err).NotTo(HaveOccurred())
+		environment := []string{cwd, home, string(bytes.TrimSpace(modCache))}
+
+		By("generating from copies of the packages in different directories")
+		var outs []map[string]string
+		for range 2 {
+			copyDir := filepath.Join(GinkgoT().TempDir(), "module")
+			Expect(os.CopyFS(copyDir, os.DirFS(cwd))).To(Succeed())
+			Expect(os.Chdir(copyDir)).To(Succeed())
+			outs = append(outs, generateAll(controllergen.WithPinnedVersion("v0.0.0-pinned")))
+			Expect(os.Chdir(cwd)).To(Succeed())
+			environment = append(environment, copyDir)
+		}
+		Expect(outs[1]).To(Equal(outs[0]))
+
+		By("checking the artifacts don't refer to the machine, nor to the build of controller-gen")
+		pinned := false
+		for path, contents := range outs[0] {
+			for _, env := range environment {
+				Expect(contents).NotTo(ContainSubstring(env), path)
+			}
+			pinned = pinned || strings.Contains(contents, "controller-gen.kubebuilder.io/version: v0.0.0-pinned\n")
+		}
+		Expect(pinned).To(BeTrue(), "the pinned version isn't recorded in any artifact")
+	})
+
+	It("should bound the peak memory of a run over many packages when releasing their syntax", func() {
+		By("setting up a module of many independent packages of many types")
+		const packages, typesPerPackage = 16, 300
+		moduleDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte("module example.com/many\n\ngo 1.24\n"), 0o644)).To(Succeed())
+		for i := range packages {
+			var src strings.Builder
+			fmt.Fprintf(&src, "// +kubebuilder:object:generate=true\npackage pkg%d\n", i)
+			for j := range typesPerPackage {
+				fmt.Fprintf(&src, "\n// Type%d is a type.\ntype Type%d struct {\n", j, j)
+				for k := range 10 {
+					fmt.Fprintf(&src, "\t// Field%d is a field.\n\tField%d map[string][]string `json:\"field%d,omitempty\"`\n", k, k, k)
+				}
+				src.WriteString("}\n")
+			}
+			pkgDir := filepath.Join(moduleDir, fmt.Sprintf("pkg%d", i))
+			Expect(os.Mkdir(pkgDir, 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(pkgDir, "types.go"), []byte(src.String()), 0o644)).To(Succeed())
+		}
+		Expect(os.Chdir(moduleDir)).To(Succeed())
+
+		// the heap is sampled while generating, collecting garbage often so
+		// that it's close to the memory in use
+		defer debug.SetGCPercent(debug.SetGCPercent(5))
+		peakHeap := func(gates genall.FeatureGates) uint64 {
+			runtime.GC()
+			var peak atomic.Uint64
+			done := make(chan struct{})
+			var sampling sync.WaitGroup
+			sampling.Go(func() {
+				ticker := time.NewTicker(time.Millisecond)
+				defer ticker.Stop()
+				var stats runtime.MemStats
+				for {
+					runtime.ReadMemStats(&stats)
+					if stats.HeapAlloc > peak.Load() {
+						peak.Store(stats.HeapAlloc)
+					}
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+				}
+			})
+			var errOut bytes.Buffer
+			Expect(controllergen.Run(context.Background(),
+				controllergen.WithOptions("object", "output:none"),
+				controllergen.WithPaths("./..."),
+				controllergen.WithFeatureGates(gates),
+				controllergen.WithErrorWriter(&errOut),
+			)).To(Succeed(), errOut.String())
+			close(done)
+			sampling.Wait()
+			return peak.Load()
+		}
+
+		withoutReleasing := peakHeap(nil)
+		withReleasing := peakHeap(genall.FeatureGates{genall.ReleaseSyntax: true})
+		GinkgoWriter.Printf("peak heap over %d packages: %d MiB, or %d MiB when releasing their syntax\n", packages, withoutReleasing>>20, withReleasing>>20)
+		Expect(withReleasing).To(BeNumerically("<", withoutReleasing*2/3))
+	})
+
+	It("should output templated CRDs into a Helm chart, kept on uninstall if asked to", func() {
+		chartDir := GinkgoT().TempDir()
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "output:crd:helm:chart="+chartDir+",templated=true,valuesKey=crds"),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithPinnedVersion("v0.0.0-test"),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+		golden.Compare(GinkgoT(), filepath.Join("..", "..", "controllergen", "testdata", "helm"), chartDir)
+
+		By("rendering the templates with the keep value set, or not")
+		contents, err := os.ReadFile(filepath.Join(chartDir, "templates", "crds", "testdata.kubebuilder.io_widgets.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		tmpl, err := template.New("crd").Parse(string(contents))
+		Expect(err).NotTo(HaveOccurred())
+		render := func(values map[string]any) map[string]any {
+			var rendered bytes.Buffer
+			Expect(tmpl.Execute(&rendered, map[string]any{"Values": map[string]any{"crds": values}})).To(Succeed())
+			var crd map[string]any
+			Expect(yaml.Unmarshal(rendered.Bytes(), &crd)).To(Succeed())
+			return crd
+		}
+		Expect(render(map[string]any{"enable": true, "keep": true})).To(HaveKeyWithValue("metadata", HaveKeyWithValue("annotations", HaveKeyWithValue("helm.sh/resource-policy", "keep"))))
+		Expect(render(map[string]any{"enable": true, "keep": false})).To(HaveKeyWithValue("metadata", HaveKeyWithValue("annotations", HaveKeyWithValue("helm.sh/resource-policy", ""))))
+		Expect(render(map[string]any{"enable": false})).To(BeNil())
+	})
+
+	Context("with protected regions in the generated files", func() {
+		generate := func(opts ...controllergen.Option) (string, error) {
+			var errOut bytes.Buffer
+			err := controllergen.Run(context.Background(), append([]controllergen.Option{
+				controllergen.WithOptions("object", "crd", "output:dir="+outDir),
+				controllergen.WithPaths("./api/v1"),
+				controllergen.WithErrorWriter(&errOut),
+			}, opts...)...)
+			return errOut.String(), err
+		}
+		deepcopyPath := func() string { return filepath.Join(outDir, "zz_generated.deepcopy.go") }
+
+		BeforeEach(func() {
+			errOut, err := generate()
+			Expect(err).NotTo(HaveOccurred(), errOut)
+		})
+
+		It("should keep the regions in their order at the end of the regenerated Go files, adding their imports", func() {
+			generated, err := os.ReadFile(deepcopyPath())
+			Expect(err).NotTo(HaveOccurred())
+			const first = `// +controllertools:protected:begin
+// Shout shouts the name of the widget.
+func (in *Widget) Shout() string {
+	return strings.ToUpper(in.Name)
+}
+// +controllertools:protected:end
+`
+			const second = `// +controllertools:protected:begin
+// Whisper whispers the name of the widget.
+func (in *Widget) Whisper() string {
+	return strings.ToLower(in.Name)
+}
+// +controllertools:protected:end
+`
+			// the regions may be anywhere, e.g. in the middle of the file
+			edited := bytes.Replace(generated, []byte("\nfunc "), []byte("\n"+first+"\nfunc "), 1)
+			edited = append(edited, "\n"+second...)
+			Expect(os.WriteFile(deepcopyPath(), edited, 0o644)).To(Succeed())
+
+			errOut, err := generate()
+			Expect(err).NotTo(HaveOccurred(), errOut)
+			regenerated, err := os.ReadFile(deepcopyPath())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(regenerated)).To(MatchRegexp(`(?s)import \(\n\t"strings"\n.*\n\)`))
+			firstAt := bytes.Index(regenerated, []byte("func (in *Widget) Shout() string"))
+			secondAt := bytes.Index(regenerated, []byte("func (in *Widget) Whisper() string"))
+			Expect(firstAt).To(BeNumerically(">", bytes.LastIndex(regenerated, []byte("DeepCopyObject"))), "the regions should follow the generated code")
+			Expect(secondAt).To(BeNumerically(">", firstAt), "the regions should keep their order")
+			Expect(bytes.Count(regenerated, []byte("protected:begin"))).To(Equal(2))
+			Expect(bytes.Count(regenerated, []byte("protected:end"))).To(Equal(2))
+
+			By("verifying the regenerated files, regions included")
+			errOut, err = generate(controllergen.WithVerify(true))
+			Expect(err).NotTo(HaveOccurred(), errOut)
+		})
+
+		It("should only keep regions in Go files", func() {
+			crdPath := filepath.Join(outDir, "testdata.kubebuilder.io_widgets.yaml")
+			generated, err := os.ReadFile(crdPath)
+			Expect(err).NotTo(HaveOccurred())
+			// not even unterminated regions matter in other files
+			edited := append(bytes.Clone(generated), "// +controllertools:protected:begin\n# kept by hand\n"...)
+			Expect(os.WriteFile(crdPath, edited, 0o644)).To(Succeed())
+
+			errOut, err := generate()
+			Expect(err).NotTo(HaveOccurred(), errOut)
+			Expect(os.ReadFile(crdPath)).To(Equal(generated))
+		})
+
+		It("should fail when the regions can't be merged into the regenerated Go files", func() {
+			generated, err := os.ReadFile(deepcopyPath())
+			Expect(err).NotTo(HaveOccurred())
+
+			By("fixing the imports of regions that aren't valid Go")
+			broken := append(bytes.Clone(generated), "\n// +controllertools:protected:begin\nfunc (in *Widget) Broken( {\n// +controllertools:protected:end\n"...)
+			Expect(os.WriteFile(deepcopyPath(), broken, 0o644)).To(Succeed())
+			errOut, err := generate()
+			Expect(err).To(HaveOccurred())
+			Expect(errOut).To(ContainSubstring("unable to keep the protected regions of " + deepcopyPath()))
+			Expect(os.ReadFile(deepcopyPath())).To(Equal(broken), "the file should be left as it was")
+
+			By("finding unterminated regions")
+			unterminated := append(bytes.Clone(generated), "\n// +controllertools:protected:begin\n"...)
+			Expect(os.WriteFile(deepcopyPath(), unterminated, 0o644)).To(Succeed())
+			errOut, err = generate()
+			Expect(err).To(HaveOccurred())
+			Expect(errOut).To(ContainSubstring(deepcopyPath() + ": unterminated protected region"))
+
+			By("finding regions ending without beginning")
+			unopened := append(bytes.Clone(generated), "\n// +controllertools:protected:end\n"...)
+			Expect(os.WriteFile(deepcopyPath(), unopened, 0o644)).To(Succeed())
+			errOut, err = generate()
+			Expect(err).To(HaveOccurred())
+			Expect(errOut).To(ContainSubstring(deepcopyPath() + ": end of a protected region without a beginning"))
+		})
+	})
+
+	It("should not write anything when verifying", func() {
+		var errOut bytes.Buffer
+		err := controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "output:crd:dir="+outDir),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithVerify(true),
+			controllergen.WithErrorWriter(&errOut),
+		)
+		Expect(err).To(MatchError(controllergen.ErrGenerationFailed))
+		Expect(errOut.String()).To(ContainSubstring("testdata.kubebuilder.io_widgets.yaml"))
+		Expect(os.ReadDir(outDir)).To(BeEmpty())
+	})
+
+	It("should end the lines of the generated files with the given line endings, and verify them with those", func() {
+		headerFile := filepath.Join(GinkgoT().TempDir(), "header.txt")
+		Expect(os.WriteFile(headerFile, []byte("# Copyright The ACME Authors.\r\n"), 0o644)).To(Succeed())
+		generate := func(endings genall.LineEndings, verify bool) error {
+			var errOut bytes.Buffer
+			err := controllergen.Run(context.Background(),
+				controllergen.WithOptions("object", "crd:headerFile="+headerFile, "output:artifacts:config="+outDir+",code="+outDir),
+				controllergen.WithPaths("./api/v1"),
+				controllergen.WithLineEndings(endings),
+				controllergen.WithVerify(verify),
+				controllergen.WithErrorWriter(&errOut),
+			)
+			GinkgoWriter.Print(errOut.String())
+			return err
+		}
+
+		By("normalizing the line endings to LF by default")
+		Expect(generate(genall.LineEndingsLF, false)).To(Succeed())
+		for _, name := range []string{"zz_generated.deepcopy.go", "testdata.kubebuilder.io_widgets.yaml"} {
+			contents, err := os.ReadFile(filepath.Join(outDir, name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).NotTo(ContainSubstring("\r"), name)
+		}
+
+		By("ending all the lines with CRLF when asked to")
+		Expect(generate(genall.LineEndingsCRLF, false)).To(Succeed())
+		for _, name := range []string{"zz_generated.deepcopy.go", "testdata.kubebuilder.io_widgets.yaml"} {
+			contents, err := os.ReadFile(filepath.Join(outDir, name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bytes.Count(contents, []byte("\n"))).To(Equal(bytes.Count(contents, []byte("\r\n"))), name)
+		}
+		Expect(os.ReadFile(filepath.Join(outDir, "testdata.kubebuilder.io_widgets.yaml"))).To(HavePrefix("# Copyright The ACME Authors.\r\n"))
+
+		By("verifying the files with the same line endings only")
+		Expect(generate(genall.LineEndingsCRLF, true)).To(Succeed())
+		Expect(generate(genall.LineEndingsLF, true)).To(MatchError(controllergen.ErrGenerationFailed))
+	})
+
+	It("should keep the state of concurrent runs apart", func() {
+		verifyDir, writeDir := filepath.Join(outDir, "verify"), filepath.Join(outDir, "write")
+		var verifyErrs, writeErrs bytes.Buffer
+		verifying, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("object", "crd", "output:dir="+verifyDir, "output:crd:bundle:file="+filepath.Join(verifyDir, "install.yaml")),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithVerify(true),
+			controllergen.WithErrorWriter(&verifyErrs),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		writing, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("object", "crd", "output:dir="+writeDir, "output:crd:bundle:file="+filepath.Join(writeDir, "install.yaml")),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithLineEndings(genall.LineEndingsCRLF),
+			controllergen.WithErrorWriter(&writeErrs),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("running them at the same time")
+		var verifyFailed, writeFailed bool
+		var wg sync.WaitGroup
+		wg.Go(func() { verifyFailed = verifying.Run() })
+		wg.Go(func() { writeFailed = writing.Run() })
+		wg.Wait()
+
+		By("only verifying the files of the verifying run, without writing any")
+		Expect(verifyFailed).To(BeTrue())
+		Expect(verifyErrs.String()).To(ContainSubstring("install.yaml"))
+		Expect(verifyErrs.String()).NotTo(ContainSubstring(writeDir))
+		Expect(verifyDir).NotTo(BeADirectory())
+
+		By("writing the files of the writing run, with its line endings and bundle")
+		Expect(writeFailed).To(BeFalse(), writeErrs.String())
+		bundle, err := os.ReadFile(filepath.Join(writeDir, "install.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(bundle)).To(ContainSubstring("name: widgets.testdata.kubebuilder.io\r\n"))
+		Expect(string(bundle)).To(ContainSubstring("name: gizmoes.testdata.kubebuilder.io\r\n"))
+		Expect(bytes.Count(bundle, []byte("\n"))).To(Equal(bytes.Count(bundle, []byte("\r\n"))))
+		deepCopy, err := os.ReadFile(filepath.Join(writeDir, "zz_generated.deepcopy.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes.Count(deepCopy, []byte("\n"))).To(Equal(bytes.Count(deepCopy, []byte("\r\n"))))
+	})
+
+	It("should refuse unknown line endings", func() {
+		_, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("object"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithLineEndings("cr"),
+		)
+		Expect(err).To(MatchError(ContainSubstring(`unknown line endings "cr"`)))
+	})
+
+	It("should lay out the generated Go files with the given templates", func() {
+		tmplDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(tmplDir, "zz_generated.deepcopy.go.tmpl"), []byte(`{{.Generated}}
+
+// Package {{.Package}} is laid out by a template.
+package {{.Package}}
+{{with .Imports}}
+import (
+{{range .}}	{{.}}
+{{end}})
+{{end}}
+{{.Body}}`), 0o644)).To(Succeed())
+
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("object", "output:object:artifacts:config="+outDir+",code="+outDir),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithGoTemplates(tmplDir),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+
+		contents, err := os.ReadFile(filepath.Join(outDir, "zz_generated.deepcopy.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(HavePrefix("// Code generated by controller-gen. DO NOT EDIT.\n\n// Package v1 is laid out by a template.\npackage v1\n"))
+		Expect(string(contents)).To(ContainSubstring("func (in *Widget) DeepCopyInto(out *Widget) {"))
+	})
+
+	It("should collect the custom markers, passing them through to their extension", func() {
+		rt, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("object"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithCustomMarkers(
+				genall.CustomMarker{Name: "acme:owner", Target: "type", Type: "string", Extension: "ownership"},
+				genall.CustomMarker{Name: "acme:retention", Target: "type", Extension: "ownership", Arguments: []genall.CustomArgument{
+					{Name: "days", Type: "int"},
+					{Name: "archive", Type: "bool", Optional: true},
+				}},
+			),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		extensionMarkers := make(map[string]map[string][]any)
+		Expect(markers.EachType(rt.Collector, rt.Roots[0], func(info *markers.TypeInfo) {
+			extensionMarkers[info.Name] = rt.ExtensionMarkers("ownership", info.Markers)
+		})).To(Succeed())
+		Expect(extensionMarkers).To(HaveKeyWithValue("Widget", map[string][]any{
+			"acme:owner":     {"team-a"},
+			"acme:retention": {map[string]any{"days": 30, "archive": false}},
+		}))
+		Expect(extensionMarkers).To(HaveKeyWithValue("WidgetList", BeEmpty()))
+	})
+
+	It("should refuse custom markers of unknown types", func() {
+		_, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("object"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithCustomMarkers(genall.CustomMarker{Name: "acme:owner", Target: "type", Type: "float"}),
+		)
+		Expect(err).To(MatchError(ContainSubstring(`unknown type "float" of custom marker "acme:owner"`)))
+	})
+
+	It("should lint the markers, reporting the ones only generators that aren't enabled use", func() {
+		var errOut bytes.Buffer
+		err := controllergen.Run(context.Background(),
+			controllergen.WithOptions("object", "output:dir="+outDir),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithLint(true),
+			controllergen.WithDiagnostics(genall.DiagnosticsJSON),
+			controllergen.WithErrorWriter(&errOut),
+		)
+		Expect(err).To(MatchError(controllergen.ErrGenerationFailed))
+		Expect(errOut.String()).To(ContainSubstring(`"marker":"kubebuilder:validation:MinLength"`))
+		Expect(errOut.String()).To(MatchRegexp(`marker \+kubebuilder:validation:MinLength is unused: it's only used by the [a-z, ]*\bcrd\b`))
+		Expect(errOut.String()).NotTo(ContainSubstring("acme:owner"))
+		Expect(os.ReadDir(outDir)).To(BeEmpty())
+	})
+
+	It("should report the errors again at the end of the run, grouped by package and file", func() {
+		var errOut bytes.Buffer
+		err := controllergen.Run(context.Background(),
+			controllergen.WithOptions("object", "output:dir="+outDir),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithLint(true),
+			controllergen.WithErrorReport(true),
+			controllergen.WithErrorWriter(&errOut),
+		)
+		Expect(err).To(MatchError(controllergen.ErrGenerationFailed))
+		report := errOut.String()
+		Expect(report).To(MatchRegexp(`\nerror report: \d+ error\(s\) in 1 package\(s\)\n`))
+		Expect(report).To(MatchRegexp(`\ntestdata\.kubebuilder\.io/docs/api/v1: \d+ error\(s\)\n  \S+types\.go: \d+ error\(s\)\n    \d+:\d+: `))
+		Expect(report).To(MatchRegexp(`\n    33:5: marker \+kubebuilder:validation:MinLength is unused`))
+	})
+
+	It("should report the timings of the phases of the run", func() {
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("crd", "output:dir="+outDir),
+			controllergen.WithPaths("./api/..."),
+			controllergen.WithTimings(genall.TimingsJSON),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+
+		var report struct {
+			Phases []genall.PhaseTiming `json:"phases"`
+		}
+		Expect(json.Unmarshal(errOut.Bytes(), &report)).To(Succeed())
+		Expect(report.Phases).To(ConsistOf(
+			SatisfyAll(HaveField("Phase", genall.PhaseLoad), HaveField("Duration", BeNumerically(">", 0)), HaveField("Allocations", BeNumerically(">", 0))),
+			SatisfyAll(HaveField("Phase", genall.PhaseMarkers), HaveField("Duration", BeNumerically(">", 0)), HaveField("Cumulative", true)),
+			SatisfyAll(HaveField("Phase", genall.PhaseGenerator), HaveField("Name", "crd"), HaveField("AllocatedBytes", BeNumerically(">", 0))),
+			SatisfyAll(HaveField("Phase", genall.PhaseOutput), HaveField("Name", "dir"), HaveField("Cumulative", true)),
+		))
+
+		By("refusing unknown formats")
+		_, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("crd"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithTimings("xml"),
+		)
+		Expect(err).To(MatchError(ContainSubstring(`unknown timings format "xml"`)))
+	})
+
+	It("should fail when the manifests of the run drifted from each other", func() {
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		types, err := os.ReadFile(filepath.Join("api", "v1", "types.go"))
+		Expect(err).NotTo(HaveOccurred())
+		overlay := map[string][]byte{
+			filepath.Join(cwd, "api", "v1", "types.go"): bytes.Replace(types, []byte("type Widget struct"), []byte("// +kubebuilder:subresource:status\ntype Widget struct"), 1),
+			filepath.Join(cwd, "api", "v1", "rbac.go"):  []byte("package v1\n\n// +kubebuilder:rbac:groups=testdata.kubebuilder.io,resources=widgets,verbs=get;list;update\n"),
+		}
+		run := func() (string, error) {
+			var errOut bytes.Buffer
+			err := controllergen.Run(context.Background(),
+				controllergen.WithOptions("crd", "rbac:roleName=manager-role", "smdschema", "output:dir="+GinkgoT().TempDir()),
+				controllergen.WithPaths("./api/..."),
+				controllergen.WithOverlay(overlay),
+				controllergen.WithConsistencyCheck(true),
+				controllergen.WithErrorWriter(&errOut),
+			)
+			return errOut.String(), err
+		}
+
+		errOut, err := run()
+		Expect(err).To(MatchError(controllergen.ErrGenerationFailed))
+		Expect(errOut).To(ContainSubstring("role.yaml: ClusterRole manager-role can write widgets.testdata.kubebuilder.io, but lacks update on their finalizers; add // +kubebuilder:rbac:groups=testdata.kubebuilder.io,resources=widgets/finalizers,verbs=update"))
+		Expect(errOut).To(ContainSubstring("role.yaml: ClusterRole manager-role can write widgets.testdata.kubebuilder.io, but lacks get, update, patch on their status"))
+		Expect(errOut).NotTo(ContainSubstring("gizmoes"))
+
+		By("granting the finalizers and status of the kind")
+		overlay[filepath.Join(cwd, "api", "v1", "rbac.go")] = []byte("package v1\n\n" +
+			"// +kubebuilder:rbac:groups=testdata.kubebuilder.io,resources=widgets,verbs=get;list;update\n" +
+			"// +kubebuilder:rbac:groups=testdata.kubebuilder.io,resources=widgets/status,verbs=get;update;patch\n" +
+			"// +kubebuilder:rbac:groups=testdata.kubebuilder.io,resources=widgets/finalizers,verbs=update\n")
+		errOut, err = run()
+		Expect(err).NotTo(HaveOccurred(), errOut)
+	})
+
+	It("should lint the markers without findings when their generators are enabled", func() {
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("object", "crd"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithLint(true),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+	})
+
+	It("should interpolate the given variables in markers, the later values overriding the earlier ones", func() {
+		rt, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("object"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithVariables(map[string]string{"NAMESPACE": "system", "PREFIX": "foo"}),
+			controllergen.WithVariables(map[string]string{"NAMESPACE": "override"}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rt.Collector.Variables).To(Equal(&markers.Variables{Values: map[string]string{"NAMESPACE": "override", "PREFIX": "foo"}}))
+
+		rt, err = controllergen.NewRuntime(context.Background(), controllergen.WithOptions("object"), controllergen.WithPaths("./api/v1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rt.Collector.Variables).To(BeNil())
+	})
+
+	It("should load the packages for the given platform", func() {
+		rt, err := controllergen.NewRuntime(context.Background(),
+			controllergen.WithOptions("object"),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithPlatform("linux/386"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rt.Roots).NotTo(BeEmpty())
+		Expect(rt.Roots[0].TypesSizes.Sizeof(types.Typ[types.Int])).To(BeEquivalentTo(4))
+
+		_, err = controllergen.NewRuntime(context.Background(), controllergen.WithOptions("object"), controllergen.WithPlatform("linux"))
+		Expect(err).To(MatchError(ContainSubstring(`invalid platform "linux"`)))
+	})
+
+	It("should write the packages loaded during the run", func() {
+		loadGraph := filepath.Join(outDir, "load-graph.json")
+		var errOut bytes.Buffer
+		Expect(controllergen.Run(context.Background(),
+			controllergen.WithOptions("object", "output:dir="+outDir),
+			controllergen.WithPaths("./api/v1"),
+			controllergen.WithLoadGraph(loadGraph),
+			controllergen.WithErrorWriter(&errOut),
+		)).To(Succeed(), errOut.String())
+
+		contents, err := os.ReadFile(loadGraph)
+		Expect(err).NotTo(HaveOccurred())
+		var graph []loader.PackageLoad
+		Expect(json.Unmarshal(contents, &graph)).To(Succeed())
+		Expect(graph).NotTo(BeEmpty())
+		Expect(graph[0]).To(And(HaveField("Root", true), HaveField("Types", loader.TypesFromSource)))
+		Expect(graph).To(ContainElement(And(HaveField("Path", "k8s.io/apimachinery/pkg/apis/meta/v1"), HaveField("ReferencedBy", Not(BeEmpty())))))
+	})
+
+	It("should only fail on the errors of the packages the generators needed when scoping errors", func() {
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		// a dependency nothing needs the types of, failing to list
+		overlay := map[string][]byte{
+			filepath.Join(cwd, "api", "v1", "broken_import.go"): []byte("package v1\n\nimport _ \"testdata.kubebuilder.io/docs/broken\"\n"),
+			filepath.Join(cwd, "broken", "a.go"):                []byte("package broken\n"),
+			filepath.Join(cwd, "broken", "b.go"):                []byte("package other\n"),
+		}
+		run := func(scopeErrors bool) (string, error) {
+			var errOut bytes.Buffer
+			err := controllergen.Run(context.Background(),
+				controllergen.WithOptions("crd", "output:dir="+GinkgoT().TempDir()),
+				controllergen.WithPaths("./api/v1"),
+				controllergen.WithOverlay(overlay),
+				controllergen.WithScopeErrors(scopeErrors),
+				controllergen.WithErrorWriter(&errOut),
+			)
+			return errOut.String(), err
+		}
+
+		errOut, err := run(false)
+		Expect(err).To(MatchError(controllergen.ErrGenerationFailed))
+		Expect(errOut).To(ContainSubstring("found packages broken (a.go) and other (b.go)"))
+		errOut, err = run(true)
+		Expect(err).NotTo(HaveOccurred(), errOut)
+	})
+
+	It("should only regenerate the artifacts of the packages affected by the changes since the last run", func() {
+		By("setting up a module with two API packages, one of them importing a third one")
+		modDir := GinkgoT().TempDir()
+		files := map[string]string{
+			"go.mod":      "module example.com/incremental\n\ngo 1.24\n",
+			"a/types.go":  "// +kubebuilder:object:generate=true\npackage a\n\ntype Widget struct {\n\tItems []string\n}\n",
+			"b/types.go":  "// +kubebuilder:object:generate=true\npackage b\n\nimport \"example.com/incremental/c\"\n\ntype Gizmo struct {\n\tItems []c.Size\n}\n",
+			"c/size.go":   "package c\n\ntype Size string\n",
+			"c/size_2.go": "package c\n\nconst DefaultSize Size = \"1Gi\"\n",
+		}
+		for name, contents := range files {
+			Expect(os.MkdirAll(filepath.Join(modDir, filepath.Dir(name)), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(modDir, name), []byte(contents), 0o644)).To(Succeed())
+		}
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(modDir)).To(Succeed())
+		DeferCleanup(os.Chdir, cwd)
+
+		manifest := filepath.Join(modDir, "manifest.json")
+		generate := func() {
+			changes, err := genall.ChangesSinceManifest(manifest)
+			Expect(err).NotTo(HaveOccurred())
+			var errOut bytes.Buffer
+			Expect(controllergen.Run(context.Background(),
+				controllergen.WithOptions("object"),
+				controllergen.WithPaths("./a", "./b"),
+				controllergen.WithGenerationManifest(manifest),
+				controllergen.WithChanges(changes),
+				controllergen.WithErrorWriter(&errOut),
+			)).To(Succeed(), errOut.String())
+		}
+		// left out of the packages, as generated files are
+		stale := func(pkg string) string {
+			return "//go:build !ignore_autogenerated\n\n// stale\npackage " + pkg + "\n"
+		}
+		markStale := func() {
+			for _, pkg := range []string{"a", "b"} {
+				Expect(os.WriteFile(filepath.Join(pkg, "zz_generated.deepcopy.go"), []byte(stale(pkg)), 0o644)).To(Succeed())
+			}
+		}
+		generated := func(pkg string) string {
+			contents, err := os.ReadFile(filepath.Join(pkg, "zz_generated.deepcopy.go"))
+			Expect(err).NotTo(HaveOccurred())
+			return string(contents)
+		}
+
+		By("generating everything without a previous run")
+		generate()
+		Expect(generated("a")).To(ContainSubstring("func (in *Widget) DeepCopy() *Widget"))
+		Expect(generated("b")).To(ContainSubstring("func (in *Gizmo) DeepCopy() *Gizmo"))
+		contents, err := os.ReadFile(manifest)
+		Expect(err).NotTo(HaveOccurred())
+		var written genall.GenerationManifest
+		Expect(json.Unmarshal(contents, &written)).To(Succeed())
+		Expect(written.Inputs).To(Equal([]string{"a/types.go", "b/types.go", "c/size.go", "c/size_2.go"}))
+		Expect(written.InputDigests).To(HaveLen(4))
+
+		By("generating nothing without changes")
+		markStale()
+		generate()
+		Expect(generated("a")).To(Equal(stale("a")))
+		Expect(generated("b")).To(Equal(stale("b")))
+
+		By("only regenerating the package importing a changed package")
+		Expect(os.WriteFile("c/size.go", []byte("package c\n\n// Size is a quantity.\ntype Size string\n"), 0o644)).To(Succeed())
+		generate()
+		Expect(generated("a")).To(Equal(stale("a")))
+		Expect(generated("b")).To(ContainSubstring("func (in *Gizmo) DeepCopy() *Gizmo"))
+
+		By("only regenerating the package with an added file, keeping the outputs of the other one in the manifest")
+		markStale()
+		Expect(os.WriteFile("a/more_types.go", []byte("package a\n\ntype Sprocket struct {\n\tItems []string\n}\n"), 0o644)).To(Succeed())
+		generate()
+		Expect(generated("a")).To(ContainSubstring("func (in *Sprocket) DeepCopy() *Sprocket"))
+		Expect(generated("b")).To(Equal(stale("b")))
+		contents, err = os.ReadFile(manifest)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(json.Unmarshal(contents, &written)).To(Succeed())
+		Expect(written.Outputs).To(ConsistOf(HaveField("Path", "a/zz_generated.deepcopy.go"), HaveField("Path", "b/zz_generated.deepcopy.go")))
+	})
+
+	It("should fail without generators", func() {
+		Expect(controllergen.Run(context.Background(), controllergen.WithPaths("./api/..."))).To(MatchError("no generators specified"))
+	})
+
+	It("should fail on unknown options", func() {
+		_, err := controllergen.NewRuntime(context.Background(), controllergen.WithOptions("nope"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("The known generators", func() {
+	It("should be returned by name, without sharing the map", func() {
+		gens := controllergen.Generators()
+		Expect(gens).To(HaveKey("crd"))
+		Expect(gens).To(HaveKey("object"))
+		delete(gens, "crd")
+		Expect(controllergen.Generators()).To(HaveKey("crd"))
+	})
+
+	It("should be options of the registry", func() {
+		for name := range controllergen.Generators() {
+			Expect(controllergen.OptionsRegistry().Lookup("+"+name, markers.DescribesPackage)).NotTo(BeNil(), name)
+		}
+	})
+})
@@ -0,0 +1,132 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllergen_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"sigs.k8s.io/controller-tools/pkg/controllergen"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+)
+
+var _ = Describe("Watching the packages", func() {
+	const delay = 200 * time.Millisecond
+
+	var (
+		// runs are the number of runs of the generators so far.
+		runs atomic.Int32
+		out  *gbytes.Buffer
+		// stop stops watching, returning the error of Watch.
+		stop func() error
+	)
+
+	BeforeEach(func() {
+		By("setting up a module with an API package")
+		modDir := GinkgoT().TempDir()
+		files := map[string]string{
+			"go.mod":       "module example.com/watch\n\ngo 1.24\n",
+			"a/types.go":   "// +kubebuilder:object:generate=true\npackage a\n\ntype Widget struct {\n\tItems []string\n}\n",
+			"a/doc.go":     "package a\n",
+			"a/a_test.go":  "package a\n",
+			"a/helpers.go": "package a\n\nfunc helper() {}\n",
+		}
+		for name, contents := range files {
+			Expect(os.MkdirAll(filepath.Join(modDir, filepath.Dir(name)), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(modDir, name), []byte(contents), 0o644)).To(Succeed())
+		}
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(modDir)).To(Succeed())
+		DeferCleanup(os.Chdir, cwd)
+
+		By("watching the package")
+		runs.Store(0)
+		out = gbytes.NewBuffer()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		var watchErr error
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			watchErr = genall.Watch(ctx, func() (*genall.Runtime, error) {
+				runs.Add(1)
+				return controllergen.NewRuntime(ctx,
+					controllergen.WithOptions("object"),
+					controllergen.WithPaths("./..."),
+				)
+			}, delay, out)
+		}()
+		stop = func() error {
+			cancel()
+			Eventually(done).Should(BeClosed())
+			return watchErr
+		}
+		DeferCleanup(stop)
+		Eventually(out).Should(gbytes.Say("watching 1 directories for changes"))
+		Expect(runs.Load()).To(BeEquivalentTo(1))
+	})
+
+	generated := func() string {
+		contents, err := os.ReadFile(filepath.Join("a", "zz_generated.deepcopy.go"))
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	It("should regenerate once after saving several files at once, until stopped", func() {
+		Expect(generated()).To(ContainSubstring("func (in *Widget) DeepCopy() *Widget"))
+
+		Expect(os.WriteFile(filepath.Join("a", "types.go"), []byte("// +kubebuilder:object:generate=true\npackage a\n\ntype Widget struct {\n\tItems []string\n}\n\ntype Gizmo struct {\n\tSizes map[string]int\n}\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join("a", "doc.go"), []byte("// Package a is watched.\npackage a\n"), 0o644)).To(Succeed())
+		Eventually(out).Should(gbytes.Say("regenerating"))
+		Eventually(out).Should(gbytes.Say("watching 1 directories for changes"))
+		Expect(generated()).To(ContainSubstring("func (in *Gizmo) DeepCopy() *Gizmo"))
+		Consistently(runs.Load, 3*delay).Should(BeEquivalentTo(2), "the changes should be debounced, and the generated files ignored")
+
+		By("regenerating when a file is removed")
+		Expect(os.Remove(filepath.Join("a", "helpers.go"))).To(Succeed())
+		Eventually(runs.Load).Should(BeEquivalentTo(3))
+
+		Expect(stop()).To(Succeed())
+	})
+
+	It("should ignore files that didn't change, test files and generated files", func() {
+		Expect(os.WriteFile(filepath.Join("a", "doc.go"), []byte("package a\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join("a", "a_test.go"), []byte("package a\n\nvar changed = true\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join("a", "zz_generated.other.go"), []byte("// Code generated by hand. DO NOT EDIT.\n\npackage a\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join("a", "notes.txt"), []byte("not Go\n"), 0o644)).To(Succeed())
+		Consistently(runs.Load, 3*delay).Should(BeEquivalentTo(1))
+		Expect(out).NotTo(gbytes.Say("regenerating"))
+	})
+
+	It("should keep watching after failed runs", func() {
+		Expect(os.WriteFile(filepath.Join("a", "doc.go"), []byte("package a\n\nfunc broken( {\n"), 0o644)).To(Succeed())
+		Eventually(out).Should(gbytes.Say("not all generators ran successfully"))
+
+		Expect(os.WriteFile(filepath.Join("a", "doc.go"), []byte("package a\n"), 0o644)).To(Succeed())
+		Eventually(runs.Load).Should(BeEquivalentTo(3))
+		Eventually(out).Should(gbytes.Say("watching 1 directories for changes"))
+		Expect(stop()).To(Succeed())
+	})
+})
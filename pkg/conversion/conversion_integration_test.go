@@ -0,0 +1,125 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/conversion"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// outputToPackageMap keeps the generated files in memory, by package path
+// and file name.
+type outputToPackageMap map[string]*outputFile
+
+// Open implements genall.OutputRule.
+func (m outputToPackageMap) Open(pkg *loader.Package, path string) (io.WriteCloser, error) {
+	key := pkg.PkgPath + "/" + path
+	if _, ok := m[key]; !ok {
+		m[key] = &outputFile{}
+	}
+	return m[key], nil
+}
+
+type outputFile struct {
+	contents []byte
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+	o.contents = append(o.contents, p...)
+	return len(p), nil
+}
+
+func (o *outputFile) Close() error {
+	return nil
+}
+
+var _ = Describe("Conversion Generation", func() {
+	var cwd string
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		var err error
+		cwd, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	It("should generate conversions between the spoke versions and the hub version", func() {
+		output := make(outputToPackageMap)
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("conversion", markers.DescribesPackage, conversion.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			fmt.Sprintf("conversion:headerFile=%s", path.Join(cwd, "../../hack/boilerplate/boilerplate.generatego.txt")),
+			"paths=./api/...",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+
+		By("running the generator and checking for errors")
+		Expect(rt.Run()).To(BeFalse())
+
+		Expect(output).To(HaveLen(3))
+		for _, version := range []string{"v1", "v1alpha1", "v1beta1"} {
+			By("checking the output for version " + version)
+			outFile := output["testdata.kubebuilder.io/conversion/api/"+version+"/zz_generated.conversion.go"]
+			Expect(outFile).NotTo(BeNil())
+
+			expectedFile, err := os.ReadFile(path.Join("api", version, "zz_generated.conversion.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(outFile.contents)).To(Equal(string(expectedFile)), "generated code not as expected\n\nDiff:\n\n%s", cmp.Diff(outFile.contents, expectedFile))
+		}
+	})
+
+	It("should fail on fields without a counterpart in the hub version", func() {
+		output := make(outputToPackageMap)
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("conversion", markers.DescribesPackage, conversion.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{"conversion", "paths=./invalid/..."})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeTrue())
+
+		By("checking the errors of the spoke version")
+		var errs []string
+		for _, root := range rt.Roots {
+			for _, err := range root.Errors {
+				errs = append(errs, err.Error())
+			}
+		}
+		Expect(errs).To(ContainElement(ContainSubstring("field Color of Gizmo has no counterpart in version v1")))
+		Expect(output).NotTo(HaveKey("testdata.kubebuilder.io/conversion/invalid/v2/zz_generated.conversion.go"))
+	})
+})
@@ -0,0 +1,343 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// metav1Path is the package of TypeMeta, which isn't converted: the API
+// version and kind of the converted object are set by the caller.
+const metav1Path = "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// qualifier returns the package qualifier to use for types from the given
+// package, importing it as needed.
+func (f *conversionFile) qualifier(pkg *types.Package) string {
+	if pkg == f.pkg.Types {
+		return ""
+	}
+	return f.imports.NeedImport(pkg.Path(), pkg.Name())
+}
+
+// typeName returns the syntax for referring to the given type in the file.
+func (f *conversionFile) typeName(typ types.Type) string {
+	return types.TypeString(typ, f.qualifier)
+}
+
+// fail records an error that prevents writing the file.
+func (f *conversionFile) fail(err error) {
+	f.pkg.AddError(err)
+	f.failed = true
+}
+
+// hasMethod checks if (a pointer to) the given type has a (hand-written)
+// method with the given name.
+func hasMethod(named *types.Named, name string) bool {
+	return types.NewMethodSet(types.NewPointer(named)).Lookup(named.Obj().Pkg(), name) != nil
+}
+
+// generateHub generates the Hub method of the given hub type, unless it's
+// hand-written.
+func (f *conversionFile) generateHub(hub *types.Named) {
+	if hasMethod(hub, "Hub") {
+		return
+	}
+
+	name := hub.Obj().Name()
+	out := new(bytes.Buffer)
+	c := &gogen.CodeWriter{Out: out}
+	c.Linef("// Hub marks %s as the hub version for conversion.", name)
+	c.Linef("func (*%s) Hub() {}", name)
+	c.Line("")
+	f.funcs[name+".Hub"] = out.Bytes()
+}
+
+// generateSpoke generates the ConvertTo and ConvertFrom methods of the given
+// spoke type (unless they're hand-written), along with the functions
+// converting it to and from the given hub type.
+func (f *conversionFile) generateSpoke(spoke, hub *types.Named) {
+	toHub := f.conversionFunc(spoke, hub)
+	fromHub := f.conversionFunc(hub, spoke)
+
+	name := spoke.Obj().Name()
+	hubVersion := f.versions[hub.Obj().Pkg().Path()].version
+	if !hasMethod(spoke, "ConvertTo") {
+		out := new(bytes.Buffer)
+		c := &gogen.CodeWriter{Out: out}
+		c.Linef("// ConvertTo converts this %s to the hub version (%s).", name, hubVersion)
+		c.Linef("func (src *%s) ConvertTo(dstRaw %s.Hub) error {", name, f.imports.NeedImport(conversionPkgPath, "conversion"))
+		c.Linef("return %s(src, dstRaw.(*%s))", toHub, f.typeName(hub))
+		c.Line("}")
+		c.Line("")
+		f.funcs[name+".ConvertTo"] = out.Bytes()
+	}
+	if !hasMethod(spoke, "ConvertFrom") {
+		out := new(bytes.Buffer)
+		c := &gogen.CodeWriter{Out: out}
+		c.Linef("// ConvertFrom converts from the hub version (%s) to this %s.", hubVersion, name)
+		c.Linef("func (dst *%s) ConvertFrom(srcRaw %s.Hub) error {", name, f.imports.NeedImport(conversionPkgPath, "conversion"))
+		c.Linef("return %s(srcRaw.(*%s), dst)", fromHub, f.typeName(hub))
+		c.Line("}")
+		c.Line("")
+		f.funcs[name+".ConvertFrom"] = out.Bytes()
+	}
+}
+
+// conversionFunc returns the name of the function converting between the
+// given types, which are declared in known versions, generating it unless
+// it's hand-written.
+func (f *conversionFile) conversionFunc(in, out *types.Named) string {
+	inVersion := f.versions[in.Obj().Pkg().Path()]
+	outVersion := f.versions[out.Obj().Pkg().Path()]
+	name := fmt.Sprintf("Convert_%s_%s_To_%s_%s", identifier(inVersion.version), in.Obj().Name(), identifier(outVersion.version), out.Obj().Name())
+
+	if _, exists := f.funcs[name]; exists {
+		return name
+	}
+	if f.pkg.Types.Scope().Lookup(name) != nil {
+		// hand-written
+		return name
+	}
+
+	// reserve the name before generating, in case of recursive types
+	f.funcs[name] = nil
+	f.funcs[name] = f.generateStructConversion(name, inVersion, in, outVersion, out)
+	return name
+}
+
+// identifier turns the given version into a valid part of an identifier.
+func identifier(version string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, version)
+}
+
+// convField is a field of a struct being converted.
+type convField struct {
+	*types.Var
+	// rename is the name of the corresponding field of the other version,
+	// if renamed.
+	rename string
+	skip   bool
+}
+
+// structFields returns the fields of the given struct type declared in the
+// given version, along with their markers.
+func structFields(version *apiVersion, named *types.Named) []convField {
+	structType := named.Underlying().(*types.Struct)
+	info := version.types[named.Obj().Name()]
+
+	fields := make([]convField, 0, structType.NumFields())
+	for i := range structType.NumFields() {
+		field := convField{Var: structType.Field(i)}
+		// the markers of each field are listed in the same order as the fields
+		if info != nil && i < len(info.Fields) {
+			fieldMarkers := info.Fields[i].Markers
+			if rename := fieldMarkers.Get(renameMarker.Name); rename != nil {
+				field.rename = string(rename.(Rename))
+			}
+			field.skip = fieldMarkers.Get(skipMarker.Name) != nil
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// counterpart returns the field of the other version of the type
+// corresponding to this one, or nil if there's none.
+func (f convField) counterpart(others []convField) *convField {
+	for i, other := range others {
+		var matches bool
+		switch {
+		case f.rename != "":
+			matches = other.Name() == f.rename
+		case other.rename != "":
+			matches = other.rename == f.Name()
+		default:
+			matches = other.Name() == f.Name()
+		}
+		if matches {
+			return &others[i]
+		}
+	}
+	return nil
+}
+
+// isTypeMeta checks if the given field is an embedded TypeMeta.
+func isTypeMeta(field *types.Var) bool {
+	named, isNamed := field.Type().(*types.Named)
+	return field.Embedded() && isNamed && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == metav1Path && named.Obj().Name() == "TypeMeta"
+}
+
+// generateStructConversion generates the function with the given name
+// converting between the given struct types, field by field.
+func (f *conversionFile) generateStructConversion(name string, inVersion *apiVersion, in *types.Named, outVersion *apiVersion, out *types.Named) []byte {
+	inFields := structFields(inVersion, in)
+	outFields := structFields(outVersion, out)
+
+	outContent := new(bytes.Buffer)
+	c := &gogen.CodeWriter{Out: outContent}
+	c.Linef("// %s converts a %s %s to a %s %s.", name, inVersion.version, in.Obj().Name(), outVersion.version, out.Obj().Name())
+	c.Linef("func %s(in *%s, out *%s) error {", name, f.typeName(in), f.typeName(out))
+	for _, field := range inFields {
+		if !field.Exported() || isTypeMeta(field.Var) || field.skip {
+			continue
+		}
+		counterpart := field.counterpart(outFields)
+		if counterpart == nil {
+			f.fail(loader.ErrFromNode(fmt.Errorf("field %s of %s has no counterpart in version %s: rename it with +kubebuilder:conversion:rename, skip it with +kubebuilder:conversion:skip, or hand-write %s", field.Name(), in.Obj().Name(), outVersion.version, name), field))
+			continue
+		}
+		if counterpart.skip {
+			continue
+		}
+		if err := f.convertInto(c, "in."+field.Name(), "out."+counterpart.Name(), field.Type(), counterpart.Type()); err != nil {
+			f.fail(loader.ErrFromNode(fmt.Errorf("unable to convert field %s of %s to version %s: %w: skip it with +kubebuilder:conversion:skip, or hand-write %s", field.Name(), in.Obj().Name(), outVersion.version, err, name), field))
+		}
+	}
+	c.Line("return nil")
+	c.Line("}")
+	c.Line("")
+	return outContent.Bytes()
+}
+
+// addr returns the syntax for taking the address of the given (addressable)
+// expression.
+func addr(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return expr[1:]
+	}
+	return "&" + expr
+}
+
+// isBasic checks if the underlying type of the given type is a basic type.
+func isBasic(typ types.Type) bool {
+	_, basic := typ.Underlying().(*types.Basic)
+	return basic
+}
+
+// convertInto writes code converting the given input expression of the given
+// type into the given output expression of the given type.
+func (f *conversionFile) convertInto(c *gogen.CodeWriter, inExpr, outExpr string, inType, outType types.Type) error {
+	if types.Identical(inType, outType) {
+		c.Linef("%s = %s", outExpr, inExpr)
+		return nil
+	}
+	if isBasic(inType) && isBasic(outType) && types.ConvertibleTo(inType, outType) {
+		c.Linef("%s = %s(%s)", outExpr, f.typeName(outType), inExpr)
+		return nil
+	}
+
+	inNamed, inIsNamed := inType.(*types.Named)
+	outNamed, outIsNamed := outType.(*types.Named)
+	if inIsNamed && outIsNamed {
+		_, inIsStruct := inNamed.Underlying().(*types.Struct)
+		_, outIsStruct := outNamed.Underlying().(*types.Struct)
+		if inIsStruct && outIsStruct {
+			if !f.isKnown(inNamed) || !f.isKnown(outNamed) {
+				return fmt.Errorf("cannot convert %s to %s outside of the API versions", inType, outType)
+			}
+			c.Linef("if err := %s(%s, %s); err != nil {", f.conversionFunc(inNamed, outNamed), addr(inExpr), addr(outExpr))
+			c.Line("return err")
+			c.Line("}")
+			return nil
+		}
+	}
+
+	var err error
+	switch inUnderlying := inType.Underlying().(type) {
+	case *types.Pointer:
+		outUnderlying, isPointer := outType.Underlying().(*types.Pointer)
+		if !isPointer {
+			break
+		}
+		c.IfElse(inExpr+" != nil", func() {
+			c.Linef("in, out := %s, %s", addr(inExpr), addr(outExpr))
+			c.Linef("*out = new(%s)", f.typeName(outUnderlying.Elem()))
+			err = f.convertInto(c, "**in", "**out", inUnderlying.Elem(), outUnderlying.Elem())
+		}, func() {
+			c.Linef("%s = nil", outExpr)
+		})
+		return err
+	case *types.Slice:
+		outUnderlying, isSlice := outType.Underlying().(*types.Slice)
+		if !isSlice {
+			break
+		}
+		c.IfElse(inExpr+" != nil", func() {
+			c.Linef("in, out := %s, %s", addr(inExpr), addr(outExpr))
+			c.Linef("*out = make(%s, len(*in))", f.typeName(outType))
+			c.For("i := range *in", func() {
+				err = f.convertInto(c, "(*in)[i]", "(*out)[i]", inUnderlying.Elem(), outUnderlying.Elem())
+			})
+		}, func() {
+			c.Linef("%s = nil", outExpr)
+		})
+		return err
+	case *types.Map:
+		outUnderlying, isMap := outType.Underlying().(*types.Map)
+		if !isMap {
+			break
+		}
+		key := "key"
+		if !types.Identical(inUnderlying.Key(), outUnderlying.Key()) {
+			if !isBasic(inUnderlying.Key()) || !isBasic(outUnderlying.Key()) || !types.ConvertibleTo(inUnderlying.Key(), outUnderlying.Key()) {
+				return fmt.Errorf("cannot convert map keys of type %s to %s", inUnderlying.Key(), outUnderlying.Key())
+			}
+			key = fmt.Sprintf("%s(key)", f.typeName(outUnderlying.Key()))
+		}
+		c.IfElse(inExpr+" != nil", func() {
+			c.Linef("in, out := %s, %s", addr(inExpr), addr(outExpr))
+			c.Linef("*out = make(%s, len(*in))", f.typeName(outType))
+			c.For("key, val := range *in", func() {
+				if types.Identical(inUnderlying.Elem(), outUnderlying.Elem()) {
+					c.Linef("(*out)[%s] = val", key)
+					return
+				}
+				c.Linef("var outVal %s", f.typeName(outUnderlying.Elem()))
+				err = f.convertInto(c, "val", "outVal", inUnderlying.Elem(), outUnderlying.Elem())
+				c.Linef("(*out)[%s] = outVal", key)
+			})
+		}, func() {
+			c.Linef("%s = nil", outExpr)
+		})
+		return err
+	}
+
+	return fmt.Errorf("cannot convert %s to %s", inType, outType)
+}
+
+// isKnown checks if the given type is declared in one of the known versions.
+func (f *conversionFile) isKnown(named *types.Named) bool {
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return false
+	}
+	_, known := f.versions[pkg.Path()]
+	return known
+}
@@ -0,0 +1,31 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conversion generates hub-and-spoke conversion functions between the
+// versions of a kind.
+//
+// For each kind with a hub version (marked with +kubebuilder:conversion:hub),
+// the other (spoke) versions of the kind in the same group get ConvertTo and
+// ConvertFrom methods implementing controller-runtime's Convertible interface,
+// backed by Convert_<version>_<Type>_To_<version>_<Type> functions for the
+// kind and the types it uses.  Fields are matched by name, unless renamed with
+// +kubebuilder:conversion:rename, and can be left out of the conversion with
+// +kubebuilder:conversion:skip.
+//
+// As with conversion-gen, any of the functions can be hand-written instead
+// (e.g. for fields whose types changed between versions), in which case the
+// hand-written function is used by the generated code.
+package conversion
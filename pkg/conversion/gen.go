@@ -0,0 +1,319 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/controller-tools/pkg/webhook"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing methods or conversion functions, we only see
+// hand-written ones.
+
+const (
+	// conversionPkgPath is the package of controller-runtime's Hub and
+	// Convertible interfaces.
+	conversionPkgPath = "sigs.k8s.io/controller-runtime/pkg/conversion"
+	// outputFileName is the name of the generated file in each package.
+	outputFileName = "zz_generated.conversion.go"
+)
+
+var (
+	renameMarker = markers.Must(markers.MakeDefinition("kubebuilder:conversion:rename", markers.DescribesField, Rename("")))
+	skipMarker   = markers.Must(markers.MakeDefinition("kubebuilder:conversion:skip", markers.DescribesField, Skip{}))
+)
+
+// +controllertools:marker:generateHelp:category=Conversion
+
+// Rename names the field of the other version of the type that corresponds
+// to this field, when it was renamed between versions.
+//
+// It's usually placed on the field of the spoke version, naming the field of
+// the hub version, e.g.:
+//
+//	// +kubebuilder:conversion:rename=Replicas
+//	Count int32 `json:"count"`
+type Rename string
+
+// +controllertools:marker:generateHelp:category=Conversion
+
+// Skip leaves this field out of conversions between versions.
+//
+// Fields without a counterpart in the other version must either be skipped,
+// or converted by a hand-written conversion function for their type.
+type Skip struct{}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates hub-and-spoke conversion functions between the versions
+// of each kind with a hub version.
+//
+// The conversion code is written to zz_generated.conversion.go in the package
+// of each spoke version, and a Hub method is written alongside the hub version
+// unless it's hand-written.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return func(node ast.Node) bool {
+		// ignore interfaces
+		_, isIface := node.(*ast.InterfaceType)
+		return !isIface
+	}
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := markers.RegisterAll(into, webhook.ConversionHubDefinition, renameMarker, skipMarker); err != nil {
+		return err
+	}
+	if err := crd.RegisterGroupVersionMarkers(into); err != nil {
+		return err
+	}
+	into.AddHelp(webhook.ConversionHubDefinition, webhook.ConversionHub{}.Help())
+	into.AddHelp(renameMarker, Rename("").Help())
+	into.AddHelp(skipMarker, Skip{}.Help())
+	return nil
+}
+
+// apiVersion is a version of an API group, i.e. a root package with a
+// +groupName marker.
+type apiVersion struct {
+	pkg            *loader.Package
+	group, version string
+	// types are the types declared in the package, by name.
+	types map[string]*markers.TypeInfo
+}
+
+// lookupNamed returns the named type with the given name in this version, if
+// any.
+func (v *apiVersion) lookupNamed(name string) *types.Named {
+	typeName, isType := v.pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !isType {
+		return nil
+	}
+	named, _ := typeName.Type().(*types.Named)
+	return named
+}
+
+// loadVersion loads the types of the given root, returning nil if it isn't
+// part of an API group.
+func loadVersion(ctx *genall.GenerationContext, root *loader.Package) *apiVersion {
+	pkgMarkers, err := markers.PackageMarkers(ctx.Collector, root)
+	if err != nil {
+		root.AddError(err)
+		return nil
+	}
+	gv := crd.GroupVersionForPackage(pkgMarkers, root)
+	if gv.Empty() {
+		return nil
+	}
+
+	ctx.Checker.Check(root)
+	root.NeedTypesInfo()
+
+	version := &apiVersion{
+		pkg:     root,
+		group:   gv.Group,
+		version: gv.Version,
+		types:   make(map[string]*markers.TypeInfo),
+	}
+	if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+		version.types[info.Name] = info
+	}); err != nil {
+		root.AddError(err)
+		return nil
+	}
+	return version
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	// group the versions (in the order of the roots) by API group
+	var groups []string
+	versionsByGroup := make(map[string][]*apiVersion)
+	versionsByPath := make(map[string]*apiVersion)
+	for _, root := range ctx.Roots {
+		version := loadVersion(ctx, root)
+		if version == nil {
+			continue
+		}
+		if _, known := versionsByGroup[version.group]; !known {
+			groups = append(groups, version.group)
+		}
+		versionsByGroup[version.group] = append(versionsByGroup[version.group], version)
+		versionsByPath[root.PkgPath] = version
+	}
+
+	files := make(map[*loader.Package]*conversionFile)
+	fileFor := func(version *apiVersion) *conversionFile {
+		if file, exists := files[version.pkg]; exists {
+			return file
+		}
+		file := newConversionFile(version.pkg, versionsByPath)
+		files[version.pkg] = file
+		return file
+	}
+
+	for _, group := range groups {
+		versions := versionsByGroup[group]
+		for _, hub := range versions {
+			for _, kind := range hubKinds(hub) {
+				hubType := hub.lookupNamed(kind)
+				if hubType == nil {
+					continue
+				}
+				if other := otherHub(versions, hub, kind); other != nil {
+					hub.pkg.AddError(loader.ErrFromNode(fmt.Errorf("kind %s has multiple hub versions (%s and %s)", kind, hub.version, other.version), hub.types[kind].RawSpec))
+					continue
+				}
+				fileFor(hub).generateHub(hubType)
+
+				for _, spoke := range versions {
+					if spoke == hub {
+						continue
+					}
+					spokeType := spoke.lookupNamed(kind)
+					if spokeType == nil {
+						continue
+					}
+					fileFor(spoke).generateSpoke(spokeType, hubType)
+				}
+			}
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		file, exists := files[root]
+		if !exists || file.failed {
+			continue
+		}
+		outContents := file.contents(headerText)
+		if outContents == nil {
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, outContents)
+	}
+
+	return nil
+}
+
+// hubKinds returns the (sorted) names of the types marked as hub versions in
+// the given version.
+func hubKinds(version *apiVersion) []string {
+	var kinds []string
+	for name, info := range version.types {
+		if info.Markers.Get(webhook.ConversionHubDefinition.Name) != nil {
+			kinds = append(kinds, name)
+		}
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// otherHub returns another version of the given group marking the given kind
+// as its hub version, if any.
+func otherHub(versions []*apiVersion, hub *apiVersion, kind string) *apiVersion {
+	for _, other := range versions {
+		if other == hub {
+			continue
+		}
+		if info, exists := other.types[kind]; exists && info.Markers.Get(webhook.ConversionHubDefinition.Name) != nil {
+			return other
+		}
+	}
+	return nil
+}
+
+// conversionFile collects the generated code of a single package.
+type conversionFile struct {
+	pkg     *loader.Package
+	imports *gogen.Imports
+	// versions are all known versions, by package path.
+	versions map[string]*apiVersion
+
+	// funcs are the generated functions and methods, by name.
+	funcs map[string][]byte
+	// failed indicates that some code couldn't be generated, so the file
+	// shouldn't be written.
+	failed bool
+}
+
+func newConversionFile(pkg *loader.Package, versions map[string]*apiVersion) *conversionFile {
+	return &conversionFile{
+		pkg:      pkg,
+		imports:  gogen.NewImports(pkg.Name),
+		versions: versions,
+		funcs:    make(map[string][]byte),
+	}
+}
+
+// contents returns the formatted contents of the file, or nil if nothing was
+// generated for the package.
+func (f *conversionFile) contents(headerText string) []byte {
+	if len(f.funcs) == 0 {
+		return nil
+	}
+
+	var importsBlock string
+	if specs := f.imports.ImportSpecs(); len(specs) > 0 {
+		importsBlock = fmt.Sprintf("import (\n%s\n)\n", strings.Join(specs, "\n"))
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[3]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+%[2]s
+`, f.pkg.Name, importsBlock, headerText)
+
+	names := make([]string, 0, len(f.funcs))
+	for name := range f.funcs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		outContent.Write(f.funcs[name])
+	}
+
+	return gogen.Format(f.pkg, outContent.Bytes())
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phase is the phase of a Widget.
+type Phase string
+
+// Endpoint is a network endpoint.
+type Endpoint struct {
+	Host string `json:"host"`
+	Port int32  `json:"port"`
+}
+
+// WidgetSpec is the spec of a Widget.
+type WidgetSpec struct {
+	Replicas  *int32              `json:"replicas,omitempty"`
+	Selector  map[string]string   `json:"selector,omitempty"`
+	Endpoints []Endpoint          `json:"endpoints,omitempty"`
+	Primary   *Endpoint           `json:"primary,omitempty"`
+	Ports     map[string]Endpoint `json:"ports,omitempty"`
+
+	// Strategy is only supported by this version.
+	// +kubebuilder:conversion:skip
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// WidgetStatus is the status of a Widget.
+type WidgetStatus struct {
+	Phase      Phase              `json:"phase,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Widget is the hub version of the Widget kind.
+// +kubebuilder:object:root=true
+// +kubebuilder:conversion:hub
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// WidgetList contains a list of Widgets.
+// +kubebuilder:object:root=true
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
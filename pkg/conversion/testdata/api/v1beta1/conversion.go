@@ -0,0 +1,46 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"net"
+	"strconv"
+
+	v1 "testdata.kubebuilder.io/conversion/api/v1"
+)
+
+// Convert_v1beta1_Endpoint_To_v1_Endpoint is hand-written, since the
+// address was split into a host and port in v1.
+func Convert_v1beta1_Endpoint_To_v1_Endpoint(in *Endpoint, out *v1.Endpoint) error {
+	host, port, err := net.SplitHostPort(in.Address)
+	if err != nil {
+		return err
+	}
+	portNum, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return err
+	}
+	out.Host, out.Port = host, int32(portNum)
+	return nil
+}
+
+// Convert_v1_Endpoint_To_v1beta1_Endpoint is hand-written, since the
+// address was split into a host and port in v1.
+func Convert_v1_Endpoint_To_v1beta1_Endpoint(in *v1.Endpoint, out *Endpoint) error {
+	out.Address = net.JoinHostPort(in.Host, strconv.Itoa(int(in.Port)))
+	return nil
+}
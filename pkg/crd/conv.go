@@ -46,6 +46,14 @@ func AsVersion(original apiextensionsv1.CustomResourceDefinition, gv schema.Grou
 	if gv == apiextv1beta1.SchemeGroupVersion {
 		return nil, fmt.Errorf("apiVersion %q is not supported", gv.String())
 	}
+	if gv == apiextensionsv1.SchemeGroupVersion {
+		// the canonical form already, so a copy is all it takes -- converting
+		// it to the internal version and back copies it twice, which adds up
+		// for groups with many large CRDs
+		crd := original.DeepCopy()
+		crd.SetGroupVersionKind(gv.WithKind("CustomResourceDefinition"))
+		return crd, nil
+	}
 	// We can use the internal versions an existing conversions from kubernetes, since they're not in k/k itself.
 	// This punts the problem of conversion down the road for a future maintainer (or future instance of @directxman12)
 	// when we have to support older versions that get removed, or when API machinery decides to yell at us for this
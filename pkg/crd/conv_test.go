@@ -0,0 +1,82 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextinternal "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-tools/pkg/crd"
+)
+
+var _ = Describe("CRD Version Conversion", func() {
+	maxProperties := int64(5)
+	original := apiextensionsv1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.testdata.kubebuilder.io"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "testdata.kubebuilder.io",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Widget", ListKind: "WidgetList", Plural: "widgets", Singular: "widget"},
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:    "v1",
+				Served:  true,
+				Storage: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						"spec": {
+							Type:          "object",
+							Required:      []string{"size"},
+							MaxProperties: &maxProperties,
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"size": {Type: "integer", Default: &apiextensionsv1.JSON{Raw: []byte("3")}},
+							},
+						},
+					},
+				}},
+				Subresources: &apiextensionsv1.CustomResourceSubresources{Status: &apiextensionsv1.CustomResourceSubresourceStatus{}},
+			}},
+		},
+	}
+
+	It("should convert to v1 as converting through the internal version does, without sharing anything", func() {
+		scheme := runtime.NewScheme()
+		Expect(apiextinternal.AddToScheme(scheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		internal, err := scheme.ConvertToVersion(original.DeepCopy(), apiextinternal.SchemeGroupVersion)
+		Expect(err).NotTo(HaveOccurred())
+		expected, err := scheme.ConvertToVersion(internal, apiextensionsv1.SchemeGroupVersion)
+		Expect(err).NotTo(HaveOccurred())
+
+		converted, err := crd.AsVersion(original, apiextensionsv1.SchemeGroupVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(converted).To(Equal(expected))
+
+		converted.(*apiextensionsv1.CustomResourceDefinition).Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["size"] = apiextensionsv1.JSONSchemaProps{}
+		Expect(original.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["size"].Type).To(Equal("integer"))
+	})
+
+	It("should refuse v1beta1", func() {
+		_, err := crd.AsVersion(original, apiextv1beta1.SchemeGroupVersion)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,128 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// DescriptionCatalog replaces the descriptions of types and fields, e.g. with
+// translations, or with wording reviewed separately from the Go source, which
+// is then left untouched.
+//
+// An example catalog file:
+//
+//	descriptions:
+//	- package: example.com/api/v1
+//	  type: CronJob
+//	  description: A CronJob runs Jobs on a schedule.
+//	  fields:
+//	    Spec: Spec is the schedule of the Jobs, and their template.
+type DescriptionCatalog struct {
+	// Descriptions are the descriptions of the types, and of their fields.
+	Descriptions []TypeDescriptions `json:"descriptions"`
+
+	// byType indexes Descriptions by package path, then by type name.
+	byType map[string]map[string]*TypeDescriptions
+}
+
+// TypeDescriptions are the descriptions of a type, and of its fields.
+type TypeDescriptions struct {
+	// Package is the import path of the package of the type.
+	Package string `json:"package"`
+	// Type is the name of the type.
+	Type string `json:"type"`
+	// Description replaces the description of the type, if set (even to the
+	// empty string, which removes it).
+	Description *string `json:"description,omitempty"`
+	// Fields replace the descriptions of the fields of the type, by Go name.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// LoadDescriptionCatalog parses the given description catalog.
+func LoadDescriptionCatalog(contents []byte) (*DescriptionCatalog, error) {
+	var catalog DescriptionCatalog
+	if err := yaml.UnmarshalStrict(contents, &catalog); err != nil {
+		return nil, fmt.Errorf("unable to parse description catalog: %w", err)
+	}
+	catalog.byType = make(map[string]map[string]*TypeDescriptions)
+	for i := range catalog.Descriptions {
+		desc := &catalog.Descriptions[i]
+		if desc.Package == "" || desc.Type == "" {
+			return nil, fmt.Errorf("description %d of the catalog must have a package and a type", i)
+		}
+		if catalog.byType[desc.Package] == nil {
+			catalog.byType[desc.Package] = make(map[string]*TypeDescriptions)
+		}
+		if _, duplicate := catalog.byType[desc.Package][desc.Type]; duplicate {
+			return nil, fmt.Errorf("type %s.%s is described more than once in the catalog", desc.Package, desc.Type)
+		}
+		catalog.byType[desc.Package][desc.Type] = desc
+	}
+	return &catalog, nil
+}
+
+// describe returns the given type info with the descriptions of the catalog,
+// if it has any for the type, warning about the fields of the catalog the
+// type doesn't have.
+func (c *DescriptionCatalog) describe(pkg *loader.Package, info *markers.TypeInfo) *markers.TypeInfo {
+	if c == nil {
+		return info
+	}
+	desc, described := c.byType[loader.NonVendorPath(pkg.PkgPath)][info.Name]
+	if !described {
+		return info
+	}
+
+	res := *info
+	if desc.Description != nil {
+		res.Doc = *desc.Description
+	}
+	res.Fields = slices.Clone(info.Fields)
+	unknown := maps.Clone(desc.Fields)
+	for i, field := range res.Fields {
+		if doc, isDescribed := desc.Fields[field.Name]; isDescribed && field.Name != "" {
+			res.Fields[i].Doc = doc
+			delete(unknown, field.Name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(unknown)) {
+		pkg.AddWarning(loader.ErrFromNode(fmt.Errorf("the description catalog describes the field %s, which type %s doesn't have", name, info.Name), info.RawSpec))
+	}
+	return &res
+}
+
+// checkTypes warns about the types of the given package in the catalog that
+// the package doesn't have, given the types it has.
+func (c *DescriptionCatalog) checkTypes(pkg *loader.Package, types map[TypeIdent]*markers.TypeInfo) {
+	if c == nil {
+		return
+	}
+	described := c.byType[loader.NonVendorPath(pkg.PkgPath)]
+	for _, name := range slices.Sorted(maps.Keys(described)) {
+		if _, exists := types[TypeIdent{Package: pkg, Name: name}]; !exists {
+			pkg.AddWarning(fmt.Errorf("the description catalog describes the type %s.%s, which doesn't exist", pkg.PkgPath, name))
+		}
+	}
+}
@@ -35,7 +35,9 @@ limitations under the License.
 // loaded from.  Each Need method will in turn call Need on anything it needs.
 //
 // In general, root packages should first be loaded into the Parser with
-// NeedPackage.  Then, CRDs can be generated with NeedCRDFor.
+// NeedPackage.  Then, CRDs can be generated with NeedCRDFor.  The schemata
+// of many types can be generated beforehand with NeedSchemasFor, concurrently
+// if the Parser's Parallelism is set.
 //
 // Errors are generally attached directly to the relevant Package with
 // AddError.
@@ -46,6 +48,14 @@ limitations under the License.
 // have validation markers attached.  Those specific types have overrides
 // listed in KnownPackages that can be added as overrides to any parser.
 //
+// # Generic Types
+//
+// Instantiations of generic types (e.g. `Wrapper[string]`) don't get schemata
+// (and thus references) of their own: the schema of the generic type is inlined
+// wherever it's instantiated, with its type parameters replaced by the schemata
+// of the type arguments.  Recursive instantiations are rejected, since their
+// schemata would be infinite.
+//
 // # Flattening
 //
 // Once schemata are generated, they can be used directly by external tooling
@@ -60,4 +70,6 @@ limitations under the License.
 // Flattened schemata may further be passed to FlattenEmbedded to remove the
 // use of AllOf (which is used to describe embedded struct fields when
 // references are in use).  This done automatically when fetching CRDs.
+// EmbeddedMarkers documents how the markers of embedded types combine with
+// those of the embedding structs when doing so.
 package crd
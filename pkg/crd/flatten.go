@@ -47,8 +47,66 @@ func isOrNil(val reflect.Value, valInt any, zeroInt any) bool {
 	}
 }
 
+// EmbeddedMarkers selects how the markers of embedded types (and of the
+// fields embedding them) combine with the markers of the structs embedding
+// them, when flattening embedded fields.
+//
+// Validation markers always combine, since the embedding struct must be valid
+// for each of the types it embeds: required fields and validation rules are
+// merged, and other conflicting validations (e.g. different MaxProperties)
+// are all kept in an allOf.  Topology markers (mapType, structType, listType
+// and listMapKey) and pruning markers (PreserveUnknownFields) can't be
+// combined that way, so one of them has to win.
+type EmbeddedMarkers string
+
+const (
+	// EmbeddedMarkersLegacy lets the topology and pruning markers of the
+	// embedded types win over those of the embedding struct, the last
+	// embedded type winning, and keeps conflicting listType and listMapKey
+	// markers in an allOf.  It's the default, for compatibility.
+	EmbeddedMarkersLegacy EmbeddedMarkers = "legacy"
+	// EmbeddedMarkersStrict lets the topology and pruning markers closest
+	// to the schema win: those of the embedding struct win over those of the
+	// embedded types, those of embedding fields win over those of their
+	// types, and later embedded fields win over earlier ones.
+	EmbeddedMarkersStrict EmbeddedMarkers = "strict"
+)
+
+// Validate checks that the given mode is known, the empty mode being the
+// legacy one.
+func (m EmbeddedMarkers) Validate() error {
+	switch m {
+	case "", EmbeddedMarkersLegacy, EmbeddedMarkersStrict:
+		return nil
+	default:
+		return fmt.Errorf("unknown embedded markers mode %q, must be %q or %q", m, EmbeddedMarkersLegacy, EmbeddedMarkersStrict)
+	}
+}
+
+// strict indicates whether the given mode is the strict one.
+func (m EmbeddedMarkers) strict() bool {
+	return m == EmbeddedMarkersStrict
+}
+
+// remainders hold the conflicting values of the fields of a src and dst schema
+// that are hoisted into an allOf.  They're only allocated once a field is
+// hoisted, which most schemata never need.
+type remainders struct {
+	src, dst *apiextensionsv1.JSONSchemaProps
+}
+
+// hoist sets the field with the given index of the remainders to the given
+// values.
+func (r *remainders) hoist(fieldIndex int, srcField, dstField reflect.Value) {
+	if r.src == nil {
+		r.src, r.dst = &apiextensionsv1.JSONSchemaProps{}, &apiextensionsv1.JSONSchemaProps{}
+	}
+	reflect.ValueOf(r.src).Elem().Field(fieldIndex).Set(srcField)
+	reflect.ValueOf(r.dst).Elem().Field(fieldIndex).Set(dstField)
+}
+
 // resolveFieldConflict handles conflicts when both src and dst have values for a field
-func resolveFieldConflict(fieldName string, srcField, dstField reflect.Value, srcInt, dstInt any, errRec ErrorRecorder, srcRemVal, dstRemVal reflect.Value, fieldIndex int) bool {
+func resolveFieldConflict(fieldName string, srcField, dstField reflect.Value, srcInt, dstInt any, errRec ErrorRecorder, mode EmbeddedMarkers, rem *remainders, fieldIndex int) bool {
 	zeroVal := reflect.Zero(srcField.Type())
 
 	switch fieldName {
@@ -63,7 +121,7 @@ func resolveFieldConflict(fieldName string, srcField, dstField reflect.Value, sr
 				dstMap[k] = v
 				continue
 			}
-			flattenAllOfInto(&dstProp, v, errRec)
+			flattenAllOfInto(&dstProp, &v, errRec, mode)
 			dstMap[k] = dstProp
 		}
 		return false
@@ -80,8 +138,7 @@ func resolveFieldConflict(fieldName string, srcField, dstField reflect.Value, sr
 			return false
 		}
 		// different values, keep both in allOf
-		srcRemVal.Field(fieldIndex).Set(srcField)
-		dstRemVal.Field(fieldIndex).Set(dstField)
+		rem.hoist(fieldIndex, srcField, dstField)
 		dstField.Set(zeroVal)
 		return true
 	case "Type":
@@ -107,13 +164,20 @@ func resolveFieldConflict(fieldName string, srcField, dstField reflect.Value, sr
 		if dstProps.Schema == nil {
 			dstProps.Schema = &apiextensionsv1.JSONSchemaProps{}
 		}
-		flattenAllOfInto(dstProps.Schema, *srcProps.Schema, errRec)
+		flattenAllOfInto(dstProps.Schema, srcProps.Schema, errRec, mode)
 		return false
-	case "XPreserveUnknownFields":
-		dstField.Set(srcField)
+	case "XPreserveUnknownFields", "XMapType":
+		if !mode.strict() {
+			dstField.Set(srcField)
+		}
 		return false
-	case "XMapType":
-		dstField.Set(srcField)
+	case "XListType", "XListMapKeys":
+		if !mode.strict() {
+			// hoist into allOf, like other fields
+			rem.hoist(fieldIndex, srcField, dstField)
+			dstField.Set(zeroVal)
+			return true
+		}
 		return false
 	case "XValidations":
 		dstField.Set(reflect.AppendSlice(srcField, dstField))
@@ -122,8 +186,7 @@ func resolveFieldConflict(fieldName string, srcField, dstField reflect.Value, sr
 	// TODO(directxman12): src isn't necessarily the field value -- it's just the most recent allOf entry
 	default:
 		// hoist into allOf...
-		srcRemVal.Field(fieldIndex).Set(srcField)
-		dstRemVal.Field(fieldIndex).Set(dstField)
+		rem.hoist(fieldIndex, srcField, dstField)
 		// ...and clear the original
 		dstField.Set(zeroVal)
 		return true
@@ -132,21 +195,31 @@ func resolveFieldConflict(fieldName string, srcField, dstField reflect.Value, sr
 
 // flattenAllOfInto copies properties from src to dst, then copies the properties
 // of each item in src's allOf to dst's properties as well.
-func flattenAllOfInto(dst *apiextensionsv1.JSONSchemaProps, src apiextensionsv1.JSONSchemaProps, errRec ErrorRecorder) {
-	if len(src.AllOf) > 0 {
-		for _, embedded := range src.AllOf {
-			flattenAllOfInto(dst, embedded, errRec)
+//
+// In the strict mode, src's allOf is flattened into src first, from the last
+// item to the first, so that what's closest to dst wins.
+func flattenAllOfInto(dst, src *apiextensionsv1.JSONSchemaProps, errRec ErrorRecorder, mode EmbeddedMarkers) {
+	if len(src.AllOf) > 0 && mode.strict() {
+		flatSrc := src.DeepCopy()
+		flatSrc.AllOf = nil
+		for i := len(src.AllOf) - 1; i >= 0; i-- {
+			flattenAllOfInto(flatSrc, &src.AllOf[i], errRec, mode)
+		}
+		src = flatSrc
+		// keep what couldn't be flattened
+		dst.AllOf = append(dst.AllOf, src.AllOf...)
+	} else if len(src.AllOf) > 0 {
+		for i := range src.AllOf {
+			flattenAllOfInto(dst, &src.AllOf[i], errRec, mode)
 		}
 	}
 
-	dstVal := reflect.Indirect(reflect.ValueOf(dst))
-	srcVal := reflect.ValueOf(src)
+	// src is read through its pointer, not to copy it into an interface
+	dstVal := reflect.ValueOf(dst).Elem()
+	srcVal := reflect.ValueOf(src).Elem()
 	typ := dstVal.Type()
 
-	srcRemainder := apiextensionsv1.JSONSchemaProps{}
-	srcRemVal := reflect.Indirect(reflect.ValueOf(&srcRemainder))
-	dstRemainder := apiextensionsv1.JSONSchemaProps{}
-	dstRemVal := reflect.Indirect(reflect.ValueOf(&dstRemainder))
+	var rem remainders
 	hoisted := false
 
 	for fieldIndex := 0; fieldIndex < srcVal.NumField(); fieldIndex++ {
@@ -193,13 +266,13 @@ func flattenAllOfInto(dst *apiextensionsv1.JSONSchemaProps, src apiextensionsv1.
 		}
 
 		// resolve conflict
-		if resolveFieldConflict(fieldName, srcField, dstField, srcInt, dstInt, errRec, srcRemVal, dstRemVal, fieldIndex) {
+		if resolveFieldConflict(fieldName, srcField, dstField, srcInt, dstInt, errRec, mode, &rem, fieldIndex) {
 			hoisted = true
 		}
 	}
 
 	if hoisted {
-		dst.AllOf = append(dst.AllOf, dstRemainder, srcRemainder)
+		dst.AllOf = append(dst.AllOf, *rem.dst, *rem.src)
 	}
 
 	// dedup required
@@ -223,6 +296,9 @@ type allOfVisitor struct {
 	// errRec is used to record errors while flattening (like two conflicting
 	// field values used in an allOf)
 	errRec ErrorRecorder
+	// mode selects how the markers of the embedded types combine with
+	// those of the embedding struct.
+	mode EmbeddedMarkers
 }
 
 func (v *allOfVisitor) Visit(schema *apiextensionsv1.JSONSchemaProps) SchemaVisitor {
@@ -234,8 +310,12 @@ func (v *allOfVisitor) Visit(schema *apiextensionsv1.JSONSchemaProps) SchemaVisi
 	origAllOf := schema.AllOf
 	schema.AllOf = nil
 
-	for _, embedded := range origAllOf {
-		flattenAllOfInto(schema, embedded, v.errRec)
+	if v.mode.strict() {
+		// the schema wins over its allOf, and later items over earlier ones
+		slices.Reverse(origAllOf)
+	}
+	for i := range origAllOf {
+		flattenAllOfInto(schema, &origAllOf[i], v.errRec, v.mode)
 	}
 	return v
 }
@@ -246,10 +326,16 @@ func (v *allOfVisitor) Visit(schema *apiextensionsv1.JSONSchemaProps) SchemaVisi
 
 // FlattenEmbedded flattens embedded fields (represented via AllOf) which have
 // already had their references resolved into simple properties in the containing
-// schema.
+// schema.  Markers are combined in the legacy mode (see EmbeddedMarkers).
 func FlattenEmbedded(schema *apiextensionsv1.JSONSchemaProps, errRec ErrorRecorder) *apiextensionsv1.JSONSchemaProps {
+	return FlattenEmbeddedWithMode(schema, errRec, EmbeddedMarkersLegacy)
+}
+
+// FlattenEmbeddedWithMode is like FlattenEmbedded, combining markers in the
+// given mode.
+func FlattenEmbeddedWithMode(schema *apiextensionsv1.JSONSchemaProps, errRec ErrorRecorder, mode EmbeddedMarkers) *apiextensionsv1.JSONSchemaProps {
 	outSchema := schema.DeepCopy()
-	EditSchema(outSchema, &allOfVisitor{errRec: errRec})
+	EditSchema(outSchema, &allOfVisitor{errRec: errRec, mode: mode})
 	return outSchema
 }
 
@@ -468,4 +468,60 @@ var _ = Describe("AllOf Flattening", func() {
 		By("ensuring that different values remain in AllOf")
 		Expect(flattened.AllOf).To(HaveLen(2))
 	})
+
+	Context("when combining the markers of embedded types", func() {
+		atomic, granular := "atomic", "granular"
+		// an embedding struct, embedding a type through a field with its own markers
+		original := func() *apiextensionsv1.JSONSchemaProps {
+			return &apiextensionsv1.JSONSchemaProps{
+				Type:     "object",
+				XMapType: &atomic,
+				Required: []string{"outer"},
+				AllOf: []apiextensionsv1.JSONSchemaProps{{
+					AllOf: []apiextensionsv1.JSONSchemaProps{
+						{Type: "object", XMapType: &granular, XListType: &granular, Required: []string{"inner"}},
+						{XListType: &atomic},
+					},
+				}},
+			}
+		}
+
+		It("should let the embedded types win in the legacy mode", func() {
+			flattened := crd.FlattenEmbedded(original(), errRec)
+			Expect(errRec.FirstError()).NotTo(HaveOccurred())
+
+			Expect(flattened.XMapType).To(Equal(&granular))
+			Expect(flattened.Required).To(Equal([]string{"inner", "outer"}))
+			Expect(flattened.AllOf).To(ConsistOf(
+				apiextensionsv1.JSONSchemaProps{XListType: &granular},
+				apiextensionsv1.JSONSchemaProps{XListType: &atomic}))
+		})
+
+		It("should let the closest markers win in the strict mode", func() {
+			flattened := crd.FlattenEmbeddedWithMode(original(), errRec, crd.EmbeddedMarkersStrict)
+			Expect(errRec.FirstError()).NotTo(HaveOccurred())
+
+			Expect(flattened).To(Equal(&apiextensionsv1.JSONSchemaProps{
+				Type:      "object",
+				XMapType:  &atomic,
+				XListType: &atomic,
+				Required:  []string{"inner", "outer"},
+			}))
+		})
+
+		It("should let later embedded types win over earlier ones in the strict mode", func() {
+			flattened := crd.FlattenEmbeddedWithMode(&apiextensionsv1.JSONSchemaProps{
+				AllOf: []apiextensionsv1.JSONSchemaProps{{XMapType: &atomic}, {XMapType: &granular}},
+			}, errRec, crd.EmbeddedMarkersStrict)
+			Expect(errRec.FirstError()).NotTo(HaveOccurred())
+
+			Expect(flattened.XMapType).To(Equal(&granular))
+		})
+
+		It("should reject unknown modes", func() {
+			Expect(crd.EmbeddedMarkers("").Validate()).To(Succeed())
+			Expect(crd.EmbeddedMarkersStrict.Validate()).To(Succeed())
+			Expect(crd.EmbeddedMarkers("merge").Validate()).To(MatchError(ContainSubstring(`unknown embedded markers mode "merge"`)))
+		})
+	})
 })
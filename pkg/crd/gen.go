@@ -27,9 +27,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
 	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
-	"sigs.k8s.io/controller-tools/pkg/version"
 )
 
 // The identifier for v1 CustomResourceDefinitions.
@@ -79,6 +79,21 @@ type Generator struct {
 	// GenerateEmbeddedObjectMeta specifies if any embedded ObjectMeta in the CRD should be generated
 	GenerateEmbeddedObjectMeta *bool `marker:",optional"`
 
+	// EmbeddedMarkers selects how markers of embedded types combine with the embedding struct's.
+	//
+	// Markers of embedded types include those of the fields embedding them.
+	// Validation markers always combine, the embedding struct having to be
+	// valid for each of the types it embeds.  Topology markers (mapType,
+	// structType, listType, listMapKey) and PreserveUnknownFields can't, so:
+	//
+	// - "legacy" (the default) lets those of the embedded types win, and keeps
+	// conflicting listType and listMapKey markers in an allOf.
+	//
+	// - "strict" lets those closest to the schema win: those of the embedding
+	// struct win over those of the embedded types, those of the embedding fields
+	// over those of their types, and later embedded fields over earlier ones.
+	EmbeddedMarkers string `marker:",optional"`
+
 	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
 	HeaderFile string `marker:",optional"`
 
@@ -98,6 +113,32 @@ type Generator struct {
 	// See https://kubernetes.io/docs/tasks/extend-kubernetes/custom-resources/custom-resource-definitions/#field-pruning
 	// for more information about field pruning and v1beta1 resources compatibility.
 	DeprecatedV1beta1CompatibilityPreserveUnknownFields *bool `marker:",optional"`
+
+	// Descriptions specifies a catalog file replacing the descriptions of
+	// types and fields, e.g. with translations, or with wording reviewed
+	// separately from the Go source:
+	//
+	//	descriptions:
+	//	- package: example.com/api/v1
+	//	  type: CronJob
+	//	  description: A CronJob runs Jobs on a schedule.
+	//	  fields:
+	//	    Spec: Spec is the schedule of the Jobs, and their template.
+	//
+	// Fields are listed by their Go names.
+	Descriptions string `marker:",optional"`
+
+	// ValidateOn dry-run applies the generated CRDs to an API server, reporting
+	// the errors of the server (e.g. non-structural schemata, or CEL rules that
+	// don't compile or are too costly) at the Go fields and types they come
+	// from.
+	//
+	// "envtest" starts an API server from the envtest binaries of the
+	// directory of $KUBEBUILDER_ASSETS (e.g. as installed by setup-envtest),
+	// "cluster" uses the cluster of the current context of $KUBECONFIG (or
+	// ~/.kube/config), and any other value is the path of the kubeconfig file
+	// of the cluster to use.  Nothing is persisted.
+	ValidateOn string `marker:",optional"`
 }
 
 func (Generator) CheckFilter() loader.NodeFilter {
@@ -107,6 +148,64 @@ func (Generator) RegisterMarkers(into *markers.Registry) error {
 	return crdmarkers.Register(into)
 }
 
+// MarkerConflicts returns the markers that conflict with one another: the
+// ones making fields (or the fields of types) both optional and required,
+// and the ones making a version both the storage version and unserved or
+// skipped.
+func (Generator) MarkerConflicts() [][2]string {
+	var conflicts [][2]string
+	for _, optional := range []string{"optional", "kubebuilder:validation:Optional", "k8s:optional"} {
+		for _, required := range []string{"required", "kubebuilder:validation:Required", "k8s:required"} {
+			conflicts = append(conflicts, [2]string{optional, required})
+		}
+	}
+	return append(conflicts,
+		[2]string{"kubebuilder:storageversion", "kubebuilder:unservedversion"},
+		[2]string{"kubebuilder:storageversion", "kubebuilder:skipversion"},
+	)
+}
+
+// IncrementalRoots returns the roots of the API groups of the affected roots,
+// since the CustomResourceDefinitions of a group are generated from the
+// versions of all its packages.
+func (Generator) IncrementalRoots(ctx *genall.GenerationContext, affected []*loader.Package) []*loader.Package {
+	return GroupRoots(ctx, affected)
+}
+
+// GroupRoots returns the roots of the given context in the same API group as
+// any of the given packages, along with the given packages themselves (e.g.
+// for packages without a group).
+func GroupRoots(ctx *genall.GenerationContext, pkgs []*loader.Package) []*loader.Package {
+	groupOf := func(pkg *loader.Package) string {
+		pkgMarkers, err := markers.PackageMarkers(ctx.Collector, pkg)
+		if err != nil {
+			return ""
+		}
+		return crd.GroupVersionForPackage(pkgMarkers, pkg).Group
+	}
+
+	groups := make(map[string]struct{})
+	included := make(map[*loader.Package]struct{}, len(pkgs))
+	for _, pkg := range pkgs {
+		included[pkg] = struct{}{}
+		if group := groupOf(pkg); group != "" {
+			groups[group] = struct{}{}
+		}
+	}
+
+	var res []*loader.Package
+	for _, root := range ctx.Roots {
+		if _, isIncluded := included[root]; isIncluded {
+			res = append(res, root)
+			continue
+		}
+		if _, inGroup := groups[groupOf(root)]; inGroup {
+			res = append(res, root)
+		}
+	}
+	return res
+}
+
 // transformRemoveCRDStatus ensures we do not write the CRD status field.
 func transformRemoveCRDStatus(obj map[string]any) error {
 	delete(obj, "status")
@@ -124,32 +223,9 @@ func transformPreserveUnknownFields(value bool) func(map[string]any) error {
 }
 
 func (g Generator) Generate(ctx *genall.GenerationContext) error {
-	parser := &Parser{
-		Collector: ctx.Collector,
-		Checker:   ctx.Checker,
-		// Perform defaulting here to avoid ambiguity later
-		IgnoreUnexportedFields: g.IgnoreUnexportedFields != nil && *g.IgnoreUnexportedFields,
-		AllowDangerousTypes:    g.AllowDangerousTypes != nil && *g.AllowDangerousTypes,
-		// Indicates the parser on whether to register the ObjectMeta type or not
-		GenerateEmbeddedObjectMeta: g.GenerateEmbeddedObjectMeta != nil && *g.GenerateEmbeddedObjectMeta,
-	}
-
-	AddKnownTypes(parser)
-	for _, root := range ctx.Roots {
-		parser.NeedPackage(root)
-	}
-
-	metav1Pkg := FindMetav1(ctx.Roots)
-	if metav1Pkg == nil {
-		// no objects in the roots, since nothing imported metav1
-		return nil
-	}
-
-	// TODO: allow selecting a specific object
-	kubeKinds := FindKubeKinds(parser, metav1Pkg)
-	if len(kubeKinds) == 0 {
-		// no objects in the roots
-		return nil
+	parser, crds, err := g.generateCRDs(ctx)
+	if err != nil || len(crds) == 0 {
+		return err
 	}
 
 	crdVersions := g.CRDVersions
@@ -177,17 +253,22 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		yamlOpts = append(yamlOpts, genall.WithTransform(transformPreserveUnknownFields(*g.DeprecatedV1beta1CompatibilityPreserveUnknownFields)))
 	}
 
-	for _, groupKind := range kubeKinds {
-		parser.NeedCRDFor(groupKind, g.MaxDescLen)
-		crdRaw := parser.CustomResourceDefinitions[groupKind]
-		addAttribution(&crdRaw)
-
-		// Prevent the top level metadata for the CRD to be generate regardless of the intention in the arguments
-		FixTopLevelMetadata(crdRaw)
+	var toValidate []*apiextensionsv1.CustomResourceDefinition
+	for i := range crds {
+		crdRaw := &crds[i]
+		if g.ValidateOn != "" {
+			toValidate = append(toValidate, crdRaw)
+		}
 
 		versionedCRDs := make([]any, len(crdVersions))
 		for i, ver := range crdVersions {
-			conv, err := AsVersion(crdRaw, schema.GroupVersion{Group: apiextensionsv1.SchemeGroupVersion.Group, Version: ver})
+			if ver == v1 {
+				// already in the canonical form, and the parser is done with
+				// it, so there's no need for a copy
+				versionedCRDs[i] = crdRaw
+				continue
+			}
+			conv, err := AsVersion(*crdRaw, schema.GroupVersion{Group: apiextensionsv1.SchemeGroupVersion.Group, Version: ver})
 			if err != nil {
 				return err
 			}
@@ -195,7 +276,6 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		}
 
 		for i, crd := range versionedCRDs {
-			removeDescriptionFromMetadata(crd.(*apiextensionsv1.CustomResourceDefinition))
 			var fileName string
 			if i == 0 {
 				fileName = fmt.Sprintf("%s_%s.yaml", crdRaw.Spec.Group, crdRaw.Spec.Names.Plural)
@@ -208,9 +288,153 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		}
 	}
 
+	if len(toValidate) > 0 {
+		return g.validateOnServer(parser, toValidate)
+	}
 	return nil
 }
 
+// CustomResourceDefinitions returns the v1 CustomResourceDefinitions of the
+// kinds of the roots, as Generate writes them, without writing (or
+// validating) them.  The errors found are added to the packages they're
+// about, as with Generate.
+func (g Generator) CustomResourceDefinitions(ctx *genall.GenerationContext) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	_, crds, err := g.generateCRDs(ctx)
+	return crds, err
+}
+
+// Schemata returns the flattened schemata of the types of the API packages
+// of the roots, as they're embedded in CustomResourceDefinitions, with their
+// descriptions truncated to MaxDescLen.  The errors found are added to the
+// packages they're about, as with Generate.
+func (g Generator) Schemata(ctx *genall.GenerationContext) (map[TypeIdent]apiextensionsv1.JSONSchemaProps, error) {
+	parser, err := g.newParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var typs []TypeIdent
+	for _, root := range ctx.Roots {
+		if _, isAPIPackage := parser.GroupVersions[root]; !isAPIPackage {
+			continue
+		}
+		if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+			typs = append(typs, TypeIdent{Package: root, Name: info.Name})
+		}); err != nil {
+			root.AddError(err)
+		}
+	}
+	parser.NeedSchemasFor(typs...)
+
+	schemata := make(map[TypeIdent]apiextensionsv1.JSONSchemaProps, len(typs))
+	for _, typ := range typs {
+		parser.NeedFlattenedSchemaFor(typ)
+		schema := parser.FlattenedSchemata[typ]
+		schema = *schema.DeepCopy() // don't mutate the cache (we might be truncating descriptions)
+		if g.MaxDescLen != nil {
+			TruncateDescription(&schema, *g.MaxDescLen)
+		}
+		schemata[typ] = schema
+	}
+	return schemata, nil
+}
+
+// newParser returns the parser of the generator, with the roots loaded.
+func (g Generator) newParser(ctx *genall.GenerationContext) (*Parser, error) {
+	embeddedMarkers := EmbeddedMarkers(g.EmbeddedMarkers)
+	if err := embeddedMarkers.Validate(); err != nil {
+		return nil, err
+	}
+
+	parser := &Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// Perform defaulting here to avoid ambiguity later
+		IgnoreUnexportedFields: g.IgnoreUnexportedFields != nil && *g.IgnoreUnexportedFields,
+		AllowDangerousTypes:    g.AllowDangerousTypes != nil && *g.AllowDangerousTypes,
+		// Indicates the parser on whether to register the ObjectMeta type or not
+		GenerateEmbeddedObjectMeta: g.GenerateEmbeddedObjectMeta != nil && *g.GenerateEmbeddedObjectMeta,
+		EmbeddedMarkers:            embeddedMarkers,
+	}
+	if ctx.FeatureGates.Enabled(genall.ConcurrentSchemas) {
+		parser.Parallelism = ctx.GeneratorParallelism
+	}
+	if g.Descriptions != "" {
+		contents, err := ctx.ReadFile(g.Descriptions)
+		if err != nil {
+			return nil, err
+		}
+		if parser.Descriptions, err = LoadDescriptionCatalog(contents); err != nil {
+			return nil, err
+		}
+	}
+
+	AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+	return parser, nil
+}
+
+// generateCRDs returns the v1 CustomResourceDefinitions of the kinds of the
+// roots, in the order of their group-kinds, along with the parser they were
+// generated with.
+func (g Generator) generateCRDs(ctx *genall.GenerationContext) (*Parser, []apiextensionsv1.CustomResourceDefinition, error) {
+	parser, err := g.newParser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	// only the API packages are looked into from there on
+	for _, root := range ctx.Roots {
+		if _, isAPIPackage := parser.GroupVersions[root]; !isAPIPackage {
+			ctx.DoneWith(root)
+		}
+	}
+
+	metav1Pkg := FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return parser, nil, nil
+	}
+
+	// TODO: allow selecting a specific object
+	kubeKinds := FindKubeKinds(parser, metav1Pkg)
+	if len(kubeKinds) == 0 {
+		// no objects in the roots
+		return parser, nil, nil
+	}
+
+	// generate the schemata of the kinds, and of the types they refer to,
+	// at once, so that independent types are generated concurrently
+	if parser.Parallelism > 1 {
+		var kindTypes []TypeIdent
+		for _, groupKind := range kubeKinds {
+			for pkg, gv := range parser.GroupVersions {
+				if gv.Group == groupKind.Group && parser.LookupType(pkg, groupKind.Kind) != nil {
+					kindTypes = append(kindTypes, TypeIdent{Package: pkg, Name: groupKind.Kind})
+				}
+			}
+		}
+		parser.NeedSchemasFor(kindTypes...)
+	}
+
+	crds := make([]apiextensionsv1.CustomResourceDefinition, 0, len(kubeKinds))
+	for _, groupKind := range kubeKinds {
+		parser.NeedCRDFor(groupKind, g.MaxDescLen)
+		crdRaw := parser.CustomResourceDefinitions[groupKind]
+		addAttribution(&crdRaw, ctx.GeneratorVersion())
+		checkModuleVersion(ctx, parser, groupKind, &crdRaw)
+
+		// Prevent the top level metadata for the CRD to be generate regardless of the intention in the arguments
+		FixTopLevelMetadata(crdRaw)
+		removeDescriptionFromMetadata(&crdRaw)
+		crds = append(crds, crdRaw)
+	}
+	return parser, crds, nil
+}
+
 func removeDescriptionFromMetadata(crd *apiextensionsv1.CustomResourceDefinition) {
 	for _, versionSpec := range crd.Spec.Versions {
 		if versionSpec.Schema != nil {
@@ -243,12 +467,12 @@ func FixTopLevelMetadata(crd apiextensionsv1.CustomResourceDefinition) {
 }
 
 // addAttribution adds attribution info to indicate controller-gen tool was used
-// to generate this CRD definition along with the version info.
-func addAttribution(crd *apiextensionsv1.CustomResourceDefinition) {
+// to generate this CRD definition along with the given version.
+func addAttribution(crd *apiextensionsv1.CustomResourceDefinition, generatorVersion string) {
 	if crd.ObjectMeta.Annotations == nil {
 		crd.ObjectMeta.Annotations = map[string]string{}
 	}
-	crd.ObjectMeta.Annotations["controller-gen.kubebuilder.io/version"] = version.Version()
+	crd.ObjectMeta.Annotations["controller-gen.kubebuilder.io/version"] = generatorVersion
 }
 
 // FindMetav1 locates the actual package representing metav1 amongst
@@ -265,7 +489,8 @@ func FindMetav1(roots []*loader.Package) *loader.Package {
 
 // FindKubeKinds locates all types that contain TypeMeta and ObjectMeta
 // (and thus may be a Kubernetes object), and returns the corresponding
-// group-kinds.
+// group-kinds, restricted to the ones with a version matching the kinds
+// filter of the parser.
 func FindKubeKinds(parser *Parser, metav1Pkg *loader.Package) []schema.GroupKind {
 	// TODO(directxman12): technically, we should be finding metav1 per-package
 	kubeKinds := map[schema.GroupKind]struct{}{}
@@ -318,11 +543,11 @@ func FindKubeKinds(parser *Parser, metav1Pkg *loader.Package) []schema.GroupKind
 			continue
 		}
 
-		groupKind := schema.GroupKind{
-			Group: parser.GroupVersions[pkg].Group,
-			Kind:  typeIdent.Name,
+		gvk := parser.GroupVersions[pkg].WithKind(typeIdent.Name)
+		if !parser.Kinds.Matches(gvk) {
+			continue
 		}
-		kubeKinds[groupKind] = struct{}{}
+		kubeKinds[gvk.GroupKind()] = struct{}{}
 	}
 
 	groupKindList := make([]schema.GroupKind, 0, len(kubeKinds))
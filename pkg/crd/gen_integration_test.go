@@ -116,6 +116,63 @@ var _ = Describe("CRD Generation proper defaulting", func() {
 		Expect(out.buf.String()).To(Equal(expectedOut), cmp.Diff(out.buf.String(), expectedOut))
 	})
 
+	It("should generate the same CRDs from schemata shared with other generators", func() {
+		By("calling Generate twice on multiple packages, sharing a cache")
+		gen := &crd.Generator{
+			CRDVersions: []string{"v1"},
+		}
+		ctx2.Cache = &genall.Cache{}
+		Expect(gen.Generate(ctx2)).NotTo(HaveOccurred())
+		out.buf.Reset()
+		Expect(gen.Generate(ctx2)).NotTo(HaveOccurred())
+
+		By("loading the desired YAMLs")
+		expectedFileFoos, err := os.ReadFile(filepath.Join(genDir, "bar.example.com_foos.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		expectedFileZoos, err := os.ReadFile(filepath.Join(genDir, "zoo", "bar.example.com_zoos.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("comparing the two")
+		expectedOut := string(expectedFileFoos) + string(expectedFileZoos)
+		Expect(out.buf.String()).To(Equal(expectedOut), cmp.Diff(out.buf.String(), expectedOut))
+	})
+
+	It("should generate the same CRDs with the schemata generated concurrently", func() {
+		By("calling Generate on multiple packages, with concurrent schemata")
+		gen := &crd.Generator{
+			CRDVersions: []string{"v1"},
+		}
+		ctx2.FeatureGates = genall.FeatureGates{genall.ConcurrentSchemas: true}
+		ctx2.GeneratorParallelism = 4
+		Expect(gen.Generate(ctx2)).NotTo(HaveOccurred())
+
+		By("loading the desired YAMLs")
+		expectedFileFoos, err := os.ReadFile(filepath.Join(genDir, "bar.example.com_foos.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		expectedFileZoos, err := os.ReadFile(filepath.Join(genDir, "zoo", "bar.example.com_zoos.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("comparing the two")
+		expectedOut := string(expectedFileFoos) + string(expectedFileZoos)
+		Expect(out.buf.String()).To(Equal(expectedOut), cmp.Diff(out.buf.String(), expectedOut))
+	})
+
+	It("should only generate the CRDs of the kinds matching the kinds filter", func() {
+		By("calling Generate on multiple packages, restricted to a kind")
+		gen := &crd.Generator{
+			CRDVersions: []string{"v1"},
+		}
+		ctx2.Kinds = genall.KindFilter{"bar.example.com/*/Zoo"}
+		Expect(gen.Generate(ctx2)).NotTo(HaveOccurred())
+
+		By("loading the desired YAML")
+		expectedFile, err := os.ReadFile(filepath.Join(genDir, "zoo", "bar.example.com_zoos.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("comparing the two")
+		Expect(out.buf.String()).To(Equal(string(expectedFile)), cmp.Diff(out.buf.String(), string(expectedFile)))
+	})
+
 	It("should add preserveUnknownFields=false when specified", func() {
 		By("calling Generate")
 		no := false
@@ -169,6 +226,51 @@ var _ = Describe("CRD Generation proper defaulting", func() {
 		By("comparing the two")
 		Expect(out.buf.String()).To(Equal(string(expectedFile)), cmp.Diff(out.buf.String(), string(expectedFile)))
 	})
+
+	It("should replace the descriptions from the description catalog", func() {
+		By("writing the catalog")
+		catalog := filepath.Join(GinkgoT().TempDir(), "descriptions.yaml")
+		Expect(os.WriteFile(catalog, []byte(`descriptions:
+- package: testdata.kubebuilder.io/cronjob/gen
+  type: Foo
+  description: A Foo, described by the catalog.
+  fields:
+    Spec: The spec of the Foo, described by the catalog.
+    Missing: A field the Foo doesn't have.
+- package: testdata.kubebuilder.io/cronjob/gen
+  type: FooSpec
+  fields:
+    DefaultedString: A defaulted string, described by the catalog.
+- package: testdata.kubebuilder.io/cronjob/gen
+  type: Bar
+  description: A type that doesn't exist.
+`), 0o644)).To(Succeed())
+
+		By("calling Generate")
+		ctx.InputRule = genall.InputFromFileSystem
+		gen := &crd.Generator{
+			CRDVersions:  []string{"v1"},
+			Descriptions: catalog,
+		}
+		Expect(gen.Generate(ctx)).NotTo(HaveOccurred())
+
+		By("checking the descriptions")
+		Expect(out.buf.String()).To(ContainSubstring("description: A Foo, described by the catalog."))
+		Expect(out.buf.String()).To(ContainSubstring("description: The spec of the Foo, described by the catalog."))
+		Expect(out.buf.String()).To(ContainSubstring("description: A defaulted string, described by the catalog."))
+		Expect(out.buf.String()).To(ContainSubstring("description: Status comments SHOULD appear in the CRD spec"))
+		Expect(out.buf.String()).NotTo(ContainSubstring("Spec comments SHOULD appear in the CRD spec"))
+
+		By("checking the warnings about what the catalog describes, but doesn't exist")
+		Expect(ctx.Roots[0].Diagnostics()).To(ContainElements(
+			SatisfyAll(
+				HaveField("Severity", loader.SeverityWarning),
+				HaveField("Message", "the description catalog describes the field Missing, which type Foo doesn't have")),
+			SatisfyAll(
+				HaveField("Severity", loader.SeverityWarning),
+				HaveField("Message", "the description catalog describes the type testdata.kubebuilder.io/cronjob/gen.Bar, which doesn't exist")),
+		))
+	})
 })
 
 type outputRule struct {
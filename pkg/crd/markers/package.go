@@ -34,6 +34,9 @@ func init() {
 		must(markers.MakeDefinition("kubebuilder:validation:Required", markers.DescribesPackage, struct{}{})).
 			WithHelp(markers.SimpleHelp("CRD validation", "specifies that all fields in this package are required by default.")),
 
+		must(markers.MakeDefinition("mapType", markers.DescribesPackage, MapType(""))).
+			WithHelp(markers.SimpleHelp("CRD processing", "specifies the default level of atomicity of all maps in this package, overridden by mapType markers on types and fields. Example: +mapType=atomic")),
+
 		must(markers.MakeDefinition("kubebuilder:skip", markers.DescribesPackage, struct{}{})).
 			WithHelp(markers.SimpleHelp("CRD", "don't consider this package as an API version. Use this to exclude internal or helper packages from CRD generation.")),
 	)
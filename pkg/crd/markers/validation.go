@@ -84,6 +84,7 @@ var ValidationMarkers = mustMakeAllWithPrefix(validationPrefix, markers.Describe
 	Enum(nil),
 	Format(""),
 	Type(""),
+	Sensitive{},
 	XPreserveUnknownFields{},
 	XEmbeddedResource{},
 	XIntOrString{},
@@ -98,6 +99,10 @@ var TypeOnlyMarkers = []*definitionWithHelp{
 		WithHelp(markers.SimpleHelp("CRD validation", "specifies a list of field names that must conform to the ExactlyOneOf constraint.")),
 	must(markers.MakeDefinition(ValidationAtLeastOneOfPrefix, markers.DescribesType, AtLeastOneOf(nil))).
 		WithHelp(markers.SimpleHelp("CRD validation", "specifies a list of field names that must conform to the AtLeastOneOf constraint.")),
+	must(markers.MakeDefinition("kubebuilder:validation:Required", markers.DescribesType, struct{}{})).
+		WithHelp(markers.SimpleHelp("CRD validation", "specifies that all fields in this type are required by default, overriding the package default.")),
+	must(markers.MakeDefinition("kubebuilder:validation:Optional", markers.DescribesType, struct{}{})).
+		WithHelp(markers.SimpleHelp("CRD validation", "specifies that all fields in this type are optional by default, overriding the package default.")),
 	must(markers.MakeDefinition(K8sEnumTag, markers.DescribesType, K8sEnum{})).
 		WithHelp(markers.SimpleHelp("CRD", "indicates that the given type is an enum; all const values of this type are considered values in the enum")),
 	must(markers.MakeDefinition(K8sEnumTag, markers.DescribesField, K8sEnumField{})),
@@ -515,6 +520,21 @@ type Example struct {
 	Value any
 }
 
+// Sensitive marks this field as holding a secret, such as a password or a token.
+//
+// String fields are given the "password" format (unless they have a format
+// already), hinting user interfaces to mask their values, and the example and
+// default values of the field are left out of the schema, and thus out of the
+// documentation and the other artifacts generated from it.
+//
+// Example:
+//
+//	// +kubebuilder:validation:Sensitive
+//	Token string
+//
+// +controllertools:marker:generateHelp:category="CRD validation"
+type Sensitive struct{}
+
 // XPreserveUnknownFields stops the apiserver from pruning fields which are not specified.
 //
 // By default the apiserver drops unknown fields from the request payload
@@ -649,7 +669,7 @@ type XValidation struct {
 	// Rule is the CEL expression that must evaluate to true.
 	// It is scoped to where this XValidation is in the schema; self is that value.
 	// Example: Rule="self.minReplicas <= self.replicas && self.replicas <= self.maxReplicas"
-	Rule string
+	Rule markers.CEL
 
 	// Message is the text shown when validation fails.
 	// If unset, the default is "failed rule: {Rule}".
@@ -664,7 +684,7 @@ type XValidation struct {
 	// The expression can use the same variables as the Rule (e.g. self, oldSelf).
 	// The result must not contain line breaks and is subject to the same message limits as Message.
 	// Example: MessageExpression="'replicas must be between ' + string(self.minReplicas) + ' and ' + string(self.maxReplicas)"
-	MessageExpression string `marker:"messageExpression,optional"`
+	MessageExpression markers.CEL `marker:"messageExpression,optional"`
 
 	// Reason is a short code for why validation failed, returned to API callers.
 	// Supported values: "FieldValueInvalid", "FieldValueForbidden", "FieldValueRequired", "FieldValueDuplicate".
@@ -970,6 +990,20 @@ func (m Example) ApplyToSchema(ctx *SchemaContext, schema *apiextensionsv1.JSONS
 	return nil
 }
 
+func (m Sensitive) ApplyToSchema(ctx *SchemaContext, schema *apiextensionsv1.JSONSchemaProps) error {
+	if schema.Type == "string" && schema.Format == "" {
+		schema.Format = "password"
+	}
+	schema.Default = nil
+	schema.Example = nil
+	return nil
+}
+
+func (m Sensitive) ApplyPriority() ApplyPriority {
+	// go after the format, default and example markers, to override them
+	return Default{}.ApplyPriority() + 1
+}
+
 func (m XPreserveUnknownFields) ApplyToSchema(ctx *SchemaContext, schema *apiextensionsv1.JSONSchemaProps) error {
 	defTrue := true
 	schema.XPreserveUnknownFields = &defTrue
@@ -1005,9 +1039,9 @@ func (m XValidation) ApplyToSchema(ctx *SchemaContext, schema *apiextensionsv1.J
 	}
 
 	schema.XValidations = append(schema.XValidations, apiextensionsv1.ValidationRule{
-		Rule:              m.Rule,
+		Rule:              m.Rule.Expression,
 		Message:           m.Message,
-		MessageExpression: m.MessageExpression,
+		MessageExpression: m.MessageExpression.Expression,
 		Reason:            reason,
 		FieldPath:         m.FieldPath,
 		OptionalOldSelf:   m.OptionalOldSelf,
@@ -1024,7 +1058,7 @@ func (fields AtMostOneOf) ApplyToSchema(ctx *SchemaContext, schema *apiextension
 		return nil
 	}
 	xvalidation := XValidation{
-		Rule:    fmt.Sprintf("%s <= 1", fieldsToOneOfSumExpr(fields)),
+		Rule:    markers.CEL{Expression: fmt.Sprintf("%s <= 1", fieldsToOneOfSumExpr(fields))},
 		Message: fmt.Sprintf("at most one of the fields in %v may be set", fields),
 	}
 	return xvalidation.ApplyToSchema(ctx, schema)
@@ -1040,7 +1074,7 @@ func (fields ExactlyOneOf) ApplyToSchema(ctx *SchemaContext, schema *apiextensio
 		return nil
 	}
 	xvalidation := XValidation{
-		Rule:    fmt.Sprintf("%s == 1", fieldsToOneOfSumExpr(fields)),
+		Rule:    markers.CEL{Expression: fmt.Sprintf("%s == 1", fieldsToOneOfSumExpr(fields))},
 		Message: fmt.Sprintf("exactly one of the fields in %v must be set", fields),
 	}
 	return xvalidation.ApplyToSchema(ctx, schema)
@@ -1056,7 +1090,7 @@ func (fields AtLeastOneOf) ApplyToSchema(ctx *SchemaContext, schema *apiextensio
 		return nil
 	}
 	xvalidation := XValidation{
-		Rule:    fieldsToOneOfOrExpr(fields),
+		Rule:    markers.CEL{Expression: fieldsToOneOfOrExpr(fields)},
 		Message: fmt.Sprintf("at least one of the fields in %v must be set", fields),
 	}
 	return xvalidation.ApplyToSchema(ctx, schema)
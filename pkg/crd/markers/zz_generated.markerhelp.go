@@ -465,6 +465,17 @@ func (SelectableField) Help() *markers.DefinitionHelp {
 	}
 }
 
+func (Sensitive) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "CRD validation",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "marks this field as holding a secret, such as a password or a token.",
+			Details: "String fields are given the \"password\" format (unless they have a format\nalready), hinting user interfaces to mask their values, and the example and\ndefault values of the field are left out of the schema, and thus out of the\ndocumentation and the other artifacts generated from it.\n\nExample:\n\n\t// +kubebuilder:validation:Sensitive\n\tToken string",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{},
+	}
+}
+
 func (SkipVersion) Help() *markers.DefinitionHelp {
 	return &markers.DefinitionHelp{
 		Category: "CRD",
@@ -18,9 +18,11 @@ package crd
 
 import (
 	"fmt"
+	"sync"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-tools/pkg/genall"
 	"sigs.k8s.io/controller-tools/pkg/internal/crd"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
@@ -39,7 +41,8 @@ func (t TypeIdent) String() string {
 // PackageOverride overrides the loading of some package
 // (potentially setting custom schemata, etc).  It must
 // call AddPackage if it wants to continue with the default
-// loading behavior.
+// loading behavior.  It's called with the maps of the parser guarded, so it
+// must not call the other methods of the parser.
 type PackageOverride func(p *Parser, pkg *loader.Package)
 
 // Parser knows how to parse out CRD information and generate
@@ -92,6 +95,95 @@ type Parser struct {
 
 	// GenerateEmbeddedObjectMeta specifies if any embedded ObjectMeta should be generated
 	GenerateEmbeddedObjectMeta bool
+
+	// EmbeddedMarkers selects how the markers of embedded types combine with
+	// those of the structs embedding them.  Defaults to the legacy mode.
+	EmbeddedMarkers EmbeddedMarkers
+
+	// Descriptions replace the descriptions of the types and fields they
+	// list, if set.
+	Descriptions *DescriptionCatalog
+
+	// Kinds restricts the kinds found by FindKubeKinds, if not empty.
+	Kinds genall.KindFilter
+
+	// Cache shares the schemata of types with the other parsers using it
+	// (e.g. of the other generators of a run), so that they're generated
+	// once for all parsers with the same options.
+	Cache *genall.Cache
+
+	// Parallelism is the maximum number of schemata generated concurrently
+	// by NeedSchemasFor.  They're generated one after another if it's zero
+	// or one.
+	Parallelism int
+
+	// mu guards the maps of the parser while schemata are generated
+	// concurrently.
+	mu sync.Mutex
+	// schemaGroup generates the schemata requested during NeedSchemasFor
+	// concurrently, if set.
+	schemaGroup *schemaGroup
+}
+
+// schemaGroup are the goroutines generating schemata concurrently, bounded
+// by the parallelism of the parser.
+type schemaGroup struct {
+	wg  sync.WaitGroup
+	sem chan struct{}
+}
+
+// sharedSchemataKey is the key of the shared schemata in a genall.Cache.
+type sharedSchemataKey struct{}
+
+// sharedSchemaKey identifies a shared schema: its type, and the options of
+// the parsers generating it the same way.
+type sharedSchemaKey struct {
+	typ                    TypeIdent
+	allowDangerousTypes    bool
+	ignoreUnexportedFields bool
+	descriptions           *DescriptionCatalog
+}
+
+// sharedSchemata are the schemata shared by parsers through a genall.Cache.
+// Schemata are copied in and out, so that parsers can't change each other's.
+type sharedSchemata struct {
+	mu       sync.RWMutex
+	schemata map[sharedSchemaKey]*apiextensionsv1.JSONSchemaProps
+}
+
+// load returns a copy of the shared schema with the given key, if any.
+func (s *sharedSchemata) load(key sharedSchemaKey) (apiextensionsv1.JSONSchemaProps, bool) {
+	if s == nil {
+		return apiextensionsv1.JSONSchemaProps{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schema, isShared := s.schemata[key]
+	if !isShared {
+		return apiextensionsv1.JSONSchemaProps{}, false
+	}
+	return *schema.DeepCopy(), true
+}
+
+// store shares a copy of the given schema under the given key.
+func (s *sharedSchemata) store(key sharedSchemaKey, schema *apiextensionsv1.JSONSchemaProps) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemata[key] = schema.DeepCopy()
+}
+
+// sharedSchemata returns the schemata shared through the cache of the
+// parser, or nil if it has none.
+func (p *Parser) sharedSchemata() *sharedSchemata {
+	if p.Cache == nil {
+		return nil
+	}
+	return p.Cache.Get(sharedSchemataKey{}, func() any {
+		return &sharedSchemata{schemata: make(map[sharedSchemaKey]*apiextensionsv1.JSONSchemaProps)}
+	}).(*sharedSchemata)
 }
 
 func (p *Parser) init() {
@@ -143,35 +235,96 @@ func (p *Parser) indexTypes(pkg *loader.Package) {
 			Name:    info.Name,
 		}
 
-		p.Types[ident] = info
+		p.Types[ident] = p.Descriptions.describe(pkg, info)
 	}); err != nil {
 		pkg.AddError(err)
 	}
+	p.Descriptions.checkTypes(pkg, p.Types)
 }
 
 // LookupType fetches type info from Types.
 func (p *Parser) LookupType(pkg *loader.Package, name string) *markers.TypeInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return p.Types[TypeIdent{Package: pkg, Name: name}]
 }
 
+// lookupDeclaration fetches the type info of a type whose schema is inlined,
+// along with the markers of its package, loading the package if needed.
+func (p *Parser) lookupDeclaration(pkg *loader.Package, name string) (*markers.TypeInfo, markers.MarkerValues) {
+	p.NeedPackage(pkg)
+	pkgMarkers, err := markers.PackageMarkers(p.Collector, pkg)
+	if err != nil {
+		pkg.AddError(err)
+	}
+	return p.LookupType(pkg, name), pkgMarkers
+}
+
 // NeedSchemaFor indicates that a schema should be generated for the given type.
+// During NeedSchemasFor, the schema is generated concurrently, and is only
+// known once NeedSchemasFor returns.
 func (p *Parser) NeedSchemaFor(typ TypeIdent) {
 	p.init()
 
 	p.NeedPackage(typ.Package)
+
+	p.mu.Lock()
 	if _, knownSchema := p.Schemata[typ]; knownSchema {
+		p.mu.Unlock()
+		return
+	}
+
+	// another parser with the same options may have generated it already
+	shared := p.sharedSchemata()
+	sharedKey := sharedSchemaKey{
+		typ:                    typ,
+		allowDangerousTypes:    p.AllowDangerousTypes,
+		ignoreUnexportedFields: p.IgnoreUnexportedFields,
+		descriptions:           p.Descriptions,
+	}
+	if schema, isShared := shared.load(sharedKey); isShared {
+		p.Schemata[typ] = schema
+		p.mu.Unlock()
 		return
 	}
 
 	info, knownInfo := p.Types[typ]
 	if !knownInfo {
+		p.mu.Unlock()
 		typ.Package.AddError(fmt.Errorf("unknown type %s", typ))
 		return
 	}
 
 	// avoid tripping recursive schemata, like ManagedFields, by adding an empty WIP schema
+	// (which also keeps concurrent requests from generating it again)
 	p.Schemata[typ] = apiextensionsv1.JSONSchemaProps{}
+	group := p.schemaGroup
+	p.mu.Unlock()
+
+	generate := func() {
+		schema := p.generateSchema(typ, info)
+
+		p.mu.Lock()
+		p.Schemata[typ] = *schema
+		p.mu.Unlock()
+		shared.store(sharedKey, schema)
+	}
+	if group == nil {
+		generate()
+		return
+	}
+	group.wg.Add(1)
+	go func() {
+		defer group.wg.Done()
+		group.sem <- struct{}{}
+		defer func() { <-group.sem }()
+		generate()
+	}()
+}
 
+// generateSchema generates the schema of the given type, requesting the
+// schemata of the types it refers to.
+func (p *Parser) generateSchema(typ TypeIdent, info *markers.TypeInfo) *apiextensionsv1.JSONSchemaProps {
 	schemaCtx := newSchemaContext(typ.Package, p, p.AllowDangerousTypes, p.IgnoreUnexportedFields)
 	ctxForInfo := schemaCtx.ForInfo(info)
 
@@ -181,9 +334,31 @@ func (p *Parser) NeedSchemaFor(typ TypeIdent) {
 	}
 	ctxForInfo.PackageMarkers = pkgMarkers
 
-	schema := infoToSchema(ctxForInfo)
+	return infoToSchema(ctxForInfo)
+}
+
+// NeedSchemasFor indicates that schemata should be generated for the given
+// types.  Up to Parallelism schemata, of the given types and of the types
+// they refer to, are generated concurrently, so the errors found generating
+// them are added to their packages in no particular order.
+// NeedSchemasFor must not be called concurrently with the other methods of
+// the parser.
+func (p *Parser) NeedSchemasFor(typs ...TypeIdent) {
+	p.init()
 
-	p.Schemata[typ] = *schema
+	if p.Parallelism <= 1 {
+		for _, typ := range typs {
+			p.NeedSchemaFor(typ)
+		}
+		return
+	}
+
+	p.schemaGroup = &schemaGroup{sem: make(chan struct{}, p.Parallelism)}
+	for _, typ := range typs {
+		p.NeedSchemaFor(typ)
+	}
+	p.schemaGroup.wg.Wait()
+	p.schemaGroup = nil
 }
 
 func (p *Parser) NeedFlattenedSchemaFor(typ TypeIdent) {
@@ -195,7 +370,7 @@ func (p *Parser) NeedFlattenedSchemaFor(typ TypeIdent) {
 
 	p.NeedSchemaFor(typ)
 	partialFlattened := p.flattener.FlattenType(typ)
-	fullyFlattened := FlattenEmbedded(partialFlattened, typ.Package)
+	fullyFlattened := FlattenEmbeddedWithMode(partialFlattened, typ.Package, p.EmbeddedMarkers)
 
 	p.FlattenedSchemata[typ] = *fullyFlattened
 }
@@ -206,6 +381,8 @@ func (p *Parser) NeedFlattenedSchemaFor(typ TypeIdent) {
 // for the the given package, *ignoring* overrides.
 // Generally, consumers should call NeedPackage, while PackageOverrides should
 // call AddPackage to continue with the normal loading procedure.
+// AddPackage must not be called concurrently with the other methods of the
+// parser, other than from a PackageOverride.
 func (p *Parser) AddPackage(pkg *loader.Package) {
 	p.init()
 	if _, checked := p.packages[pkg]; checked {
@@ -220,6 +397,8 @@ func (p *Parser) AddPackage(pkg *loader.Package) {
 // is needed for the given package.
 func (p *Parser) NeedPackage(pkg *loader.Package) {
 	p.init()
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if _, checked := p.packages[pkg]; checked {
 		return
 	}
@@ -153,6 +153,18 @@ var _ = Describe("CRD Generation From Parsing to CustomResourceDefinition", func
 			It("should successfully generate the CronJob CRD", func() {
 				assertCRD(pkgs[0], "CronJob", "testdata.kubebuilder.io_cronjobs.yaml")
 			})
+			It("should generate the same CronJob CRD with the schemata generated concurrently", func() {
+				By("requesting that the schemata of the versions be generated concurrently")
+				parser.Parallelism = 8
+				var kindTypes []crd.TypeIdent
+				for _, pkg := range pkgs {
+					kindTypes = append(kindTypes, crd.TypeIdent{Package: pkg, Name: "CronJob"})
+				}
+				parser.NeedSchemasFor(kindTypes...)
+				Expect(parser.Schemata).To(HaveKey(kindTypes[0]))
+
+				assertCRD(pkgs[0], "CronJob", "testdata.kubebuilder.io_cronjobs.yaml")
+			})
 		})
 
 		Context("Job API", func() {
@@ -186,6 +198,49 @@ var _ = Describe("CRD Generation From Parsing to CustomResourceDefinition", func
 			})
 		})
 
+		Context("Defaults API", func() {
+			BeforeEach(func() {
+				pkgPaths = []string{"./defaults/..."}
+				expPkgLen = 1
+			})
+			It("should cascade the package defaults to the types, unless they override them", func() {
+				assertCRD(pkgs[0], "Defaults", "testdata.kubebuilder.io_defaults.yaml")
+			})
+		})
+
+		Context("Vendored API", func() {
+			BeforeEach(func() {
+				// the vendored modules aren't downloadable, and a vendored
+				// copy with a go.mod of its own mustn't be loaded as a root
+				GinkgoT().Setenv("GOFLAGS", "-mod=vendor")
+				pkgPaths = []string{"./vendored/..."}
+				expPkgLen = 1
+			})
+			It("should resolve the overrides and the cross-package references against the vendored packages", func() {
+				assertCRD(pkgs[0], "Gadget", "testdata.kubebuilder.io_gadgets.yaml")
+			})
+		})
+
+		Context("Generics API", func() {
+			BeforeEach(func() {
+				pkgPaths = []string{"./generics"}
+				expPkgLen = 1
+			})
+			It("should inline the schemata of the instantiations of generic types", func() {
+				assertCRD(pkgs[0], "Generic", "testdata.kubebuilder.io_generics.yaml")
+			})
+		})
+
+		Context("Generics API with recursive instantiations", func() {
+			BeforeEach(func() {
+				pkgPaths = []string{"./generics_error/..."}
+				expPkgLen = 1
+			})
+			It("should reject recursive instantiations of generic types", func() {
+				assertError(pkgs[0], "GenericTree", "recursive instantiation of generic type Tree is not supported in CRD schemas")
+			})
+		})
+
 		Context("Enum API", func() {
 			BeforeEach(func() {
 				pkgPaths = []string{"./enum/..."}
@@ -22,6 +22,7 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"maps"
 	"slices"
 	"strings"
 
@@ -61,6 +62,16 @@ type applyFirstMarker interface {
 type schemaRequester interface {
 	NeedSchemaFor(typ TypeIdent)
 	LookupType(pkg *loader.Package, name string) *markers.TypeInfo
+	// lookupDeclaration fetches the type info and package markers of a type
+	// whose schema is inlined (i.e. a generic type), loading its package.
+	lookupDeclaration(pkg *loader.Package, name string) (*markers.TypeInfo, markers.MarkerValues)
+}
+
+// typeArg is the argument of a type parameter of a generic type whose schema
+// is being inlined, along with the context it's resolved in.
+type typeArg struct {
+	ctx  *schemaContext
+	expr ast.Expr
 }
 
 // schemaContext stores and provides information across a hierarchy of schema generation.
@@ -73,6 +84,13 @@ type schemaContext struct {
 
 	allowDangerousTypes    bool
 	ignoreUnexportedFields bool
+
+	// typeArgs are the arguments of the type parameters of the generic type
+	// whose schema is being inlined, by index.
+	typeArgs []typeArg
+	// instantiating are the generic types whose schemata are being inlined,
+	// to catch recursive instantiations.
+	instantiating []*types.TypeName
 }
 
 // newSchemaContext constructs a new schemaContext for the given package and schema requester.
@@ -94,8 +112,11 @@ func (c *schemaContext) ForInfo(info *markers.TypeInfo) *schemaContext {
 		pkg:                    c.pkg,
 		info:                   info,
 		schemaRequester:        c.schemaRequester,
+		PackageMarkers:         c.PackageMarkers,
 		allowDangerousTypes:    c.allowDangerousTypes,
 		ignoreUnexportedFields: c.ignoreUnexportedFields,
+		typeArgs:               c.typeArgs,
+		instantiating:          c.instantiating,
 	}
 }
 
@@ -185,8 +206,10 @@ func applyMarkers(ctx *schemaContext, markerSet markers.MarkerValues, props *api
 	markers := make([]schemaMarkerWithName, 0, len(markerSet))
 	itemsMarkers := make([]schemaMarkerWithName, 0, len(markerSet))
 
-	for markerName, markerValues := range markerSet {
-		for _, markerValue := range markerValues {
+	// go through the markers by name, so that the ones of the same priority
+	// always apply in the same order
+	for _, markerName := range slices.Sorted(maps.Keys(markerSet)) {
+		for _, markerValue := range markerSet[markerName] {
 			if schemaMarker, isSchemaMarker := markerValue.(SchemaMarker); isSchemaMarker {
 				if strings.HasPrefix(markerName, crdmarkers.ValidationItemsPrefix) {
 					itemsMarkers = append(itemsMarkers, schemaMarkerWithName{
@@ -232,18 +255,18 @@ func applyMarkers(ctx *schemaContext, markerSet markers.MarkerValues, props *api
 	schemaCtx := &crdmarkers.SchemaContext{Package: ctx.pkg, TypeInfo: ctx.info}
 	for _, schemaMarker := range markers {
 		if err := schemaMarker.SchemaMarker.ApplyToSchema(schemaCtx, props); err != nil {
-			ctx.pkg.AddError(loader.ErrFromNode(err /* an okay guess */, node))
+			ctx.pkg.AddError(loader.ErrFromNode(loader.ErrFromMarker(err, schemaMarker.Name) /* an okay guess */, node))
 		}
 	}
 
 	for _, schemaMarker := range itemsMarkers {
 		if props.Type != "array" || props.Items == nil || props.Items.Schema == nil {
 			err := fmt.Errorf("must apply %s to an array value, found %s", schemaMarker.Name, props.Type)
-			ctx.pkg.AddError(loader.ErrFromNode(err, node))
+			ctx.pkg.AddError(loader.ErrFromNode(loader.ErrFromMarker(err, schemaMarker.Name), node))
 		} else {
 			itemsSchema := props.Items.Schema
 			if err := schemaMarker.SchemaMarker.ApplyToSchema(schemaCtx, itemsSchema); err != nil {
-				ctx.pkg.AddError(loader.ErrFromNode(err /* an okay guess */, node))
+				ctx.pkg.AddError(loader.ErrFromNode(loader.ErrFromMarker(err, schemaMarker.Name) /* an okay guess */, node))
 			}
 		}
 	}
@@ -265,6 +288,8 @@ func typeToSchema(ctx *schemaContext, rawType ast.Expr) *apiextensionsv1.JSONSch
 		props = typeToSchema(ctx.ForInfo(&markers.TypeInfo{}), expr.X)
 	case *ast.StructType:
 		props = structToSchema(ctx, expr)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		props = instanceToSchema(ctx, expr)
 	case *ast.InterfaceType:
 		ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("interface type is not supported in CRD schemas; consider using an explicit type or apiextensionsv1.JSON instead"), rawType))
 		return &apiextensionsv1.JSONSchemaProps{}
@@ -273,7 +298,9 @@ func typeToSchema(ctx *schemaContext, rawType ast.Expr) *apiextensionsv1.JSONSch
 		return &apiextensionsv1.JSONSchemaProps{}
 	}
 
-	props.Description = ctx.info.Doc
+	if ctx.info.Doc != "" || !inlinesInstance(ctx, rawType) {
+		props.Description = ctx.info.Doc
+	}
 
 	applyMarkers(ctx, ctx.info.Markers, props, rawType)
 
@@ -336,6 +363,8 @@ func localNamedToSchema(ctx *schemaContext, ident *ast.Ident) *apiextensionsv1.J
 	case *types.Interface:
 		ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("cannot generate schema for %s; interface type is not supported in CRD schemas, consider using an explicit type or apiextensionsv1.JSON instead", ident.Name), ident))
 		return &apiextensionsv1.JSONSchemaProps{}
+	case *types.TypeParam:
+		return typeParamToSchema(ctx, typeInfo, ident)
 	case interface{ Obj() *types.TypeName }:
 		// NB(directxman12): if there are dot imports, this might be an external reference,
 		// so use typechecking info to get the actual object
@@ -397,10 +426,87 @@ func namedToSchema(ctx *schemaContext, named *ast.SelectorExpr) *apiextensionsv1
 	// NB(directxman12): we special-case things like resource.Quantity during the "collapse" phase.
 }
 
+// instanceToSchema creates a schema for an instantiation of a generic type
+// (e.g. `Wrapper[string]`) by inlining the schema of the generic type, with
+// its type parameters resolved to the type arguments.  Instantiations don't
+// get definitions of their own, since their schemata depend on their type
+// arguments.
+func instanceToSchema(ctx *schemaContext, expr ast.Expr) *apiextensionsv1.JSONSchemaProps {
+	inst, isInstance := loader.InstanceOf(ctx.pkg.TypesInfo, expr)
+	if !isInstance {
+		ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("unsupported AST kind %T", expr), expr))
+		return &apiextensionsv1.JSONSchemaProps{}
+	}
+	typeName := inst.Named.Origin().Obj()
+	if slices.Contains(ctx.instantiating, typeName) {
+		ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("recursive instantiation of generic type %s is not supported in CRD schemas", typeName.Name()), expr))
+		return &apiextensionsv1.JSONSchemaProps{}
+	}
+
+	pkg := ctx.pkg
+	if typeName.Pkg() != ctx.pkg.Types {
+		pkgPath := loader.NonVendorPath(typeName.Pkg().Path())
+		if pkg = ctx.pkg.Imports()[pkgPath]; pkg == nil {
+			pkg = ctx.findPackageRecursive(typeName.Pkg().Path())
+		}
+		if pkg == nil {
+			ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("unable to find package %q for type %s (not in direct imports)", pkgPath, typeName.Name()), expr))
+			return &apiextensionsv1.JSONSchemaProps{}
+		}
+	}
+	info, pkgMarkers := ctx.schemaRequester.lookupDeclaration(pkg, typeName.Name())
+	if info == nil {
+		ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("unknown type %s", typeName.Name()), expr))
+		return &apiextensionsv1.JSONSchemaProps{}
+	}
+
+	declCtx := newSchemaContext(pkg, ctx.schemaRequester, ctx.allowDangerousTypes, ctx.ignoreUnexportedFields).ForInfo(info)
+	declCtx.PackageMarkers = pkgMarkers
+	declCtx.instantiating = append(slices.Clip(ctx.instantiating), typeName)
+	for _, argExpr := range inst.TypeArgs {
+		declCtx.typeArgs = append(declCtx.typeArgs, typeArg{ctx: ctx, expr: argExpr})
+	}
+	return infoToSchema(declCtx)
+}
+
+// inlinesInstance checks whether the schema of the given type expression is
+// the inlined schema of an instantiation of a generic type (or a pointer to
+// one), which keeps the documentation of the generic type unless overridden,
+// like referenced types do once flattened.
+func inlinesInstance(ctx *schemaContext, expr ast.Expr) bool {
+	if star, isStar := expr.(*ast.StarExpr); isStar {
+		expr = star.X
+	}
+	_, isInstance := loader.InstanceOf(ctx.pkg.TypesInfo, expr)
+	return isInstance
+}
+
+// typeParamToSchema creates a schema for a type parameter of a generic type,
+// which is the schema of its argument in the instantiation being inlined.
+func typeParamToSchema(ctx *schemaContext, param *types.TypeParam, ident *ast.Ident) *apiextensionsv1.JSONSchemaProps {
+	if param.Index() >= len(ctx.typeArgs) {
+		ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("cannot generate schema for type parameter %s; generic types only have schemata when instantiated", ident.Name), ident))
+		return &apiextensionsv1.JSONSchemaProps{}
+	}
+	arg := ctx.typeArgs[param.Index()]
+	return typeToSchema(arg.ctx.ForInfo(&markers.TypeInfo{}), arg.expr)
+}
+
+// typeArgType resolves the given type to the type argument of the instantiation
+// being inlined if it's a type parameter.
+func typeArgType(ctx *schemaContext, typ types.Type) types.Type {
+	param, isParam := typ.(*types.TypeParam)
+	if !isParam || param.Index() >= len(ctx.typeArgs) {
+		return typ
+	}
+	arg := ctx.typeArgs[param.Index()]
+	return typeArgType(arg.ctx, arg.ctx.pkg.TypesInfo.TypeOf(arg.expr))
+}
+
 // arrayToSchema creates a schema for the items of the given array, dealing appropriately
 // with the special `[]byte` type (according to OpenAPI standards).
 func arrayToSchema(ctx *schemaContext, array *ast.ArrayType) *apiextensionsv1.JSONSchemaProps {
-	eltType := ctx.pkg.TypesInfo.TypeOf(array.Elt)
+	eltType := typeArgType(ctx, ctx.pkg.TypesInfo.TypeOf(array.Elt))
 	if eltType == byteType && array.Len == nil {
 		// byte slices are represented as base64-encoded strings
 		// (the format is defined in OpenAPI v3, but not JSON Schema)
@@ -421,7 +527,7 @@ func arrayToSchema(ctx *schemaContext, array *ast.ArrayType) *apiextensionsv1.JS
 // mapToSchema creates a schema for items of the given map.  Key types must eventually resolve
 // to string (other types aren't allowed by JSON, and thus the kubernetes API standards).
 func mapToSchema(ctx *schemaContext, mapType *ast.MapType) *apiextensionsv1.JSONSchemaProps {
-	keyType := ctx.pkg.TypesInfo.TypeOf(mapType.Key)
+	keyType := typeArgType(ctx, ctx.pkg.TypesInfo.TypeOf(mapType.Key))
 	// check that we've got a type that actually corresponds to a string, or that
 	// implements encoding.TextMarshaler (in which case it serializes to a string,
 	// just like text-marshaler-implementing field types do).
@@ -463,6 +569,8 @@ func mapToSchema(ctx *schemaContext, mapType *ast.MapType) *apiextensionsv1.JSON
 		valSchema = typeToSchema(ctx.ForInfo(&markers.TypeInfo{}), val)
 	case *ast.MapType:
 		valSchema = typeToSchema(ctx.ForInfo(&markers.TypeInfo{}), val)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		valSchema = typeToSchema(ctx.ForInfo(&markers.TypeInfo{}), val)
 	case *ast.InterfaceType:
 		ctx.pkg.AddError(loader.ErrFromNode(fmt.Errorf("interface type is not supported as map value in CRD schemas; consider using an explicit type or apiextensionsv1.JSON instead"), mapType.Value))
 		return &apiextensionsv1.JSONSchemaProps{}
@@ -472,7 +580,7 @@ func mapToSchema(ctx *schemaContext, mapType *ast.MapType) *apiextensionsv1.JSON
 	}
 
 	//nolint:goconst
-	return &apiextensionsv1.JSONSchemaProps{
+	props := &apiextensionsv1.JSONSchemaProps{
 		//nolint:goconst // this is a constant, but it's more readable to have it here
 		Type: "object",
 		AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{
@@ -480,6 +588,14 @@ func mapToSchema(ctx *schemaContext, mapType *ast.MapType) *apiextensionsv1.JSON
 			Allows: true, /* set automatically by serialization, but useful for testing */
 		},
 	}
+
+	// apply the package default, mapType markers on types and fields applying later on
+	if defaultMapType, hasDefault := ctx.PackageMarkers.Get("mapType").(crdmarkers.MapType); hasDefault {
+		if err := defaultMapType.ApplyToSchema(&crdmarkers.SchemaContext{Package: ctx.pkg, TypeInfo: ctx.info}, props); err != nil {
+			ctx.pkg.AddError(loader.ErrFromNode(loader.ErrFromMarker(err, "mapType"), mapType))
+		}
+	}
+	return props
 }
 
 // structToSchema creates a schema for the given struct.  Embedded fields are placed in AllOf,
@@ -550,9 +666,15 @@ func structToSchema(ctx *schemaContext, structType *ast.StructType) *apiextensio
 		fieldName := jsonOpts[0]
 		inline = inline || fieldName == "" // anonymous fields are inline fields in YAML/JSON
 
-		// if no default required mode is set, default to required
+		// if no default required mode is set, default to required,
+		// letting the type override the package default
 		defaultMode := "required"
-		if ctx.PackageMarkers.Get("kubebuilder:validation:Optional") != nil {
+		switch {
+		case ctx.info.Markers.Get("kubebuilder:validation:Optional") != nil:
+			defaultMode = "optional"
+		case ctx.info.Markers.Get("kubebuilder:validation:Required") != nil:
+			// explicitly required by default, whatever the package default
+		case ctx.PackageMarkers.Get("kubebuilder:validation:Optional") != nil:
 			defaultMode = "optional"
 		}
 
@@ -598,7 +720,9 @@ func structToSchema(ctx *schemaContext, structType *ast.StructType) *apiextensio
 		} else {
 			propSchema = typeToSchema(ctx.ForInfo(&markers.TypeInfo{}), field.RawField.Type)
 		}
-		propSchema.Description = field.Doc
+		if field.Doc != "" || !inlinesInstance(ctx, field.RawField.Type) {
+			propSchema.Description = field.Doc
+		}
 
 		applyMarkers(ctx, field.Markers, propSchema, field.RawField)
 
@@ -245,6 +245,25 @@ func Test_Schema_ApplyMarkers(t *testing.T) {
 	g.Expect(invocations).To(gomega.Equal([]string{"0", "applyFirst", "2", "default", "11"}))
 }
 
+func Test_Schema_SensitiveMarker(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	props := &apiextensionsv1.JSONSchemaProps{Type: "string"}
+	applyMarkers(&schemaContext{}, markers.MarkerValues{
+		"kubebuilder:validation:Sensitive": []any{crdmarkers.Sensitive{}},
+		"kubebuilder:default":              []any{crdmarkers.Default{Value: "hunter2"}},
+		"kubebuilder:example":              []any{crdmarkers.Example{Value: "hunter2"}},
+	}, props, nil)
+	g.Expect(props).To(gomega.Equal(&apiextensionsv1.JSONSchemaProps{Type: "string", Format: "password"}))
+
+	props = &apiextensionsv1.JSONSchemaProps{Type: "string"}
+	applyMarkers(&schemaContext{}, markers.MarkerValues{
+		"kubebuilder:validation:Sensitive": []any{crdmarkers.Sensitive{}},
+		"kubebuilder:validation:Format":    []any{crdmarkers.Format("byte")},
+	}, props, nil)
+	g.Expect(props.Format).To(gomega.Equal("byte"))
+}
+
 type defaultPriorityMarker struct {
 	callback func()
 }
@@ -0,0 +1,152 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"sigs.k8s.io/controller-tools/pkg/internal/apiserver"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+const (
+	// validateOnEnvtest validates CRDs on an API server started from the
+	// envtest binaries.
+	validateOnEnvtest = "envtest"
+	// validateOnCluster validates CRDs on the cluster of the current context
+	// of the default kubeconfig.
+	validateOnCluster = "cluster"
+
+	// validationFieldManager is the field manager of the dry-run applies
+	// validating CRDs.
+	validationFieldManager = "controller-gen"
+)
+
+// crdsResource is the resource of v1 CustomResourceDefinitions.
+var crdsResource = apiextensionsv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+// schemaFieldPath matches the field paths of the schemata of the versions of
+// CustomResourceDefinitions, capturing the index of the version and the path
+// below the schema.
+var schemaFieldPath = regexp.MustCompile(`^spec\.versions\[(\d+)\]\.schema\.openAPIV3Schema(?:\.(.*))?$`)
+
+// NeedsAPIServer returns true if the CRDs are validated on an API server
+// started from the envtest binaries.
+func (g Generator) NeedsAPIServer() bool {
+	return g.ValidateOn == validateOnEnvtest
+}
+
+// validateOnServer dry-run applies the given CRDs to the API server selected
+// by ValidateOn, adding the errors of the server to the Go fields and types
+// the offending schemata come from.
+func (g Generator) validateOnServer(parser *Parser, crds []*apiextensionsv1.CustomResourceDefinition) (retErr error) {
+	ctx := context.Background()
+	var config *rest.Config
+	switch g.ValidateOn {
+	case validateOnEnvtest:
+		server, err := apiserver.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to start an API server to validate the CRDs on: %w", err)
+		}
+		defer func() {
+			retErr = errors.Join(retErr, server.Stop())
+		}()
+		config = server.Config
+	default:
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		if g.ValidateOn != validateOnCluster {
+			loadingRules.ExplicitPath = g.ValidateOn
+		}
+		var err error
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+		if err != nil {
+			return fmt.Errorf("unable to load the configuration of the cluster to validate the CRDs on: %w", err)
+		}
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, crd := range crds {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+		if err != nil {
+			return err
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		delete(obj.Object, "status")
+		_, err = client.Resource(crdsResource).Apply(ctx, crd.Name, obj, metav1.ApplyOptions{
+			FieldManager: validationFieldManager,
+			Force:        true,
+			DryRun:       []string{metav1.DryRunAll},
+		})
+		if err != nil {
+			errs = append(errs, reportServerErrors(parser, crd, err)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reportServerErrors adds the causes of the given error of the API server
+// about the given CRD to the Go fields and types they're about, returning
+// the error if it has no causes it can report this way.
+func reportServerErrors(parser *Parser, crd *apiextensionsv1.CustomResourceDefinition, err error) []error {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil || len(statusErr.Status().Details.Causes) == 0 {
+		return []error{fmt.Errorf("the API server rejects the CustomResourceDefinition %s: %w", crd.Name, err)}
+	}
+
+	groupKind := schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+	var errs []error
+	for _, cause := range statusErr.Status().Details.Causes {
+		causeErr := fmt.Errorf("the API server rejects the CustomResourceDefinition %s: %s: %s", crd.Name, cause.Field, cause.Message)
+		version, path := "", ""
+		if match := schemaFieldPath.FindStringSubmatch(cause.Field); match != nil {
+			index, _ := strconv.Atoi(match[1])
+			if index < len(crd.Spec.Versions) {
+				version, path = crd.Spec.Versions[index].Name, match[2]
+			}
+		}
+		if version == "" && len(crd.Spec.Versions) > 0 {
+			// not about a schema, so it's about the kind
+			version = crd.Spec.Versions[0].Name
+		}
+
+		pkg, node := parser.SchemaSource(groupKind, version, path)
+		if node == nil {
+			errs = append(errs, causeErr)
+			continue
+		}
+		pkg.AddError(loader.ErrFromNode(causeErr, node))
+	}
+	return errs
+}
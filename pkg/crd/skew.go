@@ -0,0 +1,129 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// apiextensionsModule is the module of the API types of CRDs.
+const apiextensionsModule = "k8s.io/apiextensions-apiserver"
+
+// crdConstruct is a construct of CRDs added to their API types in some
+// version of apiextensionsModule.
+type crdConstruct struct {
+	// name describes the construct, e.g. "the fieldPath of validation rules".
+	name string
+	// minVersion is the first version of apiextensionsModule with the
+	// construct.
+	minVersion string
+	// usedBy checks if the given CRD uses the construct.
+	usedBy func(crd *apiextensionsv1.CustomResourceDefinition) bool
+}
+
+// crdConstructs are the constructs generated CRDs may use that older
+// versions of apiextensionsModule don't support.
+var crdConstructs = []crdConstruct{{
+	name:       "the messageExpression of validation rules",
+	minVersion: "v0.27.0",
+	usedBy: usesRule(func(rule apiextensionsv1.ValidationRule) bool {
+		return rule.MessageExpression != ""
+	}),
+}, {
+	name:       "the reason of validation rules",
+	minVersion: "v0.28.0",
+	usedBy: usesRule(func(rule apiextensionsv1.ValidationRule) bool {
+		return rule.Reason != nil
+	}),
+}, {
+	name:       "the fieldPath of validation rules",
+	minVersion: "v0.28.0",
+	usedBy: usesRule(func(rule apiextensionsv1.ValidationRule) bool {
+		return rule.FieldPath != ""
+	}),
+}, {
+	name:       "selectableFields",
+	minVersion: "v0.30.0",
+	usedBy: func(crd *apiextensionsv1.CustomResourceDefinition) bool {
+		for _, ver := range crd.Spec.Versions {
+			if len(ver.SelectableFields) > 0 {
+				return true
+			}
+		}
+		return false
+	},
+}}
+
+// checkModuleVersion warns about the constructs of the given CRD that the
+// version of apiextensionsModule that the module of its kind depends on
+// doesn't support, since its types (e.g. used to load the CRD in tests, or
+// to install it) silently drop them, or fail to decode them strictly.
+func checkModuleVersion(ctx *genall.GenerationContext, parser *Parser, groupKind schema.GroupKind, crd *apiextensionsv1.CustomResourceDefinition) {
+	if ctx.ModuleVersions == nil || len(crd.Spec.Versions) == 0 {
+		return
+	}
+	pkg, node := parser.SchemaSource(groupKind, crd.Spec.Versions[0].Name, "")
+	if node == nil {
+		return
+	}
+	for _, construct := range crdConstructs {
+		if !ctx.ModuleVersions.OlderThan(pkg, apiextensionsModule, construct.minVersion) || !construct.usedBy(crd) {
+			continue
+		}
+		pkg.AddWarning(loader.ErrFromNode(fmt.Errorf("the CRD of %s uses %s, which %s %s (required by %s) doesn't support, as it needs %s: upgrade it (e.g. with go get %s@%s)",
+			groupKind, construct.name, apiextensionsModule, ctx.ModuleVersions.Of(pkg, apiextensionsModule), pkg.Module.Path, construct.minVersion, apiextensionsModule, construct.minVersion), node))
+	}
+}
+
+// usesRule returns a function checking if a CRD has a validation rule
+// matching the given function.
+func usesRule(matches func(rule apiextensionsv1.ValidationRule) bool) func(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	return func(crd *apiextensionsv1.CustomResourceDefinition) bool {
+		visitor := &ruleVisitor{matches: matches}
+		for _, ver := range crd.Spec.Versions {
+			if ver.Schema != nil && ver.Schema.OpenAPIV3Schema != nil {
+				EditSchema(ver.Schema.OpenAPIV3Schema, visitor)
+			}
+		}
+		return visitor.found
+	}
+}
+
+// ruleVisitor looks for a validation rule matching a function.
+type ruleVisitor struct {
+	matches func(rule apiextensionsv1.ValidationRule) bool
+	found   bool
+}
+
+func (v *ruleVisitor) Visit(schema *apiextensionsv1.JSONSchemaProps) SchemaVisitor {
+	if schema == nil || v.found {
+		return nil
+	}
+	for _, rule := range schema.XValidations {
+		if v.matches(rule) {
+			v.found = true
+			return nil
+		}
+	}
+	return v
+}
@@ -0,0 +1,173 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// SchemaSource returns the Go field or type declaration the schema at the
+// given path in the schema of the given version of the given kind comes
+// from, along with its package, e.g. to report errors about the schema (as
+// reported by an API server) at the Go source.
+//
+// The path is a field path below openAPIV3Schema, e.g.
+// "properties[spec].properties[items].items.x-kubernetes-validations[0].rule".
+// It's followed along properties, items and additionalProperties as far as
+// the schema comes from types known to the parser, the other keywords
+// belonging to the schema reached so far.  The declaration of the kind is
+// returned for an empty path, while nil is returned for unknown kinds.
+func (p *Parser) SchemaSource(groupKind schema.GroupKind, version, path string) (*loader.Package, ast.Node) {
+	var kindPkg *loader.Package
+	for pkg, gv := range p.GroupVersions {
+		if gv.Group == groupKind.Group && gv.Version == version && p.LookupType(pkg, groupKind.Kind) != nil {
+			kindPkg = pkg
+			break
+		}
+	}
+	if kindPkg == nil {
+		return nil, nil
+	}
+	info := p.LookupType(kindPkg, groupKind.Kind)
+	kindPkg.NeedTypesInfo()
+
+	pkg := kindPkg
+	node := ast.Node(info.RawSpec)
+	typ := kindPkg.TypesInfo.TypeOf(info.RawSpec.Name)
+	for _, elem := range splitFieldPath(path) {
+		if typ == nil {
+			break
+		}
+		for ptr, isPtr := typ.(*types.Pointer); isPtr; ptr, isPtr = typ.(*types.Pointer) {
+			typ = ptr.Elem()
+		}
+
+		switch elem.name {
+		case "properties":
+			fieldPkg, field := p.jsonField(pkg, typ, elem.key, 0)
+			if field == nil {
+				return pkg, node
+			}
+			pkg, node = fieldPkg, field.RawField
+			typ = fieldPkg.TypesInfo.TypeOf(field.RawField.Type)
+		case "items":
+			switch underlying := typ.Underlying().(type) {
+			case *types.Slice:
+				typ = underlying.Elem()
+			case *types.Array:
+				typ = underlying.Elem()
+			default:
+				return pkg, node
+			}
+		case "additionalProperties":
+			mapType, isMap := typ.Underlying().(*types.Map)
+			if !isMap {
+				return pkg, node
+			}
+			typ = mapType.Elem()
+		default:
+			return pkg, node
+		}
+	}
+	return pkg, node
+}
+
+// jsonField returns the field of the given struct type (seen from the given
+// package) serialized with the given JSON name, looking into inline fields,
+// along with the package declaring it.  Types not known to the parser have
+// no fields.
+func (p *Parser) jsonField(pkg *loader.Package, typ types.Type, name string, depth int) (*loader.Package, *markers.FieldInfo) {
+	named, isNamed := typ.(*types.Named)
+	if !isNamed || named.Obj().Pkg() == nil || depth > 32 {
+		return nil, nil
+	}
+	typePkg := pkg
+	if pkgPath := loader.NonVendorPath(named.Obj().Pkg().Path()); pkgPath != pkg.PkgPath {
+		typePkg = pkg.Imports()[pkgPath]
+		if typePkg == nil {
+			return nil, nil
+		}
+	}
+	info := p.LookupType(typePkg, named.Obj().Name())
+	if info == nil {
+		return nil, nil
+	}
+	typePkg.NeedTypesInfo()
+
+	for i := range info.Fields {
+		field := &info.Fields[i]
+		jsonTag, hasTag := field.Tag.Lookup("json")
+		if !hasTag && field.Name != "" {
+			continue
+		}
+		jsonOpts := strings.Split(jsonTag, ",")
+		if jsonOpts[0] == "-" && len(jsonOpts) == 1 {
+			continue
+		}
+		if jsonOpts[0] == name && name != "" {
+			return typePkg, field
+		}
+		if jsonOpts[0] == "" || strings.Contains(jsonTag, ",inline") {
+			fieldType := typePkg.TypesInfo.TypeOf(field.RawField.Type)
+			if ptr, isPtr := fieldType.(*types.Pointer); isPtr {
+				fieldType = ptr.Elem()
+			}
+			if fieldPkg, inlineField := p.jsonField(typePkg, fieldType, name, depth+1); inlineField != nil {
+				return fieldPkg, inlineField
+			}
+		}
+	}
+	return nil, nil
+}
+
+// fieldPathElem is an element of a field path, e.g. properties[spec].
+type fieldPathElem struct {
+	name string
+	key  string
+}
+
+// splitFieldPath splits the given field path (as formatted by
+// k8s.io/apimachinery/pkg/util/validation/field.Path) into its elements.
+func splitFieldPath(path string) []fieldPathElem {
+	var elems []fieldPathElem
+	for path != "" {
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			end = len(path)
+		}
+		elem := fieldPathElem{name: path[:end]}
+		path = path[end:]
+		if strings.HasPrefix(path, "[") {
+			closing := strings.IndexByte(path, ']')
+			if closing < 0 {
+				closing = len(path) - 1
+			}
+			elem.key = path[1:closing]
+			path = path[closing+1:]
+		}
+		path = strings.TrimPrefix(path, ".")
+		elems = append(elems, elem)
+	}
+	return elems
+}
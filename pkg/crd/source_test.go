@@ -0,0 +1,202 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+var _ = Describe("CRD Schema Sources", func() {
+	var parser *crd.Parser
+	groupKind := schema.GroupKind{Group: "testdata.kubebuilder.io", Kind: "CronJob"}
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed())
+		DeferCleanup(func() { Expect(os.Chdir(cwd)).To(Succeed()) })
+
+		By("parsing the CronJob CRD")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		reg := &markers.Registry{}
+		Expect(crdmarkers.Register(reg)).To(Succeed())
+		parser = &crd.Parser{
+			Collector:              &markers.Collector{Registry: reg},
+			Checker:                &loader.TypeChecker{},
+			IgnoreUnexportedFields: true,
+			AllowDangerousTypes:    true,
+		}
+		crd.AddKnownTypes(parser)
+		parser.NeedPackage(pkgs[0])
+		parser.NeedCRDFor(groupKind, nil)
+	})
+
+	sourceName := func(path string) string {
+		_, node := parser.SchemaSource(groupKind, "v1", path)
+		switch node := node.(type) {
+		case *ast.TypeSpec:
+			return "type " + node.Name.Name
+		case *ast.Field:
+			if len(node.Names) == 0 {
+				return fmt.Sprintf("embedded %s", node.Type)
+			}
+			return "field " + node.Names[0].Name
+		default:
+			return fmt.Sprintf("%T", node)
+		}
+	}
+
+	It("should find the declaration of the kind for the root", func() {
+		Expect(sourceName("")).To(Equal("type CronJob"))
+	})
+
+	It("should follow properties, items and additionalProperties to the fields", func() {
+		Expect(sourceName("properties[spec]")).To(Equal("field Spec"))
+		Expect(sourceName("properties[spec].properties[schedule]")).To(Equal("field Schedule"))
+		Expect(sourceName("properties[spec].properties[defaultedObject].items.properties[nested]")).To(Equal("field Nested"))
+		Expect(sourceName("properties[spec].properties[nestedMapInStruct].additionalProperties.properties[innerMap]")).To(Equal("field InnerMap"))
+	})
+
+	It("should find the fields of inline structs", func() {
+		Expect(sourceName("properties[spec].properties[baz]")).To(Equal("field Baz"))
+	})
+
+	It("should stop at the schema the other keywords belong to", func() {
+		Expect(sourceName("properties[spec].properties[schedule].x-kubernetes-validations[0].rule")).To(Equal("field Schedule"))
+		Expect(sourceName("properties[spec].properties[unknown]")).To(Equal("field Spec"))
+		Expect(sourceName("properties[spec].properties[schedule].items")).To(Equal("field Schedule"))
+	})
+
+	It("should find nothing for unknown kinds", func() {
+		_, node := parser.SchemaSource(schema.GroupKind{Group: "testdata.kubebuilder.io", Kind: "Unknown"}, "v1", "")
+		Expect(node).To(BeNil())
+	})
+})
+
+var _ = Describe("CRD Validation On API Servers", func() {
+	var (
+		genCtx     *genall.GenerationContext
+		kubeconfig string
+		applied    []string
+	)
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(filepath.Join("testdata", "gen"))).To(Succeed())
+		DeferCleanup(func() { Expect(os.Chdir(cwd)).To(Succeed()) })
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		reg := &markers.Registry{}
+		Expect(crdmarkers.Register(reg)).To(Succeed())
+		genCtx = &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			Checker:    &loader.TypeChecker{},
+			OutputRule: genall.OutputToNothing,
+		}
+
+		By("starting an API server rejecting the schema of a field")
+		applied = nil
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Method).To(Equal(http.MethodPatch))
+			Expect(r.URL.Path).To(Equal("/apis/apiextensions.k8s.io/v1/customresourcedefinitions/foos.bar.example.com"))
+			Expect(r.URL.Query().Get("dryRun")).To(Equal("All"))
+			applied = append(applied, string(body))
+
+			status := metav1.Status{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+				Status:   metav1.StatusFailure,
+				Message:  `CustomResourceDefinition.apiextensions.k8s.io "foos.bar.example.com" is invalid`,
+				Reason:   metav1.StatusReasonInvalid,
+				Code:     http.StatusUnprocessableEntity,
+				Details: &metav1.StatusDetails{
+					Name: "foos.bar.example.com",
+					Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldValueInvalid,
+						Field:   "spec.versions[0].schema.openAPIV3Schema.properties[spec].properties[defaultedString].x-kubernetes-validations[0].rule",
+						Message: `Invalid value: "self.size() >": compilation failed`,
+					}, {
+						Type:    metav1.CauseTypeFieldValueInvalid,
+						Field:   "metadata.annotations",
+						Message: "Invalid value: too long",
+					}},
+				},
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Expect(json.NewEncoder(w).Encode(status)).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+
+		kubeconfig = filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+		Expect(os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`, server.URL)), 0o600)).To(Succeed())
+	})
+
+	It("should dry-run apply the CRDs, reporting the errors of the server at their Go source", func() {
+		Expect(crd.Generator{ValidateOn: kubeconfig}.Generate(genCtx)).To(Succeed())
+		Expect(applied).To(HaveLen(1))
+		Expect(applied[0]).To(ContainSubstring(`"kind":"CustomResourceDefinition"`))
+		Expect(applied[0]).NotTo(ContainSubstring(`"acceptedNames"`))
+
+		Expect(genCtx.Roots[0].Errors).To(HaveLen(2))
+		Expect(genCtx.Roots[0].Errors[0].Pos).To(ContainSubstring("foo_types.go:31"))
+		Expect(genCtx.Roots[0].Errors[0].Msg).To(ContainSubstring("compilation failed"))
+		Expect(genCtx.Roots[0].Errors[1].Pos).To(ContainSubstring("foo_types.go:35"))
+		Expect(genCtx.Roots[0].Errors[1].Msg).To(ContainSubstring("metadata.annotations: Invalid value: too long"))
+	})
+})
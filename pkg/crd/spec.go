@@ -18,6 +18,7 @@ package crd
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
@@ -61,6 +62,10 @@ func (p *Parser) NeedCRDFor(groupKind schema.GroupKind, maxDescLen *int) {
 		}
 		packages = append(packages, pkg)
 	}
+	// apply the markers of the versions in a stable order
+	slices.SortFunc(packages, func(a, b *loader.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
 
 	defaultPlural := strings.ToLower(flect.Pluralize(groupKind.Kind))
 	crd := apiextensionsv1.CustomResourceDefinition{
@@ -114,8 +119,8 @@ func (p *Parser) NeedCRDFor(groupKind schema.GroupKind, maxDescLen *int) {
 		}
 		ver := p.GroupVersions[pkg].Version
 
-		for _, markerVals := range typeInfo.Markers {
-			for _, val := range markerVals {
+		for _, markerName := range slices.Sorted(maps.Keys(typeInfo.Markers)) {
+			for _, val := range typeInfo.Markers[markerName] {
 				if specMarker, isSpecMarker := val.(SpecMarker); isSpecMarker {
 					if err := specMarker.ApplyToCRD(&crd.Spec, ver); err != nil {
 						pkg.AddError(loader.ErrFromNode(err /* an okay guess */, typeInfo.RawSpec))
@@ -0,0 +1,76 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate ../../../../.run-controller-gen.sh crd:ignoreUnexportedFields=true,allowDangerousTypes=true paths=./... output:dir=..
+
+// +groupName=testdata.kubebuilder.io
+// +versionName=v1
+// +kubebuilder:validation:Optional
+// +mapType=atomic
+package defaults
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultsSpec follows the package defaults.
+type DefaultsSpec struct {
+	// Optional by default.
+	Name string `json:"name"`
+
+	// Atomic by default.
+	Labels map[string]string `json:"labels"`
+
+	// Granular, overriding the package default.
+	// +mapType=granular
+	Annotations map[string]string `json:"annotations"`
+
+	// Atomic, like its type.
+	Selector Selector `json:"selector"`
+
+	// Granular, like its type.
+	Data Data `json:"data"`
+
+	Required RequiredByDefault `json:"required"`
+}
+
+// Selector follows the package default.
+type Selector map[string]string
+
+// Data overrides the package default.
+// +mapType=granular
+type Data map[string]string
+
+// RequiredByDefault overrides the package default.
+// +kubebuilder:validation:Required
+type RequiredByDefault struct {
+	// Required by default.
+	Name string `json:"name"`
+
+	// Optional, overriding the type default.
+	// +optional
+	Description string `json:"description"`
+}
+
+// +kubebuilder:object:root=true
+
+// Defaults is the Schema for the Defaults API.
+type Defaults struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DefaultsSpec `json:"spec"`
+}
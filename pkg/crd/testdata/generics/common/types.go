@@ -0,0 +1,28 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package common holds generic types shared by API packages.
+package common
+
+// Override is a value overriding a default one.
+type Override[T any] struct {
+	// Value is the overriding value.
+	Value T `json:"value"`
+
+	// Reason explains why the default is overridden.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
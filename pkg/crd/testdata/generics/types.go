@@ -0,0 +1,94 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate ../../../../.run-controller-gen.sh crd:ignoreUnexportedFields=true,allowDangerousTypes=true paths=. output:dir=..
+
+// +groupName=testdata.kubebuilder.io
+// +versionName=v1
+package generics
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"testdata.kubebuilder.io/cronjob/generics/common"
+)
+
+// Range is a range of values.
+type Range[T any] struct {
+	// Min is the lower bound.
+	Min T `json:"min"`
+
+	// Max is the upper bound.
+	// +optional
+	Max *T `json:"max,omitempty"`
+}
+
+// Entry is an entry of a keyed collection.
+type Entry[K ~string, V any] struct {
+	// Key identifies the entry.
+	Key K `json:"key"`
+
+	// Values are the values of the entry, by name.
+	// +optional
+	Values map[string]V `json:"values,omitempty"`
+}
+
+// Port is a network port.
+// +kubebuilder:validation:Minimum=1
+// +kubebuilder:validation:Maximum=65535
+type Port int32
+
+// Level is a named level.
+// +kubebuilder:validation:Enum=low;high
+type Level string
+
+// Ports is a range of ports, instantiating a generic type in a declaration.
+type Ports Range[Port]
+
+// GenericSpec uses instantiations of generic types.
+type GenericSpec struct {
+	// Replicas is a range of replica counts, documented on the field.
+	Replicas Range[int32] `json:"replicas"`
+
+	Ports Ports `json:"ports"`
+
+	// Window is an optional range of times.
+	// +optional
+	Window *Range[metav1.Time] `json:"window,omitempty"`
+
+	// Levels are entries of levels.
+	// +kubebuilder:validation:MaxItems=4
+	// +optional
+	Levels []Entry[Level, Range[Port]] `json:"levels,omitempty"`
+
+	// Data is raw data, overriding the default one.
+	// +optional
+	Data *common.Override[[]byte] `json:"data,omitempty"`
+
+	// Nested nests instantiations.
+	// +optional
+	Nested *common.Override[Entry[string, string]] `json:"nested,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Generic is a kind using generic types.
+type Generic struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GenericSpec `json:"spec,omitempty"`
+}
@@ -0,0 +1,8 @@
+module testdata.kubebuilder.io/vendored
+
+go 1.26.0
+
+require (
+	example.com/shared v1.0.0
+	k8s.io/apimachinery v0.35.0
+)
@@ -0,0 +1,47 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+//go:generate env GOFLAGS=-mod=vendor ../../../../.run-controller-gen.sh crd paths=./... output:dir=..
+
+// +groupName=testdata.kubebuilder.io
+// +versionName=v1
+package vendored
+
+import (
+	"example.com/shared"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GadgetSpec refers to types of vendored packages.
+type GadgetSpec struct {
+	// Primary is the main endpoint.
+	Primary shared.Endpoint `json:"primary"`
+
+	// Fallbacks are the endpoints used when the primary one is down.
+	// +optional
+	Fallbacks []shared.Endpoint `json:"fallbacks,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Gadget is the Schema for a type using vendored packages.
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GadgetSpec `json:"spec"`
+}
@@ -52,6 +52,10 @@ func (Generator) Help() *markers.DefinitionHelp {
 				Summary: "specifies if any embedded ObjectMeta in the CRD should be generated",
 				Details: "",
 			},
+			"EmbeddedMarkers": {
+				Summary: "selects how markers of embedded types combine with the embedding struct's.",
+				Details: "Markers of embedded types include those of the fields embedding them.\nValidation markers always combine, the embedding struct having to be\nvalid for each of the types it embeds.  Topology markers (mapType,\nstructType, listType, listMapKey) and PreserveUnknownFields can't, so:\n\n- \"legacy\" (the default) lets those of the embedded types win, and keeps\nconflicting listType and listMapKey markers in an allOf.\n\n- \"strict\" lets those closest to the schema win: those of the embedding\nstruct win over those of the embedded types, those of the embedding fields\nover those of their types, and later embedded fields over earlier ones.",
+			},
 			"HeaderFile": {
 				Summary: "specifies the header text (e.g. license) to prepend to generated files.",
 				Details: "",
@@ -64,6 +68,14 @@ func (Generator) Help() *markers.DefinitionHelp {
 				Summary: "indicates whether",
 				Details: "or not we should turn off field pruning for this resource.\n\nSpecifies spec.preserveUnknownFields value that is false and omitted by default.\nThis value can only be specified for CustomResourceDefinitions that were created with\n`apiextensions.k8s.io/v1beta1`.\n\nThe field can be set for compatibility reasons, although strongly discouraged, resource\nauthors should move to a structural OpenAPI schema instead.\n\nSee https://kubernetes.io/docs/tasks/extend-kubernetes/custom-resources/custom-resource-definitions/#field-pruning\nfor more information about field pruning and v1beta1 resources compatibility.",
 			},
+			"Descriptions": {
+				Summary: "specifies a catalog file replacing the descriptions of",
+				Details: "types and fields, e.g. with translations, or with wording reviewed\nseparately from the Go source:\n\n\tdescriptions:\n\t- package: example.com/api/v1\n\t  type: CronJob\n\t  description: A CronJob runs Jobs on a schedule.\n\t  fields:\n\t    Spec: Spec is the schedule of the Jobs, and their template.\n\nFields are listed by their Go names.",
+			},
+			"ValidateOn": {
+				Summary: "dry-run applies the generated CRDs to an API server, reporting",
+				Details: "the errors of the server (e.g. non-structural schemata, or CEL rules that\ndon't compile or are too costly) at the Go fields and types they come\nfrom.\n\n\"envtest\" starts an API server from the envtest binaries of the\ndirectory of $KUBEBUILDER_ASSETS (e.g. as installed by setup-envtest),\n\"cluster\" uses the cluster of the current context of $KUBECONFIG (or\n~/.kube/config), and any other value is the path of the kubeconfig file\nof the cluster to use.  Nothing is persisted.",
+			},
 		},
 	}
 }
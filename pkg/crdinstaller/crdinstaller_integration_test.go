@@ -0,0 +1,40 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdinstaller_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/crdinstaller"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+)
+
+var _ = Describe("CRDInstaller Generation", func() {
+	It("should generate the CRDs along with a package embedding them", func() {
+		headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{crdinstaller.Generator{HeaderFile: headerFile}}, "./api/...")
+		golden.Compare(GinkgoT(), filepath.Join("testdata", "_crds"), out)
+	})
+
+	It("should fail with package names that aren't identifiers", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{crdinstaller.Generator{Package: "my-crds"}}, "./api/...")
+		Expect(errOut).To(ContainSubstring(`package name "my-crds" must be a Go identifier`))
+	})
+})
@@ -0,0 +1,27 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crdinstaller generates a Go package embedding the generated CRDs,
+// with helpers to install them, e.g.:
+//
+//	controller-gen crdinstaller paths=./api/... output:crdinstaller:dir=internal/crds
+//
+// writes the CRD manifests to internal/crds, along with a crds.go file
+// embedding them, so that envtest suites and operators installing their own
+// CRDs refer to a Go package rather than to relative paths:
+//
+//	testEnv := &envtest.Environment{CRDInstallOptions: envtest.CRDInstallOptions{CRDs: crds.CRDs()}}
+package crdinstaller
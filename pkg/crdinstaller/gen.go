@@ -0,0 +1,258 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdinstaller
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// outputFileName is the name of the generated Go file.
+const outputFileName = "crds.go"
+
+// +controllertools:marker:generateHelp
+
+// Generator generates a Go package embedding the generated CRDs.
+//
+// The CRDs are generated as with the crd generator, along with crds.go,
+// embedding them via go:embed.  The package exposes CRDs, returning the
+// decoded CRDs, and InstallCRDs, creating or updating them and waiting for
+// them to be established.  The package needs the
+// k8s.io/apiextensions-apiserver and k8s.io/client-go modules.
+type Generator struct {
+	// Package is the name of the generated package (defaults to crds).
+	Package string `marker:",optional"`
+	// AllowDangerousTypes allows types which are usually omitted from CRD generation
+	// because they are not recommended, as with the crd generator.
+	AllowDangerousTypes *bool `marker:",optional"`
+	// MaxDescLen specifies the maximum description length for fields in CRD's OpenAPI schema,
+	// as with the crd generator.
+	MaxDescLen *int `marker:",optional"`
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crd.Generator{}.RegisterMarkers(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	pkgName := g.Package
+	if pkgName == "" {
+		pkgName = "crds"
+	}
+	if !token.IsIdentifier(pkgName) {
+		return fmt.Errorf("package name %q must be a Go identifier", pkgName)
+	}
+
+	recorded := &recordFiles{OutputRule: ctx.OutputRule}
+	crdCtx := *ctx
+	crdCtx.OutputRule = recorded
+	crdGen := crd.Generator{
+		AllowDangerousTypes: g.AllowDangerousTypes,
+		MaxDescLen:          g.MaxDescLen,
+		HeaderFile:          g.HeaderFile,
+		Year:                g.Year,
+	}
+	if err := crdGen.Generate(&crdCtx); err != nil {
+		return err
+	}
+	if len(recorded.files) == 0 {
+		return nil
+	}
+	slices.Sort(recorded.files)
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	outContent := new(bytes.Buffer)
+	if err := installerTemplate.Execute(outContent, installerArgs{
+		Header:  headerText,
+		Package: pkgName,
+		Files:   recorded.files,
+	}); err != nil {
+		return err
+	}
+	outBytes, err := format.Source(outContent.Bytes())
+	if err != nil {
+		return err
+	}
+
+	outputFile, err := ctx.Open(nil, outputFileName)
+	if err != nil {
+		return err
+	}
+	defer outputFile.Close()
+	n, err := outputFile.Write(outBytes)
+	if err != nil {
+		return err
+	}
+	if n < len(outBytes) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// recordFiles records the (non-package associated) files written through an
+// output rule.
+type recordFiles struct {
+	genall.OutputRule
+	files []string
+}
+
+func (r *recordFiles) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if pkg == nil {
+		if strings.Contains(filepath.ToSlash(itemPath), "/") {
+			return nil, fmt.Errorf("CRD %s must be generated next to %s to be embedded", itemPath, outputFileName)
+		}
+		r.files = append(r.files, itemPath)
+	}
+	return r.OutputRule.Open(pkg, itemPath)
+}
+
+// installerArgs are the arguments of the installer template.
+type installerArgs struct {
+	Header  string
+	Package string
+	// Files are the names of the CRD files.
+	Files []string
+}
+
+var installerTemplate = template.Must(template.New("installer").Parse(`//go:build !ignore_autogenerated
+
+{{ .Header }}
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+// Package {{ .Package }} embeds the CRDs of the project.
+package {{ .Package }}
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// files are the manifests of the CRDs.
+//
+{{- range .Files }}
+//go:embed {{ . }}
+{{- end }}
+var files embed.FS
+
+// fileNames are the names of the manifests of the CRDs, in order.
+var fileNames = []string{
+{{- range .Files }}
+	{{ printf "%q" . }},
+{{- end }}
+}
+
+// CRDs returns the CRDs of the project.  It panics if the embedded manifests
+// can't be decoded, which can only happen if they were edited by hand.
+func CRDs() []*apiextensionsv1.CustomResourceDefinition {
+	crds := make([]*apiextensionsv1.CustomResourceDefinition, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		content, err := files.ReadFile(fileName)
+		if err != nil {
+			panic(fmt.Sprintf("failed to read embedded CRD %s: %v", fileName, err))
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.UnmarshalStrict(content, crd); err != nil {
+			panic(fmt.Sprintf("failed to decode embedded CRD %s: %v", fileName, err))
+		}
+		crds = append(crds, crd)
+	}
+	return crds
+}
+
+// InstallCRDs creates the CRDs of the project, or updates the existing ones,
+// and waits until they're established, or the given context is done.
+func InstallCRDs(ctx context.Context, cfg *rest.Config) error {
+	client, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	crdClient := client.ApiextensionsV1().CustomResourceDefinitions()
+
+	crds := CRDs()
+	for _, crd := range crds {
+		existing, err := crdClient.Get(ctx, crd.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			_, err = crdClient.Create(ctx, crd, metav1.CreateOptions{})
+		case err == nil:
+			crd.ResourceVersion = existing.ResourceVersion
+			_, err = crdClient.Update(ctx, crd, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to install CRD %s: %w", crd.Name, err)
+		}
+	}
+
+	for _, crd := range crds {
+		if err := wait.PollUntilContextCancel(ctx, 100*time.Millisecond, true, func(ctx context.Context) (bool, error) {
+			installed, err := crdClient.Get(ctx, crd.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			for _, cond := range installed.Status.Conditions {
+				if cond.Type == apiextensionsv1.Established && cond.Status == apiextensionsv1.ConditionTrue {
+					return true, nil
+				}
+			}
+			return false, nil
+		}); err != nil {
+			return fmt.Errorf("CRD %s wasn't established: %w", crd.Name, err)
+		}
+	}
+	return nil
+}
+`))
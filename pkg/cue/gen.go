@@ -0,0 +1,205 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cue
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates CUE definitions for the kinds of each group-version.
+//
+// Each group-version is written to <group>/<version>/types.cue, as a CUE
+// package named after the version, with a #<Kind> definition per kind.  The
+// definitions mirror the CRD schemata of the kinds, including their defaults,
+// apart from CEL rules, which CUE can't express.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+
+	// AllowDangerousTypes allows types which are usually omitted from CRD generation
+	// because they are not recommended.
+	//
+	// Currently the following additional types are allowed when this is true:
+	// float32
+	// float64
+	//
+	// Left unspecified, the default is false
+	AllowDangerousTypes *bool `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector:           ctx.Collector,
+		Checker:             ctx.Checker,
+		Kinds:               ctx.Kinds,
+		Cache:               ctx.Cache,
+		AllowDangerousTypes: g.AllowDangerousTypes != nil && *g.AllowDangerousTypes,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	kubeKinds := crd.FindKubeKinds(parser, metav1Pkg)
+	slices.SortFunc(kubeKinds, func(a, b schema.GroupKind) int {
+		return strings.Compare(a.Kind, b.Kind)
+	})
+
+	files := make(map[schema.GroupVersion]*cueFile)
+	var groupVersions []schema.GroupVersion
+	for _, groupKind := range kubeKinds {
+		parser.NeedCRDFor(groupKind, nil)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, version := range crdRaw.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			gv := schema.GroupVersion{Group: groupKind.Group, Version: version.Name}
+			file, exists := files[gv]
+			if !exists {
+				file = &cueFile{imports: make(map[string]struct{})}
+				files[gv] = file
+				groupVersions = append(groupVersions, gv)
+			}
+			file.writeKind(gv, groupKind.Kind, version.Schema.OpenAPIV3Schema)
+		}
+	}
+
+	for _, gv := range groupVersions {
+		fileName := fmt.Sprintf("%s/%s/types.cue", gv.Group, gv.Version)
+		if err := writeOut(ctx, fileName, files[gv].contents(headerText, gv.Version)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOut writes the given contents to the given file.
+func writeOut(ctx *genall.GenerationContext, fileName string, outBytes []byte) error {
+	out, err := ctx.Open(nil, fileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	n, err := out.Write(outBytes)
+	if err != nil {
+		return err
+	}
+	if n < len(outBytes) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// cueFile collects the CUE definitions of a group-version.
+type cueFile struct {
+	// imports are the CUE standard library packages used by the definitions.
+	imports map[string]struct{}
+	defs    bytes.Buffer
+}
+
+// contents returns the contents of the file, as a CUE package with the given
+// name.
+func (f *cueFile) contents(headerText, pkgName string) []byte {
+	out := new(bytes.Buffer)
+	for _, line := range strings.Split(strings.TrimSpace(headerText), "\n") {
+		if strings.HasPrefix(line, "/*") || strings.HasPrefix(line, "*/") || line == "" && out.Len() == 0 {
+			// CUE has no block comments
+			continue
+		}
+		if line == "" {
+			out.WriteString("//\n")
+			continue
+		}
+		fmt.Fprintf(out, "// %s\n", line)
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("// Code generated by controller-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(out, "package %s\n\n", pkgName)
+
+	if len(f.imports) > 0 {
+		imports := make([]string, 0, len(f.imports))
+		for importPath := range f.imports {
+			imports = append(imports, fmt.Sprintf("\t%q", importPath))
+		}
+		slices.Sort(imports)
+		fmt.Fprintf(out, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+
+	out.Write(f.defs.Bytes())
+	return out.Bytes()
+}
+
+// writeKind writes the definition of the given kind, with the given schema.
+func (f *cueFile) writeKind(gv schema.GroupVersion, kind string, kindSchema *apiextensionsv1.JSONSchemaProps) {
+	if f.defs.Len() > 0 {
+		f.defs.WriteString("\n")
+	}
+	writeComment(&f.defs, kindSchema.Description, "")
+	fmt.Fprintf(&f.defs, "#%s: {\n", kind)
+	fmt.Fprintf(&f.defs, "\tapiVersion: %s\n", quote(gv.String()))
+	fmt.Fprintf(&f.defs, "\tkind:       %s\n", quote(kind))
+	f.writeFields(&f.defs, kindSchema, "\t", "apiVersion", "kind")
+	f.defs.WriteString("}\n")
+}
@@ -0,0 +1,249 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// identifier matches the field names which don't need quoting.
+var identifier = regexp.MustCompile(`^[a-zA-Z$][a-zA-Z0-9_$]*$`)
+
+// keywords are the CUE keywords, which need quoting as field names.
+var keywords = map[string]struct{}{
+	"package": {}, "import": {}, "for": {}, "in": {}, "if": {}, "let": {},
+	"true": {}, "false": {}, "null": {},
+}
+
+// label returns the CUE label of the given field name.
+func label(name string) string {
+	if _, isKeyword := keywords[name]; isKeyword || !identifier.MatchString(name) {
+		return quote(name)
+	}
+	return name
+}
+
+// quote returns the CUE string literal of the given string.
+func quote(str string) string {
+	return literal([]byte(strconv.Quote(str)))
+}
+
+// literal returns the CUE literal of the given JSON value, since JSON is a
+// subset of CUE.
+func literal(raw []byte) string {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	out := new(bytes.Buffer)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return string(raw)
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// writeComment writes the given description as a comment, at the given
+// indentation.
+func writeComment(out *bytes.Buffer, description, indent string) {
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		if line == "" {
+			fmt.Fprintf(out, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(out, "%s// %s\n", indent, line)
+	}
+}
+
+// writeFields writes the fields of the given object schema, at the given
+// indentation, apart from the given ones.
+func (f *cueFile) writeFields(out *bytes.Buffer, objSchema *apiextensionsv1.JSONSchemaProps, indent string, skip ...string) {
+	names := make([]string, 0, len(objSchema.Properties))
+	for name := range objSchema.Properties {
+		if !slices.Contains(skip, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop := objSchema.Properties[name]
+		writeComment(out, prop.Description, indent)
+		marker := "?"
+		if slices.Contains(objSchema.Required, name) {
+			marker = "!"
+		}
+		fmt.Fprintf(out, "%s%s%s: %s\n", indent, label(name), marker, f.expr(&prop, indent))
+	}
+}
+
+// expr returns the CUE expression constraining values of the given schema,
+// written at the given indentation.
+func (f *cueFile) expr(valueSchema *apiextensionsv1.JSONSchemaProps, indent string) string {
+	var constraints []string
+	switch {
+	case len(valueSchema.Enum) > 0:
+		values := make([]string, 0, len(valueSchema.Enum))
+		for _, value := range valueSchema.Enum {
+			values = append(values, literal(value.Raw))
+		}
+		constraints = append(constraints, strings.Join(values, " | "))
+	case valueSchema.XIntOrString:
+		constraints = append(constraints, "int | string")
+	case valueSchema.Type == "object":
+		constraints = append(constraints, f.objectConstraints(valueSchema, indent)...)
+	case valueSchema.Type == "array":
+		constraints = append(constraints, f.arrayConstraints(valueSchema, indent)...)
+	case valueSchema.Type == "string":
+		constraints = append(constraints, f.stringConstraints(valueSchema)...)
+	case valueSchema.Type == "integer" || valueSchema.Type == "number":
+		constraints = append(constraints, f.numberConstraints(valueSchema)...)
+	case valueSchema.Type == "boolean":
+		constraints = append(constraints, "bool")
+	default:
+		constraints = append(constraints, "_")
+	}
+
+	expr := strings.Join(constraints, " & ")
+	if valueSchema.Nullable {
+		expr = "null | " + expr
+	}
+	if valueSchema.Default != nil {
+		expr = "*" + literal(valueSchema.Default.Raw) + " | " + expr
+	}
+	return expr
+}
+
+// objectConstraints returns the constraints of the given object schema.
+func (f *cueFile) objectConstraints(objSchema *apiextensionsv1.JSONSchemaProps, indent string) []string {
+	var constraints []string
+	if objSchema.MinProperties != nil {
+		f.imports["struct"] = struct{}{}
+		constraints = append(constraints, fmt.Sprintf("struct.MinFields(%d)", *objSchema.MinProperties))
+	}
+	if objSchema.MaxProperties != nil {
+		f.imports["struct"] = struct{}{}
+		constraints = append(constraints, fmt.Sprintf("struct.MaxFields(%d)", *objSchema.MaxProperties))
+	}
+
+	body := new(bytes.Buffer)
+	f.writeFields(body, objSchema, indent+"\t")
+	switch {
+	case objSchema.AdditionalProperties != nil && objSchema.AdditionalProperties.Schema != nil:
+		fmt.Fprintf(body, "%s\t[string]: %s\n", indent, f.expr(objSchema.AdditionalProperties.Schema, indent+"\t"))
+	case len(objSchema.Properties) == 0,
+		objSchema.XPreserveUnknownFields != nil && *objSchema.XPreserveUnknownFields,
+		objSchema.XEmbeddedResource:
+		// definitions are closed, so unknown fields have to be allowed
+		fmt.Fprintf(body, "%s\t...\n", indent)
+	}
+	if body.String() == indent+"\t...\n" {
+		return append(constraints, "{...}")
+	}
+	return append(constraints, "{\n"+body.String()+indent+"}")
+}
+
+// arrayConstraints returns the constraints of the given array schema.
+func (f *cueFile) arrayConstraints(arraySchema *apiextensionsv1.JSONSchemaProps, indent string) []string {
+	var constraints []string
+	if arraySchema.MinItems != nil {
+		f.imports["list"] = struct{}{}
+		constraints = append(constraints, fmt.Sprintf("list.MinItems(%d)", *arraySchema.MinItems))
+	}
+	if arraySchema.MaxItems != nil {
+		f.imports["list"] = struct{}{}
+		constraints = append(constraints, fmt.Sprintf("list.MaxItems(%d)", *arraySchema.MaxItems))
+	}
+	if arraySchema.XListType != nil && *arraySchema.XListType == "set" {
+		f.imports["list"] = struct{}{}
+		constraints = append(constraints, "list.UniqueItems()")
+	}
+	items := "_"
+	if arraySchema.Items != nil && arraySchema.Items.Schema != nil {
+		items = f.expr(arraySchema.Items.Schema, indent)
+	}
+	if strings.Contains(items, " | ") && !strings.HasPrefix(items, "{") {
+		items = "(" + items + ")"
+	}
+	return append(constraints, "[..."+items+"]")
+}
+
+// stringConstraints returns the constraints of the given string schema.
+func (f *cueFile) stringConstraints(strSchema *apiextensionsv1.JSONSchemaProps) []string {
+	constraints := []string{"string"}
+	if strSchema.MinLength != nil {
+		f.imports["strings"] = struct{}{}
+		constraints = append(constraints, fmt.Sprintf("strings.MinRunes(%d)", *strSchema.MinLength))
+	}
+	if strSchema.MaxLength != nil {
+		f.imports["strings"] = struct{}{}
+		constraints = append(constraints, fmt.Sprintf("strings.MaxRunes(%d)", *strSchema.MaxLength))
+	}
+	if strSchema.Pattern != "" {
+		constraints = append(constraints, "=~"+quote(strSchema.Pattern))
+	}
+	return constraints
+}
+
+// numberConstraints returns the constraints of the given number schema.
+func (f *cueFile) numberConstraints(numSchema *apiextensionsv1.JSONSchemaProps) []string {
+	var constraints []string
+	switch {
+	case numSchema.Type == "number":
+		constraints = append(constraints, "number")
+	case numSchema.Format == "int32" || numSchema.Format == "int64":
+		// predeclared in CUE
+		constraints = append(constraints, numSchema.Format)
+	default:
+		constraints = append(constraints, "int")
+	}
+	if numSchema.Minimum != nil {
+		op := ">="
+		if numSchema.ExclusiveMinimum {
+			op = ">"
+		}
+		constraints = append(constraints, op+formatNumber(*numSchema.Minimum))
+	}
+	if numSchema.Maximum != nil {
+		op := "<="
+		if numSchema.ExclusiveMaximum {
+			op = "<"
+		}
+		constraints = append(constraints, op+formatNumber(*numSchema.Maximum))
+	}
+	if numSchema.MultipleOf != nil {
+		f.imports["math"] = struct{}{}
+		constraints = append(constraints, fmt.Sprintf("math.MultipleOf(%s)", formatNumber(*numSchema.MultipleOf)))
+	}
+	return constraints
+}
+
+// formatNumber returns the shortest representation of the given number.
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'g', -1, 64)
+}
@@ -0,0 +1,53 @@
+// Copyright The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"list"
+	"math"
+	"strings"
+	"struct"
+)
+
+// Widget is a kind with a CUE definition.
+#Widget: {
+	apiVersion: "testdata.kubebuilder.io/v1"
+	kind:       "Widget"
+	metadata?: {...}
+	// WidgetSpec is the spec of a Widget.
+	spec?: {
+		config?: {...}
+		"count-limit"?: int64
+		default?: bool
+		// Image is the image of the widget.
+		//
+		// It must not be empty.
+		image!: string & strings.MinRunes(1) & strings.MaxRunes(253)
+		labels?: struct.MaxFields(8) & {
+			[string]: string
+		}
+		ports?: [...{
+			name!: string & =~"^[a-z]+$"
+			number!: int32 & >=1 & <=65535
+			protocol?: *"TCP" | "TCP" | "UDP"
+		}]
+		priority?: null | "Low" | "High"
+		replicas?: *2 | int32 & >0 & math.MultipleOf(2)
+		tags?: list.MinItems(1) & list.UniqueItems() & [...("a" | "b")]
+		target?: int | string
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-tools/pkg/crd"
 	"sigs.k8s.io/controller-tools/pkg/deepcopy"
 	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
 )
@@ -52,6 +53,19 @@ func (m outputToMap) fileList() []string {
 	return ret
 }
 
+// outputToPackageMap is like outputToMap, but keys files by package path
+// as well, for tests that generate into multiple packages.
+type outputToPackageMap map[string]*outputFile
+
+// Open implements genall.OutputRule.
+func (m outputToPackageMap) Open(pkg *loader.Package, path string) (io.WriteCloser, error) {
+	key := pkg.PkgPath + "/" + path
+	if _, ok := m[key]; !ok {
+		m[key] = &outputFile{}
+	}
+	return m[key], nil
+}
+
 type outputFile struct {
 	contents []byte
 }
@@ -106,4 +120,83 @@ var _ = Describe("CRD Generation From Parsing to CustomResourceDefinition", func
 		By("checking for errors")
 		Expect(hadErrs).To(BeFalse())
 	})
+
+	It("should generate scheme registration for root kinds", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		output := make(outputToMap)
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("object", markers.DescribesPackage, deepcopy.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			fmt.Sprintf("object:headerFile=%s,scheme=true", path.Join(cwd, "../../hack/boilerplate/boilerplate.generatego.txt")),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+
+		By("running the generator")
+		rt.Run()
+
+		By("checking that we got output contents")
+		Expect(output.fileList()).To(ContainElement("zz_generated.register.go"))
+		outContents := output["zz_generated.register.go"].contents
+
+		By("loading the desired code")
+		expectedFile, err := os.ReadFile("zz_generated.register.go")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("comparing the two")
+		Expect(string(outContents)).To(Equal(string(expectedFile)), "generated code not as expected, check pkg/deepcopy/testdata/README.md for more details.\n\nDiff:\n\n%s", cmp.Diff(outContents, expectedFile))
+	})
+
+	It("should generate deepcopy functions for external types", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		output := make(outputToPackageMap)
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("object", markers.DescribesPackage, deepcopy.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			fmt.Sprintf("object:headerFile=%s,externalTypes=external/external.yaml", path.Join(cwd, "../../hack/boilerplate/boilerplate.generatego.txt")),
+			"paths=./external/...",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+
+		By("running the generator and checking for errors")
+		Expect(rt.Run()).To(BeFalse())
+
+		for _, pkg := range []string{"thirdparty", "api"} {
+			By("checking the output for package " + pkg)
+			outFile := output["testdata.kubebuilder.io/cronjob/external/"+pkg+"/zz_generated.deepcopy.go"]
+			Expect(outFile).NotTo(BeNil())
+
+			expectedFile, err := os.ReadFile(path.Join("external", pkg, "zz_generated.deepcopy.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(outFile.contents)).To(Equal(string(expectedFile)), "generated code not as expected, check pkg/deepcopy/testdata/README.md for more details.\n\nDiff:\n\n%s", cmp.Diff(outFile.contents, expectedFile))
+		}
+	})
+
+	It("should generate the DeepCopyInto implementations into the generated subpackage", func() {
+		headerFile := path.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{deepcopy.Generator{HeaderFile: headerFile, Subpackage: true}}, "./subpackage/api")
+		golden.CompareFiles(GinkgoT(), "testdata", out,
+			"subpackage/api/zz_generated.deepcopy.go", "subpackage/api/generated/zz_generated.deepcopy.go")
+	})
+
+	It("should fail on unexported fields that can't be copied from the generated subpackage", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{deepcopy.Generator{Subpackage: true}}, "./subpackage/invalid")
+		Expect(errOut).To(ContainSubstring("cannot deepcopy unexported field parts from the generated subpackage"))
+	})
 })
@@ -20,4 +20,10 @@ limitations under the License.
 // It's ported from k8s.io/code-generator's / k8s.io/gengo's deepcopy-gen,
 // but it's scoped specifically to runtime.Object and skips support for
 // deepcopying interfaces, which aren't handled in CRDs anyway.
+//
+// Generic types get methods on their own type parameters (e.g.
+// `func (in *Wrapper[T]) DeepCopyInto(out *Wrapper[T])`).  Since the type
+// arguments are only known at runtime, values of type parameters are copied
+// with their DeepCopy or DeepCopyInto methods when they have them (as API types
+// do), and shallow-copied otherwise (e.g. for unnamed slices or maps).
 package deepcopy
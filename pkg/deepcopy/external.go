@@ -0,0 +1,213 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deepcopy
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/yaml"
+)
+
+// ExternalTypesConfig lists types from packages we don't own (and thus can't
+// annotate with markers, or add methods to) for which deepcopy functions should
+// be generated into a local "sidecar" package.
+//
+// For each listed type, the sidecar package gets a pair of functions,
+// DeepCopyInto_<pkg>_<Type> and DeepCopy_<pkg>_<Type>.  Generated deepcopy
+// code in other packages calls these functions whenever it encounters one of the
+// listed types.
+//
+// An example config file:
+//
+//	package: example.com/myproject/api/thirdparty
+//	types:
+//	- github.com/some/dependency/api.Settings
+//	- github.com/some/dependency/api.Limits
+type ExternalTypesConfig struct {
+	// Package is the import path of the sidecar package that the functions
+	// are generated into.  It must be one of the loaded roots, and must import
+	// (potentially as a blank import) the packages containing each listed type.
+	Package string `json:"package"`
+	// Types lists the external types, in the form <import path>.<type name>.
+	Types []string `json:"types"`
+}
+
+// externalTypes holds the resolved form of an ExternalTypesConfig.
+type externalTypes struct {
+	// pkgPath is the import path of the sidecar package.
+	pkgPath string
+	// byName maps "<import path>.<type name>" to the resolved type.
+	byName map[string]*types.Named
+	// names holds the keys of byName, in config order.
+	names []string
+}
+
+// loadExternalTypes reads the given external types config, and resolves each
+// listed type against the imports of the sidecar root package.
+func loadExternalTypes(contents []byte, roots []*loader.Package, checker *loader.TypeChecker) (*externalTypes, error) {
+	var cfg ExternalTypesConfig
+	if err := yaml.UnmarshalStrict(contents, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse external types config: %w", err)
+	}
+	if cfg.Package == "" {
+		return nil, fmt.Errorf("external types config must specify a package")
+	}
+
+	var sidecar *loader.Package
+	for _, root := range roots {
+		if root.PkgPath == cfg.Package {
+			sidecar = root
+			break
+		}
+	}
+	if sidecar == nil {
+		return nil, fmt.Errorf("external types package %q is not one of the loaded packages", cfg.Package)
+	}
+
+	res := &externalTypes{
+		pkgPath: cfg.Package,
+		byName:  make(map[string]*types.Named, len(cfg.Types)),
+	}
+	for _, fullName := range cfg.Types {
+		ind := strings.LastIndex(fullName, ".")
+		if ind <= 0 || ind == len(fullName)-1 {
+			return nil, fmt.Errorf("invalid external type %q, expected <import path>.<type name>", fullName)
+		}
+		pkgPath, typeName := fullName[:ind], fullName[ind+1:]
+
+		pkg := sidecar.Imports()[pkgPath]
+		if pkg == nil {
+			return nil, fmt.Errorf("external type %q: package %q must be imported by %q", fullName, pkgPath, cfg.Package)
+		}
+		checker.Check(pkg)
+
+		obj := pkg.Types.Scope().Lookup(typeName)
+		typeNameObj, isTypeName := obj.(*types.TypeName)
+		if !isTypeName {
+			return nil, fmt.Errorf("external type %q not found", fullName)
+		}
+		named, isNamed := typeNameObj.Type().(*types.Named)
+		if !isNamed {
+			return nil, fmt.Errorf("external type %q must be a named (non-alias) type", fullName)
+		}
+		if loader.IsGeneric(named) {
+			return nil, fmt.Errorf("external type %q must not be generic", fullName)
+		}
+
+		key := loader.NonVendorPath(pkgPath) + "." + typeName
+		if _, exists := res.byName[key]; !exists {
+			res.names = append(res.names, key)
+		}
+		res.byName[key] = named
+	}
+
+	return res, nil
+}
+
+// lookup returns the given type if it's one of the external types.
+func (e *externalTypes) lookup(typeInfo types.Type) *types.Named {
+	if e == nil {
+		return nil
+	}
+	named, isNamed := typeInfo.(*types.Named)
+	if !isNamed || named.Obj().Pkg() == nil {
+		return nil
+	}
+	return e.byName[loader.NonVendorPath(named.Obj().Pkg().Path())+"."+named.Obj().Name()]
+}
+
+// funcSuffix returns the suffix used for the names of the functions
+// generated for the given external type.
+func (e *externalTypes) funcSuffix(named *types.Named) string {
+	return named.Obj().Pkg().Name() + "_" + named.Obj().Name()
+}
+
+// externalCopyFunc returns the name of the generated DeepCopyInto function for the
+// given type, qualified appropriately for the current package, or an empty
+// string if the type isn't one of the external types.
+func (c *copyMethodMaker) externalCopyFunc(typeInfo types.Type) string {
+	named := c.external.lookup(typeInfo)
+	if named == nil {
+		return ""
+	}
+	funcName := "DeepCopyInto_" + c.external.funcSuffix(named)
+	if c.pkg.PkgPath == c.external.pkgPath && !c.inSubpackage {
+		return funcName
+	}
+	return c.NeedImport(c.external.pkgPath) + "." + funcName
+}
+
+// deepCopyIntoCall returns a statement copying the struct-valued expression `in`
+// into the pointer-valued expression `out`, either by calling DeepCopyInto on
+// it, or by calling the corresponding external deepcopy function.
+func (c *copyMethodMaker) deepCopyIntoCall(typeInfo types.Type, in, out string) string {
+	if copyFunc := c.externalCopyFunc(typeInfo); copyFunc != "" {
+		return fmt.Sprintf("%s(&%s, %s)", copyFunc, in, out)
+	}
+	return fmt.Sprintf("%s.DeepCopyInto(%s)", in, out)
+}
+
+// GenerateFunctionsFor makes DeepCopyInto and DeepCopy functions (not methods)
+// for the given external type.
+func (c *copyMethodMaker) GenerateFunctionsFor(named *types.Named) {
+	suffix := c.external.funcSuffix(named)
+	typeName := (&namingInfo{typeInfo: named}).Syntax(c.pkg, c.importsList)
+
+	c.Linef("// DeepCopyInto_%s is an autogenerated deepcopy function, copying in, writing into out. in must be non-nil.", suffix)
+	c.Linef("func DeepCopyInto_%[1]s(in *%[2]s, out *%[2]s) {", suffix, typeName)
+	c.genDeepCopyIntoBlock(&namingInfo{typeInfo: named}, named)
+	c.Line("}")
+
+	c.Linef(externalDeepCopy, suffix, typeName)
+}
+
+// generateExternalFunctions generates deepcopy functions for all external
+// types, keyed for use with writeMethods.
+func (ctx *ObjectGenCtx) generateExternalFunctions(root *loader.Package, imports *importsList, byType map[string][]byte) {
+	if ctx.externalTypes == nil || root.PkgPath != ctx.externalTypes.pkgPath {
+		return
+	}
+	for _, name := range ctx.externalTypes.names {
+		named := ctx.externalTypes.byName[name]
+		outContent := new(bytes.Buffer)
+		copyCtx := &copyMethodMaker{
+			pkg:         root,
+			importsList: imports,
+			codeWriter:  &codeWriter{out: outContent},
+			external:    ctx.externalTypes,
+		}
+		copyCtx.GenerateFunctionsFor(named)
+		// NB: '~' can't appear in type names, so this never collides with
+		// the package's own types.
+		byType["~"+ctx.externalTypes.funcSuffix(named)] = outContent.Bytes()
+	}
+}
+
+// externalDeepCopy is a DeepCopy function for an external type.
+var externalDeepCopy = `
+// DeepCopy_%[1]s is an autogenerated deepcopy function, copying in, creating a new %[2]s.
+func DeepCopy_%[1]s(in *%[2]s) *%[2]s {
+	if in == nil { return nil }
+	out := new(%[2]s)
+	DeepCopyInto_%[1]s(in, out)
+	return out
+}
+`
@@ -22,10 +22,12 @@ import (
 	"go/ast"
 	"go/format"
 	"io"
+	"path"
 	"slices"
 	"strings"
 
 	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
 )
@@ -45,6 +47,28 @@ var (
 	legacyEnablePkgMarker  = markers.Must(markers.MakeDefinition("k8s:deepcopy-gen", markers.DescribesPackage, markers.RawArguments(nil)))
 	legacyEnableTypeMarker = markers.Must(markers.MakeDefinition("k8s:deepcopy-gen", markers.DescribesType, markers.RawArguments(nil)))
 	legacyIsObjectMarker   = markers.Must(markers.MakeDefinition("k8s:deepcopy-gen:interfaces", markers.DescribesType, ""))
+
+	skipFieldMarker = markers.Must(markers.MakeDefinition("deepcopy:skip", markers.DescribesField, SkipField{}))
+)
+
+// +controllertools:marker:generateHelp:category=object
+
+// SkipField skips deep-copying a field.
+//
+// This is useful for runtime-only fields (caches, channels, functions, etc.)
+// that can't or shouldn't be deep-copied, without having to hand-write
+// deepcopy for the whole type.
+type SkipField struct {
+	// Mode specifies what the field is set to in the copy.
+	//
+	// "zero" (the default) leaves the field as its zero value, while
+	// "reference" shares the original value with the copy.
+	Mode string `marker:",optional"`
+}
+
+const (
+	skipModeZero      = "zero"
+	skipModeReference = "reference"
 )
 
 // +controllertools:marker:generateHelp
@@ -56,6 +80,38 @@ type Generator struct {
 	HeaderFile string `marker:",optional"`
 	// Year specifies the year to substitute for " YEAR" in the header file.
 	Year string `marker:",optional"`
+	// Scheme enables generating scheme registration code (GroupVersion,
+	// SchemeBuilder and AddToScheme) into zz_generated.register.go, registering
+	// all root kinds of each package.
+	//
+	// The group and version are taken from the +groupName and +versionName
+	// package markers.  This replaces the scaffolded groupversion_info.go and
+	// the per-type SchemeBuilder.Register calls.
+	Scheme bool `marker:",optional"`
+	// ExternalTypes specifies a config file listing types from other packages
+	// (which can't be annotated with markers) to generate deepcopy functions for.
+	//
+	// The functions are generated into a local sidecar package named in the
+	// config file, and are used by the generated code of all other packages
+	// whenever they encounter one of the listed types, e.g.:
+	//
+	//	package: example.com/myproject/api/thirdparty
+	//	types:
+	//	- github.com/some/dependency/api.Settings
+	ExternalTypes string `marker:",optional"`
+	// Subpackage generates the DeepCopyInto implementations of each package
+	// into its "generated" subpackage, keeping only thin DeepCopyInto, DeepCopy
+	// and DeepCopyObject methods calling them in the package itself, e.g. so
+	// that the generated code can be linted differently.
+	//
+	// Since the subpackage imports the package, it registers the
+	// implementations with the package when imported, so programs (and
+	// external tests) using the deep copies of the package must import the
+	// subpackage, e.g. with a blank import.  Generic types, and types with
+	// manual DeepCopy or DeepCopyInto methods, keep their implementations in
+	// the package, and types with unexported fields that can't be
+	// shallow-copied can't be copied from the subpackage.
+	Subpackage bool `marker:",optional"`
 }
 
 func (Generator) CheckFilter() loader.NodeFilter {
@@ -66,18 +122,37 @@ func (Generator) CheckFilter() loader.NodeFilter {
 	}
 }
 
-func (Generator) RegisterMarkers(into *markers.Registry) error {
+// OnlyDependencyTypes returns true, since only the types of the fields of
+// other packages matter to deep-copying.
+func (Generator) OnlyDependencyTypes() bool {
+	return true
+}
+
+// IncrementalRoots returns the affected roots, since their deepcopy
+// implementations are generated per package.
+func (Generator) IncrementalRoots(_ *genall.GenerationContext, affected []*loader.Package) []*loader.Package {
+	return affected
+}
+
+func (d Generator) RegisterMarkers(into *markers.Registry) error {
 	if err := markers.RegisterAll(into,
 		enablePkgMarker, legacyEnablePkgMarker, enableTypeMarker,
-		legacyEnableTypeMarker, isObjectMarker, legacyIsObjectMarker); err != nil {
+		legacyEnableTypeMarker, isObjectMarker, legacyIsObjectMarker, skipFieldMarker); err != nil {
 		return err
 	}
+	if d.Scheme {
+		// only needed for scheme registration
+		if err := crd.RegisterGroupVersionMarkers(into); err != nil {
+			return err
+		}
+	}
 	into.AddHelp(enablePkgMarker,
 		markers.SimpleHelp("object", "enables or disables object interface & deepcopy implementation generation for this package"))
 	into.AddHelp(
 		enableTypeMarker, markers.SimpleHelp("object", "overrides enabling or disabling deepcopy generation for this type"))
 	into.AddHelp(isObjectMarker,
 		markers.SimpleHelp("object", "enables object interface implementation generation for this type"))
+	into.AddHelp(skipFieldMarker, SkipField{}.Help())
 
 	into.AddHelp(legacyEnablePkgMarker,
 		markers.DeprecatedHelp(enablePkgMarker.Name, "object", "enables or disables object interface & deepcopy implementation generation for this package"))
@@ -134,30 +209,43 @@ func genObjectInterface(info *markers.TypeInfo) bool {
 }
 
 func (d Generator) Generate(ctx *genall.GenerationContext) error {
-	var headerText string
-
-	if d.HeaderFile != "" {
-		headerBytes, err := ctx.ReadFile(d.HeaderFile)
-		if err != nil {
-			return err
-		}
-		headerText = string(headerBytes)
+	headerText, err := ctx.GoHeaderText(d.HeaderFile, d.Year)
+	if err != nil {
+		return err
 	}
-	headerText = strings.ReplaceAll(headerText, " YEAR", " "+d.Year)
 
 	objGenCtx := ObjectGenCtx{
 		Collector:  ctx.Collector,
 		Checker:    ctx.Checker,
 		HeaderText: headerText,
+		subpackage: d.Subpackage,
 	}
 
-	for _, root := range ctx.Roots {
-		outContents := objGenCtx.generateForPackage(root)
-		if outContents == nil {
-			continue
+	if d.ExternalTypes != "" {
+		contents, err := ctx.ReadFile(d.ExternalTypes)
+		if err != nil {
+			return err
+		}
+		objGenCtx.externalTypes, err = loadExternalTypes(contents, ctx.Roots, ctx.Checker)
+		if err != nil {
+			return err
 		}
+	}
 
-		writeOut(ctx, root, outContents)
+	for _, root := range ctx.Roots {
+		outContents, subpackageContents := objGenCtx.generateForPackage(root)
+		if outContents != nil {
+			writeOut(ctx, root, "zz_generated.deepcopy.go", outContents)
+		}
+		if subpackageContents != nil {
+			writeOut(ctx, root, path.Join(subpackageName, "zz_generated.deepcopy.go"), subpackageContents)
+		}
+		if d.Scheme {
+			if outContents := objGenCtx.generateSchemeForPackage(root); outContents != nil {
+				writeOut(ctx, root, "zz_generated.register.go", outContents)
+			}
+		}
+		ctx.DoneWith(root)
 	}
 
 	return nil
@@ -170,6 +258,13 @@ type ObjectGenCtx struct {
 	Collector  *markers.Collector
 	Checker    *loader.TypeChecker
 	HeaderText string
+
+	// externalTypes are types from other packages with generated deepcopy
+	// functions (may be nil).
+	externalTypes *externalTypes
+	// subpackage indicates that the DeepCopyInto implementations are
+	// generated into the generated subpackage of each package.
+	subpackage bool
 }
 
 // writeHeader writes out the build tag, package declaration, and imports
@@ -194,13 +289,14 @@ import (
 }
 
 // generateForPackage generates DeepCopy and runtime.Object implementations for
-// types in the given package, writing the formatted result to given writer.
-// May return nil if source could not be generated.
-func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) []byte {
+// types in the given package, returning the formatted code of the package and
+// of its generated subpackage.  Either may be nil if source could not be
+// generated, or wasn't needed.
+func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) ([]byte, []byte) {
 	allTypes, err := enabledOnPackage(ctx.Collector, root)
 	if err != nil {
 		root.AddError(err)
-		return nil
+		return nil, nil
 	}
 
 	ctx.Checker.Check(root)
@@ -216,6 +312,11 @@ func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) []byte {
 	// avoid confusing aliases by "reserving" the root package's name as an alias
 	imports.byAlias[root.Name] = ""
 
+	var subpackage *subpackageFile
+	if ctx.subpackage {
+		subpackage = newSubpackageFile(root)
+	}
+
 	if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
 		outContent := new(bytes.Buffer)
 
@@ -234,6 +335,10 @@ func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) []byte {
 			pkg:         root,
 			importsList: imports,
 			codeWriter:  &codeWriter{out: outContent},
+			external:    ctx.externalTypes,
+		}
+		if subpackage != nil && movesToSubpackage(root, info) {
+			copyCtx.subpackage = subpackage
 		}
 
 		copyCtx.GenerateMethodsFor(root, info)
@@ -244,15 +349,23 @@ func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) []byte {
 		}
 	}); err != nil {
 		root.AddError(err)
-		return nil
+		return nil, nil
 	}
 
+	ctx.generateExternalFunctions(root, imports, byType)
+
 	if len(byType) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	outContent := new(bytes.Buffer)
 	writeHeader(root, outContent, root.Name, imports, ctx.HeaderText)
+	var subpackageContents []byte
+	if subpackage != nil {
+		if subpackageContents = subpackage.contents(ctx.HeaderText); subpackageContents != nil {
+			subpackage.writeRegistration(outContent)
+		}
+	}
 	writeMethods(root, outContent, byType)
 
 	outBytes := outContent.Bytes()
@@ -264,7 +377,7 @@ func (ctx *ObjectGenCtx) generateForPackage(root *loader.Package) []byte {
 		outBytes = formattedBytes
 	}
 
-	return outBytes
+	return outBytes, subpackageContents
 }
 
 // writeMethods writes each method to the file, sorted by type name.
@@ -283,21 +396,27 @@ func writeMethods(pkg *loader.Package, out io.Writer, byType map[string][]byte)
 	}
 }
 
-// writeFormatted outputs the given code, after gofmt-ing it.  If we couldn't gofmt,
-// we write the unformatted code for debugging purposes.
-func writeOut(ctx *genall.GenerationContext, root *loader.Package, outBytes []byte) {
-	outputFile, err := ctx.Open(root, "zz_generated.deepcopy.go")
+// writeOut outputs the given (already formatted) code to the given file in
+// the package.
+func writeOut(ctx *genall.GenerationContext, root *loader.Package, fileName string, outBytes []byte) {
+	outputFile, err := ctx.Open(root, fileName)
 	if err != nil {
 		root.AddError(err)
 		return
 	}
-	defer outputFile.Close()
 	n, err := outputFile.Write(outBytes)
 	if err != nil {
+		_ = outputFile.Close()
 		root.AddError(err)
 		return
 	}
 	if n < len(outBytes) {
+		_ = outputFile.Close()
 		root.AddError(io.ErrShortWrite)
+		return
+	}
+	// e.g. when the protected regions of the file can't be kept
+	if err := outputFile.Close(); err != nil {
+		root.AddError(err)
 	}
 }
@@ -0,0 +1,146 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deepcopy
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"slices"
+
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	metav1Path  = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimePath = "k8s.io/apimachinery/pkg/runtime"
+	schemaPath  = "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// generateSchemeForPackage generates the scheme registration boilerplate
+// (GroupVersion, SchemeBuilder, AddToScheme) for the given package, registering
+// every root kind found in it.  May return nil if the package contains no
+// kinds to register.
+func (ctx *ObjectGenCtx) generateSchemeForPackage(root *loader.Package) []byte {
+	pkgMarkers, err := markers.PackageMarkers(ctx.Collector, root)
+	if err != nil {
+		root.AddError(err)
+		return nil
+	}
+
+	ctx.Checker.Check(root)
+	root.NeedTypesInfo()
+
+	var kinds []string
+	if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+		if !genObjectInterface(info) || !isKind(root, info) {
+			return
+		}
+		kinds = append(kinds, info.Name)
+	}); err != nil {
+		root.AddError(err)
+		return nil
+	}
+
+	if len(kinds) == 0 {
+		return nil
+	}
+	slices.Sort(kinds)
+
+	gv := crd.GroupVersionForPackage(pkgMarkers, root)
+	if gv.Empty() {
+		root.AddError(fmt.Errorf("unable to generate scheme registration for package %q: no +groupName marker found", root.PkgPath))
+		return nil
+	}
+
+	imports := &importsList{
+		byPath:  make(map[string]string),
+		byAlias: make(map[string]string),
+		pkg:     root,
+	}
+	// avoid confusing aliases by "reserving" the root package's name as an alias
+	imports.byAlias[root.Name] = ""
+
+	body := new(bytes.Buffer)
+	writer := &codeWriter{out: body}
+	metav1Alias := imports.NeedImport(metav1Path)
+	runtimeAlias := imports.NeedImport(runtimePath)
+	schemaAlias := imports.NeedImport(schemaPath)
+
+	writer.Linef(schemeVars, schemaAlias, gv.Group, gv.Version, runtimeAlias)
+	writer.Linef(schemeResource, schemaAlias)
+	writer.Line("// addKnownTypes adds the set of types defined in this package to the supplied scheme.")
+	writer.Linef("func addKnownTypes(scheme *%s.Scheme) error {", runtimeAlias)
+	writer.Line("scheme.AddKnownTypes(GroupVersion,")
+	for _, kind := range kinds {
+		writer.Linef("&%s{},", kind)
+	}
+	writer.Line(")")
+	writer.Linef("%s.AddToGroupVersion(scheme, GroupVersion)", metav1Alias)
+	writer.Line("return nil")
+	writer.Line("}")
+
+	outContent := new(bytes.Buffer)
+	writeHeader(root, outContent, root.Name, imports, ctx.HeaderText)
+	outContent.Write(body.Bytes())
+
+	return gogen.Format(root, outContent.Bytes())
+}
+
+// isKind checks if the given type can be registered with a scheme, that is,
+// if (a pointer to) it has a GetObjectKind method, usually via an embedded
+// TypeMeta.  Root types without one can't be runtime.Objects.
+func isKind(pkg *loader.Package, info *markers.TypeInfo) bool {
+	typeInfo := pkg.TypesInfo.TypeOf(info.RawSpec.Name)
+	if typeInfo == nil || typeInfo == types.Typ[types.Invalid] {
+		return false
+	}
+	method, _, _ := types.LookupFieldOrMethod(types.NewPointer(typeInfo), true, pkg.Types, "GetObjectKind")
+	_, isFunc := method.(*types.Func)
+	return isFunc
+}
+
+var (
+	// schemeVars declares the group-version and scheme builder for a package.
+	schemeVars = `
+var (
+	// GroupVersion is group version used to register these objects.
+	GroupVersion = %[1]s.GroupVersion{Group: %[2]q, Version: %[3]q}
+
+	// SchemeGroupVersion is an alias of GroupVersion for compatibility with
+	// code written against the upstream code generators.
+	SchemeGroupVersion = GroupVersion
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme.
+	SchemeBuilder = %[4]s.NewSchemeBuilder(addKnownTypes)
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+`
+
+	// schemeResource declares the Resource helper for a package.
+	schemeResource = `
+// Resource takes an unqualified resource and returns a Group qualified GroupResource.
+func Resource(resource string) %[1]s.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
+`
+)
@@ -0,0 +1,156 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deepcopy
+
+import (
+	"bytes"
+	"go/types"
+	"io"
+	"maps"
+	"path"
+	"slices"
+
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// subpackageName is the name of the subpackage the DeepCopyInto
+// implementations are generated into, with the Subpackage option.
+const subpackageName = "generated"
+
+// subpackageFile collects the DeepCopyInto implementations of the types of a
+// package generated into its subpackage.
+//
+// Since the subpackage imports the package, the package can't call the
+// implementations directly: the subpackage registers them with the package
+// when it's imported, and the DeepCopyInto methods of the package call the
+// registered ones.
+type subpackageFile struct {
+	root    *loader.Package
+	imports *importsList
+	// byType are the implementations, by type name.
+	byType map[string][]byte
+}
+
+func newSubpackageFile(root *loader.Package) *subpackageFile {
+	imports := &importsList{
+		byPath:       make(map[string]string),
+		byAlias:      make(map[string]string),
+		pkg:          root,
+		inSubpackage: true,
+	}
+	// avoid confusing aliases by "reserving" the subpackage's name as an alias
+	imports.byAlias[subpackageName] = ""
+
+	return &subpackageFile{
+		root:    root,
+		imports: imports,
+		byType:  make(map[string][]byte),
+	}
+}
+
+// movesToSubpackage checks if the DeepCopyInto implementation of the given
+// type is generated into the subpackage.  Generic types keep theirs in the
+// package, since generic functions can't be registered, as do types with
+// manual DeepCopy or DeepCopyInto methods, whose DeepCopyInto wraps the
+// manual methods.
+func movesToSubpackage(root *loader.Package, info *markers.TypeInfo) bool {
+	typeInfo := root.TypesInfo.TypeOf(info.RawSpec.Name)
+	if named, isNamed := typeInfo.(*types.Named); isNamed && loader.IsGeneric(named) {
+		return false
+	}
+	return !hasAnyDeepCopyMethod(root, typeInfo)
+}
+
+// generateFor generates the DeepCopyInto implementation of the given type,
+// skipping the fields skipped by the given method maker of the type.
+func (f *subpackageFile) generateFor(methods *copyMethodMaker, typeName string, typeInfo types.Type) {
+	outContent := new(bytes.Buffer)
+	c := &copyMethodMaker{
+		pkg:           f.root,
+		importsList:   f.imports,
+		codeWriter:    &codeWriter{out: outContent},
+		external:      methods.external,
+		skippedFields: methods.skippedFields,
+	}
+
+	qualifiedName := (&namingInfo{typeInfo: typeInfo}).Syntax(f.root, f.imports)
+	c.Linef("// DeepCopyInto_%s is an autogenerated deepcopy function, copying in, writing into out. in must be non-nil.", typeName)
+	c.Linef("func DeepCopyInto_%[1]s(in *%[2]s, out *%[2]s) {", typeName, qualifiedName)
+	c.genDeepCopyIntoBlock(&namingInfo{typeInfo: typeInfo}, typeInfo)
+	c.Line("}")
+
+	f.byType[typeName] = outContent.Bytes()
+}
+
+// writeRegistration writes out the registration of the implementations
+// generated into the subpackage, and the lookup of the registered ones used
+// by the DeepCopyInto methods, to the code of the package.
+func (f *subpackageFile) writeRegistration(out io.Writer) {
+	c := &codeWriter{out: out}
+	c.Line("// DeepCopyFuncs are the DeepCopyInto implementations of the types of this package,")
+	c.Line("// which its generated subpackage registers when imported.")
+	c.Line("type DeepCopyFuncs struct {")
+	for _, name := range slices.Sorted(maps.Keys(f.byType)) {
+		c.Linef("%[1]s func(in, out *%[1]s)", name)
+	}
+	c.Line("}")
+	c.Line("")
+	c.Line("var registeredDeepCopyFuncs *DeepCopyFuncs")
+	c.Line("")
+	c.Line("// RegisterDeepCopyFuncs registers the DeepCopyInto implementations of the types of this package.")
+	c.Line("func RegisterDeepCopyFuncs(funcs *DeepCopyFuncs) {")
+	c.Line("registeredDeepCopyFuncs = funcs")
+	c.Line("}")
+	c.Line("")
+	c.Line("// deepCopyFuncs returns the registered DeepCopyInto implementations.")
+	c.Line("func deepCopyFuncs() *DeepCopyFuncs {")
+	c.If("registeredDeepCopyFuncs == nil", func() {
+		c.Linef("panic(`deepcopy functions not registered, import _ %q`)", path.Join(loader.NonVendorPath(f.root.PkgPath), subpackageName))
+	})
+	c.Line("return registeredDeepCopyFuncs")
+	c.Line("}")
+	c.Line("")
+}
+
+// contents returns the formatted code of the subpackage, with the given
+// header text, or nil if no implementation was generated into it.
+func (f *subpackageFile) contents(headerText string) []byte {
+	if len(f.byType) == 0 {
+		return nil
+	}
+
+	registration := new(bytes.Buffer)
+	c := &codeWriter{out: registration}
+	rootAlias := f.imports.NeedImport(f.root.PkgPath)
+	c.Line("func init() {")
+	c.Linef("%[1]s.RegisterDeepCopyFuncs(&%[1]s.DeepCopyFuncs{", rootAlias)
+	for _, name := range slices.Sorted(maps.Keys(f.byType)) {
+		c.Linef("%[1]s: DeepCopyInto_%[1]s,", name)
+	}
+	c.Line("})")
+	c.Line("}")
+	c.Line("")
+
+	outContent := new(bytes.Buffer)
+	writeHeader(f.root, outContent, subpackageName, f.imports, headerText)
+	outContent.Write(registration.Bytes())
+	writeMethods(f.root, outContent, f.byType)
+
+	return gogen.Format(f.root, outContent.Bytes())
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-//go:generate ../../../.run-controller-gen.sh object:headerFile=./../../../hack/boilerplate/boilerplate.generatego.txt paths=.
+//go:generate ../../../.run-controller-gen.sh object:headerFile=./../../../hack/boilerplate/boilerplate.generatego.txt,scheme=true paths=.
 
 // +kubebuilder:object:generate=true
 // +groupName=testdata.kubebuilder.io
@@ -218,6 +218,25 @@ func (d DeepCopyIntoRef) DeepCopyInto(out *DeepCopyIntoRef) {
 	*out = make(DeepCopyIntoRef)
 }
 
+// Tests fields skipped with +deepcopy:skip
+type SkippedFields struct {
+	Name string `json:"name"`
+
+	// +deepcopy:skip
+	Cache map[string]*SomeStruct `json:"-"`
+	// +deepcopy:skip
+	Count int `json:"-"`
+	// +deepcopy:skip
+	Last SomeStruct `json:"-"`
+	// +deepcopy:skip:mode=reference
+	Done chan struct{} `json:"-"`
+	// +deepcopy:skip:mode=reference
+	OnChange func() `json:"-"`
+
+	// +deepcopy:skip
+	*Bar `json:"-"`
+}
+
 // Case: kubernetes-sigs/controller-tools#262 part 1:
 // Type renames of slices to pointers.
 type SliceOfPointers []*SomeStruct
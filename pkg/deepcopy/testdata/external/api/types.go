@@ -0,0 +1,32 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate ../../../../../.run-controller-gen.sh object:headerFile=./../../../../../hack/boilerplate/boilerplate.generatego.txt,externalTypes=../external.yaml paths=../...
+
+// +kubebuilder:object:generate=true
+package api
+
+import (
+	"testdata.kubebuilder.io/cronjob/external/dependency"
+)
+
+type Spec struct {
+	Settings        dependency.Settings
+	SettingsPtr     *dependency.Settings
+	SettingsList    []dependency.Settings
+	SettingsByName  map[string]dependency.Settings
+	SettingsPtrList []*dependency.Settings
+}
@@ -0,0 +1,31 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dependency stands in for a third-party package that we can't add
+// deepcopy markers or methods to.
+package dependency
+
+type Settings struct {
+	Name     string
+	Tags     []string
+	Limits   map[string]int64
+	Fallback *Settings
+	Window   Window
+}
+
+type Window struct {
+	Start, End int64
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This file contains generic types, whose deepcopy methods are generated on
+// their own type parameters, and instantiations of them.
+
+// Wrapper wraps values of any type, deep-copied with their deepcopy methods if
+// they have any.
+type Wrapper[T any] struct {
+	Value   T            `json:"value"`
+	Values  []T          `json:"values,omitempty"`
+	Pointer *T           `json:"pointer,omitempty"`
+	ByName  map[string]T `json:"byName,omitempty"`
+}
+
+// Pair pairs keys and values of any types.
+type Pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// GenericList is a generic non-struct type.
+type GenericList[T any] []T
+
+// GenericIndex maps keys of any type to values of any type.
+type GenericIndex[K comparable, V any] map[K]V
+
+// GenericTree is a recursive generic type.
+type GenericTree[T any] struct {
+	Value    T                `json:"value"`
+	Children []GenericTree[T] `json:"children,omitempty"`
+}
+
+// GenericHolder uses instantiations of generic types.
+type GenericHolder struct {
+	Str      Wrapper[string]                `json:"str"`
+	Time     Wrapper[metav1.Time]           `json:"time"`
+	Pointer  *Wrapper[int32]                `json:"pointer,omitempty"`
+	Pairs    []Pair[string, Wrapper[int32]] `json:"pairs,omitempty"`
+	Shallow  Pair[string, int32]            `json:"shallow"`
+	List     GenericList[metav1.Time]       `json:"list,omitempty"`
+	Index    GenericIndex[string, []string] `json:"index,omitempty"`
+	ByName   map[string]Wrapper[string]     `json:"byName,omitempty"`
+	Tree     GenericTree[string]            `json:"tree"`
+	Instance TimeWrapper                    `json:"instance"`
+}
+
+// TimeWrapper is a declaration of an instantiation of a generic type.
+type TimeWrapper Wrapper[metav1.Time]
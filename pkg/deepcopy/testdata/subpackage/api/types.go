@@ -0,0 +1,87 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//go:generate ../../../../../.run-controller-gen.sh object:headerFile=./../../../../../hack/boilerplate/boilerplate.generatego.txt,subpackage=true paths=.
+
+// +kubebuilder:object:generate=true
+package api
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// Widget has its DeepCopyInto implementation in the generated subpackage.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WidgetSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WidgetList is a list of Widgets.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Widget `json:"items"`
+}
+
+type WidgetSpec struct {
+	Replicas *int32            `json:"replicas,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ports    Ports             `json:"ports,omitempty"`
+	Parts    map[string]*Part  `json:"parts,omitempty"`
+	// +deepcopy:skip
+	Scratch []byte `json:"-"`
+
+	// unexported fields are left with their shallow copy, when that's enough
+	generation int
+	// +deepcopy:skip:mode=reference
+	onChange func()
+}
+
+// Ports has a value receiver.
+type Ports []Port
+
+type Port struct {
+	Name   string `json:"name"`
+	Number int32  `json:"number"`
+}
+
+type Part struct {
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// Versioned keeps its DeepCopyInto implementation in the package, since it's
+// generic.
+type Versioned[T any] struct {
+	Items []T `json:"items"`
+}
+
+// Manual keeps its DeepCopyInto implementation in the package, since it's
+// written manually.
+type Manual struct {
+	Data []byte `json:"data"`
+}
+
+func (in *Manual) DeepCopyInto(out *Manual) {
+	*out = *in
+	out.Data = append([]byte(nil), in.Data...)
+}
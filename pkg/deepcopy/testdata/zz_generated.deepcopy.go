@@ -23,6 +23,7 @@ package cronjob
 import (
 	"encoding/json"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -480,6 +481,148 @@ func (in FooSlice) DeepCopy() FooSlice {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericHolder) DeepCopyInto(out *GenericHolder) {
+	*out = *in
+	in.Str.DeepCopyInto(&out.Str)
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Pointer != nil {
+		in, out := &in.Pointer, &out.Pointer
+		*out = new(Wrapper[int32])
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pairs != nil {
+		in, out := &in.Pairs, &out.Pairs
+		*out = make([]Pair[string, Wrapper[int32]], len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Shallow = in.Shallow
+	if in.List != nil {
+		in, out := &in.List, &out.List
+		*out = make(GenericList[metav1.Time], len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Index != nil {
+		in, out := &in.Index, &out.Index
+		*out = make(GenericIndex[string, []string], len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ByName != nil {
+		in, out := &in.ByName, &out.ByName
+		*out = make(map[string]Wrapper[string], len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	in.Tree.DeepCopyInto(&out.Tree)
+	in.Instance.DeepCopyInto(&out.Instance)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericHolder.
+func (in *GenericHolder) DeepCopy() *GenericHolder {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericHolder)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in GenericIndex[K, V]) DeepCopyInto(out *GenericIndex[K, V]) {
+	{
+		in := &in
+		*out = make(GenericIndex[K, V], len(*in))
+		for key, val := range *in {
+			outVal := val
+			if copier, ok := any(val).(interface{ DeepCopy() V }); ok {
+				outVal = copier.DeepCopy()
+			} else if copier, ok := any(&val).(interface{ DeepCopyInto(*V) }); ok {
+				copier.DeepCopyInto(&outVal)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericIndex[K, V].
+func (in GenericIndex[K, V]) DeepCopy() GenericIndex[K, V] {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericIndex[K, V])
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in GenericList[T]) DeepCopyInto(out *GenericList[T]) {
+	{
+		in := &in
+		*out = make(GenericList[T], len(*in))
+		copy(*out, *in)
+		for i := range *in {
+			if copier, ok := any((*in)[i]).(interface{ DeepCopy() T }); ok {
+				(*out)[i] = copier.DeepCopy()
+			} else if copier, ok := any(&(*in)[i]).(interface{ DeepCopyInto(*T) }); ok {
+				copier.DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericList[T].
+func (in GenericList[T]) DeepCopy() GenericList[T] {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericList[T])
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericTree[T]) DeepCopyInto(out *GenericTree[T]) {
+	*out = *in
+	if copier, ok := any(in.Value).(interface{ DeepCopy() T }); ok {
+		out.Value = copier.DeepCopy()
+	} else if copier, ok := any(&in.Value).(interface{ DeepCopyInto(*T) }); ok {
+		copier.DeepCopyInto(&out.Value)
+	}
+	if in.Children != nil {
+		in, out := &in.Children, &out.Children
+		*out = make([]GenericTree[T], len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericTree[T].
+func (in *GenericTree[T]) DeepCopy() *GenericTree[T] {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericTree[T])
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Inner) DeepCopyInto(out *Inner) {
 	*out = *in
@@ -586,6 +729,50 @@ func (in MapOfStrings) DeepCopy() MapOfStrings {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Pair[K, V]) DeepCopyInto(out *Pair[K, V]) {
+	*out = *in
+	if copier, ok := any(in.Key).(interface{ DeepCopy() K }); ok {
+		out.Key = copier.DeepCopy()
+	} else if copier, ok := any(&in.Key).(interface{ DeepCopyInto(*K) }); ok {
+		copier.DeepCopyInto(&out.Key)
+	}
+	if copier, ok := any(in.Value).(interface{ DeepCopy() V }); ok {
+		out.Value = copier.DeepCopy()
+	} else if copier, ok := any(&in.Value).(interface{ DeepCopyInto(*V) }); ok {
+		copier.DeepCopyInto(&out.Value)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pair[K, V].
+func (in *Pair[K, V]) DeepCopy() *Pair[K, V] {
+	if in == nil {
+		return nil
+	}
+	out := new(Pair[K, V])
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedFields) DeepCopyInto(out *SkippedFields) {
+	*out = *in
+	out.Cache = nil
+	out.Count = 0
+	out.Last = SomeStruct{}
+	out.Bar = nil
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedFields.
+func (in *SkippedFields) DeepCopy() *SkippedFields {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedFields)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Slice) DeepCopyInto(out *Slice) {
 	{
@@ -2051,6 +2238,40 @@ func (in *TestSlices) DeepCopy() *TestSlices {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWrapper) DeepCopyInto(out *TimeWrapper) {
+	*out = *in
+	in.Value.DeepCopyInto(&out.Value)
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]metav1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pointer != nil {
+		in, out := &in.Pointer, &out.Pointer
+		*out = (*in).DeepCopy()
+	}
+	if in.ByName != nil {
+		in, out := &in.ByName, &out.ByName
+		*out = make(map[string]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWrapper.
+func (in *TimeWrapper) DeepCopy() *TimeWrapper {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWrapper)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Ttest) DeepCopyInto(out *Ttest) {
 	*out = *in
@@ -2171,3 +2392,58 @@ func (in *UntypedBlob) DeepCopy() *UntypedBlob {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Wrapper[T]) DeepCopyInto(out *Wrapper[T]) {
+	*out = *in
+	if copier, ok := any(in.Value).(interface{ DeepCopy() T }); ok {
+		out.Value = copier.DeepCopy()
+	} else if copier, ok := any(&in.Value).(interface{ DeepCopyInto(*T) }); ok {
+		copier.DeepCopyInto(&out.Value)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]T, len(*in))
+		copy(*out, *in)
+		for i := range *in {
+			if copier, ok := any((*in)[i]).(interface{ DeepCopy() T }); ok {
+				(*out)[i] = copier.DeepCopy()
+			} else if copier, ok := any(&(*in)[i]).(interface{ DeepCopyInto(*T) }); ok {
+				copier.DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.Pointer != nil {
+		in, out := &in.Pointer, &out.Pointer
+		*out = new(T)
+		**out = **in
+		if copier, ok := any((**in)).(interface{ DeepCopy() T }); ok {
+			(**out) = copier.DeepCopy()
+		} else if copier, ok := any(&(**in)).(interface{ DeepCopyInto(*T) }); ok {
+			copier.DeepCopyInto(&(**out))
+		}
+	}
+	if in.ByName != nil {
+		in, out := &in.ByName, &out.ByName
+		*out = make(map[string]T, len(*in))
+		for key, val := range *in {
+			outVal := val
+			if copier, ok := any(val).(interface{ DeepCopy() T }); ok {
+				outVal = copier.DeepCopy()
+			} else if copier, ok := any(&val).(interface{ DeepCopyInto(*T) }); ok {
+				copier.DeepCopyInto(&outVal)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Wrapper[T].
+func (in *Wrapper[T]) DeepCopy() *Wrapper[T] {
+	if in == nil {
+		return nil
+	}
+	out := new(Wrapper[T])
+	in.DeepCopyInto(out)
+	return out
+}
@@ -21,7 +21,9 @@ import (
 	"go/ast"
 	"go/types"
 	"io"
+	"maps"
 	"path"
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -86,6 +88,9 @@ type importsList struct {
 	byAlias map[string]string
 
 	pkg *loader.Package
+	// inSubpackage indicates that the imports are those of the generated
+	// subpackage of pkg, which has to import pkg itself too.
+	inSubpackage bool
 }
 
 // NeedImport marks that the given package is needed in the list of imports,
@@ -138,12 +143,16 @@ func (l *importsList) NeedImport(importPath string) string {
 }
 
 // ImportSpecs returns a string form of each import spec
-// (i.e. `alias "path/to/import").  Aliases are only present
-// when they don't match the package name.
+// (i.e. `alias "path/to/import"), in the order of their paths.
+// Aliases are only present when they don't match the package name.
 func (l *importsList) ImportSpecs() []string {
 	res := make([]string, 0, len(l.byPath))
-	for importPath, alias := range l.byPath {
+	for _, importPath := range slices.Sorted(maps.Keys(l.byPath)) {
+		alias := l.byPath[importPath]
 		pkg := l.pkg.Imports()[importPath]
+		if l.inSubpackage && importPath == loader.NonVendorPath(l.pkg.PkgPath) {
+			pkg = l.pkg
+		}
 		if pkg != nil && pkg.Name == alias {
 			// don't print if alias is the same as package name
 			// (we've already taken care of duplicates).
@@ -178,7 +187,18 @@ func (n *namingInfo) Syntax(basePkg *loader.Package, imports *importsList) strin
 	case *types.Alias:
 		typeName = typeInfo.Obj()
 	case *types.Named:
+		if loader.IsInstance(typeInfo) {
+			// instantiations of generic types, e.g. `Wrapper[metav1.Time]`
+			return types.TypeString(typeInfo, func(otherPkg *types.Package) string {
+				if otherPkg == basePkg.Types && !imports.inSubpackage {
+					return ""
+				}
+				return imports.NeedImport(loader.NonVendorPath(otherPkg.Path()))
+			})
+		}
 		typeName = typeInfo.Obj()
+	case *types.TypeParam:
+		return typeInfo.Obj().Name()
 	case *types.Basic:
 		return typeInfo.String()
 	case *types.Pointer:
@@ -199,8 +219,13 @@ func (n *namingInfo) Syntax(basePkg *loader.Package, imports *importsList) strin
 	// so we can get the appropriate alias to use.
 	otherPkg := typeName.Pkg()
 	if otherPkg == basePkg.Types {
-		// local import
-		return typeName.Name()
+		if !imports.inSubpackage {
+			// local import
+			return typeName.Name()
+		}
+		if !typeName.Exported() {
+			basePkg.AddError(fmt.Errorf("cannot refer to unexported type %s from the %s subpackage", typeName.Name(), subpackageName))
+		}
 	}
 	alias := imports.NeedImport(loader.NonVendorPath(otherPkg.Path()))
 	return alias + "." + typeName.Name()
@@ -212,6 +237,19 @@ type copyMethodMaker struct {
 	pkg *loader.Package
 	*importsList
 	*codeWriter
+
+	// external contains types from other packages that have generated
+	// deepcopy functions instead of methods (may be nil).
+	external *externalTypes
+
+	// skippedFields are the fields of the type currently being generated
+	// that are marked with +deepcopy:skip.
+	skippedFields []markers.FieldInfo
+
+	// subpackage collects the DeepCopyInto implementations generated into the
+	// generated subpackage of the package, if the type is generated there
+	// (may be nil).
+	subpackage *subpackageFile
 }
 
 // GenerateMethodsFor makes DeepCopy, DeepCopyInto, and DeepCopyObject methods
@@ -227,6 +265,19 @@ func (c *copyMethodMaker) GenerateMethodsFor(root *loader.Package, info *markers
 	// interfaces. maps, slices).
 	ptrReceiver := usePtrReceiver(typeInfo)
 
+	// generic types get methods on their own type parameters, e.g. `(in *Wrapper[T])`
+	typeName := info.Name
+	if named, isNamed := typeInfo.(*types.Named); isNamed {
+		typeName += loader.TypeParamList(named)
+	}
+
+	c.skippedFields = nil
+	for _, field := range info.Fields {
+		if field.Markers.Get(skipFieldMarker.Name) != nil {
+			c.skippedFields = append(c.skippedFields, field)
+		}
+	}
+
 	hasManualDeepCopyInto := hasDeepCopyIntoMethod(root, typeInfo)
 	hasManualDeepCopy, deepCopyOnPtr := hasDeepCopyMethod(root, typeInfo)
 
@@ -234,9 +285,9 @@ func (c *copyMethodMaker) GenerateMethodsFor(root *loader.Package, info *markers
 	if !hasManualDeepCopyInto {
 		c.Line("// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.")
 		if ptrReceiver {
-			c.Linef("func (in *%s) DeepCopyInto(out *%s) {", info.Name, info.Name)
+			c.Linef("func (in *%s) DeepCopyInto(out *%s) {", typeName, typeName)
 		} else {
-			c.Linef("func (in %s) DeepCopyInto(out *%s) {", info.Name, info.Name)
+			c.Linef("func (in %s) DeepCopyInto(out *%s) {", typeName, typeName)
 			c.Line("{in := &in") // add an extra block so that we can redefine `in` without type issues
 		}
 
@@ -248,8 +299,13 @@ func (c *copyMethodMaker) GenerateMethodsFor(root *loader.Package, info *markers
 			} else {
 				c.Line("*out = in.DeepCopy()")
 			}
+		} else if c.subpackage != nil {
+			// the implementation is generated into the subpackage, which
+			// registers it with this package
+			c.Linef("deepCopyFuncs().%s(in, out)", info.Name)
+			c.subpackage.generateFor(c, info.Name, typeInfo)
 		} else {
-			c.genDeepCopyIntoBlock(&namingInfo{nameOverride: info.Name}, typeInfo)
+			c.genDeepCopyIntoBlock(&namingInfo{nameOverride: typeName}, typeInfo)
 		}
 
 		if !ptrReceiver {
@@ -261,9 +317,9 @@ func (c *copyMethodMaker) GenerateMethodsFor(root *loader.Package, info *markers
 	if !hasManualDeepCopy {
 		// these are both straightforward, so we just template them out.
 		if ptrReceiver {
-			c.Linef(ptrDeepCopy, info.Name)
+			c.Linef(ptrDeepCopy, typeName)
 		} else {
-			c.Linef(bareDeepCopy, info.Name)
+			c.Linef(bareDeepCopy, typeName)
 		}
 
 		// maybe also generate DeepCopyObject, if asked.
@@ -271,9 +327,9 @@ func (c *copyMethodMaker) GenerateMethodsFor(root *loader.Package, info *markers
 			// we always need runtime.Object for DeepCopyObject
 			runtimeAlias := c.NeedImport("k8s.io/apimachinery/pkg/runtime")
 			if ptrReceiver {
-				c.Linef(ptrDeepCopyObj, info.Name, runtimeAlias)
+				c.Linef(ptrDeepCopyObj, typeName, runtimeAlias)
 			} else {
-				c.Linef(bareDeepCopyObj, info.Name, runtimeAlias)
+				c.Linef(bareDeepCopyObj, typeName, runtimeAlias)
 			}
 		}
 	}
@@ -329,8 +385,9 @@ func (c *copyMethodMaker) genDeepCopyIntoBlock(actualName *namingInfo, typeInfo
 // type is the given map type.
 func (c *copyMethodMaker) genMapDeepCopy(actualName *namingInfo, mapType *types.Map) {
 	// maps *must* have shallow-copiable types, since we just iterate
-	// through the keys, only trying to deepcopy the values.
-	if !fineToShallowCopy(mapType.Key()) {
+	// through the keys, only trying to deepcopy the values (keys of type
+	// parameters are copied like any key would be).
+	if _, isParam := mapType.Key().(*types.TypeParam); !isParam && !fineToShallowCopy(mapType.Key()) {
 		c.pkg.AddError(fmt.Errorf("invalid map key type: %s", mapType.Key()))
 		return
 	}
@@ -340,6 +397,13 @@ func (c *copyMethodMaker) genMapDeepCopy(actualName *namingInfo, mapType *types.
 
 	// ...and copy each element appropriately
 	c.For("key, val := range *in", func() {
+		if param, isParam := mapType.Elem().(*types.TypeParam); isParam {
+			c.Line("outVal := val")
+			c.genTypeParamDeepCopy(param, "val", "outVal")
+			c.Line("(*out)[key] = outVal")
+			return
+		}
+
 		// check if we have manually written methods,
 		// in which case we'll just try and use those
 		hasDeepCopy, copyOnPtr := hasDeepCopyMethod(c.pkg, mapType.Elem())
@@ -391,6 +455,12 @@ func (c *copyMethodMaker) genMapDeepCopy(actualName *namingInfo, mapType *types.
 			// otherwise...
 			switch underlyingElem := underlyingElem.(type) {
 			case *types.Struct:
+				if copyFunc := c.externalCopyFunc(mapType.Elem()); copyFunc != "" {
+					c.Linef("var outVal %s", (&namingInfo{typeInfo: mapType.Elem()}).Syntax(c.pkg, c.importsList))
+					c.Linef("%s(&val, &outVal)", copyFunc)
+					c.Line("(*out)[key] = outVal")
+					break
+				}
 				// structs will have deepcopy generated for them, so use that
 				c.Line("(*out)[key] = *val.DeepCopy()")
 			default:
@@ -409,6 +479,14 @@ func (c *copyMethodMaker) genSliceDeepCopy(actualName *namingInfo, sliceType *ty
 	// make the actual type (not the underlying)
 	c.Linef("*out = make(%[1]s, len(*in))", actualName.Syntax(c.pkg, c.importsList))
 
+	if param, isParam := sliceType.Elem().(*types.TypeParam); isParam {
+		c.Line("copy(*out, *in)")
+		c.For("i := range *in", func() {
+			c.genTypeParamDeepCopy(param, "(*in)[i]", "(*out)[i]")
+		})
+		return
+	}
+
 	// check if we need to do anything special, or just copy each element appropriately
 	switch {
 	case hasAnyDeepCopyMethod(c.pkg, sliceType.Elem()):
@@ -434,7 +512,7 @@ func (c *copyMethodMaker) genSliceDeepCopy(actualName *namingInfo, sliceType *ty
 			switch underlyingElem.(type) {
 			case *types.Struct:
 				// structs will always have deepcopy
-				c.Linef("(*in)[i].DeepCopyInto(&(*out)[i])")
+				c.Line(c.deepCopyIntoCall(sliceType.Elem(), "(*in)[i]", "&(*out)[i]"))
 			default:
 				c.pkg.AddError(fmt.Errorf("invalid slice element type: %s", underlyingElem))
 			}
@@ -448,6 +526,35 @@ func (c *copyMethodMaker) genStructDeepCopy(_ *namingInfo, structType *types.Str
 	c.Line("*out = *in")
 
 	for field := range structType.Fields() {
+		// the subpackage can't access private fields, so it's left with the
+		// initial assignment's shallow copy, when that's enough
+		if !field.Exported() && c.inSubpackage {
+			skipped, isSkipped := c.skippedField(field)
+			if isSkipped && skipped.Markers.Get(skipFieldMarker.Name).(SkipField).Mode == skipModeReference ||
+				!isSkipped && fineToShallowCopy(field.Type()) {
+				continue
+			}
+			c.pkg.AddError(loader.ErrFromNode(fmt.Errorf("cannot deepcopy unexported field %s from the %s subpackage", field.Name(), subpackageName), field))
+			return
+		}
+
+		if skipped, isSkipped := c.skippedField(field); isSkipped {
+			c.genSkippedField(field, skipped)
+			continue
+		}
+
+		// we can only get here for types in other packages when generating
+		// functions for external types, and can't access their private fields
+		if !field.Exported() && field.Pkg() != c.pkg.Types && !fineToShallowCopy(field.Type()) {
+			c.pkg.AddError(fmt.Errorf("cannot deepcopy unexported field %s of external type in package %s", field.Name(), field.Pkg().Path()))
+			return
+		}
+
+		if param, isParam := field.Type().(*types.TypeParam); isParam {
+			c.genTypeParamDeepCopy(param, "in."+field.Name(), "out."+field.Name())
+			continue
+		}
+
 		// if we have a manual deepcopy, use that
 		hasDeepCopy, copyOnPtr := hasDeepCopyMethod(c.pkg, field.Type())
 		hasDeepCopyInto := hasDeepCopyIntoMethod(c.pkg, field.Type())
@@ -503,7 +610,7 @@ func (c *copyMethodMaker) genStructDeepCopy(_ *namingInfo, structType *types.Str
 			if fineToShallowCopy(field.Type()) {
 				c.Linef("out.%[1]s = in.%[1]s", field.Name())
 			} else {
-				c.Linef("in.%[1]s.DeepCopyInto(&out.%[1]s)", field.Name())
+				c.Line(c.deepCopyIntoCall(field.Type(), "in."+field.Name(), "&out."+field.Name()))
 			}
 		default:
 			c.pkg.AddError(loader.ErrFromNode(fmt.Errorf("invalid field type: %s", underlyingField), field))
@@ -512,9 +619,61 @@ func (c *copyMethodMaker) genStructDeepCopy(_ *namingInfo, structType *types.Str
 	}
 }
 
+// skippedField returns the marker info for the given field if it's marked
+// with +deepcopy:skip.
+func (c *copyMethodMaker) skippedField(field *types.Var) (markers.FieldInfo, bool) {
+	for _, info := range c.skippedFields {
+		// NB: match on position, since embedded fields don't have names in FieldInfo
+		if info.RawField.Pos() <= field.Pos() && field.Pos() < info.RawField.End() {
+			return info, true
+		}
+	}
+	return markers.FieldInfo{}, false
+}
+
+// genSkippedField generates code for a field marked with +deepcopy:skip.  The
+// field has already been shallow-copied by the initial assignment.
+func (c *copyMethodMaker) genSkippedField(field *types.Var, info markers.FieldInfo) {
+	skip := info.Markers.Get(skipFieldMarker.Name).(SkipField)
+	switch skip.Mode {
+	case skipModeReference:
+		// nothing to do, initial assignment copied the reference
+	case "", skipModeZero:
+		c.Linef("out.%s = %s", field.Name(), zeroValue(c.pkg, c.importsList, field.Type()))
+	default:
+		c.pkg.AddError(loader.ErrFromNode(fmt.Errorf("invalid +deepcopy:skip mode %q for field %s, must be %q or %q",
+			skip.Mode, field.Name(), skipModeZero, skipModeReference), info.RawField))
+	}
+}
+
+// zeroValue returns an expression for the zero value of the given type.
+func zeroValue(pkg *loader.Package, imports *importsList, typeInfo types.Type) string {
+	switch underlying := eventualUnderlyingType(typeInfo).(type) {
+	case *types.Basic:
+		switch {
+		case underlying.Info()&types.IsBoolean != 0:
+			return "false"
+		case underlying.Info()&types.IsString != 0:
+			return `""`
+		case underlying.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+	case *types.Struct, *types.Array:
+		return (&namingInfo{typeInfo: typeInfo}).Syntax(pkg, imports) + "{}"
+	}
+	return "nil"
+}
+
 // genPointerDeepCopy generates DeepCopy code for the given named type whose
 // underlying type is the given struct.
 func (c *copyMethodMaker) genPointerDeepCopy(_ *namingInfo, pointerType *types.Pointer) {
+	if param, isParam := pointerType.Elem().(*types.TypeParam); isParam {
+		c.Linef("*out = new(%s)", param.Obj().Name())
+		c.Line("**out = **in")
+		c.genTypeParamDeepCopy(param, "(**in)", "(**out)")
+		return
+	}
+
 	underlyingElem := eventualUnderlyingType(pointerType.Elem())
 
 	// if we have a manually written deepcopy, just use that
@@ -558,6 +717,10 @@ func (c *copyMethodMaker) genPointerDeepCopy(_ *namingInfo, pointerType *types.P
 	switch underlyingElem := underlyingElem.(type) {
 	case *types.Struct:
 		c.Linef("*out = new(%[1]s)", (&namingInfo{typeInfo: pointerType.Elem()}).Syntax(c.pkg, c.importsList))
+		if copyFunc := c.externalCopyFunc(pointerType.Elem()); copyFunc != "" {
+			c.Linef("%s(*in, *out)", copyFunc)
+			break
+		}
 		c.Line("(*in).DeepCopyInto(*out)")
 	default:
 		c.pkg.AddError(fmt.Errorf("invalid pointer element type: %s", underlyingElem))
@@ -565,6 +728,20 @@ func (c *copyMethodMaker) genPointerDeepCopy(_ *namingInfo, pointerType *types.P
 	}
 }
 
+// genTypeParamDeepCopy generates code deep-copying the addressable expression
+// `in`, whose type is the given type parameter, into `out`, which already holds
+// a shallow copy of it.  Since the type argument is only known at runtime, its
+// DeepCopy or DeepCopyInto method (generated or manual) is used if it has one,
+// and the shallow copy is kept otherwise.
+func (c *copyMethodMaker) genTypeParamDeepCopy(param *types.TypeParam, in, out string) {
+	name := param.Obj().Name()
+	c.Linef("if copier, ok := any(%[2]s).(interface{ DeepCopy() %[1]s }); ok {", name, in)
+	c.Linef("%s = copier.DeepCopy()", out)
+	c.Linef("} else if copier, ok := any(&%[2]s).(interface{ DeepCopyInto(*%[1]s) }); ok {", name, in)
+	c.Linef("copier.DeepCopyInto(&%s)", out)
+	c.Line("}")
+}
+
 // usePtrReceiver checks if we need a pointer receiver on methods for the given type
 // Pass-by-reference types don't get pointer receivers.
 func usePtrReceiver(typeInfo types.Type) bool {
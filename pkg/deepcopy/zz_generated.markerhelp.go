@@ -40,6 +40,34 @@ func (Generator) Help() *markers.DefinitionHelp {
 				Summary: "specifies the year to substitute for \" YEAR\" in the header file.",
 				Details: "",
 			},
+			"Scheme": {
+				Summary: "enables generating scheme registration code (GroupVersion,",
+				Details: "SchemeBuilder and AddToScheme) into zz_generated.register.go, registering\nall root kinds of each package.\n\nThe group and version are taken from the +groupName and +versionName\npackage markers.  This replaces the scaffolded groupversion_info.go and\nthe per-type SchemeBuilder.Register calls.",
+			},
+			"ExternalTypes": {
+				Summary: "specifies a config file listing types from other packages",
+				Details: "(which can't be annotated with markers) to generate deepcopy functions for.\n\nThe functions are generated into a local sidecar package named in the\nconfig file, and are used by the generated code of all other packages\nwhenever they encounter one of the listed types, e.g.:\n\n\tpackage: example.com/myproject/api/thirdparty\n\ttypes:\n\t- github.com/some/dependency/api.Settings",
+			},
+			"Subpackage": {
+				Summary: "generates the DeepCopyInto implementations of each package",
+				Details: "into its \"generated\" subpackage, keeping only thin DeepCopyInto, DeepCopy\nand DeepCopyObject methods calling them in the package itself, e.g. so\nthat the generated code can be linted differently.\n\nSince the subpackage imports the package, it registers the\nimplementations with the package when imported, so programs (and\nexternal tests) using the deep copies of the package must import the\nsubpackage, e.g. with a blank import.  Generic types, and types with\nmanual DeepCopy or DeepCopyInto methods, keep their implementations in\nthe package, and types with unexported fields that can't be\nshallow-copied can't be copied from the subpackage.",
+			},
+		},
+	}
+}
+
+func (SkipField) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "object",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "skips deep-copying a field.",
+			Details: "This is useful for runtime-only fields (caches, channels, functions, etc.)\nthat can't or shouldn't be deep-copied, without having to hand-write\ndeepcopy for the whole type.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"Mode": {
+				Summary: "specifies what the field is set to in the copy.",
+				Details: "\"zero\" (the default) leaves the field as its zero value, while\n\"reference\" shares the original value with the copy.",
+			},
 		},
 	}
 }
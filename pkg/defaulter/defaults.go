@@ -0,0 +1,370 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaulter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"math"
+	"strconv"
+	"strings"
+
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	// kubebuilderDefaultMarker takes precedence over kubernetesDefaultMarker,
+	// as in the CRD schema.
+	kubebuilderDefaultMarker = "kubebuilder:default"
+	kubernetesDefaultMarker  = "default"
+)
+
+// defaulterFile collects the generated code of a single package.
+type defaulterFile struct {
+	pkg *loader.Package
+	// types are the types declared in the package, by name.
+	types map[string]*markers.TypeInfo
+	// imports are the paths of the imported packages.
+	imports map[string]struct{}
+
+	// funcs are the generated functions, by name.
+	funcs map[string][]byte
+	// needs caches whether the local types have defaults.
+	needs map[*types.Named]bool
+	// failed indicates that some code couldn't be generated, so the file
+	// shouldn't be written.
+	failed bool
+}
+
+// lookupNamed returns the named type with the given name in the package, if
+// any.
+func (f *defaulterFile) lookupNamed(name string) *types.Named {
+	typeName, isType := f.pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !isType {
+		return nil
+	}
+	named, _ := typeName.Type().(*types.Named)
+	return named
+}
+
+// isLocalStruct checks if the given type is a struct type declared in the
+// package.
+func (f *defaulterFile) isLocalStruct(typ types.Type) (*types.Named, bool) {
+	named, isNamed := typ.(*types.Named)
+	if !isNamed || named.Obj().Pkg() != f.pkg.Types {
+		return nil, false
+	}
+	_, isStruct := named.Underlying().(*types.Struct)
+	return named, isStruct
+}
+
+// funcName returns the name of the defaulting function of the given type.
+func funcName(named *types.Named) string {
+	return "SetDefaults_" + named.Obj().Name()
+}
+
+// needsDefaults checks if values of the given type have defaults to set,
+// either from the markers of their fields (recursively), or from a
+// hand-written defaulting function.
+func (f *defaulterFile) needsDefaults(typ types.Type) bool {
+	if named, isLocal := f.isLocalStruct(typ); isLocal {
+		if needs, known := f.needs[named]; known {
+			return needs
+		}
+		// assume no defaults while checking, in case of recursive types
+		f.needs[named] = false
+
+		needs := f.pkg.Types.Scope().Lookup(funcName(named)) != nil
+		structType := named.Underlying().(*types.Struct)
+		fieldInfos := f.fields(named)
+		for i := 0; i < structType.NumFields() && !needs; i++ {
+			field := structType.Field(i)
+			if !field.Exported() {
+				continue
+			}
+			needs = (i < len(fieldInfos) && defaultValue(fieldInfos[i].Markers) != nil) || f.needsDefaults(field.Type())
+		}
+		f.needs[named] = needs
+		return needs
+	}
+
+	switch underlying := typ.Underlying().(type) {
+	case *types.Pointer:
+		return f.needsDefaults(underlying.Elem())
+	case *types.Slice:
+		return f.needsDefaults(underlying.Elem())
+	case *types.Array:
+		return f.needsDefaults(underlying.Elem())
+	case *types.Map:
+		return f.needsDefaults(underlying.Elem())
+	default:
+		return false
+	}
+}
+
+// fields returns the marker information of the fields of the given local
+// struct type, in the same order as the fields of the struct.
+func (f *defaulterFile) fields(named *types.Named) []markers.FieldInfo {
+	info := f.types[named.Obj().Name()]
+	if info == nil {
+		return nil
+	}
+	return info.Fields
+}
+
+// defaultValue returns the default value declared by the given field
+// markers, or nil if there's none.
+func defaultValue(fieldMarkers markers.MarkerValues) any {
+	switch value := fieldMarkers.Get(kubebuilderDefaultMarker).(type) {
+	case crdmarkers.Default:
+		if value.Value != nil {
+			return value.Value
+		}
+	}
+	switch value := fieldMarkers.Get(kubernetesDefaultMarker).(type) {
+	case crdmarkers.KubernetesDefault:
+		return value.Value
+	case *crdmarkers.KubernetesDefault:
+		return value.Value
+	}
+	return nil
+}
+
+// setDefaultsFunc returns the name of the defaulting function of the given
+// local struct type, generating it unless it's hand-written.
+func (f *defaulterFile) setDefaultsFunc(named *types.Named) string {
+	name := funcName(named)
+	if _, exists := f.funcs[name]; exists {
+		return name
+	}
+	if f.pkg.Types.Scope().Lookup(name) != nil {
+		// hand-written
+		return name
+	}
+
+	// reserve the name before generating, in case of recursive types
+	f.funcs[name] = nil
+
+	outContent := new(bytes.Buffer)
+	c := &gogen.CodeWriter{Out: outContent}
+	typeName := named.Obj().Name()
+	c.Linef("// %s sets the defaults declared by the markers of the fields of a %s.", name, typeName)
+	c.Linef("func %s(in *%s) {", name, typeName)
+	structType := named.Underlying().(*types.Struct)
+	fieldInfos := f.fields(named)
+	for i := range structType.NumFields() {
+		field := structType.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		expr := "in." + field.Name()
+		if i < len(fieldInfos) {
+			if value := defaultValue(fieldInfos[i].Markers); value != nil {
+				if err := f.setDefault(c, expr, field.Type(), value); err != nil {
+					f.pkg.AddError(loader.ErrFromNode(fmt.Errorf("unable to default field %s of %s: %w", field.Name(), typeName, err), field))
+					f.failed = true
+				}
+			}
+		}
+		f.defaultWithin(c, expr, field.Type(), 0)
+	}
+	c.Line("}")
+	c.Line("")
+	f.funcs[name] = outContent.Bytes()
+	return name
+}
+
+// setDefault writes code setting the given expression of the given type to
+// the given default value if it's unset.
+func (f *defaulterFile) setDefault(c *gogen.CodeWriter, expr string, typ types.Type, value any) error {
+	if basic, isBasic := typ.Underlying().(*types.Basic); isBasic {
+		literal, zero, err := basicLiteral(basic, value)
+		if err != nil {
+			return err
+		}
+		unset := fmt.Sprintf("%s == %s", expr, zero)
+		if zero == "false" {
+			unset = "!" + expr
+		}
+		c.If(unset, func() {
+			c.Linef("%s = %s", expr, literal)
+		})
+		return nil
+	}
+
+	var unset string
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface:
+		unset = expr + " == nil"
+	case *types.Struct, *types.Array:
+		f.imports["reflect"] = struct{}{}
+		unset = fmt.Sprintf("reflect.ValueOf(%s).IsZero()", expr)
+	default:
+		return fmt.Errorf("unsupported type %s", typ)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	f.imports["encoding/json"] = struct{}{}
+	c.If(unset, func() {
+		c.Linef("if err := json.Unmarshal([]byte(%s), %s); err != nil {", goString(string(raw)), addr(expr))
+		c.Line("panic(err)")
+		c.Line("}")
+	})
+	return nil
+}
+
+// goString returns a Go string literal for the given string, preferring raw
+// string literals for readability.
+func goString(s string) string {
+	if !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// basicLiteral returns the Go literal for the given default value of the
+// given basic type, along with the literal of the zero value of the type.
+func basicLiteral(basic *types.Basic, value any) (literal, zero string, err error) {
+	info := basic.Info()
+	switch {
+	case info&types.IsString != 0:
+		str, isStr := value.(string)
+		if !isStr {
+			return "", "", fmt.Errorf("expected a string default, got %T", value)
+		}
+		return strconv.Quote(str), `""`, nil
+	case info&types.IsBoolean != 0:
+		boolean, isBool := value.(bool)
+		if !isBool {
+			return "", "", fmt.Errorf("expected a boolean default, got %T", value)
+		}
+		return strconv.FormatBool(boolean), "false", nil
+	case info&types.IsInteger != 0:
+		switch number := value.(type) {
+		case int:
+			return strconv.Itoa(number), "0", nil
+		case int64:
+			return strconv.FormatInt(number, 10), "0", nil
+		case float64:
+			if number != math.Trunc(number) {
+				return "", "", fmt.Errorf("expected an integer default, got %v", number)
+			}
+			return strconv.FormatFloat(number, 'f', -1, 64), "0", nil
+		}
+		return "", "", fmt.Errorf("expected an integer default, got %T", value)
+	case info&types.IsFloat != 0:
+		switch number := value.(type) {
+		case int:
+			return strconv.Itoa(number), "0", nil
+		case int64:
+			return strconv.FormatInt(number, 10), "0", nil
+		case float64:
+			return strconv.FormatFloat(number, 'g', -1, 64), "0", nil
+		}
+		return "", "", fmt.Errorf("expected a numeric default, got %T", value)
+	default:
+		return "", "", fmt.Errorf("unsupported type %s", basic)
+	}
+}
+
+// addr returns the syntax for taking the address of the given (addressable)
+// expression.
+func addr(expr string) string {
+	if strings.HasPrefix(expr, "(*") && strings.HasSuffix(expr, ")") {
+		return expr[2 : len(expr)-1]
+	}
+	return "&" + expr
+}
+
+// loopVars returns the names of the loop variables at the given nesting
+// depth.
+func loopVars(depth int) (index, key, val string) {
+	if depth == 0 {
+		return "i", "key", "val"
+	}
+	suffix := strconv.Itoa(depth + 1)
+	return "i" + suffix, "key" + suffix, "val" + suffix
+}
+
+// defaultWithin writes code setting the defaults within the value of the
+// given expression of the given type, i.e. of the fields of structs, and the
+// elements of pointers, slices and maps.
+func (f *defaulterFile) defaultWithin(c *gogen.CodeWriter, expr string, typ types.Type, depth int) {
+	if !f.needsDefaults(typ) {
+		return
+	}
+	if named, isLocal := f.isLocalStruct(typ); isLocal {
+		c.Linef("%s(%s)", f.setDefaultsFunc(named), addr(expr))
+		return
+	}
+
+	index, key, val := loopVars(depth)
+	switch underlying := typ.Underlying().(type) {
+	case *types.Pointer:
+		c.If(expr+" != nil", func() {
+			f.defaultWithin(c, "(*"+expr+")", underlying.Elem(), depth)
+		})
+	case *types.Slice:
+		c.For(fmt.Sprintf("%s := range %s", index, expr), func() {
+			f.defaultWithin(c, fmt.Sprintf("%s[%s]", expr, index), underlying.Elem(), depth+1)
+		})
+	case *types.Array:
+		c.For(fmt.Sprintf("%s := range %s", index, expr), func() {
+			f.defaultWithin(c, fmt.Sprintf("%s[%s]", expr, index), underlying.Elem(), depth+1)
+		})
+	case *types.Map:
+		switch underlying.Elem().Underlying().(type) {
+		case *types.Struct, *types.Array:
+			// map values aren't addressable, so default a copy and write it back
+			c.For(fmt.Sprintf("%s, %s := range %s", key, val, expr), func() {
+				f.defaultWithin(c, val, underlying.Elem(), depth+1)
+				c.Linef("%s[%s] = %s", expr, key, val)
+			})
+		default:
+			c.For(fmt.Sprintf("_, %s := range %s", val, expr), func() {
+				f.defaultWithin(c, val, underlying.Elem(), depth+1)
+			})
+		}
+	}
+}
+
+// generateRegisterDefaults generates the RegisterDefaults function, adding
+// the defaulting functions of the given kinds to a scheme.
+func (f *defaulterFile) generateRegisterDefaults(kinds []string) {
+	f.imports[runtimePath] = struct{}{}
+
+	outContent := new(bytes.Buffer)
+	c := &gogen.CodeWriter{Out: outContent}
+	c.Line("// RegisterDefaults adds the defaulting functions of the kinds of this package")
+	c.Line("// to the given scheme, for use with Scheme.Default.")
+	c.Line("func RegisterDefaults(scheme *runtime.Scheme) error {")
+	for _, kind := range kinds {
+		c.Linef("scheme.AddTypeDefaultingFunc(&%[1]s{}, func(obj any) { %[2]s(obj.(*%[1]s)) })", kind, "SetDefaults_"+kind)
+	}
+	c.Line("return nil")
+	c.Line("}")
+	c.Line("")
+	f.funcs["RegisterDefaults"] = outContent.Bytes()
+}
@@ -0,0 +1,29 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaulter generates functions applying the defaults declared with
+// +kubebuilder:default and +default markers to Go objects.
+//
+// The same markers set the defaults in the CRD schemas, so objects defaulted
+// by a controller or a webhook with the generated functions are guaranteed to
+// be defaulted the same way as by the API server.
+//
+// For each root kind (and each type it uses that has defaults), a
+// SetDefaults_<Type> function is generated alongside the type, along with a
+// RegisterDefaults function adding them to a runtime.Scheme.  As with
+// defaulter-gen, any of the functions can be hand-written instead, in which
+// case the hand-written function is called by the generated code.
+package defaulter
@@ -0,0 +1,208 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaulter
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing defaulting functions, we only see hand-written
+// ones.
+
+const (
+	runtimePath = "k8s.io/apimachinery/pkg/runtime"
+	// outputFileName is the name of the generated file in each package.
+	outputFileName = "zz_generated.defaults.go"
+)
+
+var isObjectMarker = markers.Must(markers.MakeDefinition("kubebuilder:object:root", markers.DescribesType, false))
+
+// +controllertools:marker:generateHelp
+
+// Generator generates functions setting the defaults declared with
+// +kubebuilder:default and +default markers.
+//
+// A SetDefaults_<Type> function is generated for each root kind (and each
+// type it uses that has defaults), along with a RegisterDefaults function
+// adding them to a runtime.Scheme, into zz_generated.defaults.go.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return func(node ast.Node) bool {
+		// ignore interfaces
+		_, isIface := node.(*ast.InterfaceType)
+		return !isIface
+	}
+}
+
+// IncrementalRoots returns the affected roots, since their defaulting
+// functions are generated per package.
+func (Generator) IncrementalRoots(_ *genall.GenerationContext, affected []*loader.Package) []*loader.Package {
+	return affected
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := crdmarkers.Register(into); err != nil {
+		return err
+	}
+	if err := into.Register(isObjectMarker); err != nil {
+		return err
+	}
+	into.AddHelp(isObjectMarker,
+		markers.SimpleHelp("object", "enables object interface implementation generation for this type"))
+	return nil
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range ctx.Roots {
+		outContents := generateForPackage(ctx, root, headerText)
+		if outContents == nil {
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, outContents)
+	}
+
+	return nil
+}
+
+// generateForPackage generates the defaulting functions of the root kinds of
+// the given package.  May return nil if no kind has defaults, or source could
+// not be generated.
+func generateForPackage(ctx *genall.GenerationContext, root *loader.Package, headerText string) []byte {
+	ctx.Checker.Check(root)
+	root.NeedTypesInfo()
+
+	file := &defaulterFile{
+		pkg:     root,
+		types:   make(map[string]*markers.TypeInfo),
+		imports: make(map[string]struct{}),
+		funcs:   make(map[string][]byte),
+		needs:   make(map[*types.Named]bool),
+	}
+	var kinds []string
+	if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+		file.types[info.Name] = info
+		if isObject, _ := info.Markers.Get(isObjectMarker.Name).(bool); isObject {
+			kinds = append(kinds, info.Name)
+		}
+	}); err != nil {
+		root.AddError(err)
+		return nil
+	}
+	slices.Sort(kinds)
+
+	var registered []string
+	for _, kind := range kinds {
+		named := file.lookupNamed(kind)
+		if named == nil || !file.needsDefaults(named) {
+			continue
+		}
+		file.setDefaultsFunc(named)
+		if isKind(root, named) {
+			registered = append(registered, kind)
+		}
+	}
+	if len(registered) > 0 && root.Types.Scope().Lookup("RegisterDefaults") == nil {
+		file.generateRegisterDefaults(registered)
+	}
+
+	if file.failed || len(file.funcs) == 0 {
+		return nil
+	}
+	return file.contents(headerText)
+}
+
+// isKind checks if the given type can be registered with a scheme, that is,
+// if (a pointer to) it has a GetObjectKind method, usually via an embedded
+// TypeMeta.
+func isKind(pkg *loader.Package, named *types.Named) bool {
+	method, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, pkg.Types, "GetObjectKind")
+	_, isFunc := method.(*types.Func)
+	return isFunc
+}
+
+// contents returns the formatted contents of the file.
+func (f *defaulterFile) contents(headerText string) []byte {
+	// standard library imports go first, as with goimports
+	var stdImports, otherImports []string
+	for importPath := range f.imports {
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			otherImports = append(otherImports, strconv.Quote(importPath))
+		} else {
+			stdImports = append(stdImports, strconv.Quote(importPath))
+		}
+	}
+	slices.Sort(stdImports)
+	slices.Sort(otherImports)
+	var importGroups []string
+	for _, group := range [][]string{stdImports, otherImports} {
+		if len(group) > 0 {
+			importGroups = append(importGroups, strings.Join(group, "\n"))
+		}
+	}
+	var importsBlock string
+	if len(importGroups) > 0 {
+		importsBlock = fmt.Sprintf("import (\n%s\n)\n", strings.Join(importGroups, "\n\n"))
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[3]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+%[2]s
+`, f.pkg.Name, importsBlock, headerText)
+
+	names := make([]string, 0, len(f.funcs))
+	for name := range f.funcs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		outContent.Write(f.funcs[name])
+	}
+
+	return gogen.Format(f.pkg, outContent.Bytes())
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Protocol is a network protocol.
+type Protocol string
+
+// Port is a network port.
+type Port struct {
+	Name string `json:"name"`
+
+	// +kubebuilder:default=80
+	Number int32 `json:"number,omitempty"`
+
+	// +kubebuilder:default=TCP
+	Protocol Protocol `json:"protocol,omitempty"`
+}
+
+// Probe is a health check.
+type Probe struct {
+	// +kubebuilder:default="/healthz"
+	Path string `json:"path,omitempty"`
+
+	// +default=10.5
+	PeriodSeconds float64 `json:"periodSeconds,omitempty"`
+}
+
+// Limits has its defaults set by a hand-written function.
+type Limits struct {
+	CPU string `json:"cpu,omitempty"`
+}
+
+// WidgetSpec is the spec of a Widget.
+type WidgetSpec struct {
+	// +kubebuilder:default=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// +kubebuilder:default=true
+	Enabled bool `json:"enabled,omitempty"`
+
+	// +kubebuilder:default={"a","b"}
+	Tags []string `json:"tags,omitempty"`
+
+	// +default={"tier": "frontend"}
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +kubebuilder:default={}
+	Probe *Probe `json:"probe,omitempty"`
+
+	// +kubebuilder:default={name: "http"}
+	Primary Port `json:"primary,omitempty"`
+
+	Ports      []Port          `json:"ports,omitempty"`
+	NamedPorts map[string]Port `json:"namedPorts,omitempty"`
+	Limits     Limits          `json:"limits,omitempty"`
+
+	// Description has no default.
+	Description string `json:"description,omitempty"`
+}
+
+// Widget is a kind with defaults.
+// +kubebuilder:object:root=true
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WidgetSpec `json:"spec,omitempty"`
+}
+
+// WidgetList contains a list of Widgets.
+// +kubebuilder:object:root=true
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
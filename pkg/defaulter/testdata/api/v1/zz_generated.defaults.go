@@ -0,0 +1,112 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RegisterDefaults adds the defaulting functions of the kinds of this package
+// to the given scheme, for use with Scheme.Default.
+func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&Widget{}, func(obj any) { SetDefaults_Widget(obj.(*Widget)) })
+	scheme.AddTypeDefaultingFunc(&WidgetList{}, func(obj any) { SetDefaults_WidgetList(obj.(*WidgetList)) })
+	return nil
+}
+
+// SetDefaults_Port sets the defaults declared by the markers of the fields of a Port.
+func SetDefaults_Port(in *Port) {
+	if in.Number == 0 {
+		in.Number = 80
+	}
+	if in.Protocol == "" {
+		in.Protocol = "TCP"
+	}
+}
+
+// SetDefaults_Probe sets the defaults declared by the markers of the fields of a Probe.
+func SetDefaults_Probe(in *Probe) {
+	if in.Path == "" {
+		in.Path = "/healthz"
+	}
+	if in.PeriodSeconds == 0 {
+		in.PeriodSeconds = 10.5
+	}
+}
+
+// SetDefaults_Widget sets the defaults declared by the markers of the fields of a Widget.
+func SetDefaults_Widget(in *Widget) {
+	SetDefaults_WidgetSpec(&in.Spec)
+}
+
+// SetDefaults_WidgetList sets the defaults declared by the markers of the fields of a WidgetList.
+func SetDefaults_WidgetList(in *WidgetList) {
+	for i := range in.Items {
+		SetDefaults_Widget(&in.Items[i])
+	}
+}
+
+// SetDefaults_WidgetSpec sets the defaults declared by the markers of the fields of a WidgetSpec.
+func SetDefaults_WidgetSpec(in *WidgetSpec) {
+	if in.Replicas == nil {
+		if err := json.Unmarshal([]byte(`1`), &in.Replicas); err != nil {
+			panic(err)
+		}
+	}
+	if !in.Enabled {
+		in.Enabled = true
+	}
+	if in.Tags == nil {
+		if err := json.Unmarshal([]byte(`["a","b"]`), &in.Tags); err != nil {
+			panic(err)
+		}
+	}
+	if in.Labels == nil {
+		if err := json.Unmarshal([]byte(`{"tier":"frontend"}`), &in.Labels); err != nil {
+			panic(err)
+		}
+	}
+	if in.Probe == nil {
+		if err := json.Unmarshal([]byte(`{}`), &in.Probe); err != nil {
+			panic(err)
+		}
+	}
+	if in.Probe != nil {
+		SetDefaults_Probe(in.Probe)
+	}
+	if reflect.ValueOf(in.Primary).IsZero() {
+		if err := json.Unmarshal([]byte(`{"name":"http"}`), &in.Primary); err != nil {
+			panic(err)
+		}
+	}
+	SetDefaults_Port(&in.Primary)
+	for i := range in.Ports {
+		SetDefaults_Port(&in.Ports[i])
+	}
+	for key, val := range in.NamedPorts {
+		SetDefaults_Port(&val)
+		in.NamedPorts[key] = val
+	}
+	SetDefaults_Limits(&in.Limits)
+}
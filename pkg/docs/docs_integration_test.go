@@ -0,0 +1,67 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/docs"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+)
+
+var _ = Describe("Docs Generation", func() {
+	It("should generate markdown API reference documentation", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{docs.Generator{}}, "./api/...")
+		golden.CompareFiles(GinkgoT(), filepath.Join("testdata", "docs"), out, "testdata.kubebuilder.io_v1.md", "testdata.kubebuilder.io_v1alpha1.md")
+	})
+
+	It("should generate HTML API reference documentation", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{docs.Generator{Format: "html"}}, "./api/...")
+		golden.CompareFiles(GinkgoT(), filepath.Join("testdata", "docs"), out, "testdata.kubebuilder.io_v1.html", "testdata.kubebuilder.io_v1alpha1.html")
+	})
+
+	It("should replace the descriptions from the description catalog", func() {
+		By("writing the catalog")
+		catalog := filepath.Join(GinkgoT().TempDir(), "descriptions.yaml")
+		Expect(os.WriteFile(catalog, []byte(`descriptions:
+- package: testdata.kubebuilder.io/docs/api/v1
+  type: WidgetSpec
+  description: The desired state of a Widget, described by the catalog.
+  fields:
+    MaxReplicas: The most replicas a Widget can have, described by the catalog.
+`), 0o644)).To(Succeed())
+
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{docs.Generator{Descriptions: catalog}}, "./api/...")
+
+		By("checking the descriptions")
+		contents, err := os.ReadFile(filepath.Join(out, "testdata.kubebuilder.io_v1.md"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("The desired state of a Widget, described by the catalog."))
+		Expect(string(contents)).To(ContainSubstring("The most replicas a Widget can have, described by the catalog."))
+		Expect(string(contents)).To(ContainSubstring("MinReplicas is the minimum number of replicas."))
+		Expect(string(contents)).NotTo(ContainSubstring("MaxReplicas is the maximum number of replicas."))
+	})
+
+	It("should fail on unknown formats", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{docs.Generator{Format: "pdf"}}, "./api/...")
+		Expect(errOut).To(ContainSubstring(`unknown documentation format "pdf", expected markdown or html`))
+	})
+})
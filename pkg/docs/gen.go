@@ -0,0 +1,401 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates API reference documentation for the kinds and types of
+// each API package.
+//
+// One document is written per group-version, named <group>_<version>.md (or
+// .html), listing the fields of each type with their descriptions, defaults
+// and validation.  Types of other packages are linked to their documentation
+// on pkg.go.dev, unless they're documented as well.
+type Generator struct {
+	// Format specifies the format of the documentation, either "markdown"
+	// (the default) or "html".
+	Format string `marker:",optional"`
+
+	// Descriptions specifies a catalog file replacing the descriptions of
+	// types and fields, as with the crd generator (see
+	// crd.DescriptionCatalog).
+	Descriptions string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	var render func(out io.Writer, doc *groupVersionDoc) error
+	var ext string
+	switch g.Format {
+	case "", "markdown":
+		render, ext = renderMarkdown, ".md"
+	case "html":
+		render, ext = renderHTML, ".html"
+	default:
+		return fmt.Errorf("unknown documentation format %q, expected markdown or html", g.Format)
+	}
+
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// documentation shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	if g.Descriptions != "" {
+		contents, err := ctx.ReadFile(g.Descriptions)
+		if err != nil {
+			return err
+		}
+		if parser.Descriptions, err = crd.LoadDescriptionCatalog(contents); err != nil {
+			return err
+		}
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	b := &builder{
+		parser:      parser,
+		ext:         ext,
+		docs:        make(map[*loader.Package]*groupVersionDoc),
+		rootsByPath: make(map[string]*loader.Package),
+	}
+	for _, root := range ctx.Roots {
+		gv, known := parser.GroupVersions[root]
+		if !known || gv.Group == "" || gv.Version == "" {
+			// not an API package
+			continue
+		}
+		b.docs[root] = &groupVersionDoc{
+			GroupVersion: gv.String(),
+			FileName:     gv.Group + "_" + gv.Version + ext,
+			Doc:          packageDoc(root),
+		}
+		b.rootsByPath[root.PkgPath] = root
+	}
+
+	var kinds map[crd.TypeIdent]struct{}
+	if metav1Pkg := crd.FindMetav1(ctx.Roots); metav1Pkg != nil {
+		kinds = make(map[crd.TypeIdent]struct{})
+		for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+			for root := range b.docs {
+				if parser.GroupVersions[root].Group == groupKind.Group {
+					kinds[crd.TypeIdent{Package: root, Name: groupKind.Kind}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		doc, exists := b.docs[root]
+		if !exists {
+			continue
+		}
+		b.documentPackage(root, doc, kinds)
+	}
+
+	for _, root := range ctx.Roots {
+		doc, exists := b.docs[root]
+		if !exists || len(doc.Types) == 0 {
+			continue
+		}
+		outContent := new(bytes.Buffer)
+		if err := render(outContent, doc); err != nil {
+			root.AddError(err)
+			continue
+		}
+		writeOut(ctx, doc.FileName, outContent.Bytes(), root)
+	}
+
+	return nil
+}
+
+// packageDoc returns the package documentation of the given package, without
+// its markers.
+func packageDoc(pkg *loader.Package) string {
+	for _, file := range pkg.Syntax {
+		if file.Doc == nil {
+			continue
+		}
+		if doc := strings.TrimSpace(markers.WithoutMarkers(file.Doc).Text()); doc != "" {
+			return doc
+		}
+	}
+	return ""
+}
+
+// builder builds the documentation of the API packages.
+type builder struct {
+	parser *crd.Parser
+	// ext is the extension of the documentation files.
+	ext string
+	// docs are the documentation of the API packages, by package.
+	docs map[*loader.Package]*groupVersionDoc
+	// rootsByPath are the documented packages, by path.
+	rootsByPath map[string]*loader.Package
+}
+
+// documentPackage fills the documentation of the types of the given package.
+func (b *builder) documentPackage(pkg *loader.Package, doc *groupVersionDoc, kinds map[crd.TypeIdent]struct{}) {
+	var typeNames []string
+	for ident := range b.parser.Types {
+		if ident.Package == pkg && ast.IsExported(ident.Name) {
+			typeNames = append(typeNames, ident.Name)
+		}
+	}
+	slices.Sort(typeNames)
+
+	appearsIn := make(map[string][]string)
+	for _, name := range typeNames {
+		ident := crd.TypeIdent{Package: pkg, Name: name}
+		typeDoc := b.documentType(ident, appearsIn)
+		if typeDoc == nil {
+			continue
+		}
+		if _, isKind := kinds[ident]; isKind {
+			doc.Kinds = append(doc.Kinds, name)
+		}
+		doc.Types = append(doc.Types, typeDoc)
+	}
+
+	for _, typeDoc := range doc.Types {
+		users := appearsIn[typeDoc.Name]
+		slices.Sort(users)
+		typeDoc.AppearsIn = slices.Compact(users)
+	}
+}
+
+// documentType returns the documentation of the given type, recording the
+// types it refers to in the given map, or nil if the type can't be
+// represented in JSON.
+func (b *builder) documentType(ident crd.TypeIdent, appearsIn map[string][]string) *typeDoc {
+	info := b.parser.Types[ident]
+	typeName, isType := ident.Package.Types.Scope().Lookup(ident.Name).(*types.TypeName)
+	if !isType {
+		return nil
+	}
+	switch typeName.Type().Underlying().(type) {
+	case *types.Signature, *types.Chan, *types.Interface:
+		return nil
+	}
+
+	b.parser.NeedFlattenedSchemaFor(ident)
+	schema := b.parser.FlattenedSchemata[ident]
+
+	typeDoc := &typeDoc{
+		Name:       ident.Name,
+		Doc:        info.Doc,
+		Validation: validationRules(&schema),
+	}
+	if _, isStruct := typeName.Type().Underlying().(*types.Struct); !isStruct {
+		underlying := b.typeRef(ident.Package, ident.Name, typeName.Type().Underlying(), appearsIn)
+		typeDoc.Underlying = &underlying
+		return typeDoc
+	}
+
+	typeDoc.Fields = b.fieldDocs(ident, info, &schema, appearsIn)
+	return typeDoc
+}
+
+// fieldDocs returns the documentation of the fields of the given struct type,
+// expanding embedded structs, with the given (flattened) schema.
+func (b *builder) fieldDocs(owner crd.TypeIdent, info *markers.TypeInfo, schema *apiextensionsv1.JSONSchemaProps, appearsIn map[string][]string) []fieldDoc {
+	pkg := owner.Package
+	var fields []fieldDoc
+	for _, field := range info.Fields {
+		jsonTag, hasTag := field.Tag.Lookup("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(jsonTag, ",")
+		typ := pkg.TypesInfo.TypeOf(field.RawField.Type)
+
+		if field.Name == "" && (name == "" || slices.Contains(strings.Split(opts, ","), "inline")) {
+			named, isNamed := typ.(*types.Named)
+			switch {
+			case isNamed && isMetav1(named, "TypeMeta"):
+				gv := b.parser.GroupVersions[pkg]
+				fields = append(fields,
+					fieldDoc{Name: "apiVersion", Type: typeRef{Name: "string"}, Doc: "`" + gv.String() + "`"},
+					fieldDoc{Name: "kind", Type: typeRef{Name: "string"}, Doc: "`" + owner.Name + "`"},
+				)
+				continue
+			case isNamed && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkg.PkgPath:
+				embedded := b.parser.Types[crd.TypeIdent{Package: pkg, Name: named.Obj().Name()}]
+				if embedded != nil {
+					fields = append(fields, b.fieldDocs(owner, embedded, schema, appearsIn)...)
+					continue
+				}
+			}
+		}
+
+		if field.Name != "" && !ast.IsExported(field.Name) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+			if !hasTag && name == "" {
+				name = types.TypeString(typ, func(*types.Package) string { return "" })
+			}
+		}
+
+		doc := fieldDoc{
+			Name: name,
+			Type: b.typeRef(pkg, owner.Name, typ, appearsIn),
+			Doc:  field.Doc,
+		}
+		if named, isNamed := deref(typ).(*types.Named); isNamed && doc.Doc == "" && (isMetav1(named, "ObjectMeta") || isMetav1(named, "ListMeta")) {
+			doc.Doc = fmt.Sprintf("Refer to the Kubernetes API documentation for the fields of `%s`.", name)
+		}
+		if prop, hasSchema := schema.Properties[name]; hasSchema {
+			if prop.Default != nil {
+				doc.Default = string(prop.Default.Raw)
+			}
+			required := "Optional"
+			if slices.Contains(schema.Required, name) {
+				required = "Required"
+			}
+			doc.Validation = append([]string{required}, validationRules(&prop)...)
+		}
+		fields = append(fields, doc)
+	}
+	return fields
+}
+
+// deref returns the type pointed to by the given type, if it's a pointer.
+func deref(typ types.Type) types.Type {
+	if ptr, isPtr := typ.(*types.Pointer); isPtr {
+		return ptr.Elem()
+	}
+	return typ
+}
+
+// isMetav1 checks if the given type is the given type of metav1.
+func isMetav1(named *types.Named, name string) bool {
+	obj := named.Obj()
+	return obj.Pkg() != nil && loader.NonVendorPath(obj.Pkg().Path()) == "k8s.io/apimachinery/pkg/apis/meta/v1" && obj.Name() == name
+}
+
+// typeRef returns the reference to the given type, used by the given type of
+// the given package.
+func (b *builder) typeRef(pkg *loader.Package, user string, typ types.Type, appearsIn map[string][]string) typeRef {
+	switch typ := typ.(type) {
+	case *types.Pointer:
+		return b.typeRef(pkg, user, typ.Elem(), appearsIn)
+	case *types.Slice:
+		if elem, isBasic := typ.Elem().(*types.Basic); isBasic && elem.Kind() == types.Byte {
+			// byte slices are base64-encoded strings
+			return typeRef{Name: "string"}
+		}
+		ref := b.typeRef(pkg, user, typ.Elem(), appearsIn)
+		ref.Array = true
+		return ref
+	case *types.Array:
+		ref := b.typeRef(pkg, user, typ.Elem(), appearsIn)
+		ref.Array = true
+		return ref
+	case *types.Map:
+		ref := b.typeRef(pkg, user, typ.Elem(), appearsIn)
+		key := b.typeRef(pkg, user, typ.Key(), appearsIn)
+		ref.MapKey = key.Name
+		return ref
+	case *types.Basic:
+		return typeRef{Name: basicName(typ)}
+	case *types.Named:
+		obj := typ.Obj()
+		if obj.Pkg() == nil {
+			// e.g. error
+			return typeRef{Name: obj.Name()}
+		}
+		pkgPath := loader.NonVendorPath(obj.Pkg().Path())
+		if pkgPath == pkg.PkgPath {
+			appearsIn[obj.Name()] = append(appearsIn[obj.Name()], user)
+		}
+		if root, documented := b.rootsByPath[pkgPath]; documented {
+			link := "#" + anchor(obj.Name())
+			if root != pkg {
+				link = b.docs[root].FileName + link
+			}
+			return typeRef{Name: obj.Name(), Link: link}
+		}
+		return typeRef{Name: obj.Name(), Link: "https://pkg.go.dev/" + pkgPath + "#" + obj.Name()}
+	default:
+		return typeRef{Name: "object"}
+	}
+}
+
+// basicName returns the JSON name of the given basic type.
+func basicName(basic *types.Basic) string {
+	info := basic.Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		return "boolean"
+	case info&types.IsInteger != 0:
+		return "integer"
+	case info&types.IsFloat != 0:
+		return "float"
+	case info&types.IsString != 0:
+		return "string"
+	}
+	return basic.Name()
+}
+
+// writeOut outputs the given documentation to the given file.
+func writeOut(ctx *genall.GenerationContext, fileName string, outBytes []byte, root *loader.Package) {
+	outputFile, err := ctx.Open(nil, fileName)
+	if err != nil {
+		root.AddError(err)
+		return
+	}
+	defer outputFile.Close()
+	n, err := outputFile.Write(outBytes)
+	if err != nil {
+		root.AddError(err)
+		return
+	}
+	if n < len(outBytes) {
+		root.AddError(io.ErrShortWrite)
+	}
+}
@@ -0,0 +1,321 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// groupVersionDoc is the documentation of an API package.
+type groupVersionDoc struct {
+	GroupVersion string
+	// FileName is the name of the documentation file.
+	FileName string
+	Doc      string
+	// Kinds are the names of the kinds of the package.
+	Kinds []string
+	Types []*typeDoc
+}
+
+// typeDoc is the documentation of a type.
+type typeDoc struct {
+	Name string
+	Doc  string
+	// Underlying is the underlying type of non-struct types.
+	Underlying *typeRef
+	Fields     []fieldDoc
+	Validation []string
+	// AppearsIn are the names of the types of the package using the type.
+	AppearsIn []string
+}
+
+// fieldDoc is the documentation of a field, by JSON name.
+type fieldDoc struct {
+	Name       string
+	Type       typeRef
+	Doc        string
+	Default    string
+	Validation []string
+}
+
+// typeRef is a reference to a type, linked to its documentation if any.
+type typeRef struct {
+	Name string
+	Link string
+	// Array indicates a list of the type.
+	Array bool
+	// MapKey is the key type of a map of the type, if any.
+	MapKey string
+}
+
+// anchor returns the anchor of the documentation of the given type.
+func anchor(name string) string {
+	return strings.ToLower(name)
+}
+
+// validationRules returns the validation rules of the given schema, in
+// human-readable form.
+func validationRules(schema *apiextensionsv1.JSONSchemaProps) []string {
+	var rules []string
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			var str string
+			if err := json.Unmarshal(value.Raw, &str); err == nil {
+				values = append(values, str)
+			} else {
+				values = append(values, string(value.Raw))
+			}
+		}
+		rules = append(rules, "Enum: ["+strings.Join(values, " ")+"]")
+	}
+	if schema.Format != "" && schema.Type != "integer" && schema.Type != "number" {
+		// the formats of numbers are implied by their Go types
+		rules = append(rules, "Format: "+schema.Format)
+	}
+	if schema.Minimum != nil {
+		rule := "Minimum: " + formatNumber(*schema.Minimum)
+		if schema.ExclusiveMinimum {
+			rule += " (exclusive)"
+		}
+		rules = append(rules, rule)
+	}
+	if schema.Maximum != nil {
+		rule := "Maximum: " + formatNumber(*schema.Maximum)
+		if schema.ExclusiveMaximum {
+			rule += " (exclusive)"
+		}
+		rules = append(rules, rule)
+	}
+	if schema.MultipleOf != nil {
+		rules = append(rules, "MultipleOf: "+formatNumber(*schema.MultipleOf))
+	}
+	if schema.MinLength != nil {
+		rules = append(rules, "MinLength: "+strconv.FormatInt(*schema.MinLength, 10))
+	}
+	if schema.MaxLength != nil {
+		rules = append(rules, "MaxLength: "+strconv.FormatInt(*schema.MaxLength, 10))
+	}
+	if schema.Pattern != "" {
+		rules = append(rules, "Pattern: `"+schema.Pattern+"`")
+	}
+	if schema.MinItems != nil {
+		rules = append(rules, "MinItems: "+strconv.FormatInt(*schema.MinItems, 10))
+	}
+	if schema.MaxItems != nil {
+		rules = append(rules, "MaxItems: "+strconv.FormatInt(*schema.MaxItems, 10))
+	}
+	if schema.XListType != nil {
+		rules = append(rules, "ListType: "+*schema.XListType)
+	}
+	if len(schema.XListMapKeys) > 0 {
+		rules = append(rules, "ListMapKeys: ["+strings.Join(schema.XListMapKeys, " ")+"]")
+	}
+	if schema.MinProperties != nil {
+		rules = append(rules, "MinProperties: "+strconv.FormatInt(*schema.MinProperties, 10))
+	}
+	if schema.MaxProperties != nil {
+		rules = append(rules, "MaxProperties: "+strconv.FormatInt(*schema.MaxProperties, 10))
+	}
+	for _, rule := range schema.XValidations {
+		text := "Rule: `" + rule.Rule + "`"
+		if rule.Message != "" {
+			text += " (" + rule.Message + ")"
+		}
+		rules = append(rules, text)
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		for _, rule := range validationRules(schema.Items.Schema) {
+			rules = append(rules, "Items "+rule)
+		}
+	}
+	return rules
+}
+
+// formatNumber returns the shortest representation of the given number.
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'g', -1, 64)
+}
+
+// htmlCode returns the given text as HTML, with `code spans` marked as code.
+func htmlCode(text string) htmltemplate.HTML {
+	var out strings.Builder
+	for i, part := range strings.Split(text, "`") {
+		escaped := htmltemplate.HTMLEscapeString(part)
+		if i%2 == 1 {
+			escaped = "<code>" + escaped + "</code>"
+		}
+		out.WriteString(escaped)
+	}
+	return htmltemplate.HTML(out.String()) //nolint:gosec // the text is escaped above
+}
+
+// markdownCell escapes the given text for use in a markdown table cell.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", "<br />")
+}
+
+var (
+	markdownTemplateRaw = `{{- define "type" -}}
+_{{ if .MapKey }}object (keys:{{ .MapKey }}, values:{{ end -}}
+{{ if .Link }}[{{ .Name }}]({{ .Link }}){{ else }}{{ .Name }}{{ end -}}
+{{ if .Array }} array{{ end }}{{ if .MapKey }}){{ end }}_
+{{- end -}}
+# {{ .GroupVersion }}
+{{ with .Doc }}
+{{ . }}
+{{ end }}
+{{- with .Kinds }}
+## Resource Types
+{{ range . }}
+- [{{ . }}](#{{ anchor . }})
+{{- end }}
+{{ end }}
+## Types
+{{ range .Types }}
+### {{ .Name }}
+{{ with .Doc }}
+{{ . }}
+{{ end }}
+{{- with .Underlying }}
+_Underlying type:_ {{ template "type" . }}
+{{ end }}
+{{- with .Validation }}
+_Validation:_
+{{ range . }}
+- {{ . }}
+{{- end }}
+{{ end }}
+{{- with .AppearsIn }}
+_Appears in:_
+{{ range . }}
+- [{{ . }}](#{{ anchor . }})
+{{- end }}
+{{ end }}
+{{- with .Fields }}
+| Field | Description | Default | Validation |
+| --- | --- | --- | --- |
+{{- range . }}
+| ` + "`{{ .Name }}`" + ` {{ template "type" .Type }} | {{ cell .Doc }} | {{ with .Default }}` + "`{{ cell . }}`" + `{{ end }} | {{ cell (join .Validation) }} |
+{{- end }}
+{{ end }}
+{{- end }}`
+	markdownTemplateHelpers = template.FuncMap{
+		"anchor": anchor,
+		"cell":   markdownCell,
+		"join":   func(lines []string) string { return strings.Join(lines, "\n") },
+	}
+	markdownTemplate = template.Must(template.New("markdown").Funcs(markdownTemplateHelpers).Parse(markdownTemplateRaw))
+
+	htmlTemplateRaw = `{{- define "type" -}}
+<em>{{ if .MapKey }}object (keys:{{ .MapKey }}, values:{{ end -}}
+{{ if .Link }}<a href="{{ .Link }}">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end -}}
+{{ if .Array }} array{{ end }}{{ if .MapKey }}){{ end }}</em>
+{{- end -}}
+{{- define "list" -}}
+<ul>
+{{- range . }}
+<li>{{ code . }}</li>
+{{- end }}
+</ul>
+{{- end -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .GroupVersion }}</title>
+</head>
+<body>
+<h1>{{ .GroupVersion }}</h1>
+{{- range paragraphs .Doc }}
+<p>{{ code . }}</p>
+{{- end }}
+{{- with .Kinds }}
+<h2>Resource Types</h2>
+<ul>
+{{- range . }}
+<li><a href="#{{ anchor . }}">{{ . }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+<h2>Types</h2>
+{{- range .Types }}
+<h3 id="{{ anchor .Name }}">{{ .Name }}</h3>
+{{- range paragraphs .Doc }}
+<p>{{ code . }}</p>
+{{- end }}
+{{- with .Underlying }}
+<p><em>Underlying type:</em> {{ template "type" . }}</p>
+{{- end }}
+{{- with .Validation }}
+<p><em>Validation:</em></p>
+{{ template "list" . }}
+{{- end }}
+{{- with .AppearsIn }}
+<p><em>Appears in:</em></p>
+<ul>
+{{- range . }}
+<li><a href="#{{ anchor . }}">{{ . }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- with .Fields }}
+<table>
+<thead>
+<tr><th>Field</th><th>Description</th><th>Default</th><th>Validation</th></tr>
+</thead>
+<tbody>
+{{- range . }}
+<tr><td><code>{{ .Name }}</code> {{ template "type" .Type }}</td><td>{{ range $i, $p := paragraphs .Doc }}{{ if $i }}<br>{{ end }}{{ code $p }}{{ end }}</td><td>{{ with .Default }}<code>{{ . }}</code>{{ end }}</td><td>{{ range $i, $rule := .Validation }}{{ if $i }}<br>{{ end }}{{ code $rule }}{{ end }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`
+	htmlTemplateHelpers = htmltemplate.FuncMap{
+		"anchor": anchor,
+		"code":   htmlCode,
+		"paragraphs": func(text string) []string {
+			if text == "" {
+				return nil
+			}
+			return strings.Split(text, "\n")
+		},
+	}
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(htmlTemplateHelpers).Parse(htmlTemplateRaw))
+)
+
+// renderMarkdown writes the given documentation as markdown.
+func renderMarkdown(out io.Writer, doc *groupVersionDoc) error {
+	return markdownTemplate.Execute(out, doc)
+}
+
+// renderHTML writes the given documentation as HTML.
+func renderHTML(out io.Writer, doc *groupVersionDoc) error {
+	return htmlTemplate.Execute(out, doc)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"testdata.kubebuilder.io/docs/api/v1alpha1"
+)
+
+// Protocol is a network protocol.
+// +kubebuilder:validation:Enum=TCP;UDP
+type Protocol string
+
+// Port is a network port.
+type Port struct {
+	// Name is the name of the port.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self != 'default'",message="the name default is reserved"
+	// +k8s:immutable
+	Name string `json:"name"`
+
+	// Number is the number of the port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Number int32 `json:"number"`
+
+	// Protocol is the protocol of the port.
+	// +kubebuilder:default=TCP
+	// +optional
+	Protocol Protocol `json:"protocol,omitempty"`
+}
+
+// CommonSpec holds the fields shared by specs.
+type CommonSpec struct {
+	// Paused stops the reconciliation of the object.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// WidgetSpec is the spec of a Widget.
+// +kubebuilder:validation:XValidation:rule="self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+type WidgetSpec struct {
+	CommonSpec `json:",inline"`
+
+	// MinReplicas is the minimum number of replicas.
+	//
+	// It defaults to one | two.
+	// +kubebuilder:default=1
+	// +optional
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the maximum number of replicas.
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Ports are the ports of the widget.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Ports []Port `json:"ports,omitempty"`
+
+	// Labels are extra labels.
+	// +kubebuilder:validation:MaxProperties=4
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Target is the port to target, by name or number.
+	// +optional
+	Target *intstr.IntOrString `json:"target,omitempty"`
+
+	// Legacy is the previous form of the spec.
+	// +optional
+	Legacy *v1alpha1.GizmoSpec `json:"legacy,omitempty"`
+}
+
+// WidgetStatus is the status of a Widget.
+type WidgetStatus struct {
+	// LastUpdated is the time of the last update.
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// Widget is a documented kind.
+// +kubebuilder:object:root=true
+// +acme:owner=team-a
+// +acme:retention:days=30
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// WidgetList contains a list of Widgets.
+// +kubebuilder:object:root=true
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
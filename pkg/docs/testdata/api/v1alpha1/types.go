@@ -0,0 +1,37 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GizmoSpec is the spec of a Gizmo.
+type GizmoSpec struct {
+	// Size is the size of the gizmo.
+	// +kubebuilder:validation:items:Pattern=`^[0-9]+[KMG]i$`
+	Sizes []string `json:"sizes,omitempty"`
+}
+
+// Gizmo is a documented kind of another version.
+// +kubebuilder:object:root=true
+type Gizmo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GizmoSpec `json:"spec,omitempty"`
+}
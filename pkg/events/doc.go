@@ -0,0 +1,27 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events generates event reason constants from markers on the types
+// recording the events (e.g. reconcilers), e.g.:
+//
+//	// +kubebuilder:event:reason=ScalingUp,description="The widget is being scaled up."
+//	// +kubebuilder:event:reason=ScalingFailed,type=Warning,description="The widget couldn't be scaled."
+//	type WidgetReconciler struct { ... }
+//
+// generates EventReasonScalingUp and EventReasonScalingFailed constants, to be
+// passed to EventRecorder.Event instead of free-form strings, and optionally
+// a reference of all the events, to be published with the rest of the docs.
+package events
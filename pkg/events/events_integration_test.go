@@ -0,0 +1,43 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/events"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+)
+
+var _ = Describe("Events Generation", func() {
+	headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+
+	It("should generate event reason constants and a reference from the event markers", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{events.Generator{HeaderFile: headerFile, Reference: "events.md"}}, "./controllers/...")
+		golden.CompareFiles(GinkgoT(), "testdata", out, "controllers/zz_generated.events.go", "events.md")
+	})
+
+	It("should fail with invalid and conflicting events", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{events.Generator{}}, "./invalid/...")
+		Expect(errOut).To(ContainSubstring(`event reason "scaling-up" of WidgetReconciler must be UpperCamelCase`))
+		Expect(errOut).To(ContainSubstring(`event type "Error" of WidgetReconciler must be Normal or Warning`))
+		Expect(errOut).To(ContainSubstring("events of reason Ready of WidgetReconciler and GadgetReconciler differ in type or description"))
+	})
+})
@@ -0,0 +1,211 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.events.go"
+
+var eventMarker = markers.Must(markers.MakeDefinition("kubebuilder:event", markers.DescribesType, Event{}))
+
+// reasonRE matches valid reasons, which are UpperCamelCase by convention, and
+// must be valid Go identifiers to be part of constant names.
+var reasonRE = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// +controllertools:marker:generateHelp:category=events
+
+// Event declares an event recorded for the type, e.g. by a reconciler.
+//
+// An EventReason<Reason> constant is generated for the reason of the event,
+// which must be UpperCamelCase.  Several types of a package may record events
+// of the same reason, as long as they agree on their type and description.
+type Event struct {
+	// Reason is the reason of the event, e.g. ScalingUp.
+	Reason string
+	// Type is the type of the event, Normal (the default) or Warning.
+	Type string `marker:",optional"`
+	// Description describes when the event is recorded.
+	Description string `marker:",optional"`
+}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates event reason constants.
+//
+// The constants of the events of each package are written to
+// zz_generated.events.go.
+type Generator struct {
+	// Reference is the name of a Markdown reference of all the events to
+	// generate, e.g. events.md.
+	Reference string `marker:",optional"`
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := into.Register(eventMarker); err != nil {
+		return err
+	}
+	into.AddHelp(eventMarker, Event{}.Help())
+	return nil
+}
+
+// eventReason is the reason of the events recorded for some types of a
+// package.
+type eventReason struct {
+	Event
+	// recordedFor are the types the events are recorded for.
+	recordedFor []string
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	reasonsByPkg := make(map[*loader.Package][]*eventReason)
+	for _, root := range ctx.Roots {
+		reasons, ok := packageReasons(ctx.Collector, root)
+		if !ok || len(reasons) == 0 {
+			continue
+		}
+		reasonsByPkg[root] = reasons
+		gogen.WriteArtifact(ctx, root, root, outputFileName, reasonsFile(root, headerText, reasons))
+	}
+
+	if g.Reference != "" && len(reasonsByPkg) > 0 {
+		gogen.WriteArtifact(ctx, ctx.Roots[0], nil, g.Reference, reference(ctx.Roots, reasonsByPkg))
+	}
+	return nil
+}
+
+// packageReasons returns the event reasons of the given package, sorted by
+// reason, and whether they're valid.
+func packageReasons(col *markers.Collector, pkg *loader.Package) ([]*eventReason, bool) {
+	byReason := make(map[string]*eventReason)
+	ok := true
+	if err := markers.EachType(col, pkg, func(info *markers.TypeInfo) {
+		for _, eventVal := range info.Markers[eventMarker.Name] {
+			event := eventVal.(Event)
+			if event.Type == "" {
+				event.Type = "Normal"
+			}
+			if !reasonRE.MatchString(event.Reason) {
+				pkg.AddError(loader.ErrFromNode(fmt.Errorf("event reason %q of %s must be UpperCamelCase", event.Reason, info.Name), info.RawSpec))
+				ok = false
+				continue
+			}
+			if event.Type != "Normal" && event.Type != "Warning" {
+				pkg.AddError(loader.ErrFromNode(fmt.Errorf("event type %q of %s must be Normal or Warning", event.Type, info.Name), info.RawSpec))
+				ok = false
+				continue
+			}
+
+			reason, exists := byReason[event.Reason]
+			if !exists {
+				byReason[event.Reason] = &eventReason{Event: event, recordedFor: []string{info.Name}}
+				continue
+			}
+			if reason.Event != event {
+				pkg.AddError(loader.ErrFromNode(fmt.Errorf("events of reason %s of %s and %s differ in type or description", event.Reason, reason.recordedFor[0], info.Name), info.RawSpec))
+				ok = false
+				continue
+			}
+			reason.recordedFor = append(reason.recordedFor, info.Name)
+		}
+	}); err != nil {
+		pkg.AddError(err)
+		return nil, false
+	}
+
+	reasons := make([]*eventReason, 0, len(byReason))
+	for _, reason := range byReason {
+		slices.Sort(reason.recordedFor)
+		reasons = append(reasons, reason)
+	}
+	slices.SortFunc(reasons, func(a, b *eventReason) int {
+		return strings.Compare(a.Reason, b.Reason)
+	})
+	return reasons, ok
+}
+
+// reasonsFile returns the formatted contents of the file of the given
+// package, with the given reasons.
+func reasonsFile(pkg *loader.Package, headerText string, reasons []*eventReason) []byte {
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+// Reasons of the events recorded by the package.
+const (
+`, pkg.Name, headerText)
+	for _, reason := range reasons {
+		fmt.Fprintf(outContent, "// EventReason%s is the reason of %s events recorded for %s.\n", reason.Reason, reason.Type, strings.Join(reason.recordedFor, ", "))
+		if reason.Description != "" {
+			fmt.Fprintf(outContent, "//\n// %s\n", strings.ReplaceAll(reason.Description, "\n", "\n// "))
+		}
+		fmt.Fprintf(outContent, "EventReason%[1]s = %[1]q\n", reason.Reason)
+	}
+	outContent.WriteString(")\n")
+
+	return gogen.Format(pkg, outContent.Bytes())
+}
+
+// reference returns the Markdown reference of the given reasons of the given
+// packages.
+func reference(roots []*loader.Package, reasonsByPkg map[*loader.Package][]*eventReason) []byte {
+	out := new(bytes.Buffer)
+	out.WriteString("# Events\n\n| Reason | Type | Recorded for | Description |\n| --- | --- | --- | --- |\n")
+	// packages are in the order of the roots, for a stable output
+	for _, root := range roots {
+		for _, reason := range reasonsByPkg[root] {
+			recordedFor := make([]string, len(reason.recordedFor))
+			for i, typeName := range reason.recordedFor {
+				recordedFor[i] = "`" + root.PkgPath + "." + typeName + "`"
+			}
+			fmt.Fprintf(out, "| %s | %s | %s | %s |\n", reason.Reason, reason.Type, strings.Join(recordedFor, ", "), markdownCell(reason.Description))
+		}
+	}
+	return out.Bytes()
+}
+
+// markdownCell escapes the given text for use in a markdown table cell.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", "<br />")
+}
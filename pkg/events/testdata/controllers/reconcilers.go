@@ -0,0 +1,30 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+// WidgetReconciler reconciles widgets.
+//
+// +kubebuilder:event:reason=ScalingUp,description="The widget is being scaled up."
+// +kubebuilder:event:reason=ScalingFailed,type=Warning,description="The widget couldn't be scaled, e.g. because its quota is exceeded | exhausted."
+// +kubebuilder:event:reason=Ready
+type WidgetReconciler struct{}
+
+// GadgetReconciler reconciles gadgets.
+//
+// +kubebuilder:event:reason=Ready
+// +kubebuilder:event:reason=Deleting,description="The gadget is being deleted."
+type GadgetReconciler struct{}
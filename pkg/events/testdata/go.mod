@@ -0,0 +1,3 @@
+module testdata.kubebuilder.io/events
+
+go 1.26.0
@@ -0,0 +1,28 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package finalizer generates the finalizer constants and helpers of kinds
+// declaring their finalizer with the +kubebuilder:finalizer marker, e.g.:
+//
+//	// +kubebuilder:finalizer=apps.example.com/cleanup
+//	type Widget struct { ... }
+//
+// generates a WidgetFinalizer constant, as well as AddFinalizer,
+// RemoveFinalizer and HasFinalizer methods on Widget.  The RBAC generator
+// grants updating the finalizers of such kinds (named by their
+// +kubebuilder:resource path, as in CRDs) to the roles accessing them, or
+// else to the default role of their packages.
+package finalizer
@@ -0,0 +1,240 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package finalizer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing methods, we only see hand-written ones.
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.finalizers.go"
+
+// Definition is the marker declaring the finalizer of a kind.
+var Definition = markers.Must(markers.MakeDefinition("kubebuilder:finalizer", markers.DescribesType, Finalizer("")))
+
+// +controllertools:marker:generateHelp:category=finalizer
+
+// Finalizer declares the finalizer of a kind, e.g. apps.example.com/cleanup.
+//
+// A <Kind>Finalizer constant is generated for it, along with AddFinalizer,
+// RemoveFinalizer and HasFinalizer methods on the kind.  The finalizer must be
+// domain-qualified, and the kind must embed metav1.ObjectMeta.
+type Finalizer string
+
+// +controllertools:marker:generateHelp
+
+// Generator generates the finalizer constants and helpers of kinds.
+//
+// The constants and helpers of the kinds of each package are written to
+// zz_generated.finalizers.go.  Methods that already exist are not generated.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return func(node ast.Node) bool {
+		// ignore interfaces
+		_, isIface := node.(*ast.InterfaceType)
+		return !isIface
+	}
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := into.Register(Definition); err != nil {
+		return err
+	}
+	into.AddHelp(Definition, Finalizer("").Help())
+	return nil
+}
+
+// kindFinalizer is the finalizer of a kind.
+type kindFinalizer struct {
+	kind      *types.Named
+	finalizer string
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range ctx.Roots {
+		outContents := generateForPackage(ctx, root, headerText)
+		if outContents == nil {
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, outContents)
+	}
+
+	return nil
+}
+
+// generateForPackage generates the finalizer constants and helpers of the
+// kinds of the given package.  May return nil if no kind has a finalizer, or
+// any finalizer is invalid.
+func generateForPackage(ctx *genall.GenerationContext, root *loader.Package, headerText string) []byte {
+	ctx.Checker.Check(root)
+	root.NeedTypesInfo()
+
+	var finalizers []kindFinalizer
+	failed := false
+	if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+		finalizerVals := info.Markers[Definition.Name]
+		if len(finalizerVals) == 0 {
+			return
+		}
+		if len(finalizerVals) > 1 {
+			root.AddError(loader.ErrFromNode(fmt.Errorf("kind %s has more than one finalizer", info.Name), info.RawSpec))
+			failed = true
+			return
+		}
+		finalizer, err := checkFinalizer(root, info, string(finalizerVals[0].(Finalizer)))
+		if err != nil {
+			root.AddError(loader.ErrFromNode(err, info.RawSpec))
+			failed = true
+			return
+		}
+		finalizers = append(finalizers, finalizer)
+	}); err != nil {
+		root.AddError(err)
+		return nil
+	}
+	if failed || len(finalizers) == 0 {
+		return nil
+	}
+	slices.SortFunc(finalizers, func(a, b kindFinalizer) int {
+		return strings.Compare(a.kind.Obj().Name(), b.kind.Obj().Name())
+	})
+
+	methods := new(bytes.Buffer)
+	for _, finalizer := range finalizers {
+		finalizer.writeMethods(root, methods)
+	}
+	var importsBlock string
+	if methods.Len() > 0 {
+		importsBlock = "import (\n\t\"slices\"\n)\n"
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+%[3]s
+const (
+`, root.Name, headerText, importsBlock)
+	for _, finalizer := range finalizers {
+		kindName := finalizer.kind.Obj().Name()
+		fmt.Fprintf(outContent, "// %[1]sFinalizer is the finalizer of %[1]s.\n%[1]sFinalizer = %[2]q\n", kindName, finalizer.finalizer)
+	}
+	outContent.WriteString(")\n\n")
+	outContent.Write(methods.Bytes())
+
+	return gogen.Format(root, outContent.Bytes())
+}
+
+// checkFinalizer checks that the given finalizer of the given kind is valid,
+// returning it.
+func checkFinalizer(pkg *loader.Package, info *markers.TypeInfo, finalizer string) (kindFinalizer, error) {
+	if errs := validation.IsQualifiedName(finalizer); len(errs) > 0 {
+		return kindFinalizer{}, fmt.Errorf("invalid finalizer %q of %s: %s", finalizer, info.Name, strings.Join(errs, ", "))
+	}
+	if !strings.Contains(finalizer, "/") {
+		return kindFinalizer{}, fmt.Errorf("finalizer %q of %s must be domain-qualified, e.g. example.com/%s", finalizer, info.Name, finalizer)
+	}
+
+	named, isNamed := pkg.TypesInfo.Defs[info.RawSpec.Name].Type().(*types.Named)
+	if !isNamed {
+		return kindFinalizer{}, fmt.Errorf("kind %s with a finalizer must be a named type", info.Name)
+	}
+	field, _, _ := types.LookupFieldOrMethod(named, false, pkg.Types, "Finalizers")
+	if fieldVar, isVar := field.(*types.Var); !isVar || !fieldVar.IsField() || !types.Identical(fieldVar.Type(), types.NewSlice(types.Typ[types.String])) {
+		return kindFinalizer{}, fmt.Errorf("kind %s with a finalizer must embed metav1.ObjectMeta", info.Name)
+	}
+	return kindFinalizer{kind: named, finalizer: finalizer}, nil
+}
+
+// writeMethods writes the finalizer methods of the kind that don't already
+// exist.
+func (f kindFinalizer) writeMethods(pkg *loader.Package, out *bytes.Buffer) {
+	hasMethod := func(name string) bool {
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(f.kind), true, pkg.Types, name)
+		return obj != nil
+	}
+	kindName := f.kind.Obj().Name()
+
+	if !hasMethod("HasFinalizer") {
+		fmt.Fprintf(out, `// HasFinalizer checks if the %[1]s has the %[1]sFinalizer finalizer.
+func (in *%[1]s) HasFinalizer() bool {
+	return slices.Contains(in.Finalizers, %[1]sFinalizer)
+}
+
+`, kindName)
+	}
+	if !hasMethod("AddFinalizer") {
+		fmt.Fprintf(out, `// AddFinalizer adds the %[1]sFinalizer finalizer to the %[1]s, returning
+// whether it was missing.
+func (in *%[1]s) AddFinalizer() bool {
+	if slices.Contains(in.Finalizers, %[1]sFinalizer) {
+		return false
+	}
+	in.Finalizers = append(in.Finalizers, %[1]sFinalizer)
+	return true
+}
+
+`, kindName)
+	}
+	if !hasMethod("RemoveFinalizer") {
+		fmt.Fprintf(out, `// RemoveFinalizer removes the %[1]sFinalizer finalizer from the %[1]s,
+// returning whether it was present.
+func (in *%[1]s) RemoveFinalizer() bool {
+	if !slices.Contains(in.Finalizers, %[1]sFinalizer) {
+		return false
+	}
+	in.Finalizers = slices.DeleteFunc(in.Finalizers, func(finalizer string) bool {
+		return finalizer == %[1]sFinalizer
+	})
+	return true
+}
+
+`, kindName)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Widget is an app made of widgets.
+// +kubebuilder:finalizer=apps.testdata.kubebuilder.io/cleanup
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// Gadget is a part of widgets, releasing its storage when deleted.
+// +kubebuilder:finalizer=apps.testdata.kubebuilder.io/release-storage
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// HasFinalizer checks if the gadget has its finalizer, or is already being
+// deleted without it.
+func (in *Gadget) HasFinalizer() bool {
+	for _, finalizer := range in.Finalizers {
+		if finalizer == GadgetFinalizer {
+			return true
+		}
+	}
+	return in.DeletionTimestamp != nil
+}
+
+// Gizmo has no finalizer.
+type Gizmo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
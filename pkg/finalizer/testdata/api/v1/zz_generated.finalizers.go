@@ -0,0 +1,81 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"slices"
+)
+
+const (
+	// GadgetFinalizer is the finalizer of Gadget.
+	GadgetFinalizer = "apps.testdata.kubebuilder.io/release-storage"
+	// WidgetFinalizer is the finalizer of Widget.
+	WidgetFinalizer = "apps.testdata.kubebuilder.io/cleanup"
+)
+
+// AddFinalizer adds the GadgetFinalizer finalizer to the Gadget, returning
+// whether it was missing.
+func (in *Gadget) AddFinalizer() bool {
+	if slices.Contains(in.Finalizers, GadgetFinalizer) {
+		return false
+	}
+	in.Finalizers = append(in.Finalizers, GadgetFinalizer)
+	return true
+}
+
+// RemoveFinalizer removes the GadgetFinalizer finalizer from the Gadget,
+// returning whether it was present.
+func (in *Gadget) RemoveFinalizer() bool {
+	if !slices.Contains(in.Finalizers, GadgetFinalizer) {
+		return false
+	}
+	in.Finalizers = slices.DeleteFunc(in.Finalizers, func(finalizer string) bool {
+		return finalizer == GadgetFinalizer
+	})
+	return true
+}
+
+// HasFinalizer checks if the Widget has the WidgetFinalizer finalizer.
+func (in *Widget) HasFinalizer() bool {
+	return slices.Contains(in.Finalizers, WidgetFinalizer)
+}
+
+// AddFinalizer adds the WidgetFinalizer finalizer to the Widget, returning
+// whether it was missing.
+func (in *Widget) AddFinalizer() bool {
+	if slices.Contains(in.Finalizers, WidgetFinalizer) {
+		return false
+	}
+	in.Finalizers = append(in.Finalizers, WidgetFinalizer)
+	return true
+}
+
+// RemoveFinalizer removes the WidgetFinalizer finalizer from the Widget,
+// returning whether it was present.
+func (in *Widget) RemoveFinalizer() bool {
+	if !slices.Contains(in.Finalizers, WidgetFinalizer) {
+		return false
+	}
+	in.Finalizers = slices.DeleteFunc(in.Finalizers, func(finalizer string) bool {
+		return finalizer == WidgetFinalizer
+	})
+	return true
+}
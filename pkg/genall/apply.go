@@ -0,0 +1,278 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// defaultFieldManager is the field manager of the fields applied by
+// controller-gen, unless set otherwise.
+const defaultFieldManager = "controller-gen"
+
+// +controllertools:marker:generateHelp:category=""
+
+// OutputToCluster applies all the manifests to a cluster with server-side
+// apply, e.g. to refresh the CRDs of a development cluster in one step.
+//
+// The manifests are applied once all the generators ran, in the same order
+// as bundled (namespaces and CRDs first), taking over the fields managed by
+// others.  Package-associated artifacts are output to their package's source
+// files' directory, as with artifacts.  Nothing is applied in verify mode.
+type OutputToCluster struct {
+	// Kubeconfig is the path of the kubeconfig file of the cluster (defaults
+	// to the --kubeconfig flag, $KUBECONFIG, or ~/.kube/config).
+	Kubeconfig string `marker:",optional"`
+	// Context is the context of the kubeconfig file to use (defaults to the
+	// --context flag, or the current context).
+	Context string `marker:",optional"`
+	// FieldManager is the field manager of the applied fields (defaults to
+	// controller-gen).
+	FieldManager string `marker:",optional"`
+}
+
+// cluster identifies a cluster to apply manifests to, with a field manager.
+type cluster struct {
+	kubeconfig   string
+	context      string
+	fieldManager string
+}
+
+func (o OutputToCluster) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return o.openInRun(outsideRun(), pkg, itemPath)
+}
+
+func (o OutputToCluster) openInRun(run *runState, pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if pkg != nil {
+		return OutputArtifacts{}.openInRun(run, pkg, itemPath)
+	}
+	if ext := filepath.Ext(itemPath); ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("cannot apply %s to a cluster, as it's not a YAML manifest", itemPath)
+	}
+	if run.planner != nil {
+		run.planner.record(fmt.Sprintf("cluster (applying %s)", itemPath))
+		return nopCloser{io.Discard}, nil
+	}
+	if run.verifier != nil {
+		// there's nothing on disk to compare with
+		return nopCloser{io.Discard}, nil
+	}
+	if !run.held {
+		return nil, errOutsideRun(o)
+	}
+
+	target := cluster{kubeconfig: o.Kubeconfig, context: o.Context, fieldManager: o.FieldManager}
+	if target.fieldManager == "" {
+		target.fieldManager = defaultFieldManager
+	}
+	run.appliesMu.Lock()
+	defer run.appliesMu.Unlock()
+	if run.applies[target] == nil {
+		run.applies[target] = make(map[string][]*unstructured.Unstructured)
+	}
+	return &applyWriter{run: run, cluster: target, itemPath: itemPath}, nil
+}
+
+// applyWriter adds the objects written to it to the ones to apply at the end
+// of a run when closed.
+type applyWriter struct {
+	bytes.Buffer
+	run      *runState
+	cluster  cluster
+	itemPath string
+}
+
+func (w *applyWriter) Close() error {
+	var objs []*unstructured.Unstructured
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(&w.Buffer))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to apply %s: %w", w.itemPath, err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(document, &obj.Object); err != nil {
+			return fmt.Errorf("unable to apply %s: %w", w.itemPath, err)
+		}
+		if obj.GetKind() == "" {
+			// e.g. the header of the file
+			continue
+		}
+		objs = append(objs, obj)
+	}
+
+	w.run.appliesMu.Lock()
+	defer w.run.appliesMu.Unlock()
+	w.run.applies[w.cluster][w.itemPath] = objs
+	return nil
+}
+
+// ClusterConfig is the cluster to apply manifests to with OutputToCluster,
+// unless the rule sets its own, or to read the objects to audit from.
+type ClusterConfig struct {
+	// Kubeconfig is the path of the kubeconfig file, defaulting to
+	// $KUBECONFIG or ~/.kube/config.
+	Kubeconfig string
+	// Context is the context of the kubeconfig file to use, defaulting to
+	// its current context.
+	Context string
+}
+
+// clientConfig returns the client configuration of the cluster.
+func (c ClusterConfig) clientConfig() clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = c.Kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: c.Context})
+}
+
+// ExportManifests returns the objects of the given resources in the cluster
+// (in all namespaces), as a multi-document YAML stream, so that they can be
+// compared with the generated manifests as an exported manifest would be.
+func (c ClusterConfig) ExportManifests(ctx context.Context, resources ...schema.GroupVersionResource) ([]byte, error) {
+	restConfig, err := c.clientConfig().ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the configuration of the cluster: %w", err)
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for _, resource := range resources {
+		list, err := client.Resource(resource).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the %s of the cluster: %w", resource.Resource, err)
+		}
+		for _, item := range list.Items {
+			document, err := yaml.Marshal(item.Object)
+			if err != nil {
+				return nil, err
+			}
+			out.WriteString("---\n")
+			out.Write(document)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// applyManifests applies the manifests output during the run to their
+// clusters, defaulting to the given one.
+func (r *runState) applyManifests(ctx context.Context, defaults ClusterConfig) error {
+	r.appliesMu.Lock()
+	defer r.appliesMu.Unlock()
+	defer clear(r.applies)
+
+	var errs []error
+	for target, artifacts := range r.applies {
+		if target.kubeconfig == "" {
+			target.kubeconfig = defaults.Kubeconfig
+		}
+		if target.context == "" {
+			target.context = defaults.Context
+		}
+		var objs []*unstructured.Unstructured
+		for _, itemPath := range slices.Sorted(maps.Keys(artifacts)) {
+			objs = append(objs, artifacts[itemPath]...)
+		}
+		slices.SortStableFunc(objs, func(a, b *unstructured.Unstructured) int {
+			return installRank(a.GetKind()) - installRank(b.GetKind())
+		})
+		if err := applyToCluster(ctx, target, objs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyToCluster applies the given objects to the given cluster, in order.
+func applyToCluster(ctx context.Context, target cluster, objs []*unstructured.Unstructured) error {
+	clientConfig := ClusterConfig{Kubeconfig: target.kubeconfig, Context: target.context}.clientConfig()
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("unable to load the configuration of the cluster: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return fmt.Errorf("unable to load the configuration of the cluster: %w", err)
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	var errs []error
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			// e.g. a custom resource of a CRD applied just before
+			mapper.Reset()
+			mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to apply %s %s: %w", gvk.Kind, obj.GetName(), err))
+			continue
+		}
+
+		resource := client.Resource(mapping.Resource)
+		var applier dynamic.ResourceInterface = resource
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace(namespace)
+			}
+			applier = resource.Namespace(obj.GetNamespace())
+		}
+		if _, err := applier.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: target.fieldManager, Force: true}); err != nil {
+			errs = append(errs, fmt.Errorf("unable to apply %s %s: %w", gvk.Kind, obj.GetName(), err))
+			continue
+		}
+		slog.Debug("applied object", "kind", gvk.Kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,267 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// +controllertools:marker:generateHelp:category=""
+
+// OutputToArchive outputs all the artifacts to a single archive, e.g. to attach them to a release.
+//
+// The format of the archive is given by the extension of its file: a gzipped
+// tarball for ".tar.gz" or ".tgz", and a zip file for ".zip".  Artifacts are
+// stored by path, with package-associated ones under the directory of their
+// package (relative to the working directory, or its import path otherwise),
+// along with a manifest.json listing the size and SHA-256 digest of each.
+// The archive is written once all the generators ran, and is reproducible:
+// the same artifacts always make for the same archive.
+type OutputToArchive struct {
+	// File is the path of the archive, e.g. dist/manifests.tar.gz.
+	File string
+}
+
+// archiveManifestName is the path of the manifest in archives.
+const archiveManifestName = "manifest.json"
+
+// archiveManifest lists the artifacts of an archive.
+type archiveManifest struct {
+	Generator string                 `json:"generator"`
+	Version   string                 `json:"version"`
+	Files     []archiveManifestEntry `json:"files"`
+}
+
+// archiveManifestEntry describes an artifact of an archive.
+type archiveManifestEntry struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func (o OutputToArchive) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return o.openInRun(outsideRun(), pkg, itemPath)
+}
+
+func (o OutputToArchive) openInRun(run *runState, pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if _, err := archiveFormat(o.File); err != nil {
+		return nil, err
+	}
+
+	entryPath := filepath.ToSlash(itemPath)
+	if pkg != nil {
+		if len(pkg.CompiledGoFiles) == 0 {
+			return nil, fmt.Errorf("cannot output to a package with no path on disk")
+		}
+		entryPath = path.Join(packageArchiveDir(pkg), entryPath)
+	}
+	if entryPath == archiveManifestName {
+		return nil, fmt.Errorf("cannot output %s to an archive, as it's the path of its manifest", itemPath)
+	}
+
+	if run.planner != nil {
+		run.planner.record(fmt.Sprintf("%s (archiving %s)", manifestPath(o.File), entryPath))
+		return nopCloser{io.Discard}, nil
+	}
+	if !run.held {
+		return nil, errOutsideRun(o)
+	}
+
+	run.archivesMu.Lock()
+	defer run.archivesMu.Unlock()
+	archivePath := filepath.Clean(o.File)
+	if run.archives[archivePath] == nil {
+		run.archives[archivePath] = make(map[string][]byte)
+	}
+	return &archiveWriter{run: run, archivePath: archivePath, entryPath: entryPath}, nil
+}
+
+// packageArchiveDir returns the directory of the given package in archives:
+// its directory relative to the working directory if it's under it, and its
+// import path otherwise.
+func packageArchiveDir(pkg *loader.Package) string {
+	dir := filepath.Dir(pkg.CompiledGoFiles[0])
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, dir); err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return pkg.PkgPath
+}
+
+// archiveWriter adds what's written to it to an archive of a run when
+// closed.
+type archiveWriter struct {
+	bytes.Buffer
+	run         *runState
+	archivePath string
+	entryPath   string
+}
+
+func (w *archiveWriter) Close() error {
+	w.run.archivesMu.Lock()
+	defer w.run.archivesMu.Unlock()
+	w.run.archives[w.archivePath][w.entryPath] = w.Bytes()
+	return nil
+}
+
+// archiveFormat returns the format of the archive at the given path, by
+// extension: "tar.gz" or "zip".
+func archiveFormat(archivePath string) (string, error) {
+	switch {
+	case strings.HasSuffix(archivePath, ".tar.gz"), strings.HasSuffix(archivePath, ".tgz"):
+		return "tar.gz", nil
+	case strings.HasSuffix(archivePath, ".zip"):
+		return "zip", nil
+	default:
+		return "", fmt.Errorf("unknown archive format of %s, must be .tar.gz, .tgz or .zip", archivePath)
+	}
+}
+
+// writeArchives writes the archives output during the run, recording the
+// given version of controller-gen in their manifests.
+func (r *runState) writeArchives(generatorVersion string) error {
+	r.archivesMu.Lock()
+	defer r.archivesMu.Unlock()
+	defer clear(r.archives)
+
+	var errs []error
+	for archivePath, artifacts := range r.archives {
+		if err := r.writeArchive(archivePath, artifacts, generatorVersion); err != nil {
+			errs = append(errs, fmt.Errorf("unable to write archive %s: %w", archivePath, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeArchive writes an archive of the given artifacts, by path, along with
+// their manifest.
+func (r *runState) writeArchive(archivePath string, artifacts map[string][]byte, generatorVersion string) error {
+	manifest := archiveManifest{
+		Generator: "controller-gen",
+		Version:   generatorVersion,
+		Files:     []archiveManifestEntry{},
+	}
+	entryPaths := slices.Sorted(maps.Keys(artifacts))
+	for _, entryPath := range entryPaths {
+		digest := sha256.Sum256(artifacts[entryPath])
+		manifest.Files = append(manifest.Files, archiveManifestEntry{
+			Path:   entryPath,
+			Size:   len(artifacts[entryPath]),
+			SHA256: hex.EncodeToString(digest[:]),
+		})
+	}
+	manifestContents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	artifacts[archiveManifestName] = append(manifestContents, '\n')
+	entryPaths = append([]string{archiveManifestName}, entryPaths...)
+
+	var out bytes.Buffer
+	format, err := archiveFormat(archivePath)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "tar.gz":
+		err = writeTarGz(&out, entryPaths, artifacts)
+	case "zip":
+		err = writeZip(&out, entryPaths, artifacts)
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := r.openRawFile(archivePath)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(out.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeTarGz writes a gzipped tarball of the given entries.  Entries have a
+// fixed modification time, so that the tarball only depends on their
+// contents.
+func writeTarGz(out io.Writer, entryPaths []string, entries map[string][]byte) error {
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, entryPath := range entryPaths {
+		header := &tar.Header{
+			Name:    entryPath,
+			Mode:    0o644,
+			Size:    int64(len(entries[entryPath])),
+			ModTime: time.Unix(0, 0),
+			Format:  tar.FormatPAX,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write(entries[entryPath]); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// writeZip writes a zip file of the given entries.  Entries have a fixed
+// modification time, so that the zip file only depends on their contents.
+func writeZip(out io.Writer, entryPaths []string, entries map[string][]byte) error {
+	zipWriter := zip.NewWriter(out)
+	for _, entryPath := range entryPaths {
+		header := &zip.FileHeader{
+			Name:   entryPath,
+			Method: zip.Deflate,
+			// the earliest time of zip files
+			Modified: time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}
+		header.SetMode(0o644)
+		entryWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := entryWriter.Write(entries[entryPath]); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
@@ -0,0 +1,232 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// +controllertools:marker:generateHelp:category=""
+
+// OutputToBundle outputs all the manifests to a single file, ordered so that
+// they can be applied at once (e.g. with kubectl apply -f).
+//
+// Objects are ordered by kind, as Helm installs them: namespaces first, then
+// CRDs before RBAC objects, workloads, webhook configurations, and finally
+// unknown kinds (e.g. custom resources).  The file is written once all the
+// generators ran.  Other artifacts are output next to the file, while
+// package-associated ones are output to their package's source files'
+// directory, as with artifacts.
+type OutputToBundle struct {
+	// File is the path of the bundle, e.g. config/install.yaml.
+	File string
+	// Namespace adds a namespace of the given name to the bundle.
+	Namespace string `marker:",optional"`
+}
+
+// bundle collects the manifests of a bundle.
+type bundle struct {
+	namespace string
+	// artifacts are the manifests of each artifact, by path, so that
+	// artifacts written twice are replaced.  They're bundled in path order,
+	// whatever the order in which generators ran.
+	artifacts map[string][]manifest
+}
+
+// manifest is an object of a bundle.
+type manifest struct {
+	kind string
+	raw  []byte
+}
+
+func (o OutputToBundle) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return o.openInRun(outsideRun(), pkg, itemPath)
+}
+
+func (o OutputToBundle) openInRun(run *runState, pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if pkg != nil {
+		return OutputArtifacts{}.openInRun(run, pkg, itemPath)
+	}
+	if ext := filepath.Ext(itemPath); ext != ".yaml" && ext != ".yml" {
+		return OutputToDirectory(filepath.Dir(o.File)).openInRun(run, nil, itemPath)
+	}
+
+	if run.planner != nil {
+		run.planner.record(fmt.Sprintf("%s (bundling %s)", manifestPath(o.File), itemPath))
+		return nopCloser{io.Discard}, nil
+	}
+	if !run.held {
+		return nil, errOutsideRun(o)
+	}
+
+	path := filepath.Clean(o.File)
+	run.bundlesMu.Lock()
+	defer run.bundlesMu.Unlock()
+	pending, exists := run.bundles[path]
+	if !exists {
+		pending = &bundle{artifacts: make(map[string][]manifest)}
+		run.bundles[path] = pending
+	}
+	if o.Namespace != "" {
+		pending.namespace = o.Namespace
+	}
+	return &bundleWriter{run: run, bundle: pending, itemPath: itemPath}, nil
+}
+
+// bundleWriter adds the manifests written to it to a bundle of a run when
+// closed.
+type bundleWriter struct {
+	bytes.Buffer
+	run      *runState
+	bundle   *bundle
+	itemPath string
+}
+
+func (w *bundleWriter) Close() error {
+	var manifests []manifest
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(&w.Buffer))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to bundle %s: %w", w.itemPath, err)
+		}
+		// the separator of the first document is kept by the reader
+		document = bytes.TrimPrefix(document, []byte("---\n"))
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(document, &typeMeta); err != nil {
+			return fmt.Errorf("unable to bundle %s: %w", w.itemPath, err)
+		}
+		if typeMeta.Kind == "" {
+			// e.g. the header of the file
+			continue
+		}
+		manifests = append(manifests, manifest{kind: typeMeta.Kind, raw: document})
+	}
+
+	w.run.bundlesMu.Lock()
+	defer w.run.bundlesMu.Unlock()
+	w.bundle.artifacts[w.itemPath] = manifests
+	return nil
+}
+
+// installOrder is the order in which kinds are applied, as with Helm.
+var installOrder = []string{
+	"PriorityClass",
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+	"ValidatingAdmissionPolicy",
+	"ValidatingAdmissionPolicyBinding",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+// installRank returns the rank of the given kind in the install order, with
+// unknown kinds last.
+func installRank(kind string) int {
+	if rank := slices.Index(installOrder, kind); rank >= 0 {
+		return rank
+	}
+	return len(installOrder)
+}
+
+// writeBundles writes the bundles output during the run.
+func (r *runState) writeBundles() error {
+	r.bundlesMu.Lock()
+	defer r.bundlesMu.Unlock()
+	defer clear(r.bundles)
+
+	var errs []error
+	for path, pending := range r.bundles {
+		var manifests []manifest
+		if pending.namespace != "" {
+			manifests = append(manifests, manifest{
+				kind: "Namespace",
+				raw:  fmt.Appendf(nil, "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n", pending.namespace),
+			})
+		}
+		for _, itemPath := range slices.Sorted(maps.Keys(pending.artifacts)) {
+			manifests = append(manifests, pending.artifacts[itemPath]...)
+		}
+		slices.SortStableFunc(manifests, func(a, b manifest) int {
+			return installRank(a.kind) - installRank(b.kind)
+		})
+
+		var out bytes.Buffer
+		for _, manifest := range manifests {
+			out.WriteString("---\n")
+			out.Write(bytes.TrimLeft(manifest.raw, "\n"))
+			if !bytes.HasSuffix(manifest.raw, []byte("\n")) {
+				out.WriteString("\n")
+			}
+		}
+		file, err := r.createFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := file.Write(out.Bytes()); err != nil {
+			errs = append(errs, err)
+		}
+		if err := file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import "sync"
+
+// Cache holds the values shared by the Generators of a run, so that they're
+// computed once for all of them (e.g. the schemata of the types of the
+// roots).  It's safe for concurrent use, but the values it holds have to be
+// as well.
+type Cache struct {
+	values sync.Map
+}
+
+// cacheEntry initializes a value of a Cache once.
+type cacheEntry struct {
+	once  sync.Once
+	value any
+}
+
+// Get returns the value held under the given key, initialized with the
+// given function the first time.
+func (c *Cache) Get(key any, newValue func() any) any {
+	rawEntry, _ := c.values.LoadOrStore(key, &cacheEntry{})
+	entry := rawEntry.(*cacheEntry)
+	entry.once.Do(func() { entry.value = newValue() })
+	return entry.value
+}
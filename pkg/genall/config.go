@@ -0,0 +1,259 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFileName is the name of the configuration file of controller-gen,
+// looked up in the working directory when none is given explicitly.
+const ConfigFileName = "controller-gen.yaml"
+
+// Config declares the options of a run of controller-gen, as an alternative
+// to passing them all on the command line:
+//
+//	paths:
+//	- ./api/...
+//	generators:
+//	  object:
+//	    headerFile: hack/boilerplate.go.txt
+//	  crd:
+//	    maxDescLen: 0
+//	  rbac:
+//	    roleName: manager-role
+//	output:
+//	  crd:
+//	    dir: config/crd/bases
+//	  rbac:
+//	    artifacts:
+//	      config: config/rbac
+//
+// Generators without options are declared with an empty value (e.g.
+// "webhook: {}"), and the "only" option of a generator restricts the kinds it
+// processes (e.g. "only: [batch.tutorial.kubebuilder.io/v1/CronJob]").  Output rules without arguments are given by name (e.g.
+// "webhook: stdout"), and the default output rule is declared as the output
+// of "default".  Custom markers are declared in the markers section (see
+// CustomMarker), and the values of the variables interpolated in the string
+// values of markers (see markers.Variables) in the variables section:
+//
+//	variables:
+//	  WEBHOOK_NAMESPACE: system
+type Config struct {
+	// Paths are the package roots, as per the paths option.
+	Paths []string `json:"paths,omitempty"`
+	// Generators are the options of the generators to run, by generator name.
+	Generators map[string]map[string]any `json:"generators,omitempty"`
+	// Output are the output rules, by generator name.
+	Output map[string]any `json:"output,omitempty"`
+	// Markers are the custom markers of the project.
+	Markers []CustomMarker `json:"markers,omitempty"`
+	// Variables are the values of the variables interpolated in the string
+	// values of markers, by name.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// LoadConfig reads the configuration file at the given path.
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rawJSON, err := yaml.YAMLToJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse configuration file %s: %w", path, err)
+	}
+	// numbers are kept as written, so that they can be passed on as options
+	decoder := json.NewDecoder(bytes.NewReader(rawJSON))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	config := &Config{}
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("unable to parse configuration file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Options returns the command line options equivalent to the configuration,
+// to be passed to FromOptions.
+func (c Config) Options() ([]string, error) {
+	var options []string
+	if len(c.Paths) > 0 {
+		paths := make([]any, len(c.Paths))
+		for i, path := range c.Paths {
+			paths[i] = path
+		}
+		options = append(options, "paths="+optionValue(paths))
+	}
+
+	for _, genName := range slices.Sorted(maps.Keys(c.Generators)) {
+		// the kinds filter is an option of its own
+		genArgs := maps.Clone(c.Generators[genName])
+		only, hasOnly := genArgs["only"]
+		delete(genArgs, "only")
+
+		args, err := optionArgs(genArgs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options of generator %q: %w", genName, err)
+		}
+		options = append(options, genName+args)
+		if hasOnly {
+			options = append(options, genName+":only="+optionValue(only))
+		}
+	}
+
+	for _, genName := range slices.Sorted(maps.Keys(c.Output)) {
+		prefix := "output:" + genName + ":"
+		if genName == "default" {
+			prefix = "output:"
+		}
+		option, err := outputOption(prefix, c.Output[genName])
+		if err != nil {
+			return nil, fmt.Errorf("invalid output rule of %q: %w", genName, err)
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// outputOption returns the option of the given output rule, which is either
+// the name of a rule without arguments, or a map of the name of the rule to
+// its arguments.
+func outputOption(prefix string, rule any) (string, error) {
+	switch rule := rule.(type) {
+	case string:
+		return prefix + rule, nil
+	case map[string]any:
+		if len(rule) != 1 {
+			return "", fmt.Errorf("expected a single output rule, got %d", len(rule))
+		}
+		for ruleName, args := range rule {
+			switch args := args.(type) {
+			case nil:
+				return prefix + ruleName, nil
+			case map[string]any:
+				optArgs, err := optionArgs(args)
+				if err != nil {
+					return "", err
+				}
+				return prefix + ruleName + optArgs, nil
+			default:
+				// the anonymous argument of the rule, like the directory of "dir"
+				return prefix + ruleName + "=" + optionValue(args), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("expected the name of an output rule or a map of it to its arguments, got %v", rule)
+}
+
+// optionArgs returns the given arguments of an option, in the marker syntax
+// (":name=value,name=value"), or nothing if there are none.
+func optionArgs(args map[string]any) (string, error) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	parts := make([]string, 0, len(args))
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		if args[name] == nil {
+			return "", fmt.Errorf("argument %q has no value", name)
+		}
+		parts = append(parts, name+"="+optionValue(args[name]))
+	}
+	return ":" + strings.Join(parts, ","), nil
+}
+
+// optionValue returns the given value, as decoded from a configuration file,
+// in the marker syntax.
+func optionValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return strconv.Quote(value)
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		return value.String()
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = optionValue(item)
+		}
+		return "{" + strings.Join(items, ",") + "}"
+	case map[string]any:
+		items := make([]string, 0, len(value))
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			items = append(items, strconv.Quote(key)+": "+optionValue(value[key]))
+		}
+		return "{" + strings.Join(items, ",") + "}"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// OverrideOptions returns the given base options (e.g. of a configuration
+// file), overridden by the given options (e.g. of the command line): the
+// options of a generator, its output rule, the default output rule and the
+// paths replace the corresponding base options.
+func OverrideOptions(optionsRegistry *markers.Registry, base, overrides []string) ([]string, error) {
+	overridden := make(map[string]bool)
+	for _, rawOpt := range overrides {
+		key, err := optionKey(optionsRegistry, rawOpt)
+		if err != nil {
+			return nil, err
+		}
+		overridden[key] = true
+	}
+
+	var options []string
+	for _, rawOpt := range base {
+		key, err := optionKey(optionsRegistry, rawOpt)
+		if err != nil {
+			return nil, err
+		}
+		if !overridden[key] {
+			options = append(options, rawOpt)
+		}
+	}
+	return append(options, overrides...), nil
+}
+
+// optionKey returns the key identifying what the given option declares: the
+// name of its marker, or "output:<generator>" for all the output rules of a
+// generator.
+func optionKey(optionsRegistry *markers.Registry, rawOpt string) (string, error) {
+	if !strings.HasPrefix(rawOpt, "+") {
+		rawOpt = "+" + rawOpt
+	}
+	defn := optionsRegistry.Lookup(rawOpt, markers.DescribesPackage)
+	if defn == nil {
+		return "", fmt.Errorf("unknown option %q", rawOpt[1:])
+	}
+	if strings.HasPrefix(defn.Name, "output:") {
+		_, genName := splitOutputRuleOption(defn.Name)
+		return "output:" + genName, nil
+	}
+	return defn.Name, nil
+}
@@ -0,0 +1,563 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/structured-merge-diff/v6/schema"
+	"sigs.k8s.io/structured-merge-diff/v6/typed"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// applySchemaSuffix is the suffix of the structured-merge-diff schemas of the
+// kinds of an API group, as written by the smdschema generator.
+const applySchemaSuffix = ".schema.yaml"
+
+// objectMetaType is the name of ObjectMeta in structured-merge-diff schemas,
+// whose fields are restricted in CRDs.
+const objectMetaType = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+
+// runArtifacts are the manifests written during a run, for checking that
+// they're consistent with each other once all the Generators ran.  They're
+// guarded by mu, since generators may run concurrently.
+type runArtifacts struct {
+	mu           sync.Mutex
+	crds         []crdArtifact
+	roles        []roleArtifact
+	webhooks     []webhookArtifact
+	applySchemas []applySchemaArtifact
+}
+
+// crdArtifact is a CRD written during a run, or a patch of one (e.g. enabling
+// its conversion webhook).
+type crdArtifact struct {
+	path string
+	crd  apiextensionsv1.CustomResourceDefinition
+}
+
+// isPatch returns true if the artifact only patches a CRD.
+func (a crdArtifact) isPatch() bool {
+	return a.crd.Spec.Names.Plural == ""
+}
+
+// roleArtifact is a ClusterRole or Role written during a run.
+type roleArtifact struct {
+	path      string
+	kind      string
+	name      string
+	namespace string
+	rules     []rbacv1.PolicyRule
+}
+
+// String describes the role in errors.
+func (a roleArtifact) String() string {
+	if a.namespace != "" {
+		return fmt.Sprintf("%s %s in namespace %s", a.kind, a.name, a.namespace)
+	}
+	return a.kind + " " + a.name
+}
+
+// webhookArtifact is an admission webhook of a configuration written during
+// a run.
+type webhookArtifact struct {
+	path         string
+	config       string
+	name         string
+	clientConfig admissionregistrationv1.WebhookClientConfig
+}
+
+// applySchemaArtifact is the structured-merge-diff schema of the kinds of an
+// API group, as embedded in their apply configurations, written during a
+// run.
+type applySchemaArtifact struct {
+	path   string
+	group  string
+	schema *schema.Schema
+}
+
+// consistencyOutputRule is an output rule recording the manifests written
+// through it, for checking their consistency.
+type consistencyOutputRule struct {
+	OutputRule
+	artifacts *runArtifacts
+}
+
+func (o consistencyOutputRule) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	wr, err := o.OutputRule.Open(pkg, itemPath)
+	if err != nil {
+		return nil, err
+	}
+	if ext := filepath.Ext(itemPath); pkg != nil || ext != ".yaml" && ext != ".yml" {
+		// only manifests are checked
+		return wr, nil
+	}
+	return &consistencyWriter{WriteCloser: wr, artifacts: o.artifacts, itemPath: itemPath}, nil
+}
+
+// consistencyWriter records the manifests written to a file of a
+// consistencyOutputRule when closed.
+type consistencyWriter struct {
+	io.WriteCloser
+	contents  bytes.Buffer
+	artifacts *runArtifacts
+	itemPath  string
+}
+
+func (w *consistencyWriter) Write(p []byte) (int, error) {
+	w.contents.Write(p)
+	return w.WriteCloser.Write(p)
+}
+
+func (w *consistencyWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	if err := w.artifacts.add(w.itemPath, w.contents.Bytes()); err != nil {
+		return fmt.Errorf("unable to check the consistency of %s: %w", w.itemPath, err)
+	}
+	return nil
+}
+
+// add records the CRDs, roles, webhook configurations and apply
+// configuration schemas of the given manifest file.
+func (a *runArtifacts) add(itemPath string, contents []byte) error {
+	if strings.HasSuffix(itemPath, applySchemaSuffix) {
+		parser, err := typed.NewParser(typed.YAMLObject(contents))
+		if err != nil {
+			return err
+		}
+		group := strings.TrimSuffix(filepath.Base(itemPath), applySchemaSuffix)
+		if group == "core" {
+			group = ""
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.applySchemas = append(a.applySchemas, applySchemaArtifact{path: itemPath, group: group, schema: &parser.Schema})
+		return nil
+	}
+
+	var crds []crdArtifact
+	var roles []roleArtifact
+	var webhooks []webhookArtifact
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(contents)))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(document, &typeMeta); err != nil {
+			return err
+		}
+		switch typeMeta.Kind {
+		case "CustomResourceDefinition":
+			artifact := crdArtifact{path: itemPath}
+			if err := yaml.Unmarshal(document, &artifact.crd); err != nil {
+				return err
+			}
+			crds = append(crds, artifact)
+		case "ClusterRole", "Role":
+			var role rbacv1.Role // a ClusterRole only adds its aggregation rule
+			if err := yaml.Unmarshal(document, &role); err != nil {
+				return err
+			}
+			roles = append(roles, roleArtifact{path: itemPath, kind: typeMeta.Kind, name: role.Name, namespace: role.Namespace, rules: role.Rules})
+		case "MutatingWebhookConfiguration":
+			var cfg admissionregistrationv1.MutatingWebhookConfiguration
+			if err := yaml.Unmarshal(document, &cfg); err != nil {
+				return err
+			}
+			for _, webhook := range cfg.Webhooks {
+				webhooks = append(webhooks, webhookArtifact{path: itemPath, config: cfg.Name, name: webhook.Name, clientConfig: webhook.ClientConfig})
+			}
+		case "ValidatingWebhookConfiguration":
+			var cfg admissionregistrationv1.ValidatingWebhookConfiguration
+			if err := yaml.Unmarshal(document, &cfg); err != nil {
+				return err
+			}
+			for _, webhook := range cfg.Webhooks {
+				webhooks = append(webhooks, webhookArtifact{path: itemPath, config: cfg.Name, name: webhook.Name, clientConfig: webhook.ClientConfig})
+			}
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.crds = append(a.crds, crds...)
+	a.roles = append(a.roles, roles...)
+	a.webhooks = append(a.webhooks, webhooks...)
+	return nil
+}
+
+// check cross-checks the recorded artifacts, returning an error for each
+// one that drifted from the others:
+//
+//   - the conversion webhooks of CRDs must patch CRDs of the run, and be
+//     served by the service of the admission webhooks of the run, on a path
+//     of their own;
+//   - the roles that can write a kind of the run must be able to update
+//     its finalizers, and its status if it has a status subresource;
+//   - the apply configuration schemas must have the fields, list types and
+//     map types of the CRDs of their group.
+//
+// Artifacts are only checked against the others of the run, e.g. roles are
+// only checked if the run generated CRDs.
+func (a *runArtifacts) check() []error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// generators may have run concurrently, so the artifacts are checked in
+	// a stable order
+	slices.SortStableFunc(a.crds, func(x, y crdArtifact) int { return strings.Compare(x.path, y.path) })
+	slices.SortStableFunc(a.roles, func(x, y roleArtifact) int { return strings.Compare(x.path, y.path) })
+	slices.SortStableFunc(a.webhooks, func(x, y webhookArtifact) int { return strings.Compare(x.path, y.path) })
+	slices.SortStableFunc(a.applySchemas, func(x, y applySchemaArtifact) int { return strings.Compare(x.path, y.path) })
+
+	var errs []error
+	errs = append(errs, a.checkConversionWebhooks()...)
+	errs = append(errs, a.checkRoles()...)
+	errs = append(errs, a.checkApplySchemas()...)
+	return errs
+}
+
+// generatedCRD returns the (complete) CRD of the given name generated during
+// the run, if any.
+func (a *runArtifacts) generatedCRD(name string) (crdArtifact, bool) {
+	for _, artifact := range a.crds {
+		if !artifact.isPatch() && artifact.crd.Name == name {
+			return artifact, true
+		}
+	}
+	return crdArtifact{}, false
+}
+
+// checkConversionWebhooks checks that the conversion webhooks of the CRDs of
+// the run are served along with its admission webhooks.
+func (a *runArtifacts) checkConversionWebhooks() []error {
+	generatedCRDs := slices.ContainsFunc(a.crds, func(artifact crdArtifact) bool { return !artifact.isPatch() })
+
+	var errs []error
+	for _, artifact := range a.crds {
+		conversion := artifact.crd.Spec.Conversion
+		if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+			continue
+		}
+		if artifact.isPatch() && generatedCRDs {
+			if _, found := a.generatedCRD(artifact.crd.Name); !found {
+				errs = append(errs, fmt.Errorf("%s: the conversion webhook patches the CRD %s, which wasn't generated by the run; run the crd and webhook generators over the same packages and kinds", artifact.path, artifact.crd.Name))
+			}
+		}
+
+		service := conversion.Webhook.ClientConfig.Service
+		if service == nil {
+			// served by URL, outside of the cluster
+			continue
+		}
+		var services []string
+		served := false
+		for _, webhook := range a.webhooks {
+			webhookService := webhook.clientConfig.Service
+			if webhookService == nil {
+				continue
+			}
+			if webhookService.Name != service.Name || webhookService.Namespace != service.Namespace || servicePort(webhookService.Port) != servicePort(service.Port) {
+				services = append(services, serviceName(webhookService.Namespace, webhookService.Name, webhookService.Port))
+				continue
+			}
+			served = true
+			if webhookService.Path != nil && service.Path != nil && *webhookService.Path == *service.Path {
+				errs = append(errs, fmt.Errorf("%s: the conversion webhook of %s is served on %s, the path of webhook %s of %s in %s; set the conversionPath of the webhook generator to a path of its own", artifact.path, artifact.crd.Name, *service.Path, webhook.name, webhook.config, webhook.path))
+			}
+		}
+		if !served && len(services) > 0 {
+			slices.Sort(services)
+			errs = append(errs, fmt.Errorf("%s: the conversion webhook of %s is served by service %s, which serves none of the webhooks of the run (served by %s); serve the conversion and admission webhooks from the same service", artifact.path, artifact.crd.Name, serviceName(service.Namespace, service.Name, service.Port), strings.Join(slices.Compact(services), ", ")))
+		}
+	}
+	return errs
+}
+
+// servicePort returns the given port of a service reference, defaulting to
+// 443 as the API server does.
+func servicePort(port *int32) int32 {
+	if port == nil {
+		return 443
+	}
+	return *port
+}
+
+// serviceName describes the given service reference in errors.
+func serviceName(namespace, name string, port *int32) string {
+	return fmt.Sprintf("%s/%s:%d", namespace, name, servicePort(port))
+}
+
+// checkRoles checks that the roles that can write the kinds of the CRDs of
+// the run can also update their finalizers and status, as their controllers
+// do.
+func (a *runArtifacts) checkRoles() []error {
+	var errs []error
+	for _, artifact := range a.crds {
+		if artifact.isPatch() {
+			continue
+		}
+		crd := artifact.crd
+		group, plural := crd.Spec.Group, crd.Spec.Names.Plural
+		hasStatus := slices.ContainsFunc(crd.Spec.Versions, func(version apiextensionsv1.CustomResourceDefinitionVersion) bool {
+			return version.Subresources != nil && version.Subresources.Status != nil
+		})
+		for _, role := range a.roles {
+			if !grantsAny(role.rules, group, plural, "create", "update", "patch") {
+				// doesn't manage the kind
+				continue
+			}
+			if missing := missingVerbs(role.rules, group, plural+"/finalizers", "update"); len(missing) > 0 {
+				errs = append(errs, fmt.Errorf("%s: %s can write %s, but lacks %s on their finalizers; add // +kubebuilder:rbac:groups=%s,resources=%s/finalizers,verbs=update", role.path, role, crd.Name, strings.Join(missing, ", "), group, plural))
+			}
+			if !hasStatus {
+				continue
+			}
+			if missing := missingVerbs(role.rules, group, plural+"/status", "get", "update", "patch"); len(missing) > 0 {
+				errs = append(errs, fmt.Errorf("%s: %s can write %s, but lacks %s on their status; add // +kubebuilder:rbac:groups=%s,resources=%s/status,verbs=get;update;patch", role.path, role, crd.Name, strings.Join(missing, ", "), group, plural))
+			}
+		}
+	}
+	return errs
+}
+
+// grantsAny returns true if the given rules grant any of the given verbs on
+// the given resource.
+func grantsAny(rules []rbacv1.PolicyRule, group, resource string, verbs ...string) bool {
+	return len(missingVerbs(rules, group, resource, verbs...)) < len(verbs)
+}
+
+// missingVerbs returns the given verbs that the given rules don't grant on the
+// given resource.
+func missingVerbs(rules []rbacv1.PolicyRule, group, resource string, verbs ...string) []string {
+	var missing []string
+	for _, verb := range verbs {
+		granted := slices.ContainsFunc(rules, func(rule rbacv1.PolicyRule) bool {
+			return matchesRule(rule.APIGroups, group, rbacv1.APIGroupAll) &&
+				matchesResource(rule.Resources, resource) &&
+				matchesRule(rule.Verbs, verb, rbacv1.VerbAll)
+		})
+		if !granted {
+			missing = append(missing, verb)
+		}
+	}
+	return missing
+}
+
+// matchesRule returns true if the given values of a rule contain the given
+// value, or the given wildcard.
+func matchesRule(values []string, value, wildcard string) bool {
+	return slices.Contains(values, value) || slices.Contains(values, wildcard)
+}
+
+// matchesResource returns true if the given resources of a rule contain the
+// given resource (possibly a subresource), including by wildcard.
+func matchesResource(resources []string, resource string) bool {
+	if matchesRule(resources, resource, rbacv1.ResourceAll) {
+		return true
+	}
+	if _, subresource, isSubresource := strings.Cut(resource, "/"); isSubresource {
+		return slices.Contains(resources, rbacv1.ResourceAll+"/"+subresource)
+	}
+	return false
+}
+
+// checkApplySchemas checks that the apply configuration schemas of the run
+// match the schemas of the CRDs of their group.
+func (a *runArtifacts) checkApplySchemas() []error {
+	var errs []error
+	for _, artifact := range a.crds {
+		if artifact.isPatch() {
+			continue
+		}
+		crd := artifact.crd
+		for _, applySchema := range a.applySchemas {
+			if applySchema.group != crd.Spec.Group {
+				continue
+			}
+			for _, version := range crd.Spec.Versions {
+				if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+					continue
+				}
+				typeName, found := kindTypeName(applySchema.schema, crd.Spec.Names.Kind, version.Name)
+				if !found {
+					errs = append(errs, fmt.Errorf("%s: %s has no type for version %s of %s; run the crd and smdschema generators over the same packages and kinds", applySchema.path, applySchema.group, version.Name, crd.Name))
+					continue
+				}
+				if typeName == "" {
+					// ambiguous, e.g. packages not named after their version
+					continue
+				}
+				comparison := schemaComparison{schema: applySchema.schema}
+				comparison.compare("", version.Schema.OpenAPIV3Schema, schema.TypeRef{NamedType: &typeName})
+				if len(comparison.drifts) > 0 {
+					errs = append(errs, fmt.Errorf("%s: version %s of %s drifted from its apply configuration schema in %s: %s; run the crd and smdschema generators over the same packages with the same options", artifact.path, version.Name, crd.Name, applySchema.path, strings.Join(comparison.drifts, ", ")))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// kindTypeName returns the name of the type of the given version of the given
+// kind in the given schema, named after the Go package of the kind, or an
+// empty name if it's ambiguous.  It returns false if there's no type of the
+// kind at all.
+func kindTypeName(smdSchema *schema.Schema, kind, version string) (string, bool) {
+	var candidates []string
+	for _, typ := range smdSchema.Types {
+		if strings.HasSuffix(typ.Name, "."+kind) {
+			candidates = append(candidates, typ.Name)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	// the packages of the versions of a kind are usually named after them
+	var inVersion []string
+	for _, candidate := range candidates {
+		if strings.HasSuffix(candidate, "."+version+"."+kind) {
+			inVersion = append(inVersion, candidate)
+		}
+	}
+	if len(inVersion) == 1 {
+		return inVersion[0], true
+	}
+	return "", true
+}
+
+// schemaComparison compares the schema of a CRD with the structured-merge-diff
+// schema of its kind, collecting the differences that matter to apply
+// configurations: the fields, and the types of lists and maps.
+type schemaComparison struct {
+	schema *schema.Schema
+	drifts []string
+}
+
+// compare compares the given CRD schema with the given type of the
+// structured-merge-diff schema, at the given path.
+func (c *schemaComparison) compare(path string, props *apiextensionsv1.JSONSchemaProps, ref schema.TypeRef) {
+	if props.XPreserveUnknownFields != nil && *props.XPreserveUnknownFields || props.XEmbeddedResource || props.XIntOrString {
+		// opaque to apply configurations
+		return
+	}
+	if ref.NamedType != nil && (strings.HasPrefix(*ref.NamedType, "__untyped") || *ref.NamedType == objectMetaType) {
+		return
+	}
+	atom, found := c.schema.Resolve(ref)
+	if !found {
+		return
+	}
+	display := path
+	if display == "" {
+		display = "the object"
+	}
+
+	switch {
+	case atom.List != nil:
+		crdType := "atomic"
+		if props.XListType != nil {
+			crdType = *props.XListType
+		}
+		applyType := "atomic"
+		if atom.List.ElementRelationship == schema.Associative {
+			applyType = "set"
+			if len(atom.List.Keys) > 0 {
+				applyType = "map"
+			}
+		}
+		switch {
+		case crdType != applyType:
+			c.drifts = append(c.drifts, fmt.Sprintf("%s has list type %s in the CRD, but %s in the apply configurations", display, crdType, applyType))
+		case crdType == "map" && !slices.Equal(props.XListMapKeys, atom.List.Keys):
+			c.drifts = append(c.drifts, fmt.Sprintf("%s is keyed by %s in the CRD, but by %s in the apply configurations", display, strings.Join(props.XListMapKeys, ", "), strings.Join(atom.List.Keys, ", ")))
+		}
+		if props.Items != nil && props.Items.Schema != nil {
+			c.compare(path+"[*]", props.Items.Schema, atom.List.ElementType)
+		}
+	case atom.Map != nil:
+		crdAtomic := props.XMapType != nil && *props.XMapType == "atomic"
+		applyAtomic := atom.Map.ElementRelationship == schema.Atomic
+		if crdAtomic != applyAtomic {
+			c.drifts = append(c.drifts, fmt.Sprintf("%s has map type %s in the CRD, but %s in the apply configurations", display, mapTypeName(crdAtomic), mapTypeName(applyAtomic)))
+		}
+		if len(atom.Map.Fields) == 0 {
+			if props.AdditionalProperties != nil && props.AdditionalProperties.Schema != nil {
+				c.compare(path+"[*]", props.AdditionalProperties.Schema, atom.Map.ElementType)
+			}
+			return
+		}
+		if len(props.Properties) == 0 {
+			// e.g. the metadata of the kind, whose fields aren't in CRDs
+			return
+		}
+		for _, field := range atom.Map.Fields {
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			fieldProps, found := props.Properties[field.Name]
+			if !found {
+				c.drifts = append(c.drifts, fmt.Sprintf("field %s is in the apply configurations, but not in the CRD", fieldPath))
+				continue
+			}
+			c.compare(fieldPath, &fieldProps, field.Type)
+		}
+		for _, name := range slices.Sorted(maps.Keys(props.Properties)) {
+			if !slices.ContainsFunc(atom.Map.Fields, func(field schema.StructField) bool { return field.Name == name }) {
+				fieldPath := name
+				if path != "" {
+					fieldPath = path + "." + name
+				}
+				c.drifts = append(c.drifts, fmt.Sprintf("field %s is in the CRD, but not in the apply configurations", fieldPath))
+			}
+		}
+	}
+}
+
+// mapTypeName names the map type of a map or object in drifts.
+func mapTypeName(atomic bool) string {
+	if atomic {
+		return "atomic"
+	}
+	return "granular"
+}
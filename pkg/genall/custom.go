@@ -0,0 +1,240 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// CustomMarker declares a marker of a project, collected like the markers of
+// the generators without being built into controller-gen, in the markers
+// section of the configuration file:
+//
+//	markers:
+//	- name: acme:owner
+//	  target: type
+//	  type: string
+//	  extension: ownership
+//	  help: is the team owning the type.
+//	- name: acme:retention
+//	  target: field
+//	  arguments:
+//	  - name: days
+//	    type: int
+//	  - name: archive
+//	    type: bool
+//	    optional: true
+//
+// Like any other marker, custom markers are sent to plugins along with the
+// type graph, with their values as JSON, and they can be passed through to
+// the generators consuming a named extension (see
+// GenerationContext.ExtensionMarkers).
+type CustomMarker struct {
+	// Name is the name of the marker, e.g. acme:owner for +acme:owner.
+	Name string `json:"name"`
+	// Target is what the marker describes: "package", "type" or "field".
+	Target string `json:"target"`
+	// Type is the type of the single argument of the marker, unless it has
+	// named Arguments:
+	//
+	//   - "any" (the default), for an argument of any type, as with
+	//     +kubebuilder:default;
+	//
+	//   - "flag", for markers without arguments, whose value is true;
+	//
+	//   - "raw", for the raw text of the argument;
+	//
+	//   - "string", "int", "bool", "[]string" or "[]int";
+	//
+	//   - "cel", for a CEL expression, syntax-checked when parsing the marker.
+	Type string `json:"type,omitempty"`
+	// Arguments are the named arguments of the marker, e.g.
+	// +acme:retention:days=30,archive=true.
+	Arguments []CustomArgument `json:"arguments,omitempty"`
+	// Extension is the name of the extension the marker is passed through
+	// to, if any.
+	Extension string `json:"extension,omitempty"`
+	// Help is the help text of the marker, shown with the markers of the
+	// generators (e.g. with controller-gen -w).
+	Help string `json:"help,omitempty"`
+}
+
+// CustomArgument is a named argument of a custom marker.
+type CustomArgument struct {
+	// Name is the name of the argument.
+	Name string `json:"name"`
+	// Type is the type of the argument: "string", "int", "bool", "[]string",
+	// "[]int" or "cel".
+	Type string `json:"type"`
+	// Optional marks that the argument can be omitted.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// customArgumentTypes are the Go types of the types of the arguments of
+// custom markers.
+var customArgumentTypes = map[string]reflect.Type{
+	"string":   reflect.TypeFor[string](),
+	"int":      reflect.TypeFor[int](),
+	"bool":     reflect.TypeFor[bool](),
+	"[]string": reflect.TypeFor[[]string](),
+	"[]int":    reflect.TypeFor[[]int](),
+	"cel":      reflect.TypeFor[markers.CEL](),
+}
+
+// customAny is the value of custom markers of type "any".
+type customAny struct {
+	Value any
+}
+
+func (v customAny) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Value)
+}
+
+// customFlag is the value of custom markers of type "flag".
+type customFlag struct{}
+
+func (customFlag) MarshalJSON() ([]byte, error) {
+	return []byte("true"), nil
+}
+
+// RegisterCustomMarkers registers the given custom markers into the given
+// registry.
+func RegisterCustomMarkers(into *markers.Registry, customMarkers []CustomMarker) error {
+	for _, marker := range customMarkers {
+		def, err := marker.definition()
+		if err != nil {
+			return err
+		}
+		if existing := into.Lookup("+"+marker.Name, def.Target); existing != nil && existing.Name == marker.Name {
+			return fmt.Errorf("custom marker %q is already a marker", marker.Name)
+		}
+		if err := into.Register(def); err != nil {
+			return err
+		}
+		into.AddHelp(def, &markers.DefinitionHelp{
+			Category:     "custom",
+			DetailedHelp: markers.DetailedHelp{Summary: marker.Help},
+		})
+	}
+	return nil
+}
+
+// definition returns the definition of the marker.
+func (m CustomMarker) definition() (*markers.Definition, error) {
+	var target markers.TargetType
+	switch m.Target {
+	case "package":
+		target = markers.DescribesPackage
+	case "type":
+		target = markers.DescribesType
+	case "field":
+		target = markers.DescribesField
+	default:
+		return nil, fmt.Errorf("unknown target %q of custom marker %q", m.Target, m.Name)
+	}
+
+	if len(m.Arguments) > 0 {
+		if m.Type != "" {
+			return nil, fmt.Errorf("custom marker %q can't have both a type and arguments", m.Name)
+		}
+		fields := make([]reflect.StructField, len(m.Arguments))
+		seen := make(map[string]bool, len(m.Arguments))
+		for i, arg := range m.Arguments {
+			typ, known := customArgumentTypes[arg.Type]
+			if !known {
+				return nil, fmt.Errorf("unknown type %q of argument %q of custom marker %q", arg.Type, arg.Name, m.Name)
+			}
+			if arg.Name == "" || seen[arg.Name] {
+				return nil, fmt.Errorf("custom marker %q has an empty or duplicate argument name %q", m.Name, arg.Name)
+			}
+			seen[arg.Name] = true
+			markerTag, jsonTag := arg.Name, arg.Name
+			if arg.Optional {
+				markerTag += ",optional"
+				jsonTag += ",omitempty"
+			}
+			fields[i] = reflect.StructField{
+				// the names of the arguments are kept in the tags, as they
+				// needn't be Go identifiers
+				Name: fmt.Sprintf("Arg%d", i),
+				Type: typ,
+				Tag:  reflect.StructTag(fmt.Sprintf("marker:%q json:%q", markerTag, jsonTag)),
+			}
+		}
+		return markers.MakeDefinition(m.Name, target, reflect.New(reflect.StructOf(fields)).Elem().Interface())
+	}
+
+	switch m.Type {
+	case "", "any":
+		return markers.MakeAnyTypeDefinition(m.Name, target, customAny{})
+	case "flag":
+		return markers.MakeDefinition(m.Name, target, customFlag{})
+	case "raw":
+		return markers.MakeDefinition(m.Name, target, markers.RawArguments(nil))
+	default:
+		typ, known := customArgumentTypes[m.Type]
+		if !known {
+			return nil, fmt.Errorf("unknown type %q of custom marker %q", m.Type, m.Name)
+		}
+		return markers.MakeDefinition(m.Name, target, reflect.Zero(typ).Interface())
+	}
+}
+
+// ExtensionMarkers returns the values of the custom markers passed through to
+// the given extension among the given marker values (e.g. of a type), by
+// marker name.  Values of markers with arguments are maps of the names of the
+// arguments to their values, flags are true and raw arguments are strings.
+func (g *GenerationContext) ExtensionMarkers(extension string, values markers.MarkerValues) map[string][]any {
+	res := make(map[string][]any)
+	for _, marker := range g.CustomMarkers {
+		if marker.Extension != extension {
+			continue
+		}
+		for _, value := range values[marker.Name] {
+			res[marker.Name] = append(res[marker.Name], customValue(value))
+		}
+	}
+	return res
+}
+
+// customValue returns the plain value of the given value of a custom marker.
+func customValue(value any) any {
+	switch value := value.(type) {
+	case customAny:
+		return value.Value
+	case customFlag:
+		return true
+	case markers.RawArguments:
+		return string(value)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Struct {
+		return value
+	}
+	args := make(map[string]any, rv.NumField())
+	for i := range rv.NumField() {
+		name, _, _ := strings.Cut(rv.Type().Field(i).Tag.Get("json"), ",")
+		args[name] = rv.Field(i).Interface()
+	}
+	return args
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"encoding/json"
+	"io"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// DiagnosticsFormat is the format in which a Runtime reports errors and
+// warnings to its ErrorWriter.
+type DiagnosticsFormat string
+
+const (
+	// DiagnosticsText reports errors and warnings as free-form text.
+	DiagnosticsText DiagnosticsFormat = "text"
+	// DiagnosticsJSON reports errors and warnings as JSON records (see
+	// loader.Diagnostic), one per line, so that editors and CI annotators
+	// can show them inline.
+	DiagnosticsJSON DiagnosticsFormat = "json"
+)
+
+// WriteDiagnostics writes the given diagnostics to the given writer as JSON
+// records, one per line.
+func WriteDiagnostics(out io.Writer, diags ...loader.Diagnostic) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	for _, diag := range diags {
+		if err := encoder.Encode(diag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasErrors returns true if any of the given diagnostics is an error.
+func hasErrors(diags []loader.Diagnostic) bool {
+	for _, diag := range diags {
+		if diag.Severity == loader.SeverityError {
+			return true
+		}
+	}
+	return false
+}
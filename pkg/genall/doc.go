@@ -24,6 +24,10 @@ limitations under the License.
 // Each generator can be considered to be the output type of a marker, for easy
 // command line parsing.
 //
+// Generators output the same bytes for the same inputs, however the run goes:
+// whatever they collect in maps is output (or applied, for markers) in a
+// sorted order, never in the order of iteration of the maps.
+//
 // # Output and Input
 //
 // Generators output artifacts via an OutputRule.  OutputRules know how to
@@ -37,6 +41,22 @@ limitations under the License.
 // OutputRules are defined for stdout, file writing, and sending to /dev/null
 // (useful for doing "type-checking" without actually saving the results).
 //
+// Generated Go files may be laid out with templates, e.g. to meet
+// style requirements on their header or package doc (see LoadGoTemplates).
+//
+// Hand-written additions to generated Go files (e.g. extra helper methods) are
+// kept when they're regenerated, if they're within a protected region, i.e.
+// between "// +controllertools:protected:begin" and
+// "// +controllertools:protected:end" comments.  Protected regions are kept at
+// the end of the regenerated files, and their imports are added.
+//
+// Generated files end their lines with the line endings of the Runtime (see
+// LineEndings), LF by default, whatever the platform and whatever the line
+// endings of their inputs (e.g. header files).  Neither do they refer to the
+// machine generating them (e.g. with absolute paths, or timestamps), and the
+// version of controller-gen they record may be pinned (see
+// GenerationContext.Version), so that they're identical across machines.
+//
 // InputRule defines custom input loading, but its shared across all
 // Generators.  There's currently only a filesystem implementation.
 //
@@ -50,6 +70,16 @@ limitations under the License.
 // skipping type-checking errors (since those are commonly caused by the
 // partial type-checking of loader.TypeChecker).
 //
+// Runs may be incremental (see Runtime.Changes), only regenerating the
+// artifacts of the roots affected by the files changed since a previous run.
+//
+// The versions of the dependencies of the modules of the roots are resolved
+// beforehand (see ModuleVersions), warning about the ones older than the
+// Generators require (see RequiresModules).
+//
+// Experimental behaviors are staged behind feature gates (see Feature), which
+// generators check with GenerationContext.FeatureGates.
+//
 // # Options
 //
 // The FromOptions (and associated helpers) function makes it easy to use generators
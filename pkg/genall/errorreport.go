@@ -0,0 +1,117 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"golang.org/x/tools/go/packages"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// errorGroup is a package, or a file of one, in the error report, with its
+// errors.
+type errorGroup struct {
+	name  string
+	diags []loader.Diagnostic
+}
+
+// reportErrors writes the error report of the run to the ErrorWriter, if
+// asked to and there were errors: the errors of the packages (except type
+// errors, as when printing them), grouped by package, then by file, and the
+// given errors of the run that aren't from any package.
+func (r *Runtime) reportErrors(runErrs []error) {
+	if !r.ErrorReport {
+		return
+	}
+	var diags []loader.Diagnostic
+	for _, diag := range loader.Diagnostics(r.Roots, packages.TypeError) {
+		if diag.Severity == loader.SeverityError {
+			diags = append(diags, diag)
+		}
+	}
+	for _, err := range runErrs {
+		diags = append(diags, loader.ErrorDiagnostic(err))
+	}
+	writeErrorReport(r.ErrorWriter, diags)
+}
+
+// writeErrorReport writes the given errors grouped by package, in the order
+// the packages were first reported in, then by file, in the order of their
+// positions in each file.  Errors without a package come last.
+func writeErrorReport(out io.Writer, diags []loader.Diagnostic) {
+	if len(diags) == 0 {
+		return
+	}
+	pkgs := groupErrors(diags, func(diag loader.Diagnostic) string { return diag.Package })
+	fmt.Fprintf(out, "\nerror report: %d error(s) in %d package(s)\n", len(diags), len(pkgs))
+	for _, pkg := range pkgs {
+		name := pkg.name
+		if name == "" {
+			name = "(no package)"
+		}
+		fmt.Fprintf(out, "%s: %d error(s)\n", name, len(pkg.diags))
+		for _, file := range groupErrors(pkg.diags, func(diag loader.Diagnostic) string { return diag.File }) {
+			if file.name != "" {
+				fmt.Fprintf(out, "  %s: %d error(s)\n", file.name, len(file.diags))
+			} else {
+				fmt.Fprintf(out, "  (no file): %d error(s)\n", len(file.diags))
+			}
+			slices.SortStableFunc(file.diags, func(a, b loader.Diagnostic) int {
+				if a.Line != b.Line {
+					return a.Line - b.Line
+				}
+				return a.Column - b.Column
+			})
+			for _, diag := range file.diags {
+				switch {
+				case diag.Line > 0 && diag.Column > 0:
+					fmt.Fprintf(out, "    %d:%d: %s\n", diag.Line, diag.Column, diag.Message)
+				case diag.Line > 0:
+					fmt.Fprintf(out, "    %d: %s\n", diag.Line, diag.Message)
+				default:
+					fmt.Fprintf(out, "    %s\n", diag.Message)
+				}
+			}
+		}
+	}
+}
+
+// groupErrors groups the given errors by the given key, in the order the
+// keys first appear in, except for the empty key, which comes last.
+func groupErrors(diags []loader.Diagnostic, key func(loader.Diagnostic) string) []errorGroup {
+	var groups []errorGroup
+	index := make(map[string]int)
+	for _, diag := range diags {
+		name := key(diag)
+		i, seen := index[name]
+		if !seen {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, errorGroup{name: name})
+		}
+		groups[i].diags = append(groups[i].diags, diag)
+	}
+	if i, hasEmpty := index[""]; hasEmpty && i != len(groups)-1 {
+		empty := groups[i]
+		groups = append(slices.Delete(groups, i, i+1), empty)
+	}
+	return groups
+}
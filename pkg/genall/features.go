@@ -0,0 +1,183 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Feature is an experimental behavior of controller-gen, which users opt into
+// (or out of) with --feature-gates, so that it can be previewed without
+// changing generator names.
+type Feature string
+
+// FeatureStage is the stage of a Feature.
+type FeatureStage string
+
+const (
+	// Alpha features are disabled by default, and may change or go away.
+	Alpha FeatureStage = "ALPHA"
+	// Beta features are enabled by default, but may still be disabled.
+	Beta FeatureStage = "BETA"
+)
+
+// FeatureSpec describes a Feature.
+type FeatureSpec struct {
+	// Default is true if the feature is enabled unless disabled explicitly.
+	Default bool
+	// Stage is the stage of the feature.
+	Stage FeatureStage
+	// Description describes the behavior enabled by the feature.
+	Description string
+}
+
+// ConcurrentGenerators runs the generators that don't need to run on their
+// own concurrently, bounded by the parallelism of the run.
+const ConcurrentGenerators Feature = "ConcurrentGenerators"
+
+// ConcurrentSchemas generates the schemata of independent types concurrently
+// in the crd generator, bounded by the parallelism of the run, so that large
+// packages don't generate their types one after another.
+const ConcurrentSchemas Feature = "ConcurrentSchemas"
+
+// ExportDataDependencies loads the types of the dependencies of the root
+// packages from compiler export data rather than type-checking their source,
+// when the generators only need their types (see NeedsOnlyDependencyTypes).
+const ExportDataDependencies Feature = "ExportDataDependencies"
+
+// ReleaseSyntax releases the syntax and type information of each root package
+// (and of the dependencies only it needs) once all the generators are done
+// with it (see GenerationContext.DoneWith), e.g. once its markers were
+// collected and its schemata or code generated, so that the memory of a run
+// over many packages is bounded by the packages being generated rather than
+// by all of them.
+const ReleaseSyntax Feature = "ReleaseSyntax"
+
+// knownFeatures are the known features, by name.  They're guarded by
+// featuresMu, since generators may add theirs from init functions of any
+// package.
+var (
+	knownFeatures = map[Feature]FeatureSpec{
+		ConcurrentGenerators: {
+			Default:     true,
+			Stage:       Beta,
+			Description: "run the generators concurrently, bounded by --parallelism",
+		},
+		ConcurrentSchemas: {
+			Default:     false,
+			Stage:       Alpha,
+			Description: "generate the schemata of independent types concurrently in the crd generator, bounded by --parallelism (reporting the errors found in no particular order)",
+		},
+		ExportDataDependencies: {
+			Default:     false,
+			Stage:       Alpha,
+			Description: "load the dependencies of the packages from compiler export data instead of source, when the generators don't need their markers",
+		},
+		ReleaseSyntax: {
+			Default:     false,
+			Stage:       Alpha,
+			Description: "release the syntax and type information of each package once all the generators are done with it, to bound the memory used over many packages",
+		},
+	}
+	featuresMu sync.RWMutex
+)
+
+// AddFeatures adds the given features to the known features, e.g. to stage
+// a risky change of a generator behind a feature gate.  It fails if a
+// feature is known already.
+func AddFeatures(features map[Feature]FeatureSpec) error {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	for name := range features {
+		if _, known := knownFeatures[name]; known {
+			return fmt.Errorf("feature %s is known already", name)
+		}
+	}
+	maps.Copy(knownFeatures, features)
+	return nil
+}
+
+// KnownFeatures returns the known features, by name.
+func KnownFeatures() map[Feature]FeatureSpec {
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+	return maps.Clone(knownFeatures)
+}
+
+// FeatureGates enable or disable features, by name.  Features not set are
+// enabled by default if their spec says so.
+type FeatureGates map[Feature]bool
+
+// ParseFeatureGates parses the given feature gates, each of the form
+// Feature=true|false.
+func ParseFeatureGates(gates []string) (FeatureGates, error) {
+	known := KnownFeatures()
+	parsed := make(FeatureGates, len(gates))
+	for _, gate := range gates {
+		name, rawEnabled, hasValue := strings.Cut(strings.TrimSpace(gate), "=")
+		if !hasValue {
+			return nil, fmt.Errorf("invalid feature gate %q, expected Feature=true|false", gate)
+		}
+		if _, isKnown := known[Feature(name)]; !isKnown {
+			return nil, fmt.Errorf("unknown feature %s (known features are %s)", name, strings.Join(featureNames(known), ", "))
+		}
+		enabled, err := strconv.ParseBool(rawEnabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q of feature gate %s, expected true or false", rawEnabled, name)
+		}
+		parsed[Feature(name)] = enabled
+	}
+	return parsed, nil
+}
+
+// Enabled returns true if the given feature is enabled, either explicitly or
+// by default.
+func (g FeatureGates) Enabled(feature Feature) bool {
+	if enabled, isSet := g[feature]; isSet {
+		return enabled
+	}
+	featuresMu.RLock()
+	defer featuresMu.RUnlock()
+	return knownFeatures[feature].Default
+}
+
+// FeatureGatesHelp describes the known features, one per line, e.g. for the
+// help of a --feature-gates flag.
+func FeatureGatesHelp() string {
+	known := KnownFeatures()
+	var lines []string
+	for _, name := range featureNames(known) {
+		spec := known[Feature(name)]
+		lines = append(lines, fmt.Sprintf("%s=true|false (%s - default=%t): %s", name, spec.Stage, spec.Default, spec.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// featureNames returns the sorted names of the given features.
+func featureNames(features map[Feature]FeatureSpec) []string {
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, string(name))
+	}
+	slices.Sort(names)
+	return names
+}
@@ -17,15 +17,24 @@ limitations under the License.
 package genall
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"golang.org/x/tools/go/packages"
 	rawyaml "gopkg.in/yaml.v2"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/controller-tools/pkg/version"
 )
 
 // Generators are a list of Generators.
@@ -71,6 +80,54 @@ type NeedsTypeChecking interface {
 	CheckFilter() loader.NodeFilter
 }
 
+// NeedsOnlyDependencyTypes indicates that a generator type-checking packages
+// only needs the types of the packages the root packages depend on, not their
+// syntax or markers, so that they may be loaded from compiler export data
+// (see the ExportDataDependencies feature) rather than from source.
+type NeedsOnlyDependencyTypes interface {
+	// OnlyDependencyTypes returns true if the generator never reads the
+	// syntax, type-checking information or markers of non-root packages.
+	OnlyDependencyTypes() bool
+}
+
+// OnlyDependencyTypes returns true if some Generators type-check packages,
+// and they all only need the types of the dependencies of the root packages
+// (see NeedsOnlyDependencyTypes).
+func (g Generators) OnlyDependencyTypes() bool {
+	checking := false
+	for _, gen := range g {
+		if _, needsChecking := (*gen).(NeedsTypeChecking); !needsChecking {
+			continue
+		}
+		checking = true
+		if typesOnly, ok := (*gen).(NeedsOnlyDependencyTypes); !ok || !typesOnly.OnlyDependencyTypes() {
+			return false
+		}
+	}
+	return checking
+}
+
+// NeedsExclusiveRun indicates that a particular generator can't run
+// concurrently with other generators, e.g. because it relies on global state
+// that isn't threadsafe (as gengo-based generators do), or reads the output of
+// the generators listed before it.  Such generators wait for the generators
+// listed before them to finish, and the generators listed after them wait for
+// them to finish.  They don't run concurrently with the ones of other
+// Runtimes either.
+type NeedsExclusiveRun interface {
+	// ExclusiveRun returns true if the generator needs to run on its own.
+	ExclusiveRun() bool
+}
+
+// NeedsAPIServer indicates that a particular generator starts an API server
+// from the envtest binaries (etcd and kube-apiserver) to run, e.g. to
+// validate its artifacts on it, so that missing binaries can be reported
+// before running it (see the doctor command of controller-gen).
+type NeedsAPIServer interface {
+	// NeedsAPIServer returns true if the generator starts an API server.
+	NeedsAPIServer() bool
+}
+
 // Generator knows how to register some set of markers, and then produce
 // output artifacts based on loaded code containing those markers,
 // sharing common loaded data.
@@ -89,6 +146,10 @@ type HasHelp interface {
 	Help() *markers.DefinitionHelp
 }
 
+// exclusiveRuns is held by the Generators needing an exclusive run, so that
+// they don't run concurrently with the ones of other Runtimes either.
+var exclusiveRuns sync.Mutex
+
 // Runtime collects generators, loaded program data (Collector, root Packages),
 // and I/O rules, running them together.
 type Runtime struct {
@@ -99,8 +160,108 @@ type Runtime struct {
 	GenerationContext
 	// OutputRules defines how to output artifacts for each Generator.
 	OutputRules OutputRules
+	// KindFilters restricts the API kinds processed by each Generator, if
+	// any.
+	KindFilters map[*Generator]KindFilter
 	// ErrorWriter defines where to write error messages.
 	ErrorWriter io.Writer
+	// OutputWriter defines where to write the artifacts output to
+	// standard-out, and the plan of dry runs, standard-out if nil.
+	OutputWriter io.Writer
+	// Verify compares the files the Generators would write with the files on
+	// disk, instead of writing them, reporting the stale ones (with their
+	// diffs) to the ErrorWriter as errors.
+	Verify bool
+	// Diagnostics is the format of the errors and warnings reported to the
+	// ErrorWriter, DiagnosticsText by default.
+	Diagnostics DiagnosticsFormat
+	// ErrorReport writes a report of the errors of the run to the
+	// ErrorWriter at its end, after reporting them as usual, grouped by
+	// package, then by file, so that the first errors of large runs aren't
+	// lost among the ones following from them.  It's only written with
+	// DiagnosticsText.
+	ErrorReport bool
+	// GenerationManifest is the path of a file to write the generation
+	// manifest of the run to, as JSON, if set.  It's not written in verify
+	// mode.
+	GenerationManifest string
+	// Depfile is the path of a file to write a Make-style depfile of the
+	// run to, if set, with a rule per generated file depending on the
+	// inputs of the run.  It's not written in verify mode.
+	Depfile string
+	// LoadGraph is the path of a file to write the packages loaded during
+	// the run to, as JSON, if set, along with why each was loaded (see
+	// loader.LoadGraph), to diagnose slow runs or errors from unexpected
+	// packages.  It's written even when the run fails.
+	LoadGraph string
+	// ScopeErrors only fails the run on the errors of the packages the
+	// Generators needed: the roots, and the dependencies that were parsed or
+	// had their types loaded.  The errors of the other dependencies (e.g.
+	// failing to list broken packages no Generator looked into) are reported
+	// as warnings instead (see loader.DowngradeUnneededErrors).  Otherwise,
+	// the errors of all the loaded packages fail the run.
+	ScopeErrors bool
+	// Parallelism is the maximum number of Generators run concurrently over
+	// the shared roots.  Generators are run one after another if it's zero
+	// or one.
+	Parallelism int
+	// GoTemplates lay out the generated Go files, if set (see
+	// LoadGoTemplates).
+	GoTemplates *template.Template
+	// LineEndings are the line endings of the generated text files (i.e.
+	// all but archives, and standard-out), LineEndingsLF by default.
+	LineEndings LineEndings
+	// DryRun runs the Generators without writing anything, and writes the
+	// plan of the run to standard-out instead: the root packages, and the
+	// output rule and files to write of each Generator.  Generators are run
+	// one after another in dry runs.
+	DryRun bool
+	// KeepGoing still writes the artifacts held until the end of the run
+	// (bundles, archives and standard-out) once a Generator failed, and
+	// summarizes the failed Generators and packages at the end.  Otherwise,
+	// the held artifacts are discarded when any Generator fails.  All the
+	// Generators run either way.
+	KeepGoing bool
+	// Lint checks the markers of the root packages instead of running the
+	// Generators, reporting the markers that only Generators that aren't
+	// enabled use, the ones on the wrong kind of node, and the conflicting
+	// ones, as errors.
+	Lint bool
+	// KnownGenerators are the Generators that could be enabled, by name, for
+	// linting the markers of the ones that aren't.
+	KnownGenerators map[string]Generator
+	// Changes are the files changed since a previous run, if set, for an
+	// incremental run: the Generators only run if any root is affected by
+	// them, and those implementing RunsIncrementally only on the roots they
+	// need for the affected ones.  The generation manifest is merged with
+	// the previous one, if any.
+	Changes *Changes
+	// Timings reports the wall time and allocations of the phases of the
+	// run to the ErrorWriter at its end, in the given format, if set:
+	// loading the packages, collecting their markers, running each
+	// Generator, and writing the artifacts of each output rule.
+	Timings TimingsFormat
+	// CheckConsistency cross-checks the manifests the Generators wrote once
+	// they all ran, failing the run with an error for each one that drifted
+	// from the others: conversion webhooks not served along with the
+	// admission webhooks, roles that can write a kind but not its status or
+	// finalizers, and apply configuration schemas (as written by the
+	// smdschema generator) not matching the CRDs.  The artifacts held until
+	// the end of the run aren't written when they drifted, unless KeepGoing.
+	// It's skipped in incremental runs, which only write some of them.
+	CheckConsistency bool
+
+	// generatorNames are the names the Generators were specified with, to
+	// log them by.
+	generatorNames map[*Generator]string
+	// loadTiming is the timing of loading the roots, if they were loaded by
+	// the Runtime.
+	loadTiming *PhaseTiming
+	// timings are the timings of the phases of the run, if reported.
+	timings *timings
+	// run is the state of the current run, shared by the output and input
+	// rules of the Generators.
+	run *runState
 }
 
 // GenerationContext defines the common information needed for each Generator
@@ -112,11 +273,57 @@ type GenerationContext struct {
 	Roots []*loader.Package
 	// Checker is the shared partial type-checker.
 	Checker *loader.TypeChecker
+	// Cache holds the values shared by the Generators.
+	Cache *Cache
 	// OutputRule describes how to output artifacts.
 	OutputRule
 	// InputRule describes how to load associated boilerplate artifacts.
 	// It should *not* be used to load source files.
 	InputRule
+	// Kinds restricts the API kinds to process, if not empty.
+	Kinds KindFilter
+	// GoHeader is the header of the generated Go files, for the generators
+	// whose own header file isn't set.
+	GoHeader GoHeader
+	// FeatureGates enable or disable the experimental behaviors of the
+	// run.
+	FeatureGates FeatureGates
+	// GeneratorParallelism is the maximum number of tasks a Generator runs
+	// concurrently (e.g. generating the schemata of independent types), if
+	// it can.  They're run one after another if it's zero or one.
+	GeneratorParallelism int
+	// CustomMarkers are the markers declared by the project, registered
+	// into the registry of the Collector.
+	CustomMarkers []CustomMarker
+	// ModuleVersions are the versions of the modules the main modules of
+	// the roots depend on, if resolved.
+	ModuleVersions *ModuleVersions
+	// Version pins the version of controller-gen recorded in the generated
+	// artifacts (e.g. in the controller-gen.kubebuilder.io/version
+	// annotation of CRDs), which is otherwise the version of the binary, so
+	// that they don't depend on how it was built.
+	Version string
+	// Cluster is the cluster that OutputToCluster applies manifests to,
+	// unless the rule sets its own, and that the generators auditing a live
+	// cluster read from (see ClusterConfig.ExportManifests).
+	Cluster ClusterConfig
+
+	// run is the state of the run of the Runtime the context is used in, if
+	// any.
+	run *runState
+	// usedRoots are the roots the Generator isn't done with yet, when their
+	// syntax is released once all the Generators are (see DoneWith).
+	usedRoots *usedRoots
+}
+
+// GeneratorVersion returns the version of controller-gen to record in the
+// generated artifacts: the pinned Version if set, or the version of the
+// binary.
+func (g GenerationContext) GeneratorVersion() string {
+	if g.Version != "" {
+		return g.Version
+	}
+	return version.Version()
 }
 
 // WriteYAMLOptions implements the Options Pattern for WriteYAML.
@@ -158,7 +365,10 @@ func (g GenerationContext) WriteYAML(itemPath, headerText string, objs []any, op
 		if err != nil {
 			return err
 		}
-		n, err := out.Write(append([]byte("---\n"), yamlContent...))
+		if _, err := io.WriteString(out, "---\n"); err != nil {
+			return err
+		}
+		n, err := out.Write(yamlContent)
 		if err != nil {
 			return err
 		}
@@ -182,14 +392,12 @@ func yamlMarshal(o any, options ...*WriteYAMLOptions) ([]byte, error) {
 
 // yamlJSONToYAMLWithFilter is based on sigs.k8s.io/yaml.JSONToYAML, but allows for transforming the final data before writing.
 func yamlJSONToYAMLWithFilter(j []byte, options ...*WriteYAMLOptions) ([]byte, error) {
-	// Convert the JSON to an object.
-	var jsonObj map[string]any
-	// We are using yaml.Unmarshal here (instead of json.Unmarshal) because the
-	// Go JSON library doesn't try to pick the right number type (int, float,
-	// etc.) when unmarshalling to any, it just picks float64
-	// universally. go-yaml does go through the effort of picking the right
-	// number type, so we can preserve number type throughout this process.
-	if err := rawyaml.Unmarshal(j, &jsonObj); err != nil {
+	// Convert the JSON to an object, with the types yaml.Unmarshal picks
+	// (which preserves the number types, unlike json.Unmarshal picking float64
+	// universally), without going through the YAML parser, which allocates a
+	// lot for large documents.
+	jsonObj, err := jsonToYAMLObject(j)
+	if err != nil {
 		return nil, err
 	}
 
@@ -201,8 +409,109 @@ func yamlJSONToYAMLWithFilter(j []byte, options ...*WriteYAMLOptions) ([]byte, e
 		}
 	}
 
-	// Marshal this object into YAML.
-	return rawyaml.Marshal(jsonObj)
+	// Marshal this object into YAML, into a buffer about the right size
+	// rather than one growing along the way.
+	var out bytes.Buffer
+	out.Grow(len(j))
+	enc := rawyaml.NewEncoder(&out)
+	if err := enc.Encode(jsonObj); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// jsonToYAMLObject decodes the given JSON object the way yaml.Unmarshal
+// decodes it into a map[string]any: the nested objects are
+// map[any]any, and the numbers are int, int64 or uint64 if they're integers
+// that fit, and float64 otherwise.
+func jsonToYAMLObject(j []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("cannot unmarshal %v into an object", tok)
+	}
+	obj := make(map[string]any)
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeJSONValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		obj[key.(string)] = value
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// decodeJSONValue decodes the next JSON value of the given decoder (which
+// uses numbers), as for jsonToYAMLObject.
+func decodeJSONValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			list := []any{}
+			for dec.More() {
+				item, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			_, err := dec.Token()
+			return list, err
+		}
+		obj := make(map[any]any)
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = value
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Number:
+		if i, err := strconv.ParseInt(string(tok), 10, 64); err == nil {
+			if i == int64(int(i)) {
+				return int(i), nil
+			}
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(string(tok), 10, 64); err == nil {
+			return u, nil
+		}
+		if f, err := strconv.ParseFloat(string(tok), 64); err == nil {
+			return f, nil
+		}
+		// out of range, left as is by YAML
+		return string(tok), nil
+	default:
+		// strings, booleans and null
+		return tok, nil
+	}
 }
 
 // ReadFile reads the given boilerplate artifact using the context's InputRule.
@@ -221,11 +530,50 @@ func (g Generators) ForRoots(rootPaths ...string) (*Runtime, error) {
 	return g.ForRootsWithConfig(&packages.Config{}, rootPaths...)
 }
 
+// ForRootsWithConfig is like ForRoots, loading the packages with the given
+// config.  If the config asks for export files (packages.NeedExportFile) and
+// the Generators only need the types of the dependencies of the roots (see
+// Generators.OnlyDependencyTypes), the dependencies are loaded from export
+// data instead of type-checked from source.  Otherwise, export files aren't
+// loaded, since that means compiling the dependencies.
 func (g Generators) ForRootsWithConfig(cfg *packages.Config, rootPaths ...string) (*Runtime, error) {
-	roots, err := loader.LoadRootsWithConfig(cfg, rootPaths...)
+	exportData := cfg.Mode&packages.NeedExportFile != 0 && g.OnlyDependencyTypes()
+	if !exportData {
+		cfg.Mode &^= packages.NeedExportFile
+	}
+
+	var roots []*loader.Package
+	var err error
+	loadTiming := measure(PhaseLoad, "", func() {
+		roots, err = loader.LoadRootsWithConfig(cfg, rootPaths...)
+	})
 	if err != nil {
 		return nil, err
 	}
+	slog.Debug("loaded root packages", "paths", rootPaths, "packages", len(roots), "duration", loadTiming.Duration)
+	rt, err := g.forLoadedRoots(roots, exportData)
+	if err != nil {
+		return nil, err
+	}
+	rt.loadTiming = &loadTiming
+	return rt, nil
+}
+
+// ForPackages is like ForRootsWithConfig, with the given packages, already
+// loaded with the given config by golang.org/x/tools/go/packages, as roots
+// (see loader.FromPackages), for tools holding such packages to run the
+// Generators without loading them again.  The dependencies of the packages
+// are loaded from export data if they were loaded with export files, and the
+// Generators only need their types.
+func (g Generators) ForPackages(cfg *packages.Config, pkgs ...*packages.Package) (*Runtime, error) {
+	exportData := cfg.Mode&packages.NeedExportFile != 0 && g.OnlyDependencyTypes()
+	return g.forLoadedRoots(loader.FromPackages(cfg, pkgs...), exportData)
+}
+
+// forLoadedRoots produces a Runtime to run the Generators against the given
+// loaded roots, loading the types of their dependencies from export data if
+// asked to.
+func (g Generators) forLoadedRoots(roots []*loader.Package, exportData bool) (*Runtime, error) {
 	rt := &Runtime{
 		Generators: g,
 		GenerationContext: GenerationContext{
@@ -233,6 +581,7 @@ func (g Generators) ForRootsWithConfig(cfg *packages.Config, rootPaths ...string
 				Registry: &markers.Registry{},
 			},
 			Roots:     roots,
+			Cache:     &Cache{},
 			InputRule: InputFromFileSystem,
 			Checker: &loader.TypeChecker{
 				NodeFilters: g.CheckFilters(),
@@ -240,6 +589,16 @@ func (g Generators) ForRootsWithConfig(cfg *packages.Config, rootPaths ...string
 		},
 		OutputRules: OutputRules{Default: OutputToNothing},
 	}
+	if exportData {
+		rootSet := make(map[*loader.Package]struct{}, len(roots))
+		for _, root := range roots {
+			rootSet[root] = struct{}{}
+		}
+		rt.Checker.ExportData = func(pkg *loader.Package) bool {
+			_, isRoot := rootSet[pkg]
+			return !isRoot
+		}
+	}
 	if err := rt.Generators.RegisterMarkers(rt.Collector.Registry); err != nil {
 		return nil, err
 	}
@@ -250,34 +609,367 @@ func (g Generators) ForRootsWithConfig(cfg *packages.Config, rootPaths ...string
 // errors (except type errors, which common result from using TypeChecker with
 // filters), returning true if errors were found.
 func (r *Runtime) Run() bool {
-	// TODO(directxman12): we could make this parallel,
-	// but we'd need to ensure all underlying machinery is threadsafe
+	return r.RunContext(context.Background())
+}
 
+// RunContext is like Run, but stops starting Generators once the given context
+// is done, as when one fails (the running ones are waited for), in which case
+// the context's error is reported along with the skipped Generators.
+func (r *Runtime) RunContext(ctx context.Context) bool {
 	if r.ErrorWriter == nil {
 		r.ErrorWriter = os.Stderr
 	}
+	if r.OutputWriter == nil {
+		r.OutputWriter = os.Stdout
+	}
 	if len(r.Generators) == 0 {
 		fmt.Fprintln(r.ErrorWriter, "no generators to run")
 		return true
 	}
+	if r.Timings != "" {
+		r.timings = &timings{}
+		defer func() {
+			if err := r.reportTimings(); err != nil {
+				fmt.Fprintln(r.ErrorWriter, err)
+			}
+		}()
+	}
+	r.run = newRunState(r)
+	defer func() { r.run = nil }()
+	if r.Lint {
+		r.lint()
+		r.scopeErrors()
+		var lintErrs []error
+		if err := r.writeLoadGraph(); err != nil {
+			lintErrs = append(lintErrs, err)
+		}
+		if r.Diagnostics == DiagnosticsJSON {
+			return r.reportDiagnostics(lintErrs)
+		}
+		for _, err := range lintErrs {
+			fmt.Fprintln(r.ErrorWriter, err)
+		}
+		hadErrs := loader.FprintErrors(r.ErrorWriter, r.Roots, packages.TypeError) || len(lintErrs) > 0
+		r.reportErrors(lintErrs)
+		return hadErrs
+	}
+
+	var affected []*loader.Package
+	if r.Changes != nil {
+		affected = r.Changes.AffectedRoots(r.Roots)
+		if len(affected) == 0 {
+			slog.Debug("no root affected by the changes, nothing to generate", "roots", len(r.Roots))
+			return false
+		}
+		slog.Debug("generating incrementally", "roots", len(r.Roots), "affected", len(affected))
+	}
+
+	if r.ModuleVersions == nil {
+		r.ModuleVersions = ResolveModuleVersions(r.Roots, r.Generators)
+	}
+	r.checkModuleVersions()
+
+	var artifacts *runArtifacts
+	if r.CheckConsistency {
+		if r.Changes == nil {
+			artifacts = &runArtifacts{}
+		} else {
+			slog.Debug("not checking the consistency of the artifacts of an incremental run")
+		}
+	}
 
-	hadErrs := false
-	for _, gen := range r.Generators {
-		ctx := r.GenerationContext // make a shallow copy
-		ctx.OutputRule = r.OutputRules.ForGenerator(gen)
+	// generators only share the (threadsafe) loader, collector and checker,
+	// so they're run concurrently, bounded by the parallelism, unless they
+	// need to run on their own
+	start := time.Now()
+	errs := make([]error, len(r.Generators))
+	skipped := make([]bool, len(r.Generators))
+	parallelism := r.Parallelism
+	if !r.FeatureGates.Enabled(ConcurrentGenerators) {
+		parallelism = 1
+	}
+	sem := make(chan struct{}, max(parallelism, 1))
+	// all the generators use their roots before any is done with them
+	genCtxs := make([]GenerationContext, len(r.Generators))
+	var release *releaser
+	if r.FeatureGates.Enabled(ReleaseSyntax) {
+		release = newReleaser(r.Collector, r.Checker)
+	}
+	for i, gen := range r.Generators {
+		genCtx := r.GenerationContext // make a shallow copy
+		genCtx.run = r.run
+		rule := r.OutputRules.ForGenerator(gen)
+		genCtx.OutputRule = boundOutputRule{OutputRule: rule, run: r.run}
+		if genCtx.InputRule != nil {
+			genCtx.InputRule = boundInputRule{InputRule: genCtx.InputRule, run: r.run}
+		}
+		if r.timings != nil {
+			genCtx.OutputRule = timedOutputRule{OutputRule: genCtx.OutputRule, name: outputRuleName(rule), timings: r.timings}
+		}
+		if artifacts != nil {
+			genCtx.OutputRule = consistencyOutputRule{OutputRule: genCtx.OutputRule, artifacts: artifacts}
+		}
+		genCtx.Kinds = r.KindFilters[gen]
+		genCtx.GeneratorParallelism = r.Parallelism
+		if r.Changes != nil {
+			genCtx.Roots = incrementalRoots(gen, &genCtx, affected)
+		}
 
 		// don't pass a typechecker to generators that don't provide a filter
 		// to avoid accidents
 		if _, needsChecking := (*gen).(NeedsTypeChecking); !needsChecking {
-			ctx.Checker = nil
+			genCtx.Checker = nil
 		}
+		if release != nil {
+			genCtx.usedRoots = release.use(genCtx.Roots)
+		}
+		genCtxs[i] = genCtx
+	}
 
-		if err := (*gen).Generate(&ctx); err != nil {
-			fmt.Fprintln(r.ErrorWriter, err)
-			hadErrs = true
+	var wg sync.WaitGroup
+	for i, gen := range r.Generators {
+		genCtx := &genCtxs[i]
+		exclusive, needsExclusiveRun := (*gen).(NeedsExclusiveRun)
+		exclusiveRun := needsExclusiveRun && exclusive.ExclusiveRun()
+		if r.DryRun || exclusiveRun {
+			wg.Wait()
+			if ctx.Err() != nil {
+				skipped[i] = true
+				genCtx.DoneWith(genCtx.Roots...)
+				continue
+			}
+			if exclusiveRun {
+				// the global state such generators rely on is shared by
+				// the Runtimes running concurrently too
+				exclusiveRuns.Lock()
+			}
+			errs[i] = r.generate(gen, genCtx)
+			if exclusiveRun {
+				exclusiveRuns.Unlock()
+			}
+			continue
+		}
+
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			skipped[i] = true
+			genCtx.DoneWith(genCtx.Roots...)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = r.generate(gen, genCtx)
+		}()
+	}
+	wg.Wait()
+	slog.Debug("ran generators", "generators", len(r.Generators), "duration", time.Since(start))
+
+	// errors are reported in the order of the generators, whichever
+	// finished first
+	var runErrs []error
+	var failedGens, skippedGens []string
+	for i, err := range errs {
+		switch {
+		case err != nil:
+			runErrs = append(runErrs, err)
+			failedGens = append(failedGens, r.generatorName(r.Generators[i]))
+		case skipped[i]:
+			skippedGens = append(skippedGens, r.generatorName(r.Generators[i]))
 		}
 	}
+	if len(skippedGens) > 0 {
+		runErrs = append(runErrs, fmt.Errorf("skipped the %s generator(s): %w", strings.Join(skippedGens, ", "), ctx.Err()))
+	}
+
+	// the artifacts are only consistent if all the generators wrote them
+	drifted := false
+	if artifacts != nil && len(failedGens) == 0 && len(skippedGens) == 0 {
+		driftErrs := artifacts.check()
+		runErrs = append(runErrs, driftErrs...)
+		drifted = len(driftErrs) > 0
+	}
+
+	// bundles, archives and standard-out hold the artifacts of all the
+	// generators, so they're only written once all the generators ran, in a
+	// stable order -- unless one failed or was skipped, or they drifted, and
+	// there's no point in going on
+	if (len(failedGens) > 0 || drifted) && !r.KeepGoing || len(skippedGens) > 0 {
+		r.run.discardHeld()
+	} else {
+		heldWrites := []struct {
+			rule  string
+			write func() error
+		}{
+			{"bundle", r.run.writeBundles},
+			{"archive", func() error { return r.run.writeArchives(r.GeneratorVersion()) }},
+			{"apply", func() error { return r.run.applyManifests(ctx, r.Cluster) }},
+			{"stdout", func() error { return r.run.writeStdout(r.OutputWriter) }},
+		}
+		for _, held := range heldWrites {
+			var err error
+			write := func() { err = held.write() }
+			// timed along with the files of the rule, if any
+			if r.timings != nil && r.timings.has(PhaseOutput, held.rule) {
+				r.timings.add(measure(PhaseOutput, held.rule, write))
+			} else {
+				write()
+			}
+			if err != nil {
+				runErrs = append(runErrs, err)
+			}
+		}
+		if err := r.writeRecording(); err != nil {
+			runErrs = append(runErrs, err)
+		}
+	}
+
+	if r.DryRun {
+		if err := r.run.planner.report(r.OutputWriter, r); err != nil {
+			runErrs = append(runErrs, err)
+		}
+	}
+	r.scopeErrors()
+	if err := r.writeLoadGraph(); err != nil {
+		runErrs = append(runErrs, err)
+	}
+
+	if r.Diagnostics == DiagnosticsJSON {
+		return r.reportDiagnostics(runErrs)
+	}
+
+	for _, err := range runErrs {
+		fmt.Fprintln(r.ErrorWriter, err)
+	}
+	hadErrs := len(runErrs) > 0
+
+	if r.Verify && r.run.verifier.report(r.ErrorWriter) {
+		hadErrs = true
+	}
 
 	// skip TypeErrors -- they're probably just from partial typechecking in crd-gen
-	return loader.PrintErrors(r.Roots, packages.TypeError) || hadErrs
+	if loader.FprintErrors(r.ErrorWriter, r.Roots, packages.TypeError) {
+		hadErrs = true
+	}
+	r.reportErrors(runErrs)
+
+	if r.KeepGoing {
+		r.summarize(failedGens)
+	}
+	return hadErrs
+}
+
+// scopeErrors downgrades the errors of the dependencies that the Generators
+// didn't need to warnings, if asked to.
+func (r *Runtime) scopeErrors() {
+	if !r.ScopeErrors {
+		return
+	}
+	if downgraded := loader.DowngradeUnneededErrors(r.Roots); downgraded > 0 {
+		slog.Debug("downgraded the errors of unneeded dependencies to warnings", "errors", downgraded)
+	}
+}
+
+// summarize reports the number of failed Generators and root packages with
+// errors (other than type errors), if any.
+func (r *Runtime) summarize(failedGens []string) {
+	var failedPkgs []string
+	for _, root := range r.Roots {
+		for _, err := range root.Errors {
+			if err.Kind != packages.TypeError {
+				failedPkgs = append(failedPkgs, root.PkgPath)
+				break
+			}
+		}
+	}
+	if len(failedGens) > 0 {
+		fmt.Fprintf(r.ErrorWriter, "%d of %d generators failed: %s\n", len(failedGens), len(r.Generators), strings.Join(failedGens, ", "))
+	}
+	if len(failedPkgs) > 0 {
+		fmt.Fprintf(r.ErrorWriter, "%d of %d packages had errors: %s\n", len(failedPkgs), len(r.Roots), strings.Join(failedPkgs, ", "))
+	}
+}
+
+// writeRecording writes the generation manifest and the depfile of the
+// files recorded during the run, if asked for.
+func (r *Runtime) writeRecording() error {
+	if r.run.recorder == nil {
+		return nil
+	}
+	var previous *GenerationManifest
+	if r.Changes != nil && r.GenerationManifest != "" {
+		var err error
+		if previous, err = readManifest(r.GenerationManifest); err != nil {
+			return err
+		}
+	}
+	manifest := r.run.recorder.manifest(r.Roots, previous)
+	if r.GenerationManifest != "" {
+		if err := writeManifest(r.GenerationManifest, manifest); err != nil {
+			return fmt.Errorf("unable to write the generation manifest: %w", err)
+		}
+	}
+	if r.Depfile != "" {
+		if err := writeDepfile(r.Depfile, manifest); err != nil {
+			return fmt.Errorf("unable to write the depfile: %w", err)
+		}
+	}
+	return nil
+}
+
+// generate runs the given Generator, logging how long it took.
+func (r *Runtime) generate(gen *Generator, ctx *GenerationContext) error {
+	name := r.generatorName(gen)
+	if r.run.planner != nil {
+		r.run.planner.start(name)
+	}
+	slog.Log(context.Background(), loader.LevelTrace, "running generator", "generator", name)
+	start := time.Now()
+	var err error
+	r.timed(PhaseGenerator, name, func() { err = (*gen).Generate(ctx) })
+	ctx.DoneWith(ctx.Roots...)
+	slog.Debug("ran generator", "generator", name, "duration", time.Since(start), "failed", err != nil)
+	return err
+}
+
+// timed runs the given function, adding its timing to the given phase if the
+// run is timed.
+func (r *Runtime) timed(phase, name string, run func()) {
+	if r.timings == nil {
+		run()
+		return
+	}
+	r.timings.add(measure(phase, name, run))
+}
+
+// generatorName returns the name the given Generator was specified with, or
+// its type otherwise.
+func (r *Runtime) generatorName(gen *Generator) string {
+	if name := r.generatorNames[gen]; name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", *gen)
+}
+
+// reportDiagnostics reports the given errors of the run, the stale files in
+// verify mode, and the errors and warnings of the packages as JSON
+// diagnostics, returning true if there are any errors.
+func (r *Runtime) reportDiagnostics(runErrs []error) bool {
+	var diags []loader.Diagnostic
+	for _, err := range runErrs {
+		diags = append(diags, loader.ErrorDiagnostic(err))
+	}
+	if r.Verify {
+		diags = append(diags, r.run.verifier.diagnostics()...)
+	}
+	// skip TypeErrors, as when printing them
+	diags = append(diags, loader.Diagnostics(r.Roots, packages.TypeError)...)
+
+	if err := WriteDiagnostics(r.ErrorWriter, diags...); err != nil {
+		fmt.Fprintln(r.ErrorWriter, err)
+		return true
+	}
+	return hasErrors(diags)
 }
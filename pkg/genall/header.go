@@ -0,0 +1,96 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GoHeader is the header (e.g. license) of the Go files generated during a
+// run, as given with --go-header-file.  It's used by the generators of Go
+// code whose own header file isn't set.
+//
+// The header may refer to the year as " YEAR" or "{{.Year}}", and to the
+// owner as "{{.Owner}}".
+type GoHeader struct {
+	// File is the path of the header file.
+	File string
+	// Year is the year substituted in the header, unless the generator sets
+	// its own.
+	Year string
+	// Owner is the owner substituted in the header.
+	Owner string
+}
+
+// GoHeaderText returns the header of the generated Go files: the given
+// header file of a generator if set, and the Go header of the run otherwise
+// (if any), with the given year (or else the year of the run) and the owner
+// substituted.
+func (g GenerationContext) GoHeaderText(headerFile, year string) (string, error) {
+	if headerFile == "" {
+		headerFile = g.GoHeader.File
+	}
+	if year == "" {
+		year = g.GoHeader.Year
+	}
+	if headerFile == "" {
+		return "", nil
+	}
+
+	headerBytes, err := g.ReadFile(headerFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.NewReplacer(
+		" YEAR", " "+year,
+		"{{.Year}}", year,
+		"{{.Owner}}", g.GoHeader.Owner,
+	).Replace(string(headerBytes)), nil
+}
+
+// GoHeaderFile returns the path of a file holding the header of the
+// generated Go files, for generators built on gengo, which read it from a
+// file: the given header file of a generator if set, or otherwise a
+// temporary file with the Go header of the run (empty if there's none),
+// removed by the returned cleanup function.
+func (g GenerationContext) GoHeaderFile(headerFile string) (string, func(), error) {
+	if headerFile != "" {
+		return headerFile, func() {}, nil
+	}
+	headerText, err := g.GoHeaderText("", "")
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmpFile, err := os.CreateTemp("", "controller-gen-header-*.txt")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	cleanup := func() { os.Remove(tmpFile.Name()) }
+	if _, err := tmpFile.WriteString(headerText); err != nil {
+		tmpFile.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	return tmpFile.Name(), cleanup, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package help
+
+import (
+	"strings"
+)
+
+// Completion is the completion data of a marker, as used by editor plugins.
+type Completion struct {
+	// Label is the marker as shown in the list of completions (e.g.
+	// "+kubebuilder:validation:Minimum").
+	Label string `json:"label"`
+	// InsertText is the text inserted when picking the completion, up to
+	// its first required argument, if any.
+	InsertText string `json:"insertText"`
+	// Target is the target (field, package, type) of the marker.
+	Target string `json:"target"`
+	// Syntax is the full syntax of the marker, with the type of each
+	// argument (e.g. "+kubebuilder:printcolumn:JSONPath=<string>,name=<string>[,priority=<int>]").
+	Syntax string `json:"syntax"`
+	// Summary is the one-line description of the marker.
+	Summary string `json:"summary,omitempty"`
+	// Deprecated marks that this marker shouldn't be used anymore.
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// Completions returns the completion data of each of the given markers, in
+// the same order.
+func Completions(cats []CategoryDoc) []Completion {
+	var res []Completion
+	for _, cat := range cats {
+		for _, marker := range cat.Markers {
+			label := "+" + marker.Name
+			res = append(res, Completion{
+				Label:      label,
+				InsertText: label + insertSuffix(marker),
+				Target:     marker.Target,
+				Syntax:     label + syntaxSuffix(marker),
+				Summary:    marker.Summary,
+				Deprecated: marker.DeprecatedInFavorOf != nil,
+			})
+		}
+	}
+	return res
+}
+
+// insertSuffix returns what starts the arguments of the given marker, if it
+// needs any.
+func insertSuffix(marker MarkerDoc) string {
+	switch {
+	case marker.Empty():
+		return ""
+	case marker.AnonymousField():
+		if marker.Fields[0].Optional || marker.Fields[0].Type == "bool" {
+			return ""
+		}
+		return "="
+	}
+	for _, field := range marker.Fields {
+		if !field.Optional {
+			return ":" + field.Name + "="
+		}
+	}
+	return ""
+}
+
+// syntaxSuffix returns the syntax of the arguments of the given marker.
+func syntaxSuffix(marker MarkerDoc) string {
+	if marker.Empty() {
+		return ""
+	}
+	if marker.AnonymousField() {
+		if marker.Fields[0].Optional {
+			return "[=<" + marker.Fields[0].TypeString() + ">]"
+		}
+		return "=<" + marker.Fields[0].TypeString() + ">"
+	}
+
+	out := &strings.Builder{}
+	sep := ":"
+	for _, field := range marker.Fields {
+		if field.Optional {
+			out.WriteString("[")
+		}
+		out.WriteString(sep + field.Name + "=<" + field.TypeString() + ">")
+		if field.Optional {
+			out.WriteString("]")
+		}
+		sep = ","
+	}
+	return out.String()
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package help
+
+import (
+	"slices"
+
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// markerKey identifies a marker across registries, which may each hold their
+// own definition of it.
+type markerKey struct {
+	name   string
+	target string
+}
+
+// ForGenerators returns the marker help for the markers registered by each of
+// the given generators (keyed by name), grouped by category, and noting which
+// generators use each marker.  It's meant for exporting the whole marker
+// reference (e.g. to render it on a website) rather than for a single run.
+func ForGenerators(regsByGen map[string]*markers.Registry) ([]CategoryDoc, error) {
+	genNames := make([]string, 0, len(regsByGen))
+	for genName := range regsByGen {
+		genNames = append(genNames, genName)
+	}
+	slices.Sort(genNames)
+
+	allMarkers := &markers.Registry{}
+	usedBy := make(map[markerKey][]string)
+	for _, genName := range genNames {
+		reg := regsByGen[genName]
+		for _, defn := range reg.AllDefinitions() {
+			key := markerKey{name: defn.Name, target: defn.Target.String()}
+			if _, known := usedBy[key]; !known {
+				if err := allMarkers.Register(defn); err != nil {
+					return nil, err
+				}
+				allMarkers.AddHelp(defn, reg.HelpFor(defn))
+			}
+			usedBy[key] = append(usedBy[key], genName)
+		}
+	}
+
+	res := ByCategory(allMarkers, SortByCategory)
+	for _, cat := range res {
+		for i, marker := range cat.Markers {
+			cat.Markers[i].Generators = usedBy[markerKey{name: marker.Name, target: marker.Target}]
+		}
+	}
+	return res, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package help
 
 import (
+	"maps"
 	"slices"
 	"strings"
 
@@ -33,20 +34,42 @@ type DetailedHelp struct {
 
 // Argument is the type data for a marker argument.
 type Argument struct {
-	// Type is the data type of the argument (string, bool, int, slice, any, raw, invalid)
+	// Type is the data type of the argument (string, bool, int, number, slice, map, struct, any, raw, invalid)
 	Type string `json:"type"`
 	// Optional marks this argument as optional.
 	Optional bool `json:"optional"`
-	// ItemType contains the type of the slice item, if this is a slice
+	// Default is the value of the argument when it's omitted, if any.
+	Default string `json:"default,omitempty"`
+	// ItemType contains the type of the slice item or map value, if this is a
+	// slice or map.
 	ItemType *Argument `json:"itemType,omitempty"`
+	// Fields contains the types of the fields, by name, if this is a struct.
+	Fields map[string]Argument `json:"fields,omitempty"`
 }
 
 func (a Argument) typeString(out *strings.Builder) {
+	if a.Type == "struct" {
+		out.WriteString("{")
+		for i, name := range slices.Sorted(maps.Keys(a.Fields)) {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(name + ": ")
+			a.Fields[name].typeString(out)
+		}
+		out.WriteString("}")
+		return
+	}
 	if a.Type == "slice" {
 		out.WriteString("[]")
 		a.ItemType.typeString(out)
 		return
 	}
+	if a.Type == "map" {
+		out.WriteString("map[string]")
+		a.ItemType.typeString(out)
+		return
+	}
 
 	out.WriteString(a.Type)
 }
@@ -93,6 +116,13 @@ type MarkerDoc struct {
 	DeprecatedInFavorOf *string `json:"deprecatedInFavorOf,omitempty"`
 	// Fields is the type and help data for each field of this marker.
 	Fields []FieldHelp `json:"fields,omitempty"`
+	// Arguments is the JSON-schema-like description of the arguments of
+	// this marker (see markers.MarkerSchema), e.g. for editor completion.
+	Arguments *markers.ArgumentSchema `json:"arguments,omitempty"`
+
+	// Generators are the names of the generators using this marker, when
+	// documenting the markers of several generators at once.
+	Generators []string `json:"generators,omitempty"`
 }
 
 // Empty checks if this marker has any arguments, returning true if not.
@@ -110,16 +140,25 @@ func (m MarkerDoc) AnonymousField() bool {
 func ForArgument(argRaw markers.Argument) Argument {
 	res := Argument{
 		Optional: argRaw.Optional,
+		Default:  argRaw.Default,
 	}
 
 	if argRaw.ItemType != nil {
 		itemType := ForArgument(*argRaw.ItemType)
 		res.ItemType = &itemType
 	}
+	if argRaw.Fields != nil {
+		res.Fields = make(map[string]Argument, len(argRaw.Fields))
+		for name, field := range argRaw.Fields {
+			res.Fields[name] = ForArgument(field)
+		}
+	}
 
 	switch argRaw.Type {
 	case markers.IntType:
 		res.Type = "int"
+	case markers.NumberType:
+		res.Type = "number"
 	case markers.StringType:
 		res.Type = "string"
 	case markers.BoolType:
@@ -128,8 +167,14 @@ func ForArgument(argRaw markers.Argument) Argument {
 		res.Type = "any"
 	case markers.SliceType:
 		res.Type = "slice"
+	case markers.MapType:
+		res.Type = "map"
+	case markers.StructType:
+		res.Type = "struct"
 	case markers.RawType:
 		res.Type = "raw"
+	case markers.CELType:
+		res.Type = "cel"
 	case markers.InvalidType:
 		res.Type = "invalid"
 	}
@@ -150,6 +195,7 @@ func ForDefinition(defn *markers.Definition, maybeHelp *markers.DefinitionHelp)
 		DeprecatedInFavorOf: help.DeprecatedInFavorOf,
 		Target:              defn.Target.String(),
 		DetailedHelp:        DetailedHelp{Summary: help.Summary, Details: help.Details},
+		Arguments:           defn.Schema(maybeHelp).Arguments,
 	}
 
 	helpByField := help.FieldsHelp(defn)
@@ -0,0 +1,294 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// RunsIncrementally indicates that the artifacts of a Generator don't depend
+// on all the root packages, so that incremental runs (see Runtime.Changes)
+// only need to run it on some of them.  Generators that don't implement it
+// are run on all the roots, as long as any root is affected by the changes.
+type RunsIncrementally interface {
+	// IncrementalRoots returns the roots of the given context the generator
+	// needs to run on to regenerate the artifacts of the given affected roots
+	// completely, e.g. the affected roots themselves for artifacts generated
+	// per package.
+	IncrementalRoots(ctx *GenerationContext, affected []*loader.Package) []*loader.Package
+}
+
+// moduleFiles are the files whose changes may affect any package, since
+// they change the versions of the dependencies.
+var moduleFiles = []string{"go.mod", "go.sum", "go.work", "go.work.sum", "modules.txt"}
+
+// Changes are the files changed since a previous run, for incremental runs
+// to only regenerate the artifacts of the root packages they affect (see
+// Runtime.Changes).
+type Changes struct {
+	// files are the absolute paths of the changed (or removed) files.
+	files map[string]struct{}
+	// known are the absolute paths of the Go files the previous run knew of,
+	// if tracked: the other Go files of the packages were added since.
+	known map[string]struct{}
+	// all is set if the changes may affect every package.
+	all bool
+}
+
+// ChangesSinceRef returns the files changed in the working tree of the git
+// repository of the working directory since its merge base with the given
+// ref (e.g. origin/main), including the untracked ones, as for the changes of
+// a pull request.
+//
+// Changes of the module files (go.mod, go.sum, go.work and vendor/modules.txt)
+// affect every package.  Other files read by the generators (e.g. header
+// files) aren't tracked this way.
+func ChangesSinceRef(ref string) (*Changes, error) {
+	top, err := gitCommand("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	base, err := gitCommand("merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	changed, err := gitCommand("-C", top, "diff", "--name-only", "--no-renames", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := gitCommand("-C", top, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &Changes{files: make(map[string]struct{})}
+	for _, file := range strings.Fields(changed + "\n" + untracked) {
+		path := filepath.Join(top, filepath.FromSlash(file))
+		changes.files[path] = struct{}{}
+		if slices.Contains(moduleFiles, filepath.Base(path)) {
+			changes.all = true
+		}
+	}
+	return changes, nil
+}
+
+// ChangesSinceManifest returns the input files changed since the run that
+// wrote the generation manifest at the given path (see
+// Runtime.GenerationManifest): the ones whose contents changed, or that were
+// removed, and the Go files added to the packages.
+//
+// Changes of inputs other than Go files (e.g. header files) affect every
+// package, as does a manifest that doesn't exist yet, or that doesn't have the
+// digests of its inputs.
+func ChangesSinceManifest(path string) (*Changes, error) {
+	manifest, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil || manifest.InputDigests == nil {
+		return &Changes{all: true}, nil
+	}
+
+	changes := &Changes{
+		files: make(map[string]struct{}),
+		known: make(map[string]struct{}, len(manifest.Inputs)),
+	}
+	for _, input := range manifest.Inputs {
+		inputPath, err := filepath.Abs(filepath.FromSlash(input))
+		if err != nil {
+			return nil, err
+		}
+		changes.known[inputPath] = struct{}{}
+		if digest, err := fileDigest(inputPath); err == nil && digest == manifest.InputDigests[input] {
+			continue
+		}
+		changes.files[inputPath] = struct{}{}
+		if filepath.Ext(inputPath) != ".go" {
+			changes.all = true
+		}
+	}
+	return changes, nil
+}
+
+// AffectedRoots returns the given roots affected by the changes: the ones
+// with changed Go files, or importing (even indirectly) packages with changed
+// Go files.  Generated and test files are ignored.
+func (c *Changes) AffectedRoots(roots []*loader.Package) []*loader.Package {
+	if c.all {
+		return roots
+	}
+	affected := make(map[*loader.Package]bool)
+	var affects func(pkg *loader.Package) bool
+	affects = func(pkg *loader.Package) bool {
+		if isAffected, seen := affected[pkg]; seen {
+			return isAffected
+		}
+		// cut import cycles short, which can't happen in valid packages
+		affected[pkg] = false
+		isAffected := c.changedPackage(pkg)
+		for _, importedPkg := range pkg.Imports() {
+			if affects(importedPkg) {
+				isAffected = true
+			}
+		}
+		affected[pkg] = isAffected
+		return isAffected
+	}
+
+	var res []*loader.Package
+	for _, root := range roots {
+		if affects(root) {
+			res = append(res, root)
+		}
+	}
+	return res
+}
+
+// changedPackage checks if any Go file of the given package changed, was
+// removed or was added.
+func (c *Changes) changedPackage(pkg *loader.Package) bool {
+	if c.known != nil && c.tracked(pkg) {
+		for _, file := range pkg.CompiledGoFiles {
+			if _, known := c.known[file]; !known && !generatedFile(file) {
+				return true
+			}
+		}
+	}
+	dir := packageDir(pkg)
+	if dir == "" {
+		return false
+	}
+	for file := range c.files {
+		if filepath.Dir(file) != dir || filepath.Ext(file) != ".go" || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if !generatedFile(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// tracked checks if the previous run knew of the given package, i.e. of any
+// of its Go files, as opposed to e.g. the packages of other modules.
+func (c *Changes) tracked(pkg *loader.Package) bool {
+	for _, file := range pkg.CompiledGoFiles {
+		if _, known := c.known[file]; known {
+			return true
+		}
+	}
+	return false
+}
+
+// packageDir returns the directory of the given package, or "" if it has
+// none (e.g. the unsafe package).
+func packageDir(pkg *loader.Package) string {
+	if pkg.Dir != "" {
+		return pkg.Dir
+	}
+	if len(pkg.GoFiles) > 0 {
+		return filepath.Dir(pkg.GoFiles[0])
+	}
+	return ""
+}
+
+// generatedFile checks if the Go file at the given path is generated, as
+// opposed to removed, or hand-written.
+func generatedFile(path string) bool {
+	contents, err := os.ReadFile(path)
+	return err == nil && isGenerated(contents)
+}
+
+// localPackages returns the given roots, along with the packages they import
+// (even indirectly) whose files are under the working directory, e.g. the
+// packages of the module that aren't roots themselves.
+func localPackages(roots []*loader.Package) []*loader.Package {
+	wd, err := os.Getwd()
+	if err != nil {
+		return roots
+	}
+	seen := make(map[*loader.Package]struct{})
+	var res []*loader.Package
+	var visit func(pkg *loader.Package, isRoot bool)
+	visit = func(pkg *loader.Package, isRoot bool) {
+		if _, isSeen := seen[pkg]; isSeen {
+			return
+		}
+		seen[pkg] = struct{}{}
+		if !isRoot {
+			rel, err := filepath.Rel(wd, packageDir(pkg))
+			if packageDir(pkg) == "" || err != nil || !filepath.IsLocal(rel) {
+				return
+			}
+		}
+		res = append(res, pkg)
+		for _, importedPkg := range pkg.Imports() {
+			visit(importedPkg, false)
+		}
+	}
+	for _, root := range roots {
+		visit(root, true)
+	}
+	return res
+}
+
+// incrementalRoots returns the roots the given Generator needs to run on for
+// the given affected roots (see RunsIncrementally).
+func incrementalRoots(gen *Generator, ctx *GenerationContext, affected []*loader.Package) []*loader.Package {
+	incremental, runsIncrementally := (*gen).(RunsIncrementally)
+	if !runsIncrementally {
+		return ctx.Roots
+	}
+	return incremental.IncrementalRoots(ctx, affected)
+}
+
+// fileDigest returns the hex-encoded SHA-256 digest of the contents of the
+// file at the given path.
+func fileDigest(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(contents)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// gitCommand runs git with the given arguments in the working directory,
+// returning its trimmed output.
+func gitCommand(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// +controllertools:marker:generateHelp:category=""
+
+// KindFilter restricts the API kinds a generator processes (e.g. "crd:only=batch.tutorial.kubebuilder.io/v1/CronJob").
+//
+// Each pattern is of the form "group", "group/version" or
+// "group/version/Kind", where any part may be "*", and a kind is processed
+// if any of the patterns matches one of its versions.  Multiple patterns can
+// be specified using "{pattern1, pattern2}".
+//
+// Generators which don't process API kinds (e.g. rbac or object) ignore it.
+type KindFilter []string
+
+// Matches returns true if the given group-version-kind matches any of the
+// patterns of the filter, or the filter is empty.
+func (f KindFilter) Matches(gvk schema.GroupVersionKind) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, pattern := range f {
+		parts := strings.Split(pattern, "/")
+		values := []string{gvk.Group, gvk.Version, gvk.Kind}
+		matches := true
+		for i, part := range parts {
+			if part != "*" && part != values[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that each pattern of the filter has at most a group, a
+// version and a kind.
+func (f KindFilter) validate() error {
+	for _, pattern := range f {
+		if pattern == "" || strings.Count(pattern, "/") > 2 {
+			return fmt.Errorf("invalid kind pattern %q, must be group[/version[/Kind]]", pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultGoTemplate is the name of the Go template laying out the generated
+// Go files that no template of their own lays out.
+const DefaultGoTemplate = "default.tmpl"
+
+// LoadGoTemplates loads the templates laying out the generated Go files from
+// the *.tmpl files of the given directory.  Each generated file is laid out
+// by the template named after it (e.g. zz_generated.deepcopy.go.tmpl), or
+// else by DefaultGoTemplate, if any, and is given a GoFile.  The templates
+// may share the templates they define.
+func LoadGoTemplates(dir string) (*template.Template, error) {
+	tmpls, err := template.New("").ParseGlob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the Go templates of %s: %w", dir, err)
+	}
+	return tmpls, nil
+}
+
+// GoFile is a generated Go file, broken down into the parts laid out by Go
+// templates, e.g.:
+//
+//	{{with .BuildConstraint}}//go:build {{.}}
+//
+//	{{end}}{{with .Header}}{{.}}
+//
+//	{{end}}{{.Generated}}
+//
+//	{{with .PackageDoc}}{{.}}
+//	{{end}}package {{.Package}}
+//	{{with .Imports}}
+//	import (
+//	{{range .}}	{{.}}
+//	{{end}})
+//	{{end}}
+//	{{range .Decls}}
+//	{{.}}
+//	{{end}}
+//
+// The result is formatted with gofmt.
+type GoFile struct {
+	// Name is the name of the file, e.g. zz_generated.deepcopy.go.
+	Name string
+	// Package is the name of the package of the file.
+	Package string
+	// BuildConstraint is the expression of the go:build constraint of the
+	// file, if any, e.g. "!ignore_autogenerated".
+	BuildConstraint string
+	// Header is the header (e.g. license) of the file, if any.
+	Header string
+	// Generated is the comment marking the file as generated.
+	Generated string
+	// PackageDoc is the doc comment of the package, if any.
+	PackageDoc string
+	// Imports are the import specs of the file, e.g.
+	// `metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"`.
+	Imports []string
+	// Decls are the top-level declarations of the file, with their doc
+	// comments.  Comments between declarations are left out.
+	Decls []string
+	// Body is the rest of the file past its imports, as is.
+	Body string
+}
+
+// parseGoFile breaks down the given contents of the Go file at the given
+// path, returning false if they don't parse.
+func parseGoFile(path string, contents []byte) (*GoFile, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, contents, parser.ParseComments)
+	if err != nil {
+		return nil, false
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	text := func(from, to token.Pos) string { return string(contents[offset(from):offset(to)]) }
+
+	goFile := &GoFile{Name: filepath.Base(path), Package: file.Name.Name}
+
+	// the preamble is the build constraint, the header and the generated
+	// comment, in any order, before the package doc
+	preambleEnd := offset(file.Package)
+	var docLines []string
+	if file.Doc != nil {
+		preambleEnd = offset(file.Doc.Pos())
+		docLines = strings.Split(text(file.Doc.Pos(), file.Doc.End()), "\n")
+	}
+	var headerLines []string
+	for line := range strings.Lines(string(contents[:preambleEnd])) {
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "//go:build "):
+			goFile.BuildConstraint = strings.TrimPrefix(line, "//go:build ")
+		case generatedCodeComment.MatchString(line):
+			goFile.Generated = line
+		default:
+			headerLines = append(headerLines, line)
+		}
+	}
+	goFile.Header = strings.TrimSpace(strings.Join(headerLines, "\n"))
+	// the generated comment may be part of the package doc, if there's no
+	// blank line between them
+	for i, line := range docLines {
+		if generatedCodeComment.MatchString(line) {
+			goFile.Generated = line
+			docLines = append(docLines[:i], docLines[i+1:]...)
+			break
+		}
+	}
+	goFile.PackageDoc = strings.TrimSpace(strings.Join(docLines, "\n"))
+
+	for _, spec := range file.Imports {
+		goFile.Imports = append(goFile.Imports, text(spec.Pos(), spec.End()))
+	}
+
+	bodyStart := file.Name.End()
+	for _, decl := range file.Decls {
+		if genDecl, isGenDecl := decl.(*ast.GenDecl); isGenDecl && genDecl.Tok == token.IMPORT {
+			bodyStart = decl.End()
+			continue
+		}
+		declStart := decl.Pos()
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Doc != nil {
+				declStart = decl.Doc.Pos()
+			}
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				declStart = decl.Doc.Pos()
+			}
+		}
+		goFile.Decls = append(goFile.Decls, text(declStart, decl.End()))
+	}
+	goFile.Body = strings.TrimSpace(string(contents[offset(bodyStart):])) + "\n"
+	return goFile, true
+}
+
+// layOutGoFile lays out the given contents of the Go file at the given path
+// with its Go template of the run, if any.  Contents that don't parse (e.g.
+// if a generator failed) are left as they are.
+func (r *runState) layOutGoFile(path string, contents []byte) ([]byte, error) {
+	if r.goTemplates == nil {
+		return contents, nil
+	}
+	tmpl := r.goTemplates.Lookup(filepath.Base(path) + ".tmpl")
+	if tmpl == nil {
+		tmpl = r.goTemplates.Lookup(DefaultGoTemplate)
+	}
+	if tmpl == nil {
+		return contents, nil
+	}
+	goFile, parsed := parseGoFile(path, contents)
+	if !parsed {
+		return contents, nil
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, goFile); err != nil {
+		return nil, fmt.Errorf("unable to lay out %s: %w", path, err)
+	}
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to lay out %s with %s: %w", path, tmpl.Name(), err)
+	}
+	return formatted, nil
+}
+
+// needsFinishing returns true if the Go file at the given path needs to be
+// finished, i.e. laid out, or to get the given protected regions back.
+func (r *runState) needsFinishing(path string, regions [][]byte) bool {
+	return len(regions) > 0 || r.goTemplates != nil && filepath.Ext(path) == ".go"
+}
+
+// finishGoFile finishes the given contents of the Go file at the given path:
+// it lays them out, and appends the given protected regions to them.
+func (r *runState) finishGoFile(path string, contents []byte, regions [][]byte) ([]byte, error) {
+	contents, err := r.layOutGoFile(path, contents)
+	if err != nil {
+		return nil, err
+	}
+	return withProtectedRegions(path, contents, regions)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bytes"
+	"io"
+)
+
+// LineEndings are the line endings of the generated files.
+type LineEndings string
+
+const (
+	// LineEndingsLF ends the lines of the generated files with "\n",
+	// whatever the platform.  It's the default.
+	LineEndingsLF LineEndings = "lf"
+	// LineEndingsCRLF ends the lines of the generated files with "\r\n",
+	// e.g. for working trees checked out with Windows line endings (as with
+	// git's core.autocrlf), so that verifying them doesn't report them all
+	// as out of date.
+	LineEndingsCRLF LineEndings = "crlf"
+)
+
+// WithLineEndings returns the given text with the given line endings, whatever
+// its own (or a mix of them).
+func WithLineEndings(text []byte, endings LineEndings) []byte {
+	crlf := []byte("\r\n")
+	if endings != LineEndingsCRLF {
+		if !bytes.Contains(text, crlf) {
+			return text
+		}
+		return bytes.ReplaceAll(text, crlf, []byte("\n"))
+	}
+	return bytes.ReplaceAll(bytes.ReplaceAll(text, crlf, []byte("\n")), []byte("\n"), crlf)
+}
+
+// lineEndingsWriter writes what's written to it to another writer when
+// closed, with the given line endings.
+type lineEndingsWriter struct {
+	bytes.Buffer
+	out     io.WriteCloser
+	endings LineEndings
+}
+
+func (w *lineEndingsWriter) Close() error {
+	if _, err := w.out.Write(WithLineEndings(w.Bytes(), w.endings)); err != nil {
+		_ = w.out.Close()
+		return err
+	}
+	return w.out.Close()
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"go/ast"
+	"maps"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// HasMarkerConflicts is implemented by Generators some of whose markers
+// can't be used together on the same node, for linting them.
+type HasMarkerConflicts interface {
+	// MarkerConflicts returns the pairs of names of markers that conflict
+	// with one another.
+	MarkerConflicts() [][2]string
+}
+
+// lint checks the markers of the root packages, instead of running the
+// Generators, adding errors to the packages for:
+//
+//   - markers only used by known Generators that aren't enabled;
+//
+//   - markers on the wrong kind of node (e.g. field markers on a type);
+//
+//   - conflicting markers on the same node (see HasMarkerConflicts).
+//
+// Other markers that aren't registered are left alone, since they may well
+// be read by other tools.
+func (r *Runtime) lint() {
+	enabled := make(map[string]bool, len(r.generatorNames))
+	var conflicts [][2]string
+	for _, gen := range r.Generators {
+		enabled[r.generatorName(gen)] = true
+		if withConflicts, hasConflicts := (*gen).(HasMarkerConflicts); hasConflicts {
+			conflicts = append(conflicts, withConflicts.MarkerConflicts()...)
+		}
+	}
+	others := make(map[string]*markers.Registry)
+	for name, gen := range r.KnownGenerators {
+		if enabled[name] {
+			continue
+		}
+		reg := &markers.Registry{}
+		if err := gen.RegisterMarkers(reg); err != nil {
+			// not enabled, so not worth failing over
+			continue
+		}
+		others[name] = reg
+	}
+
+	for _, root := range r.Roots {
+		rawMarkers, err := r.Collector.RawMarkersInPackage(root)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		found := make(map[ast.Node]map[string]markers.RawMarker)
+		for _, marker := range rawMarkers {
+			if def, _ := r.Collector.Registry.LookupAlias(marker.Text, marker.Target); def != nil {
+				if found[marker.Node] == nil {
+					found[marker.Node] = make(map[string]markers.RawMarker)
+				}
+				if _, seen := found[marker.Node][def.Name]; !seen {
+					found[marker.Node][def.Name] = marker
+				}
+				continue
+			}
+			if err := lintUnknownMarker(marker, r.Collector.Registry, others); err != nil {
+				root.AddError(loader.ErrFromNode(err, marker))
+			}
+		}
+		for _, nodeMarkers := range found {
+			for _, conflict := range conflicts {
+				first, hasFirst := nodeMarkers[conflict[0]]
+				second, hasSecond := nodeMarkers[conflict[1]]
+				if !hasFirst || !hasSecond {
+					continue
+				}
+				if second.Pos() < first.Pos() {
+					first, second = second, first
+					conflict[0], conflict[1] = conflict[1], conflict[0]
+				}
+				root.AddError(loader.ErrFromNode(loader.MarkerError{
+					Marker:       conflict[1],
+					SuggestedFix: "remove +" + conflict[0] + " or +" + conflict[1],
+					Err:          fmt.Errorf("marker +%s conflicts with +%s on the same %s", conflict[1], conflict[0], second.Target),
+				}, second))
+			}
+		}
+	}
+}
+
+// lintUnknownMarker returns the error of the given marker that isn't
+// registered by the enabled Generators, if it's registered by other known
+// Generators, or for other kinds of nodes.
+func lintUnknownMarker(marker markers.RawMarker, enabled *markers.Registry, others map[string]*markers.Registry) error {
+	var users []string
+	var def *markers.Definition
+	for _, name := range slices.Sorted(maps.Keys(others)) {
+		if otherDef, _ := others[name].LookupAlias(marker.Text, marker.Target); otherDef != nil {
+			users = append(users, name)
+			def = otherDef
+		}
+	}
+	if def != nil {
+		return loader.MarkerError{
+			Marker:       def.Name,
+			SuggestedFix: "enable the " + strings.Join(users, " or ") + " generator",
+			Err:          fmt.Errorf("marker +%s is unused: it's only used by the %s generator(s), which aren't enabled", def.Name, strings.Join(users, ", ")),
+		}
+	}
+
+	var targets []string
+	regs := []*markers.Registry{enabled}
+	for _, name := range slices.Sorted(maps.Keys(others)) {
+		regs = append(regs, others[name])
+	}
+	for _, target := range []markers.TargetType{markers.DescribesPackage, markers.DescribesType, markers.DescribesField} {
+		if target == marker.Target {
+			continue
+		}
+		for _, reg := range regs {
+			if otherDef, _ := reg.LookupAlias(marker.Text, target); otherDef != nil {
+				targets = append(targets, "a "+target.String())
+				def = otherDef
+				break
+			}
+		}
+	}
+	if def != nil {
+		return loader.MarkerError{
+			Marker: def.Name,
+			Err:    fmt.Errorf("marker +%s can't be used on a %s, only on %s", def.Name, marker.Target, strings.Join(targets, " or ")),
+		}
+	}
+	return nil
+}
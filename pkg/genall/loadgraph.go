@@ -0,0 +1,41 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// writeLoadGraph writes the packages loaded during the run, and why they were
+// loaded (see loader.LoadGraph), to the LoadGraph file as JSON, if asked for.
+func (r *Runtime) writeLoadGraph() error {
+	if r.LoadGraph == "" {
+		return nil
+	}
+	contents, err := json.MarshalIndent(loader.LoadGraph(r.Roots), "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to write the load graph: %w", err)
+	}
+	if err := os.WriteFile(r.LoadGraph, append(contents, '\n'), 0o644); err != nil {
+		return fmt.Errorf("unable to write the load graph: %w", err)
+	}
+	return nil
+}
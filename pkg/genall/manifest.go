@@ -0,0 +1,245 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// GenerationManifest describes what a run generated, for build systems to
+// track it (e.g. to cache its outputs remotely).
+type GenerationManifest struct {
+	// Inputs are the files the outputs were generated from: the Go files of
+	// the roots and of the packages they import from under the working
+	// directory, and the files read by the generators (e.g. header files).
+	Inputs []string `json:"inputs"`
+	// InputDigests are the hex-encoded SHA-256 digests of the contents of
+	// the inputs, by path, for incremental runs to find the changed ones
+	// (see ChangesSinceManifest).
+	InputDigests map[string]string `json:"inputDigests,omitempty"`
+	// Outputs are the files written by the generators, with their contents'
+	// digests.
+	Outputs []GeneratedFile `json:"outputs"`
+}
+
+// GeneratedFile is a file written by the generators.
+type GeneratedFile struct {
+	// Path is the path of the file.
+	Path string `json:"path"`
+	// SHA256 is the hex-encoded SHA-256 digest of its contents.
+	SHA256 string `json:"sha256"`
+}
+
+// recorder records the files read and written during a run.  A nil recorder
+// records nothing, for runs without generation manifest nor depfile.
+type recorder struct {
+	mu      sync.Mutex
+	inputs  map[string]struct{}
+	outputs map[string]string
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		inputs:  make(map[string]struct{}),
+		outputs: make(map[string]string),
+	}
+}
+
+// recordInput records that the file at the given path was read.
+func (r *recorder) recordInput(path string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inputs[manifestPath(path)] = struct{}{}
+}
+
+// recordOutput records that the given contents were written to the file at
+// the given path.
+func (r *recorder) recordOutput(path string, contents []byte) {
+	if r == nil {
+		return
+	}
+	digest := sha256.Sum256(contents)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outputs[manifestPath(path)] = hex.EncodeToString(digest[:])
+}
+
+// recordingWriter records what's written to the file it wraps as an output
+// when closed.
+type recordingWriter struct {
+	io.WriteCloser
+	recorder *recorder
+	path     string
+	contents []byte
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	w.contents = append(w.contents, p[:n]...)
+	return n, err
+}
+
+func (w *recordingWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	w.recorder.recordOutput(w.path, w.contents)
+	return nil
+}
+
+// manifestPath returns the given path relative to the working directory if
+// it's under it, and absolute otherwise, with forward slashes, so that
+// manifests are the same on all platforms.
+func manifestPath(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, absPath); err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(absPath)
+}
+
+// manifest returns the generation manifest of the recorded files, along
+// with the Go files of the given roots (and of their local dependencies) as
+// inputs.  Generated files aren't inputs, even if they're Go files of the
+// roots.
+//
+// The inputs and outputs of the given previous manifest that still exist are
+// kept, if any, e.g. for incremental runs that only regenerate some of the
+// outputs.
+func (r *recorder) manifest(roots []*loader.Package, previous *GenerationManifest) GenerationManifest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inputs := maps.Clone(r.inputs)
+	outputs := maps.Clone(r.outputs)
+	if previous != nil {
+		// previous manifests may have been written with the separators of
+		// the platform
+		for _, input := range previous.Inputs {
+			if _, err := os.Stat(input); err == nil {
+				inputs[filepath.ToSlash(input)] = struct{}{}
+			}
+		}
+		for _, output := range previous.Outputs {
+			outputPath := filepath.ToSlash(output.Path)
+			if _, regenerated := outputs[outputPath]; regenerated {
+				continue
+			}
+			if _, err := os.Stat(output.Path); err == nil {
+				outputs[outputPath] = output.SHA256
+			}
+		}
+	}
+	for _, pkg := range localPackages(roots) {
+		for _, file := range pkg.CompiledGoFiles {
+			inputs[manifestPath(file)] = struct{}{}
+		}
+	}
+	for output := range outputs {
+		delete(inputs, output)
+	}
+
+	manifest := GenerationManifest{
+		Inputs:       slices.Sorted(maps.Keys(inputs)),
+		InputDigests: make(map[string]string, len(inputs)),
+		Outputs:      []GeneratedFile{},
+	}
+	for _, input := range manifest.Inputs {
+		if digest, err := fileDigest(input); err == nil {
+			manifest.InputDigests[input] = digest
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(outputs)) {
+		manifest.Outputs = append(manifest.Outputs, GeneratedFile{Path: path, SHA256: outputs[path]})
+	}
+	return manifest
+}
+
+// readManifest reads the generation manifest at the given path, or returns
+// nil if there's none.
+func readManifest(path string) (*GenerationManifest, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest GenerationManifest
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to read the generation manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// writeManifest writes the given generation manifest as JSON to the file at
+// the given path.
+func writeManifest(path string, manifest GenerationManifest) error {
+	contents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(contents, '\n'), 0o644)
+}
+
+// writeDepfile writes a Make-style depfile to the file at the given path,
+// with a rule per output of the given generation manifest depending on all
+// its inputs, as understood by Make and Ninja.
+func writeDepfile(path string, manifest GenerationManifest) error {
+	inputs := make([]string, len(manifest.Inputs))
+	for i, input := range manifest.Inputs {
+		inputs[i] = depfileEscape(input)
+	}
+
+	var out strings.Builder
+	for _, output := range manifest.Outputs {
+		fmt.Fprintf(&out, "%s:", depfileEscape(output.Path))
+		for _, input := range inputs {
+			fmt.Fprintf(&out, " \\\n  %s", input)
+		}
+		out.WriteString("\n")
+	}
+	return os.WriteFile(path, []byte(out.String()), 0o644)
+}
+
+// depfileEscape escapes the spaces, number signs and dollar signs of the
+// given path for depfiles.
+func depfileEscape(path string) string {
+	return strings.NewReplacer(" ", `\ `, "#", `\#`, "$", "$$").Replace(path)
+}
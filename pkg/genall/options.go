@@ -70,6 +70,7 @@ func RegistryFromOptions(optionsRegistry *markers.Registry, options []string) (*
 // a) Generators
 // b) OutputRules
 // c) InputPaths
+// d) KindFilters (as "<generator>:only")
 //
 // The paths specified in InputPaths are loaded as package roots, and the combined with
 // the generators and the specified output rules to produce a runtime that can be run or
@@ -90,6 +91,11 @@ func FromOptionsWithConfig(cfg *packages.Config, optionsRegistry *markers.Regist
 	if err != nil {
 		return nil, err
 	}
+	genRuntime.generatorNames = make(map[*Generator]string, len(protoRt.GeneratorsByName))
+	for name, gen := range protoRt.GeneratorsByName {
+		genRuntime.generatorNames[gen] = name
+	}
+	genRuntime.KindFilters = protoRt.KindFilters
 
 	// attempt to figure out what the user wants without a lot of verbose specificity:
 	// if the user specifies a default rule, assume that they probably want to fall back
@@ -120,6 +126,7 @@ func protoFromOptions(optionsRegistry *markers.Registry, options []string) (prot
 	// collect the generators first, so that we can key the output on the actual
 	// generator, which matters if there's settings in the gen object and it's not a pointer.
 	outputByGen := make(map[string]OutputRule)
+	kindsByGen := make(map[string]KindFilter)
 	gensByName := make(map[string]*Generator)
 
 	for _, rawOpt := range options {
@@ -153,6 +160,11 @@ func protoFromOptions(optionsRegistry *markers.Registry, options []string) (prot
 
 			outputByGen[genName] = val
 			continue
+		case KindFilter:
+			if err := val.validate(); err != nil {
+				return protoRuntime{}, fmt.Errorf("unable to parse option %q: %w", rawOpt[1:], err)
+			}
+			kindsByGen[strings.TrimSuffix(defn.Name, ":only")] = val
 		case InputPaths:
 			paths = append(paths, val...)
 		default:
@@ -169,11 +181,20 @@ func protoFromOptions(optionsRegistry *markers.Registry, options []string) (prot
 
 		rules.ByGenerator[gen] = outputRule
 	}
+	kindFilters := make(map[*Generator]KindFilter, len(kindsByGen))
+	for genName, filter := range kindsByGen {
+		gen, knownGen := gensByName[genName]
+		if !knownGen {
+			return protoRuntime{}, fmt.Errorf("non-invoked generator %q", genName)
+		}
+		kindFilters[gen] = filter
+	}
 
 	return protoRuntime{
 		Paths:            paths,
 		Generators:       gens,
 		OutputRules:      rules,
+		KindFilters:      kindFilters,
 		GeneratorsByName: gensByName,
 	}, nil
 }
@@ -184,6 +205,7 @@ type protoRuntime struct {
 	Paths            []string
 	Generators       Generators
 	OutputRules      OutputRules
+	KindFilters      map[*Generator]KindFilter
 	GeneratorsByName map[string]*Generator
 }
 
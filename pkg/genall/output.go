@@ -17,10 +17,22 @@ limitations under the License.
 package genall
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"log/slog"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
 
 	"sigs.k8s.io/controller-tools/pkg/loader"
 )
@@ -74,6 +86,11 @@ func (o OutputRules) ForGenerator(gen *Generator) OutputRule {
 }
 
 // OutputRule defines how to output artifacts from a generator.
+//
+// The built-in rules write the files of the run of the Runtime they're set
+// for (e.g. comparing them with the files on disk in verify mode).  Used on
+// their own (e.g. by other rules), they write the files as they are, and the
+// rules holding artifacts until the end of a run fail to open them.
 type OutputRule interface {
 	// Open opens the given artifact path for writing.  If a package is passed,
 	// the artifact is considered to be used as part of the package (e.g.
@@ -100,29 +117,104 @@ func (o outputToNothing) Open(_ *loader.Package, _ string) (io.WriteCloser, erro
 // of if it's package-associated or not.
 type OutputToDirectory string
 
-func (o OutputToDirectory) Open(_ *loader.Package, itemPath string) (io.WriteCloser, error) {
-	// ensure the directory exists
-	if err := os.MkdirAll(filepath.Dir(filepath.Join(string(o), itemPath)), os.ModePerm); err != nil {
-		return nil, err
-	}
-	path := filepath.Join(string(o), itemPath)
-	return os.Create(path)
+func (o OutputToDirectory) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return o.openInRun(outsideRun(), pkg, itemPath)
 }
 
-// OutputToStdout outputs everything to standard-out, with no separation.
+func (o OutputToDirectory) openInRun(run *runState, _ *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return run.createFile(filepath.Join(string(o), itemPath))
+}
+
+// OutputToStdout outputs everything to standard-out (or the OutputWriter of
+// the Runtime), once all the generators ran, ordered by path.  YAML artifacts are output as a multi-document YAML
+// stream, each preceded by a comment with its path.
 //
-// Generally useful for single-artifact outputs.
+// Generally useful for piping manifests to other tools.
 var OutputToStdout = outputToStdout{}
 
 // +controllertools:marker:generateHelp:category=""
 
-// outputToStdout outputs everything to standard-out, with no separation.
+// outputToStdout outputs everything to standard-out, ordered by path.
 //
-// Generally useful for single-artifact outputs.
+// YAML artifacts are output as a multi-document YAML stream (e.g. for
+// "kubectl apply -f -"), each preceded by a "# Source: <path>" comment.
 type outputToStdout struct{}
 
-func (o outputToStdout) Open(_ *loader.Package, _ string) (io.WriteCloser, error) {
-	return nopCloser{os.Stdout}, nil
+func (o outputToStdout) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return o.openInRun(outsideRun(), pkg, itemPath)
+}
+
+func (o outputToStdout) openInRun(run *runState, _ *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if run.planner != nil {
+		run.planner.record("standard-out: " + itemPath)
+		return nopCloser{io.Discard}, nil
+	}
+	if !run.held {
+		return nil, errOutsideRun(o)
+	}
+	slog.Log(context.Background(), loader.LevelTrace, "writing to standard output", "item", itemPath)
+	return &stdoutWriter{run: run, itemPath: itemPath}, nil
+}
+
+// stdoutWriter adds what's written to it to the artifacts of standard-out
+// of a run when closed.
+type stdoutWriter struct {
+	bytes.Buffer
+	run      *runState
+	itemPath string
+}
+
+func (w *stdoutWriter) Close() error {
+	w.run.stdoutMu.Lock()
+	defer w.run.stdoutMu.Unlock()
+	w.run.stdout[w.itemPath] = append(w.run.stdout[w.itemPath], w.Bytes())
+	return nil
+}
+
+// writeStdout writes the artifacts output to standard-out during the run to
+// the given writer, ordered by path.
+func (r *runState) writeStdout(out io.Writer) error {
+	r.stdoutMu.Lock()
+	defer r.stdoutMu.Unlock()
+	defer clear(r.stdout)
+
+	for _, itemPath := range slices.Sorted(maps.Keys(r.stdout)) {
+		for _, contents := range r.stdout[itemPath] {
+			if ext := filepath.Ext(itemPath); ext != ".yaml" && ext != ".yml" {
+				if _, err := out.Write(contents); err != nil {
+					return err
+				}
+				continue
+			}
+
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, "# Source: %s\n", itemPath)
+			if !startsDocument(contents) {
+				buf.WriteString("---\n")
+			}
+			buf.Write(contents)
+			if len(contents) > 0 && contents[len(contents)-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+			if _, err := buf.WriteTo(out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// startsDocument returns true if the given YAML starts with a document
+// marker, past its leading comments.
+func startsDocument(contents []byte) bool {
+	for line := range bytes.Lines(contents) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		return bytes.Equal(line, []byte("---"))
+	}
+	return false
 }
 
 // +controllertools:marker:generateHelp:category=""
@@ -141,24 +233,170 @@ type OutputArtifacts struct {
 }
 
 func (o OutputArtifacts) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return o.openInRun(outsideRun(), pkg, itemPath)
+}
+
+func (o OutputArtifacts) openInRun(run *runState, pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
 	if pkg == nil {
-		return o.Config.Open(pkg, itemPath)
+		return o.Config.openInRun(run, pkg, itemPath)
 	}
 
 	if o.Code != "" {
-		return o.Code.Open(pkg, itemPath)
+		return o.Code.openInRun(run, pkg, itemPath)
 	}
 
 	if len(pkg.CompiledGoFiles) == 0 {
 		return nil, fmt.Errorf("cannot output to a package with no path on disk")
 	}
 	outDir := filepath.Dir(pkg.CompiledGoFiles[0])
-	if _, err := os.Stat(outDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
+	return run.createFile(filepath.Join(outDir, itemPath))
+}
+
+// +controllertools:marker:generateHelp:category=""
+
+// OutputToHelmChart outputs artifacts into the layout of a Helm chart.
+//
+// Non-package associated artifacts (e.g. CRDs) are output to the crds
+// directory of the chart, which Helm installs before the rest of the chart,
+// but never upgrades nor deletes.  When templated, they're output to the
+// templates/crds directory instead, as templates installed if the
+// <valuesKey>.enable value is set, and kept on uninstall if the
+// <valuesKey>.keep value is set, so that they're upgraded with the chart.
+//
+// Package-associated artifacts are output to their package's source files'
+// directory, as with artifacts.
+type OutputToHelmChart struct {
+	// Chart points to the directory of the chart.
+	Chart string
+	// Templated outputs templates to templates/crds, rather than plain files to crds.
+	Templated bool `marker:",optional"`
+	// ValuesKey is the key of the values of templates (defaults to "crd").
+	ValuesKey string `marker:",optional"`
+}
+
+func (o OutputToHelmChart) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	return o.openInRun(outsideRun(), pkg, itemPath)
+}
+
+func (o OutputToHelmChart) openInRun(run *runState, pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if pkg != nil {
+		return OutputArtifacts{}.openInRun(run, pkg, itemPath)
+	}
+	if !o.Templated {
+		return OutputToDirectory(filepath.Join(o.Chart, "crds")).openInRun(run, nil, itemPath)
+	}
+
+	out, err := OutputToDirectory(filepath.Join(o.Chart, "templates", "crds")).openInRun(run, nil, itemPath)
+	if err != nil {
+		return nil, err
+	}
+	if ext := filepath.Ext(itemPath); ext != ".yaml" && ext != ".yml" {
+		return out, nil
+	}
+	valuesKey := o.ValuesKey
+	if valuesKey == "" {
+		valuesKey = "crd"
+	}
+	return &helmTemplateWriter{out: out, values: ".Values." + valuesKey}, nil
+}
+
+// helmTemplateWriter turns the YAML written to it into a Helm template when
+// closed.
+type helmTemplateWriter struct {
+	bytes.Buffer
+	out    io.WriteCloser
+	values string
+}
+
+func (w *helmTemplateWriter) Close() error {
+	template, err := w.template()
+	if err != nil {
+		_ = w.out.Close()
+		return err
+	}
+	if _, err := w.out.Write(template); err != nil {
+		_ = w.out.Close()
+		return err
+	}
+	return w.out.Close()
+}
+
+// template returns the Helm template of the YAML written so far, annotating
+// its objects to be kept on uninstall if the keep value is set.
+func (w *helmTemplateWriter) template() ([]byte, error) {
+	var template bytes.Buffer
+	fmt.Fprintf(&template, "{{- if %s.enable }}\n", w.values)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(&w.Buffer))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// numbers are kept as they are, rather than turned into floats
+		var obj map[string]any
+		if err := yaml.Unmarshal(document, &obj, func(dec *json.Decoder) *json.Decoder {
+			dec.UseNumber()
+			return dec
+		}); err != nil {
 			return nil, err
 		}
+		if obj == nil {
+			// e.g. the header of the file
+			template.WriteString(escapeHelmAction(string(document)))
+			continue
+		}
+
+		u := unstructured.Unstructured{Object: escapeHelmActions(obj).(map[string]any)}
+		annotations := u.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		// Helm only keeps the objects whose policy is exactly "keep"
+		annotations[helmResourcePolicy] = fmt.Sprintf("{{ if %s.keep }}keep{{ end }}", w.values)
+		u.SetAnnotations(annotations)
+		yamlContent, err := yamlMarshal(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		template.WriteString("---\n")
+		template.Write(yamlContent)
+	}
+	template.WriteString("{{- end }}\n")
+	return template.Bytes(), nil
+}
+
+// helmResourcePolicy is the annotation of the resource policy of the objects
+// of a Helm chart.
+const helmResourcePolicy = "helm.sh/resource-policy"
+
+// escapeHelmActions escapes whatever looks like a Helm template action in the
+// given YAML value (e.g. in descriptions), including in the keys of objects.
+func escapeHelmActions(value any) any {
+	switch value := value.(type) {
+	case string:
+		return escapeHelmAction(value)
+	case map[string]any:
+		obj := make(map[string]any, len(value))
+		for key, fieldValue := range value {
+			obj[escapeHelmAction(key)] = escapeHelmActions(fieldValue)
+		}
+		return obj
+	case []any:
+		items := make([]any, len(value))
+		for i, item := range value {
+			items[i] = escapeHelmActions(item)
+		}
+		return items
+	default:
+		return value
 	}
+}
 
-	outPath := filepath.Join(outDir, itemPath)
-	return os.Create(outPath)
+// escapeHelmAction escapes whatever looks like a Helm template action in the
+// given text.
+func escapeHelmAction(text string) string {
+	return strings.ReplaceAll(text, "{{", `{{ "{{" }}`)
 }
@@ -0,0 +1,107 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// planner records the files that the generators of a dry run would write,
+// instead of writing them.  Generators are run one at a time in dry runs, so
+// that each file is recorded as written by the generator running.
+type planner struct {
+	mu sync.Mutex
+	// generator is the name of the running generator.
+	generator string
+	// files are the files each generator would write, by generator, in
+	// the order they'd be written.
+	files map[string][]string
+}
+
+// start records the files written from now on as written by the given
+// generator.
+func (p *planner) start(generator string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generator = generator
+}
+
+// record records the given file (or other destination) as written by the
+// running generator.
+func (p *planner) record(file string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files[p.generator] = append(p.files[p.generator], file)
+}
+
+// report writes the plan of the given run to the given writer: the root
+// packages, then, for each generator, its output rule, kinds filter and the
+// files it would write.
+func (p *planner) report(out io.Writer, r *Runtime) error {
+	var plan strings.Builder
+	plan.WriteString("packages:\n")
+	for _, root := range r.Roots {
+		fmt.Fprintf(&plan, "  %s\n", root.PkgPath)
+	}
+	for _, gen := range r.Generators {
+		name := r.generatorName(gen)
+		fmt.Fprintf(&plan, "\n%s (output: %s", name, describeOutputRule(r.OutputRules.ForGenerator(gen)))
+		if kinds := r.KindFilters[gen]; len(kinds) > 0 {
+			fmt.Fprintf(&plan, ", only: %s", strings.Join(kinds, ", "))
+		}
+		plan.WriteString(")\n")
+		if len(p.files[name]) == 0 {
+			plan.WriteString("  (nothing)\n")
+		}
+		for _, file := range p.files[name] {
+			fmt.Fprintf(&plan, "  %s\n", file)
+		}
+	}
+	_, err := io.WriteString(out, plan.String())
+	return err
+}
+
+// describeOutputRule returns the given output rule as it'd be specified as
+// an option.
+func describeOutputRule(rule OutputRule) string {
+	switch rule := rule.(type) {
+	case outputToNothing:
+		return "none"
+	case outputToStdout:
+		return "stdout"
+	case OutputToDirectory:
+		return "dir=" + manifestPath(string(rule))
+	case OutputArtifacts:
+		if rule.Code != "" {
+			return fmt.Sprintf("artifacts:config=%s,code=%s", manifestPath(string(rule.Config)), manifestPath(string(rule.Code)))
+		}
+		return "artifacts:config=" + manifestPath(string(rule.Config))
+	case OutputToHelmChart:
+		return "helm:chart=" + manifestPath(rule.Chart)
+	case OutputToBundle:
+		return "bundle:file=" + manifestPath(rule.File)
+	case OutputToArchive:
+		return "archive:file=" + manifestPath(rule.File)
+	case OutputToCluster:
+		return "apply"
+	default:
+		return fmt.Sprintf("%T", rule)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Profiles are the paths of the profiles to write of a run, e.g. to find
+// out why generating is slow in a given project.  Empty paths are skipped.
+type Profiles struct {
+	// CPU is the path of the CPU profile, in pprof format.
+	CPU string
+	// Memory is the path of the heap profile, in pprof format, written once
+	// the run is over.
+	Memory string
+	// Trace is the path of the execution trace, as read by `go tool trace`.
+	Trace string
+}
+
+// Start starts the CPU profile and the execution trace, if asked for.  The
+// returned function stops them, and writes the memory profile.
+func (p Profiles) Start() (stop func() error, err error) {
+	var cpuFile, traceFile *os.File
+	closeAll := func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if traceFile != nil {
+			trace.Stop()
+			traceFile.Close()
+		}
+	}
+
+	if p.CPU != "" {
+		cpuFile, err = os.Create(p.CPU)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, fmt.Errorf("unable to start the CPU profile: %w", err)
+		}
+	}
+	if p.Trace != "" {
+		traceFile, err = os.Create(p.Trace)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("unable to create the execution trace: %w", err)
+		}
+		if err := trace.Start(traceFile); err != nil {
+			traceFile.Close()
+			traceFile = nil
+			closeAll()
+			return nil, fmt.Errorf("unable to start the execution trace: %w", err)
+		}
+	}
+
+	return func() error {
+		var errs []error
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("unable to write the CPU profile: %w", err))
+			}
+		}
+		if traceFile != nil {
+			trace.Stop()
+			if err := traceFile.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("unable to write the execution trace: %w", err))
+			}
+		}
+		if p.Memory != "" {
+			if err := writeHeapProfile(p.Memory); err != nil {
+				errs = append(errs, fmt.Errorf("unable to write the memory profile: %w", err))
+			}
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// writeHeapProfile writes the profile of the live heap to the given path.
+func writeHeapProfile(path string) error {
+	memFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// get up-to-date statistics
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(memFile); err != nil {
+		memFile.Close()
+		return err
+	}
+	return memFile.Close()
+}
@@ -0,0 +1,126 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/imports"
+)
+
+const (
+	// protectedBegin starts a protected region of a generated Go file.
+	protectedBegin = "// +controllertools:protected:begin"
+	// protectedEnd ends a protected region of a generated Go file.
+	protectedEnd = "// +controllertools:protected:end"
+)
+
+// protectedRegions returns the protected regions of the Go file at the given
+// path, if any, i.e. the lines between protectedBegin and protectedEnd
+// comments (inclusive), which are kept when the file is regenerated.
+func protectedRegions(path string) ([][]byte, error) {
+	if filepath.Ext(path) != ".go" {
+		return nil, nil
+	}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var regions [][]byte
+	var region []byte
+	inRegion := false
+	for line := range bytes.Lines(contents) {
+		switch string(bytes.TrimSpace(line)) {
+		case protectedBegin:
+			if inRegion {
+				return nil, fmt.Errorf("%s: nested protected region", path)
+			}
+			inRegion = true
+		case protectedEnd:
+			if !inRegion {
+				return nil, fmt.Errorf("%s: end of a protected region without a beginning", path)
+			}
+			regions = append(regions, append(region, line...))
+			region, inRegion = nil, false
+			continue
+		}
+		if inRegion {
+			region = append(region, line...)
+		}
+	}
+	if inRegion {
+		return nil, fmt.Errorf("%s: unterminated protected region", path)
+	}
+	return regions, nil
+}
+
+// withProtectedRegions appends the given protected regions to the contents
+// of the generated Go file at the given path, fixing its imports for the
+// code of the regions.
+func withProtectedRegions(path string, contents []byte, regions [][]byte) ([]byte, error) {
+	if len(regions) == 0 {
+		return contents, nil
+	}
+	merged := bytes.Clone(contents)
+	for _, region := range regions {
+		if len(merged) > 0 && merged[len(merged)-1] != '\n' {
+			merged = append(merged, '\n')
+		}
+		merged = append(merged, '\n')
+		merged = append(merged, region...)
+	}
+	merged, err := imports.Process(path, merged, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to keep the protected regions of %s: %w", path, err)
+	}
+	return merged, nil
+}
+
+// goFileWriter writes what's written to it to its file when closed, as a
+// finished Go file.  The file is only opened then, so that it's left as it
+// is (protected regions included) if it can't be finished.
+type goFileWriter struct {
+	bytes.Buffer
+	run     *runState
+	path    string
+	regions [][]byte
+}
+
+func (w *goFileWriter) Close() error {
+	contents, err := w.run.finishGoFile(w.path, w.Bytes(), w.regions)
+	if err != nil {
+		return err
+	}
+	out, err := w.run.openFile(w.path)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(contents); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// releaser releases the syntax of the roots of a run once all the Generators
+// are done with them (see the ReleaseSyntax feature gate).
+type releaser struct {
+	collector *markers.Collector
+	checker   *loader.TypeChecker
+
+	mu sync.Mutex
+	// pending are the number of Generators that aren't done with each root
+	// yet.
+	pending map[*loader.Package]int
+}
+
+// newReleaser returns a releaser of the roots used by the Generators, which
+// must all be registered (see use) before any is done with its roots.
+func newReleaser(collector *markers.Collector, checker *loader.TypeChecker) *releaser {
+	return &releaser{
+		collector: collector,
+		checker:   checker,
+		pending:   make(map[*loader.Package]int),
+	}
+}
+
+// usedRoots are the roots a Generator isn't done with yet.
+type usedRoots struct {
+	releaser *releaser
+	mu       sync.Mutex
+	roots    map[*loader.Package]struct{}
+}
+
+// use registers a Generator using the given roots.
+func (r *releaser) use(roots []*loader.Package) *usedRoots {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used := &usedRoots{releaser: r, roots: make(map[*loader.Package]struct{}, len(roots))}
+	for _, root := range roots {
+		if _, dup := used.roots[root]; dup {
+			continue
+		}
+		used.roots[root] = struct{}{}
+		r.pending[root]++
+	}
+	return used
+}
+
+// doneWith marks the Generator as done with the given roots, releasing the
+// ones no other Generator needs anymore.
+func (u *usedRoots) doneWith(roots []*loader.Package) {
+	u.mu.Lock()
+	var done []*loader.Package
+	for _, root := range roots {
+		if _, used := u.roots[root]; used {
+			delete(u.roots, root)
+			done = append(done, root)
+		}
+	}
+	u.mu.Unlock()
+	if len(done) > 0 {
+		u.releaser.release(done)
+	}
+}
+
+// release releases the syntax of the given roots, done with by a Generator,
+// if no other Generator needs them, along with their dependencies that the
+// roots still needed don't import.
+func (r *releaser) release(roots []*loader.Package) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []*loader.Package
+	for _, root := range roots {
+		r.pending[root]--
+		if r.pending[root] == 0 {
+			delete(r.pending, root)
+			unused = append(unused, root)
+		}
+	}
+	if len(unused) == 0 {
+		return
+	}
+
+	start := time.Now()
+	released := loader.ReleaseSyntax(unused, slices.Collect(maps.Keys(r.pending)))
+	r.collector.Forget(released...)
+	if r.checker != nil {
+		r.checker.Forget(released...)
+	}
+	slog.Debug("released the syntax of packages", "roots", len(unused), "packages", len(released), "duration", time.Since(start))
+}
+
+// DoneWith tells the Runtime that the Generator won't look into the given
+// roots (or their dependencies) anymore, e.g. once their markers were
+// collected and their artifacts generated, so that their syntax can be
+// released once no other Generator needs it, with the ReleaseSyntax feature
+// gate.  Generators are done with all their roots once they return anyway.
+func (g *GenerationContext) DoneWith(roots ...*loader.Package) {
+	if g.usedRoots != nil {
+		g.usedRoots.doneWith(roots)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// runState is the state of a run of a Runtime, shared by the output and
+// input rules of its Generators: how files are written (verified, planned,
+// laid out, ...), and the artifacts held until the end of the run.  Each run
+// has its own, so that Runtimes may run concurrently.
+type runState struct {
+	// verifier compares the written files with the files on disk instead,
+	// in verify mode.
+	verifier *verifier
+	// planner records the files that would be written instead, in dry runs.
+	planner *planner
+	// goTemplates lay out the generated Go files, if any (see
+	// Runtime.GoTemplates).  They're only read once loaded, so they're safe
+	// to use concurrently.
+	goTemplates *template.Template
+	// lineEndings are the line endings of the written text files.
+	lineEndings LineEndings
+	// recorder records the files read and written, to write the generation
+	// manifest or depfile of the run, if any.
+	recorder *recorder
+
+	// held is false outside of a run, where there's no end of the run to
+	// write the held artifacts at.
+	held bool
+	// bundles are the bundles output during the run, by path.
+	bundles   map[string]*bundle
+	bundlesMu sync.Mutex
+	// archives are the artifacts of the archives output during the run, by
+	// path of the archive and then of the artifact.
+	archives   map[string]map[string][]byte
+	archivesMu sync.Mutex
+	// stdout are the artifacts output to standard-out during the run, by
+	// path.
+	stdout   map[string][][]byte
+	stdoutMu sync.Mutex
+	// applies are the manifests to apply at the end of the run, by cluster
+	// and then by path of the artifact.
+	applies   map[cluster]map[string][]*unstructured.Unstructured
+	appliesMu sync.Mutex
+}
+
+// newRunState returns the state of a run of the given Runtime.
+func newRunState(r *Runtime) *runState {
+	run := &runState{
+		goTemplates: r.GoTemplates,
+		lineEndings: r.LineEndings,
+		held:        true,
+		bundles:     make(map[string]*bundle),
+		archives:    make(map[string]map[string][]byte),
+		stdout:      make(map[string][][]byte),
+		applies:     make(map[cluster]map[string][]*unstructured.Unstructured),
+	}
+	if run.lineEndings == "" {
+		run.lineEndings = LineEndingsLF
+	}
+	if r.Verify {
+		run.verifier = &verifier{stale: make(map[string]string)}
+	}
+	if r.DryRun {
+		run.planner = &planner{files: make(map[string][]string)}
+	}
+	if !r.Verify && !r.DryRun && (r.GenerationManifest != "" || r.Depfile != "") {
+		run.recorder = newRecorder()
+	}
+	return run
+}
+
+// outsideRun returns the state the output and input rules use outside of a
+// run (e.g. when used directly by another rule): files are written as is,
+// and nothing is held until the end of a run.
+func outsideRun() *runState {
+	return &runState{lineEndings: LineEndingsLF}
+}
+
+// errOutsideRun returns the error of the given output rule holding artifacts
+// until the end of a run, when used outside of one.
+func errOutsideRun(rule OutputRule) error {
+	return fmt.Errorf("the %s output rule can only be used by the generators of a Runtime, which write its artifacts once they all ran", outputRuleName(rule))
+}
+
+// discardHeld drops the artifacts held until the end of the run, without
+// writing them.
+func (r *runState) discardHeld() {
+	r.bundlesMu.Lock()
+	clear(r.bundles)
+	r.bundlesMu.Unlock()
+
+	r.archivesMu.Lock()
+	clear(r.archives)
+	r.archivesMu.Unlock()
+
+	r.stdoutMu.Lock()
+	clear(r.stdout)
+	r.stdoutMu.Unlock()
+
+	r.appliesMu.Lock()
+	clear(r.applies)
+	r.appliesMu.Unlock()
+}
+
+// runOutputRule is an OutputRule writing its artifacts depending on the run
+// it's used in, as the built-in rules do.
+type runOutputRule interface {
+	// openInRun is like Open, in the given run.
+	openInRun(run *runState, pkg *loader.Package, itemPath string) (io.WriteCloser, error)
+}
+
+// boundOutputRule is an OutputRule used in the given run.
+type boundOutputRule struct {
+	OutputRule
+	run *runState
+}
+
+func (o boundOutputRule) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if rule, inRun := o.OutputRule.(runOutputRule); inRun {
+		return rule.openInRun(o.run, pkg, itemPath)
+	}
+	return o.OutputRule.Open(pkg, itemPath)
+}
+
+// boundInputRule is an InputRule used in the given run, recording the files
+// read from the filesystem.
+type boundInputRule struct {
+	InputRule
+	run *runState
+}
+
+func (i boundInputRule) OpenForRead(path string) (io.ReadCloser, error) {
+	if _, fromFileSystem := i.InputRule.(inputFromFileSystem); fromFileSystem {
+		i.run.recorder.recordInput(path)
+	}
+	return i.InputRule.OpenForRead(path)
+}
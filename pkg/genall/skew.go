@@ -0,0 +1,155 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// KnownModules are the modules whose versions are resolved for the module of
+// each root (see ModuleVersions), besides the ones the Generators require.
+var KnownModules = []string{
+	"k8s.io/api",
+	"k8s.io/apiextensions-apiserver",
+	"k8s.io/apimachinery",
+	"k8s.io/client-go",
+}
+
+// ModuleRequirement is the minimum minor version of a module that the
+// artifacts of a Generator need, e.g. for generated code calling functions
+// added in that version.
+type ModuleRequirement struct {
+	// Module is the path of the module, e.g. k8s.io/apimachinery.
+	Module string
+	// MinVersion is the minimum version of the module, e.g. v0.30.0.  Only
+	// its major and minor versions are compared.
+	MinVersion string
+	// Reason is what needs the version, e.g. "the generated NewTypeConverter
+	// functions".
+	Reason string
+}
+
+// RequiresModules is implemented by Generators whose artifacts need minimum
+// versions of the modules the module of the roots depends on.  The Runtime
+// warns about the modules that are older before running them, rather than
+// leaving it to confusing compilation failures.
+type RequiresModules interface {
+	// RequiredModules returns the minimum versions of the modules the
+	// artifacts of the Generator need.
+	RequiredModules() []ModuleRequirement
+}
+
+// ModuleVersions are the versions of the modules that the main modules of
+// the roots depend on (see KnownModules and RequiresModules), for generators
+// to check the constructs they generate are supported by them.
+type ModuleVersions struct {
+	// byModule are the versions of the modules each main module depends on,
+	// by path of its go.mod.
+	byModule map[string]map[string]string
+}
+
+// Of returns the version of the given module that the main module of the
+// given package depends on, if it's known, or "".
+func (v *ModuleVersions) Of(pkg *loader.Package, module string) string {
+	if v == nil || pkg.Module == nil {
+		return ""
+	}
+	return v.byModule[pkg.Module.GoMod][module]
+}
+
+// OlderThan checks whether the version of the given module that the main
+// module of the given package depends on is known to be older than the given
+// minimum version, comparing their major and minor versions.
+func (v *ModuleVersions) OlderThan(pkg *loader.Package, module, minVersion string) bool {
+	current, err := version.ParseSemantic(v.Of(pkg, module))
+	if err != nil {
+		return false
+	}
+	minimum, err := version.ParseSemantic(minVersion)
+	if err != nil {
+		return false
+	}
+	return version.MajorMinor(current.Major(), current.Minor()).LessThan(version.MajorMinor(minimum.Major(), minimum.Minor()))
+}
+
+// ResolveModuleVersions resolves the versions of the known and required
+// modules that the main modules of the given roots depend on.  Runtimes
+// resolve them before running their Generators, unless already set.
+func ResolveModuleVersions(roots []*loader.Package, gens Generators) *ModuleVersions {
+	modules := slices.Clone(KnownModules)
+	for _, gen := range gens {
+		if requiring, requires := (*gen).(RequiresModules); requires {
+			for _, req := range requiring.RequiredModules() {
+				modules = append(modules, req.Module)
+			}
+		}
+	}
+	slices.Sort(modules)
+	modules = slices.Compact(modules)
+
+	res := &ModuleVersions{byModule: make(map[string]map[string]string)}
+	for _, root := range roots {
+		if root.Module == nil {
+			continue
+		}
+		if _, resolved := res.byModule[root.Module.GoMod]; resolved {
+			continue
+		}
+		versions, err := loader.ModuleVersions(root, modules...)
+		if err != nil {
+			slog.Debug("unable to resolve the versions of the dependencies", "module", root.Module.Path, "error", err)
+		}
+		res.byModule[root.Module.GoMod] = versions
+	}
+	return res
+}
+
+// checkModuleVersions warns about the modules that the main modules of the
+// roots depend on that are older than the Generators require, on the first
+// root of each main module.
+func (r *Runtime) checkModuleVersions() {
+	checked := make(map[string]struct{})
+	for _, root := range r.Roots {
+		if root.Module == nil {
+			continue
+		}
+		if _, isChecked := checked[root.Module.GoMod]; isChecked {
+			continue
+		}
+		checked[root.Module.GoMod] = struct{}{}
+
+		for _, gen := range r.Generators {
+			requiring, requires := (*gen).(RequiresModules)
+			if !requires {
+				continue
+			}
+			for _, req := range requiring.RequiredModules() {
+				if !r.ModuleVersions.OlderThan(root, req.Module, req.MinVersion) {
+					continue
+				}
+				root.AddWarning(fmt.Errorf("%s %s (required by %s) is older than %s, which %s of the %s generator need: upgrade it (e.g. with go get %s@%s)",
+					req.Module, r.ModuleVersions.Of(root, req.Module), root.Module.Path, req.MinVersion, req.Reason, r.generatorName(gen), req.Module, req.MinVersion))
+			}
+		}
+	}
+}
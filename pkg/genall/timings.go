@@ -0,0 +1,237 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// TimingsFormat is the format of the timings report of a run.
+type TimingsFormat string
+
+const (
+	// TimingsText reports the timings as a table, for humans.
+	TimingsText TimingsFormat = "text"
+	// TimingsJSON reports the timings as a JSON object, with the phases as
+	// a list of PhaseTiming.
+	TimingsJSON TimingsFormat = "json"
+)
+
+const (
+	// PhaseLoad is loading the root packages and their dependencies.
+	PhaseLoad = "load"
+	// PhaseMarkers is collecting the markers of the packages.
+	PhaseMarkers = "markers"
+	// PhaseGenerator is running a Generator, named by the timing.
+	PhaseGenerator = "generator"
+	// PhaseOutput is writing the artifacts of an output rule, named by the
+	// timing.
+	PhaseOutput = "output"
+)
+
+// PhaseTiming is the wall time spent in a phase of a run, along with the
+// allocations of the process during it.  Allocations are those of the whole
+// process, so they include the ones of the phases run concurrently (e.g. of
+// the other Generators).
+type PhaseTiming struct {
+	// Phase is the phase, e.g. PhaseGenerator.
+	Phase string `json:"phase"`
+	// Name is the Generator or output rule of the phase, if any.
+	Name string `json:"name,omitempty"`
+	// Duration is the wall time of the phase.
+	Duration time.Duration `json:"durationNanoseconds"`
+	// Allocations is the number of heap objects allocated during the phase.
+	Allocations uint64 `json:"allocations,omitempty"`
+	// AllocatedBytes is the number of bytes allocated during the phase.
+	AllocatedBytes uint64 `json:"allocatedBytes,omitempty"`
+	// Cumulative is true if the phase is made of work spread over the run
+	// (e.g. collecting the markers of each package, or writing each file),
+	// whose times are summed, possibly over concurrent work.  Allocations
+	// aren't measured for them.
+	Cumulative bool `json:"cumulative,omitempty"`
+}
+
+// timings are the timings of the phases of a run, in the order they were
+// first added.
+type timings struct {
+	mu     sync.Mutex
+	phases []PhaseTiming
+}
+
+// add adds the given timing to the one of the same phase and name, if any.
+func (t *timings) add(timing PhaseTiming) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, known := range t.phases {
+		if known.Phase == timing.Phase && known.Name == timing.Name {
+			t.phases[i].Duration += timing.Duration
+			t.phases[i].Allocations += timing.Allocations
+			t.phases[i].AllocatedBytes += timing.AllocatedBytes
+			t.phases[i].Cumulative = known.Cumulative || timing.Cumulative
+			return
+		}
+	}
+	t.phases = append(t.phases, timing)
+}
+
+// has returns true if there's a timing of the given phase and name.
+func (t *timings) has(phase, name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.ContainsFunc(t.phases, func(timing PhaseTiming) bool {
+		return timing.Phase == phase && timing.Name == name
+	})
+}
+
+// measure runs the given function, returning the timing of the given phase
+// it ran.
+func measure(phase, name string, run func()) PhaseTiming {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	run()
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return PhaseTiming{
+		Phase:          phase,
+		Name:           name,
+		Duration:       duration,
+		Allocations:    after.Mallocs - before.Mallocs,
+		AllocatedBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+}
+
+// timedOutputRule is an output rule summing the time spent opening, writing
+// and closing its files to the timings of its phase.
+type timedOutputRule struct {
+	OutputRule
+	name    string
+	timings *timings
+}
+
+func (o timedOutputRule) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	start := time.Now()
+	wr, err := o.OutputRule.Open(pkg, itemPath)
+	o.addSince(start)
+	if err != nil {
+		return nil, err
+	}
+	return &timedWriter{WriteCloser: wr, rule: o}, nil
+}
+
+// addSince adds the time since the given start to the timing of the rule.
+func (o timedOutputRule) addSince(start time.Time) {
+	o.timings.add(PhaseTiming{Phase: PhaseOutput, Name: o.name, Duration: time.Since(start), Cumulative: true})
+}
+
+// timedWriter is a file of a timedOutputRule.
+type timedWriter struct {
+	io.WriteCloser
+	rule timedOutputRule
+}
+
+func (w *timedWriter) Write(p []byte) (int, error) {
+	defer w.rule.addSince(time.Now())
+	return w.WriteCloser.Write(p)
+}
+
+func (w *timedWriter) Close() error {
+	defer w.rule.addSince(time.Now())
+	return w.WriteCloser.Close()
+}
+
+// outputRuleName returns the name of the given output rule, as given in
+// options.
+func outputRuleName(rule OutputRule) string {
+	switch rule.(type) {
+	case OutputToDirectory:
+		return "dir"
+	case outputToNothing:
+		return "none"
+	case outputToStdout:
+		return "stdout"
+	case OutputArtifacts:
+		return "artifacts"
+	case OutputToHelmChart:
+		return "helm"
+	case OutputToBundle:
+		return "bundle"
+	case OutputToArchive:
+		return "archive"
+	case OutputToCluster:
+		return "apply"
+	default:
+		return fmt.Sprintf("%T", rule)
+	}
+}
+
+// reportTimings writes the timings of the run to the ErrorWriter, in the
+// format asked for, along with the time spent collecting markers.
+func (r *Runtime) reportTimings() error {
+	var phases []PhaseTiming
+	if r.loadTiming != nil {
+		phases = append(phases, *r.loadTiming)
+	}
+	phases = append(phases, PhaseTiming{Phase: PhaseMarkers, Duration: r.Collector.CollectionTime(), Cumulative: true})
+	r.timings.mu.Lock()
+	phases = append(phases, r.timings.phases...)
+	r.timings.mu.Unlock()
+
+	if r.Timings == TimingsJSON {
+		return json.NewEncoder(r.ErrorWriter).Encode(struct {
+			Phases []PhaseTiming `json:"phases"`
+		}{Phases: phases})
+	}
+
+	fmt.Fprintln(r.ErrorWriter, "timings:")
+	for _, phase := range phases {
+		label := phase.Phase
+		if phase.Name != "" {
+			label += " " + phase.Name
+		}
+		fmt.Fprintf(r.ErrorWriter, "  %-32s %12s", label, phase.Duration.Round(time.Microsecond))
+		if phase.Cumulative {
+			fmt.Fprint(r.ErrorWriter, "  (summed)")
+		} else {
+			fmt.Fprintf(r.ErrorWriter, "  %10d allocs  %10s", phase.Allocations, formatBytes(phase.AllocatedBytes))
+		}
+		fmt.Fprintln(r.ErrorWriter)
+	}
+	return nil
+}
+
+// formatBytes formats the given number of bytes with a binary unit.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,271 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/gengo/v2/generator"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// verifier compares the files written during a run with the files on disk,
+// instead of writing them.
+type verifier struct {
+	// mu guards stale, since generators may run concurrently.
+	mu sync.Mutex
+	// stale are the unified diffs of the files that differ from the ones on
+	// disk, by path.
+	stale map[string]string
+}
+
+// check compares the given contents of the file at the given path with the
+// file on disk.
+func (v *verifier) check(path string, contents []byte) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// files are reported relative to the working directory, if they're in it,
+	// with forward slashes, as with diffs on all platforms
+	displayPath := filepath.Clean(path)
+	if absPath, err := filepath.Abs(path); err == nil {
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, absPath); err == nil && !strings.HasPrefix(rel, "..") {
+				displayPath = rel
+			}
+		}
+	}
+	displayPath = filepath.ToSlash(displayPath)
+	if current != nil && bytes.Equal(current, contents) {
+		// the file may have been written before during the run
+		delete(v.stale, displayPath)
+		return nil
+	}
+
+	diff := difflib.UnifiedDiff{
+		FromFile: displayPath,
+		ToFile:   displayPath,
+		B:        difflib.SplitLines(string(contents)),
+		Context:  3,
+	}
+	if current == nil {
+		diff.FromFile = os.DevNull
+	} else {
+		diff.A = difflib.SplitLines(string(current))
+	}
+	diffText, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return err
+	}
+	v.stale[displayPath] = diffText
+	return nil
+}
+
+// report writes the list of stale files, followed by their diffs, to the
+// given writer, returning true if there are any.
+func (v *verifier) report(out io.Writer) bool {
+	if len(v.stale) == 0 {
+		return false
+	}
+	paths := slices.Sorted(maps.Keys(v.stale))
+	fmt.Fprintln(out, "the following files are out of date:")
+	for _, path := range paths {
+		fmt.Fprintf(out, "  %s\n", path)
+	}
+	for _, path := range paths {
+		fmt.Fprintf(out, "\n%s", v.stale[path])
+	}
+	return true
+}
+
+// diagnostics returns the stale files as diagnostics, suggesting their diffs
+// as fixes.
+func (v *verifier) diagnostics() []loader.Diagnostic {
+	var diags []loader.Diagnostic
+	for _, path := range slices.Sorted(maps.Keys(v.stale)) {
+		diags = append(diags, loader.Diagnostic{
+			File:         path,
+			Severity:     loader.SeverityError,
+			Message:      "generated file is out of date",
+			SuggestedFix: v.stale[path],
+		})
+	}
+	return diags
+}
+
+// createFile creates the file at the given path (and its directory) for
+// writing, or in verify mode, returns a writer comparing what's written to it
+// with the file when closed.  In dry runs, it only records the file.
+//
+// Go files are laid out with the Go templates of the run, if any, and their
+// protected regions are kept.
+func (r *runState) createFile(path string) (io.WriteCloser, error) {
+	if r.planner != nil {
+		r.planner.record(manifestPath(path))
+		return nopCloser{io.Discard}, nil
+	}
+	regions, err := protectedRegions(path)
+	if err != nil {
+		return nil, err
+	}
+	if !r.needsFinishing(path, regions) {
+		return r.openFile(path)
+	}
+	return &goFileWriter{run: r, path: path, regions: regions}, nil
+}
+
+// openFile opens the text file at the given path for writing, or verifying,
+// with the line endings of the run.
+func (r *runState) openFile(path string) (io.WriteCloser, error) {
+	out, err := r.openRawFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &lineEndingsWriter{out: out, endings: r.lineEndings}, nil
+}
+
+// openRawFile opens the file at the given path for writing, or verifying,
+// writing what's written to it as is (e.g. for archives).
+func (r *runState) openRawFile(path string) (io.WriteCloser, error) {
+	if r.verifier != nil {
+		slog.Log(context.Background(), loader.LevelTrace, "verifying file", "path", path)
+		return &verifyingWriter{verifier: r.verifier, path: path}, nil
+	}
+	slog.Log(context.Background(), loader.LevelTrace, "writing file", "path", path)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil || r.recorder == nil {
+		return file, err
+	}
+	return &recordingWriter{WriteCloser: file, recorder: r.recorder, path: path}, nil
+}
+
+// verifyingWriter compares what's written to it with a file when closed.
+type verifyingWriter struct {
+	bytes.Buffer
+	verifier *verifier
+	path     string
+}
+
+func (w *verifyingWriter) Close() error {
+	return w.verifier.check(w.path, w.Bytes())
+}
+
+// VerifiableFileTypes wraps the given file types of a gengo context (as
+// used by code-generator), so that the files they write are written as the
+// other files of the run of the context: compared with the files on disk
+// instead in verify mode, only recorded in dry runs, and laid out with the
+// Go templates of the run.
+func (g GenerationContext) VerifiableFileTypes(fileTypes map[string]generator.FileType) map[string]generator.FileType {
+	run := g.run
+	if run == nil {
+		run = outsideRun()
+	}
+	wrapped := make(map[string]generator.FileType, len(fileTypes))
+	for name, fileType := range fileTypes {
+		wrapped[name] = verifiableFileType{FileType: fileType, run: run}
+	}
+	return wrapped
+}
+
+// verifiableFileType assembles files to a temporary file in verify mode, to
+// compare them with the files on disk.
+type verifiableFileType struct {
+	generator.FileType
+	run *runState
+}
+
+func (t verifiableFileType) AssembleFile(f *generator.File, path string) error {
+	run := t.run
+	if run.planner != nil {
+		run.planner.record(manifestPath(path))
+		return nil
+	}
+	regions, err := protectedRegions(path)
+	if err != nil {
+		return err
+	}
+	if run.verifier == nil {
+		slog.Log(context.Background(), loader.LevelTrace, "writing file", "path", path)
+		if err := t.FileType.AssembleFile(f, path); err != nil {
+			return err
+		}
+		finishing := run.needsFinishing(path, regions)
+		if !finishing && run.recorder == nil && run.lineEndings == LineEndingsLF {
+			return nil
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if finishing || run.lineEndings != LineEndingsLF {
+			if finishing {
+				if contents, err = run.finishGoFile(path, contents, regions); err != nil {
+					return err
+				}
+			}
+			contents = WithLineEndings(contents, run.lineEndings)
+			if err := os.WriteFile(path, contents, 0o644); err != nil {
+				return err
+			}
+		}
+		run.recorder.recordOutput(path, contents)
+		return nil
+	}
+
+	slog.Log(context.Background(), loader.LevelTrace, "verifying file", "path", path)
+	tmpFile, err := os.CreateTemp("", "controller-gen-verify-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if err := t.FileType.AssembleFile(f, tmpFile.Name()); err != nil {
+		return err
+	}
+	contents, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return err
+	}
+	if contents, err = run.finishGoFile(path, contents, regions); err != nil {
+		return err
+	}
+	return run.verifier.check(path, WithLineEndings(contents, run.lineEndings))
+}
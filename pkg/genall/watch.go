@@ -0,0 +1,169 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package genall
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// generatedCodeComment matches the comment of generated Go files, as per
+// https://go.dev/s/generatedcode.
+var generatedCodeComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// Watch runs the Generators of the runtimes made by newRuntime, again each
+// time the hand-written Go files of their packages change, until the given
+// context is done.
+//
+// Changes are debounced by the given delay, so that saving several files at
+// once only runs the generators once.  Generated files (like the ones written
+// by the runs themselves), and files whose contents didn't change, are
+// ignored.  Each run loads the
+// packages anew, so that new packages matching the paths are picked up, but
+// a new package directory is only watched after the next run.  Errors of
+// runs are written to the given writer, without stopping.
+func Watch(ctx context.Context, newRuntime func() (*Runtime, error), delay time.Duration, out io.Writer) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	watched := make(map[string]bool)
+	files := make(goFiles)
+	run := func() error {
+		rt, err := newRuntime()
+		if err != nil {
+			return err
+		}
+		rt.ErrorWriter = out
+		if hadErrs := rt.RunContext(ctx); hadErrs {
+			fmt.Fprintln(out, "not all generators ran successfully")
+		}
+		for _, root := range rt.Roots {
+			for _, file := range root.GoFiles {
+				dir := filepath.Dir(file)
+				if watched[dir] {
+					continue
+				}
+				if err := watcher.Add(dir); err != nil {
+					fmt.Fprintln(out, err)
+					continue
+				}
+				if err := files.addDir(dir); err != nil {
+					fmt.Fprintln(out, err)
+				}
+				watched[dir] = true
+			}
+		}
+		fmt.Fprintf(out, "watching %d directories for changes\n", len(watched))
+		return nil
+	}
+	if err := run(); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(delay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op != fsnotify.Chmod && files.changed(event.Name) {
+				timer.Reset(delay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintln(out, err)
+		case <-timer.C:
+			fmt.Fprintln(out, "regenerating")
+			if err := run(); err != nil {
+				fmt.Fprintln(out, err)
+			}
+		}
+	}
+}
+
+// goFiles keeps track of the contents of the hand-written, non-test Go files
+// of watched directories, which the generators might read.
+type goFiles map[string][]byte
+
+// addDir starts tracking the Go files of the given directory.
+func (f goFiles) addDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			f.changed(filepath.Join(dir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// changed checks if the hand-written Go file at the given path changed since
+// it was last seen.  Generated files are ignored, so that the files written
+// by runs don't trigger new runs.
+func (f goFiles) changed(path string) bool {
+	if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+		return false
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		// e.g. removed
+		_, known := f[path]
+		delete(f, path)
+		return known
+	}
+	if isGenerated(contents) {
+		return false
+	}
+	previous, known := f[path]
+	f[path] = contents
+	return !known || !bytes.Equal(previous, contents)
+}
+
+// isGenerated checks if the given contents of a Go file are generated.
+func isGenerated(contents []byte) bool {
+	for line := range strings.Lines(string(contents)) {
+		line = strings.TrimRight(line, "\r\n")
+		if generatedCodeComment.MatchString(line) {
+			return true
+		}
+		if strings.HasPrefix(line, "package ") {
+			// the comment must come before the package clause
+			return false
+		}
+	}
+	return false
+}
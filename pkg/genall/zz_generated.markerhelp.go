@@ -35,6 +35,17 @@ func (InputPaths) Help() *markers.DefinitionHelp {
 	}
 }
 
+func (KindFilter) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "restricts the API kinds a generator processes (e.g. \"crd:only=batch.tutorial.kubebuilder.io/v1/CronJob\").",
+			Details: "Each pattern is of the form \"group\", \"group/version\" or\n\"group/version/Kind\", where any part may be \"*\", and a kind is processed\nif any of the patterns matches one of its versions.  Multiple patterns can\nbe specified using \"{pattern1, pattern2}\".\n\nGenerators which don't process API kinds (e.g. rbac or object) ignore it.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{},
+	}
+}
+
 func (OutputArtifacts) Help() *markers.DefinitionHelp {
 	return &markers.DefinitionHelp{
 		Category: "",
@@ -55,6 +66,66 @@ func (OutputArtifacts) Help() *markers.DefinitionHelp {
 	}
 }
 
+func (OutputToArchive) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "outputs all the artifacts to a single archive, e.g. to attach them to a release.",
+			Details: "The format of the archive is given by the extension of its file: a gzipped\ntarball for \".tar.gz\" or \".tgz\", and a zip file for \".zip\".  Artifacts are\nstored by path, with package-associated ones under the directory of their\npackage (relative to the working directory, or its import path otherwise),\nalong with a manifest.json listing the size and SHA-256 digest of each.\nThe archive is written once all the generators ran, and is reproducible:\nthe same artifacts always make for the same archive.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"File": {
+				Summary: "is the path of the archive, e.g. dist/manifests.tar.gz.",
+				Details: "",
+			},
+		},
+	}
+}
+
+func (OutputToBundle) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "outputs all the manifests to a single file, ordered so that",
+			Details: "they can be applied at once (e.g. with kubectl apply -f).\n\nObjects are ordered by kind, as Helm installs them: namespaces first, then\nCRDs before RBAC objects, workloads, webhook configurations, and finally\nunknown kinds (e.g. custom resources).  The file is written once all the\ngenerators ran.  Other artifacts are output next to the file, while\npackage-associated ones are output to their package's source files'\ndirectory, as with artifacts.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"File": {
+				Summary: "is the path of the bundle, e.g. config/install.yaml.",
+				Details: "",
+			},
+			"Namespace": {
+				Summary: "adds a namespace of the given name to the bundle.",
+				Details: "",
+			},
+		},
+	}
+}
+
+func (OutputToCluster) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "applies all the manifests to a cluster with server-side",
+			Details: "apply, e.g. to refresh the CRDs of a development cluster in one step.\n\nThe manifests are applied once all the generators ran, in the same order\nas bundled (namespaces and CRDs first), taking over the fields managed by\nothers.  Package-associated artifacts are output to their package's source\nfiles' directory, as with artifacts.  Nothing is applied in verify mode.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"Kubeconfig": {
+				Summary: "is the path of the kubeconfig file of the cluster (defaults",
+				Details: "to the --kubeconfig flag, $KUBECONFIG, or ~/.kube/config).",
+			},
+			"Context": {
+				Summary: "is the context of the kubeconfig file to use (defaults to the",
+				Details: "--context flag, or the current context).",
+			},
+			"FieldManager": {
+				Summary: "is the field manager of the applied fields (defaults to",
+				Details: "controller-gen).",
+			},
+		},
+	}
+}
+
 func (OutputToDirectory) Help() *markers.DefinitionHelp {
 	return &markers.DefinitionHelp{
 		Category: "",
@@ -66,6 +137,30 @@ func (OutputToDirectory) Help() *markers.DefinitionHelp {
 	}
 }
 
+func (OutputToHelmChart) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "outputs artifacts into the layout of a Helm chart.",
+			Details: "Non-package associated artifacts (e.g. CRDs) are output to the crds\ndirectory of the chart, which Helm installs before the rest of the chart,\nbut never upgrades nor deletes.  When templated, they're output to the\ntemplates/crds directory instead, as templates installed if the\n<valuesKey>.enable value is set, and kept on uninstall if the\n<valuesKey>.keep value is set, so that they're upgraded with the chart.\n\nPackage-associated artifacts are output to their package's source files'\ndirectory, as with artifacts.",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"Chart": {
+				Summary: "points to the directory of the chart.",
+				Details: "",
+			},
+			"Templated": {
+				Summary: "outputs templates to templates/crds, rather than plain files to crds.",
+				Details: "",
+			},
+			"ValuesKey": {
+				Summary: "is the key of the values of templates (defaults to \"crd\").",
+				Details: "",
+			},
+		},
+	}
+}
+
 func (outputToNothing) Help() *markers.DefinitionHelp {
 	return &markers.DefinitionHelp{
 		Category: "",
@@ -81,8 +176,8 @@ func (outputToStdout) Help() *markers.DefinitionHelp {
 	return &markers.DefinitionHelp{
 		Category: "",
 		DetailedHelp: markers.DetailedHelp{
-			Summary: "outputs everything to standard-out, with no separation.",
-			Details: "Generally useful for single-artifact outputs.",
+			Summary: "outputs everything to standard-out, ordered by path.",
+			Details: "YAML artifacts are output as a multi-document YAML stream (e.g. for\n\"kubectl apply -f -\"), each preceded by a \"# Source: <path>\" comment.",
 		},
 		FieldHelp: map[string]markers.DetailedHelp{},
 	}
@@ -0,0 +1,39 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package golden is a harness for golden-file tests of generators, e.g. of
+// the generators of a project, or of plugins: the artifacts generated into a
+// temporary directory are compared with the golden files checked in with the
+// test, and the golden files are updated instead when the tests are run with
+// -update:
+//
+//	func TestCRDs(t *testing.T) {
+//		out := golden.Generate(t, "testdata", []genall.Generator{crd.Generator{}}, "./...")
+//		golden.Compare(t, "testdata/golden", out)
+//	}
+//
+//	go test ./... -update
+//
+// Golden files checked in along with the sources they're generated from are
+// compared with CompareFiles instead, and the errors of invalid packages are
+// checked with GenerateErrors.
+//
+// Golden files are compared regardless of their line endings, so that the
+// tests pass on checkouts with Windows line endings.
+//
+// Importing the package registers the -update flag of the test binary, so it
+// can't be used along with another -update flag.
+package golden
@@ -0,0 +1,340 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golden
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/tools/go/packages"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// Version is the version of controller-gen recorded in the artifacts
+// generated by Generate.
+const Version = "v0.0.0-golden"
+
+// update is the -update flag of the test binaries importing this package.
+var update = flag.Bool("update", false, "update the golden files with the generated artifacts, instead of comparing them")
+
+// Updating returns true if the golden files are updated instead of compared,
+// i.e. if the tests were run with -update.
+func Updating() bool {
+	return *update
+}
+
+// TB is the subset of testing.TB the harness uses, so that it can be used
+// with testing.T as well as ginkgo.GinkgoT().
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+	TempDir() string
+}
+
+// Generate runs the given generators on the packages of the given paths (e.g.
+// "./..."), loaded from the given directory, returning the temporary
+// directory it output their artifacts to.
+//
+// Artifacts that aren't associated with a package (e.g. CRD manifests) are
+// output to the directory itself, while the ones of a package (e.g. Go code)
+// are output to the directory of the package relative to the given directory
+// (or to its import path, for packages outside of it), so that the artifacts
+// of several packages don't overwrite each other.  The version of
+// controller-gen recorded in the artifacts is pinned to Version, so that the
+// golden files don't depend on how the tests are built.
+//
+// The test fails if the generators report errors.
+func Generate(t TB, dir string, generators []genall.Generator, paths ...string) string {
+	t.Helper()
+
+	outputDir, errOut, hadErrs := generate(t, dir, generators, paths...)
+	if hadErrs {
+		t.Fatalf("generation failed:\n%s", errOut)
+		return ""
+	}
+	return outputDir
+}
+
+// GenerateErrors runs the given generators as Generate does, expecting them
+// to fail, e.g. on invalid markers or unsupported types, and returns the
+// errors they reported, one per line.
+//
+// The test fails if the generators don't report any error.
+func GenerateErrors(t TB, dir string, generators []genall.Generator, paths ...string) string {
+	t.Helper()
+
+	_, errOut, hadErrs := generate(t, dir, generators, paths...)
+	if !hadErrs {
+		t.Fatalf("generation succeeded, but was expected to fail")
+		return ""
+	}
+	return errOut
+}
+
+// generate runs the given generators on the packages of the given paths,
+// returning the directory it output their artifacts to, the errors they
+// reported, and whether they reported any.
+func generate(t TB, dir string, generators []genall.Generator, paths ...string) (string, string, bool) {
+	t.Helper()
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		t.Fatalf("unable to find the directory of the packages: %v", err)
+		return "", "", false
+	}
+	gens := make(genall.Generators, len(generators))
+	for i := range generators {
+		gens[i] = &generators[i]
+	}
+	rt, err := gens.ForRootsWithConfig(&packages.Config{Dir: absDir}, paths...)
+	if err != nil {
+		t.Fatalf("unable to load the packages: %v", err)
+		return "", "", false
+	}
+
+	outputDir := t.TempDir()
+	var errOut bytes.Buffer
+	rt.ErrorWriter = &errOut
+	rt.OutputRules = genall.OutputRules{Default: outputByPackage{root: absDir, dir: outputDir}}
+	rt.Version = Version
+	hadErrs := rt.Run()
+	return outputDir, errOut.String(), hadErrs
+}
+
+// outputByPackage outputs the artifacts of each package to their own
+// directory, and the others to the root of a directory.
+type outputByPackage struct {
+	// root is the directory of the loaded packages.
+	root string
+	// dir is the directory to output to.
+	dir string
+}
+
+func (o outputByPackage) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	if pkg == nil {
+		return genall.OutputToDirectory(o.dir).Open(pkg, itemPath)
+	}
+	pkgDir := filepath.FromSlash(pkg.PkgPath)
+	if len(pkg.CompiledGoFiles) > 0 {
+		rel, err := filepath.Rel(o.root, filepath.Dir(pkg.CompiledGoFiles[0]))
+		if err == nil && filepath.IsLocal(rel) {
+			pkgDir = rel
+		}
+	}
+	return genall.OutputToDirectory(filepath.Join(o.dir, pkgDir)).Open(pkg, itemPath)
+}
+
+// Compare compares the files of the given directory (e.g. as returned by
+// Generate) with the golden files of the given golden directory, failing the
+// test with their unified diffs if any of them is missing, extra, or
+// different.
+//
+// When updating (see Updating), the golden directory is replaced with the
+// given directory instead, so the golden directory mustn't contain anything
+// but golden files.
+func Compare(t TB, goldenDir, actualDir string) {
+	t.Helper()
+
+	actual, err := readFiles(actualDir)
+	if err != nil {
+		t.Fatalf("unable to read the generated files: %v", err)
+		return
+	}
+	if Updating() {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatalf("unable to remove the golden files: %v", err)
+			return
+		}
+		for path, contents := range actual {
+			writeGolden(t, filepath.Join(goldenDir, path), contents)
+		}
+		return
+	}
+
+	expected, err := readFiles(goldenDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("unable to read the golden files: %v", err)
+		return
+	}
+	compare(t, goldenDir, expected, actual)
+}
+
+// CompareFiles compares the files of the given directory (e.g. as returned by
+// Generate) with the golden files at the given slash-separated paths of the
+// given golden directory, as Compare does, for golden directories holding
+// other files too, e.g. golden files checked in along with the sources
+// they're generated from.
+//
+// When updating (see Updating), the golden files at the given paths are
+// written with the generated files instead.
+func CompareFiles(t TB, goldenDir, actualDir string, paths ...string) {
+	t.Helper()
+
+	actual, err := readFiles(actualDir)
+	if err != nil {
+		t.Fatalf("unable to read the generated files: %v", err)
+		return
+	}
+	if Updating() {
+		for _, path := range paths {
+			if contents, generated := actual[path]; generated {
+				writeGolden(t, filepath.Join(goldenDir, path), contents)
+			}
+		}
+		return
+	}
+
+	expected := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		contents, err := os.ReadFile(filepath.Join(goldenDir, filepath.FromSlash(path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unable to read the golden files: %v", err)
+			return
+		}
+		expected[path] = contents
+	}
+	compare(t, goldenDir, expected, actual)
+}
+
+// compare compares the given golden files of the given golden directory with
+// the generated ones, by path, failing the test with their unified diffs if
+// any of them is missing, extra, or different.
+func compare(t TB, goldenDir string, expected, actual map[string][]byte) {
+	t.Helper()
+
+	paths := slices.Sorted(maps.Keys(actual))
+	for path := range expected {
+		if _, generated := actual[path]; !generated {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	var diffs []string
+	for _, path := range paths {
+		expectedContents, isGolden := expected[path]
+		actualContents, generated := actual[path]
+		switch {
+		case !isGolden:
+			diffs = append(diffs, fmt.Sprintf("%s: generated, but not golden", path))
+		case !generated:
+			diffs = append(diffs, fmt.Sprintf("%s: golden, but not generated", path))
+		case !sameText(expectedContents, actualContents):
+			diffs = append(diffs, unifiedDiff(filepath.Join(goldenDir, path), expectedContents, actualContents))
+		}
+	}
+	if len(diffs) > 0 {
+		t.Errorf("generated files differ from the golden files of %s (run the tests with -update to update them):\n\n%s", goldenDir, strings.Join(diffs, "\n"))
+	}
+}
+
+// CompareFile compares the given contents with the golden file at the given
+// path, failing the test with their unified diff if they differ.
+//
+// When updating (see Updating), the golden file is written with the contents
+// instead.
+func CompareFile(t TB, goldenPath string, actual []byte) {
+	t.Helper()
+
+	if Updating() {
+		writeGolden(t, goldenPath, actual)
+		return
+	}
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("unable to read the golden file (run the tests with -update to write it): %v", err)
+		return
+	}
+	if !sameText(expected, actual) {
+		t.Errorf("generated file differs from the golden file (run the tests with -update to update it):\n\n%s", unifiedDiff(goldenPath, expected, actual))
+	}
+}
+
+// sameText checks whether the given contents are the same, regardless of
+// their line endings, since golden files may be checked out with Windows
+// line endings (e.g. with git's core.autocrlf).
+func sameText(expected, actual []byte) bool {
+	return bytes.Equal(genall.WithLineEndings(expected, genall.LineEndingsLF), genall.WithLineEndings(actual, genall.LineEndingsLF))
+}
+
+// readFiles reads the files under the given directory, by slash-separated
+// path relative to it.
+func readFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = contents
+		return nil
+	})
+	return files, err
+}
+
+// writeGolden writes the golden file at the given path (and its directory).
+func writeGolden(t TB, path string, contents []byte) {
+	t.Helper()
+
+	path = filepath.FromSlash(path)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatalf("unable to create the directory of the golden file: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, contents, 0o644); err != nil {
+		t.Fatalf("unable to write the golden file: %v", err)
+	}
+}
+
+// unifiedDiff returns the unified diff from the expected contents of the
+// golden file at the given path to the actual ones.
+func unifiedDiff(path string, expected, actual []byte) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: path,
+		ToFile:   path + " (generated)",
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Sprintf("%s: unable to compute the diff: %v", path, err)
+	}
+	return diff
+}
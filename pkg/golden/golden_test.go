@@ -0,0 +1,170 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golden_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/deepcopy"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+)
+
+// recordingT records the failures of a test, to check the ones of the
+// harness.
+type recordingT struct {
+	tempDir  string
+	errors   []string
+	fatalled bool
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...any) {
+	t.Errorf(format, args...)
+	t.fatalled = true
+}
+
+func (t *recordingT) TempDir() string {
+	return t.tempDir
+}
+
+// updating runs the rest of the spec as if the tests were run with -update.
+func updating() {
+	Expect(flag.Set("update", "true")).To(Succeed())
+	DeferCleanup(func() { Expect(flag.Set("update", strconv.FormatBool(false))).To(Succeed()) })
+}
+
+var _ = Describe("Golden-file testing", func() {
+	var t *recordingT
+	var goldenDir, actualDir string
+
+	BeforeEach(func() {
+		t = &recordingT{tempDir: GinkgoT().TempDir()}
+		goldenDir = filepath.Join(GinkgoT().TempDir(), "golden")
+		actualDir = GinkgoT().TempDir()
+
+		Expect(os.MkdirAll(filepath.Join(goldenDir, "sub"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(goldenDir, "a.yaml"), []byte("a: 1\nb: 2\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(goldenDir, "sub", "c.yaml"), []byte("c: 3\n"), 0o644)).To(Succeed())
+	})
+
+	It("should generate the artifacts of each package into its own directory", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{deepcopy.Generator{}}, "./api/...")
+		Expect(filepath.Join(out, "api", "v1", "zz_generated.deepcopy.go")).To(BeARegularFile())
+		golden.Compare(GinkgoT(), filepath.Join("testdata", "golden"), out)
+	})
+
+	It("should fail on the generation errors", func() {
+		golden.Generate(t, "testdata", []genall.Generator{deepcopy.Generator{}}, "./nonexistent")
+		Expect(t.fatalled).To(BeTrue())
+	})
+
+	It("should return the generation errors, expecting some", func() {
+		errOut := golden.GenerateErrors(t, "testdata", []genall.Generator{deepcopy.Generator{}}, "./invalid/...")
+		Expect(t.fatalled).To(BeFalse())
+		Expect(t.errors).To(BeEmpty())
+		Expect(errOut).To(ContainSubstring("expected 'package'"))
+
+		golden.GenerateErrors(t, "testdata", []genall.Generator{deepcopy.Generator{}}, "./api/...")
+		Expect(t.fatalled).To(BeTrue(), "successful generations should fail the test")
+	})
+
+	DescribeTable("should return the errors of the packages the parser can't generate schemas of",
+		func(path, message string) {
+			errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{crd.Generator{}}, path)
+			Expect(errOut).To(ContainSubstring(message))
+		},
+		Entry("of unsupported types", "./invalid/types", "unsupported AST kind *ast.ChanType"),
+		Entry("with invalid markers", "./invalid/markers", `invalid marker +kubebuilder:validation:Minimum: expected integer or float, got "one"`),
+	)
+
+	It("should pass if the files match the golden files", func() {
+		Expect(os.CopyFS(actualDir, os.DirFS(goldenDir))).To(Succeed())
+		golden.Compare(t, goldenDir, actualDir)
+		Expect(t.errors).To(BeEmpty())
+	})
+
+	It("should pass if the golden files only differ by their line endings", func() {
+		Expect(os.CopyFS(actualDir, os.DirFS(goldenDir))).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(goldenDir, "a.yaml"), []byte("a: 1\r\nb: 2\r\n"), 0o644)).To(Succeed())
+		golden.Compare(t, goldenDir, actualDir)
+		golden.CompareFile(t, filepath.Join(goldenDir, "a.yaml"), []byte("a: 1\nb: 2\n"))
+		Expect(t.errors).To(BeEmpty())
+	})
+
+	It("should report the missing, extra and different files, with their diffs", func() {
+		Expect(os.WriteFile(filepath.Join(actualDir, "a.yaml"), []byte("a: 1\nb: 3\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(actualDir, "d.yaml"), []byte("d: 4\n"), 0o644)).To(Succeed())
+
+		golden.Compare(t, goldenDir, actualDir)
+		Expect(t.errors).To(HaveLen(1))
+		Expect(t.errors[0]).To(ContainSubstring("-b: 2\n+b: 3\n"))
+		Expect(t.errors[0]).To(ContainSubstring("d.yaml: generated, but not golden"))
+		Expect(t.errors[0]).To(ContainSubstring("sub/c.yaml: golden, but not generated"))
+	})
+
+	It("should only compare the given golden files with the generated ones", func() {
+		Expect(os.WriteFile(filepath.Join(actualDir, "a.yaml"), []byte("a: 1\nb: 2\n"), 0o644)).To(Succeed())
+		golden.CompareFiles(t, goldenDir, actualDir, "a.yaml")
+		Expect(t.errors).To(BeEmpty(), "the other files of the golden directory should be ignored")
+
+		golden.CompareFiles(t, goldenDir, actualDir, "a.yaml", "sub/c.yaml")
+		Expect(t.errors).To(ConsistOf(ContainSubstring("sub/c.yaml: golden, but not generated")))
+
+		By("writing the given golden files only when updating")
+		updating()
+		Expect(os.WriteFile(filepath.Join(actualDir, "a.yaml"), []byte("a: 2\n"), 0o644)).To(Succeed())
+		golden.CompareFiles(t, goldenDir, actualDir, "a.yaml")
+		Expect(os.ReadFile(filepath.Join(goldenDir, "a.yaml"))).To(Equal([]byte("a: 2\n")))
+		Expect(filepath.Join(goldenDir, "sub", "c.yaml")).To(BeARegularFile())
+	})
+
+	It("should replace the golden files when updating", func() {
+		updating()
+		Expect(os.WriteFile(filepath.Join(actualDir, "d.yaml"), []byte("d: 4\n"), 0o644)).To(Succeed())
+
+		golden.Compare(t, goldenDir, actualDir)
+		Expect(t.errors).To(BeEmpty())
+		entries, err := os.ReadDir(goldenDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(os.ReadFile(filepath.Join(goldenDir, "d.yaml"))).To(Equal([]byte("d: 4\n")))
+	})
+
+	It("should compare and update single golden files", func() {
+		golden.CompareFile(t, filepath.Join(goldenDir, "a.yaml"), []byte("a: 1\nb: 2\n"))
+		Expect(t.errors).To(BeEmpty())
+		golden.CompareFile(t, filepath.Join(goldenDir, "a.yaml"), []byte("a: 2\nb: 2\n"))
+		Expect(t.errors).To(ConsistOf(ContainSubstring("-a: 1\n+a: 2\n")))
+
+		updating()
+		golden.CompareFile(t, filepath.Join(goldenDir, "new", "e.yaml"), []byte("e: 5\n"))
+		Expect(os.ReadFile(filepath.Join(goldenDir, "new", "e.yaml"))).To(Equal([]byte("e: 5\n")))
+	})
+})
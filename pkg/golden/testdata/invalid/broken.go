@@ -0,0 +1 @@
+broken
@@ -0,0 +1,284 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiserver starts throwaway API servers, backed by etcd, from the
+// binaries used by envtest (as installed by setup-envtest).
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// AssetsEnv is the environment variable pointing to the directory of the
+// etcd and kube-apiserver binaries, as with envtest.
+const AssetsEnv = "KUBEBUILDER_ASSETS"
+
+// startTimeout is how long the API server has to become ready.
+const startTimeout = time.Minute
+
+// Server is a running API server, along with its etcd.
+type Server struct {
+	// Config is the configuration of the clients of the API server, as a
+	// member of system:masters.
+	Config *rest.Config
+
+	dir       string
+	etcd      *process
+	apiServer *process
+}
+
+// process is a running binary.
+type process struct {
+	cmd *exec.Cmd
+	// output is the standard and error output of the binary.
+	output bytes.Buffer
+	// exited is closed once the binary exited.
+	exited chan struct{}
+}
+
+// Start starts an API server (and its etcd) from the binaries of the
+// directory of $KUBEBUILDER_ASSETS, or of $PATH if it's unset, waiting for it
+// to be ready.  It must be stopped once done with.
+func Start(ctx context.Context) (*Server, error) {
+	etcdPath, apiServerPath, err := Binaries()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "controller-gen-apiserver-*")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{dir: dir}
+	if err := s.start(ctx, etcdPath, apiServerPath); err != nil {
+		return nil, errors.Join(err, s.Stop())
+	}
+	return s, nil
+}
+
+// Binaries returns the paths of the etcd and kube-apiserver binaries that
+// Start starts, in the directory of $KUBEBUILDER_ASSETS, or in $PATH if it's
+// unset.
+func Binaries() (etcdPath, apiServerPath string, err error) {
+	if etcdPath, err = binaryPath("etcd"); err != nil {
+		return "", "", err
+	}
+	if apiServerPath, err = binaryPath("kube-apiserver"); err != nil {
+		return "", "", err
+	}
+	return etcdPath, apiServerPath, nil
+}
+
+// start starts etcd and the API server with the given binaries.
+func (s *Server) start(ctx context.Context, etcdPath, apiServerPath string) error {
+	ports, err := freePorts(3)
+	if err != nil {
+		return err
+	}
+	etcdURL := "http://127.0.0.1:" + strconv.Itoa(ports[0])
+	s.etcd, err = startProcess(etcdPath,
+		"--data-dir="+filepath.Join(s.dir, "etcd"),
+		"--listen-client-urls="+etcdURL,
+		"--advertise-client-urls="+etcdURL,
+		"--listen-peer-urls=http://127.0.0.1:"+strconv.Itoa(ports[1]),
+		"--unsafe-no-fsync=true",
+	)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.writeCredentials()
+	if err != nil {
+		return err
+	}
+	apiServerURL := "https://127.0.0.1:" + strconv.Itoa(ports[2])
+	s.apiServer, err = startProcess(apiServerPath,
+		"--etcd-servers="+etcdURL,
+		"--cert-dir="+filepath.Join(s.dir, "certs"),
+		"--bind-address=127.0.0.1",
+		"--advertise-address=127.0.0.1",
+		"--secure-port="+strconv.Itoa(ports[2]),
+		"--service-cluster-ip-range=10.0.0.0/24",
+		"--allow-privileged=true",
+		"--authorization-mode=AlwaysAllow",
+		"--token-auth-file="+filepath.Join(s.dir, "tokens.csv"),
+		"--service-account-issuer="+apiServerURL,
+		"--service-account-key-file="+filepath.Join(s.dir, "sa.key"),
+		"--service-account-signing-key-file="+filepath.Join(s.dir, "sa.key"),
+		"--disable-admission-plugins=ServiceAccount",
+	)
+	if err != nil {
+		return err
+	}
+
+	s.Config = &rest.Config{
+		Host:        apiServerURL,
+		BearerToken: token,
+		// the serving certificate is self-signed by the API server
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+	}
+	return s.waitForReadiness(ctx)
+}
+
+// writeCredentials writes the token file and the service account key of the
+// API server, returning the token of its clients.
+func (s *Server) writeCredentials() (string, error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	tokens := fmt.Sprintf("%s,controller-gen,controller-gen,\"system:masters\"\n", token)
+	if err := os.WriteFile(filepath.Join(s.dir, "tokens.csv"), []byte(tokens), 0o600); err != nil {
+		return "", err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(filepath.Join(s.dir, "sa.key"), keyPEM, 0o600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// waitForReadiness waits for the API server to be ready, failing if it (or
+// etcd) exits first.
+func (s *Server) waitForReadiness(ctx context.Context) error {
+	client, err := discovery.NewDiscoveryClientForConfig(s.Config)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		_, err := client.RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+		if err == nil {
+			slog.Debug("started API server", "host", s.Config.Host)
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-s.etcd.exited:
+			return fmt.Errorf("etcd exited: %s", bytes.TrimSpace(s.etcd.output.Bytes()))
+		case <-s.apiServer.exited:
+			return fmt.Errorf("the API server exited: %s", bytes.TrimSpace(s.apiServer.output.Bytes()))
+		case <-ctx.Done():
+			return fmt.Errorf("the API server isn't ready: %w", err)
+		}
+	}
+}
+
+// Stop stops the API server and etcd, and removes their data.
+func (s *Server) Stop() error {
+	var errs []error
+	for _, proc := range []*process{s.apiServer, s.etcd} {
+		if proc != nil {
+			errs = append(errs, proc.stop())
+		}
+	}
+	errs = append(errs, os.RemoveAll(s.dir))
+	return errors.Join(errs...)
+}
+
+// startProcess starts the binary at the given path with the given arguments.
+func startProcess(path string, args ...string) (*process, error) {
+	proc := &process{cmd: exec.Command(path, args...), exited: make(chan struct{})}
+	proc.cmd.Stdout = &proc.output
+	proc.cmd.Stderr = &proc.output
+	if err := proc.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("unable to start %s: %w", filepath.Base(path), err)
+	}
+	go func() {
+		_ = proc.cmd.Wait()
+		close(proc.exited)
+	}()
+	return proc, nil
+}
+
+// stop stops the process, waiting for it to exit.
+func (p *process) stop() error {
+	select {
+	case <-p.exited:
+		return nil
+	default:
+	}
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
+		return p.cmd.Process.Kill()
+	}
+	select {
+	case <-p.exited:
+	case <-time.After(10 * time.Second):
+		if err := p.cmd.Process.Kill(); err != nil {
+			return err
+		}
+		<-p.exited
+	}
+	return nil
+}
+
+// binaryPath returns the path of the given binary, in the directory of
+// $KUBEBUILDER_ASSETS, or in $PATH if it's unset.
+func binaryPath(name string) (string, error) {
+	if assets := os.Getenv(AssetsEnv); assets != "" {
+		path := filepath.Join(assets, name)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("unable to find %s in $%s: %w", name, AssetsEnv, err)
+		}
+		return path, nil
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("unable to find %s, set $%s to the directory of the envtest binaries (e.g. with setup-envtest use -p path): %w", name, AssetsEnv, err)
+	}
+	return path, nil
+}
+
+// freePorts returns the given number of free local ports.
+func freePorts(n int) ([]int, error) {
+	ports := make([]int, 0, n)
+	for range n {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		defer listener.Close()
+		ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+	}
+	return ports, nil
+}
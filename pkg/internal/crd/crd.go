@@ -22,6 +22,38 @@ import (
 	"sigs.k8s.io/controller-tools/pkg/markers"
 )
 
+var (
+	// GroupNameMarker and VersionNameMarker are the package markers read by
+	// GroupVersionForPackage.  They match the definitions registered by the CRD
+	// generator, for use by other generators that need the group-version.
+	GroupNameMarker   = optionalArgument(markers.Must(markers.MakeDefinition("groupName", markers.DescribesPackage, "")))
+	VersionNameMarker = markers.Must(markers.MakeDefinition("versionName", markers.DescribesPackage, ""))
+)
+
+// optionalArgument makes the anonymous argument of the given definition
+// optional, so that e.g. "+groupName=" can be used for the core group.
+func optionalArgument(def *markers.Definition) *markers.Definition {
+	field := def.Fields[""]
+	field.Optional = true
+	def.Fields[""] = field
+	return def
+}
+
+// RegisterGroupVersionMarkers registers GroupNameMarker and VersionNameMarker,
+// unless they've already been registered (along with their help) by another
+// generator.
+func RegisterGroupVersionMarkers(into *markers.Registry) error {
+	for _, def := range []*markers.Definition{GroupNameMarker, VersionNameMarker} {
+		if into.Lookup("+"+def.Name, def.Target) != nil {
+			continue
+		}
+		if err := into.Register(def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GroupVersionForPackage(pkgMarkers markers.MarkerValues, pkg *loader.Package) schema.GroupVersion {
 	if nameVal := pkgMarkers.Get("groupName"); nameVal != nil {
 		versionVal := pkg.Name // a reasonable guess
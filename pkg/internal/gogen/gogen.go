@@ -0,0 +1,217 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gogen contains the helpers shared by the generators of Go code:
+// writing out lines and blocks of code, tracking the imports of the
+// generated files, and formatting and writing them out.
+package gogen
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// CodeWriter assists in writing out Go code lines and blocks to a writer.
+type CodeWriter struct {
+	Out io.Writer
+}
+
+// Line writes a single line.
+func (c *CodeWriter) Line(line string) {
+	fmt.Fprintln(c.Out, line)
+}
+
+// Linef writes a single line with formatting (as per fmt.Sprintf).
+func (c *CodeWriter) Linef(line string, args ...any) {
+	fmt.Fprintf(c.Out, line+"\n", args...)
+}
+
+// If writes an if statement with the given setup/condition clause, executing
+// the given function to write the contents of the block.
+func (c *CodeWriter) If(setup string, block func()) {
+	c.Linef("if %s {", setup)
+	block()
+	c.Line("}")
+}
+
+// IfElse writes if and else statements with the given setup/condition clause, executing
+// the given functions to write the contents of the blocks.
+func (c *CodeWriter) IfElse(setup string, ifBlock func(), elseBlock func()) {
+	c.Linef("if %s {", setup)
+	ifBlock()
+	c.Line("} else {")
+	elseBlock()
+	c.Line("}")
+}
+
+// For writes an for statement with the given setup/condition clause, executing
+// the given function to write the contents of the block.
+func (c *CodeWriter) For(setup string, block func()) {
+	c.Linef("for %s {", setup)
+	block()
+	c.Line("}")
+}
+
+// Imports keeps track of the imports of a generated file, assigning aliases
+// based on the package names as needed.
+type Imports struct {
+	byPath  map[string]string
+	byAlias map[string]string
+	// names are the package names of the imports, by path.
+	names map[string]string
+}
+
+// NewImports returns the imports of a file of the package with the given
+// name, which is "reserved" as an alias to avoid confusing ones.
+func NewImports(pkgName string) *Imports {
+	return &Imports{
+		byPath:  map[string]string{},
+		byAlias: map[string]string{pkgName: ""},
+		names:   map[string]string{},
+	}
+}
+
+// NeedImportAs marks that the given package, with the given name, is needed
+// in the list of imports, with the given alias (e.g. metav1), unless it's
+// already imported.
+func (l *Imports) NeedImportAs(importPath, name, alias string) {
+	if _, exists := l.byPath[importPath]; exists {
+		return
+	}
+	l.byPath[importPath] = alias
+	l.byAlias[alias] = importPath
+	l.names[importPath] = name
+}
+
+// NeedImport marks that the given package, with the given name, is needed in
+// the list of imports, returning the ident (import alias) that should be used
+// to reference the package.
+func (l *Imports) NeedImport(importPath, name string) string {
+	if alias, exists := l.byPath[importPath]; exists {
+		return alias
+	}
+
+	alias := name
+	if _, taken := l.byAlias[alias]; taken {
+		// prefer conventional aliases, e.g. corev1 for k8s.io/api/core/v1
+		if parentAlias := path.Base(path.Dir(importPath)) + name; token.IsIdentifier(parentAlias) {
+			alias = parentAlias
+		}
+	}
+	for i := 2; ; i++ {
+		if _, taken := l.byAlias[alias]; !taken {
+			break
+		}
+		alias = name + strconv.Itoa(i)
+	}
+
+	l.NeedImportAs(importPath, name, alias)
+	return alias
+}
+
+// ImportSpecs returns a string form of each import spec
+// (i.e. `alias "path/to/import"), in the order of their paths.  Aliases are
+// only present when they don't match both the package name and the last
+// element of the path.
+func (l *Imports) ImportSpecs() []string {
+	res := make([]string, 0, len(l.byPath))
+	for importPath, alias := range l.byPath {
+		if alias == l.names[importPath] && alias == path.Base(importPath) {
+			res = append(res, strconv.Quote(importPath))
+		} else {
+			res = append(res, fmt.Sprintf("%s %q", alias, importPath))
+		}
+	}
+	slices.SortFunc(res, func(a, b string) int {
+		return strings.Compare(importPathOf(a), importPathOf(b))
+	})
+	return res
+}
+
+// Block returns the import specs, with the standard library ones grouped
+// first.
+func (l *Imports) Block() string {
+	var std, others []string
+	for _, spec := range l.ImportSpecs() {
+		firstElem, _, _ := strings.Cut(strings.Trim(importPathOf(spec), `"`), "/")
+		if strings.Contains(firstElem, ".") {
+			others = append(others, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	if len(std) == 0 || len(others) == 0 {
+		return strings.Join(append(std, others...), "\n")
+	}
+	return strings.Join(std, "\n") + "\n\n" + strings.Join(others, "\n")
+}
+
+// importPathOf returns the (quoted) path of the given import spec.
+func importPathOf(spec string) string {
+	return spec[strings.Index(spec, `"`):]
+}
+
+// Format formats the given generated code, reporting errors on the given
+// package.  Invalid code is returned as is.
+func Format(pkg *loader.Package, outBytes []byte) []byte {
+	formattedBytes, err := format.Source(outBytes)
+	if err != nil {
+		pkg.AddError(err)
+		// we still write the invalid source to disk to figure out what went wrong
+		return outBytes
+	}
+	return formattedBytes
+}
+
+// WriteOut outputs the given (already formatted) code to the given file in
+// the package.
+func WriteOut(ctx *genall.GenerationContext, root *loader.Package, fileName string, outBytes []byte) {
+	WriteArtifact(ctx, root, root, fileName, outBytes)
+}
+
+// WriteArtifact outputs the given contents to the given file, associated with
+// the given package (nil for config), reporting errors on the given root.
+func WriteArtifact(ctx *genall.GenerationContext, root, pkg *loader.Package, fileName string, outBytes []byte) {
+	outputFile, err := ctx.Open(pkg, fileName)
+	if err != nil {
+		root.AddError(err)
+		return
+	}
+	n, err := outputFile.Write(outBytes)
+	if err != nil {
+		_ = outputFile.Close()
+		root.AddError(err)
+		return
+	}
+	if n < len(outBytes) {
+		_ = outputFile.Close()
+		root.AddError(io.ErrShortWrite)
+		return
+	}
+	// e.g. when the protected regions of the file can't be kept
+	if err := outputFile.Close(); err != nil {
+		root.AddError(err)
+	}
+}
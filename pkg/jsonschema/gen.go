@@ -0,0 +1,274 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// metaSchemas are the URIs of the supported JSON Schema drafts, by name.
+var metaSchemas = map[string]string{
+	"draft-2020-12": "https://json-schema.org/draft/2020-12/schema",
+	"draft-07":      "http://json-schema.org/draft-07/schema#",
+}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates a JSON Schema file for each version of each kind.
+//
+// Files are named <group>/<kind>_<version>.json, in lower case, as expected by
+// the schema locations of kubeconform.  The apiVersion and kind of each file
+// only accept the ones of its kind.
+type Generator struct {
+	// Draft specifies the JSON Schema draft of the files, either
+	// "draft-2020-12" (the default) or "draft-07".
+	Draft string `marker:",optional"`
+
+	// Strict disallows fields which aren't part of the schemata, which the API
+	// server would otherwise prune.
+	Strict bool `marker:",optional"`
+
+	// AllowDangerousTypes allows types which are usually omitted from CRD generation
+	// because they are not recommended.
+	//
+	// Currently the following additional types are allowed when this is true:
+	// float32
+	// float64
+	//
+	// Left unspecified, the default is false
+	AllowDangerousTypes *bool `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	draft := g.Draft
+	if draft == "" {
+		draft = "draft-2020-12"
+	}
+	metaSchema, known := metaSchemas[draft]
+	if !known {
+		return fmt.Errorf("unknown JSON Schema draft %q, expected draft-2020-12 or draft-07", g.Draft)
+	}
+
+	parser := &crd.Parser{
+		Collector:           ctx.Collector,
+		Checker:             ctx.Checker,
+		Kinds:               ctx.Kinds,
+		Cache:               ctx.Cache,
+		AllowDangerousTypes: g.AllowDangerousTypes != nil && *g.AllowDangerousTypes,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(groupKind, nil)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, version := range crdRaw.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			schema := g.convert(version.Schema.OpenAPIV3Schema)
+			schema["$schema"] = metaSchema
+			// only accept objects of the kind, to validate against the right file
+			properties, _ := schema["properties"].(map[string]any)
+			if properties == nil {
+				properties = make(map[string]any)
+				schema["properties"] = properties
+			}
+			properties["apiVersion"] = map[string]any{"type": "string", "enum": []string{groupKind.Group + "/" + version.Name}}
+			properties["kind"] = map[string]any{"type": "string", "enum": []string{groupKind.Kind}}
+
+			fileName := fmt.Sprintf("%s/%s_%s.json", groupKind.Group, strings.ToLower(groupKind.Kind), version.Name)
+			if err := writeJSON(ctx, fileName, schema); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// convert converts the given structural schema into a JSON Schema.
+func (g Generator) convert(schema *apiextensionsv1.JSONSchemaProps) map[string]any {
+	out := make(map[string]any)
+
+	switch {
+	case schema.XIntOrString:
+		out["anyOf"] = []any{map[string]any{"type": "integer"}, map[string]any{"type": "string"}}
+	case schema.Type != "" && schema.Nullable:
+		out["type"] = []string{schema.Type, "null"}
+	case schema.Type != "":
+		out["type"] = schema.Type
+	}
+	if schema.Format != "" {
+		out["format"] = schema.Format
+	}
+	if schema.Description != "" {
+		out["description"] = schema.Description
+	}
+	if schema.Default != nil {
+		out["default"] = json.RawMessage(schema.Default.Raw)
+	}
+	if schema.Example != nil {
+		out["examples"] = []json.RawMessage{schema.Example.Raw}
+	}
+	if len(schema.Enum) > 0 {
+		enum := make([]json.RawMessage, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			enum = append(enum, value.Raw)
+		}
+		if schema.Nullable {
+			enum = append(enum, json.RawMessage("null"))
+		}
+		out["enum"] = enum
+	}
+
+	// exclusive bounds are numbers since draft-06
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum {
+			out["exclusiveMinimum"] = *schema.Minimum
+		} else {
+			out["minimum"] = *schema.Minimum
+		}
+	}
+	if schema.Maximum != nil {
+		if schema.ExclusiveMaximum {
+			out["exclusiveMaximum"] = *schema.Maximum
+		} else {
+			out["maximum"] = *schema.Maximum
+		}
+	}
+	if schema.MultipleOf != nil {
+		out["multipleOf"] = *schema.MultipleOf
+	}
+	if schema.MinLength != nil {
+		out["minLength"] = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		out["maxLength"] = *schema.MaxLength
+	}
+	if schema.Pattern != "" {
+		out["pattern"] = schema.Pattern
+	}
+	if schema.MinItems != nil {
+		out["minItems"] = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		out["maxItems"] = *schema.MaxItems
+	}
+	if schema.UniqueItems || (schema.XListType != nil && *schema.XListType == "set") {
+		out["uniqueItems"] = true
+	}
+	if schema.MinProperties != nil {
+		out["minProperties"] = *schema.MinProperties
+	}
+	if schema.MaxProperties != nil {
+		out["maxProperties"] = *schema.MaxProperties
+	}
+	if len(schema.Required) > 0 {
+		out["required"] = schema.Required
+	}
+
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]any, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			properties[name] = g.convert(&prop)
+		}
+		out["properties"] = properties
+	}
+	switch {
+	case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+		out["additionalProperties"] = g.convert(schema.AdditionalProperties.Schema)
+	case schema.AdditionalProperties != nil:
+		out["additionalProperties"] = schema.AdditionalProperties.Allows
+	case g.Strict && len(schema.Properties) > 0 && (schema.XPreserveUnknownFields == nil || !*schema.XPreserveUnknownFields):
+		out["additionalProperties"] = false
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		out["items"] = g.convert(schema.Items.Schema)
+	}
+
+	for keyword, schemata := range map[string][]apiextensionsv1.JSONSchemaProps{
+		"allOf": schema.AllOf,
+		"anyOf": schema.AnyOf,
+		"oneOf": schema.OneOf,
+	} {
+		if len(schemata) == 0 {
+			continue
+		}
+		converted := make([]any, 0, len(schemata))
+		for _, subSchema := range schemata {
+			converted = append(converted, g.convert(&subSchema))
+		}
+		out[keyword] = converted
+	}
+	if schema.Not != nil {
+		out["not"] = g.convert(schema.Not)
+	}
+
+	return out
+}
+
+// writeJSON writes the given schema out, as indented JSON.
+func writeJSON(ctx *genall.GenerationContext, fileName string, schema map[string]any) error {
+	jsonContent, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	out, err := ctx.Open(nil, fileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	n, err := out.Write(append(jsonContent, '\n'))
+	if err != nil {
+		return err
+	}
+	if n < len(jsonContent) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
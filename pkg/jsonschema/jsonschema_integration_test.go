@@ -0,0 +1,64 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/jsonschema"
+)
+
+var _ = Describe("JSON Schema Generation", func() {
+	It("should generate a JSON Schema for each version of each kind", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{jsonschema.Generator{}}, "./api/...")
+		golden.Compare(GinkgoT(), filepath.Join("testdata", "schemas"), out)
+	})
+
+	It("should disallow unknown fields in strict mode, for the selected draft", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{jsonschema.Generator{Draft: "draft-07", Strict: true}}, "./api/...")
+
+		By("checking the generated schema")
+		contents, err := os.ReadFile(filepath.Join(out, "testdata.kubebuilder.io", "widget_v1.json"))
+		Expect(err).NotTo(HaveOccurred())
+		var schema struct {
+			Schema               string `json:"$schema"`
+			AdditionalProperties *bool  `json:"additionalProperties"`
+			Properties           map[string]struct {
+				AdditionalProperties *bool `json:"additionalProperties"`
+				Properties           map[string]struct {
+					AdditionalProperties *bool `json:"additionalProperties"`
+				} `json:"properties"`
+			} `json:"properties"`
+		}
+		Expect(json.Unmarshal(contents, &schema)).To(Succeed())
+		Expect(schema.Schema).To(Equal("http://json-schema.org/draft-07/schema#"))
+		Expect(schema.AdditionalProperties).To(HaveValue(BeFalse()))
+		Expect(schema.Properties["spec"].AdditionalProperties).To(HaveValue(BeFalse()))
+		Expect(schema.Properties["spec"].Properties["config"].AdditionalProperties).To(BeNil(), "preserved unknown fields should be allowed")
+	})
+
+	It("should fail with unknown drafts", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{jsonschema.Generator{Draft: "draft-04"}}, "./api/...")
+		Expect(errOut).To(ContainSubstring(`unknown JSON Schema draft "draft-04", expected draft-2020-12 or draft-07`))
+	})
+})
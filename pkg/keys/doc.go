@@ -0,0 +1,27 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package keys generates constants for the well-known label and annotation
+// keys owned by a project, from package markers, e.g.:
+//
+//	// +kubebuilder:label:key="apps.example.com/managed-by",description="The controller managing the object."
+//	// +kubebuilder:annotation:key="apps.example.com/paused"
+//	package keys
+//
+// generates ManagedByLabel and PausedAnnotation constants, so that typos in
+// keys are caught at compile time rather than at runtime.  The keys are
+// validated as qualified names, as the API server would.
+package keys
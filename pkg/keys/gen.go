@@ -0,0 +1,234 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keys
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.keys.go"
+
+var (
+	labelMarker      = markers.Must(markers.MakeDefinition("kubebuilder:label", markers.DescribesPackage, Label{}))
+	annotationMarker = markers.Must(markers.MakeDefinition("kubebuilder:annotation", markers.DescribesPackage, Annotation{}))
+)
+
+// +controllertools:marker:generateHelp:category=keys
+
+// Label declares a label key owned by the project.
+//
+// A <Name>Label constant is generated for it, named after the name of the key
+// (e.g. ManagedByLabel for apps.example.com/managed-by) unless a name is set.
+type Label struct {
+	// Key is the label key, e.g. apps.example.com/managed-by.
+	Key string
+	// Name overrides the name of the constant, minus its Label suffix.
+	Name string `marker:",optional"`
+	// Description describes the label.
+	Description string `marker:",optional"`
+}
+
+// +controllertools:marker:generateHelp:category=keys
+
+// Annotation declares an annotation key owned by the project.
+//
+// A <Name>Annotation constant is generated for it, named after the name of
+// the key (e.g. PausedAnnotation for apps.example.com/paused) unless a name is
+// set.
+type Annotation struct {
+	// Key is the annotation key, e.g. apps.example.com/paused.
+	Key string
+	// Name overrides the name of the constant, minus its Annotation suffix.
+	Name string `marker:",optional"`
+	// Description describes the annotation.
+	Description string `marker:",optional"`
+}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates constants for label and annotation keys.
+//
+// The constants of the keys declared by each package are written to
+// zz_generated.keys.go.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := markers.RegisterAll(into, labelMarker, annotationMarker); err != nil {
+		return err
+	}
+	into.AddHelp(labelMarker, Label{}.Help())
+	into.AddHelp(annotationMarker, Annotation{}.Help())
+	return nil
+}
+
+// keyConst is the constant of a label or annotation key.
+type keyConst struct {
+	name        string
+	key         string
+	kind        string
+	description string
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range ctx.Roots {
+		consts, ok := packageKeys(ctx.Collector, root)
+		if !ok || len(consts) == 0 {
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, keysFile(root, headerText, consts))
+	}
+	return nil
+}
+
+// packageKeys returns the key constants of the given package, sorted by name,
+// and whether they're valid.
+func packageKeys(col *markers.Collector, pkg *loader.Package) ([]keyConst, bool) {
+	markerSet, err := markers.PackageMarkers(col, pkg)
+	if err != nil {
+		pkg.AddError(err)
+		return nil, false
+	}
+
+	var consts []keyConst
+	for _, labelVal := range markerSet[labelMarker.Name] {
+		label := labelVal.(Label)
+		consts = append(consts, keyConst{name: label.Name, key: label.Key, kind: "Label", description: label.Description})
+	}
+	for _, annotationVal := range markerSet[annotationMarker.Name] {
+		annotation := annotationVal.(Annotation)
+		consts = append(consts, keyConst{name: annotation.Name, key: annotation.Key, kind: "Annotation", description: annotation.Description})
+	}
+
+	ok := true
+	byName := make(map[string]keyConst, len(consts))
+	byKey := make(map[string]keyConst, len(consts))
+	for i := range consts {
+		keyConst := &consts[i]
+		if errs := validation.IsQualifiedName(keyConst.key); len(errs) > 0 {
+			pkg.AddError(fmt.Errorf("%s key %q is invalid: %s", strings.ToLower(keyConst.kind), keyConst.key, strings.Join(errs, ", ")))
+			ok = false
+			continue
+		}
+		if keyConst.name == "" {
+			keyConst.name = constName(keyConst.key)
+		}
+		if !isExported(keyConst.name) {
+			pkg.AddError(fmt.Errorf("name %q of %s key %q must be an exported identifier", keyConst.name, strings.ToLower(keyConst.kind), keyConst.key))
+			ok = false
+			continue
+		}
+		keyConst.name += keyConst.kind
+
+		if other, exists := byName[keyConst.name]; exists {
+			pkg.AddError(fmt.Errorf("keys %q and %q have the same constant %s, set the name of either", other.key, keyConst.key, keyConst.name))
+			ok = false
+			continue
+		}
+		byName[keyConst.name] = *keyConst
+		if other, exists := byKey[keyConst.kind+keyConst.key]; exists {
+			pkg.AddError(fmt.Errorf("%s key %q is declared twice, as %s and %s", strings.ToLower(keyConst.kind), keyConst.key, other.name, keyConst.name))
+			ok = false
+			continue
+		}
+		byKey[keyConst.kind+keyConst.key] = *keyConst
+	}
+
+	slices.SortFunc(consts, func(a, b keyConst) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return consts, ok
+}
+
+// constName returns the name of the constant of the given key, without its
+// kind suffix, from the name part of the key (e.g. ManagedBy for
+// apps.example.com/managed-by).
+func constName(key string) string {
+	name := key
+	if _, after, found := strings.Cut(key, "/"); found {
+		name = after
+	}
+	var out strings.Builder
+	for part := range strings.FieldsFuncSeq(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		out.WriteString(string(runes))
+	}
+	return out.String()
+}
+
+// isExported checks whether the given name is an exported Go identifier.
+func isExported(name string) bool {
+	for i, r := range name {
+		switch {
+		case i == 0 && !unicode.IsUpper(r):
+			return false
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_':
+			return false
+		}
+	}
+	return name != ""
+}
+
+// keysFile returns the formatted contents of the file of the given package,
+// with the given constants.
+func keysFile(pkg *loader.Package, headerText string, consts []keyConst) []byte {
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+// Label and annotation keys owned by the project.
+const (
+`, pkg.Name, headerText)
+	for _, keyConst := range consts {
+		fmt.Fprintf(outContent, "// %s is the %s key %s.\n", keyConst.name, strings.ToLower(keyConst.kind), keyConst.key)
+		if keyConst.description != "" {
+			fmt.Fprintf(outContent, "//\n// %s\n", strings.ReplaceAll(keyConst.description, "\n", "\n// "))
+		}
+		fmt.Fprintf(outContent, "%s = %q\n", keyConst.name, keyConst.key)
+	}
+	outContent.WriteString(")\n")
+
+	return gogen.Format(pkg, outContent.Bytes())
+}
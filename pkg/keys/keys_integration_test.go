@@ -0,0 +1,43 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keys_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/keys"
+)
+
+var _ = Describe("Keys Generation", func() {
+	headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+
+	It("should generate key constants from the label and annotation markers", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{keys.Generator{HeaderFile: headerFile}}, "./labels/...")
+		golden.CompareFiles(GinkgoT(), "testdata", out, "labels/zz_generated.keys.go")
+	})
+
+	It("should fail with invalid keys and conflicting constants", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{keys.Generator{}}, "./invalid/...")
+		Expect(errOut).To(ContainSubstring(`label key "apps.example.com/Not Valid" is invalid`))
+		Expect(errOut).To(ContainSubstring(`keys "apps.example.com/tier" and "other.example.com/tier" have the same constant TierLabel`))
+		Expect(errOut).To(ContainSubstring(`name "paused" of annotation key "apps.example.com/paused" must be an exported identifier`))
+	})
+})
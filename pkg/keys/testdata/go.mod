@@ -0,0 +1,3 @@
+module testdata.kubebuilder.io/keys
+
+go 1.26.0
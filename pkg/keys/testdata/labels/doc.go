@@ -0,0 +1,24 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package labels holds the label and annotation keys owned by the project.
+//
+// +kubebuilder:label:key="apps.example.com/managed-by",description="The controller managing the object."
+// +kubebuilder:label:key="apps.example.com/tier"
+// +kubebuilder:label:key="apps.example.com/tier-v2",name=LegacyTier
+// +kubebuilder:annotation:key="apps.example.com/paused",description="Pauses the reconciliation of the object,\nuntil removed."
+// +kubebuilder:annotation:key="apps.example.com/tier"
+package labels
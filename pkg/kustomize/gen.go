@@ -0,0 +1,188 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/controller-tools/pkg/rbac"
+	"sigs.k8s.io/controller-tools/pkg/webhook"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates a kustomize base for the generated manifests.
+//
+// The base is written to kustomization.yaml, and includes a kustomization
+// listing the manifests of each of the crd, rbac and webhook generators that
+// generate any, written to kustomization.yaml in their (relative) directory.
+// The defaults match the default output of controller-gen, as well as the
+// config/default base of kubebuilder projects, e.g.:
+//
+//	controller-gen crd rbac:roleName=manager-role webhook kustomize output:kustomize:dir=config/default paths=./...
+//
+// The manifests are assumed to be generated with the default options of the
+// generators (e.g. rbac without a custom fileName, or webhook without split).
+type Generator struct {
+	// Namespace sets the namespace of all the objects of the base.
+	Namespace string `marker:",optional"`
+	// NamePrefix is prefixed to the names of all the objects of the base.
+	NamePrefix string `marker:",optional"`
+	// Labels are added to all the objects of the base, and their selectors.
+	Labels map[string]string `marker:",optional"`
+
+	// CRDDir is the directory of the CRDs, relative to the base (defaults to "../crd").
+	CRDDir string `marker:"crdDir,optional"`
+	// RBACDir is the directory of the RBAC manifests, relative to the base (defaults to "../rbac").
+	RBACDir string `marker:"rbacDir,optional"`
+	// WebhookDir is the directory of the webhook manifests, relative to the base (defaults to "../webhook").
+	WebhookDir string `marker:",optional"`
+
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+// kustomization is a kustomize Kustomization.
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace,omitempty"`
+	NamePrefix string   `json:"namePrefix,omitempty"`
+	Labels     []labels `json:"labels,omitempty"`
+	Resources  []string `json:"resources"`
+}
+
+// labels are labels added by a Kustomization.
+type labels struct {
+	Pairs            map[string]string `json:"pairs"`
+	IncludeSelectors bool              `json:"includeSelectors"`
+}
+
+func newKustomization(resources []string) kustomization {
+	return kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  resources,
+	}
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := (crd.Generator{}).RegisterMarkers(into); err != nil {
+		return err
+	}
+	if err := (rbac.Generator{}).RegisterMarkers(into); err != nil {
+		return err
+	}
+	return webhook.Generator{}.RegisterMarkers(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	components := []struct {
+		dir string
+		gen genall.Generator
+	}{
+		{dir: defaultDir(g.CRDDir, "../crd"), gen: crd.Generator{}},
+		// the name of the role doesn't matter, only the files it's written to
+		{dir: defaultDir(g.RBACDir, "../rbac"), gen: rbac.Generator{RoleName: "manager-role"}},
+		{dir: defaultDir(g.WebhookDir, "../webhook"), gen: webhook.Generator{}},
+	}
+
+	base := newKustomization(nil)
+	base.Namespace = g.Namespace
+	base.NamePrefix = g.NamePrefix
+	if len(g.Labels) > 0 {
+		base.Labels = []labels{{Pairs: g.Labels, IncludeSelectors: true}}
+	}
+	for _, component := range components {
+		files, err := generatedFiles(ctx, component.gen)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		if err := ctx.WriteYAML(path.Join(component.dir, "kustomization.yaml"), headerText, []any{newKustomization(files)}); err != nil {
+			return err
+		}
+		base.Resources = append(base.Resources, component.dir)
+	}
+	if len(base.Resources) == 0 {
+		return nil
+	}
+
+	return ctx.WriteYAML("kustomization.yaml", headerText, []any{base})
+}
+
+// defaultDir returns the given directory, or the given default if unset.
+func defaultDir(dir, defaultDir string) string {
+	if dir == "" {
+		return defaultDir
+	}
+	return dir
+}
+
+// generatedFiles returns the sorted (non-package associated) files the given
+// generator generates.
+func generatedFiles(ctx *genall.GenerationContext, gen genall.Generator) ([]string, error) {
+	recorded := make(recordPaths)
+	genCtx := *ctx
+	genCtx.OutputRule = recorded
+	if err := gen.Generate(&genCtx); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for file, isConfig := range recorded {
+		if isConfig {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// recordPaths records the paths of artifacts, and whether they're non-package
+// associated, discarding their contents.
+type recordPaths map[string]bool
+
+func (r recordPaths) Open(pkg *loader.Package, itemPath string) (io.WriteCloser, error) {
+	r[itemPath] = pkg == nil
+	return genall.OutputToNothing.Open(pkg, itemPath)
+}
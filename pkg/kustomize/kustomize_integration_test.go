@@ -0,0 +1,63 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/kustomize"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+var _ = Describe("Kustomize Generation", func() {
+	It("should generate a base including the generated CRDs, RBAC and webhook manifests", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+
+		By("generating the base into its own directory, next to the ones of the components")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("kustomize", markers.DescribesPackage, kustomize.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			`kustomize:namespace=widget-system,namePrefix=widget-,labels={"app.kubernetes.io/name":"widget"}`,
+			"paths={./api/...,./controllers/...}",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		out := GinkgoT().TempDir()
+		rt.OutputRules = genall.OutputRules{Default: genall.OutputToDirectory(filepath.Join(out, "default"))}
+		Expect(rt.Run()).To(BeFalse())
+
+		golden.Compare(GinkgoT(), "config", out)
+	})
+
+	It("should only include the manifests that are generated", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{kustomize.Generator{CRDDir: "crd"}}, "./api/...")
+		Expect(filepath.Join(out, "crd", "kustomization.yaml")).To(BeARegularFile())
+		contents, err := os.ReadFile(filepath.Join(out, "kustomization.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(HaveSuffix("resources:\n- crd\n"))
+		Expect(os.ReadDir(out)).To(HaveLen(2), "only the CRDs should be included")
+	})
+})
@@ -0,0 +1,25 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+// +kubebuilder:rbac:groups=apps.testdata.kubebuilder.io,resources=widgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.testdata.kubebuilder.io,resources=gadgets,verbs=get,component=gadgets
+
+// +kubebuilder:webhook:path=/validate-apps-testdata-kubebuilder-io-v1-widget,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.testdata.kubebuilder.io,resources=widgets,verbs=create;update,versions=v1,name=vwidget.kb.io,admissionReviewVersions=v1
+
+// WidgetReconciler reconciles widgets.
+type WidgetReconciler struct{}
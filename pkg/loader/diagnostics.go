@@ -0,0 +1,286 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity string
+
+const (
+	// SeverityError is the severity of errors, which make the generation
+	// fail.
+	SeverityError Severity = "error"
+	// SeverityWarning is the severity of warnings.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a machine-readable record of an error or a warning, e.g. for
+// editors and CI annotators to show marker problems inline.
+type Diagnostic struct {
+	// File is the file of the problem, if known.
+	File string `json:"file,omitempty"`
+	// Line is the line of the problem in the file, if known.
+	Line int `json:"line,omitempty"`
+	// Column is the column of the problem in the line, if known.
+	Column int `json:"column,omitempty"`
+	// Package is the import path of the package of the problem, if any.
+	Package string `json:"package,omitempty"`
+	// Marker is the name of the marker causing the problem, if any (see
+	// MarkerError).
+	Marker string `json:"marker,omitempty"`
+	// Severity is the severity of the problem.
+	Severity Severity `json:"severity"`
+	// Message describes the problem.
+	Message string `json:"message"`
+	// SuggestedFix describes how to fix the problem, if known (see
+	// MarkerError).
+	SuggestedFix string `json:"suggestedFix,omitempty"`
+
+	// kind is the kind of package error the diagnostic was reported as, so
+	// that type errors can be skipped.
+	kind packages.ErrorKind
+}
+
+func (d Diagnostic) String() string {
+	switch {
+	case d.File != "":
+		pos := token.Position{Filename: d.File, Line: d.Line, Column: d.Column}
+		return fmt.Sprintf("%s: %s", pos, d.Message)
+	case d.Package != "":
+		return fmt.Sprintf("%s: %s", d.Package, d.Message)
+	default:
+		return d.Message
+	}
+}
+
+// ErrorDiagnostic returns the diagnostic of the given error, which isn't
+// associated with a package (e.g. an error returned by a generator).
+func ErrorDiagnostic(err error) Diagnostic {
+	diag := Diagnostic{
+		Severity: SeverityError,
+		Message:  err.Error(),
+		kind:     packages.UnknownError,
+	}
+	var markerErr MarkerError
+	if errors.As(err, &markerErr) {
+		diag.Marker = markerErr.Marker
+		diag.SuggestedFix = markerErr.SuggestedFix
+	}
+	return diag
+}
+
+// Diagnostics returns the diagnostics of the errors and warnings added to
+// the package.
+func (p *Package) Diagnostics() []Diagnostic {
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+	return slices.Clone(p.diagnostics)
+}
+
+// Diagnostics returns the diagnostics of the errors and warnings associated
+// with all packages in the given package graph, starting at the given root
+// packages and traversing through all imports, as PrintErrors prints them.
+// It will skip any errors of the kinds specified in filterKinds.
+func Diagnostics(pkgs []*Package, filterKinds ...packages.ErrorKind) []Diagnostic {
+	pkgsRaw := make([]*packages.Package, len(pkgs))
+	for i, pkg := range pkgs {
+		pkgsRaw[i] = pkg.Package
+	}
+	wrapped := wrappedPackages(pkgs)
+
+	var diags []Diagnostic
+	packages.Visit(pkgsRaw, nil, func(pkgRaw *packages.Package) {
+		// errors reported when loading the package have no diagnostics
+		pkg := wrapped[pkgRaw]
+		loadErrors := pkgRaw.Errors
+		if pkg != nil {
+			loadErrors = loadErrors[:pkg.loadErrors]
+		}
+		pkgDiags := make([]Diagnostic, 0, len(pkgRaw.Errors))
+		for _, err := range loadErrors {
+			pkgDiags = append(pkgDiags, loadDiagnostic(pkgRaw, err))
+		}
+		if pkg != nil {
+			pkgDiags = append(pkgDiags, pkg.Diagnostics()...)
+		}
+		for _, diag := range pkgDiags {
+			if diag.Severity == SeverityError && slices.Contains(filterKinds, diag.kind) {
+				continue
+			}
+			diags = append(diags, diag)
+		}
+	})
+	return diags
+}
+
+// DowngradeUnneededErrors turns the errors reported when loading the packages
+// in the graph of the given roots into warnings, for the dependencies that
+// nothing needed beyond listing them (they were neither parsed nor had their
+// types loaded, and had no errors added since), e.g. broken dependencies of
+// packages that no generator looked into, so that they don't make the
+// generation fail.  The errors reported at the imports of such dependencies
+// are downgraded as well.  It returns the number of downgraded errors.
+func DowngradeUnneededErrors(roots []*Package) int {
+	var graph []*Package
+	needed := make(map[*Package]bool, len(roots))
+	for _, root := range roots {
+		if _, seen := needed[root]; !seen {
+			needed[root] = true
+			graph = append(graph, root)
+		}
+	}
+	for i := 0; i < len(graph); i++ {
+		for _, importedPkg := range graph[i].Imports() {
+			if _, seen := needed[importedPkg]; seen {
+				continue
+			}
+			load := importedPkg.load()
+			needed[importedPkg] = load.Parsed || load.Types != "" || importedPkg.hasAddedErrors()
+			graph = append(graph, importedPkg)
+		}
+	}
+
+	downgraded := 0
+	for _, pkg := range graph {
+		if !needed[pkg] {
+			downgraded += pkg.downgradeLoadErrors(func(packages.Error) bool { return true })
+			continue
+		}
+		unneededImports := pkg.importPositions(func(importedPkg *Package) bool { return !needed[importedPkg] })
+		if len(unneededImports) > 0 {
+			downgraded += pkg.downgradeLoadErrors(func(err packages.Error) bool {
+				for _, pos := range unneededImports {
+					if err.Pos == pos || strings.HasSuffix(pos, string(filepath.Separator)+err.Pos) {
+						return true
+					}
+				}
+				return false
+			})
+		}
+	}
+	return downgraded
+}
+
+// hasAddedErrors checks whether errors were added to the package since it was
+// loaded.
+func (p *Package) hasAddedErrors() bool {
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+	return len(p.Errors) > p.loadErrors
+}
+
+// importPositions returns the positions (as file:line:column) of the imports
+// of the package, in its parsed files, importing the packages selected by
+// the given function.
+func (p *Package) importPositions(selected func(*Package) bool) []string {
+	var positions []string
+	imports := p.Imports()
+	for _, file := range p.Syntax {
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || imports[path] == nil || !selected(imports[path]) {
+				continue
+			}
+			pos := p.loader.cfg.Fset.Position(spec.Pos())
+			positions = append(positions, fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column))
+		}
+	}
+	return positions
+}
+
+// downgradeLoadErrors turns the errors reported when loading the package
+// that are selected by the given function into warnings, returning their
+// number.
+func (p *Package) downgradeLoadErrors(selected func(packages.Error) bool) int {
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+	var kept []packages.Error
+	downgraded := 0
+	for i, err := range p.Errors {
+		if i >= p.loadErrors || !selected(err) {
+			kept = append(kept, err)
+			continue
+		}
+		diag := loadDiagnostic(p.Package, err)
+		diag.Severity = SeverityWarning
+		p.diagnostics = append(p.diagnostics, diag)
+		downgraded++
+	}
+	p.Errors = kept
+	p.loadErrors -= downgraded
+	return downgraded
+}
+
+// loadDiagnostic returns the diagnostic of an error reported when loading the
+// given package, whose position is formatted as file:line:column (or a prefix
+// of it).
+func loadDiagnostic(pkg *packages.Package, err packages.Error) Diagnostic {
+	diag := Diagnostic{
+		Package:  pkg.PkgPath,
+		Severity: SeverityError,
+		Message:  err.Msg,
+		kind:     err.Kind,
+	}
+	file := err.Pos
+	for _, field := range []*int{&diag.Column, &diag.Line} {
+		i := strings.LastIndex(file, ":")
+		if i < 0 {
+			break
+		}
+		n, convErr := strconv.Atoi(file[i+1:])
+		if convErr != nil {
+			break
+		}
+		*field = n
+		file = file[:i]
+	}
+	if diag.Column != 0 && diag.Line == 0 {
+		// only the line was given
+		diag.Line, diag.Column = diag.Column, 0
+	}
+	if diag.Line != 0 {
+		diag.File = file
+	}
+	return diag
+}
+
+// wrappedPackages returns the Packages wrapping the packages loaded along
+// with the given packages, by the packages they wrap.
+func wrappedPackages(pkgs []*Package) map[*packages.Package]*Package {
+	wrapped := make(map[*packages.Package]*Package)
+	for _, pkg := range pkgs {
+		if pkg.loader == nil {
+			continue
+		}
+		pkg.loader.packagesMu.Lock()
+		maps.Copy(wrapped, pkg.loader.packages)
+		pkg.loader.packagesMu.Unlock()
+	}
+	return wrapped
+}
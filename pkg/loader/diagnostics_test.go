@@ -0,0 +1,118 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/go/packages"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+var _ = Describe("Diagnostics", func() {
+	var pkg *loader.Package
+
+	BeforeEach(func() {
+		cwd, err := os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Chdir("./testmod")).To(Succeed())
+		DeferCleanup(os.Chdir, cwd)
+
+		pkgs, err := loader.LoadRoots("sigs.k8s.io/controller-tools/pkg/loader/testmod/submod1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+		pkg = pkgs[0]
+		pkg.NeedSyntax()
+		Expect(pkg.Syntax).NotTo(BeEmpty())
+	})
+
+	It("should report the position, marker and suggested fix of errors", func() {
+		file := pkg.Syntax[0]
+		pkg.AddError(loader.ErrFromNode(loader.ErrFromMarker(loader.ErrList{
+			errors.New("expected integer"),
+			errors.New("expected string"),
+		}, "foo:bar"), file))
+		pkg.AddError(loader.MarkerError{Marker: "foo:baz", SuggestedFix: "use +foo:qux", Err: errors.New("deprecated")})
+
+		diags := loader.Diagnostics([]*loader.Package{pkg})
+		Expect(diags).To(HaveLen(3))
+		for _, diag := range diags[:2] {
+			Expect(filepath.Base(diag.File)).To(Equal("dummy.go"))
+			Expect(diag.Line).To(BeNumerically(">", 1))
+			Expect(diag.Column).To(Equal(1))
+			Expect(diag.Marker).To(Equal("foo:bar"))
+			Expect(diag.Severity).To(Equal(loader.SeverityError))
+		}
+		Expect(diags[0].Message).To(Equal("expected integer"))
+		Expect(diags[1].Message).To(Equal("expected string"))
+		Expect(diags[2]).To(Equal(loader.Diagnostic{
+			Package:      pkg.PkgPath,
+			Marker:       "foo:baz",
+			Severity:     loader.SeverityError,
+			Message:      "deprecated",
+			SuggestedFix: "use +foo:qux",
+		}))
+		Expect(pkg.Errors).To(HaveLen(3))
+	})
+
+	It("should report warnings without adding them to the errors", func() {
+		pkg.AddWarning(errors.New("rbac rule references unknown API group"))
+
+		Expect(pkg.Errors).To(BeEmpty())
+		diags := loader.Diagnostics([]*loader.Package{pkg})
+		Expect(diags).To(HaveLen(1))
+		Expect(diags[0].Severity).To(Equal(loader.SeverityWarning))
+		Expect(diags[0].String()).To(Equal(pkg.PkgPath + ": rbac rule references unknown API group"))
+	})
+
+	It("should downgrade the load errors of the dependencies nothing needed to warnings", func() {
+		// a file of another package in the directory of a dependency
+		brokenFile, err := filepath.Abs("./submod1/other.go")
+		Expect(err).ToNot(HaveOccurred())
+		load := func() (root, dep *loader.Package) {
+			cfg := &packages.Config{Overlay: map[string][]byte{brokenFile: []byte("package other\n")}}
+			roots, err := loader.LoadRootsWithConfig(cfg, "sigs.k8s.io/controller-tools/pkg/loader/testmod")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(roots).To(HaveLen(1))
+			dep = roots[0].Imports()["sigs.k8s.io/controller-tools/pkg/loader/testmod/submod1"]
+			Expect(dep.Errors).NotTo(BeEmpty())
+			return roots[0], dep
+		}
+
+		root, dep := load()
+		Expect(loader.DowngradeUnneededErrors([]*loader.Package{root})).To(BeNumerically(">", 0))
+		Expect(dep.Errors).To(BeEmpty())
+		diags := loader.Diagnostics([]*loader.Package{root})
+		Expect(diags).NotTo(BeEmpty())
+		Expect(diags).To(HaveEach(HaveField("Severity", loader.SeverityWarning)))
+
+		By("keeping the errors of the dependencies that were parsed")
+		root, dep = load()
+		dep.NeedSyntax()
+		Expect(loader.DowngradeUnneededErrors([]*loader.Package{root})).To(BeZero())
+		Expect(dep.Errors).NotTo(BeEmpty())
+	})
+
+	It("should skip the errors of the given kinds", func() {
+		pkg.AddError(errors.New("unknown"))
+		Expect(loader.Diagnostics([]*loader.Package{pkg}, packages.UnknownError)).To(BeEmpty())
+	})
+})
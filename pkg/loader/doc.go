@@ -20,7 +20,8 @@ limitations under the License.
 // cases where you don't actually care about certain imports.
 //
 // Because it uses go/packages, it's modules-aware, and works in both modules-
-// and non-modules environments.
+// and non-modules environments.  Roots in a Go workspace (go.work) are loaded
+// together, so that the modules of the workspace resolve to each other.
 //
 // # Loading
 //
@@ -33,6 +34,14 @@ limitations under the License.
 // Packages are suitable for comparison, as each unique package only ever has
 // one *Package object returned.
 //
+// Tools already holding packages loaded by golang.org/x/tools/go/packages can
+// wrap them with FromPackages instead, reusing their syntax and types.
+//
+// Files may be replaced or added in memory with the overlays of the config
+// (packages.Config.Overlay), e.g. for unsaved editor buffers.  Their contents
+// are read by the go command and when parsing the packages alike (see
+// Package.ReadFile).
+//
 // # Syntax and TypeChecking
 //
 // ASTs and type-checking information can be loaded with NeedSyntax and
@@ -41,12 +50,31 @@ limitations under the License.
 // check the current package -- if you want to type-check imports as well,
 // you'll need to type-check them first.
 //
+// When only the types of a package are needed (not its syntax or markers),
+// NeedTypesFromExportData loads them from compiler export data instead, which
+// is much cheaper for large dependencies.  It requires loading the packages
+// with packages.NeedExportFile.  Export files are only loaded once first needed, since
+// that means compiling the packages, and a TypesCache keeps the types read
+// from them on disk across loads, keyed by the contents of the files of the
+// packages and of their dependencies, so that unchanged packages are read
+// back without loading export files at all.
+//
+// ReleaseSyntax drops the ASTs and type-checking information of the packages
+// that are done with, but for the ones other packages still need, so that
+// their memory can be reclaimed while going over many packages.  Released
+// packages are loaded again when next needed.
+//
 // # Reference Pruning and Recursive Checking
 //
 // In order to type-check using only the packages you care about, you can use a
 // TypeChecker.  TypeChecker will visit each top-level type declaration,
 // collect (optionally filtered) references, and type-check references
-// packages.
+// packages.  Its ExportData selects the packages to load from export data
+// instead.
+//
+// LoadGraph describes which packages were loaded so far, how much of each, and
+// which import or referencing type pulled each in, to diagnose slow loading
+// or errors from unexpected packages.
 //
 // # Errors
 //
@@ -29,6 +29,10 @@ type PositionedError struct {
 	error
 }
 
+func (e PositionedError) Unwrap() error {
+	return e.error
+}
+
 // Node is the intersection of go/ast.Node and go/types.Var.
 type Node interface {
 	Pos() token.Pos // position of first character belonging to the node
@@ -67,3 +71,40 @@ type ErrList []error
 func (l ErrList) Error() string {
 	return fmt.Sprintf("%v", []error(l))
 }
+
+// MarkerError represents some error in the usage of a marker, optionally
+// with a suggested fix.
+type MarkerError struct {
+	// Marker is the name of the marker.
+	Marker string
+	// SuggestedFix describes how to fix the error, if known.
+	SuggestedFix string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e MarkerError) Error() string {
+	return e.Err.Error()
+}
+
+func (e MarkerError) Unwrap() error {
+	return e.Err
+}
+
+// ErrFromMarker returns the given error, with additional information
+// attaching it to the given marker.  Like ErrFromNode, it will automatically
+// map over error lists, so it should be called before ErrFromNode.
+func ErrFromMarker(err error, marker string) error {
+	var asList ErrList
+	if isList := errors.As(err, &asList); isList {
+		resList := make(ErrList, len(asList))
+		for i, baseErr := range asList {
+			resList[i] = ErrFromMarker(baseErr, marker)
+		}
+		return resList
+	}
+	return MarkerError{
+		Marker: marker,
+		Err:    err,
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go/types"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/packages"
+)
+
+// needExportFiles loads the export files of the loaded packages, if they were
+// asked for and not loaded yet.
+func (l *loader) needExportFiles() error {
+	l.exportFilesOnce.Do(func() {
+		for _, load := range l.exportLoads {
+			if err := load(); err != nil {
+				l.exportFilesErr = err
+				return
+			}
+		}
+	})
+	return l.exportFilesErr
+}
+
+// loadExportFiles sets the export files of the given loaded packages and of
+// their dependencies, loading them again with the given config and patterns.
+// They're loaded without the ignore_autogenerated build tag, which would
+// leave the generated code of the dependencies (e.g. the deep-copy methods of
+// k8s.io/api) out, failing to compile them.
+func (l *loader) loadExportFiles(loadCfg *packages.Config, rawPkgs []*packages.Package, patterns ...string) error {
+	cfg := *loadCfg
+	cfg.Mode = packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedExportFile
+	cfg.BuildFlags = withoutBuildTag(cfg.BuildFlags, "ignore_autogenerated")
+
+	start := time.Now()
+	exportPkgs, err := packages.Load(&cfg, patterns...)
+	if err != nil {
+		return fmt.Errorf("load the export data of packages in root %q: %w", cfg.Dir, err)
+	}
+	exportFiles := make(map[string]string)
+	packages.Visit(exportPkgs, nil, func(pkg *packages.Package) {
+		exportFiles[pkg.ID] = pkg.ExportFile
+	})
+	slog.Log(context.Background(), LevelTrace, "loaded export files", "roots", patterns, "dir", cfg.Dir, "packages", len(exportFiles), "duration", time.Since(start))
+
+	packages.Visit(rawPkgs, nil, func(pkg *packages.Package) {
+		if pkg.ExportFile == "" {
+			pkg.ExportFile = exportFiles[pkg.ID]
+		}
+	})
+	return nil
+}
+
+// withoutBuildTag returns the given build flags, without the given tag in
+// their -tags flags.
+func withoutBuildTag(buildFlags []string, tag string) []string {
+	res := make([]string, 0, len(buildFlags))
+	for _, flag := range buildFlags {
+		name, tags, isTags := strings.Cut(flag, "=")
+		if !isTags || strings.TrimLeft(name, "-") != "tags" {
+			res = append(res, flag)
+			continue
+		}
+		tagList := strings.Split(tags, ",")
+		tagList = slices.DeleteFunc(tagList, func(t string) bool { return t == tag })
+		res = append(res, name+"="+strings.Join(tagList, ","))
+	}
+	return res
+}
+
+// NeedTypesFromExportData loads the types of the package (into Types) from
+// the export data the compiler wrote for it, rather than by parsing and
+// type-checking its source, which is much cheaper for large dependencies
+// (e.g. k8s.io/api).  It requires the packages to be loaded with their export
+// files (packages.NeedExportFile), and leaves Syntax and TypesInfo unset, so
+// it's only suitable for packages whose markers and docs aren't needed.
+//
+// The packages the package depends on are loaded from their export data
+// first, unless they were type-checked already, so that the types of a
+// package are shared by all the packages using them.
+func (p *Package) NeedTypesFromExportData() {
+	p.needTypesFromExportData(nil)
+}
+
+// needTypesFromExportData is NeedTypesFromExportData, reading the types of
+// the package and of its dependencies from the given cache, if any, when
+// they're there, and adding them to it otherwise.
+func (p *Package) needTypesFromExportData(cache *TypesCache) {
+	p.exportOnce.Do(func() {
+		p.Lock()
+		checked := p.Types != nil && p.Types.Complete()
+		p.Unlock()
+		if checked {
+			p.loader.shareTypes(p.Types)
+			return
+		}
+		if p.PkgPath == "unsafe" {
+			p.Lock()
+			p.Types = types.Unsafe
+			p.Unlock()
+			return
+		}
+
+		for _, importedPkg := range p.Imports() {
+			if importedPkg.PkgPath != "unsafe" && !importedPkg.hasExportData(cache) {
+				// e.g. failing to build (like runtime/cgo without a C
+				// compiler), the export data of the package itself covers
+				// whatever it uses of it
+				continue
+			}
+			importedPkg.needTypesFromExportData(cache)
+		}
+
+		start := time.Now()
+		typesPkg, fromCache, err := p.loader.readTypes(p, cache)
+		if err != nil {
+			p.AddError(err)
+			return
+		}
+		p.Lock()
+		p.Types = typesPkg
+		p.Fset = p.loader.cfg.Fset
+		p.Unlock()
+		slog.Log(context.Background(), LevelTrace, "loaded package from export data", "package", p.PkgPath, "cached", fromCache, "duration", time.Since(start))
+	})
+}
+
+// hasExportData checks whether the types of the package can be read from the
+// given cache or from export data.
+func (p *Package) hasExportData(cache *TypesCache) bool {
+	if cache.has(p) {
+		return true
+	}
+	return p.loader.needExportFiles() == nil && p.ExportFile != ""
+}
+
+// readTypes reads the types of the given package from the given cache, if
+// there, or from its export data otherwise, adding them to the cache.
+func (l *loader) readTypes(pkg *Package, cache *TypesCache) (typesPkg *types.Package, fromCache bool, err error) {
+	if typesPkg, ok := cache.read(pkg); ok {
+		return typesPkg, true, nil
+	}
+	if err := l.needExportFiles(); err != nil {
+		return nil, false, err
+	}
+	if typesPkg, err = l.readExportData(pkg); err != nil {
+		return nil, false, err
+	}
+	cache.write(pkg, typesPkg)
+	return typesPkg, false, nil
+}
+
+// shareTypes makes the given type-checked package the one the packages read
+// from export data refer to.
+func (l *loader) shareTypes(typesPkg *types.Package) {
+	l.exportMu.Lock()
+	defer l.exportMu.Unlock()
+	if l.exportedTypes == nil {
+		l.exportedTypes = make(map[string]*types.Package)
+	}
+	if _, exists := l.exportedTypes[typesPkg.Path()]; !exists {
+		l.exportedTypes[typesPkg.Path()] = typesPkg
+	}
+}
+
+// sharesTypes checks whether the given type-checked package is shared with
+// the packages read from export data.
+func (l *loader) sharesTypes(typesPkg *types.Package) bool {
+	l.exportMu.Lock()
+	defer l.exportMu.Unlock()
+	return typesPkg != nil && l.exportedTypes[typesPkg.Path()] == typesPkg
+}
+
+// readExportData reads the types of the given package from its export data.
+func (l *loader) readExportData(pkg *Package) (*types.Package, error) {
+	if pkg.ExportFile == "" {
+		return nil, fmt.Errorf("no export data for package %q (packages must be loaded with packages.NeedExportFile)", pkg.PkgPath)
+	}
+	file, err := os.Open(pkg.ExportFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the export data of package %q: %w", pkg.PkgPath, err)
+	}
+	defer file.Close()
+	reader, err := gcexportdata.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the export data of package %q: %w", pkg.PkgPath, err)
+	}
+	return l.decodeExportData(pkg, reader)
+}
+
+// decodeExportData decodes the types of the given package from the given
+// export data, sharing the packages it refers to with the other reads.
+func (l *loader) decodeExportData(pkg *Package, reader io.Reader) (*types.Package, error) {
+	l.exportMu.Lock()
+	defer l.exportMu.Unlock()
+	if l.exportedTypes == nil {
+		l.exportedTypes = make(map[string]*types.Package)
+	}
+	if typesPkg := l.exportedTypes[pkg.PkgPath]; typesPkg != nil && typesPkg.Complete() {
+		return typesPkg, nil
+	}
+	typesPkg, err := gcexportdata.Read(reader, l.cfg.Fset, l.exportedTypes, pkg.PkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the export data of package %q: %w", pkg.PkgPath, err)
+	}
+	return typesPkg, nil
+}
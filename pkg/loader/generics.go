@@ -0,0 +1,93 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// These helpers are shared by the generators to handle generic types
+// consistently: generic declarations (e.g. `type Wrapper[T any] struct{...}`)
+// and their instantiations (e.g. `Wrapper[string]`) in type expressions.
+
+// Instance is an instantiation of a generic type in a type expression,
+// e.g. `Wrapper[string]` or `pkg.Pair[string, Value]`.
+type Instance struct {
+	// Named is the instantiated type.
+	Named *types.Named
+	// Generic is the expression of the generic type, e.g. `Wrapper` or
+	// `pkg.Pair`.
+	Generic ast.Expr
+	// TypeArgs are the expressions of the type arguments, in order.
+	TypeArgs []ast.Expr
+}
+
+// InstanceOf returns the instantiation of a generic type the given type
+// expression denotes, if it's one (i.e. an index expression whose type is
+// an instantiated named type).
+func InstanceOf(info *types.Info, expr ast.Expr) (Instance, bool) {
+	var inst Instance
+	switch expr := expr.(type) {
+	case *ast.IndexExpr:
+		inst.Generic, inst.TypeArgs = expr.X, []ast.Expr{expr.Index}
+	case *ast.IndexListExpr:
+		inst.Generic, inst.TypeArgs = expr.X, expr.Indices
+	default:
+		return Instance{}, false
+	}
+	typ := info.TypeOf(expr)
+	if alias, isAlias := typ.(*types.Alias); isAlias {
+		typ = types.Unalias(alias)
+	}
+	named, isNamed := typ.(*types.Named)
+	if !isNamed || named.TypeArgs().Len() == 0 {
+		return Instance{}, false
+	}
+	inst.Named = named
+	return inst, true
+}
+
+// IsGeneric checks whether the given type is a generic type declaration (as
+// opposed to an instantiation of one, or a non-generic type).
+func IsGeneric(typ types.Type) bool {
+	named, isNamed := typ.(*types.Named)
+	return isNamed && named.TypeParams().Len() > 0 && named.TypeArgs().Len() == 0
+}
+
+// IsInstance checks whether the given type is an instantiation of a generic
+// type.
+func IsInstance(typ types.Type) bool {
+	named, isNamed := typ.(*types.Named)
+	return isNamed && named.TypeArgs().Len() > 0
+}
+
+// TypeParamList returns the type parameters of the given generic type as they
+// are written in a receiver or an instantiation by its own parameters, e.g.
+// "[K, V]" for `Pair[K comparable, V any]`, or "" if it isn't generic.
+func TypeParamList(named *types.Named) string {
+	params := named.TypeParams()
+	if params.Len() == 0 {
+		return ""
+	}
+	names := make([]string, params.Len())
+	for ind := range params.Len() {
+		names[ind] = params.At(ind).Obj().Name()
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"maps"
+	"slices"
+)
+
+// typeRef is a reference to a type, by the package it's in and its name.
+type typeRef struct {
+	pkg      *Package
+	typeName string
+}
+
+// String returns the type reference as <package path>.<name>, or just the
+// package path if the package is dot-imported rather than referenced by a
+// particular type.
+func (r typeRef) String() string {
+	if r.typeName == "" {
+		return r.pkg.PkgPath
+	}
+	return r.pkg.PkgPath + "." + r.typeName
+}
+
+// How the types of a package were loaded, in PackageLoad.Types.
+const (
+	// TypesFromSource marks packages that were parsed and type-checked.
+	TypesFromSource = "source"
+	// TypesFromExportData marks packages whose types were read from the
+	// export data of the compiler (see Package.NeedTypesFromExportData).
+	TypesFromExportData = "exportData"
+)
+
+// PackageLoad describes how much of a package was loaded, and why, to
+// diagnose why loading is slow or why the errors of an unexpected package
+// are reported.
+type PackageLoad struct {
+	// Path is the path of the package.
+	Path string `json:"path"`
+	// Root is true for root packages.
+	Root bool `json:"root,omitempty"`
+	// ImportedBy is the path of the package importing this one on a shortest
+	// chain of imports from a root (empty for roots).
+	ImportedBy string `json:"importedBy,omitempty"`
+	// Parsed is true if the syntax of the package was loaded.
+	Parsed bool `json:"parsed,omitempty"`
+	// Types is how the types of the package were loaded (TypesFromSource or
+	// TypesFromExportData), if at all.
+	Types string `json:"types,omitempty"`
+	// ReferencedBy is the type (as <package path>.<name>) whose references
+	// made a TypeChecker load the types of the package, if any.
+	ReferencedBy string `json:"referencedBy,omitempty"`
+	// Errors is the number of errors of the package.
+	Errors int `json:"errors,omitempty"`
+}
+
+// LoadGraph describes how the given roots and the packages they (transitively)
+// import were loaded so far, roots first, then breadth-first along imports
+// (in order of import path within each package).
+func LoadGraph(roots []*Package) []PackageLoad {
+	var graph []PackageLoad
+	seen := make(map[*Package]struct{}, len(roots))
+	queue := make([]*Package, 0, len(roots))
+	importedBy := make(map[*Package]*Package)
+	for _, root := range roots {
+		if _, ok := seen[root]; ok {
+			continue
+		}
+		seen[root] = struct{}{}
+		queue = append(queue, root)
+	}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		load := pkg.load()
+		if importer, ok := importedBy[pkg]; ok {
+			load.ImportedBy = importer.PkgPath
+		} else {
+			load.Root = true
+		}
+		graph = append(graph, load)
+
+		imports := pkg.Imports()
+		for _, path := range slices.Sorted(maps.Keys(imports)) {
+			importedPkg := imports[path]
+			if _, ok := seen[importedPkg]; ok {
+				continue
+			}
+			seen[importedPkg] = struct{}{}
+			importedBy[importedPkg] = pkg
+			queue = append(queue, importedPkg)
+		}
+	}
+	return graph
+}
+
+// load describes how much of the package was loaded, apart from where it is
+// in the graph.
+func (p *Package) load() PackageLoad {
+	p.Lock()
+	defer p.Unlock()
+
+	// released packages were loaded all the same
+	load := PackageLoad{
+		Path:   p.PkgPath,
+		Parsed: p.Syntax != nil,
+	}
+	switch {
+	case p.TypesInfo != nil || p.releasedTypes:
+		load.Types = TypesFromSource
+	case p.Types != nil && p.Types.Complete():
+		load.Types = TypesFromExportData
+	}
+	if ref := p.referencedBy.Load(); ref != nil {
+		load.ReferencedBy = ref.String()
+	}
+
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+	load.Parsed = load.Parsed || p.released
+	load.Errors = len(p.Errors)
+	return load
+}
@@ -17,21 +17,38 @@ limitations under the License.
 package loader
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/scanner"
 	"go/token"
 	"go/types"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/tools/go/packages"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// LevelTrace is the level of the most detailed logs of loading and generating
+// (e.g. of each type-checked package and written file), below slog.LevelDebug.
+const LevelTrace = slog.LevelDebug - 4
+
 // Much of this is strongly inspired by the contents of go/packages,
 // except that it allows for lazy loading of syntax and type-checking
 // information to speed up cases where full traversal isn't needed.
@@ -40,8 +57,15 @@ import (
 // in the given package graph, starting at the given root
 // packages and traversing through all imports.  It will skip
 // any errors of the kinds specified in filterKinds.  It will
-// return true if any errors were printed.
+// return true if any errors were printed.  Warnings are printed
+// too, but aren't counted.
 func PrintErrors(pkgs []*Package, filterKinds ...packages.ErrorKind) bool {
+	return FprintErrors(os.Stderr, pkgs, filterKinds...)
+}
+
+// FprintErrors is like PrintErrors, printing to the given writer instead of
+// standard-error.
+func FprintErrors(out io.Writer, pkgs []*Package, filterKinds ...packages.ErrorKind) bool {
 	pkgsRaw := make([]*packages.Package, len(pkgs))
 	for i, pkg := range pkgs {
 		pkgsRaw[i] = pkg.Package
@@ -50,6 +74,7 @@ func PrintErrors(pkgs []*Package, filterKinds ...packages.ErrorKind) bool {
 	for _, errKind := range filterKinds {
 		toSkip[errKind] = struct{}{}
 	}
+	wrapped := wrappedPackages(pkgs)
 	hadErrors := false
 	packages.Visit(pkgsRaw, nil, func(pkgRaw *packages.Package) {
 		for _, err := range pkgRaw.Errors {
@@ -57,7 +82,14 @@ func PrintErrors(pkgs []*Package, filterKinds ...packages.ErrorKind) bool {
 				continue
 			}
 			hadErrors = true
-			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(out, err)
+		}
+		if pkg := wrapped[pkgRaw]; pkg != nil {
+			for _, diag := range pkg.Diagnostics() {
+				if diag.Severity == SeverityWarning {
+					fmt.Fprintf(out, "warning: %s\n", diag)
+				}
+			}
 		}
 	})
 	return hadErrors
@@ -76,14 +108,47 @@ type Package struct {
 
 	loader *loader
 	sync.Mutex
+
+	// importsOnce, syntaxOnce, typesOnce and exportOnce ensure that
+	// imports, syntax, type-checking information and types from export data
+	// are only loaded once, even when requested concurrently (e.g. by
+	// generators running in parallel).
+	importsOnce sync.Once
+	syntaxOnce  sync.Once
+	typesOnce   sync.Once
+	exportOnce  sync.Once
+	// errorsMu guards Errors and diagnostics.
+	errorsMu sync.Mutex
+	// diagnostics are the diagnostics of the errors and warnings added to
+	// the package.
+	diagnostics []Diagnostic
+	// loadErrors is the number of errors reported when loading the package,
+	// which come first in Errors.
+	loadErrors int
+	// referencedBy is the first type found referencing the package when
+	// type-checking only referenced packages (see TypeChecker).
+	referencedBy atomic.Pointer[typeRef]
+	// cacheKey is the key of the types of the package in a TypesCache,
+	// computed once (see typesKey).
+	cacheKey     string
+	cacheKeyErr  error
+	cacheKeyOnce sync.Once
+	// released is set once the syntax of the package was released (see
+	// ReleaseSyntax), after which the errors found again when loading it
+	// again are only reported once.  It's guarded by errorsMu.
+	released bool
+	// releasedTypes is set once the type-checking information of the
+	// package was released, so that it's still described as type-checked
+	// from source (see LoadGraph).  It's guarded by the package's lock.
+	releasedTypes bool
 }
 
 // Imports returns the imports for the given package, indexed by
 // package path (*not* name in any particular file).
 func (p *Package) Imports() map[string]*Package {
-	if p.imports == nil {
+	p.importsOnce.Do(func() {
 		p.imports = p.loader.packagesFor(p.Package.Imports)
-	}
+	})
 
 	return p.imports
 }
@@ -91,16 +156,45 @@ func (p *Package) Imports() map[string]*Package {
 // NeedTypesInfo indicates that type-checking information is needed for this package.
 // Actual type-checking information can be accessed via the Types and TypesInfo fields.
 func (p *Package) NeedTypesInfo() {
-	if p.TypesInfo != nil {
-		return
-	}
-	p.NeedSyntax()
-	p.loader.typeCheck(p)
+	p.typesOnce.Do(func() {
+		if p.TypesInfo != nil {
+			return
+		}
+		start := time.Now()
+		p.NeedSyntax()
+		p.loader.typeCheck(p)
+		slog.Log(context.Background(), LevelTrace, "type-checked package", "package", p.PkgPath, "duration", time.Since(start))
+	})
 }
 
 // NeedSyntax indicates that a parsed AST is needed for this package.
 // Actual ASTs can be accessed via the Syntax field.
 func (p *Package) NeedSyntax() {
+	p.syntaxOnce.Do(p.parseSyntax)
+}
+
+// AddFile adds a file of the package other than its Go files (e.g. a
+// configuration file next to them) to the file set of the loaded packages,
+// so that errors can be reported at positions in it.
+func (p *Package) AddFile(filename string, src []byte) *token.File {
+	file := p.loader.cfg.Fset.AddFile(filename, -1, len(src))
+	file.SetLinesForContent(src)
+	return file
+}
+
+// ReadFile reads the given file of the package (or any other file, e.g. next
+// to its Go files), from the overlays the packages were loaded with (see
+// packages.Config.Overlay), if there, or from disk otherwise, so that files
+// that were never saved are read as the go command saw them.
+func (p *Package) ReadFile(filename string) ([]byte, error) {
+	if src, ok := p.loader.cfg.Overlay[filename]; ok {
+		return src, nil
+	}
+	return os.ReadFile(filename)
+}
+
+// parseSyntax parses the package's files, unless its syntax is already loaded.
+func (p *Package) parseSyntax() {
 	if p.Syntax != nil {
 		return
 	}
@@ -110,7 +204,7 @@ func (p *Package) NeedSyntax() {
 	for i, filename := range p.CompiledGoFiles {
 		go func(i int, filename string) {
 			defer wg.Done()
-			src, err := os.ReadFile(filename)
+			src, err := p.ReadFile(filename)
 			if err != nil {
 				p.AddError(err)
 				return
@@ -133,50 +227,86 @@ func (p *Package) NeedSyntax() {
 
 // AddError adds an error to the errors associated with the given package.
 func (p *Package) AddError(err error) {
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+	p.report(SeverityError, err)
+}
+
+// AddWarning adds a warning to the diagnostics of the given package.  Unlike
+// errors, warnings don't make the generation fail, and are printed by
+// PrintErrors without being counted.
+func (p *Package) AddWarning(err error) {
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+	p.report(SeverityWarning, err)
+}
+
+// report adds an error or a warning to the given package, with errorsMu held.
+func (p *Package) report(severity Severity, err error) {
 	switch typedErr := err.(type) {
 	case *os.PathError:
 		// file-reading errors
-		p.Errors = append(p.Errors, packages.Error{
+		p.add(severity, packages.Error{
 			Pos:  typedErr.Path + ":1",
 			Msg:  typedErr.Err.Error(),
 			Kind: packages.ParseError,
-		})
+		}, token.Position{Filename: typedErr.Path, Line: 1}, err)
 	case scanner.ErrorList:
 		// parsing/scanning errors
 		for _, subErr := range typedErr {
-			p.Errors = append(p.Errors, packages.Error{
+			p.add(severity, packages.Error{
 				Pos:  subErr.Pos.String(),
 				Msg:  subErr.Msg,
 				Kind: packages.ParseError,
-			})
+			}, subErr.Pos, subErr)
 		}
 	case types.Error:
 		// type-checking errors
-		p.Errors = append(p.Errors, packages.Error{
-			Pos:  typedErr.Fset.Position(typedErr.Pos).String(),
+		pos := typedErr.Fset.Position(typedErr.Pos)
+		p.add(severity, packages.Error{
+			Pos:  pos.String(),
 			Msg:  typedErr.Msg,
 			Kind: packages.TypeError,
-		})
+		}, pos, err)
 	case ErrList:
 		for _, subErr := range typedErr {
-			p.AddError(subErr)
+			p.report(severity, subErr)
 		}
 	case PositionedError:
-		p.Errors = append(p.Errors, packages.Error{
-			Pos:  p.loader.cfg.Fset.Position(typedErr.Pos).String(),
+		pos := p.loader.cfg.Fset.Position(typedErr.Pos)
+		p.add(severity, packages.Error{
+			Pos:  pos.String(),
 			Msg:  typedErr.Error(),
 			Kind: packages.UnknownError,
-		})
+		}, pos, err)
 	default:
 		// should only happen for external errors, like ref checking
-		p.Errors = append(p.Errors, packages.Error{
+		p.add(severity, packages.Error{
 			Pos:  p.ID + ":-",
 			Msg:  err.Error(),
 			Kind: packages.UnknownError,
-		})
+		}, token.Position{}, err)
 	}
 }
 
+// add adds the given error or warning to the given package, along with its
+// diagnostic.  Only errors are added to the package's Errors.
+func (p *Package) add(severity Severity, pkgErr packages.Error, pos token.Position, err error) {
+	diag := ErrorDiagnostic(err)
+	diag.Severity = severity
+	diag.Message = pkgErr.Msg
+	diag.Package = p.PkgPath
+	diag.File, diag.Line, diag.Column = pos.Filename, pos.Line, pos.Column
+	diag.kind = pkgErr.Kind
+	if p.released && p.reported(diag) {
+		return
+	}
+	if severity == SeverityError {
+		p.Errors = append(p.Errors, pkgErr)
+	}
+	p.diagnostics = append(p.diagnostics, diag)
+}
+
 // loader loads packages and their imports.  Loaded packages will have
 // type size, imports, and exports file information populated.  Additional
 // information, like ASTs and type-checking information, can be accessed
@@ -193,6 +323,19 @@ type loader struct {
 	// the same underlying packages.Package.
 	packages   map[*packages.Package]*Package
 	packagesMu sync.Mutex
+
+	// exportedTypes are the packages read from export data (and the
+	// type-checked packages they refer to), by path, shared by all the
+	// reads so that each package has a single types.Package.
+	exportedTypes map[string]*types.Package
+	exportMu      sync.Mutex
+
+	// exportLoads load the export files of the loaded packages, which is
+	// only done once export data is first needed (see needExportFiles),
+	// since it means compiling the packages.
+	exportLoads     []func() error
+	exportFilesOnce sync.Once
+	exportFilesErr  error
 }
 
 // packageFor returns a wrapped Package for the given packages.Package,
@@ -201,8 +344,9 @@ type loader struct {
 func (l *loader) packageFor(pkgRaw *packages.Package) *Package {
 	if l.packages[pkgRaw] == nil {
 		l.packages[pkgRaw] = &Package{
-			Package: pkgRaw,
-			loader:  l,
+			Package:    pkgRaw,
+			loader:     l,
+			loadErrors: len(pkgRaw.Errors),
 		}
 	}
 	return l.packages[pkgRaw]
@@ -328,6 +472,46 @@ func LoadRoots(roots ...string) ([]*Package, error) {
 	return LoadRootsWithConfig(&packages.Config{}, roots...)
 }
 
+// FromPackages returns the given packages, already loaded with the given
+// config by golang.org/x/tools/go/packages, as root Packages, so that tools
+// holding such packages (e.g. in a larger analysis pipeline) don't need to
+// load them again.  They must have been loaded with at least
+// packages.NeedName, NeedFiles, NeedCompiledGoFiles, NeedImports and
+// NeedTypesSizes, and NeedDeps for their imports to be available.
+//
+// The syntax and type-checking information the packages (and their imports)
+// were loaded with, if any, are used as is, rather than loaded again.  To
+// share a single set of types, packages should be loaded with either none of
+// them or all of them (as with packages.LoadAllSyntax), since packages with
+// types but without type-checking information (i.e. from export data) are
+// type-checked again from source when needed.
+func FromPackages(cfg *packages.Config, pkgs ...*packages.Package) []*Package {
+	l := &loader{
+		cfg:      cfg,
+		packages: make(map[*packages.Package]*Package),
+	}
+	if l.cfg.Fset == nil {
+		// parse any file not parsed yet along with the ones that were
+		for _, pkg := range pkgs {
+			if pkg.Fset != nil {
+				l.cfg.Fset = pkg.Fset
+				break
+			}
+		}
+	}
+	if l.cfg.Fset == nil {
+		l.cfg.Fset = token.NewFileSet()
+	}
+
+	for _, pkg := range pkgs {
+		l.Roots = append(l.Roots, l.packageFor(pkg))
+	}
+	for _, root := range l.Roots {
+		visitImports(l.Roots, root, nil)
+	}
+	return l.Roots
+}
+
 // LoadRootsWithConfig functions like LoadRoots, except that it allows passing
 // a custom loading config.  The config will be modified to suit the needs of
 // the loader.
@@ -368,12 +552,20 @@ func LoadRoots(roots ...string) ([]*Package, error) {
 //
 //  5. Load the filesystem path roots and return the load packages for the
 //     package/module roots AND the filesystem path roots.
+//
+// Filesystem path roots in a Go workspace (a go.work file, or the GOWORK
+// environment variable) are instead loaded all at once from the directory of
+// the workspace, so that the packages of the modules of the workspace
+// resolve together: a package of one module imported by another is the same
+// Package, whether it's a root or not.  Their nested Go modules aren't
+// looked for, since "..." already spans the modules of the workspace, and
+// the other modules can't be loaded from within it.
 func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, error) {
 	l := &loader{
 		cfg:      cfg,
 		packages: make(map[*packages.Package]*Package),
 	}
-	l.cfg.Mode |= packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedTypesSizes
+	l.cfg.Mode |= packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedTypesSizes | packages.NeedModule
 	if l.cfg.Fset == nil {
 		l.cfg.Fset = token.NewFileSet()
 	}
@@ -386,6 +578,11 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 	//   flags with `-tags=""` to disable use of the default `ignore_autogenerated` tag.
 	l.cfg.BuildFlags = append([]string{"-tags=ignore_autogenerated"}, l.cfg.BuildFlags...)
 
+	// the export files of the packages are loaded on their own, when first
+	// needed (see needExportFiles)
+	exportFiles := l.cfg.Mode&packages.NeedExportFile != 0
+	l.cfg.Mode &^= packages.NeedExportFile
+
 	// Visit the import graphs of the loaded, root packages. If an imported
 	// package refers to another loaded, root package, then replace the
 	// instance of the imported package with a reference to the loaded, root
@@ -410,7 +607,9 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 	// otherwise the package is only returned if the result of
 	// validatePkgFn(pkg.ID) is truthy
 	loadPackages := func(roots ...string) ([]*Package, error) {
+		start := time.Now()
 		rawPkgs, err := packages.Load(l.cfg, roots...)
+		slog.Log(context.Background(), LevelTrace, "loaded packages", "roots", roots, "dir", l.cfg.Dir, "packages", len(rawPkgs), "duration", time.Since(start))
 		if err != nil {
 			loadRoot := l.cfg.Dir
 			if l.cfg.Dir == "" {
@@ -418,6 +617,12 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 			}
 			return nil, fmt.Errorf("load packages in root %q: %w", loadRoot, err)
 		}
+		if exportFiles {
+			cfg := *l.cfg
+			l.exportLoads = append(l.exportLoads, func() error {
+				return l.loadExportFiles(&cfg, rawPkgs, roots...)
+			})
+		}
 		var pkgs []*Package
 		for _, rp := range rawPkgs {
 			p := l.packageFor(rp)
@@ -491,20 +696,33 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 	// a loop below
 	cfgDir := cfg.Dir
 
-	// addNestedGoModulesToRoots is given to filepath.WalkDir and adds the
-	// directory part of p to the list of filesystem path roots IFF p is the
-	// path to a file named "go.mod"
-	addNestedGoModulesToRoots := func(
-		p string,
-		d os.DirEntry,
-		e error) error {
-		if e != nil {
-			return e
-		}
-		if !d.IsDir() && filepath.Base(p) == "go.mod" {
-			fspRoots = append(fspRoots, filepath.Join(filepath.Dir(p), "..."))
+	// workspaceRoots are the filesystem path roots in Go workspaces, by
+	// go.work file
+	workspaceRoots := make(map[string][]string)
+
+	// addNestedGoModulesToRoots returns the function given to
+	// filepath.WalkDir for the given root, which adds the directory part of
+	// p to the list of filesystem path roots IFF p is the path to a file
+	// named "go.mod".  Like go list, it skips the vendor directories below
+	// the root, since the vendored copies of modules (which may come with
+	// their go.mod) are dependencies rather than roots, and walking them is
+	// slow.
+	addNestedGoModulesToRoots := func(root string) fs.WalkDirFunc {
+		return func(
+			p string,
+			d os.DirEntry,
+			e error) error {
+			if e != nil {
+				return e
+			}
+			if d.IsDir() && d.Name() == "vendor" && p != root {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && filepath.Base(p) == "go.mod" {
+				fspRoots = append(fspRoots, filepath.Join(filepath.Dir(p), "..."))
+			}
+			return nil
 		}
-		return nil
 	}
 
 	// in the first pass over the filesystem path roots we:
@@ -546,6 +764,21 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 
 		b, d := filepath.Base(r), filepath.Dir(r)
 
+		// roots in a workspace are loaded together, later
+		rootDir := d
+		if b != "..." {
+			rootDir = r
+		}
+		workFile, err := workspaceFile(l.cfg, rootDir)
+		if err != nil {
+			return nil, err
+		}
+		if workFile != "" {
+			workspaceRoots[workFile] = append(workspaceRoots[workFile], r)
+			fspRoots[i] = ""
+			continue
+		}
+
 		// if the base element is "..." then it means nested traversal is
 		// activated. this can be passed directly to the loader. however, if
 		// specified we also want to traverse the path manually to determine if
@@ -554,7 +787,7 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 		if b == "..." {
 			if err := filepath.WalkDir(
 				d,
-				addNestedGoModulesToRoots); err != nil {
+				addNestedGoModulesToRoots(d)); err != nil {
 				return nil, err
 			}
 		}
@@ -572,6 +805,10 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 	//
 	//    4. execute the loader with the value from step three
 	for _, r := range fspRoots {
+		if r == "" {
+			// in a workspace
+			continue
+		}
 		b, d := filepath.Base(r), filepath.Dir(r)
 
 		// we want the base part of the path to be either "..." or ".", except
@@ -603,9 +840,128 @@ func LoadRootsWithConfig(cfg *packages.Config, roots ...string) ([]*Package, err
 		l.Roots = append(l.Roots, pkgs...)
 	}
 
+	// finally, load the roots of each workspace all at once
+	for _, workFile := range slices.Sorted(maps.Keys(workspaceRoots)) {
+		l.cfg.Dir = filepath.Dir(workFile)
+		patterns, err := workspacePatterns(l.cfg, workspaceRoots[workFile])
+		if err != nil {
+			return nil, err
+		}
+		if len(patterns) == 0 {
+			// no modules of the workspace there
+			continue
+		}
+		pkgs, err := loadPackages(patterns...)
+		if err != nil {
+			return nil, err
+		}
+		l.Roots = append(l.Roots, pkgs...)
+	}
+
 	return l.Roots, nil
 }
 
+// workspaceFile returns the path of the go.work file of the Go workspace the
+// given directory is in, as the go command finds it with the given config, or
+// an empty string if it isn't in a workspace.
+func workspaceFile(cfg *packages.Config, dir string) (string, error) {
+	workFile, err := goCommand(cfg, dir, "env", "GOWORK")
+	if err != nil || workFile == "off" {
+		return "", err
+	}
+	return workFile, nil
+}
+
+// workspacePatterns returns the patterns to load the given absolute
+// filesystem path roots with, from the directory of their workspace in the
+// given config.  Since the go command only matches "..." patterns within the
+// modules of the workspace, the ones spanning several modules (e.g. the
+// directory of the workspace itself) are split into a pattern per module.
+func workspacePatterns(cfg *packages.Config, roots []string) ([]string, error) {
+	out, err := goCommand(cfg, cfg.Dir, "list", "-m", "-f", "{{.Dir}}")
+	if err != nil {
+		return nil, err
+	}
+	modDirs := strings.Split(out, "\n")
+	within := func(dir, parent string) bool {
+		return dir == parent || strings.HasPrefix(dir, parent+string(filepath.Separator))
+	}
+
+	var patterns []string
+	for _, r := range roots {
+		d := filepath.Dir(r)
+		if filepath.Base(r) != "..." || slices.ContainsFunc(modDirs, func(modDir string) bool { return within(d, modDir) }) {
+			patterns = append(patterns, r)
+			continue
+		}
+		for _, modDir := range modDirs {
+			if within(modDir, d) {
+				patterns = append(patterns, filepath.Join(modDir, "..."))
+			}
+		}
+	}
+	return patterns, nil
+}
+
+// ModuleVersions returns the versions of the given modules the main module
+// of the given package depends on, by path, as selected by the go command
+// (including replacements, and the modules of the workspace, if any).
+// Modules it doesn't depend on, or replaced by directories, are left out, as
+// are all of them for packages that aren't part of a module.  The go.mod and
+// go.sum files of the module are left untouched, so modules whose go.mod
+// isn't in go.sum are left out as well.
+func ModuleVersions(pkg *Package, paths ...string) (map[string]string, error) {
+	if pkg.Module == nil || pkg.Module.Dir == "" || len(paths) == 0 {
+		return nil, nil
+	}
+	out, err := goCommand(pkg.loader.cfg, pkg.Module.Dir, append([]string{"list", "-m", "-mod=readonly", "-e", "-json"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string, len(paths))
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		var mod packages.Module
+		if err := dec.Decode(&mod); err != nil {
+			return nil, fmt.Errorf("unable to parse the modules of %s: %w", pkg.Module.Path, err)
+		}
+		if mod.Error != nil {
+			continue
+		}
+		version := mod.Version
+		if mod.Replace != nil {
+			version = mod.Replace.Version
+		}
+		if version != "" {
+			versions[mod.Path] = version
+		}
+	}
+	return versions, nil
+}
+
+// goCommand runs the go command with the given arguments in the given
+// directory and the environment of the given config, returning its trimmed
+// output.
+func goCommand(cfg *packages.Config, dir string, args ...string) (string, error) {
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = cfg.Env
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("go %s in %q: %w: %s", strings.Join(args, " "), dir, err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("go %s in %q: %w", strings.Join(args, " "), dir, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // visitImports walks a dependency graph, replacing imported package
 // references with those from the rootPkgs list. This ensures the
 // kubebuilder marker generation is handled correctly. For more info,
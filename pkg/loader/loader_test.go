@@ -17,10 +17,15 @@ limitations under the License.
 package loader_test
 
 import (
+	"errors"
+	"go/types"
 	"os"
+	"path/filepath"
+	"slices"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"golang.org/x/tools/go/packages"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 )
 
@@ -119,6 +124,34 @@ var _ = Describe("Loader parsing root module", func() {
 		})
 	})
 
+	Context("resolving the versions of dependencies", func() {
+		It("should return the versions the main module of the package depends on", func() {
+			pkgs, err := loader.LoadRoots("./")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(1))
+			Expect(pkgs[0].Module).NotTo(BeNil())
+			Expect(pkgs[0].Module.Path).To(Equal(rootPkg))
+
+			versions, err := loader.ModuleVersions(pkgs[0], "k8s.io/apimachinery", "example.com/unknown")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(HaveLen(1))
+			Expect(versions).To(HaveKeyWithValue("k8s.io/apimachinery", MatchRegexp(`^v0\.\d+\.\d+`)))
+		})
+
+		It("should leave out the modules replaced by directories", func() {
+			cwd, err := os.Getwd()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.Chdir("./testmod")).To(Succeed())
+			DeferCleanup(func() { Expect(os.Chdir(cwd)).To(Succeed()) })
+
+			pkgs, err := loader.LoadRoots("./")
+			Expect(err).ToNot(HaveOccurred())
+			versions, err := loader.ModuleVersions(pkgs[0], testmodPkg+"/submod1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(BeEmpty())
+		})
+	})
+
 	Context("with roots=[../../pkg/loader]", func() {
 		It("should load one package", func() {
 			pkgs, err := loader.LoadRoots("../../pkg/loader")
@@ -187,4 +220,203 @@ var _ = Describe("Loader parsing root module", func() {
 			assertPkgExists(testmodPkg+"/submod1/subdir1", pkgs)
 		})
 	})
+
+	Context("with overlays", func() {
+		It("should load the overlaid and added files instead of the ones on disk", func() {
+			dir, err := filepath.Abs("./testmod/subdir1/subdir2")
+			Expect(err).ToNot(HaveOccurred())
+			cfg := &packages.Config{Overlay: map[string][]byte{
+				filepath.Join(dir, "dummy.go"): []byte("package dummy\n\ntype Overlaid struct{}\n"),
+				filepath.Join(dir, "added.go"): []byte("package dummy\n\ntype Added Overlaid\n"),
+			}}
+			pkgs, err := loader.LoadRootsWithConfig(cfg, "./testmod/subdir1/subdir2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(1))
+			pkg := pkgs[0]
+			Expect(pkg.CompiledGoFiles).To(ContainElement(filepath.Join(dir, "added.go")))
+
+			pkg.NeedTypesInfo()
+			Expect(pkg.Errors).To(BeEmpty())
+			Expect(pkg.Types.Scope().Lookup("Overlaid")).NotTo(BeNil())
+			Expect(pkg.Types.Scope().Lookup("Added")).NotTo(BeNil())
+		})
+	})
+
+	Context("with roots in a Go workspace", func() {
+		const testworkPkg = loaderPkg + "/testwork"
+
+		// the go command refuses -mod=mod in workspace mode
+		var cfg *packages.Config
+		BeforeEach(func() {
+			cfg = &packages.Config{Env: append(os.Environ(), "GOFLAGS=")}
+		})
+
+		It("should load the packages of all the modules of the workspace together", func() {
+			pkgs, err := loader.LoadRootsWithConfig(cfg, "./testwork/...")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(2))
+			assertPkgExists(testworkPkg+"/api/v1", pkgs)
+			assertPkgExists(testworkPkg+"/controllers", pkgs)
+
+			By("resolving the packages of one module imported by another to the same package")
+			api := pkgs[indexOfPackage(testworkPkg+"/api/v1", pkgs)]
+			controllers := pkgs[indexOfPackage(testworkPkg+"/controllers", pkgs)]
+			Expect(controllers.Imports()).To(HaveKeyWithValue(testworkPkg+"/api/v1", BeIdenticalTo(api)))
+		})
+
+		It("should load the types of the selected dependencies from export data", func() {
+			cfg.Mode = packages.NeedExportFile
+			pkgs, err := loader.LoadRootsWithConfig(cfg, "./testwork/controllers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(1))
+			controllers := pkgs[0]
+			api := controllers.Imports()[testworkPkg+"/api/v1"]
+
+			checker := &loader.TypeChecker{ExportData: func(pkg *loader.Package) bool { return pkg != controllers }}
+			checker.Check(controllers)
+			Expect(controllers.Errors).To(BeEmpty())
+			Expect(api.Errors).To(BeEmpty())
+
+			By("checking that the dependency wasn't parsed, but has its types")
+			Expect(api.Syntax).To(BeNil())
+			Expect(api.TypesInfo).To(BeNil())
+			Expect(api.Types.Complete()).To(BeTrue())
+			Expect(loader.LoadGraph(pkgs)).To(ContainElement(HaveField("Types", loader.TypesFromExportData)))
+
+			By("checking that the root refers to the types of the dependency")
+			field := controllers.Types.Scope().Lookup("Reconciler").Type().Underlying().(*types.Struct).Field(0)
+			Expect(field.Type().(*types.Named).Obj()).To(BeIdenticalTo(api.Types.Scope().Lookup("Spec")))
+		})
+
+		It("should cache the types read from export data across loads", func() {
+			cache := &loader.TypesCache{Dir: GinkgoT().TempDir()}
+			load := func() (controllers, api *loader.Package) {
+				cfg := &packages.Config{Mode: packages.NeedExportFile, Env: append(os.Environ(), "GOFLAGS=")}
+				pkgs, err := loader.LoadRootsWithConfig(cfg, "./testwork/controllers")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(pkgs).To(HaveLen(1))
+				controllers = pkgs[0]
+				checker := &loader.TypeChecker{ExportData: func(pkg *loader.Package) bool { return pkg != controllers }, Cache: cache}
+				checker.Check(controllers)
+				Expect(controllers.Errors).To(BeEmpty())
+				return controllers, controllers.Imports()[testworkPkg+"/api/v1"]
+			}
+
+			By("reading the types from export data the first time")
+			_, api := load()
+			Expect(api.ExportFile).NotTo(BeEmpty())
+			cached, err := filepath.Glob(filepath.Join(cache.Dir, "*", "*"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cached).NotTo(BeEmpty())
+
+			By("reading them from the cache, without loading export files, the next time")
+			controllers, api := load()
+			Expect(api.Errors).To(BeEmpty())
+			Expect(api.ExportFile).To(BeEmpty())
+			Expect(api.Types.Complete()).To(BeTrue())
+			field := controllers.Types.Scope().Lookup("Reconciler").Type().Underlying().(*types.Struct).Field(0)
+			Expect(field.Type().(*types.Named).Obj()).To(BeIdenticalTo(api.Types.Scope().Lookup("Spec")))
+		})
+
+		It("should use the syntax and types of packages loaded beforehand", func() {
+			cfg.Mode = packages.LoadAllSyntax
+			cfg.Dir = "./testwork"
+			rawPkgs, err := packages.Load(cfg, "./controllers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rawPkgs).To(HaveLen(1))
+			rawAPI := rawPkgs[0].Imports[testworkPkg+"/api/v1"]
+			Expect(rawAPI).NotTo(BeNil())
+
+			pkgs := loader.FromPackages(cfg, rawPkgs...)
+			Expect(pkgs).To(HaveLen(1))
+			controllers := pkgs[0]
+			Expect(controllers.Package).To(BeIdenticalTo(rawPkgs[0]))
+			api := controllers.Imports()[testworkPkg+"/api/v1"]
+			Expect(api.Package).To(BeIdenticalTo(rawAPI))
+
+			By("type-checking without loading the packages again")
+			rawTypes, rawAPITypes := rawPkgs[0].Types, rawAPI.Types
+			(&loader.TypeChecker{}).Check(controllers)
+			Expect(controllers.Errors).To(BeEmpty())
+			Expect(controllers.Types).To(BeIdenticalTo(rawTypes))
+			Expect(api.Types).To(BeIdenticalTo(rawAPITypes))
+		})
+
+		It("should describe which packages were loaded and why", func() {
+			pkgs, err := loader.LoadRootsWithConfig(cfg, "./testwork/controllers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(1))
+			(&loader.TypeChecker{}).Check(pkgs[0])
+
+			graph := loader.LoadGraph(pkgs)
+			Expect(graph[0]).To(Equal(loader.PackageLoad{
+				Path:   testworkPkg + "/controllers",
+				Root:   true,
+				Parsed: true,
+				Types:  loader.TypesFromSource,
+			}))
+			Expect(graph).To(ContainElement(loader.PackageLoad{
+				Path:         testworkPkg + "/api/v1",
+				ImportedBy:   testworkPkg + "/controllers",
+				Parsed:       true,
+				Types:        loader.TypesFromSource,
+				ReferencedBy: testworkPkg + "/controllers.Reconciler",
+			}))
+		})
+
+		It("should parse and type-check released packages again when needed", func() {
+			pkgs, err := loader.LoadRootsWithConfig(cfg, "./testwork/controllers")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(1))
+			checker := &loader.TypeChecker{}
+			checker.Check(pkgs[0])
+			pkgs[0].AddError(errors.New("from a generator"))
+			errs := slices.Clone(pkgs[0].Errors)
+			api := pkgs[0].Imports()[testworkPkg+"/api/v1"]
+			Expect(api.TypesInfo).NotTo(BeNil())
+
+			released := loader.ReleaseSyntax(pkgs, nil)
+			Expect(released).To(ContainElements(pkgs[0], api))
+			checker.Forget(released...)
+			Expect(pkgs[0].Syntax).To(BeNil())
+			Expect(pkgs[0].TypesInfo).To(BeNil())
+			Expect(api.Syntax).To(BeNil())
+			Expect(api.Types).To(BeNil())
+
+			checker.Check(pkgs[0])
+			Expect(pkgs[0].Syntax).NotTo(BeEmpty())
+			Expect(api.TypesInfo).NotTo(BeNil())
+			Expect(pkgs[0].Types.Imports()).To(ContainElement(BeIdenticalTo(api.Types)))
+			Expect(pkgs[0].Errors).To(Equal(errs))
+		})
+
+		It("should keep the packages imported by the packages still needed when releasing", func() {
+			pkgs, err := loader.LoadRootsWithConfig(cfg, "./testwork/controllers", "./testwork/api/...")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(2))
+			api := pkgs[indexOfPackage(testworkPkg+"/api/v1", pkgs)]
+			controllers := pkgs[indexOfPackage(testworkPkg+"/controllers", pkgs)]
+			checker := &loader.TypeChecker{}
+			checker.Check(controllers)
+			Expect(api.TypesInfo).NotTo(BeNil())
+
+			By("releasing the API package, which the controllers still need")
+			Expect(loader.ReleaseSyntax([]*loader.Package{api}, []*loader.Package{controllers})).To(BeEmpty())
+			Expect(api.TypesInfo).NotTo(BeNil())
+
+			By("releasing the controllers, which nothing needs")
+			Expect(loader.ReleaseSyntax([]*loader.Package{controllers}, []*loader.Package{api})).To(ConsistOf(controllers))
+			Expect(controllers.Syntax).To(BeNil())
+			Expect(api.TypesInfo).NotTo(BeNil())
+		})
+
+		It("should load roots of several modules of the workspace", func() {
+			pkgs, err := loader.LoadRootsWithConfig(cfg, "./testwork/controllers", "./testwork/api/...")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pkgs).To(HaveLen(2))
+			api := pkgs[indexOfPackage(testworkPkg+"/api/v1", pkgs)]
+			controllers := pkgs[indexOfPackage(testworkPkg+"/controllers", pkgs)]
+			Expect(controllers.Imports()).To(HaveKeyWithValue(testworkPkg+"/api/v1", BeIdenticalTo(api)))
+		})
+	})
 })
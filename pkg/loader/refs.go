@@ -83,12 +83,16 @@ type referenceSet struct {
 	imports *importsMap
 	pkg     *Package
 
-	externalRefs map[*Package]struct{}
+	// externalRefs are the referenced packages, along with the name of
+	// the first type referencing each.
+	externalRefs map[*Package]string
+	// typeName is the name of the type whose references are collected.
+	typeName string
 }
 
 func (r *referenceSet) init() {
 	if r.externalRefs == nil {
-		r.externalRefs = make(map[*Package]struct{})
+		r.externalRefs = make(map[*Package]string)
 	}
 }
 
@@ -96,9 +100,11 @@ func (r *referenceSet) init() {
 // when true is returned and rejecting them when false is returned.
 type NodeFilter func(ast.Node) bool
 
-// collectReferences saves all references to external types in the given info.
-func (r *referenceSet) collectReferences(rawType ast.Expr, filterNode NodeFilter) {
+// collectReferences saves all references to external types in the given
+// type of the given name.
+func (r *referenceSet) collectReferences(typeName string, rawType ast.Expr, filterNode NodeFilter) {
 	r.init()
+	r.typeName = typeName
 	col := &referenceCollector{
 		refs:       r,
 		filterNode: filterNode,
@@ -113,7 +119,9 @@ func (r *referenceSet) external(pkgName string) {
 		r.pkg.AddError(fmt.Errorf("use of unimported package %q", pkgName))
 		return
 	}
-	r.externalRefs[pkg] = struct{}{}
+	if _, exists := r.externalRefs[pkg]; !exists {
+		r.externalRefs[pkg] = r.typeName
+	}
 }
 
 // referenceCollector visits nodes in an AST, adding external references to a
@@ -145,8 +153,10 @@ func (c *referenceCollector) Visit(node ast.Node) ast.Visitor {
 	}
 }
 
-// allReferencedPackages finds all directly referenced packages in the given package.
-func allReferencedPackages(pkg *Package, filterNodes NodeFilter) []*Package {
+// allReferencedPackages finds all directly referenced packages in the given
+// package, along with the name of a type referencing each (empty for
+// dot-imported packages).
+func allReferencedPackages(pkg *Package, filterNodes NodeFilter) map[*Package]string {
 	pkg.NeedSyntax()
 	refsByFile := make(map[*ast.File]*referenceSet)
 	for _, file := range pkg.Syntax {
@@ -165,24 +175,23 @@ func allReferencedPackages(pkg *Package, filterNodes NodeFilter) []*Package {
 
 	EachType(pkg, func(file *ast.File, _ *ast.GenDecl, spec *ast.TypeSpec) {
 		refs := refsByFile[file]
-		refs.collectReferences(spec.Type, filterNodes)
+		refs.collectReferences(spec.Name.Name, spec.Type, filterNodes)
 	})
 
-	allPackages := make(map[*Package]struct{})
+	allPackages := make(map[*Package]string)
 	for _, refs := range refsByFile {
 		for _, pkg := range refs.imports.dotImports {
-			allPackages[pkg] = struct{}{}
+			if _, exists := allPackages[pkg]; !exists {
+				allPackages[pkg] = ""
+			}
 		}
-		for ref := range refs.externalRefs {
-			allPackages[ref] = struct{}{}
+		for ref, typeName := range refs.externalRefs {
+			if allPackages[ref] == "" {
+				allPackages[ref] = typeName
+			}
 		}
 	}
-
-	res := make([]*Package, 0, len(allPackages))
-	for pkg := range allPackages {
-		res = append(res, pkg)
-	}
-	return res
+	return allPackages
 }
 
 // TypeChecker performs type-checking on a limitted subset of packages by
@@ -199,20 +208,43 @@ type TypeChecker struct {
 	// results.
 	NodeFilters []NodeFilter
 
+	// ExportData selects the packages whose types are loaded from export
+	// data (see Package.NeedTypesFromExportData) instead of type-checked
+	// from source, e.g. the dependencies of the root packages when their
+	// markers aren't needed.  The references of such packages aren't
+	// followed, since their export data covers them.
+	ExportData func(*Package) bool
+	// Cache, if set, holds the types of the packages selected by ExportData
+	// across runs, so that they're only read from export data (compiling
+	// them if needed) when their files or the ones of their dependencies
+	// changed.
+	Cache *TypesCache
+
 	checkedPackages map[*Package]struct{}
 	sync.Mutex
 }
 
 // Check type-checks the given package and all packages referenced by types
 // that pass through (have true returned by) any of the NodeFilters.
+//
+// It's safe to call Check concurrently.
 func (c *TypeChecker) Check(root *Package) {
+	c.Lock()
 	c.init()
+	c.Unlock()
+
+	c.check(root)
+}
 
-	// use a sub-checker with the appropriate settings
-	(&TypeChecker{
-		NodeFilters:     c.NodeFilters,
-		checkedPackages: c.checkedPackages,
-	}).check(root)
+// Forget forgets that the given packages were checked, so that they're
+// checked again by the next calls to Check, e.g. once their syntax was
+// released (see ReleaseSyntax).
+func (c *TypeChecker) Forget(pkgs ...*Package) {
+	c.Lock()
+	defer c.Unlock()
+	for _, pkg := range pkgs {
+		delete(c.checkedPackages, pkg)
+	}
 }
 
 func (c *TypeChecker) isNodeInteresting(node ast.Node) bool {
@@ -240,6 +272,11 @@ func (c *TypeChecker) init() {
 // are actually referenced by our types (it's the actual implementation of Check,
 // without initialization).
 func (c *TypeChecker) check(root *Package) {
+	if c.ExportData != nil && c.ExportData(root) {
+		root.needTypesFromExportData(c.Cache)
+		return
+	}
+
 	root.Lock()
 	defer root.Unlock()
 
@@ -254,7 +291,8 @@ func (c *TypeChecker) check(root *Package) {
 
 	// first, resolve imports for all leaf packages...
 	var wg sync.WaitGroup
-	for _, pkg := range refedPackages {
+	for pkg, typeName := range refedPackages {
+		pkg.referencedBy.CompareAndSwap(nil, &typeRef{pkg: root, typeName: typeName})
 		wg.Add(1)
 		go func(pkg *Package) {
 			defer wg.Done()
@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"sync"
+)
+
+// ReleaseSyntax releases the syntax and type-checking information of the
+// given packages and of the packages they import, directly or not, so that
+// the memory they hold (by far the largest part of the memory of a
+// generation) can be reclaimed once nothing uses them anymore, e.g. once
+// all the generators are done with a root package.  It returns the released
+// packages.
+//
+// The packages also imported by the given needed ones, directly or not, are
+// kept: the types of a package checked again are new objects, which must not
+// be mixed with the ones of the packages checked against its previous types.
+// Released packages are parsed and type-checked again if needed anyway.
+// Types read from export data are kept, along with the packages whose types
+// they share.  The errors found again when loading a package again are only
+// reported once.
+//
+// ReleaseSyntax must not be called concurrently with the other methods of
+// the released packages.
+func ReleaseSyntax(done, needed []*Package) []*Package {
+	kept := make(map[*Package]struct{})
+	walkImports(needed, func(pkg *Package) bool {
+		kept[pkg] = struct{}{}
+		return true
+	})
+
+	var released []*Package
+	walkImports(done, func(pkg *Package) bool {
+		if _, isKept := kept[pkg]; isKept {
+			return false
+		}
+		if pkg.releaseSyntax() {
+			released = append(released, pkg)
+		}
+		return true
+	})
+	return released
+}
+
+// walkImports calls the given function on the given packages and on the
+// packages they import, directly or not, once each, only following the
+// imports of the packages it returns true for.
+func walkImports(pkgs []*Package, visit func(*Package) bool) {
+	seen := make(map[*Package]struct{}, len(pkgs))
+	queue := append([]*Package(nil), pkgs...)
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[pkg]; ok {
+			continue
+		}
+		seen[pkg] = struct{}{}
+		if !visit(pkg) {
+			continue
+		}
+		for _, importedPkg := range pkg.Imports() {
+			queue = append(queue, importedPkg)
+		}
+	}
+}
+
+// releaseSyntax releases the syntax and type-checking information of the
+// package, so that they're loaded again when needed, returning whether there
+// was anything to release.
+func (p *Package) releaseSyntax() bool {
+	p.Lock()
+	defer p.Unlock()
+	if p.Syntax == nil && p.TypesInfo == nil {
+		return false
+	}
+	if p.TypesInfo != nil {
+		if p.loader.sharesTypes(p.Types) {
+			// packages read from export data refer to these types
+			return false
+		}
+		p.Types = nil
+		p.TypesInfo = nil
+		p.releasedTypes = true
+		p.typesOnce = sync.Once{}
+		p.exportOnce = sync.Once{}
+	}
+	p.Syntax = nil
+	p.syntaxOnce = sync.Once{}
+
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+	p.released = true
+	return true
+}
+
+// reported checks whether the given diagnostic was already reported on the
+// package, with errorsMu held.
+func (p *Package) reported(diag Diagnostic) bool {
+	for _, existing := range p.diagnostics {
+		if existing.Severity == diag.Severity && existing.Message == diag.Message &&
+			existing.File == diag.File && existing.Line == diag.Line && existing.Column == diag.Column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,3 @@
+module sigs.k8s.io/controller-tools/pkg/loader/testwork/api
+
+go 1.22
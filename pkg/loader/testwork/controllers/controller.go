@@ -0,0 +1,7 @@
+package controllers
+
+import v1 "sigs.k8s.io/controller-tools/pkg/loader/testwork/api/v1"
+
+type Reconciler struct {
+	Spec v1.Spec
+}
@@ -0,0 +1,3 @@
+module sigs.k8s.io/controller-tools/pkg/loader/testwork/other
+
+go 1.22
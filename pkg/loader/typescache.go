@@ -0,0 +1,178 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/types"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/tools/go/gcexportdata"
+)
+
+// typesCacheVersion is part of the keys of the cached types, to be bumped
+// when what they're computed from changes.
+const typesCacheVersion = "controller-tools types v1"
+
+// TypesCache is an on-disk cache of the types of packages read from export
+// data (see TypeChecker.Cache), so that later loads of unchanged packages
+// warm-start from it, without loading (and so compiling) export files.
+//
+// The types of a package are keyed by the contents of its files, its build
+// configuration and the keys of the packages it imports, so that they're
+// invalidated whenever the package or any of its dependencies changes.
+type TypesCache struct {
+	// Dir is the directory the cached types are stored in.
+	Dir string
+}
+
+// DefaultTypesCacheDir returns the default directory of a TypesCache, under
+// the user's cache directory.
+func DefaultTypesCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "controller-tools", "types"), nil
+}
+
+// path returns the path of the cached types of the given package, or false
+// if the package can't be cached.
+func (c *TypesCache) path(pkg *Package) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	key, err := pkg.typesKey()
+	if err != nil {
+		slog.Debug("unable to cache the types of package", "package", pkg.PkgPath, "error", err)
+		return "", false
+	}
+	return filepath.Join(c.Dir, key[:2], key), true
+}
+
+// has checks whether the types of the given package are cached.
+func (c *TypesCache) has(pkg *Package) bool {
+	path, ok := c.path(pkg)
+	if !ok {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// read reads the cached types of the given package, if there.
+func (c *TypesCache) read(pkg *Package) (*types.Package, bool) {
+	path, ok := c.path(pkg)
+	if !ok {
+		return nil, false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	typesPkg, err := pkg.loader.decodeExportData(pkg, bufio.NewReader(file))
+	if err != nil {
+		slog.Debug("unable to read the cached types of package", "package", pkg.PkgPath, "error", err)
+		return nil, false
+	}
+	return typesPkg, true
+}
+
+// write caches the given types of the given package.  Failing to do so only
+// means loading them again next time, so it's only logged.
+func (c *TypesCache) write(pkg *Package, typesPkg *types.Package) {
+	path, ok := c.path(pkg)
+	if !ok {
+		return
+	}
+	if err := writeTypes(path, pkg, typesPkg); err != nil {
+		slog.Debug("unable to cache the types of package", "package", pkg.PkgPath, "error", err)
+	}
+}
+
+// writeTypes writes the given types of the given package to the given path
+// as export data, atomically, so that concurrent runs never read partially
+// written types.
+func writeTypes(path string, pkg *Package, typesPkg *types.Package) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	out := bufio.NewWriter(file)
+	pkg.loader.exportMu.Lock()
+	err = gcexportdata.Write(out, pkg.loader.cfg.Fset, typesPkg)
+	pkg.loader.exportMu.Unlock()
+	if err == nil {
+		err = out.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// typesKey returns the key of the types of the package in a TypesCache,
+// hashing the contents of its files (including the ones excluded by build
+// constraints, such as generated code left out with ignore_autogenerated),
+// its build configuration and the keys of its imports.
+func (p *Package) typesKey() (string, error) {
+	p.cacheKeyOnce.Do(func() {
+		hash := sha256.New()
+		fmt.Fprintf(hash, "%s\n%s\n%+v\n%q\n", typesCacheVersion, p.PkgPath, p.TypesSizes, p.loader.cfg.BuildFlags)
+		for _, files := range []struct {
+			kind  string
+			paths []string
+		}{{"go", p.GoFiles}, {"ignored", p.IgnoredFiles}} {
+			for _, file := range slices.Sorted(slices.Values(files.paths)) {
+				contents, err := p.ReadFile(file)
+				if err != nil {
+					p.cacheKeyErr = err
+					return
+				}
+				fmt.Fprintf(hash, "%s %s %d\n", files.kind, filepath.Base(file), len(contents))
+				hash.Write(contents)
+			}
+		}
+		imports := p.Imports()
+		for _, path := range slices.Sorted(maps.Keys(imports)) {
+			key, err := imports[path].typesKey()
+			if err != nil {
+				p.cacheKeyErr = err
+				return
+			}
+			fmt.Fprintf(hash, "import %s %s\n", path, key)
+		}
+		p.cacheKey = hex.EncodeToString(hash.Sum(nil))
+	})
+	return p.cacheKey, p.cacheKeyErr
+}
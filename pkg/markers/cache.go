@@ -0,0 +1,73 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markers
+
+import (
+	"sync"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// packageCache caches values computed per package.  Concurrent callers (e.g.
+// generators running in parallel) wait for the ongoing computation of a
+// package rather than repeating it.  Failed computations aren't cached, so
+// that they're retried on the next call.  The zero value is ready to use.
+type packageCache[T any] struct {
+	entries map[*loader.Package]*cacheEntry[T]
+	mu      sync.Mutex
+}
+
+// cacheEntry is a (possibly ongoing) computation of a packageCache.
+type cacheEntry[T any] struct {
+	// done is closed once the computation is over.
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// get returns the value of the given package, computing it with the given
+// function unless it's cached or being computed already.
+func (c *packageCache[T]) get(pkg *loader.Package, compute func() (T, error)) (T, error) {
+	c.mu.Lock()
+	if entry, exists := c.entries[pkg]; exists {
+		c.mu.Unlock()
+		<-entry.done
+		return entry.val, entry.err
+	}
+	if c.entries == nil {
+		c.entries = make(map[*loader.Package]*cacheEntry[T])
+	}
+	entry := &cacheEntry[T]{done: make(chan struct{})}
+	c.entries[pkg] = entry
+	c.mu.Unlock()
+
+	entry.val, entry.err = compute()
+	if entry.err != nil {
+		c.mu.Lock()
+		delete(c.entries, pkg)
+		c.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.val, entry.err
+}
+
+// forget forgets the cached value of the given package.
+func (c *packageCache[T]) forget(pkg *loader.Package) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, pkg)
+}
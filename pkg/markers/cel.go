@@ -0,0 +1,225 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markers
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	sc "text/scanner"
+
+	"github.com/google/cel-go/cel"
+)
+
+// CEL is a CEL expression, as a marker argument.  It's written like a string
+// argument, and is syntax-checked (but not type-checked, since the variables
+// available to it depend on where it's used) when parsing the marker, so that
+// invalid expressions are reported at the marker itself.
+//
+// When collected by a Collector, the position of the expression in the source
+// is known, so that generators can report later problems of the expression
+// (e.g. when type-checking it) at the expression itself, passing it to
+// loader.ErrFromNode.
+type CEL struct {
+	// Expression is the text of the expression.
+	Expression string
+	// AST is the parsed, unchecked, expression.
+	AST *cel.Ast
+
+	// offset and length are the range of the argument (including quotes,
+	// if any) in the arguments of the marker.
+	offset, length int
+	// pos is the position of the argument in the source, if known.
+	pos token.Pos
+}
+
+// celType is a pre-computed reflect.Type representing a CEL expression.
+var celType = reflect.TypeFor[CEL]()
+
+// ParseCEL parses the given expression as the value of a CEL argument,
+// without position.
+func ParseCEL(expr string) (CEL, error) {
+	ast, iss := celParser().Parse(expr)
+	if err := iss.Err(); err != nil {
+		return CEL{}, err
+	}
+	return CEL{Expression: expr, AST: ast}, nil
+}
+
+// Pos returns the position of the expression in the source (including its
+// quotes), or token.NoPos if unknown.
+func (c CEL) Pos() token.Pos {
+	return c.pos
+}
+
+// End returns the position just after the expression in the source (including
+// its quotes), or token.NoPos if unknown.
+func (c CEL) End() token.Pos {
+	if !c.pos.IsValid() {
+		return token.NoPos
+	}
+	return c.pos + token.Pos(c.length)
+}
+
+func (c CEL) String() string {
+	return c.Expression
+}
+
+// MarshalJSON marshals the expression as its text.
+func (c CEL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Expression)
+}
+
+// celParser returns the environment parsing CEL expressions, accepting the
+// syntax of all the expressions Kubernetes accepts.
+var celParser = sync.OnceValue(func() *cel.Env {
+	env, err := cel.NewEnv(cel.OptionalTypes())
+	if err != nil {
+		panic(fmt.Sprintf("unable to set up the CEL environment: %v", err))
+	}
+	return env
+})
+
+// parseCEL parses a CEL expression, reporting its syntax errors at the
+// offending part of the expression.
+func (a *Argument) parseCEL(scanner *sc.Scanner, raw string, out reflect.Value) {
+	peekNoSpace(scanner)
+	start := scanner.Pos().Offset
+
+	var expr string
+	a.parseString(scanner, raw, reflect.ValueOf(&expr).Elem(), false)
+	end := scanner.Pos().Offset
+	if end <= start {
+		// nothing parsed, an error was already reported
+		return
+	}
+
+	exprStart := start
+	if raw[start] == '"' || raw[start] == '\'' || raw[start] == '`' {
+		// NB: positions past escapes in quoted strings are a tad off
+		exprStart++
+	}
+	ast, iss := celParser().Parse(expr)
+	if err := iss.Err(); err != nil {
+		afterExpr := scanner.Position
+		for _, celErr := range iss.Errors() {
+			errOffset := exprStart + exprOffset(expr, celErr.Location.Line(), celErr.Location.Column())
+			scanner.Position.Offset = errOffset
+			scanner.Position.Column = utf8.RuneCountInString(raw[:errOffset]) + 1
+			scanner.Error(scanner, fmt.Sprintf("invalid CEL expression: %s", celErr.Message))
+		}
+		scanner.Position = afterExpr
+		return
+	}
+
+	castAndSet(out, reflect.ValueOf(CEL{Expression: expr, AST: ast, offset: start, length: end - start}))
+}
+
+// exprOffset returns the offset in bytes of the given (1-based) line and
+// (0-based) column in runes in the given expression.
+func exprOffset(expr string, line, column int) int {
+	offset := 0
+	for range line - 1 {
+		nextLine := strings.IndexByte(expr[offset:], '\n')
+		if nextLine < 0 {
+			return offset
+		}
+		offset += nextLine + 1
+	}
+	for i := range expr[offset:] {
+		if column == 0 {
+			return offset + i
+		}
+		column--
+	}
+	return len(expr)
+}
+
+// containsCEL checks whether values of the argument may contain CEL expressions.
+func (a Argument) containsCEL() bool {
+	switch a.Type {
+	case CELType:
+		return true
+	case SliceType, MapType:
+		return a.ItemType.containsCEL()
+	case StructType:
+		for _, field := range a.Fields {
+			if field.containsCEL() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsCEL checks whether values of the definition may contain CEL expressions.
+func (d *Definition) containsCEL() bool {
+	for _, field := range d.Fields {
+		if field.containsCEL() {
+			return true
+		}
+	}
+	return false
+}
+
+// positionCEL returns the given marker value, with the positions of its CEL
+// expressions set from the given position of the arguments of the marker.
+func positionCEL(val any, argsPos token.Pos) any {
+	out := reflect.New(reflect.TypeOf(val)).Elem()
+	out.Set(reflect.ValueOf(val))
+	setCELPos(out, argsPos)
+	return out.Interface()
+}
+
+// setCELPos sets the positions of the CEL expressions in the given settable value.
+func setCELPos(val reflect.Value, argsPos token.Pos) {
+	switch val.Kind() {
+	case reflect.Struct:
+		if val.Type() == celType {
+			if expr := val.Addr().Interface().(*CEL); expr.AST != nil {
+				// not an omitted argument
+				expr.pos = argsPos + token.Pos(expr.offset)
+			}
+			return
+		}
+		for i := range val.NumField() {
+			if val.Field(i).CanSet() {
+				setCELPos(val.Field(i), argsPos)
+			}
+		}
+	case reflect.Pointer:
+		if !val.IsNil() {
+			setCELPos(val.Elem(), argsPos)
+		}
+	case reflect.Slice:
+		for i := range val.Len() {
+			setCELPos(val.Index(i), argsPos)
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			elem := reflect.New(val.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			setCELPos(elem, argsPos)
+			val.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
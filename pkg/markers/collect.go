@@ -17,10 +17,20 @@ limitations under the License.
 package markers
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/token"
+	"iter"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"sigs.k8s.io/controller-tools/pkg/loader"
 )
@@ -31,8 +41,22 @@ import (
 type Collector struct {
 	*Registry
 
-	byPackage map[*loader.Package]map[ast.Node]MarkerValues
-	mu        sync.Mutex
+	// Variables are the variables interpolated in the string values of
+	// markers, if any.  Without them, ${NAME} is left as is.
+	Variables *Variables
+
+	// byPackage are the marker values of the packages, and rawByPackage
+	// the raw markers associated with their nodes.
+	byPackage    packageCache[map[ast.Node]MarkerValues]
+	rawByPackage packageCache[map[ast.Node][]markerComment]
+	// warned are the markers already warned about, since packages failing
+	// to parse are parsed again on each call.
+	warned map[token.Pos]struct{}
+	// sidecarFiles are the sidecar files read, by path.
+	sidecarFiles map[string]*token.File
+	mu           sync.Mutex
+	// collecting is the time spent collecting markers, in nanoseconds.
+	collecting atomic.Int64
 }
 
 // MarkerValues are all the values for some set of markers.
@@ -52,8 +76,11 @@ func (c *Collector) init() {
 	if c.Registry == nil {
 		c.Registry = &Registry{}
 	}
-	if c.byPackage == nil {
-		c.byPackage = make(map[*loader.Package]map[ast.Node]MarkerValues)
+	if c.warned == nil {
+		c.warned = make(map[token.Pos]struct{})
+	}
+	if c.sidecarFiles == nil {
+		c.sidecarFiles = make(map[string]*token.File)
 	}
 }
 
@@ -61,10 +88,16 @@ func (c *Collector) init() {
 // are cached by package ID, so this is safe to call repeatedly from different functions.
 // Each file in the package is treated as a distinct node.
 //
+// Markers are lines of `//` comments or of `/* */` comments (optionally
+// decorated with a leading `*`) starting with `+`.
+//
 // We consider a marker to be associated with a given AST node if either of the following are true:
 //
 // - it's in the Godoc for that AST node
 //
+//   - it's in the Godoc of a group of types (type ( ... )), and that node is
+//     one of the types of the group (after the markers of the type itself)
+//
 //   - it's in the closest non-godoc comment group above that node,
 //     *and* that node is a type or field node, *and* [it's either
 //     registered as type-level *or* it's not registered as being
@@ -72,55 +105,154 @@ func (c *Collector) init() {
 //
 //   - it's not in the Godoc of a node, doesn't meet the above criteria, and
 //     isn't in a struct definition (in which case it's package-level)
+//
+// Markers may also be attached to the package, and to its types and fields
+// by name, in the sidecar file of the package (see SidecarFile).
 func (c *Collector) MarkersInPackage(pkg *loader.Package) (map[ast.Node]MarkerValues, error) {
 	c.mu.Lock()
 	c.init()
-	if markers, exist := c.byPackage[pkg]; exist {
-		c.mu.Unlock()
-		return markers, nil
+	c.mu.Unlock()
+
+	return c.byPackage.get(pkg, func() (map[ast.Node]MarkerValues, error) {
+		nodeMarkersRaw, err := c.rawMarkersByNode(pkg)
+		if err != nil {
+			return nil, err
+		}
+		defer c.addCollectionTime(time.Now())
+		return c.parseMarkersInPackage(pkg, nodeMarkersRaw)
+	})
+}
+
+// CollectionTime returns the time spent collecting the markers of packages
+// so far, summed over the packages (which may be collected concurrently),
+// including the time spent parsing the packages that weren't yet.
+func (c *Collector) CollectionTime() time.Duration {
+	return time.Duration(c.collecting.Load())
+}
+
+// addCollectionTime adds the time since the given start to the time spent
+// collecting markers.
+func (c *Collector) addCollectionTime(start time.Time) {
+	c.collecting.Add(int64(time.Since(start)))
+}
+
+// Forget forgets the markers collected from the given packages, which refer
+// to their syntax, once it was released (see loader.ReleaseSyntax), so that
+// they're collected again from the packages parsed again if needed.
+func (c *Collector) Forget(pkgs ...*loader.Package) {
+	for _, pkg := range pkgs {
+		c.byPackage.forget(pkg)
+		c.rawByPackage.forget(pkg)
 	}
-	// unlock early, it's ok if we do a bit extra work rather than locking while we're working
+}
+
+// rawMarkersByNode returns the raw markers of the given package (including
+// the ones of its sidecar file), by the node they're associated with.  The
+// result is cached, and mustn't be modified.
+func (c *Collector) rawMarkersByNode(pkg *loader.Package) (map[ast.Node][]markerComment, error) {
+	return c.rawByPackage.get(pkg, func() (map[ast.Node][]markerComment, error) {
+		defer c.addCollectionTime(time.Now())
+		pkg.NeedSyntax()
+		nodeMarkersRaw := c.associatePkgMarkers(pkg)
+		if err := c.associateSidecarMarkers(pkg, nodeMarkersRaw); err != nil {
+			return nil, err
+		}
+		return nodeMarkersRaw, nil
+	})
+}
+
+// RawMarker is a marker found in a package, registered or not.
+type RawMarker struct {
+	// Node is the node the marker is associated with: the *ast.File for
+	// package-level markers, or the *ast.TypeSpec or *ast.Field.
+	Node ast.Node
+	// Target is the kind of node the marker is associated with.
+	Target TargetType
+	// Text is the text of the marker, as should be passed to
+	// Registry.Lookup and Definition.Parse.
+	Text string
+
+	pos token.Pos
+}
+
+// Pos returns the position of the marker, i.e. of its leading `+`.
+func (m RawMarker) Pos() token.Pos {
+	return m.pos
+}
+
+// RawMarkersInPackage returns all the markers of the given package, whether
+// they're registered or not, associated with nodes as with MarkersInPackage,
+// in the order of their positions.  It's meant for checking the markers
+// (e.g. for ones no generator uses), rather than for reading their values.
+func (c *Collector) RawMarkersInPackage(pkg *loader.Package) ([]RawMarker, error) {
+	c.mu.Lock()
+	c.init()
 	c.mu.Unlock()
 
-	pkg.NeedSyntax()
-	nodeMarkersRaw := c.associatePkgMarkers(pkg)
-	markers, err := c.parseMarkersInPackage(nodeMarkersRaw)
+	nodeMarkersRaw, err := c.rawMarkersByNode(pkg)
 	if err != nil {
 		return nil, err
 	}
+	var res []RawMarker
+	for node, markersRaw := range nodeMarkersRaw {
+		for _, markerRaw := range markersRaw {
+			res = append(res, RawMarker{Node: node, Target: targetOf(node), Text: markerRaw.Text(), pos: markerRaw.Pos()})
+		}
+	}
+	slices.SortFunc(res, func(a, b RawMarker) int {
+		return cmp.Compare(a.pos, b.pos)
+	})
+	return res, nil
+}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.byPackage[pkg] = markers
-	return markers, nil
+// targetOf returns the kind of the given node markers are associated with.
+func targetOf(node ast.Node) TargetType {
+	switch node.(type) {
+	case *ast.File:
+		return DescribesPackage
+	case *ast.Field:
+		return DescribesField
+	default:
+		return DescribesType
+	}
 }
 
 // parseMarkersInPackage parses the given raw marker comments into output values using the registry.
-func (c *Collector) parseMarkersInPackage(nodeMarkersRaw map[ast.Node][]markerComment) (map[ast.Node]MarkerValues, error) {
+// Markers written with a deprecated alias are reported as warnings on the package.
+func (c *Collector) parseMarkersInPackage(pkg *loader.Package, nodeMarkersRaw map[ast.Node][]markerComment) (map[ast.Node]MarkerValues, error) {
 	var errors []error
 	nodeMarkerValues := make(map[ast.Node]MarkerValues)
 	for node, markersRaw := range nodeMarkersRaw {
-		var target TargetType
-		switch node.(type) {
-		case *ast.File:
-			target = DescribesPackage
-		case *ast.Field:
-			target = DescribesField
-		default:
-			target = DescribesType
-		}
+		target := targetOf(node)
 		markerVals := make(map[string][]any)
 		for _, markerRaw := range markersRaw {
 			markerText := markerRaw.Text()
-			def := c.Registry.Lookup(markerText, target)
+			def, alias := c.Registry.LookupAlias(markerText, target)
 			if def == nil {
+				c.checkUnknownMarker(pkg, markerRaw, target)
 				continue
 			}
+			if alias != "" {
+				c.warn(pkg, markerRaw, fmt.Errorf("marker %q is deprecated, use %q instead", alias, def.Name))
+				markerText = "+" + def.Name + markerText[len(alias)+1:]
+			}
 			val, err := def.Parse(markerText)
 			if err != nil {
-				errors = append(errors, loader.ErrFromNode(err, markerRaw))
+				errors = append(errors, parseError(err, def, markerRaw))
 				continue
 			}
+			if c.Variables != nil && strings.Contains(markerText, "${") {
+				if val, err = c.Variables.interpolate(val); err != nil {
+					errors = append(errors, loader.ErrFromNode(loader.MarkerError{
+						Marker: def.Name,
+						Err:    fmt.Errorf("invalid marker +%s: %w", def.Name, err),
+					}, markerRaw))
+					continue
+				}
+			}
+			if def.containsCEL() && strings.Contains(markerRaw.Comment.Text, markerRaw.text) {
+				val = positionCEL(val, markerRaw.Pos()+token.Pos(argumentsOffset(markerRaw.text, def)))
+			}
 			markerVals[def.Name] = append(markerVals[def.Name], val)
 		}
 		nodeMarkerValues[node] = markerVals
@@ -129,12 +261,120 @@ func (c *Collector) parseMarkersInPackage(nodeMarkersRaw map[ast.Node][]markerCo
 	return nodeMarkerValues, loader.MaybeErrList(errors)
 }
 
+// parseError returns the given error of parsing the given marker of the
+// given definition, along with the syntax the marker was expected to follow.
+// Errors of the arguments are positioned at the offending argument, when the
+// marker isn't continued over several lines.
+func parseError(err error, def *Definition, markerRaw markerComment) error {
+	if errList, isList := err.(loader.ErrList); isList {
+		resList := make(loader.ErrList, len(errList))
+		for i, baseErr := range errList {
+			resList[i] = parseError(baseErr, def, markerRaw)
+		}
+		return resList
+	}
+
+	var node loader.Node = markerRaw
+	var scannerErr *ScannerError
+	if errors.As(err, &scannerErr) && strings.Contains(markerRaw.Comment.Text, markerRaw.text) {
+		node = position(markerRaw.Pos() + token.Pos(argumentsOffset(markerRaw.text, def)+scannerErr.Pos.Offset))
+		err = errors.New(scannerErr.Msg)
+	}
+	return loader.ErrFromNode(loader.MarkerError{
+		Marker:       def.Name,
+		SuggestedFix: "use " + def.Syntax(),
+		Err:          fmt.Errorf("invalid marker +%s: %w (expected %s)", def.Name, err, def.Syntax()),
+	}, node)
+}
+
+// argumentsOffset returns the offset of the arguments in the given marker of
+// the given definition, as scanned by Definition.Parse.
+func argumentsOffset(markerText string, def *Definition) int {
+	name, anonName, fields := splitMarker(markerText)
+	if !def.AnonymousField() && !def.Empty() && len(anonName) >= len(name)+1 {
+		// the first argument is part of the name, as in +a:b:c=arg
+		return len("+" + name + ":")
+	}
+	return len(markerText) - len(fields)
+}
+
+// position is a position in the source, as a loader.Node.
+type position token.Pos
+
+func (p position) Pos() token.Pos {
+	return token.Pos(p)
+}
+
+// warn warns about the given marker of the given package, once.
+func (c *Collector) warn(pkg *loader.Package, markerRaw markerComment, err error) {
+	c.mu.Lock()
+	_, warned := c.warned[markerRaw.Pos()]
+	c.warned[markerRaw.Pos()] = struct{}{}
+	c.mu.Unlock()
+	if !warned {
+		pkg.AddWarning(loader.ErrFromNode(err, markerRaw))
+	}
+}
+
+// checkUnknownMarker warns about the given marker that isn't registered for
+// the given target, if it's registered for other targets instead, or if it
+// looks like a typo of a registered marker.  Other markers are left alone,
+// since they may well be read by other tools.
+func (c *Collector) checkUnknownMarker(pkg *loader.Package, markerRaw markerComment, target TargetType) {
+	markerText := markerRaw.Text()
+	var otherTargets []string
+	var otherDef *Definition
+	for _, otherTarget := range []TargetType{DescribesPackage, DescribesType, DescribesField} {
+		if otherTarget == target {
+			continue
+		}
+		if def := c.Registry.Lookup(markerText, otherTarget); def != nil {
+			otherTargets = append(otherTargets, "a "+otherTarget.String())
+			otherDef = def
+		}
+	}
+	if otherDef != nil {
+		c.warn(pkg, markerRaw, loader.MarkerError{
+			Marker: otherDef.Name,
+			Err:    fmt.Errorf("marker +%s can't be used on a %s, only on %s", otherDef.Name, target, strings.Join(otherTargets, " or ")),
+		})
+		return
+	}
+
+	name, anonName, _ := splitMarker(markerText)
+	nearest := c.Registry.Nearest(anonName)
+	if nearest == "" && name != anonName {
+		nearest = c.Registry.Nearest(name)
+	}
+	if nearest == "" {
+		return
+	}
+	c.warn(pkg, markerRaw, loader.MarkerError{
+		Marker:       nearest,
+		SuggestedFix: "use +" + nearest,
+		Err:          fmt.Errorf("unknown marker +%s (did you mean +%s?)", anonName, nearest),
+	})
+}
+
 // associatePkgMarkers associates markers with AST nodes in the given package.
+// The files are processed concurrently, since that's the bulk of the work for
+// packages with many files.
 func (c *Collector) associatePkgMarkers(pkg *loader.Package) map[ast.Node][]markerComment {
+	fileNodeMarkers := make([]map[ast.Node][]markerComment, len(pkg.Syntax))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, file := range pkg.Syntax {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			fileNodeMarkers[i] = c.associateFileMarkers(file)
+		})
+	}
+	wg.Wait()
+
 	nodeMarkers := make(map[ast.Node][]markerComment)
-	for _, file := range pkg.Syntax {
-		fileNodeMarkers := c.associateFileMarkers(file)
-		for node, markers := range fileNodeMarkers {
+	for _, fileMarkers := range fileNodeMarkers {
+		for node, markers := range fileMarkers {
 			nodeMarkers[node] = append(nodeMarkers[node], markers...)
 		}
 	}
@@ -150,6 +390,7 @@ func (c *Collector) associateFileMarkers(file *ast.File) map[ast.Node][]markerCo
 		markerVisitor: &markerVisitor{
 			nodeMarkers: make(map[ast.Node][]markerComment),
 			allComments: file.Comments,
+			registry:    c.Registry,
 		},
 	}
 	ast.Walk(visitor, file)
@@ -197,19 +438,29 @@ func (c *Collector) associateFileMarkers(file *ast.File) map[ast.Node][]markerCo
 	return visitor.nodeMarkers
 }
 
-// markerComment is an AST comment that contains a marker.
+// markerComment is an AST comment that contains a marker (the first
+// line of the marker, if it's continued over several lines).
 // It may or may not be from a Godoc comment, which affects
 // marker re-associated (from type-level to package-level)
 type markerComment struct {
 	*ast.Comment
+	// offset is the offset of the marker in the text of the comment, which
+	// may hold several markers for `/* */` comments.
+	offset    int
+	text      string
 	fromGodoc bool
 }
 
+// Pos returns the position of the marker itself, i.e. of its leading `+`.
+func (c markerComment) Pos() token.Pos {
+	return c.Slash + token.Pos(c.offset)
+}
+
 // Text returns the text of the marker, stripped of the comment
 // marker and leading spaces, as should be passed to Registry.Lookup
 // and Registry.Parse.
 func (c markerComment) Text() string {
-	return strings.TrimSpace(c.Comment.Text[2:])
+	return c.text
 }
 
 // markerVisistor visits AST nodes, recording markers associated with each node.
@@ -218,23 +469,211 @@ type markerVisitor struct {
 	commentInd  int
 
 	declComments         []markerComment
+	groupComments        []markerComment
+	firstGroupComments   []markerComment
 	lastLineCommentGroup *ast.CommentGroup
 
 	pkgMarkers  []markerComment
 	nodeMarkers map[ast.Node][]markerComment
+
+	// registry tells the type-level markers in the godoc of groups of types,
+	// the only ones applying to each type of the group.
+	registry *Registry
 }
 
-// isMarkerComment checks that the given comment is a single-line (`//`)
-// comment and it's first non-space content is `+`.
-func isMarkerComment(comment string) bool {
-	if comment[0:2] != "//" {
-		return false
+// commentLine is a line of a comment of a comment group: the comment itself
+// for `//` comments, or one of the lines of a `/* */` comment.
+type commentLine struct {
+	// comment is the index of the comment in the comment group.
+	comment int
+	// block is true for the lines of `/* */` comments.
+	block bool
+	// start and end are the range of the line in the text of the comment,
+	// and offset is the offset of its content (after the comment markers,
+	// leading spaces and, in block comments, the leading `*` decoration).
+	start, end, offset int
+	// text is the content of the line, without leading or trailing spaces.
+	text string
+}
+
+// indent returns the indentation of the content of the line, i.e. its
+// offset from the start of the line.
+func (l commentLine) indent() int {
+	return l.offset - l.start
+}
+
+// isMarker checks that the first non-space content of the line is `+`.
+// In `/* */` comments, which often hold prose or commented-out code, the
+// `+` must also be followed by the name of the marker, e.g. not by a space
+// or a digit.
+func (l commentLine) isMarker() bool {
+	if !l.block {
+		return strings.HasPrefix(l.text, "+")
+	}
+	name, _ := utf8.DecodeRuneInString(strings.TrimPrefix(l.text, "+"))
+	return strings.HasPrefix(l.text, "+") && unicode.IsLetter(name)
+}
+
+// commentLines returns the lines of the given comments of a comment group.
+func commentLines(comments []*ast.Comment) []commentLine {
+	var lines []commentLine
+	for i, comment := range comments {
+		if strings.HasPrefix(comment.Text, "//") {
+			content := strings.TrimLeft(comment.Text[2:], " \t")
+			lines = append(lines, commentLine{
+				comment: i,
+				end:     len(comment.Text),
+				offset:  len(comment.Text) - len(content),
+				text:    strings.TrimRight(content, " \t\r"),
+			})
+			continue
+		}
+		// a /* */ comment, whose delimiters are in its first and last lines
+		body := strings.TrimSuffix(comment.Text, "*/")
+		for start := 0; start < len(comment.Text); {
+			end := len(comment.Text)
+			if nl := strings.IndexByte(comment.Text[start:], '\n'); nl >= 0 {
+				end = start + nl
+			}
+			contentStart, contentEnd := start, min(end, len(body))
+			if start == 0 {
+				contentStart = len("/*")
+			}
+			content := strings.TrimLeft(body[min(contentStart, contentEnd):contentEnd], " \t")
+			if strings.HasPrefix(content, "*") {
+				// javadoc-style decoration, as in ` * +marker`
+				content = strings.TrimLeft(content[1:], " \t")
+			}
+			lines = append(lines, commentLine{
+				comment: i,
+				block:   true,
+				start:   start,
+				end:     end,
+				offset:  contentEnd - len(content),
+				text:    strings.TrimRight(content, " \t\r"),
+			})
+			start = end + 1
+		}
+	}
+	return lines
+}
+
+// markerContinuation ends the lines of markers continued on the next line.
+const markerContinuation = `\`
+
+// markerSpan is a marker spanning the comments from start (inclusive) to end
+// (exclusive) of a comment group, and the lines from startLine to endLine
+// (likewise) of the comment group.
+type markerSpan struct {
+	start, end         int
+	startLine, endLine int
+	// offset is the offset of the marker in the text of its first comment.
+	offset int
+	text   string
+}
+
+// markerSpans returns the markers in the given comments of a comment group.
+//
+// Markers are lines of `//` comments, or lines of `/* */` comments
+// (optionally after a leading `*`, as in javadoc-style comments), whose
+// first non-space content is `+`.
+//
+// Markers may be continued over several lines by ending each line but the
+// last with a backslash, and indenting the following lines further than the
+// marker, e.g. for long CEL rules.  The lines are joined without their
+// backslash and the leading spaces of the following lines.  Lines that
+// aren't indented further (e.g. the rest of the godoc after a pattern ending
+// in a backslash) or are markers themselves don't continue markers.  Markers
+// in `//` comments are continued by the following `//` comments, and markers
+// in `/* */` comments by the following lines of the same comment.
+func markerSpans(comments []*ast.Comment) []markerSpan {
+	lines := commentLines(comments)
+	var spans []markerSpan
+	for i := 0; i < len(lines); i++ {
+		if !lines[i].isMarker() {
+			continue
+		}
+		span := markerSpan{start: lines[i].comment, startLine: i, offset: lines[i].offset, text: lines[i].text}
+		for strings.HasSuffix(span.text, markerContinuation) && i+1 < len(lines) && continues(lines[i], lines[i+1]) {
+			i++
+			span.text = strings.TrimSuffix(span.text, markerContinuation) + lines[i].text
+		}
+		span.end, span.endLine = lines[i].comment+1, i+1
+		spans = append(spans, span)
 	}
-	stripped := strings.TrimSpace(comment[2:])
-	if len(stripped) < 1 || stripped[0] != '+' {
+	return spans
+}
+
+// continues checks that the given next line can continue a marker on the
+// given line, i.e. that it's not a marker itself, that it's indented further
+// than the line, and that it's either in the same `/* */` comment or that
+// both are `//` comments.
+func continues(line, next commentLine) bool {
+	if next.isMarker() || line.block != next.block || next.indent() <= line.indent() {
 		return false
 	}
-	return true
+	return !line.block || line.comment == next.comment
+}
+
+// MarkersIn returns the markers in the given comments of a comment group, as
+// the comments of each marker (one per line, for markers continued over
+// several `//` comments, or the `/* */` comment holding the marker), and the
+// text of the marker with its lines joined, as should be passed to
+// Registry.Lookup and Definition.Parse.
+func MarkersIn(comments []*ast.Comment) iter.Seq2[[]*ast.Comment, string] {
+	return func(yield func([]*ast.Comment, string) bool) {
+		for _, span := range markerSpans(comments) {
+			if !yield(comments[span.start:span.end], span.text) {
+				return
+			}
+		}
+	}
+}
+
+// WithoutMarkers returns the given comment group without its markers, as
+// used for documentation: `//` comments holding markers are dropped, and the
+// lines holding markers are cut out of `/* */` comments.  Returns nil if
+// the group is nil.
+func WithoutMarkers(group *ast.CommentGroup) *ast.CommentGroup {
+	if group == nil {
+		return nil
+	}
+	lines := commentLines(group.List)
+	isMarker := make([]bool, len(lines))
+	for _, span := range markerSpans(group.List) {
+		for i := span.startLine; i < span.endLine; i++ {
+			isMarker[i] = true
+		}
+	}
+
+	res := &ast.CommentGroup{List: make([]*ast.Comment, 0, len(group.List))}
+	for i := 0; i < len(lines); {
+		comment := group.List[lines[i].comment]
+		var kept []string
+		anyMarker := false
+		for ; i < len(lines) && group.List[lines[i].comment] == comment; i++ {
+			line := lines[i]
+			switch {
+			case !isMarker[i]:
+				kept = append(kept, comment.Text[line.start:line.end])
+			case line.block && line.start == 0 && line.end == len(comment.Text):
+				// a single-line block comment holding a marker
+				kept = append(kept, "/**/")
+			case line.block && line.start == 0:
+				kept = append(kept, "/*")
+			case line.block && line.end == len(comment.Text):
+				kept = append(kept, "*/")
+			}
+			anyMarker = anyMarker || isMarker[i]
+		}
+		switch {
+		case !anyMarker:
+			res.List = append(res.List, comment)
+		case strings.HasPrefix(comment.Text, "/*") && strings.Join(kept, "") != "/**/":
+			res.List = append(res.List, &ast.Comment{Slash: comment.Slash, Text: strings.Join(kept, "\n")})
+		}
+	}
+	return res
 }
 
 // markersBetween grabs the markers between the given indicies in the list of all comments.
@@ -244,12 +683,9 @@ func (v *markerVisitor) markersBetween(fromGodoc bool, start, end int) []markerC
 	}
 	var res []markerComment
 	for i := start; i < end; i++ {
-		commentGroup := v.allComments[i]
-		for _, comment := range commentGroup.List {
-			if !isMarkerComment(comment.Text) {
-				continue
-			}
-			res = append(res, markerComment{Comment: comment, fromGodoc: fromGodoc})
+		comments := v.allComments[i].List
+		for _, span := range markerSpans(comments) {
+			res = append(res, markerComment{Comment: comments[span.start], offset: span.offset, text: span.text, fromGodoc: fromGodoc})
 		}
 	}
 	return res
@@ -269,6 +705,11 @@ func (v markerSubVisitor) Visit(node ast.Node) ast.Visitor {
 		// of the block if we don't want to collect package-level markers in
 		// this block.
 
+		if _, isDecl := v.node.(*ast.GenDecl); isDecl {
+			// end of a group of types (if it's one)
+			v.groupComments, v.firstGroupComments = nil, nil
+		}
+
 		if !v.collectPackageLevel {
 			if v.commentInd < len(v.allComments) {
 				lastCommentInd := v.commentInd
@@ -353,6 +794,19 @@ func (v markerSubVisitor) Visit(node ast.Node) ast.Visitor {
 		if typedNode.Lparen != token.NoPos || typedNode.Tok != token.TYPE {
 			// not a single-line type spec, treat them as free comments
 			v.pkgMarkers = append(v.pkgMarkers, markerCommentBlock...)
+			if typedNode.Tok == token.TYPE {
+				// the type-level markers in the godoc of a group of types apply
+				// to each of them -- the others stay with the first type only,
+				// as if in its own godoc, so that they're reported once
+				v.groupComments, v.firstGroupComments = nil, nil
+				for _, marker := range docCommentBlock {
+					if v.registry.Lookup(marker.Text(), DescribesType) != nil {
+						v.groupComments = append(v.groupComments, marker)
+					} else {
+						v.firstGroupComments = append(v.firstGroupComments, marker)
+					}
+				}
+			}
 			break
 		}
 		// save these, we'll need them when we encounter the actual type spec
@@ -364,8 +818,11 @@ func (v markerSubVisitor) Visit(node ast.Node) ast.Visitor {
 		v.nodeMarkers[node] = append(v.nodeMarkers[node], v.declComments...)
 		v.nodeMarkers[node] = append(v.nodeMarkers[node], markerCommentBlock...)
 		v.nodeMarkers[node] = append(v.nodeMarkers[node], docCommentBlock...)
+		v.nodeMarkers[node] = append(v.nodeMarkers[node], v.groupComments...)
+		v.nodeMarkers[node] = append(v.nodeMarkers[node], v.firstGroupComments...)
 
 		v.declComments = nil
+		v.firstGroupComments = nil
 		v.collectPackageLevel = false // don't collect package-level inside type structs
 	case *ast.Field:
 		v.nodeMarkers[node] = append(v.nodeMarkers[node], markerCommentBlock...)
@@ -17,8 +17,15 @@ limitations under the License.
 package markers_test
 
 import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"sync"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/loader"
 	. "sigs.k8s.io/controller-tools/pkg/markers"
 )
 
@@ -27,6 +34,151 @@ type fieldPath struct {
 	field string
 }
 
+var _ = Describe("Collecting invalid markers", func() {
+	It("should report the position of the invalid argument and the expected syntax", func() {
+		reg := &Registry{}
+		mustDefine(reg, "testing:typelvl", DescribesType, 0)
+		col := &Collector{Registry: reg}
+
+		_, err := col.MarkersInPackage(fakePkg)
+		Expect(err).To(HaveOccurred())
+
+		By("type-checking the package, to know its file set")
+		fakePkg.NeedTypesInfo()
+
+		var positions []token.Position
+		for _, err := range flattenErrs(err) {
+			var posErr loader.PositionedError
+			Expect(errors.As(err, &posErr)).To(BeTrue())
+			Expect(err).To(MatchError(HavePrefix("invalid marker +testing:typelvl: ")))
+			Expect(err).To(MatchError(HaveSuffix(" (expected +testing:typelvl=<int>)")))
+			positions = append(positions, fakePkg.Fset.Position(posErr.Pos))
+		}
+		Expect(positions).To(ContainElement(SatisfyAll(
+			HaveField("Line", 94),
+			// at the quote of +testing:typelvl="here without godoc"
+			HaveField("Column", 26))))
+		Expect(positions).To(ContainElement(SatisfyAll(
+			HaveField("Filename", HaveSuffix(SidecarFile)),
+			HaveField("Line", 7),
+			// at the quote of +testing:typelvl="here in sidecar", itself quoted
+			HaveField("Column", 25))))
+	})
+})
+
+var _ = Describe("Collecting CEL expressions", func() {
+	It("should know the position of the expressions", func() {
+		reg := &Registry{}
+		mustDefine(reg, "testing:rule", DescribesType, celStruct{})
+		col := &Collector{Registry: reg}
+
+		var rule *CEL
+		Expect(EachType(col, fakePkg, func(info *TypeInfo) {
+			if val := info.Markers.Get("testing:rule"); val != nil {
+				expr := val.(celStruct).Rule
+				rule = &expr
+			}
+		})).To(Succeed())
+		Expect(rule).NotTo(BeNil())
+		Expect(rule.AST).NotTo(BeNil())
+
+		By("type-checking the package, to know its file set")
+		fakePkg.NeedTypesInfo()
+
+		pos := fakePkg.Fset.Position(rule.Pos())
+		Expect(pos.Filename).To(HaveSuffix("cel.go"))
+		Expect(pos.Line).To(Equal(4))
+		// at the quote of +testing:rule:rule="self.min <= self.max"
+		Expect(pos.Column).To(Equal(28))
+		Expect(rule.End() - rule.Pos()).To(BeEquivalentTo(len(`"self.min <= self.max"`)))
+	})
+})
+
+var _ = Describe("Collecting markers concurrently", func() {
+	It("should collect the markers of each package once", func() {
+		reg := &Registry{}
+		mustDefine(reg, "testing:typelvl", DescribesType, "")
+		col := &Collector{Registry: reg}
+
+		results := make([]map[ast.Node]MarkerValues, 8)
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Go(func() {
+				defer GinkgoRecover()
+				var err error
+				results[i], err = col.MarkersInPackage(fakePkg)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		}
+		wg.Wait()
+
+		for _, res := range results {
+			Expect(reflect.ValueOf(res).Pointer()).To(Equal(reflect.ValueOf(results[0]).Pointer()))
+		}
+		Expect(results[0]).To(ContainElement(HaveKeyWithValue("testing:typelvl", ContainElement("here on type"))))
+	})
+})
+
+var _ = Describe("Collecting markers with variables", func() {
+	type serviceStruct struct {
+		Namespace string
+		Name      string
+		Path      *string
+	}
+
+	collect := func(vars *Variables) (any, error) {
+		reg := &Registry{}
+		mustDefine(reg, "testing:service", DescribesType, serviceStruct{})
+		col := &Collector{Registry: reg, Variables: vars}
+
+		var service any
+		err := EachType(col, fakePkg, func(info *TypeInfo) {
+			if val := info.Markers.Get("testing:service"); val != nil {
+				service = val
+			}
+		})
+		return service, err
+	}
+
+	It("should interpolate the variables in string values", func() {
+		service, err := collect(&Variables{Values: map[string]string{"NAMESPACE": "system", "PREFIX": "foo"}})
+		Expect(err).NotTo(HaveOccurred())
+		path := "/${path}"
+		Expect(service).To(Equal(serviceStruct{Namespace: "system", Name: "foo-webhook", Path: &path}))
+	})
+
+	It("should resolve the variables from the environment only if asked to", func() {
+		GinkgoT().Setenv("NAMESPACE", "from-env")
+		vars := &Variables{Values: map[string]string{"PREFIX": "foo"}}
+		_, err := collect(vars)
+		Expect(err).To(MatchError(ContainSubstring(`invalid marker +testing:service: undefined variable "NAMESPACE"`)))
+
+		vars.FromEnv = true
+		service, err := collect(vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(service).To(HaveField("Namespace", "from-env"))
+	})
+
+	It("should leave the values alone without variables", func() {
+		service, err := collect(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(service).To(HaveField("Namespace", "${NAMESPACE}"))
+	})
+})
+
+// flattenErrs returns the errors of the given (possibly nested) error list.
+func flattenErrs(err error) []error {
+	errList, isList := err.(loader.ErrList)
+	if !isList {
+		return []error{err}
+	}
+	var errs []error
+	for _, err := range errList {
+		errs = append(errs, flattenErrs(err)...)
+	}
+	return errs
+}
+
 var _ = Describe("Collecting", func() {
 	var col *Collector
 	var markersByType map[string]MarkerValues
@@ -44,6 +196,7 @@ var _ = Describe("Collecting", func() {
 		mustDefine(reg, "testing:fieldlvl", DescribesField, "")
 		mustDefine(reg, "testing:eitherlvl", DescribesType, "")
 		mustDefine(reg, "testing:eitherlvl", DescribesPackage, "")
+		Expect(reg.Alias("testing:oldtypelvl", "testing:typelvl", DescribesType)).To(Succeed())
 
 		col = &Collector{Registry: reg}
 
@@ -82,6 +235,8 @@ var _ = Describe("Collecting", func() {
 			By("checking that it contains the right package-level markers")
 			Expect(pkgMarkers).To(HaveKeyWithValue("testing:pkglvl", ContainElement("here unattached")))
 			Expect(pkgMarkers).To(HaveKeyWithValue("testing:pkglvl", ContainElement("here at end after last node")))
+			Expect(pkgMarkers).To(HaveKeyWithValue("testing:pkglvl", ContainElement("here in block")))
+			Expect(pkgMarkers).To(HaveKeyWithValue("testing:pkglvl", ContainElement("here in sidecar")))
 
 			By("checking that it doesn't contain any markers it's not supposed to")
 			Expect(pkgMarkers).To(HaveKeyWithValue("testing:pkglvl", Not(ContainElement(ContainSubstring("not here")))))
@@ -196,6 +351,123 @@ foo:
 
 		})
 
+		Context("continued over several lines", func() {
+			It("should join the lines of the markers", func() {
+				Expect(markersByType).To(HaveKeyWithValue("Continued",
+					HaveKeyWithValue("testing:typelvl", ContainElement("here continued over lines"))))
+			})
+
+			It("should not continue markers onto other markers", func() {
+				Expect(markersByType).To(HaveKeyWithValue("Continued",
+					HaveKeyWithValue("testing:typelvl", ContainElements(`here not continued\`, "here after not continued"))))
+			})
+
+			It("should have docs without the continued lines", func() {
+				Expect(docsByType).To(HaveKeyWithValue("Continued", "continued godoc"))
+			})
+
+			It("should not continue markers ending in a backslash onto lines that aren't indented further", func() {
+				Expect(markersByType).To(HaveKeyWithValue("TrailingBackslash",
+					HaveKeyWithValue("testing:typelvl", ConsistOf(`here ending in a backslash\`))))
+				Expect(docsByType).To(HaveKeyWithValue("TrailingBackslash", "TrailingBackslash has a marker ending in a backslash.\nfollowed by the rest of the godoc."))
+			})
+		})
+
+		Context("in /*…*/-style comments", func() {
+			It("should associate markers on each line of the comment", func() {
+				Expect(markersByType).To(HaveKeyWithValue("InBlock",
+					HaveKeyWithValue("testing:typelvl", ConsistOf("here in block godoc", "here continued in block"))))
+				Expect(markersByType).To(HaveKeyWithValue("InSingleLineBlock",
+					HaveKeyWithValue("testing:typelvl", ConsistOf("here in single-line block"))))
+			})
+
+			It("should have docs without the markers", func() {
+				Expect(docsByType).To(HaveKeyWithValue("InBlock", "Block godoc."))
+				Expect(docsByType).To(HaveKeyWithValue("InSingleLineBlock", ""))
+			})
+
+			It("should not take lines starting with a plus but no marker name for markers", func() {
+				Expect(markersByType).To(HaveKeyWithValue("InBlockWithoutMarkers", BeEmpty()))
+				Expect(docsByType).To(HaveKeyWithValue("InBlockWithoutMarkers",
+					"InBlockWithoutMarkers has lines starting with a plus,\n+ but not followed by a marker name\n+1 either"))
+			})
+		})
+
+		Context("in groups of types", func() {
+			It("should associate markers in the godoc of the group with each type, after their own", func() {
+				Expect(markersByType).To(HaveKeyWithValue("Grouped1",
+					HaveKeyWithValue("testing:typelvl", Equal([]any{"here in grouped type", "here in group godoc"}))))
+				Expect(markersByType).To(HaveKeyWithValue("Grouped2",
+					HaveKeyWithValue("testing:typelvl", Equal([]any{"here in group godoc"}))))
+			})
+
+			It("should not duplicate other markers in the godoc of the group", func() {
+				rawMarkers, err := col.RawMarkersInPackage(fakePkg)
+				Expect(err).NotTo(HaveOccurred())
+				var inGroupGodoc []RawMarker
+				Expect(rawMarkers).To(ContainElement(HaveField("Text", ContainSubstring("not here in group godoc")), &inGroupGodoc))
+				Expect(inGroupGodoc).To(HaveLen(1))
+				Expect(inGroupGodoc[0].Target).To(Equal(DescribesType))
+
+				By("not making them package-level, as markers in godoc")
+				pkgMarkers, err := PackageMarkers(col, fakePkg)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pkgMarkers).To(HaveKeyWithValue("testing:pkglvl", Not(ContainElement("not here in group godoc"))))
+			})
+
+			It("should not associate them with types after the group", func() {
+				Expect(markersByType).To(HaveKeyWithValue("NotGrouped", BeEmpty()))
+			})
+		})
+
+		Context("from the sidecar file", func() {
+			It("should associate its markers with the named types, after the ones in the source", func() {
+				Expect(markersByType).To(HaveKeyWithValue("Annotated",
+					HaveKeyWithValue("testing:typelvl", Equal([]any{"here in source", "here in sidecar"}))))
+			})
+
+			It("should associate its markers with the named fields", func() {
+				Expect(markersByField).To(HaveKeyWithValue(fieldPath{typ: "Annotated", field: "Field"},
+					HaveKeyWithValue("testing:fieldlvl", Equal([]any{"here in sidecar"}))))
+			})
+		})
+
+		Context("using a deprecated alias", func() {
+			It("should map the values onto the new marker", func() {
+				Expect(markersByType).To(HaveKeyWithValue("Renamed",
+					HaveKeyWithValue("testing:typelvl", ContainElement("here renamed"))))
+				Expect(markersByType).To(HaveKeyWithValue("Renamed", Not(HaveKey("testing:oldtypelvl"))))
+			})
+
+			It("should warn about the deprecated name", func() {
+				Expect(fakePkg.Diagnostics()).To(ContainElement(SatisfyAll(
+					HaveField("Severity", loader.SeverityWarning),
+					HaveField("Message", ContainSubstring(`marker "testing:oldtypelvl" is deprecated, use "testing:typelvl" instead`)),
+					HaveField("Line", Not(BeZero())))))
+			})
+		})
+
+		Context("that are unknown", func() {
+			It("should warn about markers on the wrong target", func() {
+				Expect(fakePkg.Diagnostics()).To(ContainElement(SatisfyAll(
+					HaveField("Severity", loader.SeverityWarning),
+					HaveField("Marker", "testing:fieldlvl"),
+					HaveField("Message", "marker +testing:fieldlvl can't be used on a type, only on a field"))))
+			})
+
+			It("should warn about misspelled markers, suggesting the known one", func() {
+				Expect(fakePkg.Diagnostics()).To(ContainElement(SatisfyAll(
+					HaveField("Severity", loader.SeverityWarning),
+					HaveField("Message", "unknown marker +testing:TypeLvl (did you mean +testing:typelvl?)"),
+					HaveField("SuggestedFix", "use +testing:typelvl"))))
+			})
+
+			It("should not warn about markers unrelated to the known ones", func() {
+				Expect(fakePkg.Diagnostics()).NotTo(ContainElement(
+					HaveField("Message", ContainSubstring("not here"))))
+			})
+		})
+
 		It("should consider markers on the gendecl even if there are no more markers in the file", func() {
 			Expect(markersByType).To(HaveKeyWithValue("Cheese",
 				HaveKeyWithValue("testing:typelvl", ContainElement("here on typedecl with no more"))))
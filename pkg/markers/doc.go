@@ -47,6 +47,42 @@ limitations under the License.
 // Note that the first form will not properly parse nested slices, but is
 // generally convenient and is the form used in many existing markers.
 //
+// Long markers (e.g. with CEL rules) may be continued over several comment
+// lines by ending each line but the last with a backslash, and indenting the
+// continuation lines further than the marker:
+//
+//	+path:to:marker:rule="self.minReplicas <= self.replicas && \
+//	    self.replicas <= self.maxReplicas",message="..."
+//
+// The lines are joined without the backslashes and the leading spaces of the
+// continuation lines, which can't be markers themselves.  Lines that aren't
+// indented further don't continue the marker, so that values ending in a
+// backslash (e.g. patterns) keep it.
+//
+// Structs take the form
+//
+//	{field: val, field: {field: val}}
+//
+// with the fields named as in marker structs (below), so that complex markers
+// can group related arguments rather than flattening them.  Structs
+// implementing Validator are validated once parsed.
+//
+// Slices of structs let a single marker hold repeated structured values (e.g.
+// a list of match conditions), in the order they're given, in either slice
+// form:
+//
+//	{{name: a, port: 80}, {name: b, port: 443}}
+//
+//	{name: a, port: 80};{name: b, port: 443}
+//
+// A single struct is a slice of one.
+//
+// CEL expressions (the CEL type) are written like strings, and are
+// syntax-checked when the marker is parsed, so that invalid expressions are
+// reported at the marker (at the offending part of the expression) rather
+// than when applying the generated output.  Collected expressions know their
+// position in the source, for reporting later problems with them.
+//
 // Each of those argument types maps to the corresponding go type.  Pointers
 // mark optional fields (a struct tag, below, may also be used).  The empty
 // interface will match any type.
@@ -54,8 +90,9 @@ limitations under the License.
 // Struct fields may optionally be annotated with the `marker` struct tag.  The
 // first argument is a name override.  If it's left blank (or the tag isn't
 // present), the camelCase version of the name will be used.  The only
-// additional argument defined is `optional`, which marks a field as optional
-// without using a pointer.
+// additional arguments defined are `optional`, which marks a field as optional
+// without using a pointer, and `default=<val>` (which must come last), which
+// sets the value of the field when it's omitted, in the marker syntax.
 //
 // All parsed values are unmarshalled into the output type.  If any
 // non-optional fields aren't mentioned, an error will be raised unless
@@ -69,6 +106,11 @@ limitations under the License.
 // long as each describes a different construct (type, field, or package).
 // Definitions can then be looked up by passing unparsed markers.
 //
+// A marker can be renamed by registering its old name as an alias of the new
+// one with Registry.Alias.  Markers using the old name are then parsed by the
+// new definition and collected under the new name, while the Collector warns
+// about the deprecated name on the package.
+//
 // # Collection and Extraction
 //
 // Markers can be collected from a loader.Package using a Collector.  The
@@ -80,7 +122,38 @@ limitations under the License.
 // inside a some other block (e.g. a struct definition, interface definition,
 // etc) are considered package level.  Markers in a "closest non-Go comment
 // block" may also be considered package level if registered as such and no
-// identical type-level definition exists.
+// identical type-level definition exists.  Type-level markers in the Godoc of
+// a group of types (`type ( ... )`) are associated with each type of the
+// group, after the type's own markers.  Other markers there are associated
+// with the first type of the group only, as if in its Godoc.
+//
+// Markers may also be written in `/* */` comments, one per line, optionally
+// decorated with a leading `*` as in javadoc-style comments, with their name
+// right after the `+` (other lines starting with a `+` are left as prose or
+// code):
+//
+//	/**
+//	 * Foo does things.
+//	 * +path:to:marker=val
+//	 */
+//
+// They follow the same association rules as markers in `//` comments, and are
+// left out of the documentation, as with WithoutMarkers.
+//
+// Markers for code that can't be edited (e.g. generated code) may be written
+// in a sidecar file next to the Go files of the package (see SidecarFile),
+// attaching them to the package, or to its types and fields by name.  They're
+// collected as if they were written in the Go files, after the markers there.
+//
+// The Collector may interpolate variables written as ${NAME} in the string
+// values of markers (see Variables), from values given when generating rather
+// than hardcoded in the source.
+//
+// Markers failing to parse are reported at the offending argument, along with
+// the syntax they were expected to follow.  Markers that aren't registered
+// for the node they're on are ignored, since they may well be read by other
+// tools, but the Collector warns about the ones registered for other kinds of
+// nodes, and about the ones looking like typos of registered markers.
 //
 // Like loader.Package, Collector's methods are idempotent and will not
 // reperform work.
@@ -110,4 +183,8 @@ limitations under the License.
 //
 // Help is then registered into a registry as associated with the actual
 // definition, and can then be later retrieved from the registry.
+//
+// Registry.Schemas describes every registered marker, along with a
+// JSON-schema-like description of its arguments (see ArgumentSchema), e.g. for
+// editors to complete and check markers.
 package markers
@@ -111,6 +111,23 @@ var _ = BeforeSuite(func() {
 					type HasNonAsteriskDocWithYamlEmbeeded struct {
 					}
 
+					// continued godoc
+					// +testing:typelvl="here continued \
+					//     over lines"
+					// +testing:typelvl=here not continued\
+					// +testing:typelvl="here after not continued"
+					type Continued struct {
+					}
+
+					// +testing:oldtypelvl="here renamed"
+					type Renamed struct {
+					}
+
+					// +testing:fieldlvl="here on the wrong target"
+					// +testing:TypeLvl="here misspelled"
+					type Misplaced struct {
+					}
+
 					type Baz interface {
 						// +testing:pkglvl="not here in interface"
 					}
@@ -124,11 +141,17 @@ var _ = BeforeSuite(func() {
 
 					// +testing:pkglvl="here at end after last node"
 
-					/* +testing:pkglvl="not here in block" */
+					/* +testing:pkglvl="here in block" */
 
 					// +testing:typelvl="here on typedecl with no more"
 					type Cheese struct { }
 
+					// TrailingBackslash has a marker ending in a backslash.
+					// +testing:typelvl=here ending in a backslash\
+					// followed by the rest of the godoc.
+					type TrailingBackslash struct {
+					}
+
 					// ensure that we're fine if we've got an end-of-line
 					// comment that's the last comment of the file, but
 					// we still have a bit more to traverse (field list --> ident).
@@ -138,6 +161,77 @@ var _ = BeforeSuite(func() {
 						bar int // not collected
 					}
 				`,
+				"block.go": `
+					package testdata
+
+					/**
+					 * Block godoc.
+					 * +testing:typelvl="here in block godoc"
+					 * +testing:typelvl="here continued \
+					 *     in block"
+					 */
+					type InBlock struct {
+					}
+
+					/*
+					InBlockWithoutMarkers has lines starting with a plus,
+					+ but not followed by a marker name
+					+1 either
+					*/
+					type InBlockWithoutMarkers struct {
+					}
+
+					/* +testing:typelvl="here in single-line block" */
+					type InSingleLineBlock struct {
+					}
+
+					// +testing:typelvl="here in group godoc"
+					// +testing:pkglvl="not here in group godoc"
+					type (
+						// +testing:typelvl="here in grouped type"
+						Grouped1 struct {
+						}
+
+						Grouped2 struct {
+						}
+					)
+
+					type NotGrouped struct {
+					}
+				`,
+				"sidecar.go": `
+					package testdata
+
+					// +testing:typelvl="here in source"
+					type Annotated struct {
+						Field string
+					}
+				`,
+				"markers.yaml": `
+package:
+- +testing:pkglvl="here in sidecar"
+types:
+  Annotated:
+    markers:
+    - '+testing:typelvl="here in sidecar"'
+    fields:
+      Field:
+      - +testing:fieldlvl="here in sidecar"
+`,
+				"cel.go": `
+					package testdata
+
+					// +testing:rule:rule="self.min <= self.max"
+					type Validated struct {
+					}
+				`,
+				"vars.go": `
+					package testdata
+
+					// +testing:service:namespace="${NAMESPACE}",name="${PREFIX}-webhook",path="/$${path}"
+					type Interpolated struct {
+					}
+				`,
 			},
 		},
 	}
@@ -19,7 +19,9 @@ package markers
 import (
 	"bytes"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	sc "text/scanner"
@@ -100,6 +102,11 @@ const (
 	// RawType represents content that gets passed directly to the marker
 	// without any parsing. It should *only* be used with anonymous markers.
 	RawType
+	// StructType is a struct, of the form {field: val, field: val}, with its
+	// fields specified in Fields.
+	StructType
+	// CELType is a CEL expression (see CEL), written like a string.
+	CELType
 )
 
 // Argument is the type of a marker argument.
@@ -116,6 +123,17 @@ type Argument struct {
 	// ItemType is the type of the slice item for slices, and the value type
 	// for maps.
 	ItemType *Argument
+
+	// Fields are the types of the fields of structs, by argument name.
+	Fields map[string]Argument
+	// FieldNames maps the argument names of the fields of structs to the
+	// names of the fields in the Go struct.
+	FieldNames map[string]string
+
+	// Default is the value of the argument when it's omitted, in the marker
+	// syntax (as per the default option of the marker struct tag), if any.
+	// Arguments with a default are optional.
+	Default string
 }
 
 // typeString contains the internals of TypeString.
@@ -146,6 +164,19 @@ func (a Argument) typeString(out *strings.Builder) {
 		a.ItemType.typeString(out)
 	case RawType:
 		out.WriteString("<raw>")
+	case StructType:
+		out.WriteString("{")
+		for i, name := range slices.Sorted(maps.Keys(a.Fields)) {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(name)
+			out.WriteString(": ")
+			a.Fields[name].typeString(out)
+		}
+		out.WriteString("}")
+	case CELType:
+		out.WriteString("cel")
 	}
 }
 
@@ -429,16 +460,26 @@ func (a *Argument) parseString(scanner *sc.Scanner, raw string, out reflect.Valu
 	castAndSet(out, reflect.ValueOf(raw[startPos:endPos]))
 }
 
+// startsStruct checks if the scanner is at the start of a non-empty struct,
+// i.e. an open curly brace followed by a field name and a colon, as opposed
+// to the start of a delimitted slice.
+func startsStruct(scanner *sc.Scanner, raw string) bool {
+	subScanner := parserScanner(raw[scanner.Pos().Offset:], func(*sc.Scanner, string) {})
+	return subScanner.Scan() == '{' && subScanner.Scan() == sc.Ident && subScanner.Scan() == ':'
+}
+
 // parseSlice parses either of the two slice forms (curly-brace-delimitted and semicolon-separated).
 func (a *Argument) parseSlice(scanner *sc.Scanner, raw string, out reflect.Value) {
 	// slices have two supported formats, like string:
 	// - `{val, val, val}` (preferred)
 	// - `val;val;val` (legacy)
+	// slices of structs are in either form as well, i.e. `{{...}, {...}}`
+	// or `{...};{...}` (or just `{...}` for a single struct)
 	resSlice := reflect.Zero(out.Type())
 	elem := reflect.Indirect(reflect.New(out.Type().Elem()))
 
 	// preferred case
-	if peekNoSpace(scanner) == '{' {
+	if peekNoSpace(scanner) == '{' && (a.ItemType.Type != StructType || !startsStruct(scanner, raw)) {
 		// NB(directxman12): supporting delimitted slices in bare slices
 		// would require an extra look-ahead here :-/
 
@@ -511,6 +552,91 @@ func (a *Argument) parseMap(scanner *sc.Scanner, raw string, out reflect.Value)
 	castAndSet(out, resMap)
 }
 
+// parseStruct parses a struct of the form {field: val, field: val}, setting
+// the omitted fields to their defaults, and validating the result if it's a
+// Validator.
+func (a *Argument) parseStruct(scanner *sc.Scanner, raw string, out reflect.Value) {
+	if !expect(scanner, '{', "open curly brace") {
+		return
+	}
+
+	seen := make(map[string]bool, len(a.Fields))
+	for hint := peekNoSpace(scanner); hint != '}' && hint != sc.EOF; hint = peekNoSpace(scanner) {
+		if !expect(scanner, sc.Ident, "field name") {
+			return
+		}
+		argName := scanner.TokenText()
+		fieldType, known := a.Fields[argName]
+		if !known {
+			scanner.Error(scanner, fmt.Sprintf("unknown field %q", argName))
+			return
+		}
+		if seen[argName] {
+			scanner.Error(scanner, fmt.Sprintf("duplicate field %q", argName))
+			return
+		}
+		seen[argName] = true
+		if !expect(scanner, ':', "colon") {
+			return
+		}
+		fieldType.parse(scanner, raw, out.FieldByName(a.FieldNames[argName]), false /* not in a slice */)
+
+		if peekNoSpace(scanner) == '}' {
+			break
+		}
+		if !expect(scanner, ',', "comma") {
+			return
+		}
+	}
+
+	if !expect(scanner, '}', "close curly brace") {
+		return
+	}
+
+	for _, argName := range slices.Sorted(maps.Keys(a.Fields)) {
+		fieldType := a.Fields[argName]
+		switch {
+		case seen[argName]:
+		case fieldType.Default != "":
+			if err := fieldType.parseDefault(out.FieldByName(a.FieldNames[argName])); err != nil {
+				scanner.Error(scanner, fmt.Sprintf("invalid default of field %q: %v", argName, err))
+				return
+			}
+		case !fieldType.Optional:
+			scanner.Error(scanner, fmt.Sprintf("missing field %q", argName))
+			return
+		}
+	}
+
+	if validator, isValidator := out.Addr().Interface().(Validator); isValidator {
+		if err := validator.Validate(); err != nil {
+			scanner.Error(scanner, err.Error())
+		}
+	}
+}
+
+// parseDefault parses the default of the argument into the given value.
+func (a Argument) parseDefault(out reflect.Value) error {
+	var errs []error
+	scanner := parserScanner(a.Default, func(scanner *sc.Scanner, msg string) {
+		errs = append(errs, &ScannerError{Msg: msg, Pos: scanner.Position})
+	})
+	a.Parse(scanner, a.Default, out)
+	if len(errs) == 0 {
+		if tok := scanner.Scan(); tok != sc.EOF {
+			scanner.Error(scanner, fmt.Sprintf("extra content: %q", a.Default[scanner.Position.Offset:]))
+		}
+	}
+	return loader.MaybeErrList(errs)
+}
+
+// Validator is implemented by the struct types of marker arguments that
+// check that their values are valid, once parsed and defaulted.
+type Validator interface {
+	// Validate returns an error if the value isn't valid.
+	Validate() error
+}
+
 // parse functions like Parse, except that it allows passing down whether or not we're
 // already in a slice, to avoid duplicate legacy slice detection for AnyType
 func (a *Argument) parse(scanner *sc.Scanner, raw string, out reflect.Value, inSlice bool) {
@@ -634,6 +760,12 @@ func (a *Argument) parse(scanner *sc.Scanner, raw string, out reflect.Value, inS
 	case MapType:
 		// maps are {string: val, string: val, string: val}
 		a.parseMap(scanner, raw, out)
+	case StructType:
+		// structs are {field: val, field: val}
+		a.parseStruct(scanner, raw, out)
+	case CELType:
+		// CEL expressions are strings, syntax-checked
+		a.parseCEL(scanner, raw, out)
 	}
 }
 
@@ -648,6 +780,12 @@ func (a *Argument) Parse(scanner *sc.Scanner, raw string, out reflect.Value) {
 // raw reflect.Type.  It can construct arguments from the Go types
 // corresponding to any of the types listed in ArgumentType.
 func ArgumentFromType(rawType reflect.Type) (Argument, error) {
+	return argumentFromType(rawType, nil)
+}
+
+// argumentFromType functions like ArgumentFromType, except that it allows
+// passing down the struct types being constructed, to detect recursive ones.
+func argumentFromType(rawType reflect.Type, inStructs []reflect.Type) (Argument, error) {
 	if rawType == rawArgsType {
 		return Argument{
 			Type: RawType,
@@ -667,6 +805,11 @@ func ArgumentFromType(rawType reflect.Type) (Argument, error) {
 		arg.Optional = true
 	}
 
+	if rawType == celType {
+		arg.Type = CELType
+		return arg, nil
+	}
+
 	switch rawType.Kind() {
 	case reflect.String:
 		arg.Type = StringType
@@ -678,7 +821,7 @@ func ArgumentFromType(rawType reflect.Type) (Argument, error) {
 		arg.Type = BoolType
 	case reflect.Slice:
 		arg.Type = SliceType
-		itemType, err := ArgumentFromType(rawType.Elem())
+		itemType, err := argumentFromType(rawType.Elem(), inStructs)
 		if err != nil {
 			return Argument{}, fmt.Errorf("bad slice item type: %w", err)
 		}
@@ -688,11 +831,21 @@ func ArgumentFromType(rawType reflect.Type) (Argument, error) {
 		if rawType.Key().Kind() != reflect.String {
 			return Argument{}, fmt.Errorf("bad map key type: map keys must be strings")
 		}
-		itemType, err := ArgumentFromType(rawType.Elem())
+		itemType, err := argumentFromType(rawType.Elem(), inStructs)
 		if err != nil {
 			return Argument{}, fmt.Errorf("bad slice item type: %w", err)
 		}
 		arg.ItemType = &itemType
+	case reflect.Struct:
+		if slices.Contains(inStructs, rawType) {
+			return Argument{}, fmt.Errorf("recursive struct type %s", rawType)
+		}
+		arg.Type = StructType
+		var err error
+		arg.Fields, arg.FieldNames, err = structArguments(rawType, append(inStructs, rawType))
+		if err != nil {
+			return Argument{}, fmt.Errorf("bad struct type %s: %w", rawType, err)
+		}
 	default:
 		return Argument{}, fmt.Errorf("type has unsupported kind %s", rawType.Kind())
 	}
@@ -762,10 +915,39 @@ func (d *Definition) Empty() bool {
 	return len(d.Fields) == 0
 }
 
+// Syntax returns the syntax of the marker, as shown in its help, e.g.
+// `+a:b=<int>` for anonymous markers, or `+a:b:c=<string>,[d=<int>]`.
+func (d *Definition) Syntax() string {
+	out := &strings.Builder{}
+	out.WriteString("+" + d.Name)
+	if d.Empty() {
+		return out.String()
+	}
+	if d.AnonymousField() {
+		out.WriteString("=<" + d.Fields[""].TypeString() + ">")
+		return out.String()
+	}
+
+	sep := ":"
+	for _, name := range slices.Sorted(maps.Keys(d.Fields)) {
+		arg := d.Fields[name]
+		if arg.Optional {
+			fmt.Fprintf(out, "%s[%s=<%s>]", sep, name, arg.TypeString())
+		} else {
+			fmt.Fprintf(out, "%s%s=<%s>", sep, name, arg.TypeString())
+		}
+		sep = ","
+	}
+	return out.String()
+}
+
 // argumentInfo returns information about an argument field as the marker parser's field loader
 // would see it.  This can be useful if you have to interact with marker definition structs
 // externally (e.g. at compile time).
-func argumentInfo(fieldName string, tag reflect.StructTag) (argName string, optionalOpt bool) {
+//
+// The default option (e.g. `marker:"port,default=443"`) must come last, since its value
+// may contain commas.
+func argumentInfo(fieldName string, tag reflect.StructTag) (argName string, optionalOpt bool, defaultOpt string) {
 	argName = lowerCamelCase(fieldName)
 	markerTag, tagSpecified := tag.Lookup("marker")
 	markerTagParts := strings.Split(markerTag, ",")
@@ -774,13 +956,54 @@ func argumentInfo(fieldName string, tag reflect.StructTag) (argName string, opti
 		argName = markerTagParts[0]
 	}
 	optionalOpt = false
-	for _, tagOption := range markerTagParts[1:] {
+	for i, tagOption := range markerTagParts[1:] {
 		if tagOption == "optional" {
 			optionalOpt = true
 		}
+		if value, isDefault := strings.CutPrefix(tagOption, "default="); isDefault {
+			defaultOpt = strings.Join(append([]string{value}, markerTagParts[i+2:]...), ",")
+			break
+		}
 	}
 
-	return argName, optionalOpt
+	return argName, optionalOpt, defaultOpt
+}
+
+// structArguments returns the arguments of the exported fields of the given
+// struct type, by argument name, and the names of the fields by argument
+// name.
+func structArguments(structType reflect.Type, inStructs []reflect.Type) (map[string]Argument, map[string]string, error) {
+	fields := make(map[string]Argument)
+	fieldNames := make(map[string]string)
+	for field := range structType.Fields() {
+		if field.PkgPath != "" {
+			// as per the reflect package docs, pkgpath is empty for exported fields,
+			// so non-empty package path means a private field, which we should skip
+			continue
+		}
+		argName, optionalOpt, defaultOpt := argumentInfo(field.Name, field.Tag)
+
+		argType, err := argumentFromType(field.Type, inStructs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to extract type information for field %q: %w", field.Name, err)
+		}
+
+		if argType.Type == RawType {
+			return nil, nil, fmt.Errorf("RawArguments must be the direct type of a marker, and not a field")
+		}
+
+		argType.Optional = optionalOpt || argType.Optional || defaultOpt != ""
+		argType.Default = defaultOpt
+		if defaultOpt != "" {
+			if err := argType.parseDefault(reflect.New(field.Type).Elem()); err != nil {
+				return nil, nil, fmt.Errorf("invalid default of field %q: %w", field.Name, err)
+			}
+		}
+
+		fields[argName] = argType
+		fieldNames[argName] = field.Name
+	}
+	return fields, fieldNames, nil
 }
 
 // loadFields uses reflection to populate argument information from the Output type.
@@ -800,28 +1023,12 @@ func (d *Definition) loadFields() error {
 		return nil
 	}
 
-	for field := range d.Output.Fields() {
-		if field.PkgPath != "" {
-			// as per the reflect package docs, pkgpath is empty for exported fields,
-			// so non-empty package path means a private field, which we should skip
-			continue
-		}
-		argName, optionalOpt := argumentInfo(field.Name, field.Tag)
-
-		argType, err := ArgumentFromType(field.Type)
-		if err != nil {
-			return fmt.Errorf("unable to extract type information for field %q: %w", field.Name, err)
-		}
-
-		if argType.Type == RawType {
-			return fmt.Errorf("RawArguments must be the direct type of a marker, and not a field")
-		}
-
-		argType.Optional = optionalOpt || argType.Optional
-
-		d.Fields[argName] = argType
-		d.FieldNames[argName] = field.Name
+	fields, fieldNames, err := structArguments(d.Output, []reflect.Type{d.Output})
+	if err != nil {
+		return err
 	}
+	maps.Copy(d.Fields, fields)
+	maps.Copy(d.FieldNames, fieldNames)
 
 	return nil
 }
@@ -931,6 +1138,19 @@ func (d *Definition) Parse(rawMarker string) (any, error) {
 		scanner.Error(scanner, fmt.Sprintf("extra arguments provided: %q", fields[scanner.Position.Offset:]))
 	}
 
+	// omitted arguments are set to their defaults, if any
+	if len(errs) == 0 && !d.AnonymousField() {
+		for _, argName := range slices.Sorted(maps.Keys(d.Fields)) {
+			arg := d.Fields[argName]
+			if _, wasSeen := seen[argName]; wasSeen || arg.Default == "" {
+				continue
+			}
+			if err := arg.parseDefault(out.FieldByName(d.FieldNames[argName])); err != nil {
+				scanner.Error(scanner, fmt.Sprintf("invalid default of argument %q: %v", argName, err))
+			}
+		}
+	}
+
 	if d.Strict {
 		for argName, arg := range d.Fields {
 			if _, wasSeen := seen[argName]; !wasSeen && !arg.Optional {
@@ -18,6 +18,7 @@ package markers_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 	sc "text/scanner"
@@ -54,6 +55,38 @@ type CustomType struct {
 	Value any
 }
 
+type portStruct struct {
+	Number   int
+	Protocol string `marker:",default=TCP"`
+}
+
+type nestedStruct struct {
+	Name    string
+	Port    portStruct
+	Ports   []portStruct `marker:",optional"`
+	Timeout int          `marker:",default=10"`
+}
+
+type rangeStruct struct {
+	Min int
+	Max int
+}
+
+func (r rangeStruct) Validate() error {
+	if r.Min > r.Max {
+		return fmt.Errorf("min %d exceeds max %d", r.Min, r.Max)
+	}
+	return nil
+}
+
+type validatedStruct struct {
+	Range rangeStruct
+}
+
+type celStruct struct {
+	Rule CEL
+}
+
 var _ = Describe("Parsing", func() {
 	var reg *Registry
 
@@ -74,6 +107,9 @@ var _ = Describe("Parsing", func() {
 			mustDefine(reg, "testing:tripleDefined", DescribesPackage, 0)
 			mustDefine(reg, "testing:tripleDefined", DescribesField, "")
 			mustDefine(reg, "testing:tripleDefined", DescribesType, false)
+			mustDefine(reg, "testing:nested", DescribesPackage, nestedStruct{})
+			mustDefine(reg, "testing:validated", DescribesPackage, validatedStruct{})
+			mustDefine(reg, "testing:cel", DescribesPackage, celStruct{})
 
 			defn, err := MakeAnyTypeDefinition("testing:custom", DescribesPackage, CustomType{})
 			Expect(err).NotTo(HaveOccurred())
@@ -153,6 +189,51 @@ var _ = Describe("Parsing", func() {
 			It("shouldn't require any arguments to an optional-valued marker", parseTestCase{reg: &reg, raw: "+testing:allOptional", output: allOptionalStruct{}}.Run)
 		})
 
+		Context("when parsing markers with nested struct arguments", func() {
+			It("should set the omitted fields to their defaults", parseTestCase{
+				reg:    &reg,
+				raw:    `+testing:nested:name=foo,port={number: 443}`,
+				output: nestedStruct{Name: "foo", Port: portStruct{Number: 443, Protocol: "TCP"}, Timeout: 10},
+			}.Run)
+
+			It("should support slices of structs", parseTestCase{
+				reg: &reg,
+				raw: `+testing:nested:name=foo,port={number: 53, protocol: UDP},ports={{number: 1}, {protocol: UDP, number: 2}},timeout=5`,
+				output: nestedStruct{
+					Name:    "foo",
+					Port:    portStruct{Number: 53, Protocol: "UDP"},
+					Ports:   []portStruct{{Number: 1, Protocol: "TCP"}, {Number: 2, Protocol: "UDP"}},
+					Timeout: 5,
+				},
+			}.Run)
+
+			It("should error out for missing fields", func() {
+				_, err := reg.Lookup("+testing:nested", DescribesPackage).Parse(`+testing:nested:name=foo,port={protocol: UDP}`)
+				Expect(err).To(MatchError(ContainSubstring(`missing field "number"`)))
+			})
+
+			It("should error out for unknown fields", func() {
+				_, err := reg.Lookup("+testing:nested", DescribesPackage).Parse(`+testing:nested:name=foo,port={number: 1, nope: 2}`)
+				Expect(err).To(MatchError(ContainSubstring(`unknown field "nope"`)))
+			})
+
+			It("should validate the structs", func() {
+				_, err := reg.Lookup("+testing:validated", DescribesPackage).Parse(`+testing:validated:range={min: 2, max: 1}`)
+				Expect(err).To(MatchError(ContainSubstring("min 2 exceeds max 1")))
+			})
+
+			It("should describe the struct type", func() {
+				Expect(reg.Lookup("+testing:nested", DescribesPackage).Fields["port"].TypeString()).To(Equal("{number: int, protocol: string}"))
+			})
+
+			It("should reject invalid defaults when defining the marker", func() {
+				_, err := MakeDefinition("testing:badDefault", DescribesPackage, struct {
+					Port int `marker:",default=http"`
+				}{})
+				Expect(err).To(MatchError(ContainSubstring(`invalid default of field "Port"`)))
+			})
+		})
+
 		It("should support markers with multiple segments in the name", parseTestCase{reg: &reg, raw: "+testing:multi:segment=42", output: 42}.Run)
 
 		Context("when dealing with disambiguating anonymous markers", func() {
@@ -166,6 +247,73 @@ var _ = Describe("Parsing", func() {
 			It("should properly parse the field-level one", parseTestCase{reg: &reg, raw: "+testing:tripleDefined=foo", output: "foo", target: DescribesField}.Run)
 			It("should properly parse the type-level one", parseTestCase{reg: &reg, raw: "+testing:tripleDefined=true", output: true, target: DescribesType}.Run)
 		})
+
+		It("should describe the syntax of markers", func() {
+			Expect(reg.Lookup("+testing:empty", DescribesPackage).Syntax()).To(Equal("+testing:empty"))
+			Expect(reg.Lookup("+testing:multi:segment", DescribesPackage).Syntax()).To(Equal("+testing:multi:segment=<int>"))
+			Expect(reg.Lookup("+testing:allOptional", DescribesPackage).Syntax()).To(Equal("+testing:allOptional:[optInt=<*int>],[optStr=<string>]"))
+		})
+
+		It("should suggest the nearest marker to misspelled ones", func() {
+			Expect(reg.Nearest("testing:multifield")).To(Equal("testing:multiField"))
+			Expect(reg.Nearest("testing:multiFeld")).To(Equal("testing:multiField"))
+			Expect(reg.Nearest("testing:unrelated")).To(BeEmpty())
+			Expect(reg.Nearest("testing:multiField")).To(BeEmpty())
+		})
+
+		Context("when looking up deprecated aliases", func() {
+			BeforeEach(func() {
+				Expect(reg.Alias("testing:oldMultiField", "testing:multiField", DescribesPackage)).To(Succeed())
+			})
+
+			It("should resolve them to the new definition", func() {
+				def, alias := reg.LookupAlias("+testing:oldMultiField:str=foo", DescribesPackage)
+				Expect(def).To(BeIdenticalTo(reg.Lookup("+testing:multiField", DescribesPackage)))
+				Expect(alias).To(Equal("testing:oldMultiField"))
+			})
+
+			It("should only resolve them for the aliased target", func() {
+				Expect(reg.Lookup("+testing:oldMultiField:str=foo", DescribesType)).To(BeNil())
+			})
+
+			It("should not report an alias for the new name", func() {
+				_, alias := reg.LookupAlias("+testing:multiField:str=foo", DescribesPackage)
+				Expect(alias).To(BeEmpty())
+			})
+
+			It("should refuse aliasing unknown markers", func() {
+				Expect(reg.Alias("testing:old", "testing:unknown", DescribesPackage)).NotTo(Succeed())
+			})
+
+			It("should refuse shadowing existing markers", func() {
+				Expect(reg.Alias("testing:empty", "testing:multiField", DescribesPackage)).NotTo(Succeed())
+			})
+		})
+
+		Context("when parsing CEL arguments", func() {
+			It("should parse the expressions", func() {
+				val, err := reg.Lookup("+testing:cel", DescribesPackage).Parse(`+testing:cel:rule="self.min <= self.max"`)
+				Expect(err).NotTo(HaveOccurred())
+				rule := val.(celStruct).Rule
+				Expect(rule.Expression).To(Equal("self.min <= self.max"))
+				Expect(rule.AST).NotTo(BeNil())
+			})
+
+			It("should error out for invalid expressions, at the offending part", func() {
+				_, err := reg.Lookup("+testing:cel", DescribesPackage).Parse(`+testing:cel:rule="self.min <= )"`)
+				Expect(err).To(MatchError(ContainSubstring("invalid CEL expression")))
+				errs := flattenErrs(err)
+				Expect(errs).To(HaveLen(1))
+				var scanErr *ScannerError
+				Expect(errors.As(errs[0], &scanErr)).To(BeTrue())
+				// at the ) of the expression, in the arguments of the marker
+				Expect(scanErr.Pos.Offset).To(Equal(len(`rule="self.min <= `)))
+			})
+
+			It("should describe the type of CEL arguments", func() {
+				Expect(reg.Lookup("+testing:cel", DescribesPackage).Syntax()).To(Equal("+testing:cel:rule=<cel>"))
+			})
+		})
 	})
 
 	Context("of individual arguments", func() {
@@ -191,6 +339,29 @@ var _ = Describe("Parsing", func() {
 		It("should support maps", argParseTestCase{arg: Argument{Type: MapType, ItemType: &Argument{Type: StringType}}, raw: "{formal: hello, `informal`: `hi!`}", output: map[string]string{"formal": "hello", "informal": "hi!"}}.Run)
 		It("should work with empty maps (which are equal to empty lists in the output)", argParseTestCase{arg: Argument{Type: MapType, ItemType: &Argument{Type: StringType}}, raw: "{}", output: map[string]string{}}.Run)
 
+		Context("with slices of structs", func() {
+			var portsArg Argument
+			BeforeEach(func() {
+				var err error
+				portsArg, err = ArgumentFromType(reflect.TypeFor[[]portStruct]())
+				Expect(err).NotTo(HaveOccurred())
+			})
+			ports := []portStruct{{Number: 80, Protocol: "TCP"}, {Number: 53, Protocol: "UDP"}, {Number: 443, Protocol: "TCP"}}
+
+			It("should support delimitted slices, in order", func() {
+				argParseTestCase{arg: portsArg, raw: "{{number: 80}, {number: 53, protocol: UDP}, {number: 443}}", output: ports}.Run()
+			})
+			It("should support bare slices, in order", func() {
+				argParseTestCase{arg: portsArg, raw: "{number: 80};{number: 53, protocol: UDP};{number: 443}", output: ports}.Run()
+			})
+			It("should support single structs", func() {
+				argParseTestCase{arg: portsArg, raw: "{number: 80}", output: ports[:1]}.Run()
+			})
+			It("should support empty slices", func() {
+				argParseTestCase{arg: portsArg, raw: "{}", output: []portStruct(nil)}.Run()
+			})
+		})
+
 		Context("with any value", func() {
 			anyArg := Argument{Type: AnyType}
 			It("should support bare strings", argParseTestCase{arg: anyArg, raw: `some string here!`, output: "some string here!"}.Run)
@@ -281,3 +452,68 @@ func (tc argParseTestCase) Run() {
 	By("checking that it equals the expected output")
 	Expect(actualOut.Interface()).To(Equal(tc.output))
 }
+
+var _ = Describe("Describing markers", func() {
+	It("should describe the arguments and targets of every registered marker", func() {
+		reg := &Registry{}
+		mustDefine(reg, "testing:nested", DescribesType, nestedStruct{})
+		mustDefine(reg, "testing:anonymous", DescribesField, 0)
+		mustDefine(reg, "testing:anonymous", DescribesType, celStruct{})
+		mustDefine(reg, "testing:empty", DescribesPackage, struct{}{})
+		Expect(reg.Alias("testing:old", "testing:empty", DescribesPackage)).To(Succeed())
+		reg.AddHelp(reg.Lookup("+testing:empty", DescribesPackage), &DefinitionHelp{
+			DetailedHelp:        DetailedHelp{Summary: "does nothing."},
+			DeprecatedInFavorOf: new(string),
+		})
+
+		port := &ArgumentSchema{
+			Type: "object",
+			Properties: map[string]*ArgumentSchema{
+				"number":   {Type: "integer"},
+				"protocol": {Type: "string", Default: "TCP"},
+			},
+			Required: []string{"number"},
+		}
+		Expect(reg.Schemas()).To(Equal([]MarkerSchema{
+			{
+				Name:      "testing:anonymous",
+				Target:    "field",
+				Syntax:    "+testing:anonymous=<int>",
+				Arguments: &ArgumentSchema{Type: "integer"},
+			},
+			{
+				Name:   "testing:anonymous",
+				Target: "type",
+				Syntax: "+testing:anonymous:rule=<cel>",
+				Arguments: &ArgumentSchema{
+					Type:       "object",
+					Properties: map[string]*ArgumentSchema{"rule": {Type: "string", Format: "cel"}},
+					Required:   []string{"rule"},
+				},
+			},
+			{
+				Name:        "testing:empty",
+				Target:      "package",
+				Aliases:     []string{"testing:old"},
+				Description: "does nothing.",
+				Deprecated:  true,
+				Syntax:      "+testing:empty",
+			},
+			{
+				Name:   "testing:nested",
+				Target: "type",
+				Syntax: reg.Lookup("+testing:nested", DescribesType).Syntax(),
+				Arguments: &ArgumentSchema{
+					Type: "object",
+					Properties: map[string]*ArgumentSchema{
+						"name":    {Type: "string"},
+						"port":    port,
+						"ports":   {Type: "array", Items: port},
+						"timeout": {Type: "integer", Default: "10"},
+					},
+					Required: []string{"name", "port"},
+				},
+			},
+		}))
+	})
+})
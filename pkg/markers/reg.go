@@ -18,6 +18,7 @@ package markers
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -28,6 +29,7 @@ type Registry struct {
 	forType  map[string]*Definition
 	forField map[string]*Definition
 	helpFor  map[*Definition]*DefinitionHelp
+	aliases  map[TargetType]map[string]*Definition
 
 	mu       sync.RWMutex
 	initOnce sync.Once
@@ -47,6 +49,9 @@ func (r *Registry) init() {
 		if r.helpFor == nil {
 			r.helpFor = make(map[*Definition]*DefinitionHelp)
 		}
+		if r.aliases == nil {
+			r.aliases = make(map[TargetType]map[string]*Definition)
+		}
 	})
 }
 
@@ -82,6 +87,35 @@ func (r *Registry) Register(def *Definition) error {
 	return nil
 }
 
+// Alias registers oldName as a deprecated alias of the marker already
+// registered as newName for the given target.  Markers using the old name are
+// parsed by the new definition, and collecting them reports a warning pointing
+// at the replacement, so that markers can be renamed without breaking
+// existing sources at once.
+func (r *Registry) Alias(oldName, newName string, target TargetType) error {
+	r.init()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	defs := r.definitionsFor(target)
+	if defs == nil {
+		return fmt.Errorf("unknown target type %v", target)
+	}
+	def, exists := defs[newName]
+	if !exists {
+		return fmt.Errorf("cannot alias %q to unknown marker %q", oldName, newName)
+	}
+	if _, exists := defs[oldName]; exists {
+		return fmt.Errorf("cannot alias %q to %q: %q is already a marker", oldName, newName, oldName)
+	}
+	if r.aliases[target] == nil {
+		r.aliases[target] = make(map[string]*Definition)
+	}
+	r.aliases[target][oldName] = def
+	return nil
+}
+
 // AddHelp stores the given help in the registry, marking it as associated with
 // the given definition.
 func (r *Registry) AddHelp(def *Definition, help *DefinitionHelp) {
@@ -94,19 +128,48 @@ func (r *Registry) AddHelp(def *Definition, help *DefinitionHelp) {
 }
 
 // Lookup fetches the definition corresponding to the given name and target type.
+// Deprecated aliases resolve to the definition they were renamed to.
 func (r *Registry) Lookup(name string, target TargetType) *Definition {
+	def, _ := r.LookupAlias(name, target)
+	return def
+}
+
+// LookupAlias is like Lookup, but also returns the deprecated alias the
+// given marker was written with, if any.
+func (r *Registry) LookupAlias(name string, target TargetType) (def *Definition, alias string) {
 	r.init()
 
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	defs := r.definitionsFor(target)
+	if defs == nil {
+		return nil, ""
+	}
+	if def := tryAnonLookup(name, defs); def != nil {
+		return def, ""
+	}
+	name, anonName, _ := splitMarker(name)
+	aliases := r.aliases[target]
+	if def, exists := aliases[anonName]; exists {
+		return def, anonName
+	}
+	if def, exists := aliases[name]; exists {
+		return def, name
+	}
+	return nil, ""
+}
+
+// definitionsFor returns the definitions registered for the given target, or
+// nil if the target is unknown.
+func (r *Registry) definitionsFor(target TargetType) map[string]*Definition {
 	switch target {
 	case DescribesPackage:
-		return tryAnonLookup(name, r.forPkg)
+		return r.forPkg
 	case DescribesType:
-		return tryAnonLookup(name, r.forType)
+		return r.forType
 	case DescribesField:
-		return tryAnonLookup(name, r.forField)
+		return r.forField
 	default:
 		return nil
 	}
@@ -137,6 +200,60 @@ func (r *Registry) AllDefinitions() []*Definition {
 	return res
 }
 
+// maxTypoDistance is the maximum edit distance between an unknown marker
+// name and a registered one for the unknown marker to be considered a typo
+// of the registered one.
+const maxTypoDistance = 2
+
+// Nearest returns the name of the registered marker closest to the given
+// unknown marker name, if the unknown name looks like a typo of it (e.g.
+// differing in case, or by a couple of characters), or an empty string.
+func (r *Registry) Nearest(name string) string {
+	r.init()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nearest, nearestDistance := "", maxTypoDistance+1
+	for _, defs := range []map[string]*Definition{r.forPkg, r.forType, r.forField} {
+		for defName := range defs {
+			distance := editDistance(strings.ToLower(name), strings.ToLower(defName))
+			if distance > 0 && len(defName) <= 2*distance {
+				// short names are too easily close to each other
+				continue
+			}
+			if distance < nearestDistance || (distance == nearestDistance && defName < nearest) {
+				nearest, nearestDistance = defName, distance
+			}
+		}
+	}
+	if nearest == name {
+		return ""
+	}
+	return nearest
+}
+
+// editDistance returns the Levenshtein distance between the given strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
 // tryAnonLookup tries looking up the given marker as both an struct-based
 // marker and an anonymous marker, returning whichever format matches first,
 // preferring the longer (anonymous) name in case of conflicts.
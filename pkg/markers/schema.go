@@ -0,0 +1,168 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markers
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ArgumentSchema is a JSON-schema-like description of the values of a marker
+// argument, e.g. for editors to complete and check markers.
+type ArgumentSchema struct {
+	// Type is the JSON schema type of the values: integer, number, string,
+	// boolean, array or object, or empty for values of any type.
+	Type string `json:"type,omitempty"`
+	// Format refines string values: "raw" for raw arguments (see
+	// RawArguments), or "cel" for CEL expressions (see CEL).
+	Format string `json:"format,omitempty"`
+	// Description is the summary of the help of the argument, if any.
+	Description string `json:"description,omitempty"`
+	// Default is the value of the argument when it's omitted, in the marker
+	// syntax, if any.
+	Default string `json:"default,omitempty"`
+	// Items is the schema of the items of arrays.
+	Items *ArgumentSchema `json:"items,omitempty"`
+	// AdditionalProperties is the schema of the values of maps.
+	AdditionalProperties *ArgumentSchema `json:"additionalProperties,omitempty"`
+	// Properties are the schemas of the fields of structs, by name.
+	Properties map[string]*ArgumentSchema `json:"properties,omitempty"`
+	// Required are the names of the fields of structs that can't be
+	// omitted, sorted.
+	Required []string `json:"required,omitempty"`
+}
+
+// MarkerSchema describes a registered marker, and the schema of its
+// arguments.
+type MarkerSchema struct {
+	// Name is the name of the marker, without the leading `+`.
+	Name string `json:"name"`
+	// Target is the kind of node the marker is used on: package, type or
+	// field.
+	Target string `json:"target"`
+	// Aliases are the deprecated names of the marker (see Registry.Alias),
+	// sorted.
+	Aliases []string `json:"aliases,omitempty"`
+	// Description is the summary of the help of the marker, if any.
+	Description string `json:"description,omitempty"`
+	// Deprecated marks the marker as deprecated (see
+	// DefinitionHelp.DeprecatedInFavorOf).
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Syntax is the syntax of the marker, as Definition.Syntax.
+	Syntax string `json:"syntax"`
+	// Arguments is the schema of the arguments of the marker: the schema of
+	// its single value for markers with an anonymous argument (as in
+	// +name=value), or an object with a property per named argument.  It's
+	// nil for markers without arguments.
+	Arguments *ArgumentSchema `json:"arguments,omitempty"`
+}
+
+// Schema returns the schema of the values of the argument.
+func (a Argument) Schema() *ArgumentSchema {
+	res := &ArgumentSchema{Default: a.Default}
+	switch a.Type {
+	case IntType:
+		res.Type = "integer"
+	case NumberType:
+		res.Type = "number"
+	case StringType:
+		res.Type = "string"
+	case BoolType:
+		res.Type = "boolean"
+	case RawType:
+		res.Type, res.Format = "string", "raw"
+	case CELType:
+		res.Type, res.Format = "string", "cel"
+	case SliceType:
+		res.Type, res.Items = "array", a.ItemType.Schema()
+	case MapType:
+		res.Type, res.AdditionalProperties = "object", a.ItemType.Schema()
+	case StructType:
+		res.Type = "object"
+		res.Properties, res.Required = fieldSchemas(a.Fields, nil)
+	}
+	return res
+}
+
+// fieldSchemas returns the schemas of the given fields, described by the
+// given help, along with the names of the required ones.
+func fieldSchemas(fields map[string]Argument, help map[string]DetailedHelp) (map[string]*ArgumentSchema, []string) {
+	props := make(map[string]*ArgumentSchema, len(fields))
+	var required []string
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		field := fields[name]
+		props[name] = field.Schema()
+		props[name].Description = help[name].Summary
+		if !field.Optional && field.Default == "" {
+			required = append(required, name)
+		}
+	}
+	return props, required
+}
+
+// Schema returns the schema of the marker, described by the given help, if
+// any.
+func (d *Definition) Schema(help *DefinitionHelp) MarkerSchema {
+	res := MarkerSchema{
+		Name:   d.Name,
+		Target: d.Target.String(),
+		Syntax: d.Syntax(),
+	}
+	var fieldsHelp map[string]DetailedHelp
+	if help != nil {
+		res.Description = help.Summary
+		res.Deprecated = help.DeprecatedInFavorOf != nil
+		fieldsHelp = help.FieldsHelp(d)
+	}
+	switch {
+	case d.AnonymousField():
+		res.Arguments = d.Fields[""].Schema()
+		res.Arguments.Description = fieldsHelp[""].Summary
+	case !d.Empty():
+		res.Arguments = &ArgumentSchema{Type: "object"}
+		res.Arguments.Properties, res.Arguments.Required = fieldSchemas(d.Fields, fieldsHelp)
+	}
+	return res
+}
+
+// Schemas returns the schemas of all the markers of the registry, sorted by
+// name and target, e.g. for editors to complete and check markers.
+func (r *Registry) Schemas() []MarkerSchema {
+	r.init()
+
+	defs := r.AllDefinitions()
+	r.mu.RLock()
+	aliases := make(map[*Definition][]string)
+	for _, byName := range r.aliases {
+		for alias, def := range byName {
+			aliases[def] = append(aliases[def], alias)
+		}
+	}
+	r.mu.RUnlock()
+
+	res := make([]MarkerSchema, len(defs))
+	for i, def := range defs {
+		res[i] = def.Schema(r.HelpFor(def))
+		res[i].Aliases = slices.Sorted(slices.Values(aliases[def]))
+	}
+	slices.SortFunc(res, func(a, b MarkerSchema) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.Target, b.Target))
+	})
+	return res
+}
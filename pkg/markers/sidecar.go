@@ -0,0 +1,246 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// SidecarFile is the name of the file, next to the Go files of a package,
+// holding markers for the package and its types and fields, as if they were
+// written in the Go files (e.g. for generated code, which can't be edited):
+//
+//	package:
+//	- +groupName=example.com
+//	types:
+//	  Foo:
+//	    markers:
+//	    - +kubebuilder:object:root=true
+//	    fields:
+//	      Replicas:
+//	      - +kubebuilder:validation:Minimum=1
+//
+// The markers of the sidecar file come after the ones of the Go files.
+const SidecarFile = "markers.yaml"
+
+// sidecar is the content of a sidecar file.
+type sidecar struct {
+	// Package are the package-level markers.
+	Package []yaml.Node `yaml:"package"`
+	// Types are the types with markers, by name.
+	Types yaml.Node `yaml:"types"`
+}
+
+// sidecarType is the entry of a type in a sidecar file.
+type sidecarType struct {
+	// Markers are the type-level markers.
+	Markers []yaml.Node `yaml:"markers"`
+	// Fields are the fields with markers, by name, with their markers.
+	Fields yaml.Node `yaml:"fields"`
+}
+
+// associateSidecarMarkers adds the markers of the sidecar file of the given
+// package, if any, to the given markers by node.
+func (c *Collector) associateSidecarMarkers(pkg *loader.Package, nodeMarkers map[ast.Node][]markerComment) error {
+	if len(pkg.CompiledGoFiles) == 0 || len(pkg.Syntax) == 0 {
+		return nil
+	}
+	path := filepath.Join(filepath.Dir(pkg.CompiledGoFiles[0]), SidecarFile)
+	src, err := pkg.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	file, added := c.sidecarFiles[path]
+	if !added {
+		// packages failing to parse are parsed again on each call
+		file = pkg.AddFile(path, src)
+		c.sidecarFiles[path] = file
+	}
+	c.mu.Unlock()
+
+	var content sidecar
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&content); err != nil && !errors.Is(err, io.EOF) {
+		return loader.ErrFromNode(fmt.Errorf("invalid %s: %w", SidecarFile, err), position(file.Base()))
+	}
+
+	types, fields := namedNodes(pkg)
+	var errs []error
+	add := func(node ast.Node, entries []yaml.Node) {
+		for _, entry := range entries {
+			marker, err := sidecarMarker(file, src, entry)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			nodeMarkers[node] = append(nodeMarkers[node], marker)
+		}
+	}
+
+	add(pkg.Syntax[0], content.Package)
+	for typeName, entry := range mappingEntries(&content.Types) {
+		typeSpec, known := types[typeName.Value]
+		if !known {
+			errs = append(errs, loader.ErrFromNode(fmt.Errorf("unknown type %s in %s", typeName.Value, SidecarFile), sidecarPos(file, src, typeName)))
+			continue
+		}
+		var typeEntry sidecarType
+		if err := entry.Decode(&typeEntry); err != nil {
+			errs = append(errs, loader.ErrFromNode(fmt.Errorf("invalid type %s in %s: %w", typeName.Value, SidecarFile, err), sidecarPos(file, src, entry)))
+			continue
+		}
+		add(typeSpec, typeEntry.Markers)
+		for fieldName, fieldEntry := range mappingEntries(&typeEntry.Fields) {
+			field, known := fields[typeName.Value+"."+fieldName.Value]
+			if !known {
+				errs = append(errs, loader.ErrFromNode(fmt.Errorf("unknown field %s of type %s in %s", fieldName.Value, typeName.Value, SidecarFile), sidecarPos(file, src, fieldName)))
+				continue
+			}
+			var fieldMarkers []yaml.Node
+			if err := fieldEntry.Decode(&fieldMarkers); err != nil {
+				errs = append(errs, loader.ErrFromNode(fmt.Errorf("invalid field %s of type %s in %s: %w", fieldName.Value, typeName.Value, SidecarFile, err), sidecarPos(file, src, fieldEntry)))
+				continue
+			}
+			add(field, fieldMarkers)
+		}
+	}
+	return loader.MaybeErrList(errs)
+}
+
+// mappingEntries returns the keys and values of the given mapping node, in order.
+func mappingEntries(node *yaml.Node) iter.Seq2[*yaml.Node, *yaml.Node] {
+	return func(yield func(*yaml.Node, *yaml.Node) bool) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if !yield(node.Content[i], node.Content[i+1]) {
+				return
+			}
+		}
+	}
+}
+
+// sidecarMarker returns the marker of the given entry of a sidecar file.
+func sidecarMarker(file *token.File, src []byte, entry yaml.Node) (markerComment, error) {
+	pos := sidecarPos(file, src, &entry)
+	text := strings.TrimSpace(entry.Value)
+	if entry.Kind != yaml.ScalarNode || !strings.HasPrefix(text, "+") {
+		return markerComment{}, loader.ErrFromNode(fmt.Errorf("invalid marker in %s: markers must be strings starting with +", SidecarFile), pos)
+	}
+	if entry.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		// the marker starts after the quote
+		pos++
+	}
+	return markerComment{
+		Comment:   &ast.Comment{Slash: token.Pos(pos), Text: text},
+		text:      text,
+		fromGodoc: true, // explicitly attached, so never re-associated
+	}, nil
+}
+
+// sidecarPos returns the position of the given node of the given sidecar file.
+func sidecarPos(file *token.File, src []byte, node *yaml.Node) position {
+	if node.Line < 1 || node.Line > file.LineCount() {
+		return position(file.Base())
+	}
+	lineStart := file.Offset(file.LineStart(node.Line))
+	// yaml columns count runes
+	offset := lineStart
+	for column := 1; column < node.Column && offset < len(src); column++ {
+		_, size := utf8.DecodeRune(src[offset:])
+		offset += size
+	}
+	return position(file.Pos(offset))
+}
+
+// namedNodes returns the type specs of the given package by name, and the
+// fields of its struct types by type and field name (as in Type.Field).
+func namedNodes(pkg *loader.Package) (map[string]*ast.TypeSpec, map[string]*ast.Field) {
+	types := make(map[string]*ast.TypeSpec)
+	fields := make(map[string]*ast.Field)
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, isGen := decl.(*ast.GenDecl)
+			if !isGen || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				types[typeSpec.Name.Name] = typeSpec
+				structType, isStruct := typeSpec.Type.(*ast.StructType)
+				if !isStruct {
+					continue
+				}
+				for _, field := range structType.Fields.List {
+					for _, name := range fieldNames(field) {
+						fields[typeSpec.Name.Name+"."+name] = field
+					}
+				}
+			}
+		}
+	}
+	return types, fields
+}
+
+// fieldNames returns the names of the given field, which is the name of its
+// type for embedded fields.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) > 0 {
+		names := make([]string, len(field.Names))
+		for i, name := range field.Names {
+			names[i] = name.Name
+		}
+		return names
+	}
+	typ := field.Type
+	for {
+		switch typed := typ.(type) {
+		case *ast.StarExpr:
+			typ = typed.X
+		case *ast.SelectorExpr:
+			return []string{typed.Sel.Name}
+		case *ast.IndexExpr:
+			typ = typed.X
+		case *ast.IndexListExpr:
+			typ = typed.X
+		case *ast.Ident:
+			return []string{typed.Name}
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markers
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Variables are the variables interpolated in the string values of markers,
+// written as ${NAME}, so that values depending on where the code is generated
+// for (e.g. the namespace of a webhook service) needn't be hardcoded in the
+// source.  A literal ${ is written as $${.
+type Variables struct {
+	// Values are the values of the variables, by name.
+	Values map[string]string
+	// FromEnv resolves the variables without values from the environment.
+	// The environment isn't used otherwise, so that the generated output
+	// only depends on the declared values.
+	FromEnv bool
+}
+
+// lookup returns the value of the given variable, if any.
+func (v *Variables) lookup(name string) (string, bool) {
+	if val, ok := v.Values[name]; ok {
+		return val, true
+	}
+	if v.FromEnv {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+// Expand returns the given string with its variables interpolated, failing
+// on undefined variables.
+func (v *Variables) Expand(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var res strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			res.WriteString(s)
+			return res.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			// escaped, as in $${
+			res.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+		res.WriteString(s[:start])
+
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable %q", s[start:])
+		}
+		name := s[start+2 : start+end]
+		if name == "" {
+			return "", fmt.Errorf("empty variable name in %q", s)
+		}
+		val, ok := v.lookup(name)
+		if !ok {
+			return "", fmt.Errorf("undefined variable %q", name)
+		}
+		res.WriteString(val)
+		s = s[start+end+1:]
+	}
+}
+
+// interpolate returns the given marker value, with the variables in its
+// strings interpolated.
+func (v *Variables) interpolate(val any) (any, error) {
+	out := reflect.New(reflect.TypeOf(val)).Elem()
+	out.Set(reflect.ValueOf(val))
+	if err := v.expandValue(out); err != nil {
+		return nil, err
+	}
+	return out.Interface(), nil
+}
+
+// expandValue interpolates the variables in the strings of the given settable
+// value.  CEL expressions are left alone, since `$` means nothing in them.
+func (v *Variables) expandValue(val reflect.Value) error {
+	switch val.Kind() {
+	case reflect.String:
+		expanded, err := v.Expand(val.String())
+		if err != nil {
+			return err
+		}
+		val.SetString(expanded)
+	case reflect.Struct:
+		if val.Type() == celType {
+			return nil
+		}
+		for i := range val.NumField() {
+			if !val.Field(i).CanSet() {
+				continue
+			}
+			if err := v.expandValue(val.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		if !val.IsNil() {
+			return v.expandValue(val.Elem())
+		}
+	case reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		elem := reflect.New(val.Elem().Type()).Elem()
+		elem.Set(val.Elem())
+		if err := v.expandValue(elem); err != nil {
+			return err
+		}
+		val.Set(elem)
+	case reflect.Slice:
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			// raw arguments
+			return nil
+		}
+		for i := range val.Len() {
+			if err := v.expandValue(val.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			elem := reflect.New(val.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := v.expandValue(elem); err != nil {
+				return err
+			}
+			val.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"go/ast"
 	"go/token"
 	"reflect"
+	"slices"
 	"strings"
 
 	"sigs.k8s.io/controller-tools/pkg/loader"
@@ -50,14 +51,7 @@ func extractDoc(node ast.Node, decl *ast.GenDecl) string {
 	}
 
 	// filter out markers
-	var outGroup ast.CommentGroup
-	outGroup.List = make([]*ast.Comment, 0, len(docs.List))
-	for _, comment := range docs.List {
-		if isMarkerComment(comment.Text) {
-			continue
-		}
-		outGroup.List = append(outGroup.List, comment)
-	}
+	outGroup := WithoutMarkers(docs)
 	isAsteriskComment := false
 	for _, l := range outGroup.List {
 		if strings.HasPrefix(l.Text, "/*") {
@@ -75,6 +69,21 @@ func extractDoc(node ast.Node, decl *ast.GenDecl) string {
 		lines = lines[:len(lines)-1]
 	}
 
+	// javadoc-style /*…*/ comments have each line decorated with a `*`
+	decorated := isAsteriskComment && !slices.ContainsFunc(lines, func(line string) bool {
+		line = strings.TrimSpace(line)
+		return line != "" && !strings.HasPrefix(line, "*")
+	})
+	if decorated {
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(strings.TrimSpace(line), "*")
+		}
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			// e.g. the rest of the opening `/**`
+			lines = lines[1:]
+		}
+	}
+
 	outLines := make([]string, 0, len(lines))
 	var insideCodeBlock bool
 	for i, line := range lines {
@@ -0,0 +1,29 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics generates constants and collectors for the metrics exported
+// by a project, from package markers, e.g.:
+//
+//	// +kubebuilder:metric:name=apps_reconcile_errors_total,type=counter,description="Errors of reconciliations.",labels=controller;reason
+//	package metrics
+//
+// generates an AppsReconcileErrorsTotalMetric constant with the name of the
+// metric, ControllerMetricLabel and ReasonMetricLabel constants with the
+// names of its labels, and a NewAppsReconcileErrorsTotalMetric function
+// creating its Prometheus collector, with the description of the marker as help.  A
+// machine-readable catalog of the metrics is written as well, so that
+// runbooks and dashboards can be checked against the code.
+package metrics
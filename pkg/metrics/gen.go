@@ -0,0 +1,365 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	// outputFileName is the name of the generated file in each package.
+	outputFileName = "zz_generated.metrics.go"
+	// catalogFileName is the name of the catalog of the metrics.
+	catalogFileName = "metrics.yaml"
+
+	prometheusPath = "github.com/prometheus/client_golang/prometheus"
+)
+
+var metricMarker = markers.Must(markers.MakeDefinition("kubebuilder:metric", markers.DescribesPackage, Metric{}))
+
+var (
+	// metricNamePattern and labelNamePattern are the valid names of
+	// Prometheus metrics and labels.
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// collectorTypes are the Prometheus types of the collectors of each metric
+// type, without and with labels, and of their options.
+var collectorTypes = map[string]struct{ plain, vec, opts string }{
+	"counter":   {plain: "Counter", vec: "CounterVec", opts: "CounterOpts"},
+	"gauge":     {plain: "Gauge", vec: "GaugeVec", opts: "GaugeOpts"},
+	"histogram": {plain: "Histogram", vec: "HistogramVec", opts: "HistogramOpts"},
+	"summary":   {plain: "Summary", vec: "SummaryVec", opts: "SummaryOpts"},
+}
+
+// +controllertools:marker:generateHelp:category=metrics
+
+// Metric declares a metric exported by the project.
+//
+// A <Name>Metric constant is generated with the name of the metric (e.g.
+// AppsReconcileTotalMetric for apps_reconcile_total) unless a constant is set,
+// along with a <Label>MetricLabel constant per label, and a New<Name>Metric
+// function creating the Prometheus collector of the metric.
+type Metric struct {
+	// Name is the name of the metric, e.g. apps_reconcile_total.
+	Name string
+	// Type is the type of the metric: counter, gauge, histogram or summary.
+	Type string
+	// Description describes the metric, as the help of its collector.
+	Description string
+	// Labels are the names of the labels of the metric.
+	Labels []string `marker:",optional"`
+	// Constant overrides the name of the constant of the metric, minus its
+	// Metric suffix.
+	Constant string `marker:",optional"`
+}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates constants and collectors for metrics.
+//
+// The constants and collectors of the metrics declared by each package are
+// written to zz_generated.metrics.go, and a catalog of all the metrics, with
+// their types, help texts, labels and packages, to metrics.yaml.  The
+// collectors use github.com/prometheus/client_golang, which the module of the
+// packages must require.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := into.Register(metricMarker); err != nil {
+		return err
+	}
+	into.AddHelp(metricMarker, Metric{}.Help())
+	return nil
+}
+
+// metricDecl is a metric declared by a package.
+type metricDecl struct {
+	Metric
+	pkg *loader.Package
+}
+
+// catalog is the machine-readable catalog of the metrics.
+type catalog struct {
+	Metrics []catalogEntry `json:"metrics"`
+}
+
+// catalogEntry is the entry of a metric in the catalog.
+type catalogEntry struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Help    string   `json:"help"`
+	Labels  []string `json:"labels,omitempty"`
+	Package string   `json:"package"`
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	var all []metricDecl
+	valid := true
+	for _, root := range ctx.Roots {
+		metrics, ok := packageMetrics(ctx.Collector, root)
+		if !ok {
+			valid = false
+			continue
+		}
+		all = append(all, metrics...)
+	}
+	if !valid || len(all) == 0 {
+		return nil
+	}
+
+	// the names of metrics are global to the process exposing them
+	slices.SortStableFunc(all, func(a, b metricDecl) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for i := 1; i < len(all); i++ {
+		if all[i].Name == all[i-1].Name {
+			all[i].pkg.AddError(fmt.Errorf("metric %q is declared by both %s and %s", all[i].Name, all[i-1].pkg.PkgPath, all[i].pkg.PkgPath))
+			return nil
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		var metrics []metricDecl
+		for _, metric := range all {
+			if metric.pkg == root {
+				metrics = append(metrics, metric)
+			}
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, metricsFile(root, headerText, metrics))
+	}
+
+	entries := make([]catalogEntry, 0, len(all))
+	for _, metric := range all {
+		entries = append(entries, catalogEntry{
+			Name:    metric.Name,
+			Type:    metric.Type,
+			Help:    metric.Description,
+			Labels:  metric.Labels,
+			Package: metric.pkg.PkgPath,
+		})
+	}
+	return ctx.WriteYAML(catalogFileName, "", []any{catalog{Metrics: entries}})
+}
+
+// packageMetrics returns the metrics declared by the given package, with
+// their constant names, sorted by constant, and whether they're valid.
+func packageMetrics(col *markers.Collector, pkg *loader.Package) ([]metricDecl, bool) {
+	markerSet, err := markers.PackageMarkers(col, pkg)
+	if err != nil {
+		pkg.AddError(err)
+		return nil, false
+	}
+
+	ok := true
+	var metrics []metricDecl
+	byConstant := make(map[string]string)
+	for _, metricVal := range markerSet[metricMarker.Name] {
+		metric := metricVal.(Metric)
+		if err := validate(metric); err != nil {
+			pkg.AddError(err)
+			ok = false
+			continue
+		}
+		if metric.Constant == "" {
+			metric.Constant = constName(metric.Name)
+		}
+		if !isExported(metric.Constant) {
+			pkg.AddError(fmt.Errorf("constant %q of metric %q must be an exported identifier", metric.Constant, metric.Name))
+			ok = false
+			continue
+		}
+		if other, exists := byConstant[metric.Constant]; exists {
+			pkg.AddError(fmt.Errorf("metrics %q and %q have the same constant %sMetric, set the constant of either", other, metric.Name, metric.Constant))
+			ok = false
+			continue
+		}
+		byConstant[metric.Constant] = metric.Name
+		metrics = append(metrics, metricDecl{Metric: metric, pkg: pkg})
+	}
+	return metrics, ok
+}
+
+// validate checks the given metric like Prometheus would when registering
+// its collector.
+func validate(metric Metric) error {
+	if !metricNamePattern.MatchString(metric.Name) {
+		return fmt.Errorf("metric name %q is invalid: must match %s", metric.Name, metricNamePattern)
+	}
+	if _, known := collectorTypes[metric.Type]; !known {
+		return fmt.Errorf("type %q of metric %q is invalid: must be counter, gauge, histogram or summary", metric.Type, metric.Name)
+	}
+	if metric.Description == "" {
+		return fmt.Errorf("metric %q has no description", metric.Name)
+	}
+	seen := make(map[string]struct{}, len(metric.Labels))
+	for _, label := range metric.Labels {
+		if !labelNamePattern.MatchString(label) || strings.HasPrefix(label, "__") {
+			return fmt.Errorf("label name %q of metric %q is invalid: must match %s, without a __ prefix", label, metric.Name, labelNamePattern)
+		}
+		if _, duplicate := seen[label]; duplicate {
+			return fmt.Errorf("label %q of metric %q is declared twice", label, metric.Name)
+		}
+		seen[label] = struct{}{}
+	}
+	return nil
+}
+
+// constName returns the name of the constant of the given metric or label
+// name, without its suffix (e.g. AppsReconcileTotal for apps_reconcile_total).
+func constName(name string) string {
+	var out strings.Builder
+	for part := range strings.FieldsFuncSeq(name, func(r rune) bool { return r == '_' || r == ':' }) {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		out.WriteString(string(runes))
+	}
+	return out.String()
+}
+
+// isExported checks whether the given name is an exported Go identifier.
+func isExported(name string) bool {
+	for i, r := range name {
+		switch {
+		case i == 0 && !unicode.IsUpper(r):
+			return false
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_':
+			return false
+		}
+	}
+	return name != ""
+}
+
+// metricsFile returns the formatted contents of the file of the given
+// package, with the given metrics.
+func metricsFile(pkg *loader.Package, headerText string, metrics []metricDecl) []byte {
+	slices.SortFunc(metrics, func(a, b metricDecl) int {
+		return strings.Compare(a.Constant, b.Constant)
+	})
+	var labels []string
+	for _, metric := range metrics {
+		for _, label := range metric.Labels {
+			if !slices.Contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	slices.Sort(labels)
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import "%[3]s"
+
+// Names of the metrics exported by the project.
+const (
+`, pkg.Name, headerText, prometheusPath)
+	for _, metric := range metrics {
+		fmt.Fprintf(outContent, "%s\n//\n", comment(fmt.Sprintf("%sMetric is the name of the %s %s.", metric.Constant, metric.Type, metric.Name)))
+		fmt.Fprintf(outContent, "// %s\n", strings.ReplaceAll(metric.Description, "\n", "\n// "))
+		fmt.Fprintf(outContent, "%sMetric = %q\n", metric.Constant, metric.Name)
+	}
+	outContent.WriteString(")\n")
+
+	if len(labels) > 0 {
+		outContent.WriteString("\n// Names of the labels of the metrics exported by the project.\nconst (\n")
+		for _, label := range labels {
+			fmt.Fprintf(outContent, "// %sMetricLabel is the name of the %s label of metrics.\n", constName(label), label)
+			fmt.Fprintf(outContent, "%sMetricLabel = %q\n", constName(label), label)
+		}
+		outContent.WriteString(")\n")
+	}
+
+	for _, metric := range metrics {
+		collector := collectorTypes[metric.Type]
+		labelConsts := make([]string, 0, len(metric.Labels))
+		for _, label := range metric.Labels {
+			labelConsts = append(labelConsts, constName(label)+"MetricLabel")
+		}
+		opts := fmt.Sprintf("prometheus.%s{\nName: %sMetric,\nHelp: %q,\n}", collector.opts, metric.Constant, metric.Description)
+
+		doc := fmt.Sprintf("New%sMetric returns a new collector of the %s %s", metric.Constant, metric.Type, metric.Name)
+		switch len(labelConsts) {
+		case 0:
+			fmt.Fprintf(outContent, "\n%s\n", comment(doc+"."))
+			fmt.Fprintf(outContent, "func New%sMetric() prometheus.%s {\n", metric.Constant, collector.plain)
+			fmt.Fprintf(outContent, "return prometheus.New%s(%s)\n}\n", collector.plain, opts)
+			continue
+		case 1:
+			doc += ", with the " + metric.Labels[0] + " label."
+		default:
+			doc += ", with the " + joinLabels(metric.Labels) + " labels."
+		}
+		fmt.Fprintf(outContent, "\n%s\n", comment(doc))
+		fmt.Fprintf(outContent, "func New%sMetric() *prometheus.%s {\n", metric.Constant, collector.vec)
+		fmt.Fprintf(outContent, "return prometheus.New%s(%s, []string{%s})\n}\n", collector.vec, opts, strings.Join(labelConsts, ", "))
+	}
+
+	return gogen.Format(pkg, outContent.Bytes())
+}
+
+// joinLabels returns the given (several) labels in English, e.g. "a, b and
+// c".
+func joinLabels(labels []string) string {
+	return strings.Join(labels[:len(labels)-1], ", ") + " and " + labels[len(labels)-1]
+}
+
+// comment returns the given text as a comment, wrapped at 80 columns.
+func comment(text string) string {
+	var out strings.Builder
+	line := "//"
+	for word := range strings.FieldsSeq(text) {
+		if len(line)+1+len(word) > 80 && line != "//" {
+			out.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	out.WriteString(line)
+	return out.String()
+}
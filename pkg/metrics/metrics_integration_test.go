@@ -0,0 +1,44 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/metrics"
+)
+
+var _ = Describe("Metrics Generation", func() {
+	headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+
+	It("should generate metric constants, collectors and catalog from the metric markers", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{metrics.Generator{HeaderFile: headerFile}}, "./metrics/...")
+		golden.CompareFiles(GinkgoT(), "testdata", out, "metrics/zz_generated.metrics.go", "metrics.yaml")
+	})
+
+	It("should fail with invalid metrics and conflicting constants", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{metrics.Generator{}}, "./invalid/...")
+		Expect(errOut).To(ContainSubstring(`metric name "apps-errors" is invalid`))
+		Expect(errOut).To(ContainSubstring(`type "meter" of metric "apps_errors_total" is invalid`))
+		Expect(errOut).To(ContainSubstring(`label name "__reason" of metric "apps_retries_total" is invalid`))
+		Expect(errOut).To(ContainSubstring(`metrics "apps_ready" and "apps:ready" have the same constant AppsReadyMetric`))
+	})
+})
@@ -0,0 +1,22 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:metric:name=apps-errors,type=counter,description="Errors."
+// +kubebuilder:metric:name=apps_errors_total,type=meter,description="Errors."
+// +kubebuilder:metric:name=apps_retries_total,type=counter,description="Retries.",labels=__reason
+// +kubebuilder:metric:name=apps_ready,type=gauge,description="Readiness."
+// +kubebuilder:metric:name=apps:ready,type=gauge,description="Readiness."
+package invalid
@@ -0,0 +1,23 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics holds the metrics exported by the project.
+//
+// +kubebuilder:metric:name=apps_reconcile_total,type=counter,description="Total number of reconciliations per controller.",labels=controller;result
+// +kubebuilder:metric:name=apps_reconcile_duration_seconds,type=histogram,description="Duration of reconciliations.",labels=controller
+// +kubebuilder:metric:name=apps_workers,type=gauge,description="Number of running workers."
+// +kubebuilder:metric:name=apps_queue_latency_seconds,type=summary,description="Time spent in the queue,\nbefore reconciliation.",constant=QueueLatency
+package metrics
@@ -0,0 +1,129 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olm
+
+import (
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// descriptorWalker collects the descriptors of the fields of a spec or status.
+type descriptorWalker struct {
+	parser *crd.Parser
+	// visiting holds the types being walked, to stop at recursive types.
+	visiting map[crd.TypeIdent]struct{}
+}
+
+// describeFields returns the descriptors of the fields under the given field
+// of the given package, whose JSON path from the given root ("spec" or
+// "status") is prefix.
+func (w *descriptorWalker) describeFields(pkg *loader.Package, field markers.FieldInfo, root, prefix string) []descriptor {
+	pkg.NeedTypesInfo()
+	typ := pkg.TypesInfo.TypeOf(field.RawField.Type)
+	for {
+		switch underlying := typ.(type) {
+		case *types.Pointer:
+			typ = underlying.Elem()
+			continue
+		case *types.Slice:
+			if prefix != "" {
+				prefix += "[0]"
+			}
+			typ = underlying.Elem()
+			continue
+		}
+		break
+	}
+
+	named, isNamed := typ.(*types.Named)
+	if !isNamed || named.Obj().Pkg() == nil {
+		return nil
+	}
+	typePkg := pkg
+	if pkgPath := named.Obj().Pkg().Path(); pkgPath != pkg.PkgPath {
+		typePkg = pkg.Imports()[pkgPath]
+		if typePkg == nil {
+			return nil
+		}
+		w.parser.NeedPackage(typePkg)
+	}
+	ident := crd.TypeIdent{Package: typePkg, Name: named.Obj().Name()}
+	info := w.parser.Types[ident]
+	if info == nil {
+		return nil
+	}
+	if _, visiting := w.visiting[ident]; visiting {
+		return nil
+	}
+	w.visiting[ident] = struct{}{}
+	defer delete(w.visiting, ident)
+
+	var descs []descriptor
+	for _, subField := range info.Fields {
+		name := jsonName(subField)
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			// inline fields are at the level of the struct
+			descs = append(descs, w.describeFields(typePkg, subField, root, prefix)...)
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if fieldDesc, hasDesc := subField.Markers.Get(fieldMarker.Name).(FieldDescriptor); hasDesc {
+			if slices.Contains(fieldDesc.Type, root) {
+				desc := descriptor{
+					Path:         path,
+					DisplayName:  fieldDesc.DisplayName,
+					Description:  subField.Doc,
+					XDescriptors: fieldDesc.XDescriptors,
+				}
+				if desc.DisplayName == "" {
+					desc.DisplayName = displayName(subField.Name)
+				}
+				descs = append(descs, desc)
+			} else {
+				typePkg.AddError(loader.ErrFromNode(fmt.Errorf("field %s is part of the %s of the kind, but its descriptor types are %v", subField.Name, root, fieldDesc.Type), subField.RawField))
+			}
+		}
+		descs = append(descs, w.describeFields(typePkg, subField, root, path)...)
+	}
+	return descs
+}
+
+// jsonName returns the JSON name of the given field, "" if it's inline, or
+// "-" if it's skipped.
+func jsonName(field markers.FieldInfo) string {
+	tag, hasTag := field.Tag.Lookup("json")
+	name, opts, _ := strings.Cut(tag, ",")
+	if !hasTag || name == "" {
+		if field.Name == "" || slices.Contains(strings.Split(opts, ","), "inline") {
+			return ""
+		}
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,28 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package olm generates the owned CRD descriptions of an Operator Lifecycle
+// Manager ClusterServiceVersion, from markers on the kinds and their fields.
+//
+// The markers are the ones of operator-sdk, so that existing annotations keep
+// working, e.g.:
+//
+//	// +operator-sdk:csv:customresourcedefinitions:displayName="Widget App",resources={{Deployment,v1,widget-deployment}}
+//	type Widget struct { ... }
+//
+//	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Size",xDescriptors="urn:alm:descriptor:com.tectonic.ui:podCount"
+//	Size int32 `json:"size"`
+package olm
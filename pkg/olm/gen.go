@@ -0,0 +1,285 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const descriptorMarkerName = "operator-sdk:csv:customresourcedefinitions"
+
+var (
+	kindMarker  = markers.Must(markers.MakeDefinition(descriptorMarkerName, markers.DescribesType, KindDescription{}))
+	fieldMarker = markers.Must(markers.MakeDefinition(descriptorMarkerName, markers.DescribesField, FieldDescriptor{}))
+)
+
+// +controllertools:marker:generateHelp:category=olm
+
+// KindDescription customizes the owned CRD description of a kind.
+type KindDescription struct {
+	// DisplayName is the name of the kind shown in UIs.
+	//
+	// It defaults to the words of the kind, e.g. "Widget Set" for WidgetSet.
+	DisplayName string `marker:",optional"`
+	// Resources lists the kinds of the objects created for the kind, as
+	// {kind,version,name} triples, e.g. {{Deployment,v1,widget-deployment}}.
+	Resources [][]string `marker:",optional"`
+}
+
+// +controllertools:marker:generateHelp:category=olm
+
+// FieldDescriptor adds a spec or status descriptor for a field.
+//
+// The path of the descriptor is the JSON path of the field from the spec or
+// status of the kind, and its description the documentation of the field.
+type FieldDescriptor struct {
+	// Type lists the descriptors to add, "spec" or "status", which must match
+	// the part of the kind the field belongs to.
+	Type []string
+	// DisplayName is the name of the field shown in UIs.
+	//
+	// It defaults to the words of the field name, e.g. "Max Replicas" for
+	// MaxReplicas.
+	DisplayName string `marker:",optional"`
+	// XDescriptors lists the x-descriptors of the field, telling UIs how to
+	// show it, e.g. "urn:alm:descriptor:com.tectonic.ui:podCount".
+	XDescriptors []string `marker:",optional"`
+}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates the owned CRD descriptions of a ClusterServiceVersion.
+//
+// The descriptions of all versions of all kinds are written to
+// <name>.clusterserviceversion.yaml, as a ClusterServiceVersion to be used as
+// the base of a bundle (e.g. by operator-sdk generate kustomize manifests).
+type Generator struct {
+	// Name is the name of the ClusterServiceVersion, and of its file.
+	//
+	// Left unspecified, the name is left out, and the file is named
+	// clusterserviceversion.yaml.
+	Name string `marker:",optional"`
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := crdmarkers.Register(into); err != nil {
+		return err
+	}
+	if err := markers.RegisterAll(into, kindMarker, fieldMarker); err != nil {
+		return err
+	}
+	into.AddHelp(kindMarker, KindDescription{}.Help())
+	into.AddHelp(fieldMarker, FieldDescriptor{}.Help())
+	return nil
+}
+
+// clusterServiceVersion is the part of a ClusterServiceVersion generated from
+// the kinds.
+type clusterServiceVersion struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	Metadata   *objectMeta               `json:"metadata,omitempty"`
+	Spec       clusterServiceVersionSpec `json:"spec"`
+}
+
+type objectMeta struct {
+	Name string `json:"name"`
+}
+
+type clusterServiceVersionSpec struct {
+	CustomResourceDefinitions customResourceDefinitions `json:"customresourcedefinitions"`
+}
+
+type customResourceDefinitions struct {
+	Owned []crdDescription `json:"owned"`
+}
+
+// crdDescription describes a version of a kind.
+type crdDescription struct {
+	Name              string                 `json:"name"`
+	Version           string                 `json:"version"`
+	Kind              string                 `json:"kind"`
+	DisplayName       string                 `json:"displayName,omitempty"`
+	Description       string                 `json:"description,omitempty"`
+	Resources         []apiResourceReference `json:"resources,omitempty"`
+	SpecDescriptors   []descriptor           `json:"specDescriptors,omitempty"`
+	StatusDescriptors []descriptor           `json:"statusDescriptors,omitempty"`
+}
+
+// apiResourceReference is a kind of the objects created for a kind.
+type apiResourceReference struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+}
+
+// descriptor describes a field of the spec or status of a kind.
+type descriptor struct {
+	Path         string   `json:"path"`
+	DisplayName  string   `json:"displayName,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	XDescriptors []string `json:"x-descriptors,omitempty"`
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// descriptions shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	var owned []crdDescription
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(groupKind, nil)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, version := range crdRaw.Spec.Versions {
+			gv := schema.GroupVersion{Group: groupKind.Group, Version: version.Name}
+			for _, root := range ctx.Roots {
+				if parser.GroupVersions[root] != gv {
+					continue
+				}
+				info := parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}]
+				if info == nil {
+					continue
+				}
+				desc := describeKind(parser, root, info)
+				desc.Name = crdRaw.Name
+				desc.Version = version.Name
+				owned = append(owned, desc)
+			}
+		}
+	}
+	if len(owned) == 0 {
+		return nil
+	}
+	slices.SortFunc(owned, func(a, b crdDescription) int {
+		if a.Name != b.Name {
+			return strings.Compare(a.Name, b.Name)
+		}
+		return strings.Compare(a.Version, b.Version)
+	})
+
+	csv := clusterServiceVersion{
+		APIVersion: "operators.coreos.com/v1alpha1",
+		Kind:       "ClusterServiceVersion",
+		Spec: clusterServiceVersionSpec{
+			CustomResourceDefinitions: customResourceDefinitions{Owned: owned},
+		},
+	}
+	fileName := "clusterserviceversion.yaml"
+	if g.Name != "" {
+		csv.Metadata = &objectMeta{Name: g.Name}
+		fileName = g.Name + ".clusterserviceversion.yaml"
+	}
+	return ctx.WriteYAML(fileName, headerText, []any{csv})
+}
+
+// describeKind returns the description of the given kind, without its name
+// and version.
+func describeKind(parser *crd.Parser, pkg *loader.Package, info *markers.TypeInfo) crdDescription {
+	desc := crdDescription{
+		Kind:        info.Name,
+		DisplayName: displayName(info.Name),
+		Description: info.Doc,
+	}
+	if kindDesc, hasDesc := info.Markers.Get(kindMarker.Name).(KindDescription); hasDesc {
+		if kindDesc.DisplayName != "" {
+			desc.DisplayName = kindDesc.DisplayName
+		}
+		for _, resource := range kindDesc.Resources {
+			if len(resource) != 3 {
+				pkg.AddError(loader.ErrFromNode(fmt.Errorf("resources must be {kind,version,name} triples, not %v", resource), info.RawSpec))
+				continue
+			}
+			desc.Resources = append(desc.Resources, apiResourceReference{Kind: resource[0], Version: resource[1], Name: resource[2]})
+		}
+	}
+
+	w := &descriptorWalker{parser: parser, visiting: make(map[crd.TypeIdent]struct{})}
+	for _, field := range info.Fields {
+		switch jsonName(field) {
+		case "spec":
+			desc.SpecDescriptors = w.describeFields(pkg, field, "spec", "")
+		case "status":
+			desc.StatusDescriptors = w.describeFields(pkg, field, "status", "")
+		}
+	}
+	return desc
+}
+
+// displayName returns the words of the given Go identifier, e.g. "Max
+// Replicas" for MaxReplicas, or "HTTP Port" for HTTPPort.
+func displayName(name string) string {
+	runes := []rune(name)
+	var words strings.Builder
+	for i, char := range runes {
+		if i > 0 && unicode.IsUpper(char) &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+			words.WriteRune(' ')
+		}
+		if i == 0 {
+			char = unicode.ToUpper(char)
+		}
+		words.WriteRune(char)
+	}
+	return words.String()
+}
@@ -0,0 +1,39 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olm_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/olm"
+)
+
+var _ = Describe("OLM Generation", func() {
+	It("should generate the owned CRD descriptions of the kinds", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{olm.Generator{Name: "widget-operator"}}, "./api/...")
+		golden.Compare(GinkgoT(), filepath.Join("testdata", "config", "manifests"), out)
+	})
+
+	It("should fail with descriptors of the wrong part of the kind", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{olm.Generator{}}, "./invalid/...")
+		Expect(errOut).To(ContainSubstring("field Ready is part of the spec of the kind"))
+	})
+})
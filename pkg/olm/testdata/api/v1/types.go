@@ -0,0 +1,116 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WidgetSpec defines the desired state of a widget.
+type WidgetSpec struct {
+	// Size is the number of widget pods.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podCount"
+	Size int32 `json:"size"`
+
+	// HTTPPort is the port the widgets listen on.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number","urn:alm:descriptor:com.tectonic.ui:advanced"}
+	HTTPPort int32 `json:"httpPort,omitempty"`
+
+	// Storage configures the storage of the widgets.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage Settings"
+	Storage *StorageSpec `json:"storage,omitempty"`
+
+	// Sidecars are added to the widget pods.
+	Sidecars []Sidecar `json:"sidecars,omitempty"`
+
+	// Paused isn't described.
+	Paused bool `json:"paused,omitempty"`
+
+	Tuning `json:",inline"`
+}
+
+// StorageSpec configures the storage of the widgets.
+type StorageSpec struct {
+	// StorageClassName is the storage class of the volumes.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:io.kubernetes:StorageClass"
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+// Sidecar is a container added to the widget pods.
+type Sidecar struct {
+	// Image is the image of the sidecar.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Image string `json:"image"`
+
+	// Next is the sidecar started after this one.
+	Next *Sidecar `json:"next,omitempty"`
+}
+
+// Tuning holds the tuning settings of the widgets.
+type Tuning struct {
+	// MaxConnections caps the connections of each widget.
+	// +operator-sdk:csv:customresourcedefinitions:type={spec,status}
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+}
+
+// WidgetStatus defines the observed state of a widget.
+type WidgetStatus struct {
+	// Nodes are the nodes running the widgets.
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Nodes",xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses"
+	Nodes []string `json:"nodes,omitempty"`
+
+	// Conditions are the conditions of the widget.
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	Tuning `json:",inline"`
+}
+
+// Widget is an app made of widgets.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +operator-sdk:csv:customresourcedefinitions:displayName="Widget App",resources={{Deployment,v1,widget-deployment},{Service,v1,widget-service}}
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// WidgetList contains a list of widgets.
+// +kubebuilder:object:root=true
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
+
+// GadgetSet is a set of gadgets, without descriptors.
+// +kubebuilder:object:root=true
+type GadgetSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GadgetSetSpec `json:"spec,omitempty"`
+}
+
+// GadgetSetSpec defines the desired state of a gadget set.
+type GadgetSetSpec struct {
+	// Replicas is the number of gadgets.
+	Replicas int32 `json:"replicas"`
+}
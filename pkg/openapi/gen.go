@@ -0,0 +1,191 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/gengo/v2"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/parser"
+	"k8s.io/kube-openapi/cmd/openapi-gen/args"
+	"k8s.io/kube-openapi/pkg/generators"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	// defaultOutputPackage is the default package of the definitions,
+	// relative to the directory containing the API packages.
+	defaultOutputPackage = "openapi"
+	// outputFileName is the name of the generated file.
+	outputFileName = "zz_generated.openapi.go"
+	// openAPITag is the tag marking the packages and types openapi-gen
+	// generates definitions for.
+	openAPITag = "+k8s:openapi-gen=true"
+	// generatedBy is the comment marking the generated file as such, like the
+	// other generated files (rather than naming the running binary).
+	generatedBy = "// Code generated by controller-gen. DO NOT EDIT."
+)
+
+// apimachineryPackages are the packages of apimachinery with OpenAPI
+// definitions, which are referenced by most API types (e.g. ObjectMeta).
+var apimachineryPackages = []string{
+	"k8s.io/apimachinery/pkg/api/resource",
+	"k8s.io/apimachinery/pkg/apis/meta/v1",
+	"k8s.io/apimachinery/pkg/runtime",
+	"k8s.io/apimachinery/pkg/util/intstr",
+	"k8s.io/apimachinery/pkg/version",
+}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates OpenAPI definitions, in the style of openapi-gen, for
+// the types of the API packages.
+//
+// The definitions are written to zz_generated.openapi.go, as a
+// GetOpenAPIDefinitions function for the OpenAPI configuration of aggregated
+// API servers.  All types of the API packages get definitions, unless marked
+// with +k8s:openapi-gen=false, along with the types of apimachinery they
+// refer to (like ObjectMeta).
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+
+	// OutputPackage specifies the package to generate the definitions in,
+	// relative to the directory containing the API packages.  Defaults to
+	// "openapi", e.g. api/openapi for the packages api/v1 and api/v2.
+	OutputPackage string `marker:",optional"`
+
+	// ViolationsReport specifies a file to report violations of the API
+	// rules of openapi-gen to (e.g. lists without a list type), relative to
+	// the current directory.  Violations aren't reported by default.
+	ViolationsReport string `marker:",optional"`
+}
+
+// ExclusiveRun is true, since openapi-gen is run on gengo, whose parser isn't
+// threadsafe.
+func (Generator) ExclusiveRun() bool {
+	return true
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crd.RegisterGroupVersionMarkers(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	var roots []*loader.Package
+	for _, root := range ctx.Roots {
+		pkgMarkers, err := markers.PackageMarkers(ctx.Collector, root)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		if crd.GroupVersionForPackage(pkgMarkers, root).Empty() {
+			// not an API package
+			continue
+		}
+		roots = append(roots, root)
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+
+	headerFilePath, cleanup, err := ctx.GoHeaderFile(g.HeaderFile)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	boilerplate, err := gengo.GoBoilerplate(headerFilePath, gengo.StdBuildTag, generatedBy)
+	if err != nil {
+		return err
+	}
+
+	arguments := args.New()
+	arguments.OutputFile = outputFileName
+	arguments.OutputDir, arguments.OutputPkg = outputPackage(roots, g.OutputPackage)
+	arguments.ReportFilename = g.ViolationsReport
+	if arguments.ReportFilename == "" {
+		arguments.ReportFilename = os.DevNull
+	}
+	if err := arguments.Validate(); err != nil {
+		return err
+	}
+
+	// The following code is based on the main function of openapi-gen.
+	pkgPaths := make([]string, 0, len(roots))
+	for _, root := range roots {
+		pkgPaths = append(pkgPaths, root.PkgPath)
+	}
+	slices.Sort(pkgPaths)
+	p := parser.NewWithOptions(parser.Options{BuildTags: []string{gengo.StdBuildTag}})
+	if err := p.LoadPackages(append(pkgPaths, apimachineryPackages...)...); err != nil {
+		return fmt.Errorf("failed making a parser: %w", err)
+	}
+	c, err := generator.NewContext(p, generators.NameSystems(), generators.DefaultNameSystem())
+	if err != nil {
+		return fmt.Errorf("failed making a context: %w", err)
+	}
+	// check the definitions instead of writing them in verify mode
+	c.FileTypes = ctx.VerifiableFileTypes(c.FileTypes)
+	for _, pkgPath := range pkgPaths {
+		pkg, ok := c.Universe[pkgPath]
+		if !ok {
+			return fmt.Errorf("package %q not found in universe", pkgPath)
+		}
+		// openapi-gen only generates definitions for tagged packages
+		pkg.Comments = append(pkg.Comments, openAPITag)
+	}
+
+	targets := generators.GetOpenAPITargets(c, arguments, boilerplate)
+	if err := c.ExecuteTargets(targets); err != nil {
+		return fmt.Errorf("failed executing generator: %w", err)
+	}
+	return nil
+}
+
+// outputPackage returns the directory and import path of the given package,
+// relative to the deepest directory containing the given API packages (or of
+// the default package, if empty).
+func outputPackage(roots []*loader.Package, rel string) (string, string) {
+	if rel == "" {
+		rel = defaultOutputPackage
+	}
+	dir, pkgPath := filepath.Dir(roots[0].Dir), path.Dir(roots[0].PkgPath)
+	for _, root := range roots[1:] {
+		for !isWithin(filepath.Dir(root.Dir), dir, string(filepath.Separator)) {
+			dir = filepath.Dir(dir)
+		}
+		for !isWithin(path.Dir(root.PkgPath), pkgPath, "/") {
+			pkgPath = path.Dir(pkgPath)
+		}
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), path.Join(pkgPath, rel)
+}
+
+// isWithin checks if the given path is the given parent, or within it.
+func isWithin(p, parent, separator string) bool {
+	return p == parent || strings.HasPrefix(p, strings.TrimSuffix(parent, separator)+separator)
+}
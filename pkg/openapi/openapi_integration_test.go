@@ -0,0 +1,81 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi_test
+
+import (
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/controller-tools/pkg/openapi"
+)
+
+const generatedFile = "api/openapi/zz_generated.openapi.go"
+
+var _ = Describe("OpenAPI Generation", func() {
+	var originalCWD string
+
+	BeforeEach(func() {
+		By("copying the testdata to a temporary directory")
+		tmpDir := GinkgoT().TempDir()
+		Expect(os.CopyFS(tmpDir, os.DirFS("./testdata"))).To(Succeed())
+
+		By("switching into the copy to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		originalCWD = cwd
+		DeferCleanup(os.Chdir, originalCWD)
+		Expect(os.Chdir(tmpDir)).To(Succeed()) // go modules are directory-sensitive
+	})
+
+	It("should generate the OpenAPI definitions of the types of the API packages", func() {
+		Expect(os.Remove(generatedFile)).To(Succeed())
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("openapi", markers.DescribesPackage, openapi.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			"openapi:violationsReport=violations.txt,headerFile=" + path.Join(originalCWD, "../../hack/boilerplate/boilerplate.generatego.txt"),
+			"paths=./api/...",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: genall.OutputToNothing}
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "Generator should run without errors")
+
+		By("comparing the generated definitions to the checked in ones")
+		actual, err := os.ReadFile(generatedFile)
+		Expect(err).NotTo(HaveOccurred())
+		golden.CompareFile(GinkgoT(), path.Join(originalCWD, "testdata", generatedFile), actual)
+
+		By("checking that the API rule violations of the API types were reported")
+		violations, err := os.ReadFile("violations.txt")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(violations)).NotTo(ContainSubstring("testdata.kubebuilder.io"))
+	})
+	It("should fail on missing header files", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), ".", []genall.Generator{openapi.Generator{HeaderFile: "missing.txt"}}, "./api/...")
+		Expect(errOut).To(ContainSubstring("missing.txt: no such file or directory"))
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:object:generate=true
+// +groupName=widgets.testdata.kubebuilder.io
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// WidgetSpec is the spec of a widget.
+type WidgetSpec struct {
+	// Replicas is the number of replicas of the widget.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Image is the image of the widget.
+	Image string `json:"image"`
+	// Memory is the memory of each replica of the widget.
+	// +optional
+	Memory resource.Quantity `json:"memory,omitempty"`
+	// Port is the port the widget listens on, by number or name.
+	// +optional
+	Port intstr.IntOrString `json:"port,omitempty"`
+	// Tags are the tags of the widget.
+	// +listType=set
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// WidgetStatus is the status of a widget.
+type WidgetStatus struct {
+	// Conditions are the conditions of the widget.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Widget is an app made of widgets.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WidgetList is a list of widgets.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
+
+// WidgetCache is an internal type, without a definition.
+// +k8s:openapi-gen=false
+type WidgetCache struct {
+	Widgets map[string]Widget `json:"widgets"`
+}
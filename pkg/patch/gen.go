@@ -0,0 +1,130 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing declarations, we only see hand-written ones.
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.patch.go"
+
+// operationType is the name of the (unexported) type of the operations of
+// the JSON patches of a package.
+const operationType = "jsonPatchOperation"
+
+// +controllertools:marker:generateHelp
+
+// Generator generates typed patch helpers of kinds.
+//
+// The helpers of the kinds of each package are written to
+// zz_generated.patch.go, as a <Kind>MergePatch function returning the JSON
+// merge patch (RFC 7386) turning an original object into a modified one, and
+// a <Kind>JSONPatch builder of JSON patches (RFC 6902), created with
+// New<Kind>JSONPatch, with Set and Remove methods for the fields of the spec
+// and the status of the kind, and a TestResourceVersion method for
+// optimistic locking.  Both give the data of patches, to be sent with the
+// corresponding patch type (e.g. with controller-runtime's client.RawPatch).
+// Kinds for which any of these names already exist are skipped.
+//
+// The merge patches are computed with gopkg.in/evanphx/json-patch.v4, which
+// the API packages' module must require.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// patches shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	kindsByPkg := make(map[*loader.Package][]string)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		for _, root := range ctx.Roots {
+			if parser.GroupVersions[root].Group != groupKind.Group {
+				continue
+			}
+			if parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}] == nil {
+				continue
+			}
+			kindsByPkg[root] = append(kindsByPkg[root], groupKind.Kind)
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		kinds := kindsByPkg[root]
+		if len(kinds) == 0 {
+			continue
+		}
+		slices.Sort(kinds)
+		if obj := root.Types.Scope().Lookup(operationType); obj != nil {
+			root.AddError(fmt.Errorf("cannot generate the patch helpers: %s is already declared at %s", operationType, root.Fset.Position(obj.Pos())))
+			continue
+		}
+
+		file := newPatchFile(root)
+		for _, kind := range kinds {
+			file.generateHelpers(parser, kind)
+		}
+		if outContents := file.contents(headerText); outContents != nil {
+			gogen.WriteOut(ctx, root, outputFileName, outContents)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// jsonPatchPath is the package computing JSON merge patches, which the API
+// packages' module must require.
+const jsonPatchPath = "gopkg.in/evanphx/json-patch.v4"
+
+// patchFile collects the generated patch helpers of a single package.
+type patchFile struct {
+	pkg     *loader.Package
+	imports *gogen.Imports
+	out     *bytes.Buffer
+}
+
+func newPatchFile(pkg *loader.Package) *patchFile {
+	return &patchFile{
+		pkg:     pkg,
+		imports: gogen.NewImports(pkg.Name),
+		out:     new(bytes.Buffer),
+	}
+}
+
+// qualifier returns the package qualifier to use for types from the given
+// package, importing it as needed.
+func (f *patchFile) qualifier(pkg *types.Package) string {
+	if pkg == f.pkg.Types {
+		return ""
+	}
+	return f.imports.NeedImport(loader.NonVendorPath(pkg.Path()), pkg.Name())
+}
+
+// typeName returns the syntax for referring to the given type in the file.
+func (f *patchFile) typeName(typ types.Type) string {
+	return types.TypeString(typ, f.qualifier)
+}
+
+// generateHelpers generates the patch helpers of the given kind, unless any
+// of their names already exist.
+func (f *patchFile) generateHelpers(parser *crd.Parser, kind string) {
+	mergePatch, jsonPatch := kind+"MergePatch", kind+"JSONPatch"
+	scope := f.pkg.Types.Scope()
+	for _, name := range []string{mergePatch, jsonPatch, "New" + jsonPatch} {
+		if scope.Lookup(name) != nil {
+			return
+		}
+	}
+	jsonPkg := f.imports.NeedImport("encoding/json", "json")
+	jsonPatchPkg := f.imports.NeedImport(jsonPatchPath, "jsonpatch")
+
+	c := &gogen.CodeWriter{Out: f.out}
+	c.Line("")
+	c.Line(comment(fmt.Sprintf("%s returns the JSON merge patch (RFC 7386) turning the given original %s into the modified one, to be sent with the merge patch type.", mergePatch, kind)))
+	c.Linef(`func %[1]s(original, modified *%[2]s) ([]byte, error) {
+	originalJSON, err := %[3]s.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	modifiedJSON, err := %[3]s.Marshal(modified)
+	if err != nil {
+		return nil, err
+	}
+	return %[4]s.CreateMergePatch(originalJSON, modifiedJSON)
+}`, mergePatch, kind, jsonPkg, jsonPatchPkg)
+
+	c.Line("")
+	c.Line(comment(fmt.Sprintf("%s builds a JSON patch (RFC 6902) of a %s, to be sent with the JSON patch type.  Its data is given by json.Marshal.", jsonPatch, kind)))
+	c.Linef(`type %[1]s struct {
+	operations []%[2]s
+}`, jsonPatch, operationType)
+
+	c.Line("")
+	c.Line(comment(fmt.Sprintf("New%s returns a builder of an empty JSON patch of a %s.", jsonPatch, kind)))
+	c.Linef(`func New%[1]s() *%[1]s {
+	return &%[1]s{operations: []%[2]s{}}
+}`, jsonPatch, operationType)
+
+	c.Line("")
+	c.Line(comment(fmt.Sprintf("TestResourceVersion makes the patch fail unless the %s has the given resource version, for optimistic locking.", kind)))
+	f.writeOperation(c, jsonPatch, "TestResourceVersion", "resourceVersion string", "test", "/metadata/resourceVersion", "resourceVersion")
+
+	for _, part := range patchFields(parser, f.pkg, kind) {
+		if part.name != "Spec" && part.name != "Status" {
+			continue
+		}
+		named, isNamed := part.typ.(*types.Named)
+		if !isNamed {
+			continue
+		}
+		partPkg := f.pkg
+		if named.Obj().Pkg() != f.pkg.Types {
+			partPkg = f.pkg.Imports()[named.Obj().Pkg().Path()]
+		}
+		if partPkg == nil {
+			continue
+		}
+		for _, field := range patchFields(parser, partPkg, named.Obj().Name()) {
+			fieldPath := "/" + pointerToken(part.jsonName) + "/" + pointerToken(field.jsonName)
+			c.Line("")
+			c.Line(comment(fmt.Sprintf("Set%s%s sets the %s field of the %s of the %s.", part.name, field.name, field.jsonName, part.jsonName, kind)))
+			f.writeOperation(c, jsonPatch, "Set"+part.name+field.name, "value "+f.typeName(field.typ), "add", fieldPath, "value")
+			if !field.optional {
+				continue
+			}
+			c.Line("")
+			c.Line(comment(fmt.Sprintf("Remove%s%s removes the %s field of the %s of the %s, which must be set.", part.name, field.name, field.jsonName, part.jsonName, kind)))
+			f.writeOperation(c, jsonPatch, "Remove"+part.name+field.name, "", "remove", fieldPath, "")
+		}
+	}
+
+	c.Linef(`
+// MarshalJSON returns the data of the patch.
+func (p *%[1]s) MarshalJSON() ([]byte, error) {
+	return %[2]s.Marshal(p.operations)
+}`, jsonPatch, jsonPkg)
+}
+
+// writeOperation writes the given method of the given JSON patch builder,
+// with the given parameters, adding an operation with the given type, path
+// and value (if any).
+func (f *patchFile) writeOperation(c *gogen.CodeWriter, jsonPatch, method, params, op, opPath, value string) {
+	operation := fmt.Sprintf("%s{Op: %q, Path: %q}", operationType, op, opPath)
+	if value != "" {
+		operation = fmt.Sprintf("%s{Op: %q, Path: %q, Value: %s}", operationType, op, opPath, value)
+	}
+	c.Linef(`func (p *%[1]s) %[2]s(%[3]s) *%[1]s {
+	p.operations = append(p.operations, %[4]s)
+	return p
+}`, jsonPatch, method, params, operation)
+}
+
+// comment returns the given text as a comment, wrapped at 80 columns.
+func comment(text string) string {
+	var out strings.Builder
+	line := "//"
+	for word := range strings.FieldsSeq(text) {
+		if len(line)+1+len(word) > 80 && line != "//" {
+			out.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	out.WriteString(line)
+	return out.String()
+}
+
+// pointerToken returns the given JSON field name as a token of a JSON
+// pointer (RFC 6901).
+func pointerToken(jsonName string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(jsonName)
+}
+
+// patchField is a field set by JSON patches.
+type patchField struct {
+	name     string
+	jsonName string
+	typ      types.Type
+	// optional indicates that the field can be removed.
+	optional bool
+}
+
+// patchFields returns the serialized fields of the given struct type of the
+// given package, including the ones promoted from embedded structs.
+func patchFields(parser *crd.Parser, pkg *loader.Package, typeName string) []patchField {
+	info := parser.Types[crd.TypeIdent{Package: pkg, Name: typeName}]
+	if info == nil {
+		return nil
+	}
+	var fields []patchField
+	for _, field := range info.Fields {
+		jsonName, jsonOpts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		typ := pkg.TypesInfo.TypeOf(field.RawField.Type)
+		if field.Name == "" {
+			// inlined structs are patched through the fields they promote
+			if named, isNamed := typ.(*types.Named); isNamed && jsonName == "" {
+				embeddedPkg := pkg
+				if named.Obj().Pkg() != pkg.Types {
+					embeddedPkg = pkg.Imports()[named.Obj().Pkg().Path()]
+				}
+				if embeddedPkg != nil {
+					fields = append(fields, patchFields(parser, embeddedPkg, named.Obj().Name())...)
+				}
+			}
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		opts := strings.Split(jsonOpts, ",")
+		_, isPointer := typ.(*types.Pointer)
+		fields = append(fields, patchField{
+			name:     field.Name,
+			jsonName: jsonName,
+			typ:      typ,
+			optional: isPointer || slices.Contains(opts, "omitempty") || slices.Contains(opts, "omitzero"),
+		})
+	}
+	return fields
+}
+
+// contents returns the formatted contents of the file, or nil if no helper
+// was generated for the package.
+func (f *patchFile) contents(headerText string) []byte {
+	if f.out.Len() == 0 {
+		return nil
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[3]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+%[2]s
+)
+
+// %[4]s is an operation of a JSON patch (RFC 6902).
+type %[4]s struct {
+	Op    string `+"`"+`json:"op"`+"`"+`
+	Path  string `+"`"+`json:"path"`+"`"+`
+	Value any    `+"`"+`json:"value,omitempty"`+"`"+`
+}
+`, f.pkg.Name, f.imports.Block(), headerText, operationType)
+	outContent.Write(f.out.Bytes())
+
+	return gogen.Format(f.pkg, outContent.Bytes())
+}
@@ -0,0 +1,40 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/patch"
+)
+
+var _ = Describe("Patch Generation", func() {
+	headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+
+	It("should generate patch helpers for the kinds of the package, except hand-written ones", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{patch.Generator{HeaderFile: headerFile}}, "./api/...")
+		golden.CompareFiles(GinkgoT(), "testdata", out, "api/v1/zz_generated.patch.go")
+	})
+	It("should fail on packages already declaring the types of the patch helpers", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{patch.Generator{}}, "./invalid/...")
+		Expect(errOut).To(ContainSubstring("cannot generate the patch helpers: jsonPatchOperation is already declared at"))
+	})
+})
@@ -0,0 +1,89 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +groupName=batch.testdata.kubebuilder.io
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CommonSpec holds the fields shared by specs.
+type CommonSpec struct {
+	// Paused stops the reconciliation of the object.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// CronJobSpec is the spec of a cron job.
+type CronJobSpec struct {
+	CommonSpec `json:",inline"`
+
+	// Schedule is the schedule of the job, in cron format.
+	Schedule string `json:"schedule"`
+	// StartingDeadlineSeconds is the deadline of missed jobs.
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// Template is the template of the pods.
+	// +optional
+	Template corev1.PodTemplateSpec `json:"template,omitzero"`
+	// Internal isn't serialized.
+	Internal string `json:"-"`
+}
+
+// CronJobStatus is the status of a cron job.
+type CronJobStatus struct {
+	// LastScheduleTime is when the job was last scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// Active are the running jobs.
+	// +optional
+	Active []corev1.ObjectReference `json:"active,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CronJob runs jobs on a schedule.
+type CronJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CronJobSpec   `json:"spec,omitempty"`
+	Status CronJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// Schedule is a cluster-scoped kind without spec nor status.
+type Schedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Job has a hand-written merge patch function, so isn't generated.
+type Job struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// JobMergePatch returns the merge patch of a job.
+func JobMergePatch(original, modified *Job) ([]byte, error) {
+	return nil, nil
+}
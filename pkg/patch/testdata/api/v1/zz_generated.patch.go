@@ -0,0 +1,184 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"encoding/json"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// jsonPatchOperation is an operation of a JSON patch (RFC 6902).
+type jsonPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// CronJobMergePatch returns the JSON merge patch (RFC 7386) turning the given
+// original CronJob into the modified one, to be sent with the merge patch type.
+func CronJobMergePatch(original, modified *CronJob) ([]byte, error) {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	modifiedJSON, err := json.Marshal(modified)
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
+}
+
+// CronJobJSONPatch builds a JSON patch (RFC 6902) of a CronJob, to be sent with
+// the JSON patch type. Its data is given by json.Marshal.
+type CronJobJSONPatch struct {
+	operations []jsonPatchOperation
+}
+
+// NewCronJobJSONPatch returns a builder of an empty JSON patch of a CronJob.
+func NewCronJobJSONPatch() *CronJobJSONPatch {
+	return &CronJobJSONPatch{operations: []jsonPatchOperation{}}
+}
+
+// TestResourceVersion makes the patch fail unless the CronJob has the given
+// resource version, for optimistic locking.
+func (p *CronJobJSONPatch) TestResourceVersion(resourceVersion string) *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "test", Path: "/metadata/resourceVersion", Value: resourceVersion})
+	return p
+}
+
+// SetSpecPaused sets the paused field of the spec of the CronJob.
+func (p *CronJobJSONPatch) SetSpecPaused(value bool) *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "add", Path: "/spec/paused", Value: value})
+	return p
+}
+
+// RemoveSpecPaused removes the paused field of the spec of the CronJob, which
+// must be set.
+func (p *CronJobJSONPatch) RemoveSpecPaused() *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "remove", Path: "/spec/paused"})
+	return p
+}
+
+// SetSpecSchedule sets the schedule field of the spec of the CronJob.
+func (p *CronJobJSONPatch) SetSpecSchedule(value string) *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "add", Path: "/spec/schedule", Value: value})
+	return p
+}
+
+// SetSpecStartingDeadlineSeconds sets the startingDeadlineSeconds field of the
+// spec of the CronJob.
+func (p *CronJobJSONPatch) SetSpecStartingDeadlineSeconds(value *int64) *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "add", Path: "/spec/startingDeadlineSeconds", Value: value})
+	return p
+}
+
+// RemoveSpecStartingDeadlineSeconds removes the startingDeadlineSeconds field
+// of the spec of the CronJob, which must be set.
+func (p *CronJobJSONPatch) RemoveSpecStartingDeadlineSeconds() *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "remove", Path: "/spec/startingDeadlineSeconds"})
+	return p
+}
+
+// SetSpecTemplate sets the template field of the spec of the CronJob.
+func (p *CronJobJSONPatch) SetSpecTemplate(value corev1.PodTemplateSpec) *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "add", Path: "/spec/template", Value: value})
+	return p
+}
+
+// RemoveSpecTemplate removes the template field of the spec of the CronJob,
+// which must be set.
+func (p *CronJobJSONPatch) RemoveSpecTemplate() *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "remove", Path: "/spec/template"})
+	return p
+}
+
+// SetStatusLastScheduleTime sets the lastScheduleTime field of the status of
+// the CronJob.
+func (p *CronJobJSONPatch) SetStatusLastScheduleTime(value *metav1.Time) *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "add", Path: "/status/lastScheduleTime", Value: value})
+	return p
+}
+
+// RemoveStatusLastScheduleTime removes the lastScheduleTime field of the status
+// of the CronJob, which must be set.
+func (p *CronJobJSONPatch) RemoveStatusLastScheduleTime() *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "remove", Path: "/status/lastScheduleTime"})
+	return p
+}
+
+// SetStatusActive sets the active field of the status of the CronJob.
+func (p *CronJobJSONPatch) SetStatusActive(value []corev1.ObjectReference) *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "add", Path: "/status/active", Value: value})
+	return p
+}
+
+// RemoveStatusActive removes the active field of the status of the CronJob,
+// which must be set.
+func (p *CronJobJSONPatch) RemoveStatusActive() *CronJobJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "remove", Path: "/status/active"})
+	return p
+}
+
+// MarshalJSON returns the data of the patch.
+func (p *CronJobJSONPatch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.operations)
+}
+
+// ScheduleMergePatch returns the JSON merge patch (RFC 7386) turning the given
+// original Schedule into the modified one, to be sent with the merge patch
+// type.
+func ScheduleMergePatch(original, modified *Schedule) ([]byte, error) {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	modifiedJSON, err := json.Marshal(modified)
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
+}
+
+// ScheduleJSONPatch builds a JSON patch (RFC 6902) of a Schedule, to be sent
+// with the JSON patch type. Its data is given by json.Marshal.
+type ScheduleJSONPatch struct {
+	operations []jsonPatchOperation
+}
+
+// NewScheduleJSONPatch returns a builder of an empty JSON patch of a Schedule.
+func NewScheduleJSONPatch() *ScheduleJSONPatch {
+	return &ScheduleJSONPatch{operations: []jsonPatchOperation{}}
+}
+
+// TestResourceVersion makes the patch fail unless the Schedule has the given
+// resource version, for optimistic locking.
+func (p *ScheduleJSONPatch) TestResourceVersion(resourceVersion string) *ScheduleJSONPatch {
+	p.operations = append(p.operations, jsonPatchOperation{Op: "test", Path: "/metadata/resourceVersion", Value: resourceVersion})
+	return p
+}
+
+// MarshalJSON returns the data of the patch.
+func (p *ScheduleJSONPatch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.operations)
+}
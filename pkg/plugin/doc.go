@@ -0,0 +1,52 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin runs external generators ("plugins") within controller-gen.
+//
+// Plugins are executables speaking a JSON protocol over their standard input
+// and output, so that organizations can add generators (e.g. of internal
+// policy manifests) to a single controller-gen invocation, sharing the loaded
+// packages and markers, without forking controller-gen.  Each plugin is run
+// twice per invocation, once per phase, reading a single Request from its
+// standard input and writing a single Response to its standard output:
+//
+//   - in the describe phase, the plugin declares the markers it reads, which
+//     are collected with the markers of the other generators;
+//
+//   - in the generate phase, the plugin receives the type graph of the root
+//     packages (their types, fields and markers, with fully qualified type
+//     names) and responds with the files to write, which are output like the
+//     artifacts of the other generators (e.g. checked in verify mode).
+//
+// Anything the plugin writes to its standard error is passed through, and a
+// plugin failing (exiting with a non-zero status) fails the generator.
+//
+// Plugins written in Go can use Serve to implement the protocol:
+//
+//	type policyPlugin struct{}
+//
+//	func (policyPlugin) Markers() []plugin.MarkerDefinition {
+//		return []plugin.MarkerDefinition{{Name: "policy:owner", Target: "type", Type: "string"}}
+//	}
+//
+//	func (policyPlugin) Generate(packages []plugin.Package) ([]plugin.File, error) {
+//		...
+//	}
+//
+//	func main() {
+//		plugin.Serve(policyPlugin{})
+//	}
+package plugin
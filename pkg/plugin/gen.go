@@ -0,0 +1,280 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator runs external generators ("plugins") within controller-gen.
+//
+// Plugins are executables speaking the JSON protocol of this package over
+// their standard input and output.  Each command is a plugin executable (looked up in PATH unless it's a path),
+// followed by its arguments, separated by spaces, e.g.
+// plugin:commands={"./bin/policy-gen --strict"}.  Plugins are run one after
+// another, receiving the type graph and markers of the root packages, and the
+// files they generate are output to their package's directory if they're
+// associated with one, or to the output directory of the generator
+// otherwise.
+type Generator struct {
+	// Commands are the command lines of the plugins to run.
+	Commands []string
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return func(node ast.Node) bool {
+		// the type graph references the types of all the fields
+		_, isIface := node.(*ast.InterfaceType)
+		return !isIface
+	}
+}
+
+func (g Generator) RegisterMarkers(into *markers.Registry) error {
+	for _, command := range g.Commands {
+		resp, err := run(command, &Request{Phase: PhaseDescribe})
+		if err != nil {
+			return err
+		}
+		for _, marker := range resp.Markers {
+			def, err := marker.definition()
+			if err != nil {
+				return fmt.Errorf("plugin %q: %w", command, err)
+			}
+			if err := into.Register(def); err != nil {
+				return err
+			}
+			into.AddHelp(def, &markers.DefinitionHelp{
+				Category:     "plugin",
+				DetailedHelp: markers.DetailedHelp{Summary: marker.Help},
+			})
+		}
+	}
+	return nil
+}
+
+// anyValue is the value of markers of type "any".
+type anyValue struct {
+	Value any
+}
+
+// flagValue is the value of markers of type "flag".
+type flagValue struct{}
+
+// definition returns the definition of the marker.
+func (m MarkerDefinition) definition() (*markers.Definition, error) {
+	var target markers.TargetType
+	switch m.Target {
+	case "package":
+		target = markers.DescribesPackage
+	case "type":
+		target = markers.DescribesType
+	case "field":
+		target = markers.DescribesField
+	default:
+		return nil, fmt.Errorf("unknown target %q of marker %q", m.Target, m.Name)
+	}
+
+	switch m.Type {
+	case "", "any":
+		return markers.MakeAnyTypeDefinition(m.Name, target, anyValue{})
+	case "flag":
+		return markers.MakeDefinition(m.Name, target, flagValue{})
+	case "raw":
+		return markers.MakeDefinition(m.Name, target, markers.RawArguments(nil))
+	default:
+		return nil, fmt.Errorf("unknown type %q of marker %q", m.Type, m.Name)
+	}
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	if len(g.Commands) == 0 {
+		return nil
+	}
+
+	req := &Request{Phase: PhaseGenerate}
+	rootsByPath := make(map[string]*loader.Package, len(ctx.Roots))
+	for _, root := range ctx.Roots {
+		pkg, err := typeGraph(ctx, root)
+		if err != nil {
+			return err
+		}
+		req.Packages = append(req.Packages, *pkg)
+		rootsByPath[root.PkgPath] = root
+	}
+
+	var errs []error
+	for _, command := range g.Commands {
+		resp, err := run(command, req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(resp.Errors) > 0 {
+			for _, msg := range resp.Errors {
+				errs = append(errs, fmt.Errorf("plugin %q: %s", command, msg))
+			}
+			continue
+		}
+		for _, file := range resp.Files {
+			if err := writeFile(ctx, rootsByPath, file); err != nil {
+				errs = append(errs, fmt.Errorf("plugin %q: %w", command, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// typeGraph returns the types of the given root package, with their markers.
+func typeGraph(ctx *genall.GenerationContext, root *loader.Package) (*Package, error) {
+	ctx.Checker.Check(root)
+	root.NeedTypesInfo()
+
+	pkgMarkers, err := markers.PackageMarkers(ctx.Collector, root)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &Package{
+		Path:    root.PkgPath,
+		Name:    root.Name,
+		Markers: toMarkers(pkgMarkers),
+	}
+
+	err = markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+		typ := Type{
+			Name:    info.Name,
+			Doc:     info.Doc,
+			Markers: toMarkers(info.Markers),
+		}
+		if _, isStruct := info.RawSpec.Type.(*ast.StructType); !isStruct {
+			typ.Underlying = typeString(root, info.RawSpec.Type)
+		}
+		for _, field := range info.Fields {
+			typ.Fields = append(typ.Fields, Field{
+				Name:    field.Name,
+				Type:    typeString(root, field.RawField.Type),
+				Tag:     string(field.Tag),
+				Doc:     field.Doc,
+				Markers: toMarkers(field.Markers),
+			})
+		}
+		pkg.Types = append(pkg.Types, typ)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// typeString returns the fully qualified type of the given expression, or
+// the expression itself if it couldn't be type-checked.
+func typeString(root *loader.Package, expr ast.Expr) string {
+	if typ := root.TypesInfo.TypeOf(expr); typ != nil && typ != types.Typ[types.Invalid] {
+		return types.TypeString(typ, nil)
+	}
+	return types.ExprString(expr)
+}
+
+// toMarkers converts the given marker values to the values sent to plugins.
+func toMarkers(values markers.MarkerValues) Markers {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make(Markers, len(values))
+	for name, nameValues := range values {
+		for _, value := range nameValues {
+			switch value := value.(type) {
+			case anyValue:
+				out[name] = append(out[name], value.Value)
+			case flagValue:
+				out[name] = append(out[name], true)
+			case markers.RawArguments:
+				out[name] = append(out[name], string(value))
+			default:
+				out[name] = append(out[name], value)
+			}
+		}
+	}
+	return out
+}
+
+// run runs the plugin with the given command line, sending it the given
+// request.
+func run(command string, req *Request) (*Response, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty plugin command")
+	}
+	req.ProtocolVersion = ProtocolVersion
+	rawReq, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the request of plugin %q: %w", command, err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(rawReq)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %q failed in the %s phase: %w", command, req.Phase, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("unable to read the response of plugin %q: %w", command, err)
+	}
+	return &resp, nil
+}
+
+// writeFile writes the given file generated by a plugin.
+func writeFile(ctx *genall.GenerationContext, rootsByPath map[string]*loader.Package, file File) error {
+	if !filepath.IsLocal(file.Path) {
+		return fmt.Errorf("invalid path %q, which must be relative and can't reference parent directories", file.Path)
+	}
+	var root *loader.Package
+	if file.Package != "" {
+		var isRoot bool
+		if root, isRoot = rootsByPath[file.Package]; !isRoot {
+			return fmt.Errorf("file %s is associated with package %q, which isn't a root package", file.Path, file.Package)
+		}
+	}
+
+	out, err := ctx.Open(root, file.Path)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write([]byte(file.Contents)); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/controller-tools/pkg/plugin"
+)
+
+// outputToMap keeps the generated files in memory, by package path (if any)
+// and file name.
+type outputToMap map[string]*outputFile
+
+// Open implements genall.OutputRule.
+func (m outputToMap) Open(pkg *loader.Package, path string) (io.WriteCloser, error) {
+	if pkg != nil {
+		path = pkg.PkgPath + "/" + path
+	}
+	if _, ok := m[path]; !ok {
+		m[path] = &outputFile{}
+	}
+	return m[path], nil
+}
+
+type outputFile struct {
+	bytes.Buffer
+}
+
+func (o *outputFile) Close() error {
+	return nil
+}
+
+var _ = Describe("Plugin Generation", func() {
+	var (
+		command string
+		output  outputToMap
+	)
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+
+		By("running the test binary as the plugin")
+		command, err = os.Executable()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Setenv(servePluginEnv, "1")).To(Succeed())
+		DeferCleanup(os.Unsetenv, servePluginEnv)
+
+		output = make(outputToMap)
+	})
+
+	runtimeFor := func(command string) *genall.Runtime {
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("plugin", markers.DescribesPackage, plugin.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			`plugin:commands={"` + command + `"}`,
+			"paths=./api/...",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+		rt.ErrorWriter = GinkgoWriter
+		return rt
+	}
+
+	It("should write the files generated by the plugin from the type graph and markers", func() {
+		By("running the plugin")
+		Expect(runtimeFor(command).Run()).To(BeFalse())
+
+		By("comparing the generated files to the checked in files")
+		for path, expectedPath := range map[string]string{
+			"policies.txt": "policies.txt",
+			"testdata.kubebuilder.io/plugin/api/v1/OWNERS.policy": "api/v1/OWNERS.policy",
+		} {
+			Expect(output).To(HaveKey(path))
+			expected, err := os.ReadFile(expectedPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output[path].String()).To(Equal(string(expected)), "generated %s not as expected\n\nDiff:\n\n%s", path, cmp.Diff(output[path].String(), string(expected)))
+		}
+	})
+
+	It("should fail without writing files if the plugin fails", func() {
+		Expect(runtimeFor(command + " --fail").Run()).To(BeTrue())
+		Expect(output).To(BeEmpty())
+	})
+})
@@ -0,0 +1,96 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/plugin"
+)
+
+// servePluginEnv makes the test binary serve policyPlugin instead of running
+// the tests, so that the tests can run it as a plugin.
+const servePluginEnv = "CONTROLLER_GEN_SERVE_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(servePluginEnv) != "" {
+		plugin.Serve(policyPlugin{fail: slices.Contains(os.Args[1:], "--fail")})
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestPluginGeneration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Plugin Generation Suite")
+}
+
+// policyPlugin lists the policies declared with markers.
+type policyPlugin struct {
+	fail bool
+}
+
+func (policyPlugin) Markers() []plugin.MarkerDefinition {
+	return []plugin.MarkerDefinition{
+		{Name: "policy:tier", Target: "package", Help: "sets the tier of the policies of the package."},
+		{Name: "policy:owner", Target: "type", Help: "sets the owner of the policy of the type."},
+		{Name: "policy:rule", Target: "type", Type: "raw", Help: "adds a rule to the policy of the type."},
+		{Name: "policy:required", Target: "field", Type: "flag", Help: "requires the field."},
+	}
+}
+
+func (p policyPlugin) Generate(packages []plugin.Package) ([]plugin.File, error) {
+	if p.fail {
+		return nil, errors.New("policies are invalid")
+	}
+
+	var files []plugin.File
+	var policies strings.Builder
+	for _, pkg := range packages {
+		fmt.Fprintf(&policies, "%s (tier %v):\n", pkg.Path, pkg.Markers["policy:tier"])
+		var owners strings.Builder
+		for _, typ := range pkg.Types {
+			fmt.Fprintf(&policies, "  %s", typ.Name)
+			if typ.Underlying != "" {
+				fmt.Fprintf(&policies, " (%s)", typ.Underlying)
+			}
+			fmt.Fprintf(&policies, ": %s\n", typ.Doc)
+			for _, owner := range typ.Markers["policy:owner"] {
+				fmt.Fprintf(&owners, "%s: %v\n", typ.Name, owner)
+			}
+			for _, rule := range typ.Markers["policy:rule"] {
+				fmt.Fprintf(&policies, "    rule %v\n", rule)
+			}
+			for _, field := range typ.Fields {
+				fmt.Fprintf(&policies, "    %s %s", field.Name, field.Type)
+				if len(field.Markers["policy:required"]) > 0 {
+					fmt.Fprintf(&policies, " (required: %v)", field.Markers["policy:required"][0])
+				}
+				fmt.Fprintln(&policies)
+			}
+		}
+		files = append(files, plugin.File{Path: "OWNERS.policy", Package: pkg.Path, Contents: owners.String()})
+	}
+	return append(files, plugin.File{Path: "policies.txt", Contents: policies.String()}), nil
+}
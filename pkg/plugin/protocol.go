@@ -0,0 +1,188 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ProtocolVersion is the version of the protocol spoken with plugins.
+const ProtocolVersion = "v1"
+
+// Phase is the phase of the run of a plugin.
+type Phase string
+
+const (
+	// PhaseDescribe asks the plugin for the markers it reads.
+	PhaseDescribe Phase = "describe"
+	// PhaseGenerate asks the plugin for the files it generates.
+	PhaseGenerate Phase = "generate"
+)
+
+// Request is the request sent to the standard input of a plugin.
+type Request struct {
+	// ProtocolVersion is the version of the protocol (see ProtocolVersion).
+	ProtocolVersion string `json:"protocolVersion"`
+	// Phase is the phase of the run.
+	Phase Phase `json:"phase"`
+	// Packages are the root packages, in the generate phase.
+	Packages []Package `json:"packages,omitempty"`
+}
+
+// Response is the response read from the standard output of a plugin.
+type Response struct {
+	// Markers are the markers read by the plugin, in the describe phase.
+	Markers []MarkerDefinition `json:"markers,omitempty"`
+	// Files are the generated files, in the generate phase.
+	Files []File `json:"files,omitempty"`
+	// Errors are the errors of the plugin, if it failed.  No files are
+	// written if there are any.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// MarkerDefinition declares a marker read by a plugin.
+type MarkerDefinition struct {
+	// Name is the name of the marker, e.g. policy:owner for +policy:owner.
+	Name string `json:"name"`
+	// Target is what the marker describes: "package", "type" or "field".
+	Target string `json:"target"`
+	// Type is the type of the argument of the marker:
+	//
+	//   - "any" (the default), for an argument of any type, as with
+	//     +kubebuilder:default, received as the JSON equivalent of its value
+	//     (e.g. +policy:owner=team-a is received as "team-a");
+	//
+	//   - "flag", for markers without arguments like +policy:required,
+	//     received as true;
+	//
+	//   - "raw", for arguments parsed by the plugin itself, received as the
+	//     raw text of the argument.
+	Type string `json:"type,omitempty"`
+	// Help is the help text of the marker, shown with the markers of the
+	// generators (e.g. with controller-gen -w).
+	Help string `json:"help,omitempty"`
+}
+
+// Markers are the values of the markers of a package, type or field, by
+// marker name.  They hold the markers of the plugin, along with the markers
+// of the other generators run in the same invocation.
+type Markers map[string][]any
+
+// Package is a root package, along with its types.
+type Package struct {
+	// Path is the import path of the package.
+	Path string `json:"path"`
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// Markers are the package-level markers.
+	Markers Markers `json:"markers,omitempty"`
+	// Types are the types declared in the package.
+	Types []Type `json:"types,omitempty"`
+}
+
+// Type is a type declared in a root package.
+type Type struct {
+	// Name is the name of the type.
+	Name string `json:"name"`
+	// Doc is the Godoc of the type, without markers.
+	Doc string `json:"doc,omitempty"`
+	// Markers are the markers of the type.
+	Markers Markers `json:"markers,omitempty"`
+	// Underlying is the fully qualified underlying type, unless the type is
+	// a struct (whose fields are listed in Fields), e.g. "string" or
+	// "map[string]example.com/api/v1.Rule".
+	Underlying string `json:"underlying,omitempty"`
+	// Fields are the fields of the type, if it's a struct.
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// Field is a field of a struct type.
+type Field struct {
+	// Name is the name of the field, or empty for embedded fields.
+	Name string `json:"name,omitempty"`
+	// Type is the fully qualified type of the field, e.g.
+	// "*example.com/api/v1.WidgetSpec", referencing types of the same or other
+	// packages.
+	Type string `json:"type"`
+	// Tag is the struct tag of the field.
+	Tag string `json:"tag,omitempty"`
+	// Doc is the Godoc of the field, without markers.
+	Doc string `json:"doc,omitempty"`
+	// Markers are the markers of the field.
+	Markers Markers `json:"markers,omitempty"`
+}
+
+// File is a file generated by a plugin.
+type File struct {
+	// Path is the path of the file, relative to the directory of its package
+	// if it's associated with one, or to the output directory otherwise.  It
+	// can't reference parent directories.
+	Path string `json:"path"`
+	// Package is the import path of the root package the file is associated
+	// with, e.g. for Go code, if any.
+	Package string `json:"package,omitempty"`
+	// Contents are the contents of the file.
+	Contents string `json:"contents"`
+}
+
+// Plugin is a plugin written in Go, served by Serve.
+type Plugin interface {
+	// Markers returns the markers read by the plugin.
+	Markers() []MarkerDefinition
+	// Generate returns the files generated for the given root packages.
+	Generate(packages []Package) ([]File, error)
+}
+
+// Serve implements the protocol for the given plugin, handling the request
+// read from the standard input.  Errors returned by the plugin are sent in
+// the response, while malformed requests make the process exit with a
+// non-zero status.
+func Serve(p Plugin) {
+	if err := serve(p, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func serve(p Plugin, in io.Reader, out io.Writer) error {
+	var req Request
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
+		return fmt.Errorf("unable to read request: %w", err)
+	}
+	if req.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %q, expected %q", req.ProtocolVersion, ProtocolVersion)
+	}
+
+	var resp Response
+	switch req.Phase {
+	case PhaseDescribe:
+		resp.Markers = p.Markers()
+	case PhaseGenerate:
+		files, err := p.Generate(req.Packages)
+		if err != nil {
+			resp.Errors = []string{err.Error()}
+			break
+		}
+		resp.Files = files
+	default:
+		return fmt.Errorf("unknown phase %q", req.Phase)
+	}
+	return json.NewEncoder(out).Encode(resp)
+}
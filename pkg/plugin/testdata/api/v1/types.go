@@ -0,0 +1,46 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +policy:tier=gold
+package v1
+
+// Widget is a widget.
+// +policy:owner=team-a
+// +policy:rule="self.spec.size <= 10"
+type Widget struct {
+	// Spec is the desired state of the widget.
+	// +policy:required
+	Spec WidgetSpec `json:"spec"`
+	// Status is the observed state of the widget.
+	Status *WidgetStatus `json:"status,omitempty"`
+}
+
+// WidgetSpec is the desired state of a widget.
+type WidgetSpec struct {
+	// Size is the size of the widget.
+	// +policy:required
+	Size int32 `json:"size"`
+	// Labels are the labels of the widget.
+	Labels map[string]Label `json:"labels,omitempty"`
+}
+
+// WidgetStatus is the observed state of a widget.
+type WidgetStatus struct {
+	Ready bool `json:"ready"`
+}
+
+// Label is the value of a label.
+type Label string
@@ -0,0 +1,3 @@
+module testdata.kubebuilder.io/plugin
+
+go 1.26.0
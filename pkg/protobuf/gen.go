@@ -0,0 +1,298 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	protoFileName = "generated.proto"
+	goFileName    = "generated.pb.go"
+)
+
+// stdAliases are the aliases of the standard packages used by the
+// marshalers.
+var stdAliases = map[string]struct{}{
+	"binary": {}, "bits": {}, "fmt": {}, "io": {}, "math": {}, "sort": {},
+}
+
+var (
+	enablePkgMarker  = markers.Must(markers.MakeDefinition("kubebuilder:protobuf:generate", markers.DescribesPackage, false))
+	enableTypeMarker = markers.Must(markers.MakeDefinition("kubebuilder:protobuf:generate", markers.DescribesType, false))
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates protobuf definitions and marshalers for API types.
+//
+// The struct types of packages marked with +kubebuilder:protobuf:generate=true
+// are written as proto2 messages to generated.proto, with Marshal, Unmarshal,
+// Size and the other methods of gogo/protobuf messages written to
+// generated.pb.go, as with go-to-protobuf.
+//
+// Fields keep the numbers of their protobuf tags (e.g.
+// `protobuf:"bytes,1,opt,name=metadata"`); fields without one are numbered
+// after the highest tagged number, in declaration order, so fields should be
+// tagged to keep their numbers stable.  TypeMeta isn't part of the messages,
+// and types of other packages must have marshalers of their own.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return func(node ast.Node) bool {
+		// ignore interfaces
+		_, isIface := node.(*ast.InterfaceType)
+		return !isIface
+	}
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	if err := markers.RegisterAll(into, enablePkgMarker, enableTypeMarker); err != nil {
+		return err
+	}
+	into.AddHelp(enablePkgMarker,
+		markers.SimpleHelp("protobuf", "enables or disables protobuf definition & marshaler generation for this package"))
+	into.AddHelp(enableTypeMarker,
+		markers.SimpleHelp("protobuf", "overrides enabling or disabling protobuf generation for this type"))
+	return nil
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	var headerText string
+
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	for _, root := range ctx.Roots {
+		pkgMarkers, err := markers.PackageMarkers(ctx.Collector, root)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		enabled, _ := pkgMarkers.Get(enablePkgMarker.Name).(bool)
+
+		ctx.Checker.Check(root)
+		root.NeedTypesInfo()
+		file := newProtoFile(root)
+		if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+			if typeMarker := info.Markers.Get(enableTypeMarker.Name); typeMarker != nil {
+				if !typeMarker.(bool) {
+					return
+				}
+			} else if !enabled {
+				return
+			}
+			file.addType(info)
+		}); err != nil {
+			root.AddError(err)
+			continue
+		}
+		if len(file.messages) == 0 {
+			continue
+		}
+
+		file.generate()
+		if file.failed {
+			continue
+		}
+		gogen.WriteOut(ctx, root, protoFileName, file.protoContents(headerText))
+		if file.goCode.Len() > 0 {
+			gogen.WriteOut(ctx, root, goFileName, file.goContents(headerText))
+		}
+	}
+
+	return nil
+}
+
+// protoFile collects the messages of a single package.
+type protoFile struct {
+	pkg *loader.Package
+
+	// messages are the messages of the package, by name.
+	messages map[string]*message
+	// protoImports are the imported proto files.
+	protoImports map[string]struct{}
+	// imports are the aliases of the imported Go packages, by path, and
+	// importsByAlias the paths by alias.
+	imports        map[string]string
+	importsByAlias map[string]string
+	// helpers are the names of the needed helper functions.
+	helpers map[string]struct{}
+
+	goCode bytes.Buffer
+	// failed indicates that some message couldn't be generated, so the files
+	// shouldn't be written.
+	failed bool
+}
+
+func newProtoFile(pkg *loader.Package) *protoFile {
+	return &protoFile{
+		pkg:            pkg,
+		messages:       make(map[string]*message),
+		protoImports:   make(map[string]struct{}),
+		imports:        make(map[string]string),
+		importsByAlias: make(map[string]string),
+		helpers:        make(map[string]struct{}),
+	}
+}
+
+// needImport marks that the given Go package is needed, returning the alias
+// to use for it.
+func (f *protoFile) needImport(importPath string) string {
+	importPath = loader.NonVendorPath(importPath)
+	if alias, exists := f.imports[importPath]; exists {
+		return alias
+	}
+	// join path elements till the alias is unique, e.g. v1 then metav1
+	elems := strings.Split(importPath, "/")
+	isStd := !strings.Contains(elems[0], ".")
+	available := func(alias string) bool {
+		if _, taken := f.importsByAlias[alias]; taken || alias == f.pkg.Name {
+			return false
+		}
+		// keep the aliases of the standard packages used by the marshalers
+		_, isStdAlias := stdAliases[alias]
+		return isStd || !isStdAlias
+	}
+	var alias string
+	for i := len(elems) - 1; i >= 0; i-- {
+		alias = strings.Map(func(r rune) rune {
+			if r == '.' || r == '-' {
+				return -1
+			}
+			return r
+		}, elems[i]) + alias
+		if available(alias) && (alias[0] < '0' || alias[0] > '9') {
+			break
+		}
+	}
+	for base, i := alias, 2; !available(alias); i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	f.imports[importPath] = alias
+	f.importsByAlias[alias] = importPath
+	return alias
+}
+
+// qualifier qualifies the types of other packages with their import alias.
+func (f *protoFile) qualifier(pkg *types.Package) string {
+	if pkg.Path() == f.pkg.PkgPath {
+		return ""
+	}
+	return f.needImport(pkg.Path())
+}
+
+// typeExpr returns the syntax of the given type.
+func (f *protoFile) typeExpr(typ types.Type) string {
+	return types.TypeString(typ, f.qualifier)
+}
+
+// protoPackage returns the proto package of the given Go package.
+func protoPackage(pkgPath string) string {
+	return strings.NewReplacer("/", ".", "-", "_").Replace(loader.NonVendorPath(pkgPath))
+}
+
+// protoContents returns the contents of generated.proto.
+func (f *protoFile) protoContents(headerText string) []byte {
+	out := new(bytes.Buffer)
+	if headerText = strings.TrimSpace(headerText); headerText != "" {
+		fmt.Fprintf(out, "%s\n\n", headerText)
+	}
+	out.WriteString("// Code generated by controller-gen. DO NOT EDIT.\n\n")
+	out.WriteString("syntax = \"proto2\";\n\n")
+	fmt.Fprintf(out, "package %s;\n\n", protoPackage(f.pkg.PkgPath))
+
+	if len(f.protoImports) > 0 {
+		protoImports := make([]string, 0, len(f.protoImports))
+		for protoImport := range f.protoImports {
+			protoImports = append(protoImports, protoImport)
+		}
+		slices.Sort(protoImports)
+		for _, protoImport := range protoImports {
+			fmt.Fprintf(out, "import %q;\n", protoImport)
+		}
+		out.WriteString("\n")
+	}
+	fmt.Fprintf(out, "option go_package = %q;\n", loader.NonVendorPath(f.pkg.PkgPath))
+
+	for _, name := range f.messageNames() {
+		out.WriteString("\n")
+		f.messages[name].writeProto(out)
+	}
+	return out.Bytes()
+}
+
+// messageNames returns the sorted names of the messages.
+func (f *protoFile) messageNames() []string {
+	names := make([]string, 0, len(f.messages))
+	for name := range f.messages {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// goContents returns the formatted contents of generated.pb.go.
+func (f *protoFile) goContents(headerText string) []byte {
+	// the aliases are already chosen by needImport
+	imports := gogen.NewImports(f.pkg.Name)
+	for importPath, alias := range f.imports {
+		imports.NeedImportAs(importPath, path.Base(importPath), alias)
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[3]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+%[2]s
+)
+
+`, f.pkg.Name, imports.Block(), headerText)
+	outContent.Write(f.goCode.Bytes())
+
+	return gogen.Format(f.pkg, outContent.Bytes())
+}
@@ -0,0 +1,609 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/types"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+)
+
+// codeWriter writes out the Go code of the marshalers.
+type codeWriter struct {
+	gogen.CodeWriter
+}
+
+// returnErr writes code returning the error of the given expression, if any.
+func (c *codeWriter) returnErr(errExpr, zero string) {
+	c.Linef("if err := %s; err != nil {", errExpr)
+	if zero != "" {
+		c.Linef("return %s, err", zero)
+	} else {
+		c.Line("return err")
+	}
+	c.Line("}")
+}
+
+// fieldKey returns the encoded key of the given field number, for values of
+// the given kind.
+func fieldKey(number int, kind scalarKind) []byte {
+	return binary.AppendUvarint(nil, uint64(number)<<3|uint64(kind.wireType()))
+}
+
+// isBasic checks whether the given type is the given basic type itself.
+func isBasic(typ types.Type, kind types.BasicKind) bool {
+	basic, isBasic := typ.(*types.Basic)
+	return isBasic && basic.Kind() == kind
+}
+
+// varName returns the name of a variable with the given base name, prefixed
+// with the given prefix.
+func varName(prefix, base string) string {
+	if prefix == "" {
+		return base
+	}
+	return prefix + strings.ToUpper(base[:1]) + base[1:]
+}
+
+// writeMarshalers writes the methods of the message of the given type.
+func (f *protoFile) writeMarshalers(name string, fields []*protoField) {
+	c := &codeWriter{gogen.CodeWriter{Out: &f.goCode}}
+
+	c.Linef("func (m *%s) Reset() { *m = %s{} }", name, name)
+	c.Line("")
+	c.Linef("func (*%s) ProtoMessage() {}", name)
+	c.Line("")
+	c.Linef("func (m *%s) String() string {", name)
+	c.Line(`if m == nil {
+		return "nil"
+	}`)
+	c.Linef("return %s.Sprintf(\"%%+v\", *m)", f.needImport("fmt"))
+	c.Line("}")
+	c.Line("")
+
+	c.Linef(`func (m *%s) Marshal() (dAtA []byte, err error) {
+		size := m.Size()
+		dAtA = make([]byte, size)
+		n, err := m.MarshalToSizedBuffer(dAtA[:size])
+		if err != nil {
+			return nil, err
+		}
+		return dAtA[:n], nil
+	}
+
+	func (m *%[1]s) MarshalTo(dAtA []byte) (int, error) {
+		size := m.Size()
+		return m.MarshalToSizedBuffer(dAtA[:size])
+	}
+	`, name)
+
+	c.Linef("func (m *%s) MarshalToSizedBuffer(dAtA []byte) (int, error) {", name)
+	c.Line("i := len(dAtA)")
+	// fields are written backwards, so in reverse order
+	for i := len(fields) - 1; i >= 0; i-- {
+		f.marshalField(c, fields[i])
+	}
+	c.Line("return len(dAtA) - i, nil")
+	c.Line("}")
+	c.Line("")
+
+	c.Linef("func (m *%s) Size() (n int) {", name)
+	c.Line(`if m == nil {
+		return 0
+	}
+	var l int
+	_ = l`)
+	for _, field := range fields {
+		f.sizeField(c, field)
+	}
+	c.Line("return n")
+	c.Line("}")
+	c.Line("")
+
+	fmtAlias, ioAlias := f.needImport("fmt"), f.needImport("io")
+	c.Linef("func (m *%s) Unmarshal(dAtA []byte) error {", name)
+	c.Linef(`iNdEx := 0
+	for iNdEx < len(dAtA) {
+		preIndex := iNdEx
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = next
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return %[1]s.Errorf("proto: %[2]s: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return %[1]s.Errorf("proto: %[2]s: illegal tag %%d (wire type %%d)", fieldNum, wire)
+		}
+		switch fieldNum {`, fmtAlias, name)
+	for _, field := range fields {
+		c.Linef("case %d:", field.number)
+		c.Linef("if wireType != %d {", field.value.kind.wireType())
+		c.Linef("return %s.Errorf(\"proto: wrong wireType = %%d for field %s\", wireType)", fmtAlias, field.goName)
+		c.Line("}")
+		f.unmarshalField(c, field)
+	}
+	c.Linef(`default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > len(dAtA) {
+				return %s.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+	return nil
+}
+`, ioAlias)
+}
+
+// marshalField writes the code encoding the given field backwards.
+func (f *protoFile) marshalField(c *codeWriter, field *protoField) {
+	expr := "m." + field.goName
+	switch {
+	case field.isMap:
+		keys := "keysFor" + field.goName
+		keyExpr := "k"
+		if !isBasic(field.key.goType, types.String) {
+			keyExpr = "string(k)"
+		}
+		c.Linef("if len(%s) > 0 {", expr)
+		c.Linef("%s := make([]string, 0, len(%s))", keys, expr)
+		c.Linef("for k := range %s {", expr)
+		c.Linef("%[1]s = append(%[1]s, %[2]s)", keys, keyExpr)
+		c.Line("}")
+		c.Linef("%s.Strings(%s)", f.needImport("sort"), keys)
+		c.Linef("for iNdEx := len(%s) - 1; iNdEx >= 0; iNdEx-- {", keys)
+		if isBasic(field.key.goType, types.String) {
+			c.Linef("v := %s[%s[iNdEx]]", expr, keys)
+		} else {
+			c.Linef("v := %s[%s(%s[iNdEx])]", expr, f.typeExpr(field.key.goType), keys)
+		}
+		c.Line("baseI := i")
+		if field.value.pointer {
+			c.Line("if v != nil {")
+			f.marshalScalar(c, "v", field.value, 2)
+			c.Line("}")
+		} else {
+			f.marshalScalar(c, "v", field.value, 2)
+		}
+		f.marshalScalar(c, keys+"[iNdEx]", scalar{kind: kindString, goType: types.Typ[types.String]}, 1)
+		c.Line("i = encodeVarintGenerated(dAtA, i, uint64(baseI-i))")
+		f.writeKey(c, field.number, kindString)
+		c.Line("}")
+		c.Line("}")
+	case field.repeated:
+		c.Linef("for iNdEx := len(%s) - 1; iNdEx >= 0; iNdEx-- {", expr)
+		f.marshalScalar(c, expr+"[iNdEx]", field.value, field.number)
+		c.Line("}")
+	case field.value.pointer, field.value.kind == kindBytes:
+		c.Linef("if %s != nil {", expr)
+		f.marshalScalar(c, deref(expr, field.value), field.value, field.number)
+		c.Line("}")
+	default:
+		f.marshalScalar(c, expr, field.value, field.number)
+	}
+}
+
+// deref returns the syntax for the value pointed to by the given expression
+// of the given pointer value (messages being used through their pointer).
+func deref(expr string, value scalar) string {
+	if !value.pointer || value.kind == kindMessage {
+		return expr
+	}
+	return "*" + expr
+}
+
+// writeKey writes the code writing the key of the given field backwards.
+func (f *protoFile) writeKey(c *codeWriter, number int, kind scalarKind) {
+	key := fieldKey(number, kind)
+	for i := len(key) - 1; i >= 0; i-- {
+		c.Line("i--")
+		c.Linef("dAtA[i] = 0x%x", key[i])
+	}
+}
+
+// marshalScalar writes the code encoding the given expression backwards, as
+// the field with the given number.
+func (f *protoFile) marshalScalar(c *codeWriter, expr string, value scalar, number int) {
+	switch value.kind {
+	case kindString, kindBytes:
+		if value.kind == kindString && !isBasic(value.goType, types.String) {
+			expr = "string(" + expr + ")"
+		}
+		c.Linef("i -= len(%s)", expr)
+		c.Linef("copy(dAtA[i:], %s)", expr)
+		c.Linef("i = encodeVarintGenerated(dAtA, i, uint64(len(%s)))", expr)
+	case kindBool:
+		c.Line("i--")
+		c.Linef("if %s {", expr)
+		c.Line("dAtA[i] = 1")
+		c.Line("} else {")
+		c.Line("dAtA[i] = 0")
+		c.Line("}")
+	case kindVarint:
+		c.Linef("i = encodeVarintGenerated(dAtA, i, uint64(%s))", expr)
+	case kindDouble:
+		c.Line("i -= 8")
+		c.Linef("%s.LittleEndian.PutUint64(dAtA[i:], %s.Float64bits(float64(%s)))", f.needImport("encoding/binary"), f.needImport("math"), expr)
+	case kindFloat:
+		c.Line("i -= 4")
+		c.Linef("%s.LittleEndian.PutUint32(dAtA[i:], %s.Float32bits(float32(%s)))", f.needImport("encoding/binary"), f.needImport("math"), expr)
+	case kindMessage:
+		c.Line("{")
+		c.Linef("size, err := %s.MarshalToSizedBuffer(dAtA[:i])", expr)
+		c.Line(`if err != nil {
+			return 0, err
+		}
+		i -= size`)
+		c.Line("i = encodeVarintGenerated(dAtA, i, uint64(size))")
+		c.Line("}")
+	}
+	f.writeKey(c, number, value.kind)
+}
+
+// fixedSize returns the size of encoded values of the given kind, if fixed.
+func fixedSize(kind scalarKind) int {
+	switch kind {
+	case kindBool:
+		return 1
+	case kindDouble:
+		return 8
+	case kindFloat:
+		return 4
+	}
+	return 0
+}
+
+// sizeField writes the code adding the encoded size of the given field.
+func (f *protoFile) sizeField(c *codeWriter, field *protoField) {
+	expr := "m." + field.goName
+	keyLen := len(fieldKey(field.number, field.value.kind))
+	switch {
+	case field.isMap:
+		c.Linef("for k, v := range %s {", expr)
+		c.Line("_ = k")
+		c.Line("_ = v")
+		c.Line("mapEntrySize := 0")
+		f.sizeScalar(c, "mapEntrySize", "k", field.key, 1)
+		if field.value.pointer {
+			c.Line("if v != nil {")
+			f.sizeScalar(c, "mapEntrySize", "v", field.value, 2)
+			c.Line("}")
+		} else {
+			f.sizeScalar(c, "mapEntrySize", "v", field.value, 2)
+		}
+		c.Linef("n += mapEntrySize + %d + sovGenerated(uint64(mapEntrySize))", keyLen)
+		c.Line("}")
+	case field.repeated && fixedSize(field.value.kind) > 0:
+		c.Linef("n += len(%s) * %d", expr, keyLen+fixedSize(field.value.kind))
+	case field.repeated:
+		c.Linef("for _, e := range %s {", expr)
+		f.sizeScalar(c, "n", "e", field.value, field.number)
+		c.Line("}")
+	case field.value.pointer, field.value.kind == kindBytes:
+		c.Linef("if %s != nil {", expr)
+		f.sizeScalar(c, "n", deref(expr, field.value), field.value, field.number)
+		c.Line("}")
+	default:
+		f.sizeScalar(c, "n", expr, field.value, field.number)
+	}
+}
+
+// sizeScalar writes the code adding the encoded size of the given expression,
+// as the field with the given number, to the given variable.
+func (f *protoFile) sizeScalar(c *codeWriter, acc, expr string, value scalar, number int) {
+	keyLen := len(fieldKey(number, value.kind))
+	switch value.kind {
+	case kindString, kindBytes:
+		c.Linef("l = len(%s)", expr)
+		c.Linef("%s += %d + l + sovGenerated(uint64(l))", acc, keyLen)
+	case kindVarint:
+		c.Linef("%s += %d + sovGenerated(uint64(%s))", acc, keyLen, expr)
+	case kindMessage:
+		c.Linef("l = %s.Size()", expr)
+		c.Linef("%s += %d + l + sovGenerated(uint64(l))", acc, keyLen)
+	default:
+		c.Linef("%s += %d", acc, keyLen+fixedSize(value.kind))
+	}
+}
+
+// unmarshalField writes the code decoding the given field, at iNdEx of dAtA.
+func (f *protoFile) unmarshalField(c *codeWriter, field *protoField) {
+	expr := "m." + field.goName
+	if field.isMap {
+		f.unmarshalMap(c, expr, field)
+		return
+	}
+
+	valueExpr := f.readScalar(c, "", "dAtA", "iNdEx", field.value)
+	switch {
+	case field.value.kind == kindMessage && field.repeated:
+		elem := f.typeExpr(field.value.goType) + "{}"
+		if field.value.pointer {
+			elem = "&" + elem
+		}
+		c.Linef("%[1]s = append(%[1]s, %[2]s)", expr, elem)
+		c.returnErr(fmt.Sprintf("%[1]s[len(%[1]s)-1].Unmarshal(%[2]s)", expr, valueExpr), "")
+	case field.value.kind == kindMessage && field.value.pointer:
+		c.Linef("if %s == nil {", expr)
+		c.Linef("%s = &%s{}", expr, f.typeExpr(field.value.goType))
+		c.Line("}")
+		c.returnErr(expr+".Unmarshal("+valueExpr+")", "")
+	case field.value.kind == kindMessage:
+		c.returnErr(expr+".Unmarshal("+valueExpr+")", "")
+	case field.repeated:
+		c.Linef("%[1]s = append(%[1]s, %[2]s)", expr, valueExpr)
+	case field.value.pointer:
+		c.Linef("value := %s", valueExpr)
+		c.Linef("%s = &value", expr)
+	default:
+		c.Linef("%s = %s", expr, valueExpr)
+	}
+}
+
+// unmarshalMap writes the code decoding an entry of the given map field, at
+// iNdEx of dAtA.
+func (f *protoFile) unmarshalMap(c *codeWriter, expr string, field *protoField) {
+	fmtAlias, ioAlias := f.needImport("fmt"), f.needImport("io")
+	data := f.readScalar(c, "", "dAtA", "iNdEx", scalar{kind: kindBytes})
+	c.Linef("if %s == nil {", expr)
+	c.Linef("%s = make(%s)", expr, f.typeExpr(field.goType))
+	c.Line("}")
+	c.Linef("var mapkey %s", f.typeExpr(field.key.goType))
+	valueType := f.typeExpr(field.value.goType)
+	if field.value.pointer {
+		c.Linef("var mapvalue *%s", valueType)
+	} else {
+		c.Linef("var mapvalue %s", valueType)
+	}
+	c.Linef("for entryIndex := 0; entryIndex < len(%s); {", data)
+	c.Line("entryPreIndex := entryIndex")
+	c.Linef("entryWire, entryNext, err := decodeVarintGenerated(%s, entryIndex)", data)
+	c.Line(`if err != nil {
+		return err
+	}
+	entryIndex = entryNext`)
+	c.Line("switch entryWire >> 3 {")
+	for _, entryField := range []struct {
+		number int
+		value  scalar
+		target string
+	}{{1, field.key, "mapkey"}, {2, field.value, "mapvalue"}} {
+		c.Linef("case %d:", entryField.number)
+		c.Linef("if entryWireType := int(entryWire & 0x7); entryWireType != %d {", entryField.value.kind.wireType())
+		c.Linef("return %s.Errorf(\"proto: wrong wireType = %%d for field %s\", entryWireType)", fmtAlias, field.goName)
+		c.Line("}")
+		valueExpr := f.readScalar(c, "entry", data, "entryIndex", entryField.value)
+		switch {
+		case entryField.value.kind == kindMessage && entryField.value.pointer:
+			c.Linef("%s = &%s{}", entryField.target, valueType)
+			c.returnErr(entryField.target+".Unmarshal("+valueExpr+")", "")
+		case entryField.value.kind == kindMessage:
+			c.returnErr(entryField.target+".Unmarshal("+valueExpr+")", "")
+		default:
+			c.Linef("%s = %s", entryField.target, valueExpr)
+		}
+	}
+	c.Linef(`default:
+			skippy, err := skipGenerated(%[1]s[entryPreIndex:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (entryPreIndex+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (entryPreIndex + skippy) > len(%[1]s) {
+				return %[2]s.ErrUnexpectedEOF
+			}
+			entryIndex = entryPreIndex + skippy
+		}
+	}`, data, ioAlias)
+	c.Linef("%s[mapkey] = mapvalue", expr)
+}
+
+// readScalar writes the code reading a value of the given kind at the given
+// index of the given buffer, returning the syntax of the value (or of its
+// encoded bytes, for messages).  The declared variables are prefixed with the
+// given prefix.
+func (f *protoFile) readScalar(c *codeWriter, prefix, buf, index string, value scalar) string {
+	data, num, next := varName(prefix, "data"), varName(prefix, "v"), varName(prefix, "next")
+	switch value.kind {
+	case kindString, kindBytes, kindMessage:
+		c.Linef("%s, %s, err := decodeBytesGenerated(%s, %s)", data, next, buf, index)
+	case kindBool, kindVarint:
+		c.Linef("%s, %s, err := decodeVarintGenerated(%s, %s)", num, next, buf, index)
+	case kindDouble:
+		f.helpers["decodeFixed64Generated"] = struct{}{}
+		c.Linef("%s, %s, err := decodeFixed64Generated(%s, %s)", num, next, buf, index)
+	case kindFloat:
+		f.helpers["decodeFixed32Generated"] = struct{}{}
+		c.Linef("%s, %s, err := decodeFixed32Generated(%s, %s)", num, next, buf, index)
+	}
+	c.Line(`if err != nil {
+		return err
+	}`)
+	c.Linef("%s = %s", index, next)
+
+	convert := func(expr string, kind types.BasicKind) string {
+		if isBasic(value.goType, kind) {
+			return expr
+		}
+		return f.typeExpr(value.goType) + "(" + expr + ")"
+	}
+	switch value.kind {
+	case kindString:
+		return f.typeExpr(value.goType) + "(" + data + ")"
+	case kindBytes:
+		if value.goType == nil {
+			// raw bytes, e.g. of a map entry
+			return data
+		}
+		if isBytesType(value.goType) {
+			return "append([]byte{}, " + data + "...)"
+		}
+		return f.typeExpr(value.goType) + "(append([]byte{}, " + data + "...))"
+	case kindBool:
+		return convert(num+" != 0", types.Bool)
+	case kindVarint:
+		return f.typeExpr(value.goType) + "(" + num + ")"
+	case kindDouble:
+		return convert(f.needImport("math")+".Float64frombits("+num+")", types.Float64)
+	case kindFloat:
+		return convert(f.needImport("math")+".Float32frombits("+num+")", types.Float32)
+	}
+	return data
+}
+
+// isBytesType checks whether the given type is []byte itself.
+func isBytesType(typ types.Type) bool {
+	slice, isSlice := typ.(*types.Slice)
+	return isSlice && isBasic(slice.Elem(), types.Uint8)
+}
+
+// writeHelpers writes the helper functions used by the marshalers.
+func (f *protoFile) writeHelpers() {
+	c := &codeWriter{gogen.CodeWriter{Out: &f.goCode}}
+	fmtAlias, ioAlias, bitsAlias := f.needImport("fmt"), f.needImport("io"), f.needImport("math/bits")
+
+	c.Linef(`var (
+	ErrInvalidLengthGenerated        = %[1]s.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowGenerated          = %[1]s.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupGenerated = %[1]s.Errorf("proto: unexpected end of group")
+)
+
+func encodeVarintGenerated(dAtA []byte, offset int, v uint64) int {
+	offset -= sovGenerated(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+
+func sovGenerated(x uint64) (n int) {
+	return (%[3]s.Len64(x|1) + 6) / 7
+}
+
+func decodeVarintGenerated(dAtA []byte, iNdEx int) (uint64, int, error) {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 64 {
+			return 0, 0, ErrIntOverflowGenerated
+		}
+		if iNdEx >= len(dAtA) {
+			return 0, 0, %[2]s.ErrUnexpectedEOF
+		}
+		b := dAtA[iNdEx]
+		iNdEx++
+		v |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			return v, iNdEx, nil
+		}
+	}
+}
+
+func decodeBytesGenerated(dAtA []byte, iNdEx int) ([]byte, int, error) {
+	length, iNdEx, err := decodeVarintGenerated(dAtA, iNdEx)
+	if err != nil {
+		return nil, 0, err
+	}
+	postIndex := iNdEx + int(length)
+	if int(length) < 0 || postIndex < 0 {
+		return nil, 0, ErrInvalidLengthGenerated
+	}
+	if postIndex > len(dAtA) {
+		return nil, 0, %[2]s.ErrUnexpectedEOF
+	}
+	return dAtA[iNdEx:postIndex], postIndex, nil
+}
+`, fmtAlias, ioAlias, bitsAlias)
+
+	if _, needed := f.helpers["decodeFixed64Generated"]; needed {
+		c.Linef(`func decodeFixed64Generated(dAtA []byte, iNdEx int) (uint64, int, error) {
+	if iNdEx+8 > len(dAtA) {
+		return 0, 0, %[1]s.ErrUnexpectedEOF
+	}
+	return %[2]s.LittleEndian.Uint64(dAtA[iNdEx:]), iNdEx + 8, nil
+}
+`, ioAlias, f.needImport("encoding/binary"))
+	}
+	if _, needed := f.helpers["decodeFixed32Generated"]; needed {
+		c.Linef(`func decodeFixed32Generated(dAtA []byte, iNdEx int) (uint32, int, error) {
+	if iNdEx+4 > len(dAtA) {
+		return 0, 0, %[1]s.ErrUnexpectedEOF
+	}
+	return %[2]s.LittleEndian.Uint32(dAtA[iNdEx:]), iNdEx + 4, nil
+}
+`, ioAlias, f.needImport("encoding/binary"))
+	}
+
+	c.Linef(`func skipGenerated(dAtA []byte) (n int, err error) {
+	iNdEx := 0
+	depth := 0
+	for iNdEx < len(dAtA) {
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return 0, err
+		}
+		iNdEx = next
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			if _, iNdEx, err = decodeVarintGenerated(dAtA, iNdEx); err != nil {
+				return 0, err
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			if _, iNdEx, err = decodeBytesGenerated(dAtA, iNdEx); err != nil {
+				return 0, err
+			}
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupGenerated
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, %[1]s.Errorf("proto: illegal wireType %%d", wireType)
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, %[2]s.ErrUnexpectedEOF
+}`, fmtAlias, ioAlias)
+}
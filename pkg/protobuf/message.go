@@ -0,0 +1,389 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const metav1PkgPath = "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// scalarKind is the kind of the encoding of a single value.
+type scalarKind int
+
+const (
+	kindString scalarKind = iota
+	kindBytes
+	kindBool
+	kindVarint
+	kindDouble
+	kindFloat
+	kindMessage
+)
+
+// wireType returns the protobuf wire type of values of the kind.
+func (k scalarKind) wireType() int {
+	switch k {
+	case kindBool, kindVarint:
+		return 0
+	case kindDouble:
+		return 1
+	case kindFloat:
+		return 5
+	default:
+		return 2
+	}
+}
+
+// scalar describes the encoding of a single value (a field, an item of a
+// repeated field, or a key or value of a map).
+type scalar struct {
+	kind scalarKind
+	// goType is the Go type of the value, without the pointer.
+	goType types.Type
+	// pointer indicates that the value is a pointer to goType.
+	pointer bool
+	// protoType is the proto type of the value.
+	protoType string
+}
+
+// message is a message of the package.
+type message struct {
+	info   *markers.TypeInfo
+	fields []*protoField
+	// handWritten indicates that the type has hand-written marshalers, so
+	// it's only written to generated.proto.
+	handWritten bool
+}
+
+// protoField is a field of a message.
+type protoField struct {
+	// goName is the name of the Go field.
+	goName string
+	// name and number are the name and number of the proto field.
+	name   string
+	number int
+	doc    string
+	// goType is the Go type of the field.
+	goType types.Type
+
+	// repeated indicates a repeated field, with value as its items, and
+	// isMap a map, with key and value as its keys and values.
+	repeated bool
+	isMap    bool
+	key      scalar
+	value    scalar
+}
+
+// addType adds the given struct type as a message.
+func (f *protoFile) addType(info *markers.TypeInfo) {
+	if !ast.IsExported(info.Name) {
+		return
+	}
+	typeName, isTypeName := f.pkg.TypesInfo.Defs[info.RawSpec.Name].(*types.TypeName)
+	if !isTypeName {
+		return
+	}
+	if _, isStruct := typeName.Type().Underlying().(*types.Struct); !isStruct {
+		return
+	}
+	f.messages[info.Name] = &message{
+		info:        info,
+		handWritten: hasMarshalers(typeName.Type()),
+	}
+}
+
+// hasMarshalers checks whether the given type has the methods of marshalable
+// messages.
+func hasMarshalers(typ types.Type) bool {
+	methods := types.NewMethodSet(types.NewPointer(typ))
+	for _, name := range []string{"MarshalToSizedBuffer", "Size", "Unmarshal"} {
+		if methods.Lookup(nil, name) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isMetav1 checks whether the given type is the given type of metav1.
+func isMetav1(typ types.Type, name string) bool {
+	named, isNamed := types.Unalias(typ).(*types.Named)
+	return isNamed && named.Obj().Pkg() != nil &&
+		loader.NonVendorPath(named.Obj().Pkg().Path()) == metav1PkgPath && named.Obj().Name() == name
+}
+
+// generate computes the fields of the messages, and writes their marshalers.
+func (f *protoFile) generate() {
+	for _, name := range f.messageNames() {
+		msg := f.messages[name]
+		msg.fields = f.fieldsOf(msg.info)
+	}
+	if f.failed {
+		return
+	}
+	for _, name := range f.messageNames() {
+		if msg := f.messages[name]; !msg.handWritten {
+			f.writeMarshalers(name, msg.fields)
+		}
+	}
+	if f.goCode.Len() > 0 {
+		f.writeHelpers()
+	}
+}
+
+// fieldsOf returns the fields of the message of the given type, sorted by
+// number.
+func (f *protoFile) fieldsOf(info *markers.TypeInfo) []*protoField {
+	var fields, untagged []*protoField
+	numbers := make(map[int]string)
+	maxNumber := 0
+	for _, field := range info.Fields {
+		protoTag, hasProtoTag := field.Tag.Lookup("protobuf")
+		jsonTag, _ := field.Tag.Lookup("json")
+		if protoTag == "-" || jsonTag == "-" {
+			continue
+		}
+		fieldType := f.pkg.TypesInfo.TypeOf(field.RawField.Type)
+		goName := field.Name
+		if goName == "" {
+			// embedded
+			if isMetav1(fieldType, "TypeMeta") {
+				continue
+			}
+			elemType := fieldType
+			if ptr, isPtr := elemType.(*types.Pointer); isPtr {
+				elemType = ptr.Elem()
+			}
+			named, isNamed := types.Unalias(elemType).(*types.Named)
+			if !isNamed {
+				continue
+			}
+			goName = named.Obj().Name()
+		}
+		if !ast.IsExported(goName) {
+			continue
+		}
+
+		protoField := &protoField{
+			goName: goName,
+			name:   strings.Split(jsonTag, ",")[0],
+			doc:    field.Doc,
+			goType: fieldType,
+		}
+		if protoField.name == "" {
+			protoField.name = string(unicode.ToLower(rune(goName[0]))) + goName[1:]
+		}
+		if err := f.classifyField(protoField); err != nil {
+			f.pkg.AddError(loader.ErrFromNode(err, field.RawField))
+			f.failed = true
+			continue
+		}
+
+		if !hasProtoTag {
+			untagged = append(untagged, protoField)
+			fields = append(fields, protoField)
+			continue
+		}
+		tagParts := strings.Split(protoTag, ",")
+		number, err := 0, fmt.Errorf("missing field number")
+		if len(tagParts) > 1 {
+			number, err = strconv.Atoi(tagParts[1])
+		}
+		if err != nil || number <= 0 {
+			f.pkg.AddError(loader.ErrFromNode(fmt.Errorf("invalid protobuf tag %q: %v", protoTag, err), field.RawField))
+			f.failed = true
+			continue
+		}
+		for _, part := range tagParts[2:] {
+			if name, isName := strings.CutPrefix(part, "name="); isName {
+				protoField.name = name
+			}
+		}
+		if other, used := numbers[number]; used {
+			f.pkg.AddError(loader.ErrFromNode(fmt.Errorf("field number %d of %s is already used by %s", number, goName, other), field.RawField))
+			f.failed = true
+			continue
+		}
+		numbers[number] = goName
+		protoField.number = number
+		maxNumber = max(maxNumber, number)
+		fields = append(fields, protoField)
+	}
+
+	for _, field := range untagged {
+		maxNumber++
+		field.number = maxNumber
+	}
+	slices.SortFunc(fields, func(a, b *protoField) int {
+		return a.number - b.number
+	})
+	return fields
+}
+
+// classifyField fills in the shape and the encoding of the values of the
+// given field.
+func (f *protoFile) classifyField(field *protoField) error {
+	var err error
+	switch underlying := field.goType.Underlying().(type) {
+	case *types.Slice:
+		if isBytes(underlying) {
+			field.value, err = f.classify(field.goType)
+			return err
+		}
+		field.repeated = true
+		field.value, err = f.classify(underlying.Elem())
+		if err == nil && field.value.pointer && field.value.kind != kindMessage {
+			err = fmt.Errorf("unsupported type %s: only pointers to messages can be repeated", field.goType)
+		}
+		return err
+	case *types.Map:
+		field.isMap = true
+		if field.key, err = f.classify(underlying.Key()); err != nil {
+			return err
+		}
+		if field.key.kind != kindString || field.key.pointer {
+			return fmt.Errorf("unsupported type %s: only maps with string keys are supported", field.goType)
+		}
+		field.value, err = f.classify(underlying.Elem())
+		if err == nil && field.value.pointer && field.value.kind != kindMessage {
+			err = fmt.Errorf("unsupported type %s: only pointers to messages can be map values", field.goType)
+		}
+		return err
+	}
+	field.value, err = f.classify(field.goType)
+	return err
+}
+
+// isBytes checks whether the given slice is a slice of bytes.
+func isBytes(slice *types.Slice) bool {
+	basic, isBasic := slice.Elem().Underlying().(*types.Basic)
+	return isBasic && basic.Kind() == types.Uint8
+}
+
+// classify returns the encoding of single values of the given type.
+func (f *protoFile) classify(typ types.Type) (scalar, error) {
+	if ptr, isPtr := typ.(*types.Pointer); isPtr {
+		value, err := f.classify(ptr.Elem())
+		if err != nil {
+			return value, err
+		}
+		if value.pointer {
+			return value, fmt.Errorf("unsupported type %s: pointers to pointers can't be marshaled", typ)
+		}
+		value.pointer = true
+		return value, nil
+	}
+
+	value := scalar{goType: typ}
+	switch underlying := typ.Underlying().(type) {
+	case *types.Struct:
+		named, isNamed := types.Unalias(typ).(*types.Named)
+		if !isNamed {
+			return value, fmt.Errorf("unsupported type %s: anonymous structs can't be marshaled", typ)
+		}
+		value.kind = kindMessage
+		if named.Obj().Pkg().Path() == f.pkg.PkgPath {
+			if _, isMessage := f.messages[named.Obj().Name()]; !isMessage && !hasMarshalers(named) {
+				return value, fmt.Errorf("type %s isn't a message: enable protobuf generation for it", named.Obj().Name())
+			}
+			value.protoType = named.Obj().Name()
+			return value, nil
+		}
+		if !hasMarshalers(named) {
+			return value, fmt.Errorf("type %s has no protobuf marshalers", typ)
+		}
+		pkgPath := loader.NonVendorPath(named.Obj().Pkg().Path())
+		f.protoImports[pkgPath+"/"+protoFileName] = struct{}{}
+		value.protoType = "." + protoPackage(pkgPath) + "." + named.Obj().Name()
+		return value, nil
+	case *types.Slice:
+		if !isBytes(underlying) {
+			return value, fmt.Errorf("unsupported type %s: nested lists can't be marshaled", typ)
+		}
+		value.kind, value.protoType = kindBytes, "bytes"
+		return value, nil
+	case *types.Basic:
+		switch underlying.Kind() {
+		case types.String:
+			value.kind, value.protoType = kindString, "string"
+		case types.Bool:
+			value.kind, value.protoType = kindBool, "bool"
+		case types.Int8, types.Int16, types.Int32:
+			value.kind, value.protoType = kindVarint, "int32"
+		case types.Int, types.Int64:
+			value.kind, value.protoType = kindVarint, "int64"
+		case types.Uint8, types.Uint16, types.Uint32:
+			value.kind, value.protoType = kindVarint, "uint32"
+		case types.Uint, types.Uint64:
+			value.kind, value.protoType = kindVarint, "uint64"
+		case types.Float64:
+			value.kind, value.protoType = kindDouble, "double"
+		case types.Float32:
+			value.kind, value.protoType = kindFloat, "float"
+		default:
+			return value, fmt.Errorf("unsupported type %s", typ)
+		}
+		return value, nil
+	}
+	return value, fmt.Errorf("unsupported type %s", typ)
+}
+
+// writeComment writes the given documentation as proto comments, at the given
+// indentation.
+func writeComment(out *bytes.Buffer, doc, indent string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		if line == "" {
+			fmt.Fprintf(out, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(out, "%s// %s\n", indent, line)
+	}
+}
+
+// writeProto writes the definition of the message.
+func (m *message) writeProto(out *bytes.Buffer) {
+	writeComment(out, m.info.Doc, "")
+	fmt.Fprintf(out, "message %s {\n", m.info.Name)
+	for i, field := range m.fields {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		writeComment(out, field.doc, "  ")
+		switch {
+		case field.isMap:
+			fmt.Fprintf(out, "  map<%s, %s> %s = %d;\n", field.key.protoType, field.value.protoType, field.name, field.number)
+		case field.repeated:
+			fmt.Fprintf(out, "  repeated %s %s = %d;\n", field.value.protoType, field.name, field.number)
+		default:
+			fmt.Fprintf(out, "  optional %s %s = %d;\n", field.value.protoType, field.name, field.number)
+		}
+	}
+	out.WriteString("}\n")
+}
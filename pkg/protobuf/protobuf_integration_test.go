@@ -0,0 +1,41 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/protobuf"
+)
+
+var _ = Describe("Protobuf Generation", func() {
+	headerFile := filepath.Join("..", "..", "hack", "boilerplate", "boilerplate.generatego.txt")
+
+	It("should generate protobuf definitions and marshalers for the types", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{protobuf.Generator{HeaderFile: headerFile}}, "./api/...")
+		golden.CompareFiles(GinkgoT(), "testdata", out, "api/v1/generated.proto", "api/v1/generated.pb.go")
+	})
+
+	It("should fail with fields which can't be marshaled", func() {
+		errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{protobuf.Generator{}}, "./invalid/...")
+		Expect(errOut).To(ContainSubstring("only maps with string keys are supported"))
+	})
+})
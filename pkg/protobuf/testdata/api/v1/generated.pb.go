@@ -0,0 +1,1288 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (m *Port) Reset() { *m = Port{} }
+
+func (*Port) ProtoMessage() {}
+
+func (m *Port) String() string {
+	if m == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%+v", *m)
+}
+
+func (m *Port) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Port) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Port) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	i -= len(string(m.Protocol))
+	copy(dAtA[i:], string(m.Protocol))
+	i = encodeVarintGenerated(dAtA, i, uint64(len(string(m.Protocol))))
+	i--
+	dAtA[i] = 0x1a
+	i = encodeVarintGenerated(dAtA, i, uint64(m.Number))
+	i--
+	dAtA[i] = 0x10
+	i -= len(m.Name)
+	copy(dAtA[i:], m.Name)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Name)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *Port) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	n += 1 + l + sovGenerated(uint64(l))
+	n += 1 + sovGenerated(uint64(m.Number))
+	l = len(m.Protocol)
+	n += 1 + l + sovGenerated(uint64(l))
+	return n
+}
+
+func (m *Port) Unmarshal(dAtA []byte) error {
+	iNdEx := 0
+	for iNdEx < len(dAtA) {
+		preIndex := iNdEx
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = next
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Port: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Port: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Name = string(data)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Number", wireType)
+			}
+			v, next, err := decodeVarintGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Number = int32(v)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Protocol = Protocol(data)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > len(dAtA) {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+	return nil
+}
+
+func (m *Widget) Reset() { *m = Widget{} }
+
+func (*Widget) ProtoMessage() {}
+
+func (m *Widget) String() string {
+	if m == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%+v", *m)
+}
+
+func (m *Widget) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Widget) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Widget) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	{
+		size, err := m.Status.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x1a
+	{
+		size, err := m.Spec.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x12
+	{
+		size, err := m.ObjectMeta.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *Widget) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.ObjectMeta.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	l = m.Spec.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	l = m.Status.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	return n
+}
+
+func (m *Widget) Unmarshal(dAtA []byte) error {
+	iNdEx := 0
+	for iNdEx < len(dAtA) {
+		preIndex := iNdEx
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = next
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Widget: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Widget: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObjectMeta", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if err := m.ObjectMeta.Unmarshal(data); err != nil {
+				return err
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Spec", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if err := m.Spec.Unmarshal(data); err != nil {
+				return err
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if err := m.Status.Unmarshal(data); err != nil {
+				return err
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > len(dAtA) {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+	return nil
+}
+
+func (m *WidgetList) Reset() { *m = WidgetList{} }
+
+func (*WidgetList) ProtoMessage() {}
+
+func (m *WidgetList) String() string {
+	if m == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%+v", *m)
+}
+
+func (m *WidgetList) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WidgetList) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *WidgetList) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	for iNdEx := len(m.Items) - 1; iNdEx >= 0; iNdEx-- {
+		{
+			size, err := m.Items[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	{
+		size, err := m.ListMeta.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *WidgetList) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.ListMeta.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	for _, e := range m.Items {
+		l = e.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	return n
+}
+
+func (m *WidgetList) Unmarshal(dAtA []byte) error {
+	iNdEx := 0
+	for iNdEx < len(dAtA) {
+		preIndex := iNdEx
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = next
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WidgetList: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WidgetList: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ListMeta", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if err := m.ListMeta.Unmarshal(data); err != nil {
+				return err
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Items", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Items = append(m.Items, Widget{})
+			if err := m.Items[len(m.Items)-1].Unmarshal(data); err != nil {
+				return err
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > len(dAtA) {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+	return nil
+}
+
+func (m *WidgetSpec) Reset() { *m = WidgetSpec{} }
+
+func (*WidgetSpec) ProtoMessage() {}
+
+func (m *WidgetSpec) String() string {
+	if m == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%+v", *m)
+}
+
+func (m *WidgetSpec) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WidgetSpec) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *WidgetSpec) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	for iNdEx := len(m.Owners) - 1; iNdEx >= 0; iNdEx-- {
+		{
+			size, err := m.Owners[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x8a
+	}
+	for iNdEx := len(m.Protocols) - 1; iNdEx >= 0; iNdEx-- {
+		i -= len(string(m.Protocols[iNdEx]))
+		copy(dAtA[i:], string(m.Protocols[iNdEx]))
+		i = encodeVarintGenerated(dAtA, i, uint64(len(string(m.Protocols[iNdEx]))))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
+	for iNdEx := len(m.Counts) - 1; iNdEx >= 0; iNdEx-- {
+		i = encodeVarintGenerated(dAtA, i, uint64(m.Counts[iNdEx]))
+		i--
+		dAtA[i] = 0x78
+	}
+	for iNdEx := len(m.Tags) - 1; iNdEx >= 0; iNdEx-- {
+		i -= len(m.Tags[iNdEx])
+		copy(dAtA[i:], m.Tags[iNdEx])
+		i = encodeVarintGenerated(dAtA, i, uint64(len(m.Tags[iNdEx])))
+		i--
+		dAtA[i] = 0x72
+	}
+	if m.Data != nil {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintGenerated(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0x6a
+	}
+	i--
+	if m.Enabled {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i--
+	dAtA[i] = 0x60
+	i -= 4
+	binary.LittleEndian.PutUint32(dAtA[i:], math.Float32bits(float32(m.Ratio)))
+	i--
+	dAtA[i] = 0x5d
+	i -= 8
+	binary.LittleEndian.PutUint64(dAtA[i:], math.Float64bits(float64(m.Weight)))
+	i--
+	dAtA[i] = 0x51
+	{
+		size, err := m.Timeout.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x4a
+	{
+		size, err := m.Target.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x42
+	if m.Selector != nil {
+		{
+			size, err := m.Selector.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3a
+	}
+	if len(m.Templates) > 0 {
+		keysForTemplates := make([]string, 0, len(m.Templates))
+		for k := range m.Templates {
+			keysForTemplates = append(keysForTemplates, k)
+		}
+		sort.Strings(keysForTemplates)
+		for iNdEx := len(keysForTemplates) - 1; iNdEx >= 0; iNdEx-- {
+			v := m.Templates[keysForTemplates[iNdEx]]
+			baseI := i
+			{
+				size, err := v.MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGenerated(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+			i -= len(keysForTemplates[iNdEx])
+			copy(dAtA[i:], keysForTemplates[iNdEx])
+			i = encodeVarintGenerated(dAtA, i, uint64(len(keysForTemplates[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintGenerated(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if len(m.Labels) > 0 {
+		keysForLabels := make([]string, 0, len(m.Labels))
+		for k := range m.Labels {
+			keysForLabels = append(keysForLabels, k)
+		}
+		sort.Strings(keysForLabels)
+		for iNdEx := len(keysForLabels) - 1; iNdEx >= 0; iNdEx-- {
+			v := m.Labels[keysForLabels[iNdEx]]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintGenerated(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(keysForLabels[iNdEx])
+			copy(dAtA[i:], keysForLabels[iNdEx])
+			i = encodeVarintGenerated(dAtA, i, uint64(len(keysForLabels[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintGenerated(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	for iNdEx := len(m.Ports) - 1; iNdEx >= 0; iNdEx-- {
+		{
+			size, err := m.Ports[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Paused != nil {
+		i--
+		if *m.Paused {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Replicas != nil {
+		i = encodeVarintGenerated(dAtA, i, uint64(*m.Replicas))
+		i--
+		dAtA[i] = 0x10
+	}
+	i -= len(m.Image)
+	copy(dAtA[i:], m.Image)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Image)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *WidgetSpec) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Image)
+	n += 1 + l + sovGenerated(uint64(l))
+	if m.Replicas != nil {
+		n += 1 + sovGenerated(uint64(*m.Replicas))
+	}
+	if m.Paused != nil {
+		n += 2
+	}
+	for _, e := range m.Ports {
+		l = e.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	for k, v := range m.Labels {
+		_ = k
+		_ = v
+		mapEntrySize := 0
+		l = len(k)
+		mapEntrySize += 1 + l + sovGenerated(uint64(l))
+		l = len(v)
+		mapEntrySize += 1 + l + sovGenerated(uint64(l))
+		n += mapEntrySize + 1 + sovGenerated(uint64(mapEntrySize))
+	}
+	for k, v := range m.Templates {
+		_ = k
+		_ = v
+		mapEntrySize := 0
+		l = len(k)
+		mapEntrySize += 1 + l + sovGenerated(uint64(l))
+		l = v.Size()
+		mapEntrySize += 1 + l + sovGenerated(uint64(l))
+		n += mapEntrySize + 1 + sovGenerated(uint64(mapEntrySize))
+	}
+	if m.Selector != nil {
+		l = m.Selector.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	l = m.Target.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	l = m.Timeout.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	n += 9
+	n += 5
+	n += 2
+	if m.Data != nil {
+		l = len(m.Data)
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	for _, e := range m.Tags {
+		l = len(e)
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	for _, e := range m.Counts {
+		n += 1 + sovGenerated(uint64(e))
+	}
+	for _, e := range m.Protocols {
+		l = len(e)
+		n += 2 + l + sovGenerated(uint64(l))
+	}
+	for _, e := range m.Owners {
+		l = e.Size()
+		n += 2 + l + sovGenerated(uint64(l))
+	}
+	return n
+}
+
+func (m *WidgetSpec) Unmarshal(dAtA []byte) error {
+	iNdEx := 0
+	for iNdEx < len(dAtA) {
+		preIndex := iNdEx
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = next
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WidgetSpec: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WidgetSpec: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Image", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Image = string(data)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replicas", wireType)
+			}
+			v, next, err := decodeVarintGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			value := int32(v)
+			m.Replicas = &value
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Paused", wireType)
+			}
+			v, next, err := decodeVarintGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			value := v != 0
+			m.Paused = &value
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ports", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Ports = append(m.Ports, Port{})
+			if err := m.Ports[len(m.Ports)-1].Unmarshal(data); err != nil {
+				return err
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if m.Labels == nil {
+				m.Labels = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for entryIndex := 0; entryIndex < len(data); {
+				entryPreIndex := entryIndex
+				entryWire, entryNext, err := decodeVarintGenerated(data, entryIndex)
+				if err != nil {
+					return err
+				}
+				entryIndex = entryNext
+				switch entryWire >> 3 {
+				case 1:
+					if entryWireType := int(entryWire & 0x7); entryWireType != 2 {
+						return fmt.Errorf("proto: wrong wireType = %d for field Labels", entryWireType)
+					}
+					entryData, entryNext, err := decodeBytesGenerated(data, entryIndex)
+					if err != nil {
+						return err
+					}
+					entryIndex = entryNext
+					mapkey = string(entryData)
+				case 2:
+					if entryWireType := int(entryWire & 0x7); entryWireType != 2 {
+						return fmt.Errorf("proto: wrong wireType = %d for field Labels", entryWireType)
+					}
+					entryData, entryNext, err := decodeBytesGenerated(data, entryIndex)
+					if err != nil {
+						return err
+					}
+					entryIndex = entryNext
+					mapvalue = string(entryData)
+				default:
+					skippy, err := skipGenerated(data[entryPreIndex:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (entryPreIndex+skippy) < 0 {
+						return ErrInvalidLengthGenerated
+					}
+					if (entryPreIndex + skippy) > len(data) {
+						return io.ErrUnexpectedEOF
+					}
+					entryIndex = entryPreIndex + skippy
+				}
+			}
+			m.Labels[mapkey] = mapvalue
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Templates", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if m.Templates == nil {
+				m.Templates = make(map[string]Port)
+			}
+			var mapkey string
+			var mapvalue Port
+			for entryIndex := 0; entryIndex < len(data); {
+				entryPreIndex := entryIndex
+				entryWire, entryNext, err := decodeVarintGenerated(data, entryIndex)
+				if err != nil {
+					return err
+				}
+				entryIndex = entryNext
+				switch entryWire >> 3 {
+				case 1:
+					if entryWireType := int(entryWire & 0x7); entryWireType != 2 {
+						return fmt.Errorf("proto: wrong wireType = %d for field Templates", entryWireType)
+					}
+					entryData, entryNext, err := decodeBytesGenerated(data, entryIndex)
+					if err != nil {
+						return err
+					}
+					entryIndex = entryNext
+					mapkey = string(entryData)
+				case 2:
+					if entryWireType := int(entryWire & 0x7); entryWireType != 2 {
+						return fmt.Errorf("proto: wrong wireType = %d for field Templates", entryWireType)
+					}
+					entryData, entryNext, err := decodeBytesGenerated(data, entryIndex)
+					if err != nil {
+						return err
+					}
+					entryIndex = entryNext
+					if err := mapvalue.Unmarshal(entryData); err != nil {
+						return err
+					}
+				default:
+					skippy, err := skipGenerated(data[entryPreIndex:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (entryPreIndex+skippy) < 0 {
+						return ErrInvalidLengthGenerated
+					}
+					if (entryPreIndex + skippy) > len(data) {
+						return io.ErrUnexpectedEOF
+					}
+					entryIndex = entryPreIndex + skippy
+				}
+			}
+			m.Templates[mapkey] = mapvalue
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Selector", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if m.Selector == nil {
+				m.Selector = &metav1.LabelSelector{}
+			}
+			if err := m.Selector.Unmarshal(data); err != nil {
+				return err
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Target", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if err := m.Target.Unmarshal(data); err != nil {
+				return err
+			}
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if err := m.Timeout.Unmarshal(data); err != nil {
+				return err
+			}
+		case 10:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Weight", wireType)
+			}
+			v, next, err := decodeFixed64Generated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Weight = math.Float64frombits(v)
+		case 11:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ratio", wireType)
+			}
+			v, next, err := decodeFixed32Generated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Ratio = math.Float32frombits(v)
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Enabled", wireType)
+			}
+			v, next, err := decodeVarintGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Enabled = v != 0
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Data = append([]byte{}, data...)
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Tags = append(m.Tags, string(data))
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Counts", wireType)
+			}
+			v, next, err := decodeVarintGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Counts = append(m.Counts, int64(v))
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocols", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Protocols = append(m.Protocols, Protocol(data))
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owners", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Owners = append(m.Owners, &Port{})
+			if err := m.Owners[len(m.Owners)-1].Unmarshal(data); err != nil {
+				return err
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > len(dAtA) {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+	return nil
+}
+
+func (m *WidgetStatus) Reset() { *m = WidgetStatus{} }
+
+func (*WidgetStatus) ProtoMessage() {}
+
+func (m *WidgetStatus) String() string {
+	if m == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%+v", *m)
+}
+
+func (m *WidgetStatus) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WidgetStatus) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *WidgetStatus) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	i = encodeVarintGenerated(dAtA, i, uint64(m.ObservedGeneration))
+	i--
+	dAtA[i] = 0x18
+	if m.LastUpdated != nil {
+		{
+			size, err := m.LastUpdated.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	for iNdEx := len(m.Conditions) - 1; iNdEx >= 0; iNdEx-- {
+		{
+			size, err := m.Conditions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *WidgetStatus) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	for _, e := range m.Conditions {
+		l = e.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	if m.LastUpdated != nil {
+		l = m.LastUpdated.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	n += 1 + sovGenerated(uint64(m.ObservedGeneration))
+	return n
+}
+
+func (m *WidgetStatus) Unmarshal(dAtA []byte) error {
+	iNdEx := 0
+	for iNdEx < len(dAtA) {
+		preIndex := iNdEx
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return err
+		}
+		iNdEx = next
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WidgetStatus: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WidgetStatus: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Conditions", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.Conditions = append(m.Conditions, metav1.Condition{})
+			if err := m.Conditions[len(m.Conditions)-1].Unmarshal(data); err != nil {
+				return err
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastUpdated", wireType)
+			}
+			data, next, err := decodeBytesGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			if m.LastUpdated == nil {
+				m.LastUpdated = &metav1.Time{}
+			}
+			if err := m.LastUpdated.Unmarshal(data); err != nil {
+				return err
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObservedGeneration", wireType)
+			}
+			v, next, err := decodeVarintGenerated(dAtA, iNdEx)
+			if err != nil {
+				return err
+			}
+			iNdEx = next
+			m.ObservedGeneration = int64(v)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > len(dAtA) {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+	return nil
+}
+
+var (
+	ErrInvalidLengthGenerated        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowGenerated          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupGenerated = fmt.Errorf("proto: unexpected end of group")
+)
+
+func encodeVarintGenerated(dAtA []byte, offset int, v uint64) int {
+	offset -= sovGenerated(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+
+func sovGenerated(x uint64) (n int) {
+	return (bits.Len64(x|1) + 6) / 7
+}
+
+func decodeVarintGenerated(dAtA []byte, iNdEx int) (uint64, int, error) {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 64 {
+			return 0, 0, ErrIntOverflowGenerated
+		}
+		if iNdEx >= len(dAtA) {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		b := dAtA[iNdEx]
+		iNdEx++
+		v |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			return v, iNdEx, nil
+		}
+	}
+}
+
+func decodeBytesGenerated(dAtA []byte, iNdEx int) ([]byte, int, error) {
+	length, iNdEx, err := decodeVarintGenerated(dAtA, iNdEx)
+	if err != nil {
+		return nil, 0, err
+	}
+	postIndex := iNdEx + int(length)
+	if int(length) < 0 || postIndex < 0 {
+		return nil, 0, ErrInvalidLengthGenerated
+	}
+	if postIndex > len(dAtA) {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	return dAtA[iNdEx:postIndex], postIndex, nil
+}
+
+func decodeFixed64Generated(dAtA []byte, iNdEx int) (uint64, int, error) {
+	if iNdEx+8 > len(dAtA) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return binary.LittleEndian.Uint64(dAtA[iNdEx:]), iNdEx + 8, nil
+}
+
+func decodeFixed32Generated(dAtA []byte, iNdEx int) (uint32, int, error) {
+	if iNdEx+4 > len(dAtA) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	return binary.LittleEndian.Uint32(dAtA[iNdEx:]), iNdEx + 4, nil
+}
+
+func skipGenerated(dAtA []byte) (n int, err error) {
+	iNdEx := 0
+	depth := 0
+	for iNdEx < len(dAtA) {
+		wire, next, err := decodeVarintGenerated(dAtA, iNdEx)
+		if err != nil {
+			return 0, err
+		}
+		iNdEx = next
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			if _, iNdEx, err = decodeVarintGenerated(dAtA, iNdEx); err != nil {
+				return 0, err
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			if _, iNdEx, err = decodeBytesGenerated(dAtA, iNdEx); err != nil {
+				return 0, err
+			}
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupGenerated
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
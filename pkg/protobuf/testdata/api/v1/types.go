@@ -0,0 +1,136 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Protocol is a network protocol.
+type Protocol string
+
+// Port is a network port.
+type Port struct {
+	// Name is the name of the port.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// Number is the number of the port.
+	Number int32 `json:"number" protobuf:"varint,2,opt,name=number"`
+
+	// Protocol is the protocol of the port.
+	// +optional
+	Protocol Protocol `json:"protocol,omitempty" protobuf:"bytes,3,opt,name=protocol,casttype=Protocol"`
+}
+
+// WidgetSpec is the spec of a Widget.
+type WidgetSpec struct {
+	// Image is the image of the widget.
+	Image string `json:"image" protobuf:"bytes,1,opt,name=image"`
+
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty" protobuf:"varint,2,opt,name=replicas"`
+
+	// +optional
+	Paused *bool `json:"paused,omitempty" protobuf:"varint,3,opt,name=paused"`
+
+	// +optional
+	Ports []Port `json:"ports,omitempty" protobuf:"bytes,4,rep,name=ports"`
+
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" protobuf:"bytes,5,rep,name=labels"`
+
+	// +optional
+	Templates map[string]Port `json:"templates,omitempty" protobuf:"bytes,6,rep,name=templates"`
+
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" protobuf:"bytes,7,opt,name=selector"`
+
+	// Target is the port to target, by name or number.
+	Target intstr.IntOrString `json:"target" protobuf:"bytes,8,opt,name=target"`
+
+	// +optional
+	Timeout metav1.Duration `json:"timeout,omitempty" protobuf:"bytes,9,opt,name=timeout"`
+
+	// Fields without protobuf tags are numbered after the tagged ones.
+
+	// +optional
+	Weight float64 `json:"weight,omitempty"`
+
+	// +optional
+	Ratio float32 `json:"ratio,omitempty"`
+
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// +optional
+	Data []byte `json:"data,omitempty"`
+
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// +optional
+	Counts []int64 `json:"counts,omitempty"`
+
+	// +optional
+	Protocols []Protocol `json:"protocols,omitempty"`
+
+	// +optional
+	Owners []*Port `json:"owners,omitempty"`
+
+	// Cache isn't part of the message.
+	Cache string `json:"-"`
+}
+
+// WidgetStatus is the status of a Widget.
+type WidgetStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,1,rep,name=conditions"`
+
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty" protobuf:"bytes,2,opt,name=lastUpdated"`
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,3,opt,name=observedGeneration"`
+}
+
+// Widget is a kind with protobuf marshalers.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+	Status WidgetStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// WidgetList contains a list of Widgets.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	Items           []Widget `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// widgetCache isn't exported, so it has no message.
+type widgetCache struct {
+	widgets map[string]*Widget
+}
+
+// Settings is internal, so it has no message.
+// +kubebuilder:protobuf:generate=false
+type Settings struct {
+	Verbose bool `json:"verbose"`
+}
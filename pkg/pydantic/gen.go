@@ -0,0 +1,277 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pydantic
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates Pydantic v2 models for the kinds of each group-version.
+//
+// Each group-version is written to a Python module named
+// <group>_<version>.py, with dots and dashes of the group replaced by
+// underscores.  The module has a model per kind and per struct type used by
+// the kinds, with snake_case fields aliased to the JSON field names, and a
+// type alias per other type (e.g. a Literal of the values of an enum).
+// Validation markers are mapped to field constraints where Pydantic has an
+// equivalent, so CEL rules for instance aren't checked.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// Python has no issue with the types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	kubeKinds := crd.FindKubeKinds(parser, metav1Pkg)
+	slices.SortFunc(kubeKinds, func(a, b schema.GroupKind) int {
+		return strings.Compare(a.Kind, b.Kind)
+	})
+
+	modules := make(map[*loader.Package]*module)
+	for _, groupKind := range kubeKinds {
+		for _, root := range ctx.Roots {
+			gv, known := parser.GroupVersions[root]
+			ident := crd.TypeIdent{Package: root, Name: groupKind.Kind}
+			if !known || gv.Group != groupKind.Group || parser.Types[ident] == nil {
+				continue
+			}
+			mod, exists := modules[root]
+			if !exists {
+				mod = newModule(parser, root, gv)
+				modules[root] = mod
+			}
+			mod.kinds[ident] = struct{}{}
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		mod, exists := modules[root]
+		if !exists {
+			continue
+		}
+		kinds := make([]string, 0, len(mod.kinds))
+		for ident := range mod.kinds {
+			kinds = append(kinds, ident.Name)
+		}
+		slices.Sort(kinds)
+		for _, kind := range kinds {
+			mod.need(crd.TypeIdent{Package: root, Name: kind})
+		}
+
+		moduleName := strings.NewReplacer(".", "_", "-", "_").Replace(mod.gv.Group) + "_" + mod.gv.Version
+		if err := writeOut(ctx, moduleName+".py", mod.contents(headerText)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOut writes the given contents to the given file.
+func writeOut(ctx *genall.GenerationContext, fileName string, outBytes []byte) error {
+	out, err := ctx.Open(nil, fileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	n, err := out.Write(outBytes)
+	if err != nil {
+		return err
+	}
+	if n < len(outBytes) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// module collects the models of a group-version.
+type module struct {
+	parser *crd.Parser
+	pkg    *loader.Package
+	gv     schema.GroupVersion
+
+	// kinds are the kinds of the group-version.
+	kinds map[crd.TypeIdent]struct{}
+	// names are the Python names of the types, and taken the types by name.
+	names map[crd.TypeIdent]string
+	taken map[string]crd.TypeIdent
+	// written marks the types whose definitions are written (or being
+	// written, for recursive types).
+	written map[crd.TypeIdent]struct{}
+	// imports are the names imported from each Python module.
+	imports map[string]map[string]struct{}
+	// needsObjectMeta indicates that the ObjectMeta model is used.
+	needsObjectMeta bool
+
+	defs bytes.Buffer
+}
+
+func newModule(parser *crd.Parser, pkg *loader.Package, gv schema.GroupVersion) *module {
+	mod := &module{
+		parser:  parser,
+		pkg:     pkg,
+		gv:      gv,
+		kinds:   make(map[crd.TypeIdent]struct{}),
+		names:   make(map[crd.TypeIdent]string),
+		taken:   make(map[string]crd.TypeIdent),
+		written: make(map[crd.TypeIdent]struct{}),
+		imports: make(map[string]map[string]struct{}),
+	}
+	// the names used by the module itself aren't available to types
+	for _, name := range reservedNames {
+		mod.taken[name] = crd.TypeIdent{}
+	}
+	return mod
+}
+
+// use marks the given name of the given Python module as imported.
+func (m *module) use(pyModule, name string) {
+	if m.imports[pyModule] == nil {
+		m.imports[pyModule] = make(map[string]struct{})
+	}
+	m.imports[pyModule][name] = struct{}{}
+}
+
+// nameFor returns the Python name of the given type, qualifying it with its
+// package name if another type has the same name.
+func (m *module) nameFor(ident crd.TypeIdent) string {
+	if name, known := m.names[ident]; known {
+		return name
+	}
+	name := ident.Name
+	if _, isTaken := m.taken[name]; isTaken {
+		qualified := strings.ToUpper(ident.Package.Name[:1]) + ident.Package.Name[1:] + ident.Name
+		name = qualified
+		for i := 2; ; i++ {
+			if _, isTaken := m.taken[name]; !isTaken {
+				break
+			}
+			name = fmt.Sprintf("%s%d", qualified, i)
+		}
+	}
+	m.names[ident] = name
+	m.taken[name] = ident
+	return name
+}
+
+// contents returns the contents of the module.
+func (m *module) contents(headerText string) []byte {
+	out := new(bytes.Buffer)
+	for _, line := range strings.Split(strings.TrimSpace(headerText), "\n") {
+		switch {
+		case strings.HasPrefix(line, "/*"), strings.HasPrefix(line, "*/"):
+			// Python has no block comments
+		case line == "" && out.Len() == 0:
+		case line == "":
+			out.WriteString("#\n")
+		default:
+			fmt.Fprintf(out, "# %s\n", line)
+		}
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("# Code generated by controller-gen. DO NOT EDIT.\n\n")
+	out.WriteString("from __future__ import annotations\n\n")
+
+	m.use("pydantic", "BaseModel")
+	m.use("pydantic", "ConfigDict")
+	pyModules := make([]string, 0, len(m.imports))
+	for pyModule := range m.imports {
+		pyModules = append(pyModules, pyModule)
+	}
+	// the standard library first, then pydantic
+	slices.SortFunc(pyModules, func(a, b string) int {
+		if (a == "pydantic") != (b == "pydantic") {
+			if a == "pydantic" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+	for i, pyModule := range pyModules {
+		if pyModule == "pydantic" && i > 0 {
+			out.WriteString("\n")
+		}
+		names := make([]string, 0, len(m.imports[pyModule]))
+		for name := range m.imports[pyModule] {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		fmt.Fprintf(out, "from %s import %s\n", pyModule, strings.Join(names, ", "))
+	}
+
+	out.WriteString(baseModelDef)
+	if m.needsObjectMeta {
+		out.WriteString(objectMetaDef)
+	}
+	out.Write(m.defs.Bytes())
+	return out.Bytes()
+}
@@ -0,0 +1,475 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pydantic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+const metav1PkgPath = "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// baseModelDef is the definition of the base of all models.
+const baseModelDef = `
+
+class _BaseModel(BaseModel):
+    """Base of the models, accepting fields by name or by JSON name.
+
+    Dump models with model_dump(by_alias=True, exclude_none=True) to get
+    valid objects.
+    """
+
+    model_config = ConfigDict(populate_by_name=True)
+`
+
+// objectMetaDef is the definition of the ObjectMeta model, covering the
+// fields of metadata usually read or set by clients.
+const objectMetaDef = `
+
+class ObjectMeta(_BaseModel):
+    """ObjectMeta is the metadata of an object."""
+
+    annotations: dict[str, str] | None = None
+    creation_timestamp: datetime | None = Field(default=None, alias="creationTimestamp")
+    deletion_timestamp: datetime | None = Field(default=None, alias="deletionTimestamp")
+    finalizers: list[str] | None = None
+    generate_name: str | None = Field(default=None, alias="generateName")
+    generation: int | None = None
+    labels: dict[str, str] | None = None
+    name: str | None = None
+    namespace: str | None = None
+    resource_version: str | None = Field(default=None, alias="resourceVersion")
+    uid: str | None = None
+`
+
+// reservedNames are the names the generated modules use themselves.
+var reservedNames = []string{"Annotated", "Any", "BaseModel", "ConfigDict", "Field", "Literal", "ObjectMeta", "datetime"}
+
+// reservedAttributes are the attribute names which can't be used by fields,
+// being Python keywords or shadowing attributes of pydantic.BaseModel.
+var reservedAttributes = map[string]struct{}{
+	"False": {}, "None": {}, "True": {}, "and": {}, "as": {}, "assert": {}, "async": {}, "await": {},
+	"break": {}, "class": {}, "continue": {}, "def": {}, "del": {}, "elif": {}, "else": {}, "except": {},
+	"finally": {}, "for": {}, "from": {}, "global": {}, "if": {}, "import": {}, "in": {}, "is": {},
+	"lambda": {}, "nonlocal": {}, "not": {}, "or": {}, "pass": {}, "raise": {}, "return": {}, "try": {},
+	"while": {}, "with": {}, "yield": {},
+	"construct": {}, "copy": {}, "dict": {}, "from_orm": {}, "json": {}, "parse_file": {}, "parse_obj": {},
+	"parse_raw": {}, "schema": {}, "schema_json": {}, "update_forward_refs": {}, "validate": {},
+}
+
+// attributeName returns the snake_case Python attribute name of the given JSON
+// field name.
+func attributeName(fieldName string) string {
+	runes := []rune(fieldName)
+	var name strings.Builder
+	for i, char := range runes {
+		switch {
+		case unicode.IsUpper(char):
+			// start a word at "aB", and at "AAb" for the second capital
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				name.WriteRune('_')
+			}
+			name.WriteRune(unicode.ToLower(char))
+		case char < unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char)):
+			name.WriteRune(char)
+		default:
+			name.WriteRune('_')
+		}
+	}
+	attr := name.String()
+	if attr == "" || unicode.IsDigit(rune(attr[0])) || attr[0] == '_' {
+		// pydantic makes fields starting with an underscore private
+		attr = "field_" + attr
+	}
+	if _, reserved := reservedAttributes[attr]; reserved || strings.HasPrefix(attr, "model_") {
+		attr += "_"
+	}
+	return attr
+}
+
+// pyString returns the Python literal of the given string.
+func pyString(value string) string {
+	return pyLiteral(value)
+}
+
+// pyJSON returns the Python literal of the given JSON value.
+func pyJSON(raw []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return string(raw)
+	}
+	return pyLiteral(value)
+}
+
+// pyLiteral returns the Python literal of the given decoded JSON value.
+func pyLiteral(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if value {
+			return "True"
+		}
+		return "False"
+	case json.Number:
+		return value.String()
+	case string:
+		// JSON strings are valid Python strings
+		out := new(bytes.Buffer)
+		encoder := json.NewEncoder(out)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return strconv.Quote(value)
+		}
+		return strings.TrimSuffix(out.String(), "\n")
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, pyLiteral(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		entries := make([]string, 0, len(value))
+		for _, key := range keys {
+			entries = append(entries, pyLiteral(key)+": "+pyLiteral(value[key]))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+	return fmt.Sprint(value)
+}
+
+// writeDocstring writes the given description as a docstring, at the given
+// indentation.
+func writeDocstring(out *bytes.Buffer, description, indent string) {
+	if description == "" {
+		return
+	}
+	description = strings.ReplaceAll(description, `\`, `\\`)
+	description = strings.ReplaceAll(description, `"""`, `\"\"\"`)
+	lines := strings.Split(description, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(out, "%s\"\"\"%s\"\"\"\n", indent, description)
+		return
+	}
+	fmt.Fprintf(out, "%s\"\"\"%s\n", indent, lines[0])
+	for _, line := range lines[1:] {
+		if line == "" {
+			out.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(out, "%s%s\n", indent, line)
+	}
+	fmt.Fprintf(out, "%s\"\"\"\n", indent)
+}
+
+// isClass checks whether the given schema is written as a model.
+func isClass(typeSchema *apiextensionsv1.JSONSchemaProps) bool {
+	return typeSchema.Type == "object" && (len(typeSchema.Properties) > 0 || len(typeSchema.AllOf) > 0)
+}
+
+// resolve returns the type referred to by the given reference, from the
+// given package.
+func resolve(pkg *loader.Package, ref string) (crd.TypeIdent, error) {
+	typeName, pkgPath, err := crd.RefParts(ref)
+	if err != nil {
+		return crd.TypeIdent{}, err
+	}
+	if pkgPath == "" {
+		return crd.TypeIdent{Package: pkg, Name: typeName}, nil
+	}
+	return crd.TypeIdent{Package: pkg.Imports()[pkgPath], Name: typeName}, nil
+}
+
+// isMetav1 checks whether the given type is the given type of metav1.
+func isMetav1(ident crd.TypeIdent, name string) bool {
+	return ident.Package != nil && loader.NonVendorPath(ident.Package.PkgPath) == metav1PkgPath && ident.Name == name
+}
+
+// need returns the Python name of the given type, writing its definition
+// (after the ones it depends on) if not written yet.
+func (m *module) need(ident crd.TypeIdent) string {
+	name := m.nameFor(ident)
+	if _, written := m.written[ident]; written {
+		return name
+	}
+	m.written[ident] = struct{}{}
+
+	m.parser.NeedSchemaFor(ident)
+	typeSchema := m.parser.Schemata[ident]
+	def := new(bytes.Buffer)
+	if !isClass(&typeSchema) {
+		def.WriteString("\n")
+		fmt.Fprintf(def, "%s = %s\n", name, m.annotatedType(ident.Package, &typeSchema))
+		writeDocstring(def, typeSchema.Description, "")
+		m.defs.WriteString("\n")
+		m.defs.Write(def.Bytes())
+		return name
+	}
+
+	var bases []string
+	body := new(bytes.Buffer)
+	if _, isKind := m.kinds[ident]; isKind {
+		m.use("typing", "Literal")
+		m.use("pydantic", "Field")
+		fmt.Fprintf(body, "    api_version: Literal[%[1]s] = Field(default=%[1]s, alias=\"apiVersion\")\n", pyString(m.gv.String()))
+		fmt.Fprintf(body, "    kind: Literal[%[1]s] = %[1]s\n", pyString(ident.Name))
+	}
+	for _, embedded := range typeSchema.AllOf {
+		if embedded.Ref == nil {
+			m.writeFields(body, ident.Package, &embedded)
+			continue
+		}
+		embeddedIdent, err := resolve(ident.Package, *embedded.Ref)
+		if err != nil {
+			ident.Package.AddError(err)
+			continue
+		}
+		switch {
+		case embeddedIdent.Package == nil:
+		case isMetav1(embeddedIdent, "TypeMeta"):
+			if _, isKind := m.kinds[ident]; !isKind {
+				m.use("pydantic", "Field")
+				body.WriteString("    api_version: str | None = Field(default=None, alias=\"apiVersion\")\n")
+				body.WriteString("    kind: str | None = None\n")
+			}
+		default:
+			bases = append(bases, m.need(embeddedIdent))
+		}
+	}
+	m.writeFields(body, ident.Package, &typeSchema)
+	if typeSchema.XPreserveUnknownFields != nil && *typeSchema.XPreserveUnknownFields {
+		if body.Len() > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString("    model_config = ConfigDict(extra=\"allow\")\n")
+	}
+	if len(bases) == 0 {
+		bases = append(bases, "_BaseModel")
+	}
+
+	fmt.Fprintf(def, "\nclass %s(%s):\n", name, strings.Join(bases, ", "))
+	writeDocstring(def, typeSchema.Description, "    ")
+	switch {
+	case body.Len() == 0 && typeSchema.Description == "":
+		def.WriteString("    pass\n")
+	case body.Len() > 0 && typeSchema.Description != "":
+		def.WriteString("\n")
+		fallthrough
+	default:
+		def.Write(body.Bytes())
+	}
+	m.defs.WriteString("\n")
+	m.defs.Write(def.Bytes())
+	return name
+}
+
+// writeFields writes the fields of the properties of the given object schema,
+// whose references are relative to the given package.
+func (m *module) writeFields(out *bytes.Buffer, pkg *loader.Package, objSchema *apiextensionsv1.JSONSchemaProps) {
+	names := make([]string, 0, len(objSchema.Properties))
+	for name := range objSchema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop := objSchema.Properties[name]
+		required := slices.Contains(objSchema.Required, name)
+		attr := attributeName(name)
+
+		pyType := m.pyType(pkg, &prop)
+		if prop.Nullable || !required {
+			pyType += " | None"
+		}
+		var args []string
+		switch {
+		case prop.Default != nil:
+			args = append(args, "default="+pyJSON(prop.Default.Raw))
+		case !required:
+			args = append(args, "default=None")
+		}
+		if attr != name {
+			args = append(args, "alias="+pyString(name))
+		}
+		if prop.Description != "" {
+			args = append(args, "description="+pyString(prop.Description))
+		}
+		args = append(args, constraints(&prop)...)
+
+		switch {
+		case len(args) == 0:
+			fmt.Fprintf(out, "    %s: %s\n", attr, pyType)
+		case len(args) == 1 && args[0] == "default=None":
+			fmt.Fprintf(out, "    %s: %s = None\n", attr, pyType)
+		default:
+			m.use("pydantic", "Field")
+			fmt.Fprintf(out, "    %s: %s = Field(%s)\n", attr, pyType, strings.Join(args, ", "))
+		}
+	}
+}
+
+// constraints returns the arguments of pydantic.Field checking the
+// validations of the given schema which have a Pydantic equivalent.
+func constraints(valueSchema *apiextensionsv1.JSONSchemaProps) []string {
+	var args []string
+	number := func(value float64) string {
+		if valueSchema.Type == "integer" {
+			return strconv.FormatInt(int64(value), 10)
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	if valueSchema.Minimum != nil {
+		op := "ge"
+		if valueSchema.ExclusiveMinimum {
+			op = "gt"
+		}
+		args = append(args, op+"="+number(*valueSchema.Minimum))
+	}
+	if valueSchema.Maximum != nil {
+		op := "le"
+		if valueSchema.ExclusiveMaximum {
+			op = "lt"
+		}
+		args = append(args, op+"="+number(*valueSchema.Maximum))
+	}
+	if valueSchema.MultipleOf != nil {
+		args = append(args, "multiple_of="+number(*valueSchema.MultipleOf))
+	}
+
+	// Pydantic checks the length of strings, lists and dicts alike
+	minLength, maxLength := valueSchema.MinLength, valueSchema.MaxLength
+	switch valueSchema.Type {
+	case "array":
+		minLength, maxLength = valueSchema.MinItems, valueSchema.MaxItems
+	case "object":
+		minLength, maxLength = valueSchema.MinProperties, valueSchema.MaxProperties
+	}
+	if minLength != nil {
+		args = append(args, fmt.Sprintf("min_length=%d", *minLength))
+	}
+	if maxLength != nil {
+		args = append(args, fmt.Sprintf("max_length=%d", *maxLength))
+	}
+	if valueSchema.Pattern != "" {
+		args = append(args, "pattern="+pyString(valueSchema.Pattern))
+	}
+	return args
+}
+
+// annotatedType returns the Python type of values of the given schema, whose
+// references are relative to the given package, annotated with the
+// constraints of the schema.
+func (m *module) annotatedType(pkg *loader.Package, valueSchema *apiextensionsv1.JSONSchemaProps) string {
+	pyType := m.pyType(pkg, valueSchema)
+	if valueSchema.Nullable {
+		pyType += " | None"
+	}
+	args := constraints(valueSchema)
+	if len(args) == 0 {
+		return pyType
+	}
+	m.use("typing", "Annotated")
+	m.use("pydantic", "Field")
+	return fmt.Sprintf("Annotated[%s, Field(%s)]", pyType, strings.Join(args, ", "))
+}
+
+// pyType returns the Python type of values of the given schema, whose
+// references are relative to the given package.
+func (m *module) pyType(pkg *loader.Package, valueSchema *apiextensionsv1.JSONSchemaProps) string {
+	switch {
+	case len(valueSchema.Enum) > 0:
+		m.use("typing", "Literal")
+		values := make([]string, 0, len(valueSchema.Enum))
+		for _, value := range valueSchema.Enum {
+			values = append(values, pyJSON(value.Raw))
+		}
+		return "Literal[" + strings.Join(values, ", ") + "]"
+	case valueSchema.Ref != nil:
+		return m.refType(pkg, *valueSchema.Ref)
+	case valueSchema.XIntOrString:
+		return "int | str"
+	case valueSchema.Type == "string" && valueSchema.Format == "date-time":
+		m.use("datetime", "datetime")
+		return "datetime"
+	case valueSchema.Type == "string":
+		return "str"
+	case valueSchema.Type == "integer":
+		return "int"
+	case valueSchema.Type == "number":
+		return "float"
+	case valueSchema.Type == "boolean":
+		return "bool"
+	case valueSchema.Type == "array":
+		if valueSchema.Items == nil || valueSchema.Items.Schema == nil {
+			m.use("typing", "Any")
+			return "list[Any]"
+		}
+		return "list[" + m.annotatedType(pkg, valueSchema.Items.Schema) + "]"
+	case valueSchema.Type == "object" && valueSchema.AdditionalProperties != nil && valueSchema.AdditionalProperties.Schema != nil:
+		return "dict[str, " + m.annotatedType(pkg, valueSchema.AdditionalProperties.Schema) + "]"
+	case valueSchema.Type == "object":
+		m.use("typing", "Any")
+		return "dict[str, Any]"
+	}
+	m.use("typing", "Any")
+	return "Any"
+}
+
+// refType returns the Python type of the type referred to by the given
+// reference, from the given package.
+func (m *module) refType(pkg *loader.Package, ref string) string {
+	ident, err := resolve(pkg, ref)
+	if err != nil {
+		pkg.AddError(err)
+		m.use("typing", "Any")
+		return "Any"
+	}
+	switch {
+	case ident.Package == nil:
+		m.use("typing", "Any")
+		return "Any"
+	case isMetav1(ident, "ObjectMeta"):
+		m.needsObjectMeta = true
+		m.use("datetime", "datetime")
+		m.use("pydantic", "Field")
+		return "ObjectMeta"
+	}
+	m.parser.NeedSchemaFor(ident)
+	refSchema := m.parser.Schemata[ident]
+	if ident.Package != m.pkg && !isClass(&refSchema) {
+		// inline the simple types of other packages, like metav1.Time
+		return m.annotatedType(ident.Package, &refSchema)
+	}
+	return m.need(ident)
+}
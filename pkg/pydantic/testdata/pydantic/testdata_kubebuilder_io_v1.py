@@ -0,0 +1,110 @@
+# Copyright The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Code generated by controller-gen. DO NOT EDIT.
+
+from __future__ import annotations
+
+from datetime import datetime
+from typing import Annotated, Any, Literal
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class _BaseModel(BaseModel):
+    """Base of the models, accepting fields by name or by JSON name.
+
+    Dump models with model_dump(by_alias=True, exclude_none=True) to get
+    valid objects.
+    """
+
+    model_config = ConfigDict(populate_by_name=True)
+
+
+class ObjectMeta(_BaseModel):
+    """ObjectMeta is the metadata of an object."""
+
+    annotations: dict[str, str] | None = None
+    creation_timestamp: datetime | None = Field(default=None, alias="creationTimestamp")
+    deletion_timestamp: datetime | None = Field(default=None, alias="deletionTimestamp")
+    finalizers: list[str] | None = None
+    generate_name: str | None = Field(default=None, alias="generateName")
+    generation: int | None = None
+    labels: dict[str, str] | None = None
+    name: str | None = None
+    namespace: str | None = None
+    resource_version: str | None = Field(default=None, alias="resourceVersion")
+    uid: str | None = None
+
+
+class CommonSpec(_BaseModel):
+    """CommonSpec holds the fields shared by specs."""
+
+    paused: bool | None = Field(default=None, description="Paused stops the reconciliation of the object.")
+
+
+Protocol = Literal["TCP", "UDP"]
+"""Protocol is a network protocol."""
+
+
+class Port(_BaseModel):
+    """Port is a network port."""
+
+    name: str = Field(description="Name is the name of the port.", pattern="^[a-z]+$")
+    number: int = Field(description="Number is the number of the port.", ge=1, le=65535)
+    protocol: Protocol | None = Field(default="TCP", description="Protocol is the protocol of the port.")
+
+
+class WidgetSpec(CommonSpec):
+    """WidgetSpec is the spec of a Widget."""
+
+    config: dict[str, Any] | None = None
+    count_limit: int | None = Field(default=None, alias="count-limit")
+    from_: str | None = Field(default=None, alias="from")
+    http_headers: dict[str, str] | None = Field(default=None, alias="httpHeaders")
+    image: str = Field(description="Image is the image of the widget.\n\nIt must not be empty.", min_length=1, max_length=253)
+    labels: dict[str, str] | None = Field(default=None, max_length=8)
+    ports: list[Port] | None = None
+    priority: Literal["Low", "High"] | None = None
+    replicas: int | None = Field(default=2, gt=0, multiple_of=2)
+    tags: list[Annotated[str, Field(max_length=16)]] | None = Field(default=None, min_length=1)
+    target: int | str | None = None
+
+
+class Condition(_BaseModel):
+    """Condition contains details for one aspect of the current state of this API Resource."""
+
+    last_transition_time: datetime = Field(alias="lastTransitionTime", description="lastTransitionTime is the last time the condition transitioned from one status to another.\nThis should be when the underlying condition changed.  If that is not known, then using the time when the API field changed is acceptable.")
+    message: str = Field(description="message is a human readable message indicating details about the transition.\nThis may be an empty string.", max_length=32768)
+    observed_generation: int | None = Field(default=None, alias="observedGeneration", description="observedGeneration represents the .metadata.generation that the condition was set based upon.\nFor instance, if .metadata.generation is currently 12, but the .status.conditions[x].observedGeneration is 9, the condition is out of date\nwith respect to the current state of the instance.", ge=0)
+    reason: str = Field(description="reason contains a programmatic identifier indicating the reason for the condition's last transition.\nProducers of specific condition types may define expected values and meanings for this field,\nand whether the values are considered a guaranteed API.\nThe value should be a CamelCase string.\nThis field may not be empty.", min_length=1, max_length=1024, pattern="^[A-Za-z]([A-Za-z0-9_,:]*[A-Za-z0-9_])?$")
+    status: Literal["True", "False", "Unknown"] = Field(description="status of the condition, one of True, False, Unknown.")
+    type: str = Field(description="type of condition in CamelCase or in foo.example.com/CamelCase.", max_length=316, pattern="^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])$")
+
+
+class WidgetStatus(_BaseModel):
+    """WidgetStatus is the status of a Widget."""
+
+    conditions: list[Condition] | None = None
+    last_updated: datetime | None = Field(default=None, alias="lastUpdated")
+
+
+class Widget(_BaseModel):
+    """Widget is a kind with Pydantic models."""
+
+    api_version: Literal["testdata.kubebuilder.io/v1"] = Field(default="testdata.kubebuilder.io/v1", alias="apiVersion")
+    kind: Literal["Widget"] = "Widget"
+    metadata: ObjectMeta | None = None
+    spec: WidgetSpec | None = None
+    status: WidgetStatus | None = None
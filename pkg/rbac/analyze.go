@@ -0,0 +1,372 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	// clientPkgPath is the import path of the controller-runtime client package.
+	clientPkgPath = "sigs.k8s.io/controller-runtime/pkg/client"
+	// controllerutilPkgPath is the import path of the controller-runtime
+	// controllerutil package.
+	controllerutilPkgPath = "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var (
+	// clientVerbs maps the methods of controller-runtime clients to RBAC verbs.
+	clientVerbs = map[string]string{
+		"Get":         "get",
+		"List":        "list",
+		"Watch":       "watch",
+		"Create":      "create",
+		"Update":      "update",
+		"Patch":       "patch",
+		"Apply":       "patch",
+		"Delete":      "delete",
+		"DeleteAllOf": "deletecollection",
+	}
+)
+
+// ClientCall is a call to a controller-runtime client, along with the
+// permission it needs.
+type ClientCall struct {
+	// Package is the package containing the call.
+	Package *loader.Package
+	// Node is the call expression.
+	Node ast.Node
+
+	// Group is the API group of the object passed to the call.
+	Group string
+	// Resource is the resource (possibly with a subresource) accessed by the call.
+	Resource string
+	// Verb is the verb needed for the call.
+	Verb string
+}
+
+// Marker returns an rbac marker granting the permission needed for the call.
+func (c ClientCall) Marker() string {
+	group := c.Group
+	if group == "" {
+		group = `""`
+	}
+	return fmt.Sprintf("+%s:groups=%s,resources=%s,verbs=%s", RuleDefinition.Name, group, c.Resource, c.Verb)
+}
+
+// AnalyzeClientCalls finds calls to controller-runtime clients in the given
+// roots, figuring out the permission needed for each of them from the type of
+// the object passed to the call.
+//
+// This is experimental: the API group comes from the +groupName marker of the
+// object's package, and the resource is guessed by pluralizing the kind, so
+// calls for objects in packages without that marker (e.g. unstructured
+// objects) are skipped, and custom resource names aren't taken into account.
+func AnalyzeClientCalls(ctx *genall.GenerationContext) []ClientCall {
+	checker := ctx.Checker
+	if checker == nil {
+		// the rbac generator doesn't ask for type-checking, since it doesn't
+		// need it outside of this analysis
+		checker = &loader.TypeChecker{}
+	}
+
+	var calls []ClientCall
+	for _, root := range ctx.Roots {
+		info := checkBodies(checker, root)
+
+		for _, file := range root.Syntax {
+			ast.Inspect(file, func(node ast.Node) bool {
+				callExpr, isCall := node.(*ast.CallExpr)
+				if !isCall {
+					return true
+				}
+				if call, ok := analyzeClientCall(ctx.Collector, root, info, callExpr); ok {
+					calls = append(calls, call)
+				}
+				if call, ok := analyzeOwnerReferenceCall(ctx.Collector, root, info, callExpr); ok {
+					calls = append(calls, call)
+				}
+				return true
+			})
+		}
+	}
+	return calls
+}
+
+// importerFunc implements types.Importer with a function.
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// checkBodies type-checks the given package including its function bodies,
+// which the loader skips, returning the resulting type information.  Type
+// errors are ignored, since this is a best-effort analysis.
+func checkBodies(checker *loader.TypeChecker, root *loader.Package) *types.Info {
+	// NB: this also makes sure the syntax and file set are loaded
+	checker.Check(root)
+	root.NeedTypesInfo()
+	imports := root.Imports()
+	for _, imported := range imports {
+		checker.Check(imported)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	config := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			imported := imports[path]
+			if imported == nil || imported.Types == nil {
+				return nil, fmt.Errorf("package %q not loaded", path)
+			}
+			return imported.Types, nil
+		}),
+		Error: func(error) {},
+		Sizes: root.TypesSizes,
+	}
+	_, _ = config.Check(root.PkgPath, root.Fset, root.Syntax, info)
+	return info
+}
+
+// analyzeClientCall figures out the permission needed by the given call, if
+// it's a call to a controller-runtime client.
+func analyzeClientCall(col *markers.Collector, root *loader.Package, info *types.Info, callExpr *ast.CallExpr) (ClientCall, bool) {
+	selExpr, isSel := callExpr.Fun.(*ast.SelectorExpr)
+	if !isSel {
+		return ClientCall{}, false
+	}
+	selection := info.Selections[selExpr]
+	if selection == nil {
+		return ClientCall{}, false
+	}
+	method, isFunc := selection.Obj().(*types.Func)
+	if !isFunc || method.Pkg() == nil || method.Pkg().Path() != clientPkgPath {
+		return ClientCall{}, false
+	}
+	verb, isClientVerb := clientVerbs[method.Name()]
+	if !isClientVerb {
+		return ClientCall{}, false
+	}
+
+	// calls on Status() or SubResource(name) access the subresource of the object
+	subResource, isSubResource := subResourceOf(selExpr.X)
+	objArg := 1
+	if method.Name() == "Get" && !isSubResource {
+		objArg = 2
+	}
+	if len(callExpr.Args) <= objArg {
+		return ClientCall{}, false
+	}
+
+	group, resource, ok := resourceOf(col, root, info.TypeOf(callExpr.Args[objArg]))
+	if !ok {
+		return ClientCall{}, false
+	}
+	if isSubResource {
+		resource += "/" + subResource
+	}
+
+	return ClientCall{
+		Package:  root,
+		Node:     callExpr,
+		Group:    group,
+		Resource: resource,
+		Verb:     verb,
+	}, true
+}
+
+// analyzeOwnerReferenceCall figures out the permission needed by the given
+// call, if it's a call to controllerutil.SetControllerReference.
+//
+// Controller references block the deletion of their owner, which (with the
+// OwnerReferencesPermissionEnforcement admission plugin) requires permission
+// to update the finalizers subresource of the owner.
+func analyzeOwnerReferenceCall(col *markers.Collector, root *loader.Package, info *types.Info, callExpr *ast.CallExpr) (ClientCall, bool) {
+	selExpr, isSel := callExpr.Fun.(*ast.SelectorExpr)
+	if !isSel {
+		return ClientCall{}, false
+	}
+	function, isFunc := info.Uses[selExpr.Sel].(*types.Func)
+	if !isFunc || function.Pkg() == nil || function.Pkg().Path() != controllerutilPkgPath || function.Name() != "SetControllerReference" {
+		return ClientCall{}, false
+	}
+	if len(callExpr.Args) == 0 {
+		return ClientCall{}, false
+	}
+
+	group, resource, ok := resourceOf(col, root, info.TypeOf(callExpr.Args[0]))
+	if !ok {
+		return ClientCall{}, false
+	}
+	return ClientCall{
+		Package:  root,
+		Node:     callExpr,
+		Group:    group,
+		Resource: resource + "/finalizers",
+		Verb:     "update",
+	}, true
+}
+
+// resourceOf figures out the API group and resource of the given object type,
+// from the +groupName marker of the package of the type and its (pluralized)
+// kind.
+func resourceOf(col *markers.Collector, root *loader.Package, objType types.Type) (group, resource string, ok bool) {
+	if ptr, isPtr := objType.(*types.Pointer); isPtr {
+		objType = ptr.Elem()
+	}
+	named, isNamed := objType.(*types.Named)
+	if !isNamed || named.Obj().Pkg() == nil {
+		return "", "", false
+	}
+
+	objPkg := findPackage(root, named.Obj().Pkg().Path())
+	if objPkg == nil {
+		return "", "", false
+	}
+	pkgMarkers, err := markers.PackageMarkers(col, objPkg)
+	if err != nil {
+		// not our problem to report, just skip it
+		return "", "", false
+	}
+	gv := crd.GroupVersionForPackage(pkgMarkers, objPkg)
+	if gv.Version == "" {
+		return "", "", false
+	}
+
+	kind := named.Obj().Name()
+	if itemKind, isList := strings.CutSuffix(kind, "List"); isList && named.Obj().Pkg().Scope().Lookup(itemKind) != nil {
+		kind = itemKind
+	}
+	return gv.Group, strings.ToLower(flect.Pluralize(kind)), true
+}
+
+// subResourceOf returns the subresource accessed via the given receiver of a
+// client call, i.e. "status" for c.Status(), or name for c.SubResource("name").
+func subResourceOf(recv ast.Expr) (string, bool) {
+	callExpr, isCall := recv.(*ast.CallExpr)
+	if !isCall {
+		return "", false
+	}
+	selExpr, isSel := callExpr.Fun.(*ast.SelectorExpr)
+	if !isSel {
+		return "", false
+	}
+	switch selExpr.Sel.Name {
+	case "Status":
+		return "status", true
+	case "SubResource":
+		if len(callExpr.Args) != 1 {
+			return "", false
+		}
+		lit, isLit := callExpr.Args[0].(*ast.BasicLit)
+		if !isLit || lit.Kind != token.STRING {
+			return "", false
+		}
+		name, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return "", false
+		}
+		return name, true
+	default:
+		return "", false
+	}
+}
+
+// findPackage finds the package with the given path among the given root
+// and its (transitive) imports.
+func findPackage(root *loader.Package, pkgPath string) *loader.Package {
+	seen := map[string]bool{root.PkgPath: true}
+	queue := []*loader.Package{root}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if pkg.PkgPath == pkgPath {
+			return pkg
+		}
+		for _, imported := range pkg.Imports() {
+			if !seen[imported.PkgPath] {
+				seen[imported.PkgPath] = true
+				queue = append(queue, imported)
+			}
+		}
+	}
+	return nil
+}
+
+// UncoveredClientCalls returns the given calls whose permissions aren't
+// granted by any of the given roles (as returned by GenerateRoles).
+func UncoveredClientCalls(calls []ClientCall, roles []any) []ClientCall {
+	var rules []*Rule
+	for _, role := range roles {
+		var policyRules []rbacv1.PolicyRule
+		switch role := role.(type) {
+		case rbacv1.ClusterRole:
+			// aggregated roles are for users, not for the controller itself
+			if isAggregated(role) {
+				continue
+			}
+			policyRules = role.Rules
+		case rbacv1.Role:
+			policyRules = role.Rules
+		}
+		for _, policyRule := range policyRules {
+			rules = append(rules, &Rule{
+				Groups:        policyRule.APIGroups,
+				Resources:     policyRule.Resources,
+				ResourceNames: policyRule.ResourceNames,
+				Verbs:         policyRule.Verbs,
+				URLs:          policyRule.NonResourceURLs,
+			})
+		}
+	}
+
+	var uncovered []ClientCall
+	for _, call := range calls {
+		needed := &Rule{Groups: []string{call.Group}, Resources: []string{call.Resource}, Verbs: []string{call.Verb}}
+		covered := false
+		for _, rule := range rules {
+			if rule.covers(needed) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			uncovered = append(uncovered, call)
+		}
+	}
+	return uncovered
+}
@@ -0,0 +1,220 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// RoleDrift describes the differences between the permissions of a generated
+// role and those of the corresponding existing role.
+type RoleDrift struct {
+	// Kind is the kind of the role (ClusterRole or Role).
+	Kind string
+	// Namespace is the namespace of the role (empty for ClusterRoles).
+	Namespace string
+	// Name is the name of the role.
+	Name string
+
+	// Missing lists permissions that are generated, but not granted by the
+	// existing role.
+	Missing []string
+	// Extra lists permissions that are granted by the existing role, but not
+	// generated (i.e. that aren't needed anymore).
+	Extra []string
+}
+
+func (d RoleDrift) String() string {
+	var out strings.Builder
+	name := d.Name
+	if d.Namespace != "" {
+		name = d.Namespace + "/" + d.Name
+	}
+	fmt.Fprintf(&out, "%s %s:", d.Kind, name)
+	for _, perm := range d.Missing {
+		fmt.Fprintf(&out, "\n  missing: %s", perm)
+	}
+	for _, perm := range d.Extra {
+		fmt.Fprintf(&out, "\n  extra: %s", perm)
+	}
+	return out.String()
+}
+
+// roleID identifies a role for the purposes of auditing.
+type roleID struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// AuditRoles compares the given generated roles (as returned by GenerateRoles)
+// against the ClusterRoles and Roles in the given (potentially multi-document)
+// YAML, returning the drift for each role whose permissions differ.  Existing
+// roles that aren't generated are ignored, so the YAML may contain unrelated
+// roles (e.g. the output of "kubectl get clusterroles -o yaml").
+//
+// Permissions are compared after expanding each rule into individual
+// (group, resource, resource name, verb) or (URL, verb) tuples, so differences
+// in how rules are grouped don't count as drift, but wildcards are compared
+// literally.
+func AuditRoles(generated []any, existingYAML []byte) ([]RoleDrift, error) {
+	existing, err := parseRoles(existingYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[roleID][]rbacv1.PolicyRule)
+	var ids []roleID
+	for _, obj := range generated {
+		var id roleID
+		var rules []rbacv1.PolicyRule
+		switch role := obj.(type) {
+		case rbacv1.ClusterRole:
+			id, rules = roleID{kind: "ClusterRole", name: role.Name}, role.Rules
+		case rbacv1.Role:
+			id, rules = roleID{kind: "Role", namespace: role.Namespace, name: role.Name}, role.Rules
+		default:
+			continue
+		}
+		wanted[id] = rules
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b roleID) int {
+		return strings.Compare(a.kind+"/"+a.namespace+"/"+a.name, b.kind+"/"+b.namespace+"/"+b.name)
+	})
+
+	var drifts []RoleDrift
+	for _, id := range ids {
+		wantedPerms := expandRules(wanted[id])
+		existingPerms := expandRules(existing[id])
+
+		drift := RoleDrift{Kind: id.kind, Namespace: id.namespace, Name: id.name}
+		for perm := range wantedPerms {
+			if _, granted := existingPerms[perm]; !granted {
+				drift.Missing = append(drift.Missing, perm)
+			}
+		}
+		for perm := range existingPerms {
+			if _, needed := wantedPerms[perm]; !needed {
+				drift.Extra = append(drift.Extra, perm)
+			}
+		}
+		if len(drift.Missing) == 0 && len(drift.Extra) == 0 {
+			continue
+		}
+		slices.Sort(drift.Missing)
+		slices.Sort(drift.Extra)
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// parseRoles extracts the rules of all ClusterRoles and Roles in the given YAML.
+func parseRoles(rawYAML []byte) (map[roleID][]rbacv1.PolicyRule, error) {
+	res := make(map[roleID][]rbacv1.PolicyRule)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(rawYAML)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read existing roles: %w", err)
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, fmt.Errorf("unable to parse existing roles: %w", err)
+		}
+		if typeMeta.APIVersion == "v1" && typeMeta.Kind == "List" {
+			var list struct {
+				Items []json.RawMessage `json:"items"`
+			}
+			if err := yaml.Unmarshal(doc, &list); err != nil {
+				return nil, fmt.Errorf("unable to parse existing roles: %w", err)
+			}
+			for _, item := range list.Items {
+				itemRoles, err := parseRoles(item)
+				if err != nil {
+					return nil, err
+				}
+				maps.Copy(res, itemRoles)
+			}
+			continue
+		}
+		if typeMeta.APIVersion != rbacv1.SchemeGroupVersion.String() {
+			continue
+		}
+
+		switch typeMeta.Kind {
+		case "ClusterRole":
+			var role rbacv1.ClusterRole
+			if err := yaml.Unmarshal(doc, &role); err != nil {
+				return nil, fmt.Errorf("unable to parse existing ClusterRole: %w", err)
+			}
+			res[roleID{kind: "ClusterRole", name: role.Name}] = role.Rules
+		case "Role":
+			var role rbacv1.Role
+			if err := yaml.Unmarshal(doc, &role); err != nil {
+				return nil, fmt.Errorf("unable to parse existing Role: %w", err)
+			}
+			res[roleID{kind: "Role", namespace: role.Namespace, name: role.Name}] = role.Rules
+		}
+	}
+	return res, nil
+}
+
+// expandRules expands the given rules into the set of individual permissions
+// that they grant.
+func expandRules(rules []rbacv1.PolicyRule) map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			for _, url := range rule.NonResourceURLs {
+				res[fmt.Sprintf("%s %s", verb, url)] = struct{}{}
+			}
+			for _, group := range rule.APIGroups {
+				if group == "" {
+					group = "core"
+				}
+				for _, resource := range rule.Resources {
+					if len(rule.ResourceNames) == 0 {
+						res[fmt.Sprintf("%s %s/%s", verb, group, resource)] = struct{}{}
+						continue
+					}
+					for _, name := range rule.ResourceNames {
+						res[fmt.Sprintf("%s %s/%s (name %s)", verb, group, resource, name)] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+const (
+	// helmNamespace is the Helm template for the namespace the chart is installed into.
+	helmNamespace = "{{ .Release.Namespace }}"
+	// helmNamePrefix is the Helm template prefixed to the names of generated objects.
+	helmNamePrefix = "{{ .Release.Name }}-"
+	// helmIfNamespaced is the Helm template condition selecting Roles over ClusterRoles.
+	helmIfNamespaced = "{{ if and .Values.rbac .Values.rbac.namespaced }}"
+)
+
+// helmToggle returns a Helm template evaluating to namespaced if the chart's
+// rbac.namespaced value is set, and to clusterScoped otherwise.
+func helmToggle(namespaced, clusterScoped string) string {
+	return helmIfNamespaced + namespaced + "{{ else }}" + clusterScoped + "{{ end }}"
+}
+
+// TemplateForHelm rewrites the given objs (as returned by GenerateRoles and
+// GenerateBindings) into Helm chart templates.
+//
+// Names are prefixed with the release name, ServiceAccounts are placed in the
+// release namespace, and ClusterRoles (except aggregated ones), along with their
+// bindings, become Roles (and RoleBindings) in the release namespace when the
+// chart's rbac.namespaced value is set.  Roles for explicitly namespaced rules
+// keep their namespace.
+//
+// The templates are plain strings, so they get quoted when marshaled to YAML,
+// which Helm handles just fine.
+func TemplateForHelm(objs []any) []any {
+	toggled := make(map[string]bool)
+	for _, obj := range objs {
+		if role, isClusterRole := obj.(rbacv1.ClusterRole); isClusterRole && !isAggregated(role) {
+			toggled[role.Name] = true
+		}
+	}
+
+	res := make([]any, 0, len(objs))
+	for _, obj := range objs {
+		switch obj := obj.(type) {
+		case rbacv1.ClusterRole:
+			if toggled[obj.Name] {
+				obj.Kind = helmToggle("Role", "ClusterRole")
+				obj.Namespace = helmToggle(helmNamespace, "")
+			}
+			obj.Name = helmNamePrefix + obj.Name
+			res = append(res, obj)
+		case rbacv1.Role:
+			obj.Name = helmNamePrefix + obj.Name
+			res = append(res, obj)
+		case rbacv1.ClusterRoleBinding:
+			if toggled[obj.RoleRef.Name] {
+				obj.Kind = helmToggle("RoleBinding", "ClusterRoleBinding")
+				obj.Namespace = helmToggle(helmNamespace, "")
+				obj.RoleRef.Kind = helmToggle("Role", "ClusterRole")
+			}
+			obj.Name = helmNamePrefix + obj.Name
+			obj.RoleRef.Name = helmNamePrefix + obj.RoleRef.Name
+			obj.Subjects = helmSubjects(obj.Subjects)
+			res = append(res, obj)
+		case rbacv1.RoleBinding:
+			obj.Name = helmNamePrefix + obj.Name
+			obj.RoleRef.Name = helmNamePrefix + obj.RoleRef.Name
+			obj.Subjects = helmSubjects(obj.Subjects)
+			res = append(res, obj)
+		case corev1.ServiceAccount:
+			obj.Name = helmNamePrefix + obj.Name
+			obj.Namespace = helmNamespace
+			res = append(res, obj)
+		default:
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+// helmSubjects rewrites the given ServiceAccount subjects to refer to the
+// templated ServiceAccount.
+func helmSubjects(subjects []rbacv1.Subject) []rbacv1.Subject {
+	res := make([]rbacv1.Subject, len(subjects))
+	for i, subject := range subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind {
+			subject.Name = helmNamePrefix + subject.Name
+			subject.Namespace = helmNamespace
+		}
+		res[i] = subject
+	}
+	return res
+}
@@ -0,0 +1,124 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// KustomizeComponent is a kustomize Component, i.e. a reusable set of
+// kustomizations that can be included with the components field of a
+// kustomization.
+type KustomizeComponent struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Patches    []KustomizePatch `json:"patches,omitempty"`
+}
+
+// KustomizePatch is an (inline) patch of a KustomizeComponent.
+type KustomizePatch struct {
+	// Target selects the objects to patch.
+	Target KustomizeTarget `json:"target"`
+	// Patch is a JSON6902 patch, in YAML form.
+	Patch string `json:"patch"`
+}
+
+// KustomizeTarget selects the objects patched by a KustomizePatch.
+type KustomizeTarget struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// jsonPatchOp is a single JSON6902 patch operation.
+type jsonPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// GenerateNamespacedComponent generates a kustomize Component that narrows the
+// given objs (as returned by GenerateRoles and GenerateBindings) for
+// single-namespace installs.
+//
+// Each ClusterRole (except aggregated ones) becomes a Role, with the rules of
+// the ClusterRole of the same name in narrowedRoles, i.e. the roles generated
+// without the rules marked ClusterOnly, and ClusterRoleBindings for them become
+// RoleBindings.  The namespace of the install is set by the namespace field of
+// the kustomization including the component, as usual.
+//
+// If there's nothing to narrow, nil is returned.
+func GenerateNamespacedComponent(objs []any, narrowedRoles []any) (*KustomizeComponent, error) {
+	narrowedRules := make(map[string][]rbacv1.PolicyRule)
+	for _, obj := range narrowedRoles {
+		if role, isClusterRole := obj.(rbacv1.ClusterRole); isClusterRole {
+			narrowedRules[role.Name] = role.Rules
+		}
+	}
+
+	narrowed := make(map[string]bool)
+	var patches []KustomizePatch
+	for _, obj := range objs {
+		var (
+			target KustomizeTarget
+			ops    []jsonPatchOp
+		)
+		switch obj := obj.(type) {
+		case rbacv1.ClusterRole:
+			if isAggregated(obj) {
+				continue
+			}
+			narrowed[obj.Name] = true
+			rules := narrowedRules[obj.Name]
+			if rules == nil {
+				// all of the rules are cluster-only
+				rules = []rbacv1.PolicyRule{}
+			}
+			target = KustomizeTarget{Kind: "ClusterRole", Name: obj.Name}
+			ops = []jsonPatchOp{
+				{Op: "replace", Path: "/kind", Value: "Role"},
+				{Op: "replace", Path: "/rules", Value: rules},
+			}
+		case rbacv1.ClusterRoleBinding:
+			if obj.RoleRef.Kind != "ClusterRole" || !narrowed[obj.RoleRef.Name] {
+				continue
+			}
+			target = KustomizeTarget{Kind: "ClusterRoleBinding", Name: obj.Name}
+			ops = []jsonPatchOp{
+				{Op: "replace", Path: "/kind", Value: "RoleBinding"},
+				{Op: "replace", Path: "/roleRef/kind", Value: "Role"},
+			}
+		default:
+			continue
+		}
+
+		patch, err := yaml.Marshal(ops)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, KustomizePatch{Target: target, Patch: string(patch)})
+	}
+	if len(patches) == 0 {
+		return nil, nil
+	}
+
+	return &KustomizeComponent{
+		APIVersion: "kustomize.config.k8s.io/v1alpha1",
+		Kind:       "Component",
+		Patches:    patches,
+	}, nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+)
+
+// OverridesConfig overrides the scoping of the rules generated from rbac
+// markers, or drops them, without editing the markers themselves.  This is
+// useful for keeping a config file per environment.
+//
+// An example config file:
+//
+//	overrides:
+//	# grant access to secrets in the install namespace only
+//	- match:
+//	    groups: [""]
+//	    resources: [secrets]
+//	  namespace: my-operator-system
+//	# this environment doesn't have any ingresses
+//	- match:
+//	    groups: [networking.k8s.io]
+//	    resources: [ingresses]
+//	  drop: true
+type OverridesConfig struct {
+	// Overrides are the overrides to apply.  For each rule, only the first
+	// matching override is applied.
+	Overrides []RuleOverride `json:"overrides"`
+}
+
+// RuleOverride overrides the rules matched by Match.
+//
+// Markers listing several groups or resources are considered one group and
+// resource at a time, so an override may only apply to part of a marker.
+type RuleOverride struct {
+	// Match selects the rules to override.
+	Match RuleMatch `json:"match"`
+
+	// Drop drops the matched rules.
+	Drop bool `json:"drop,omitempty"`
+	// Namespace moves the matched rules into a Role in the given namespace, or
+	// into a ClusterRole if set to the empty string.
+	Namespace *string `json:"namespace,omitempty"`
+	// RoleName moves the matched rules into the role with the given name.
+	RoleName string `json:"roleName,omitempty"`
+}
+
+// RuleMatch selects rules by the fields of their markers.  Unset fields
+// match everything.
+type RuleMatch struct {
+	// Groups matches rules for any of the given API groups.
+	Groups []string `json:"groups,omitempty"`
+	// Resources matches rules for any of the given resources.
+	Resources []string `json:"resources,omitempty"`
+	// URLs matches rules for non-resource URLs, all of which are in the
+	// given list.
+	URLs []string `json:"urls,omitempty"`
+	// Namespace matches rules for the given namespace, or cluster-scoped rules
+	// if set to the empty string.
+	Namespace *string `json:"namespace,omitempty"`
+	// RoleName matches rules with the given custom role name.
+	RoleName string `json:"roleName,omitempty"`
+	// Component matches rules of the given component.
+	Component string `json:"component,omitempty"`
+}
+
+// loadOverrides parses the given overrides config.
+func loadOverrides(contents []byte) (*OverridesConfig, error) {
+	var cfg OverridesConfig
+	if err := yaml.UnmarshalStrict(contents, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse rbac overrides config: %w", err)
+	}
+	for i, override := range cfg.Overrides {
+		if override.Drop && (override.Namespace != nil || override.RoleName != "") {
+			return nil, fmt.Errorf("rbac override %d can't both drop rules and change their scope", i)
+		}
+	}
+	return &cfg, nil
+}
+
+// apply applies the overrides to the given rule, returning the resulting rules.
+func (c *OverridesConfig) apply(rule Rule) []Rule {
+	if c == nil || len(c.Overrides) == 0 {
+		return []Rule{rule}
+	}
+
+	var res []Rule
+	for _, piece := range splitRule(rule) {
+		override := c.find(piece)
+		switch {
+		case override == nil:
+			res = append(res, piece)
+		case override.Drop:
+			continue
+		default:
+			if override.Namespace != nil {
+				piece.Namespace = *override.Namespace
+			}
+			if override.RoleName != "" {
+				piece.RoleName = override.RoleName
+			}
+			res = append(res, piece)
+		}
+	}
+	return res
+}
+
+// find returns the first override matching the given (split) rule, if any.
+func (c *OverridesConfig) find(rule Rule) *RuleOverride {
+	for i, override := range c.Overrides {
+		if override.Match.matches(rule) {
+			return &c.Overrides[i]
+		}
+	}
+	return nil
+}
+
+// matches checks if the given (split) rule is matched.
+func (m RuleMatch) matches(rule Rule) bool {
+	if len(m.Groups) > 0 && (len(rule.Groups) == 0 || !slices.ContainsFunc(m.Groups, func(group string) bool {
+		return normalizeGroup(group) == normalizeGroup(rule.Groups[0])
+	})) {
+		return false
+	}
+	if len(m.Resources) > 0 && (len(rule.Resources) == 0 || !slices.Contains(m.Resources, rule.Resources[0])) {
+		return false
+	}
+	if len(m.URLs) > 0 && (len(rule.URLs) == 0 || !allMatch(m.URLs, rule.URLs, func(pattern, url string) bool { return pattern == url })) {
+		return false
+	}
+	if m.Namespace != nil && *m.Namespace != rule.Namespace {
+		return false
+	}
+	if m.RoleName != "" && m.RoleName != rule.RoleName {
+		return false
+	}
+	return m.Component == "" || m.Component == rule.Component
+}
+
+// splitRule splits the given rule into one rule per group and resource.
+func splitRule(rule Rule) []Rule {
+	groups := rule.Groups
+	if len(groups) == 0 {
+		groups = []string{""}
+	}
+	resources := rule.Resources
+	if len(resources) == 0 {
+		resources = []string{""}
+	}
+
+	res := make([]Rule, 0, len(groups)*len(resources))
+	for _, group := range groups {
+		for _, resource := range resources {
+			piece := rule
+			if len(rule.Groups) > 0 {
+				piece.Groups = []string{group}
+			}
+			if len(rule.Resources) > 0 {
+				piece.Resources = []string{resource}
+			}
+			res = append(res, piece)
+		}
+	}
+	return res
+}
+
+// normalizeGroup maps the "core" alias to the actual core API group.
+func normalizeGroup(group string) string {
+	if group == "core" {
+		return ""
+	}
+	return group
+}
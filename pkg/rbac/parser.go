@@ -20,16 +20,32 @@ limitations under the License.
 // The markers take the form:
 //
 //	+kubebuilder:rbac:groups=<groups>,resources=<resources>,resourceNames=<resource names>,verbs=<verbs>,urls=<non resource urls>
+//
+// The generated roles are canonical: markers granting the same permissions are
+// merged, no matter which packages they're in, permissions covered by broader
+// ones are dropped, every list in a rule is sorted, and the rules of each role
+// are sorted by their groups, resources, resource names, non-resource URLs and
+// verbs.  Roles themselves are sorted by their component, namespace and name.
+// The output thus doesn't depend on the order in which packages are loaded.
 package rbac
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"path"
 	"slices"
 	"strings"
 
+	"github.com/gobuffalo/flect"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/finalizer"
 	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
 )
 
@@ -37,10 +53,33 @@ var (
 	// RuleDefinition is a marker for defining RBAC rules.
 	// Call ToRule on the value to get a Kubernetes RBAC policy rule.
 	RuleDefinition = markers.Must(markers.MakeDefinition("kubebuilder:rbac", markers.DescribesPackage, Rule{}))
+
+	// DefaultRoleNameDefinition is a marker for setting the default role name
+	// of the RBAC rules in a package.
+	DefaultRoleNameDefinition = markers.Must(markers.MakeDefinition("kubebuilder:rbac:defaultRoleName", markers.DescribesPackage, DefaultRoleName("")))
+
+	// resourceDefinition is the marker of the crd generator naming the
+	// resources of kinds.
+	resourceDefinition = markers.Must(markers.MakeDefinition("kubebuilder:resource", markers.DescribesType, crdmarkers.Resource{}))
 )
 
 // +controllertools:marker:generateHelp:category=RBAC
 
+// DefaultRoleName sets the name of the Role or ClusterRole that the RBAC rules
+// in this package belong to, overriding the roleName of the generator.
+//
+// This makes it possible to generate several named roles in a single invocation,
+// with the rules for each role kept in their own package.  Rules that set their
+// own roleName aren't affected.
+//
+// Example:
+//
+//	// +kubebuilder:rbac:defaultRoleName=webhook-role
+//	// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+type DefaultRoleName string
+
+// +controllertools:marker:generateHelp:category=RBAC
+
 // Rule specifies an RBAC rule to all access to some resources or non-resource URLs.
 //
 // RBAC markers are used to generate ClusterRole or Role manifests.
@@ -61,6 +100,9 @@ var (
 //	// +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
 //	// +kubebuilder:rbac:groups=apps,resources=deployments/scale,verbs=get;update
 //
+//	// Access to the finalizers subresource, needed for setting blocking owner references
+//	// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch,finalizers=true
+//
 //	// Access to specific resource instances by name
 //	// +kubebuilder:rbac:groups="",resources=configmaps,resourceNames=my-config,verbs=get
 //
@@ -72,6 +114,9 @@ var (
 //
 //	// Custom role name
 //	// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list,roleName=deployment-reader
+//
+//	// User-facing permissions aggregated into the built-in admin and edit roles
+//	// +kubebuilder:rbac:groups=batch.tutorial.kubebuilder.io,resources=cronjobs,verbs=*,aggregateTo=admin;edit
 type Rule struct {
 	// Groups specifies the API groups that this rule encompasses.
 	// Use empty string ("") for the core API group.
@@ -106,6 +151,16 @@ type Rule struct {
 	// Example: "/metrics;/healthz".
 	URLs []string `marker:"urls,optional"`
 
+	// Finalizers also grants permission to update the finalizers subresource
+	// of each of the given resources.
+	//
+	// This permission is needed to set owner references that block the deletion
+	// of their owner (like the ones set by controllerutil.SetControllerReference)
+	// when the OwnerReferencesPermissionEnforcement admission plugin is enabled,
+	// and is easily forgotten since it's not needed otherwise.
+	// Example: "true".
+	Finalizers bool `marker:",optional"`
+
 	// Namespace specifies the scope of the Rule.
 	// If not set, the Rule belongs to the generated ClusterRole.
 	// If set, the Rule belongs to a Role, whose namespace is specified by this field.
@@ -113,7 +168,8 @@ type Rule struct {
 	Namespace string `marker:",optional"`
 
 	// RoleName specifies a custom name for the Role or ClusterRole.
-	// If not set, uses the default roleName from the generator.
+	// If not set, uses the +kubebuilder:rbac:defaultRoleName of the package, if
+	// any, or else the default roleName from the generator.
 	// Useful for avoiding name conflicts when the same roleName is used across multiple namespaces.
 	//
 	// Example: When using namespace-scoped RBAC markers with kustomize's global namespace transformation,
@@ -125,8 +181,81 @@ type Rule struct {
 	//
 	// This generates Roles named "infra-manager" and "user-secrets" instead of both being "manager-role".
 	RoleName string `marker:"roleName,optional"`
+
+	// Component groups the Rule with other Rules of the same component.
+	//
+	// Roles for each component are generated separately from the roles for Rules
+	// without a component, and are written into their own "<component>_<fileName>"
+	// file, so that different deployments of the same codebase can ship only the
+	// permissions that they need.
+	// Example: "webhook".
+	Component string `marker:",optional"`
+
+	// AggregateTo specifies the ClusterRoles that this Rule is aggregated into.
+	//
+	// If set, the Rule doesn't belong to the generated ClusterRole, but to a separate
+	// ClusterRole labeled with "rbac.authorization.k8s.io/aggregate-to-<name>: true"
+	// for each of the given names.  This is usually used to grant users access to
+	// custom resources through the built-in "admin", "edit" and "view" roles.
+	// Multiple names can be specified separated by semicolons.
+	//
+	// Rules with the same set of names are merged into the same ClusterRole, named
+	// "<roleName>-aggregate-to-<names joined by dashes>" unless RoleName is set,
+	// in which case all the Rules of the role must have the same names.
+	// AggregateTo can't be combined with Namespace.
+	// Example: "admin;edit".
+	AggregateTo []string `marker:"aggregateTo,optional"`
+
+	// Labels specifies labels to add to the Role or ClusterRole that the Rule
+	// belongs to.  Labels from all the Rules of a role are combined.
+	// Example: {app.kubernetes.io/part-of: my-operator}.
+	Labels map[string]string `marker:",optional"`
+
+	// Annotations specifies annotations to add to the Role or ClusterRole that
+	// the Rule belongs to.  Annotations from all the Rules of a role are combined.
+	// Example: {"argocd.argoproj.io/sync-wave": "-1"}.
+	Annotations map[string]string `marker:",optional"`
+
+	// ClusterOnly marks the Rule as only needed by cluster-wide installs.
+	//
+	// The kustomize component generated for single-namespace installs (see the
+	// namespacedComponent option of the generator) turns ClusterRoles into Roles
+	// in the install namespace, and leaves out the Rules marked ClusterOnly, e.g.
+	// Rules for cluster-scoped resources, which can't be granted by Roles.
+	// Example: "true".
+	ClusterOnly bool `marker:"clusterOnly,optional"`
+}
+
+// kindResource returns the resource of the given kind, as the crd generator
+// names it: its +kubebuilder:resource path, or else its lower-cased plural.
+func kindResource(info *markers.TypeInfo) string {
+	if resource, ok := info.Markers.Get(resourceDefinition.Name).(crdmarkers.Resource); ok && resource.Path != "" {
+		return resource.Path
+	}
+	return strings.ToLower(flect.Pluralize(info.Name))
+}
+
+// accesses checks if r grants any verb on the given resource of the given
+// group.
+func (r *Rule) accesses(group, resource string) bool {
+	return slices.ContainsFunc(r.Groups, func(pattern string) bool {
+		return wildcardMatches(pattern, group) || pattern == "core" && group == ""
+	}) && slices.ContainsFunc(r.Resources, func(pattern string) bool { return wildcardMatches(pattern, resource) })
+}
+
+// describeAggregateTo describes the given ";"-joined ClusterRoles a role is
+// aggregated into in errors.
+func describeAggregateTo(aggregateTo string) string {
+	if aggregateTo == "" {
+		return "no ClusterRole"
+	}
+	return "ClusterRoles " + strings.ReplaceAll(aggregateTo, ";", ", ")
 }
 
+// aggregateToLabelPrefix is the prefix for the labels used to aggregate
+// ClusterRoles into other ClusterRoles.
+const aggregateToLabelPrefix = "rbac.authorization.k8s.io/aggregate-to-"
+
 // ruleKey represents the resources and non-resources a Rule applies.
 type ruleKey struct {
 	Groups        string
@@ -183,6 +312,113 @@ func (r *Rule) normalize() {
 	r.URLs = removeDupAndSort(r.URLs)
 }
 
+// compareRules defines the canonical order of the (normalized) Rules of a
+// role: Rules are ordered by their groups, then by their resources, resource
+// names, non-resource URLs and finally their verbs, each compared as a sorted
+// list of strings.
+func compareRules(a, b *Rule) int {
+	if c := slices.Compare(a.Groups, b.Groups); c != 0 {
+		return c
+	}
+	if c := slices.Compare(a.Resources, b.Resources); c != 0 {
+		return c
+	}
+	if c := slices.Compare(a.ResourceNames, b.ResourceNames); c != 0 {
+		return c
+	}
+	if c := slices.Compare(a.URLs, b.URLs); c != 0 {
+		return c
+	}
+	return slices.Compare(a.Verbs, b.Verbs)
+}
+
+// dropCoveredRules collapses wildcards within each of the given Rules, and then
+// removes Rules whose permissions are entirely covered by another Rule.  If two
+// Rules cover each other, only the one with the greatest ruleKey is kept.
+func dropCoveredRules(ruleMap map[ruleKey]*Rule) {
+	keys := make([]ruleKey, 0, len(ruleMap))
+	for key, rule := range ruleMap {
+		rule.minimize()
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b ruleKey) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, key := range keys {
+		for _, otherKey := range keys {
+			if other, ok := ruleMap[otherKey]; ok && otherKey != key && other.covers(ruleMap[key]) {
+				delete(ruleMap, key)
+				break
+			}
+		}
+	}
+}
+
+// minimize collapses the fields of a Rule containing wildcards: any "*" verb,
+// group or resource replaces all other entries, and URLs matched by another
+// URL wildcard in the Rule are removed.
+func (r *Rule) minimize() {
+	if slices.Contains(r.Verbs, "*") {
+		r.Verbs = []string{"*"}
+	}
+	if slices.Contains(r.Groups, "*") {
+		r.Groups = []string{"*"}
+	}
+	if slices.Contains(r.Resources, "*") {
+		r.Resources = []string{"*"}
+	}
+	urls := r.URLs
+	r.URLs = slices.DeleteFunc(slices.Clone(urls), func(url string) bool {
+		return slices.ContainsFunc(urls, func(other string) bool {
+			return other != url && urlMatches(other, url)
+		})
+	})
+}
+
+// covers checks if every permission granted by other is also granted by r.
+func (r *Rule) covers(other *Rule) bool {
+	if !allMatch(r.Verbs, other.Verbs, wildcardMatches) {
+		return false
+	}
+	if len(other.URLs) > 0 && !allMatch(r.URLs, other.URLs, urlMatches) {
+		return false
+	}
+	if len(other.Groups) == 0 && len(other.Resources) == 0 {
+		return true
+	}
+	if !allMatch(r.Groups, other.Groups, wildcardMatches) || !allMatch(r.Resources, other.Resources, wildcardMatches) {
+		return false
+	}
+	// rules without resource names apply to all names
+	return len(r.ResourceNames) == 0 || (len(other.ResourceNames) > 0 && allMatch(r.ResourceNames, other.ResourceNames, wildcardMatches))
+}
+
+// allMatch checks if each of the given values is matched by one of the given patterns.
+func allMatch(patterns, values []string, matches func(pattern, value string) bool) bool {
+	for _, value := range values {
+		if !slices.ContainsFunc(patterns, func(pattern string) bool { return matches(pattern, value) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// wildcardMatches checks if a verb, group or resource pattern matches the given value.
+func wildcardMatches(pattern, value string) bool {
+	return pattern == "*" || pattern == value
+}
+
+// urlMatches checks if a non-resource URL pattern matches the given URL (or
+// URL pattern).  Patterns may end in "*" to match any URL with that prefix.
+func urlMatches(pattern, url string) bool {
+	if pattern == "*" || pattern == url {
+		return true
+	}
+	prefix, isWildcard := strings.CutSuffix(pattern, "*")
+	return isWildcard && strings.HasPrefix(url, prefix)
+}
+
 // removeDupAndSort removes duplicates in strs, sorts the items, and returns a
 // new slice of strings.
 func removeDupAndSort(strs []string) []string {
@@ -221,7 +457,10 @@ func (r *Rule) ToRule() rbacv1.PolicyRule {
 // Generator generates ClusterRole objects.
 type Generator struct {
 	// RoleName sets the name of the generated ClusterRole.
-	RoleName string
+	//
+	// It may be omitted if every rule gets its name from its own roleName, or from
+	// a +kubebuilder:rbac:defaultRoleName marker on its package.
+	RoleName string `marker:",optional"`
 
 	// FileName sets the file name for the generated manifest(s). If not set, defaults to "role.yaml".
 	FileName string `marker:",optional"`
@@ -231,70 +470,344 @@ type Generator struct {
 
 	// Year specifies the year to substitute for " YEAR" in the header file.
 	Year string `marker:",optional"`
+
+	// ServiceAccountName enables generating a ServiceAccount with the given name,
+	// along with a ClusterRoleBinding (or RoleBinding) binding each generated
+	// ClusterRole (or Role) to it.  Aggregated ClusterRoles aren't bound.
+	ServiceAccountName string `marker:",optional"`
+
+	// ServiceAccountNamespace sets the namespace of the generated ServiceAccount.
+	// If not set, defaults to "system".
+	ServiceAccountNamespace string `marker:",optional"`
+
+	// Overrides specifies a config file overriding the scoping of the rules
+	// generated from rbac markers, or dropping some of them, e.g. for a specific
+	// environment:
+	//
+	//	overrides:
+	//	- match:
+	//	    groups: [""]
+	//	    resources: [secrets]
+	//	  namespace: my-operator-system
+	//	- match:
+	//	    groups: [networking.k8s.io]
+	//	    resources: [ingresses]
+	//	  drop: true
+	//
+	// Each rule is overridden by the first override matching it.
+	Overrides string `marker:",optional"`
+
+	// NamespacedComponent enables writing a kustomize Component for
+	// single-namespace installs into the given directory (relative to the output).
+	//
+	// The Component turns the generated ClusterRoles (and their bindings) into
+	// Roles (and RoleBindings), leaving out the rules marked clusterOnly, so that
+	// the same set of markers can be used for both cluster-wide and single-namespace
+	// installs.  It can't be combined with Helm.
+	NamespacedComponent string `marker:",optional"`
+
+	// AuditAgainst enables audit mode, comparing the generated roles against the
+	// ClusterRoles and Roles in the given YAML file instead of writing them out.
+	//
+	// Any permissions that are generated but missing from the file, or present
+	// in the file but no longer generated, are reported as errors, which makes
+	// this useful for checking least-privilege drift in CI.
+	AuditAgainst string `marker:",optional"`
+
+	// AuditCluster enables audit mode against the ClusterRoles and Roles of
+	// the cluster of the run instead (see the --kubeconfig and --context
+	// flags), as with AuditAgainst.
+	AuditCluster bool `marker:",optional"`
+
+	// Helm enables writing the generated manifests as Helm chart templates.
+	//
+	// Object names are prefixed with the release name, the ServiceAccount is
+	// placed in the release namespace, and setting the chart's rbac.namespaced
+	// value turns the generated ClusterRoles into Roles in the release namespace.
+	Helm bool `marker:",optional"`
+
+	// Labels specifies labels to add to all the generated Roles and ClusterRoles.
+	// Labels set by rbac markers take precedence.
+	Labels map[string]string `marker:",optional"`
+
+	// Annotations specifies annotations to add to all the generated Roles and
+	// ClusterRoles.  Annotations set by rbac markers take precedence.
+	Annotations map[string]string `marker:",optional"`
+
+	// ValidateResources enables warnings for rbac rules referencing API groups or
+	// resources that don't match any built-in Kubernetes resource, or any kind
+	// defined in the loaded packages (or the packages they import directly).
+	//
+	// This catches typos and mixups like "groups=deployments.apps" early.
+	ValidateResources bool `marker:",optional"`
+
+	// StrictResources makes the problems found by ValidateResources errors
+	// instead of warnings.
+	StrictResources bool `marker:",optional"`
+
+	// AnalyzeClientCalls enables (experimental) checking of calls to controller-runtime
+	// clients against the generated roles.
+	//
+	// The permission needed by each Get, List, Create, Update, Patch, Delete, etc call
+	// is figured out from the type of the object passed to it, as is the permission
+	// to update the finalizers of the owner passed to controllerutil.SetControllerReference.
+	// Calls whose permission isn't granted by any rbac marker are reported as errors,
+	// along with a marker that would grant it.
+	AnalyzeClientCalls bool `marker:",optional"`
 }
 
-func (Generator) RegisterMarkers(into *markers.Registry) error {
+func (g Generator) RegisterMarkers(into *markers.Registry) error {
 	if err := into.Register(RuleDefinition); err != nil {
 		return err
 	}
 	into.AddHelp(RuleDefinition, Rule{}.Help())
-	return nil
+	if err := into.Register(DefaultRoleNameDefinition); err != nil {
+		return err
+	}
+	into.AddHelp(DefaultRoleNameDefinition, DefaultRoleName("").Help())
+	if err := into.Register(finalizer.Definition); err != nil {
+		return err
+	}
+	into.AddHelp(finalizer.Definition, finalizer.Finalizer("").Help())
+	if err := into.Register(resourceDefinition); err != nil {
+		return err
+	}
+	into.AddHelp(resourceDefinition, crdmarkers.Resource{}.Help())
+	// needed to figure out the API groups of kinds
+	return crd.RegisterGroupVersionMarkers(into)
 }
 
 // GenerateRoles generate a slice of objs representing either a ClusterRole or a Role object
-// The order of the objs in the returned slice is stable and determined by their namespaces.
+// The order of the objs in the returned slice is stable and determined by their
+// components and namespaces.
 func GenerateRoles(ctx *genall.GenerationContext, roleName string) ([]any, error) {
-	// Group rules by namespace:roleName combination
-	// Key format: "namespace:roleName" or ":roleName" for ClusterRole
+	objsByComponent, err := GenerateRolesByComponent(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	var objs []any
+	for _, component := range slices.Sorted(maps.Keys(objsByComponent)) {
+		objs = append(objs, objsByComponent[component]...)
+	}
+	return objs, nil
+}
+
+// GenerateRolesByComponent is like GenerateRoles, but groups the generated objs
+// by the component of the rules they were generated from.  Rules without a
+// component are grouped under the empty string.
+func GenerateRolesByComponent(ctx *genall.GenerationContext, roleName string) (map[string][]any, error) {
+	return generateRolesByComponent(ctx, roleName, generateOptions{})
+}
+
+// generateOptions customizes the generation of roles.
+type generateOptions struct {
+	// overrides are applied to the rules before generating the roles.
+	overrides *OverridesConfig
+	// narrowed leaves out the rules marked ClusterOnly.  Problems with the
+	// rules aren't reported, since they're reported when generating the
+	// complete roles.
+	narrowed bool
+}
+
+// generateRolesByComponent implements GenerateRolesByComponent.
+func generateRolesByComponent(ctx *genall.GenerationContext, roleName string, opts generateOptions) (map[string][]any, error) {
+	// Group rules by component:namespace:roleName combination
 	type nsRoleKey struct {
+		component string
 		namespace string
 		roleName  string
+		// aggregateTo is the sorted, ";"-joined list of ClusterRoles
+		// that the role is aggregated into
+		aggregateTo string
 	}
 	rulesByNSRole := make(map[nsRoleKey][]*Rule)
+	// aggregateToByRole holds the ClusterRoles each role is aggregated into,
+	// by its key without them, since a role can only have one set of them
+	aggregateToByRole := make(map[nsRoleKey]string)
+	// metadataByNSRole holds the labels and annotations of each role
+	metadataByNSRole := make(map[nsRoleKey]*metav1.ObjectMeta)
+	// finalizerKind is a kind declaring a finalizer
+	type finalizerKind struct {
+		info     *markers.TypeInfo
+		group    string
+		resource string
+		// roleName is the default role name of the package of the kind
+		roleName string
+		addError func(error)
+	}
+	var finalizerKinds []finalizerKind
 
 	for _, root := range ctx.Roots {
+		addError := func(err error) {
+			if !opts.narrowed {
+				root.AddError(err)
+			}
+		}
 		markerSet, err := markers.PackageMarkers(ctx.Collector, root)
 		if err != nil {
-			root.AddError(err)
+			addError(err)
+		}
+
+		pkgRoleName := roleName
+		if defaultRoleNames := markerSet[DefaultRoleNameDefinition.Name]; len(defaultRoleNames) > 0 {
+			if len(defaultRoleNames) > 1 {
+				addError(fmt.Errorf("package %q has more than one default rbac role name", root.PkgPath))
+			}
+			pkgRoleName = string(defaultRoleNames[0].(DefaultRoleName))
 		}
 
 		// group RBAC markers by namespace and roleName, separate by resource
 		for _, markerValue := range markerSet[RuleDefinition.Name] {
-			rule := markerValue.(Rule)
-			// Use custom roleName if specified, otherwise use default
-			effectiveRoleName := rule.RoleName
-			if effectiveRoleName == "" {
-				effectiveRoleName = roleName
-			}
-			key := nsRoleKey{namespace: rule.Namespace, roleName: effectiveRoleName}
-
-			if len(rule.Resources) == 0 {
-				// Add a rule without any resource if Resources is empty.
-				r := Rule{
-					Groups:        rule.Groups,
-					Resources:     []string{},
-					ResourceNames: rule.ResourceNames,
-					URLs:          rule.URLs,
-					Namespace:     rule.Namespace,
-					RoleName:      effectiveRoleName,
-					Verbs:         rule.Verbs,
+			for _, rule := range opts.overrides.apply(markerValue.(Rule)) {
+				if opts.narrowed && rule.ClusterOnly {
+					continue
 				}
-				rulesByNSRole[key] = append(rulesByNSRole[key], &r)
-				continue
-			}
-			for _, resource := range rule.Resources {
-				r := Rule{
-					Groups:        rule.Groups,
-					Resources:     []string{resource},
-					ResourceNames: rule.ResourceNames,
-					URLs:          rule.URLs,
-					Namespace:     rule.Namespace,
-					RoleName:      effectiveRoleName,
-					Verbs:         rule.Verbs,
+				if len(rule.URLs) > 0 {
+					// see the validation of PolicyRules in k8s.io/kubernetes/pkg/apis/rbac/validation
+					if rule.Namespace != "" {
+						addError(fmt.Errorf("rbac rule for namespace %q can't specify urls, since Roles can't grant access to non-resource URLs", rule.Namespace))
+						continue
+					}
+					if len(rule.Groups) > 0 || len(rule.Resources) > 0 || len(rule.ResourceNames) > 0 {
+						addError(fmt.Errorf("rbac rule with urls %v can't also specify groups, resources or resourceNames", rule.URLs))
+						continue
+					}
+				}
+				aggregateTo := removeDupAndSort(rule.AggregateTo)
+				if len(aggregateTo) > 0 && rule.Namespace != "" {
+					addError(fmt.Errorf("rbac rule for namespace %q can't be aggregated into ClusterRoles %v", rule.Namespace, aggregateTo))
+					continue
+				}
+				// Use custom roleName if specified, otherwise use default
+				effectiveRoleName := rule.RoleName
+				if effectiveRoleName == "" {
+					effectiveRoleName = pkgRoleName
+					if effectiveRoleName == "" {
+						addError(fmt.Errorf("rbac rule has no role name; set roleName on the rule, add a +kubebuilder:rbac:defaultRoleName marker to the package, or set roleName on the generator"))
+						continue
+					}
+					if len(aggregateTo) > 0 {
+						effectiveRoleName += "-aggregate-to-" + strings.Join(aggregateTo, "-")
+					}
+				}
+				key := nsRoleKey{
+					component:   rule.Component,
+					namespace:   rule.Namespace,
+					roleName:    effectiveRoleName,
+					aggregateTo: strings.Join(aggregateTo, ";"),
+				}
+				roleKey := key
+				roleKey.aggregateTo = ""
+				if known, seen := aggregateToByRole[roleKey]; !seen {
+					aggregateToByRole[roleKey] = key.aggregateTo
+				} else if known != key.aggregateTo {
+					addError(fmt.Errorf("rbac role %q is aggregated into %s by some rules, but into %s by others; give the rules with different aggregateTo different roleNames", effectiveRoleName, describeAggregateTo(known), describeAggregateTo(key.aggregateTo)))
+					continue
+				}
+				if len(rule.Labels) > 0 || len(rule.Annotations) > 0 {
+					metadata := metadataByNSRole[key]
+					if metadata == nil {
+						metadata = &metav1.ObjectMeta{}
+						metadataByNSRole[key] = metadata
+					}
+					var err error
+					if metadata.Labels, err = mergeMetadata(metadata.Labels, rule.Labels); err != nil {
+						addError(fmt.Errorf("conflicting labels for rbac role %q: %w", effectiveRoleName, err))
+					}
+					if metadata.Annotations, err = mergeMetadata(metadata.Annotations, rule.Annotations); err != nil {
+						addError(fmt.Errorf("conflicting annotations for rbac role %q: %w", effectiveRoleName, err))
+					}
+				}
+
+				if len(rule.Resources) == 0 {
+					// Add a rule without any resource if Resources is empty.
+					r := Rule{
+						Groups:        rule.Groups,
+						Resources:     []string{},
+						ResourceNames: rule.ResourceNames,
+						URLs:          rule.URLs,
+						Namespace:     rule.Namespace,
+						RoleName:      effectiveRoleName,
+						Verbs:         rule.Verbs,
+					}
+					rulesByNSRole[key] = append(rulesByNSRole[key], &r)
+					continue
+				}
+				for _, resource := range rule.Resources {
+					r := Rule{
+						Groups:        rule.Groups,
+						Resources:     []string{resource},
+						ResourceNames: rule.ResourceNames,
+						URLs:          rule.URLs,
+						Namespace:     rule.Namespace,
+						RoleName:      effectiveRoleName,
+						Verbs:         rule.Verbs,
+					}
+					rulesByNSRole[key] = append(rulesByNSRole[key], &r)
+
+					if rule.Finalizers && resource != "*" && !strings.Contains(resource, "/") {
+						rulesByNSRole[key] = append(rulesByNSRole[key], &Rule{
+							Groups:        rule.Groups,
+							Resources:     []string{resource + "/finalizers"},
+							ResourceNames: rule.ResourceNames,
+							Namespace:     rule.Namespace,
+							RoleName:      effectiveRoleName,
+							Verbs:         []string{"update"},
+						})
+					}
 				}
-				rulesByNSRole[key] = append(rulesByNSRole[key], &r)
 			}
 		}
+
+		// the kinds declaring a finalizer are granted updating it once all
+		// the rules are grouped, to know the roles accessing them
+		if err := markers.EachType(ctx.Collector, root, func(info *markers.TypeInfo) {
+			if info.Markers.Get(finalizer.Definition.Name) == nil {
+				return
+			}
+			gv := crd.GroupVersionForPackage(markerSet, root)
+			if gv.Version == "" {
+				addError(loader.ErrFromNode(fmt.Errorf("kind %s has a finalizer, but its package has no +groupName marker", info.Name), info.RawSpec))
+				return
+			}
+			finalizerKinds = append(finalizerKinds, finalizerKind{
+				info:     info,
+				group:    gv.Group,
+				resource: kindResource(info),
+				roleName: pkgRoleName,
+				addError: addError,
+			})
+		}); err != nil {
+			addError(err)
+		}
+	}
+
+	// grant updating the finalizers of the kinds declaring one to the
+	// (non-aggregated) roles accessing them, or else to the default role of
+	// their package
+	for _, kind := range finalizerKinds {
+		var keys []nsRoleKey
+		for key, rules := range rulesByNSRole {
+			if key.aggregateTo == "" && slices.ContainsFunc(rules, func(rule *Rule) bool { return rule.accesses(kind.group, kind.resource) }) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			if kind.roleName == "" {
+				kind.addError(loader.ErrFromNode(fmt.Errorf("kind %s has a finalizer, but no role name to grant updating it to; add a +kubebuilder:rbac:defaultRoleName marker to the package, or set roleName on the generator", kind.info.Name), kind.info.RawSpec))
+				continue
+			}
+			keys = append(keys, nsRoleKey{roleName: kind.roleName})
+		}
+		for _, key := range keys {
+			rulesByNSRole[key] = append(rulesByNSRole[key], &Rule{
+				Groups:    []string{kind.group},
+				Resources: []string{kind.resource + "/finalizers"},
+				Namespace: key.namespace,
+				RoleName:  key.roleName,
+				Verbs:     []string{"update"},
+			})
+		}
 	}
 
 	// NormalizeRules merge Rule with the same ruleKey and sort the Rules
@@ -317,6 +830,9 @@ func GenerateRoles(ctx *genall.GenerationContext, roleName string) ([]any, error
 			ruleMap[key].addVerbs(rule.Verbs)
 		}
 
+		// drop rules covered by broader ones before they get merged with others
+		dropCoveredRules(ruleMap)
+
 		// deduplicate resources
 		// 1. create map based on key without resources
 		ruleMapWithoutResources := make(map[string][]*Rule)
@@ -375,24 +891,14 @@ func GenerateRoles(ctx *genall.GenerationContext, roleName string) ([]any, error
 			ruleMap[key] = rule
 		}
 
-		// sort the Rules in rules according to their ruleKeys
-		keys := make([]ruleKey, 0, len(ruleMap))
-		for key := range ruleMap {
-			keys = append(keys, key)
-		}
-		slices.SortStableFunc(keys, func(a, b ruleKey) int {
-			return strings.Compare(a.String(), b.String())
-		})
+		// drop rules made redundant by merging
+		dropCoveredRules(ruleMap)
 
-		// Normalize rule verbs to "*" if any verb in the rule is an asterisk
-		for _, rule := range ruleMap {
-			if slices.Contains(rule.Verbs, "*") {
-				rule.Verbs = []string{"*"}
-			}
-		}
-		policyRules := make([]rbacv1.PolicyRule, 0, len(keys))
-		for _, key := range keys {
-			policyRules = append(policyRules, ruleMap[key].ToRule())
+		// sort the Rules into their canonical order
+		sortedRules := slices.SortedFunc(maps.Values(ruleMap), compareRules)
+		policyRules := make([]rbacv1.PolicyRule, 0, len(sortedRules))
+		for _, rule := range sortedRules {
+			policyRules = append(policyRules, rule.ToRule())
 		}
 		return policyRules
 	}
@@ -403,41 +909,64 @@ func GenerateRoles(ctx *genall.GenerationContext, roleName string) ([]any, error
 		keys = append(keys, key)
 	}
 	slices.SortFunc(keys, func(a, b nsRoleKey) int {
-		// Sort by namespace first, then by roleName
+		// Sort by component first, then by namespace, then by roleName
+		if a.component != b.component {
+			return strings.Compare(a.component, b.component)
+		}
 		if a.namespace != b.namespace {
 			return strings.Compare(a.namespace, b.namespace)
 		}
-		return strings.Compare(a.roleName, b.roleName)
+		if a.roleName != b.roleName {
+			return strings.Compare(a.roleName, b.roleName)
+		}
+		return strings.Compare(a.aggregateTo, b.aggregateTo)
 	})
 
 	// process the items in rulesByNSRole by the sorted order to make sure the output is stable
-	var objs []any
+	objs := make(map[string][]any)
 	for _, key := range keys {
 		rules := rulesByNSRole[key]
 		policyRules := NormalizeRules(rules)
 		if len(policyRules) == 0 {
 			continue
 		}
+		var labels, annotations map[string]string
+		if metadata := metadataByNSRole[key]; metadata != nil {
+			labels = metadata.Labels
+			annotations = metadata.Annotations
+		}
 		if key.namespace == "" {
-			objs = append(objs, rbacv1.ClusterRole{
+			if key.aggregateTo != "" {
+				if labels == nil {
+					labels = make(map[string]string)
+				}
+				for target := range strings.SplitSeq(key.aggregateTo, ";") {
+					labels[aggregateToLabelPrefix+target] = "true"
+				}
+			}
+			objs[key.component] = append(objs[key.component], rbacv1.ClusterRole{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "ClusterRole",
 					APIVersion: rbacv1.SchemeGroupVersion.String(),
 				},
 				ObjectMeta: metav1.ObjectMeta{
-					Name: key.roleName,
+					Name:        key.roleName,
+					Labels:      labels,
+					Annotations: annotations,
 				},
 				Rules: policyRules,
 			})
 		} else {
-			objs = append(objs, rbacv1.Role{
+			objs[key.component] = append(objs[key.component], rbacv1.Role{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "Role",
 					APIVersion: rbacv1.SchemeGroupVersion.String(),
 				},
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      key.roleName,
-					Namespace: key.namespace,
+					Name:        key.roleName,
+					Namespace:   key.namespace,
+					Labels:      labels,
+					Annotations: annotations,
 				},
 				Rules: policyRules,
 			})
@@ -447,14 +976,215 @@ func GenerateRoles(ctx *genall.GenerationContext, roleName string) ([]any, error
 	return objs, nil
 }
 
+// mergeMetadata merges the given labels or annotations into the given map,
+// returning an error if they conflict with the existing ones.
+func mergeMetadata(into, from map[string]string) (map[string]string, error) {
+	for name, value := range from {
+		if existing, exists := into[name]; exists && existing != value {
+			return into, fmt.Errorf("%q is set to both %q and %q", name, existing, value)
+		}
+		if into == nil {
+			into = make(map[string]string, len(from))
+		}
+		into[name] = value
+	}
+	return into, nil
+}
+
+// AddRoleMetadata adds the given labels and annotations to each of the given
+// roles (as returned by GenerateRoles).  Labels and annotations already set on
+// a role take precedence.
+func AddRoleMetadata(objs []any, labels, annotations map[string]string) []any {
+	res := make([]any, 0, len(objs))
+	for _, obj := range objs {
+		switch role := obj.(type) {
+		case rbacv1.ClusterRole:
+			role.Labels = withDefaults(role.Labels, labels)
+			role.Annotations = withDefaults(role.Annotations, annotations)
+			obj = role
+		case rbacv1.Role:
+			role.Labels = withDefaults(role.Labels, labels)
+			role.Annotations = withDefaults(role.Annotations, annotations)
+			obj = role
+		}
+		res = append(res, obj)
+	}
+	return res
+}
+
+// withDefaults returns a copy of the given map with the given defaults added.
+func withDefaults(values, defaults map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return values
+	}
+	res := maps.Clone(defaults)
+	maps.Copy(res, values)
+	return res
+}
+
+// GenerateBindings generates a ServiceAccount with the given name and namespace,
+// along with a binding of each of the given roles (as returned by GenerateRoles)
+// to it.  Bindings are named "<role name>-binding", and ClusterRoles that are
+// aggregated into other ClusterRoles are skipped.
+func GenerateBindings(roles []any, name, namespace string) []any {
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      name,
+		Namespace: namespace,
+	}}
+
+	objs := []any{corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ServiceAccount",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}}
+	for _, role := range roles {
+		switch role := role.(type) {
+		case rbacv1.ClusterRole:
+			if isAggregated(role) {
+				continue
+			}
+			objs = append(objs, rbacv1.ClusterRoleBinding{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ClusterRoleBinding",
+					APIVersion: rbacv1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name: role.Name + "-binding",
+				},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: rbacv1.GroupName,
+					Kind:     "ClusterRole",
+					Name:     role.Name,
+				},
+				Subjects: subjects,
+			})
+		case rbacv1.Role:
+			objs = append(objs, rbacv1.RoleBinding{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "RoleBinding",
+					APIVersion: rbacv1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      role.Name + "-binding",
+					Namespace: role.Namespace,
+				},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: rbacv1.GroupName,
+					Kind:     "Role",
+					Name:     role.Name,
+				},
+				Subjects: subjects,
+			})
+		}
+	}
+	return objs
+}
+
+// isAggregated checks if the given ClusterRole is aggregated into other ClusterRoles.
+func isAggregated(role rbacv1.ClusterRole) bool {
+	for label := range role.Labels {
+		if strings.HasPrefix(label, aggregateToLabelPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (g Generator) Generate(ctx *genall.GenerationContext) error {
-	objs, err := GenerateRoles(ctx, g.RoleName)
+	var opts generateOptions
+	if g.Overrides != "" {
+		contents, err := ctx.ReadFile(g.Overrides)
+		if err != nil {
+			return err
+		}
+		if opts.overrides, err = loadOverrides(contents); err != nil {
+			return err
+		}
+	}
+
+	objsByComponent, err := generateRolesByComponent(ctx, g.RoleName, opts)
 	if err != nil {
 		return err
 	}
+	var roles []any
+	for _, component := range slices.Sorted(maps.Keys(objsByComponent)) {
+		roles = append(roles, objsByComponent[component]...)
+	}
 
-	if len(objs) == 0 {
-		return nil
+	if g.AnalyzeClientCalls {
+		for _, call := range UncoveredClientCalls(AnalyzeClientCalls(ctx), roles) {
+			err := fmt.Errorf("client call needs %s %s/%s, which isn't granted by any rbac marker; add e.g. %s",
+				call.Verb, call.Group, call.Resource, call.Marker())
+			call.Package.AddError(loader.ErrFromNode(loader.MarkerError{
+				Marker:       RuleDefinition.Name,
+				SuggestedFix: "add " + call.Marker(),
+				Err:          err,
+			}, call.Node))
+		}
+	}
+
+	if g.ValidateResources || g.StrictResources {
+		for _, unknown := range ValidateResources(ctx) {
+			err := loader.MarkerError{Marker: RuleDefinition.Name, Err: unknown}
+			if unknown.Hint != "" {
+				err.SuggestedFix = "use " + unknown.Hint
+			}
+			if g.StrictResources {
+				unknown.Package.AddError(err)
+				continue
+			}
+			unknown.Package.AddWarning(err)
+		}
+	}
+
+	if g.AuditAgainst != "" && g.AuditCluster {
+		return fmt.Errorf("auditAgainst and auditCluster are mutually exclusive")
+	}
+	if g.AuditAgainst != "" || g.AuditCluster {
+		source := g.AuditAgainst
+		var existing []byte
+		var err error
+		if g.AuditCluster {
+			source = "the cluster"
+			existing, err = ctx.Cluster.ExportManifests(context.Background(),
+				rbacv1.SchemeGroupVersion.WithResource("clusterroles"),
+				rbacv1.SchemeGroupVersion.WithResource("roles"))
+		} else {
+			existing, err = ctx.ReadFile(g.AuditAgainst)
+		}
+		if err != nil {
+			return err
+		}
+		drifts, err := AuditRoles(roles, existing)
+		if err != nil {
+			return err
+		}
+		if len(drifts) == 0 {
+			return nil
+		}
+		msgs := make([]string, 0, len(drifts))
+		for _, drift := range drifts {
+			msgs = append(msgs, drift.String())
+		}
+		return fmt.Errorf("generated roles differ from %s:\n%s", source, strings.Join(msgs, "\n"))
+	}
+
+	if g.NamespacedComponent != "" && g.Helm {
+		return fmt.Errorf("namespacedComponent can't be combined with helm, use the rbac.namespaced value of the chart instead")
+	}
+	var narrowedByComponent map[string][]any
+	if g.NamespacedComponent != "" {
+		opts.narrowed = true
+		narrowedByComponent, err = generateRolesByComponent(ctx, g.RoleName, opts)
+		if err != nil {
+			return err
+		}
 	}
 
 	var headerText string
@@ -472,5 +1202,48 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		fileName = g.FileName
 	}
 
-	return ctx.WriteYAML(fileName, headerText, objs, genall.WithTransform(genall.TransformRemoveCreationTimestamp))
+	for _, component := range slices.Sorted(maps.Keys(objsByComponent)) {
+		objs := objsByComponent[component]
+		if len(objs) == 0 {
+			continue
+		}
+
+		objs = AddRoleMetadata(objs, g.Labels, g.Annotations)
+		if g.ServiceAccountName != "" {
+			namespace := g.ServiceAccountNamespace
+			if namespace == "" {
+				namespace = "system"
+			}
+			objs = append(objs, GenerateBindings(objs, g.ServiceAccountName, namespace)...)
+		}
+		if g.Helm {
+			objs = TemplateForHelm(objs)
+		}
+
+		componentFileName := fileName
+		if component != "" {
+			componentFileName = component + "_" + fileName
+		}
+		if err := ctx.WriteYAML(componentFileName, headerText, objs, genall.WithTransform(genall.TransformRemoveCreationTimestamp)); err != nil {
+			return err
+		}
+
+		if g.NamespacedComponent != "" {
+			kustomization, err := GenerateNamespacedComponent(objs, narrowedByComponent[component])
+			if err != nil {
+				return err
+			}
+			if kustomization == nil {
+				continue
+			}
+			componentDir := g.NamespacedComponent
+			if component != "" {
+				componentDir = component + "_" + componentDir
+			}
+			if err := ctx.WriteYAML(path.Join(componentDir, "kustomization.yaml"), headerText, []any{kustomization}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
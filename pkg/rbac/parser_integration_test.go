@@ -18,13 +18,21 @@ package rbac_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-tools/pkg/genall"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
@@ -83,4 +91,725 @@ var _ = Describe("ClusterRole generated by the RBAC Generator", func() {
 
 		})
 	}
+
+	It("should generate the same roles regardless of the order of the packages", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".", "./components", "./rolenames/...")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(pkgs)).To(BeNumerically(">", 2))
+
+		By("registering the markers")
+		reg := &markers.Registry{}
+		Expect(rbac.Generator{}.RegisterMarkers(reg)).To(Succeed())
+
+		By("generating the roles")
+		objs, err := rbac.GenerateRoles(&genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		expected, err := yaml.Marshal(objs)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("generating the roles with the packages in reverse order")
+		reversed := slices.Clone(pkgs)
+		slices.Reverse(reversed)
+		objs, err = rbac.GenerateRoles(&genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     reversed,
+		}, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		actual, err := yaml.Marshal(objs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(actual)).To(Equal(string(expected)))
+	})
+
+	It("should bind the generated roles to a ServiceAccount", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering RBAC rule marker")
+		reg := &markers.Registry{}
+		Expect(reg.Register(rbac.RuleDefinition)).To(Succeed())
+
+		By("generating the roles and bindings")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		roles, err := rbac.GenerateRoles(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		objs := rbac.GenerateBindings(roles, "controller-manager", "system")
+
+		By("checking the ServiceAccount")
+		Expect(objs).NotTo(BeEmpty())
+		Expect(objs[0]).To(BeAssignableToTypeOf(corev1.ServiceAccount{}))
+		sa := objs[0].(corev1.ServiceAccount)
+		Expect(sa.Name).To(Equal("controller-manager"))
+		Expect(sa.Namespace).To(Equal("system"))
+
+		By("checking the bindings")
+		subject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "controller-manager", Namespace: "system"}
+		var bound []string
+		for _, obj := range objs[1:] {
+			switch binding := obj.(type) {
+			case rbacv1.ClusterRoleBinding:
+				Expect(binding.Name).To(Equal(binding.RoleRef.Name + "-binding"))
+				Expect(binding.RoleRef.Kind).To(Equal("ClusterRole"))
+				Expect(binding.Subjects).To(ConsistOf(subject))
+				bound = append(bound, binding.RoleRef.Name)
+			case rbacv1.RoleBinding:
+				Expect(binding.Name).To(Equal(binding.RoleRef.Name + "-binding"))
+				Expect(binding.RoleRef.Kind).To(Equal("Role"))
+				Expect(binding.Subjects).To(ConsistOf(subject))
+				bound = append(bound, binding.Namespace+"/"+binding.RoleRef.Name)
+			default:
+				Fail(fmt.Sprintf("unexpected object %T", obj))
+			}
+		}
+		Expect(bound).To(ContainElements("manager-role", "zoo/manager-role", "infrastructure/infra-deployment-manager"))
+		Expect(bound).NotTo(ContainElement("manager-role-aggregate-to-admin-edit"), "aggregated ClusterRoles shouldn't be bound")
+	})
+
+	It("should audit the generated roles against existing ones", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering RBAC rule marker")
+		reg := &markers.Registry{}
+		Expect(reg.Register(rbac.RuleDefinition)).To(Succeed())
+
+		By("generating the roles")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		roles, err := rbac.GenerateRoles(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("auditing against the expected roles")
+		expectedFile, err := os.ReadFile("role.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		drifts, err := rbac.AuditRoles(roles, expectedFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drifts).To(BeEmpty())
+
+		By("auditing against drifted roles")
+		drifts, err = rbac.AuditRoles(roles, []byte(`apiVersion: v1
+kind: List
+items:
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: Role
+  metadata:
+    name: manager-role
+    namespace: park
+  rules:
+  - apiGroups: ["art"]
+    resources: ["jobs"]
+    verbs: ["get", "delete"]
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: Role
+  metadata:
+    name: manager-role
+    namespace: zoo
+  rules:
+  - apiGroups: ["art"]
+    resources: ["jobs"]
+    verbs: ["get"]
+`))
+		Expect(err).NotTo(HaveOccurred())
+		var parkDrift *rbac.RoleDrift
+		for i, drift := range drifts {
+			if drift.Kind == "Role" && drift.Namespace == "park" {
+				parkDrift = &drifts[i]
+			}
+		}
+		Expect(parkDrift).NotTo(BeNil())
+		Expect(parkDrift.Missing).To(BeEmpty())
+		Expect(parkDrift.Extra).To(ConsistOf("delete art/jobs"))
+
+		var zooDrift *rbac.RoleDrift
+		for i, drift := range drifts {
+			if drift.Kind == "Role" && drift.Namespace == "zoo" {
+				zooDrift = &drifts[i]
+			}
+		}
+		Expect(zooDrift).NotTo(BeNil())
+		Expect(zooDrift.Missing).To(ConsistOf("get wave/jobs"))
+		Expect(zooDrift.Extra).To(BeEmpty())
+	})
+
+	It("should audit the generated roles against the ones of a cluster", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering the RBAC markers")
+		reg := &markers.Registry{}
+		Expect(rbac.Generator{}.RegisterMarkers(reg)).To(Succeed())
+
+		By("starting an API server serving the expected roles, but the one of the zoo namespace, and another ClusterRole")
+		expectedFile, err := os.ReadFile("role.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		installed := map[string][]map[string]any{
+			"clusterroles": {{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRole",
+				"metadata":   map[string]any{"name": "other-role"},
+				"rules":      []any{map[string]any{"apiGroups": []any{"apps"}, "resources": []any{"deployments"}, "verbs": []any{"get"}}},
+			}},
+		}
+		for _, doc := range bytes.Split(expectedFile, []byte("---\n")) {
+			var obj map[string]any
+			Expect(yaml.Unmarshal(doc, &obj)).To(Succeed())
+			if obj == nil {
+				continue
+			}
+			if metadata := obj["metadata"].(map[string]any); metadata["namespace"] == "zoo" {
+				continue
+			}
+			resource := strings.ToLower(obj["kind"].(string)) + "s"
+			installed[resource] = append(installed[resource], obj)
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodGet))
+			resource, found := strings.CutPrefix(r.URL.Path, "/apis/rbac.authorization.k8s.io/v1/")
+			Expect(found).To(BeTrue())
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(map[string]any{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "List",
+				"metadata":   map[string]any{},
+				"items":      installed[resource],
+			})).To(Succeed())
+		}))
+		defer server.Close()
+		kubeconfig := filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+		Expect(os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`, server.URL)), 0o600)).To(Succeed())
+
+		By("auditing the generated roles against the ones of the cluster")
+		outputDir := GinkgoT().TempDir()
+		ctx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+			Cluster:    genall.ClusterConfig{Kubeconfig: kubeconfig},
+		}
+		err = rbac.Generator{RoleName: "manager-role", AuditCluster: true}.Generate(ctx)
+		Expect(err).To(MatchError(`generated roles differ from the cluster:
+Role zoo/manager-role:
+  missing: get art/jobs
+  missing: get wave/jobs`))
+
+		By("checking that nothing was written")
+		entries, err := os.ReadDir(outputDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+
+		By("checking that the cluster can't be audited along with a file")
+		err = rbac.Generator{RoleName: "manager-role", AuditCluster: true, AuditAgainst: "role.yaml"}.Generate(ctx)
+		Expect(err).To(MatchError("auditAgainst and auditCluster are mutually exclusive"))
+	})
+
+	It("should reject invalid non-resource URL rules", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./invalid")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("registering RBAC rule marker")
+		reg := &markers.Registry{}
+		Expect(reg.Register(rbac.RuleDefinition)).To(Succeed())
+
+		By("generating the roles")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		objs, err := rbac.GenerateRoles(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+
+		By("checking for errors")
+		Expect(pkgs[0].Errors).To(HaveLen(2))
+		Expect(pkgs[0].Errors[0].Error()).To(ContainSubstring("can't grant access to non-resource URLs"))
+		Expect(pkgs[0].Errors[1].Error()).To(ContainSubstring("can't also specify groups, resources or resourceNames"))
+	})
+
+	It("should reject roles aggregated into different ClusterRoles by their rules", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./aggregateconflict")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("registering RBAC rule marker")
+		reg := &markers.Registry{}
+		Expect(reg.Register(rbac.RuleDefinition)).To(Succeed())
+
+		By("generating the roles")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		objs, err := rbac.GenerateRoles(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking that a single ClusterRole of the name was generated, and the conflict reported")
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].(rbacv1.ClusterRole).Name).To(Equal("viewer"))
+		Expect(objs[0].(rbacv1.ClusterRole).Labels).To(Equal(map[string]string{"rbac.authorization.k8s.io/aggregate-to-view": "true"}))
+		Expect(pkgs[0].Errors).To(HaveLen(1))
+		Expect(pkgs[0].Errors[0].Error()).To(ContainSubstring(`rbac role "viewer" is aggregated into ClusterRoles view by some rules, but into ClusterRoles edit by others`))
+	})
+
+	It("should group the generated roles by component", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./components")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering RBAC rule marker")
+		reg := &markers.Registry{}
+		Expect(reg.Register(rbac.RuleDefinition)).To(Succeed())
+
+		By("generating the roles")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		objsByComponent, err := rbac.GenerateRolesByComponent(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objsByComponent).To(HaveLen(3))
+
+		By("checking the roles of each component")
+		Expect(objsByComponent[""]).To(HaveLen(1))
+		Expect(objsByComponent[""][0].(rbacv1.ClusterRole).Rules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list", "watch"}},
+			rbacv1.PolicyRule{APIGroups: []string{"batch.io"}, Resources: []string{"cronjobs"}, Verbs: []string{"get", "list", "watch"}},
+		))
+
+		Expect(objsByComponent["webhook"]).To(HaveLen(1))
+		Expect(objsByComponent["webhook"][0].(rbacv1.ClusterRole).Name).To(Equal("manager-role"))
+		Expect(objsByComponent["webhook"][0].(rbacv1.ClusterRole).Rules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+			rbacv1.PolicyRule{APIGroups: []string{"batch.io"}, Resources: []string{"cronjobs"}, Verbs: []string{"get"}},
+		))
+
+		Expect(objsByComponent["cleaner"]).To(HaveLen(1))
+		Expect(objsByComponent["cleaner"][0].(rbacv1.Role).Namespace).To(Equal("jobs"))
+	})
+
+	It("should grant updating the finalizers of kinds declaring one", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./finalizers")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering the markers of the generator")
+		reg := &markers.Registry{}
+		Expect(rbac.Generator{}.RegisterMarkers(reg)).To(Succeed())
+
+		By("generating the roles")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		objs, err := rbac.GenerateRoles(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs[0].Errors).To(BeEmpty())
+		Expect(objs).To(HaveLen(2))
+		Expect(objs[0].(rbacv1.ClusterRole).Rules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{"apps.example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get", "update"}},
+			rbacv1.PolicyRule{APIGroups: []string{"apps.example.com"}, Resources: []string{"widgets/finalizers"}, Verbs: []string{"update"}},
+		))
+
+		By("granting the finalizers of the kind with a resource path to the role accessing it")
+		role := objs[1].(rbacv1.Role)
+		Expect(role.Namespace).To(Equal("zoo"))
+		Expect(role.Rules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{"apps.example.com"}, Resources: []string{"gizmata"}, Verbs: []string{"get", "update"}},
+			rbacv1.PolicyRule{APIGroups: []string{"apps.example.com"}, Resources: []string{"gizmata/finalizers"}, Verbs: []string{"update"}},
+		))
+	})
+
+	It("should generate a kustomize component for single-namespace installs", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./components")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering RBAC rule marker")
+		reg := &markers.Registry{}
+		Expect(reg.Register(rbac.RuleDefinition)).To(Succeed())
+
+		By("running the generator")
+		outputDir := GinkgoT().TempDir()
+		ctx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		gen := rbac.Generator{
+			RoleName:            "manager-role",
+			ServiceAccountName:  "controller-manager",
+			NamespacedComponent: "namespaced",
+		}
+		Expect(gen.Generate(ctx)).To(Succeed())
+		Expect(pkgs[0].Errors).To(BeEmpty())
+
+		By("checking the component")
+		contents, err := os.ReadFile(filepath.Join(outputDir, "namespaced", "kustomization.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		var component rbac.KustomizeComponent
+		Expect(yaml.Unmarshal(contents, &component)).To(Succeed())
+		Expect(component.Kind).To(Equal("Component"))
+		Expect(component.Patches).To(HaveLen(2))
+
+		Expect(component.Patches[0].Target).To(Equal(rbac.KustomizeTarget{Kind: "ClusterRole", Name: "manager-role"}))
+		Expect(component.Patches[0].Patch).To(MatchYAML(`
+- op: replace
+  path: /kind
+  value: Role
+- op: replace
+  path: /rules
+  value:
+  - apiGroups: [batch.io]
+    resources: [cronjobs]
+    verbs: [get, list, watch]
+`))
+		Expect(component.Patches[1].Target).To(Equal(rbac.KustomizeTarget{Kind: "ClusterRoleBinding", Name: "manager-role-binding"}))
+		Expect(component.Patches[1].Patch).To(MatchYAML(`
+- op: replace
+  path: /kind
+  value: RoleBinding
+- op: replace
+  path: /roleRef/kind
+  value: Role
+`))
+
+		By("checking the components of other components")
+		_, err = os.Stat(filepath.Join(outputDir, "webhook_namespaced", "kustomization.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = os.Stat(filepath.Join(outputDir, "cleaner_namespaced", "kustomization.yaml"))
+		Expect(os.IsNotExist(err)).To(BeTrue(), "nothing to narrow for namespaced roles")
+	})
+
+	It("should apply overrides from a config file", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./components")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering RBAC rule marker")
+		reg := &markers.Registry{}
+		Expect(reg.Register(rbac.RuleDefinition)).To(Succeed())
+
+		By("running the generator")
+		outputDir := GinkgoT().TempDir()
+		ctx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+			InputRule:  genall.InputFromFileSystem,
+		}
+		gen := rbac.Generator{
+			RoleName:  "manager-role",
+			Overrides: "./components/overrides.yaml",
+		}
+		Expect(gen.Generate(ctx)).To(Succeed())
+		Expect(pkgs[0].Errors).To(BeEmpty())
+
+		By("checking the dropped rules")
+		contents, err := os.ReadFile(filepath.Join(outputDir, "role.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).NotTo(ContainSubstring("namespaces"))
+
+		By("checking the rescoped rules")
+		contents, err = os.ReadFile(filepath.Join(outputDir, "webhook_role.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		docs := bytes.Split(contents, []byte("\n---\n"))
+		Expect(docs).To(HaveLen(2))
+		var clusterRole rbacv1.ClusterRole
+		Expect(yaml.Unmarshal(docs[0], &clusterRole)).To(Succeed())
+		Expect(clusterRole.Rules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{"batch.io"}, Resources: []string{"cronjobs"}, Verbs: []string{"get"}},
+		))
+		var role rbacv1.Role
+		Expect(yaml.Unmarshal(docs[1], &role)).To(Succeed())
+		Expect(role.Kind).To(Equal("Role"))
+		Expect(role.Namespace).To(Equal("webhook-system"))
+		Expect(role.Rules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+		))
+	})
+
+	It("should generate a role for each role name", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./rolenames/...")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(2))
+
+		By("registering the markers")
+		reg := &markers.Registry{}
+		Expect(rbac.Generator{}.RegisterMarkers(reg)).To(Succeed())
+
+		By("generating the roles")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		objs, err := rbac.GenerateRoles(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		for _, pkg := range pkgs {
+			Expect(pkg.Errors).To(BeEmpty())
+		}
+
+		By("checking the roles")
+		rulesByName := make(map[string][]rbacv1.PolicyRule)
+		for _, obj := range objs {
+			role := obj.(rbacv1.ClusterRole)
+			rulesByName[role.Name] = role.Rules
+		}
+		Expect(rulesByName).To(Equal(map[string][]rbacv1.PolicyRule{
+			"manager-role": {{APIGroups: []string{"batch.io"}, Resources: []string{"cronjobs"}, Verbs: []string{"get", "list", "watch"}}},
+			"status-role":  {{APIGroups: []string{"batch.io"}, Resources: []string{"cronjobs/status"}, Verbs: []string{"update"}}},
+			"webhook-role": {
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+				{APIGroups: []string{"batch.io"}, Resources: []string{"cronjobs"}, Verbs: []string{"get"}},
+			},
+		}))
+
+		By("generating the roles without a generator role name")
+		objs, err = rbac.GenerateRoles(ctx, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(2))
+		var controllerPkg *loader.Package
+		for _, pkg := range pkgs {
+			if pkg.Name == "controller" {
+				controllerPkg = pkg
+			}
+		}
+		Expect(controllerPkg.Errors).To(HaveLen(1))
+		Expect(controllerPkg.Errors[0].Error()).To(ContainSubstring("has no role name"))
+	})
+
+	It("should add labels and annotations to the generated roles", func() {
+		roles := []any{
+			rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "manager-role", Labels: map[string]string{"tier": "marker"}}},
+			rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "manager-role", Namespace: "zoo"}},
+		}
+		objs := rbac.AddRoleMetadata(append(roles, rbac.GenerateBindings(roles, "controller-manager", "system")...),
+			map[string]string{"tier": "generator", "app": "test"}, map[string]string{"note": "generated"})
+
+		Expect(objs[0].(rbacv1.ClusterRole).Labels).To(Equal(map[string]string{"tier": "marker", "app": "test"}))
+		Expect(objs[0].(rbacv1.ClusterRole).Annotations).To(Equal(map[string]string{"note": "generated"}))
+		Expect(objs[1].(rbacv1.Role).Labels).To(Equal(map[string]string{"tier": "generator", "app": "test"}))
+		Expect(objs[2].(corev1.ServiceAccount).Labels).To(BeEmpty())
+	})
+
+	It("should template the generated objects for Helm", func() {
+		By("templating a ClusterRole, aggregated ClusterRole, Role and their bindings")
+		roles := []any{
+			rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "manager-role"}},
+			rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{
+				Name:   "manager-role-aggregate-to-view",
+				Labels: map[string]string{"rbac.authorization.k8s.io/aggregate-to-view": "true"},
+			}},
+			rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "manager-role", Namespace: "zoo"}},
+		}
+		objs := rbac.TemplateForHelm(append(roles, rbac.GenerateBindings(roles, "controller-manager", "system")...))
+		Expect(objs).To(HaveLen(6))
+
+		By("checking the toggled ClusterRole")
+		clusterRole := objs[0].(rbacv1.ClusterRole)
+		Expect(clusterRole.Name).To(Equal("{{ .Release.Name }}-manager-role"))
+		Expect(clusterRole.Kind).To(Equal("{{ if and .Values.rbac .Values.rbac.namespaced }}Role{{ else }}ClusterRole{{ end }}"))
+		Expect(clusterRole.Namespace).To(Equal("{{ if and .Values.rbac .Values.rbac.namespaced }}{{ .Release.Namespace }}{{ else }}{{ end }}"))
+
+		By("checking the aggregated ClusterRole and Role keep their kind and namespace")
+		Expect(objs[1].(rbacv1.ClusterRole).Kind).To(BeEmpty())
+		Expect(objs[1].(rbacv1.ClusterRole).Namespace).To(BeEmpty())
+		Expect(objs[2].(rbacv1.Role).Namespace).To(Equal("zoo"))
+
+		By("checking the ServiceAccount and bindings")
+		serviceAccount := objs[3].(corev1.ServiceAccount)
+		Expect(serviceAccount.Name).To(Equal("{{ .Release.Name }}-controller-manager"))
+		Expect(serviceAccount.Namespace).To(Equal("{{ .Release.Namespace }}"))
+
+		clusterRoleBinding := objs[4].(rbacv1.ClusterRoleBinding)
+		Expect(clusterRoleBinding.Kind).To(Equal("{{ if and .Values.rbac .Values.rbac.namespaced }}RoleBinding{{ else }}ClusterRoleBinding{{ end }}"))
+		Expect(clusterRoleBinding.RoleRef.Kind).To(Equal("{{ if and .Values.rbac .Values.rbac.namespaced }}Role{{ else }}ClusterRole{{ end }}"))
+		Expect(clusterRoleBinding.RoleRef.Name).To(Equal("{{ .Release.Name }}-manager-role"))
+		Expect(clusterRoleBinding.Subjects).To(ConsistOf(rbacv1.Subject{
+			Kind: rbacv1.ServiceAccountKind, Name: "{{ .Release.Name }}-controller-manager", Namespace: "{{ .Release.Namespace }}",
+		}))
+
+		roleBinding := objs[5].(rbacv1.RoleBinding)
+		Expect(roleBinding.Namespace).To(Equal("zoo"))
+		Expect(roleBinding.RoleRef.Name).To(Equal("{{ .Release.Name }}-manager-role"))
+	})
+
+	It("should find client calls that aren't covered by any rbac marker", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/analyze")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./controller")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering the markers")
+		reg := &markers.Registry{}
+		Expect(rbac.Generator{AnalyzeClientCalls: true}.RegisterMarkers(reg)).To(Succeed())
+
+		By("analyzing the client calls")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Checker:   &loader.TypeChecker{},
+			Roots:     pkgs,
+		}
+		calls := rbac.AnalyzeClientCalls(ctx)
+		Expect(pkgs[0].Errors).To(BeEmpty())
+		var needed []string
+		for _, call := range calls {
+			needed = append(needed, call.Marker())
+		}
+		Expect(needed).To(Equal([]string{
+			"+kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=get",
+			"+kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=list",
+			"+kubebuilder:rbac:groups=batch.io,resources=cronjobs/status,verbs=update",
+			"+kubebuilder:rbac:groups=batch.io,resources=cronjobs/finalizers,verbs=update",
+			`+kubebuilder:rbac:groups="",resources=pods,verbs=create`,
+			"+kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=delete",
+			`+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create`,
+			`+kubebuilder:rbac:groups="",resources=secrets,verbs=get`,
+		}))
+
+		By("checking the calls against the generated roles")
+		roles, err := rbac.GenerateRoles(ctx, "manager-role")
+		Expect(err).NotTo(HaveOccurred())
+		var uncovered []string
+		for _, call := range rbac.UncoveredClientCalls(calls, roles) {
+			uncovered = append(uncovered, call.Marker())
+		}
+		Expect(uncovered).To(Equal([]string{
+			"+kubebuilder:rbac:groups=batch.io,resources=cronjobs/finalizers,verbs=update",
+			"+kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=delete",
+			`+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create`,
+			`+kubebuilder:rbac:groups="",resources=secrets,verbs=get`,
+		}))
+	})
+
+	It("should find rbac rules referencing unknown resources", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/analyze")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./validate")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("registering the markers")
+		reg := &markers.Registry{}
+		Expect(rbac.Generator{ValidateResources: true}.RegisterMarkers(reg)).To(Succeed())
+
+		By("validating the resources")
+		ctx := &genall.GenerationContext{
+			Collector: &markers.Collector{Registry: reg},
+			Roots:     pkgs,
+		}
+		var problems []string
+		for _, unknown := range rbac.ValidateResources(ctx) {
+			problems = append(problems, unknown.Error())
+		}
+		Expect(problems).To(Equal([]string{
+			`rbac rule references unknown resource "cronjob" in API group "batch.io"`,
+			`rbac rule references unknown API group "deployments.apps" (did you mean groups=apps,resources=deployments?)`,
+			`rbac rule references unknown API group "unknown.io"`,
+		}))
+	})
 })
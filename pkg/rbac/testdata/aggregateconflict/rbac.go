@@ -0,0 +1,23 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package aggregateconflict contains rules of a role aggregated into
+// different ClusterRoles.
+package aggregateconflict
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get,roleName=viewer,aggregateTo=view
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;update,roleName=viewer,aggregateTo=edit
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get,roleName=viewer,aggregateTo=view
@@ -0,0 +1,61 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package client is a minimal stand-in for the controller-runtime client
+// package, containing just enough for analyzing client calls.
+package client
+
+import "context"
+
+type Object any
+
+type ObjectList any
+
+type ObjectKey struct {
+	Namespace string
+	Name      string
+}
+
+type Reader interface {
+	Get(ctx context.Context, key ObjectKey, obj Object) error
+	List(ctx context.Context, list ObjectList) error
+}
+
+type Writer interface {
+	Create(ctx context.Context, obj Object) error
+	Delete(ctx context.Context, obj Object) error
+	Update(ctx context.Context, obj Object) error
+	Patch(ctx context.Context, obj Object) error
+	DeleteAllOf(ctx context.Context, obj Object) error
+}
+
+type SubResourceWriter interface {
+	Create(ctx context.Context, obj Object, subResource Object) error
+	Update(ctx context.Context, obj Object) error
+	Patch(ctx context.Context, obj Object) error
+}
+
+type SubResourceClient interface {
+	SubResourceWriter
+	Get(ctx context.Context, obj Object, subResource Object) error
+}
+
+type Client interface {
+	Reader
+	Writer
+	Status() SubResourceWriter
+	SubResource(subResource string) SubResourceClient
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	batchv1 "testdata.kubebuilder.io/analyze/api/v1"
+	"testdata.kubebuilder.io/analyze/core"
+)
+
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs/status,verbs=*
+// +kubebuilder:rbac:groups="",resources=pods,verbs=create
+
+type Reconciler struct {
+	client.Client
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	var cronJob batchv1.CronJob
+	if err := r.Get(ctx, client.ObjectKey{}, &cronJob); err != nil {
+		return err
+	}
+	var cronJobs batchv1.CronJobList
+	if err := r.List(ctx, &cronJobs); err != nil {
+		return err
+	}
+	if err := r.Status().Update(ctx, &cronJob); err != nil {
+		return err
+	}
+	pod := &core.Pod{}
+	if err := controllerutil.SetControllerReference(&cronJob, pod, nil); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, pod); err != nil {
+		return err
+	}
+
+	// not covered by any marker
+	if err := r.Delete(ctx, &cronJob); err != nil {
+		return err
+	}
+	if err := r.SubResource("eviction").Create(ctx, &core.Pod{}, nil); err != nil {
+		return err
+	}
+	secret := &core.Secret{}
+	return r.Client.Get(ctx, client.ObjectKey{}, secret)
+}
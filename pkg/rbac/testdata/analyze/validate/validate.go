@@ -0,0 +1,30 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validate contains rbac markers referencing known and unknown resources.
+package validate
+
+import (
+	_ "testdata.kubebuilder.io/analyze/api/v1"
+)
+
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs;cronjobs/status,verbs=get
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get
+// +kubebuilder:rbac:groups=*,resources=*,verbs=get
+// +kubebuilder:rbac:groups=batch.io,resources=cronjob,verbs=get
+// +kubebuilder:rbac:groups=deployments.apps,resources=*,verbs=get
+// +kubebuilder:rbac:groups=unknown.io,resources=things,verbs=get
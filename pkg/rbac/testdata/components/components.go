@@ -0,0 +1,24 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package components contains rbac markers split across components.
+package components
+
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch,clusterOnly=true
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=get,component=webhook
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get,component=webhook
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=update,component=cleaner,namespace=jobs
@@ -60,3 +60,23 @@ package controller
 // +kubebuilder:rbac:groups=apps,namespace=infrastructure,roleName=infra-deployment-manager,resources=statefulsets,verbs=get;list
 // Test backward compatibility - no roleName specified (uses default)
 // +kubebuilder:rbac:groups=monitoring,namespace=observability,resources=prometheuses,verbs=get;list
+// Test aggregation into other ClusterRoles
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=get;list;watch,aggregateTo=view;edit;admin
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs,verbs=create;update;patch;delete,aggregateTo=edit;admin
+// +kubebuilder:rbac:groups=batch.io,resources=cronjobs/status,verbs=get,aggregateTo=admin;edit
+// Test removal of rules covered by broader rules
+// +kubebuilder:rbac:groups=minimize,resources=things;others,verbs=*
+// +kubebuilder:rbac:groups=minimize,resources=others,resourceNames=special,verbs=delete
+// +kubebuilder:rbac:groups=minimize,resources=stuff,resourceNames=special,verbs=delete
+// +kubebuilder:rbac:groups=minimize,resources=stuff,verbs=get;list;watch
+// +kubebuilder:rbac:groups=minimize;minimize-too,resources=stuff,verbs=get
+// +kubebuilder:rbac:groups=*,resources=everything,verbs=get;list
+// +kubebuilder:rbac:groups=minimize,resources=everything,verbs=list
+// +kubebuilder:rbac:urls=/minimize/*,verbs=get
+// +kubebuilder:rbac:urls=/minimize/metrics,verbs=get
+
+// +kubebuilder:rbac:groups=finalize,resources=owners,verbs=get;list;watch,finalizers=true
+// +kubebuilder:rbac:groups=finalize,resources=owners/status,verbs=update,finalizers=true
+
+// +kubebuilder:rbac:groups=metadata,resources=things,verbs=get,namespace=meta,labels={app.kubernetes.io/part-of: testdata},annotations={"argocd.argoproj.io/sync-wave": "-1"}
+// +kubebuilder:rbac:groups=metadata,resources=others,verbs=get,namespace=meta,labels={app.kubernetes.io/component: rbac}
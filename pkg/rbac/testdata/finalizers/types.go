@@ -0,0 +1,33 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +groupName=apps.example.com
+package finalizers
+
+// +kubebuilder:rbac:groups=apps.example.com,resources=widgets,verbs=get;update
+// +kubebuilder:rbac:groups=apps.example.com,resources=gizmata,verbs=get;update,namespace=zoo
+
+// Widget has a finalizer.
+// +kubebuilder:finalizer=apps.example.com/cleanup
+type Widget struct{}
+
+// Gizmo has a finalizer, and a resource path of its own.
+// +kubebuilder:finalizer=apps.example.com/cleanup
+// +kubebuilder:resource:path=gizmata
+type Gizmo struct{}
+
+// Gadget has no finalizer.
+type Gadget struct{}
@@ -0,0 +1,228 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"fmt"
+	"go/ast"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// builtinResources lists the resources of the API groups built into Kubernetes
+// (and a few commonly installed extensions), by API group.
+var builtinResources = map[string][]string{
+	"": {
+		"bindings", "componentstatuses", "configmaps", "endpoints", "events", "limitranges",
+		"namespaces", "nodes", "persistentvolumeclaims", "persistentvolumes", "pods", "podtemplates",
+		"replicationcontrollers", "resourcequotas", "secrets", "serviceaccounts", "services",
+	},
+	"admissionregistration.k8s.io": {
+		"mutatingadmissionpolicies", "mutatingadmissionpolicybindings", "mutatingwebhookconfigurations",
+		"validatingadmissionpolicies", "validatingadmissionpolicybindings", "validatingwebhookconfigurations",
+	},
+	"apiextensions.k8s.io":   {"customresourcedefinitions"},
+	"apiregistration.k8s.io": {"apiservices"},
+	"apps":                   {"controllerrevisions", "daemonsets", "deployments", "replicasets", "statefulsets"},
+	"authentication.k8s.io":  {"selfsubjectreviews", "tokenreviews"},
+	"authorization.k8s.io": {
+		"localsubjectaccessreviews", "selfsubjectaccessreviews", "selfsubjectrulesreviews", "subjectaccessreviews",
+	},
+	"autoscaling":                  {"horizontalpodautoscalers"},
+	"batch":                        {"cronjobs", "jobs"},
+	"certificates.k8s.io":          {"certificatesigningrequests", "clustertrustbundles"},
+	"coordination.k8s.io":          {"leasecandidates", "leases"},
+	"discovery.k8s.io":             {"endpointslices"},
+	"events.k8s.io":                {"events"},
+	"flowcontrol.apiserver.k8s.io": {"flowschemas", "prioritylevelconfigurations"},
+	"internal.apiserver.k8s.io":    {"storageversions"},
+	"metrics.k8s.io":               {"nodes", "pods"},
+	"networking.k8s.io":            {"ingressclasses", "ingresses", "ipaddresses", "networkpolicies", "servicecidrs"},
+	"node.k8s.io":                  {"runtimeclasses"},
+	"policy":                       {"poddisruptionbudgets"},
+	"rbac.authorization.k8s.io":    {"clusterrolebindings", "clusterroles", "rolebindings", "roles"},
+	"resource.k8s.io":              {"deviceclasses", "resourceclaims", "resourceclaimtemplates", "resourceslices"},
+	"scheduling.k8s.io":            {"priorityclasses"},
+	"storage.k8s.io": {
+		"csidrivers", "csinodes", "csistoragecapacities", "storageclasses", "volumeattachments", "volumeattributesclasses",
+	},
+	"storagemigration.k8s.io": {"storageversionmigrations"},
+}
+
+// UnknownResource is a resource referenced by an rbac rule that doesn't match
+// any known API resource.
+type UnknownResource struct {
+	// Package is the package containing the rule.
+	Package *loader.Package
+	// Group is the API group referenced by the rule.
+	Group string
+	// Resource is the resource referenced by the rule, or empty if the whole
+	// API group is unknown.
+	Resource string
+	// Hint is a suggested fix for the rule, if any.
+	Hint string
+}
+
+func (u UnknownResource) Error() string {
+	var msg string
+	if u.Resource == "" {
+		msg = fmt.Sprintf("rbac rule references unknown API group %q", u.Group)
+	} else {
+		msg = fmt.Sprintf("rbac rule references unknown resource %q in API group %q", u.Resource, u.Group)
+	}
+	if u.Hint != "" {
+		msg += fmt.Sprintf(" (did you mean %s?)", u.Hint)
+	}
+	return msg
+}
+
+// ValidateResources checks the groups and resources of the rbac rules in the
+// given roots against the built-in Kubernetes API resources and the kinds
+// defined in the roots and the packages they import.
+//
+// Kinds are recognized as types embedding TypeMeta in packages with a
+// +groupName marker, and their resources are guessed by pluralizing their names,
+// so custom resource names aren't taken into account.  Rules with wildcard
+// groups or resources aren't checked.
+func ValidateResources(ctx *genall.GenerationContext) []UnknownResource {
+	known := make(map[string][]string, len(builtinResources))
+	for group, resources := range builtinResources {
+		known[group] = slices.Clone(resources)
+	}
+	seen := make(map[string]bool)
+	for _, root := range ctx.Roots {
+		for _, pkg := range append([]*loader.Package{root}, slices.Collect(maps.Values(root.Imports()))...) {
+			if seen[pkg.PkgPath] {
+				continue
+			}
+			seen[pkg.PkgPath] = true
+			addKnownResources(ctx.Collector, pkg, known)
+		}
+	}
+
+	var unknown []UnknownResource
+	for _, root := range ctx.Roots {
+		markerSet, err := markers.PackageMarkers(ctx.Collector, root)
+		if err != nil {
+			// reported by the generator itself
+			continue
+		}
+		for _, markerValue := range markerSet[RuleDefinition.Name] {
+			rule := markerValue.(Rule)
+			for _, group := range rule.Groups {
+				if group == "core" {
+					group = ""
+				}
+				if group == "*" {
+					continue
+				}
+				resources, knownGroup := known[group]
+				if !knownGroup {
+					unknown = append(unknown, UnknownResource{Package: root, Group: group, Hint: groupHint(group, known)})
+					continue
+				}
+				for _, resource := range rule.Resources {
+					resource, _, _ = strings.Cut(resource, "/")
+					if resource == "*" || slices.Contains(resources, resource) {
+						continue
+					}
+					unknown = append(unknown, UnknownResource{Package: root, Group: group, Resource: resource})
+				}
+			}
+		}
+	}
+	return unknown
+}
+
+// addKnownResources adds the resources of the kinds defined in the given
+// package, if it has a +groupName marker, to the given known resources.
+func addKnownResources(col *markers.Collector, pkg *loader.Package, known map[string][]string) {
+	if firstElem, _, _ := strings.Cut(pkg.PkgPath, "/"); !strings.Contains(firstElem, ".") || firstElem == "k8s.io" {
+		// the standard library doesn't define any kinds, and the ones in
+		// k8s.io are covered by the built-in resources
+		return
+	}
+	pkgMarkers, err := markers.PackageMarkers(col, pkg)
+	if err != nil {
+		return
+	}
+	gv := crd.GroupVersionForPackage(pkgMarkers, pkg)
+	if gv.Version == "" {
+		return
+	}
+
+	var kinds []string
+	_ = markers.EachType(col, pkg, func(info *markers.TypeInfo) {
+		if !embedsTypeMeta(info) {
+			return
+		}
+		kinds = append(kinds, info.Name)
+	})
+	for _, kind := range kinds {
+		if itemKind, isList := strings.CutSuffix(kind, "List"); isList && slices.Contains(kinds, itemKind) {
+			continue
+		}
+		resource := strings.ToLower(flect.Pluralize(kind))
+		if !slices.Contains(known[gv.Group], resource) {
+			known[gv.Group] = append(known[gv.Group], resource)
+		}
+	}
+	if _, exists := known[gv.Group]; !exists {
+		// the group exists, even if we couldn't find any kinds in it
+		known[gv.Group] = nil
+	}
+}
+
+// embedsTypeMeta checks if the given type embeds TypeMeta, i.e. is a kind.
+func embedsTypeMeta(info *markers.TypeInfo) bool {
+	for _, field := range info.Fields {
+		if field.Name != "" {
+			continue
+		}
+		switch fieldType := field.RawField.Type.(type) {
+		case *ast.SelectorExpr:
+			if fieldType.Sel.Name == "TypeMeta" {
+				return true
+			}
+		case *ast.Ident:
+			if fieldType.Name == "TypeMeta" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// groupHint suggests a fix for an unknown API group that is actually a
+// qualified resource, like "deployments.apps".
+func groupHint(group string, known map[string][]string) string {
+	resource, actualGroup, isQualified := strings.Cut(group, ".")
+	if !isQualified {
+		return ""
+	}
+	if resources, knownGroup := known[actualGroup]; knownGroup && slices.Contains(resources, resource) {
+		return fmt.Sprintf("groups=%s,resources=%s", actualGroup, resource)
+	}
+	return ""
+}
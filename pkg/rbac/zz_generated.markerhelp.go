@@ -24,6 +24,17 @@ import (
 	"sigs.k8s.io/controller-tools/pkg/markers"
 )
 
+func (DefaultRoleName) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "RBAC",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "sets the name of the Role or ClusterRole that the RBAC rules",
+			Details: "in this package belong to, overriding the roleName of the generator.\n\nThis makes it possible to generate several named roles in a single invocation,\nwith the rules for each role kept in their own package.  Rules that set their\nown roleName aren't affected.\n\nExample:\n\n\t// +kubebuilder:rbac:defaultRoleName=webhook-role\n\t// +kubebuilder:rbac:groups=\"\",resources=secrets,verbs=get",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{},
+	}
+}
+
 func (Generator) Help() *markers.DefinitionHelp {
 	return &markers.DefinitionHelp{
 		Category: "",
@@ -34,7 +45,7 @@ func (Generator) Help() *markers.DefinitionHelp {
 		FieldHelp: map[string]markers.DetailedHelp{
 			"RoleName": {
 				Summary: "sets the name of the generated ClusterRole.",
-				Details: "",
+				Details: "It may be omitted if every rule gets its name from its own roleName, or from\na +kubebuilder:rbac:defaultRoleName marker on its package.",
 			},
 			"FileName": {
 				Summary: "sets the file name for the generated manifest(s). If not set, defaults to \"role.yaml\".",
@@ -48,6 +59,54 @@ func (Generator) Help() *markers.DefinitionHelp {
 				Summary: "specifies the year to substitute for \" YEAR\" in the header file.",
 				Details: "",
 			},
+			"ServiceAccountName": {
+				Summary: "enables generating a ServiceAccount with the given name,",
+				Details: "along with a ClusterRoleBinding (or RoleBinding) binding each generated\nClusterRole (or Role) to it.  Aggregated ClusterRoles aren't bound.",
+			},
+			"ServiceAccountNamespace": {
+				Summary: "sets the namespace of the generated ServiceAccount.",
+				Details: "If not set, defaults to \"system\".",
+			},
+			"Overrides": {
+				Summary: "specifies a config file overriding the scoping of the rules",
+				Details: "generated from rbac markers, or dropping some of them, e.g. for a specific\nenvironment:\n\n\toverrides:\n\t- match:\n\t    groups: [\"\"]\n\t    resources: [secrets]\n\t  namespace: my-operator-system\n\t- match:\n\t    groups: [networking.k8s.io]\n\t    resources: [ingresses]\n\t  drop: true\n\nEach rule is overridden by the first override matching it.",
+			},
+			"NamespacedComponent": {
+				Summary: "enables writing a kustomize Component for",
+				Details: "single-namespace installs into the given directory (relative to the output).\n\nThe Component turns the generated ClusterRoles (and their bindings) into\nRoles (and RoleBindings), leaving out the rules marked clusterOnly, so that\nthe same set of markers can be used for both cluster-wide and single-namespace\ninstalls.  It can't be combined with Helm.",
+			},
+			"AuditAgainst": {
+				Summary: "enables audit mode, comparing the generated roles against the",
+				Details: "ClusterRoles and Roles in the given YAML file instead of writing them out.\n\nAny permissions that are generated but missing from the file, or present\nin the file but no longer generated, are reported as errors, which makes\nthis useful for checking least-privilege drift in CI.",
+			},
+			"AuditCluster": {
+				Summary: "enables audit mode against the ClusterRoles and Roles of",
+				Details: "the cluster of the run instead (see the --kubeconfig and --context\nflags), as with AuditAgainst.",
+			},
+			"Helm": {
+				Summary: "enables writing the generated manifests as Helm chart templates.",
+				Details: "Object names are prefixed with the release name, the ServiceAccount is\nplaced in the release namespace, and setting the chart's rbac.namespaced\nvalue turns the generated ClusterRoles into Roles in the release namespace.",
+			},
+			"Labels": {
+				Summary: "specifies labels to add to all the generated Roles and ClusterRoles.",
+				Details: "Labels set by rbac markers take precedence.",
+			},
+			"Annotations": {
+				Summary: "specifies annotations to add to all the generated Roles and",
+				Details: "ClusterRoles.  Annotations set by rbac markers take precedence.",
+			},
+			"ValidateResources": {
+				Summary: "enables warnings for rbac rules referencing API groups or",
+				Details: "resources that don't match any built-in Kubernetes resource, or any kind\ndefined in the loaded packages (or the packages they import directly).\n\nThis catches typos and mixups like \"groups=deployments.apps\" early.",
+			},
+			"StrictResources": {
+				Summary: "makes the problems found by ValidateResources errors",
+				Details: "instead of warnings.",
+			},
+			"AnalyzeClientCalls": {
+				Summary: "enables (experimental) checking of calls to controller-runtime",
+				Details: "clients against the generated roles.\n\nThe permission needed by each Get, List, Create, Update, Patch, Delete, etc call\nis figured out from the type of the object passed to it, as is the permission\nto update the finalizers of the owner passed to controllerutil.SetControllerReference.\nCalls whose permission isn't granted by any rbac marker are reported as errors,\nalong with a marker that would grant it.",
+			},
 		},
 	}
 }
@@ -57,7 +116,7 @@ func (Rule) Help() *markers.DefinitionHelp {
 		Category: "RBAC",
 		DetailedHelp: markers.DetailedHelp{
 			Summary: "specifies an RBAC rule to all access to some resources or non-resource URLs.",
-			Details: "RBAC markers are used to generate ClusterRole or Role manifests.\nMultiple markers can be combined to build comprehensive RBAC policies.\n\nExamples:\n\n\t// Basic resource access\n\t// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch\n\n\t// Core API group (use empty string)\n\t// +kubebuilder:rbac:groups=\"\",resources=pods;services,verbs=get;list;watch\n\n\t// Multiple API groups and resources\n\t// +kubebuilder:rbac:groups=apps;batch,resources=deployments;jobs,verbs=get;list;watch;create;update;patch;delete\n\n\t// Access to resource status or scale subresources\n\t// +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch\n\t// +kubebuilder:rbac:groups=apps,resources=deployments/scale,verbs=get;update\n\n\t// Access to specific resource instances by name\n\t// +kubebuilder:rbac:groups=\"\",resources=configmaps,resourceNames=my-config,verbs=get\n\n\t// Non-resource URLs (for metrics, healthz, etc.)\n\t// +kubebuilder:rbac:urls=/metrics;/healthz,verbs=get\n\n\t// Namespace-scoped Role instead of ClusterRole\n\t// +kubebuilder:rbac:groups=\"\",namespace=my-namespace,resources=secrets,verbs=get;list;watch\n\n\t// Custom role name\n\t// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list,roleName=deployment-reader",
+			Details: "RBAC markers are used to generate ClusterRole or Role manifests.\nMultiple markers can be combined to build comprehensive RBAC policies.\n\nExamples:\n\n\t// Basic resource access\n\t// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch\n\n\t// Core API group (use empty string)\n\t// +kubebuilder:rbac:groups=\"\",resources=pods;services,verbs=get;list;watch\n\n\t// Multiple API groups and resources\n\t// +kubebuilder:rbac:groups=apps;batch,resources=deployments;jobs,verbs=get;list;watch;create;update;patch;delete\n\n\t// Access to resource status or scale subresources\n\t// +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch\n\t// +kubebuilder:rbac:groups=apps,resources=deployments/scale,verbs=get;update\n\n\t// Access to the finalizers subresource, needed for setting blocking owner references\n\t// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch,finalizers=true\n\n\t// Access to specific resource instances by name\n\t// +kubebuilder:rbac:groups=\"\",resources=configmaps,resourceNames=my-config,verbs=get\n\n\t// Non-resource URLs (for metrics, healthz, etc.)\n\t// +kubebuilder:rbac:urls=/metrics;/healthz,verbs=get\n\n\t// Namespace-scoped Role instead of ClusterRole\n\t// +kubebuilder:rbac:groups=\"\",namespace=my-namespace,resources=secrets,verbs=get;list;watch\n\n\t// Custom role name\n\t// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list,roleName=deployment-reader\n\n\t// User-facing permissions aggregated into the built-in admin and edit roles\n\t// +kubebuilder:rbac:groups=batch.tutorial.kubebuilder.io,resources=cronjobs,verbs=*,aggregateTo=admin;edit",
 		},
 		FieldHelp: map[string]markers.DetailedHelp{
 			"Groups": {
@@ -80,13 +139,37 @@ func (Rule) Help() *markers.DefinitionHelp {
 				Summary: "URL specifies the non-resource URLs that this rule encompasses.",
 				Details: "Non-resource URLs are paths that don't represent resources, like \"/metrics\" or \"/healthz\".\nMultiple URLs can be specified separated by semicolons.\nExample: \"/metrics;/healthz\".",
 			},
+			"Finalizers": {
+				Summary: "also grants permission to update the finalizers subresource",
+				Details: "of each of the given resources.\n\nThis permission is needed to set owner references that block the deletion\nof their owner (like the ones set by controllerutil.SetControllerReference)\nwhen the OwnerReferencesPermissionEnforcement admission plugin is enabled,\nand is easily forgotten since it's not needed otherwise.\nExample: \"true\".",
+			},
 			"Namespace": {
 				Summary: "specifies the scope of the Rule.",
 				Details: "If not set, the Rule belongs to the generated ClusterRole.\nIf set, the Rule belongs to a Role, whose namespace is specified by this field.\nExample: \"my-namespace\".",
 			},
 			"RoleName": {
 				Summary: "specifies a custom name for the Role or ClusterRole.",
-				Details: "If not set, uses the default roleName from the generator.\nUseful for avoiding name conflicts when the same roleName is used across multiple namespaces.\n\nExample: When using namespace-scoped RBAC markers with kustomize's global namespace transformation,\nmultiple Roles might end up in the same namespace with identical names, causing an \"ID conflict\" error.\nUse roleName to ensure each Role has a unique name:\n\n  // +kubebuilder:rbac:groups=apps,namespace=infrastructure,roleName=infra-manager,resources=deployments,verbs=get;list\n  // +kubebuilder:rbac:groups=\"\",namespace=users,roleName=user-secrets,resources=secrets,verbs=get\n\nThis generates Roles named \"infra-manager\" and \"user-secrets\" instead of both being \"manager-role\".",
+				Details: "If not set, uses the +kubebuilder:rbac:defaultRoleName of the package, if\nany, or else the default roleName from the generator.\nUseful for avoiding name conflicts when the same roleName is used across multiple namespaces.\n\nExample: When using namespace-scoped RBAC markers with kustomize's global namespace transformation,\nmultiple Roles might end up in the same namespace with identical names, causing an \"ID conflict\" error.\nUse roleName to ensure each Role has a unique name:\n\n  // +kubebuilder:rbac:groups=apps,namespace=infrastructure,roleName=infra-manager,resources=deployments,verbs=get;list\n  // +kubebuilder:rbac:groups=\"\",namespace=users,roleName=user-secrets,resources=secrets,verbs=get\n\nThis generates Roles named \"infra-manager\" and \"user-secrets\" instead of both being \"manager-role\".",
+			},
+			"Component": {
+				Summary: "groups the Rule with other Rules of the same component.",
+				Details: "Roles for each component are generated separately from the roles for Rules\nwithout a component, and are written into their own \"<component>_<fileName>\"\nfile, so that different deployments of the same codebase can ship only the\npermissions that they need.\nExample: \"webhook\".",
+			},
+			"AggregateTo": {
+				Summary: "specifies the ClusterRoles that this Rule is aggregated into.",
+				Details: "If set, the Rule doesn't belong to the generated ClusterRole, but to a separate\nClusterRole labeled with \"rbac.authorization.k8s.io/aggregate-to-<name>: true\"\nfor each of the given names.  This is usually used to grant users access to\ncustom resources through the built-in \"admin\", \"edit\" and \"view\" roles.\nMultiple names can be specified separated by semicolons.\n\nRules with the same set of names are merged into the same ClusterRole, named\n\"<roleName>-aggregate-to-<names joined by dashes>\" unless RoleName is set,\nin which case all the Rules of the role must have the same names.\nAggregateTo can't be combined with Namespace.\nExample: \"admin;edit\".",
+			},
+			"Labels": {
+				Summary: "specifies labels to add to the Role or ClusterRole that the Rule",
+				Details: "belongs to.  Labels from all the Rules of a role are combined.\nExample: {app.kubernetes.io/part-of: my-operator}.",
+			},
+			"Annotations": {
+				Summary: "specifies annotations to add to the Role or ClusterRole that",
+				Details: "the Rule belongs to.  Annotations from all the Rules of a role are combined.\nExample: {\"argocd.argoproj.io/sync-wave\": \"-1\"}.",
+			},
+			"ClusterOnly": {
+				Summary: "marks the Rule as only needed by cluster-wide installs.",
+				Details: "The kustomize component generated for single-namespace installs (see the\nnamespacedComponent option of the generator) turns ClusterRoles into Roles\nin the install namespace, and leaves out the Rules marked ClusterOnly, e.g.\nRules for cluster-scoped resources, which can't be granted by Roles.\nExample: \"true\".",
 			},
 		},
 	}
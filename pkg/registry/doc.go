@@ -0,0 +1,29 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry generates a registry of the kinds of API packages, mapping
+// their group-version-kinds and resources to their Go types, for generic
+// tooling like dynamic controllers and CLI plugins:
+//
+//	entry, found := v1.RegistryEntryForResource("widgets")
+//	if found {
+//		obj := entry.New()
+//		...
+//	}
+//
+// Unlike a runtime.Scheme, the registry knows the resources and scopes of the
+// kinds, and needs no reflection to find their types.
+package registry
@@ -0,0 +1,237 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing declarations, we only see hand-written ones.
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.registry.go"
+
+// generatedNames are the names of the declarations of the generated file.
+var generatedNames = []string{"RegistryEntry", "Registry", "RegistryEntryFor", "RegistryEntryForResource"}
+
+// +controllertools:marker:generateHelp
+
+// Generator generates registries of the kinds of API packages.
+//
+// The registry of each package is written to zz_generated.registry.go, as a
+// Registry of RegistryEntry values with the group-version-kind, resource and
+// scope of each kind, along with functions creating new objects and lists of
+// it, and RegistryEntryFor and RegistryEntryForResource functions looking
+// entries up.  The kinds must implement runtime.Object, e.g. by generating
+// their DeepCopyObject methods with the object generator.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+// registryEntry is the entry of a kind in the registry of its package.
+type registryEntry struct {
+	gvk        schema.GroupVersionKind
+	resource   string
+	namespaced bool
+	// listKind is the kind of the list of the kind, if it has one.
+	listKind string
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// the registry shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	entriesByPkg := make(map[*loader.Package][]registryEntry)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(groupKind, nil)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, root := range ctx.Roots {
+			if parser.GroupVersions[root].Group != groupKind.Group {
+				continue
+			}
+			if parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}] == nil {
+				continue
+			}
+			entry := registryEntry{
+				gvk:        parser.GroupVersions[root].WithKind(groupKind.Kind),
+				resource:   crdRaw.Spec.Names.Plural,
+				namespaced: crdRaw.Spec.Scope == apiextensionsv1.NamespaceScoped,
+			}
+			if root.Types.Scope().Lookup(crdRaw.Spec.Names.ListKind) != nil {
+				entry.listKind = crdRaw.Spec.Names.ListKind
+			}
+			entriesByPkg[root] = append(entriesByPkg[root], entry)
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		entries := entriesByPkg[root]
+		if len(entries) == 0 {
+			continue
+		}
+		slices.SortFunc(entries, func(a, b registryEntry) int {
+			return strings.Compare(a.gvk.Kind, b.gvk.Kind)
+		})
+		if outContents := generateForPackage(root, entries, headerText); outContents != nil {
+			gogen.WriteOut(ctx, root, outputFileName, outContents)
+		}
+	}
+	return nil
+}
+
+// generateForPackage generates the registry of the given kinds of the given
+// package.  May return nil if the names of the registry are already taken.
+func generateForPackage(root *loader.Package, entries []registryEntry, headerText string) []byte {
+	for _, name := range generatedNames {
+		if obj := root.Types.Scope().Lookup(name); obj != nil {
+			root.AddError(fmt.Errorf("cannot generate the registry: %s is already declared at %s", name, root.Fset.Position(obj.Pos())))
+			return nil
+		}
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RegistryEntry describes a kind of this group-version, for generic tooling.
+type RegistryEntry struct {
+	// GroupVersionKind is the group, version and kind of the objects.
+	GroupVersionKind schema.GroupVersionKind
+	// GroupVersionResource is the group, version and (plural) resource of the
+	// objects.
+	GroupVersionResource schema.GroupVersionResource
+	// Namespaced indicates whether the objects are namespaced, rather than
+	// cluster-scoped.
+	Namespaced bool
+	// ListKind is the kind of lists of the objects, if any.
+	ListKind string
+
+	// New returns a new, empty object of the kind.
+	New func() runtime.Object
+	// NewList returns a new, empty list of the kind, or is nil if the kind
+	// has no list.
+	NewList func() runtime.Object
+}
+
+// Registry is the registry of the kinds of this group-version, sorted by kind.
+var Registry = []RegistryEntry{
+`, root.Name, headerText)
+
+	for _, entry := range entries {
+		gv := entry.gvk.GroupVersion()
+		fmt.Fprintf(outContent, `{
+	GroupVersionKind:     schema.GroupVersionKind{Group: %[1]q, Version: %[2]q, Kind: %[3]q},
+	GroupVersionResource: schema.GroupVersionResource{Group: %[1]q, Version: %[2]q, Resource: %[4]q},
+	Namespaced:           %[5]t,
+`, gv.Group, gv.Version, entry.gvk.Kind, entry.resource, entry.namespaced)
+		if entry.listKind != "" {
+			fmt.Fprintf(outContent, "ListKind: %q,\n", entry.listKind)
+		}
+		fmt.Fprintf(outContent, "New: func() runtime.Object { return &%s{} },\n", entry.gvk.Kind)
+		if entry.listKind != "" {
+			fmt.Fprintf(outContent, "NewList: func() runtime.Object { return &%s{} },\n", entry.listKind)
+		}
+		outContent.WriteString("},\n")
+	}
+
+	outContent.WriteString(`}
+
+// RegistryEntryFor returns the entry of the given group-version-kind in the
+// registry, if any.
+func RegistryEntryFor(gvk schema.GroupVersionKind) (RegistryEntry, bool) {
+	for _, entry := range Registry {
+		if entry.GroupVersionKind == gvk {
+			return entry, true
+		}
+	}
+	return RegistryEntry{}, false
+}
+
+// RegistryEntryForResource returns the entry of the given (plural) resource
+// in the registry, if any.
+func RegistryEntryForResource(resource string) (RegistryEntry, bool) {
+	for _, entry := range Registry {
+		if entry.GroupVersionResource.Resource == resource {
+			return entry, true
+		}
+	}
+	return RegistryEntry{}, false
+}
+`)
+
+	return gogen.Format(root, outContent.Bytes())
+}
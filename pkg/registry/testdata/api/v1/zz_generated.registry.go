@@ -0,0 +1,86 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RegistryEntry describes a kind of this group-version, for generic tooling.
+type RegistryEntry struct {
+	// GroupVersionKind is the group, version and kind of the objects.
+	GroupVersionKind schema.GroupVersionKind
+	// GroupVersionResource is the group, version and (plural) resource of the
+	// objects.
+	GroupVersionResource schema.GroupVersionResource
+	// Namespaced indicates whether the objects are namespaced, rather than
+	// cluster-scoped.
+	Namespaced bool
+	// ListKind is the kind of lists of the objects, if any.
+	ListKind string
+
+	// New returns a new, empty object of the kind.
+	New func() runtime.Object
+	// NewList returns a new, empty list of the kind, or is nil if the kind
+	// has no list.
+	NewList func() runtime.Object
+}
+
+// Registry is the registry of the kinds of this group-version, sorted by kind.
+var Registry = []RegistryEntry{
+	{
+		GroupVersionKind:     schema.GroupVersionKind{Group: "apps.testdata.kubebuilder.io", Version: "v1", Kind: "Gadget"},
+		GroupVersionResource: schema.GroupVersionResource{Group: "apps.testdata.kubebuilder.io", Version: "v1", Resource: "gizmos"},
+		Namespaced:           false,
+		New:                  func() runtime.Object { return &Gadget{} },
+	},
+	{
+		GroupVersionKind:     schema.GroupVersionKind{Group: "apps.testdata.kubebuilder.io", Version: "v1", Kind: "Widget"},
+		GroupVersionResource: schema.GroupVersionResource{Group: "apps.testdata.kubebuilder.io", Version: "v1", Resource: "widgets"},
+		Namespaced:           true,
+		ListKind:             "WidgetList",
+		New:                  func() runtime.Object { return &Widget{} },
+		NewList:              func() runtime.Object { return &WidgetList{} },
+	},
+}
+
+// RegistryEntryFor returns the entry of the given group-version-kind in the
+// registry, if any.
+func RegistryEntryFor(gvk schema.GroupVersionKind) (RegistryEntry, bool) {
+	for _, entry := range Registry {
+		if entry.GroupVersionKind == gvk {
+			return entry, true
+		}
+	}
+	return RegistryEntry{}, false
+}
+
+// RegistryEntryForResource returns the entry of the given (plural) resource
+// in the registry, if any.
+func RegistryEntryForResource(resource string) (RegistryEntry, bool) {
+	for _, entry := range Registry {
+		if entry.GroupVersionResource.Resource == resource {
+			return entry, true
+		}
+	}
+	return RegistryEntry{}, false
+}
@@ -0,0 +1,23 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package roundtrip generates fuzz round-trip tests for the kinds of API
+// packages, similar to the round-trip tests of apimachinery.
+//
+// Random objects of each kind (and its list) are checked to survive JSON
+// marshalling, deep copies and conversions to and from unstructured objects,
+// so that asymmetric json tags and lossy types are caught automatically.
+package roundtrip
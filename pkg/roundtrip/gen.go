@@ -0,0 +1,204 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtrip
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.roundtrip_test.go"
+
+// defaultIterations is the default number of random objects of each kind to
+// round-trip.
+const defaultIterations = 100
+
+// +controllertools:marker:generateHelp
+
+// Generator generates fuzz round-trip tests for the kinds of API packages.
+//
+// The tests of the kinds of each package are written to
+// zz_generated.roundtrip_test.go.  Objects are filled randomly with the fuzzer
+// functions of the meta types of apimachinery, with a random seed reported on
+// failures.
+type Generator struct {
+	// Iterations is the number of random objects of each kind to round-trip (defaults to 100).
+	Iterations int `marker:",optional"`
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// tests shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	iterations := g.Iterations
+	if iterations <= 0 {
+		iterations = defaultIterations
+	}
+
+	kindsByPkg := make(map[*loader.Package][]string)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		for _, root := range ctx.Roots {
+			if parser.GroupVersions[root].Group != groupKind.Group {
+				continue
+			}
+			if parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}] == nil {
+				continue
+			}
+			kindsByPkg[root] = append(kindsByPkg[root], groupKind.Kind)
+			if parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind + "List"}] != nil {
+				kindsByPkg[root] = append(kindsByPkg[root], groupKind.Kind+"List")
+			}
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		kinds := kindsByPkg[root]
+		if len(kinds) == 0 {
+			continue
+		}
+		slices.Sort(kinds)
+		gogen.WriteOut(ctx, root, outputFileName, testsFile(root, headerText, iterations, kinds))
+	}
+	return nil
+}
+
+// testsFile returns the formatted contents of the test file of the given
+// package, testing the given kinds.
+func testsFile(pkg *loader.Package, headerText string, iterations int, kinds []string) []byte {
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+// roundTripIterations is the number of random objects of each kind to round-trip.
+const roundTripIterations = %[3]d
+`, pkg.Name, headerText, iterations)
+
+	for _, kind := range kinds {
+		fmt.Fprintf(outContent, `
+func Test%[1]sRoundTrip(t *testing.T) {
+	testRoundTrip[%[1]s](t)
+}
+`, kind)
+	}
+
+	outContent.WriteString(`
+// testRoundTrip checks that random objects of the given type survive JSON
+// marshalling, deep copies and conversions to and from unstructured objects.
+func testRoundTrip[T any](t *testing.T) {
+	t.Helper()
+	seed := rand.Int63()
+	filler := fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(seed), serializer.NewCodecFactory(runtime.NewScheme()))
+	for range roundTripIterations {
+		original := new(T)
+		filler.Fill(original)
+
+		data, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("failed to marshal %T (seed %d): %v", original, seed, err)
+		}
+		fromJSON := new(T)
+		if err := json.Unmarshal(data, fromJSON); err != nil {
+			t.Fatalf("failed to unmarshal %T (seed %d): %v\n%s", original, seed, err, data)
+		}
+		if !apiequality.Semantic.DeepEqual(original, fromJSON) {
+			t.Fatalf("%T changed through JSON (seed %d):\n%s", original, seed, diff.ObjectGoPrintSideBySide(original, fromJSON))
+		}
+
+		if obj, isObj := any(original).(runtime.Object); isObj {
+			if copied := obj.DeepCopyObject(); !apiequality.Semantic.DeepEqual(obj, copied) {
+				t.Fatalf("%T changed through DeepCopy (seed %d):\n%s", original, seed, diff.ObjectGoPrintSideBySide(obj, copied))
+			}
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(original)
+		if err != nil {
+			t.Fatalf("failed to convert %T to unstructured (seed %d): %v", original, seed, err)
+		}
+		fromUnstructured := new(T)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, fromUnstructured); err != nil {
+			t.Fatalf("failed to convert %T from unstructured (seed %d): %v", original, seed, err)
+		}
+		if !apiequality.Semantic.DeepEqual(original, fromUnstructured) {
+			t.Fatalf("%T changed through unstructured (seed %d):\n%s", original, seed, diff.ObjectGoPrintSideBySide(original, fromUnstructured))
+		}
+	}
+}
+`)
+
+	return gogen.Format(pkg, outContent.Bytes())
+}
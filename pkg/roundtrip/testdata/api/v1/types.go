@@ -0,0 +1,75 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:object:generate=true
+// +groupName=apps.testdata.kubebuilder.io
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WidgetSpec is the spec of a widget.
+type WidgetSpec struct {
+	Replicas *int32            `json:"replicas,omitempty"`
+	Image    string            `json:"image"`
+	Memory   resource.Quantity `json:"memory,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ports    []Port            `json:"ports,omitempty"`
+}
+
+// Port is a port of a widget.
+type Port struct {
+	Name string `json:"name"`
+	Port int32  `json:"port"`
+}
+
+// WidgetStatus is the status of a widget.
+type WidgetStatus struct {
+	LastScaleTime *metav1.Time       `json:"lastScaleTime,omitempty"`
+	Conditions    []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Widget is an app made of widgets.
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WidgetList is a list of widgets.
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// Gadget is a part of widgets, without a list.
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Parts []string `json:"parts,omitempty"`
+}
@@ -0,0 +1,91 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+// roundTripIterations is the number of random objects of each kind to round-trip.
+const roundTripIterations = 100
+
+func TestGadgetRoundTrip(t *testing.T) {
+	testRoundTrip[Gadget](t)
+}
+
+func TestWidgetRoundTrip(t *testing.T) {
+	testRoundTrip[Widget](t)
+}
+
+func TestWidgetListRoundTrip(t *testing.T) {
+	testRoundTrip[WidgetList](t)
+}
+
+// testRoundTrip checks that random objects of the given type survive JSON
+// marshalling, deep copies and conversions to and from unstructured objects.
+func testRoundTrip[T any](t *testing.T) {
+	t.Helper()
+	seed := rand.Int63()
+	filler := fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(seed), serializer.NewCodecFactory(runtime.NewScheme()))
+	for range roundTripIterations {
+		original := new(T)
+		filler.Fill(original)
+
+		data, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("failed to marshal %T (seed %d): %v", original, seed, err)
+		}
+		fromJSON := new(T)
+		if err := json.Unmarshal(data, fromJSON); err != nil {
+			t.Fatalf("failed to unmarshal %T (seed %d): %v\n%s", original, seed, err, data)
+		}
+		if !apiequality.Semantic.DeepEqual(original, fromJSON) {
+			t.Fatalf("%T changed through JSON (seed %d):\n%s", original, seed, diff.ObjectGoPrintSideBySide(original, fromJSON))
+		}
+
+		if obj, isObj := any(original).(runtime.Object); isObj {
+			if copied := obj.DeepCopyObject(); !apiequality.Semantic.DeepEqual(obj, copied) {
+				t.Fatalf("%T changed through DeepCopy (seed %d):\n%s", original, seed, diff.ObjectGoPrintSideBySide(obj, copied))
+			}
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(original)
+		if err != nil {
+			t.Fatalf("failed to convert %T to unstructured (seed %d): %v", original, seed, err)
+		}
+		fromUnstructured := new(T)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, fromUnstructured); err != nil {
+			t.Fatalf("failed to convert %T from unstructured (seed %d): %v", original, seed, err)
+		}
+		if !apiequality.Semantic.DeepEqual(original, fromUnstructured) {
+			t.Fatalf("%T changed through unstructured (seed %d):\n%s", original, seed, diff.ObjectGoPrintSideBySide(original, fromUnstructured))
+		}
+	}
+}
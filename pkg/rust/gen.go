@@ -0,0 +1,291 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rust
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates Rust types, for use with kube-rs, for the kinds of each
+// group-version.  It's experimental.
+//
+// Each group-version is written to a Rust module named <group>_<version>.rs,
+// with dots and dashes of the group replaced by underscores.  The spec type of
+// each kind derives kube::CustomResource, with the group, version, plural,
+// scope, short names and status of the kind, so that kube-rs generates the
+// kind itself; kinds without a spec are written as plain structs.  The other
+// types used by the kinds are written as structs with serde attributes mapping
+// snake_case fields to the JSON field names, as enums for string enums, and as
+// type aliases otherwise, and the types of Kubernetes (like ObjectMeta and
+// Condition) are the ones of k8s-openapi.  Validation markers aren't mapped:
+// the CRDs stay the source of truth for validation.
+//
+// The modules use the kube (with the derive feature), k8s-openapi (with the
+// schemars feature), schemars, serde and serde_json crates.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// Rust has no issue with the types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	kubeKinds := crd.FindKubeKinds(parser, metav1Pkg)
+	slices.SortFunc(kubeKinds, func(a, b schema.GroupKind) int {
+		return strings.Compare(a.Kind, b.Kind)
+	})
+
+	modules := make(map[*loader.Package]*module)
+	for _, groupKind := range kubeKinds {
+		// the names and scope of the kind are the ones of its CRD
+		parser.NeedCRDFor(groupKind, nil)
+		crdRaw, generated := parser.CustomResourceDefinitions[groupKind]
+		if !generated {
+			continue
+		}
+		for _, root := range ctx.Roots {
+			gv, known := parser.GroupVersions[root]
+			ident := crd.TypeIdent{Package: root, Name: groupKind.Kind}
+			if !known || gv.Group != groupKind.Group || parser.Types[ident] == nil {
+				continue
+			}
+			mod, exists := modules[root]
+			if !exists {
+				mod = newModule(parser, root, gv)
+				modules[root] = mod
+			}
+			mod.kinds[ident] = crdRaw.Spec
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		mod, exists := modules[root]
+		if !exists {
+			continue
+		}
+		kinds := make([]string, 0, len(mod.kinds))
+		for ident := range mod.kinds {
+			kinds = append(kinds, ident.Name)
+		}
+		slices.Sort(kinds)
+		// kinds get their own names first, since kube-rs names them
+		for _, kind := range kinds {
+			mod.nameFor(crd.TypeIdent{Package: root, Name: kind})
+		}
+		for _, kind := range kinds {
+			mod.needKind(crd.TypeIdent{Package: root, Name: kind})
+		}
+
+		moduleName := strings.NewReplacer(".", "_", "-", "_").Replace(mod.gv.Group) + "_" + mod.gv.Version
+		if err := writeOut(ctx, moduleName+".rs", mod.contents(headerText)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOut writes the given contents to the given file.
+func writeOut(ctx *genall.GenerationContext, fileName string, outBytes []byte) error {
+	out, err := ctx.Open(nil, fileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	n, err := out.Write(outBytes)
+	if err != nil {
+		return err
+	}
+	if n < len(outBytes) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// module collects the types of a group-version.
+type module struct {
+	parser *crd.Parser
+	pkg    *loader.Package
+	gv     schema.GroupVersion
+
+	// kinds are the kinds of the group-version, with the spec of their CRD.
+	kinds map[crd.TypeIdent]apiextensionsv1.CustomResourceDefinitionSpec
+	// customResources are the arguments of the kube attribute of the spec
+	// types deriving kube::CustomResource.
+	customResources map[crd.TypeIdent]string
+	// names are the Rust names of the types, and taken the types by name.
+	names map[crd.TypeIdent]string
+	taken map[string]crd.TypeIdent
+	// written marks the types whose definitions are written (or being
+	// written).
+	written map[crd.TypeIdent]struct{}
+	// imports are the names imported from each Rust module.
+	imports map[string]map[string]struct{}
+
+	defs bytes.Buffer
+}
+
+func newModule(parser *crd.Parser, pkg *loader.Package, gv schema.GroupVersion) *module {
+	mod := &module{
+		parser:          parser,
+		pkg:             pkg,
+		gv:              gv,
+		kinds:           make(map[crd.TypeIdent]apiextensionsv1.CustomResourceDefinitionSpec),
+		customResources: make(map[crd.TypeIdent]string),
+		names:           make(map[crd.TypeIdent]string),
+		taken:           make(map[string]crd.TypeIdent),
+		written:         make(map[crd.TypeIdent]struct{}),
+		imports:         make(map[string]map[string]struct{}),
+	}
+	// the names used by the module itself aren't available to types
+	for _, name := range reservedNames {
+		mod.taken[name] = crd.TypeIdent{}
+	}
+	return mod
+}
+
+// use marks the given name of the given Rust module as imported.
+func (m *module) use(rustModule, name string) {
+	if m.imports[rustModule] == nil {
+		m.imports[rustModule] = make(map[string]struct{})
+	}
+	m.imports[rustModule][name] = struct{}{}
+}
+
+// nameFor returns the Rust name of the given type, qualifying it with its
+// package name if another type has the same name.
+func (m *module) nameFor(ident crd.TypeIdent) string {
+	if name, known := m.names[ident]; known {
+		return name
+	}
+	name := ident.Name
+	if _, isTaken := m.taken[name]; isTaken {
+		qualified := strings.ToUpper(ident.Package.Name[:1]) + ident.Package.Name[1:] + ident.Name
+		name = qualified
+		for i := 2; ; i++ {
+			if _, isTaken := m.taken[name]; !isTaken {
+				break
+			}
+			name = fmt.Sprintf("%s%d", qualified, i)
+		}
+	}
+	m.names[ident] = name
+	m.taken[name] = ident
+	return name
+}
+
+// contents returns the contents of the module.
+func (m *module) contents(headerText string) []byte {
+	out := new(bytes.Buffer)
+	if headerText = strings.TrimSpace(headerText); headerText != "" {
+		// Rust has block comments, like Go
+		out.WriteString(headerText)
+		out.WriteString("\n\n")
+	}
+	out.WriteString("// Code generated by controller-gen. DO NOT EDIT.\n\n")
+
+	m.use("serde", "Deserialize")
+	m.use("serde", "Serialize")
+	m.use("schemars", "JsonSchema")
+	rustModules := make([]string, 0, len(m.imports))
+	for rustModule := range m.imports {
+		rustModules = append(rustModules, rustModule)
+	}
+	// the standard library first, then the crates
+	isStd := func(rustModule string) bool {
+		return strings.HasPrefix(rustModule, "std::")
+	}
+	slices.SortFunc(rustModules, func(a, b string) int {
+		if isStd(a) != isStd(b) {
+			if isStd(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	for i, rustModule := range rustModules {
+		if i > 0 && isStd(rustModules[i-1]) && !isStd(rustModule) {
+			out.WriteString("\n")
+		}
+		names := make([]string, 0, len(m.imports[rustModule]))
+		for name := range m.imports[rustModule] {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		if len(names) == 1 {
+			fmt.Fprintf(out, "use %s::%s;\n", rustModule, names[0])
+			continue
+		}
+		fmt.Fprintf(out, "use %s::{%s};\n", rustModule, strings.Join(names, ", "))
+	}
+
+	out.Write(m.defs.Bytes())
+	return out.Bytes()
+}
@@ -0,0 +1,526 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rust
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+const (
+	metav1PkgPath = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1Module  = "k8s_openapi::apimachinery::pkg::apis::meta::v1"
+
+	structDerives         = "Clone, Debug, Deserialize, JsonSchema, PartialEq, Serialize"
+	customResourceDerives = "Clone, CustomResource, Debug, Deserialize, JsonSchema, PartialEq, Serialize"
+	enumDerives           = "Clone, Copy, Debug, Deserialize, Eq, JsonSchema, PartialEq, Serialize"
+)
+
+// apimachineryTypes are the k8s-openapi modules of the apimachinery types
+// with an equivalent there, by Go package and name.
+var apimachineryTypes = map[string]map[string]string{
+	metav1PkgPath: {
+		"Condition":      metav1Module,
+		"LabelSelector":  metav1Module,
+		"ListMeta":       metav1Module,
+		"MicroTime":      metav1Module,
+		"ObjectMeta":     metav1Module,
+		"OwnerReference": metav1Module,
+		"Time":           metav1Module,
+	},
+	"k8s.io/apimachinery/pkg/api/resource": {
+		"Quantity": "k8s_openapi::apimachinery::pkg::api::resource",
+	},
+	"k8s.io/apimachinery/pkg/util/intstr": {
+		"IntOrString": "k8s_openapi::apimachinery::pkg::util::intstr",
+	},
+}
+
+// reservedNames are the names the generated modules use themselves.
+var reservedNames = []string{
+	"BTreeMap", "Box", "CustomResource", "Deserialize", "JsonSchema", "Option", "Serialize", "String", "Vec",
+	"Condition", "IntOrString", "LabelSelector", "ListMeta", "MicroTime", "ObjectMeta", "OwnerReference", "Quantity", "Time",
+}
+
+// keywords are the Rust keywords, which can't be used as field names unless
+// written as raw identifiers.
+var keywords = map[string]struct{}{
+	"abstract": {}, "as": {}, "async": {}, "await": {}, "become": {}, "box": {}, "break": {}, "const": {},
+	"continue": {}, "crate": {}, "do": {}, "dyn": {}, "else": {}, "enum": {}, "extern": {}, "false": {},
+	"final": {}, "fn": {}, "for": {}, "gen": {}, "if": {}, "impl": {}, "in": {}, "let": {}, "loop": {},
+	"macro": {}, "match": {}, "mod": {}, "move": {}, "mut": {}, "override": {}, "priv": {}, "pub": {},
+	"ref": {}, "return": {}, "self": {}, "static": {}, "struct": {}, "super": {}, "trait": {}, "true": {},
+	"try": {}, "type": {}, "typeof": {}, "unsafe": {}, "unsized": {}, "use": {}, "virtual": {}, "where": {},
+	"while": {}, "yield": {},
+}
+
+// typeMetaSchema is the schema of the fields of an embedded TypeMeta.
+var typeMetaSchema = apiextensionsv1.JSONSchemaProps{
+	Type: "object",
+	Properties: map[string]apiextensionsv1.JSONSchemaProps{
+		"apiVersion": {Type: "string"},
+		"kind":       {Type: "string"},
+	},
+}
+
+// fieldName returns the snake_case Rust field name of the given JSON field
+// name.
+func fieldName(jsonName string) string {
+	runes := []rune(jsonName)
+	var name strings.Builder
+	for i, char := range runes {
+		switch {
+		case unicode.IsUpper(char):
+			// start a word at "aB", and at "AAb" for the second capital
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				name.WriteRune('_')
+			}
+			name.WriteRune(unicode.ToLower(char))
+		case char < unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char)):
+			name.WriteRune(char)
+		default:
+			name.WriteRune('_')
+		}
+	}
+	field := name.String()
+	if strings.Trim(field, "_") == "" || unicode.IsDigit(rune(field[0])) {
+		field = "field_" + field
+	}
+	return field
+}
+
+// identifier returns the given field name as a Rust identifier, i.e. as a raw
+// identifier for keywords.
+func identifier(field string) string {
+	if _, keyword := keywords[field]; !keyword {
+		return field
+	}
+	switch field {
+	case "crate", "self", "super":
+		// these can't be raw identifiers
+		return field + "_"
+	}
+	return "r#" + field
+}
+
+// uniqueName returns the given name, suffixed as needed to not be one of the
+// given names, and adds it to them.
+func uniqueName(names map[string]struct{}, name string) string {
+	unique := name
+	for i := 2; ; i++ {
+		if _, isTaken := names[unique]; !isTaken {
+			break
+		}
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	names[unique] = struct{}{}
+	return unique
+}
+
+// variantName returns the UpperCamelCase Rust enum variant name of the given
+// enum value.
+func variantName(value string) string {
+	var name strings.Builder
+	wordStart := true
+	for _, char := range value {
+		if char >= unicode.MaxASCII || !unicode.IsLetter(char) && !unicode.IsDigit(char) {
+			wordStart = true
+			continue
+		}
+		if wordStart {
+			char = unicode.ToUpper(char)
+		}
+		name.WriteRune(char)
+		wordStart = false
+	}
+	variant := name.String()
+	if variant == "" || unicode.IsDigit(rune(variant[0])) || variant == "Self" {
+		variant = "Value" + variant
+	}
+	return variant
+}
+
+// rustString returns the Rust literal of the given string.
+func rustString(value string) string {
+	var literal strings.Builder
+	literal.WriteByte('"')
+	for _, char := range value {
+		switch char {
+		case '"', '\\':
+			literal.WriteByte('\\')
+			literal.WriteRune(char)
+		case '\n':
+			literal.WriteString(`\n`)
+		case '\r':
+			literal.WriteString(`\r`)
+		case '\t':
+			literal.WriteString(`\t`)
+		default:
+			if unicode.IsControl(char) {
+				fmt.Fprintf(&literal, `\u{%x}`, char)
+				continue
+			}
+			literal.WriteRune(char)
+		}
+	}
+	literal.WriteByte('"')
+	return literal.String()
+}
+
+// writeDocComment writes the given description as a doc comment, at the given
+// indentation.
+func writeDocComment(out *bytes.Buffer, description, indent string) {
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		if line == "" {
+			fmt.Fprintf(out, "%s///\n", indent)
+			continue
+		}
+		fmt.Fprintf(out, "%s/// %s\n", indent, line)
+	}
+}
+
+// isStruct checks whether the given schema is written as a struct.
+func isStruct(typeSchema *apiextensionsv1.JSONSchemaProps) bool {
+	return typeSchema.Type == "object" && (len(typeSchema.Properties) > 0 || len(typeSchema.AllOf) > 0)
+}
+
+// stringEnum returns the values of the given schema, if it's an enum of
+// strings.
+func stringEnum(typeSchema *apiextensionsv1.JSONSchemaProps) ([]string, bool) {
+	if len(typeSchema.Enum) == 0 {
+		return nil, false
+	}
+	values := make([]string, 0, len(typeSchema.Enum))
+	for _, raw := range typeSchema.Enum {
+		var value string
+		if err := json.Unmarshal(raw.Raw, &value); err != nil {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return values, true
+}
+
+// resolve returns the type referred to by the given reference, from the
+// given package.
+func resolve(pkg *loader.Package, ref string) (crd.TypeIdent, error) {
+	typeName, pkgPath, err := crd.RefParts(ref)
+	if err != nil {
+		return crd.TypeIdent{}, err
+	}
+	if pkgPath == "" {
+		return crd.TypeIdent{Package: pkg, Name: typeName}, nil
+	}
+	return crd.TypeIdent{Package: pkg.Imports()[pkgPath], Name: typeName}, nil
+}
+
+// isMetav1 checks whether the given type is the given type of metav1.
+func isMetav1(ident crd.TypeIdent, name string) bool {
+	return ident.Package != nil && loader.NonVendorPath(ident.Package.PkgPath) == metav1PkgPath && ident.Name == name
+}
+
+// localStruct returns the type the given property refers to, if it's a
+// struct of the module's package.
+func (m *module) localStruct(prop apiextensionsv1.JSONSchemaProps) (crd.TypeIdent, bool) {
+	if prop.Ref == nil {
+		return crd.TypeIdent{}, false
+	}
+	ident, err := resolve(m.pkg, *prop.Ref)
+	if err != nil || ident.Package != m.pkg {
+		return crd.TypeIdent{}, false
+	}
+	m.parser.NeedSchemaFor(ident)
+	refSchema := m.parser.Schemata[ident]
+	return ident, isStruct(&refSchema)
+}
+
+// needKind writes the definitions of the given kind: its spec type deriving
+// kube::CustomResource if it has one, or a plain struct otherwise.
+func (m *module) needKind(ident crd.TypeIdent) {
+	m.parser.NeedSchemaFor(ident)
+	kindSchema := m.parser.Schemata[ident]
+
+	// kube-rs generates the kind from its spec type, with TypeMeta,
+	// ObjectMeta, the spec and the status only
+	specIdent, hasSpec := m.localStruct(kindSchema.Properties["spec"])
+	_, specWritten := m.written[specIdent]
+	canDerive := hasSpec && !specWritten
+	for name, prop := range kindSchema.Properties {
+		switch name {
+		case "spec":
+		case "metadata":
+			if prop.Ref == nil {
+				canDerive = false
+			} else if metaIdent, err := resolve(ident.Package, *prop.Ref); err != nil || !isMetav1(metaIdent, "ObjectMeta") {
+				canDerive = false
+			}
+		case "status":
+			if _, isLocal := m.localStruct(prop); !isLocal {
+				canDerive = false
+			}
+		default:
+			canDerive = false
+		}
+	}
+	for _, embedded := range kindSchema.AllOf {
+		if embedded.Ref == nil {
+			canDerive = false
+			continue
+		}
+		if embeddedIdent, err := resolve(ident.Package, *embedded.Ref); err != nil || !isMetav1(embeddedIdent, "TypeMeta") {
+			canDerive = false
+		}
+	}
+	if !canDerive {
+		m.need(ident)
+		return
+	}
+
+	crdSpec := m.kinds[ident]
+	args := []string{
+		"group = " + rustString(m.gv.Group),
+		"version = " + rustString(m.gv.Version),
+		"kind = " + rustString(ident.Name),
+		"plural = " + rustString(crdSpec.Names.Plural),
+	}
+	if name := m.nameFor(ident); name != ident.Name {
+		args = append(args, "struct = "+rustString(name))
+	}
+	if crdSpec.Scope == apiextensionsv1.NamespaceScoped {
+		args = append(args, "namespaced")
+	}
+	var statusIdent *crd.TypeIdent
+	if ident, hasStatus := m.localStruct(kindSchema.Properties["status"]); hasStatus {
+		statusIdent = &ident
+		args = append(args, "status = "+rustString(m.nameFor(ident)))
+	}
+	for _, shortName := range crdSpec.Names.ShortNames {
+		args = append(args, "shortname = "+rustString(shortName))
+	}
+	for _, category := range crdSpec.Names.Categories {
+		args = append(args, "category = "+rustString(category))
+	}
+	m.customResources[specIdent] = strings.Join(args, ", ")
+	m.use("kube", "CustomResource")
+
+	m.written[ident] = struct{}{}
+	m.need(specIdent)
+	if statusIdent != nil {
+		m.need(*statusIdent)
+	}
+}
+
+// need returns the Rust name of the given type, writing its definition
+// (after the ones it depends on) if not written yet.
+func (m *module) need(ident crd.TypeIdent) string {
+	name := m.nameFor(ident)
+	if _, written := m.written[ident]; written {
+		return name
+	}
+	m.written[ident] = struct{}{}
+
+	m.parser.NeedSchemaFor(ident)
+	typeSchema := m.parser.Schemata[ident]
+	def := new(bytes.Buffer)
+	if values, isEnum := stringEnum(&typeSchema); isEnum {
+		writeDocComment(def, typeSchema.Description, "")
+		fmt.Fprintf(def, "#[derive(%s)]\n", enumDerives)
+		fmt.Fprintf(def, "pub enum %s {\n", name)
+		variants := make(map[string]struct{}, len(values))
+		for _, value := range values {
+			variant := uniqueName(variants, variantName(value))
+			if variant != value {
+				fmt.Fprintf(def, "    #[serde(rename = %s)]\n", rustString(value))
+			}
+			fmt.Fprintf(def, "    %s,\n", variant)
+		}
+		def.WriteString("}\n")
+		m.defs.WriteString("\n")
+		m.defs.Write(def.Bytes())
+		return name
+	}
+	if !isStruct(&typeSchema) {
+		writeDocComment(def, typeSchema.Description, "")
+		fmt.Fprintf(def, "pub type %s = %s;\n", name, m.rustType(ident.Package, &typeSchema))
+		m.defs.WriteString("\n")
+		m.defs.Write(def.Bytes())
+		return name
+	}
+
+	body := new(bytes.Buffer)
+	fields := make(map[string]struct{})
+	for _, embedded := range typeSchema.AllOf {
+		if embedded.Ref == nil {
+			m.writeFields(body, fields, ident.Package, &embedded)
+			continue
+		}
+		embeddedIdent, err := resolve(ident.Package, *embedded.Ref)
+		if err != nil {
+			ident.Package.AddError(err)
+			continue
+		}
+		switch {
+		case embeddedIdent.Package == nil:
+		case isMetav1(embeddedIdent, "TypeMeta"):
+			m.writeFields(body, fields, ident.Package, &typeMetaSchema)
+		default:
+			// serde flattens embedded structs like encoding/json inlines them
+			field := uniqueName(fields, fieldName(embeddedIdent.Name))
+			body.WriteString("    #[serde(flatten)]\n")
+			fmt.Fprintf(body, "    pub %s: %s,\n", identifier(field), m.refType(ident.Package, *embedded.Ref))
+		}
+	}
+	m.writeFields(body, fields, ident.Package, &typeSchema)
+	if typeSchema.XPreserveUnknownFields != nil && *typeSchema.XPreserveUnknownFields {
+		m.use("std::collections", "BTreeMap")
+		field := uniqueName(fields, "extra")
+		body.WriteString("    #[serde(flatten)]\n")
+		fmt.Fprintf(body, "    pub %s: BTreeMap<String, serde_json::Value>,\n", identifier(field))
+	}
+
+	writeDocComment(def, typeSchema.Description, "")
+	if kubeArgs, isCustomResource := m.customResources[ident]; isCustomResource {
+		fmt.Fprintf(def, "#[derive(%s)]\n", customResourceDerives)
+		fmt.Fprintf(def, "#[kube(%s)]\n", kubeArgs)
+	} else {
+		fmt.Fprintf(def, "#[derive(%s)]\n", structDerives)
+	}
+	if body.Len() == 0 {
+		fmt.Fprintf(def, "pub struct %s {}\n", name)
+	} else {
+		fmt.Fprintf(def, "pub struct %s {\n", name)
+		def.Write(body.Bytes())
+		def.WriteString("}\n")
+	}
+	m.defs.WriteString("\n")
+	m.defs.Write(def.Bytes())
+	return name
+}
+
+// writeFields writes the fields of the properties of the given object schema,
+// whose references are relative to the given package, recording their names
+// in the given ones.
+func (m *module) writeFields(out *bytes.Buffer, fields map[string]struct{}, pkg *loader.Package, objSchema *apiextensionsv1.JSONSchemaProps) {
+	names := make([]string, 0, len(objSchema.Properties))
+	for name := range objSchema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop := objSchema.Properties[name]
+		required := slices.Contains(objSchema.Required, name)
+		field := uniqueName(fields, fieldName(name))
+
+		rustType := m.rustType(pkg, &prop)
+		var serdeArgs []string
+		if field != name {
+			serdeArgs = append(serdeArgs, "rename = "+rustString(name))
+		}
+		if prop.Nullable || !required {
+			rustType = "Option<" + rustType + ">"
+		}
+		if !required {
+			serdeArgs = append(serdeArgs, "default", `skip_serializing_if = "Option::is_none"`)
+		}
+
+		writeDocComment(out, prop.Description, "    ")
+		if len(serdeArgs) > 0 {
+			fmt.Fprintf(out, "    #[serde(%s)]\n", strings.Join(serdeArgs, ", "))
+		}
+		fmt.Fprintf(out, "    pub %s: %s,\n", identifier(field), rustType)
+	}
+}
+
+// rustType returns the Rust type of values of the given schema, whose
+// references are relative to the given package.
+func (m *module) rustType(pkg *loader.Package, valueSchema *apiextensionsv1.JSONSchemaProps) string {
+	switch {
+	case valueSchema.Ref != nil:
+		return m.refType(pkg, *valueSchema.Ref)
+	case valueSchema.XIntOrString:
+		m.use("k8s_openapi::apimachinery::pkg::util::intstr", "IntOrString")
+		return "IntOrString"
+	case valueSchema.Type == "string" && valueSchema.Format == "date-time":
+		m.use(metav1Module, "Time")
+		return "Time"
+	case valueSchema.Type == "string":
+		return "String"
+	case valueSchema.Type == "integer" && valueSchema.Format == "int32":
+		return "i32"
+	case valueSchema.Type == "integer":
+		return "i64"
+	case valueSchema.Type == "number":
+		return "f64"
+	case valueSchema.Type == "boolean":
+		return "bool"
+	case valueSchema.Type == "array":
+		if valueSchema.Items == nil || valueSchema.Items.Schema == nil {
+			return "Vec<serde_json::Value>"
+		}
+		return "Vec<" + m.rustType(pkg, valueSchema.Items.Schema) + ">"
+	case valueSchema.Type == "object" && valueSchema.AdditionalProperties != nil && valueSchema.AdditionalProperties.Schema != nil:
+		m.use("std::collections", "BTreeMap")
+		return "BTreeMap<String, " + m.rustType(pkg, valueSchema.AdditionalProperties.Schema) + ">"
+	case valueSchema.Type == "object":
+		m.use("std::collections", "BTreeMap")
+		return "BTreeMap<String, serde_json::Value>"
+	}
+	return "serde_json::Value"
+}
+
+// refType returns the Rust type of the type referred to by the given
+// reference, from the given package.
+func (m *module) refType(pkg *loader.Package, ref string) string {
+	ident, err := resolve(pkg, ref)
+	if err != nil {
+		pkg.AddError(err)
+		return "serde_json::Value"
+	}
+	if ident.Package == nil {
+		return "serde_json::Value"
+	}
+	pkgPath := loader.NonVendorPath(ident.Package.PkgPath)
+	if rustModule, known := apimachineryTypes[pkgPath][ident.Name]; known {
+		m.use(rustModule, ident.Name)
+		return ident.Name
+	}
+	if group, isBuiltin := strings.CutPrefix(pkgPath, "k8s.io/api/"); isBuiltin && path.Base(group) != group {
+		// k8s-openapi has the types of the built-in APIs, by group-version
+		return "k8s_openapi::api::" + strings.ReplaceAll(group, "/", "::") + "::" + ident.Name
+	}
+	m.parser.NeedSchemaFor(ident)
+	refSchema := m.parser.Schemata[ident]
+	if ident.Package != m.pkg && !isStruct(&refSchema) {
+		// inline the simple types of other packages
+		return m.rustType(ident.Package, &refSchema)
+	}
+	return m.need(ident)
+}
@@ -0,0 +1,23 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package samples generates sample custom resources for the kinds of API
+// packages, as shipped in config/samples.
+//
+// Samples are built from the CRD schemata of the kinds: fields are populated
+// from their example and default markers, and required fields without either
+// get placeholders, so that the samples stay current with the API.
+package samples
@@ -0,0 +1,211 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package samples
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates a sample custom resource for each version of each kind.
+//
+// Samples are named <group>_<version>_<kind>.yaml, after the first label of
+// the group, as scaffolded by kubebuilder.  Fields are populated from their
+// example markers, then their default markers, and required fields without
+// either get placeholder values (e.g. the first value of an enum, or the
+// minimum of a number).  The status of kinds is omitted.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// samples shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(groupKind, nil)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, version := range crdRaw.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			sample := sampleObject(schema.GroupVersionKind{Group: groupKind.Group, Version: version.Name, Kind: groupKind.Kind}, version.Schema.OpenAPIV3Schema)
+			if err := ctx.WriteYAML(sampleFileName(groupKind.Group, version.Name, groupKind.Kind), headerText, []any{sample}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sampleFileName returns the name of the sample of the given kind.
+func sampleFileName(group, version, kind string) string {
+	groupPrefix, _, _ := strings.Cut(group, ".")
+	return fmt.Sprintf("%s_%s_%s.yaml", groupPrefix, version, strings.ToLower(kind))
+}
+
+// sampleObject returns the sample object of the given kind, with the given
+// schema.
+func sampleObject(gvk schema.GroupVersionKind, objSchema *apiextensionsv1.JSONSchemaProps) map[string]any {
+	obj := map[string]any{
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind,
+		"metadata": map[string]any{
+			"name": strings.ToLower(gvk.Kind) + "-sample",
+		},
+	}
+	for name, prop := range objSchema.Properties {
+		switch name {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+		// the top-level fields are always sampled, as with kubebuilder
+		if value := sampleValue(name, &prop, true); value != nil {
+			obj[name] = value
+		}
+	}
+	return obj
+}
+
+// sampleValue returns the sample value of the given field, with the given
+// schema, or nil if it shouldn't be part of the sample.
+//
+// Values come from the example, then the default of the field.  Objects are
+// sampled if any of their fields are, and required values get placeholders.
+func sampleValue(name string, fieldSchema *apiextensionsv1.JSONSchemaProps, required bool) any {
+	if fieldSchema.Example != nil {
+		return rawValue(fieldSchema.Example)
+	}
+	if fieldSchema.Default != nil {
+		return rawValue(fieldSchema.Default)
+	}
+
+	switch fieldSchema.Type {
+	case "object":
+		obj := make(map[string]any)
+		for propName, prop := range fieldSchema.Properties {
+			if value := sampleValue(propName, &prop, slices.Contains(fieldSchema.Required, propName)); value != nil {
+				obj[propName] = value
+			}
+		}
+		if len(obj) > 0 || required {
+			return obj
+		}
+	case "array":
+		if !required {
+			return nil
+		}
+		if fieldSchema.Items != nil && fieldSchema.Items.Schema != nil {
+			if item := sampleValue(name, fieldSchema.Items.Schema, true); item != nil {
+				return []any{item}
+			}
+		}
+		return []any{}
+	default:
+		if required {
+			return placeholder(name, fieldSchema)
+		}
+	}
+	return nil
+}
+
+// placeholder returns the placeholder value of the given required field, with
+// the given schema.
+func placeholder(name string, fieldSchema *apiextensionsv1.JSONSchemaProps) any {
+	if len(fieldSchema.Enum) > 0 {
+		return rawValue(&fieldSchema.Enum[0])
+	}
+	switch fieldSchema.Type {
+	case "boolean":
+		return false
+	case "integer", "number":
+		if fieldSchema.Minimum == nil {
+			return 0
+		}
+		minimum := *fieldSchema.Minimum
+		if fieldSchema.ExclusiveMinimum {
+			minimum++
+		}
+		return minimum
+	}
+	// strings, and int-or-strings
+	return "<" + name + ">"
+}
+
+// rawValue decodes the given JSON value.
+func rawValue(raw *apiextensionsv1.JSON) any {
+	var value any
+	if err := json.Unmarshal(raw.Raw, &value); err != nil {
+		// markers are validated when parsed, so this shouldn't happen
+		return string(raw.Raw)
+	}
+	return value
+}
@@ -0,0 +1,85 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Port is a network port.
+type Port struct {
+	// +kubebuilder:example=http
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Minimum=1
+	Number int32 `json:"number"`
+
+	// +kubebuilder:validation:Enum=TCP;UDP
+	Protocol string `json:"protocol"`
+
+	// +optional
+	AppProtocol string `json:"appProtocol,omitempty"`
+}
+
+// Resources are the resources of a widget.
+type Resources struct {
+	// +kubebuilder:default="100m"
+	// +optional
+	CPU string `json:"cpu,omitempty"`
+
+	// +optional
+	Memory string `json:"memory,omitempty"`
+}
+
+// WidgetSpec is the spec of a Widget.
+type WidgetSpec struct {
+	// Image is the image of the widget.
+	Image string `json:"image"`
+
+	// +kubebuilder:default=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// +kubebuilder:example={"tier": "frontend"}
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Ports []Port `json:"ports"`
+
+	// +optional
+	Resources Resources `json:"resources,omitempty"`
+
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	Suspended bool `json:"suspended"`
+}
+
+// WidgetStatus is the status of a Widget.
+type WidgetStatus struct {
+	Ready bool `json:"ready"`
+}
+
+// Widget is a kind with a sample.
+// +kubebuilder:object:root=true
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
@@ -0,0 +1,128 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemapatcher
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+	yamlop "sigs.k8s.io/controller-tools/pkg/schemapatcher/internal/yaml"
+)
+
+// manifestChange is a change of an entry of a CRD in a manifest file.
+type manifestChange struct {
+	// Entry describes the changed entry, e.g.
+	// "spec.versions[v1].schema.openAPIV3Schema" of a CRD.
+	Entry string
+	// Old and New are the YAML texts of the entry before and after the
+	// change, empty if it didn't exist or was deleted.
+	Old, New string
+}
+
+// recordChange records the change of the entry at the given path under the
+// given node of the CRD, from the given node tree (if any) to the other.
+func (e *partialCRD) recordChange(root *yaml.Node, oldNode, newNode *yaml.Node, path ...string) error {
+	change := manifestChange{Entry: e.describe(root, path...)}
+	var err error
+	if change.Old, err = nodeText(oldNode); err != nil {
+		return err
+	}
+	if change.New, err = nodeText(newNode); err != nil {
+		return err
+	}
+	change.Old, change.New = e.File.Template.restore(change.Old), e.File.Template.restore(change.New)
+	e.File.Changes = append(e.File.Changes, change)
+	return nil
+}
+
+// describe returns the name of the CRD and the path of the entry at the given
+// path under the given node of it, naming the versions, e.g.
+// "examples.example.com: spec.versions[v1].subresources".
+func (e *partialCRD) describe(root *yaml.Node, path ...string) string {
+	entry := strings.Join(path, ".")
+	if root != e.Yaml {
+		// the node of a version
+		if nameNode, found, _ := yamlop.GetNode(root, "name"); found {
+			entry = fmt.Sprintf("spec.versions[%s].%s", nameNode.Value, entry)
+		}
+	}
+	if nameNode, found, _ := yamlop.GetNode(e.Yaml, "metadata", "name"); found && nameNode.Value != "" {
+		return nameNode.Value + ": " + entry
+	}
+	return entry
+}
+
+// nodeText returns the given node tree encoded as YAML, or "" for nil.
+func nodeText(node *yaml.Node) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// driftReport returns the report of the changes of the given manifest files
+// (the entries changed in each file, followed by their diffs), or "" if none
+// were changed.
+func driftReport(files []*manifestFile) (string, error) {
+	var entries, diffs strings.Builder
+	for _, file := range files {
+		if len(file.Changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&entries, "  %s\n", file.Name)
+		for _, change := range file.Changes {
+			fmt.Fprintf(&entries, "    %s\n", change.Entry)
+
+			label := fmt.Sprintf("%s (%s)", file.Name, change.Entry)
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        splitLines(change.Old),
+				B:        splitLines(change.New),
+				FromFile: label,
+				ToFile:   label + " (generated)",
+				Context:  3,
+			})
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&diffs, "\n%s", diff)
+		}
+	}
+	if entries.Len() == 0 {
+		return "", nil
+	}
+	return "the following CRD manifests are out of date:\n" + entries.String() + diffs.String(), nil
+}
+
+// splitLines splits the given text into lines, with none if it's empty.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return difflib.SplitLines(text)
+}
@@ -17,9 +17,16 @@ limitations under the License.
 package schemapatcher
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"maps"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -61,8 +68,20 @@ var (
 //
 // It will generate output for each "CRD Version" (API version of the CRD type
 // itself) , e.g. apiextensions/v1) available.
+//
+// Only the schemata are replaced in the text of the manifests (along with the
+// conversion, metadata and subresources, if asked for), leaving the rest of
+// the files (comments, formatting, anchors, other documents) as written.
 type Generator struct {
 	// ManifestsPath contains the CustomResourceDefinition YAML files.
+	//
+	// JSON files are patched as well, and written out as JSON, indented as
+	// they were.  So are templates (e.g. of Helm charts), whose actions are
+	// left as they are, as long as they don't span several lines.
+	//
+	// If it holds a kustomization, the files it refers to (resources, bases,
+	// CRDs and patches, following the kustomizations of the directories it
+	// refers to) are patched instead, wherever they are.
 	ManifestsPath string `marker:"manifests"`
 
 	// MaxDescLen specifies the maximum description length for fields in CRD's OpenAPI schema.
@@ -72,8 +91,50 @@ type Generator struct {
 	// closest sentence boundary if it exceeds n characters.
 	MaxDescLen *int `marker:",optional"`
 
+	// Versions are the versions whose schemata are updated, e.g. to leave the
+	// schemata of older versions as they are, when they're frozen.
+	//
+	// All the versions are updated if empty.
+	Versions []string `marker:",optional"`
+
+	// Protected are the paths of the parts of the schemata that are manually
+	// maintained (e.g. CEL rules or descriptions that can't be generated),
+	// which are kept as they are while the rest is updated.
+	//
+	// A path is a dotted list of fields (with "[]" for the items of lists),
+	// protecting their whole schema, optionally followed by a slash and a
+	// keyword of their schema, protecting that keyword only, and optionally
+	// preceded by a kind and a colon, applying to that kind only, e.g.
+	// "spec.rules[]" or "Example:spec/x-kubernetes-validations".
+	//
+	// The entries of the schemata marked with a "+schemapatch:protected"
+	// comment in the manifests are protected as well.
+	Protected []string `marker:",optional"`
+
 	// GenerateEmbeddedObjectMeta specifies if any embedded ObjectMeta in the CRD should be generated
 	GenerateEmbeddedObjectMeta *bool `marker:",optional"`
+
+	// Conversion specifies if the conversion of the CRDs should be replaced
+	// with the generated one (removing it if none is generated).
+	Conversion *bool `marker:",optional"`
+
+	// Annotations specifies if the generated annotations of the CRDs should be
+	// added (or updated), keeping the others.
+	Annotations *bool `marker:",optional"`
+
+	// Labels specifies if the generated labels of the CRDs should be added (or
+	// updated), keeping the others.
+	Labels *bool `marker:",optional"`
+
+	// Subresources specifies if the subresources of the updated versions
+	// should be replaced with the generated ones (removing them if none are
+	// generated).
+	Subresources *bool `marker:",optional"`
+
+	// Verify reports the manifests that are out of date, along with the diffs
+	// of their changed entries (e.g. the schema of each version), instead of
+	// writing them, failing if there are any (e.g. for CI checks).
+	Verify *bool `marker:",optional"`
 }
 
 var _ genall.Generator = &Generator{}
@@ -82,6 +143,12 @@ func (Generator) CheckFilter() loader.NodeFilter {
 	return crdgen.Generator{}.CheckFilter()
 }
 
+// ExclusiveRun is true, since the manifests patched in place may be written by
+// the generators listed before this one (e.g. crd).
+func (Generator) ExclusiveRun() bool {
+	return true
+}
+
 func (Generator) RegisterMarkers(into *markers.Registry) error {
 	return crdmarkers.Register(into)
 }
@@ -90,6 +157,8 @@ func (g Generator) Generate(ctx *genall.GenerationContext) (result error) {
 	parser := &crdgen.Parser{
 		Collector: ctx.Collector,
 		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
 		// Indicates the parser on whether to register the ObjectMeta type or not
 		GenerateEmbeddedObjectMeta: g.GenerateEmbeddedObjectMeta != nil && *g.GenerateEmbeddedObjectMeta,
 	}
@@ -118,11 +187,17 @@ func (g Generator) Generate(ctx *genall.GenerationContext) (result error) {
 			continue
 		}
 
+		if g.patchesFields() {
+			parser.NeedCRDFor(groupKind, g.MaxDescLen)
+			crd := parser.CustomResourceDefinitions[groupKind]
+			existingSet.Generated = &crd
+		}
+
 		for pkg, gv := range parser.GroupVersions {
 			if gv.Group != groupKind.Group {
 				continue
 			}
-			if _, wantedVersion := existingSet.Versions[gv.Version]; !wantedVersion {
+			if _, wantedVersion := existingSet.Versions[gv.Version]; !wantedVersion || !g.updatesVersion(gv.Version) {
 				continue
 			}
 
@@ -144,8 +219,29 @@ func (g Generator) Generate(ctx *genall.GenerationContext) (result error) {
 		}
 	}
 
+	// go through the CRDs in order, so that the changes of the files (and
+	// their reports) don't depend on the iteration order of the sets
+	sortedSets := make([]*partialCRDSet, 0, len(partialCRDSets))
+	for _, groupKind := range slices.SortedFunc(maps.Keys(partialCRDSets), func(a, b schema.GroupKind) int {
+		return strings.Compare(a.String(), b.String())
+	}) {
+		sortedSets = append(sortedSets, partialCRDSets[groupKind])
+	}
+
+	// patch the other fields of existing CRDs, if asked for
+	for _, existingSet := range sortedSets {
+		if existingSet.Generated == nil {
+			continue
+		}
+		for _, crdInfo := range existingSet.CRDVersions {
+			if err := g.setFields(crdInfo, existingSet.Generated, ctx.GeneratorVersion()); err != nil {
+				return fmt.Errorf("failed to set fields for %s: %w", existingSet.GroupKind, err)
+			}
+		}
+	}
+
 	// patch existing CRDs with new schemata
-	for _, existingSet := range partialCRDSets {
+	for _, existingSet := range sortedSets {
 		// first, figure out if we need to merge schemata together if they're *all*
 		// identical (meaning we also don't have any "unset" versions)
 
@@ -153,6 +249,19 @@ func (g Generator) Generate(ctx *genall.GenerationContext) (result error) {
 			continue
 		}
 
+		existingSet.Protected, err = parseProtectedPaths(g.Protected, existingSet.GroupKind.Kind)
+		if err != nil {
+			return err
+		}
+
+		// leave the versions we don't update alone
+		existingSet.Frozen = make(map[string]struct{})
+		for ver := range existingSet.Versions {
+			if !g.updatesVersion(ver) {
+				existingSet.Frozen[ver] = struct{}{}
+			}
+		}
+
 		// copy over the new versions that we have, keeping old versions so
 		// that we can tell if a schema would be nil
 		var someVer string
@@ -182,32 +291,245 @@ func (g Generator) Generate(ctx *genall.GenerationContext) (result error) {
 		}
 	}
 
-	// write the final result out to the new location
-	for _, set := range partialCRDSets {
-		// We assume all CRD versions came from different files, since this
-		// is how controller-gen works.  If they came from the same file,
-		// it'd be non-sensical, since you couldn't reasonably use kubectl
-		// with them against older servers.
+	// write the final result out to the new location, file by file, since
+	// several CRDs may come from the same file
+	files := make(map[string]*manifestFile)
+	for _, set := range sortedSets {
 		for _, crd := range set.CRDVersions {
-			if err := func() error {
-				outWriter, err := ctx.OutputRule.Open(nil, crd.FileName)
-				if err != nil {
-					return err
-				}
-				defer outWriter.Close()
+			files[crd.File.Name] = crd.File
+		}
+	}
+	sortedFiles := make([]*manifestFile, 0, len(files))
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		sortedFiles = append(sortedFiles, files[name])
+	}
+
+	if isTrue(g.Verify) {
+		report, err := driftReport(sortedFiles)
+		if err != nil {
+			return err
+		}
+		if report != "" {
+			return errors.New(report)
+		}
+		return nil
+	}
 
-				enc := yaml.NewEncoder(outWriter)
-				// yaml.v2 defaults to indent=2, yaml.v3 defaults to indent=4,
-				// so be compatible with everything else in k8s and choose 2.
-				enc.SetIndent(2)
+	for _, file := range sortedFiles {
+		if err := file.write(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// manifestFile is a file of CRD manifests (along with any other document),
+// patched in place.
+type manifestFile struct {
+	// Name is the path of the file, relative to the manifests directory.
+	Name string
+	// Documents are the YAML structures of the documents of the file.
+	Documents []*yaml.Node
+	// Source is the text of the file, in which the patched nodes are
+	// replaced, leaving the rest of the text (comments, formatting,
+	// anchors...) untouched.
+	Source *yamlop.Source
+	// Reencode is set if a patched node couldn't be replaced in the text,
+	// in which case all the documents of the file are encoded again.
+	//
+	// It's always the case for JSON files once patched, since their nodes
+	// are in flow style.
+	Reencode bool
+	// JSON is the format of the file if it's JSON rather than YAML, in
+	// which case it's encoded again as JSON.
+	JSON *jsonFormat
+	// Template are the template actions of the file, if it's a template
+	// (e.g. of a Helm chart), masked in its text and restored when writing
+	// it, which requires patching it in place.
+	Template *templateActions
+	// Changes are the changes of the entries of the CRDs of the file.
+	Changes []manifestChange
+}
 
-				return enc.Encode(crd.Yaml)
-			}(); err != nil {
-				return err
+// jsonFormat is the format of a JSON manifest file, kept when encoding it
+// again.
+type jsonFormat struct {
+	// Indent is the indentation of each level, or "" if the file is compact.
+	Indent string
+	// FinalNewline is set if the file ends with a line break.
+	FinalNewline bool
+}
+
+// detectJSONFormat returns the format of the given manifest file if it's
+// JSON (i.e. starts with an object or an array), or nil if it's YAML.
+func detectJSONFormat(rawContent []byte) *jsonFormat {
+	content := bytes.TrimSpace(rawContent)
+	if len(content) == 0 || content[0] != '{' && content[0] != '[' {
+		return nil
+	}
+	format := &jsonFormat{FinalNewline: bytes.HasSuffix(rawContent, []byte("\n"))}
+	// the indentation of the first nested line, if any
+	if _, rest, found := bytes.Cut(content, []byte("\n")); found {
+		format.Indent = string(rest[:len(rest)-len(bytes.TrimLeft(rest, " \t"))])
+	}
+	return format
+}
+
+// splice applies the given edit of the text of the file, falling back to
+// encoding the documents of the file again if it can't be made.
+func (f *manifestFile) splice(edit func(*yamlop.Source) error) {
+	if f.Reencode {
+		return
+	}
+	if err := edit(f.Source); err != nil {
+		// whatever was wrong with it, the nodes themselves are patched
+		// anyway, so encode them instead
+		f.Reencode = true
+	}
+}
+
+// write writes the patched file out.
+func (f *manifestFile) write(ctx *genall.GenerationContext) error {
+	if f.Template != nil && f.Reencode {
+		// encoding it again would lose the template actions
+		return fmt.Errorf("%s: unable to patch the template in place", f.Name)
+	}
+
+	outWriter, err := ctx.OutputRule.Open(nil, f.Name)
+	if err != nil {
+		return err
+	}
+	defer outWriter.Close()
+
+	if f.Template != nil {
+		_, err := io.WriteString(outWriter, f.Template.restore(string(f.Source.Bytes())))
+		return err
+	}
+	if !f.Reencode {
+		_, err := outWriter.Write(f.Source.Bytes())
+		return err
+	}
+
+	if f.JSON != nil {
+		return f.writeJSON(outWriter)
+	}
+
+	enc := yaml.NewEncoder(outWriter)
+	// yaml.v2 defaults to indent=2, yaml.v3 defaults to indent=4,
+	// so be compatible with everything else in k8s and choose 2.
+	enc.SetIndent(2)
+	for _, doc := range f.Documents {
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// writeJSON writes the documents of the file out as JSON, in the format of
+// the file.
+func (f *manifestFile) writeJSON(out io.Writer) error {
+	var buf bytes.Buffer
+	for i, doc := range f.Documents {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		rawJSON, err := yamlop.ToJSON(doc)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if f.JSON.Indent == "" {
+			buf.Write(rawJSON)
+		} else if err := json.Indent(&buf, rawJSON, "", f.JSON.Indent); err != nil {
+			return err
+		}
+	}
+	if f.JSON.FinalNewline {
+		buf.WriteByte('\n')
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// updatesVersion checks whether the schemata of the given version are
+// updated.
+func (g Generator) updatesVersion(version string) bool {
+	return len(g.Versions) == 0 || slices.Contains(g.Versions, version)
+}
+
+// patchesFields checks whether any field besides the schemata is patched.
+func (g Generator) patchesFields() bool {
+	return isTrue(g.Conversion) || isTrue(g.Annotations) || isTrue(g.Labels) || isTrue(g.Subresources)
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+// setFields patches the fields of the given CRD besides the schemata (as
+// asked for) with the ones of the generated CRD, attributed to the given
+// version of controller-gen.
+func (g Generator) setFields(crd *partialCRD, generated *apiextensionsv1.CustomResourceDefinition, generatorVersion string) error {
+	if isTrue(g.Conversion) {
+		if generated.Spec.Conversion == nil {
+			if err := crd.deleteNode(crd.Yaml, "spec", "conversion"); err != nil {
+				return fmt.Errorf("spec.conversion: %w", err)
 			}
+		} else if err := crd.setNode(crd.Yaml, generated.Spec.Conversion, "spec", "conversion"); err != nil {
+			return fmt.Errorf("spec.conversion: %w", err)
 		}
 	}
 
+	if isTrue(g.Annotations) {
+		annotations := maps.Clone(generated.Annotations)
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		// as added by the crd generator
+		annotations["controller-gen.kubebuilder.io/version"] = generatorVersion
+		for _, key := range slices.Sorted(maps.Keys(annotations)) {
+			if err := crd.setNode(crd.Yaml, annotations[key], "metadata", "annotations", key); err != nil {
+				return fmt.Errorf("metadata.annotations[%q]: %w", key, err)
+			}
+		}
+	}
+
+	if isTrue(g.Labels) {
+		for _, key := range slices.Sorted(maps.Keys(generated.Labels)) {
+			if err := crd.setNode(crd.Yaml, generated.Labels[key], "metadata", "labels", key); err != nil {
+				return fmt.Errorf("metadata.labels[%q]: %w", key, err)
+			}
+		}
+	}
+
+	if isTrue(g.Subresources) {
+		versions, found, err := crd.getVersionsNode()
+		if err != nil || !found {
+			return err
+		}
+		for i, verNode := range versions.Content {
+			nameNode, _, _ := yamlop.GetNode(verNode, "name")
+			if nameNode == nil || !g.updatesVersion(nameNode.Value) {
+				continue
+			}
+			idx := slices.IndexFunc(generated.Spec.Versions, func(ver apiextensionsv1.CustomResourceDefinitionVersion) bool {
+				return ver.Name == nameNode.Value
+			})
+			if idx < 0 {
+				// no types for it, leave it as is
+				continue
+			}
+			if subresources := generated.Spec.Versions[idx].Subresources; subresources == nil {
+				err = crd.deleteNode(verNode, "subresources")
+			} else {
+				err = crd.setNode(verNode, subresources, "subresources")
+			}
+			if err != nil {
+				return fmt.Errorf("spec.versions[%d].subresources: %w", i, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -227,6 +549,14 @@ type partialCRDSet struct {
 	CRDVersions []*partialCRD
 	// Versions are the versions of the given GroupKind in this set of CRDs.
 	Versions map[string]struct{}
+	// Frozen are the versions whose schemata are left untouched.
+	Frozen map[string]struct{}
+	// Protected are the paths of the entries of the schemata that are kept as
+	// they are, as they're manually maintained.
+	Protected []protectedPath
+	// Generated is the CRD generated from Go IDL, if any field besides the
+	// schemata is patched.
+	Generated *apiextensionsv1.CustomResourceDefinition
 }
 
 // partialCRD represents the raw YAML encoding of a given CRD instance, plus
@@ -234,12 +564,12 @@ type partialCRDSet struct {
 type partialCRD struct {
 	// Yaml is the raw YAML structure of the CRD.
 	Yaml *yaml.Node
-	// FileName is the source name of the file that this was read from.
+	// File is the file that this was read from.
 	//
 	// This isn't on partialCRDSet because we could have different CRD versions
 	// stored in the same file (like controller-tools does by default) or in
 	// different files.
-	FileName string
+	File *manifestFile
 
 	// CRDVersion is the version of the CRD object itself, from
 	// apiextensions (currently apiextensions/v1 or apiextensions/v1beta1).
@@ -249,7 +579,7 @@ type partialCRD struct {
 // setGlobalSchema sets the versioned schemas (as per setVersionedSchemata).
 func (e *partialCRDSet) setGlobalSchema() error {
 	for _, crdInfo := range e.CRDVersions {
-		if err := crdInfo.setVersionedSchemata(e.NewSchemata); err != nil {
+		if err := crdInfo.setVersionedSchemata(e.NewSchemata, e.Frozen, e.Protected); err != nil {
 			return err
 		}
 	}
@@ -276,7 +606,7 @@ func (e *partialCRD) getVersionsNode() (*yaml.Node, bool, error) {
 // setVersionedSchemata on partialCRD.
 func (e *partialCRDSet) setVersionedSchemata() error {
 	for _, crdInfo := range e.CRDVersions {
-		if err := crdInfo.setVersionedSchemata(e.NewSchemata); err != nil {
+		if err := crdInfo.setVersionedSchemata(e.NewSchemata, e.Frozen, e.Protected); err != nil {
 			return err
 		}
 	}
@@ -284,12 +614,17 @@ func (e *partialCRDSet) setVersionedSchemata() error {
 }
 
 // setVersionedSchemata populates all existing versions with new schemata,
-// wiping the schema of any version that doesn't have a listed schema.
-// Any "unknown" versions are ignored.
-func (e *partialCRD) setVersionedSchemata(newSchemata map[string]apiextensionsv1.JSONSchemaProps) error {
+// wiping the schema of any version that doesn't have a listed schema, apart
+// from the frozen ones, which are left untouched.  Any "unknown" versions are
+// ignored.  The protected entries of the schemata are kept as they are (see
+// setSchema).
+func (e *partialCRD) setVersionedSchemata(newSchemata map[string]apiextensionsv1.JSONSchemaProps, frozen map[string]struct{}, protected []protectedPath) error {
 	var err error
-	if err := yamlop.DeleteNode(e.Yaml, "spec", "validation"); err != nil {
-		return err
+	if len(frozen) == 0 {
+		// the legacy top-level schema would apply to the frozen versions
+		if err := e.deleteNode(e.Yaml, "spec", "validation"); err != nil {
+			return err
+		}
 	}
 
 	versions, found, err := e.getVersionsNode()
@@ -309,102 +644,246 @@ func (e *partialCRD) setVersionedSchemata(newSchemata map[string]apiextensionsv1
 		if name == "" {
 			return fmt.Errorf("unexpected empty name at spec.versions[%d]", i)
 		}
+		if _, isFrozen := frozen[name]; isFrozen {
+			continue
+		}
 		newSchema, found := newSchemata[name]
 		if !found {
-			if err := yamlop.DeleteNode(verNode, "schema"); err != nil {
-				return fmt.Errorf("spec.versions[%d]: %w", i, err)
-			}
-		} else {
-			schemaNodeTree, err := yamlop.ToYAML(newSchema)
-			if err != nil {
-				return fmt.Errorf("failed to convert schema to YAML: %w", err)
-			}
-			schemaNodeTree = schemaNodeTree.Content[0] // get rid of the document node
-			yamlop.SetStyle(schemaNodeTree, 0)         // clear the style so it defaults to an auto-chosen one
-			if err := yamlop.SetNode(verNode, *schemaNodeTree, "schema", "openAPIV3Schema"); err != nil {
+			if err := e.deleteNode(verNode, "schema"); err != nil {
 				return fmt.Errorf("spec.versions[%d]: %w", i, err)
 			}
+		} else if err := e.setSchema(verNode, newSchema, protected); err != nil {
+			return fmt.Errorf("spec.versions[%d]: %w", i, err)
 		}
 	}
 	return nil
 }
 
+// setSchema sets the schema of the given version of the CRD to the given
+// schema, apart from its protected entries (the ones at the given paths, or
+// marked in the existing schema), which are kept as they are.
+func (e *partialCRD) setSchema(verNode *yaml.Node, newSchema apiextensionsv1.JSONSchemaProps, protected []protectedPath) error {
+	nodeTree, err := valueNode(newSchema)
+	if err != nil {
+		return err
+	}
+	existingSchema, found, err := yamlop.GetNode(verNode, "schema", "openAPIV3Schema")
+	if err != nil || !found {
+		return e.setValueNode(verNode, newSchema, nodeTree, "schema", "openAPIV3Schema")
+	}
+	paths := append(slices.Clip(protected), markedPaths(existingSchema)...)
+	if len(paths) == 0 {
+		return e.setValueNode(verNode, newSchema, nodeTree, "schema", "openAPIV3Schema")
+	}
+
+	mergeProtected(existingSchema, nodeTree, paths)
+	var mergedSchema apiextensionsv1.JSONSchemaProps
+	if err := decodeNode(nodeTree, &mergedSchema); err != nil {
+		return fmt.Errorf("invalid protected schema entries: %w", err)
+	}
+	return e.setValueNode(verNode, mergedSchema, nodeTree, "schema", "openAPIV3Schema")
+}
+
+// setNode sets the value at the given path under the given node of the CRD
+// to the given value, both in its YAML structure and in the text of its file,
+// unless it already has this value.
+func (e *partialCRD) setNode(root *yaml.Node, val any, path ...string) error {
+	nodeTree, err := valueNode(val)
+	if err != nil {
+		return err
+	}
+	return e.setValueNode(root, val, nodeTree, path...)
+}
+
+// setValueNode sets the value at the given path under the given node of the
+// CRD to the given node tree, encoding the given value, unless it already has
+// this value.
+func (e *partialCRD) setValueNode(root *yaml.Node, val any, nodeTree *yaml.Node, path ...string) error {
+	unchanged, err := hasValue(root, val, path...)
+	if err != nil {
+		return err
+	}
+	if unchanged {
+		// leave it as written
+		return nil
+	}
+	existing, _, _ := yamlop.GetNode(root, path...)
+	if err := e.recordChange(root, existing, nodeTree, path...); err != nil {
+		return err
+	}
+	e.File.splice(func(src *yamlop.Source) error {
+		return src.Set(root, nodeTree, path...)
+	})
+	return yamlop.SetNode(root, *nodeTree, path...)
+}
+
+// valueNode converts the given value into a YAML node tree.
+func valueNode(val any) (*yaml.Node, error) {
+	nodeTree, err := yamlop.ToYAML(val)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert value to YAML: %w", err)
+	}
+	nodeTree = nodeTree.Content[0] // get rid of the document node
+	yamlop.SetStyle(nodeTree, 0)   // clear the style so it defaults to an auto-chosen one
+	return nodeTree, nil
+}
+
+// deleteNode deletes the entry at the given path under the given node of the
+// CRD, both in its YAML structure and in the text of its file.
+func (e *partialCRD) deleteNode(root *yaml.Node, path ...string) error {
+	existing, found, err := yamlop.GetNode(root, path...)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// no-op
+		return nil
+	}
+	if err := e.recordChange(root, existing, nil, path...); err != nil {
+		return err
+	}
+	e.File.splice(func(src *yamlop.Source) error { return src.Delete(root, path...) })
+	return yamlop.DeleteNode(root, path...)
+}
+
+// hasValue checks whether the value at the given path under the given node
+// already is the given value, as far as its meaning goes.
+func hasValue(root *yaml.Node, val any, path ...string) (bool, error) {
+	node, found, err := yamlop.GetNode(root, path...)
+	if err != nil || !found {
+		return false, err
+	}
+	// compare them as the same type, to leave aside the differences that
+	// don't matter (e.g. null or empty fields)
+	existingValue := reflect.New(reflect.TypeOf(val))
+	if err := decodeNode(node, existingValue.Interface()); err != nil {
+		// e.g. non-string keys, which aren't valid in CRDs anyway
+		return false, nil
+	}
+	return equality.Semantic.DeepEqual(existingValue.Elem().Interface(), val), nil
+}
+
+// decodeNode decodes the given YAML node tree into the given pointer as JSON,
+// paying attention to JSON tags.
+func decodeNode(node *yaml.Node, into any) error {
+	var rawValue any
+	if err := node.Decode(&rawValue); err != nil {
+		return err
+	}
+	rawJSON, err := json.Marshal(rawValue)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(rawJSON, into)
+}
+
 // crdsFromDirectory returns loads all CRDs from the given directory in a
 // manner that preserves ordering, comments, etc in order to make patching
 // minimally invasive.  Returned CRDs are mapped by group-kind.
+//
+// If the directory holds a kustomization, the CRDs are loaded from the files
+// it refers to instead (see kustomizationFiles).
 func crdsFromDirectory(ctx *genall.GenerationContext, dir string) (map[schema.GroupKind]*partialCRDSet, error) {
 	res := map[schema.GroupKind]*partialCRDSet{}
-	dirEntries, err := os.ReadDir(dir)
+	fileNames, err := manifestFileNames(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
-	for _, fileInfo := range dirEntries {
-		// find all files that are YAML
-		if fileInfo.IsDir() || filepath.Ext(fileInfo.Name()) != ".yaml" {
-			continue
-		}
-
-		rawContent, err := ctx.ReadFile(filepath.Join(dir, fileInfo.Name()))
+	for _, fileName := range fileNames {
+		rawContent, err := ctx.ReadFile(filepath.Join(dir, fileName))
 		if err != nil {
 			return nil, err
 		}
 
-		// NB(directxman12): we could use the universal deserializer for this, but it's
-		// really pretty clunky, and the alternative is actually kinda easier to understand
-
-		// ensure that this is a CRD
-		var typeMeta metav1.TypeMeta
-		if err := kyaml.Unmarshal(rawContent, &typeMeta); err != nil {
+		// mask the actions of templates (e.g. Helm charts) so that they can
+		// be parsed as YAML
+		rawContent, actions, err := maskTemplateActions(rawContent)
+		if err != nil {
 			continue
 		}
 
-		if typeMeta.APIVersion == "" || typeMeta.Kind != "CustomResourceDefinition" {
-			// If there's no API version this file probably isn't a CRD.
-			// Likewise we don't need to care if the Kind isn't CustomResourceDefinition.
+		// then actually unmarshal in a manner that preserves ordering, etc,
+		// reading all the documents of the file
+		docs, err := decodeDocuments(rawContent)
+		if err != nil {
 			continue
 		}
-
-		if !isSupportedAPIExtGroupVer(typeMeta.APIVersion) {
-			return nil, fmt.Errorf("load %q: apiVersion %q not supported", filepath.Join(dir, fileInfo.Name()), typeMeta.APIVersion)
+		file := &manifestFile{
+			Name:      fileName,
+			Documents: docs,
+			Source:    yamlop.NewSource(rawContent),
+			JSON:      detectJSONFormat(rawContent),
+			Template:  actions,
 		}
 
-		// collect the group-kind and versions from the actual structured form
-		var actualCRD crdIsh
-		if err := kyaml.Unmarshal(rawContent, &actualCRD); err != nil {
-			continue
-		}
-		groupKind := schema.GroupKind{Group: actualCRD.Spec.Group, Kind: actualCRD.Spec.Names.Kind}
-		versions := make(map[string]struct{}, len(actualCRD.Spec.Versions))
-		for _, ver := range actualCRD.Spec.Versions {
-			versions[ver.Name] = struct{}{}
-		}
+		for _, doc := range docs {
+			// NB(directxman12): we could use the universal deserializer for this, but it's
+			// really pretty clunky, and the alternative is actually kinda easier to understand
+			rawDoc, err := yaml.Marshal(doc)
+			if err != nil {
+				continue
+			}
 
-		// then actually unmarshal in a manner that preserves ordering, etc
-		var yamlNodeTree yaml.Node
-		if err := yaml.Unmarshal(rawContent, &yamlNodeTree); err != nil {
-			continue
-		}
+			// ensure that this is a CRD
+			var typeMeta metav1.TypeMeta
+			if err := kyaml.Unmarshal(rawDoc, &typeMeta); err != nil {
+				continue
+			}
 
-		// then store this CRDVersion of the CRD in a set, populating the set if necessary
-		if res[groupKind] == nil {
-			res[groupKind] = &partialCRDSet{
-				GroupKind:   groupKind,
-				NewSchemata: make(map[string]apiextensionsv1.JSONSchemaProps),
-				Versions:    make(map[string]struct{}),
+			if typeMeta.APIVersion == "" || typeMeta.Kind != "CustomResourceDefinition" {
+				// If there's no API version this document probably isn't a CRD.
+				// Likewise we don't need to care if the Kind isn't CustomResourceDefinition.
+				continue
 			}
+
+			if !isSupportedAPIExtGroupVer(typeMeta.APIVersion) {
+				return nil, fmt.Errorf("load %q: apiVersion %q not supported", filepath.Join(dir, fileName), typeMeta.APIVersion)
+			}
+
+			// collect the group-kind and versions from the actual structured form
+			var actualCRD crdIsh
+			if err := kyaml.Unmarshal(rawDoc, &actualCRD); err != nil {
+				continue
+			}
+			groupKind := schema.GroupKind{Group: actualCRD.Spec.Group, Kind: actualCRD.Spec.Names.Kind}
+
+			// then store this CRDVersion of the CRD in a set, populating the set if necessary
+			if res[groupKind] == nil {
+				res[groupKind] = &partialCRDSet{
+					GroupKind:   groupKind,
+					NewSchemata: make(map[string]apiextensionsv1.JSONSchemaProps),
+					Versions:    make(map[string]struct{}),
+				}
+			}
+			for _, ver := range actualCRD.Spec.Versions {
+				res[groupKind].Versions[ver.Name] = struct{}{}
+			}
+			res[groupKind].CRDVersions = append(res[groupKind].CRDVersions, &partialCRD{
+				Yaml:       doc,
+				File:       file,
+				CRDVersion: typeMeta.APIVersion,
+			})
 		}
-		for ver := range versions {
-			res[groupKind].Versions[ver] = struct{}{}
-		}
-		res[groupKind].CRDVersions = append(res[groupKind].CRDVersions, &partialCRD{
-			Yaml:       &yamlNodeTree,
-			FileName:   fileInfo.Name(),
-			CRDVersion: typeMeta.APIVersion,
-		})
 	}
 	return res, nil
 }
 
+// decodeDocuments decodes the documents of the given YAML stream.
+func decodeDocuments(rawContent []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(rawContent))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+}
+
 // isSupportedAPIExtGroupVer checks if the given string-form group-version
 // is one of the known apiextensions versions (v1).
 func isSupportedAPIExtGroupVer(groupVer string) bool {
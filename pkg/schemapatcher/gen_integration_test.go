@@ -19,12 +19,14 @@ package schemapatcher_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"sigs.k8s.io/controller-tools/pkg/genall"
 	. "sigs.k8s.io/controller-tools/pkg/schemapatcher"
+	"sigs.k8s.io/controller-tools/pkg/version"
 )
 
 var _ = Describe("CRD Patching From Parsing to Editing", func() {
@@ -89,4 +91,504 @@ var _ = Describe("CRD Patching From Parsing to Editing", func() {
 			Expect(actualContents).To(MatchYAML(expectedContents), "contents not as expected, check pkg/schemapatcher/testdata/README.md for more details.\n\nDiff:\n\n%s", cmp.Diff(string(actualContents), string(expectedContents)))
 		}
 	})
+
+	It("should only replace the patched schemas, leaving the rest of the manifests as written", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the generation runtime")
+		var crdSchemaGen genall.Generator = &Generator{
+			ManifestsPath: "./formatted",
+		}
+		rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+		Expect(err).NotTo(HaveOccurred())
+
+		outputDir := GinkgoT().TempDir()
+		rt.OutputRules.Default = genall.OutputToDirectory(outputDir)
+		rt.ErrorWriter = GinkgoWriter
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "unexpectedly had errors")
+
+		By("checking that the text of the patched manifest is exactly as expected")
+		actualContents, err := os.ReadFile(filepath.Join(outputDir, "kubebuilder-example-crd.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		expectedContents, err := os.ReadFile(filepath.Join("expected-formatted", "kubebuilder-example-crd.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(actualContents)).To(Equal(string(expectedContents)), "contents not as expected, check pkg/schemapatcher/testdata/README.md for more details.\n\nDiff:\n\n%s", cmp.Diff(string(actualContents), string(expectedContents)))
+	})
+
+	It("should only update the schemata of the given versions", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		const manifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+spec:
+  group: kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  names:
+    kind: Example
+    plural: examples
+  scope: Cluster
+  versions:
+  - name: v1beta1
+    schema:
+      openAPIV3Schema:
+        description: frozen by policy
+        type: object
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+		patch := func(versions ...string) string {
+			manifestsDir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(manifestsDir, "examples.yaml"), []byte(manifest), 0o644)).To(Succeed())
+
+			var crdSchemaGen genall.Generator = &Generator{
+				ManifestsPath: manifestsDir,
+				Versions:      versions,
+			}
+			rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+			Expect(err).NotTo(HaveOccurred())
+			outputDir := GinkgoT().TempDir()
+			rt.OutputRules.Default = genall.OutputToDirectory(outputDir)
+			rt.ErrorWriter = GinkgoWriter
+			Expect(rt.Run()).To(BeFalse(), "unexpectedly had errors")
+
+			contents, err := os.ReadFile(filepath.Join(outputDir, "examples.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			return string(contents)
+		}
+
+		By("updating all the versions, wiping the schemata of the ones without types")
+		patched := patch()
+		Expect(patched).NotTo(ContainSubstring("frozen by policy"))
+		Expect(patched).To(ContainSubstring("Example is a kind with schema changes."))
+
+		By("only updating the given versions")
+		patched = patch("v1")
+		Expect(patched).To(HavePrefix(manifest[:strings.Index(manifest, "  - name: v1\n")]))
+		Expect(patched).To(ContainSubstring("Example is a kind with schema changes."))
+	})
+
+	It("should patch the CRDs referenced by a kustomization in place", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("writing a kustomization referring to CRDs outside of the manifests directory")
+		configDir := GinkgoT().TempDir()
+		files := map[string]string{
+			"crd/kustomization.yaml": `resources:
+- ../shared
+- https://example.com/remote.yaml
+patches:
+- path: patches/conversion.yaml
+`,
+			"crd/patches/conversion.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+spec:
+  conversion:
+    strategy: None
+`,
+			"shared/kustomization.yaml": `resources:
+- bases/examples.yaml
+`,
+			"shared/bases/examples.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+spec:
+  group: kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  names:
+    kind: Example
+    plural: examples
+  scope: Cluster
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+`,
+		}
+		for name, contents := range files {
+			path := filepath.Join(configDir, name)
+			Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte(contents), 0o644)).To(Succeed())
+		}
+
+		By("loading the generation runtime")
+		var crdSchemaGen genall.Generator = &Generator{
+			ManifestsPath: filepath.Join(configDir, "crd"),
+		}
+		rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+		Expect(err).NotTo(HaveOccurred())
+
+		outputDir := GinkgoT().TempDir()
+		rt.OutputRules.Default = genall.OutputToDirectory(filepath.Join(outputDir, "crd"))
+		rt.ErrorWriter = GinkgoWriter
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "unexpectedly had errors")
+
+		By("checking that the referenced CRD was patched at the same path relative to the manifests directory")
+		patched, err := os.ReadFile(filepath.Join(outputDir, "shared", "bases", "examples.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(patched)).To(ContainSubstring("Example is a kind with schema changes."))
+
+		By("checking that the patch without schemata was left alone")
+		patch, err := os.ReadFile(filepath.Join(outputDir, "crd", "patches", "conversion.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(patch)).To(Equal(files["crd/patches/conversion.yaml"]))
+	})
+
+	It("should patch the conversion, metadata and subresources of the CRDs when asked to", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		const manifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  annotations:
+    # injected by cert-manager
+    cert-manager.io/inject-ca-from: system/serving-cert
+  name: examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+spec:
+  conversion:
+    strategy: Webhook
+  group: kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  names:
+    kind: Example
+    plural: examples
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+		manifestsDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(manifestsDir, "examples.yaml"), []byte(manifest), 0o644)).To(Succeed())
+
+		By("loading the generation runtime")
+		enabled := true
+		var crdSchemaGen genall.Generator = &Generator{
+			ManifestsPath: manifestsDir,
+			Conversion:    &enabled,
+			Annotations:   &enabled,
+			Labels:        &enabled,
+			Subresources:  &enabled,
+		}
+		rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+		Expect(err).NotTo(HaveOccurred())
+		outputDir := GinkgoT().TempDir()
+		rt.OutputRules.Default = genall.OutputToDirectory(outputDir)
+		rt.ErrorWriter = GinkgoWriter
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "unexpectedly had errors")
+
+		By("checking that the fields were patched, leaving the rest as written")
+		contents, err := os.ReadFile(filepath.Join(outputDir, "examples.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		patched := string(contents)
+		Expect(patched).To(HavePrefix(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  annotations:
+    # injected by cert-manager
+    cert-manager.io/inject-ca-from: system/serving-cert
+    controller-gen.kubebuilder.io/version: ` + version.Version() + `
+  name: examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  labels:
+    app: example
+spec:
+  group: kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  names:
+    kind: Example
+    plural: examples
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`))
+		Expect(patched).To(ContainSubstring(`
+    subresources:
+      status: {}
+`))
+		Expect(patched).To(ContainSubstring("Example is a kind with schema changes."))
+	})
+
+	It("should patch JSON manifests, keeping them in JSON", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		const manifest = `{
+    "apiVersion": "apiextensions.k8s.io/v1",
+    "kind": "CustomResourceDefinition",
+    "metadata": {
+        "name": "examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io"
+    },
+    "spec": {
+        "group": "kubebuilder.schemapatcher.controller-tools.sigs.k8s.io",
+        "names": {
+            "kind": "Example",
+            "plural": "examples"
+        },
+        "scope": "Cluster",
+        "versions": [
+            {
+                "name": "v1",
+                "schema": {
+                    "openAPIV3Schema": {
+                        "type": "object"
+                    }
+                },
+                "served": true,
+                "storage": true
+            }
+        ]
+    }
+}
+`
+		manifestsDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(manifestsDir, "examples.json"), []byte(manifest), 0o644)).To(Succeed())
+
+		By("loading the generation runtime")
+		var crdSchemaGen genall.Generator = &Generator{
+			ManifestsPath: manifestsDir,
+		}
+		rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+		Expect(err).NotTo(HaveOccurred())
+		outputDir := GinkgoT().TempDir()
+		rt.OutputRules.Default = genall.OutputToDirectory(outputDir)
+		rt.ErrorWriter = GinkgoWriter
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "unexpectedly had errors")
+
+		By("checking that the manifest was patched, in the same JSON format")
+		contents, err := os.ReadFile(filepath.Join(outputDir, "examples.json"))
+		Expect(err).NotTo(HaveOccurred())
+		patched := string(contents)
+		Expect(patched).To(HavePrefix(manifest[:strings.Index(manifest, `                    "openAPIV3Schema": {`)]))
+		Expect(patched).To(ContainSubstring(`
+                "served": true,
+                "storage": true
+            }
+        ]
+    }
+}
+`))
+		Expect(patched).To(HaveSuffix("}\n"))
+		Expect(patched).To(ContainSubstring(`"description": "Example is a kind with schema changes."`))
+	})
+
+	It("should keep the protected parts of the schemata as they are", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		const manifest = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+spec:
+  group: kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  names:
+    kind: Example
+    plural: examples
+  scope: Cluster
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              foo:
+                description: |-
+                  foo is written by hand.
+                type: string
+              gone:
+                description: gone is written by hand.
+                type: string
+            # +schemapatch:protected
+            x-kubernetes-validations:
+            - rule: self.foo != self.bar
+              message: foo and bar must differ
+`
+		manifestsDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(manifestsDir, "examples.yaml"), []byte(manifest), 0o644)).To(Succeed())
+
+		By("loading the generation runtime")
+		var crdSchemaGen genall.Generator = &Generator{
+			ManifestsPath: manifestsDir,
+			Protected:     []string{"Example:spec.foo/description", "spec.gone/description", "Other:spec.bar"},
+		}
+		rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+		Expect(err).NotTo(HaveOccurred())
+		outputDir := GinkgoT().TempDir()
+		rt.OutputRules.Default = genall.OutputToDirectory(outputDir)
+		rt.ErrorWriter = GinkgoWriter
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "unexpectedly had errors")
+
+		By("checking that the protected entries were kept, and the rest was generated")
+		contents, err := os.ReadFile(filepath.Join(outputDir, "examples.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		patched := string(contents)
+		Expect(patched).To(ContainSubstring("Example is a kind with schema changes."))
+		Expect(patched).To(ContainSubstring(`
+              bar:
+                description: foo contains foo.
+                type: string
+              foo:
+                description: |-
+                  foo is written by hand.
+                type: string
+`))
+		Expect(patched).NotTo(ContainSubstring("gone"))
+		Expect(patched).To(HaveSuffix(`
+            # +schemapatch:protected
+            x-kubernetes-validations:
+              - rule: self.foo != self.bar
+                message: foo and bar must differ
+`))
+	})
+
+	It("should report the out of date manifests instead of writing them when verifying", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		verify := func(manifestsPath string) (string, bool) {
+			enabled := true
+			var crdSchemaGen genall.Generator = &Generator{
+				ManifestsPath: manifestsPath,
+				Verify:        &enabled,
+			}
+			rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+			Expect(err).NotTo(HaveOccurred())
+			outputDir := GinkgoT().TempDir()
+			rt.OutputRules.Default = genall.OutputToDirectory(outputDir)
+			var errOut strings.Builder
+			rt.ErrorWriter = &errOut
+			failed := rt.Run()
+
+			By("checking that nothing was written")
+			written, err := os.ReadDir(outputDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(written).To(BeEmpty())
+			return errOut.String(), failed
+		}
+
+		By("verifying the up to date manifests")
+		report, failed := verify("./expected")
+		Expect(failed).To(BeFalse(), "unexpectedly had errors: %s", report)
+
+		By("verifying the out of date manifests")
+		report, failed = verify("./valid")
+		Expect(failed).To(BeTrue(), "unexpectedly succeeded")
+		Expect(report).To(HavePrefix(`the following CRD manifests are out of date:
+  kubebuilder-example-crd.v1.yaml
+    example.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io: spec.versions[v1].schema.openAPIV3Schema
+  kubebuilder-unchanged-crd.yaml
+    unchanged.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io: spec.versions[v1].schema.openAPIV3Schema
+`))
+		Expect(report).To(ContainSubstring(`--- kubebuilder-example-crd.v1.yaml (example.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io: spec.versions[v1].schema.openAPIV3Schema)
++++ kubebuilder-example-crd.v1.yaml (example.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io: spec.versions[v1].schema.openAPIV3Schema) (generated)
+`))
+		Expect(report).To(ContainSubstring(`
+       - bar
++      - foo
+`))
+	})
+
+	It("should patch CRDs in templates, keeping their actions as they are", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		const manifest = `{{- if .Values.crds.install }}
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: examples.kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  labels:
+    {{- include "chart.labels" . | nindent 4 }}
+  annotations:
+    helm.sh/resource-policy: {{ .Values.crds.policy | quote }}
+spec:
+  group: kubebuilder.schemapatcher.controller-tools.sigs.k8s.io
+  names:
+    kind: Example
+    plural: {{ .Values.plural }}
+  scope: Cluster
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+    served: true
+    storage: true
+{{- end }}
+`
+		manifestsDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(manifestsDir, "examples.yaml"), []byte(manifest), 0o644)).To(Succeed())
+
+		By("loading the generation runtime")
+		var crdSchemaGen genall.Generator = &Generator{
+			ManifestsPath: manifestsDir,
+		}
+		rt, err := genall.Generators{&crdSchemaGen}.ForRoots("./...")
+		Expect(err).NotTo(HaveOccurred())
+		outputDir := GinkgoT().TempDir()
+		rt.OutputRules.Default = genall.OutputToDirectory(outputDir)
+		rt.ErrorWriter = GinkgoWriter
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeFalse(), "unexpectedly had errors")
+
+		By("checking that only the schema was patched, leaving the template actions as they are")
+		contents, err := os.ReadFile(filepath.Join(outputDir, "examples.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		patched := string(contents)
+		Expect(patched).To(HavePrefix(manifest[:strings.Index(manifest, "      openAPIV3Schema:\n")]))
+		Expect(patched).To(HaveSuffix(`
+    served: true
+    storage: true
+{{- end }}
+`))
+		Expect(patched).To(ContainSubstring("Example is a kind with schema changes."))
+		Expect(patched).NotTo(ContainSubstring("schemapatch_template"))
+	})
 })
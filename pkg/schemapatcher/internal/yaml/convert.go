@@ -17,6 +17,7 @@ limitations under the License.
 package yaml
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -43,6 +44,79 @@ func ToYAML(rawObj any) (*yaml.Node, error) {
 	return &out, nil
 }
 
+// ToJSON converts a YAML node tree into compact JSON, keeping the order of
+// the keys of its mappings.
+func ToJSON(root *yaml.Node) ([]byte, error) {
+	var out bytes.Buffer
+	if err := writeJSON(&out, root); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeJSON writes the given YAML node tree out as compact JSON.
+func writeJSON(out *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			out.WriteString("null")
+			return nil
+		}
+		return writeJSON(out, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(out, node.Alias)
+	case yaml.MappingNode:
+		out.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeJSONValue(out, node.Content[i].Value); err != nil {
+				return err
+			}
+			out.WriteByte(':')
+			if err := writeJSON(out, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+		return nil
+	case yaml.SequenceNode:
+		out.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeJSON(out, item); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+		return nil
+	case yaml.ScalarNode:
+		var val any
+		if err := node.Decode(&val); err != nil {
+			return err
+		}
+		return writeJSONValue(out, val)
+	default:
+		return fmt.Errorf("unexpected node kind %v", node.Kind)
+	}
+}
+
+// writeJSONValue writes the given value out as compact JSON, leaving HTML
+// characters as they are.
+func writeJSONValue(out *bytes.Buffer, val any) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(val); err != nil {
+		return err
+	}
+	// drop the newline added by the encoder
+	out.Truncate(out.Len() - 1)
+	return nil
+}
+
 // changeAll calls the given callback for all nodes in
 // the given YAML node tree.
 func changeAll(root *yaml.Node, cb func(*yaml.Node)) {
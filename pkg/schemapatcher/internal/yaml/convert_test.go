@@ -0,0 +1,45 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yaml
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("ToJSON", func() {
+	It("should keep the order of the keys", func() {
+		var root yaml.Node
+		Expect(yaml.Unmarshal([]byte(`
+z: &a
+  - 1
+  - true
+  - null
+a: "<html> & 1.5"
+m: *a
+`), &root)).To(Succeed())
+		Expect(ToJSON(&root)).To(Equal([]byte(`{"z":[1,true,null],"a":"<html> & 1.5","m":[1,true,null]}`)))
+	})
+
+	It("should refuse values that can't be encoded in JSON", func() {
+		var root yaml.Node
+		Expect(yaml.Unmarshal([]byte(`a: .inf`), &root)).To(Succeed())
+		_, err := ToJSON(&root)
+		Expect(err).To(HaveOccurred())
+	})
+})
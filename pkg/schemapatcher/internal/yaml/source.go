@@ -0,0 +1,319 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotSpliceable is returned when editing a Source if the edited node
+// can't be replaced in the text of the source (e.g. because it's in flow
+// style, or wasn't decoded from the source), in which case the whole
+// document has to be encoded again instead.
+var ErrNotSpliceable = errors.New("unable to edit the node in place")
+
+// Source is the text of a stream of YAML documents, edited by replacing the
+// lines of the entries of its block mappings, so that everything else in it
+// (comments, formatting, key ordering, anchors, other documents...) is kept
+// as is.  The nodes passed to its methods must have been decoded from the
+// same text (with a yaml.Decoder, for streams of several documents), before
+// being modified.
+type Source struct {
+	// lines are the lines of the source, including their line breaks.
+	lines []string
+	// edits are the edits made so far, which don't overlap.
+	edits []lineEdit
+}
+
+// lineEdit replaces the lines [start, end) of a source with some text
+// (inserting it before start if start == end).
+type lineEdit struct {
+	start, end int
+	text       string
+}
+
+// NewSource returns a Source of the given text.
+func NewSource(src []byte) *Source {
+	return &Source{lines: strings.SplitAfter(string(src), "\n")}
+}
+
+// Edited checks whether the source was edited.
+func (s *Source) Edited() bool {
+	return len(s.edits) > 0
+}
+
+// Bytes returns the edited text of the source.
+func (s *Source) Bytes() []byte {
+	edits := slices.Clone(s.edits)
+	slices.SortStableFunc(edits, func(a, b lineEdit) int { return a.start - b.start })
+
+	var out bytes.Buffer
+	line := 0
+	for _, edit := range edits {
+		for ; line < edit.start; line++ {
+			out.WriteString(s.lines[line])
+		}
+		if out.Len() > 0 && out.Bytes()[out.Len()-1] != '\n' {
+			// inserting after a last line without line break
+			out.WriteByte('\n')
+		}
+		out.WriteString(edit.text)
+		line = max(line, edit.end)
+	}
+	for ; line < len(s.lines); line++ {
+		out.WriteString(s.lines[line])
+	}
+	return out.Bytes()
+}
+
+// Set sets the value at the given path under the given node, as SetNode, by
+// replacing the lines of the deepest existing entry of the path, or adding
+// an entry at the end of the deepest existing mapping of the path.
+func (s *Source) Set(root *yaml.Node, val *yaml.Node, path ...string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("must specify a path to set")
+	}
+	mapping := documentContent(root)
+	var key, value *yaml.Node
+	for len(path) > 0 {
+		if mapping.Kind != yaml.MappingNode {
+			return fmt.Errorf("unexpected non-mapping (%v) before path %v", mapping.Kind, path)
+		}
+		if isFlow(mapping) && len(mapping.Content) > 0 {
+			return ErrNotSpliceable
+		}
+		nextKey, nextValue := entryOf(mapping, path[0])
+		if nextKey == nil {
+			break
+		}
+		key, value = nextKey, nextValue
+		mapping = nextValue
+		path = path[1:]
+	}
+	if len(path) == 0 {
+		return s.replaceEntry(key, value, val)
+	}
+
+	// nest the rest of the path in new mappings
+	newVal := val
+	for i := len(path) - 1; i > 0; i-- {
+		newVal = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{plainKey(path[i]), newVal}}
+	}
+	if len(mapping.Content) > 0 {
+		return s.addEntry(mapping, plainKey(path[0]), newVal)
+	}
+	if key == nil {
+		return ErrNotSpliceable
+	}
+	// empty mapping, replaced as a whole
+	newMapping := *mapping
+	newMapping.Style = 0
+	newMapping.Content = []*yaml.Node{plainKey(path[0]), newVal}
+	return s.replaceEntry(key, value, &newMapping)
+}
+
+// Delete deletes the entry at the given path under the given node, if any,
+// as DeleteNode, by removing its lines.
+func (s *Source) Delete(root *yaml.Node, path ...string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("must specify a path to delete")
+	}
+	mapping := documentContent(root)
+	var key, value *yaml.Node
+	for _, name := range path {
+		if mapping.Kind != yaml.MappingNode {
+			return fmt.Errorf("unexpected non-mapping node")
+		}
+		if key, value = entryOf(mapping, name); key == nil {
+			// no-op, entry not found
+			return nil
+		}
+		if isFlow(mapping) {
+			return ErrNotSpliceable
+		}
+		mapping = value
+	}
+	start, end, prefix, err := s.entryLines(key, value)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(prefix) != "" {
+		// e.g. the first entry of a mapping in a sequence
+		return ErrNotSpliceable
+	}
+	return s.edit(lineEdit{start: start, end: end})
+}
+
+// replaceEntry replaces the lines of the entry of the given key and value
+// with an entry of the given key and new value.
+func (s *Source) replaceEntry(key, value, newValue *yaml.Node) error {
+	start, end, prefix, err := s.entryLines(key, value)
+	if err != nil {
+		return err
+	}
+	text, err := entryText(prefix, len(prefix), key, newValue)
+	if err != nil {
+		return err
+	}
+	return s.edit(lineEdit{start: start, end: end, text: text})
+}
+
+// addEntry adds an entry of the given key and value after the last entry of
+// the given block mapping decoded from the source (following the entries
+// added before).
+func (s *Source) addEntry(mapping, key, value *yaml.Node) error {
+	firstKey := mapping.Content[0]
+	if firstKey.Line == 0 {
+		return ErrNotSpliceable
+	}
+	last := len(mapping.Content) - 2
+	for mapping.Content[last].Line == 0 {
+		last -= 2
+	}
+	lastKey, lastValue := mapping.Content[last], mapping.Content[last+1]
+	_, end, _, err := s.entryLines(lastKey, lastValue)
+	if err != nil {
+		return err
+	}
+	indent := firstKey.Column - 1
+	text, err := entryText(strings.Repeat(" ", indent), indent, key, value)
+	if err != nil {
+		return err
+	}
+	return s.edit(lineEdit{start: end, end: end, text: text})
+}
+
+// entryLines returns the lines [start, end) of the entry of the given key
+// and value of a block mapping, along with the text before the key on its
+// line (e.g. indentation, or the dash of a sequence item).
+func (s *Source) entryLines(key, value *yaml.Node) (start, end int, prefix string, err error) {
+	if key.Line == 0 || key.Line > len(s.lines) {
+		return 0, 0, "", ErrNotSpliceable
+	}
+	start = key.Line - 1
+	column := key.Column - 1
+	if column > len(s.lines[start]) {
+		return 0, 0, "", ErrNotSpliceable
+	}
+	prefix = s.lines[start][:column]
+
+	// the entry goes on until the next line indented as much as the key (or
+	// less), apart from the items of a sequence that aren't indented,
+	// leaving out blank lines and comments at its end
+	sequence := value.Kind == yaml.SequenceNode && !isFlow(value)
+	end = start + 1
+	for i := start + 1; i < len(s.lines); i++ {
+		line := strings.TrimRight(s.lines[i], "\r\n")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+		indent := len(line) - len(content)
+		if indent < column || indent == column && !(sequence && isSequenceItem(content)) {
+			break
+		}
+		end = i + 1
+	}
+	return start, end, prefix, nil
+}
+
+// edit adds the given edit, unless it overlaps the ones made so far.
+func (s *Source) edit(edit lineEdit) error {
+	for _, other := range s.edits {
+		if edit.start < other.end && other.start < edit.end || edit.start == other.start && (edit.start == edit.end) != (other.start == other.end) {
+			return ErrNotSpliceable
+		}
+	}
+	s.edits = append(s.edits, edit)
+	return nil
+}
+
+// entryText returns the lines of an entry of the given key and value, its
+// first line starting with the given prefix, and the others indented by the
+// given number of spaces.
+func entryText(prefix string, indent int, key, value *yaml.Node) (string, error) {
+	entryKey := *key
+	entryKey.HeadComment = ""
+	entryKey.FootComment = ""
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	// be compatible with everything else in k8s, as when encoding whole
+	// documents
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{&entryKey, value}}); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for i, line := range strings.SplitAfter(buf.String(), "\n") {
+		switch {
+		case line == "":
+		case i == 0:
+			out.WriteString(prefix + line)
+		case line == "\n":
+			out.WriteString(line)
+		default:
+			out.WriteString(strings.Repeat(" ", indent) + line)
+		}
+	}
+	return out.String(), nil
+}
+
+// isFlow checks whether the given node is in flow style.
+func isFlow(node *yaml.Node) bool {
+	return node.Style&yaml.FlowStyle != 0
+}
+
+// isSequenceItem checks whether the given content of a line (without its
+// indentation) starts a sequence item.
+func isSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// documentContent returns the content of the given document node, or the
+// node itself if it isn't a document.
+func documentContent(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// entryOf returns the key and value of the entry of the given key in the
+// given mapping, or nils if there's none.
+func entryOf(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// plainKey returns a mapping key node of the given name.
+func plainKey(name string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yaml
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Source", func() {
+	const src = `# header
+spec:
+  # the versions
+  versions:
+  - name: v1 # first
+    schema:
+      openAPIV3Schema:
+        type: object
+    # after the schema
+    served: true
+  - name: v2
+    served: false
+  flow: {a: b}
+`
+
+	edit := func(edit func(*Source, *yaml.Node) error) (string, error) {
+		var root yaml.Node
+		Expect(yaml.Unmarshal([]byte(src), &root)).To(Succeed())
+		source := NewSource([]byte(src))
+		err := edit(source, &root)
+		return string(source.Bytes()), err
+	}
+	versions := func(root *yaml.Node) []*yaml.Node {
+		versions, found, err := GetNode(root, "spec", "versions")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		return versions.Content
+	}
+	schema := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "type"}, {Kind: yaml.ScalarNode, Value: "string"},
+	}}
+
+	It("should replace the lines of existing entries", func() {
+		Expect(edit(func(s *Source, root *yaml.Node) error {
+			return s.Set(versions(root)[0], schema, "schema", "openAPIV3Schema")
+		})).To(Equal(`# header
+spec:
+  # the versions
+  versions:
+  - name: v1 # first
+    schema:
+      openAPIV3Schema:
+        type: string
+    # after the schema
+    served: true
+  - name: v2
+    served: false
+  flow: {a: b}
+`))
+	})
+
+	It("should add entries after the last entry of the deepest existing mapping", func() {
+		Expect(edit(func(s *Source, root *yaml.Node) error {
+			return s.Set(versions(root)[1], schema, "schema", "openAPIV3Schema")
+		})).To(Equal(`# header
+spec:
+  # the versions
+  versions:
+  - name: v1 # first
+    schema:
+      openAPIV3Schema:
+        type: object
+    # after the schema
+    served: true
+  - name: v2
+    served: false
+    schema:
+      openAPIV3Schema:
+        type: string
+  flow: {a: b}
+`))
+	})
+
+	It("should add entries after the ones added before to the same mapping", func() {
+		Expect(edit(func(s *Source, root *yaml.Node) error {
+			ver := versions(root)[1]
+			Expect(s.Set(ver, schema, "subresources")).To(Succeed())
+			Expect(SetNode(ver, *schema, "subresources")).To(Succeed())
+			return s.Set(ver, schema, "schema", "openAPIV3Schema")
+		})).To(Equal(`# header
+spec:
+  # the versions
+  versions:
+  - name: v1 # first
+    schema:
+      openAPIV3Schema:
+        type: object
+    # after the schema
+    served: true
+  - name: v2
+    served: false
+    subresources:
+      type: string
+    schema:
+      openAPIV3Schema:
+        type: string
+  flow: {a: b}
+`))
+	})
+
+	It("should replace whole entries with non-indented sequences", func() {
+		Expect(edit(func(s *Source, root *yaml.Node) error {
+			return s.Set(root, &yaml.Node{Kind: yaml.SequenceNode}, "spec", "versions")
+		})).To(Equal(`# header
+spec:
+  # the versions
+  versions: []
+  flow: {a: b}
+`))
+	})
+
+	It("should delete the lines of entries, keeping the comments after them", func() {
+		Expect(edit(func(s *Source, root *yaml.Node) error {
+			return s.Delete(versions(root)[0], "schema")
+		})).To(Equal(`# header
+spec:
+  # the versions
+  versions:
+  - name: v1 # first
+    # after the schema
+    served: true
+  - name: v2
+    served: false
+  flow: {a: b}
+`))
+	})
+
+	It("should refuse to edit flow-style mappings or the first entries of sequence items", func() {
+		_, err := edit(func(s *Source, root *yaml.Node) error {
+			return s.Set(root, schema, "spec", "flow", "a")
+		})
+		Expect(err).To(MatchError(ErrNotSpliceable))
+		_, err = edit(func(s *Source, root *yaml.Node) error {
+			return s.Delete(versions(root)[0], "name")
+		})
+		Expect(err).To(MatchError(ErrNotSpliceable))
+	})
+
+	It("should refuse overlapping edits", func() {
+		_, err := edit(func(s *Source, root *yaml.Node) error {
+			Expect(s.Delete(versions(root)[0], "schema")).To(Succeed())
+			return s.Set(versions(root)[0], schema, "schema", "openAPIV3Schema")
+		})
+		Expect(err).To(MatchError(ErrNotSpliceable))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemapatcher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// kustomizationNames are the names kustomize recognizes for kustomizations.
+var kustomizationNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// kustomization is the part of a kustomization referring to other files.
+type kustomization struct {
+	Resources             []string `json:"resources"`
+	Bases                 []string `json:"bases"`
+	Components            []string `json:"components"`
+	CRDs                  []string `json:"crds"`
+	PatchesStrategicMerge []string `json:"patchesStrategicMerge"`
+	Patches               []struct {
+		Path string `json:"path"`
+	} `json:"patches"`
+}
+
+// manifestFileNames returns the paths (relative to the given directory) of
+// the files that may hold CRD manifests: the files referred to by the
+// kustomization of the directory, if any, or the YAML and JSON files in it
+// otherwise.
+func manifestFileNames(ctx *genall.GenerationContext, dir string) ([]string, error) {
+	if kustomizationPath := findKustomization(dir); kustomizationPath != "" {
+		seen := make(map[string]struct{})
+		var fileNames []string
+		if err := kustomizationFiles(ctx, dir, kustomizationPath, seen, &fileNames); err != nil {
+			return nil, err
+		}
+		return fileNames, nil
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var fileNames []string
+	for _, fileInfo := range dirEntries {
+		// find all files that are YAML or JSON
+		if ext := filepath.Ext(fileInfo.Name()); fileInfo.IsDir() || ext != ".yaml" && ext != ".json" {
+			continue
+		}
+		fileNames = append(fileNames, fileInfo.Name())
+	}
+	return fileNames, nil
+}
+
+// kustomizationFiles adds the paths (relative to the given root directory) of
+// the files the given kustomization refers to (its resources, bases, CRDs and
+// patches), following the kustomizations of the directories it refers to.
+// Remote references are skipped, since they can't be patched in place.
+func kustomizationFiles(ctx *genall.GenerationContext, root, kustomizationPath string, seen map[string]struct{}, fileNames *[]string) error {
+	if _, ok := seen[kustomizationPath]; ok {
+		return nil
+	}
+	seen[kustomizationPath] = struct{}{}
+
+	rawContent, err := ctx.ReadFile(kustomizationPath)
+	if err != nil {
+		return err
+	}
+	var k kustomization
+	if err := kyaml.Unmarshal(rawContent, &k); err != nil {
+		return fmt.Errorf("load %q: %w", kustomizationPath, err)
+	}
+	refs := make([]string, 0, len(k.Resources)+len(k.Bases)+len(k.Components)+len(k.CRDs)+len(k.PatchesStrategicMerge)+len(k.Patches))
+	refs = append(refs, k.Resources...)
+	refs = append(refs, k.Bases...)
+	refs = append(refs, k.Components...)
+	refs = append(refs, k.CRDs...)
+	refs = append(refs, k.PatchesStrategicMerge...)
+	for _, patch := range k.Patches {
+		if patch.Path != "" {
+			refs = append(refs, patch.Path)
+		}
+	}
+
+	dir := filepath.Dir(kustomizationPath)
+	for _, ref := range refs {
+		if isRemote(ref) {
+			continue
+		}
+		refPath := filepath.Join(dir, ref)
+		info, err := os.Stat(refPath)
+		if err != nil {
+			return fmt.Errorf("load %q: %w", kustomizationPath, err)
+		}
+		if info.IsDir() {
+			nestedPath := findKustomization(refPath)
+			if nestedPath == "" {
+				return fmt.Errorf("load %q: no kustomization in directory %q", kustomizationPath, ref)
+			}
+			if err := kustomizationFiles(ctx, root, nestedPath, seen, fileNames); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := seen[refPath]; ok {
+			continue
+		}
+		seen[refPath] = struct{}{}
+		relPath, err := filepath.Rel(root, refPath)
+		if err != nil {
+			return err
+		}
+		*fileNames = append(*fileNames, relPath)
+	}
+	return nil
+}
+
+// findKustomization returns the path of the kustomization of the given
+// directory, or "" if there's none.
+func findKustomization(dir string) string {
+	for _, name := range kustomizationNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			// unreadable, let reading it fail
+			return path
+		}
+	}
+	return ""
+}
+
+// isRemote checks whether the given kustomization reference refers to a
+// remote resource (e.g. a URL or a git repository) rather than a local path.
+func isRemote(ref string) bool {
+	return strings.Contains(ref, "://") || strings.HasPrefix(ref, "github.com/") || strings.HasPrefix(ref, "git@")
+}
@@ -0,0 +1,144 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemapatcher
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	yamlop "sigs.k8s.io/controller-tools/pkg/schemapatcher/internal/yaml"
+)
+
+// protectedMarker is the comment marking an entry of a schema in a manifest
+// as manually maintained.
+const protectedMarker = "+schemapatch:protected"
+
+// protectedPath is the path (as keys of nested mappings) of a manually
+// maintained subtree of a schema, relative to the openAPIV3Schema node.
+type protectedPath []string
+
+// parseProtectedPaths parses the given protected paths (see
+// Generator.Protected), returning the ones applying to the given kind.
+func parseProtectedPaths(paths []string, kind string) ([]protectedPath, error) {
+	var res []protectedPath
+	for _, rawPath := range paths {
+		path := rawPath
+		if pathKind, rest, found := strings.Cut(path, ":"); found {
+			if pathKind != kind {
+				continue
+			}
+			path = rest
+		}
+		fields, keyword, _ := strings.Cut(path, "/")
+
+		var parsed protectedPath
+		if fields != "" {
+			for _, field := range strings.Split(fields, ".") {
+				name := strings.TrimRight(field, "[]")
+				if name == "" {
+					return nil, fmt.Errorf("invalid protected path %q: empty field name", rawPath)
+				}
+				parsed = append(parsed, "properties", name)
+				// the items of lists, e.g. "rules[]"
+				for range strings.Count(field[len(name):], "[]") {
+					parsed = append(parsed, "items")
+				}
+			}
+		}
+		if keyword != "" {
+			parsed = append(parsed, keyword)
+		}
+		if len(parsed) == 0 {
+			return nil, fmt.Errorf("invalid protected path %q: the whole schema can't be protected", rawPath)
+		}
+		res = append(res, parsed)
+	}
+	return res, nil
+}
+
+// markedPaths returns the paths of the entries of the given schema marked
+// as manually maintained (by a comment containing protectedMarker, before
+// their key or after it, on the same line).
+func markedPaths(schemaNode *yaml.Node) []protectedPath {
+	var res []protectedPath
+	var walk func(node *yaml.Node, path protectedPath)
+	walk = func(node *yaml.Node, path protectedPath) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			entryPath := append(slices.Clip(path), key.Value)
+			if isMarked(key) || value.Kind != yaml.ScalarNode && strings.Contains(value.LineComment, protectedMarker) {
+				res = append(res, entryPath)
+				continue
+			}
+			walk(value, entryPath)
+		}
+	}
+	walk(schemaNode, nil)
+	return res
+}
+
+// isMarked checks whether the given mapping key has a comment containing
+// protectedMarker.
+func isMarked(key *yaml.Node) bool {
+	return strings.Contains(key.HeadComment, protectedMarker) || strings.Contains(key.LineComment, protectedMarker)
+}
+
+// mergeProtected replaces the entries at the given protected paths in the
+// given new schema with the ones in the existing schema (comments included),
+// leaving the rest of the new schema as generated.  Protected entries that
+// are missing from the existing schema are left as generated, and the ones
+// whose parent is missing from the new schema (e.g. since the field was
+// removed) are dropped.
+func mergeProtected(existingSchema, newSchema *yaml.Node, paths []protectedPath) {
+	for _, path := range paths {
+		parentPath, name := path[:len(path)-1], path[len(path)-1]
+		existingParent, found, err := yamlop.GetNode(existingSchema, parentPath...)
+		if err != nil || !found || existingParent.Kind != yaml.MappingNode {
+			continue
+		}
+		existingIdx := entryIndex(existingParent, name)
+		if existingIdx < 0 {
+			continue
+		}
+		newParent, found, err := yamlop.GetNode(newSchema, parentPath...)
+		if err != nil || !found || newParent.Kind != yaml.MappingNode {
+			continue
+		}
+		entry := existingParent.Content[existingIdx : existingIdx+2]
+		if newIdx := entryIndex(newParent, name); newIdx >= 0 {
+			copy(newParent.Content[newIdx:], entry)
+		} else {
+			newParent.Content = append(newParent.Content, entry...)
+		}
+	}
+}
+
+// entryIndex returns the index of the key of the entry of the given key in
+// the given mapping, or -1 if there's none.
+func entryIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemapatcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateActionRE matches the actions of Go templates (as in Helm charts),
+// e.g. "{{ .Values.name }}" or "{{- if .Values.crds.install }}".
+var templateActionRE = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// templatePlaceholderPrefix starts the placeholders of template actions.
+const templatePlaceholderPrefix = "__schemapatch_template_"
+
+// templateActions are the template actions of a manifest file, replaced by
+// placeholders so that it can be parsed as YAML and patched in place: the
+// lines holding only actions (e.g. "{{- if ... }}" or "{{- include ... }}")
+// are replaced by comments, and the other actions (e.g. in values) by plain
+// scalars, which are all opaque to patching.
+type templateActions struct {
+	// replacer replaces the placeholders with the original actions.
+	replacer *strings.Replacer
+}
+
+// maskTemplateActions replaces the template actions of the given manifest
+// file with placeholders, returning the masked file along with its actions,
+// or nil if it has none.
+func maskTemplateActions(rawContent []byte) ([]byte, *templateActions, error) {
+	if !bytes.Contains(rawContent, []byte("{{")) {
+		return rawContent, nil, nil
+	}
+	if bytes.Contains(rawContent, []byte(templatePlaceholderPrefix)) {
+		return nil, nil, fmt.Errorf("template already contains %q", templatePlaceholderPrefix)
+	}
+
+	// pairs of placeholders and original text, for a strings.Replacer
+	var originals []string
+	placeholder := func(prefix, original string) string {
+		token := fmt.Sprintf("%s%s%d__", prefix, templatePlaceholderPrefix, len(originals)/2)
+		originals = append(originals, token, original)
+		return token
+	}
+
+	lines := strings.SplitAfter(string(rawContent), "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "{{") {
+			continue
+		}
+		content := strings.TrimRight(line, "\r\n")
+		lineBreak := line[len(content):]
+		if strings.TrimSpace(templateActionRE.ReplaceAllString(content, "")) == "" {
+			// a line of actions only (e.g. control structures), made a comment
+			indent := content[:len(content)-len(strings.TrimLeft(content, " \t"))]
+			lines[i] = indent + placeholder("#", content[len(indent):]) + lineBreak
+			continue
+		}
+		masked := templateActionRE.ReplaceAllStringFunc(content, func(action string) string {
+			return placeholder("", action)
+		})
+		if strings.Contains(masked, "{{") {
+			return nil, nil, errors.New("template actions spanning several lines aren't supported")
+		}
+		lines[i] = masked + lineBreak
+	}
+	return []byte(strings.Join(lines, "")), &templateActions{replacer: strings.NewReplacer(originals...)}, nil
+}
+
+// restore replaces the placeholders in the given text with the original
+// template actions.
+func (t *templateActions) restore(text string) string {
+	if t == nil {
+		return text
+	}
+	return t.replacer.Replace(text)
+}
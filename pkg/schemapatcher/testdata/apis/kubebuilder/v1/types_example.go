@@ -21,6 +21,8 @@ import (
 )
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:metadata:labels="app=example"
 
 // Example is a kind with schema changes.
 type Example struct {
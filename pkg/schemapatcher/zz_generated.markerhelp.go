@@ -29,21 +29,49 @@ func (Generator) Help() *markers.DefinitionHelp {
 		Category: "",
 		DetailedHelp: markers.DetailedHelp{
 			Summary: "patches existing CRDs with new schemata.",
-			Details: "It will generate output for each \"CRD Version\" (API version of the CRD type\nitself) , e.g. apiextensions/v1) available.",
+			Details: "It will generate output for each \"CRD Version\" (API version of the CRD type\nitself) , e.g. apiextensions/v1) available.\n\nOnly the schemata are replaced in the text of the manifests (along with the\nconversion, metadata and subresources, if asked for), leaving the rest of\nthe files (comments, formatting, anchors, other documents) as written.",
 		},
 		FieldHelp: map[string]markers.DetailedHelp{
 			"ManifestsPath": {
 				Summary: "contains the CustomResourceDefinition YAML files.",
-				Details: "",
+				Details: "JSON files are patched as well, and written out as JSON, indented as\nthey were.  So are templates (e.g. of Helm charts), whose actions are\nleft as they are, as long as they don't span several lines.\n\nIf it holds a kustomization, the files it refers to (resources, bases,\nCRDs and patches, following the kustomizations of the directories it\nrefers to) are patched instead, wherever they are.",
 			},
 			"MaxDescLen": {
 				Summary: "specifies the maximum description length for fields in CRD's OpenAPI schema.",
 				Details: "0 indicates drop the description for all fields completely.\nn indicates limit the description to at most n characters and truncate the description to\nclosest sentence boundary if it exceeds n characters.",
 			},
+			"Versions": {
+				Summary: "are the versions whose schemata are updated, e.g. to leave the",
+				Details: "schemata of older versions as they are, when they're frozen.\n\nAll the versions are updated if empty.",
+			},
+			"Protected": {
+				Summary: "are the paths of the parts of the schemata that are manually",
+				Details: "maintained (e.g. CEL rules or descriptions that can't be generated),\nwhich are kept as they are while the rest is updated.\n\nA path is a dotted list of fields (with \"[]\" for the items of lists),\nprotecting their whole schema, optionally followed by a slash and a\nkeyword of their schema, protecting that keyword only, and optionally\npreceded by a kind and a colon, applying to that kind only, e.g.\n\"spec.rules[]\" or \"Example:spec/x-kubernetes-validations\".\n\nThe entries of the schemata marked with a \"+schemapatch:protected\"\ncomment in the manifests are protected as well.",
+			},
 			"GenerateEmbeddedObjectMeta": {
 				Summary: "specifies if any embedded ObjectMeta in the CRD should be generated",
 				Details: "",
 			},
+			"Conversion": {
+				Summary: "specifies if the conversion of the CRDs should be replaced",
+				Details: "with the generated one (removing it if none is generated).",
+			},
+			"Annotations": {
+				Summary: "specifies if the generated annotations of the CRDs should be",
+				Details: "added (or updated), keeping the others.",
+			},
+			"Labels": {
+				Summary: "specifies if the generated labels of the CRDs should be added (or",
+				Details: "updated), keeping the others.",
+			},
+			"Subresources": {
+				Summary: "specifies if the subresources of the updated versions",
+				Details: "should be replaced with the generated ones (removing them if none are\ngenerated).",
+			},
+			"Verify": {
+				Summary: "reports the manifests that are out of date, along with the diffs",
+				Details: "of their changed entries (e.g. the schema of each version), instead of\nwriting them, failing if there are any (e.g. for CI checks).",
+			},
 		},
 	}
 }
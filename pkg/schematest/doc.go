@@ -0,0 +1,27 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schematest generates tests validating the sample custom resources
+// of a project against the schemas of their CRDs, so that changes to the API
+// types that break the samples fail go test, without a cluster:
+//
+//	--- FAIL: TestSampleSchemas/valid/apps_v1_widget.yaml (0.00s)
+//	    zz_generated.schema_test.go:184: Widget widget-sample is invalid: spec.count: Forbidden: unknown field, which would be pruned
+//
+// The samples of config/samples (e.g. as generated by the samples generator)
+// must be valid, and those of config/samples/invalid must be invalid, so that
+// validation markers can be covered by samples, too.
+package schematest
@@ -0,0 +1,225 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	// outputFileName is the name of the generated file in each package.
+	outputFileName = "zz_generated.schema_test.go"
+	// defaultSamples is the default directory of the valid samples.
+	defaultSamples = "config/samples"
+	// defaultInvalidSamples is the default directory of the invalid samples.
+	defaultInvalidSamples = "config/samples/invalid"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates tests validating sample custom resources against the
+// schemas of the CRDs of their kinds.
+//
+// The test of each package is written to zz_generated.schema_test.go, with
+// the schemas of the versions of the kinds of the package.  It checks that
+// the samples of these kinds (in the YAML files of the samples directories)
+// pass the OpenAPI validation of the API server, and have no unknown fields,
+// which would be pruned.  CEL validation rules aren't checked.
+type Generator struct {
+	// Samples specifies the directory of the samples that must be valid.
+	// Defaults to config/samples.
+	Samples string `marker:",optional"`
+	// InvalidSamples specifies the directory of the samples that must be
+	// invalid.  Defaults to config/samples/invalid.
+	InvalidSamples string `marker:",optional"`
+
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+// kindSchema is the schema of a version of a kind.
+type kindSchema struct {
+	apiVersion, kind string
+	schema           []byte
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	samples, err := filepath.Abs(defaultIfEmpty(g.Samples, defaultSamples))
+	if err != nil {
+		return err
+	}
+	invalidSamples, err := filepath.Abs(defaultIfEmpty(g.InvalidSamples, defaultInvalidSamples))
+	if err != nil {
+		return err
+	}
+
+	// descriptions aren't needed for validation
+	noDescriptions := 0
+	schemasByPkg := make(map[*loader.Package][]kindSchema)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(groupKind, &noDescriptions)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, root := range ctx.Roots {
+			gv := parser.GroupVersions[root]
+			if gv.Group != groupKind.Group || parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}] == nil {
+				continue
+			}
+			for _, version := range crdRaw.Spec.Versions {
+				if version.Name != gv.Version || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+					continue
+				}
+				schemaJSON, err := json.MarshalIndent(version.Schema.OpenAPIV3Schema, "", "  ")
+				if err != nil {
+					root.AddError(err)
+					continue
+				}
+				schemasByPkg[root] = append(schemasByPkg[root], kindSchema{
+					apiVersion: gv.String(),
+					kind:       groupKind.Kind,
+					schema:     schemaJSON,
+				})
+			}
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		schemas := schemasByPkg[root]
+		if len(schemas) == 0 {
+			continue
+		}
+		slices.SortFunc(schemas, func(a, b kindSchema) int {
+			return strings.Compare(a.kind, b.kind)
+		})
+		outContents, err := generateForPackage(root, schemas, samples, invalidSamples, headerText)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, outContents)
+	}
+	return nil
+}
+
+// defaultIfEmpty returns the given value, or the given default if it's empty.
+func defaultIfEmpty(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// generateForPackage generates the test of the samples of the kinds with the
+// given schemas of the given package.
+func generateForPackage(root *loader.Package, schemas []kindSchema, samples, invalidSamples, headerText string) ([]byte, error) {
+	samplesRel, err := filepath.Rel(root.Dir, samples)
+	if err != nil {
+		return nil, err
+	}
+	invalidSamplesRel, err := filepath.Rel(root.Dir, invalidSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s_test
+
+%[3]s
+
+// sampleSchemas are the schemas of the versions of the kinds of this
+// package, by apiVersion and kind.
+var sampleSchemas = map[sampleKind]string{
+`, root.Name, headerText, testPrelude)
+	for _, schema := range schemas {
+		fmt.Fprintf(outContent, "{apiVersion: %q, kind: %q}: %s,\n", schema.apiVersion, schema.kind, stringLiteral(string(schema.schema)))
+	}
+	outContent.WriteString("}\n\n")
+	fmt.Fprintf(outContent, `const (
+	// samplesDir is the directory of the samples that must be valid.
+	samplesDir = %q
+	// invalidSamplesDir is the directory of the samples that must be invalid.
+	invalidSamplesDir = %q
+)
+
+`, filepath.ToSlash(samplesRel), filepath.ToSlash(invalidSamplesRel))
+	outContent.WriteString(testFuncs)
+
+	return gogen.Format(root, outContent.Bytes()), nil
+}
+
+// stringLiteral returns the given string as a raw string literal, if
+// possible.
+func stringLiteral(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
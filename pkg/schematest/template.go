@@ -0,0 +1,152 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematest
+
+// testPrelude is the start of the generated tests, after the package clause.
+const testPrelude = `import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// sampleKind is a version of a kind.
+type sampleKind struct {
+	apiVersion, kind string
+}`
+
+// testFuncs are the functions of the generated tests, after the schemas and
+// the directories of the samples.
+const testFuncs = `// TestSampleSchemas checks that the samples of the kinds of this package are
+// valid, or invalid, according to the schemas of their CRDs.
+func TestSampleSchemas(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		dir   string
+		valid bool
+	}{
+		{name: "valid", dir: samplesDir, valid: true},
+		{name: "invalid", dir: invalidSamplesDir, valid: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := filepath.Glob(filepath.Join(tc.dir, "*.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range files {
+				t.Run(filepath.Base(file), func(t *testing.T) {
+					objs, err := readSamples(file)
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, obj := range objs {
+						apiVersion, _ := obj["apiVersion"].(string)
+						kind, _ := obj["kind"].(string)
+						schema, known := sampleSchemas[sampleKind{apiVersion: apiVersion, kind: kind}]
+						if !known {
+							// a sample of another package
+							continue
+						}
+						metadata, _ := obj["metadata"].(map[string]any)
+						name, _ := metadata["name"].(string)
+
+						errs, err := validateSample(obj, schema)
+						if err != nil {
+							t.Fatal(err)
+						}
+						switch {
+						case tc.valid && len(errs) > 0:
+							t.Errorf("%s %s is invalid: %v", kind, name, errs.ToAggregate())
+						case !tc.valid && len(errs) == 0:
+							t.Errorf("%s %s is valid, but should be invalid", kind, name)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+// readSamples reads the objects of the given YAML file.
+func readSamples(file string) ([]map[string]any, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objs []map[string]any
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var obj map[string]any
+		if err := utilyaml.Unmarshal(doc, &obj); err != nil {
+			return nil, err
+		}
+		if len(obj) > 0 {
+			objs = append(objs, obj)
+		}
+	}
+}
+
+// validateSample validates the given object against the given schema, like
+// the API server does, also reporting the unknown fields it would prune.
+func validateSample(obj map[string]any, schemaJSON string) (field.ErrorList, error) {
+	var v1Schema apiextensionsv1.JSONSchemaProps
+	if err := json.Unmarshal([]byte(schemaJSON), &v1Schema); err != nil {
+		return nil, err
+	}
+	var schema apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&v1Schema, &schema, nil); err != nil {
+		return nil, err
+	}
+
+	validator, _, err := validation.NewSchemaValidator(&schema)
+	if err != nil {
+		return nil, err
+	}
+	errs := validation.ValidateCustomResource(nil, obj, validator)
+
+	structural, err := structuralschema.NewStructural(&schema)
+	if err != nil {
+		return nil, err
+	}
+	pruneOpts := structuralschema.UnknownFieldPathOptions{TrackUnknownFieldPaths: true}
+	for _, path := range pruning.PruneWithOptions(runtime.DeepCopyJSON(obj), structural, true, pruneOpts) {
+		errs = append(errs, field.Forbidden(field.NewPath(path), "unknown field, which would be pruned"))
+	}
+	return errs, nil
+}
+`
@@ -0,0 +1,34 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ssamigration generates helpers migrating objects managed with
+// client-side apply, or updates, to server-side apply.
+//
+// Controllers adopting the generated apply configurations must move the
+// ownership of the fields they set with Update (or that kubectl set with
+// client-side apply) to their server-side apply field manager, or the fields
+// removed from later apply configurations are left behind, still owned by
+// the old managers.  The generated helpers wrap k8s.io/client-go's csaupgrade
+// package for each kind:
+//
+//	patch, err := v1.WidgetManagedFieldsUpgradePatch(widget, "widget-controller", "manager", "kubectl-client-side-apply")
+//	if err != nil {
+//		return err
+//	}
+//	if patch != nil {
+//		err = c.Patch(ctx, widget, client.RawPatch(types.JSONPatchType, patch))
+//	}
+package ssamigration
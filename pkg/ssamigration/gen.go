@@ -0,0 +1,145 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssamigration
+
+import (
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// NB: markers.LoadRoots ignores autogenerated code via a build tag, so any
+// time we check for existing declarations, we only see hand-written ones.
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.ssamigration.go"
+
+// +controllertools:marker:generateHelp
+
+// Generator generates helpers migrating objects of kinds from client-side
+// apply, or updates, to server-side apply.
+//
+// The helpers of the kinds of each package are written to
+// zz_generated.ssamigration.go, as an Upgrade<Kind>ManagedFields function,
+// moving the ownership of the fields of an object from the given client-side
+// apply (or Update) field managers to the given server-side apply field
+// manager, and a <Kind>ManagedFieldsUpgradePatch function returning the JSON
+// patch doing so on the server, guarded by the resource version of the
+// object.  Kinds with a status subresource get the same helpers for their
+// status, named Upgrade<Kind>StatusManagedFields and
+// <Kind>StatusManagedFieldsUpgradePatch.  Kinds for which any of these names
+// already exist are skipped.
+//
+// The helpers use k8s.io/client-go/util/csaupgrade, which the API packages'
+// module must require, and need the kinds to implement runtime.Object (see
+// the object generator).
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+// migratedKind is a kind whose objects can be migrated.
+type migratedKind struct {
+	name string
+	// hasStatus indicates that the kind has a status subresource.
+	hasStatus bool
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// the helpers shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	// descriptions aren't needed to find subresources
+	noDescriptions := 0
+	kindsByPkg := make(map[*loader.Package][]migratedKind)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		parser.NeedCRDFor(groupKind, &noDescriptions)
+		crdRaw := parser.CustomResourceDefinitions[groupKind]
+		for _, root := range ctx.Roots {
+			gv := parser.GroupVersions[root]
+			if gv.Group != groupKind.Group {
+				continue
+			}
+			if parser.Types[crd.TypeIdent{Package: root, Name: groupKind.Kind}] == nil {
+				continue
+			}
+			kind := migratedKind{name: groupKind.Kind}
+			for _, version := range crdRaw.Spec.Versions {
+				if version.Name == gv.Version && version.Subresources != nil && version.Subresources.Status != nil {
+					kind.hasStatus = true
+				}
+			}
+			kindsByPkg[root] = append(kindsByPkg[root], kind)
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		kinds := kindsByPkg[root]
+		if len(kinds) == 0 {
+			continue
+		}
+		slices.SortFunc(kinds, func(a, b migratedKind) int {
+			return strings.Compare(a.name, b.name)
+		})
+
+		file := &migrationFile{pkg: root}
+		for _, kind := range kinds {
+			file.generateHelpers(kind)
+		}
+		if outContents := file.contents(headerText); outContents != nil {
+			gogen.WriteOut(ctx, root, outputFileName, outContents)
+		}
+	}
+	return nil
+}
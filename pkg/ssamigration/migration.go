@@ -0,0 +1,128 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssamigration
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// migrationFile collects the generated migration helpers of a single
+// package.
+type migrationFile struct {
+	pkg *loader.Package
+	out bytes.Buffer
+}
+
+// migrationTarget is a part of objects migrated by helpers.
+type migrationTarget struct {
+	// name is the name of the part in the names of the helpers.
+	name string
+	// description describes the part in the comments of the helpers.
+	description string
+	// subresource is the subresource of the part, if any.
+	subresource string
+}
+
+// generateHelpers generates the migration helpers of the given kind, unless
+// any of their names already exist.
+func (f *migrationFile) generateHelpers(kind migratedKind) {
+	targets := []migrationTarget{{name: kind.name, description: "the given " + kind.name}}
+	if kind.hasStatus {
+		targets = append(targets, migrationTarget{
+			name:        kind.name + "Status",
+			description: "the status subresource of the given " + kind.name,
+			subresource: "status",
+		})
+	}
+
+	scope := f.pkg.Types.Scope()
+	for _, target := range targets {
+		for _, name := range []string{"Upgrade" + target.name + "ManagedFields", target.name + "ManagedFieldsUpgradePatch"} {
+			if scope.Lookup(name) != nil {
+				return
+			}
+		}
+	}
+
+	for _, target := range targets {
+		upgrade, upgradePatch := "Upgrade"+target.name+"ManagedFields", target.name+"ManagedFieldsUpgradePatch"
+		var options, sentTo string
+		if target.subresource != "" {
+			options = fmt.Sprintf(", csaupgrade.Subresource(%q)", target.subresource)
+			sentTo = fmt.Sprintf(" to the %s subresource", target.subresource)
+		}
+
+		fmt.Fprintf(&f.out, "\n%s\n", comment(fmt.Sprintf("%s moves the ownership of the fields of %s managed by the given client-side apply or Update field managers (e.g. kubectl-client-side-apply) to the given server-side apply field manager, so that they can be managed with apply configurations.  It only changes the managed fields of the object in memory, see csaupgrade.UpgradeManagedFields.", upgrade, target.description)))
+		fmt.Fprintf(&f.out, `func %[1]s(obj *%[2]s, ssaManager string, csaManagers ...string) error {
+	return csaupgrade.UpgradeManagedFields(obj, sets.New(csaManagers...), ssaManager%[3]s)
+}
+`, upgrade, kind.name, options)
+
+		fmt.Fprintf(&f.out, "\n%s\n", comment(fmt.Sprintf("%s returns the JSON patch upgrading the managed fields of %s like %s does, to be sent%s with the JSON patch type, or nil if there's nothing to upgrade.  The patch fails with a conflict if the %s changed since it was read.", upgradePatch, target.description, upgrade, sentTo, kind.name)))
+		fmt.Fprintf(&f.out, `func %[1]s(obj *%[2]s, ssaManager string, csaManagers ...string) ([]byte, error) {
+	return csaupgrade.UpgradeManagedFieldsPatch(obj, sets.New(csaManagers...), ssaManager%[3]s)
+}
+`, upgradePatch, kind.name, options)
+	}
+}
+
+// contents returns the formatted contents of the file, or nil if it has no
+// helpers.
+func (f *migrationFile) contents(headerText string) []byte {
+	if f.out.Len() == 0 {
+		return nil
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+)
+`, f.pkg.Name, headerText)
+	outContent.Write(f.out.Bytes())
+
+	return gogen.Format(f.pkg, outContent.Bytes())
+}
+
+// comment returns the given text as a comment, wrapped at 80 columns.
+func comment(text string) string {
+	var out strings.Builder
+	line := "//"
+	for word := range strings.FieldsSeq(text) {
+		if len(line)+1+len(word) > 80 && line != "//" {
+			out.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	out.WriteString(line)
+	return out.String()
+}
@@ -0,0 +1,25 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package storageversion generates StorageVersionMigration objects for the
+// kinds whose storage version changed since the previous release.
+//
+// The storage versions of the kinds (as set by the
+// +kubebuilder:storageversion marker) are compared against the ones of the
+// CRD manifests of the previous release, so that the objects stored in the
+// previous storage version are migrated on upgrades, rather than being left
+// stale, blocking the removal of that version.
+package storageversion
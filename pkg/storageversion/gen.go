@@ -0,0 +1,231 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversion
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	storagemigrationv1beta1 "k8s.io/api/storagemigration/v1beta1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	kyaml "sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates StorageVersionMigration objects for the kinds whose
+// storage version changed since the previous release.
+//
+// The migrations are written to storageversionmigrations.yaml, to be applied
+// once the upgraded CRDs are, and named <plural>.<group>-<storage version>.
+// Kinds whose previous storage version isn't served anymore are reported as
+// errors, since their stored objects couldn't be read to be migrated.
+type Generator struct {
+	// Previous is the directory of the CRD manifests of the previous release.
+	Previous string
+	// FileName is the name of the generated file (defaults to storageversionmigrations.yaml).
+	FileName string `marker:",optional"`
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	previousCRDs, err := crdsFromDirectory(ctx, g.Previous)
+	if err != nil {
+		return err
+	}
+
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// migrations shouldn't fail on types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	var migrations []any
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		previousCRD, released := previousCRDs[groupKind]
+		if !released {
+			// new kinds have nothing to migrate
+			continue
+		}
+		parser.NeedCRDFor(groupKind, nil)
+		crdRaw, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+
+		previousVersion := storageVersion(previousCRD)
+		currentVersion := storageVersion(&crdRaw)
+		if previousVersion == "" || currentVersion == "" || previousVersion == currentVersion {
+			continue
+		}
+		if !slices.ContainsFunc(crdRaw.Spec.Versions, func(version apiextensionsv1.CustomResourceDefinitionVersion) bool {
+			return version.Name == previousVersion && version.Served
+		}) {
+			kindPackage(ctx.Roots, parser, schema.GroupVersion{Group: groupKind.Group, Version: currentVersion}, groupKind.Kind).AddError(
+				fmt.Errorf("version %s of %s, stored by the previous release, must still be served so that its objects are migrated to %s", previousVersion, crdRaw.Name, currentVersion))
+			continue
+		}
+
+		migrations = append(migrations, storagemigrationv1beta1.StorageVersionMigration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: storagemigrationv1beta1.SchemeGroupVersion.String(),
+				Kind:       "StorageVersionMigration",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: crdRaw.Name + "-" + currentVersion,
+			},
+			Spec: storagemigrationv1beta1.StorageVersionMigrationSpec{
+				Resource: metav1.GroupResource{Group: groupKind.Group, Resource: crdRaw.Spec.Names.Plural},
+			},
+		})
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	slices.SortFunc(migrations, func(a, b any) int {
+		return strings.Compare(a.(storagemigrationv1beta1.StorageVersionMigration).Name, b.(storagemigrationv1beta1.StorageVersionMigration).Name)
+	})
+
+	fileName := g.FileName
+	if fileName == "" {
+		fileName = "storageversionmigrations.yaml"
+	}
+	return ctx.WriteYAML(fileName, headerText, migrations, genall.WithTransform(removeStatus))
+}
+
+// removeStatus removes the (empty) status and creation timestamp of
+// migrations.
+func removeStatus(obj map[string]any) error {
+	delete(obj, "status")
+	return genall.TransformRemoveCreationTimestamp(obj)
+}
+
+// storageVersion returns the storage version of the given CRD, if any.
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+// kindPackage returns the package of the given version of the given kind,
+// to report errors on.
+func kindPackage(roots []*loader.Package, parser *crd.Parser, gv schema.GroupVersion, kind string) *loader.Package {
+	for _, root := range roots {
+		if parser.GroupVersions[root] == gv && parser.Types[crd.TypeIdent{Package: root, Name: kind}] != nil {
+			return root
+		}
+	}
+	return roots[0]
+}
+
+// crdsFromDirectory loads the CRDs of the YAML files of the given directory,
+// by group-kind.  Files may hold several documents (e.g. a release bundle),
+// of which documents other than CRDs are ignored.
+func crdsFromDirectory(ctx *genall.GenerationContext, dir string) (map[schema.GroupKind]*apiextensionsv1.CustomResourceDefinition, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[schema.GroupKind]*apiextensionsv1.CustomResourceDefinition)
+	for _, fileInfo := range dirEntries {
+		if fileInfo.IsDir() || (filepath.Ext(fileInfo.Name()) != ".yaml" && filepath.Ext(fileInfo.Name()) != ".yml") {
+			continue
+		}
+		rawContent, err := ctx.ReadFile(filepath.Join(dir, fileInfo.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(rawContent)))
+		for {
+			document, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("load %q: %w", filepath.Join(dir, fileInfo.Name()), err)
+			}
+
+			var typeMeta metav1.TypeMeta
+			if err := kyaml.Unmarshal(document, &typeMeta); err != nil {
+				continue
+			}
+			if typeMeta.APIVersion != apiextensionsv1.SchemeGroupVersion.String() || typeMeta.Kind != "CustomResourceDefinition" {
+				continue
+			}
+
+			previousCRD := &apiextensionsv1.CustomResourceDefinition{}
+			if err := kyaml.Unmarshal(document, previousCRD); err != nil {
+				return nil, fmt.Errorf("load %q: %w", filepath.Join(dir, fileInfo.Name()), err)
+			}
+			res[schema.GroupKind{Group: previousCRD.Spec.Group, Kind: previousCRD.Spec.Names.Kind}] = previousCRD
+		}
+	}
+	return res, nil
+}
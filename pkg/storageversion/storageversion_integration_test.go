@@ -0,0 +1,45 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversion_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/golden"
+	"sigs.k8s.io/controller-tools/pkg/storageversion"
+)
+
+var _ = Describe("StorageVersion Generation", func() {
+	previous := filepath.Join("testdata", "previous")
+
+	It("should generate migrations for the kinds whose storage version changed", func() {
+		out := golden.Generate(GinkgoT(), "testdata", []genall.Generator{storageversion.Generator{Previous: previous}}, "./api/...")
+		golden.Compare(GinkgoT(), filepath.Join("testdata", "config"), out)
+	})
+
+	DescribeTable("should fail on invalid packages and previous releases",
+		func(gen storageversion.Generator, path, message string) {
+			errOut := golden.GenerateErrors(GinkgoT(), "testdata", []genall.Generator{gen}, path)
+			Expect(errOut).To(ContainSubstring(message))
+		},
+		Entry("with kinds whose previous storage version is not served anymore", storageversion.Generator{Previous: previous}, "./invalid/...", "version v1 of things.removed.testdata.kubebuilder.io, stored by the previous release, must still be served"),
+		Entry("with missing previous releases", storageversion.Generator{Previous: "missing"}, "./api/...", "missing: no such file or directory"),
+	)
+})
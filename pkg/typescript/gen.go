@@ -0,0 +1,222 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typescript
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// +controllertools:marker:generateHelp
+
+// Generator generates TypeScript type definitions for the kinds of each
+// group-version.
+//
+// Each group-version is written to <group>_<version>.d.ts, with an interface
+// per kind and per struct type used by the kinds, and a type alias per other
+// type (e.g. a union of the values of an enum).  Types of other packages are
+// inlined, apart from ObjectMeta.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crd.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdmarkers.Register(into)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	parser := &crd.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+		// TypeScript has no issue with the types the CRDs disallow
+		AllowDangerousTypes: true,
+	}
+	crd.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+
+	metav1Pkg := crd.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	files := make(map[*loader.Package]*tsFile)
+	for _, groupKind := range crd.FindKubeKinds(parser, metav1Pkg) {
+		for _, root := range ctx.Roots {
+			gv, known := parser.GroupVersions[root]
+			ident := crd.TypeIdent{Package: root, Name: groupKind.Kind}
+			if !known || gv.Group != groupKind.Group || parser.Types[ident] == nil {
+				continue
+			}
+			file, exists := files[root]
+			if !exists {
+				file = newTSFile(parser, root, gv)
+				files[root] = file
+			}
+			file.kinds[groupKind.Kind] = struct{}{}
+			file.needType(groupKind.Kind)
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		file, exists := files[root]
+		if !exists {
+			continue
+		}
+		file.writeTypes()
+		fileName := fmt.Sprintf("%s_%s.d.ts", file.gv.Group, file.gv.Version)
+		if err := writeOut(ctx, fileName, file.contents(headerText)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOut writes the given contents to the given file.
+func writeOut(ctx *genall.GenerationContext, fileName string, outBytes []byte) error {
+	out, err := ctx.Open(nil, fileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	n, err := out.Write(outBytes)
+	if err != nil {
+		return err
+	}
+	if n < len(outBytes) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// tsFile collects the type definitions of a group-version.
+type tsFile struct {
+	parser *crd.Parser
+	pkg    *loader.Package
+	gv     schema.GroupVersion
+
+	// kinds are the names of the kinds of the package.
+	kinds map[string]struct{}
+	// decls are the written declarations, by type name.
+	decls map[string]string
+	// queue are the names of the types still to be written.
+	queue []string
+	// needsObjectMeta indicates that the ObjectMeta interface is used.
+	needsObjectMeta bool
+}
+
+func newTSFile(parser *crd.Parser, pkg *loader.Package, gv schema.GroupVersion) *tsFile {
+	return &tsFile{
+		parser: parser,
+		pkg:    pkg,
+		gv:     gv,
+		kinds:  make(map[string]struct{}),
+		decls:  make(map[string]string),
+	}
+}
+
+// needType marks that the declaration of the given type of the package is
+// needed.
+func (f *tsFile) needType(name string) {
+	if _, written := f.decls[name]; written || slices.Contains(f.queue, name) {
+		return
+	}
+	f.queue = append(f.queue, name)
+}
+
+// writeTypes writes the declarations of the needed types, and of the types
+// they use.
+func (f *tsFile) writeTypes() {
+	for len(f.queue) > 0 {
+		name := f.queue[0]
+		f.queue = f.queue[1:]
+		if _, written := f.decls[name]; written {
+			continue
+		}
+		// mark the type as written first, in case of recursive types
+		f.decls[name] = ""
+		f.decls[name] = f.declaration(name)
+	}
+}
+
+// contents returns the contents of the file.
+func (f *tsFile) contents(headerText string) []byte {
+	out := new(bytes.Buffer)
+	for _, line := range strings.Split(strings.TrimSpace(headerText), "\n") {
+		switch {
+		case strings.HasPrefix(line, "/*"), strings.HasPrefix(line, "*/"):
+			// written as line comments, like the rest of the header
+		case line == "" && out.Len() == 0:
+		case line == "":
+			out.WriteString("//\n")
+		default:
+			fmt.Fprintf(out, "// %s\n", line)
+		}
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("// Code generated by controller-gen. DO NOT EDIT.\n")
+
+	names := make([]string, 0, len(f.decls))
+	for name := range f.decls {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if f.needsObjectMeta {
+		out.WriteString("\n")
+		out.WriteString(objectMetaDecl)
+	}
+	for _, name := range names {
+		out.WriteString("\n")
+		out.WriteString(f.decls[name])
+	}
+	return out.Bytes()
+}
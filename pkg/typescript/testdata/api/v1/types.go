@@ -0,0 +1,114 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Protocol is a network protocol.
+// +kubebuilder:validation:Enum=TCP;UDP
+type Protocol string
+
+// Port is a network port.
+type Port struct {
+	// Name is the name of the port.
+	Name string `json:"name"`
+
+	// Number is the number of the port.
+	Number int32 `json:"number"`
+
+	// Protocol is the protocol of the port.
+	// +optional
+	Protocol Protocol `json:"protocol,omitempty"`
+}
+
+// CommonSpec holds the fields shared by specs.
+type CommonSpec struct {
+	// Paused stops the reconciliation of the object.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// WidgetSpec is the spec of a Widget.
+type WidgetSpec struct {
+	CommonSpec `json:",inline"`
+
+	// Image is the image of the widget.
+	//
+	// It must not contain */ sequences.
+	Image string `json:"image"`
+
+	// +kubebuilder:validation:Enum=1;2;3
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// +kubebuilder:validation:Enum=Low;High
+	// +nullable
+	// +optional
+	Priority *string `json:"priority,omitempty"`
+
+	// +kubebuilder:validation:items:Enum=a;b
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// +optional
+	Ports []Port `json:"ports,omitempty"`
+
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +optional
+	Target *intstr.IntOrString `json:"target,omitempty"`
+
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+
+	// +optional
+	Count int64 `json:"count-limit,omitempty"`
+}
+
+// WidgetStatus is the status of a Widget.
+type WidgetStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Widget is a kind with TypeScript type definitions.
+// +kubebuilder:object:root=true
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WidgetSpec   `json:"spec,omitempty"`
+	Status WidgetStatus `json:"status,omitempty"`
+}
+
+// WidgetList contains a list of Widgets.
+// +kubebuilder:object:root=true
+type WidgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Widget `json:"items"`
+}
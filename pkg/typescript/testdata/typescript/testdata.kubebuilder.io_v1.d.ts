@@ -0,0 +1,135 @@
+// Copyright The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+/** ObjectMeta is the metadata of an object. */
+export interface ObjectMeta {
+  annotations?: { [key: string]: string };
+  creationTimestamp?: string;
+  deletionTimestamp?: string;
+  finalizers?: string[];
+  generateName?: string;
+  generation?: number;
+  labels?: { [key: string]: string };
+  name?: string;
+  namespace?: string;
+  resourceVersion?: string;
+  uid?: string;
+}
+
+/** CommonSpec holds the fields shared by specs. */
+export interface CommonSpec {
+  /** Paused stops the reconciliation of the object. */
+  paused?: boolean;
+}
+
+/** Port is a network port. */
+export interface Port {
+  /** Name is the name of the port. */
+  name: string;
+  /** Number is the number of the port. */
+  number: number;
+  /** Protocol is the protocol of the port. */
+  protocol?: Protocol;
+}
+
+/** Protocol is a network protocol. */
+export type Protocol = "TCP" | "UDP";
+
+/** Widget is a kind with TypeScript type definitions. */
+export interface Widget {
+  apiVersion: "testdata.kubebuilder.io/v1";
+  kind: "Widget";
+  metadata?: ObjectMeta;
+  spec?: WidgetSpec;
+  status?: WidgetStatus;
+}
+
+/** WidgetSpec is the spec of a Widget. */
+export interface WidgetSpec extends CommonSpec {
+  config?: { [key: string]: unknown };
+  "count-limit"?: number;
+  /**
+   * Image is the image of the widget.
+   *
+   * It must not contain *\/ sequences.
+   */
+  image: string;
+  labels?: { [key: string]: string };
+  ports?: Port[];
+  priority?: "Low" | "High" | null;
+  replicas?: 1 | 2 | 3;
+  selector?: {
+    /** matchExpressions is a list of label selector requirements. The requirements are ANDed. */
+    matchExpressions?: {
+      /** key is the label key that the selector applies to. */
+      key: string;
+      /**
+       * operator represents a key's relationship to a set of values.
+       * Valid operators are In, NotIn, Exists and DoesNotExist.
+       */
+      operator: string;
+      /**
+       * values is an array of string values. If the operator is In or NotIn,
+       * the values array must be non-empty. If the operator is Exists or DoesNotExist,
+       * the values array must be empty. This array is replaced during a strategic
+       * merge patch.
+       */
+      values?: string[];
+    }[];
+    /**
+     * matchLabels is a map of {key,value} pairs. A single {key,value} in the matchLabels
+     * map is equivalent to an element of matchExpressions, whose key field is "key", the
+     * operator is "In", and the values array contains only "value". The requirements are ANDed.
+     */
+    matchLabels?: { [key: string]: string };
+  };
+  tags?: ("a" | "b")[];
+  target?: number | string;
+}
+
+/** WidgetStatus is the status of a Widget. */
+export interface WidgetStatus {
+  conditions?: {
+    /**
+     * lastTransitionTime is the last time the condition transitioned from one status to another.
+     * This should be when the underlying condition changed.  If that is not known, then using the time when the API field changed is acceptable.
+     */
+    lastTransitionTime: string;
+    /**
+     * message is a human readable message indicating details about the transition.
+     * This may be an empty string.
+     */
+    message: string;
+    /**
+     * observedGeneration represents the .metadata.generation that the condition was set based upon.
+     * For instance, if .metadata.generation is currently 12, but the .status.conditions[x].observedGeneration is 9, the condition is out of date
+     * with respect to the current state of the instance.
+     */
+    observedGeneration?: number;
+    /**
+     * reason contains a programmatic identifier indicating the reason for the condition's last transition.
+     * Producers of specific condition types may define expected values and meanings for this field,
+     * and whether the values are considered a guaranteed API.
+     * The value should be a CamelCase string.
+     * This field may not be empty.
+     */
+    reason: string;
+    /** status of the condition, one of True, False, Unknown. */
+    status: "True" | "False" | "Unknown";
+    /** type of condition in CamelCase or in foo.example.com/CamelCase. */
+    type: string;
+  }[];
+}
@@ -0,0 +1,280 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package typescript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+const metav1PkgPath = "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// objectMetaDecl is the declaration of the ObjectMeta interface, covering the
+// fields of metadata usually read or set by clients.
+const objectMetaDecl = `/** ObjectMeta is the metadata of an object. */
+export interface ObjectMeta {
+  annotations?: { [key: string]: string };
+  creationTimestamp?: string;
+  deletionTimestamp?: string;
+  finalizers?: string[];
+  generateName?: string;
+  generation?: number;
+  labels?: { [key: string]: string };
+  name?: string;
+  namespace?: string;
+  resourceVersion?: string;
+  uid?: string;
+}
+`
+
+// identifier matches the property names which don't need quoting.
+var identifier = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+
+// propertyName returns the TypeScript property name of the given field.
+func propertyName(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+	return literal([]byte(fmt.Sprintf("%q", name)))
+}
+
+// literal returns the TypeScript literal type of the given JSON value.
+func literal(raw []byte) string {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	out := new(bytes.Buffer)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return string(raw)
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// writeDoc writes the given description as a JSDoc comment, at the given
+// indentation.
+func writeDoc(out *bytes.Buffer, description, indent string) {
+	if description == "" {
+		return
+	}
+	description = strings.ReplaceAll(description, "*/", `*\/`)
+	lines := strings.Split(description, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(out, "%s/** %s */\n", indent, description)
+		return
+	}
+	fmt.Fprintf(out, "%s/**\n", indent)
+	for _, line := range lines {
+		if line == "" {
+			fmt.Fprintf(out, "%s *\n", indent)
+			continue
+		}
+		fmt.Fprintf(out, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(out, "%s */\n", indent)
+}
+
+// declaration returns the declaration of the given type of the package.
+func (f *tsFile) declaration(name string) string {
+	ident := crd.TypeIdent{Package: f.pkg, Name: name}
+	f.parser.NeedSchemaFor(ident)
+	typeSchema := f.parser.Schemata[ident]
+
+	out := new(bytes.Buffer)
+	writeDoc(out, typeSchema.Description, "")
+	if typeSchema.Type != "object" || (len(typeSchema.Properties) == 0 && len(typeSchema.AllOf) == 0) {
+		fmt.Fprintf(out, "export type %s = %s;\n", name, f.tsType(&typeSchema, ""))
+		return out.String()
+	}
+
+	var extends []string
+	members := new(bytes.Buffer)
+	if _, isKind := f.kinds[name]; isKind {
+		fmt.Fprintf(members, "  apiVersion: %q;\n", f.gv.String())
+		fmt.Fprintf(members, "  kind: %q;\n", name)
+	}
+	for _, embedded := range typeSchema.AllOf {
+		if embedded.Ref == nil {
+			f.writeMembers(members, &embedded, "  ")
+			continue
+		}
+		embeddedIdent, local := f.resolve(*embedded.Ref)
+		switch {
+		case local:
+			extends = append(extends, embeddedIdent.Name)
+			f.needType(embeddedIdent.Name)
+		case embeddedIdent.Package == nil:
+		case loader.NonVendorPath(embeddedIdent.Package.PkgPath) == metav1PkgPath && embeddedIdent.Name == "TypeMeta":
+			if _, isKind := f.kinds[name]; !isKind {
+				members.WriteString("  apiVersion?: string;\n  kind?: string;\n")
+			}
+		default:
+			// inline the fields of embedded types of other packages
+			f.parser.NeedFlattenedSchemaFor(embeddedIdent)
+			flattened := f.parser.FlattenedSchemata[embeddedIdent]
+			f.writeMembers(members, &flattened, "  ")
+		}
+	}
+	f.writeMembers(members, &typeSchema, "  ")
+
+	fmt.Fprintf(out, "export interface %s", name)
+	if len(extends) > 0 {
+		fmt.Fprintf(out, " extends %s", strings.Join(extends, ", "))
+	}
+	fmt.Fprintf(out, " {\n%s}\n", members.String())
+	return out.String()
+}
+
+// resolve returns the type referred to by the given reference, and whether
+// it's a type of the package.
+func (f *tsFile) resolve(ref string) (crd.TypeIdent, bool) {
+	typeName, pkgPath, err := crd.RefParts(ref)
+	if err != nil {
+		f.pkg.AddError(err)
+		return crd.TypeIdent{}, false
+	}
+	if pkgPath == "" {
+		return crd.TypeIdent{Package: f.pkg, Name: typeName}, true
+	}
+	return crd.TypeIdent{Package: f.pkg.Imports()[pkgPath], Name: typeName}, false
+}
+
+// writeMembers writes the properties of the given object schema, at the
+// given indentation.
+func (f *tsFile) writeMembers(out *bytes.Buffer, objSchema *apiextensionsv1.JSONSchemaProps, indent string) {
+	names := make([]string, 0, len(objSchema.Properties))
+	for name := range objSchema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop := objSchema.Properties[name]
+		writeDoc(out, prop.Description, indent)
+		optional := "?"
+		if slices.Contains(objSchema.Required, name) {
+			optional = ""
+		}
+		fmt.Fprintf(out, "%s%s%s: %s;\n", indent, propertyName(name), optional, f.tsType(&prop, indent))
+	}
+}
+
+// tsType returns the TypeScript type of values of the given schema, written
+// at the given indentation.
+func (f *tsFile) tsType(valueSchema *apiextensionsv1.JSONSchemaProps, indent string) string {
+	var tsType string
+	switch {
+	case len(valueSchema.Enum) > 0:
+		values := make([]string, 0, len(valueSchema.Enum))
+		for _, value := range valueSchema.Enum {
+			values = append(values, literal(value.Raw))
+		}
+		tsType = strings.Join(values, " | ")
+	case valueSchema.Ref != nil:
+		tsType = f.refType(*valueSchema.Ref, indent)
+	case valueSchema.XIntOrString:
+		tsType = "number | string"
+	case valueSchema.Type == "string":
+		tsType = "string"
+	case valueSchema.Type == "integer", valueSchema.Type == "number":
+		tsType = "number"
+	case valueSchema.Type == "boolean":
+		tsType = "boolean"
+	case valueSchema.Type == "array":
+		items := "unknown"
+		if valueSchema.Items != nil && valueSchema.Items.Schema != nil {
+			items = f.tsType(valueSchema.Items.Schema, indent)
+		}
+		if isUnion(items) {
+			items = "(" + items + ")"
+		}
+		tsType = items + "[]"
+	case valueSchema.Type == "object" && len(valueSchema.Properties) > 0:
+		members := new(bytes.Buffer)
+		f.writeMembers(members, valueSchema, indent+"  ")
+		tsType = "{\n" + members.String() + indent + "}"
+	case valueSchema.Type == "object" && valueSchema.AdditionalProperties != nil && valueSchema.AdditionalProperties.Schema != nil:
+		tsType = fmt.Sprintf("{ [key: string]: %s }", f.tsType(valueSchema.AdditionalProperties.Schema, indent))
+	case valueSchema.Type == "object":
+		tsType = "{ [key: string]: unknown }"
+	default:
+		tsType = "unknown"
+	}
+	if valueSchema.Nullable {
+		tsType += " | null"
+	}
+	return tsType
+}
+
+// isUnion checks whether the given TypeScript type is a union, outside of any
+// nested object or parenthesized type.
+func isUnion(tsType string) bool {
+	depth, quoted := 0, false
+	for i, char := range tsType {
+		switch {
+		case quoted:
+			if char == '"' && tsType[i-1] != '\\' {
+				quoted = false
+			}
+			continue
+		case char == '"':
+			quoted = true
+			continue
+		}
+		switch char {
+		case '{', '(':
+			depth++
+		case '}', ')':
+			depth--
+		case '|':
+			if depth == 0 && i > 0 && tsType[i-1] == ' ' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refType returns the TypeScript type of the type referred to by the given
+// reference, written at the given indentation.
+func (f *tsFile) refType(ref, indent string) string {
+	ident, local := f.resolve(ref)
+	switch {
+	case local:
+		f.needType(ident.Name)
+		return ident.Name
+	case ident.Package == nil:
+		return "unknown"
+	case loader.NonVendorPath(ident.Package.PkgPath) == metav1PkgPath && ident.Name == "ObjectMeta":
+		f.needsObjectMeta = true
+		return "ObjectMeta"
+	}
+	// inline the types of other packages
+	f.parser.NeedFlattenedSchemaFor(ident)
+	flattened := f.parser.FlattenedSchemata[ident]
+	return f.tsType(&flattened, indent)
+}
@@ -0,0 +1,30 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation generates Go functions validating objects against the
+// validation markers of their kinds.
+//
+// The checks are generated from the CRD schema produced by the CRD generator
+// from the same markers, so objects are validated with the semantics of the
+// published schema: value validations (bounds, lengths, patterns, enums,
+// required fields, list types, ...) are turned into plain Go code, while CEL
+// rules (+kubebuilder:validation:XValidation) are evaluated with the same CEL
+// validator as the API server, from an embedded copy of the schema.
+//
+// Formats aren't checked, and objects are validated as they are, so defaults
+// should be applied first (e.g. with the functions of the defaulter
+// generator).
+package validation
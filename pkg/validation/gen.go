@@ -0,0 +1,240 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crdgen "sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// outputFileName is the name of the generated file in each package.
+const outputFileName = "zz_generated.validation.go"
+
+// +controllertools:marker:generateHelp
+
+// Generator generates Validate<Kind> functions from the validation markers of
+// the kinds of each package.
+//
+// The functions return a field.ErrorList, as with the API server, and are
+// written to zz_generated.validation.go.  CEL rules are evaluated with the
+// validator of k8s.io/apiextensions-apiserver, which must thus be a
+// dependency of packages with such rules.
+type Generator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+}
+
+func (Generator) CheckFilter() loader.NodeFilter {
+	return crdgen.Generator{}.CheckFilter()
+}
+
+func (Generator) RegisterMarkers(into *markers.Registry) error {
+	return crdgen.Generator{}.RegisterMarkers(into)
+}
+
+// IncrementalRoots returns the roots of the API groups of the affected roots,
+// as for CustomResourceDefinitions.
+func (Generator) IncrementalRoots(ctx *genall.GenerationContext, affected []*loader.Package) []*loader.Package {
+	return crdgen.GroupRoots(ctx, affected)
+}
+
+func (g Generator) Generate(ctx *genall.GenerationContext) error {
+	headerText, err := ctx.GoHeaderText(g.HeaderFile, g.Year)
+	if err != nil {
+		return err
+	}
+
+	parser := &crdgen.Parser{
+		Collector: ctx.Collector,
+		Checker:   ctx.Checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+	}
+	crdgen.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+	metav1Pkg := crdgen.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	kubeKinds := crdgen.FindKubeKinds(parser, metav1Pkg)
+	slices.SortFunc(kubeKinds, func(a, b schema.GroupKind) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	files := make(map[*loader.Package]*validationFile)
+	for _, groupKind := range kubeKinds {
+		parser.NeedCRDFor(groupKind, nil)
+		crd, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists {
+			continue
+		}
+		for _, root := range ctx.Roots {
+			gv, known := parser.GroupVersions[root]
+			if !known || gv.Group != groupKind.Group {
+				continue
+			}
+			for _, version := range crd.Spec.Versions {
+				if version.Name != gv.Version || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+					continue
+				}
+				file, exists := files[root]
+				if !exists {
+					file = newValidationFile(root)
+					files[root] = file
+				}
+				file.generateKind(groupKind.Kind, version.Schema.OpenAPIV3Schema)
+			}
+		}
+	}
+
+	for _, root := range ctx.Roots {
+		file, exists := files[root]
+		if !exists || file.failed || len(file.funcs) == 0 {
+			continue
+		}
+		gogen.WriteOut(ctx, root, outputFileName, file.contents(headerText))
+	}
+
+	return nil
+}
+
+// validationFile collects the generated code of a single package.
+type validationFile struct {
+	pkg *loader.Package
+	// imports are the aliases of the imported packages, by path.
+	imports map[string]string
+
+	// vars are the generated package-level variables, by name.
+	vars map[string]string
+	// funcs are the generated functions, by name.
+	funcs map[string][]byte
+	// failed indicates that some code couldn't be generated, so the file
+	// shouldn't be written.
+	failed bool
+}
+
+func newValidationFile(pkg *loader.Package) *validationFile {
+	return &validationFile{
+		pkg:     pkg,
+		imports: make(map[string]string),
+		vars:    make(map[string]string),
+		funcs:   make(map[string][]byte),
+	}
+}
+
+// needImport marks that the given package is needed, returning the alias to
+// use for it.
+func (f *validationFile) needImport(importPath, alias string) string {
+	f.imports[importPath] = alias
+	return alias
+}
+
+// lookupNamed returns the named type with the given name in the package, if
+// any.
+func (f *validationFile) lookupNamed(name string) *types.Named {
+	typeName, isType := f.pkg.Types.Scope().Lookup(name).(*types.TypeName)
+	if !isType {
+		return nil
+	}
+	named, _ := typeName.Type().(*types.Named)
+	return named
+}
+
+// contents returns the formatted contents of the file.
+func (f *validationFile) contents(headerText string) []byte {
+	// standard library imports go first, as with goimports
+	var stdImports, otherImports []string
+	for importPath, alias := range f.imports {
+		spec := strconv.Quote(importPath)
+		if alias != importPath[strings.LastIndex(importPath, "/")+1:] {
+			spec = alias + " " + spec
+		}
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			otherImports = append(otherImports, spec)
+		} else {
+			stdImports = append(stdImports, spec)
+		}
+	}
+	byPath := func(a, b string) int {
+		return strings.Compare(a[strings.Index(a, `"`):], b[strings.Index(b, `"`):])
+	}
+	slices.SortFunc(stdImports, byPath)
+	slices.SortFunc(otherImports, byPath)
+	var importGroups []string
+	for _, group := range [][]string{stdImports, otherImports} {
+		if len(group) > 0 {
+			importGroups = append(importGroups, strings.Join(group, "\n"))
+		}
+	}
+
+	outContent := new(bytes.Buffer)
+	// NB: blank line after build tags to distinguish them from comments
+	fmt.Fprintf(outContent, `//go:build !ignore_autogenerated
+
+%[3]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+%[2]s
+)
+
+`, f.pkg.Name, strings.Join(importGroups, "\n\n"), headerText)
+
+	if len(f.vars) > 0 {
+		varNames := make([]string, 0, len(f.vars))
+		for name := range f.vars {
+			varNames = append(varNames, name)
+		}
+		slices.Sort(varNames)
+		outContent.WriteString("var (\n")
+		for _, name := range varNames {
+			fmt.Fprintf(outContent, "%s = %s\n", name, f.vars[name])
+		}
+		outContent.WriteString(")\n\n")
+	}
+
+	names := make([]string, 0, len(f.funcs))
+	for name := range f.funcs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		outContent.Write(f.funcs[name])
+	}
+
+	return gogen.Format(f.pkg, outContent.Bytes())
+}
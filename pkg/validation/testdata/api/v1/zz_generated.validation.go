@@ -0,0 +1,177 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+)
+
+var (
+	pattern1           = regexp.MustCompile(`^[a-z][-a-z0-9]*$`)
+	pattern2           = regexp.MustCompile(`^[a-z]+$`)
+	widgetCELValidator = sync.OnceValue(func() *cel.Validator { return newCELValidator(widgetSchema) })
+	widgetSchema       = `{"type":"object","properties":{"apiVersion":{"type":"string"},"kind":{"type":"string"},"metadata":{"type":"object"},"spec":{"type":"object","required":["maxReplicas","minReplicas","name","ports"],"properties":{"maxReplicas":{"type":"integer","format":"int32","minimum":0,"exclusiveMinimum":true,"multipleOf":2},"minReplicas":{"type":"integer","format":"int32","minimum":0},"name":{"type":"string","maxLength":63,"pattern":"^[a-z][-a-z0-9]*$"},"namedPorts":{"type":"object","maxProperties":4,"additionalProperties":{"type":"object","required":["name","number"],"properties":{"name":{"type":"string","minLength":1},"number":{"type":"integer","format":"int32","maximum":65535,"minimum":1},"protocol":{"type":"string","enum":["TCP","UDP"]}}}},"ports":{"type":"array","maxItems":8,"minItems":1,"items":{"type":"object","required":["name","number"],"properties":{"name":{"type":"string","minLength":1},"number":{"type":"integer","format":"int32","maximum":65535,"minimum":1},"protocol":{"type":"string","enum":["TCP","UDP"]}}},"x-kubernetes-list-map-keys":["name"],"x-kubernetes-list-type":"map"},"priority":{"type":"string","enum":["Low","High"]},"tags":{"type":"array","items":{"type":"string","pattern":"^[a-z]+$"},"x-kubernetes-list-type":"set"},"weight":{"type":"integer","format":"int32","maximum":100,"minimum":1}},"x-kubernetes-validations":[{"rule":"self.minReplicas \u003c= self.maxReplicas","message":"minReplicas must not exceed maxReplicas"}]}}}`
+)
+
+// ValidateWidget validates a Widget against the validation markers of its type and fields.
+func ValidateWidget(obj *Widget) field.ErrorList {
+	var allErrs field.ErrorList
+	if utf8.RuneCountInString(obj.Spec.Name) > 63 {
+		allErrs = append(allErrs, field.TooLongCharacters(field.NewPath("spec").Child("name"), obj.Spec.Name, 63))
+	}
+	if !pattern1.MatchString(obj.Spec.Name) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("name"), obj.Spec.Name, "should match '^[a-z][-a-z0-9]*$'"))
+	}
+	if obj.Spec.MinReplicas < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("minReplicas"), obj.Spec.MinReplicas, "should be greater than or equal to 0"))
+	}
+	if obj.Spec.MaxReplicas <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("maxReplicas"), obj.Spec.MaxReplicas, "should be greater than 0"))
+	}
+	if obj.Spec.MaxReplicas%2 != 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("maxReplicas"), obj.Spec.MaxReplicas, "should be a multiple of 2"))
+	}
+	if obj.Spec.Weight != nil {
+		if *obj.Spec.Weight < 1 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("weight"), *obj.Spec.Weight, "should be greater than or equal to 1"))
+		}
+		if *obj.Spec.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("weight"), *obj.Spec.Weight, "should be less than or equal to 100"))
+		}
+	}
+	if obj.Spec.Ports == nil {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("ports"), ""))
+	}
+	if obj.Spec.Ports != nil {
+		if len(obj.Spec.Ports) < 1 {
+			allErrs = append(allErrs, field.TooFew(field.NewPath("spec").Child("ports"), len(obj.Spec.Ports), 1))
+		}
+		if len(obj.Spec.Ports) > 8 {
+			allErrs = append(allErrs, field.TooMany(field.NewPath("spec").Child("ports"), len(obj.Spec.Ports), 8))
+		}
+		seen := make(map[any]struct{}, len(obj.Spec.Ports))
+		for i := range obj.Spec.Ports {
+			if _, duplicate := seen[obj.Spec.Ports[i].Name]; duplicate {
+				allErrs = append(allErrs, field.Duplicate(field.NewPath("spec").Child("ports").Index(i), obj.Spec.Ports[i].Name))
+			}
+			seen[obj.Spec.Ports[i].Name] = struct{}{}
+		}
+		for i := range obj.Spec.Ports {
+			if utf8.RuneCountInString(obj.Spec.Ports[i].Name) < 1 {
+				allErrs = append(allErrs, field.TooShort(field.NewPath("spec").Child("ports").Index(i).Child("name"), obj.Spec.Ports[i].Name, 1))
+			}
+			if obj.Spec.Ports[i].Number < 1 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("ports").Index(i).Child("number"), obj.Spec.Ports[i].Number, "should be greater than or equal to 1"))
+			}
+			if obj.Spec.Ports[i].Number > 65535 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("ports").Index(i).Child("number"), obj.Spec.Ports[i].Number, "should be less than or equal to 65535"))
+			}
+			if obj.Spec.Ports[i].Protocol != "" {
+				switch obj.Spec.Ports[i].Protocol {
+				case "TCP", "UDP":
+				default:
+					allErrs = append(allErrs, field.NotSupported(field.NewPath("spec").Child("ports").Index(i).Child("protocol"), obj.Spec.Ports[i].Protocol, []string{"TCP", "UDP"}))
+				}
+			}
+		}
+	}
+	if obj.Spec.Tags != nil {
+		seen := make(map[any]struct{}, len(obj.Spec.Tags))
+		for i := range obj.Spec.Tags {
+			if _, duplicate := seen[obj.Spec.Tags[i]]; duplicate {
+				allErrs = append(allErrs, field.Duplicate(field.NewPath("spec").Child("tags").Index(i), obj.Spec.Tags[i]))
+			}
+			seen[obj.Spec.Tags[i]] = struct{}{}
+		}
+		for i := range obj.Spec.Tags {
+			if !pattern2.MatchString(obj.Spec.Tags[i]) {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("tags").Index(i), obj.Spec.Tags[i], "should match '^[a-z]+$'"))
+			}
+		}
+	}
+	if obj.Spec.NamedPorts != nil {
+		if len(obj.Spec.NamedPorts) > 4 {
+			allErrs = append(allErrs, field.TooMany(field.NewPath("spec").Child("namedPorts"), len(obj.Spec.NamedPorts), 4))
+		}
+		for key, val := range obj.Spec.NamedPorts {
+			if utf8.RuneCountInString(val.Name) < 1 {
+				allErrs = append(allErrs, field.TooShort(field.NewPath("spec").Child("namedPorts").Key(key).Child("name"), val.Name, 1))
+			}
+			if val.Number < 1 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("namedPorts").Key(key).Child("number"), val.Number, "should be greater than or equal to 1"))
+			}
+			if val.Number > 65535 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("namedPorts").Key(key).Child("number"), val.Number, "should be less than or equal to 65535"))
+			}
+			if val.Protocol != "" {
+				switch val.Protocol {
+				case "TCP", "UDP":
+				default:
+					allErrs = append(allErrs, field.NotSupported(field.NewPath("spec").Child("namedPorts").Key(key).Child("protocol"), val.Protocol, []string{"TCP", "UDP"}))
+				}
+			}
+		}
+	}
+	if obj.Spec.Priority != "" {
+		switch obj.Spec.Priority {
+		case "Low", "High":
+		default:
+			allErrs = append(allErrs, field.NotSupported(field.NewPath("spec").Child("priority"), obj.Spec.Priority, []string{"Low", "High"}))
+		}
+	}
+	unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return append(allErrs, field.InternalError(nil, err))
+	}
+	celErrs, _ := widgetCELValidator().Validate(context.TODO(), nil, nil, unstructuredObj, nil, celconfig.RuntimeCELCostBudget)
+	allErrs = append(allErrs, celErrs...)
+	return allErrs
+}
+
+// newCELValidator returns the validator of the CEL rules of the given CRD
+// schema, as run by the API server.
+func newCELValidator(schemaJSON string) *cel.Validator {
+	var versionedSchema apiextensionsv1.JSONSchemaProps
+	if err := json.Unmarshal([]byte(schemaJSON), &versionedSchema); err != nil {
+		panic(err)
+	}
+	var internalSchema apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&versionedSchema, &internalSchema, nil); err != nil {
+		panic(err)
+	}
+	structural, err := structuralschema.NewStructural(&internalSchema)
+	if err != nil {
+		panic(err)
+	}
+	return cel.NewValidator(structural, true, celconfig.PerCallLimit)
+}
@@ -0,0 +1,650 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+)
+
+const (
+	fieldPkgPath   = "k8s.io/apimachinery/pkg/util/validation/field"
+	runtimePkgPath = "k8s.io/apimachinery/pkg/runtime"
+)
+
+// codeWriter writes out the code of validations to a buffer, skipping the
+// blocks that end up empty.
+type codeWriter struct {
+	gogen.CodeWriter
+	out *bytes.Buffer
+}
+
+func newCodeWriter(out *bytes.Buffer) *codeWriter {
+	return &codeWriter{CodeWriter: gogen.CodeWriter{Out: out}, out: out}
+}
+
+// Block writes the given clause (e.g. an if or for clause), with the code
+// written by the given function as its block, unless no code is written.
+// It returns whether the block was written.
+func (c *codeWriter) Block(clause string, block func(c *codeWriter)) bool {
+	body := newCodeWriter(new(bytes.Buffer))
+	block(body)
+	if body.out.Len() == 0 {
+		return false
+	}
+	c.Linef("%s {", clause)
+	c.out.Write(body.out.Bytes())
+	c.Line("}")
+	return true
+}
+
+// appendErr writes code appending the given error to the list of errors.
+func (c *codeWriter) appendErr(errExpr string, args ...any) {
+	c.Linef("allErrs = append(allErrs, "+errExpr+")", args...)
+}
+
+// generateKind generates the Validate<Kind> function of the given kind, with
+// the given schema, unless it's hand-written.
+func (f *validationFile) generateKind(kind string, schema *apiextensionsv1.JSONSchemaProps) {
+	named := f.lookupNamed(kind)
+	if named == nil {
+		return
+	}
+	name := "Validate" + kind
+	if f.pkg.Types.Scope().Lookup(name) != nil {
+		// hand-written
+		return
+	}
+	fieldAlias := f.needImport(fieldPkgPath, "field")
+
+	outContent := new(bytes.Buffer)
+	c := newCodeWriter(outContent)
+	c.Linef("// %s validates a %s against the validation markers of its type and fields.", name, kind)
+	c.Linef("func %s(obj *%s) %s.ErrorList {", name, kind, fieldAlias)
+	c.Linef("var allErrs %s.ErrorList", fieldAlias)
+	f.validateValue(c, "(*obj)", "", named, schema, 0)
+	if hasCELRules(schema) {
+		f.generateCELValidation(c, kind, schema)
+	}
+	c.Line("return allErrs")
+	c.Line("}")
+	c.Line("")
+	f.funcs[name] = outContent.Bytes()
+}
+
+// selector returns the syntax for selecting the given field of the struct
+// value of the given expression.
+func selector(expr, fieldName string) string {
+	if strings.HasPrefix(expr, "(*") && strings.HasSuffix(expr, ")") {
+		// fields are selected through pointers automatically
+		expr = expr[2 : len(expr)-1]
+	}
+	return expr + "." + fieldName
+}
+
+// value returns the syntax for using the given expression as an operand.
+func value(expr string) string {
+	if strings.HasPrefix(expr, "(*") && strings.HasSuffix(expr, ")") && !strings.HasPrefix(expr, "(**") {
+		return expr[1 : len(expr)-1]
+	}
+	return expr
+}
+
+// childPath returns the syntax for the path of the given child of the given
+// path, starting a new path if the given path is empty (the root).
+func childPath(path, name string) string {
+	if path == "" {
+		return fmt.Sprintf("field.NewPath(%q)", name)
+	}
+	return fmt.Sprintf("%s.Child(%q)", path, name)
+}
+
+// pathOrNil returns the syntax of the given path, or nil for the root.
+func pathOrNil(path string) string {
+	if path == "" {
+		return "nil"
+	}
+	return path
+}
+
+// loopVars returns the names of the loop variables at the given nesting
+// depth.
+func loopVars(depth int) (index, key, val, seen string) {
+	if depth == 0 {
+		return "i", "key", "val", "seen"
+	}
+	suffix := strconv.Itoa(depth + 1)
+	return "i" + suffix, "key" + suffix, "val" + suffix, "seen" + suffix
+}
+
+// validateValue writes the checks of the given expression of the given type
+// against the given schema.
+func (f *validationFile) validateValue(c *codeWriter, expr, path string, typ types.Type, schema *apiextensionsv1.JSONSchemaProps, depth int) {
+	if schema == nil || schema.XIntOrString {
+		return
+	}
+
+	switch underlying := typ.Underlying().(type) {
+	case *types.Pointer:
+		c.Block(fmt.Sprintf("if %s != nil", value(expr)), func(c *codeWriter) {
+			f.validateValue(c, "(*"+value(expr)+")", path, underlying.Elem(), schema, depth)
+		})
+	case *types.Basic:
+		f.validateBasic(c, value(expr), path, typ, underlying, schema)
+	case *types.Struct:
+		// other Go types (e.g. metav1.Time) are marshaled to other JSON types
+		if schema.Type == "object" {
+			f.validateStruct(c, expr, path, underlying, schema, depth)
+		}
+	case *types.Slice:
+		if schema.Type == "array" {
+			c.Block(fmt.Sprintf("if %s != nil", value(expr)), func(c *codeWriter) {
+				f.validateArray(c, expr, path, underlying, schema, depth)
+			})
+		}
+	case *types.Map:
+		if schema.Type == "object" {
+			c.Block(fmt.Sprintf("if %s != nil", value(expr)), func(c *codeWriter) {
+				f.validateMap(c, expr, path, underlying, schema, depth)
+			})
+		}
+	}
+}
+
+// jsonField returns the JSON name and options of the given struct field.
+func jsonField(field *types.Var, tag string) (name string, opts []string, skip bool) {
+	jsonTag, hasTag := reflect.StructTag(tag).Lookup("json")
+	if jsonTag == "-" {
+		return "", nil, true
+	}
+	if !field.Exported() && !field.Embedded() {
+		return "", nil, true
+	}
+	if !hasTag {
+		if field.Embedded() {
+			return "", []string{"inline"}, false
+		}
+		return field.Name(), nil, false
+	}
+	parts := strings.Split(jsonTag, ",")
+	name, opts = parts[0], parts[1:]
+	if name == "" && field.Embedded() {
+		opts = append(opts, "inline")
+	}
+	if name == "" {
+		name = field.Name()
+	}
+	return name, opts, false
+}
+
+// omittedCondition returns the condition under which the given field
+// expression of the given type is omitted from the JSON form of the object,
+// or "" if it's never omitted.
+func omittedCondition(expr string, typ types.Type, opts []string) string {
+	omitEmpty := slices.Contains(opts, "omitempty")
+	omitZero := slices.Contains(opts, "omitzero")
+	switch underlying := typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface:
+		// nil values are serialized as null, which the API server drops
+		return expr + " == nil"
+	case *types.Basic:
+		if !omitEmpty && !omitZero {
+			return ""
+		}
+		info := underlying.Info()
+		switch {
+		case info&types.IsString != 0:
+			return expr + ` == ""`
+		case info&types.IsBoolean != 0:
+			return "!" + expr
+		case info&types.IsNumeric != 0:
+			return expr + " == 0"
+		}
+	case *types.Struct:
+		if omitZero {
+			return fmt.Sprintf("reflect.ValueOf(%s).IsZero()", expr)
+		}
+	}
+	return ""
+}
+
+// negate returns the negation of the given condition.
+func negate(cond string) string {
+	switch {
+	case strings.HasSuffix(cond, " == nil"):
+		return strings.TrimSuffix(cond, " == nil") + " != nil"
+	case strings.HasSuffix(cond, ` == ""`):
+		return strings.TrimSuffix(cond, ` == ""`) + ` != ""`
+	case strings.HasSuffix(cond, " == 0"):
+		return strings.TrimSuffix(cond, " == 0") + " != 0"
+	case strings.HasPrefix(cond, "!"):
+		return cond[1:]
+	}
+	return "!" + cond
+}
+
+// validateStruct writes the checks of the fields of the given struct
+// expression against the given object schema.
+func (f *validationFile) validateStruct(c *codeWriter, expr, path string, structType *types.Struct, schema *apiextensionsv1.JSONSchemaProps, depth int) {
+	for i := range structType.NumFields() {
+		field := structType.Field(i)
+		name, opts, skip := jsonField(field, structType.Tag(i))
+		if skip {
+			continue
+		}
+		fieldExpr := selector(expr, field.Name())
+		if slices.Contains(opts, "inline") {
+			f.validateValue(c, fieldExpr, path, field.Type(), schema, depth)
+			continue
+		}
+
+		fieldPath := childPath(path, name)
+		omitted := omittedCondition(fieldExpr, field.Type(), opts)
+		guardWritten := false
+		if omitted != "" && slices.Contains(schema.Required, name) {
+			guardWritten = c.Block("if "+omitted, func(c *codeWriter) {
+				c.appendErr(`field.Required(%s, "")`, fieldPath)
+			})
+		}
+
+		if propSchema, hasSchema := schema.Properties[name]; hasSchema {
+			validateField := func(c *codeWriter) {
+				f.validateValue(c, fieldExpr, fieldPath, field.Type(), &propSchema, depth)
+			}
+			switch field.Type().Underlying().(type) {
+			case *types.Basic, *types.Struct:
+				// only validate values that are present (nil values are
+				// skipped by the checks of their types)
+				if omitted != "" {
+					guardWritten = c.Block("if "+negate(omitted), validateField) || guardWritten
+					break
+				}
+				validateField(c)
+			default:
+				validateField(c)
+			}
+		}
+
+		if guardWritten && strings.HasPrefix(omitted, "reflect.") {
+			f.needImport("reflect", "reflect")
+		}
+	}
+}
+
+// validateArray writes the checks of the given (non-nil) slice expression
+// against the given array schema.
+func (f *validationFile) validateArray(c *codeWriter, expr, path string, sliceType *types.Slice, schema *apiextensionsv1.JSONSchemaProps, depth int) {
+	val := value(expr)
+	if schema.MinItems != nil {
+		c.Block(fmt.Sprintf("if len(%s) < %d", val, *schema.MinItems), func(c *codeWriter) {
+			c.appendErr("field.TooFew(%s, len(%s), %d)", pathOrNil(path), val, *schema.MinItems)
+		})
+	}
+	if schema.MaxItems != nil {
+		c.Block(fmt.Sprintf("if len(%s) > %d", val, *schema.MaxItems), func(c *codeWriter) {
+			c.appendErr("field.TooMany(%s, len(%s), %d)", pathOrNil(path), val, *schema.MaxItems)
+		})
+	}
+
+	index, _, _, seen := loopVars(depth)
+	elemPath := fmt.Sprintf("%s.Index(%s)", pathOrNil(path), index)
+	elem := fmt.Sprintf("%s[%s]", expr, index)
+	if key := f.listKey(elem, sliceType.Elem(), schema); key != "" {
+		c.Linef("%s := make(map[any]struct{}, len(%s))", seen, val)
+		c.Block(fmt.Sprintf("for %s := range %s", index, val), func(c *codeWriter) {
+			c.Block(fmt.Sprintf("if _, duplicate := %s[%s]; duplicate", seen, key), func(c *codeWriter) {
+				c.appendErr("field.Duplicate(%s, %s)", elemPath, key)
+			})
+			c.Linef("%s[%s] = struct{}{}", seen, key)
+		})
+	}
+
+	if schema.Items != nil && schema.Items.Schema != nil {
+		c.Block(fmt.Sprintf("for %s := range %s", index, val), func(c *codeWriter) {
+			f.validateValue(c, elem, elemPath, sliceType.Elem(), schema.Items.Schema, depth+1)
+		})
+	}
+}
+
+// listKey returns the syntax of the key identifying the given element of a
+// list in the uniqueness checks of its list type, or "" if the list type
+// doesn't require unique elements, or the key can't be computed.
+func (f *validationFile) listKey(elem string, elemType types.Type, schema *apiextensionsv1.JSONSchemaProps) string {
+	if schema.XListType == nil {
+		return ""
+	}
+	switch *schema.XListType {
+	case "set":
+		if _, isBasic := elemType.Underlying().(*types.Basic); isBasic {
+			return elem
+		}
+	case "map":
+		structType, isStruct := elemType.Underlying().(*types.Struct)
+		if !isStruct || len(schema.XListMapKeys) == 0 {
+			return ""
+		}
+		keyFields := make([]string, 0, len(schema.XListMapKeys))
+		for _, key := range schema.XListMapKeys {
+			found := false
+			for i := range structType.NumFields() {
+				field := structType.Field(i)
+				name, _, skip := jsonField(field, structType.Tag(i))
+				if skip || name != key {
+					continue
+				}
+				if _, isBasic := field.Type().Underlying().(*types.Basic); !isBasic {
+					// optional (pointer) keys aren't supported
+					return ""
+				}
+				keyFields = append(keyFields, selector(elem, field.Name()))
+				found = true
+				break
+			}
+			if !found {
+				return ""
+			}
+		}
+		if len(keyFields) == 1 {
+			return keyFields[0]
+		}
+		return fmt.Sprintf("[%d]any{%s}", len(keyFields), strings.Join(keyFields, ", "))
+	}
+	return ""
+}
+
+// validateMap writes the checks of the given (non-nil) map expression
+// against the given object schema.
+func (f *validationFile) validateMap(c *codeWriter, expr, path string, mapType *types.Map, schema *apiextensionsv1.JSONSchemaProps, depth int) {
+	val := value(expr)
+	if schema.MinProperties != nil {
+		c.Block(fmt.Sprintf("if len(%s) < %d", val, *schema.MinProperties), func(c *codeWriter) {
+			c.appendErr("field.TooFew(%s, len(%s), %d)", pathOrNil(path), val, *schema.MinProperties)
+		})
+	}
+	if schema.MaxProperties != nil {
+		c.Block(fmt.Sprintf("if len(%s) > %d", val, *schema.MaxProperties), func(c *codeWriter) {
+			c.appendErr("field.TooMany(%s, len(%s), %d)", pathOrNil(path), val, *schema.MaxProperties)
+		})
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		_, key, elem, _ := loopVars(depth)
+		c.Block(fmt.Sprintf("for %s, %s := range %s", key, elem, val), func(c *codeWriter) {
+			f.validateValue(c, elem, fmt.Sprintf("%s.Key(%s)", pathOrNil(path), key), mapType.Elem(), schema.AdditionalProperties.Schema, depth+1)
+		})
+	}
+}
+
+// validateBasic writes the checks of the given basic value against the given
+// schema.
+func (f *validationFile) validateBasic(c *codeWriter, val, path string, typ types.Type, basic *types.Basic, schema *apiextensionsv1.JSONSchemaProps) {
+	path = pathOrNil(path)
+	info := basic.Info()
+	switch {
+	case info&types.IsString != 0 && schema.Type == "string":
+		str := val
+		if !types.Identical(typ, types.Typ[types.String]) {
+			str = fmt.Sprintf("string(%s)", val)
+		}
+		if schema.MaxLength != nil {
+			f.needImport("unicode/utf8", "utf8")
+			c.Block(fmt.Sprintf("if utf8.RuneCountInString(%s) > %d", str, *schema.MaxLength), func(c *codeWriter) {
+				c.appendErr("field.TooLongCharacters(%s, %s, %d)", path, val, *schema.MaxLength)
+			})
+		}
+		if schema.MinLength != nil {
+			f.needImport("unicode/utf8", "utf8")
+			c.Block(fmt.Sprintf("if utf8.RuneCountInString(%s) < %d", str, *schema.MinLength), func(c *codeWriter) {
+				c.appendErr("field.TooShort(%s, %s, %d)", path, val, *schema.MinLength)
+			})
+		}
+		if schema.Pattern != "" {
+			pattern := f.patternVar(schema.Pattern)
+			c.Block(fmt.Sprintf("if !%s.MatchString(%s)", pattern, str), func(c *codeWriter) {
+				c.appendErr("field.Invalid(%s, %s, %s)", path, val, strconv.Quote(fmt.Sprintf("should match '%s'", schema.Pattern)))
+			})
+		}
+		f.validateEnum(c, val, path, schema)
+	case info&types.IsNumeric != 0 && (schema.Type == "integer" || schema.Type == "number"):
+		isInteger := info&types.IsInteger != 0
+		if schema.Minimum != nil {
+			op, detail := "<", "should be greater than or equal to %v"
+			if schema.ExclusiveMinimum {
+				op, detail = "<=", "should be greater than %v"
+			}
+			c.Block(fmt.Sprintf("if %s %s %s", numberOperand(val, isInteger, *schema.Minimum), op, formatNumber(*schema.Minimum)), func(c *codeWriter) {
+				c.appendErr("field.Invalid(%s, %s, %q)", path, val, fmt.Sprintf(detail, formatNumber(*schema.Minimum)))
+			})
+		}
+		if schema.Maximum != nil {
+			op, detail := ">", "should be less than or equal to %v"
+			if schema.ExclusiveMaximum {
+				op, detail = ">=", "should be less than %v"
+			}
+			c.Block(fmt.Sprintf("if %s %s %s", numberOperand(val, isInteger, *schema.Maximum), op, formatNumber(*schema.Maximum)), func(c *codeWriter) {
+				c.appendErr("field.Invalid(%s, %s, %q)", path, val, fmt.Sprintf(detail, formatNumber(*schema.Maximum)))
+			})
+		}
+		if schema.MultipleOf != nil {
+			multipleOf := *schema.MultipleOf
+			cond := fmt.Sprintf("%s%%%s != 0", val, formatNumber(multipleOf))
+			if !isInteger || multipleOf != math.Trunc(multipleOf) {
+				f.needImport("math", "math")
+				cond = fmt.Sprintf("math.Mod(float64(%s), %s) != 0", val, formatNumber(multipleOf))
+			}
+			c.Block("if "+cond, func(c *codeWriter) {
+				c.appendErr("field.Invalid(%s, %s, %q)", path, val, "should be a multiple of "+formatNumber(multipleOf))
+			})
+		}
+		f.validateEnum(c, val, path, schema)
+	}
+}
+
+// patternVar returns the name of the package-level variable holding the
+// compiled form of the given pattern.
+func (f *validationFile) patternVar(pattern string) string {
+	compiled := fmt.Sprintf("regexp.MustCompile(%s)", goString(pattern))
+	for name, existing := range f.vars {
+		if existing == compiled {
+			return name
+		}
+	}
+	f.needImport("regexp", "regexp")
+	name := fmt.Sprintf("pattern%d", len(f.vars)+1)
+	f.vars[name] = compiled
+	return name
+}
+
+// validateEnum writes the check of the given value against the enum of the
+// given schema, if any.
+func (f *validationFile) validateEnum(c *codeWriter, val, path string, schema *apiextensionsv1.JSONSchemaProps) {
+	if len(schema.Enum) == 0 {
+		return
+	}
+	cases := make([]string, 0, len(schema.Enum))
+	supported := make([]string, 0, len(schema.Enum))
+	for _, raw := range schema.Enum {
+		var enumValue any
+		if err := json.Unmarshal(raw.Raw, &enumValue); err != nil {
+			return
+		}
+		switch enumValue := enumValue.(type) {
+		case string:
+			cases = append(cases, strconv.Quote(enumValue))
+			supported = append(supported, strconv.Quote(enumValue))
+		case float64:
+			cases = append(cases, formatNumber(enumValue))
+			supported = append(supported, strconv.Quote(formatNumber(enumValue)))
+		default:
+			// e.g. null, for nullable fields
+			return
+		}
+	}
+	c.Linef("switch %s {", val)
+	c.Linef("case %s:", strings.Join(cases, ", "))
+	c.Line("default:")
+	c.appendErr("field.NotSupported(%s, %s, []string{%s})", path, val, strings.Join(supported, ", "))
+	c.Line("}")
+}
+
+// numberOperand returns the syntax for comparing the given number value to
+// the given bound, converting it to a float if the bound isn't an integer
+// (or might overflow the type of the value).
+func numberOperand(val string, isInteger bool, bound float64) string {
+	if isInteger && (bound != math.Trunc(bound) || math.Abs(bound) > math.MaxInt32) {
+		return fmt.Sprintf("float64(%s)", val)
+	}
+	return val
+}
+
+// formatNumber returns the shortest Go literal for the given number.
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'g', -1, 64)
+}
+
+// goString returns a Go string literal for the given string, preferring raw
+// string literals for readability.
+func goString(s string) string {
+	if !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// hasCELRules checks if the given schema has CEL rules, at any depth.
+func hasCELRules(schema *apiextensionsv1.JSONSchemaProps) bool {
+	if schema == nil {
+		return false
+	}
+	if len(schema.XValidations) > 0 {
+		return true
+	}
+	for _, prop := range schema.Properties {
+		if hasCELRules(&prop) {
+			return true
+		}
+	}
+	if schema.Items != nil && hasCELRules(schema.Items.Schema) {
+		return true
+	}
+	return schema.AdditionalProperties != nil && hasCELRules(schema.AdditionalProperties.Schema)
+}
+
+// withoutDescriptions returns a copy of the given schema without the
+// descriptions, which don't affect validation.
+func withoutDescriptions(schema *apiextensionsv1.JSONSchemaProps) *apiextensionsv1.JSONSchemaProps {
+	schema = schema.DeepCopy()
+	var strip func(schema *apiextensionsv1.JSONSchemaProps)
+	strip = func(schema *apiextensionsv1.JSONSchemaProps) {
+		if schema == nil {
+			return
+		}
+		schema.Description = ""
+		for name, prop := range schema.Properties {
+			strip(&prop)
+			schema.Properties[name] = prop
+		}
+		if schema.Items != nil {
+			strip(schema.Items.Schema)
+		}
+		if schema.AdditionalProperties != nil {
+			strip(schema.AdditionalProperties.Schema)
+		}
+	}
+	strip(schema)
+	return schema
+}
+
+// lowerFirst returns the given name with its first letter in lower case.
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// generateCELValidation writes code evaluating the CEL rules of the given
+// kind, with the validator of the API server.
+func (f *validationFile) generateCELValidation(c *codeWriter, kind string, schema *apiextensionsv1.JSONSchemaProps) {
+	schemaJSON, err := json.Marshal(withoutDescriptions(schema))
+	if err != nil {
+		f.pkg.AddError(err)
+		f.failed = true
+		return
+	}
+	f.generateNewCELValidator()
+
+	schemaVar := lowerFirst(kind) + "Schema"
+	validatorVar := lowerFirst(kind) + "CELValidator"
+	f.needImport("sync", "sync")
+	f.vars[schemaVar] = goString(string(schemaJSON))
+	f.vars[validatorVar] = fmt.Sprintf("sync.OnceValue(func() *cel.Validator { return newCELValidator(%s) })", schemaVar)
+
+	f.needImport("context", "context")
+	f.needImport(runtimePkgPath, "runtime")
+	c.Line("unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)")
+	c.Block("if err != nil", func(c *codeWriter) {
+		c.Line("return append(allErrs, field.InternalError(nil, err))")
+	})
+	c.Linef("celErrs, _ := %s().Validate(context.TODO(), nil, nil, unstructuredObj, nil, celconfig.RuntimeCELCostBudget)", validatorVar)
+	c.Line("allErrs = append(allErrs, celErrs...)")
+}
+
+// generateNewCELValidator generates the newCELValidator function, used to
+// build the CEL validators of the kinds.
+func (f *validationFile) generateNewCELValidator() {
+	const name = "newCELValidator"
+	if _, exists := f.funcs[name]; exists {
+		return
+	}
+	f.needImport("encoding/json", "json")
+	f.needImport("k8s.io/apiextensions-apiserver/pkg/apis/apiextensions", "apiextensions")
+	f.needImport("k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1", "apiextensionsv1")
+	f.needImport("k8s.io/apiextensions-apiserver/pkg/apiserver/schema", "structuralschema")
+	f.needImport("k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel", "cel")
+	f.needImport("k8s.io/apiserver/pkg/apis/cel", "celconfig")
+
+	f.funcs[name] = []byte(`// newCELValidator returns the validator of the CEL rules of the given CRD
+// schema, as run by the API server.
+func newCELValidator(schemaJSON string) *cel.Validator {
+	var versionedSchema apiextensionsv1.JSONSchemaProps
+	if err := json.Unmarshal([]byte(schemaJSON), &versionedSchema); err != nil {
+		panic(err)
+	}
+	var internalSchema apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&versionedSchema, &internalSchema, nil); err != nil {
+		panic(err)
+	}
+	structural, err := structuralschema.NewStructural(&internalSchema)
+	if err != nil {
+		panic(err)
+	}
+	return cel.NewValidator(structural, true, celconfig.PerCallLimit)
+}
+
+`)
+}
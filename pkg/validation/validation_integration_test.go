@@ -0,0 +1,117 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+	"sigs.k8s.io/controller-tools/pkg/validation"
+)
+
+// outputToPackageMap keeps the generated files in memory, by package path
+// and file name.
+type outputToPackageMap map[string]*outputFile
+
+// Open implements genall.OutputRule.
+func (m outputToPackageMap) Open(pkg *loader.Package, path string) (io.WriteCloser, error) {
+	key := pkg.PkgPath + "/" + path
+	if _, ok := m[key]; !ok {
+		m[key] = &outputFile{}
+	}
+	return m[key], nil
+}
+
+type outputFile struct {
+	contents []byte
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+	o.contents = append(o.contents, p...)
+	return len(p), nil
+}
+
+func (o *outputFile) Close() error {
+	return nil
+}
+
+var _ = Describe("Validation Generation", func() {
+	var cwd string
+
+	BeforeEach(func() {
+		By("switching into testdata to appease go modules")
+		var err error
+		cwd, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata")).To(Succeed()) // go modules are directory-sensitive
+		DeferCleanup(os.Chdir, cwd)
+	})
+
+	It("should generate validation functions from the validation markers", func() {
+		output := make(outputToPackageMap)
+
+		By("initializing the runtime")
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("validation", markers.DescribesPackage, validation.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{
+			fmt.Sprintf("validation:headerFile=%s", path.Join(cwd, "../../hack/boilerplate/boilerplate.generatego.txt")),
+			"paths=./api/...",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: output}
+
+		By("running the generator and checking for errors")
+		Expect(rt.Run()).To(BeFalse())
+
+		By("comparing the generated code to the checked in code")
+		outFile := output["testdata.kubebuilder.io/validation/api/v1/zz_generated.validation.go"]
+		Expect(outFile).NotTo(BeNil())
+		expectedFile, err := os.ReadFile("api/v1/zz_generated.validation.go")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(outFile.contents)).To(Equal(string(expectedFile)), "generated code not as expected\n\nDiff:\n\n%s", cmp.Diff(outFile.contents, expectedFile))
+	})
+
+	It("should fail on validation markers not matching the types of their fields", func() {
+		optionsRegistry := &markers.Registry{}
+		Expect(genall.RegisterOptionsMarkers(optionsRegistry)).To(Succeed())
+		Expect(optionsRegistry.Register(markers.Must(markers.MakeDefinition("validation", markers.DescribesPackage, validation.Generator{})))).To(Succeed())
+		rt, err := genall.FromOptions(optionsRegistry, []string{"validation", "paths=./invalid/..."})
+		Expect(err).NotTo(HaveOccurred())
+		rt.OutputRules = genall.OutputRules{Default: make(outputToPackageMap)}
+
+		By("running the generator")
+		Expect(rt.Run()).To(BeTrue())
+
+		By("checking the errors of the package")
+		var errs []string
+		for _, root := range rt.Roots {
+			for _, err := range root.Errors {
+				errs = append(errs, err.Error())
+			}
+		}
+		Expect(errs).To(ContainElement(ContainSubstring("must apply maximum to a numeric value")))
+	})
+})
@@ -0,0 +1,384 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/yaml"
+)
+
+// WebhookDrift describes the differences between a generated webhook and the
+// corresponding existing webhook.
+type WebhookDrift struct {
+	// Kind is the kind of the configuration of the webhook
+	// (MutatingWebhookConfiguration or ValidatingWebhookConfiguration).
+	Kind string
+	// Name is the name of the webhook.
+	Name string
+
+	// Missing is set if the webhook is generated, but doesn't exist.
+	Missing bool
+	// Extra is set if the webhook exists, but isn't generated anymore.
+	Extra bool
+	// Changes lists the changed settings of the webhook, as
+	// "<field>: <existing> -> <generated>", and the added and removed rules,
+	// as "rules: +<rule>" and "rules: -<rule>".
+	Changes []string
+}
+
+func (d WebhookDrift) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s webhook %s:", d.Kind, d.Name)
+	switch {
+	case d.Missing:
+		out.WriteString("\n  missing")
+	case d.Extra:
+		out.WriteString("\n  extra")
+	}
+	for _, change := range d.Changes {
+		fmt.Fprintf(&out, "\n  %s", change)
+	}
+	return out.String()
+}
+
+// webhookID identifies a webhook for the purposes of auditing.  Webhooks are
+// identified by name rather than by the name of their configuration, since
+// configurations are usually renamed when deployed (e.g. by kustomize).
+type webhookID struct {
+	kind string
+	name string
+}
+
+// auditedWebhook is a webhook being audited, along with the name of its
+// configuration.  Validating webhooks are represented as mutating ones,
+// which have a superset of their fields.
+type auditedWebhook struct {
+	configuration string
+	webhook       admissionregv1.MutatingWebhook
+}
+
+// AuditWebhooks compares the given generated webhook configurations (as
+// generated by the webhook generator) against the webhook configurations in
+// the given (potentially multi-document) YAML, returning the drift for each
+// webhook that differs.
+//
+// Webhooks are matched by kind and name, and compared after applying the
+// defaults of the API server, ignoring their CA bundles and the names and
+// namespaces of their services (which usually differ when deployed).  Existing
+// webhooks that aren't generated are only reported if their configuration
+// contains generated webhooks, so the YAML may contain the webhooks of other
+// components (e.g. the output of "kubectl get
+// mutatingwebhookconfigurations,validatingwebhookconfigurations -o yaml").
+func AuditWebhooks(generated []any, existingYAML []byte) ([]WebhookDrift, error) {
+	existing, err := parseWebhooks(existingYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[webhookID]auditedWebhook)
+	for _, obj := range generated {
+		webhooks, err := webhooksOf(obj)
+		if err != nil {
+			return nil, err
+		}
+		for id, webhook := range webhooks {
+			wanted[id] = webhook
+		}
+	}
+
+	// the configurations of the existing webhooks matching generated ones
+	auditedConfigurations := make(map[string]bool)
+	for id := range wanted {
+		if existingWebhook, exists := existing[id]; exists {
+			auditedConfigurations[id.kind+"/"+existingWebhook.configuration] = true
+		}
+	}
+
+	var drifts []WebhookDrift
+	for id, wantedWebhook := range wanted {
+		existingWebhook, exists := existing[id]
+		if !exists {
+			drifts = append(drifts, WebhookDrift{Kind: id.kind, Name: id.name, Missing: true})
+			continue
+		}
+		if changes := webhookChanges(existingWebhook.webhook, wantedWebhook.webhook); len(changes) > 0 {
+			drifts = append(drifts, WebhookDrift{Kind: id.kind, Name: id.name, Changes: changes})
+		}
+	}
+	for id, existingWebhook := range existing {
+		if _, isWanted := wanted[id]; !isWanted && auditedConfigurations[id.kind+"/"+existingWebhook.configuration] {
+			drifts = append(drifts, WebhookDrift{Kind: id.kind, Name: id.name, Extra: true})
+		}
+	}
+	slices.SortFunc(drifts, func(a, b WebhookDrift) int {
+		return strings.Compare(a.Kind+"/"+a.Name, b.Kind+"/"+b.Name)
+	})
+	return drifts, nil
+}
+
+// webhookChanges lists the differences between the given existing and
+// generated webhooks.
+func webhookChanges(existing, generated admissionregv1.MutatingWebhook) []string {
+	existing, generated = normalizeWebhook(existing), normalizeWebhook(generated)
+
+	var changes []string
+	existingRules, generatedRules := expandWebhookRules(existing.Rules), expandWebhookRules(generated.Rules)
+	for _, rule := range generatedRules {
+		if !slices.Contains(existingRules, rule) {
+			changes = append(changes, "rules: +"+rule)
+		}
+	}
+	for _, rule := range existingRules {
+		if !slices.Contains(generatedRules, rule) {
+			changes = append(changes, "rules: -"+rule)
+		}
+	}
+
+	fields := []struct {
+		name  string
+		value func(admissionregv1.MutatingWebhook) any
+	}{
+		{"clientConfig.service.path", func(w admissionregv1.MutatingWebhook) any {
+			if w.ClientConfig.Service == nil {
+				return nil
+			}
+			return w.ClientConfig.Service.Path
+		}},
+		{"clientConfig.service.port", func(w admissionregv1.MutatingWebhook) any {
+			if w.ClientConfig.Service == nil {
+				return nil
+			}
+			return w.ClientConfig.Service.Port
+		}},
+		{"clientConfig.url", func(w admissionregv1.MutatingWebhook) any { return w.ClientConfig.URL }},
+		{"failurePolicy", func(w admissionregv1.MutatingWebhook) any { return w.FailurePolicy }},
+		{"matchPolicy", func(w admissionregv1.MutatingWebhook) any { return w.MatchPolicy }},
+		{"namespaceSelector", func(w admissionregv1.MutatingWebhook) any { return w.NamespaceSelector }},
+		{"objectSelector", func(w admissionregv1.MutatingWebhook) any { return w.ObjectSelector }},
+		{"sideEffects", func(w admissionregv1.MutatingWebhook) any { return w.SideEffects }},
+		{"timeoutSeconds", func(w admissionregv1.MutatingWebhook) any { return w.TimeoutSeconds }},
+		{"admissionReviewVersions", func(w admissionregv1.MutatingWebhook) any { return w.AdmissionReviewVersions }},
+		{"reinvocationPolicy", func(w admissionregv1.MutatingWebhook) any { return w.ReinvocationPolicy }},
+		{"matchConditions", func(w admissionregv1.MutatingWebhook) any { return w.MatchConditions }},
+	}
+	for _, field := range fields {
+		existingValue, generatedValue := auditValue(field.value(existing)), auditValue(field.value(generated))
+		if existingValue != generatedValue {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", field.name, existingValue, generatedValue))
+		}
+	}
+	return changes
+}
+
+// normalizeWebhook applies the defaults of the API server to the given
+// webhook, so that webhooks read from a cluster can be compared with
+// generated ones.
+func normalizeWebhook(webhook admissionregv1.MutatingWebhook) admissionregv1.MutatingWebhook {
+	webhook = *webhook.DeepCopy()
+	if webhook.FailurePolicy == nil {
+		webhook.FailurePolicy = ptrTo(admissionregv1.Fail)
+	}
+	if webhook.MatchPolicy == nil {
+		webhook.MatchPolicy = ptrTo(admissionregv1.Equivalent)
+	}
+	if webhook.NamespaceSelector == nil {
+		webhook.NamespaceSelector = &metav1.LabelSelector{}
+	}
+	if webhook.ObjectSelector == nil {
+		webhook.ObjectSelector = &metav1.LabelSelector{}
+	}
+	if webhook.TimeoutSeconds == nil {
+		webhook.TimeoutSeconds = ptrTo(int32(10))
+	}
+	if webhook.ReinvocationPolicy == nil {
+		webhook.ReinvocationPolicy = ptrTo(admissionregv1.NeverReinvocationPolicy)
+	}
+	if webhook.ClientConfig.Service != nil && webhook.ClientConfig.Service.Port == nil {
+		webhook.ClientConfig.Service.Port = ptrTo(int32(443))
+	}
+	return webhook
+}
+
+// expandWebhookRules expands the given rules into the individual
+// (operation, group, version, resource, scope) combinations they match,
+// sorted.
+func expandWebhookRules(rules []admissionregv1.RuleWithOperations) []string {
+	var res []string
+	for _, rule := range rules {
+		scope := admissionregv1.AllScopes
+		if rule.Scope != nil {
+			scope = *rule.Scope
+		}
+		for _, operation := range rule.Operations {
+			for _, group := range rule.APIGroups {
+				for _, version := range rule.APIVersions {
+					for _, resource := range rule.Resources {
+						expanded := fmt.Sprintf("%s %s/%s/%s (scope %s)", operation, group, version, resource, scope)
+						if !slices.Contains(res, expanded) {
+							res = append(res, expanded)
+						}
+					}
+				}
+			}
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// auditValue formats the given value of a webhook field for comparison and
+// display.
+func auditValue(value any) string {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(out)
+}
+
+// webhooksOf returns the webhooks of the given webhook configuration, by ID.
+func webhooksOf(obj any) (map[webhookID]auditedWebhook, error) {
+	res := make(map[webhookID]auditedWebhook)
+	switch obj := obj.(type) {
+	case *admissionregv1.MutatingWebhookConfiguration:
+		for _, webhook := range obj.Webhooks {
+			res[webhookID{kind: "MutatingWebhookConfiguration", name: webhook.Name}] = auditedWebhook{configuration: obj.Name, webhook: webhook}
+		}
+	case *admissionregv1.ValidatingWebhookConfiguration:
+		for _, validatingWebhook := range obj.Webhooks {
+			// validating webhooks have a subset of the fields of mutating ones
+			raw, err := json.Marshal(validatingWebhook)
+			if err != nil {
+				return nil, err
+			}
+			var webhook admissionregv1.MutatingWebhook
+			if err := json.Unmarshal(raw, &webhook); err != nil {
+				return nil, err
+			}
+			res[webhookID{kind: "ValidatingWebhookConfiguration", name: webhook.Name}] = auditedWebhook{configuration: obj.Name, webhook: webhook}
+		}
+	}
+	return res, nil
+}
+
+// parseWebhooks extracts the webhooks of all webhook configurations in the
+// given YAML.
+func parseWebhooks(rawYAML []byte) (map[webhookID]auditedWebhook, error) {
+	res := make(map[webhookID]auditedWebhook)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(rawYAML)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read existing webhook configurations: %w", err)
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, fmt.Errorf("unable to parse existing webhook configurations: %w", err)
+		}
+		if typeMeta.APIVersion == "v1" && typeMeta.Kind == "List" {
+			var list struct {
+				Items []json.RawMessage `json:"items"`
+			}
+			if err := yaml.Unmarshal(doc, &list); err != nil {
+				return nil, fmt.Errorf("unable to parse existing webhook configurations: %w", err)
+			}
+			for _, item := range list.Items {
+				itemWebhooks, err := parseWebhooks(item)
+				if err != nil {
+					return nil, err
+				}
+				for id, webhook := range itemWebhooks {
+					res[id] = webhook
+				}
+			}
+			continue
+		}
+		if typeMeta.APIVersion != admissionregv1.SchemeGroupVersion.String() {
+			continue
+		}
+
+		var obj any
+		switch typeMeta.Kind {
+		case "MutatingWebhookConfiguration":
+			obj = &admissionregv1.MutatingWebhookConfiguration{}
+		case "ValidatingWebhookConfiguration":
+			obj = &admissionregv1.ValidatingWebhookConfiguration{}
+		default:
+			continue
+		}
+		if err := yaml.Unmarshal(doc, obj); err != nil {
+			return nil, fmt.Errorf("unable to parse existing %s: %w", typeMeta.Kind, err)
+		}
+		webhooks, err := webhooksOf(obj)
+		if err != nil {
+			return nil, err
+		}
+		for id, webhook := range webhooks {
+			res[id] = webhook
+		}
+	}
+	return res, nil
+}
+
+// readAuditManifests reads the manifests to audit against from the given
+// file, or from the YAML files in the given directory.
+func readAuditManifests(ctx *genall.GenerationContext, path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return ctx.ReadFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var docs [][]byte
+	for _, entry := range entries {
+		if entry.IsDir() || (filepath.Ext(entry.Name()) != ".yaml" && filepath.Ext(entry.Name()) != ".yml") {
+			continue
+		}
+		doc, err := ctx.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return bytes.Join(docs, []byte("\n---\n")), nil
+}
+
+// ptrTo returns a pointer to the given value.
+func ptrTo[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,124 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// certManagerInjectAnnotation asks the cert-manager CA injector to inject
+	// the CA of the given Certificate into an object.
+	certManagerInjectAnnotation = "cert-manager.io/inject-ca-from"
+
+	// serviceNamespacePlaceholder is replaced with the namespace of the
+	// webhook service in the certManagerCertificate option.
+	serviceNamespacePlaceholder = "$(SERVICE_NAMESPACE)"
+)
+
+// caInjection describes how the CA of the webhook server is injected into
+// the generated objects.
+type caInjection struct {
+	// caBundle is the CA bundle to set on client configs, if any.
+	caBundle []byte
+	// certificate is the cert-manager Certificate to inject the CA from, if
+	// any, possibly containing the service namespace placeholder.
+	certificate string
+}
+
+// caInjection returns the CA injection configured by the generator options.
+func (g Generator) caInjection() (caInjection, error) {
+	var injection caInjection
+	if g.CABundle != "" {
+		caBundle, err := base64.StdEncoding.DecodeString(g.CABundle)
+		if err != nil {
+			return injection, fmt.Errorf("caBundle must be base64-encoded: %w", err)
+		}
+		injection.caBundle = caBundle
+	}
+	if g.CertManagerCertificate != "" {
+		injection.certificate = g.CertManagerCertificate
+		if !strings.Contains(injection.certificate, "/") {
+			injection.certificate = serviceNamespacePlaceholder + "/" + injection.certificate
+		}
+	}
+	return injection, nil
+}
+
+// inject sets the CA bundle, if any, on the given client configs of the webhooks
+// of the given object, and adds the cert-manager annotation, if any, to it.
+func (c caInjection) inject(obj *metav1.ObjectMeta, clientConfigs []*admissionregv1.WebhookClientConfig) error {
+	var namespaces []string
+	for _, clientConfig := range clientConfigs {
+		if c.caBundle != nil {
+			clientConfig.CABundle = c.caBundle
+		}
+		if clientConfig.Service != nil {
+			namespaces = append(namespaces, clientConfig.Service.Namespace)
+		}
+	}
+	if !strings.Contains(c.certificate, serviceNamespacePlaceholder) {
+		return c.annotate(obj, "")
+	}
+	namespace, err := commonNamespace(obj.Name, namespaces)
+	if err != nil {
+		return err
+	}
+	return c.annotate(obj, namespace)
+}
+
+// annotate adds the cert-manager annotation, if any, to the given object, for a
+// webhook service in the given namespace.  The namespace may be empty if the
+// webhooks of the object aren't served by a service.
+func (c caInjection) annotate(obj *metav1.ObjectMeta, serviceNamespace string) error {
+	if c.certificate == "" {
+		return nil
+	}
+	certificate := c.certificate
+	if strings.Contains(certificate, serviceNamespacePlaceholder) {
+		if serviceNamespace == "" {
+			return fmt.Errorf("the namespace of certManagerCertificate %q for %s can't be derived, since its webhooks aren't served by a service", c.certificate, obj.Name)
+		}
+		certificate = strings.ReplaceAll(certificate, serviceNamespacePlaceholder, serviceNamespace)
+	}
+	if obj.Annotations == nil {
+		obj.Annotations = make(map[string]string)
+	}
+	obj.Annotations[certManagerInjectAnnotation] = certificate
+	return nil
+}
+
+// commonNamespace returns the namespace shared by the given service namespaces
+// (ignoring empty ones), or an error if they differ.
+func commonNamespace(name string, namespaces []string) (string, error) {
+	var common string
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			continue
+		}
+		if common != "" && namespace != common {
+			return "", fmt.Errorf("the webhooks of %s are served by services in different namespaces (%s and %s), so the namespace of the cert-manager certificate is ambiguous", name, common, namespace)
+		}
+		common = namespace
+	}
+	return common, nil
+}
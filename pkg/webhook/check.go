@@ -0,0 +1,171 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"go/ast"
+	"slices"
+	"strings"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// supportedAdmissionReviewVersions are the versions of AdmissionReview that
+// webhook servers (like the one of controller-runtime) can be asked to use.
+var supportedAdmissionReviewVersions = []string{"v1", "v1beta1"}
+
+// webhookMarker is a webhook marker, along with where it was declared.
+type webhookMarker struct {
+	cfg     Config
+	root    *loader.Package
+	comment *ast.Comment
+}
+
+// webhookMarkers returns the webhook markers of the given package, in the
+// order they're declared.
+func webhookMarkers(root *loader.Package) []webhookMarker {
+	root.NeedSyntax()
+	var res []webhookMarker
+	for _, file := range root.Syntax {
+		for _, group := range file.Comments {
+			for lines, text := range markers.MarkersIn(group.List) {
+				if !strings.HasPrefix(text, "+"+ConfigDefinition.Name+":") {
+					continue
+				}
+				cfg, err := ConfigDefinition.Parse(text)
+				if err != nil {
+					// reported by the marker collector
+					continue
+				}
+				res = append(res, webhookMarker{cfg: cfg.(Config), root: root, comment: lines[0]})
+			}
+		}
+	}
+	return res
+}
+
+// checkWebhooks checks for webhook markers of the given packages that can't
+// work when deployed, adding errors at the positions of the offending markers.
+// Returns whether any errors were found.
+//
+// Besides the settings the API server would reject (see checkWebhook),
+// this checks for multiple webhooks for different resources served on the
+// same path, which would only fail at runtime.
+func (g Generator) checkWebhooks(roots []*loader.Package) bool {
+	found := false
+	byPath := make(map[string]webhookMarker)
+	for _, root := range roots {
+		for _, marker := range webhookMarkers(root) {
+			cfg, err := g.withDefaults(marker.cfg)
+			if err != nil {
+				// reported when generating the webhook
+				continue
+			}
+			for _, err := range checkWebhook(cfg) {
+				root.AddError(loader.ErrFromNode(err, marker.comment))
+				found = true
+			}
+
+			if marker.cfg.Path == "" {
+				// served from an external URL
+				continue
+			}
+			other, seen := byPath[marker.cfg.Path]
+			if !seen {
+				byPath[marker.cfg.Path] = marker
+				continue
+			}
+			if resources, otherResources := webhookResources(marker.cfg), webhookResources(other.cfg); resources != otherResources {
+				// report both markers, since either may be wrong
+				other.root.AddError(loader.ErrFromNode(fmt.Errorf("path %s is served by webhooks for different resources: %s here, and %s by another webhook",
+					other.cfg.Path, otherResources, resources), other.comment))
+				root.AddError(loader.ErrFromNode(fmt.Errorf("path %s is served by webhooks for different resources: %s here, and %s by another webhook",
+					marker.cfg.Path, resources, otherResources), marker.comment))
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// checkWebhook checks the settings of the given webhook (with the defaults of
+// the generator applied) against the requirements of the API server for
+// admissionregistration.k8s.io/v1 webhooks, returning an error for each
+// violation that explains how to fix it.
+func checkWebhook(cfg Config) []error {
+	var errs []error
+
+	switch strings.ToLower(cfg.SideEffects) {
+	case strings.ToLower(string(admissionregv1.SideEffectClassNone)), strings.ToLower(string(admissionregv1.SideEffectClassNoneOnDryRun)):
+	case "":
+		errs = append(errs, fmt.Errorf("sideEffects is required: set it to None if the webhook has no side effects, or to NoneOnDryRun if it skips them on dry-run requests (or set the defaultSideEffects option)"))
+	case strings.ToLower(string(admissionregv1.SideEffectClassSome)), strings.ToLower(string(admissionregv1.SideEffectClassUnknown)):
+		errs = append(errs, fmt.Errorf("sideEffects=%s is not allowed for v1 webhooks, since dry-run requests must not have side effects: "+
+			"set it to NoneOnDryRun and skip the side effects when the request has dryRun set, or to None if there are none", cfg.SideEffects))
+	default:
+		errs = append(errs, fmt.Errorf("unknown value %q for sideEffects, must be None or NoneOnDryRun", cfg.SideEffects))
+	}
+
+	switch strings.ToLower(cfg.FailurePolicy) {
+	case strings.ToLower(string(admissionregv1.Fail)), strings.ToLower(string(admissionregv1.Ignore)):
+	default:
+		errs = append(errs, fmt.Errorf("unknown value %q for failurePolicy, must be Fail or Ignore", cfg.FailurePolicy))
+	}
+
+	if _, err := cfg.matchPolicy(); err != nil {
+		errs = append(errs, fmt.Errorf("%w, must be Exact or Equivalent", err))
+	}
+
+	if cfg.TimeoutSeconds != 0 && (cfg.TimeoutSeconds < 1 || cfg.TimeoutSeconds > 30) {
+		errs = append(errs, fmt.Errorf("timeoutSeconds=%d is out of range, must be between 1 and 30 (or unset, for the default of 10)", cfg.TimeoutSeconds))
+	}
+
+	if len(cfg.AdmissionReviewVersions) == 0 {
+		errs = append(errs, fmt.Errorf("admissionReviewVersions must not be empty, e.g. set admissionReviewVersions=v1"))
+	}
+	seen := make(map[string]bool, len(cfg.AdmissionReviewVersions))
+	for _, version := range cfg.AdmissionReviewVersions {
+		switch {
+		case !slices.Contains(supportedAdmissionReviewVersions, version):
+			errs = append(errs, fmt.Errorf("unsupported admissionReviewVersion %q, must be one of %s",
+				version, strings.Join(supportedAdmissionReviewVersions, ", ")))
+		case seen[version]:
+			errs = append(errs, fmt.Errorf("duplicate admissionReviewVersion %q", version))
+		}
+		seen[version] = true
+	}
+
+	return errs
+}
+
+// webhookResources describes the group-version-resources a webhook is for.
+func webhookResources(cfg Config) string {
+	groups := make([]string, 0, len(cfg.Groups))
+	for _, group := range cfg.Groups {
+		if group == "" {
+			group = "core"
+		}
+		groups = append(groups, group)
+	}
+	slices.Sort(groups)
+	versions := slices.Sorted(slices.Values(cfg.Versions))
+	resources := slices.Sorted(slices.Values(cfg.Resources))
+	return fmt.Sprintf("%s/%s %s", strings.Join(groups, ";"), strings.Join(versions, ";"), strings.Join(resources, ";"))
+}
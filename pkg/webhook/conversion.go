@@ -0,0 +1,203 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crdgen "sigs.k8s.io/controller-tools/pkg/crd"
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+// defaultConversionPath is the path conversion webhooks are served on by
+// controller-runtime.
+const defaultConversionPath = "/convert"
+
+// ConversionHubDefinition is a marker for marking the hub version of a kind
+// for conversion.
+var ConversionHubDefinition = markers.Must(markers.MakeDefinition("kubebuilder:conversion:hub", markers.DescribesType, ConversionHub{}))
+
+// +controllertools:marker:generateHelp:category=Webhook
+
+// ConversionHub marks this version of a kind as the hub version for conversion.
+//
+// The other (spoke) versions of the kind convert to and from the hub version,
+// as with the Hub and Convertible interfaces of controller-runtime.  With the
+// conversion option of the webhook generator, a CRD patch enabling the
+// conversion webhook is generated for each kind with multiple versions and a
+// hub version.
+//
+// Example:
+//
+//	// +kubebuilder:conversion:hub
+//	type MyKind struct {
+//	    metav1.TypeMeta
+//	    metav1.ObjectMeta
+//	}
+type ConversionHub struct{}
+
+// crdConversionPatch is a (strategic merge) patch for a CustomResourceDefinition
+// enabling its conversion webhook.
+type crdConversionPatch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              crdConversionPatchSpec `json:"spec"`
+}
+
+// crdConversionPatchSpec is the spec of a crdConversionPatch.
+type crdConversionPatchSpec struct {
+	Conversion apiextensionsv1.CustomResourceConversion `json:"conversion"`
+}
+
+// conversionService returns the service serving the conversion webhook, i.e.
+// the service of the webhooks in the given configs, so that all webhooks are
+// served by the same service.
+func conversionService(cfgs []Config, path string) (*apiextensionsv1.ServiceReference, error) {
+	service := &apiextensionsv1.ServiceReference{
+		Name:      defaultServiceName,
+		Namespace: defaultServiceNamespace,
+		Path:      &path,
+	}
+	found := false
+	for _, cfg := range cfgs {
+		if cfg.Path == "" {
+			// served from an external URL
+			continue
+		}
+		clientConfig, err := cfg.clientConfig()
+		if err != nil {
+			return nil, err
+		}
+		cfgService := clientConfig.Service
+		if found && (cfgService.Name != service.Name || cfgService.Namespace != service.Namespace || !ptrEqual(cfgService.Port, service.Port)) {
+			return nil, fmt.Errorf("webhooks are served by multiple services, so the service of the conversion webhook is ambiguous")
+		}
+		service.Name, service.Namespace, service.Port = cfgService.Name, cfgService.Namespace, cfgService.Port
+		found = true
+	}
+	return service, nil
+}
+
+// generateConversionPatches generates a CRD patch enabling the conversion
+// webhook for each kind in the roots with multiple versions and a hub version.
+func (g Generator) generateConversionPatches(ctx *genall.GenerationContext, cfgs []Config, injection caInjection, headerText string) error {
+	path := g.ConversionPath
+	if path == "" {
+		path = defaultConversionPath
+	}
+	service, err := conversionService(cfgs, path)
+	if err != nil {
+		return err
+	}
+
+	checker := ctx.Checker
+	if checker == nil {
+		// the generator doesn't need type-checking unless converting
+		checker = &loader.TypeChecker{}
+	}
+	parser := &crdgen.Parser{
+		Collector: ctx.Collector,
+		Checker:   checker,
+		Kinds:     ctx.Kinds,
+		Cache:     ctx.Cache,
+	}
+	crdgen.AddKnownTypes(parser)
+	for _, root := range ctx.Roots {
+		parser.NeedPackage(root)
+	}
+	metav1Pkg := crdgen.FindMetav1(ctx.Roots)
+	if metav1Pkg == nil {
+		// no objects in the roots, since nothing imported metav1
+		return nil
+	}
+
+	kubeKinds := crdgen.FindKubeKinds(parser, metav1Pkg)
+	slices.SortFunc(kubeKinds, func(a, b schema.GroupKind) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, groupKind := range kubeKinds {
+		var hubs []string
+		for pkg, gv := range parser.GroupVersions {
+			if gv.Group != groupKind.Group {
+				continue
+			}
+			info, known := parser.Types[crdgen.TypeIdent{Package: pkg, Name: groupKind.Kind}]
+			if known && info.Markers.Get(ConversionHubDefinition.Name) != nil {
+				hubs = append(hubs, gv.Version)
+			}
+		}
+		if len(hubs) == 0 {
+			continue
+		}
+		if len(hubs) > 1 {
+			slices.Sort(hubs)
+			return fmt.Errorf("%s has multiple hub versions for conversion: %s", groupKind, strings.Join(hubs, ", "))
+		}
+
+		parser.NeedCRDFor(groupKind, nil)
+		crd, exists := parser.CustomResourceDefinitions[groupKind]
+		if !exists || len(crd.Spec.Versions) < 2 {
+			// nothing to convert between
+			continue
+		}
+
+		patch := crdConversionPatch{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+				Kind:       "CustomResourceDefinition",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: crd.Name,
+			},
+			Spec: crdConversionPatchSpec{
+				Conversion: apiextensionsv1.CustomResourceConversion{
+					Strategy: apiextensionsv1.WebhookConverter,
+					Webhook: &apiextensionsv1.WebhookConversion{
+						ClientConfig: &apiextensionsv1.WebhookClientConfig{
+							Service:  service,
+							CABundle: injection.caBundle,
+						},
+						ConversionReviewVersions: []string{"v1"},
+					},
+				},
+			},
+		}
+		if err := injection.annotate(&patch.ObjectMeta, service.Namespace); err != nil {
+			return err
+		}
+		fileName := fmt.Sprintf("conversion_%s_%s.yaml", crd.Spec.Group, crd.Spec.Names.Plural)
+		if err := ctx.WriteYAML(fileName, headerText, []any{patch}, genall.WithTransform(genall.TransformRemoveCreationTimestamp)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ptrEqual checks if the given pointers are both nil, or point to equal values.
+func ptrEqual[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,283 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+)
+
+// envtestImports are the packages imported by the generated test suite,
+// by the names the suite refers to them with.
+var envtestImports = []struct{ name, pkgName, path string }{
+	{"context", "context", "context"},
+	{"tls", "tls", "crypto/tls"},
+	{"net", "net", "net"},
+	{"filepath", "filepath", "path/filepath"},
+	{"strconv", "strconv", "strconv"},
+	{"sync", "sync", "sync"},
+	{"testing", "testing", "testing"},
+	{"time", "time", "time"},
+	{"apierrors", "errors", "k8s.io/apimachinery/pkg/api/errors"},
+	{"meta", "meta", "k8s.io/apimachinery/pkg/api/meta"},
+	{"unstructured", "unstructured", "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"},
+	{"schema", "schema", "k8s.io/apimachinery/pkg/runtime/schema"},
+	{"client", "client", "sigs.k8s.io/controller-runtime/pkg/client"},
+	{"envtest", "envtest", "sigs.k8s.io/controller-runtime/pkg/envtest"},
+	{"manager", "manager", managerPath},
+	{"metricsserver", "server", "sigs.k8s.io/controller-runtime/pkg/metrics/server"},
+	{"webhook", "webhook", "sigs.k8s.io/controller-runtime/pkg/webhook"},
+	{"admission", "admission", admissionPath},
+}
+
+// envtestSample is a webhook exercised by the generated test suite, with
+// the resource of its sample object.
+type envtestSample struct {
+	Name, Ident              string
+	Group, Version, Resource string
+}
+
+// generateEnvtestSuite generates a test suite checking that the given
+// webhooks of the given package are called by the API server, using the
+// webhook configurations (and CRDs) in the given directories.
+func generateEnvtestSuite(root *loader.Package, webhooks []registeredWebhook, headerText, manifestsDir string, crdDirs []string) []byte {
+	taken := packageIdentifiers(root)
+	// declared by the generated registration code and the suite itself
+	for _, webhook := range webhooks {
+		taken[webhook.ident+"Path"] = true
+	}
+	taken["TestGeneratedWebhooks"] = true
+	taken["generatedWebhookRecorder"] = true
+	taken["generatedWebhookSample"] = true
+
+	aliases := make(map[string]string, len(envtestImports))
+	var stdImports, imports []string
+	for _, imp := range envtestImports {
+		aliases[imp.name] = importAlias(imp.name, taken)
+		spec := importSpec(aliases[imp.name], imp.pkgName, imp.path)
+		if first, _, _ := strings.Cut(imp.path, "/"); !strings.Contains(first, ".") {
+			stdImports = append(stdImports, spec)
+		} else {
+			imports = append(imports, spec)
+		}
+	}
+
+	idents := make([]string, 0, len(webhooks))
+	var samples []envtestSample
+	for _, webhook := range webhooks {
+		idents = append(idents, webhook.ident)
+		cfg := webhook.cfg
+		if !slices.Contains(cfg.Verbs, "create") && !slices.Contains(cfg.Verbs, "*") {
+			continue
+		}
+		if len(cfg.Groups) == 0 || len(cfg.Versions) == 0 || len(cfg.Resources) == 0 ||
+			cfg.Groups[0] == "*" || cfg.Versions[0] == "*" || strings.Contains(cfg.Resources[0], "*") {
+			// no specific resource to create a sample of
+			continue
+		}
+		resource, _, _ := strings.Cut(cfg.Resources[0], "/")
+		samples = append(samples, envtestSample{
+			Ident:    webhook.ident,
+			Group:    cfg.Groups[0],
+			Version:  cfg.Versions[0],
+			Resource: resource,
+			Name:     cmp.Or(cfg.Name, cfg.Path),
+		})
+	}
+
+	out := new(bytes.Buffer)
+	err := envtestSuiteTemplate.Execute(out, map[string]any{
+		"Package":      root.Name,
+		"Header":       headerText,
+		"StdImports":   stdImports,
+		"Imports":      imports,
+		"A":            aliases,
+		"ManifestsDir": manifestsDir,
+		"CRDDirs":      crdDirs,
+		"Idents":       idents,
+		"Samples":      samples,
+	})
+	if err != nil {
+		root.AddError(err)
+	}
+	return gogen.Format(root, out.Bytes())
+}
+
+// envtestSuiteTemplate is the template of the generated test suite.  The
+// aliases of the imported packages are in .A, by package name.
+var envtestSuiteTemplate = template.Must(template.New("envtest").Parse(`//go:build !ignore_autogenerated
+
+{{ .Header }}
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .StdImports }}
+	{{ . }}
+{{- end }}
+{{ range .Imports }}
+	{{ . }}
+{{- end }}
+)
+
+// generatedWebhookRecorder is a handler recording the paths of the webhooks
+// it's called for, and allowing all requests.
+type generatedWebhookRecorder struct {
+	mu     {{ .A.sync }}.Mutex
+	called map[string]bool
+}
+
+func (r *generatedWebhookRecorder) handlerFor(path string) {{ .A.admission }}.Handler {
+	return {{ .A.admission }}.HandlerFunc(func(_ {{ .A.context }}.Context, _ {{ .A.admission }}.Request) {{ .A.admission }}.Response {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.called[path] = true
+		return {{ .A.admission }}.Allowed("")
+	})
+}
+
+func (r *generatedWebhookRecorder) wasCalled(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.called[path]
+}
+
+// generatedWebhookSample is a webhook, with the resource to create a sample
+// object of to call it.
+type generatedWebhookSample struct {
+	name     string
+	path     string
+	resource {{ .A.schema }}.GroupVersionResource
+}
+
+// TestGeneratedWebhooks checks that the webhook configurations call the
+// handlers registered by SetupWebhooksWithManager, on the same paths.
+func TestGeneratedWebhooks(t *{{ .A.testing }}.T) {
+	env := &{{ .A.envtest }}.Environment{
+{{- if .CRDDirs }}
+		CRDDirectoryPaths: []string{
+{{- range .CRDDirs }}
+			{{ $.A.filepath }}.FromSlash({{ printf "%q" . }}),
+{{- end }}
+		},
+		ErrorIfCRDPathMissing: true,
+{{- end }}
+		WebhookInstallOptions: {{ .A.envtest }}.WebhookInstallOptions{
+			Paths: []string{ {{- .A.filepath }}.FromSlash({{ printf "%q" .ManifestsDir }})},
+		},
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("unable to start the test environment: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Errorf("unable to stop the test environment: %v", err)
+		}
+	})
+
+	opts := &env.WebhookInstallOptions
+	mgr, err := {{ .A.manager }}.New(cfg, {{ .A.manager }}.Options{
+		Metrics: {{ .A.metricsserver }}.Options{BindAddress: "0"},
+		WebhookServer: {{ .A.webhook }}.NewServer({{ .A.webhook }}.Options{
+			Host:    opts.LocalServingHost,
+			Port:    opts.LocalServingPort,
+			CertDir: opts.LocalServingCertDir,
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unable to create the manager: %v", err)
+	}
+
+	recorder := &generatedWebhookRecorder{called: make(map[string]bool)}
+	if err := SetupWebhooksWithManager(mgr, WebhookHandlers{
+{{- range .Idents }}
+		{{ . }}: recorder.handlerFor({{ . }}Path),
+{{- end }}
+	}); err != nil {
+		t.Fatalf("unable to set up the webhooks: %v", err)
+	}
+
+	ctx, cancel := {{ .A.context }}.WithCancel({{ .A.context }}.Background())
+	t.Cleanup(cancel)
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			t.Errorf("unable to start the manager: %v", err)
+		}
+	}()
+
+	addr := {{ .A.net }}.JoinHostPort(opts.LocalServingHost, {{ .A.strconv }}.Itoa(opts.LocalServingPort))
+	deadline := {{ .A.time }}.Now().Add(10 * {{ .A.time }}.Second)
+	for {
+		conn, err := {{ .A.tls }}.DialWithDialer(&{{ .A.net }}.Dialer{Timeout: {{ .A.time }}.Second}, "tcp", addr, &{{ .A.tls }}.Config{InsecureSkipVerify: true}) //nolint:gosec
+		if err == nil {
+			_ = conn.Close()
+			break
+		}
+		if {{ .A.time }}.Now().After(deadline) {
+			t.Fatalf("the webhook server isn't serving: %v", err)
+		}
+		{{ .A.time }}.Sleep(100 * {{ .A.time }}.Millisecond)
+	}
+
+	c, err := {{ .A.client }}.New(cfg, {{ .A.client }}.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		t.Fatalf("unable to create a client: %v", err)
+	}
+	samples := []generatedWebhookSample{
+{{- range .Samples }}
+		{name: {{ printf "%q" .Name }}, path: {{ .Ident }}Path, resource: {{ $.A.schema }}.GroupVersionResource{Group: {{ printf "%q" .Group }}, Version: {{ printf "%q" .Version }}, Resource: {{ printf "%q" .Resource }}}},
+{{- end }}
+	}
+	for _, sample := range samples {
+		t.Run(sample.name, func(t *{{ .A.testing }}.T) {
+			gvk, err := mgr.GetRESTMapper().KindFor(sample.resource)
+			if err != nil {
+				t.Fatalf("unknown resource %s: %v", sample.resource, err)
+			}
+			mapping, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				t.Fatalf("unknown kind %s: %v", gvk, err)
+			}
+			obj := &{{ .A.unstructured }}.Unstructured{}
+			obj.SetGroupVersionKind(gvk)
+			obj.SetGenerateName("sample-")
+			if mapping.Scope.Name() == {{ .A.meta }}.RESTScopeNameNamespace {
+				obj.SetNamespace("default")
+			}
+
+			err = c.Create(ctx, obj, {{ .A.client }}.DryRunAll)
+			if recorder.wasCalled(sample.path) {
+				return
+			}
+			if {{ .A.apierrors }}.IsInvalid(err) {
+				t.Skipf("the empty sample %s is invalid, so the webhook isn't called: %v", gvk.Kind, err)
+			}
+			t.Errorf("creating a sample %s didn't call the webhook on path %s (err: %v), check that the webhook configurations in %s are up to date",
+				gvk.Kind, sample.path, err, {{ printf "%q" .ManifestsDir }})
+		})
+	}
+}
+`))
@@ -19,20 +19,26 @@ limitations under the License.
 //
 // The markers take the form:
 //
-//	+kubebuilder:webhook:webhookVersions=<[]string>,failurePolicy=<string>,matchPolicy=<string>,groups=<[]string>,resources=<[]string>,verbs=<[]string>,versions=<[]string>,name=<string>,path=<string>,mutating=<bool>,sideEffects=<string>,timeoutSeconds=<int>,admissionReviewVersions=<[]string>,reinvocationPolicy=<string>
+//	+kubebuilder:webhook:webhookVersions=<[]string>,failurePolicy=<string>,matchPolicy=<string>,groups=<[]string>,resources=<[]string>,verbs=<[]string>,versions=<[]string>,name=<string>,path=<string>,mutating=<bool>,sideEffects=<string>,timeoutSeconds=<int>,admissionReviewVersions=<[]string>,reinvocationPolicy=<string>,matchConditions=<map[string]string>,namespaceSelector=<string>,objectSelector=<string>
 package webhook
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
+	"github.com/gobuffalo/flect"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation"
+	crdmarkers "sigs.k8s.io/controller-tools/pkg/crd/markers"
 	"sigs.k8s.io/controller-tools/pkg/genall"
 	"sigs.k8s.io/controller-tools/pkg/markers"
 )
@@ -43,6 +49,9 @@ const (
 	defaultWebhookVersion   = v1
 	defaultServiceName      = "webhook-service"
 	defaultServiceNamespace = "system"
+
+	// maxMatchConditions is the maximum number of match conditions of a webhook.
+	maxMatchConditions = 64
 )
 
 var (
@@ -108,7 +117,9 @@ type Config struct {
 	// It may be either "ignore" (to skip the webhook and continue on) or "fail" (to reject
 	// the object in question). Most webhooks should use "fail" to ensure the webhook logic
 	// is always executed.
-	FailurePolicy string
+	//
+	// Required, unless the defaultFailurePolicy option of the generator is set.
+	FailurePolicy string `marker:",optional"`
 
 	// MatchPolicy defines how the "rules" list is used to match incoming requests.
 	// Allowed values are "Exact" (match only if it exactly matches the specified rule)
@@ -123,12 +134,14 @@ type Config struct {
 	// If the value is "NoneOnDryRun", then the webhook is responsible for inspecting the "dryRun" property of the
 	// AdmissionReview sent in the request, and avoiding side effects if that value is "true."
 	// Most webhooks should use "None".
+	// Defaults to the defaultSideEffects option of the generator, if set.
 	SideEffects string `marker:",optional"`
 
 	// TimeoutSeconds allows configuring how long the API server should wait for a webhook to respond before treating the call as a failure.
 	// If the timeout expires before the webhook responds, the webhook call will be ignored or the API call will be rejected based on the failure policy.
 	// The timeout value must be between 1 and 30 seconds.
-	// The timeout for an admission webhook defaults to 10 seconds.
+	// The timeout for an admission webhook defaults to the defaultTimeoutSeconds option of the
+	// generator if set, or else to 10 seconds.
 	TimeoutSeconds int `marker:",optional"`
 
 	// Groups specifies the API groups that this webhook receives requests for.
@@ -155,7 +168,9 @@ type Config struct {
 
 	// Name indicates the name of this webhook configuration. Should be a domain with at least three segments separated by dots
 	// Example: "myresource.mygroup.example.com".
-	Name string
+	//
+	// Required, unless the nameTemplate option of the generator is set.
+	Name string `marker:",optional"`
 
 	// ServiceName indicates the name of the K8s Service the webhook uses.
 	// Defaults to "webhook-service" if not specified.
@@ -193,8 +208,39 @@ type Config struct {
 	// built-in mutating admission plugins are re-run if a mutating webhook modifies
 	// an object, and mutating webhooks can specify a reinvocationPolicy to control
 	// whether they are reinvoked as well. May be "Never" or "IfNeeded". Defaults to "Never".
+	//
+	// "IfNeeded" is needed e.g. for defaulting webhooks that must see the changes made by
+	// sidecar injectors.  It's ignored for validating webhooks, which are never reinvoked.
 	ReinvocationPolicy string `marker:"reinvocationPolicy,optional"`
 
+	// MatchConditions are CEL expressions, by name, that must all be true for a request to be
+	// sent to the webhook, so that it can pre-filter requests that match its rules.
+	//
+	// The expressions have access to the object, oldObject, request and authorizer variables.
+	// Names must be qualified names (like label keys).  At most 64 conditions may be given.
+	//
+	// Example:
+	//
+	//	// +kubebuilder:webhook:...,matchConditions={exclude-leases: "!(request.resource.group == 'coordination.k8s.io' && request.resource.resource == 'leases')"}
+	MatchConditions map[string]string `marker:"matchConditions,optional"`
+
+	// NamespaceSelector limits the webhook to objects in namespaces matching the given label
+	// selector, in the usual label selector syntax (as used by kubectl).  Set-based requirements
+	// become matchExpressions, and equality-based ones become matchLabels.
+	//
+	// Example (skip kube-system and the webhook's own namespace):
+	//
+	//	// +kubebuilder:webhook:...,namespaceSelector="kubernetes.io/metadata.name notin (kube-system,my-operator-system)"
+	NamespaceSelector string `marker:"namespaceSelector,optional"`
+
+	// ObjectSelector limits the webhook to objects with labels matching the given label
+	// selector, in the usual label selector syntax (as used by kubectl).
+	//
+	// Example:
+	//
+	//	// +kubebuilder:webhook:...,objectSelector="!example.com/skip-webhook"
+	ObjectSelector string `marker:"objectSelector,optional"`
+
 	// URL allows mutating webhooks configuration to specify an external URL when generating
 	// the manifests, instead of using the internal service communication. Should be in format of
 	// https://address:port/path
@@ -205,8 +251,8 @@ type Config struct {
 
 	// Patch applies a strategic merge patch to customize the generated webhook configuration.
 	//
-	// This allows you to set any webhook field that isn't directly exposed as a marker parameter,
-	// such as namespaceSelector, objectSelector, or matchConditions. The patch is a JSON object
+	// This allows you to set any webhook field, including the ones that aren't directly exposed
+	// as marker parameters. The patch is a JSON object
 	// that follows Kubernetes strategic merge patch semantics and is applied to the webhook
 	// configuration after all other marker parameters are processed.
 	//
@@ -324,6 +370,26 @@ func (c Config) ToMutatingWebhook() (admissionregv1.MutatingWebhook, error) {
 		return admissionregv1.MutatingWebhook{}, err
 	}
 
+	matchConditions, err := c.matchConditions()
+	if err != nil {
+		return admissionregv1.MutatingWebhook{}, err
+	}
+
+	namespaceSelector, err := labelSelector("namespaceSelector", c.NamespaceSelector)
+	if err != nil {
+		return admissionregv1.MutatingWebhook{}, err
+	}
+
+	objectSelector, err := labelSelector("objectSelector", c.ObjectSelector)
+	if err != nil {
+		return admissionregv1.MutatingWebhook{}, err
+	}
+
+	reinvocationPolicy, err := c.reinvocationPolicy()
+	if err != nil {
+		return admissionregv1.MutatingWebhook{}, err
+	}
+
 	webhook := admissionregv1.MutatingWebhook{
 		Name:                    c.Name,
 		Rules:                   c.rules(),
@@ -333,7 +399,10 @@ func (c Config) ToMutatingWebhook() (admissionregv1.MutatingWebhook, error) {
 		SideEffects:             c.sideEffects(),
 		TimeoutSeconds:          c.timeoutSeconds(),
 		AdmissionReviewVersions: c.AdmissionReviewVersions,
-		ReinvocationPolicy:      c.reinvocationPolicy(),
+		ReinvocationPolicy:      reinvocationPolicy,
+		MatchConditions:         matchConditions,
+		NamespaceSelector:       namespaceSelector,
+		ObjectSelector:          objectSelector,
 	}
 
 	// Apply strategic merge patch if provided
@@ -360,6 +429,21 @@ func (c Config) ToValidatingWebhook() (admissionregv1.ValidatingWebhook, error)
 		return admissionregv1.ValidatingWebhook{}, err
 	}
 
+	matchConditions, err := c.matchConditions()
+	if err != nil {
+		return admissionregv1.ValidatingWebhook{}, err
+	}
+
+	namespaceSelector, err := labelSelector("namespaceSelector", c.NamespaceSelector)
+	if err != nil {
+		return admissionregv1.ValidatingWebhook{}, err
+	}
+
+	objectSelector, err := labelSelector("objectSelector", c.ObjectSelector)
+	if err != nil {
+		return admissionregv1.ValidatingWebhook{}, err
+	}
+
 	webhook := admissionregv1.ValidatingWebhook{
 		Name:                    c.Name,
 		Rules:                   c.rules(),
@@ -369,6 +453,9 @@ func (c Config) ToValidatingWebhook() (admissionregv1.ValidatingWebhook, error)
 		SideEffects:             c.sideEffects(),
 		TimeoutSeconds:          c.timeoutSeconds(),
 		AdmissionReviewVersions: c.AdmissionReviewVersions,
+		MatchConditions:         matchConditions,
+		NamespaceSelector:       namespaceSelector,
+		ObjectSelector:          objectSelector,
 	}
 
 	// Apply strategic merge patch if provided
@@ -505,17 +592,55 @@ func (c Config) timeoutSeconds() *int32 {
 }
 
 // reinvocationPolicy returns the reinvocationPolicy config for a mutating webhook.
-func (c Config) reinvocationPolicy() *admissionregv1.ReinvocationPolicyType {
+func (c Config) reinvocationPolicy() (*admissionregv1.ReinvocationPolicyType, error) {
 	var reinvocationPolicy admissionregv1.ReinvocationPolicyType
 	switch strings.ToLower(c.ReinvocationPolicy) {
 	case strings.ToLower(string(admissionregv1.NeverReinvocationPolicy)):
 		reinvocationPolicy = admissionregv1.NeverReinvocationPolicy
 	case strings.ToLower(string(admissionregv1.IfNeededReinvocationPolicy)):
 		reinvocationPolicy = admissionregv1.IfNeededReinvocationPolicy
+	case "":
+		return nil, nil
 	default:
-		return nil
+		return nil, fmt.Errorf("unknown value %q for reinvocationPolicy, must be Never or IfNeeded", c.ReinvocationPolicy)
+	}
+	return &reinvocationPolicy, nil
+}
+
+// matchConditions returns the matchConditions config for a webhook, sorted by name.
+func (c Config) matchConditions() ([]admissionregv1.MatchCondition, error) {
+	if len(c.MatchConditions) > maxMatchConditions {
+		return nil, fmt.Errorf("%s has %d match conditions, at most %d are allowed", c.Name, len(c.MatchConditions), maxMatchConditions)
+	}
+
+	names := slices.Sorted(maps.Keys(c.MatchConditions))
+	var matchConditions []admissionregv1.MatchCondition
+	for _, name := range names {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid match condition name %q for %s: %s", name, c.Name, strings.Join(errs, ", "))
+		}
+		expression := strings.TrimSpace(c.MatchConditions[name])
+		if expression == "" {
+			return nil, fmt.Errorf("match condition %q for %s has an empty expression", name, c.Name)
+		}
+		matchConditions = append(matchConditions, admissionregv1.MatchCondition{
+			Name:       name,
+			Expression: expression,
+		})
 	}
-	return &reinvocationPolicy
+	return matchConditions, nil
+}
+
+// labelSelector parses the given label selector of a webhook, if set.
+func labelSelector(field, selector string) (*metav1.LabelSelector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+	labelSelector, err := metav1.ParseToLabelSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", field, selector, err)
+	}
+	return labelSelector, nil
 }
 
 // webhookVersions returns the target API versions of the {Mutating,Validating}WebhookConfiguration objects for a webhook.
@@ -537,6 +662,76 @@ func (c Config) webhookVersions() ([]string, error) {
 
 // Generator generates (partial) {Mutating,Validating}WebhookConfiguration objects.
 type Generator struct {
+	// Conversion enables generating CustomResourceDefinition patches that enable
+	// the conversion webhook of kinds with multiple versions and a hub version
+	// (marked with +kubebuilder:conversion:hub), written to
+	// conversion_<group>_<plural>.yaml.
+	//
+	// The conversion webhook is served by the same service as the other webhooks.
+	Conversion bool `marker:",optional"`
+
+	// ConversionPath specifies the path the conversion webhook is served on.
+	// Defaults to "/convert", as served by controller-runtime.
+	ConversionPath string `marker:",optional"`
+
+	// CABundle specifies the (base64-encoded) CA bundle to set on the client
+	// configs of the generated webhooks, e.g. a literal bundle, or a placeholder
+	// to be replaced when deploying.
+	CABundle string `marker:",optional"`
+
+	// CertManagerCertificate specifies the cert-manager Certificate
+	// ("<namespace>/<name>") whose CA is injected into the generated objects,
+	// by adding the cert-manager.io/inject-ca-from annotation.
+	//
+	// The namespace may be omitted, or contain "$(SERVICE_NAMESPACE)", to use
+	// the namespace of the webhook service.
+	CertManagerCertificate string `marker:",optional"`
+
+	// Split splits the generated configurations into one file per webhook
+	// service ("service") or per API group ("group"), written to
+	// <service namespace>-<service name>/manifests.yaml or
+	// <group>/manifests.yaml, so that independently deployed webhook servers
+	// can own their own manifests.  The names of the split configurations are
+	// suffixed with the same key, so that they don't conflict.
+	//
+	// Webhooks served from a URL are split into "external" when splitting by
+	// service, and webhooks for the core API group into "core" when splitting
+	// by group.
+	Split string `marker:",optional"`
+
+	// NameTemplate specifies the template of the names of webhooks whose markers
+	// don't set one, e.g. "{kind}.{group}.example.com".
+	//
+	// The template may contain {operation} ("mutate" or "validate"), {resource}
+	// (the first resource of the webhook), {kind} (its singular form), {group}
+	// (its group, or "core") and {version} (its first version).
+	NameTemplate string `marker:",optional"`
+
+	// AuditAgainst enables audit mode, comparing the generated webhook
+	// configurations against the ones in the given YAML file (or the YAML
+	// files in the given directory) instead of writing them out.
+	//
+	// Webhooks that are generated but missing, present but no longer
+	// generated, or whose settings or rules differ, are reported as errors.
+	AuditAgainst string `marker:",optional"`
+
+	// AuditCluster enables audit mode against the webhook configurations
+	// installed in the cluster of the run instead (see the --kubeconfig and
+	// --context flags), as with AuditAgainst.
+	AuditCluster bool `marker:",optional"`
+
+	// DefaultFailurePolicy specifies the failurePolicy of webhooks whose markers
+	// don't set one.
+	DefaultFailurePolicy string `marker:",optional"`
+
+	// DefaultSideEffects specifies the sideEffects of webhooks whose markers
+	// don't set them.
+	DefaultSideEffects string `marker:",optional"`
+
+	// DefaultTimeoutSeconds specifies the timeoutSeconds of webhooks whose
+	// markers don't set it.
+	DefaultTimeoutSeconds int `marker:",optional"`
+
 	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
 	HeaderFile string `marker:",optional"`
 
@@ -544,15 +739,25 @@ type Generator struct {
 	Year string `marker:",optional"`
 }
 
-func (Generator) RegisterMarkers(into *markers.Registry) error {
+func (g Generator) RegisterMarkers(into *markers.Registry) error {
 	if err := into.Register(ConfigDefinition); err != nil {
 		return err
 	}
 	if err := into.Register(WebhookConfigDefinition); err != nil {
 		return err
 	}
+	if err := into.Register(ConversionHubDefinition); err != nil {
+		return err
+	}
 	into.AddHelp(ConfigDefinition, Config{}.Help())
 	into.AddHelp(WebhookConfigDefinition, Config{}.Help())
+	into.AddHelp(ConversionHubDefinition, ConversionHub{}.Help())
+	if g.Conversion {
+		// needed to figure out the versions of kinds
+		if err := crdmarkers.Register(into); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -563,6 +768,18 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 	validatingCfgs := make(map[string][]admissionregv1.ValidatingWebhook, len(supportedWebhookVersions))
 	var mutatingWebhookCfgs admissionregv1.MutatingWebhookConfiguration
 	var validatingWebhookCfgs admissionregv1.ValidatingWebhookConfiguration
+	var allCfgs []Config
+
+	injection, err := g.caInjection()
+	if err != nil {
+		return err
+	}
+
+	if g.checkWebhooks(ctx.Roots) {
+		// the errors are reported at the markers, and the manifests would
+		// only fail at runtime
+		return nil
+	}
 
 	for _, root := range ctx.Roots {
 		markerSet, err := markers.PackageMarkers(ctx.Collector, root)
@@ -604,7 +821,11 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		})
 
 		for _, cfg := range cfgs {
-			cfg := cfg.(Config)
+			cfg, err := g.withDefaults(cfg.(Config))
+			if err != nil {
+				return err
+			}
+			allCfgs = append(allCfgs, cfg)
 			webhookVersions, err := cfg.webhookVersions()
 			if err != nil {
 				return err
@@ -633,8 +854,8 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 	//nolint:dupl
 	for _, version := range supportedWebhookVersions {
 		if cfgs, ok := mutatingCfgs[version]; ok {
-			slices.SortFunc(cfgs, func(a, b admissionregv1.MutatingWebhook) int {
-				return strings.Compare(a.Name, b.Name)
+			slices.SortStableFunc(cfgs, func(a, b admissionregv1.MutatingWebhook) int {
+				return compareWebhooks(a.Name, b.Name, a.ClientConfig, b.ClientConfig)
 			})
 			var objRaw *admissionregv1.MutatingWebhookConfiguration
 			if mutatingWebhookCfgs.Name != "" {
@@ -652,6 +873,14 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 			})
 			objRaw.Webhooks = cfgs
 
+			clientConfigs := make([]*admissionregv1.WebhookClientConfig, 0, len(objRaw.Webhooks))
+			for i := range objRaw.Webhooks {
+				clientConfigs = append(clientConfigs, &objRaw.Webhooks[i].ClientConfig)
+			}
+			if err := injection.inject(&objRaw.ObjectMeta, clientConfigs); err != nil {
+				return err
+			}
+
 			for i := range objRaw.Webhooks {
 				// SideEffects is required in admissionregistration/v1, if this is not set or set to `Some` or `Known`,
 				// return an error
@@ -673,8 +902,8 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		}
 
 		if cfgs, ok := validatingCfgs[version]; ok {
-			slices.SortFunc(cfgs, func(a, b admissionregv1.ValidatingWebhook) int {
-				return strings.Compare(a.Name, b.Name)
+			slices.SortStableFunc(cfgs, func(a, b admissionregv1.ValidatingWebhook) int {
+				return compareWebhooks(a.Name, b.Name, a.ClientConfig, b.ClientConfig)
 			})
 			var objRaw *admissionregv1.ValidatingWebhookConfiguration
 			if validatingWebhookCfgs.Name != "" {
@@ -692,6 +921,14 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 			})
 			objRaw.Webhooks = cfgs
 
+			clientConfigs := make([]*admissionregv1.WebhookClientConfig, 0, len(objRaw.Webhooks))
+			for i := range objRaw.Webhooks {
+				clientConfigs = append(clientConfigs, &objRaw.Webhooks[i].ClientConfig)
+			}
+			if err := injection.inject(&objRaw.ObjectMeta, clientConfigs); err != nil {
+				return err
+			}
+
 			for i := range objRaw.Webhooks {
 				// SideEffects is required in admissionregistration/v1, if this is not set or set to `Some` or `Known`,
 				// return an error
@@ -713,6 +950,38 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		}
 	}
 
+	if g.AuditAgainst != "" && g.AuditCluster {
+		return fmt.Errorf("auditAgainst and auditCluster are mutually exclusive")
+	}
+	if g.AuditAgainst != "" || g.AuditCluster {
+		source := g.AuditAgainst
+		var existing []byte
+		var err error
+		if g.AuditCluster {
+			source = "the cluster"
+			existing, err = ctx.Cluster.ExportManifests(context.Background(),
+				admissionregv1.SchemeGroupVersion.WithResource("mutatingwebhookconfigurations"),
+				admissionregv1.SchemeGroupVersion.WithResource("validatingwebhookconfigurations"))
+		} else {
+			existing, err = readAuditManifests(ctx, g.AuditAgainst)
+		}
+		if err != nil {
+			return err
+		}
+		drifts, err := AuditWebhooks(versionedWebhooks[defaultWebhookVersion], existing)
+		if err != nil {
+			return err
+		}
+		if len(drifts) == 0 {
+			return nil
+		}
+		msgs := make([]string, 0, len(drifts))
+		for _, drift := range drifts {
+			msgs = append(msgs, drift.String())
+		}
+		return fmt.Errorf("generated webhook configurations differ from %s:\n%s", source, strings.Join(msgs, "\n"))
+	}
+
 	var headerText string
 	if g.HeaderFile != "" {
 		headerBytes, err := ctx.ReadFile(g.HeaderFile)
@@ -730,13 +999,97 @@ func (g Generator) Generate(ctx *genall.GenerationContext) error {
 		} else {
 			fileName = fmt.Sprintf("manifests.%s.yaml", k)
 		}
-		if err := ctx.WriteYAML(fileName, headerText, v, genall.WithTransform(genall.TransformRemoveCreationTimestamp)); err != nil {
+		if g.Split == "" {
+			if err := ctx.WriteYAML(fileName, headerText, v, genall.WithTransform(genall.TransformRemoveCreationTimestamp)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		byKey, err := splitConfigurations(v, g.Split)
+		if err != nil {
 			return err
 		}
+		for _, key := range slices.Sorted(maps.Keys(byKey)) {
+			if err := ctx.WriteYAML(key+"/"+fileName, headerText, byKey[key], genall.WithTransform(genall.TransformRemoveCreationTimestamp)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if g.Conversion {
+		return g.generateConversionPatches(ctx, allCfgs, injection, headerText)
 	}
 	return nil
 }
 
+// nameFromTemplate returns the name of this webhook according to the given
+// name template.
+func (c Config) nameFromTemplate(template string) string {
+	first := func(values []string) string {
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+	operation := "validate"
+	if c.Mutating {
+		operation = "mutate"
+	}
+	resource, _, _ := strings.Cut(first(c.Resources), "/")
+	group := first(c.Groups)
+	if group == "" {
+		group = "core"
+	}
+	return strings.NewReplacer(
+		"{operation}", operation,
+		"{resource}", resource,
+		"{kind}", flect.Singularize(resource),
+		"{group}", group,
+		"{version}", first(c.Versions),
+	).Replace(template)
+}
+
+// withDefaults sets the fields of the given webhook config that aren't set by
+// its marker to the defaults of the generator.
+func (g Generator) withDefaults(cfg Config) (Config, error) {
+	if cfg.Name == "" {
+		if g.NameTemplate == "" {
+			return cfg, fmt.Errorf("name is required for webhook on path %s, unless the nameTemplate option is set", cfg.Path)
+		}
+		cfg.Name = cfg.nameFromTemplate(g.NameTemplate)
+	}
+	if cfg.FailurePolicy == "" {
+		if g.DefaultFailurePolicy == "" {
+			return cfg, fmt.Errorf("failurePolicy is required for webhook %s, unless the defaultFailurePolicy option is set", cfg.Name)
+		}
+		cfg.FailurePolicy = g.DefaultFailurePolicy
+	}
+	if cfg.SideEffects == "" {
+		cfg.SideEffects = g.DefaultSideEffects
+	}
+	if cfg.TimeoutSeconds == 0 {
+		cfg.TimeoutSeconds = g.DefaultTimeoutSeconds
+	}
+	return cfg, nil
+}
+
+// compareWebhooks orders webhooks canonically, by name and then by the path
+// or URL they're served on, so that adding a webhook doesn't reorder the others.
+func compareWebhooks(aName, bName string, aClientConfig, bClientConfig admissionregv1.WebhookClientConfig) int {
+	location := func(clientConfig admissionregv1.WebhookClientConfig) string {
+		switch {
+		case clientConfig.Service != nil && clientConfig.Service.Path != nil:
+			return *clientConfig.Service.Path
+		case clientConfig.URL != nil:
+			return *clientConfig.URL
+		default:
+			return ""
+		}
+	}
+	return cmp.Or(strings.Compare(aName, bName), strings.Compare(location(aClientConfig), location(bClientConfig)))
+}
+
 func checkSideEffectsForV1(sideEffects *admissionregv1.SideEffectClass) error {
 	if sideEffects == nil {
 		return fmt.Errorf("SideEffects is required for creating v1 {Mutating,Validating}WebhookConfiguration")
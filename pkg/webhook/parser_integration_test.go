@@ -18,13 +18,20 @@ package webhook_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"sigs.k8s.io/controller-tools/pkg/genall"
 	"sigs.k8s.io/controller-tools/pkg/loader"
 	"sigs.k8s.io/controller-tools/pkg/markers"
@@ -122,8 +129,48 @@ var _ = Describe("Webhook Generation From Parsing to CustomResourceDefinition",
 			Roots:      pkgs,
 			OutputRule: genall.OutputToDirectory(outputDir),
 		}
-		err = webhook.Generator{}.Generate(genCtx)
-		Expect(err).To(MatchError("SideEffects should not be set to `Some` or `Unknown` for v1 {Mutating,Validating}WebhookConfiguration"))
+		Expect(webhook.Generator{}.Generate(genCtx)).To(Succeed())
+		Expect(genCtx.Roots[0].Errors).To(HaveLen(1))
+		Expect(genCtx.Roots[0].Errors[0].Error()).To(MatchRegexp(`^.*webhook\.go:19:1: sideEffects=Some is not allowed for v1 webhooks, since dry-run requests must not have side effects: ` +
+			`set it to NoneOnDryRun and skip the side effects when the request has dryRun set, or to None if there are none$`))
+	})
+
+	It("should fail with webhooks for different resources on the same path, or unsupported admissionReviewVersions", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/invalid-path-collision")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(reg.Register(webhook.ConfigDefinition)).To(Succeed())
+		Expect(reg.Register(webhook.WebhookConfigDefinition)).To(Succeed())
+
+		By("requesting that the manifest be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		Expect(webhook.Generator{}.Generate(genCtx)).To(Succeed())
+
+		By("checking that the errors are reported at the markers")
+		Expect(pkgs[0].Errors).To(HaveLen(3))
+		Expect(pkgs[0].Errors[0].Error()).To(MatchRegexp(`^.*webhook\.go:20:1: path /validate-testdata-kubebuilder-io-v1-cronjob is served by webhooks for different resources: ` +
+			`testdata.kubebuilder.io/v1 cronjobs here, and batch/v1 jobs by another webhook$`))
+		Expect(pkgs[0].Errors[1].Error()).To(MatchRegexp(`^.*webhook\.go:24:1: path /validate-testdata-kubebuilder-io-v1-cronjob is served by webhooks for different resources: ` +
+			`batch/v1 jobs here, and testdata.kubebuilder.io/v1 cronjobs by another webhook$`))
+		Expect(pkgs[0].Errors[2].Error()).To(MatchRegexp(`^.*webhook\.go:27:1: unsupported admissionReviewVersion "v2", must be one of v1, v1beta1$`))
+
+		By("checking that no manifests were generated")
+		Expect(path.Join(outputDir, "manifests.yaml")).NotTo(BeAnExistingFile())
 	})
 
 	It("should fail with invalid timeout seconds", func() {
@@ -150,8 +197,10 @@ var _ = Describe("Webhook Generation From Parsing to CustomResourceDefinition",
 			Roots:      pkgs,
 			OutputRule: genall.OutputToDirectory(outputDir),
 		}
-		err = webhook.Generator{}.Generate(genCtx)
-		Expect(err).To(MatchError("TimeoutSeconds must be between 1 and 30 seconds"))
+		Expect(webhook.Generator{}.Generate(genCtx)).To(Succeed())
+		Expect(genCtx.Roots[0].Errors).To(HaveLen(2))
+		Expect(genCtx.Roots[0].Errors[0].Error()).To(MatchRegexp(`^.*webhook\.go:19:1: timeoutSeconds=40 is out of range, must be between 1 and 30 \(or unset, for the default of 10\)$`))
+		Expect(genCtx.Roots[0].Errors[1].Error()).To(MatchRegexp(`^.*webhook\.go:21:1: timeoutSeconds=-1 is out of range`))
 	})
 
 	It("should properly generate the webhook definition", func() {
@@ -636,6 +685,574 @@ var _ = Describe("Webhook Generation From Parsing to CustomResourceDefinition",
 		assertSame(actualValidating, expectedValidating)
 	})
 
+	It("should properly generate webhook definitions with matchConditions", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-matchconditions")).To(Succeed())
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(reg.Register(webhook.ConfigDefinition)).To(Succeed())
+		Expect(reg.Register(webhook.WebhookConfigDefinition)).To(Succeed())
+
+		By("requesting that the manifest be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		Expect(webhook.Generator{}.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("loading the generated v1 YAML")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "manifests.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		actualMutating, actualValidating := unmarshalBothV1(actualFile)
+
+		By("loading the desired v1 YAML")
+		expectedFile, err := os.ReadFile("manifests.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		expectedMutating, expectedValidating := unmarshalBothV1(expectedFile)
+
+		By("comparing the two")
+		assertSame(actualMutating, expectedMutating)
+		assertSame(actualValidating, expectedValidating)
+	})
+
+	It("should properly generate webhook definitions with selector markers", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-selectors-markers")).To(Succeed())
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(reg.Register(webhook.ConfigDefinition)).To(Succeed())
+		Expect(reg.Register(webhook.WebhookConfigDefinition)).To(Succeed())
+
+		By("requesting that the manifest be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		Expect(webhook.Generator{}.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("loading the generated v1 YAML")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "manifests.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		actualMutating, actualValidating := unmarshalBothV1(actualFile)
+
+		By("loading the desired v1 YAML")
+		expectedFile, err := os.ReadFile("manifests.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		expectedMutating, expectedValidating := unmarshalBothV1(expectedFile)
+
+		By("comparing the two")
+		assertSame(actualMutating, expectedMutating)
+		assertSame(actualValidating, expectedValidating)
+	})
+
+	It("should fill in the webhook settings not set by markers from the generator defaults", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-defaults")).To(Succeed())
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(reg.Register(webhook.ConfigDefinition)).To(Succeed())
+		Expect(reg.Register(webhook.WebhookConfigDefinition)).To(Succeed())
+
+		By("requesting that the manifest be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		By("checking that the failurePolicy is required without a default")
+		Expect(webhook.Generator{}.Generate(genCtx)).To(MatchError(ContainSubstring("failurePolicy is required")))
+
+		gen := webhook.Generator{DefaultFailurePolicy: "Fail", DefaultSideEffects: "None", DefaultTimeoutSeconds: 5}
+		Expect(gen.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("loading the generated v1 YAML")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "manifests.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		actualMutating, actualValidating := unmarshalBothV1(actualFile)
+
+		By("loading the desired v1 YAML")
+		expectedFile, err := os.ReadFile("manifests.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		expectedMutating, expectedValidating := unmarshalBothV1(expectedFile)
+
+		By("comparing the two")
+		assertSame(actualMutating, expectedMutating)
+		assertSame(actualValidating, expectedValidating)
+	})
+
+	It("should name webhooks using the name template, and order them by name", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-name-template")).To(Succeed())
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(reg.Register(webhook.ConfigDefinition)).To(Succeed())
+		Expect(reg.Register(webhook.WebhookConfigDefinition)).To(Succeed())
+
+		By("requesting that the manifest be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		By("checking that the name is required without a name template")
+		Expect(webhook.Generator{}.Generate(genCtx)).To(MatchError(ContainSubstring("name is required")))
+
+		gen := webhook.Generator{NameTemplate: "{operation}.{kind}.{group}.example.com"}
+		Expect(gen.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("loading the generated v1 YAML")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "manifests.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		actualMutating, actualValidating := unmarshalBothV1(actualFile)
+
+		By("loading the desired v1 YAML")
+		expectedFile, err := os.ReadFile("manifests.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		expectedMutating, expectedValidating := unmarshalBothV1(expectedFile)
+
+		By("comparing the two")
+		assertSame(actualMutating, expectedMutating)
+		assertSame(actualValidating, expectedValidating)
+	})
+
+	It("should report the drift of the generated webhook configurations from the existing ones", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-audit")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(reg.Register(webhook.ConfigDefinition)).To(Succeed())
+		Expect(reg.Register(webhook.WebhookConfigDefinition)).To(Succeed())
+
+		By("auditing the generated configurations against the exported ones")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+			InputRule:  genall.InputFromFileSystem,
+		}
+		err = webhook.Generator{AuditAgainst: "cluster"}.Generate(genCtx)
+		Expect(err).To(MatchError(`generated webhook configurations differ from cluster:
+ValidatingWebhookConfiguration webhook deletion.cronjob.testdata.kubebuilder.io:
+  missing
+ValidatingWebhookConfiguration webhook immutable.cronjob.testdata.kubebuilder.io:
+  extra
+ValidatingWebhookConfiguration webhook validation.cronjob.testdata.kubebuilder.io:
+  rules: +UPDATE testdata.kubebuilder.io/v1/cronjobs (scope *)
+  timeoutSeconds: 10 -> 5`))
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("checking that nothing was written")
+		entries, err := os.ReadDir(outputDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("should report the drift of the generated webhook configurations from the ones installed in a cluster", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-audit")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(reg.Register(webhook.ConfigDefinition)).To(Succeed())
+		Expect(reg.Register(webhook.WebhookConfigDefinition)).To(Succeed())
+
+		By("starting an API server serving the exported configurations")
+		var exported struct {
+			Items []map[string]any `json:"items"`
+		}
+		exportedFile, err := os.ReadFile(filepath.Join("cluster", "webhooks.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(yaml.Unmarshal(exportedFile, &exported)).To(Succeed())
+		var other map[string]any
+		otherFile, err := os.ReadFile(filepath.Join("cluster", "other.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(yaml.Unmarshal(otherFile, &other)).To(Succeed())
+		installed := map[string][]map[string]any{}
+		for _, obj := range append(exported.Items, other) {
+			resource := strings.ToLower(obj["kind"].(string)) + "s"
+			installed[resource] = append(installed[resource], obj)
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodGet))
+			resource, found := strings.CutPrefix(r.URL.Path, "/apis/admissionregistration.k8s.io/v1/")
+			Expect(found).To(BeTrue())
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(map[string]any{
+				"apiVersion": "admissionregistration.k8s.io/v1",
+				"kind":       "List",
+				"metadata":   map[string]any{},
+				"items":      installed[resource],
+			})).To(Succeed())
+		}))
+		defer server.Close()
+		kubeconfig := filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+		Expect(os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user: {}
+`, server.URL)), 0o600)).To(Succeed())
+
+		By("auditing the generated configurations against the installed ones")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+			InputRule:  genall.InputFromFileSystem,
+			Cluster:    genall.ClusterConfig{Kubeconfig: kubeconfig},
+		}
+		err = webhook.Generator{AuditCluster: true}.Generate(genCtx)
+		Expect(err).To(MatchError(`generated webhook configurations differ from the cluster:
+ValidatingWebhookConfiguration webhook deletion.cronjob.testdata.kubebuilder.io:
+  missing
+ValidatingWebhookConfiguration webhook immutable.cronjob.testdata.kubebuilder.io:
+  extra
+ValidatingWebhookConfiguration webhook validation.cronjob.testdata.kubebuilder.io:
+  rules: +UPDATE testdata.kubebuilder.io/v1/cronjobs (scope *)
+  timeoutSeconds: 10 -> 5`))
+
+		By("checking that nothing was written")
+		entries, err := os.ReadDir(outputDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+
+		By("checking that the cluster can't be audited along with a manifest")
+		err = webhook.Generator{AuditCluster: true, AuditAgainst: "cluster"}.Generate(genCtx)
+		Expect(err).To(MatchError("auditAgainst and auditCluster are mutually exclusive"))
+	})
+
+	It("should inject the CA into the generated webhook configurations", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-matchconditions")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(webhook.Generator{}.RegisterMarkers(reg)).To(Succeed())
+
+		By("requesting that the manifest be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		By("checking that the CA bundle must be base64-encoded")
+		Expect(webhook.Generator{CABundle: "not base64!"}.Generate(genCtx)).To(MatchError(ContainSubstring("caBundle must be base64-encoded")))
+
+		gen := webhook.Generator{CABundle: "Cg==", CertManagerCertificate: "serving-cert"}
+		Expect(gen.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("loading the generated v1 YAML")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "manifests.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		actualMutating, actualValidating := unmarshalBothV1(actualFile)
+
+		By("checking the annotations, which use the namespace of the webhook service")
+		Expect(actualMutating.Annotations).To(HaveKeyWithValue("cert-manager.io/inject-ca-from", "system/serving-cert"))
+		Expect(actualValidating.Annotations).To(HaveKeyWithValue("cert-manager.io/inject-ca-from", "system/serving-cert"))
+
+		By("checking the CA bundles")
+		for _, w := range actualMutating.Webhooks {
+			Expect(w.ClientConfig.CABundle).To(Equal([]byte("\n")))
+		}
+		for _, w := range actualValidating.Webhooks {
+			Expect(w.ClientConfig.CABundle).To(Equal([]byte("\n")))
+		}
+	})
+
+	It("should split the generated webhook configurations", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/registration")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(webhook.Generator{}.RegisterMarkers(reg)).To(Succeed())
+
+		for split, expected := range map[string]map[string][]string{
+			"group": {
+				"core":                    {"validation.configmap.testdata.kubebuilder.io", "validation.pod.testdata.kubebuilder.io"},
+				"testdata.kubebuilder.io": {"default.cronjob.testdata.kubebuilder.io", "validation.cronjob.testdata.kubebuilder.io"},
+			},
+			"service": {
+				"external":               {"validation.configmap.testdata.kubebuilder.io"},
+				"system-webhook-service": {"default.cronjob.testdata.kubebuilder.io", "validation.cronjob.testdata.kubebuilder.io", "validation.pod.testdata.kubebuilder.io"},
+			},
+		} {
+			By("requesting that the manifests be split by " + split)
+			outputDir := GinkgoT().TempDir()
+			genCtx := &genall.GenerationContext{
+				Collector:  &markers.Collector{Registry: reg},
+				Roots:      pkgs,
+				OutputRule: genall.OutputToDirectory(outputDir),
+			}
+			Expect(webhook.Generator{Split: split}.Generate(genCtx)).To(Succeed())
+			for _, r := range genCtx.Roots {
+				Expect(r.Errors).To(HaveLen(0))
+			}
+
+			By("checking the webhooks of each split")
+			entries, err := os.ReadDir(outputDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(len(expected)))
+			for key, names := range expected {
+				actualFile, err := os.ReadFile(path.Join(outputDir, key, "manifests.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				var actualNames []string
+				for _, document := range bytes.Split(actualFile, []byte("\n---\n")) {
+					cfg := &admissionregv1.ValidatingWebhookConfiguration{}
+					Expect(yaml.Unmarshal(document, cfg)).To(Succeed())
+					Expect(cfg.Name).To(HaveSuffix("-" + key))
+					for _, w := range cfg.Webhooks {
+						actualNames = append(actualNames, w.Name)
+					}
+				}
+				Expect(actualNames).To(ConsistOf(names))
+			}
+		}
+
+		By("checking that unknown splits are rejected")
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(GinkgoT().TempDir()),
+		}
+		Expect(webhook.Generator{Split: "kind"}.Generate(genCtx)).To(MatchError(ContainSubstring(`unknown value "kind" for split`)))
+	})
+
+	It("should generate CRD patches enabling the conversion webhook of kinds with a hub version", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/valid-conversion")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots("./...")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(2))
+
+		By("setting up the parser")
+		gen := webhook.Generator{Conversion: true}
+		reg := &markers.Registry{}
+		Expect(gen.RegisterMarkers(reg)).To(Succeed())
+
+		By("requesting that the manifests be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		Expect(gen.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("checking that only kinds with a hub version are patched")
+		entries, err := os.ReadDir(outputDir)
+		Expect(err).NotTo(HaveOccurred())
+		var fileNames []string
+		for _, entry := range entries {
+			fileNames = append(fileNames, entry.Name())
+		}
+		Expect(fileNames).To(ConsistOf("manifests.yaml", "conversion_testdata.kubebuilder.io_cronjobs.yaml"))
+
+		By("loading the generated CRD patch")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "conversion_testdata.kubebuilder.io_cronjobs.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		actualPatch := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(yaml.UnmarshalStrict(actualFile, actualPatch)).To(Succeed())
+
+		By("loading the desired CRD patch")
+		expectedFile, err := os.ReadFile("conversion_testdata.kubebuilder.io_cronjobs.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		expectedPatch := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(yaml.UnmarshalStrict(expectedFile, expectedPatch)).To(Succeed())
+
+		By("comparing the two")
+		assertSame(actualPatch, expectedPatch)
+	})
+
+	It("should generate Go code registering the webhook handlers on the paths of the markers", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/registration")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(webhook.RegistrationGenerator{}.RegisterMarkers(reg)).To(Succeed())
+
+		By("requesting that the code be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		Expect(webhook.RegistrationGenerator{}.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("comparing the generated code with the golden file")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "zz_generated.webhook.go"))
+		Expect(err).NotTo(HaveOccurred())
+		expectedFile, err := os.ReadFile("zz_generated.webhook.go")
+		Expect(err).NotTo(HaveOccurred())
+		assertSame(string(actualFile), string(expectedFile))
+
+		By("checking that no test suite is generated unless requested")
+		Expect(path.Join(outputDir, "zz_generated.webhook_test.go")).NotTo(BeAnExistingFile())
+	})
+
+	It("should generate an envtest suite calling the registered webhook handlers", func() {
+		By("switching into testdata to appease go modules")
+		cwd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir("./testdata/registration")).To(Succeed()) // go modules are directory-sensitive
+		defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+		By("loading the roots")
+		pkgs, err := loader.LoadRoots(".")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+
+		By("setting up the parser")
+		reg := &markers.Registry{}
+		Expect(webhook.RegistrationGenerator{}.RegisterMarkers(reg)).To(Succeed())
+
+		By("requesting that the code be generated")
+		outputDir := GinkgoT().TempDir()
+		genCtx := &genall.GenerationContext{
+			Collector:  &markers.Collector{Registry: reg},
+			Roots:      pkgs,
+			OutputRule: genall.OutputToDirectory(outputDir),
+		}
+		Expect(webhook.RegistrationGenerator{EnvtestManifestsDir: "../config/webhook"}.Generate(genCtx)).To(Succeed())
+		for _, r := range genCtx.Roots {
+			Expect(r.Errors).To(HaveLen(0))
+		}
+
+		By("comparing the generated test suite with the golden file")
+		actualFile, err := os.ReadFile(path.Join(outputDir, "zz_generated.webhook_test.go"))
+		Expect(err).NotTo(HaveOccurred())
+		expectedFile, err := os.ReadFile("zz_generated.webhook_test.go")
+		Expect(err).NotTo(HaveOccurred())
+		assertSame(string(actualFile), string(expectedFile))
+	})
+
 })
 
 func unmarshalBothV1(in []byte) (mutating admissionregv1.MutatingWebhookConfiguration, validating admissionregv1.ValidatingWebhookConfiguration) {
@@ -17,6 +17,7 @@ limitations under the License.
 package webhook
 
 import (
+	"reflect"
 	"testing"
 
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
@@ -304,3 +305,141 @@ func TestConfigToValidatingWebhookWithPatch(t *testing.T) {
 		t.Errorf("expected env=production, got %q", webhook.NamespaceSelector.MatchLabels["env"])
 	}
 }
+
+// TestMatchConditions verifies that match conditions are validated and sorted by name.
+func TestMatchConditions(t *testing.T) {
+	tests := []struct {
+		name            string
+		matchConditions map[string]string
+		expected        []admissionregv1.MatchCondition
+		expectError     bool
+	}{
+		{
+			name: "no match conditions",
+		},
+		{
+			name:            "sorted by name",
+			matchConditions: map[string]string{"b": "has(object.spec)", "a": " !request.dryRun "},
+			expected: []admissionregv1.MatchCondition{
+				{Name: "a", Expression: "!request.dryRun"},
+				{Name: "b", Expression: "has(object.spec)"},
+			},
+		},
+		{
+			name:            "invalid name",
+			matchConditions: map[string]string{"not a name": "true"},
+			expectError:     true,
+		},
+		{
+			name:            "empty expression",
+			matchConditions: map[string]string{"empty": " "},
+			expectError:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Name: "test.example.com", MatchConditions: tt.matchConditions}
+			matchConditions, err := cfg.matchConditions()
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(matchConditions, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, matchConditions)
+			}
+		})
+	}
+}
+
+// TestReinvocationPolicy verifies that reinvocation policies are case-insensitive and validated.
+func TestReinvocationPolicy(t *testing.T) {
+	never, ifNeeded := admissionregv1.NeverReinvocationPolicy, admissionregv1.IfNeededReinvocationPolicy
+	tests := []struct {
+		reinvocationPolicy string
+		expected           *admissionregv1.ReinvocationPolicyType
+		expectError        bool
+	}{
+		{reinvocationPolicy: ""},
+		{reinvocationPolicy: "Never", expected: &never},
+		{reinvocationPolicy: "ifneeded", expected: &ifNeeded},
+		{reinvocationPolicy: "Always", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reinvocationPolicy, func(t *testing.T) {
+			cfg := Config{Mutating: true, ReinvocationPolicy: tt.reinvocationPolicy}
+			reinvocationPolicy, err := cfg.reinvocationPolicy()
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(reinvocationPolicy, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, reinvocationPolicy)
+			}
+		})
+	}
+}
+
+// TestCheckWebhook verifies that webhook settings the API server would reject are reported.
+func TestCheckWebhook(t *testing.T) {
+	valid := Config{
+		FailurePolicy:           "fail",
+		SideEffects:             "None",
+		AdmissionReviewVersions: []string{"v1"},
+	}
+	tests := []struct {
+		name     string
+		modify   func(*Config)
+		expected []string
+	}{
+		{
+			name:   "valid",
+			modify: func(*Config) {},
+		},
+		{
+			name:     "missing sideEffects",
+			modify:   func(c *Config) { c.SideEffects = "" },
+			expected: []string{"sideEffects is required: set it to None if the webhook has no side effects, or to NoneOnDryRun if it skips them on dry-run requests (or set the defaultSideEffects option)"},
+		},
+		{
+			name:     "unknown sideEffects",
+			modify:   func(c *Config) { c.SideEffects = "Nope" },
+			expected: []string{`unknown value "Nope" for sideEffects, must be None or NoneOnDryRun`},
+		},
+		{
+			name:     "unknown failurePolicy and matchPolicy",
+			modify:   func(c *Config) { c.FailurePolicy, c.MatchPolicy = "retry", "Fuzzy" },
+			expected: []string{`unknown value "retry" for failurePolicy, must be Fail or Ignore`, `unknown value "Fuzzy" for matchPolicy, must be Exact or Equivalent`},
+		},
+		{
+			name:     "duplicate and unsupported admissionReviewVersions",
+			modify:   func(c *Config) { c.AdmissionReviewVersions = []string{"v1", "v1", "v2"} },
+			expected: []string{`duplicate admissionReviewVersion "v1"`, `unsupported admissionReviewVersion "v2", must be one of v1, v1beta1`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			var actual []string
+			for _, err := range checkWebhook(cfg) {
+				actual = append(actual, err.Error())
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected errors %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
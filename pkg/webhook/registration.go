@@ -0,0 +1,324 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/ast"
+	"slices"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/controller-tools/pkg/genall"
+	"sigs.k8s.io/controller-tools/pkg/internal/gogen"
+	"sigs.k8s.io/controller-tools/pkg/loader"
+	"sigs.k8s.io/controller-tools/pkg/markers"
+)
+
+const (
+	managerPath   = "sigs.k8s.io/controller-runtime/pkg/manager"
+	admissionPath = "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +controllertools:marker:generateHelp
+
+// RegistrationGenerator generates Go code registering webhook handlers with
+// the webhook server of a controller-runtime manager, on the paths declared by
+// the webhook markers of each package.
+//
+// For each package with webhook markers, zz_generated.webhook.go contains a
+// constant with the path of each webhook, a WebhookHandlers struct with a
+// field for the handler of each webhook, and a SetupWebhooksWithManager
+// function registering them, so that the registered paths always match the
+// generated webhook configurations.  Webhooks served from a URL are skipped.
+type RegistrationGenerator struct {
+	// HeaderFile specifies the header text (e.g. license) to prepend to generated files.
+	HeaderFile string `marker:",optional"`
+
+	// Year specifies the year to substitute for " YEAR" in the header file.
+	Year string `marker:",optional"`
+
+	// EnvtestManifestsDir enables generating a test suite, in
+	// zz_generated.webhook_test.go, checking that the generated webhook
+	// configurations in the given directory (relative to each package) call
+	// the registered handlers.
+	//
+	// The suite starts an envtest environment with the webhook configurations
+	// pointed at a local webhook server, registers recording handlers with
+	// SetupWebhooksWithManager, and creates a sample object (as a dry run)
+	// for each webhook handling creation, failing if its handler isn't
+	// called.  Since the sample objects are empty, webhooks aren't called for
+	// kinds whose empty objects are invalid, so those are skipped.
+	EnvtestManifestsDir string `marker:",optional"`
+
+	// EnvtestCRDDirs specifies the directories (relative to each package) of
+	// the CustomResourceDefinitions to install for the test suite, for
+	// webhooks of custom resources.
+	EnvtestCRDDirs []string `marker:",optional"`
+}
+
+var _ genall.Generator = &RegistrationGenerator{}
+
+func (RegistrationGenerator) RegisterMarkers(into *markers.Registry) error {
+	if err := into.Register(ConfigDefinition); err != nil {
+		return err
+	}
+	into.AddHelp(ConfigDefinition, Config{}.Help())
+	return nil
+}
+
+func (g RegistrationGenerator) Generate(ctx *genall.GenerationContext) error {
+	var headerText string
+	if g.HeaderFile != "" {
+		headerBytes, err := ctx.ReadFile(g.HeaderFile)
+		if err != nil {
+			return err
+		}
+		headerText = string(headerBytes)
+	}
+	headerText = strings.ReplaceAll(headerText, " YEAR", " "+g.Year)
+
+	for _, root := range ctx.Roots {
+		markerSet, err := markers.PackageMarkers(ctx.Collector, root)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+
+		var cfgs []Config
+		for _, cfg := range markerSet[ConfigDefinition.Name] {
+			cfgs = append(cfgs, cfg.(Config))
+		}
+		webhooks, err := registeredWebhooks(cfgs)
+		if err != nil {
+			root.AddError(err)
+			continue
+		}
+		if len(webhooks) == 0 {
+			// none of the webhooks are served on a path
+			continue
+		}
+
+		gogen.WriteOut(ctx, root, "zz_generated.webhook.go", generateRegistration(root, webhooks, headerText))
+		if g.EnvtestManifestsDir == "" {
+			continue
+		}
+		suite := generateEnvtestSuite(root, webhooks, headerText, g.EnvtestManifestsDir, g.EnvtestCRDDirs)
+		gogen.WriteOut(ctx, root, "zz_generated.webhook_test.go", suite)
+	}
+	return nil
+}
+
+// registeredWebhook is a webhook to register with the webhook server.
+type registeredWebhook struct {
+	cfg Config
+	// ident is the Go identifier of the webhook in the generated code.
+	ident string
+}
+
+// registeredWebhooks returns the webhooks to register out of the given ones,
+// i.e. the ones served on a path, ordered by their Go identifiers.
+func registeredWebhooks(cfgs []Config) ([]registeredWebhook, error) {
+	var webhooks []registeredWebhook
+	byIdent := make(map[string]Config)
+	byPath := make(map[string]Config)
+	for _, cfg := range cfgs {
+		if cfg.Path == "" {
+			// served from an external URL, not by the manager
+			continue
+		}
+		if other, seen := byPath[cfg.Path]; seen {
+			if other.Name == cfg.Name || cfg.Name == "" {
+				// the same webhook, e.g. generated for multiple webhook versions
+				continue
+			}
+			return nil, fmt.Errorf("webhooks %s and %s are both served on path %s", other.Name, cfg.Name, cfg.Path)
+		}
+		byPath[cfg.Path] = cfg
+
+		ident := goIdentifier(cmp.Or(cfg.Name, cfg.Path))
+		if other, seen := byIdent[ident]; seen {
+			return nil, fmt.Errorf("webhooks %s and %s have the same Go identifier %s", other.Name, cfg.Name, ident)
+		}
+		byIdent[ident] = cfg
+		webhooks = append(webhooks, registeredWebhook{cfg: cfg, ident: ident})
+	}
+	slices.SortFunc(webhooks, func(a, b registeredWebhook) int {
+		return strings.Compare(a.ident, b.ident)
+	})
+	return webhooks, nil
+}
+
+// generateRegistration generates the registration code for the given webhooks
+// of the given package.
+func generateRegistration(root *loader.Package, webhooks []registeredWebhook, headerText string) []byte {
+	taken := packageIdentifiers(root)
+	fmtAlias := importAlias("fmt", taken)
+	managerAlias := importAlias("manager", taken)
+	admissionAlias := importAlias("admission", taken)
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, registrationHeader, root.Name, headerText,
+		importSpec(fmtAlias, "fmt", "fmt"), importSpec(managerAlias, "manager", managerPath), importSpec(admissionAlias, "admission", admissionPath))
+
+	out.WriteString("const (\n")
+	for _, webhook := range webhooks {
+		fmt.Fprintf(out, "// %sPath is the path of the %s webhook.\n", webhook.ident, webhook.cfg.Name)
+		fmt.Fprintf(out, "%sPath = %q\n", webhook.ident, webhook.cfg.Path)
+	}
+	out.WriteString(")\n\n")
+
+	out.WriteString("// WebhookHandlers contains the handlers of the webhooks declared in this package.\n")
+	out.WriteString("type WebhookHandlers struct {\n")
+	for _, webhook := range webhooks {
+		fmt.Fprintf(out, "// %s handles the %s webhook, %s.\n", webhook.ident, webhook.cfg.Name, describeWebhook(webhook.cfg))
+		fmt.Fprintf(out, "%s %s.Handler\n", webhook.ident, admissionAlias)
+	}
+	out.WriteString("}\n\n")
+
+	out.WriteString("// SetupWebhooksWithManager registers the given handlers with the webhook server\n")
+	out.WriteString("// of the given manager, on the paths of their webhooks.\n")
+	fmt.Fprintf(out, "func SetupWebhooksWithManager(mgr %s.Manager, handlers WebhookHandlers) error {\n", managerAlias)
+	for _, webhook := range webhooks {
+		fmt.Fprintf(out, "if handlers.%s == nil {\n", webhook.ident)
+		fmt.Fprintf(out, "return %s.Errorf(\"missing handler for the %%s webhook\", %q)\n", fmtAlias, webhook.cfg.Name)
+		out.WriteString("}\n")
+	}
+	out.WriteString("\nserver := mgr.GetWebhookServer()\n")
+	for _, webhook := range webhooks {
+		fmt.Fprintf(out, "server.Register(%sPath, &%s.Webhook{Handler: handlers.%s})\n", webhook.ident, admissionAlias, webhook.ident)
+	}
+	out.WriteString("return nil\n}\n")
+
+	return gogen.Format(root, out.Bytes())
+}
+
+// describeWebhook describes the requests the given webhook handles.
+func describeWebhook(cfg Config) string {
+	kind := "validating"
+	if cfg.Mutating {
+		kind = "mutating"
+	}
+	verbs := make([]string, 0, len(cfg.Verbs))
+	for _, verb := range cfg.Verbs {
+		verbs = append(verbs, strings.ToLower(string(verbToAPIVariant(verb))))
+	}
+	groups := make([]string, 0, len(cfg.Groups))
+	for _, group := range cfg.Groups {
+		if group == "" {
+			group = "core"
+		}
+		groups = append(groups, group)
+	}
+	return fmt.Sprintf("%s %s of %s in %s/%s", kind,
+		strings.Join(verbs, ", "), strings.Join(cfg.Resources, ", "), strings.Join(groups, ", "), strings.Join(cfg.Versions, ", "))
+}
+
+// goIdentifier turns the given webhook name into an exported Go identifier,
+// e.g. "default.cronjob.example.com" into "DefaultCronjobExampleCom".
+func goIdentifier(name string) string {
+	var ident strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		ident.WriteRune(r)
+	}
+	if ident.Len() == 0 || !unicode.IsLetter([]rune(ident.String())[0]) {
+		return "Webhook" + ident.String()
+	}
+	return ident.String()
+}
+
+// packageIdentifiers returns the identifiers declared at the top level of the
+// given package, which can't be used as import names.
+func packageIdentifiers(pkg *loader.Package) map[string]bool {
+	pkg.NeedSyntax()
+	taken := make(map[string]bool)
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					taken[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						taken[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							taken[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	// declared by the generated code itself
+	taken["WebhookHandlers"] = true
+	taken["SetupWebhooksWithManager"] = true
+	return taken
+}
+
+// importAlias returns a name for importing the package with the given name
+// that doesn't conflict with the given identifiers.
+func importAlias(name string, taken map[string]bool) string {
+	alias := name
+	for i := 1; taken[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[alias] = true
+	return alias
+}
+
+// importSpec returns an import spec for the given path, with the given alias
+// if it differs from the package name.
+func importSpec(alias, name, path string) string {
+	if alias == name {
+		return fmt.Sprintf("%q", path)
+	}
+	return fmt.Sprintf("%s %q", alias, path)
+}
+
+// registrationHeader is the build tag, package declaration and imports of
+// the generated registration code.
+const registrationHeader = `//go:build !ignore_autogenerated
+
+%[2]s
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package %[1]s
+
+import (
+	%[3]s
+
+	%[4]s
+	%[5]s
+)
+
+`
@@ -0,0 +1,121 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+)
+
+const (
+	// splitByService splits configurations by the service of their webhooks.
+	splitByService = "service"
+	// splitByGroup splits configurations by the API group of their webhooks.
+	splitByGroup = "group"
+
+	// externalSplitKey is the key of webhooks served from a URL, when
+	// splitting by service.
+	externalSplitKey = "external"
+)
+
+// splitConfigurations splits the webhooks of the given configurations by
+// service or API group, returning the split configurations by key.
+func splitConfigurations(objs []any, by string) (map[string][]any, error) {
+	var keyOf func(admissionregv1.WebhookClientConfig, []admissionregv1.RuleWithOperations) string
+	switch by {
+	case splitByService:
+		keyOf = serviceSplitKey
+	case splitByGroup:
+		keyOf = groupSplitKey
+	default:
+		return nil, fmt.Errorf("unknown value %q for split, must be %q or %q", by, splitByService, splitByGroup)
+	}
+
+	byKey := make(map[string][]any)
+	for _, obj := range objs {
+		switch obj := obj.(type) {
+		case *admissionregv1.MutatingWebhookConfiguration:
+			webhooks := make(map[string][]admissionregv1.MutatingWebhook)
+			var keys []string
+			for _, webhook := range obj.Webhooks {
+				key := keyOf(webhook.ClientConfig, webhook.Rules)
+				if _, seen := webhooks[key]; !seen {
+					keys = append(keys, key)
+				}
+				webhooks[key] = append(webhooks[key], webhook)
+			}
+			for _, key := range keys {
+				split := obj.DeepCopy()
+				split.Name = obj.Name + "-" + key
+				split.Webhooks = webhooks[key]
+				byKey[key] = append(byKey[key], split)
+			}
+		case *admissionregv1.ValidatingWebhookConfiguration:
+			webhooks := make(map[string][]admissionregv1.ValidatingWebhook)
+			var keys []string
+			for _, webhook := range obj.Webhooks {
+				key := keyOf(webhook.ClientConfig, webhook.Rules)
+				if _, seen := webhooks[key]; !seen {
+					keys = append(keys, key)
+				}
+				webhooks[key] = append(webhooks[key], webhook)
+			}
+			for _, key := range keys {
+				split := obj.DeepCopy()
+				split.Name = obj.Name + "-" + key
+				split.Webhooks = webhooks[key]
+				byKey[key] = append(byKey[key], split)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected webhook configuration %T", obj)
+		}
+	}
+	return byKey, nil
+}
+
+// serviceSplitKey returns the key of a webhook with the given client config
+// when splitting by service.
+func serviceSplitKey(clientConfig admissionregv1.WebhookClientConfig, _ []admissionregv1.RuleWithOperations) string {
+	if clientConfig.Service == nil {
+		return externalSplitKey
+	}
+	return clientConfig.Service.Namespace + "-" + clientConfig.Service.Name
+}
+
+// groupSplitKey returns the key of a webhook with the given rules when
+// splitting by API group.  Webhooks for multiple groups are keyed by all of
+// their groups.
+func groupSplitKey(_ admissionregv1.WebhookClientConfig, rules []admissionregv1.RuleWithOperations) string {
+	var groups []string
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			switch group {
+			case "":
+				group = "core"
+			case "*":
+				group = "all"
+			}
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		return "core"
+	}
+	return strings.Join(groups, "-")
+}
@@ -0,0 +1,26 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-testdata-kubebuilder-io-v1-cronjob,mutating=true,failurePolicy=fail,groups=testdata.kubebuilder.io,resources=cronjobs,versions=v1,name=default.cronjob.testdata.kubebuilder.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-testdata-kubebuilder-io-v1-cronjob,mutating=false,failurePolicy=fail,groups=testdata.kubebuilder.io,resources=cronjobs,versions=v1,name=validation.cronjob.testdata.kubebuilder.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create,path=/validate-v1-pod,mutating=false,failurePolicy=ignore,groups=core,resources=pods,versions=v1,name=validation.pod.testdata.kubebuilder.io,sideEffects=None,admissionReviewVersions=v1
+// External webhooks aren't registered.
+// +kubebuilder:webhook:verbs=create,url="https://example.com/validate",mutating=false,failurePolicy=ignore,groups=core,resources=configmaps,versions=v1,name=validation.configmap.testdata.kubebuilder.io,sideEffects=None,admissionReviewVersions=v1
+
+// admission conflicts with the default import name of the admission package.
+type admission struct{}
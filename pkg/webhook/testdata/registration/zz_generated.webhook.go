@@ -0,0 +1,51 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package cronjob
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	admission1 "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// DefaultCronjobTestdataKubebuilderIoPath is the path of the default.cronjob.testdata.kubebuilder.io webhook.
+	DefaultCronjobTestdataKubebuilderIoPath = "/mutate-testdata-kubebuilder-io-v1-cronjob"
+	// ValidationCronjobTestdataKubebuilderIoPath is the path of the validation.cronjob.testdata.kubebuilder.io webhook.
+	ValidationCronjobTestdataKubebuilderIoPath = "/validate-testdata-kubebuilder-io-v1-cronjob"
+	// ValidationPodTestdataKubebuilderIoPath is the path of the validation.pod.testdata.kubebuilder.io webhook.
+	ValidationPodTestdataKubebuilderIoPath = "/validate-v1-pod"
+)
+
+// WebhookHandlers contains the handlers of the webhooks declared in this package.
+type WebhookHandlers struct {
+	// DefaultCronjobTestdataKubebuilderIo handles the default.cronjob.testdata.kubebuilder.io webhook, mutating create, update of cronjobs in testdata.kubebuilder.io/v1.
+	DefaultCronjobTestdataKubebuilderIo admission1.Handler
+	// ValidationCronjobTestdataKubebuilderIo handles the validation.cronjob.testdata.kubebuilder.io webhook, validating create, update, delete of cronjobs in testdata.kubebuilder.io/v1.
+	ValidationCronjobTestdataKubebuilderIo admission1.Handler
+	// ValidationPodTestdataKubebuilderIo handles the validation.pod.testdata.kubebuilder.io webhook, validating create of pods in core/v1.
+	ValidationPodTestdataKubebuilderIo admission1.Handler
+}
+
+// SetupWebhooksWithManager registers the given handlers with the webhook server
+// of the given manager, on the paths of their webhooks.
+func SetupWebhooksWithManager(mgr manager.Manager, handlers WebhookHandlers) error {
+	if handlers.DefaultCronjobTestdataKubebuilderIo == nil {
+		return fmt.Errorf("missing handler for the %s webhook", "default.cronjob.testdata.kubebuilder.io")
+	}
+	if handlers.ValidationCronjobTestdataKubebuilderIo == nil {
+		return fmt.Errorf("missing handler for the %s webhook", "validation.cronjob.testdata.kubebuilder.io")
+	}
+	if handlers.ValidationPodTestdataKubebuilderIo == nil {
+		return fmt.Errorf("missing handler for the %s webhook", "validation.pod.testdata.kubebuilder.io")
+	}
+
+	server := mgr.GetWebhookServer()
+	server.Register(DefaultCronjobTestdataKubebuilderIoPath, &admission1.Webhook{Handler: handlers.DefaultCronjobTestdataKubebuilderIo})
+	server.Register(ValidationCronjobTestdataKubebuilderIoPath, &admission1.Webhook{Handler: handlers.ValidationCronjobTestdataKubebuilderIo})
+	server.Register(ValidationPodTestdataKubebuilderIoPath, &admission1.Webhook{Handler: handlers.ValidationPodTestdataKubebuilderIo})
+	return nil
+}
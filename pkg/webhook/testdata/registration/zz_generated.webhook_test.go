@@ -0,0 +1,158 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package cronjob
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	admission1 "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// generatedWebhookRecorder is a handler recording the paths of the webhooks
+// it's called for, and allowing all requests.
+type generatedWebhookRecorder struct {
+	mu     sync.Mutex
+	called map[string]bool
+}
+
+func (r *generatedWebhookRecorder) handlerFor(path string) admission1.Handler {
+	return admission1.HandlerFunc(func(_ context.Context, _ admission1.Request) admission1.Response {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.called[path] = true
+		return admission1.Allowed("")
+	})
+}
+
+func (r *generatedWebhookRecorder) wasCalled(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.called[path]
+}
+
+// generatedWebhookSample is a webhook, with the resource to create a sample
+// object of to call it.
+type generatedWebhookSample struct {
+	name     string
+	path     string
+	resource schema.GroupVersionResource
+}
+
+// TestGeneratedWebhooks checks that the webhook configurations call the
+// handlers registered by SetupWebhooksWithManager, on the same paths.
+func TestGeneratedWebhooks(t *testing.T) {
+	env := &envtest.Environment{
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.FromSlash("../config/webhook")},
+		},
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("unable to start the test environment: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Errorf("unable to stop the test environment: %v", err)
+		}
+	})
+
+	opts := &env.WebhookInstallOptions
+	mgr, err := manager.New(cfg, manager.Options{
+		Metrics: metricsserver.Options{BindAddress: "0"},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    opts.LocalServingHost,
+			Port:    opts.LocalServingPort,
+			CertDir: opts.LocalServingCertDir,
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unable to create the manager: %v", err)
+	}
+
+	recorder := &generatedWebhookRecorder{called: make(map[string]bool)}
+	if err := SetupWebhooksWithManager(mgr, WebhookHandlers{
+		DefaultCronjobTestdataKubebuilderIo:    recorder.handlerFor(DefaultCronjobTestdataKubebuilderIoPath),
+		ValidationCronjobTestdataKubebuilderIo: recorder.handlerFor(ValidationCronjobTestdataKubebuilderIoPath),
+		ValidationPodTestdataKubebuilderIo:     recorder.handlerFor(ValidationPodTestdataKubebuilderIoPath),
+	}); err != nil {
+		t.Fatalf("unable to set up the webhooks: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			t.Errorf("unable to start the manager: %v", err)
+		}
+	}()
+
+	addr := net.JoinHostPort(opts.LocalServingHost, strconv.Itoa(opts.LocalServingPort))
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", addr, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec
+		if err == nil {
+			_ = conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the webhook server isn't serving: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		t.Fatalf("unable to create a client: %v", err)
+	}
+	samples := []generatedWebhookSample{
+		{name: "default.cronjob.testdata.kubebuilder.io", path: DefaultCronjobTestdataKubebuilderIoPath, resource: schema.GroupVersionResource{Group: "testdata.kubebuilder.io", Version: "v1", Resource: "cronjobs"}},
+		{name: "validation.cronjob.testdata.kubebuilder.io", path: ValidationCronjobTestdataKubebuilderIoPath, resource: schema.GroupVersionResource{Group: "testdata.kubebuilder.io", Version: "v1", Resource: "cronjobs"}},
+		{name: "validation.pod.testdata.kubebuilder.io", path: ValidationPodTestdataKubebuilderIoPath, resource: schema.GroupVersionResource{Group: "core", Version: "v1", Resource: "pods"}},
+	}
+	for _, sample := range samples {
+		t.Run(sample.name, func(t *testing.T) {
+			gvk, err := mgr.GetRESTMapper().KindFor(sample.resource)
+			if err != nil {
+				t.Fatalf("unknown resource %s: %v", sample.resource, err)
+			}
+			mapping, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				t.Fatalf("unknown kind %s: %v", gvk, err)
+			}
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(gvk)
+			obj.SetGenerateName("sample-")
+			if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				obj.SetNamespace("default")
+			}
+
+			err = c.Create(ctx, obj, client.DryRunAll)
+			if recorder.wasCalled(sample.path) {
+				return
+			}
+			if apierrors.IsInvalid(err) {
+				t.Skipf("the empty sample %s is invalid, so the webhook isn't called: %v", gvk.Kind, err)
+			}
+			t.Errorf("creating a sample %s didn't call the webhook on path %s (err: %v), check that the webhook configurations in %s are up to date",
+				gvk.Kind, sample.path, err, "../config/webhook")
+		})
+	}
+}
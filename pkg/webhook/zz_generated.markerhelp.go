@@ -38,7 +38,7 @@ func (Config) Help() *markers.DefinitionHelp {
 			},
 			"FailurePolicy": {
 				Summary: "specifies what should happen if the API server cannot reach the webhook.",
-				Details: "It may be either \"ignore\" (to skip the webhook and continue on) or \"fail\" (to reject\nthe object in question). Most webhooks should use \"fail\" to ensure the webhook logic\nis always executed.",
+				Details: "It may be either \"ignore\" (to skip the webhook and continue on) or \"fail\" (to reject\nthe object in question). Most webhooks should use \"fail\" to ensure the webhook logic\nis always executed.\n\nRequired, unless the defaultFailurePolicy option of the generator is set.",
 			},
 			"MatchPolicy": {
 				Summary: "defines how the \"rules\" list is used to match incoming requests.",
@@ -46,11 +46,11 @@ func (Config) Help() *markers.DefinitionHelp {
 			},
 			"SideEffects": {
 				Summary: "specify whether calling the webhook will have side effects.",
-				Details: "This has an impact on dry runs and `kubectl diff`: if the sideEffect is \"Unknown\" (the default) or \"Some\", then\nthe API server will not call the webhook on a dry-run request and fails instead.\nIf the value is \"None\", then the webhook has no side effects and the API server will call it on dry-run.\nIf the value is \"NoneOnDryRun\", then the webhook is responsible for inspecting the \"dryRun\" property of the\nAdmissionReview sent in the request, and avoiding side effects if that value is \"true.\"\nMost webhooks should use \"None\".",
+				Details: "This has an impact on dry runs and `kubectl diff`: if the sideEffect is \"Unknown\" (the default) or \"Some\", then\nthe API server will not call the webhook on a dry-run request and fails instead.\nIf the value is \"None\", then the webhook has no side effects and the API server will call it on dry-run.\nIf the value is \"NoneOnDryRun\", then the webhook is responsible for inspecting the \"dryRun\" property of the\nAdmissionReview sent in the request, and avoiding side effects if that value is \"true.\"\nMost webhooks should use \"None\".\nDefaults to the defaultSideEffects option of the generator, if set.",
 			},
 			"TimeoutSeconds": {
 				Summary: "allows configuring how long the API server should wait for a webhook to respond before treating the call as a failure.",
-				Details: "If the timeout expires before the webhook responds, the webhook call will be ignored or the API call will be rejected based on the failure policy.\nThe timeout value must be between 1 and 30 seconds.\nThe timeout for an admission webhook defaults to 10 seconds.",
+				Details: "If the timeout expires before the webhook responds, the webhook call will be ignored or the API call will be rejected based on the failure policy.\nThe timeout value must be between 1 and 30 seconds.\nThe timeout for an admission webhook defaults to the defaultTimeoutSeconds option of the\ngenerator if set, or else to 10 seconds.",
 			},
 			"Groups": {
 				Summary: "specifies the API groups that this webhook receives requests for.",
@@ -70,7 +70,7 @@ func (Config) Help() *markers.DefinitionHelp {
 			},
 			"Name": {
 				Summary: "indicates the name of this webhook configuration. Should be a domain with at least three segments separated by dots",
-				Details: "Example: \"myresource.mygroup.example.com\".",
+				Details: "Example: \"myresource.mygroup.example.com\".\n\nRequired, unless the nameTemplate option of the generator is set.",
 			},
 			"ServiceName": {
 				Summary: "indicates the name of the K8s Service the webhook uses.",
@@ -98,7 +98,19 @@ func (Config) Help() *markers.DefinitionHelp {
 			},
 			"ReinvocationPolicy": {
 				Summary: "allows mutating webhooks to request reinvocation after other mutations.",
-				Details: "To allow mutating admission plugins to observe changes made by other plugins,\nbuilt-in mutating admission plugins are re-run if a mutating webhook modifies\nan object, and mutating webhooks can specify a reinvocationPolicy to control\nwhether they are reinvoked as well. May be \"Never\" or \"IfNeeded\". Defaults to \"Never\".",
+				Details: "To allow mutating admission plugins to observe changes made by other plugins,\nbuilt-in mutating admission plugins are re-run if a mutating webhook modifies\nan object, and mutating webhooks can specify a reinvocationPolicy to control\nwhether they are reinvoked as well. May be \"Never\" or \"IfNeeded\". Defaults to \"Never\".\n\n\"IfNeeded\" is needed e.g. for defaulting webhooks that must see the changes made by\nsidecar injectors.  It's ignored for validating webhooks, which are never reinvoked.",
+			},
+			"MatchConditions": {
+				Summary: "are CEL expressions, by name, that must all be true for a request to be",
+				Details: "sent to the webhook, so that it can pre-filter requests that match its rules.\n\nThe expressions have access to the object, oldObject, request and authorizer variables.\nNames must be qualified names (like label keys).  At most 64 conditions may be given.\n\nExample:\n\n\t// +kubebuilder:webhook:...,matchConditions={exclude-leases: \"!(request.resource.group == 'coordination.k8s.io' && request.resource.resource == 'leases')\"}",
+			},
+			"NamespaceSelector": {
+				Summary: "limits the webhook to objects in namespaces matching the given label",
+				Details: "selector, in the usual label selector syntax (as used by kubectl).  Set-based requirements\nbecome matchExpressions, and equality-based ones become matchLabels.\n\nExample (skip kube-system and the webhook's own namespace):\n\n\t// +kubebuilder:webhook:...,namespaceSelector=\"kubernetes.io/metadata.name notin (kube-system,my-operator-system)\"",
+			},
+			"ObjectSelector": {
+				Summary: "limits the webhook to objects with labels matching the given label",
+				Details: "selector, in the usual label selector syntax (as used by kubectl).\n\nExample:\n\n\t// +kubebuilder:webhook:...,objectSelector=\"!example.com/skip-webhook\"",
 			},
 			"URL": {
 				Summary: "allows mutating webhooks configuration to specify an external URL when generating",
@@ -106,12 +118,23 @@ func (Config) Help() *markers.DefinitionHelp {
 			},
 			"Patch": {
 				Summary: "applies a strategic merge patch to customize the generated webhook configuration.",
-				Details: "This allows you to set any webhook field that isn't directly exposed as a marker parameter,\nsuch as namespaceSelector, objectSelector, or matchConditions. The patch is a JSON object\nthat follows Kubernetes strategic merge patch semantics and is applied to the webhook\nconfiguration after all other marker parameters are processed.\n\nUse backticks to avoid escaping quotes in the JSON.\n\nCommon use cases:\n- Limit webhook scope to specific namespaces using namespaceSelector\n- Filter webhook invocations by object labels using objectSelector\n- Combine multiple customizations in a single patch\n\nExample (limit to labeled namespaces):\n\n\t// +kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,...,patch=`{\"namespaceSelector\":{\"matchLabels\":{\"webhook-enabled\":\"true\"}}}`\n\nExample (filter by object labels with matchExpressions):\n\n\t// +kubebuilder:webhook:path=/validate-v1-deployment,...,patch=`{\"objectSelector\":{\"matchExpressions\":[{\"key\":\"tier\",\"operator\":\"In\",\"values\":[\"frontend\",\"backend\"]}]}}`\n\nExample (combine namespace and object selectors):\n\n\t// +kubebuilder:webhook:...,patch=`{\"namespaceSelector\":{\"matchLabels\":{\"env\":\"production\"}},\"objectSelector\":{\"matchLabels\":{\"managed-by\":\"my-operator\"}}}`\n\nExample (override timeout):\n\n\t// +kubebuilder:webhook:...,patch=`{\"timeoutSeconds\":25}`",
+				Details: "This allows you to set any webhook field, including the ones that aren't directly exposed\nas marker parameters. The patch is a JSON object\nthat follows Kubernetes strategic merge patch semantics and is applied to the webhook\nconfiguration after all other marker parameters are processed.\n\nUse backticks to avoid escaping quotes in the JSON.\n\nCommon use cases:\n- Limit webhook scope to specific namespaces using namespaceSelector\n- Filter webhook invocations by object labels using objectSelector\n- Combine multiple customizations in a single patch\n\nExample (limit to labeled namespaces):\n\n\t// +kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,...,patch=`{\"namespaceSelector\":{\"matchLabels\":{\"webhook-enabled\":\"true\"}}}`\n\nExample (filter by object labels with matchExpressions):\n\n\t// +kubebuilder:webhook:path=/validate-v1-deployment,...,patch=`{\"objectSelector\":{\"matchExpressions\":[{\"key\":\"tier\",\"operator\":\"In\",\"values\":[\"frontend\",\"backend\"]}]}}`\n\nExample (combine namespace and object selectors):\n\n\t// +kubebuilder:webhook:...,patch=`{\"namespaceSelector\":{\"matchLabels\":{\"env\":\"production\"}},\"objectSelector\":{\"matchLabels\":{\"managed-by\":\"my-operator\"}}}`\n\nExample (override timeout):\n\n\t// +kubebuilder:webhook:...,patch=`{\"timeoutSeconds\":25}`",
 			},
 		},
 	}
 }
 
+func (ConversionHub) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "Webhook",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "marks this version of a kind as the hub version for conversion.",
+			Details: "The other (spoke) versions of the kind convert to and from the hub version,\nas with the Hub and Convertible interfaces of controller-runtime.  With the\nconversion option of the webhook generator, a CRD patch enabling the\nconversion webhook is generated for each kind with multiple versions and a\nhub version.\n\nExample:\n\n\t// +kubebuilder:conversion:hub\n\ttype MyKind struct {\n\t    metav1.TypeMeta\n\t    metav1.ObjectMeta\n\t}",
+		},
+		FieldHelp: map[string]markers.DetailedHelp{},
+	}
+}
+
 func (Generator) Help() *markers.DefinitionHelp {
 	return &markers.DefinitionHelp{
 		Category: "",
@@ -119,6 +142,70 @@ func (Generator) Help() *markers.DefinitionHelp {
 			Summary: "generates (partial) {Mutating,Validating}WebhookConfiguration objects.",
 			Details: "",
 		},
+		FieldHelp: map[string]markers.DetailedHelp{
+			"Conversion": {
+				Summary: "enables generating CustomResourceDefinition patches that enable",
+				Details: "the conversion webhook of kinds with multiple versions and a hub version\n(marked with +kubebuilder:conversion:hub), written to\nconversion_<group>_<plural>.yaml.\n\nThe conversion webhook is served by the same service as the other webhooks.",
+			},
+			"ConversionPath": {
+				Summary: "specifies the path the conversion webhook is served on.",
+				Details: "Defaults to \"/convert\", as served by controller-runtime.",
+			},
+			"CABundle": {
+				Summary: "specifies the (base64-encoded) CA bundle to set on the client",
+				Details: "configs of the generated webhooks, e.g. a literal bundle, or a placeholder\nto be replaced when deploying.",
+			},
+			"CertManagerCertificate": {
+				Summary: "specifies the cert-manager Certificate",
+				Details: "(\"<namespace>/<name>\") whose CA is injected into the generated objects,\nby adding the cert-manager.io/inject-ca-from annotation.\n\nThe namespace may be omitted, or contain \"$(SERVICE_NAMESPACE)\", to use\nthe namespace of the webhook service.",
+			},
+			"Split": {
+				Summary: "splits the generated configurations into one file per webhook",
+				Details: "service (\"service\") or per API group (\"group\"), written to\n<service namespace>-<service name>/manifests.yaml or\n<group>/manifests.yaml, so that independently deployed webhook servers\ncan own their own manifests.  The names of the split configurations are\nsuffixed with the same key, so that they don't conflict.\n\nWebhooks served from a URL are split into \"external\" when splitting by\nservice, and webhooks for the core API group into \"core\" when splitting\nby group.",
+			},
+			"NameTemplate": {
+				Summary: "specifies the template of the names of webhooks whose markers",
+				Details: "don't set one, e.g. \"{kind}.{group}.example.com\".\n\nThe template may contain {operation} (\"mutate\" or \"validate\"), {resource}\n(the first resource of the webhook), {kind} (its singular form), {group}\n(its group, or \"core\") and {version} (its first version).",
+			},
+			"AuditAgainst": {
+				Summary: "enables audit mode, comparing the generated webhook",
+				Details: "configurations against the ones in the given YAML file (or the YAML\nfiles in the given directory) instead of writing them out.\n\nWebhooks that are generated but missing, present but no longer\ngenerated, or whose settings or rules differ, are reported as errors.",
+			},
+			"AuditCluster": {
+				Summary: "enables audit mode against the webhook configurations",
+				Details: "installed in the cluster of the run instead (see the --kubeconfig and\n--context flags), as with AuditAgainst.",
+			},
+			"DefaultFailurePolicy": {
+				Summary: "specifies the failurePolicy of webhooks whose markers",
+				Details: "don't set one.",
+			},
+			"DefaultSideEffects": {
+				Summary: "specifies the sideEffects of webhooks whose markers",
+				Details: "don't set them.",
+			},
+			"DefaultTimeoutSeconds": {
+				Summary: "specifies the timeoutSeconds of webhooks whose",
+				Details: "markers don't set it.",
+			},
+			"HeaderFile": {
+				Summary: "specifies the header text (e.g. license) to prepend to generated files.",
+				Details: "",
+			},
+			"Year": {
+				Summary: "specifies the year to substitute for \" YEAR\" in the header file.",
+				Details: "",
+			},
+		},
+	}
+}
+
+func (RegistrationGenerator) Help() *markers.DefinitionHelp {
+	return &markers.DefinitionHelp{
+		Category: "",
+		DetailedHelp: markers.DetailedHelp{
+			Summary: "generates Go code registering webhook handlers with",
+			Details: "the webhook server of a controller-runtime manager, on the paths declared by\nthe webhook markers of each package.\n\nFor each package with webhook markers, zz_generated.webhook.go contains a\nconstant with the path of each webhook, a WebhookHandlers struct with a\nfield for the handler of each webhook, and a SetupWebhooksWithManager\nfunction registering them, so that the registered paths always match the\ngenerated webhook configurations.  Webhooks served from a URL are skipped.",
+		},
 		FieldHelp: map[string]markers.DetailedHelp{
 			"HeaderFile": {
 				Summary: "specifies the header text (e.g. license) to prepend to generated files.",
@@ -128,6 +215,14 @@ func (Generator) Help() *markers.DefinitionHelp {
 				Summary: "specifies the year to substitute for \" YEAR\" in the header file.",
 				Details: "",
 			},
+			"EnvtestManifestsDir": {
+				Summary: "enables generating a test suite, in",
+				Details: "zz_generated.webhook_test.go, checking that the generated webhook\nconfigurations in the given directory (relative to each package) call\nthe registered handlers.\n\nThe suite starts an envtest environment with the webhook configurations\npointed at a local webhook server, registers recording handlers with\nSetupWebhooksWithManager, and creates a sample object (as a dry run)\nfor each webhook handling creation, failing if its handler isn't\ncalled.  Since the sample objects are empty, webhooks aren't called for\nkinds whose empty objects are invalid, so those are skipped.",
+			},
+			"EnvtestCRDDirs": {
+				Summary: "specifies the directories (relative to each package) of",
+				Details: "the CustomResourceDefinitions to install for the test suite, for\nwebhooks of custom resources.",
+			},
 		},
 	}
 }